@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	"fmt"
 
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -52,6 +53,11 @@ type PodAutoscalerSpec struct {
 	// ScaleTargetRef points to scale-able resource that this PodAutoscaler should target and scale. e.g. Deployment
 	ScaleTargetRef corev1.ObjectReference `json:"scaleTargetRef"`
 
+	// RayWorkerGroup scales the worker group of this name in the RayClusters of a RayClusterFleet target instead of
+	// the fleet replicas. The replicas and their limits count the workers of the group across the fleet.
+	// +optional
+	RayWorkerGroup string `json:"rayWorkerGroup,omitempty"`
+
 	//// PodSelector allows for more flexible selection of pods to scale based on labels.
 	//PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
 
@@ -64,12 +70,46 @@ type PodAutoscalerSpec struct {
 	MaxReplicas int32 `json:"maxReplicas"`
 
 	// MetricsSources defines a list of sources from which metrics are collected to make scaling decisions.
+	// Each metric computes desired replicas on its own, which are combined according to MetricsAggregation.
 	// +kubebuilder:validation:MinItems=1
 	MetricsSources []MetricSource `json:"metricsSources,omitempty"`
 
+	// MetricsAggregation defines how the desired replicas of multiple metrics are combined, Max by default.
+	// +kubebuilder:validation:Enum={Max,Weighted}
+	// +optional
+	MetricsAggregation MetricsAggregationType `json:"metricsAggregation,omitempty"`
+
 	// ScalingStrategy defines the strategy to use for scaling.
-	// +kubebuilder:validation:Enum={HPA,KPA,APA}
+	// +kubebuilder:validation:Enum={HPA,KPA,APA,SLO}
 	ScalingStrategy ScalingStrategyType `json:"scalingStrategy"`
+
+	// SLOTargets defines the latency objectives of the SLO scaling strategy, which takes no MetricsSources.
+	// +optional
+	SLOTargets *SLOTargets `json:"sloTargets,omitempty"`
+
+	// Behavior configures the scaling behavior in the up and down directions, unrestricted if unset.
+	// +optional
+	Behavior *PodAutoscalerBehavior `json:"behavior,omitempty"`
+
+	// Pools splits the scaled capacity over targets serving the model on different GPU types. The replicas, minReplicas
+	// and maxReplicas of the pa then count capacity units, which are split over the pools by their weights.
+	// +optional
+	Pools []ScalingPool `json:"pools,omitempty"`
+}
+
+// PodAutoscalerBehavior configures the scaling behavior like the behavior of a HorizontalPodAutoscaler, it damps
+// replica flapping on bursty traffic.
+type PodAutoscalerBehavior struct {
+	// ScaleUp is the scaling policy for scaling up, scaling up is not restricted if unset.
+	// +optional
+	ScaleUp *autoscalingv2.HPAScalingRules `json:"scaleUp,omitempty"`
+	// ScaleDown is the scaling policy for scaling down, scaling down is not restricted if unset.
+	// +optional
+	ScaleDown *autoscalingv2.HPAScalingRules `json:"scaleDown,omitempty"`
+	// PanicThreshold is the factor of the observed load over the load the ready pods handle at which KPA enters panic
+	// mode, e.g. "2" for twice. It overrides the kpa.autoscaling.aibrix.ai/panic-threshold annotation.
+	// +optional
+	PanicThreshold string `json:"panicThreshold,omitempty"`
 }
 
 // ScalingStrategyType defines the type for scaling strategies.
@@ -84,6 +124,56 @@ const (
 
 	// APA represents the AiBrix Pod Autoscaling Algorithm
 	APA ScalingStrategyType = "APA"
+
+	// SLO scales on observed latency percentiles versus the SLOTargets
+	SLO ScalingStrategyType = "SLO"
+)
+
+// SLOTargets defines the latency objectives of the SLO scaling strategy. At least one of TTFT and ITL must be set,
+// the target replicas follow the latency furthest above its target.
+type SLOTargets struct {
+	// Percentile of the requests which should meet the targets, e.g. "99" for P99. Defaults to "90".
+	// +optional
+	Percentile string `json:"percentile,omitempty"`
+	// TTFT is the target time to first token in seconds, e.g. "0.5".
+	// +optional
+	TTFT string `json:"ttft,omitempty"`
+	// ITL is the target inter-token latency in seconds, e.g. "0.05".
+	// +optional
+	ITL string `json:"itl,omitempty"`
+}
+
+// ScalingPool is a target serving the model on one GPU type.
+type ScalingPool struct {
+	// Name of the pool, e.g. the GPU type.
+	Name string `json:"name"`
+	// ScaleTargetRef points to the scale-able resource of the pool, the scaleTargetRef of the pa if unset.
+	// +optional
+	ScaleTargetRef *corev1.ObjectReference `json:"scaleTargetRef,omitempty"`
+	// Capacity is the throughput of a replica of the pool in capacity units, e.g. "2" for a GPU type serving twice the
+	// requests of the reference one. Defaults to "1".
+	// +optional
+	Capacity string `json:"capacity,omitempty"`
+	// Weight is the share of the capacity placed on the pool relative to the other pools. Defaults to "1".
+	// +optional
+	Weight string `json:"weight,omitempty"`
+	// MinReplicas is the minimum number of replicas of the pool.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the maximum number of replicas of the pool, e.g. the GPUs of the type available.
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+}
+
+// MetricsAggregationType defines how the desired replicas of multiple metrics are combined.
+type MetricsAggregationType string
+
+const (
+	// MaxAggregation scales to the highest desired replicas of all metrics, so no metric exceeds its target.
+	MaxAggregation MetricsAggregationType = "Max"
+
+	// WeightedAggregation scales to the weighted average of the desired replicas of all metrics, rounded up.
+	WeightedAggregation MetricsAggregationType = "Weighted"
 )
 
 type MetricSourceType string
@@ -93,6 +183,12 @@ const (
 	POD MetricSourceType = "pod"
 	// DOMAIN only need to access specified domain
 	DOMAIN MetricSourceType = "domain"
+	// KAFKA reads the lag of a consumer group from a Kafka REST proxy
+	KAFKA MetricSourceType = "kafka"
+	// REDIS reads the length of a queue in Redis
+	REDIS MetricSourceType = "redis"
+	// JSON reads a number from the JSON response of an endpoint
+	JSON MetricSourceType = "json"
 )
 
 type ProtocolType string
@@ -104,22 +200,94 @@ const (
 
 // MetricSource defines an endpoint and path from which metrics are collected.
 type MetricSource struct {
-	// access an endpoint or scan a list of k8s pod
-	// +kubebuilder:validation:Enum={pod,domain}
+	// access an endpoint, scan a list of k8s pod or read an external system
+	// +kubebuilder:validation:Enum={pod,domain,kafka,redis,json}
 	MetricSourceType MetricSourceType `json:"metricSourceType"`
-	// http or https
+	// http or https, meaningless for MetricSourceType.REDIS
 	// +kubebuilder:validation:Enum={http,https}
-	ProtocolType ProtocolType `json:"protocolType"`
+	// +optional
+	ProtocolType ProtocolType `json:"protocolType,omitempty"`
 	// e.g. service1.example.com. meaningless for MetricSourceType.POD
 	Endpoint string `json:"endpoint,omitempty"`
-	// e.g. /api/metrics/cpu
-	Path string `json:"path"`
+	// e.g. /api/metrics/cpu. meaningless for MetricSourceType.KAFKA and MetricSourceType.REDIS
+	// +optional
+	Path string `json:"path,omitempty"`
 	// e.g. 8080. meaningless for MetricSourceType.DOMAIN
 	Port string `json:"port,omitempty"`
 	// TargetMetric identifies the specific metric to monitor (e.g., kv_cache_utilization).
 	TargetMetric string `json:"targetMetric"`
 	// TargetValue sets the desired threshold for the metric (e.g., 50 for 50% utilization).
 	TargetValue string `json:"targetValue"`
+	// Weight of the metric for the Weighted aggregation, e.g. 2 for a metric counting twice. Defaults to 1.
+	// +optional
+	Weight string `json:"weight,omitempty"`
+	// UpFluctuationTolerance overrides the tolerance of APA before scaling up for this metric, e.g. 0.1 for 10% above the target.
+	// +optional
+	UpFluctuationTolerance string `json:"upFluctuationTolerance,omitempty"`
+	// DownFluctuationTolerance overrides the tolerance of APA before scaling down for this metric, e.g. 0.2 for 20% below the target.
+	// +optional
+	DownFluctuationTolerance string `json:"downFluctuationTolerance,omitempty"`
+	// Kafka configures the consumer lag of MetricSourceType.KAFKA.
+	// +optional
+	Kafka *KafkaMetricSource `json:"kafka,omitempty"`
+	// Redis configures the queue of MetricSourceType.REDIS.
+	// +optional
+	Redis *RedisMetricSource `json:"redis,omitempty"`
+	// JSON configures the field of MetricSourceType.JSON.
+	// +optional
+	JSON *JSONMetricSource `json:"json,omitempty"`
+	// Auth configures the credentials to access the endpoint, for all but MetricSourceType.POD.
+	// +optional
+	Auth *MetricSourceAuth `json:"auth,omitempty"`
+	// TLS configures the verification of the certificate of the endpoint, for the https sources, and connects to
+	// MetricSourceType.REDIS over TLS.
+	// +optional
+	TLS *MetricSourceTLS `json:"tls,omitempty"`
+}
+
+// KafkaMetricSource reads the lag of a consumer group from the v3 API of a Kafka REST proxy at the endpoint.
+type KafkaMetricSource struct {
+	// ClusterID of the Kafka cluster in the REST proxy.
+	ClusterID string `json:"clusterID"`
+	// ConsumerGroup whose lag is the metric value.
+	ConsumerGroup string `json:"consumerGroup"`
+	// Topic limits the lag to the partitions of a topic, all topics of the consumer group by default.
+	// +optional
+	Topic string `json:"topic,omitempty"`
+}
+
+// RedisMetricSource reads the length of a list, stream, set or sorted set from the Redis at the endpoint.
+type RedisMetricSource struct {
+	// Key of the queue.
+	Key string `json:"key"`
+	// DB of the key, 0 by default.
+	// +optional
+	DB int32 `json:"db,omitempty"`
+}
+
+// JSONMetricSource reads a number from the JSON response of the endpoint and path.
+type JSONMetricSource struct {
+	// Field is the path of the number in the response in GJSON syntax, e.g. "data.queue.length".
+	Field string `json:"field"`
+}
+
+// MetricSourceAuth refers to the credentials of a metric source.
+type MetricSourceAuth struct {
+	// SecretName of the Secret in the namespace of the PodAutoscaler. Its "token" key is sent as bearer token, its
+	// "username" and "password" keys as basic auth, or as Redis ACL user and password.
+	SecretName string `json:"secretName"`
+}
+
+// MetricSourceTLS configures the verification of the certificate of a metric source, verified with the system roots
+// by default.
+type MetricSourceTLS struct {
+	// CASecretName of the Secret in the namespace of the PodAutoscaler whose "ca.crt" key is the CA bundle verifying
+	// the certificate of the endpoint.
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+	// InsecureSkipVerify disables the verification of the certificate of the endpoint, for test setups only.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
 }
 
 // PodAutoscalerStatus defines the observed state of PodAutoscaler
@@ -144,6 +312,53 @@ type PodAutoscalerStatus struct {
 	// Conditions is the set of conditions required for this autoscaler to scale its target,
 	// and indicates whether or not those conditions are met.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Pools is the breakdown of the replicas over the pools of the spec.
+	// +optional
+	Pools []PoolStatus `json:"pools,omitempty"`
+
+	// Placement is the cheapest mix of pools recommended for the workload of the model, with the
+	// autoscaling.aibrix.ai/placement annotation.
+	// +optional
+	Placement *PlacementStatus `json:"placement,omitempty"`
+}
+
+// PoolStatus is the scale of a pool.
+type PoolStatus struct {
+	// Name of the pool.
+	Name string `json:"name"`
+	// DesiredReplicas is the number of replicas of the pool computed by the PodAutoscaler.
+	DesiredReplicas int32 `json:"desiredReplicas"`
+	// ActualReplicas is the number of replicas of the pool when the PodAutoscaler last observed it.
+	ActualReplicas int32 `json:"actualReplicas"`
+}
+
+// PlacementStatus is the cheapest replicas of the pools serving the request rate of the model within the SLO of
+// their GPU profiles.
+type PlacementStatus struct {
+	// LastUpdateTime is when the recommendation was computed.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+	// RequestRate is the requests per second the recommendation serves, the P90 arrival rate of the model.
+	RequestRate string `json:"requestRate"`
+	// UnservedRequestRate is the requests per second the pools cannot serve within their SLO and limits.
+	// +optional
+	UnservedRequestRate string `json:"unservedRequestRate,omitempty"`
+	// Cost is the cost of the recommended replicas, in the unit of the costs of the GPU profiles.
+	Cost string `json:"cost"`
+	// Applied tells whether the pools are scaled in the proportions of the recommendation instead of their weights.
+	Applied bool `json:"applied"`
+	// Pools are the recommended replicas of each pool.
+	Pools []PoolPlacement `json:"pools,omitempty"`
+}
+
+// PoolPlacement is the recommended replicas of a pool.
+type PoolPlacement struct {
+	// Name of the pool.
+	Name string `json:"name"`
+	// Replicas recommended for the pool.
+	Replicas int32 `json:"replicas"`
+	// Cost of the replicas.
+	Cost string `json:"cost"`
 }
 
 // +kubebuilder:object:root=true
@@ -169,7 +384,8 @@ const (
 	QPS = "qps"
 )
 
-// GetPaMetricSources Currently, we don't support metric resources that are more than one yet.
+// GetPaMetricSources returns the single metric source of the PodAutoscaler. Scalers of KPA and APA work on one metric,
+// PodAutoscalers with multiple metrics run a scaler per metric, see NewNamespaceNameMetrics.
 func GetPaMetricSources(pa PodAutoscaler) (MetricSource, error) {
 	if len(pa.Spec.MetricsSources) != 1 {
 		return MetricSource{}, fmt.Errorf("for now we only support one MetricsSource")
@@ -21,6 +21,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// DesiredInstancesAnnotation is set by the gateway to load the adapter on more pods than spec.replicas while all
+	// the pods hosting it are saturated.
+	DesiredInstancesAnnotation = "adapter.model.aibrix.ai/desired-instances"
+	// DesiredInstancesTimeAnnotation is the RFC 3339 time the gateway last raised the desired instances. The
+	// controller falls back to spec.replicas once it's older than the scale-in idle window.
+	DesiredInstancesTimeAnnotation = "adapter.model.aibrix.ai/desired-instances-time"
+)
+
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // ModelAdapterSpec defines the desired state of ModelAdapter
@@ -39,15 +48,26 @@ type ModelAdapterSpec struct {
 	// +kubebuilder:default=default
 	SchedulerName string `json:"schedulerName,omitempty"`
 
-	// ArtifactURL is the address of the model artifact to be downloaded. Different protocol is supported like s3,gcs,huggingface
+	// SchedulingPolicy decides on which pods the adapter instances are placed.
+	// +optional
+	// +kubebuilder:default=Spread
+	SchedulingPolicy ModelAdapterSchedulingPolicy `json:"schedulingPolicy,omitempty"`
+
+	// ArtifactURL is the address of the model artifact to be downloaded. Different protocol is supported like s3,gcs,oci,huggingface
 	// +kubebuilder:validation:Required
 	ArtifactURL string `json:"artifactURL,omitempty"`
 
+	// ArtifactChecksum is the sha256 digest of the adapter weights, e.g. "sha256:<hex>". The runtime sidecar verifies
+	// adapter_model.safetensors, or the artifact itself if it's a single file, before the adapter is loaded.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	ArtifactChecksum string `json:"artifactChecksum,omitempty"`
+
 	// CredentialsSecretRef points to the secret used to authenticate the artifact download requests
 	// +optional
 	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
 
-	// Replicas is the desired number of replicas of model adapter
+	// Replicas is the number of distinct pods the model adapter is loaded on, spread across zones when possible.
 	// +optional
 	// +kubebuilder:default=1
 	Replicas *int32 `json:"replicas,omitempty"`
@@ -55,6 +75,59 @@ type ModelAdapterSpec struct {
 	// Additional fields can be added here to customize the scheduling and deployment
 	// +optional
 	AdditionalConfig map[string]string `json:"additionalConfig,omitempty"`
+
+	// RolloutStrategy rolls a changed ArtifactURL out to the instances progressively. If unset, all instances load the
+	// new artifact at once.
+	// +optional
+	RolloutStrategy *ModelAdapterRolloutStrategy `json:"rolloutStrategy,omitempty"`
+}
+
+// ModelAdapterSchedulingPolicy decides how the adapter instances are placed on the pods.
+// +kubebuilder:validation:Enum=Spread;Pack;LeastLoaded
+type ModelAdapterSchedulingPolicy string
+
+const (
+	// ModelAdapterSchedulingSpread places the instances on the pods hosting the fewest adapters.
+	ModelAdapterSchedulingSpread ModelAdapterSchedulingPolicy = "Spread"
+	// ModelAdapterSchedulingPack places the instances on the pods hosting the most adapters that still have
+	// room for one more, so fewer pods spend memory on LoRA slots.
+	ModelAdapterSchedulingPack ModelAdapterSchedulingPolicy = "Pack"
+	// ModelAdapterSchedulingLeastLoaded places the instances on the pods serving the fewest requests.
+	ModelAdapterSchedulingLeastLoaded ModelAdapterSchedulingPolicy = "LeastLoaded"
+)
+
+// ModelAdapterRolloutStrategy loads a new artifact on a share of the instances first and adds more instances after
+// each successful analysis, or rolls all instances back to the previous artifact if the analysis fails.
+type ModelAdapterRolloutStrategy struct {
+	// CanaryPercent is the percentage of the instances which load the new artifact first, at least one instance.
+	// +optional
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	CanaryPercent int32 `json:"canaryPercent,omitempty"`
+
+	// StepPercent is the percentage of the instances which load the new artifact after each successful analysis.
+	// +optional
+	// +kubebuilder:default=25
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	StepPercent int32 `json:"stepPercent,omitempty"`
+
+	// AnalysisIntervalSeconds is how long the instances serve the new artifact before each analysis.
+	// +optional
+	// +kubebuilder:default=60
+	// +kubebuilder:validation:Minimum=1
+	AnalysisIntervalSeconds int32 `json:"analysisIntervalSeconds,omitempty"`
+
+	// MaxErrorRate is the highest share of the instances serving the new artifact whose engine may fail, e.g. "0.1".
+	// Defaults to "0".
+	// +optional
+	MaxErrorRate string `json:"maxErrorRate,omitempty"`
+
+	// MaxLatencyRatio is the highest mean request latency of the instances serving the new artifact relative to
+	// the other instances, e.g. "1.2". Defaults to "1.5".
+	// +optional
+	MaxLatencyRatio string `json:"maxLatencyRatio,omitempty"`
 }
 
 // ModelAdapterPhase is a string representation of the ModelAdapter lifecycle phase.
@@ -94,6 +167,83 @@ type ModelAdapterStatus struct {
 	// Instances lists all pod instances of ModelAdapter
 	// +optional
 	Instances []string `json:"instances,omitempty"`
+	// InstanceStatuses is the load state of the adapter on each pod it's scheduled to, including the pods
+	// which failed to load it.
+	// +listType=map
+	// +listMapKey=podName
+	// +optional
+	InstanceStatuses []ModelAdapterInstanceStatus `json:"instanceStatuses,omitempty"`
+	// ArtifactURL is the artifact loaded on the instances, which differs from the spec while a rollout is in
+	// progress or after it was rolled back.
+	// +optional
+	ArtifactURL string `json:"artifactURL,omitempty"`
+	// Rollout is the state of the latest rollout of the ArtifactURL.
+	// +optional
+	Rollout *ModelAdapterRolloutStatus `json:"rollout,omitempty"`
+}
+
+// ModelAdapterInstancePhase is the load state of the adapter on a pod.
+type ModelAdapterInstancePhase string
+
+const (
+	// ModelAdapterInstancePending means the adapter has been scheduled to the pod but not loaded yet.
+	ModelAdapterInstancePending ModelAdapterInstancePhase = "Pending"
+	// ModelAdapterInstanceLoading means the artifact is being downloaded to the node of the pod.
+	ModelAdapterInstanceLoading ModelAdapterInstancePhase = "Loading"
+	// ModelAdapterInstanceReady means the adapter is loaded and served on the pod.
+	ModelAdapterInstanceReady ModelAdapterInstancePhase = "Ready"
+	// ModelAdapterInstanceFailed means the adapter failed to load on the pod, loading is retried.
+	ModelAdapterInstanceFailed ModelAdapterInstancePhase = "Failed"
+)
+
+// ModelAdapterInstanceStatus is the load state of the adapter on a pod.
+type ModelAdapterInstanceStatus struct {
+	// PodName is the name of the pod.
+	PodName string `json:"podName"`
+	// Phase is the load state of the adapter on the pod.
+	Phase ModelAdapterInstancePhase `json:"phase"`
+	// Reason is a brief CamelCase reason for the phase.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message explains the phase, e.g. why loading failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the phase changed.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// LastUpdateTime is the last time the reason or message changed.
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// ModelAdapterRolloutPhase is the state of a rollout.
+type ModelAdapterRolloutPhase string
+
+const (
+	// ModelAdapterRolloutProgressing means the new artifact is being loaded on more instances step by step.
+	ModelAdapterRolloutProgressing ModelAdapterRolloutPhase = "Progressing"
+	// ModelAdapterRolloutCompleted means all instances serve the new artifact.
+	ModelAdapterRolloutCompleted ModelAdapterRolloutPhase = "Completed"
+	// ModelAdapterRolloutRolledBack means the analysis failed and all instances serve the previous artifact again,
+	// until the ArtifactURL of the spec changes.
+	ModelAdapterRolloutRolledBack ModelAdapterRolloutPhase = "RolledBack"
+)
+
+// ModelAdapterRolloutStatus is the state of a rollout.
+type ModelAdapterRolloutStatus struct {
+	// Phase of the rollout.
+	Phase ModelAdapterRolloutPhase `json:"phase"`
+	// ArtifactURL is the artifact being rolled out.
+	ArtifactURL string `json:"artifactURL"`
+	// UpdatedInstances lists the instances serving the artifact being rolled out.
+	// +optional
+	UpdatedInstances []string `json:"updatedInstances,omitempty"`
+	// LastStepTime is the last time more instances loaded the artifact being rolled out.
+	// +optional
+	LastStepTime *metav1.Time `json:"lastStepTime,omitempty"`
+	// Message explains the latest analysis.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 type ModelAdapterConditionType string
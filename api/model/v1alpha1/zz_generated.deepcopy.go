@@ -85,6 +85,62 @@ func (in *ModelAdapterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelAdapterInstanceStatus) DeepCopyInto(out *ModelAdapterInstanceStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAdapterInstanceStatus.
+func (in *ModelAdapterInstanceStatus) DeepCopy() *ModelAdapterInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelAdapterInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelAdapterRolloutStatus) DeepCopyInto(out *ModelAdapterRolloutStatus) {
+	*out = *in
+	if in.UpdatedInstances != nil {
+		in, out := &in.UpdatedInstances, &out.UpdatedInstances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastStepTime != nil {
+		in, out := &in.LastStepTime, &out.LastStepTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAdapterRolloutStatus.
+func (in *ModelAdapterRolloutStatus) DeepCopy() *ModelAdapterRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelAdapterRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelAdapterRolloutStrategy) DeepCopyInto(out *ModelAdapterRolloutStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAdapterRolloutStrategy.
+func (in *ModelAdapterRolloutStrategy) DeepCopy() *ModelAdapterRolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelAdapterRolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModelAdapterSpec) DeepCopyInto(out *ModelAdapterSpec) {
 	*out = *in
@@ -115,6 +171,11 @@ func (in *ModelAdapterSpec) DeepCopyInto(out *ModelAdapterSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(ModelAdapterRolloutStrategy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAdapterSpec.
@@ -142,6 +203,18 @@ func (in *ModelAdapterStatus) DeepCopyInto(out *ModelAdapterStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.InstanceStatuses != nil {
+		in, out := &in.InstanceStatuses, &out.InstanceStatuses
+		*out = make([]ModelAdapterInstanceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(ModelAdapterRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelAdapterStatus.
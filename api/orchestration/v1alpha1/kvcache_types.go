@@ -83,11 +83,16 @@ type CacheSpec struct {
 	// +kubebuilder:default:="IfNotPresent"
 	ImagePullPolicy string `json:"imagePullPolicy,omitempty"`
 
-	// shared memory size for kvcach
+	// shared memory size for kvcach, it is the memory the cache server allocates objects from.
+	// The cache server uses its default size when it is empty.
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=""
 	SharedMemorySize string `json:"sharedMemorySize,omitempty"`
 
+	// Eviction configures how the cache makes room once its shared memory fills up.
+	// +kubebuilder:validation:Optional
+	Eviction *EvictionSpec `json:"eviction,omitempty"`
+
 	// kvcache environment configuration
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:={}
@@ -104,6 +109,43 @@ type CacheSpec struct {
 	CPU string `json:"cpu,omitempty"`
 }
 
+// EvictionPolicy is the way the cache makes room for new kv tensors.
+// +kubebuilder:validation:Enum=None;Spill
+type EvictionPolicy string
+
+const (
+	// EvictionPolicyNone rejects new objects once the shared memory is full.
+	EvictionPolicyNone EvictionPolicy = "None"
+	// EvictionPolicySpill spills cold objects to the local disk of the cache pod.
+	EvictionPolicySpill EvictionPolicy = "Spill"
+)
+
+// EvictionSpec configures the eviction of the cache.
+type EvictionSpec struct {
+	// Policy is the eviction policy of the cache.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="None"
+	Policy EvictionPolicy `json:"policy,omitempty"`
+
+	// HighWatermark is the percentage of shared memory usage that starts the eviction.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=80
+	HighWatermark int32 `json:"highWatermark,omitempty"`
+
+	// LowWatermark is the percentage of shared memory usage the eviction stops at.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=30
+	LowWatermark int32 `json:"lowWatermark,omitempty"`
+
+	// SpillVolumeSize limits the local disk used by the Spill policy, it is unlimited when empty.
+	// +kubebuilder:validation:Optional
+	SpillVolumeSize string `json:"spillVolumeSize,omitempty"`
+}
+
 // KVCacheSpec defines the desired state of KVCache
 type KVCacheSpec struct {
 	// Replicas is the number of kv cache pods to deploy
@@ -139,10 +181,29 @@ type KVCacheStatus struct {
 	ReadyReplicas int32 `json:"current,omitempty"`
 	// Represents the kv cache deployment's current state.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// RPCEndpoint is the host:port inference engines connect to the cache at.
+	RPCEndpoint string `json:"rpcEndpoint,omitempty"`
+	// MetadataEndpoint is the url of the metadata service of the cache.
+	MetadataEndpoint string `json:"metadataEndpoint,omitempty"`
+	// SocketHostPath is the host directory of the unix domain socket of the cache, inference
+	// engines colocated with a cache pod mount it to exchange kv tensors through shared memory.
+	SocketHostPath string `json:"socketHostPath,omitempty"`
+	// ConnectionConfigMap is the ConfigMap holding the connection environment variables of the cache,
+	// inference engines load them with envFrom.
+	ConnectionConfigMap string `json:"connectionConfigMap,omitempty"`
 }
 
+// These are valid conditions of a KVCache.
+const (
+	// KVCacheReady means all the cache pods are ready.
+	KVCacheReady = "Ready"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.current"
+//+kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".status.rpcEndpoint"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // KVCache is the Schema for the kvcaches API
 type KVCache struct {
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	rayclusterv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -32,3 +33,78 @@ type RayClusterTemplateSpec struct {
 	// +optional
 	Spec rayclusterv1.RayClusterSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 }
+
+// GangScheduler is the batch scheduler that admits the pods of a ray cluster as a gang.
+// +kubebuilder:validation:Enum=volcano;kueue
+type GangScheduler string
+
+const (
+	// VolcanoGangScheduler lets KubeRay create a volcano PodGroup for every ray cluster.
+	// KubeRay has to be started with batch scheduling enabled.
+	VolcanoGangScheduler GangScheduler = "volcano"
+	// KueueGangScheduler queues every ray cluster as a kueue workload, which is admitted as a whole.
+	KueueGangScheduler GangScheduler = "kueue"
+)
+
+// GangSchedulingPolicy describes how the head and worker pods of a ray cluster are admitted
+// together, so a replica never holds accelerators while part of its pods can't be scheduled.
+type GangSchedulingPolicy struct {
+	// Scheduler is the gang scheduler the ray clusters are submitted to.
+	Scheduler GangScheduler `json:"scheduler"`
+
+	// Queue is the volcano queue or the kueue LocalQueue the ray clusters are submitted to.
+	// It is required by kueue, volcano uses its default queue when it is empty.
+	// +optional
+	Queue string `json:"queue,omitempty"`
+
+	// PriorityClassName is the priority class of the pods of the ray clusters, used by the
+	// scheduler to order and preempt gangs. It doesn't override a priority class set in the template.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// ServingProbe checks the model server behind the head service of a ray cluster. A ray cluster
+// whose pods are ready is only counted as ready once the probe succeeds.
+type ServingProbe struct {
+	// HTTPGet probes the model server with an HTTP GET request, a response with a status code
+	// in [200, 400) means it is serving. The host defaults to the head service of the ray cluster.
+	// +optional
+	HTTPGet *corev1.HTTPGetAction `json:"httpGet,omitempty"`
+
+	// GRPC probes the model server with the gRPC health checking protocol.
+	// +optional
+	GRPC *corev1.GRPCAction `json:"grpc,omitempty"`
+
+	// Number of seconds after which the probe times out. Defaults to 1.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// How often in seconds to perform the probe. Defaults to 10.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+}
+
+// RayClusterServingStatus is the serving state of a ray cluster checked by the serving probe.
+type RayClusterServingStatus struct {
+	// Name of the ray cluster.
+	Name string `json:"name"`
+
+	// Endpoint is the host:port the model of the ray cluster is served at.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Serving is true when the last probe of the model server succeeded.
+	Serving bool `json:"serving"`
+
+	// Last time serving changed.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// A human readable message indicating why the ray cluster isn't serving.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
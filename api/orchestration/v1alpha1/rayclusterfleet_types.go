@@ -20,6 +20,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
@@ -59,10 +60,19 @@ type RayClusterFleetSpec struct {
 	// +optional
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty" protobuf:"varint,6,opt,name=revisionHistoryLimit"`
 
-	// Indicates that the deployment is paused.
+	// Indicates that the deployment is paused. A paused fleet still follows changes of replicas,
+	// but template changes are not rolled out until it is resumed.
 	// +optional
 	Paused bool `json:"paused,omitempty" protobuf:"varint,7,opt,name=paused"`
 
+	// Partition is the number of ray clusters a rolling update keeps on the previous revisions,
+	// so a new engine version can be validated on part of the fleet before it is rolled out
+	// to the rest. Lowering it continues the rollout. Only used by the RollingUpdate strategy.
+	// Defaults to 0, which rolls out to all ray clusters.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Partition *int32 `json:"partition,omitempty" protobuf:"varint,10,opt,name=partition"`
+
 	// The maximum time in seconds for a deployment to make progress before it
 	// is considered to be failed. The deployment controller will continue to
 	// process failed deployments and a condition with a ProgressDeadlineExceeded
@@ -71,6 +81,38 @@ type RayClusterFleetSpec struct {
 	// +kubebuilder:default=600
 	// +optional
 	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty" protobuf:"varint,9,opt,name=progressDeadlineSeconds"`
+
+	// TopologySpreadConstraints describes how the ray clusters of the fleet are spread across
+	// topology domains such as zones and nodes. They are added to the head pod of every ray cluster,
+	// a constraint without labelSelector selects the head pods of the fleet.
+	// +optional
+	// +listType=map
+	// +listMapKey=topologyKey
+	// +listMapKey=whenUnsatisfiable
+	TopologySpreadConstraints []v1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// DisruptionBudget makes the fleet maintain a PodDisruptionBudget over the head pods of its
+	// ray clusters, so that voluntary disruptions don't take down several replicas at once.
+	// +optional
+	DisruptionBudget *RayClusterFleetDisruptionBudget `json:"disruptionBudget,omitempty"`
+
+	// GangScheduling makes the head and worker pods of every ray cluster of the fleet be admitted
+	// atomically by a gang scheduler.
+	// +optional
+	GangScheduling *GangSchedulingPolicy `json:"gangScheduling,omitempty"`
+
+	// ServingProbe checks the model server of every ray cluster of the fleet. When it is set, a ray
+	// cluster is ready only once its model answers the probe, rather than when its pods are ready.
+	// +optional
+	ServingProbe *ServingProbe `json:"servingProbe,omitempty"`
+}
+
+// RayClusterFleetDisruptionBudget defines the PodDisruptionBudget of a fleet.
+type RayClusterFleetDisruptionBudget struct {
+	// MaxUnavailable is the number or percentage of ray clusters whose head pod can be unavailable
+	// after voluntary disruptions. Defaults to 1.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
 // RayClusterFleetStatus defines the observed state of RayClusterFleet
@@ -113,6 +155,13 @@ type RayClusterFleetStatus struct {
 	// newest ReplicaSet.
 	// +optional
 	CollisionCount *int32 `json:"collisionCount,omitempty" protobuf:"varint,8,opt,name=collisionCount"`
+
+	// ServingStatuses is the serving state of every ray cluster of the fleet, reported when a
+	// serving probe is set, so that consumers like the gateway can tell which replicas are serving.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ServingStatuses []RayClusterServingStatus `json:"servingStatuses,omitempty"`
 }
 
 // DeploymentCondition describes the state of a deployment at a certain point.
@@ -151,6 +200,10 @@ const (
 // +genclient
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas"
+// +kubebuilder:printcolumn:name="Up-To-Date",type="integer",JSONPath=".status.updatedReplicas"
+// +kubebuilder:printcolumn:name="Available",type="integer",JSONPath=".status.availableReplicas"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // RayClusterFleet is the Schema for the rayclusterfleets API
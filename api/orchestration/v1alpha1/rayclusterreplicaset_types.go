@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -52,6 +53,24 @@ type RayClusterReplicaSetSpec struct {
 	// More info: https://kubernetes.io/docs/concepts/workloads/controllers/replicationcontroller#pod-template
 	// +optional
 	Template RayClusterTemplateSpec `json:"template,omitempty"`
+
+	// TopologySpreadConstraints are added to the head pod of every ray cluster of the replica set.
+	// They are set by the owning RayClusterFleet.
+	// +optional
+	// +listType=map
+	// +listMapKey=topologyKey
+	// +listMapKey=whenUnsatisfiable
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// GangScheduling makes the head and worker pods of every ray cluster of the replica set be
+	// admitted atomically by a gang scheduler.
+	// +optional
+	GangScheduling *GangSchedulingPolicy `json:"gangScheduling,omitempty"`
+
+	// ServingProbe checks the model server of every ray cluster of the replica set. When it is set,
+	// a ray cluster is ready only once its model answers the probe.
+	// +optional
+	ServingProbe *ServingProbe `json:"servingProbe,omitempty"`
 }
 
 // RayClusterReplicaSetStatus defines the observed state of RayClusterReplicaSet
@@ -83,6 +102,13 @@ type RayClusterReplicaSetStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,6,rep,name=conditions"`
+
+	// ServingStatuses is the serving state of every ray cluster of the replica set, reported when
+	// a serving probe is set.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ServingStatuses []RayClusterServingStatus `json:"servingStatuses,omitempty"`
 }
 
 // These are valid conditions of a replica set.
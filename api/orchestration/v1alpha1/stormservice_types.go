@@ -0,0 +1,170 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// RoleSpec describes a group of identical pods playing one role in the serving unit, e.g. prefill, decode or router.
+type RoleSpec struct {
+	// Name of the role, unique within the StormService.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Replicas is the number of pods of the role.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default:=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// UpgradeOrder orders the roles during an update with the InOrder strategy. Roles are updated from the
+	// lowest order to the highest, a role only starts once all the roles of lower orders are rolled out.
+	// Roles of the same order are updated together.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	UpgradeOrder int32 `json:"upgradeOrder,omitempty"`
+
+	// Template describes the pods of the role.
+	// +kubebuilder:validation:Required
+	Template corev1.PodTemplateSpec `json:"template"`
+}
+
+// StormServiceUpdateStrategyType is the way the roles of a StormService are updated.
+// +kubebuilder:validation:Enum=InOrder;Parallel
+type StormServiceUpdateStrategyType string
+
+const (
+	// InOrderStormServiceUpdateStrategyType updates the roles by ascending upgrade order.
+	InOrderStormServiceUpdateStrategyType StormServiceUpdateStrategyType = "InOrder"
+	// ParallelStormServiceUpdateStrategyType updates all the roles at once.
+	ParallelStormServiceUpdateStrategyType StormServiceUpdateStrategyType = "Parallel"
+)
+
+// StormServiceUpdateStrategy describes how the roles are replaced by an update.
+type StormServiceUpdateStrategy struct {
+	// Type of the update.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="InOrder"
+	Type StormServiceUpdateStrategyType `json:"type,omitempty"`
+
+	// MaxUnavailable is the maximum number of pods of a role that can be unavailable during its update.
+	// Defaults to 25%.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// MaxSurge is the maximum number of pods of a role that can be scheduled above its replicas during its update.
+	// Defaults to 25%.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XIntOrString
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+}
+
+// StormServiceSpec defines the desired state of StormService
+type StormServiceSpec struct {
+	// Roles are the pod groups composing the serving unit.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=name
+	Roles []RoleSpec `json:"roles"`
+
+	// UpdateStrategy is the way the roles are replaced when their templates change.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:={type: "InOrder"}
+	UpdateStrategy StormServiceUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// Paused holds template changes of the roles back, scaling the roles is still applied.
+	// +kubebuilder:validation:Optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// RoleStatus is the observed state of a role.
+type RoleStatus struct {
+	// Name of the role.
+	Name string `json:"name"`
+	// Replicas is the number of pods of the role.
+	Replicas int32 `json:"replicas"`
+	// ReadyReplicas is the number of ready pods of the role.
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// UpdatedReplicas is the number of pods of the role running the update revision.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+	// AvailableReplicas is the number of available pods of the role.
+	AvailableReplicas int32 `json:"availableReplicas"`
+}
+
+// StormServiceStatus defines the observed state of StormService
+type StormServiceStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// UpdateRevision is the revision of the role templates the service rolls out.
+	UpdateRevision string `json:"updateRevision,omitempty"`
+	// CurrentRevision is the revision all the roles ran at last, it catches up with UpdateRevision
+	// once an update is rolled out.
+	CurrentRevision string `json:"currentRevision,omitempty"`
+	// Roles are the observed states of the roles.
+	// +listType=map
+	// +listMapKey=name
+	Roles []RoleStatus `json:"roles,omitempty"`
+	// Conditions of the service.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// These are valid conditions of a StormService.
+const (
+	// StormServiceReady means the pods of all the roles are available.
+	StormServiceReady = "Ready"
+	// StormServiceProgressing means an update of the roles is in progress.
+	StormServiceProgressing = "Progressing"
+)
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.currentRevision"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// StormService is the Schema for the stormservices API, it manages the roles of a composite serving unit,
+// e.g. the prefill and decode groups of a disaggregated deployment, as one object.
+type StormService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StormServiceSpec   `json:"spec,omitempty"`
+	Status StormServiceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StormServiceList contains a list of StormService
+type StormServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StormService `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StormService{}, &StormServiceList{})
+}
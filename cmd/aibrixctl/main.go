@@ -0,0 +1,329 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// aibrixctl inspects the live routing state of a gateway plugin through its cache service, e.g.
+//
+//	kubectl -n aibrix-system port-forward svc/aibrix-gateway-plugins 50052:50052
+//	aibrixctl pods -model llama2-7b
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/vllm-project/aibrix/pkg/cacheapi"
+)
+
+const usage = `Usage: aibrixctl [flags] <command> [command flags]
+
+Commands:
+  models         list models and the pods serving them
+  pods           list pods, their readiness and models
+  metrics        show the metrics the gateway has cached per pod
+  prefix-cache   show prefix cache hit rates and blocks per pod
+  decisions      show recent routing decisions
+  explain        show the pod each routing strategy would select for a request, without sending it
+
+Flags:
+`
+
+type command struct {
+	flags *flag.FlagSet
+	run   func(ctx context.Context, client cacheapi.CacheServiceClient, out io.Writer) error
+}
+
+var (
+	addr    = flag.String("addr", defaultAddr(), "address of the gateway plugin gRPC server, defaults to $AIBRIX_GATEWAY_ADDR")
+	timeout = flag.Duration("timeout", 10*time.Second, "timeout of the request")
+	output  = flag.String("o", "table", "output format, table or json")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	commands := newCommands()
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+	_ = cmd.flags.Parse(flag.Args()[1:])
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to %s: %v\n", *addr, err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if err := cmd.run(ctx, cacheapi.NewCacheServiceClient(conn), os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+func defaultAddr() string {
+	if addr := os.Getenv("AIBRIX_GATEWAY_ADDR"); addr != "" {
+		return addr
+	}
+	return "localhost:50052"
+}
+
+func newCommands() map[string]*command {
+	commands := map[string]*command{}
+
+	models := flag.NewFlagSet("models", flag.ExitOnError)
+	commands["models"] = &command{flags: models, run: func(ctx context.Context, client cacheapi.CacheServiceClient, out io.Writer) error {
+		resp, err := client.ListModels(ctx, &cacheapi.ListModelsRequest{})
+		if err != nil {
+			return err
+		}
+		return render(out, resp, func(w *tabwriter.Writer) {
+			fmt.Fprintln(w, "MODEL\tREADY\tENGINE READY\tENGINE\tMAX LEN\tWARM\tPODS")
+			for _, model := range resp.Models {
+				engine := model.Engine
+				if model.EngineVersion != "" {
+					engine += "/" + model.EngineVersion
+				}
+				fmt.Fprintf(w, "%s\t%d/%d\t%d/%d\t%s\t%d\t%d\t%s\n", model.Name, model.ReadyPods, len(model.Pods), model.EngineReadyPods, len(model.Pods), engine, model.MaxModelLen, len(model.WarmNodes), strings.Join(model.Pods, ","))
+			}
+		})
+	}}
+
+	pods := flag.NewFlagSet("pods", flag.ExitOnError)
+	podsModel := pods.String("model", "", "only show pods serving the model")
+	commands["pods"] = &command{flags: pods, run: func(ctx context.Context, client cacheapi.CacheServiceClient, out io.Writer) error {
+		resp, err := client.ListPods(ctx, &cacheapi.ListPodsRequest{Model: *podsModel})
+		if err != nil {
+			return err
+		}
+		return render(out, resp, func(w *tabwriter.Writer) {
+			fmt.Fprintln(w, "NAMESPACE\tPOD\tIP\tZONE\tREADY\tENGINE READY\tDRAINING\tINFLIGHT\tMODELS")
+			for _, pod := range resp.Pods {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%t\t%t\t%d\t%s\n", pod.Namespace, pod.Name, pod.Ip, pod.Zone, pod.Ready, pod.EngineReady, pod.Draining, pod.Inflight, strings.Join(pod.Models, ","))
+			}
+		})
+	}}
+
+	metrics := flag.NewFlagSet("metrics", flag.ExitOnError)
+	metricsModel := metrics.String("model", "", "only show pods serving the model")
+	metricsFilter := metrics.String("metric", "", "comma separated metric names to show, all metrics if empty")
+	commands["metrics"] = &command{flags: metrics, run: func(ctx context.Context, client cacheapi.CacheServiceClient, out io.Writer) error {
+		resp, err := client.ListPods(ctx, &cacheapi.ListPodsRequest{Model: *metricsModel})
+		if err != nil {
+			return err
+		}
+		var names []string
+		if *metricsFilter != "" {
+			names = strings.Split(*metricsFilter, ",")
+		}
+		return render(out, resp, func(w *tabwriter.Writer) {
+			fmt.Fprintln(w, "POD\tMODEL\tMETRIC\tVALUE")
+			for _, pod := range resp.Pods {
+				printMetrics(w, pod.Name, "-", pod.Metrics, names)
+				for _, model := range sortedKeys(pod.ModelMetrics) {
+					if *metricsModel != "" && model != *metricsModel {
+						continue
+					}
+					printMetrics(w, pod.Name, model, pod.ModelMetrics[model].GetValues(), names)
+				}
+			}
+		})
+	}}
+
+	prefixCache := flag.NewFlagSet("prefix-cache", flag.ExitOnError)
+	commands["prefix-cache"] = &command{flags: prefixCache, run: func(ctx context.Context, client cacheapi.CacheServiceClient, out io.Writer) error {
+		resp, err := client.GetPrefixCacheStats(ctx, &cacheapi.GetPrefixCacheStatsRequest{})
+		if err != nil {
+			return err
+		}
+		return render(out, resp, func(w *tabwriter.Writer) {
+			if !resp.Enabled {
+				fmt.Fprintln(w, "prefix cache router is not enabled")
+				return
+			}
+			fmt.Fprintf(w, "LOOKUPS\t%d\n", resp.Lookups)
+			fmt.Fprintf(w, "HIT RATE\t%s\n", percent(resp.Hits, resp.Lookups))
+			fmt.Fprintf(w, "TOKEN MATCH RATE\t%s\n", percent(resp.MatchedTokens, resp.TotalTokens))
+			fmt.Fprintf(w, "BLOCKS\t%d\n", resp.Blocks)
+			fmt.Fprintf(w, "SPECULATIVE\t%d\n\n", resp.Speculative)
+			fmt.Fprintln(w, "MODEL\tPOD\tBLOCKS")
+			for _, model := range sortedKeys(resp.ModelPodBlocks) {
+				blocks := resp.ModelPodBlocks[model].GetBlocks()
+				for _, pod := range sortedKeys(blocks) {
+					fmt.Fprintf(w, "%s\t%s\t%d\n", model, pod, blocks[pod])
+				}
+			}
+		})
+	}}
+
+	decisions := flag.NewFlagSet("decisions", flag.ExitOnError)
+	decisionsModel := decisions.String("model", "", "only show decisions of the model")
+	decisionsLimit := decisions.Int("limit", 20, "maximum number of decisions to show, 0 for the whole history")
+	commands["decisions"] = &command{flags: decisions, run: func(ctx context.Context, client cacheapi.CacheServiceClient, out io.Writer) error {
+		resp, err := client.ListRoutingDecisions(ctx, &cacheapi.ListRoutingDecisionsRequest{Model: *decisionsModel, Limit: int32(*decisionsLimit)})
+		if err != nil {
+			return err
+		}
+		return render(out, resp, func(w *tabwriter.Writer) {
+			fmt.Fprintln(w, "TIME\tREQUEST\tMODEL\tSTRATEGY\tTARGET\tSTATUS\tQUEUE(ms)\tTOTAL(ms)\tCANDIDATES")
+			for _, d := range resp.Decisions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n", d.Timestamp.AsTime().Local().Format(time.RFC3339), d.RequestId, d.Model,
+					orDash(d.RoutingStrategy), orDash(d.TargetPod), d.StatusCode, d.QueueingDelayMs, d.DurationMs, formatCandidates(d.Candidates))
+			}
+		})
+	}}
+
+	explain := flag.NewFlagSet("explain", flag.ExitOnError)
+	explainModel := explain.String("model", "", "model of the request")
+	explainMessage := explain.String("message", "", "prompt of the request, as the routers see it")
+	explainBody := explain.String("body", "", "file with the JSON body of the request, - for stdin, instead of -message")
+	explainEndpoint := explain.String("endpoint", "chat_completions", "endpoint of the body: chat_completions, completions, embeddings or rerank")
+	explainStrategies := explain.String("strategies", "", "comma separated routing strategies to explain, all strategies if empty")
+	explainHeaders := explain.String("headers", "", "comma separated key=value request headers, e.g. routing-strategy=least-request")
+	commands["explain"] = &command{flags: explain, run: func(ctx context.Context, client cacheapi.CacheServiceClient, out io.Writer) error {
+		req := &cacheapi.ExplainRoutingRequest{Model: *explainModel, Message: *explainMessage, Endpoint: *explainEndpoint}
+		if *explainBody != "" {
+			var data []byte
+			var err error
+			if *explainBody == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(*explainBody)
+			}
+			if err != nil {
+				return err
+			}
+			req.Body = &structpb.Struct{}
+			if err := protojson.Unmarshal(data, req.Body); err != nil {
+				return fmt.Errorf("invalid request body: %v", err)
+			}
+			if req.Model == "" {
+				req.Model = req.Body.GetFields()["model"].GetStringValue()
+			}
+		}
+		if *explainStrategies != "" {
+			req.Strategies = strings.Split(*explainStrategies, ",")
+		}
+		if *explainHeaders != "" {
+			req.Headers = map[string]string{}
+			for _, header := range strings.Split(*explainHeaders, ",") {
+				key, value, _ := strings.Cut(header, "=")
+				req.Headers[key] = value
+			}
+		}
+		resp, err := client.ExplainRouting(ctx, req)
+		if err != nil {
+			return err
+		}
+		return render(out, resp, func(w *tabwriter.Writer) {
+			fmt.Fprintf(w, "MODEL\t%s\n", resp.Model)
+			fmt.Fprintf(w, "STRATEGY\t%s\n", orDash(resp.RoutingStrategy))
+			fmt.Fprintf(w, "PODS\t%s\n\n", strings.Join(resp.Pods, ","))
+			fmt.Fprintln(w, "STRATEGY\tTARGET\tADDRESS\tCANDIDATES\tERROR")
+			for _, e := range resp.Explanations {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.RoutingStrategy, orDash(e.TargetPod), orDash(e.TargetAddress), formatCandidates(e.Candidates), e.Error)
+			}
+		})
+	}}
+
+	return commands
+}
+
+// render writes the response as json, or as a table using printTable.
+func render(out io.Writer, resp proto.Message, printTable func(w *tabwriter.Writer)) error {
+	switch *output {
+	case "json":
+		data, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "table":
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		printTable(w)
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+}
+
+func printMetrics(w io.Writer, pod, model string, values map[string]float64, names []string) {
+	if len(names) == 0 {
+		names = sortedKeys(values)
+	}
+	for _, name := range names {
+		if value, ok := values[name]; ok {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%g\n", pod, model, name, value)
+		}
+	}
+}
+
+func formatCandidates(candidates []*cacheapi.CandidateScore) string {
+	formatted := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		formatted = append(formatted, fmt.Sprintf("%s=%.3g", c.Pod, c.Score))
+	}
+	return strings.Join(formatted, " ")
+}
+
+func percent(part, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
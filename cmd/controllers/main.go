@@ -230,8 +230,8 @@ func main() {
 		panic(err)
 	}
 
-	if features.IsControllerEnabled(features.ModelAdapterController) {
-		// cache is enabled for model adapter scheduling.
+	if features.IsControllerEnabled(features.ModelAdapterController) || features.IsControllerEnabled(features.PodAutoscalerController) {
+		// cache is enabled for model adapter scheduling and the latency histograms of SLO autoscaling.
 		cache.NewCache(config, stopCh, nil)
 	}
 
@@ -286,4 +286,14 @@ func setupControllers(mgr ctrl.Manager, runtimeConfig config.RuntimeConfig, cert
 		setupLog.Error(err, "unable to create webhook", "webhook", "Model")
 		os.Exit(1)
 	}
+
+	if err := apiwebhook.SetupPodAutoscalerWebhook(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "PodAutoscaler")
+		os.Exit(1)
+	}
+
+	if err := apiwebhook.SetupRayClusterFleetWebhook(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "RayClusterFleet")
+		os.Exit(1)
+	}
 }
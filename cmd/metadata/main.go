@@ -17,13 +17,23 @@ limitations under the License.
 package main
 
 import (
+	"context"
+
 	"github.com/vllm-project/aibrix/pkg/metadata"
-	"github.com/vllm-project/aibrix/pkg/utils"
+	"github.com/vllm-project/aibrix/pkg/storage"
 	"k8s.io/klog/v2"
 )
 
 func main() {
-	redisClient := utils.GetRedisClient()
+	redisConfig := storage.LoadRedisConfig()
+	redisClient, err := storage.NewRedisClient(redisConfig)
+	if err != nil {
+		klog.Fatalf("Error creating Redis client: %v", err)
+	}
+	if err := redisClient.Ping(context.Background()).Err(); err != nil {
+		klog.Fatalf("Error connecting to Redis %s: %v", redisConfig, err)
+	}
+	klog.Infof("Connected to Redis %s", redisConfig)
 
 	klog.Info("Starting listening on port 8090")
 	srv := metadata.NewHTTPServer(":8090", redisClient)
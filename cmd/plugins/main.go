@@ -17,14 +17,17 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -33,29 +36,52 @@ import (
 
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/cacheapi"
+	"github.com/vllm-project/aibrix/pkg/client/clientset/versioned"
+	aibrixconfig "github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/features"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
 	"github.com/vllm-project/aibrix/pkg/plugins/gateway"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/batch"
+	"github.com/vllm-project/aibrix/pkg/storage"
+	"github.com/vllm-project/aibrix/pkg/tracing"
 	"github.com/vllm-project/aibrix/pkg/utils"
 	healthPb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 var (
-	grpc_port int
+	grpc_port   int
+	health_port int
+	debug_port  int
 )
 
+const preflightRetryInterval = 10 * time.Second
+
 func main() {
 	flag.IntVar(&grpc_port, "port", 50052, "gRPC port")
+	flag.IntVar(&health_port, "health-port", 8080, "HTTP port serving /healthz, the /readyz preflight report, /drain, /workload-profile, the /v1/files and /v1/batches batch API and /metrics")
+	flag.IntVar(&debug_port, "debug-port", 0, "localhost HTTP port serving pprof, goroutine dumps and the cache state under /debug/, 0 disables it")
 	klog.InitFlags(flag.CommandLine)
 	defer klog.Flush()
 	flag.Parse()
 
-	// Connect to Redis
-	redisClient := utils.GetRedisClient()
+	// Connectivity is validated by preflight below.
+	redisConfig := storage.LoadRedisConfig()
+	redisClient, err := storage.NewRedisClient(redisConfig)
+	if err != nil {
+		klog.Fatalf("Error creating redis client: %v", err)
+	}
+	klog.InfoS("using redis", "redis", redisConfig.String())
+	kvStoreBackend := utils.LoadEnv("AIBRIX_KV_STORE", kvstore.BackendRedis)
+	kvStore, err := kvstore.New(kvStoreBackend, redisClient)
+	if err != nil {
+		klog.Fatalf("Error creating kv store: %v", err)
+	}
 
 	fmt.Println("starting cache")
 	stopCh := make(chan struct{})
 	defer close(stopCh)
 	var config *rest.Config
-	var err error
 
 	// ref: https://github.com/kubernetes-sigs/controller-runtime/issues/878#issuecomment-1002204308
 	kubeConfig := flag.Lookup("kubeconfig").Value.String()
@@ -71,14 +97,59 @@ func main() {
 		panic(err)
 	}
 
-	cache.NewCache(config, stopCh, redisClient)
-
 	// Connect to K8s cluster
 	k8sClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		klog.Fatalf("Error creating kubernetes client: %v", err)
 	}
 
+	batchFiles, err := batch.NewFileStore(utils.LoadEnv("AIBRIX_BATCH_FILE_STORE", batch.FileStoreKV), kvStore)
+	if err != nil {
+		klog.Fatalf("Error creating batch file store: %v", err)
+	}
+	batchStore := batch.NewStore(kvStore, batchFiles)
+	batchScheduler := batch.NewScheduler(batchStore, gateway.NewBatchLimiter(redisClient))
+
+	// Validate dependencies before starting informers, the report is served on /readyz meanwhile.
+	preflight := gateway.NewPreflight(k8sClient, redisClient)
+	if kvStoreBackend != kvstore.BackendRedis {
+		preflight.RedisOptional()
+	}
+	healthMux := newHealthMux(preflight, kvStore)
+	go serveHealth(healthMux)
+	for {
+		report := preflight.Run(context.Background())
+		gateway.LogReport(report)
+		if report.Ready {
+			break
+		}
+		klog.Errorf("preflight failed on %s, retrying in %v", report.FailedChecks(), preflightRetryInterval)
+		time.Sleep(preflightRetryInterval)
+	}
+
+	// the batch API is served once the api keys are loaded, they are shared with the gateway.
+	authenticator := gateway.LoadAuthenticator(redisClient, k8sClient)
+	batchHandler := batch.Handler(batchStore, batchScheduler, authenticator)
+	for _, pattern := range []string{"/v1/files", "/v1/files/", "/v1/batches", "/v1/batches/"} {
+		healthMux.Handle(pattern, batchHandler)
+	}
+
+	c := cache.NewCache(config, stopCh, kvStore)
+	batchCtx, stopBatches := context.WithCancel(context.Background())
+	defer stopBatches()
+	go batchScheduler.Run(batchCtx, c)
+	tracing.Init("aibrix-gateway-plugins", stopCh)
+
+	features.WatchFlags(k8sClient, utils.LoadEnv("POD_NAMESPACE", "aibrix-system"),
+		utils.LoadEnv("AIBRIX_FEATURE_FLAGS_CONFIGMAP", "aibrix-feature-flags"), stopCh)
+	aibrixconfig.WatchGatewayConfig(k8sClient, utils.LoadEnv("POD_NAMESPACE", "aibrix-system"),
+		utils.LoadEnv("AIBRIX_GATEWAY_CONFIGMAP", "aibrix-gateway-config"), gateway.ValidateGatewayConfig, stopCh)
+
+	aibrixClient, err := versioned.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("Error creating aibrix client: %v", err)
+	}
+
 	// grpc server init
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", grpc_port))
 	if err != nil {
@@ -87,8 +158,14 @@ func main() {
 
 	s := grpc.NewServer()
 
-	extProcPb.RegisterExternalProcessorServer(s, gateway.NewServer(redisClient, k8sClient))
+	gatewayServer := gateway.NewServer(redisClient, k8sClient, aibrixClient, authenticator)
+	gatewayServer.WatchModelConfigs(stopCh)
+	extProcPb.RegisterExternalProcessorServer(s, gatewayServer)
+	cacheapi.RegisterCacheServiceServer(s, gateway.NewCacheService(gatewayServer))
 	healthPb.RegisterHealthServer(s, &gateway.HealthServer{})
+	if debug_port > 0 {
+		go serveDebug(gatewayServer)
+	}
 
 	klog.Info("starting gRPC server on port :50052")
 
@@ -104,7 +181,37 @@ func main() {
 		os.Exit(0)
 	}()
 
-	if err := s.Serve(lis); err != nil {
+	// the gateway turns ready once the cache is synced, by cache.NewCache above, and the ext_proc server is serving.
+	if err := preflight.ServeGRPC(s, lis); err != nil {
 		panic(err)
 	}
 }
+
+// newHealthMux creates the mux of the health port, the batch API is added to it once the gateway is ready.
+func newHealthMux(preflight *gateway.Preflight, kvStore kvstore.Store) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/readyz", preflight)
+	mux.Handle("/drain", gateway.DrainHandler())
+	mux.Handle("/metrics/push", gateway.MetricPushHandler())
+	mux.Handle("/workload-profile", gateway.WorkloadProfileHandler(kvStore))
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
+
+func serveHealth(mux *http.ServeMux) {
+	klog.Infof("starting health server on port :%d", health_port)
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", health_port), mux); err != nil {
+		klog.Fatalf("failed to start health server: %v", err)
+	}
+}
+
+// serveDebug serves the diagnostics of the gateway on localhost only, to be reached through kubectl port-forward.
+func serveDebug(gatewayServer *gateway.Server) {
+	klog.Infof("starting debug server on 127.0.0.1:%d", debug_port)
+	if err := http.ListenAndServe(fmt.Sprintf("127.0.0.1:%d", debug_port), gateway.DebugHandler(gatewayServer)); err != nil {
+		klog.Errorf("failed to start debug server: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// profiler measures the tokens per second and max concurrency of a model served on a GPU type and stores the
+// capacity profile in the kv store of the gateway, keyed by model and GPU type, e.g.
+//
+//	profiler -endpoint http://10.0.0.1:8000 -model llama2-7b -gpu A10 -tpot-p90 0.05
+//
+// It runs as the Kubernetes Job of samples/profiling against a pod of the model, the kv store is configured by the
+// same environment variables as the gateway.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/profiling"
+	"github.com/vllm-project/aibrix/pkg/storage"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+func main() {
+	runner := profiling.Runner{}
+	var (
+		gpu           = flag.String("gpu", "", "GPU type of the pod, e.g. A10")
+		concurrencies = flag.String("concurrencies", "1,2,4,8,16,32,64,128,256", "comma separated concurrencies to measure, in order")
+		timeout       = flag.Duration("request-timeout", 5*time.Minute, "timeout of a request")
+		dryRun        = flag.Bool("dry-run", false, "print the capacity profile without storing it")
+	)
+	flag.StringVar(&runner.Endpoint, "endpoint", "", "base URL of the OpenAI compatible engine, e.g. http://10.0.0.1:8000")
+	flag.StringVar(&runner.Model, "model", "", "model to profile")
+	flag.StringVar(&runner.APIKey, "api-key", os.Getenv("LLM_API_KEY"), "API key of the engine, LLM_API_KEY by default")
+	flag.IntVar(&runner.InputTokens, "input-tokens", 512, "approximate input tokens of a request")
+	flag.IntVar(&runner.OutputTokens, "output-tokens", 128, "output tokens of a request")
+	flag.IntVar(&runner.RequestsPerLevel, "requests", 64, "requests per concurrency, at least the concurrency")
+	flag.Float64Var(&runner.SLO.LatencyP90, "latency-p90", 0, "SLO of the P90 end to end latency in seconds, 0 to not check it")
+	flag.Float64Var(&runner.SLO.TimePerOutputTokenP90, "tpot-p90", 0, "SLO of the P90 latency per output token in seconds, 0 to not check it")
+	klog.InitFlags(flag.CommandLine)
+	defer klog.Flush()
+	flag.Parse()
+
+	if runner.Endpoint == "" || runner.Model == "" || *gpu == "" {
+		fmt.Fprintln(os.Stderr, "-endpoint, -model and -gpu are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	for _, value := range strings.Split(*concurrencies, ",") {
+		concurrency, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || concurrency <= 0 {
+			exit("invalid concurrency %q", value)
+		}
+		runner.Concurrencies = append(runner.Concurrencies, concurrency)
+	}
+	runner.Client = &http.Client{Timeout: *timeout}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	levels, err := runner.Run(ctx)
+	if err != nil {
+		exit("failed to profile %s: %v", runner.Model, err)
+	}
+	profile := profiling.Collect(runner.Model, *gpu, runner.InputTokens, runner.OutputTokens, runner.SLO, levels)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(profile); err != nil {
+		exit("%v", err)
+	}
+	if *dryRun {
+		return
+	}
+
+	redisClient, err := storage.NewRedisClient(storage.LoadRedisConfig())
+	if err != nil {
+		exit("failed to create redis client: %v", err)
+	}
+	store, err := kvstore.New(utils.LoadEnv("AIBRIX_KV_STORE", kvstore.BackendRedis), redisClient)
+	if err != nil {
+		exit("failed to create kv store: %v", err)
+	}
+	defer store.Close()
+	if err := profiling.Save(ctx, store, profile); err != nil {
+		exit("failed to store the capacity profile: %v", err)
+	}
+	klog.InfoS("Stored capacity profile", "key", profiling.Key(profile.Model, profile.GPU),
+		"maxConcurrency", profile.MaxConcurrency, "tokensPerSecond", profile.TokensPerSecond)
+}
+
+func exit(format string, args ...interface{}) {
+	klog.Flush()
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
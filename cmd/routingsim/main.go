@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// routingsim replays a request trace against routing algorithms on a simulated fleet and compares their latency,
+// load balance and prefix cache hits, e.g.
+//
+//	routingsim -trace trace.jsonl -model llama2-7b -pods 8 -routers random,least-request,prefix-cache
+//
+// The trace is either a file with one window per line, {"timestamp": <unix seconds>, "trace": <trace>}, or read from
+// the Redis the gateway writes request traces to with -redis.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/redis/go-redis/v9"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/simulation"
+)
+
+func main() {
+	var (
+		traceFile = flag.String("trace", "", "file of trace windows, one JSON object per line")
+		redisAddr = flag.String("redis", "", "address of the Redis to read the traces of the model from, instead of -trace")
+		model     = flag.String("model", "", "model of the trace")
+		routers   = flag.String("routers", "random,least-request,throughput,prefix-cache,least-kv-cache,least-latency", "comma separated routing strategies to compare")
+		profile   = flag.String("profile", "", "JSON file of the pod profile, defaults to a 7B model on one A10 GPU")
+		output    = flag.String("o", "table", "output format, table or json")
+	)
+	config := simulation.DefaultConfig("", 4)
+	flag.IntVar(&config.Pods, "pods", config.Pods, "number of simulated pods")
+	flag.Float64Var(&config.RateScale, "rate-scale", config.RateScale, "multiplies the request rate of the trace")
+	flag.IntVar(&config.PrefixGroups, "prefix-groups", config.PrefixGroups, "number of distinct shared prompt prefixes")
+	flag.Float64Var(&config.SharedPrefixRatio, "shared-prefix-ratio", config.SharedPrefixRatio, "fraction of the prompt made of the shared prefix")
+	flag.Int64Var(&config.Seed, "seed", config.Seed, "seed of the synthesized requests")
+	flag.Parse()
+
+	// routers log their env configuration and every decision, which would bury the results.
+	klog.LogToStderr(false)
+	klog.SetOutput(io.Discard)
+
+	if *model == "" || (*traceFile == "") == (*redisAddr == "") {
+		fmt.Fprintln(os.Stderr, "-model and one of -trace or -redis are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	config.Model = *model
+	if *profile != "" {
+		data, err := os.ReadFile(*profile)
+		if err == nil {
+			err = json.Unmarshal(data, &config.Profile)
+		}
+		if err != nil {
+			exit("failed to read the pod profile: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	var windows []simulation.TraceWindow
+	var err error
+	if *traceFile != "" {
+		windows, err = simulation.LoadTraceFile(*traceFile)
+	} else {
+		store := kvstore.NewRedisStore(redis.NewClient(&redis.Options{Addr: *redisAddr}))
+		defer store.Close()
+		windows, err = simulation.LoadStoreTraces(ctx, store, *model)
+	}
+	if err != nil {
+		exit("failed to load the trace: %v", err)
+	}
+
+	cache.NewOfflineCache()
+	results := map[string]simulation.Result{}
+	for _, name := range strings.Split(*routers, ",") {
+		router, err := gateway.NewRouter(strings.TrimSpace(name))
+		if err != nil {
+			exit("%v", err)
+		}
+		result, err := simulation.Run(ctx, router, windows, config)
+		if err != nil {
+			exit("%s: %v", name, err)
+		}
+		results[strings.TrimSpace(name)] = result
+	}
+
+	if *output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			exit("%v", err)
+		}
+		return
+	}
+	printTable(os.Stdout, results)
+}
+
+func printTable(out io.Writer, results map[string]simulation.Result) {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROUTER\tREQUESTS\tFAILED\tP50\tP99\tTTFT P50\tTTFT P99\tQUEUE\tIMBALANCE\tPREFIX HIT")
+	for _, name := range names {
+		r := results[name]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2fs\t%.2fs\t%.2fs\t%.2fs\t%.2fs\t%.2f\t%.1f%%\n", name, r.Requests, r.Failed,
+			r.LatencyP50, r.LatencyP99, r.TTFTP50, r.TTFTP99, r.MeanQueueTime, r.LoadImbalance, r.PrefixHitRate*100)
+	}
+	w.Flush()
+}
+
+func exit(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PodBatchLoanAnnotation is set by the PodAutoscaler on the idle pods of an interactive model it lends to batch
+	// workloads, BatchLoanLent while batches run on the pod and BatchLoanReclaiming while their requests complete
+	// before the pod serves interactive requests again.
+	PodBatchLoanAnnotation = "autoscaling.aibrix.ai/batch-loan"
+	BatchLoanLent          = "lent"
+	BatchLoanReclaiming    = "reclaiming"
+)
+
+// isLent returns true if the pod is lent to batch workloads or being reclaimed, it serves no interactive requests.
+func isLent(pod *v1.Pod) bool {
+	_, ok := pod.Annotations[PodBatchLoanAnnotation]
+	return ok
+}
+
+// GetLentPodsForModel returns the pods of the model lent to batch workloads that are Ready, not draining and whose
+// engine is healthy. Pods being reclaimed are excluded.
+func (c *Cache) GetLentPodsForModel(modelName string) map[string]*v1.Pod {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	lentPods := map[string]*v1.Pod{}
+	for name, pod := range c.modelToPodMapping[modelName] {
+		if pod.Annotations[PodBatchLoanAnnotation] == BatchLoanLent && c.isEngineReadyLocked(pod) {
+			lentPods[name] = pod
+		}
+	}
+	return lentPods
+}
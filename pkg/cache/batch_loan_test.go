@@ -0,0 +1,52 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("BatchLoan", func() {
+	It("should route interactive requests to the pods not lent to batch workloads", func() {
+		shared := newHealthTestPod("p1", true)
+		lent := newHealthTestPod("p2", true)
+		lent.Annotations = map[string]string{PodBatchLoanAnnotation: BatchLoanLent}
+		reclaiming := newHealthTestPod("p3", true)
+		reclaiming.Annotations = map[string]string{PodBatchLoanAnnotation: BatchLoanReclaiming}
+		pods := map[string]*v1.Pod{"p1": shared, "p2": lent, "p3": reclaiming}
+		cache := &Cache{
+			pods:              pods,
+			modelToPodMapping: map[string]map[string]*v1.Pod{"llama-7b": pods},
+			engineHealth:      map[string]*engineHealth{},
+		}
+		for name := range pods {
+			cache.recordScrapeLocked(name, nil)
+		}
+
+		readyPods, err := cache.GetReadyPodsForModel("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readyPods).To(HaveLen(1))
+		Expect(readyPods).To(HaveKey("p1"))
+
+		lentPods := cache.GetLentPodsForModel("llama-7b")
+		Expect(lentPods).To(HaveLen(1))
+		Expect(lentPods).To(HaveKey("p2"))
+		Expect(cache.GetLentPodsForModel("unknown")).To(BeEmpty())
+	})
+})
@@ -21,13 +21,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	crdinformers "github.com/vllm-project/aibrix/pkg/client/informers/externalversions"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
@@ -38,10 +38,13 @@ import (
 	"k8s.io/klog/v2"
 
 	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
 	v1alpha1 "github.com/vllm-project/aibrix/pkg/client/clientset/versioned"
 	v1alpha1scheme "github.com/vllm-project/aibrix/pkg/client/clientset/versioned/scheme"
+	aibrixconfig "github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
 	"github.com/vllm-project/aibrix/pkg/metrics"
 	"github.com/vllm-project/aibrix/pkg/utils"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -51,21 +54,44 @@ var once sync.Once
 
 // type global
 type Cache struct {
-	mu                sync.RWMutex
-	redisClient       *redis.Client
-	prometheusApi     prometheusv1.API
-	initialized       bool
-	subscribers       []metrics.MetricSubscriber
-	metrics           map[string]interface{}
-	ModelMetrics      map[string]map[string]interface{}
-	Pods              map[string]*v1.Pod
-	PodMetrics        map[string]map[string]metrics.MetricValue            // pod_name: map[metric_name]metric_val
-	PodModelMetrics   map[string]map[string]map[string]metrics.MetricValue // pod_name: map[model_name]map[metric_name]metric_val
-	PodToModelMapping map[string]map[string]struct{}                       // pod_name: map[model_name]struct{}
-	ModelToPodMapping map[string]map[string]*v1.Pod                        // model_name: map[pod_name]*v1.Pod
-	requestTrace      *sync.Map                                            // model_name: RequestTrace
-	numRequestsTraces int32                                                // counter for requestTrace
-	pendingRequests   *sync.Map                                            // model_name: *int32
+	mu                 timedRWMutex
+	kvStore            kvstore.Store
+	traceWriter        *traceWriter
+	kubeClient         kubernetes.Interface
+	prometheusApi      prometheusv1.API
+	initialized        bool
+	subscribers        []metrics.MetricSubscriber
+	metrics            map[string]interface{}
+	modelMetrics       map[string]map[string]interface{}
+	pods               map[string]*v1.Pod
+	podMetrics         podMetricStore                         // pod_name: *podMetricRecord
+	podToModelMapping  map[string]map[string]struct{}         // pod_name: map[model_name]struct{}
+	modelToPodMapping  map[string]map[string]*v1.Pod          // model_name: map[pod_name]*v1.Pod
+	modelAdapters      map[string]*modelv1alpha1.ModelAdapter // model_name: *ModelAdapter
+	requestTrace       *sync.Map                              // model_name: RequestTrace
+	numRequestsTraces  int32                                  // counter for requestTrace
+	pendingRequests    *sync.Map                              // model_name: *int32
+	engineHealth       map[string]*engineHealth               // pod_name: *engineHealth
+	scrapeActivity     map[string]*scrapeActivity             // pod_name: *scrapeActivity
+	metricPushes       map[string]map[pushedMetric]time.Time  // pod_name: map[pushedMetric]push_time
+	watchMu            sync.Mutex                             // guards metricWatches, taken after mu
+	metricWatches      map[string][]*metricWatch              // pod_name: []*metricWatch
+	drainingPods       map[string]*podDrain                   // pod_name: *podDrain
+	podRequests        sync.Map                               // pod_name: *int32
+	podBatchItems      sync.Map                               // pod_name: *int32
+	remoteLoads        map[string]*remoteLoad                 // replica: *remoteLoad
+	federation         map[string]ClusterSummary              // cluster_name: ClusterSummary
+	nodeTopology       map[string]Topology                    // node_name: Topology
+	kvTransferSamples  map[string]map[string]kvTransferSample // pod_name: map[model_name]kvTransferSample
+	engineModelInfo    map[string]*engineModelInfo            // pod_name: *engineModelInfo
+	startingPods       map[string]struct{}                    // pod_name: struct{}
+	warmNodes          map[string]map[string]string           // model_name: map[node_name]prewarm_pod_name
+	ownershipProviders []PodOwnershipProvider
+	checkpointers      map[string]Checkpointer // name: Checkpointer
+	restoredCheckpoint *Checkpoint
+	leaderElection     bool
+	leading            atomic.Bool
+	federationExpires  time.Time
 }
 
 type Block struct {
@@ -74,10 +100,11 @@ type Block struct {
 }
 
 const (
-	modelIdentifier                       = "model.aibrix.ai/name"
-	podPort                               = 8000
-	defaultPodMetricRefreshIntervalInMS   = 50
-	expireWriteRequestTraceIntervalInMins = 10
+	modelIdentifier                     = "model.aibrix.ai/name"
+	podPort                             = 8000
+	defaultPodMetricRefreshIntervalInMS = 50
+	defaultPodMetricScrapeTimeoutInMS   = 1000
+	defaultPodMetricScrapeMaxIdleConns  = 1024
 )
 
 var (
@@ -90,6 +117,13 @@ var (
 		metrics.AvgGenerationThroughputToksPerS,
 		metrics.GPUCacheUsagePerc,
 		metrics.CPUCacheUsagePerc,
+		metrics.KVOffloadL1QueryTokens,
+		metrics.KVOffloadL1HitTokens,
+		metrics.KVOffloadL2QueryTokens,
+		metrics.KVOffloadL2HitTokens,
+		metrics.KVOffloadL1Evictions,
+		metrics.KVOffloadL2Evictions,
+		metrics.KVOffloadTransferBytes,
 	}
 	// histogram metric example - time_to_first_token_seconds, _sum, _bucket _count.
 	histogramMetricNames = []string{
@@ -125,8 +159,46 @@ var (
 
 	// TODO: add a helper function for get methods.
 	podMetricRefreshInterval = getPodMetricRefreshInterval()
+
+	// podMetricScrapeClient is shared by the scrapes of all the engines, to reuse their connections.
+	podMetricScrapeClient = metrics.NewScrapeClient(getPodMetricScrapeTimeout(), getPodMetricScrapeMaxIdleConns())
+
+	// fetchEngineMetrics scrapes the metrics endpoint of an engine.
+	fetchEngineMetrics = func(url string) (map[string]*dto.MetricFamily, error) {
+		return metrics.ParseMetricsURLWithClient(podMetricScrapeClient, url)
+	}
 )
 
+func getPodMetricScrapeTimeout() time.Duration {
+	value := utils.LoadEnv("AIBRIX_POD_METRIC_SCRAPE_TIMEOUT_MS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_POD_METRIC_SCRAPE_TIMEOUT_MS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_POD_METRIC_SCRAPE_TIMEOUT_MS env value for pod metrics scrape timeout: %d ms", intValue)
+			return time.Duration(intValue) * time.Millisecond
+		}
+	}
+	return defaultPodMetricScrapeTimeoutInMS * time.Millisecond
+}
+
+// getPodMetricScrapeMaxIdleConns returns the number of idle connections kept across the engines, it should be at least
+// the number of engine pods for every scrape to reuse a connection.
+func getPodMetricScrapeMaxIdleConns() int {
+	value := utils.LoadEnv("AIBRIX_POD_METRIC_SCRAPE_MAX_IDLE_CONNS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_POD_METRIC_SCRAPE_MAX_IDLE_CONNS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_POD_METRIC_SCRAPE_MAX_IDLE_CONNS env value for pod metrics scrape idle connections: %d", intValue)
+			return intValue
+		}
+	}
+	return defaultPodMetricScrapeMaxIdleConns
+}
+
 func getPodMetricRefreshInterval() time.Duration {
 	value := utils.LoadEnv("AIBRIX_POD_METRIC_REFRESH_INTERVAL_MS", "")
 	if value != "" {
@@ -149,7 +221,7 @@ func GetCache() (*Cache, error) {
 	return &instance, nil
 }
 
-func NewCache(config *rest.Config, stopCh <-chan struct{}, redisClient *redis.Client) *Cache {
+func NewCache(config *rest.Config, stopCh <-chan struct{}, kvStore kvstore.Store) *Cache {
 	once.Do(func() {
 		if err := v1alpha1scheme.AddToScheme(scheme.Scheme); err != nil {
 			panic(err)
@@ -165,11 +237,15 @@ func NewCache(config *rest.Config, stopCh <-chan struct{}, redisClient *redis.Cl
 			panic(err)
 		}
 
-		factory := informers.NewSharedInformerFactoryWithOptions(k8sClientSet, 0)
-		crdFactory := crdinformers.NewSharedInformerFactoryWithOptions(crdClientSet, 0)
+		factory := informers.NewSharedInformerFactoryWithOptions(k8sClientSet, informerResyncPeriod)
+		crdFactory := crdinformers.NewSharedInformerFactoryWithOptions(crdClientSet, informerResyncPeriod)
 
 		podInformer := factory.Core().V1().Pods().Informer()
+		nodeInformer := factory.Core().V1().Nodes().Informer()
 		modelInformer := crdFactory.Model().V1alpha1().ModelAdapters().Informer()
+		setupInformer(podInformer, "pods", transformPod)
+		setupInformer(nodeInformer, "nodes", transformNode)
+		setupInformer(modelInformer, "modeladapters", transformModelAdapter)
 
 		defer runtime.HandleCrash()
 		factory.Start(stopCh)
@@ -199,15 +275,25 @@ func NewCache(config *rest.Config, stopCh <-chan struct{}, redisClient *redis.Cl
 
 		instance = Cache{
 			initialized:       true,
-			redisClient:       redisClient,
+			kvStore:           kvStore,
+			kubeClient:        k8sClientSet,
 			prometheusApi:     prometheusApi,
-			Pods:              map[string]*v1.Pod{},
-			PodMetrics:        map[string]map[string]metrics.MetricValue{},
-			PodModelMetrics:   map[string]map[string]map[string]metrics.MetricValue{},
-			PodToModelMapping: map[string]map[string]struct{}{},
-			ModelToPodMapping: map[string]map[string]*v1.Pod{},
+			pods:              map[string]*v1.Pod{},
+			podToModelMapping: map[string]map[string]struct{}{},
+			modelToPodMapping: map[string]map[string]*v1.Pod{},
+			modelAdapters:     map[string]*modelv1alpha1.ModelAdapter{},
 			requestTrace:      &sync.Map{},
 			pendingRequests:   &sync.Map{},
+			engineHealth:      map[string]*engineHealth{},
+			drainingPods:      map[string]*podDrain{},
+			nodeTopology:      map[string]Topology{},
+		}
+		if kvStore != nil {
+			instance.traceWriter = newTraceWriter(kvStore)
+		}
+		if leaderElectionEnabled {
+			instance.leaderElection = true
+			go instance.runLeaderElection(k8sClientSet, stopCh)
 		}
 		if _, err := podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 			AddFunc:    instance.addPod,
@@ -217,6 +303,15 @@ func NewCache(config *rest.Config, stopCh <-chan struct{}, redisClient *redis.Cl
 			panic(err)
 		}
 
+		// node topology is best effort and not waited for, routing falls back to all zones until nodes are known.
+		if _, err = nodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    instance.addNode,
+			UpdateFunc: instance.updateNode,
+			DeleteFunc: instance.deleteNode,
+		}); err != nil {
+			panic(err)
+		}
+
 		if _, err = modelInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 			AddFunc:    instance.addModelAdapter,
 			UpdateFunc: instance.updateModelAdapter,
@@ -225,6 +320,19 @@ func NewCache(config *rest.Config, stopCh <-chan struct{}, redisClient *redis.Cl
 			panic(err)
 		}
 
+		prometheus.MustRegister(&kvCacheEfficiencyCollector{cache: &instance})
+		prometheus.MustRegister(&cacheCollector{cache: &instance})
+
+		if kvStore != nil && checkpointInterval > 0 {
+			result := instance.restoreCheckpoint(kvStore, podNames(podInformer.GetStore().ListKeys()), time.Now())
+			checkpointRestores.WithLabelValues(result).Inc()
+			go instance.runCheckpoints(kvStore, stopCh)
+		}
+
+		if reconcileInterval > 0 {
+			go instance.runReconciliation(podInformer.GetStore(), modelInformer.GetStore(), stopCh)
+		}
+
 		ticker := time.NewTicker(podMetricRefreshInterval)
 		go func() {
 			for {
@@ -240,43 +348,81 @@ func NewCache(config *rest.Config, stopCh <-chan struct{}, redisClient *redis.Cl
 			}
 		}()
 
-		tickerOffset := time.Duration(time.Now().UnixNano()) % RequestTraceWriteInterval
-		var traceAlignmentTimer *time.Timer
-		// TODO: Using ticker may be a problem if writeRequestTraceToStorage takes too long.
-		var traceTicker *time.Ticker
-		// To limit the offset of each tick, we align the ticker by waiting for a round
-		// Very small offset usually will not be a problem, because it take some time to write to the Redis.
-		if tickerOffset > MaxRequestTraceIntervalOffset {
-			traceAlignmentTimer = time.NewTimer(RequestTraceWriteInterval - tickerOffset)
-		} else {
-			traceTicker = time.NewTicker(RequestTraceWriteInterval)
+		if engineHealthProbeInterval > 0 {
+			healthTicker := time.NewTicker(engineHealthProbeInterval)
+			go func() {
+				for {
+					select {
+					case <-healthTicker.C:
+						instance.probeEngineHealth()
+					case <-stopCh:
+						healthTicker.Stop()
+						return
+					}
+				}
+			}()
 		}
-		go func() {
-			if redisClient == nil {
-				return
-			}
-			if traceAlignmentTimer != nil {
-				// Wait for alignment
-				<-traceAlignmentTimer.C
-				traceAlignmentTimer = nil
 
-				// TODO: Clean up data if necessary.
+		if modelInfoRefreshInterval > 0 {
+			modelInfoTicker := time.NewTicker(modelInfoRefreshInterval)
+			go func() {
+				for {
+					select {
+					case <-modelInfoTicker.C:
+						instance.refreshModelInfo()
+					case <-stopCh:
+						modelInfoTicker.Stop()
+						return
+					}
+				}
+			}()
+		}
+
+		if podDeletionCostEnabled {
+			deletionCostTicker := time.NewTicker(podDeletionCostRefreshInterval)
+			go func() {
+				for {
+					select {
+					case <-deletionCostTicker.C:
+						instance.updatePodDeletionCost()
+					case <-stopCh:
+						deletionCostTicker.Stop()
+						return
+					}
+				}
+			}()
+		}
 
-				// Start ticker
-				traceTicker = time.NewTicker(RequestTraceWriteInterval)
+		if kvStore != nil && loadReportInterval > 0 {
+			go instance.runLoadReports(kvStore, stopCh)
+		}
+		if kvStore != nil && metricPushEnabled {
+			go instance.receiveMetricPushes(kvStore, stopCh)
+		}
+		if kvStore != nil && federationCluster != "" {
+			go instance.runFederation(kvStore, stopCh)
+		}
+
+		go func() {
+			if kvStore == nil {
+				return
 			}
-			klog.Infof("trace ticker start at %s", time.Now())
+			go instance.traceWriter.run(stopCh)
+			klog.Infof("trace writer start at %s", time.Now())
 			for {
+				// the interval may be reloaded from the gateway ConfigMap, so each window is aligned on its own.
+				traceConfig := aibrixconfig.RequestTrace()
+				traceTimer := time.NewTimer(traceConfig.Interval - time.Duration(time.Now().UnixNano())%traceConfig.Interval)
 				select {
-				case <-traceTicker.C:
+				case <-traceTimer.C:
 					if atomic.LoadInt32(&instance.numRequestsTraces) == 0 {
 						continue
 					}
 					t := time.Now().Unix()
-					roundT := t - t%int64(RequestTraceWriteInterval/time.Second)
-					instance.writeRequestTraceToStorage(roundT)
+					roundT := t - t%int64(traceConfig.Interval/time.Second)
+					instance.writeRequestTraceToStorage(roundT, traceConfig)
 				case <-stopCh:
-					traceTicker.Stop()
+					traceTimer.Stop()
 					return
 				}
 			}
@@ -290,15 +436,23 @@ func (c *Cache) addPod(obj interface{}) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	pod := obj.(*v1.Pod)
+	c.addPodLocked(obj.(*v1.Pod))
+}
+
+func (c *Cache) addPodLocked(pod *v1.Pod) {
+	if c.updateWarmNodesLocked(pod, false) {
+		return
+	}
 	// only track pods with model deployments
 	modelName, ok := pod.Labels[modelIdentifier]
 	if !ok {
 		return
 	}
 
-	c.Pods[pod.Name] = pod
+	c.pods[pod.Name] = pod
 	c.addPodAndModelMappingLocked(pod.Name, modelName)
+	c.markDrainingLocked(pod)
+	c.trackColdStartLocked(pod)
 	klog.V(4).Infof("POD CREATED: %s/%s", pod.Namespace, pod.Name)
 	c.debugInfoLocked()
 }
@@ -309,6 +463,9 @@ func (c *Cache) updatePod(oldObj interface{}, newObj interface{}) {
 
 	oldPod := oldObj.(*v1.Pod)
 	newPod := newObj.(*v1.Pod)
+	if c.updateWarmNodesLocked(newPod, false) {
+		return
+	}
 
 	oldModelName, oldOk := oldPod.Labels[modelIdentifier]
 	newModelName, newOk := newPod.Labels[modelIdentifier]
@@ -319,14 +476,31 @@ func (c *Cache) updatePod(oldObj interface{}, newObj interface{}) {
 
 	// Remove old mappings if present
 	if oldOk {
-		delete(c.Pods, oldPod.Name)
+		delete(c.pods, oldPod.Name)
 		c.deletePodAndModelMapping(oldPod.Name, oldModelName)
 	}
 
 	// Add new mappings if present
 	if newOk {
-		c.Pods[newPod.Name] = newPod
+		c.pods[newPod.Name] = newPod
 		c.addPodAndModelMappingLocked(newPod.Name, newModelName)
+		// the lora adapters on the pod must be routed with its new state as well.
+		for modelName := range c.podToModelMapping[newPod.Name] {
+			if pods, ok := c.modelToPodMapping[modelName]; ok {
+				pods[newPod.Name] = newPod
+			}
+		}
+	}
+
+	// the engine of a pod becoming ready again, e.g. after a container restart, must be observed serving again.
+	if !utils.IsPodReady(oldPod) && utils.IsPodReady(newPod) {
+		delete(c.engineHealth, newPod.Name)
+		delete(c.scrapeActivity, newPod.Name)
+		delete(c.engineModelInfo, newPod.Name)
+	}
+	if newOk {
+		c.markDrainingLocked(newPod)
+		c.observeColdStartLocked(newPod, time.Now())
 	}
 
 	klog.V(4).Infof("POD UPDATED: %s/%s %s", newPod.Namespace, newPod.Name, newPod.Status.Phase)
@@ -337,21 +511,42 @@ func (c *Cache) deletePod(obj interface{}) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	pod := obj.(*v1.Pod)
+	// the final state of a pod deleted while the watch was down is only known from the store of the informer.
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		klog.Errorf("unexpected object deleted from the pod informer: %T", obj)
+		return
+	}
+	c.deletePodLocked(pod)
+}
+
+func (c *Cache) deletePodLocked(pod *v1.Pod) {
+	if c.updateWarmNodesLocked(pod, true) {
+		return
+	}
 	_, ok := pod.Labels[modelIdentifier]
 	if !ok {
 		return
 	}
 
 	// delete base model and associated lora models on this pod
-	if models, ok := c.PodToModelMapping[pod.Name]; ok {
+	if models, ok := c.podToModelMapping[pod.Name]; ok {
 		for modelName := range models {
 			c.deletePodAndModelMapping(pod.Name, modelName)
 		}
 	}
-	delete(c.Pods, pod.Name)
-	delete(c.PodMetrics, pod.Name)
-	delete(c.PodModelMetrics, pod.Name)
+	delete(c.pods, pod.Name)
+	c.podMetrics.delete(pod.Name)
+	delete(c.engineHealth, pod.Name)
+	delete(c.scrapeActivity, pod.Name)
+	delete(c.metricPushes, pod.Name)
+	delete(c.kvTransferSamples, pod.Name)
+	delete(c.engineModelInfo, pod.Name)
+	delete(c.startingPods, pod.Name)
+	c.forgetDrainLocked(pod.Name)
 
 	klog.V(4).Infof("POD DELETED: %s/%s", pod.Namespace, pod.Name)
 	c.debugInfoLocked()
@@ -361,7 +556,11 @@ func (c *Cache) addModelAdapter(obj interface{}) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	model := obj.(*modelv1alpha1.ModelAdapter)
+	c.addModelAdapterLocked(obj.(*modelv1alpha1.ModelAdapter))
+}
+
+func (c *Cache) addModelAdapterLocked(model *modelv1alpha1.ModelAdapter) {
+	c.modelAdapters[model.Name] = model
 	for _, pod := range model.Status.Instances {
 		c.addPodAndModelMappingLocked(pod, model.Name)
 	}
@@ -381,6 +580,8 @@ func (c *Cache) updateModelAdapter(oldObj interface{}, newObj interface{}) {
 		c.deletePodAndModelMapping(pod, oldModel.Name)
 	}
 
+	delete(c.modelAdapters, oldModel.Name)
+	c.modelAdapters[newModel.Name] = newModel
 	for _, pod := range newModel.Status.Instances {
 		c.addPodAndModelMappingLocked(pod, newModel.Name)
 	}
@@ -393,7 +594,19 @@ func (c *Cache) deleteModelAdapter(obj interface{}) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	model := obj.(*modelv1alpha1.ModelAdapter)
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	model, ok := obj.(*modelv1alpha1.ModelAdapter)
+	if !ok {
+		klog.Errorf("unexpected object deleted from the model adapter informer: %T", obj)
+		return
+	}
+	c.deleteModelAdapterLocked(model)
+}
+
+func (c *Cache) deleteModelAdapterLocked(model *modelv1alpha1.ModelAdapter) {
+	delete(c.modelAdapters, model.Name)
 	for _, pod := range model.Status.Instances {
 		c.deletePodAndModelMapping(pod, model.Name)
 	}
@@ -403,49 +616,49 @@ func (c *Cache) deleteModelAdapter(obj interface{}) {
 }
 
 func (c *Cache) addPodAndModelMappingLocked(podName, modelName string) {
-	pod, ok := c.Pods[podName]
+	pod, ok := c.pods[podName]
 	if !ok {
 		klog.Errorf("pod %s does not exist in internal-cache", podName)
 		return
 	}
 
-	models, ok := c.PodToModelMapping[podName]
+	models, ok := c.podToModelMapping[podName]
 	if !ok {
-		c.PodToModelMapping[podName] = map[string]struct{}{
+		c.podToModelMapping[podName] = map[string]struct{}{
 			modelName: {},
 		}
 	} else {
 		models[modelName] = struct{}{}
-		c.PodToModelMapping[podName] = models
+		c.podToModelMapping[podName] = models
 	}
 
-	pods, ok := c.ModelToPodMapping[modelName]
+	pods, ok := c.modelToPodMapping[modelName]
 	if !ok {
-		c.ModelToPodMapping[modelName] = map[string]*v1.Pod{
+		c.modelToPodMapping[modelName] = map[string]*v1.Pod{
 			podName: pod,
 		}
 	} else {
 		pods[podName] = pod
-		c.ModelToPodMapping[modelName] = pods
+		c.modelToPodMapping[modelName] = pods
 	}
 }
 
 func (c *Cache) deletePodAndModelMapping(podName, modelName string) {
-	if models, ok := c.PodToModelMapping[podName]; ok {
+	if models, ok := c.podToModelMapping[podName]; ok {
 		delete(models, modelName)
 		if len(models) != 0 {
-			c.PodToModelMapping[podName] = models
+			c.podToModelMapping[podName] = models
 		} else {
-			delete(c.PodToModelMapping, podName)
+			delete(c.podToModelMapping, podName)
 		}
 	}
 
-	if pods, ok := c.ModelToPodMapping[modelName]; ok {
+	if pods, ok := c.modelToPodMapping[modelName]; ok {
 		delete(pods, podName)
 		if len(pods) != 0 {
-			c.ModelToPodMapping[modelName] = pods
+			c.modelToPodMapping[modelName] = pods
 		} else {
-			delete(c.ModelToPodMapping, modelName)
+			delete(c.modelToPodMapping, modelName)
 		}
 	}
 }
@@ -458,42 +671,40 @@ func (c *Cache) debugInfo() {
 }
 
 func (c *Cache) debugInfoLocked() {
-	for _, pod := range c.Pods {
+	for _, pod := range c.pods {
 		klog.V(4).Infof("pod: %s, podIP: %v", pod.Name, pod.Status.PodIP)
 	}
-	for podName, models := range c.PodToModelMapping {
+	for podName, models := range c.podToModelMapping {
 		var modelList string
 		for modelName := range models {
 			modelList += modelName + " "
 		}
 		klog.V(4).Infof("pod: %s, models: %s", podName, modelList)
 	}
-	for modelName, pods := range c.ModelToPodMapping {
+	for modelName, pods := range c.modelToPodMapping {
 		var podList string
 		for podName := range pods {
 			podList += podName + " "
 		}
 		klog.V(4).Infof("model: %s, pods: %s", modelName, podList)
 	}
-	for podName, metrics := range c.PodMetrics {
-		for metricName, metricVal := range metrics {
+	c.podMetrics.rangePods(func(podName string, record *podMetricRecord) {
+		for metricName, metricVal := range record.pod {
 			klog.V(5).Infof("%v_%v_%v", podName, metricName, metricVal)
 		}
-	}
-	for podName, models := range c.PodModelMetrics {
-		for modelName, metrics := range models {
+		for modelName, metrics := range record.models {
 			for metricName, metricVal := range metrics {
 				klog.V(5).Infof("%v_%v_%v_%v", podName, modelName, metricName, metricVal)
 			}
 		}
-	}
+	})
 }
 
 func (c *Cache) GetPod(podName string) (*v1.Pod, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	pod, ok := c.Pods[podName]
+	pod, ok := c.pods[podName]
 	if !ok {
 		return nil, fmt.Errorf("pod does not exist in the cache: %s", podName)
 	}
@@ -501,56 +712,86 @@ func (c *Cache) GetPod(podName string) (*v1.Pod, error) {
 	return pod, nil
 }
 
+// GetPods returns a copy of the pods in the cache.
 func (c *Cache) GetPods() map[string]*v1.Pod {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return c.Pods
+	return maps.Clone(c.pods)
 }
 
+// GetPodsForModel returns a copy of the pods serving the model.
 func (c *Cache) GetPodsForModel(modelName string) (map[string]*v1.Pod, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	podsMap, ok := c.ModelToPodMapping[modelName]
+	podsMap, ok := c.modelToPodMapping[modelName]
 	if !ok {
 		return nil, fmt.Errorf("model does not exist in the cache: %s", modelName)
 	}
 
-	return podsMap, nil
+	return maps.Clone(podsMap), nil
 }
 
+// GetModelsForPod returns a copy of the models served by the pod.
 func (c *Cache) GetModelsForPod(podName string) (map[string]struct{}, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	models, ok := c.PodToModelMapping[podName]
+	models, ok := c.podToModelMapping[podName]
 	if !ok {
 		return nil, fmt.Errorf("pod does not exist in the cache: %s", podName)
 	}
 
-	return models, nil
+	return maps.Clone(models), nil
 }
 
-func (c *Cache) CheckModelExists(modelName string) bool {
+// GetModels returns the names of all base models and lora adapters present in the cache.
+func (c *Cache) GetModels() []string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	_, ok := c.ModelToPodMapping[modelName]
-
-	return ok
+	models := make([]string, 0, len(c.modelToPodMapping))
+	for modelName := range c.modelToPodMapping {
+		models = append(models, modelName)
+	}
+	return models
 }
 
-func (c *Cache) GetPodMetric(podName, metricName string) (metrics.MetricValue, error) {
+// GetModelAdapter returns the ModelAdapter object if the model is a lora adapter.
+func (c *Cache) GetModelAdapter(modelName string) (*modelv1alpha1.ModelAdapter, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	podMetrics, ok := c.PodMetrics[podName]
+	adapter, ok := c.modelAdapters[modelName]
 	if !ok {
+		return nil, fmt.Errorf("model adapter does not exist in the cache: %s", modelName)
+	}
+
+	return adapter, nil
+}
+
+// KVStore returns the store shared by the gateway replicas, nil if the cache runs without one.
+func (c *Cache) KVStore() kvstore.Store {
+	return c.kvStore
+}
+
+func (c *Cache) CheckModelExists(modelName string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.modelToPodMapping[modelName]
+
+	return ok
+}
+
+func (c *Cache) GetPodMetric(podName, metricName string) (metrics.MetricValue, error) {
+	record := c.podMetrics.load(podName)
+	if record == nil {
 		return nil, fmt.Errorf("pod does not exist in the podMetrics cache")
 	}
 
-	metricVal, ok := podMetrics[metricName]
+	metricVal, ok := record.pod[metricName]
 	if !ok {
 		return nil, fmt.Errorf("no metric available for %v", metricName)
 	}
@@ -559,15 +800,12 @@ func (c *Cache) GetPodMetric(podName, metricName string) (metrics.MetricValue, e
 }
 
 func (c *Cache) GetPodModelMetric(podName, modelName string, metricName string) (metrics.MetricValue, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	podMetrics, ok := c.PodModelMetrics[podName]
-	if !ok {
+	record := c.podMetrics.load(podName)
+	if record == nil {
 		return nil, fmt.Errorf("pod does not exist in the podMetrics cache")
 	}
 
-	modelMetrics, ok := podMetrics[modelName]
+	modelMetrics, ok := record.models[modelName]
 	if !ok {
 		return nil, fmt.Errorf("model does not exist in the podMetrics cache")
 	}
@@ -580,29 +818,7 @@ func (c *Cache) GetPodModelMetric(podName, modelName string, metricName string)
 	return metricVal, nil
 }
 
-// Update `PodMetrics` and `PodModelMetrics` according to the metric scope
-// TODO: replace in-place metric update podMetrics and podModelMetrics to fresh copy for preventing stale metric keys
-func (c *Cache) updatePodRecordLocked(podName string, modelName string, metricName string, scope metrics.MetricScope, metricValue metrics.MetricValue) error {
-	if scope == metrics.PodMetricScope {
-		if modelName != "" {
-			return fmt.Errorf("modelName should be empty for scope %v", scope)
-		}
-		c.PodMetrics[podName][metricName] = metricValue
-	} else if scope == metrics.PodModelMetricScope {
-		if modelName == "" {
-			return fmt.Errorf("modelName should not be empty for scope %v", scope)
-		}
-		if len(c.PodModelMetrics[podName][modelName]) == 0 {
-			c.PodModelMetrics[podName][modelName] = map[string]metrics.MetricValue{}
-		}
-		c.PodModelMetrics[podName][modelName][metricName] = metricValue
-	} else {
-		return fmt.Errorf("scope %v is not supported", scope)
-	}
-	return nil
-}
-
-func (c *Cache) queryUpdatePromQLMetricsLocked(metric metrics.Metric, queryLabels map[string]string, podName string, modelName string, metricName string) error {
+func (c *Cache) queryUpdatePromQLMetrics(record *podMetricRecord, metric metrics.Metric, queryLabels map[string]string, podName string, modelName string, metricName string) error {
 	scope := metric.MetricScope
 	query := metrics.BuildQuery(metric.PromQL, queryLabels)
 	// Querying metrics
@@ -617,7 +833,7 @@ func (c *Cache) queryUpdatePromQLMetricsLocked(metric metrics.Metric, queryLabel
 
 	// Update metrics
 	metricValue := &metrics.PrometheusMetricValue{Result: &result}
-	err = c.updatePodRecordLocked(podName, modelName, metricName, scope, metricValue)
+	err = record.set(modelName, metricName, scope, metricValue)
 	if err != nil {
 		return fmt.Errorf("failed to update metrics %s from prometheus %s: %v", metricName, podName, err)
 	}
@@ -625,7 +841,7 @@ func (c *Cache) queryUpdatePromQLMetricsLocked(metric metrics.Metric, queryLabel
 	return nil
 }
 
-func (c *Cache) updateSimpleMetricFromRawMetricsLocked(pod *v1.Pod, allMetrics map[string]*dto.MetricFamily) {
+func (c *Cache) updateSimpleMetricFromRawMetrics(record *podMetricRecord, pod *v1.Pod, allMetrics map[string]*dto.MetricFamily) {
 	podName := pod.Name
 	for _, metricName := range counterGaugeMetricNames {
 		metric, exists := metrics.Metrics[metricName]
@@ -650,7 +866,7 @@ func (c *Cache) updateSimpleMetricFromRawMetricsLocked(pod *v1.Pod, allMetrics m
 				continue
 			}
 
-			err = c.updatePodRecordLocked(podName, modelName, metricName, scope, &metrics.SimpleMetricValue{Value: metricValue})
+			err = record.set(modelName, metricName, scope, &metrics.SimpleMetricValue{Value: metricValue})
 			if err != nil {
 				klog.V(4).Infof("Failed to update metrics %s from pod %s %s %d: %v", metricName, podName, pod.Status.PodIP, podPort, err)
 				continue
@@ -661,7 +877,7 @@ func (c *Cache) updateSimpleMetricFromRawMetricsLocked(pod *v1.Pod, allMetrics m
 	}
 }
 
-func (c *Cache) updateHistogramMetricFromRawMetricsLocked(pod *v1.Pod, allMetrics map[string]*dto.MetricFamily) {
+func (c *Cache) updateHistogramMetricFromRawMetrics(record *podMetricRecord, pod *v1.Pod, allMetrics map[string]*dto.MetricFamily) {
 	podName := pod.Name
 	for _, metricName := range histogramMetricNames {
 		metric, exists := metrics.Metrics[metricName]
@@ -689,7 +905,7 @@ func (c *Cache) updateHistogramMetricFromRawMetricsLocked(pod *v1.Pod, allMetric
 				Count:   metricValue.Count,
 				Buckets: metricValue.Buckets,
 			}
-			err = c.updatePodRecordLocked(podName, modelName, metricName, scope, histogramValue)
+			err = record.set(modelName, metricName, scope, histogramValue)
 			if err != nil {
 				klog.V(4).Infof("Failed to update metrics %s from pod %s %s %d: %v", metricName, podName, pod.Status.PodIP, podPort, err)
 				continue
@@ -701,7 +917,7 @@ func (c *Cache) updateHistogramMetricFromRawMetricsLocked(pod *v1.Pod, allMetric
 	}
 }
 
-func (c *Cache) updateQueryLabelMetricFromRawMetricsLocked(pod *v1.Pod, allMetrics map[string]*dto.MetricFamily) {
+func (c *Cache) updateQueryLabelMetricFromRawMetrics(record *podMetricRecord, pod *v1.Pod, allMetrics map[string]*dto.MetricFamily) {
 	podName := pod.Name
 
 	for _, labelMetricName := range labelQueryMetricNames {
@@ -720,7 +936,7 @@ func (c *Cache) updateQueryLabelMetricFromRawMetricsLocked(pod *v1.Pod, allMetri
 		for _, familyMetric := range metricFamily.Metric {
 			modelName, _ := metrics.GetLabelValueForKey(familyMetric, "model_name")
 			labelValue, _ := metrics.GetLabelValueForKey(familyMetric, labelMetricName)
-			err := c.updatePodRecordLocked(podName, modelName, labelMetricName, scope, &metrics.LabelValueMetricValue{Value: labelValue})
+			err := record.set(modelName, labelMetricName, scope, &metrics.LabelValueMetricValue{Value: labelValue})
 			if err != nil {
 				klog.V(4).Infof("Failed to update metrics %s from pod %s %s %d: %v", labelMetricName, podName, pod.Status.PodIP, podPort, err)
 				continue
@@ -731,7 +947,7 @@ func (c *Cache) updateQueryLabelMetricFromRawMetricsLocked(pod *v1.Pod, allMetri
 	}
 }
 
-func (c *Cache) updateMetricFromPromQLLocked(pod *v1.Pod) {
+func (c *Cache) updateMetricFromPromQL(record *podMetricRecord, pod *v1.Pod, modelNames []string) {
 	podName := pod.Name
 
 	for _, metricName := range prometheusMetricNames {
@@ -745,16 +961,16 @@ func (c *Cache) updateMetricFromPromQLLocked(pod *v1.Pod) {
 		}
 		scope := metric.MetricScope
 		if scope == metrics.PodMetricScope {
-			err := c.queryUpdatePromQLMetricsLocked(metric, queryLabels, podName, "", metricName)
+			err := c.queryUpdatePromQLMetrics(record, metric, queryLabels, podName, "", metricName)
 			if err != nil {
 				klog.V(4).Infof("Failed to query and update PromQL metrics: %v", err)
 				continue
 			}
 		} else if scope == metrics.PodModelMetricScope {
-			if modelNames, ok := c.PodToModelMapping[podName]; ok {
-				for modelName := range modelNames {
+			if len(modelNames) > 0 {
+				for _, modelName := range modelNames {
 					queryLabels["model_name"] = modelName
-					err := c.queryUpdatePromQLMetricsLocked(metric, queryLabels, podName, modelName, metricName)
+					err := c.queryUpdatePromQLMetrics(record, metric, queryLabels, podName, modelName, metricName)
 					if err != nil {
 						klog.V(4).Infof("Failed to query and update PromQL metrics: %v", err)
 						continue
@@ -769,47 +985,77 @@ func (c *Cache) updateMetricFromPromQLLocked(pod *v1.Pod) {
 	}
 }
 
+// updatePodMetrics scrapes the metrics of the ready pods due for a scrape. The engines are scraped without holding the
+// cache lock, so that routing decisions never wait on a slow engine.
 func (c *Cache) updatePodMetrics() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	now := time.Now()
+	c.mu.RLock()
+	var duePods []*v1.Pod
+	for _, pod := range utils.FilterReadyPods(c.pods) {
+		if c.isScrapeDueLocked(pod, now) {
+			duePods = append(duePods, pod)
+		}
+	}
+	c.mu.RUnlock()
 
-	readyPods := utils.FilterReadyPods(c.Pods)
-	if len(readyPods) == 0 {
-		return
+	for _, pod := range duePods {
+		c.scrapePod(pod)
 	}
+}
 
-	for _, pod := range readyPods {
-		podName := pod.Name
-		if len(c.PodMetrics[podName]) == 0 {
-			c.PodMetrics[podName] = map[string]metrics.MetricValue{}
-		}
-		if len(c.PodModelMetrics[podName]) == 0 {
-			c.PodModelMetrics[podName] = make(map[string]map[string]metrics.MetricValue)
-		}
+// scrapePod updates a copy of the metrics of the pod with the metrics scraped from its engine and replaces them.
+func (c *Cache) scrapePod(pod *v1.Pod) {
+	podName := pod.Name
+	c.mu.RLock()
+	modelNames := make([]string, 0, len(c.podToModelMapping[podName]))
+	for modelName := range c.podToModelMapping[podName] {
+		modelNames = append(modelNames, modelName)
+	}
+	c.mu.RUnlock()
 
-		// We should use the primary container port. In the future, we can decide whether to use sidecar container's port
-		url := fmt.Sprintf("http://%s:%d/metrics", pod.Status.PodIP, podPort)
-		allMetrics, err := metrics.ParseMetricsURL(url)
-		if err != nil {
-			klog.V(4).Infof("Error parsing metric families: %v\n", err)
-		}
+	// We should use the primary container port. In the future, we can decide whether to use sidecar container's port
+	url := fmt.Sprintf("http://%s:%d/metrics", pod.Status.PodIP, podPort)
+	start := time.Now()
+	allMetrics, err := fetchEngineMetrics(url)
+	if err != nil {
+		klog.V(4).Infof("Error parsing metric families: %v\n", err)
+	}
 
-		// parse counterGaugeMetricsNames
-		c.updateSimpleMetricFromRawMetricsLocked(pod, allMetrics)
+	// besides the scrape, only pushes update the metrics of a pod, they are kept by keepPushedMetricsLocked.
+	record := c.podMetrics.load(podName).clone()
 
-		// parse histogramMetrics
-		c.updateHistogramMetricFromRawMetricsLocked(pod, allMetrics)
+	// parse counterGaugeMetricsNames
+	c.updateSimpleMetricFromRawMetrics(record, pod, allMetrics)
 
-		// parse QueryLabel metrics
-		c.updateQueryLabelMetricFromRawMetricsLocked(pod, allMetrics)
+	// parse histogramMetrics
+	c.updateHistogramMetricFromRawMetrics(record, pod, allMetrics)
 
-		if c.prometheusApi == nil {
-			klog.V(4).InfoS("Prometheus api is not initialized, PROMETHEUS_ENDPOINT is not configured, skip fetching prometheus metrics")
-			continue
-		}
+	// parse QueryLabel metrics
+	c.updateQueryLabelMetricFromRawMetrics(record, pod, allMetrics)
+
+	if c.prometheusApi == nil {
+		klog.V(4).InfoS("Prometheus api is not initialized, PROMETHEUS_ENDPOINT is not configured, skip fetching prometheus metrics")
+	} else {
 		// parse prometheus metrics
-		c.updateMetricFromPromQLLocked(pod)
+		c.updateMetricFromPromQL(record, pod, modelNames)
 	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.pods[podName]; !ok {
+		// deleted while scraped.
+		return
+	}
+	now := time.Now()
+	c.recordScrapeLocked(podName, err)
+	c.recordScrapeActivityLocked(podName, record, err, now, now.Sub(start))
+
+	c.keepPushedMetricsLocked(podName, record, now)
+
+	// derive KV offloading transfer bandwidth
+	c.updateKVTransferBandwidthLocked(record, podName, now)
+	c.podMetrics.store(podName, record)
+	c.notifyMetricWatches(podName)
 }
 
 func (c *Cache) updateModelMetrics() {
@@ -899,7 +1145,7 @@ func (c *Cache) getTraceKey(inputTokens, outputTokens int64) (traceKey string) {
 	return
 }
 
-func (c *Cache) writeRequestTraceToStorage(roundT int64) {
+func (c *Cache) writeRequestTraceToStorage(roundT int64, traceConfig aibrixconfig.RequestTraceConfig) {
 	// Save and reset trace context, atomicity is guaranteed.
 	var requestTrace *sync.Map
 	numTraces := atomic.LoadInt32(&c.numRequestsTraces)
@@ -912,6 +1158,14 @@ func (c *Cache) writeRequestTraceToStorage(roundT int64) {
 		numTraces, numResetTo = updatedNumTraces, updatedNumTraces-numTraces
 	}
 
+	batch := traceBatch{
+		roundT:   roundT,
+		traces:   map[string]map[string]int{},
+		encoding: traceConfig.Encoding,
+		values:   map[string][]byte{},
+		ttl:      traceConfig.TTL,
+		created:  time.Now(),
+	}
 	requestTrace.Range(func(iModelName, iTrace any) bool {
 		modelName := iModelName.(string)
 		trace := iTrace.(*RequestTrace)
@@ -922,24 +1176,23 @@ func (c *Cache) writeRequestTraceToStorage(roundT int64) {
 		if pCounter, loaded := c.pendingRequests.Load(modelName); loaded {
 			pending = atomic.LoadInt32(pCounter.(*int32))
 		}
-		traceMap := trace.ToMapLocked(pending)
+		batch.traces[traceConfig.Key(modelName, roundT)] = trace.ToMapLocked(pending, traceConfig.Interval)
 		trace.RecycleLocked()
 		trace.Unlock()
-
-		value, err := json.Marshal(traceMap)
-		if err != nil {
-			klog.ErrorS(err, "error to marshall request trace for redis set")
-			return true
-		}
-
-		key := fmt.Sprintf("aibrix:%v_request_trace_%v", modelName, roundT)
-		if _, err = c.redisClient.Set(context.Background(), key, value, expireWriteRequestTraceIntervalInMins*time.Minute).Result(); err != nil {
-			klog.Error(err)
-		}
 		return true
 	})
 
-	klog.V(5).Infof("writeRequestTraceWithKey: %v", roundT)
+	// every replica merges its traces into the windows, only the leader writes the meta key.
+	if len(batch.traces) > 0 {
+		if c.IsLeader() {
+			// the meta key is refreshed with every batch, so it lives as long as the traces it describes.
+			meta, err := json.Marshal(traceConfig.Meta(RequestTraceVersion, int(1/RequestTracePrecision)))
+			if err == nil {
+				batch.values[traceConfig.MetaKey()] = meta
+			}
+		}
+		c.traceWriter.enqueue(batch)
+	}
 }
 
 func (c *Cache) AddSubscriber(subscriber metrics.MetricSubscriber) {
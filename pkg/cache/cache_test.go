@@ -16,6 +16,8 @@ limitations under the License.
 package cache
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
@@ -23,10 +25,16 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
 )
 
 func newTraceCache() *Cache {
@@ -96,6 +104,64 @@ var _ = Describe("Cache", func() {
 		Expect(*pProfileCounter.(*int32)).To(Equal(int32(1)))
 	})
 
+	It("should write request traces to the kv store", func() {
+		cache := newTraceCache()
+		store := kvstore.NewMemoryStore()
+		cache.kvStore = store
+		cache.traceWriter = newTraceWriter(store)
+		term := cache.AddRequestCount("no use now", "llama-7b")
+		cache.DoneRequestTrace("no use now", "llama-7b", 1, 1, term)
+
+		traceConfig := config.RequestTraceConfig{Interval: 30 * time.Second, TTL: time.Hour, KeyPrefix: "aibrix:", KeySchema: config.RequestTraceKeySchemaV1}
+		cache.writeRequestTraceToStorage(100, traceConfig)
+		Expect(cache.traceWriter.queue).To(HaveLen(1))
+		cache.traceWriter.write(<-cache.traceWriter.queue, nil)
+		value, err := store.Get(context.Background(), "aibrix:llama-7b_request_trace_100")
+		Expect(err).ToNot(HaveOccurred())
+		var trace map[string]interface{}
+		Expect(json.Unmarshal(value, &trace)).To(Succeed())
+		Expect(trace).To(HaveKeyWithValue("0:0", BeNumerically("==", 1)))
+		Expect(trace).To(HaveKeyWithValue(MetaKeyIntervalInSeconds.ToString(), BeNumerically("==", 30)))
+
+		value, err = store.Get(context.Background(), traceConfig.MetaKey())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.ParseRequestTraceMeta(value)).To(Equal(traceConfig))
+	})
+
+	It("should merge the request traces of all replicas", func() {
+		store := kvstore.NewMemoryStore()
+		traceConfig := config.RequestTraceConfig{Interval: 30 * time.Second, TTL: time.Hour, KeyPrefix: "aibrix:", KeySchema: config.RequestTraceKeySchemaV1}
+		replica := func(leader bool, requests int) {
+			cache := newTraceCache()
+			cache.leaderElection = true
+			cache.leading.Store(leader)
+			cache.traceWriter = newTraceWriter(store)
+			for i := 0; i < requests; i++ {
+				term := cache.AddRequestCount("no use now", "llama-7b")
+				cache.DoneRequestTrace("no use now", "llama-7b", 1, 1, term)
+			}
+			cache.AddRequestCount("no use now", "llama-7b")
+			cache.writeRequestTraceToStorage(100, traceConfig)
+			cache.traceWriter.write(<-cache.traceWriter.queue, nil)
+		}
+
+		replica(false, 2)
+		_, err := store.Get(context.Background(), traceConfig.MetaKey())
+		Expect(err).To(MatchError(kvstore.ErrNotFound), "only the leader writes the meta key")
+		replica(true, 3)
+
+		value, err := store.Get(context.Background(), "aibrix:llama-7b_request_trace_100")
+		Expect(err).ToNot(HaveOccurred())
+		trace, err := config.DecodeRequestTrace(value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(trace).To(HaveKeyWithValue("0:0", 5))
+		Expect(trace).To(HaveKeyWithValue(MetaKeyTotalRequests.ToString(), 7))
+		Expect(trace).To(HaveKeyWithValue(MetaKeyPendingRequests.ToString(), 2))
+		Expect(trace).To(HaveKeyWithValue(MetaKeyVersionKey.ToString(), RequestTraceVersion))
+		_, err = store.Get(context.Background(), traceConfig.MetaKey())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
 	It("should global pending counter return 0.", func() {
 		cache := newTraceCache()
 		total := 100000
@@ -119,6 +185,23 @@ var _ = Describe("Cache", func() {
 		pendingCounter, _ := cache.pendingRequests.Load("model")
 		Expect(atomic.LoadInt32(pendingCounter.(*int32))).To(Equal(int32(0)))
 	})
+
+	It("should not be mutated through the maps returned by the accessors", func() {
+		cache := New()
+		cache.SetPod(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1"}}, "llama-7b")
+
+		delete(cache.GetPods(), "p1")
+		pods, err := cache.GetPodsForModel("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		delete(pods, "p1")
+		models, err := cache.GetModelsForPod("p1")
+		Expect(err).ToNot(HaveOccurred())
+		models["qwen-7b"] = struct{}{}
+
+		Expect(cache.GetPods()).To(HaveKey("p1"))
+		Expect(cache.GetPodsForModel("llama-7b")).To(HaveKey("p1"))
+		Expect(cache.GetModelsForPod("p1")).To(Equal(map[string]struct{}{"llama-7b": {}}))
+	})
 })
 
 func BenchmarkLagacyAddRequestTrace(b *testing.B) {
@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ModelCapabilitiesAnnotationKey is the pod annotation declaring the comma separated capabilities of the engine,
+	// it replaces the capabilities discovered from the engine arguments.
+	ModelCapabilitiesAnnotationKey = "model.aibrix.ai/capabilities"
+
+	// CapabilityToolCalling is the support of tools the model chooses to call.
+	CapabilityToolCalling = "tool-calling"
+	// CapabilityJSONMode is the support of JSON and JSON schema response formats, and of guided decoding.
+	CapabilityJSONMode = "json-mode"
+	// CapabilityMultimodal is the support of image, audio or video inputs.
+	CapabilityMultimodal = "multimodal"
+	// CapabilitySpeculativeDecoding is the engine decoding with a draft model.
+	CapabilitySpeculativeDecoding = "speculative-decoding"
+)
+
+// vllmCapabilityFlags are the vLLM arguments enabling a capability. vLLM serves JSON response formats with its
+// default guided decoding backend.
+var vllmCapabilityFlags = map[string]string{
+	"--enable-auto-tool-choice": CapabilityToolCalling,
+	"--limit-mm-per-prompt":     CapabilityMultimodal,
+	"--speculative-model":       CapabilitySpeculativeDecoding,
+	"--speculative-config":      CapabilitySpeculativeDecoding,
+}
+
+// podCapabilities returns the sorted capabilities of the engine of the pod, from its annotation or else from the
+// arguments the engine was launched with.
+func podCapabilities(pod *v1.Pod) []string {
+	capabilities := map[string]bool{}
+	if value, ok := pod.Annotations[ModelCapabilitiesAnnotationKey]; ok {
+		for _, capability := range strings.Split(value, ",") {
+			if capability = strings.TrimSpace(capability); capability != "" {
+				capabilities[capability] = true
+			}
+		}
+	} else if PodEngine(pod) == defaultModelEngine {
+		capabilities[CapabilityJSONMode] = true
+		for _, container := range pod.Spec.Containers {
+			for _, args := range [][]string{container.Command, container.Args} {
+				for _, arg := range args {
+					// arguments may be a shell command line.
+					for _, field := range strings.Fields(arg) {
+						flag, _, _ := strings.Cut(field, "=")
+						if capability, ok := vllmCapabilityFlags[strings.ReplaceAll(flag, "_", "-")]; ok {
+							capabilities[capability] = true
+						}
+					}
+				}
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(capabilities))
+	for capability := range capabilities {
+		sorted = append(sorted, capability)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// GetPodCapabilities returns the sorted capabilities of the engine of the pod, nil if the pod is unknown.
+func (c *Cache) GetPodCapabilities(podName string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pod, ok := c.pods[podName]
+	if !ok {
+		return nil
+	}
+	return podCapabilities(pod)
+}
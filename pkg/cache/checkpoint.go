@@ -0,0 +1,350 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// checkpointKeyPrefix is followed by the replica writing the checkpoint.
+	checkpointKeyPrefix            = "aibrix:cache-checkpoint:"
+	defaultCheckpointMaxAgeInSecs  = 60
+	checkpointTimeout              = 5 * time.Second
+	checkpointRestoreResultNone    = "none"
+	checkpointRestoreResultStale   = "stale"
+	checkpointRestoreResultFailed  = "failed"
+	checkpointRestoreResultSuccess = "restored"
+)
+
+var (
+	checkpointInterval = getCheckpointInterval()
+	checkpointMaxAge   = getCheckpointMaxAge()
+
+	checkpointRestores = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_cache_checkpoint_restores_total",
+		Help: "Restores of the cache from a checkpoint at startup, by result: restored, stale, none or failed.",
+	}, []string{"result"})
+	checkpointSaves = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_cache_checkpoint_saves_total",
+		Help: "Checkpoints of the cache written to the kv store, by result: success or failure.",
+	}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(checkpointRestores, checkpointSaves)
+}
+
+// getCheckpointInterval returns how often the cache is checkpointed, 0 disables checkpoints.
+func getCheckpointInterval() time.Duration {
+	value := utils.LoadEnv("AIBRIX_CACHE_CHECKPOINT_INTERVAL_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_CACHE_CHECKPOINT_INTERVAL_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_CACHE_CHECKPOINT_INTERVAL_S env value for cache checkpoint interval: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return 0
+}
+
+// getCheckpointMaxAge returns the age beyond which a checkpoint is too stale to be restored.
+func getCheckpointMaxAge() time.Duration {
+	value := utils.LoadEnv("AIBRIX_CACHE_CHECKPOINT_MAX_AGE_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_CACHE_CHECKPOINT_MAX_AGE_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_CACHE_CHECKPOINT_MAX_AGE_S env value for cache checkpoint max age: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultCheckpointMaxAgeInSecs * time.Second
+}
+
+// Checkpointer is state kept outside of the cache, e.g. the prefix cache index of a router, that is checkpointed and
+// restored along with it.
+type Checkpointer interface {
+	// Checkpoint returns the state to restore.
+	Checkpoint() (json.RawMessage, error)
+	// Restore restores the state checkpointed age ago.
+	Restore(data json.RawMessage, age time.Duration) error
+}
+
+// Checkpoint is the state of the cache of a gateway replica that takes a while to rebuild after a restart: the metrics
+// scraped from the pods, the health of their engines and the requests in flight.
+type Checkpoint struct {
+	Replica         string                                          `json:"replica"`
+	Timestamp       time.Time                                       `json:"timestamp"`
+	PodMetrics      map[string]map[string]MetricSnapshot            `json:"podMetrics,omitempty"`
+	PodModelMetrics map[string]map[string]map[string]MetricSnapshot `json:"podModelMetrics,omitempty"`
+	// HealthyPods are the pods whose engine served.
+	HealthyPods []string `json:"healthyPods,omitempty"`
+	// Inflight is the number of in-flight engine requests per pod.
+	Inflight map[string]int32 `json:"inflight,omitempty"`
+	// Extensions are the states of the checkpointers by name.
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+// MetricSnapshot is a metric value, one of the fields is set. Prometheus query results are not checkpointed.
+type MetricSnapshot struct {
+	Value     *float64                      `json:"value,omitempty"`
+	Histogram *metrics.HistogramMetricValue `json:"histogram,omitempty"`
+	Label     *string                       `json:"label,omitempty"`
+}
+
+func snapshotMetric(value metrics.MetricValue) (MetricSnapshot, bool) {
+	switch value := value.(type) {
+	case *metrics.SimpleMetricValue:
+		return MetricSnapshot{Value: &value.Value}, true
+	case *metrics.HistogramMetricValue:
+		return MetricSnapshot{Histogram: value}, true
+	case *metrics.LabelValueMetricValue:
+		return MetricSnapshot{Label: &value.Value}, true
+	default:
+		return MetricSnapshot{}, false
+	}
+}
+
+func (s MetricSnapshot) metricValue() (metrics.MetricValue, bool) {
+	switch {
+	case s.Value != nil:
+		return &metrics.SimpleMetricValue{Value: *s.Value}, true
+	case s.Histogram != nil:
+		return s.Histogram, true
+	case s.Label != nil:
+		return &metrics.LabelValueMetricValue{Value: *s.Label}, true
+	default:
+		return nil, false
+	}
+}
+
+func snapshotMetrics(values map[string]metrics.MetricValue) map[string]MetricSnapshot {
+	snapshots := make(map[string]MetricSnapshot, len(values))
+	for name, value := range values {
+		if snapshot, ok := snapshotMetric(value); ok {
+			snapshots[name] = snapshot
+		}
+	}
+	return snapshots
+}
+
+func restoreMetrics(snapshots map[string]MetricSnapshot) map[string]metrics.MetricValue {
+	values := make(map[string]metrics.MetricValue, len(snapshots))
+	for name, snapshot := range snapshots {
+		if value, ok := snapshot.metricValue(); ok {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// AddCheckpointer registers state checkpointed along with the cache under the name. If the cache was restored from a
+// checkpoint holding state of the name, the state is restored right away.
+func (c *Cache) AddCheckpointer(name string, checkpointer Checkpointer) {
+	c.mu.Lock()
+	if c.checkpointers == nil {
+		c.checkpointers = map[string]Checkpointer{}
+	}
+	c.checkpointers[name] = checkpointer
+	restored := c.restoredCheckpoint
+	c.mu.Unlock()
+
+	if restored == nil || restored.Extensions[name] == nil {
+		return
+	}
+	if err := checkpointer.Restore(restored.Extensions[name], time.Since(restored.Timestamp)); err != nil {
+		klog.ErrorS(err, "failed to restore checkpointed state", "name", name, "replica", restored.Replica)
+	}
+}
+
+// checkpoint returns the checkpoint of the cache at now.
+func (c *Cache) checkpoint(now time.Time) *Checkpoint {
+	c.mu.RLock()
+	checkpoint := &Checkpoint{
+		Replica:         loadReportReplica,
+		Timestamp:       now,
+		PodMetrics:      map[string]map[string]MetricSnapshot{},
+		PodModelMetrics: map[string]map[string]map[string]MetricSnapshot{},
+		Inflight:        map[string]int32{},
+	}
+	c.podMetrics.rangePods(func(pod string, record *podMetricRecord) {
+		checkpoint.PodMetrics[pod] = snapshotMetrics(record.pod)
+		checkpoint.PodModelMetrics[pod] = make(map[string]map[string]MetricSnapshot, len(record.models))
+		for model, values := range record.models {
+			checkpoint.PodModelMetrics[pod][model] = snapshotMetrics(values)
+		}
+	})
+	for pod, health := range c.engineHealth {
+		if health.healthy() {
+			checkpoint.HealthyPods = append(checkpoint.HealthyPods, pod)
+		}
+	}
+	checkpointers := make(map[string]Checkpointer, len(c.checkpointers))
+	for name, checkpointer := range c.checkpointers {
+		checkpointers[name] = checkpointer
+	}
+	c.mu.RUnlock()
+
+	c.podBatchItems.Range(func(key, value any) bool {
+		if items := atomic.LoadInt32(value.(*int32)); items > 0 {
+			checkpoint.Inflight[key.(string)] = items
+		}
+		return true
+	})
+	for name, checkpointer := range checkpointers {
+		data, err := checkpointer.Checkpoint()
+		if err != nil {
+			klog.ErrorS(err, "failed to checkpoint state", "name", name)
+			continue
+		}
+		if checkpoint.Extensions == nil {
+			checkpoint.Extensions = map[string]json.RawMessage{}
+		}
+		checkpoint.Extensions[name] = data
+	}
+	return checkpoint
+}
+
+// saveCheckpoint writes the checkpoint of the replica, it expires once too stale to be restored.
+func (c *Cache) saveCheckpoint(ctx context.Context, store kvstore.Store, now time.Time) error {
+	data, err := json.Marshal(c.checkpoint(now))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, checkpointTimeout)
+	defer cancel()
+	return store.Set(ctx, checkpointKeyPrefix+loadReportReplica, data, checkpointMaxAge)
+}
+
+// runCheckpoints writes the checkpoint of the replica every interval.
+func (c *Cache) runCheckpoints(store kvstore.Store, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.saveCheckpoint(context.Background(), store, time.Now()); err != nil {
+				klog.ErrorS(err, "failed to save the cache checkpoint")
+				checkpointSaves.WithLabelValues("failure").Inc()
+			} else {
+				checkpointSaves.WithLabelValues("success").Inc()
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// restoreCheckpoint warms the cache up from the checkpoints of the gateway replicas that are not older than the max
+// age. The metrics, engine health and checkpointed state come from the latest checkpoint and are restored for the
+// existing pods only, until they are scraped again. The in-flight requests of every checkpoint are merged as the load
+// of its replica: the next load report of a running replica replaces them, those of a stopped one expire with the
+// checkpoint.
+func (c *Cache) restoreCheckpoint(store kvstore.Store, pods map[string]struct{}, now time.Time) string {
+	ctx, cancel := context.WithTimeout(context.Background(), checkpointTimeout)
+	defer cancel()
+
+	var latest *Checkpoint
+	var checkpoints []*Checkpoint
+	stale := false
+	err := store.Scan(ctx, checkpointKeyPrefix, func(key string, value []byte) error {
+		var checkpoint Checkpoint
+		if err := json.Unmarshal(value, &checkpoint); err != nil {
+			klog.V(4).ErrorS(err, "ignoring invalid cache checkpoint", "key", key)
+			return nil
+		}
+		if age := now.Sub(checkpoint.Timestamp); age > checkpointMaxAge || age < 0 {
+			stale = true
+			return nil
+		}
+		checkpoints = append(checkpoints, &checkpoint)
+		if latest == nil || checkpoint.Timestamp.After(latest.Timestamp) {
+			latest = &checkpoint
+		}
+		return nil
+	})
+	switch {
+	case err != nil:
+		klog.ErrorS(err, "failed to read the cache checkpoints")
+		return checkpointRestoreResultFailed
+	case latest == nil && stale:
+		return checkpointRestoreResultStale
+	case latest == nil:
+		return checkpointRestoreResultNone
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for pod := range pods {
+		values, models := latest.PodMetrics[pod], latest.PodModelMetrics[pod]
+		if values == nil && models == nil {
+			continue
+		}
+		record := &podMetricRecord{pod: restoreMetrics(values), models: make(map[string]map[string]metrics.MetricValue, len(models))}
+		for model, values := range models {
+			record.models[model] = restoreMetrics(values)
+		}
+		c.podMetrics.store(pod, record)
+	}
+	for _, pod := range latest.HealthyPods {
+		if _, ok := pods[pod]; ok {
+			health := c.engineHealthLocked(pod)
+			health.succeeded, health.lastSuccess = true, latest.Timestamp
+		}
+	}
+	if c.remoteLoads == nil {
+		c.remoteLoads = map[string]*remoteLoad{}
+	}
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Replica == loadReportReplica || len(checkpoint.Inflight) == 0 {
+			continue
+		}
+		if _, ok := c.remoteLoads[checkpoint.Replica]; ok {
+			continue
+		}
+		c.remoteLoads[checkpoint.Replica] = &remoteLoad{pods: checkpoint.Inflight, expires: checkpoint.Timestamp.Add(checkpointMaxAge)}
+	}
+	c.restoredCheckpoint = latest
+	klog.InfoS("cache restored from checkpoint", "replica", latest.Replica, "age", now.Sub(latest.Timestamp),
+		"pods", len(latest.PodMetrics), "checkpoints", len(checkpoints))
+	return checkpointRestoreResultSuccess
+}
+
+// podNames returns the names of the pods of the informer keys, namespace/name.
+func podNames(keys []string) map[string]struct{} {
+	names := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		names[key[strings.LastIndexByte(key, '/')+1:]] = struct{}{}
+	}
+	return names
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+type fakeCheckpointer struct {
+	state    string
+	restored string
+}
+
+func (f *fakeCheckpointer) Checkpoint() (json.RawMessage, error) {
+	return json.Marshal(f.state)
+}
+
+func (f *fakeCheckpointer) Restore(data json.RawMessage, age time.Duration) error {
+	return json.Unmarshal(data, &f.restored)
+}
+
+var _ = Describe("Checkpoint", func() {
+	var store kvstore.Store
+
+	BeforeEach(func() {
+		store = kvstore.NewMemoryStore()
+	})
+
+	It("should restore the metrics, health and checkpointed state of the existing pods", func() {
+		source := &Cache{}
+		source.SetPodMetric("p1", metrics.NumRequestsRunning, &metrics.SimpleMetricValue{Value: 3})
+		source.SetPodMetric("p1", metrics.MaxLora, &metrics.LabelValueMetricValue{Value: "4"})
+		source.SetPodMetric("p2", metrics.NumRequestsRunning, &metrics.SimpleMetricValue{Value: 1})
+		source.SetPodModelMetric("p1", "llama", metrics.E2ERequestLatencySeconds,
+			&metrics.HistogramMetricValue{Sum: 4, Count: 2, Buckets: map[string]float64{"1": 1}})
+		source.engineHealthLocked("p1").succeeded = true
+		source.AddCheckpointer("prefix-cache", &fakeCheckpointer{state: "blocks"})
+		Expect(source.saveCheckpoint(context.Background(), store, time.Now().Add(-time.Second))).To(Succeed())
+
+		cache := &Cache{}
+		Expect(cache.restoreCheckpoint(store, podNames([]string{"default/p1"}), time.Now())).To(Equal(checkpointRestoreResultSuccess))
+		running, err := cache.GetPodMetric("p1", metrics.NumRequestsRunning)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(running.GetSimpleValue()).To(Equal(3.0))
+		lora, err := cache.GetPodMetric("p1", metrics.MaxLora)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lora.GetLabelValue()).To(Equal("4"))
+		latency, err := cache.GetPodModelMetric("p1", "llama", metrics.E2ERequestLatencySeconds)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(latency.GetHistogramValue().Count).To(Equal(2.0))
+		Expect(cache.engineHealth["p1"].healthy()).To(BeTrue())
+		// the pod does not exist anymore.
+		Expect(cache.podMetrics.load("p2")).To(BeNil())
+
+		// state registered after the restore is restored on registration.
+		checkpointer := &fakeCheckpointer{}
+		cache.AddCheckpointer("prefix-cache", checkpointer)
+		Expect(checkpointer.restored).To(Equal("blocks"))
+	})
+
+	It("should restore the in-flight requests of the other replicas as their load", func() {
+		now := time.Now()
+		checkpoint, err := json.Marshal(Checkpoint{Replica: "gw-1", Timestamp: now.Add(-time.Second), Inflight: map[string]int32{"p1": 2}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.Set(context.Background(), checkpointKeyPrefix+"gw-1", checkpoint, time.Minute)).To(Succeed())
+
+		cache := &Cache{}
+		Expect(cache.restoreCheckpoint(store, podNames([]string{"default/p1"}), now)).To(Equal(checkpointRestoreResultSuccess))
+		Expect(cache.GetPodRemoteInflightBatchItems("p1")).To(Equal(int32(2)))
+
+		// the next load report of the replica replaces it.
+		cache.mergeLoadReport(LoadReport{Replica: "gw-1"}, now)
+		Expect(cache.GetPodRemoteInflightBatchItems("p1")).To(BeZero())
+	})
+
+	It("should not restore stale checkpoints", func() {
+		cache := &Cache{}
+		Expect(cache.restoreCheckpoint(store, nil, time.Now())).To(Equal(checkpointRestoreResultNone))
+
+		source := &Cache{}
+		source.SetPodMetric("p1", metrics.NumRequestsRunning, &metrics.SimpleMetricValue{Value: 3})
+		Expect(source.saveCheckpoint(context.Background(), store, time.Now().Add(-2*checkpointMaxAge))).To(Succeed())
+		Expect(cache.restoreCheckpoint(store, podNames([]string{"default/p1"}), time.Now())).To(Equal(checkpointRestoreResultStale))
+		Expect(cache.podMetrics.load("p1")).To(BeNil())
+	})
+})
@@ -0,0 +1,129 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/profiling"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+var (
+	podColdStartDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "aibrix_gateway_pod_cold_start_seconds",
+		Help:    "Time a new pod of the model took to serve, by phase: scheduling, startup of the containers including image pulls, loading of the model until the engine serves, and total.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"model", "phase"})
+	modelColdStartEstimate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "aibrix_gateway_model_cold_start_estimate_seconds",
+		Help: "Rolling P90 of the time the recent new pods of the model took to serve.",
+	}, []string{"model"})
+)
+
+func init() {
+	prometheus.MustRegister(podColdStartDuration, modelColdStartEstimate)
+}
+
+// trackColdStartLocked starts measuring the cold start of a pod the gateway sees before it is Ready. Pods already Ready,
+// e.g. when the gateway starts, or Ready again after a container restart are not cold starts.
+func (c *Cache) trackColdStartLocked(pod *v1.Pod) {
+	if utils.IsPodReady(pod) || pod.DeletionTimestamp != nil {
+		return
+	}
+	if c.startingPods == nil {
+		c.startingPods = map[string]struct{}{}
+	}
+	c.startingPods[pod.Name] = struct{}{}
+}
+
+// observeColdStartLocked records the cold start of a tracked pod once its engine serves.
+func (c *Cache) observeColdStartLocked(pod *v1.Pod, now time.Time) {
+	if _, ok := c.startingPods[pod.Name]; !ok || !c.isEngineReadyLocked(pod) {
+		return
+	}
+	delete(c.startingPods, pod.Name)
+
+	modelName := pod.Labels[modelIdentifier]
+	sample := coldStartSample(pod, now)
+	podColdStartDuration.WithLabelValues(modelName, "scheduling").Observe(sample.SchedulingSeconds)
+	podColdStartDuration.WithLabelValues(modelName, "startup").Observe(sample.StartupSeconds)
+	podColdStartDuration.WithLabelValues(modelName, "loading").Observe(sample.LoadingSeconds)
+	podColdStartDuration.WithLabelValues(modelName, "total").Observe(sample.TotalSeconds)
+	klog.InfoS("pod cold start", "pod", pod.Name, "model", modelName, "scheduling", sample.SchedulingSeconds,
+		"startup", sample.StartupSeconds, "loading", sample.LoadingSeconds, "total", sample.TotalSeconds)
+	if c.kvStore != nil {
+		go saveColdStart(c.kvStore, modelName, sample)
+	}
+}
+
+// coldStartSample splits the time from creating the pod to its engine serving at now into phases, phases whose end
+// was not reported count as zero.
+func coldStartSample(pod *v1.Pod, now time.Time) profiling.ColdStartSample {
+	created := pod.CreationTimestamp.Time
+	scheduled := created
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionTrue && condition.LastTransitionTime.After(created) {
+			scheduled = condition.LastTransitionTime.Time
+		}
+	}
+	started := scheduled
+	for _, status := range pod.Status.ContainerStatuses {
+		if running := status.State.Running; running != nil && running.StartedAt.After(started) {
+			started = running.StartedAt.Time
+		}
+	}
+	if now.Before(started) {
+		now = started
+	}
+	return profiling.ColdStartSample{
+		Pod:               pod.Name,
+		SchedulingSeconds: scheduled.Sub(created).Seconds(),
+		StartupSeconds:    started.Sub(scheduled).Seconds(),
+		LoadingSeconds:    now.Sub(started).Seconds(),
+		TotalSeconds:      now.Sub(created).Seconds(),
+		Serving:           now,
+	}
+}
+
+// saveColdStart adds the sample to the cold start estimate of the model in the kv store, where the gateway replicas
+// merge their samples and the autoscaler reads it.
+func saveColdStart(store kvstore.Store, modelName string, sample profiling.ColdStartSample) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	coldStart := &profiling.ColdStart{Model: modelName}
+	if stored, err := profiling.LoadColdStart(ctx, store, modelName); err == nil {
+		coldStart = stored
+	} else if !errors.Is(err, kvstore.ErrNotFound) {
+		klog.ErrorS(err, "failed to read the cold start estimate", "model", modelName)
+	}
+	coldStart.Add(sample)
+	coldStart.Updated = time.Now()
+	if err := profiling.SaveColdStart(ctx, store, *coldStart); err != nil {
+		klog.ErrorS(err, "failed to write the cold start estimate", "model", modelName)
+		return
+	}
+	modelColdStartEstimate.WithLabelValues(modelName).Set(coldStart.Seconds)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/profiling"
+)
+
+var _ = Describe("ColdStart", func() {
+	var (
+		cache   *Cache
+		store   *kvstore.MemoryStore
+		created time.Time
+	)
+
+	BeforeEach(func() {
+		store = kvstore.NewMemoryStore()
+		cache = &Cache{
+			pods:              map[string]*v1.Pod{},
+			podToModelMapping: map[string]map[string]struct{}{},
+			modelToPodMapping: map[string]map[string]*v1.Pod{},
+			engineHealth:      map[string]*engineHealth{},
+			kvStore:           store,
+		}
+		created = time.Now().Add(-5 * time.Minute)
+	})
+
+	starting := func(name string) *v1.Pod {
+		pod := newHealthTestPod(name, false)
+		pod.CreationTimestamp = metav1.NewTime(created)
+		pod.Status.Conditions = append(pod.Status.Conditions, v1.PodCondition{
+			Type: v1.PodScheduled, Status: v1.ConditionTrue, LastTransitionTime: metav1.NewTime(created.Add(10 * time.Second)),
+		})
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{{State: v1.ContainerState{
+			Running: &v1.ContainerStateRunning{StartedAt: metav1.NewTime(created.Add(70 * time.Second))},
+		}}}
+		return pod
+	}
+
+	ready := func(pod *v1.Pod) *v1.Pod {
+		pod = pod.DeepCopy()
+		pod.Status.Conditions[0].Status = v1.ConditionTrue
+		return pod
+	}
+
+	It("should split the cold start of a new pod in phases once its engine serves", func() {
+		sample := coldStartSample(starting("p1"), created.Add(4*time.Minute))
+		Expect(sample.SchedulingSeconds).To(Equal(10.0))
+		Expect(sample.StartupSeconds).To(Equal(60.0))
+		Expect(sample.LoadingSeconds).To(Equal(170.0))
+		Expect(sample.TotalSeconds).To(Equal(240.0))
+
+		pod := starting("p1")
+		cache.addPod(pod)
+		cache.updatePod(pod, ready(pod))
+		Expect(cache.startingPods).To(HaveKey("p1"))
+
+		cache.recordScrapeLocked("p1", nil)
+		Expect(cache.startingPods).To(BeEmpty())
+		Eventually(func() float64 {
+			coldStart, err := profiling.LoadColdStart(context.Background(), store, "llama-7b")
+			if err != nil {
+				return 0
+			}
+			return coldStart.Seconds
+		}).Should(BeNumerically(">=", 300))
+	})
+
+	It("should not measure pods already ready", func() {
+		cache.addPod(ready(starting("p1")))
+		cache.recordScrapeLocked("p1", nil)
+		Expect(cache.startingPods).To(BeEmpty())
+		_, err := profiling.LoadColdStart(context.Background(), store, "llama-7b")
+		Expect(err).To(MatchError(kvstore.ErrNotFound))
+	})
+})
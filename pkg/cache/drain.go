@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// PodDrainAnnotation is set by the PodAutoscaler on the pods it removes on scale-down, they are drained like
+	// terminating pods until the target is scaled down.
+	PodDrainAnnotation = "autoscaling.aibrix.ai/drain"
+
+	drainOutcomeCompleted = "completed"
+	drainOutcomeDeleted   = "deleted"
+	drainOutcomeCancelled = "cancelled"
+)
+
+var (
+	podDrainDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "aibrix_gateway_pod_drain_duration_seconds",
+		Help:    "Time from a pod entering Terminating or being marked for scale-down until its in-flight requests completed, the pod was deleted or the scale-down cancelled.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"outcome"})
+	podsDraining = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "aibrix_gateway_pods_draining",
+		Help: "Number of terminating or scale-down pods with in-flight requests.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(podDrainDuration, podsDraining)
+}
+
+// podDrain tracks a pod from entering Terminating, or being marked for scale-down, until its in-flight requests completed.
+type podDrain struct {
+	start     time.Time
+	completed bool
+}
+
+// drainRequested returns true if the pod is terminating or marked for scale-down.
+func drainRequested(pod *v1.Pod) bool {
+	_, marked := pod.Annotations[PodDrainAnnotation]
+	return marked || pod.DeletionTimestamp != nil
+}
+
+// markDrainingLocked starts draining the pod if it is terminating or marked for scale-down, and stops draining it if
+// the mark was removed because the scale-down was cancelled. Draining pods are not routing candidates anymore.
+func (c *Cache) markDrainingLocked(pod *v1.Pod) {
+	if !drainRequested(pod) {
+		if drain, ok := c.drainingPods[pod.Name]; ok {
+			c.completeDrainLocked(pod.Name, drain, drainOutcomeCancelled)
+			delete(c.drainingPods, pod.Name)
+		}
+		return
+	}
+	if _, ok := c.drainingPods[pod.Name]; ok {
+		return
+	}
+	if c.drainingPods == nil {
+		c.drainingPods = map[string]*podDrain{}
+	}
+	drain := &podDrain{start: time.Now()}
+	c.drainingPods[pod.Name] = drain
+	podsDraining.Inc()
+
+	inflight := c.GetPodInflightRequests(pod.Name)
+	klog.InfoS("pod draining", "pod", pod.Name, "inflightRequests", inflight)
+	if inflight == 0 {
+		c.completeDrainLocked(pod.Name, drain, drainOutcomeCompleted)
+	}
+}
+
+func (c *Cache) completeDrainLocked(podName string, drain *podDrain, outcome string) {
+	if drain.completed {
+		return
+	}
+	drain.completed = true
+	duration := time.Since(drain.start)
+	podsDraining.Dec()
+	podDrainDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+	klog.InfoS("pod drain finished", "pod", podName, "outcome", outcome, "duration", duration)
+}
+
+// forgetDrainLocked drops the drain state of a deleted pod, recording an unfinished drain.
+func (c *Cache) forgetDrainLocked(podName string) {
+	if drain, ok := c.drainingPods[podName]; ok {
+		c.completeDrainLocked(podName, drain, drainOutcomeDeleted)
+		delete(c.drainingPods, podName)
+	}
+	c.podRequests.Delete(podName)
+	c.podBatchItems.Delete(podName)
+}
+
+func (c *Cache) isDrainingLocked(pod *v1.Pod) bool {
+	_, ok := c.drainingPods[pod.Name]
+	return ok || drainRequested(pod)
+}
+
+// AddPodRequest counts a request routed to the pod until DonePodRequest is called.
+func (c *Cache) AddPodRequest(podName string) {
+	newCounter := int32(0)
+	pCounter, _ := c.podRequests.LoadOrStore(podName, &newCounter)
+	atomic.AddInt32(pCounter.(*int32), 1)
+}
+
+// DonePodRequest completes a request routed to the pod, finishing the drain of a terminating pod with its last request.
+func (c *Cache) DonePodRequest(podName string) {
+	pCounter, ok := c.podRequests.Load(podName)
+	if !ok || atomic.AddInt32(pCounter.(*int32), -1) > 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if drain, ok := c.drainingPods[podName]; ok {
+		c.completeDrainLocked(podName, drain, drainOutcomeCompleted)
+	}
+}
+
+// GetPodInflightRequests returns the number of requests the gateway routed to the pod that did not complete yet.
+func (c *Cache) GetPodInflightRequests(podName string) int32 {
+	if pCounter, ok := c.podRequests.Load(podName); ok {
+		return atomic.LoadInt32(pCounter.(*int32))
+	}
+	return 0
+}
+
+// AddPodBatchItems counts the inputs of a request routed to the pod until DonePodBatchItems is called, e.g. the texts
+// of an embedding request or the documents of a rerank request. Engines serve each input as a request.
+func (c *Cache) AddPodBatchItems(podName string, items int32) {
+	newCounter := int32(0)
+	pCounter, _ := c.podBatchItems.LoadOrStore(podName, &newCounter)
+	atomic.AddInt32(pCounter.(*int32), items)
+}
+
+// DonePodBatchItems completes the inputs of a request routed to the pod.
+func (c *Cache) DonePodBatchItems(podName string, items int32) {
+	if pCounter, ok := c.podBatchItems.Load(podName); ok {
+		atomic.AddInt32(pCounter.(*int32), -items)
+	}
+}
+
+// GetPodInflightBatchItems returns the number of inputs of the requests the gateway routed to the pod that did not
+// complete yet.
+func (c *Cache) GetPodInflightBatchItems(podName string) int32 {
+	if pCounter, ok := c.podBatchItems.Load(podName); ok {
+		return atomic.LoadInt32(pCounter.(*int32))
+	}
+	return 0
+}
+
+// IsPodDraining returns true if the pod is terminating or marked for scale-down.
+func (c *Cache) IsPodDraining(podName string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pod, ok := c.pods[podName]
+	return ok && c.isDrainingLocked(pod)
+}
+
+// IsPodDrained returns true once a draining pod has no in-flight requests left, e.g. to end a preStop hook early.
+func (c *Cache) IsPodDrained(podName string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	drain, ok := c.drainingPods[podName]
+	return ok && drain.completed
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("PodDrain", func() {
+	var cache *Cache
+
+	terminating := func(pod *v1.Pod) *v1.Pod {
+		pod = pod.DeepCopy()
+		now := metav1.Now()
+		pod.DeletionTimestamp = &now
+		return pod
+	}
+
+	BeforeEach(func() {
+		pod := newHealthTestPod("p1", true)
+		cache = &Cache{
+			pods:              map[string]*v1.Pod{"p1": pod},
+			podToModelMapping: map[string]map[string]struct{}{"p1": {"llama-7b": {}}},
+			modelToPodMapping: map[string]map[string]*v1.Pod{"llama-7b": {"p1": pod}},
+			engineHealth:      map[string]*engineHealth{},
+		}
+		cache.recordScrapeLocked("p1", nil)
+	})
+
+	It("should stop routing to terminating pods and complete the drain with the last request", func() {
+		cache.AddPodRequest("p1")
+		cache.AddPodRequest("p1")
+		pod := cache.pods["p1"]
+		cache.updatePod(pod, terminating(pod))
+
+		Expect(cache.IsPodDraining("p1")).To(BeTrue())
+		Expect(cache.IsEngineReady("p1")).To(BeFalse())
+		pods, err := cache.GetReadyPodsForModel("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pods).To(BeEmpty())
+
+		cache.DonePodRequest("p1")
+		Expect(cache.IsPodDrained("p1")).To(BeFalse())
+		Expect(cache.GetPodInflightRequests("p1")).To(Equal(int32(1)))
+
+		cache.DonePodRequest("p1")
+		Expect(cache.IsPodDrained("p1")).To(BeTrue())
+	})
+
+	It("should complete the drain immediately without in-flight requests", func() {
+		pod := cache.pods["p1"]
+		cache.updatePod(pod, terminating(pod))
+		Expect(cache.IsPodDrained("p1")).To(BeTrue())
+	})
+
+	It("should drain pods marked for scale-down until the mark is removed", func() {
+		cache.AddPodRequest("p1")
+		pod := cache.pods["p1"]
+		marked := pod.DeepCopy()
+		marked.Annotations = map[string]string{PodDrainAnnotation: "2025-01-01T00:00:00Z"}
+		cache.updatePod(pod, marked)
+
+		Expect(cache.IsPodDraining("p1")).To(BeTrue())
+		Expect(cache.IsEngineReady("p1")).To(BeFalse())
+		Expect(cache.IsPodDrained("p1")).To(BeFalse())
+
+		cache.updatePod(marked, pod)
+		Expect(cache.IsPodDraining("p1")).To(BeFalse())
+		Expect(cache.IsEngineReady("p1")).To(BeTrue())
+		Expect(cache.drainingPods).To(BeEmpty())
+		cache.DonePodRequest("p1")
+	})
+
+	It("should count the inputs of batch requests", func() {
+		cache.AddPodBatchItems("p1", 16)
+		cache.AddPodBatchItems("p1", 1)
+		Expect(cache.GetPodInflightBatchItems("p1")).To(Equal(int32(17)))
+		cache.DonePodBatchItems("p1", 16)
+		Expect(cache.GetPodInflightBatchItems("p1")).To(Equal(int32(1)))
+		Expect(cache.GetPodInflightBatchItems("p2")).To(BeZero())
+	})
+
+	It("should forget drain state of deleted pods", func() {
+		cache.AddPodRequest("p1")
+		cache.AddPodBatchItems("p1", 4)
+		pod := terminating(cache.pods["p1"])
+		cache.updatePod(cache.pods["p1"], pod)
+		cache.deletePod(pod)
+
+		Expect(cache.drainingPods).To(BeEmpty())
+		Expect(cache.GetPodInflightRequests("p1")).To(BeZero())
+		Expect(cache.GetPodInflightBatchItems("p1")).To(BeZero())
+		cache.DonePodRequest("p1")
+	})
+})
@@ -0,0 +1,710 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakecache provides an in-memory cache.Interface for the unit tests of the routers, the autoscaler and the
+// controllers, without informers, engines or Redis.
+package fakecache
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/prefixcacheindexer"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// Cache is a cache.Interface holding what the test sets, it doesn't scrape, probe or expire anything. The engine of a
+// pod is ready when the pod is Ready, unless set otherwise with WithEngineNotReady or SetEngineReady.
+type Cache struct {
+	mu                  sync.Mutex
+	pods                map[string]*v1.Pod                                   // pod_name: *v1.Pod
+	modelToPods         map[string]map[string]*v1.Pod                        // model_name: map[pod_name]*v1.Pod
+	podMetrics          map[string]map[string]metrics.MetricValue            // pod_name: map[metric_name]metric_val
+	podModelMetrics     map[string]map[string]map[string]metrics.MetricValue // pod_name: map[model_name]map[metric_name]metric_val
+	modelInfo           map[string]*cache.ModelInfo                          // model_name: *ModelInfo
+	modelAdapters       map[string]*modelv1alpha1.ModelAdapter               // model_name: *ModelAdapter
+	topology            map[string]cache.Topology                            // pod_name: Topology
+	engineNotReady      map[string]bool                                      // pod_name: true
+	draining            map[string]bool                                      // pod_name: true
+	inflightRequests    map[string]int32                                     // pod_name: requests
+	inflightBatchItems  map[string]int32                                     // pod_name: items
+	federatedClusters   []cache.ClusterSummary
+	watches             []*metricWatch
+	prefixCacheIndexer  prefixcacheindexer.PrefixCacheIndexer
+	kvStore             kvstore.Store
+	subscribers         []metrics.MetricSubscriber
+	checkpointers       map[string]cache.Checkpointer
+	ownershipProviders  []cache.PodOwnershipProvider
+	notLeader           bool
+	pendingRequestCount map[string]int // model_name: requests
+}
+
+var _ cache.Interface = (*Cache)(nil)
+
+// Option configures the fake cache returned by New.
+type Option func(*Cache)
+
+// New returns a fake cache configured by the options.
+func New(opts ...Option) *Cache {
+	c := &Cache{
+		pods:                map[string]*v1.Pod{},
+		modelToPods:         map[string]map[string]*v1.Pod{},
+		podMetrics:          map[string]map[string]metrics.MetricValue{},
+		podModelMetrics:     map[string]map[string]map[string]metrics.MetricValue{},
+		modelInfo:           map[string]*cache.ModelInfo{},
+		modelAdapters:       map[string]*modelv1alpha1.ModelAdapter{},
+		topology:            map[string]cache.Topology{},
+		engineNotReady:      map[string]bool{},
+		draining:            map[string]bool{},
+		inflightRequests:    map[string]int32{},
+		inflightBatchItems:  map[string]int32{},
+		prefixCacheIndexer:  prefixcacheindexer.NewPrefixHashTable(),
+		checkpointers:       map[string]cache.Checkpointer{},
+		pendingRequestCount: map[string]int{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithPods adds the pods serving the model.
+func WithPods(model string, pods ...*v1.Pod) Option {
+	return func(c *Cache) {
+		for _, pod := range pods {
+			c.SetPod(pod, model)
+		}
+	}
+}
+
+// WithMetrics sets metrics of the pod, metric_name: value.
+func WithMetrics(podName string, values map[string]metrics.MetricValue) Option {
+	return func(c *Cache) {
+		for metricName, value := range values {
+			c.SetPodMetric(podName, metricName, value)
+		}
+	}
+}
+
+// WithModelMetrics sets metrics of the model on the pod, metric_name: value.
+func WithModelMetrics(podName, model string, values map[string]metrics.MetricValue) Option {
+	return func(c *Cache) {
+		for metricName, value := range values {
+			c.SetPodModelMetric(podName, model, metricName, value)
+		}
+	}
+}
+
+// WithPrefixBlocks indexes the prompts, as tokens, on the pod in the prefix cache indexer returned by
+// PrefixCacheIndexer, as if the pod served them.
+func WithPrefixBlocks(model, podName string, prompts ...[]int) Option {
+	return func(c *Cache) {
+		for _, tokens := range prompts {
+			c.prefixCacheIndexer.AddPrefix(tokens, model, podName)
+		}
+	}
+}
+
+// WithModelInfo sets the metadata of the model.
+func WithModelInfo(model string, info cache.ModelInfo) Option {
+	return func(c *Cache) {
+		c.modelInfo[model] = &info
+	}
+}
+
+// WithModelAdapter adds the lora adapter, its pods are added with WithPods.
+func WithModelAdapter(adapter *modelv1alpha1.ModelAdapter) Option {
+	return func(c *Cache) {
+		c.modelAdapters[adapter.Name] = adapter
+	}
+}
+
+// WithTopology sets the topology of the pod.
+func WithTopology(podName string, topology cache.Topology) Option {
+	return func(c *Cache) {
+		c.topology[podName] = topology
+	}
+}
+
+// WithEngineNotReady marks the engine of the pods unhealthy.
+func WithEngineNotReady(podNames ...string) Option {
+	return func(c *Cache) {
+		for _, podName := range podNames {
+			c.SetEngineReady(podName, false)
+		}
+	}
+}
+
+// WithDrainingPods marks the pods draining.
+func WithDrainingPods(podNames ...string) Option {
+	return func(c *Cache) {
+		for _, podName := range podNames {
+			c.SetPodDraining(podName, true)
+		}
+	}
+}
+
+// WithKVStore sets the kv store returned by KVStore.
+func WithKVStore(store kvstore.Store) Option {
+	return func(c *Cache) {
+		c.kvStore = store
+	}
+}
+
+// WithFollower makes IsLeader return false.
+func WithFollower() Option {
+	return func(c *Cache) {
+		c.notLeader = true
+	}
+}
+
+// PrefixCacheIndexer returns the prefix cache indexer filled by WithPrefixBlocks, to build prefix aware routers on.
+func (c *Cache) PrefixCacheIndexer() prefixcacheindexer.PrefixCacheIndexer {
+	return c.prefixCacheIndexer
+}
+
+// SetEngineReady sets whether the engine of the pod is healthy.
+func (c *Cache) SetEngineReady(podName string, ready bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.engineNotReady[podName] = !ready
+}
+
+// SetPodDraining sets whether the pod is draining.
+func (c *Cache) SetPodDraining(podName string, draining bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.draining[podName] = draining
+}
+
+// PendingRequests returns the requests of the model counted by AddRequestCount and not done yet.
+func (c *Cache) PendingRequests(model string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.pendingRequestCount[model]
+}
+
+// Checkpointers returns the checkpointers registered with AddCheckpointer, name: Checkpointer.
+func (c *Cache) Checkpointers() map[string]cache.Checkpointer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return maps.Clone(c.checkpointers)
+}
+
+// OwnershipProviders returns the providers registered with AddOwnershipProvider.
+func (c *Cache) OwnershipProviders() []cache.PodOwnershipProvider {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]cache.PodOwnershipProvider(nil), c.ownershipProviders...)
+}
+
+func (c *Cache) SetPod(pod *v1.Pod, modelName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pods[pod.Name] = pod
+	if c.modelToPods[modelName] == nil {
+		c.modelToPods[modelName] = map[string]*v1.Pod{}
+	}
+	c.modelToPods[modelName][pod.Name] = pod
+}
+
+func (c *Cache) SetPodMetric(podName, metricName string, value metrics.MetricValue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.podMetrics[podName] == nil {
+		c.podMetrics[podName] = map[string]metrics.MetricValue{}
+	}
+	c.podMetrics[podName][metricName] = value
+	c.notifyLocked(podName)
+}
+
+func (c *Cache) SetPodModelMetric(podName, modelName, metricName string, value metrics.MetricValue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.podModelMetrics[podName] == nil {
+		c.podModelMetrics[podName] = map[string]map[string]metrics.MetricValue{}
+	}
+	if c.podModelMetrics[podName][modelName] == nil {
+		c.podModelMetrics[podName][modelName] = map[string]metrics.MetricValue{}
+	}
+	c.podModelMetrics[podName][modelName][metricName] = value
+	c.notifyLocked(podName)
+}
+
+func (c *Cache) GetPod(podName string) (*v1.Pod, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pod, ok := c.pods[podName]
+	if !ok {
+		return nil, fmt.Errorf("pod does not exist in the cache: %s", podName)
+	}
+	return pod, nil
+}
+
+func (c *Cache) GetPods() map[string]*v1.Pod {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return maps.Clone(c.pods)
+}
+
+func (c *Cache) GetPodsForModel(modelName string) (map[string]*v1.Pod, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pods, ok := c.modelToPods[modelName]
+	if !ok {
+		return nil, fmt.Errorf("model does not exist in the cache: %s", modelName)
+	}
+	return maps.Clone(pods), nil
+}
+
+func (c *Cache) GetReadyPodsForModel(modelName string) (map[string]*v1.Pod, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pods, ok := c.modelToPods[modelName]
+	if !ok {
+		return nil, fmt.Errorf("model does not exist in the cache: %s", modelName)
+	}
+	readyPods := make(map[string]*v1.Pod, len(pods))
+	for name, pod := range pods {
+		if c.isEngineReadyLocked(pod) && !c.draining[name] {
+			readyPods[name] = pod
+		}
+	}
+	return readyPods, nil
+}
+
+func (c *Cache) GetLentPodsForModel(modelName string) map[string]*v1.Pod {
+	return map[string]*v1.Pod{}
+}
+
+func (c *Cache) GetModelsForPod(podName string) (map[string]struct{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.pods[podName]; !ok {
+		return nil, fmt.Errorf("pod does not exist in the cache: %s", podName)
+	}
+	models := map[string]struct{}{}
+	for model, pods := range c.modelToPods {
+		if _, ok := pods[podName]; ok {
+			models[model] = struct{}{}
+		}
+	}
+	return models, nil
+}
+
+func (c *Cache) GetModels() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	models := make([]string, 0, len(c.modelToPods))
+	for model := range c.modelToPods {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+func (c *Cache) CheckModelExists(modelName string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.modelToPods[modelName]
+	return ok
+}
+
+func (c *Cache) GetModelAdapter(modelName string) (*modelv1alpha1.ModelAdapter, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	adapter, ok := c.modelAdapters[modelName]
+	if !ok {
+		return nil, fmt.Errorf("model adapter does not exist in the cache: %s", modelName)
+	}
+	return adapter, nil
+}
+
+func (c *Cache) GetModelInfo(modelName string) (*cache.ModelInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, ok := c.modelInfo[modelName]
+	if !ok {
+		return nil, fmt.Errorf("model does not exist in the cache: %s", modelName)
+	}
+	copied := *info
+	return &copied, nil
+}
+
+func (c *Cache) GetPodMaxModelLen(podName, modelName string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if info, ok := c.modelInfo[modelName]; ok {
+		return info.MaxModelLen
+	}
+	return 0
+}
+
+func (c *Cache) GetPodCapabilities(podName string) []string {
+	return nil
+}
+
+func (c *Cache) GetPodSnapshots() []cache.PodSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshots := make([]cache.PodSnapshot, 0, len(c.pods))
+	for name, pod := range c.pods {
+		snapshot := cache.PodSnapshot{
+			Pod:          pod,
+			EngineReady:  c.isEngineReadyLocked(pod),
+			Draining:     c.draining[name],
+			Inflight:     c.inflightRequests[name],
+			Topology:     c.topology[name],
+			Metrics:      maps.Clone(c.podMetrics[name]),
+			ModelMetrics: map[string]map[string]metrics.MetricValue{},
+		}
+		if snapshot.Metrics == nil {
+			snapshot.Metrics = map[string]metrics.MetricValue{}
+		}
+		for model, pods := range c.modelToPods {
+			if _, ok := pods[name]; ok {
+				snapshot.Models = append(snapshot.Models, model)
+			}
+		}
+		sort.Strings(snapshot.Models)
+		for model, modelMetrics := range c.podModelMetrics[name] {
+			snapshot.ModelMetrics[model] = maps.Clone(modelMetrics)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Pod.Name < snapshots[j].Pod.Name })
+	return snapshots
+}
+
+// GetScaleDownVictims ranks the pods of the model by serving state then inflight requests, it ignores the prefix
+// ownership.
+func (c *Cache) GetScaleDownVictims(modelName string) ([]cache.ScaleDownCandidate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pods, ok := c.modelToPods[modelName]
+	if !ok {
+		return nil, fmt.Errorf("model does not exist in the cache: %s", modelName)
+	}
+	candidates := make([]cache.ScaleDownCandidate, 0, len(pods))
+	for name, pod := range pods {
+		inflight := float64(c.inflightRequests[name])
+		candidates = append(candidates, cache.ScaleDownCandidate{
+			PodName:          name,
+			InflightRequests: inflight,
+			Serving:          c.isEngineReadyLocked(pod) && !c.draining[name],
+			Score:            inflight,
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Serving != candidates[j].Serving {
+			return !candidates[i].Serving
+		}
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score < candidates[j].Score
+		}
+		return candidates[i].PodName < candidates[j].PodName
+	})
+	return candidates, nil
+}
+
+func (c *Cache) GetWarmNodes(modelName string) []string {
+	return nil
+}
+
+func (c *Cache) GetPodTopology(podName string) (cache.Topology, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	topology, ok := c.topology[podName]
+	return topology, ok
+}
+
+func (c *Cache) GetNodeTopology(nodeName string) (cache.Topology, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, topology := range c.topology {
+		if topology.Node == nodeName {
+			return topology, true
+		}
+	}
+	return cache.Topology{}, false
+}
+
+func (c *Cache) IsEngineReady(podName string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pod, ok := c.pods[podName]
+	return ok && c.isEngineReadyLocked(pod)
+}
+
+func (c *Cache) isEngineReadyLocked(pod *v1.Pod) bool {
+	return utils.IsPodReady(pod) && !c.engineNotReady[pod.Name]
+}
+
+func (c *Cache) IsPodDraining(podName string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.draining[podName]
+}
+
+func (c *Cache) IsPodDrained(podName string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.draining[podName] && c.inflightRequests[podName] == 0
+}
+
+func (c *Cache) GetPodMetric(podName, metricName string) (metrics.MetricValue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	podMetrics, ok := c.podMetrics[podName]
+	if !ok {
+		return nil, fmt.Errorf("pod does not exist in the podMetrics cache")
+	}
+	metricVal, ok := podMetrics[metricName]
+	if !ok {
+		return nil, fmt.Errorf("no metric available for %v", metricName)
+	}
+	return metricVal, nil
+}
+
+func (c *Cache) GetPodModelMetric(podName, modelName string, metricName string) (metrics.MetricValue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modelMetrics, ok := c.podModelMetrics[podName][modelName]
+	if !ok {
+		return nil, fmt.Errorf("pod does not exist in the podMetrics cache")
+	}
+	metricVal, ok := modelMetrics[metricName]
+	if !ok {
+		return nil, fmt.Errorf("no metric available for %v", metricName)
+	}
+	return metricVal, nil
+}
+
+func (c *Cache) GetKVCacheEfficiency(modelName string) (*cache.KVCacheEfficiency, error) {
+	return nil, fmt.Errorf("no pod of model %s reports KV offloading metrics", modelName)
+}
+
+// PushPodMetrics sets the pushed metrics as simple metric values.
+func (c *Cache) PushPodMetrics(push cache.MetricPush) error {
+	if push.Pod == "" {
+		return fmt.Errorf("pod is required")
+	}
+	for metricName, value := range push.Metrics {
+		if push.Model != "" {
+			c.SetPodModelMetric(push.Pod, push.Model, metricName, &metrics.SimpleMetricValue{Value: value})
+		} else {
+			c.SetPodMetric(push.Pod, metricName, &metrics.SimpleMetricValue{Value: value})
+		}
+	}
+	return nil
+}
+
+type metricWatch struct {
+	pod       string
+	metric    string
+	threshold float64
+	above     bool
+	events    chan cache.MetricThresholdEvent
+}
+
+// WatchPodMetric notifies the crossings of the threshold by the simple metric of the pod set afterwards, the first
+// event once it is above the threshold. Events are dropped if the channel is full.
+func (c *Cache) WatchPodMetric(ctx context.Context, podName, metricName string, threshold float64) <-chan cache.MetricThresholdEvent {
+	watch := &metricWatch{pod: podName, metric: metricName, threshold: threshold, events: make(chan cache.MetricThresholdEvent, 16)}
+	c.mu.Lock()
+	c.watches = append(c.watches, watch)
+	c.notifyLocked(podName)
+	c.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, w := range c.watches {
+			if w == watch {
+				c.watches = append(c.watches[:i], c.watches[i+1:]...)
+				break
+			}
+		}
+		close(watch.events)
+	}()
+	return watch.events
+}
+
+func (c *Cache) notifyLocked(podName string) {
+	for _, watch := range c.watches {
+		if watch.pod != podName {
+			continue
+		}
+		metricVal, ok := c.podMetrics[podName][watch.metric]
+		if !ok {
+			continue
+		}
+		value := metricVal.GetSimpleValue()
+		if above := value > watch.threshold; above != watch.above {
+			watch.above = above
+			select {
+			case watch.events <- cache.MetricThresholdEvent{Pod: podName, Metric: watch.metric, Value: value, Threshold: watch.threshold, Above: above, Time: time.Now()}:
+			default:
+			}
+		}
+	}
+}
+
+func (c *Cache) AddSubscriber(subscriber metrics.MetricSubscriber) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.subscribers = append(c.subscribers, subscriber)
+}
+
+func (c *Cache) AddRequestCount(requestID string, modelName string) (traceTerm int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pendingRequestCount[modelName]++
+	return 0
+}
+
+func (c *Cache) DoneRequestCount(requestID string, modelName string, traceTerm int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pendingRequestCount[modelName]--
+}
+
+func (c *Cache) AddRequestTrace(requestID string, modelName string, inputTokens, outputTokens int64) {
+}
+
+func (c *Cache) DoneRequestTrace(requestID string, modelName string, inputTokens, outputTokens, traceTerm int64) {
+	c.DoneRequestCount(requestID, modelName, traceTerm)
+}
+
+func (c *Cache) AddPodRequest(podName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inflightRequests[podName]++
+}
+
+func (c *Cache) DonePodRequest(podName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inflightRequests[podName]--
+}
+
+func (c *Cache) GetPodInflightRequests(podName string) int32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.inflightRequests[podName]
+}
+
+func (c *Cache) AddPodBatchItems(podName string, items int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inflightBatchItems[podName] += items
+}
+
+func (c *Cache) DonePodBatchItems(podName string, items int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inflightBatchItems[podName] -= items
+}
+
+func (c *Cache) GetPodInflightBatchItems(podName string) int32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.inflightBatchItems[podName]
+}
+
+func (c *Cache) GetPodRemoteInflightBatchItems(podName string) int32 {
+	return 0
+}
+
+func (c *Cache) UpdateFederatedClusters(summaries []cache.ClusterSummary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.federatedClusters = summaries
+}
+
+func (c *Cache) GetFederatedClusters(modelName string) []cache.FederatedCluster {
+	return nil
+}
+
+func (c *Cache) IsLeader() bool {
+	return !c.notLeader
+}
+
+func (c *Cache) KVStore() kvstore.Store {
+	return c.kvStore
+}
+
+func (c *Cache) AddCheckpointer(name string, checkpointer cache.Checkpointer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkpointers[name] = checkpointer
+}
+
+func (c *Cache) AddOwnershipProvider(provider cache.PodOwnershipProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ownershipProviders = append(c.ownershipProviders, provider)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakecache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+func newPod(name string, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     v1.PodStatus{Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}}},
+	}
+}
+
+func TestCache(t *testing.T) {
+	c := New(
+		WithPods("llama-7b", newPod("p1", true), newPod("p2", true), newPod("p3", false), newPod("p4", true)),
+		WithMetrics("p1", map[string]metrics.MetricValue{metrics.NumRequestsWaiting: &metrics.SimpleMetricValue{Value: 3}}),
+		WithModelMetrics("p1", "llama-7b", map[string]metrics.MetricValue{metrics.NumRequestsRunning: &metrics.SimpleMetricValue{Value: 5}}),
+		WithEngineNotReady("p2"),
+		WithDrainingPods("p4"),
+	)
+
+	pods, err := c.GetPodsForModel("llama-7b")
+	require.NoError(t, err)
+	assert.Len(t, pods, 4)
+	ready, err := c.GetReadyPodsForModel("llama-7b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p1"}, keys(ready))
+	_, err = c.GetPodsForModel("qwen-7b")
+	assert.Error(t, err)
+
+	waiting, err := c.GetPodMetric("p1", metrics.NumRequestsWaiting)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, waiting.GetSimpleValue())
+	running, err := c.GetPodModelMetric("p1", "llama-7b", metrics.NumRequestsRunning)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, running.GetSimpleValue())
+	_, err = c.GetPodMetric("p2", metrics.NumRequestsWaiting)
+	assert.Error(t, err)
+
+	c.AddPodRequest("p4")
+	assert.False(t, c.IsPodDrained("p4"))
+	c.DonePodRequest("p4")
+	assert.True(t, c.IsPodDrained("p4"))
+}
+
+func TestWatchPodMetric(t *testing.T) {
+	c := New(WithPods("llama-7b", newPod("p1", true)))
+	ctx, cancel := context.WithCancel(context.Background())
+	events := c.WatchPodMetric(ctx, "p1", metrics.NumRequestsWaiting, 4)
+
+	c.SetPodMetric("p1", metrics.NumRequestsWaiting, &metrics.SimpleMetricValue{Value: 2})
+	c.SetPodMetric("p1", metrics.NumRequestsWaiting, &metrics.SimpleMetricValue{Value: 6})
+	c.SetPodMetric("p1", metrics.NumRequestsWaiting, &metrics.SimpleMetricValue{Value: 1})
+	event := <-events
+	assert.True(t, event.Above)
+	assert.Equal(t, 6.0, event.Value)
+	event = <-events
+	assert.False(t, event.Above)
+
+	cancel()
+	_, ok := <-events
+	assert.False(t, ok)
+}
+
+func keys(pods map[string]*v1.Pod) []string {
+	names := make([]string, 0, len(pods))
+	for name := range pods {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultFederationIntervalInMS = 2000
+	// federationKeyPrefix is the prefix of the keys the clusters write their summaries to.
+	federationKeyPrefix = "aibrix:federation:cluster:"
+	// the summary of a cluster missing federationExpiryIntervals updates expires, e.g. the cluster is down.
+	federationExpiryIntervals = 3
+)
+
+var (
+	federationCluster  = utils.LoadEnv("AIBRIX_FEDERATION_CLUSTER", "")
+	federationAddress  = utils.LoadEnv("AIBRIX_FEDERATION_ADDRESS", "")
+	federationInterval = getFederationInterval()
+
+	federatedClusters = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "aibrix_gateway_federated_clusters",
+		Help: "Number of other clusters whose capacity summary is known to the gateway.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(federatedClusters)
+}
+
+func getFederationInterval() time.Duration {
+	value := utils.LoadEnv("AIBRIX_FEDERATION_INTERVAL_MS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_FEDERATION_INTERVAL_MS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_FEDERATION_INTERVAL_MS env value for federation interval: %d ms", intValue)
+			return time.Duration(intValue) * time.Millisecond
+		}
+	}
+	return defaultFederationIntervalInMS * time.Millisecond
+}
+
+// FederationCluster returns the name of the cluster in the federation, empty if the gateway is not federated.
+func FederationCluster() string {
+	return federationCluster
+}
+
+// ClusterSummary is the capacity and health of the models of a cluster the gateways of the other clusters route on.
+type ClusterSummary struct {
+	Cluster string `json:"cluster"`
+	// Address is the host:port of the gateway of the cluster, the other clusters send their requests to it.
+	Address string                  `json:"address"`
+	Models  map[string]ModelSummary `json:"models,omitempty"`
+}
+
+// ModelSummary is the capacity of a model in a cluster.
+type ModelSummary struct {
+	Pods int32 `json:"pods"`
+	// ReadyPods is the number of pods serving requests, excluding the pods draining, lent or with an unhealthy engine.
+	ReadyPods int32 `json:"ready_pods"`
+	// Inflight is the number of engine requests the gateway replicas of the cluster are waiting for.
+	Inflight int32 `json:"inflight"`
+}
+
+// FederatedCluster is another cluster serving a model.
+type FederatedCluster struct {
+	Cluster   string
+	Address   string
+	ReadyPods int32
+	Inflight  int32
+}
+
+// localClusterSummary returns the summary of the models of the cluster, as seen by the replica.
+func (c *Cache) localClusterSummary(now time.Time) ClusterSummary {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	summary := ClusterSummary{Cluster: federationCluster, Address: federationAddress, Models: map[string]ModelSummary{}}
+	for model, pods := range c.modelToPodMapping {
+		modelSummary := ModelSummary{Pods: int32(len(pods))}
+		for name, pod := range pods {
+			if !c.isEngineReadyLocked(pod) || isLent(pod) {
+				continue
+			}
+			modelSummary.ReadyPods++
+			if items, ok := c.podBatchItems.Load(name); ok {
+				modelSummary.Inflight += atomic.LoadInt32(items.(*int32))
+			}
+			modelSummary.Inflight += c.getPodRemoteInflightBatchItemsLocked(name, now)
+		}
+		summary.Models[model] = modelSummary
+	}
+	return summary
+}
+
+// UpdateFederatedClusters replaces the summaries of the other clusters with summaries exchanged outside of the store,
+// e.g. by a federation service.
+func (c *Cache) UpdateFederatedClusters(summaries []ClusterSummary) {
+	c.setFederatedClusters(summaries, time.Now())
+}
+
+// setFederatedClusters replaces the summaries of the other clusters, the summary of this cluster is ignored. They expire
+// like the summaries in the store if they are not read again, e.g. the store is down.
+func (c *Cache) setFederatedClusters(summaries []ClusterSummary, now time.Time) {
+	clusters := map[string]ClusterSummary{}
+	for _, summary := range summaries {
+		if summary.Cluster != "" && summary.Cluster != federationCluster {
+			clusters[summary.Cluster] = summary
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.federation = clusters
+	c.federationExpires = now.Add(federationExpiryIntervals * federationInterval)
+	federatedClusters.Set(float64(len(clusters)))
+}
+
+// GetFederatedClusters returns the other clusters with ready pods for the model, the least loaded first. It lags
+// behind them by up to a federation interval.
+func (c *Cache) GetFederatedClusters(modelName string) []FederatedCluster {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var clusters []FederatedCluster
+	if !time.Now().Before(c.federationExpires) {
+		return clusters
+	}
+	for name, summary := range c.federation {
+		model, ok := summary.Models[modelName]
+		if !ok || model.ReadyPods == 0 || summary.Address == "" {
+			continue
+		}
+		clusters = append(clusters, FederatedCluster{
+			Cluster: name, Address: summary.Address, ReadyPods: model.ReadyPods, Inflight: model.Inflight,
+		})
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		// compare the in-flight requests per ready pod without dividing.
+		left := int64(clusters[i].Inflight) * int64(clusters[j].ReadyPods)
+		right := int64(clusters[j].Inflight) * int64(clusters[i].ReadyPods)
+		if left != right {
+			return left < right
+		}
+		return clusters[i].Cluster < clusters[j].Cluster
+	})
+	return clusters
+}
+
+// runFederation writes the summary of the cluster to the store every interval and reads the summaries of the other
+// clusters, so that the gateway can send the requests it can't serve to them. The store must be shared by the
+// clusters.
+func (c *Cache) runFederation(store kvstore.Store, stopCh <-chan struct{}) {
+	klog.InfoS("joining cluster federation", "cluster", federationCluster, "address", federationAddress)
+	ticker := time.NewTicker(federationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.syncFederation(store)
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (c *Cache) syncFederation(store kvstore.Store) {
+	ctx, cancel := context.WithTimeout(context.Background(), federationInterval)
+	defer cancel()
+
+	now := time.Now()
+	// the replicas of the cluster write the same summary, the last one wins, or the leader only with leader election.
+	if c.IsLeader() {
+		if summary, err := json.Marshal(c.localClusterSummary(now)); err != nil {
+			klog.ErrorS(err, "failed to marshal cluster summary")
+		} else if err := store.Set(ctx, federationKeyPrefix+federationCluster, summary, federationExpiryIntervals*federationInterval); err != nil {
+			klog.V(4).ErrorS(err, "failed to write cluster summary")
+		}
+	}
+
+	var summaries []ClusterSummary
+	err := store.Scan(ctx, federationKeyPrefix, func(key string, value []byte) error {
+		var summary ClusterSummary
+		if err := json.Unmarshal(value, &summary); err != nil {
+			klog.V(4).ErrorS(err, "ignoring invalid cluster summary", "key", key)
+			return nil
+		}
+		if summary.Cluster == "" {
+			summary.Cluster = strings.TrimPrefix(key, federationKeyPrefix)
+		}
+		summaries = append(summaries, summary)
+		return nil
+	})
+	if err != nil {
+		// keep routing on the last summaries until they expire.
+		klog.V(4).ErrorS(err, "failed to read cluster summaries")
+		return
+	}
+	c.setFederatedClusters(summaries, now)
+}
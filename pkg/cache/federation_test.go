@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+var _ = Describe("Federation", func() {
+	var cache *Cache
+	var cluster, address string
+
+	BeforeEach(func() {
+		cluster, address = federationCluster, federationAddress
+		federationCluster, federationAddress = "us-east", "10.0.0.1:80"
+
+		lent := newHealthTestPod("p3", true)
+		lent.Annotations = map[string]string{PodBatchLoanAnnotation: BatchLoanLent}
+		pods := map[string]*v1.Pod{"p1": newHealthTestPod("p1", true), "p2": newHealthTestPod("p2", false), "p3": lent}
+		cache = &Cache{
+			pods:              pods,
+			modelToPodMapping: map[string]map[string]*v1.Pod{"llama-7b": pods},
+			engineHealth:      map[string]*engineHealth{},
+		}
+		for name := range pods {
+			cache.recordScrapeLocked(name, nil)
+		}
+	})
+
+	AfterEach(func() {
+		federationCluster, federationAddress = cluster, address
+	})
+
+	It("should summarize the ready pods of the models and their load", func() {
+		cache.AddPodBatchItems("p1", 2)
+		cache.AddPodBatchItems("p2", 5)
+		cache.mergeLoadReport(LoadReport{Replica: "gw-1", Pods: map[string]int32{"p1": 1}}, time.Now())
+
+		summary := cache.localClusterSummary(time.Now())
+		Expect(summary.Cluster).To(Equal("us-east"))
+		Expect(summary.Address).To(Equal("10.0.0.1:80"))
+		Expect(summary.Models).To(Equal(map[string]ModelSummary{"llama-7b": {Pods: 3, ReadyPods: 1, Inflight: 3}}))
+	})
+
+	It("should return the other clusters serving the model, the least loaded first", func() {
+		cache.setFederatedClusters([]ClusterSummary{
+			{Cluster: "us-east", Address: "10.0.0.1:80", Models: map[string]ModelSummary{"llama-7b": {Pods: 1, ReadyPods: 1}}},
+			{Cluster: "us-west", Address: "10.0.0.2:80", Models: map[string]ModelSummary{"llama-7b": {Pods: 2, ReadyPods: 2, Inflight: 6}}},
+			{Cluster: "eu-west", Address: "10.0.0.3:80", Models: map[string]ModelSummary{"llama-7b": {Pods: 4, ReadyPods: 4, Inflight: 8}}},
+			{Cluster: "ap-south", Address: "10.0.0.4:80", Models: map[string]ModelSummary{"llama-7b": {Pods: 2}}},
+			{Cluster: "no-address", Models: map[string]ModelSummary{"llama-7b": {Pods: 1, ReadyPods: 1}}},
+		}, time.Now())
+
+		clusters := cache.GetFederatedClusters("llama-7b")
+		Expect(clusters).To(Equal([]FederatedCluster{
+			{Cluster: "eu-west", Address: "10.0.0.3:80", ReadyPods: 4, Inflight: 8},
+			{Cluster: "us-west", Address: "10.0.0.2:80", ReadyPods: 2, Inflight: 6},
+		}))
+		Expect(cache.GetFederatedClusters("unknown")).To(BeEmpty())
+
+		// the summaries expire if they are not read again.
+		cache.setFederatedClusters([]ClusterSummary{
+			{Cluster: "us-west", Address: "10.0.0.2:80", Models: map[string]ModelSummary{"llama-7b": {Pods: 2, ReadyPods: 2}}},
+		}, time.Now().Add(-federationExpiryIntervals*federationInterval))
+		Expect(cache.GetFederatedClusters("llama-7b")).To(BeEmpty())
+	})
+
+	It("should exchange the summaries of the clusters through the store", func() {
+		store := kvstore.NewMemoryStore()
+		ctx := context.Background()
+		remote, err := json.Marshal(ClusterSummary{
+			Cluster: "us-west", Address: "10.0.0.2:80", Models: map[string]ModelSummary{"llama-7b": {Pods: 1, ReadyPods: 1}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.Set(ctx, federationKeyPrefix+"us-west", remote, time.Minute)).To(Succeed())
+
+		cache.syncFederation(store)
+
+		Expect(cache.GetFederatedClusters("llama-7b")).To(Equal([]FederatedCluster{
+			{Cluster: "us-west", Address: "10.0.0.2:80", ReadyPods: 1},
+		}))
+		local, err := store.Get(ctx, federationKeyPrefix+"us-east")
+		Expect(err).ToNot(HaveOccurred())
+		var summary ClusterSummary
+		Expect(json.Unmarshal(local, &summary)).To(Succeed())
+		Expect(summary.Models).To(HaveKeyWithValue("llama-7b", ModelSummary{Pods: 3, ReadyPods: 1}))
+	})
+	It("should only write the summary of the cluster when leading the replicas", func() {
+		store := kvstore.NewMemoryStore()
+		remote, err := json.Marshal(ClusterSummary{
+			Cluster: "us-west", Address: "10.0.0.2:80", Models: map[string]ModelSummary{"llama-7b": {Pods: 1, ReadyPods: 1}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.Set(context.Background(), federationKeyPrefix+"us-west", remote, time.Minute)).To(Succeed())
+
+		cache.leaderElection = true
+		cache.syncFederation(store)
+		_, err = store.Get(context.Background(), federationKeyPrefix+"us-east")
+		Expect(err).To(MatchError(kvstore.ErrNotFound))
+		// followers still read the summaries of the other clusters.
+		Expect(cache.GetFederatedClusters("llama-7b")).To(HaveLen(1))
+
+		cache.leading.Store(true)
+		cache.syncFederation(store)
+		_, err = store.Get(context.Background(), federationKeyPrefix+"us-east")
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultEngineHealthProbeIntervalInMS = 1000
+	defaultEngineHealthFailureThreshold  = 3
+	engineHealthProbeTimeout             = time.Second
+)
+
+var (
+	engineHealthGatingEnabled    = utils.LoadEnv("AIBRIX_ENGINE_HEALTH_GATING", "true") == "true"
+	engineHealthPath             = utils.LoadEnv("AIBRIX_ENGINE_HEALTH_PATH", "/health")
+	engineHealthProbeInterval    = getEngineHealthProbeInterval()
+	engineHealthFailureThreshold = getEngineHealthFailureThreshold()
+	engineHealthClient           = &http.Client{Timeout: engineHealthProbeTimeout}
+)
+
+func getEngineHealthProbeInterval() time.Duration {
+	value := utils.LoadEnv("AIBRIX_ENGINE_HEALTH_PROBE_INTERVAL_MS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_ENGINE_HEALTH_PROBE_INTERVAL_MS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_ENGINE_HEALTH_PROBE_INTERVAL_MS env value for engine health probe interval: %d ms", intValue)
+			return time.Duration(intValue) * time.Millisecond
+		}
+	}
+	return defaultEngineHealthProbeIntervalInMS * time.Millisecond
+}
+
+func getEngineHealthFailureThreshold() int {
+	value := utils.LoadEnv("AIBRIX_ENGINE_HEALTH_FAILURE_THRESHOLD", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_ENGINE_HEALTH_FAILURE_THRESHOLD: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_ENGINE_HEALTH_FAILURE_THRESHOLD env value for engine health failure threshold: %d", intValue)
+			return intValue
+		}
+	}
+	return defaultEngineHealthFailureThreshold
+}
+
+// engineHealth tracks whether the inference engine of a pod is able to serve, based on metric scrapes
+// and health probes. Kubernetes readiness may flip before the engine finished loading the model.
+type engineHealth struct {
+	succeeded     bool // at least one scrape or probe succeeded
+	scrapeFailure int  // consecutive failed metric scrapes
+	probeFailure  int  // consecutive failed health probes
+	lastSuccess   time.Time
+}
+
+func (h *engineHealth) healthy() bool {
+	threshold := engineHealthFailureThreshold
+	if configured := config.Gateway().EngineHealthFailureThreshold; configured > 0 {
+		threshold = configured
+	}
+	return h.succeeded && h.scrapeFailure < threshold && h.probeFailure < threshold
+}
+
+func (c *Cache) engineHealthLocked(podName string) *engineHealth {
+	if c.engineHealth == nil {
+		c.engineHealth = map[string]*engineHealth{}
+	}
+	health, ok := c.engineHealth[podName]
+	if !ok {
+		health = &engineHealth{}
+		c.engineHealth[podName] = health
+	}
+	return health
+}
+
+// recordScrapeLocked records the result of a metric scrape of the pod.
+func (c *Cache) recordScrapeLocked(podName string, err error) {
+	health := c.engineHealthLocked(podName)
+	wasHealthy := health.healthy()
+	if err != nil {
+		health.scrapeFailure++
+	} else {
+		health.scrapeFailure = 0
+		health.succeeded = true
+		health.lastSuccess = time.Now()
+		if pod, ok := c.pods[podName]; ok {
+			c.observeColdStartLocked(pod, health.lastSuccess)
+		}
+	}
+	logEngineHealthChange(podName, wasHealthy, health, err)
+}
+
+// recordProbe records the result of a health probe of the pod.
+func (c *Cache) recordProbe(podName string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.pods[podName]; !ok {
+		return // pod was deleted while probing
+	}
+	health := c.engineHealthLocked(podName)
+	wasHealthy := health.healthy()
+	if err != nil {
+		health.probeFailure++
+	} else {
+		health.probeFailure = 0
+		health.succeeded = true
+		health.lastSuccess = time.Now()
+		if pod, ok := c.pods[podName]; ok {
+			c.observeColdStartLocked(pod, health.lastSuccess)
+		}
+	}
+	logEngineHealthChange(podName, wasHealthy, health, err)
+}
+
+func logEngineHealthChange(podName string, wasHealthy bool, health *engineHealth, err error) {
+	if isHealthy := health.healthy(); isHealthy != wasHealthy {
+		klog.InfoS("engine health changed", "pod", podName, "healthy", isHealthy, "lastSuccess", health.lastSuccess, "error", err)
+	}
+}
+
+// isEngineReadyLocked returns true if the pod is Ready, not draining and its engine is healthy, or if health gating is disabled.
+func (c *Cache) isEngineReadyLocked(pod *v1.Pod) bool {
+	if !utils.IsPodReady(pod) || c.isDrainingLocked(pod) {
+		return false
+	}
+	if !engineHealthGatingEnabled {
+		return true
+	}
+	health, ok := c.engineHealth[pod.Name]
+	return ok && health.healthy()
+}
+
+// IsEngineReady returns true if the pod is Ready and its engine has been observed serving.
+func (c *Cache) IsEngineReady(podName string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pod, ok := c.pods[podName]
+	return ok && c.isEngineReadyLocked(pod)
+}
+
+// GetReadyPodsForModel returns the pods of the model that are Ready, not draining and whose engine is healthy. Pods lent
+// to batch workloads are excluded.
+// Routers should use it instead of the raw pod readiness.
+func (c *Cache) GetReadyPodsForModel(modelName string) (map[string]*v1.Pod, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	podsMap, ok := c.modelToPodMapping[modelName]
+	if !ok {
+		return nil, fmt.Errorf("model does not exist in the cache: %s", modelName)
+	}
+
+	readyPods := make(map[string]*v1.Pod, len(podsMap))
+	for name, pod := range podsMap {
+		if c.isEngineReadyLocked(pod) && !isLent(pod) {
+			readyPods[name] = pod
+		}
+	}
+	return readyPods, nil
+}
+
+// probeEngineHealth probes the health endpoint of all Ready pods concurrently. Only connection errors and
+// 5xx responses count as failures, engines without a health endpoint are tracked by metric scrapes only.
+func (c *Cache) probeEngineHealth() {
+	c.mu.RLock()
+	readyPods := utils.FilterReadyPods(c.pods)
+	c.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, pod := range readyPods {
+		wg.Add(1)
+		go func(pod *v1.Pod) {
+			defer wg.Done()
+			c.recordProbe(pod.Name, probeEngine(pod))
+		}(pod)
+	}
+	wg.Wait()
+}
+
+func probeEngine(pod *v1.Pod) error {
+	resp, err := engineHealthClient.Get(fmt.Sprintf("http://%s:%d%s", pod.Status.PodIP, podPort, engineHealthPath))
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("health check returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newHealthTestPod(name string, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{modelIdentifier: "llama-7b"}},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+var _ = Describe("EngineHealth", func() {
+	var cache *Cache
+
+	BeforeEach(func() {
+		pod := newHealthTestPod("p1", true)
+		cache = &Cache{
+			pods:              map[string]*v1.Pod{"p1": pod},
+			podToModelMapping: map[string]map[string]struct{}{"p1": {"llama-7b": {}}},
+			modelToPodMapping: map[string]map[string]*v1.Pod{"llama-7b": {"p1": pod}},
+			engineHealth:      map[string]*engineHealth{},
+		}
+	})
+
+	It("should exclude pods whose engine was never observed serving", func() {
+		pods, err := cache.GetReadyPodsForModel("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pods).To(BeEmpty())
+		Expect(cache.IsEngineReady("p1")).To(BeFalse())
+	})
+
+	It("should include pods after a successful scrape or probe", func() {
+		cache.recordScrapeLocked("p1", nil)
+		pods, err := cache.GetReadyPodsForModel("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pods).To(HaveKey("p1"))
+
+		cache.engineHealth = map[string]*engineHealth{}
+		cache.recordProbe("p1", nil)
+		Expect(cache.IsEngineReady("p1")).To(BeTrue())
+	})
+
+	It("should exclude pods once the failure threshold is reached", func() {
+		cache.recordScrapeLocked("p1", nil)
+		for i := 0; i < engineHealthFailureThreshold-1; i++ {
+			cache.recordProbe("p1", errors.New("connection refused"))
+		}
+		Expect(cache.IsEngineReady("p1")).To(BeTrue())
+
+		cache.recordProbe("p1", errors.New("connection refused"))
+		Expect(cache.IsEngineReady("p1")).To(BeFalse())
+
+		cache.recordProbe("p1", nil)
+		Expect(cache.IsEngineReady("p1")).To(BeTrue())
+	})
+
+	It("should reset engine health when a pod becomes ready again", func() {
+		cache.recordScrapeLocked("p1", nil)
+		cache.updatePod(newHealthTestPod("p1", false), newHealthTestPod("p1", true))
+		Expect(cache.IsEngineReady("p1")).To(BeFalse())
+	})
+
+	It("should ignore probes of deleted pods", func() {
+		cache.recordProbe("p2", nil)
+		Expect(cache.engineHealth).ToNot(HaveKey("p2"))
+	})
+})
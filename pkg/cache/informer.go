@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	watchErrorReasonExpired       = "expired"
+	watchErrorReasonUnexpectedEOF = "unexpected_eof"
+	watchErrorReasonFailed        = "failed"
+)
+
+var (
+	informerResyncPeriod = getInformerResyncPeriod()
+
+	informerWatchErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_informer_watch_errors_total",
+		Help: "Watches of the informers of the cache ended by an error, by resource and reason: expired, unexpected_eof or failed.",
+	}, []string{"resource", "reason"})
+)
+
+func init() {
+	prometheus.MustRegister(informerWatchErrors)
+}
+
+// getInformerResyncPeriod returns how often the informers of the cache replay their store to the event handlers, 0
+// disables resyncs. The handlers are idempotent, a resync only repairs a cache that missed an event.
+func getInformerResyncPeriod() time.Duration {
+	value := utils.LoadEnv("AIBRIX_INFORMER_RESYNC_PERIOD_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_INFORMER_RESYNC_PERIOD_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_INFORMER_RESYNC_PERIOD_S env value for informer resync period: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return 0
+}
+
+// watchErrorHandler returns the watch error handler of the informer of resource, it counts the errors before the
+// default handling: logging them, the reflector relists and watches again with a backoff either way.
+func watchErrorHandler(resource string) toolscache.WatchErrorHandler {
+	return func(r *toolscache.Reflector, err error) {
+		switch {
+		case errors.Is(err, io.EOF):
+			// the watch was closed normally
+		case apierrors.IsResourceExpired(err) || apierrors.IsGone(err):
+			informerWatchErrors.WithLabelValues(resource, watchErrorReasonExpired).Inc()
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			informerWatchErrors.WithLabelValues(resource, watchErrorReasonUnexpectedEOF).Inc()
+		default:
+			informerWatchErrors.WithLabelValues(resource, watchErrorReasonFailed).Inc()
+		}
+		toolscache.DefaultWatchErrorHandler(r, err)
+	}
+}
+
+// setupInformer sets the transform and the watch error handler of the informer of resource, before it is started.
+func setupInformer(informer toolscache.SharedIndexInformer, resource string, transform toolscache.TransformFunc) {
+	if err := informer.SetTransform(transform); err != nil {
+		panic(err)
+	}
+	if err := informer.SetWatchErrorHandler(watchErrorHandler(resource)); err != nil {
+		panic(err)
+	}
+}
+
+// transformNode is the transform of the node informer, the cache only reads the topology labels of the nodes.
+func transformNode(obj interface{}) (interface{}, error) {
+	if node, ok := obj.(*v1.Node); ok {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            node.Name,
+				UID:             node.UID,
+				ResourceVersion: node.ResourceVersion,
+				Labels:          node.Labels,
+			},
+		}, nil
+	}
+	return obj, nil
+}
+
+// transformModelAdapter is the transform of the model adapter informer. The adapters are kept in the cache as they
+// are, only their managed fields and last applied manifest are dropped.
+func transformModelAdapter(obj interface{}) (interface{}, error) {
+	if adapter, ok := obj.(*modelv1alpha1.ModelAdapter); ok {
+		adapter.ManagedFields = nil
+		if _, ok := adapter.Annotations[lastAppliedConfigAnnotationKey]; ok {
+			delete(adapter.Annotations, lastAppliedConfigAnnotationKey)
+		}
+		return adapter, nil
+	}
+	return obj, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+)
+
+var _ = Describe("Informer", func() {
+	It("should keep the topology labels of the nodes", func() {
+		node := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          "node-1",
+				Labels:        map[string]string{v1.LabelTopologyZone: "us-west-1a", "cloud.google.com/gke-nodepool": "h100"},
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubelet"}},
+			},
+			Status: v1.NodeStatus{Images: []v1.ContainerImage{{Names: []string{"vllm/vllm-openai:v0.8.0"}}}},
+		}
+		topology := nodeTopology(node)
+
+		obj, err := transformNode(node)
+		Expect(err).ToNot(HaveOccurred())
+		projection := obj.(*v1.Node)
+		Expect(nodeTopology(projection)).To(Equal(topology))
+		Expect(projection.ManagedFields).To(BeEmpty())
+		Expect(projection.Status.Images).To(BeEmpty())
+
+		tombstone := toolscache.DeletedFinalStateUnknown{Key: "node-1", Obj: node}
+		Expect(transformNode(tombstone)).To(Equal(tombstone))
+	})
+
+	It("should drop the managed fields of the model adapters", func() {
+		adapter := &modelv1alpha1.ModelAdapter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          "lora-1",
+				Annotations:   map[string]string{lastAppliedConfigAnnotationKey: "{}", "owner": "team-a"},
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			},
+			Status: modelv1alpha1.ModelAdapterStatus{Instances: []string{"p1"}},
+		}
+
+		obj, err := transformModelAdapter(adapter)
+		Expect(err).ToNot(HaveOccurred())
+		projection := obj.(*modelv1alpha1.ModelAdapter)
+		Expect(projection.ManagedFields).To(BeEmpty())
+		Expect(projection.Annotations).To(Equal(map[string]string{"owner": "team-a"}))
+		Expect(projection.Status.Instances).To(Equal([]string{"p1"}))
+	})
+
+	It("should count the watch errors by reason", func() {
+		reflector := toolscache.NewReflector(&toolscache.ListWatch{}, &v1.Node{}, toolscache.NewStore(toolscache.MetaNamespaceKeyFunc), 0)
+		handler := watchErrorHandler("test")
+
+		handler(reflector, io.EOF)
+		handler(reflector, apierrors.NewResourceExpired("too old resource version"))
+		handler(reflector, io.ErrUnexpectedEOF)
+		handler(reflector, errors.New("connection refused"))
+		handler(reflector, errors.New("connection refused"))
+
+		Expect(testutil.ToFloat64(informerWatchErrors.WithLabelValues("test", watchErrorReasonExpired))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(informerWatchErrors.WithLabelValues("test", watchErrorReasonUnexpectedEOF))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(informerWatchErrors.WithLabelValues("test", watchErrorReasonFailed))).To(Equal(2.0))
+	})
+})
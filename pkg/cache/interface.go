@@ -0,0 +1,94 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+// Interface is the interface of the cache the gateway, the routers and the controllers depend on, so they can be tested
+// against a fake and don't depend on how the cache stores its state. Maps returned by the accessors are copies owned
+// by the caller.
+type Interface interface {
+	// Pods and models.
+	GetPod(podName string) (*v1.Pod, error)
+	GetPods() map[string]*v1.Pod
+	GetPodsForModel(modelName string) (map[string]*v1.Pod, error)
+	GetReadyPodsForModel(modelName string) (map[string]*v1.Pod, error)
+	GetLentPodsForModel(modelName string) map[string]*v1.Pod
+	GetModelsForPod(podName string) (map[string]struct{}, error)
+	GetModels() []string
+	CheckModelExists(modelName string) bool
+	GetModelAdapter(modelName string) (*modelv1alpha1.ModelAdapter, error)
+	GetModelInfo(modelName string) (*ModelInfo, error)
+	GetPodMaxModelLen(podName, modelName string) int
+	GetPodCapabilities(podName string) []string
+	GetPodSnapshots() []PodSnapshot
+	GetScaleDownVictims(modelName string) ([]ScaleDownCandidate, error)
+	GetWarmNodes(modelName string) []string
+
+	// Topology.
+	GetPodTopology(podName string) (Topology, bool)
+	GetNodeTopology(nodeName string) (Topology, bool)
+
+	// Engine health and drain.
+	IsEngineReady(podName string) bool
+	IsPodDraining(podName string) bool
+	IsPodDrained(podName string) bool
+
+	// Engine metrics.
+	GetPodMetric(podName, metricName string) (metrics.MetricValue, error)
+	GetPodModelMetric(podName, modelName string, metricName string) (metrics.MetricValue, error)
+	GetKVCacheEfficiency(modelName string) (*KVCacheEfficiency, error)
+	PushPodMetrics(push MetricPush) error
+	WatchPodMetric(ctx context.Context, podName, metricName string, threshold float64) <-chan MetricThresholdEvent
+	AddSubscriber(subscriber metrics.MetricSubscriber)
+
+	// Requests routed by the gateway.
+	AddRequestCount(requestID string, modelName string) (traceTerm int64)
+	DoneRequestCount(requestID string, modelName string, traceTerm int64)
+	AddRequestTrace(requestID string, modelName string, inputTokens, outputTokens int64)
+	DoneRequestTrace(requestID string, modelName string, inputTokens, outputTokens, traceTerm int64)
+	AddPodRequest(podName string)
+	DonePodRequest(podName string)
+	GetPodInflightRequests(podName string) int32
+	AddPodBatchItems(podName string, items int32)
+	DonePodBatchItems(podName string, items int32)
+	GetPodInflightBatchItems(podName string) int32
+	GetPodRemoteInflightBatchItems(podName string) int32
+
+	// Federation and replicas.
+	UpdateFederatedClusters(summaries []ClusterSummary)
+	GetFederatedClusters(modelName string) []FederatedCluster
+	IsLeader() bool
+	KVStore() kvstore.Store
+	AddCheckpointer(name string, checkpointer Checkpointer)
+	AddOwnershipProvider(provider PodOwnershipProvider)
+
+	// Pods and metrics set without a cluster, see NewOfflineCache.
+	SetPod(pod *v1.Pod, modelName string)
+	SetPodMetric(podName, metricName string, value metrics.MetricValue)
+	SetPodModelMetric(podName, modelName, metricName string, value metrics.MetricValue)
+}
+
+var _ Interface = (*Cache)(nil)
@@ -0,0 +1,183 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+const (
+	kvOffloadTierL1 = "l1"
+	kvOffloadTierL2 = "l2"
+)
+
+var (
+	kvCacheHitRatioDesc = prometheus.NewDesc(
+		"aibrix_gateway_kv_cache_hit_ratio",
+		"Ratio of prompt tokens hit in the KV offloading tier across the pods of the model, tier \"all\" counts hits in any tier.",
+		[]string{"model", "tier"}, nil,
+	)
+	kvCacheEvictionsDesc = prometheus.NewDesc(
+		"aibrix_gateway_kv_cache_evictions",
+		"Blocks evicted from the KV offloading tier across the pods of the model.",
+		[]string{"model", "tier"}, nil,
+	)
+	kvCacheTransferBandwidthDesc = prometheus.NewDesc(
+		"aibrix_gateway_kv_cache_transfer_bandwidth_bytes",
+		"KV cache transfer bandwidth in bytes per second between the pods of the model and the offloading tiers.",
+		[]string{"model"}, nil,
+	)
+)
+
+// kvTransferSample is the last scraped transfer counter of a model in a pod.
+type kvTransferSample struct {
+	bytes float64
+	at    time.Time
+}
+
+// KVCacheEfficiency aggregates the KV offloading connector metrics of the pods serving a model. Hit rates are
+// token weighted over the lifetime of the engines, so they can be used to size the external cache tier.
+type KVCacheEfficiency struct {
+	// Pods is the number of pods reporting KV offloading connector metrics.
+	Pods int
+	// L1HitRate is the ratio of looked up prompt tokens hit in the engine local tier.
+	L1HitRate float64
+	// L2HitRate is the ratio of prompt tokens missed in L1 and hit in the external tier.
+	L2HitRate float64
+	// HitRate is the ratio of looked up prompt tokens hit in any tier.
+	HitRate float64
+	// L1Evictions and L2Evictions are the blocks evicted from each tier.
+	L1Evictions float64
+	L2Evictions float64
+	// TransferBandwidth is the KV cache transfer rate in bytes per second over the last scrape interval.
+	TransferBandwidth float64
+}
+
+// updateKVTransferBandwidthLocked derives the transfer bandwidth of every model in the scraped metrics of the pod from
+// the transfer counter scraped now and the one of the previous scrape. Counter resets (engine restarts) skip one interval.
+func (c *Cache) updateKVTransferBandwidthLocked(record *podMetricRecord, podName string, now time.Time) {
+	for modelName, modelMetrics := range record.models {
+		transferred, ok := modelMetrics[metrics.KVOffloadTransferBytes]
+		if !ok {
+			continue
+		}
+		if c.kvTransferSamples == nil {
+			c.kvTransferSamples = map[string]map[string]kvTransferSample{}
+		}
+		if c.kvTransferSamples[podName] == nil {
+			c.kvTransferSamples[podName] = map[string]kvTransferSample{}
+		}
+
+		sample := kvTransferSample{bytes: transferred.GetSimpleValue(), at: now}
+		if last, ok := c.kvTransferSamples[podName][modelName]; ok && sample.bytes >= last.bytes && now.After(last.at) {
+			bandwidth := (sample.bytes - last.bytes) / now.Sub(last.at).Seconds()
+			modelMetrics[metrics.KVOffloadTransferBandwidth] = &metrics.SimpleMetricValue{Value: bandwidth}
+		}
+		c.kvTransferSamples[podName][modelName] = sample
+	}
+}
+
+// GetKVCacheEfficiency returns the KV offloading cache efficiency of the model aggregated over its pods.
+func (c *Cache) GetKVCacheEfficiency(modelName string) (*KVCacheEfficiency, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.getKVCacheEfficiencyLocked(modelName)
+}
+
+func (c *Cache) getKVCacheEfficiencyLocked(modelName string) (*KVCacheEfficiency, error) {
+	pods, ok := c.modelToPodMapping[modelName]
+	if !ok {
+		return nil, fmt.Errorf("model does not exist in the cache: %s", modelName)
+	}
+
+	var l1Queries, l1Hits, l2Queries, l2Hits float64
+	efficiency := &KVCacheEfficiency{}
+	for podName := range pods {
+		modelMetrics := c.podMetrics.load(podName).modelMetrics(modelName)
+		if modelMetrics == nil {
+			continue
+		}
+		if _, ok := modelMetrics[metrics.KVOffloadL1QueryTokens]; !ok {
+			continue
+		}
+		efficiency.Pods++
+		l1Queries += simpleValue(modelMetrics, metrics.KVOffloadL1QueryTokens)
+		l1Hits += simpleValue(modelMetrics, metrics.KVOffloadL1HitTokens)
+		l2Queries += simpleValue(modelMetrics, metrics.KVOffloadL2QueryTokens)
+		l2Hits += simpleValue(modelMetrics, metrics.KVOffloadL2HitTokens)
+		efficiency.L1Evictions += simpleValue(modelMetrics, metrics.KVOffloadL1Evictions)
+		efficiency.L2Evictions += simpleValue(modelMetrics, metrics.KVOffloadL2Evictions)
+		efficiency.TransferBandwidth += simpleValue(modelMetrics, metrics.KVOffloadTransferBandwidth)
+	}
+	if efficiency.Pods == 0 {
+		return nil, fmt.Errorf("no pod of model %s reports kv offloading metrics", modelName)
+	}
+
+	if l1Queries > 0 {
+		efficiency.L1HitRate = l1Hits / l1Queries
+		efficiency.HitRate = (l1Hits + l2Hits) / l1Queries
+	}
+	if l2Queries > 0 {
+		efficiency.L2HitRate = l2Hits / l2Queries
+	}
+	return efficiency, nil
+}
+
+func simpleValue(modelMetrics map[string]metrics.MetricValue, metricName string) float64 {
+	if metricVal, ok := modelMetrics[metricName]; ok {
+		return metricVal.GetSimpleValue()
+	}
+	return 0
+}
+
+// kvCacheEfficiencyCollector exports the KV cache efficiency of every model at scrape time, so models leaving
+// the cache don't leave stale series behind.
+type kvCacheEfficiencyCollector struct {
+	cache *Cache
+}
+
+var _ prometheus.Collector = (*kvCacheEfficiencyCollector)(nil)
+
+func (k *kvCacheEfficiencyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- kvCacheHitRatioDesc
+	ch <- kvCacheEvictionsDesc
+	ch <- kvCacheTransferBandwidthDesc
+}
+
+func (k *kvCacheEfficiencyCollector) Collect(ch chan<- prometheus.Metric) {
+	k.cache.mu.RLock()
+	defer k.cache.mu.RUnlock()
+
+	for modelName := range k.cache.modelToPodMapping {
+		efficiency, err := k.cache.getKVCacheEfficiencyLocked(modelName)
+		if err != nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(kvCacheHitRatioDesc, prometheus.GaugeValue, efficiency.L1HitRate, modelName, kvOffloadTierL1)
+		ch <- prometheus.MustNewConstMetric(kvCacheHitRatioDesc, prometheus.GaugeValue, efficiency.L2HitRate, modelName, kvOffloadTierL2)
+		ch <- prometheus.MustNewConstMetric(kvCacheHitRatioDesc, prometheus.GaugeValue, efficiency.HitRate, modelName, "all")
+		ch <- prometheus.MustNewConstMetric(kvCacheEvictionsDesc, prometheus.GaugeValue, efficiency.L1Evictions, modelName, kvOffloadTierL1)
+		ch <- prometheus.MustNewConstMetric(kvCacheEvictionsDesc, prometheus.GaugeValue, efficiency.L2Evictions, modelName, kvOffloadTierL2)
+		ch <- prometheus.MustNewConstMetric(kvCacheTransferBandwidthDesc, prometheus.GaugeValue, efficiency.TransferBandwidth, modelName)
+	}
+}
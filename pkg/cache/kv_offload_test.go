@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+func kvOffloadMetrics(l1Queries, l1Hits, l2Queries, l2Hits, transferred float64) map[string]metrics.MetricValue {
+	return map[string]metrics.MetricValue{
+		metrics.KVOffloadL1QueryTokens: &metrics.SimpleMetricValue{Value: l1Queries},
+		metrics.KVOffloadL1HitTokens:   &metrics.SimpleMetricValue{Value: l1Hits},
+		metrics.KVOffloadL2QueryTokens: &metrics.SimpleMetricValue{Value: l2Queries},
+		metrics.KVOffloadL2HitTokens:   &metrics.SimpleMetricValue{Value: l2Hits},
+		metrics.KVOffloadL1Evictions:   &metrics.SimpleMetricValue{Value: 10},
+		metrics.KVOffloadTransferBytes: &metrics.SimpleMetricValue{Value: transferred},
+	}
+}
+
+var _ = Describe("KVOffload", func() {
+	var cache *Cache
+
+	BeforeEach(func() {
+		p1 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1"}}
+		p2 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p2"}}
+		p3 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p3"}}
+		cache = &Cache{
+			modelToPodMapping: map[string]map[string]*v1.Pod{"llama-7b": {"p1": p1, "p2": p2, "p3": p3}},
+		}
+		for podName, modelMetrics := range map[string]map[string]metrics.MetricValue{
+			"p1": kvOffloadMetrics(100, 50, 50, 25, 0),
+			"p2": kvOffloadMetrics(300, 150, 150, 0, 0),
+			"p3": {metrics.NumRequestsRunning: &metrics.SimpleMetricValue{Value: 1}},
+		} {
+			cache.podMetrics.store(podName, &podMetricRecord{models: map[string]map[string]metrics.MetricValue{"llama-7b": modelMetrics}})
+		}
+	})
+
+	It("should aggregate hit rates over the pods reporting offloading metrics", func() {
+		efficiency, err := cache.GetKVCacheEfficiency("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(efficiency.Pods).To(Equal(2))
+		Expect(efficiency.L1HitRate).To(Equal(0.5))
+		Expect(efficiency.L2HitRate).To(Equal(0.125))
+		Expect(efficiency.HitRate).To(Equal(0.5625))
+		Expect(efficiency.L1Evictions).To(Equal(20.0))
+	})
+
+	It("should fail for models without offloading metrics", func() {
+		_, err := cache.GetKVCacheEfficiency("llama-13b")
+		Expect(err).To(HaveOccurred())
+
+		cache.podMetrics.delete("p1")
+		cache.podMetrics.delete("p2")
+		_, err = cache.GetKVCacheEfficiency("llama-7b")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should derive the transfer bandwidth between scrapes", func() {
+		now := time.Now()
+		record := cache.podMetrics.load("p1")
+		cache.updateKVTransferBandwidthLocked(record, "p1", now)
+		Expect(record.models["llama-7b"]).ToNot(HaveKey(metrics.KVOffloadTransferBandwidth))
+
+		record.models["llama-7b"][metrics.KVOffloadTransferBytes] = &metrics.SimpleMetricValue{Value: 4096}
+		cache.updateKVTransferBandwidthLocked(record, "p1", now.Add(2*time.Second))
+		efficiency, err := cache.GetKVCacheEfficiency("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(efficiency.TransferBandwidth).To(Equal(2048.0))
+
+		// an engine restart resets the counter, the last bandwidth is kept for one interval.
+		record.models["llama-7b"][metrics.KVOffloadTransferBytes] = &metrics.SimpleMetricValue{Value: 0}
+		cache.updateKVTransferBandwidthLocked(record, "p1", now.Add(3*time.Second))
+		Expect(record.models["llama-7b"][metrics.KVOffloadTransferBandwidth].GetSimpleValue()).To(Equal(2048.0))
+		Expect(cache.kvTransferSamples["p1"]["llama-7b"].bytes).To(Equal(0.0))
+	})
+})
@@ -0,0 +1,102 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	leaderElectionLeaseDuration = 15 * time.Second
+	leaderElectionRenewDeadline = 10 * time.Second
+	leaderElectionRetryPeriod   = 2 * time.Second
+)
+
+var (
+	leaderElectionEnabled = utils.LoadEnv("AIBRIX_LEADER_ELECTION_ENABLED", "false") == "true"
+	leaderElectionLease   = utils.LoadEnv("AIBRIX_LEADER_ELECTION_LEASE", "aibrix-gateway-plugins-leader")
+
+	leaderGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "aibrix_gateway_leader",
+		Help: "1 if the replica holds the gateway lease and performs the cluster-wide writes, 0 otherwise.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(leaderGauge)
+}
+
+// IsLeader returns whether the replica performs the cluster-wide writes: the request trace meta key, the pod deletion
+// costs and the federation summary of the cluster. All replicas do without leader election.
+func (c *Cache) IsLeader() bool {
+	return !c.leaderElection || c.leading.Load()
+}
+
+// runLeaderElection campaigns for the lease of the gateway replicas until stopped, the lease is released on stop so a
+// new leader takes over without waiting for it to expire.
+func (c *Cache) runLeaderElection(client kubernetes.Interface, stopCh <-chan struct{}) {
+	identity := utils.LoadEnv("POD_NAME", loadReportReplica)
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      leaderElectionLease,
+			Namespace: utils.LoadEnv("POD_NAMESPACE", "aibrix-system"),
+		},
+		Client:     client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	// a replica losing the lease campaigns again.
+	for ctx.Err() == nil {
+		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			LeaseDuration:   leaderElectionLeaseDuration,
+			RenewDeadline:   leaderElectionRenewDeadline,
+			RetryPeriod:     leaderElectionRetryPeriod,
+			ReleaseOnCancel: true,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(context.Context) {
+					klog.InfoS("started leading the gateway replicas", "identity", identity, "lease", leaderElectionLease)
+					c.leading.Store(true)
+					leaderGauge.Set(1)
+				},
+				OnStoppedLeading: func() {
+					klog.InfoS("stopped leading the gateway replicas", "identity", identity, "lease", leaderElectionLease)
+					c.leading.Store(false)
+					leaderGauge.Set(0)
+				},
+				OnNewLeader: func(leader string) {
+					klog.V(4).InfoS("gateway leader elected", "leader", leader)
+				},
+			},
+		})
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultLoadReportIntervalInMS = 500
+	// loadReportChannel is the channel the gateway replicas publish their load reports on.
+	loadReportChannel = "aibrix:gateway-load-reports"
+	// the reports of a replica missing loadReportExpiryIntervals publications are dropped, e.g. the replica stopped.
+	loadReportExpiryIntervals    = 3
+	loadReportResubscribeBackoff = 5 * time.Second
+)
+
+var (
+	loadReportInterval = getLoadReportInterval()
+	loadReportReplica  = getLoadReportReplica()
+
+	loadReportReplicas = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "aibrix_gateway_load_report_replicas",
+		Help: "Number of other gateway replicas whose in-flight load is merged into the routing of this replica.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(loadReportReplicas)
+}
+
+func getLoadReportInterval() time.Duration {
+	value := utils.LoadEnv("AIBRIX_LOAD_REPORT_INTERVAL_MS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_LOAD_REPORT_INTERVAL_MS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_LOAD_REPORT_INTERVAL_MS env value for load report interval: %d ms", intValue)
+			return time.Duration(intValue) * time.Millisecond
+		}
+	}
+	return defaultLoadReportIntervalInMS * time.Millisecond
+}
+
+// getLoadReportReplica identifies the replica in its reports, by the name of its pod.
+func getLoadReportReplica() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return uuid.New().String()
+}
+
+// LoadReport is the load a gateway replica routed to the pods and is waiting for.
+type LoadReport struct {
+	Replica string `json:"replica"`
+	// Pods is the number of in-flight engine requests per pod, each input of batch requests counted as a request.
+	Pods map[string]int32 `json:"pods,omitempty"`
+}
+
+// remoteLoad is the last load reported by another replica.
+type remoteLoad struct {
+	pods    map[string]int32
+	expires time.Time
+}
+
+// localLoadReport returns the load of the replica, pods without in-flight requests left out.
+func (c *Cache) localLoadReport() LoadReport {
+	report := LoadReport{Replica: loadReportReplica, Pods: map[string]int32{}}
+	c.podBatchItems.Range(func(key, value any) bool {
+		if items := atomic.LoadInt32(value.(*int32)); items > 0 {
+			report.Pods[key.(string)] = items
+		}
+		return true
+	})
+	return report
+}
+
+// mergeLoadReport replaces the load of another replica with its report, the reports of this replica are ignored.
+func (c *Cache) mergeLoadReport(report LoadReport, now time.Time) {
+	if report.Replica == "" || report.Replica == loadReportReplica {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.remoteLoads == nil {
+		c.remoteLoads = map[string]*remoteLoad{}
+	}
+	for replica, load := range c.remoteLoads {
+		if !now.Before(load.expires) {
+			delete(c.remoteLoads, replica)
+		}
+	}
+	if len(report.Pods) == 0 {
+		delete(c.remoteLoads, report.Replica)
+	} else {
+		c.remoteLoads[report.Replica] = &remoteLoad{
+			pods:    report.Pods,
+			expires: now.Add(loadReportExpiryIntervals * loadReportInterval),
+		}
+	}
+	loadReportReplicas.Set(float64(len(c.remoteLoads)))
+}
+
+// GetPodRemoteInflightBatchItems returns the in-flight engine requests the other gateway replicas reported for the
+// pod. It lags behind them by up to a report interval.
+func (c *Cache) GetPodRemoteInflightBatchItems(podName string) int32 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.getPodRemoteInflightBatchItemsLocked(podName, time.Now())
+}
+
+func (c *Cache) getPodRemoteInflightBatchItemsLocked(podName string, now time.Time) int32 {
+	var items int32
+	for _, load := range c.remoteLoads {
+		if now.Before(load.expires) {
+			items += load.pods[podName]
+		}
+	}
+	return items
+}
+
+// runLoadReports publishes the load of the replica every interval and merges the reports of the other replicas, so
+// that replicas routing independently don't pile their requests onto the same pod.
+func (c *Cache) runLoadReports(store kvstore.Store, stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.receiveLoadReports(ctx, store)
+
+	ticker := time.NewTicker(loadReportInterval)
+	defer ticker.Stop()
+	idle := false
+	for {
+		select {
+		case <-ticker.C:
+			report := c.localLoadReport()
+			// an idle replica publishes once, the others drop its load on the empty report.
+			if len(report.Pods) == 0 && idle {
+				continue
+			}
+			idle = len(report.Pods) == 0
+			c.publishLoadReport(ctx, store, report)
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (c *Cache) publishLoadReport(ctx context.Context, store kvstore.Store, report LoadReport) {
+	message, err := json.Marshal(report)
+	if err != nil {
+		klog.ErrorS(err, "failed to marshal load report")
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, loadReportInterval)
+	defer cancel()
+	if err := store.Publish(ctx, loadReportChannel, message); err != nil {
+		klog.V(4).ErrorS(err, "failed to publish load report")
+	}
+}
+
+// receiveLoadReports merges the reports of the other replicas until ctx is done, subscribing again if the
+// subscription ends.
+func (c *Cache) receiveLoadReports(ctx context.Context, store kvstore.Store) {
+	for {
+		if messages, err := store.Subscribe(ctx, loadReportChannel); err != nil {
+			klog.ErrorS(err, "failed to subscribe to load reports")
+		} else {
+			for message := range messages {
+				var report LoadReport
+				if err := json.Unmarshal(message, &report); err != nil {
+					klog.V(4).ErrorS(err, "ignoring invalid load report")
+					continue
+				}
+				c.mergeLoadReport(report, time.Now())
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(loadReportResubscribeBackoff):
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+var _ = Describe("LoadReport", func() {
+	var cache *Cache
+
+	BeforeEach(func() {
+		cache = &Cache{}
+	})
+
+	It("should report the in-flight engine requests of the replica", func() {
+		cache.AddPodBatchItems("p1", 3)
+		cache.AddPodBatchItems("p2", 1)
+		cache.DonePodBatchItems("p2", 1)
+
+		report := cache.localLoadReport()
+		Expect(report.Replica).To(Equal(loadReportReplica))
+		Expect(report.Pods).To(Equal(map[string]int32{"p1": 3}))
+	})
+
+	It("should sum the reports of the other replicas until they expire", func() {
+		now := time.Now()
+		cache.mergeLoadReport(LoadReport{Replica: "gw-1", Pods: map[string]int32{"p1": 2}}, now)
+		cache.mergeLoadReport(LoadReport{Replica: "gw-2", Pods: map[string]int32{"p1": 1, "p2": 4}}, now)
+		cache.mergeLoadReport(LoadReport{Replica: loadReportReplica, Pods: map[string]int32{"p1": 10}}, now)
+		Expect(cache.GetPodRemoteInflightBatchItems("p1")).To(Equal(int32(3)))
+		Expect(cache.GetPodRemoteInflightBatchItems("p2")).To(Equal(int32(4)))
+		Expect(cache.GetPodRemoteInflightBatchItems("p3")).To(BeZero())
+
+		// a new report replaces the load of the replica, an empty one drops it.
+		cache.mergeLoadReport(LoadReport{Replica: "gw-1", Pods: map[string]int32{"p1": 1}}, now)
+		cache.mergeLoadReport(LoadReport{Replica: "gw-2"}, now)
+		Expect(cache.GetPodRemoteInflightBatchItems("p1")).To(Equal(int32(1)))
+		Expect(cache.GetPodRemoteInflightBatchItems("p2")).To(BeZero())
+
+		// the load of a replica that stopped reporting expires.
+		cache.mergeLoadReport(LoadReport{Replica: "gw-3", Pods: map[string]int32{"p1": 1}}, now.Add(-loadReportExpiryIntervals*loadReportInterval))
+		Expect(cache.GetPodRemoteInflightBatchItems("p1")).To(Equal(int32(1)))
+	})
+
+	It("should publish its load and merge the reports of the other replicas", func() {
+		store := kvstore.NewMemoryStore()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		published, err := store.Subscribe(ctx, loadReportChannel)
+		Expect(err).ToNot(HaveOccurred())
+
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		cache.AddPodBatchItems("p1", 2)
+		go cache.runLoadReports(store, stopCh)
+
+		var report LoadReport
+		Expect(json.Unmarshal(<-published, &report)).To(Succeed())
+		Expect(report.Pods).To(Equal(map[string]int32{"p1": 2}))
+
+		message, err := json.Marshal(LoadReport{Replica: "gw-1", Pods: map[string]int32{"p1": 5}})
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(func() int32 {
+			Expect(store.Publish(ctx, loadReportChannel, message)).To(Succeed())
+			return cache.GetPodRemoteInflightBatchItems("p1")
+		}, time.Second, 10*time.Millisecond).Should(Equal(int32(5)))
+	})
+})
@@ -0,0 +1,220 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// MetricPushChannel is the kv store channel engines publish their MetricPush on.
+	MetricPushChannel = "aibrix:engine-metrics"
+
+	defaultMetricPushStalenessInMS = 5000
+	metricPushResubscribeBackoff   = 5 * time.Second
+
+	metricPushResultAccepted = "accepted"
+	metricPushResultRejected = "rejected"
+)
+
+// ErrMetricPushDisabled is returned for the metrics pushed while AIBRIX_METRIC_PUSH_ENABLED is not set.
+var ErrMetricPushDisabled = errors.New("metric push is disabled")
+
+var (
+	metricPushEnabled = utils.LoadEnv("AIBRIX_METRIC_PUSH_ENABLED", "false") == "true"
+	// metricPushStaleness is how long a pushed metric takes precedence over the scrapes of the pod.
+	metricPushStaleness = getMetricPushStaleness()
+
+	metricPushes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_metric_pushes_total",
+		Help: "Metric pushes received from the engines, by result.",
+	}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(metricPushes)
+}
+
+func getMetricPushStaleness() time.Duration {
+	value := utils.LoadEnv("AIBRIX_METRIC_PUSH_STALENESS_MS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_METRIC_PUSH_STALENESS_MS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_METRIC_PUSH_STALENESS_MS env value for metric push staleness: %d ms", intValue)
+			return time.Duration(intValue) * time.Millisecond
+		}
+	}
+	return defaultMetricPushStalenessInMS * time.Millisecond
+}
+
+// MetricPush is the latest value of metrics of an engine, pushed by the engine or its sidecar when they change instead
+// of waiting for the next scrape. Only the changed metrics need to be pushed.
+type MetricPush struct {
+	Pod string `json:"pod"`
+	// Model is the model the metrics are about, required for the metrics scoped to a model on the pod.
+	Model string `json:"model,omitempty"`
+	// Metrics are metric_name: value, e.g. num_requests_waiting, only gauges and counters are supported.
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// pushedMetric identifies a pushed metric of a pod.
+type pushedMetric struct {
+	model  string
+	metric string
+}
+
+// PushPodMetrics updates the metrics of the pod with the pushed values. Pushed metrics take precedence over the scraped
+// ones for AIBRIX_METRIC_PUSH_STALENESS_MS, after which the scrapes update them again.
+func (c *Cache) PushPodMetrics(push MetricPush) error {
+	if !metricPushEnabled {
+		return ErrMetricPushDisabled
+	}
+	err := c.pushPodMetrics(push, time.Now())
+	if err != nil {
+		metricPushes.WithLabelValues(metricPushResultRejected).Inc()
+		return err
+	}
+	metricPushes.WithLabelValues(metricPushResultAccepted).Inc()
+	return nil
+}
+
+func (c *Cache) pushPodMetrics(push MetricPush, now time.Time) error {
+	values := make(map[pushedMetric]metrics.MetricValue, len(push.Metrics))
+	scopes := make(map[pushedMetric]metrics.MetricScope, len(push.Metrics))
+	for metricName, value := range push.Metrics {
+		metric, ok := metrics.Metrics[metricName]
+		if !ok || (metric.MetricType.Raw != metrics.Gauge && metric.MetricType.Raw != metrics.Counter) {
+			return fmt.Errorf("metric %s can't be pushed", metricName)
+		}
+		key := pushedMetric{metric: metricName}
+		if metric.MetricScope == metrics.PodModelMetricScope {
+			if push.Model == "" {
+				return fmt.Errorf("model is required for metric %s", metricName)
+			}
+			key.model = push.Model
+		}
+		values[key] = &metrics.SimpleMetricValue{Value: value}
+		scopes[key] = metric.MetricScope
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.pods[push.Pod]; !ok {
+		return fmt.Errorf("pod does not exist in the cache: %s", push.Pod)
+	}
+	var err error
+	c.podMetrics.update(push.Pod, func(record *podMetricRecord) {
+		for key, value := range values {
+			if err = record.set(key.model, key.metric, scopes[key], value); err != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	c.notifyMetricWatches(push.Pod)
+	if c.metricPushes == nil {
+		c.metricPushes = map[string]map[pushedMetric]time.Time{}
+	}
+	if c.metricPushes[push.Pod] == nil {
+		c.metricPushes[push.Pod] = map[pushedMetric]time.Time{}
+	}
+	for key := range values {
+		c.metricPushes[push.Pod][key] = now
+	}
+	return nil
+}
+
+// keepPushedMetricsLocked replaces the scraped metrics of the pod in record with the values pushed within
+// metricPushStaleness, which are more recent than the scrape.
+func (c *Cache) keepPushedMetricsLocked(podName string, record *podMetricRecord, now time.Time) {
+	pushes, ok := c.metricPushes[podName]
+	if !ok {
+		return
+	}
+	current := c.podMetrics.load(podName)
+	for key, pushed := range pushes {
+		if now.Sub(pushed) >= metricPushStaleness {
+			delete(pushes, key)
+			continue
+		}
+		var value metrics.MetricValue
+		if key.model == "" {
+			value, ok = current.metric(key.metric)
+			if ok {
+				record.pod[key.metric] = value
+			}
+		} else if value, ok = current.modelMetrics(key.model)[key.metric]; ok {
+			if record.models[key.model] == nil {
+				record.models[key.model] = map[string]metrics.MetricValue{}
+			}
+			record.models[key.model][key.metric] = value
+		}
+	}
+	if len(pushes) == 0 {
+		delete(c.metricPushes, podName)
+	}
+}
+
+// receiveMetricPushes ingests the metrics the engines publish on MetricPushChannel until stopCh is closed, subscribing
+// again if the subscription ends.
+func (c *Cache) receiveMetricPushes(store kvstore.Store, stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	for {
+		if messages, err := store.Subscribe(ctx, MetricPushChannel); err != nil {
+			klog.ErrorS(err, "failed to subscribe to metric pushes")
+		} else {
+			for message := range messages {
+				var push MetricPush
+				if err := json.Unmarshal(message, &push); err != nil {
+					metricPushes.WithLabelValues(metricPushResultRejected).Inc()
+					klog.V(4).ErrorS(err, "ignoring invalid metric push")
+					continue
+				}
+				if err := c.PushPodMetrics(push); err != nil {
+					klog.V(4).ErrorS(err, "ignoring metric push", "pod", push.Pod)
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(metricPushResubscribeBackoff):
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+var _ = Describe("MetricPush", func() {
+	var (
+		cache   *Cache
+		enabled bool
+		fetch   func(string) (map[string]*dto.MetricFamily, error)
+	)
+
+	waiting := func() float64 {
+		value, err := cache.GetPodModelMetric("p1", "llama-7b", metrics.NumRequestsWaiting)
+		Expect(err).ToNot(HaveOccurred())
+		return value.GetSimpleValue()
+	}
+
+	BeforeEach(func() {
+		enabled, fetch = metricPushEnabled, fetchEngineMetrics
+		metricPushEnabled = true
+		fetchEngineMetrics = func(string) (map[string]*dto.MetricFamily, error) {
+			var parser expfmt.TextParser
+			return parser.TextToMetricFamilies(strings.NewReader(`# TYPE vllm:num_requests_running gauge
+vllm:num_requests_running{model_name="llama-7b"} 1
+# TYPE vllm:num_requests_waiting gauge
+vllm:num_requests_waiting{model_name="llama-7b"} 1
+`))
+		}
+
+		pod := newHealthTestPod("p1", true)
+		pod.Status.PodIP = "10.0.0.1"
+		cache = &Cache{
+			pods:              map[string]*v1.Pod{},
+			podToModelMapping: map[string]map[string]struct{}{},
+			modelToPodMapping: map[string]map[string]*v1.Pod{},
+		}
+		cache.SetPod(pod, "llama-7b")
+	})
+
+	AfterEach(func() {
+		metricPushEnabled, fetchEngineMetrics = enabled, fetch
+	})
+
+	It("should prefer the pushed metrics to the scraped ones until they are stale", func() {
+		cache.updatePodMetrics()
+		Expect(waiting()).To(Equal(1.0))
+
+		Expect(cache.PushPodMetrics(MetricPush{Pod: "p1", Model: "llama-7b", Metrics: map[string]float64{metrics.NumRequestsWaiting: 5}})).To(Succeed())
+		Expect(waiting()).To(Equal(5.0))
+
+		cache.updatePodMetrics()
+		Expect(waiting()).To(Equal(5.0))
+		running, err := cache.GetPodModelMetric("p1", "llama-7b", metrics.NumRequestsRunning)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(running.GetSimpleValue()).To(Equal(1.0))
+
+		cache.metricPushes["p1"][pushedMetric{model: "llama-7b", metric: metrics.NumRequestsWaiting}] = time.Now().Add(-metricPushStaleness)
+		cache.updatePodMetrics()
+		Expect(waiting()).To(Equal(1.0))
+		Expect(cache.metricPushes).ToNot(HaveKey("p1"))
+	})
+
+	It("should reject the pushes it can't apply", func() {
+		Expect(cache.PushPodMetrics(MetricPush{Pod: "p2", Model: "llama-7b", Metrics: map[string]float64{metrics.NumRequestsWaiting: 5}})).ToNot(Succeed())
+		Expect(cache.PushPodMetrics(MetricPush{Pod: "p1", Metrics: map[string]float64{metrics.NumRequestsWaiting: 5}})).ToNot(Succeed())
+		Expect(cache.PushPodMetrics(MetricPush{Pod: "p1", Model: "llama-7b", Metrics: map[string]float64{metrics.TimeToFirstTokenSeconds: 5}})).ToNot(Succeed())
+
+		metricPushEnabled = false
+		Expect(cache.PushPodMetrics(MetricPush{Pod: "p1", Model: "llama-7b", Metrics: map[string]float64{metrics.NumRequestsWaiting: 5}})).To(MatchError(ErrMetricPushDisabled))
+		Expect(cache.podMetrics.load("p1")).To(BeNil())
+	})
+
+	It("should ingest the metrics published on the kv store", func() {
+		store := kvstore.NewMemoryStore()
+		stopCh, done := make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(done)
+			cache.receiveMetricPushes(store, stopCh)
+		}()
+		defer func() {
+			close(stopCh)
+			<-done
+		}()
+
+		Eventually(func() error {
+			if err := store.Publish(context.Background(), MetricPushChannel,
+				[]byte(`{"pod": "p1", "model": "llama-7b", "metrics": {"num_requests_waiting": 7}}`)); err != nil {
+				return err
+			}
+			_, err := cache.GetPodModelMetric("p1", "llama-7b", metrics.NumRequestsWaiting)
+			return err
+		}).Should(Succeed())
+		Expect(waiting()).To(Equal(7.0))
+	})
+})
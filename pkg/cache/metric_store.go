@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+// metricShardCount is the number of shards of the pod metrics, a power of two.
+const metricShardCount = 32
+
+// podMetricStore holds the metrics scraped from the pods, sharded by pod name so that routing decisions reading the
+// metrics of some pods don't wait on the scrape of others. The zero value is ready to use.
+type podMetricStore struct {
+	shards [metricShardCount]podMetricShard
+}
+
+type podMetricShard struct {
+	mu   sync.RWMutex
+	pods map[string]*podMetricRecord // pod_name: *podMetricRecord
+}
+
+// podMetricRecord holds the metrics of a pod. Records are never modified once stored: a scrape clones the record of
+// the pod, updates the clone and replaces the record, so readers use a record without holding any lock.
+type podMetricRecord struct {
+	pod    map[string]metrics.MetricValue            // metric_name: metric_val
+	models map[string]map[string]metrics.MetricValue // model_name: map[metric_name]metric_val
+}
+
+func (s *podMetricStore) shard(podName string) *podMetricShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(podName))
+	return &s.shards[h.Sum32()&(metricShardCount-1)]
+}
+
+// load returns the metrics of the pod, nil if it was never scraped.
+func (s *podMetricStore) load(podName string) *podMetricRecord {
+	shard := s.shard(podName)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	return shard.pods[podName]
+}
+
+// store replaces the metrics of the pod.
+func (s *podMetricStore) store(podName string, record *podMetricRecord) {
+	shard := s.shard(podName)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if shard.pods == nil {
+		shard.pods = map[string]*podMetricRecord{}
+	}
+	shard.pods[podName] = record
+}
+
+// update replaces the metrics of the pod by a clone updated by fn.
+func (s *podMetricStore) update(podName string, fn func(record *podMetricRecord)) {
+	shard := s.shard(podName)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if shard.pods == nil {
+		shard.pods = map[string]*podMetricRecord{}
+	}
+	record := shard.pods[podName].clone()
+	fn(record)
+	shard.pods[podName] = record
+}
+
+func (s *podMetricStore) delete(podName string) {
+	shard := s.shard(podName)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	delete(shard.pods, podName)
+}
+
+// rangePods calls fn with the metrics of every pod, shard by shard, without holding the lock of the shard.
+func (s *podMetricStore) rangePods(fn func(podName string, record *podMetricRecord)) {
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.RLock()
+		records := make(map[string]*podMetricRecord, len(shard.pods))
+		for podName, record := range shard.pods {
+			records[podName] = record
+		}
+		shard.mu.RUnlock()
+		for podName, record := range records {
+			fn(podName, record)
+		}
+	}
+}
+
+// clone returns a copy of the record to update, an empty record if nil. Metric values are immutable and shared.
+func (r *podMetricRecord) clone() *podMetricRecord {
+	clone := &podMetricRecord{
+		pod:    map[string]metrics.MetricValue{},
+		models: map[string]map[string]metrics.MetricValue{},
+	}
+	if r == nil {
+		return clone
+	}
+	for metricName, metricVal := range r.pod {
+		clone.pod[metricName] = metricVal
+	}
+	for modelName, modelMetrics := range r.models {
+		clone.models[modelName] = make(map[string]metrics.MetricValue, len(modelMetrics))
+		for metricName, metricVal := range modelMetrics {
+			clone.models[modelName][metricName] = metricVal
+		}
+	}
+	return clone
+}
+
+// metric returns the metric of the pod, the record may be nil.
+func (r *podMetricRecord) metric(metricName string) (metrics.MetricValue, bool) {
+	if r == nil {
+		return nil, false
+	}
+	metricVal, ok := r.pod[metricName]
+	return metricVal, ok
+}
+
+// modelMetrics returns the metrics of the model on the pod, nil if none. The record may be nil.
+func (r *podMetricRecord) modelMetrics(modelName string) map[string]metrics.MetricValue {
+	if r == nil {
+		return nil
+	}
+	return r.models[modelName]
+}
+
+// set sets the metric of the pod or of the model on the pod according to the metric scope, on a record being updated.
+func (r *podMetricRecord) set(modelName string, metricName string, scope metrics.MetricScope, metricValue metrics.MetricValue) error {
+	switch scope {
+	case metrics.PodMetricScope:
+		if modelName != "" {
+			return fmt.Errorf("modelName should be empty for scope %v", scope)
+		}
+		r.pod[metricName] = metricValue
+	case metrics.PodModelMetricScope:
+		if modelName == "" {
+			return fmt.Errorf("modelName should not be empty for scope %v", scope)
+		}
+		if r.models[modelName] == nil {
+			r.models[modelName] = map[string]metrics.MetricValue{}
+		}
+		r.models[modelName][metricName] = metricValue
+	default:
+		return fmt.Errorf("scope %v is not supported", scope)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+const (
+	benchmarkPods  = 64
+	benchmarkModel = "llama-7b"
+	// benchmarkScrapeLatency is the time an engine takes to serve its metrics.
+	benchmarkScrapeLatency = 500 * time.Microsecond
+)
+
+const benchmarkEngineMetrics = `
+# TYPE vllm:num_requests_running gauge
+vllm:num_requests_running{model_name="llama-7b"} 4
+# TYPE vllm:num_requests_waiting gauge
+vllm:num_requests_waiting{model_name="llama-7b"} 1
+# TYPE vllm:num_requests_swapped gauge
+vllm:num_requests_swapped{model_name="llama-7b"} 0
+# TYPE vllm:gpu_cache_usage_perc gauge
+vllm:gpu_cache_usage_perc{model_name="llama-7b"} 0.4
+# TYPE vllm:cpu_cache_usage_perc gauge
+vllm:cpu_cache_usage_perc{model_name="llama-7b"} 0
+# TYPE vllm:avg_prompt_throughput_toks_per_s gauge
+vllm:avg_prompt_throughput_toks_per_s{model_name="llama-7b"} 1200
+# TYPE vllm:avg_generation_throughput_toks_per_s gauge
+vllm:avg_generation_throughput_toks_per_s{model_name="llama-7b"} 300
+# TYPE vllm:time_to_first_token_seconds histogram
+vllm:time_to_first_token_seconds_sum{model_name="llama-7b"} 12
+vllm:time_to_first_token_seconds_count{model_name="llama-7b"} 100
+vllm:time_to_first_token_seconds_bucket{model_name="llama-7b",le="0.1"} 60
+vllm:time_to_first_token_seconds_bucket{model_name="llama-7b",le="+Inf"} 100
+`
+
+// newBenchmarkCache returns a cache of ready pods whose engines take benchmarkScrapeLatency to serve their metrics.
+func newBenchmarkCache(b *testing.B) *Cache {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(benchmarkEngineMetrics))
+	if err != nil {
+		b.Fatal(err)
+	}
+	fetch := fetchEngineMetrics
+	b.Cleanup(func() { fetchEngineMetrics = fetch })
+	fetchEngineMetrics = func(string) (map[string]*dto.MetricFamily, error) {
+		time.Sleep(benchmarkScrapeLatency)
+		return families, nil
+	}
+
+	c := &Cache{
+		pods:              map[string]*v1.Pod{},
+		podToModelMapping: map[string]map[string]struct{}{},
+		modelToPodMapping: map[string]map[string]*v1.Pod{},
+	}
+	for i := 0; i < benchmarkPods; i++ {
+		c.SetPod(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i)},
+			Status: v1.PodStatus{
+				PodIP:      fmt.Sprintf("10.0.0.%d", i),
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			},
+		}, benchmarkModel)
+	}
+	c.updatePodMetrics()
+	return c
+}
+
+// routeOnMetrics reads the load of every pod of the model, as the least-request routing does.
+func routeOnMetrics(b *testing.B, c *Cache) {
+	pods, err := c.GetPodsForModel(benchmarkModel)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, pod := range pods {
+		if _, err := c.GetPodModelMetric(pod.Name, benchmarkModel, metrics.NumRequestsRunning); err != nil {
+			b.Fatal(err)
+		}
+		_, _ = c.GetPodModelMetric(pod.Name, benchmarkModel, metrics.NumRequestsWaiting)
+		_, _ = c.GetPodModelMetric(pod.Name, benchmarkModel, metrics.GPUCacheUsagePerc)
+	}
+}
+
+// BenchmarkRoutingDuringScrape measures a routing decision while the pods are scraped every 50ms, the default refresh
+// interval, each engine taking benchmarkScrapeLatency to respond. An op is a routing decision, at 10k RPS the
+// decisions of a second must complete within a second of a core, i.e. an op must take less than 100µs.
+func BenchmarkRoutingDuringScrape(b *testing.B) {
+	c := newBenchmarkCache(b)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(defaultPodMetricRefreshIntervalInMS * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			c.updatePodMetrics()
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	// let the first scrape start.
+	time.Sleep(time.Millisecond)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			routeOnMetrics(b, c)
+		}
+	})
+}
+
+// BenchmarkRouting measures a routing decision without scrapes, the lower bound of BenchmarkRoutingDuringScrape.
+func BenchmarkRouting(b *testing.B) {
+	c := newBenchmarkCache(b)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			routeOnMetrics(b, c)
+		}
+	})
+}
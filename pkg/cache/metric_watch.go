@@ -0,0 +1,131 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// metricWatchBuffer is the number of events a watcher may lag behind before events are dropped.
+const metricWatchBuffer = 16
+
+// MetricThresholdEvent reports a metric of a pod crossing the threshold of a watch.
+type MetricThresholdEvent struct {
+	Pod       string
+	Metric    string
+	Value     float64
+	Threshold float64
+	// Above is true if the metric rose above the threshold, false if it fell back to or below it.
+	Above bool
+	Time  time.Time
+}
+
+// metricWatch is a watch of a metric of a pod, see WatchPodMetric.
+type metricWatch struct {
+	metric    string
+	threshold float64
+	above     bool
+	events    chan MetricThresholdEvent
+}
+
+// WatchPodMetric returns the events of the metric of the pod crossing the threshold, as soon as the metrics of the pod
+// are scraped or pushed. The metric is the one of the pod, or else the sum over the models on the pod, e.g. the
+// waiting requests of a base model and its adapters. The first event is sent when the metric is first above the
+// threshold. The channel is closed when ctx is done, events are dropped if the receiver lags behind.
+func (c *Cache) WatchPodMetric(ctx context.Context, podName, metricName string, threshold float64) <-chan MetricThresholdEvent {
+	watch := &metricWatch{
+		metric:    metricName,
+		threshold: threshold,
+		events:    make(chan MetricThresholdEvent, metricWatchBuffer),
+	}
+
+	c.watchMu.Lock()
+	if c.metricWatches == nil {
+		c.metricWatches = map[string][]*metricWatch{}
+	}
+	c.metricWatches[podName] = append(c.metricWatches[podName], watch)
+	c.evaluateMetricWatchLocked(podName, watch, c.podMetrics.load(podName), time.Now())
+	c.watchMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.watchMu.Lock()
+		defer c.watchMu.Unlock()
+		watches := c.metricWatches[podName]
+		for i, w := range watches {
+			if w == watch {
+				c.metricWatches[podName] = append(watches[:i:i], watches[i+1:]...)
+				break
+			}
+		}
+		if len(c.metricWatches[podName]) == 0 {
+			delete(c.metricWatches, podName)
+		}
+		close(watch.events)
+	}()
+	return watch.events
+}
+
+// notifyMetricWatches evaluates the watches of the pod against its current metrics.
+func (c *Cache) notifyMetricWatches(podName string) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	watches := c.metricWatches[podName]
+	if len(watches) == 0 {
+		return
+	}
+	record, now := c.podMetrics.load(podName), time.Now()
+	for _, watch := range watches {
+		c.evaluateMetricWatchLocked(podName, watch, record, now)
+	}
+}
+
+func (c *Cache) evaluateMetricWatchLocked(podName string, watch *metricWatch, record *podMetricRecord, now time.Time) {
+	value, ok := record.total(watch.metric)
+	if !ok || (value > watch.threshold) == watch.above {
+		return
+	}
+	watch.above = !watch.above
+	event := MetricThresholdEvent{Pod: podName, Metric: watch.metric, Value: value, Threshold: watch.threshold, Above: watch.above, Time: now}
+	select {
+	case watch.events <- event:
+	default:
+		klog.V(4).InfoS("dropped metric threshold event, the watcher lags behind", "pod", podName, "metric", watch.metric)
+	}
+}
+
+// total returns the metric of the pod, or else the sum of the metric over the models on the pod. The record may be nil.
+func (r *podMetricRecord) total(metricName string) (float64, bool) {
+	if value, ok := r.metric(metricName); ok {
+		return value.GetSimpleValue(), true
+	}
+	if r == nil {
+		return 0, false
+	}
+	var total float64
+	found := false
+	for _, modelMetrics := range r.models {
+		if value, ok := modelMetrics[metricName]; ok {
+			total += value.GetSimpleValue()
+			found = true
+		}
+	}
+	return total, found
+}
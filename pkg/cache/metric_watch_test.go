@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+var _ = Describe("MetricWatch", func() {
+	var (
+		cache  *Cache
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	setWaiting := func(modelName string, value float64) {
+		cache.SetPodModelMetric("p1", modelName, metrics.NumRequestsWaiting, &metrics.SimpleMetricValue{Value: value})
+	}
+
+	BeforeEach(func() {
+		cache = &Cache{}
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should send an event when the metric crosses the threshold", func() {
+		events := cache.WatchPodMetric(ctx, "p1", metrics.NumRequestsWaiting, 4)
+		setWaiting("llama", 2)
+		Expect(events).ToNot(Receive())
+
+		// the waiting requests of the base model and its adapter add up.
+		setWaiting("llama-lora", 3)
+		var event MetricThresholdEvent
+		Expect(events).To(Receive(&event))
+		Expect(event.Pod).To(Equal("p1"))
+		Expect(event.Value).To(Equal(5.0))
+		Expect(event.Above).To(BeTrue())
+
+		// no event while the metric stays above the threshold.
+		setWaiting("llama", 4)
+		Expect(events).ToNot(Receive())
+
+		setWaiting("llama", 1)
+		Expect(events).To(Receive(&event))
+		Expect(event.Value).To(Equal(4.0))
+		Expect(event.Above).To(BeFalse())
+	})
+
+	It("should send an event for a metric already above the threshold", func() {
+		cache.SetPodMetric("p1", metrics.GPUCacheUsagePerc, &metrics.SimpleMetricValue{Value: 0.9})
+		events := cache.WatchPodMetric(ctx, "p1", metrics.GPUCacheUsagePerc, 0.8)
+		Expect(events).To(Receive(HaveField("Above", true)))
+	})
+
+	It("should close the channel when the context is done", func() {
+		events := cache.WatchPodMetric(ctx, "p1", metrics.NumRequestsWaiting, 4)
+		cancel()
+		Eventually(events).Should(BeClosed())
+		Eventually(func() int {
+			cache.watchMu.Lock()
+			defer cache.watchMu.Unlock()
+			return len(cache.metricWatches)
+		}).Should(BeZero())
+		setWaiting("llama", 10)
+	})
+})
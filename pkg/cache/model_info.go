@@ -0,0 +1,295 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// ModelMaxModelLenAnnotationKey is the pod annotation declaring the max context length of the model.
+	ModelMaxModelLenAnnotationKey = "model.aibrix.ai/max-model-len"
+	// ModelDTypeAnnotationKey is the pod annotation declaring the data type of the model weights.
+	ModelDTypeAnnotationKey = "model.aibrix.ai/dtype"
+	// ModelQuantizationAnnotationKey is the pod annotation declaring the quantization method of the model.
+	ModelQuantizationAnnotationKey = "model.aibrix.ai/quantization"
+	// ModelEngineVersionAnnotationKey is the pod annotation declaring the version of the inference engine.
+	ModelEngineVersionAnnotationKey = "model.aibrix.ai/engine-version"
+	// ModelTokenizerAnnotationKey is the pod annotation declaring the tokenizer of the model, see tokenizer.New.
+	ModelTokenizerAnnotationKey = "model.aibrix.ai/tokenizer"
+	// ModelChatTemplateAnnotationKey is the pod annotation declaring the chat template of the model, see
+	// tokenizer.NewChatTemplate.
+	ModelChatTemplateAnnotationKey = "model.aibrix.ai/chat-template"
+	// ModelVisionEncoderAnnotationKey is the pod annotation naming the vision encoder of a multimodal model, which
+	// the gateway estimates the tokens of images with.
+	ModelVisionEncoderAnnotationKey = "model.aibrix.ai/vision-encoder"
+
+	// modelEngineLabelKey is the pod label naming the inference engine, vLLM is assumed if it's missing.
+	modelEngineLabelKey = "model.aibrix.ai/engine"
+	defaultModelEngine  = "vllm"
+
+	defaultModelInfoRefreshIntervalInSecs = 30
+	modelInfoRequestTimeout               = 2 * time.Second
+)
+
+var (
+	modelInfoRefreshInterval = getModelInfoRefreshInterval()
+	modelInfoClient          = &http.Client{Timeout: modelInfoRequestTimeout}
+)
+
+func getModelInfoRefreshInterval() time.Duration {
+	value := utils.LoadEnv("AIBRIX_MODEL_INFO_REFRESH_INTERVAL_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_MODEL_INFO_REFRESH_INTERVAL_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_MODEL_INFO_REFRESH_INTERVAL_S env value for model info refresh interval: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultModelInfoRefreshIntervalInSecs * time.Second
+}
+
+// ModelInfo is the metadata of a model served by the pods in the cache. Empty fields are unknown.
+type ModelInfo struct {
+	// MaxModelLen is the max context length in tokens, the smallest one across the pods of the model.
+	MaxModelLen int
+	// DType is the data type of the model weights, e.g. bfloat16.
+	DType string
+	// Quantization is the quantization method of the model, e.g. awq or fp8.
+	Quantization string
+	// Engine is the inference engine serving the model, e.g. vllm.
+	Engine string
+	// EngineVersion is the version of the inference engine.
+	EngineVersion string
+	// Tokenizer is the tokenizer spec of the model, e.g. hf:/models/llama/tokenizer.json.
+	Tokenizer string
+	// ChatTemplate is the chat template of the model, e.g. chatml.
+	ChatTemplate string
+	// VisionEncoder is the vision encoder of a multimodal model, e.g. qwen2-vl.
+	VisionEncoder string
+	// Capabilities are the sorted engine capabilities of the model, those of any of its pods, e.g. tool-calling.
+	Capabilities []string
+}
+
+// engineModelInfo is the metadata reported by the inference engine of a pod.
+type engineModelInfo struct {
+	engineVersion string
+	maxModelLen   map[string]int // model_name: max_model_len
+}
+
+// GetModelInfo returns the metadata of the model. Pod annotations take precedence over the metadata reported
+// by the engines. When the pods of the model disagree, e.g. during a rollout, the newest pod wins except for the
+// max context length, which is the smallest one so that a request fitting in it fits on every pod.
+func (c *Cache) GetModelInfo(modelName string) (*ModelInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	podsMap, ok := c.modelToPodMapping[modelName]
+	if !ok {
+		return nil, fmt.Errorf("model does not exist in the cache: %s", modelName)
+	}
+
+	pods := make([]*v1.Pod, 0, len(podsMap))
+	for _, pod := range podsMap {
+		pods = append(pods, pod)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if !pods[i].CreationTimestamp.Equal(&pods[j].CreationTimestamp) {
+			return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+		}
+		return pods[i].Name < pods[j].Name
+	})
+
+	info := &ModelInfo{}
+	capabilities := map[string]bool{}
+	for _, pod := range pods {
+		podInfo := c.podModelInfoLocked(pod, modelName)
+		for _, capability := range podInfo.Capabilities {
+			if !capabilities[capability] {
+				capabilities[capability] = true
+				info.Capabilities = append(info.Capabilities, capability)
+			}
+		}
+		if podInfo.MaxModelLen > 0 && (info.MaxModelLen == 0 || podInfo.MaxModelLen < info.MaxModelLen) {
+			info.MaxModelLen = podInfo.MaxModelLen
+		}
+		for _, field := range []struct{ target, value *string }{
+			{&info.DType, &podInfo.DType},
+			{&info.Quantization, &podInfo.Quantization},
+			{&info.Engine, &podInfo.Engine},
+			{&info.EngineVersion, &podInfo.EngineVersion},
+			{&info.Tokenizer, &podInfo.Tokenizer},
+			{&info.ChatTemplate, &podInfo.ChatTemplate},
+			{&info.VisionEncoder, &podInfo.VisionEncoder},
+		} {
+			if *field.value != "" {
+				*field.target = *field.value
+			}
+		}
+	}
+	sort.Strings(info.Capabilities)
+	return info, nil
+}
+
+// GetPodMaxModelLen returns the max context length of the model on the pod, 0 if unknown. Pods of a model may be
+// launched with different max context lengths, unlike GetModelInfo it is not the smallest one of the model.
+func (c *Cache) GetPodMaxModelLen(podName, modelName string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pod, ok := c.pods[podName]
+	if !ok {
+		return 0
+	}
+	return c.podModelInfoLocked(pod, modelName).MaxModelLen
+}
+
+// PodEngine returns the inference engine of the pod, named by its model.aibrix.ai/engine label, vLLM if unset.
+func PodEngine(pod *v1.Pod) string {
+	if engine := pod.Labels[modelEngineLabelKey]; engine != "" {
+		return engine
+	}
+	return defaultModelEngine
+}
+
+// podModelInfoLocked returns the metadata of the model on the pod, from its annotations first and its engine then.
+func (c *Cache) podModelInfoLocked(pod *v1.Pod, modelName string) ModelInfo {
+	info := ModelInfo{
+		DType:         pod.Annotations[ModelDTypeAnnotationKey],
+		Quantization:  pod.Annotations[ModelQuantizationAnnotationKey],
+		Engine:        PodEngine(pod),
+		EngineVersion: pod.Annotations[ModelEngineVersionAnnotationKey],
+		Tokenizer:     pod.Annotations[ModelTokenizerAnnotationKey],
+		ChatTemplate:  pod.Annotations[ModelChatTemplateAnnotationKey],
+		VisionEncoder: pod.Annotations[ModelVisionEncoderAnnotationKey],
+		Capabilities:  podCapabilities(pod),
+	}
+	if value, ok := pod.Annotations[ModelMaxModelLenAnnotationKey]; ok {
+		maxModelLen, err := strconv.Atoi(value)
+		if err != nil || maxModelLen <= 0 {
+			klog.V(4).InfoS("invalid max model len annotation", "pod", pod.Name, "value", value)
+		} else {
+			info.MaxModelLen = maxModelLen
+		}
+	}
+
+	if engineInfo, ok := c.engineModelInfo[pod.Name]; ok {
+		if info.MaxModelLen == 0 {
+			info.MaxModelLen = engineInfo.maxModelLen[modelName]
+		}
+		if info.EngineVersion == "" {
+			info.EngineVersion = engineInfo.engineVersion
+		}
+	}
+	return info
+}
+
+// refreshModelInfo fetches the model metadata from the engines of the ready pods that didn't report it yet.
+// The metadata doesn't change while the engine is running, it is fetched again once the pod becomes ready again.
+func (c *Cache) refreshModelInfo() {
+	c.mu.RLock()
+	var pods []*v1.Pod
+	for _, pod := range c.pods {
+		if _, ok := c.engineModelInfo[pod.Name]; !ok && c.isEngineReadyLocked(pod) {
+			pods = append(pods, pod)
+		}
+	}
+	c.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, pod := range pods {
+		wg.Add(1)
+		go func(pod *v1.Pod) {
+			defer wg.Done()
+			info, err := fetchEngineModelInfo(pod)
+			if err != nil {
+				klog.V(4).InfoS("failed to fetch model info from engine", "pod", pod.Name, "error", err)
+				return
+			}
+			c.setEngineModelInfo(pod.Name, info)
+		}(pod)
+	}
+	wg.Wait()
+}
+
+func (c *Cache) setEngineModelInfo(podName string, info *engineModelInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.pods[podName]; !ok {
+		return // pod was deleted while fetching
+	}
+	if c.engineModelInfo == nil {
+		c.engineModelInfo = map[string]*engineModelInfo{}
+	}
+	c.engineModelInfo[podName] = info
+	klog.V(4).InfoS("fetched model info from engine", "pod", podName, "engineVersion", info.engineVersion, "maxModelLen", info.maxModelLen)
+}
+
+// fetchEngineModelInfo reads the max context length of the models from the OpenAI compatible /v1/models endpoint
+// of the engine, and the engine version from its /version endpoint if it has one.
+func fetchEngineModelInfo(pod *v1.Pod) (*engineModelInfo, error) {
+	var models struct {
+		Data []struct {
+			ID          string `json:"id"`
+			MaxModelLen int    `json:"max_model_len"`
+		} `json:"data"`
+	}
+	if err := getEngineJSON(pod, "/v1/models", &models); err != nil {
+		return nil, err
+	}
+	info := &engineModelInfo{maxModelLen: make(map[string]int, len(models.Data))}
+	for _, model := range models.Data {
+		if model.MaxModelLen > 0 {
+			info.maxModelLen[model.ID] = model.MaxModelLen
+		}
+	}
+
+	var version struct {
+		Version string `json:"version"`
+	}
+	if err := getEngineJSON(pod, "/version", &version); err != nil {
+		klog.V(4).InfoS("engine doesn't report its version", "pod", pod.Name, "error", err)
+	}
+	info.engineVersion = version.Version
+	return info, nil
+}
+
+func getEngineJSON(pod *v1.Pod, path string, out interface{}) error {
+	resp, err := modelInfoClient.Get(fmt.Sprintf("http://%s:%d%s", pod.Status.PodIP, podPort, path))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
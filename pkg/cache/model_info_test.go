@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ModelInfo", func() {
+	var cache *Cache
+
+	BeforeEach(func() {
+		created := metav1.NewTime(time.Now())
+		p1 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              "p1",
+			CreationTimestamp: metav1.NewTime(created.Add(-time.Hour)),
+			Annotations: map[string]string{
+				ModelDTypeAnnotationKey:       "float16",
+				ModelMaxModelLenAnnotationKey: "8192",
+				ModelTokenizerAnnotationKey:   "remote",
+			},
+		}}
+		p2 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              "p2",
+			CreationTimestamp: created,
+			Annotations: map[string]string{
+				ModelDTypeAnnotationKey:        "bfloat16",
+				ModelChatTemplateAnnotationKey: "llama3",
+			},
+		}}
+		cache = &Cache{
+			pods:              map[string]*v1.Pod{"p1": p1, "p2": p2},
+			modelToPodMapping: map[string]map[string]*v1.Pod{"llama-7b": {"p1": p1, "p2": p2}},
+			engineModelInfo: map[string]*engineModelInfo{
+				"p1": {engineVersion: "0.6.1", maxModelLen: map[string]int{"llama-7b": 32768}},
+				"p2": {engineVersion: "0.6.2", maxModelLen: map[string]int{"llama-7b": 4096}},
+			},
+		}
+	})
+
+	It("should merge annotations and engine reported metadata", func() {
+		info, err := cache.GetModelInfo("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		// the annotation of p1 takes precedence over its engine, the smallest length across the pods wins.
+		Expect(info.MaxModelLen).To(Equal(4096))
+		// the newest pod wins.
+		Expect(info.DType).To(Equal("bfloat16"))
+		Expect(info.EngineVersion).To(Equal("0.6.2"))
+		Expect(info.Engine).To(Equal(defaultModelEngine))
+		Expect(info.Quantization).To(BeEmpty())
+		// only p1 declares its tokenizer.
+		Expect(info.Tokenizer).To(Equal("remote"))
+		Expect(info.ChatTemplate).To(Equal("llama3"))
+	})
+
+	It("should fall back to annotations without engine metadata", func() {
+		cache.engineModelInfo = nil
+		info, err := cache.GetModelInfo("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.MaxModelLen).To(Equal(8192))
+		Expect(info.EngineVersion).To(BeEmpty())
+	})
+
+	It("should discover the engine capabilities", func() {
+		cache.pods["p1"].Spec.Containers = []v1.Container{{
+			Command: []string{"/bin/sh", "-c"},
+			Args:    []string{"vllm serve llama-7b --enable-auto-tool-choice --speculative_config={\"num_speculative_tokens\":5}"},
+		}}
+		Expect(cache.GetPodCapabilities("p1")).To(Equal([]string{CapabilityJSONMode, CapabilitySpeculativeDecoding, CapabilityToolCalling}))
+		// the annotation replaces the discovered capabilities.
+		cache.pods["p2"].Annotations[ModelCapabilitiesAnnotationKey] = "multimodal, tool-calling"
+		Expect(cache.GetPodCapabilities("p2")).To(Equal([]string{CapabilityMultimodal, CapabilityToolCalling}))
+		Expect(cache.GetPodCapabilities("p3")).To(BeNil())
+
+		info, err := cache.GetModelInfo("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Capabilities).To(Equal([]string{CapabilityJSONMode, CapabilityMultimodal, CapabilitySpeculativeDecoding, CapabilityToolCalling}))
+	})
+
+	It("should fail for unknown models", func() {
+		_, err := cache.GetModelInfo("llama-13b")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should drop engine metadata of deleted pods", func() {
+		cache.setEngineModelInfo("p3", &engineModelInfo{engineVersion: "0.6.2"})
+		Expect(cache.engineModelInfo).ToNot(HaveKey("p3"))
+	})
+})
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+// NewOfflineCache initializes the cache without connecting to a cluster, e.g. to replay request traces against
+// routers. Pods and metrics are set by the caller, GetCache returns the same cache afterwards. If the cache was
+// already initialized by NewCache, that cache is returned.
+func NewOfflineCache() *Cache {
+	once.Do(func() {
+		instance.initOffline()
+	})
+	return &instance
+}
+
+// New returns an empty cache not connected to a cluster and independent of the cache returned by GetCache, e.g. for
+// tests. Pods and metrics are set with SetPod, SetPodMetric and SetPodModelMetric.
+func New() *Cache {
+	c := &Cache{}
+	c.initOffline()
+	return c
+}
+
+func (c *Cache) initOffline() {
+	c.initialized = true
+	c.pods = map[string]*v1.Pod{}
+	c.podToModelMapping = map[string]map[string]struct{}{}
+	c.modelToPodMapping = map[string]map[string]*v1.Pod{}
+	c.modelAdapters = map[string]*modelv1alpha1.ModelAdapter{}
+	c.requestTrace = &sync.Map{}
+	c.pendingRequests = &sync.Map{}
+	c.engineHealth = map[string]*engineHealth{}
+	c.drainingPods = map[string]*podDrain{}
+	c.nodeTopology = map[string]Topology{}
+}
+
+// SetPod adds the pod serving the model to the cache, replacing a pod with the same name.
+func (c *Cache) SetPod(pod *v1.Pod, modelName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pods[pod.Name] = pod
+	c.addPodAndModelMappingLocked(pod.Name, modelName)
+}
+
+// SetPodMetric sets a metric of the pod, as if it was scraped from the engine.
+func (c *Cache) SetPodMetric(podName, metricName string, value metrics.MetricValue) {
+	c.podMetrics.update(podName, func(record *podMetricRecord) {
+		record.pod[metricName] = value
+	})
+	c.notifyMetricWatches(podName)
+}
+
+// SetPodModelMetric sets a metric of the model on the pod, as if it was scraped from the engine.
+func (c *Cache) SetPodModelMetric(podName, modelName, metricName string, value metrics.MetricValue) {
+	c.podMetrics.update(podName, func(record *podMetricRecord) {
+		if record.models[modelName] == nil {
+			record.models[modelName] = map[string]metrics.MetricValue{}
+		}
+		record.models[modelName][metricName] = value
+	})
+	c.notifyMetricWatches(podName)
+}
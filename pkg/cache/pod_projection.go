@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// lastAppliedConfigAnnotationKey holds the whole manifest applied by kubectl, the cache doesn't need it.
+const lastAppliedConfigAnnotationKey = "kubectl.kubernetes.io/last-applied-configuration"
+
+// transformPod is the transform of the pod informer, so the informer store and the cache hold pod projections
+// instead of the pods decoded from the API server.
+func transformPod(obj interface{}) (interface{}, error) {
+	if pod, ok := obj.(*v1.Pod); ok {
+		return projectPod(pod), nil
+	}
+	return obj, nil
+}
+
+// projectPod returns a pod with only the fields read by the cache and the routers: identity, labels, annotations,
+// owners, node, readiness, IP, the command line of the containers and when they started. Managed fields, env,
+// volumes, resources and condition messages, most of the size of a pod, are dropped. The maps and slices of the pod
+// are shared with the projection rather than copied, the decoded pod is not used by anything else.
+func projectPod(pod *v1.Pod) *v1.Pod {
+	projection := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              pod.Name,
+			Namespace:         pod.Namespace,
+			UID:               pod.UID,
+			ResourceVersion:   pod.ResourceVersion,
+			CreationTimestamp: pod.CreationTimestamp,
+			DeletionTimestamp: pod.DeletionTimestamp,
+			Labels:            pod.Labels,
+			Annotations:       pod.Annotations,
+			OwnerReferences:   pod.OwnerReferences,
+		},
+		Spec: v1.PodSpec{
+			NodeName: pod.Spec.NodeName,
+		},
+		Status: v1.PodStatus{
+			Phase: pod.Status.Phase,
+			PodIP: pod.Status.PodIP,
+		},
+	}
+	if _, ok := pod.Annotations[lastAppliedConfigAnnotationKey]; ok {
+		delete(projection.Annotations, lastAppliedConfigAnnotationKey)
+	}
+	for _, container := range pod.Spec.Containers {
+		projection.Spec.Containers = append(projection.Spec.Containers, v1.Container{
+			Name:    container.Name,
+			Command: container.Command,
+			Args:    container.Args,
+		})
+	}
+	for _, condition := range pod.Status.Conditions {
+		projection.Status.Conditions = append(projection.Status.Conditions, v1.PodCondition{
+			Type:               condition.Type,
+			Status:             condition.Status,
+			LastTransitionTime: condition.LastTransitionTime,
+		})
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		projection.Status.ContainerStatuses = append(projection.Status.ContainerStatuses, v1.ContainerStatus{
+			Name:  status.Name,
+			Ready: status.Ready,
+			State: v1.ContainerState{Running: status.State.Running},
+		})
+	}
+	return projection
+}
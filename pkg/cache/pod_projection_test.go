@@ -0,0 +1,138 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+// newEnginePod returns a pod the way the API server sends a vLLM pod of a Deployment.
+func newEnginePod(now time.Time) *v1.Pod {
+	var env []v1.EnvVar
+	var fields []string
+	for i := 0; i < 20; i++ {
+		env = append(env, v1.EnvVar{Name: fmt.Sprintf("ENV_%d", i), Value: strings.Repeat("v", 40)})
+		fields = append(fields, fmt.Sprintf(`"k:{\"name\":\"ENV_%d\"}":{".":{},"f:name":{},"f:value":{}}`, i))
+	}
+	managedFields := &metav1.FieldsV1{Raw: []byte("{" + strings.Join(fields, ",") + "}")}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "llama-7b-5d4f8-x2x9k",
+			Namespace:         "default",
+			UID:               "5b7b6e2c-8c1f-4d0e-9a61-0f6f2a3c1d11",
+			ResourceVersion:   "12345",
+			CreationTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+			Labels:            map[string]string{modelIdentifier: "llama-7b", "pod-template-hash": "5d4f8"},
+			Annotations: map[string]string{
+				ModelMaxModelLenAnnotationKey:  "4096",
+				lastAppliedConfigAnnotationKey: strings.Repeat("{}", 2000),
+			},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "llama-7b-5d4f8", Controller: ptr.To(true)}},
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, FieldsV1: managedFields},
+				{Manager: "kubelet", Operation: metav1.ManagedFieldsOperationUpdate, FieldsV1: managedFields},
+			},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node-1",
+			Containers: []v1.Container{{
+				Name:    "vllm",
+				Image:   "vllm/vllm-openai:v0.8.0",
+				Command: []string{"python3", "-m", "vllm.entrypoints.openai.api_server"},
+				Args:    []string{"--model", "/models/llama-7b", "--enable-auto-tool-choice"},
+				Env:     env,
+				Resources: v1.ResourceRequirements{
+					Limits: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+				},
+				VolumeMounts: []v1.VolumeMount{{Name: "models", MountPath: "/models"}},
+			}},
+			Volumes: []v1.Volume{{Name: "models", VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "models"},
+			}}},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			PodIP: "10.0.0.1",
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodScheduled, Status: v1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(-50 * time.Second))},
+				{Type: v1.PodReady, Status: v1.ConditionTrue, Message: "all containers are ready"},
+			},
+			ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "vllm",
+				Ready: true,
+				Image: "vllm/vllm-openai:v0.8.0",
+				State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-40 * time.Second))}},
+			}},
+		},
+	}
+}
+
+var _ = Describe("PodProjection", func() {
+	It("should keep the fields read by the cache and the routers", func() {
+		now := time.Now()
+		pod := newEnginePod(now)
+		capabilities := podCapabilities(pod)
+		coldStart := coldStartSample(pod, now)
+
+		obj, err := transformPod(pod)
+		Expect(err).ToNot(HaveOccurred())
+		projection := obj.(*v1.Pod)
+
+		Expect(projection.Name).To(Equal("llama-7b-5d4f8-x2x9k"))
+		Expect(projection.Namespace).To(Equal("default"))
+		Expect(projection.UID).To(Equal(pod.UID))
+		Expect(projection.Labels).To(HaveKeyWithValue(modelIdentifier, "llama-7b"))
+		Expect(projection.Annotations).To(Equal(map[string]string{ModelMaxModelLenAnnotationKey: "4096"}))
+		Expect(projection.OwnerReferences).To(Equal(pod.OwnerReferences))
+		Expect(projection.Spec.NodeName).To(Equal("node-1"))
+		Expect(projection.Status.PodIP).To(Equal("10.0.0.1"))
+		Expect(projection.Status.Phase).To(Equal(v1.PodRunning))
+		Expect(podCapabilities(projection)).To(Equal(capabilities))
+		Expect(coldStartSample(projection, now)).To(Equal(coldStart))
+
+		Expect(projection.ManagedFields).To(BeEmpty())
+		Expect(projection.Spec.Volumes).To(BeEmpty())
+		Expect(projection.Spec.Containers[0].Env).To(BeEmpty())
+		Expect(projection.Spec.Containers[0].Resources.Limits).To(BeEmpty())
+		Expect(projection.Status.Conditions[1].Message).To(BeEmpty())
+
+		full, err := json.Marshal(newEnginePod(now))
+		Expect(err).ToNot(HaveOccurred())
+		projected, err := json.Marshal(projection)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(projected) * 4).To(BeNumerically("<", len(full)))
+	})
+
+	It("should pass the objects other than pods through", func() {
+		tombstone := toolscache.DeletedFinalStateUnknown{Key: "default/p1", Obj: &v1.Pod{}}
+		Expect(transformPod(tombstone)).To(Equal(tombstone))
+		var obj runtime.Object = &v1.Node{}
+		Expect(transformPod(obj)).To(BeIdenticalTo(obj))
+	})
+})
@@ -0,0 +1,199 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultReconcileIntervalInSecs = 60
+
+	correctionGhostPod       = "ghost_pod"
+	correctionMissingPod     = "missing_pod"
+	correctionGhostAdapter   = "ghost_adapter"
+	correctionMissingAdapter = "missing_adapter"
+	correctionStaleMapping   = "stale_mapping"
+	correctionMissingMapping = "missing_mapping"
+)
+
+var (
+	reconcileInterval = getReconcileInterval()
+
+	reconcileCorrections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_cache_reconcile_corrections_total",
+		Help: "Drifts from the informers corrected in the cache by the reconciliation, by kind: ghost_pod, missing_pod, ghost_adapter, missing_adapter, stale_mapping or missing_mapping.",
+	}, []string{"kind"})
+)
+
+func init() {
+	prometheus.MustRegister(reconcileCorrections)
+}
+
+// getReconcileInterval returns how often the cache is reconciled with the informers, 0 disables the reconciliation.
+func getReconcileInterval() time.Duration {
+	value := utils.LoadEnv("AIBRIX_CACHE_RECONCILE_INTERVAL_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_CACHE_RECONCILE_INTERVAL_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_CACHE_RECONCILE_INTERVAL_S env value for cache reconcile interval: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultReconcileIntervalInSecs * time.Second
+}
+
+func (c *Cache) runReconciliation(pods, adapters toolscache.Store, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.reconcile(pods, adapters)
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// reconcile corrects the drift of the cache from the stores of the pod and model adapter informers, left by missed
+// or misapplied events, and returns the corrections by kind. Ghost pods would keep being routed to, so they are
+// removed first, then the missing pods and the ghost and missing adapters are applied as their events would have been,
+// and last the model mappings are rebuilt from the pods and adapters where they differ. The stores are read under the
+// cache lock: they are updated before the event handlers are called, so an object applied by a handler is always
+// found in them. An event still queued for the handlers may be corrected ahead of them, it is then a no-op.
+func (c *Cache) reconcile(pods, adapters toolscache.Store) map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	corrections := map[string]int{}
+	for name, pod := range c.pods {
+		obj, exists, err := pods.GetByKey(pod.Namespace + "/" + name)
+		if err != nil {
+			continue
+		}
+		if exists {
+			if current, ok := obj.(*v1.Pod); ok && current.Labels[modelIdentifier] != "" {
+				continue
+			}
+		}
+		klog.InfoS("removing ghost pod from the cache", "pod", klog.KObj(pod))
+		c.deletePodLocked(pod)
+		corrections[correctionGhostPod]++
+	}
+	for _, obj := range pods.List() {
+		pod, ok := obj.(*v1.Pod)
+		if !ok || pod.Labels[modelIdentifier] == "" {
+			continue
+		}
+		if _, ok := pod.Labels[prewarmLabel]; ok {
+			continue
+		}
+		if _, ok := c.pods[pod.Name]; !ok {
+			klog.InfoS("adding missing pod to the cache", "pod", klog.KObj(pod))
+			c.addPodLocked(pod)
+			corrections[correctionMissingPod]++
+		}
+	}
+
+	for name, adapter := range c.modelAdapters {
+		if _, exists, err := adapters.GetByKey(adapter.Namespace + "/" + name); err == nil && !exists {
+			klog.InfoS("removing ghost model adapter from the cache", "modelAdapter", klog.KObj(adapter))
+			c.deleteModelAdapterLocked(adapter)
+			corrections[correctionGhostAdapter]++
+		}
+	}
+	for _, obj := range adapters.List() {
+		adapter, ok := obj.(*modelv1alpha1.ModelAdapter)
+		if !ok {
+			continue
+		}
+		if _, ok := c.modelAdapters[adapter.Name]; !ok {
+			klog.InfoS("adding missing model adapter to the cache", "modelAdapter", klog.KObj(adapter))
+			c.addModelAdapterLocked(adapter)
+			corrections[correctionMissingAdapter]++
+		}
+	}
+
+	c.reconcileMappingsLocked(corrections)
+
+	for kind, count := range corrections {
+		reconcileCorrections.WithLabelValues(kind).Add(float64(count))
+	}
+	if len(corrections) > 0 {
+		klog.InfoS("cache reconciled with the informers", "corrections", corrections)
+	}
+	return corrections
+}
+
+// reconcileMappingsLocked makes the model mappings match the pods and adapters of the cache: a pod serves the model
+// of its label and the adapters listing it as an instance, and the mappings of a model hold the cached pod.
+func (c *Cache) reconcileMappingsLocked(corrections map[string]int) {
+	expected := make(map[string]map[string]struct{}, len(c.pods))
+	for name, pod := range c.pods {
+		if modelName := pod.Labels[modelIdentifier]; modelName != "" {
+			expected[name] = map[string]struct{}{modelName: {}}
+		}
+	}
+	for name, adapter := range c.modelAdapters {
+		for _, podName := range adapter.Status.Instances {
+			if models, ok := expected[podName]; ok {
+				models[name] = struct{}{}
+			}
+		}
+	}
+
+	for podName, models := range c.podToModelMapping {
+		for modelName := range models {
+			if _, ok := expected[podName][modelName]; !ok {
+				c.deletePodAndModelMapping(podName, modelName)
+				corrections[correctionStaleMapping]++
+			}
+		}
+	}
+	for modelName, pods := range c.modelToPodMapping {
+		for podName, pod := range pods {
+			if _, ok := expected[podName][modelName]; !ok {
+				c.deletePodAndModelMapping(podName, modelName)
+				corrections[correctionStaleMapping]++
+			} else if pod != c.pods[podName] {
+				pods[podName] = c.pods[podName]
+				corrections[correctionStaleMapping]++
+			}
+		}
+	}
+	for podName, models := range expected {
+		for modelName := range models {
+			_, mapped := c.podToModelMapping[podName][modelName]
+			if _, ok := c.modelToPodMapping[modelName][podName]; !ok || !mapped {
+				c.addPodAndModelMappingLocked(podName, modelName)
+				corrections[correctionMissingMapping]++
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+func newModelPod(name, model string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: "default",
+		Labels:    map[string]string{modelIdentifier: model},
+	}}
+}
+
+func newModelAdapter(name string, instances ...string) *modelv1alpha1.ModelAdapter {
+	return &modelv1alpha1.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status:     modelv1alpha1.ModelAdapterStatus{Instances: instances},
+	}
+}
+
+var _ = Describe("Reconcile", func() {
+	It("should delete the pods and model adapters of tombstones", func() {
+		c := New()
+		p1 := newModelPod("p1", "llama-7b")
+		c.SetPod(p1, "llama-7b")
+		c.SetPod(newModelPod("p2", "llama-7b"), "llama-7b")
+		c.SetPodMetric("p1", metrics.NumRequestsWaiting, &metrics.SimpleMetricValue{Value: 1})
+		c.addModelAdapter(newModelAdapter("lora-1", "p1", "p2"))
+
+		Expect(func() {
+			c.deletePod(toolscache.DeletedFinalStateUnknown{Key: "default/p1", Obj: p1})
+		}).ToNot(Panic())
+		Expect(c.GetPods()).ToNot(HaveKey("p1"))
+		_, err := c.GetModelsForPod("p1")
+		Expect(err).To(HaveOccurred())
+		_, err = c.GetPodMetric("p1", metrics.NumRequestsWaiting)
+		Expect(err).To(HaveOccurred())
+
+		Expect(func() {
+			c.deleteModelAdapter(toolscache.DeletedFinalStateUnknown{Key: "default/lora-1", Obj: c.modelAdapters["lora-1"]})
+		}).ToNot(Panic())
+		Expect(c.modelAdapters).To(BeEmpty())
+		Expect(c.GetModelsForPod("p2")).To(Equal(map[string]struct{}{"llama-7b": {}}))
+
+		Expect(func() { c.deletePod(toolscache.DeletedFinalStateUnknown{Key: "default/p3"}) }).ToNot(Panic())
+		Expect(func() { c.deleteNode(toolscache.DeletedFinalStateUnknown{Key: "node-1"}) }).ToNot(Panic())
+	})
+
+	It("should remove the ghost pods missing from the informer", func() {
+		c := New()
+		for _, name := range []string{"p1", "p2", "p3"} {
+			c.SetPod(newModelPod(name, "llama-7b"), "llama-7b")
+		}
+		adapter := newModelAdapter("lora-1", "p1", "p3")
+		c.addModelAdapter(adapter)
+
+		pods := toolscache.NewStore(toolscache.MetaNamespaceKeyFunc)
+		Expect(pods.Add(newModelPod("p1", "llama-7b"))).To(Succeed())
+		unlabeled := newModelPod("p2", "llama-7b")
+		unlabeled.Labels = nil
+		Expect(pods.Add(unlabeled)).To(Succeed())
+		adapters := toolscache.NewStore(toolscache.MetaNamespaceKeyFunc)
+		Expect(adapters.Add(adapter)).To(Succeed())
+
+		before := testutil.ToFloat64(reconcileCorrections.WithLabelValues(correctionGhostPod))
+		Expect(c.reconcile(pods, adapters)).To(Equal(map[string]int{correctionGhostPod: 2}))
+		Expect(testutil.ToFloat64(reconcileCorrections.WithLabelValues(correctionGhostPod)) - before).To(Equal(2.0))
+
+		Expect(c.GetPods()).To(HaveLen(1))
+		Expect(c.GetPods()).To(HaveKey("p1"))
+		Expect(c.GetModelsForPod("p1")).To(Equal(map[string]struct{}{"llama-7b": {}, "lora-1": {}}))
+		Expect(c.GetPodsForModel("lora-1")).To(HaveLen(1))
+
+		Expect(c.reconcile(pods, adapters)).To(BeEmpty())
+	})
+
+	It("should apply the pods and model adapters missed by the cache", func() {
+		c := New()
+		c.addModelAdapter(newModelAdapter("lora-2", "p1"))
+
+		pods := toolscache.NewStore(toolscache.MetaNamespaceKeyFunc)
+		Expect(pods.Add(newModelPod("p1", "llama-7b"))).To(Succeed())
+		prewarm := newModelPod("prewarm-1", "llama-7b")
+		prewarm.Labels[prewarmLabel] = "llama-7b"
+		Expect(pods.Add(prewarm)).To(Succeed())
+		adapters := toolscache.NewStore(toolscache.MetaNamespaceKeyFunc)
+		Expect(adapters.Add(newModelAdapter("lora-1", "p1"))).To(Succeed())
+
+		Expect(c.reconcile(pods, adapters)).To(Equal(map[string]int{
+			correctionMissingPod:     1,
+			correctionGhostAdapter:   1,
+			correctionMissingAdapter: 1,
+		}))
+		Expect(c.GetPods()).To(HaveLen(1))
+		Expect(c.GetModelsForPod("p1")).To(Equal(map[string]struct{}{"llama-7b": {}, "lora-1": {}}))
+		Expect(c.modelAdapters).To(HaveKey("lora-1"))
+		Expect(c.modelAdapters).ToNot(HaveKey("lora-2"))
+
+		Expect(c.reconcile(pods, adapters)).To(BeEmpty())
+	})
+
+	It("should rebuild the model mappings that drifted", func() {
+		c := New()
+		c.SetPod(newModelPod("p1", "llama-7b"), "llama-7b")
+		c.SetPod(newModelPod("p2", "llama-7b"), "llama-7b")
+		c.modelToPodMapping["llama-7b"]["p9"] = newModelPod("p9", "llama-7b")
+		c.podToModelMapping["p9"] = map[string]struct{}{"llama-7b": {}}
+		delete(c.podToModelMapping, "p1")
+		c.modelToPodMapping["llama-7b"]["p2"] = newModelPod("p2", "llama-7b")
+
+		pods := toolscache.NewStore(toolscache.MetaNamespaceKeyFunc)
+		Expect(pods.Add(newModelPod("p1", "llama-7b"))).To(Succeed())
+		Expect(pods.Add(newModelPod("p2", "llama-7b"))).To(Succeed())
+		adapters := toolscache.NewStore(toolscache.MetaNamespaceKeyFunc)
+
+		Expect(c.reconcile(pods, adapters)).To(Equal(map[string]int{correctionStaleMapping: 2, correctionMissingMapping: 1}))
+		Expect(c.podToModelMapping).ToNot(HaveKey("p9"))
+		Expect(c.GetModelsForPod("p1")).To(Equal(map[string]struct{}{"llama-7b": {}}))
+		Expect(c.modelToPodMapping["llama-7b"]).To(HaveLen(2))
+		Expect(c.modelToPodMapping["llama-7b"]["p2"]).To(BeIdenticalTo(c.pods["p2"]))
+
+		Expect(c.reconcile(pods, adapters)).To(BeEmpty())
+	})
+
+	It("should route the lora adapters of an updated pod with its new state", func() {
+		c := New()
+		oldPod := newModelPod("p1", "llama-7b")
+		c.SetPod(oldPod, "llama-7b")
+		c.addModelAdapter(newModelAdapter("lora-1", "p1"))
+
+		newPod := oldPod.DeepCopy()
+		newPod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+		c.updatePod(oldPod, newPod)
+		Expect(c.modelToPodMapping["lora-1"]["p1"]).To(BeIdenticalTo(newPod))
+		Expect(c.modelToPodMapping["llama-7b"]["p1"]).To(BeIdenticalTo(newPod))
+	})
+})
@@ -16,9 +16,12 @@ limitations under the License.
 package cache
 
 import (
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/vllm-project/aibrix/pkg/config"
 )
 
 type RequestTraceMetaKey int
@@ -44,11 +47,8 @@ const (
 	// v2: Added meta data include version(meta_v), bucket precision(meta_precision), and interval(meta_interval_sec) to notify client the trace interval.
 	// v3: Added the number of total requests(meta_total_reqs) and pending requests(meta_pending_reqs) for uncompleted requests.
 	RequestTraceVersion = 3
-	// Trace write interval
-	RequestTraceWriteInterval = 10 * time.Second
-	// Max tolerable write delay to write ticks.
-	// For example for RequestTraceWriteInterval = 10s and MaxRequestTraceIntervalOffset = 500ms, the trace should be written before X:00.5s, X:10.5s, .., X:50.5s.
-	MaxRequestTraceIntervalOffset = 500 * time.Millisecond
+	// Default trace write interval, see config.RequestTrace for the configured one.
+	RequestTraceWriteInterval = config.DefaultRequestTraceInterval
 	// The precision of buckets in trace. 0.1 means requests will be split into buckets of .1 according to log2(tokens)
 	RequestTracePrecision = 0.1
 )
@@ -123,24 +123,41 @@ func (t *RequestTrace) Unlock() {
 	t.mu.Unlock()
 }
 
-func (t *RequestTrace) ToMapLocked(total_pending int32) map[string]int {
+func (t *RequestTrace) ToMapLocked(total_pending int32, interval time.Duration) map[string]int {
 	ret := make(map[string]int, int(t.numKeys)+int(RequestTraceNumMetaKeys))
 	t.trace.Range(func(_key, _count any) bool {
 		ret[_key.(string)] = int(*(_count.(*int32)))
 		return true
 	})
 	ret[MetaKeyVersionKey.ToString()] = RequestTraceVersion
-	ret[MetaKeyIntervalInSeconds.ToString()] = int(RequestTraceWriteInterval / time.Second)
+	ret[MetaKeyIntervalInSeconds.ToString()] = int(interval / time.Second)
 	ret[MetaKeyTracePrecision.ToString()] = int(1 / RequestTracePrecision)
 	ret[MetaKeyTotalRequests.ToString()] = int(atomic.LoadInt32(&t.numRequests))
 	ret[MetaKeyPendingRequests.ToString()] = int(total_pending) // Disregard differences between pending in or out of window in this version.
 	return ret
 }
 
-func (t *RequestTrace) ToMap(total_pending int32) map[string]int {
+// mergeRequestTraces adds the trace of a window to the trace of the same window written by the other replicas: the
+// buckets and the total and pending requests are summed, the other meta keys describe the trace and are taken from it.
+func mergeRequestTraces(written, trace map[string]int) map[string]int {
+	merged := make(map[string]int, len(written)+len(trace))
+	for key, count := range written {
+		merged[key] = count
+	}
+	for key, count := range trace {
+		if strings.HasPrefix(key, "meta_") && key != MetaKeyTotalRequests.ToString() && key != MetaKeyPendingRequests.ToString() {
+			merged[key] = count
+		} else {
+			merged[key] += count
+		}
+	}
+	return merged
+}
+
+func (t *RequestTrace) ToMap(total_pending int32, interval time.Duration) map[string]int {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	return t.ToMapLocked(total_pending)
+	return t.ToMapLocked(total_pending, interval)
 }
 
 func (t *RequestTrace) RecycleLocked() {
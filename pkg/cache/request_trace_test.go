@@ -58,7 +58,7 @@ var _ = Describe("reqeustTrace", func() {
 		trace.AddRequest("no use now", "no use now")
 		trace.DoneRequest("no use now", 0)
 		trace.AddRequestTrace("no use now", "1:1")
-		traceMap := trace.ToMap(2)
+		traceMap := trace.ToMap(2, RequestTraceWriteInterval)
 		expected := []byte("{\"1:1\":1,\"meta_interval_sec\":10,\"meta_pending_reqs\":2,\"meta_precision\":10,\"meta_total_reqs\":1,\"meta_v\":3}")
 		marshaled, err := json.Marshal(traceMap)
 		Expect(err).To(BeNil())
@@ -71,7 +71,7 @@ var _ = Describe("reqeustTrace", func() {
 		trace.DoneRequest("no use now", 0)
 		trace.DoneRequest("no use now", 0)
 		// TODO: Since in window pending requests are not used in this version, this test will never fail.
-		traceMap := trace.ToMap(0)
+		traceMap := trace.ToMap(0, RequestTraceWriteInterval)
 		Expect(traceMap[MetaKeyPendingRequests.ToString()]).To(Equal(0))
 	})
 
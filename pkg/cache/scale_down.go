@@ -0,0 +1,205 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// PodDeletionCostAnnotation is honored by the ReplicaSet controller when choosing which pods to remove on scale-down.
+	// Pods with lower cost are deleted first.
+	PodDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
+
+	defaultPodDeletionCostRefreshIntervalInSecs = 30
+	// inflightRequestOwnershipWeight converts an in-flight request into prefix block equivalents,
+	// dropping a pod with running requests means re-prefill for all of them.
+	inflightRequestOwnershipWeight = 16
+)
+
+var (
+	podDeletionCostEnabled         = utils.LoadEnv("AIBRIX_POD_DELETION_COST_ENABLED", "false") == "true"
+	podDeletionCostRefreshInterval = getPodDeletionCostRefreshInterval()
+)
+
+func getPodDeletionCostRefreshInterval() time.Duration {
+	value := utils.LoadEnv("AIBRIX_POD_DELETION_COST_REFRESH_INTERVAL_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_POD_DELETION_COST_REFRESH_INTERVAL_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_POD_DELETION_COST_REFRESH_INTERVAL_S env value for pod deletion cost refresh interval: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultPodDeletionCostRefreshIntervalInSecs * time.Second
+}
+
+// PodOwnershipProvider reports how much reusable routing state (e.g. prefix cache blocks) each pod owns for a model.
+type PodOwnershipProvider interface {
+	// PodOwnership returns pod_name: owned units for the given model.
+	PodOwnership(model string) map[string]int
+}
+
+// ScaleDownCandidate describes the cost of removing a pod from a model deployment.
+type ScaleDownCandidate struct {
+	PodName          string
+	OwnedBlocks      int
+	InflightRequests float64
+	// Serving is false if the pod is not Ready, draining or its engine is unhealthy, such pods are the best victims.
+	Serving bool
+	// Score is the estimated KV cache loss in prefix block equivalents, lower is a better victim.
+	Score float64
+}
+
+// AddOwnershipProvider registers a provider whose ownership is taken into account when ranking scale-down victims.
+func (c *Cache) AddOwnershipProvider(provider PodOwnershipProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ownershipProviders = append(c.ownershipProviders, provider)
+}
+
+// GetScaleDownVictims returns the pods of a model ranked from the cheapest to the most expensive to remove, the pods not
+// serving first.
+func (c *Cache) GetScaleDownVictims(modelName string) ([]ScaleDownCandidate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.getScaleDownVictimsLocked(modelName)
+}
+
+func (c *Cache) getScaleDownVictimsLocked(modelName string) ([]ScaleDownCandidate, error) {
+	pods, ok := c.modelToPodMapping[modelName]
+	if !ok {
+		return nil, fmt.Errorf("model does not exist in the cache: %s", modelName)
+	}
+
+	owned := map[string]int{}
+	for _, provider := range c.ownershipProviders {
+		for podName, blocks := range provider.PodOwnership(modelName) {
+			owned[podName] += blocks
+		}
+	}
+
+	candidates := make([]ScaleDownCandidate, 0, len(pods))
+	for podName, pod := range pods {
+		candidate := ScaleDownCandidate{
+			PodName:          podName,
+			OwnedBlocks:      owned[podName],
+			InflightRequests: c.getInflightRequestsLocked(podName, modelName),
+			Serving:          pod == nil || c.isEngineReadyLocked(pod),
+		}
+		// the KV cache of a pod that doesn't serve is lost anyway.
+		if candidate.Serving {
+			candidate.Score = float64(candidate.OwnedBlocks) + inflightRequestOwnershipWeight*candidate.InflightRequests
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Serving != candidates[j].Serving {
+			return !candidates[i].Serving
+		}
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score < candidates[j].Score
+		}
+		return candidates[i].PodName < candidates[j].PodName
+	})
+	return candidates, nil
+}
+
+// getInflightRequestsLocked returns the running and waiting requests the engine reported, or the requests the gateway
+// replicas routed to the pod if more, as they show up before the next scrape.
+func (c *Cache) getInflightRequestsLocked(podName, modelName string) float64 {
+	var inflight float64
+	record := c.podMetrics.load(podName)
+	for _, metricName := range []string{metrics.NumRequestsRunning, metrics.NumRequestsWaiting} {
+		if metricVal, ok := record.modelMetrics(modelName)[metricName]; ok {
+			inflight += metricVal.GetSimpleValue()
+		} else if metricVal, ok := record.metric(metricName); ok {
+			inflight += metricVal.GetSimpleValue()
+		}
+	}
+	routed := float64(c.GetPodInflightBatchItems(podName) + c.getPodRemoteInflightBatchItemsLocked(podName, time.Now()))
+	return math.Max(inflight, routed)
+}
+
+// updatePodDeletionCost publishes the victim ranking as pod deletion cost, so a scale-down issued by the
+// PodAutoscaler removes the pods owning the least KV cache first.
+func (c *Cache) updatePodDeletionCost() {
+	if !c.IsLeader() {
+		return
+	}
+	costs := map[types.NamespacedName]string{}
+
+	c.mu.RLock()
+	for modelName := range c.modelToPodMapping {
+		candidates, err := c.getScaleDownVictimsLocked(modelName)
+		if err != nil {
+			continue
+		}
+		for _, candidate := range candidates {
+			pod, ok := c.pods[candidate.PodName]
+			if !ok || pod.Labels[modelIdentifier] != modelName {
+				// lora adapters share the base model pod, the base model ranking is authoritative.
+				continue
+			}
+			if _, ok := pod.Annotations[PodDrainAnnotation]; ok {
+				// the PodAutoscaler set the cost of the pods it drains for removal.
+				continue
+			}
+			cost := strconv.Itoa(int(math.Min(candidate.Score, math.MaxInt32)))
+			if !candidate.Serving {
+				// below idle serving pods, which cost 0.
+				cost = "-1"
+			}
+			if pod.Annotations[PodDeletionCostAnnotation] == cost {
+				continue
+			}
+			costs[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = cost
+		}
+	}
+	c.mu.RUnlock()
+
+	for key, cost := range costs {
+		if err := patchPodDeletionCost(c.kubeClient, key, cost); err != nil {
+			klog.ErrorS(err, "failed to update pod deletion cost", "pod", key, "cost", cost)
+			continue
+		}
+		klog.V(4).InfoS("updated pod deletion cost", "pod", key, "cost", cost)
+	}
+}
+
+func patchPodDeletionCost(client kubernetes.Interface, key types.NamespacedName, cost string) error {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, PodDeletionCostAnnotation, cost)
+	_, err := client.CoreV1().Pods(key.Namespace).Patch(context.Background(), key.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeOwnershipProvider map[string]int
+
+func (p fakeOwnershipProvider) PodOwnership(model string) map[string]int {
+	return p
+}
+
+var _ = Describe("ScaleDownVictims", func() {
+	It("should rank pods with the least ownership first", func() {
+		cache := &Cache{
+			modelToPodMapping: map[string]map[string]*v1.Pod{
+				"llama-7b": {"p1": nil, "p2": nil, "p3": nil},
+			},
+		}
+		cache.SetPodMetric("p3", metrics.NumRequestsRunning, &metrics.SimpleMetricValue{Value: 2})
+		cache.AddOwnershipProvider(fakeOwnershipProvider{"p1": 40, "p2": 8})
+
+		victims, err := cache.GetScaleDownVictims("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(victims).To(HaveLen(3))
+		Expect(victims[0].PodName).To(Equal("p2"))
+		Expect(victims[1].PodName).To(Equal("p3"))
+		Expect(victims[1].Score).To(Equal(float64(2 * inflightRequestOwnershipWeight)))
+		Expect(victims[2].PodName).To(Equal("p1"))
+		Expect(victims[2].OwnedBlocks).To(Equal(40))
+	})
+
+	It("should rank pods not serving first and count routed requests", func() {
+		readyPod := func(name string) *v1.Pod {
+			return &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Status:     v1.PodStatus{Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}},
+			}
+		}
+		cache := &Cache{
+			modelToPodMapping: map[string]map[string]*v1.Pod{
+				"llama-7b": {"p1": readyPod("p1"), "p2": readyPod("p2"), "p3": readyPod("p3")},
+			},
+			// p3 never served, e.g. it is still loading the model.
+			engineHealth: map[string]*engineHealth{"p1": {succeeded: true}, "p2": {succeeded: true}},
+		}
+		cache.AddOwnershipProvider(fakeOwnershipProvider{"p1": 40, "p3": 100})
+		// the requests routed to p2 are not scraped yet.
+		cache.AddPodBatchItems("p2", 3)
+
+		victims, err := cache.GetScaleDownVictims("llama-7b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(victims[0].PodName).To(Equal("p3"))
+		Expect(victims[0].Serving).To(BeFalse())
+		Expect(victims[0].Score).To(BeZero())
+		Expect(victims[1].PodName).To(Equal("p1"))
+		Expect(victims[2].PodName).To(Equal("p2"))
+		Expect(victims[2].InflightRequests).To(Equal(float64(3)))
+	})
+
+	It("should return error for unknown model", func() {
+		cache := &Cache{modelToPodMapping: map[string]map[string]*v1.Pod{}}
+		_, err := cache.GetScaleDownVictims("unknown")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,152 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// ModelIdleMetricRefreshIntervalAnnotationKey is the pod annotation overriding AIBRIX_POD_METRIC_IDLE_REFRESH_INTERVAL_MS
+	// for the pods of a model, as a duration, e.g. 2s. 0s scrapes the pods at the refresh interval even when idle.
+	ModelIdleMetricRefreshIntervalAnnotationKey = "model.aibrix.ai/idle-metric-refresh-interval"
+
+	defaultPodMetricIdleScrapes = 3
+)
+
+var (
+	// podMetricIdleRefreshInterval is the scrape interval of the pods serving no request, 0 scrapes every pod at
+	// podMetricRefreshInterval.
+	podMetricIdleRefreshInterval = getPodMetricIdleRefreshInterval()
+	// podMetricIdleScrapes is the number of consecutive scrapes without requests before a pod is scraped at the idle
+	// interval, so that a pod between two requests keeps the refresh interval.
+	podMetricIdleScrapes = getPodMetricIdleScrapes()
+)
+
+func getPodMetricIdleRefreshInterval() time.Duration {
+	value := utils.LoadEnv("AIBRIX_POD_METRIC_IDLE_REFRESH_INTERVAL_MS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_POD_METRIC_IDLE_REFRESH_INTERVAL_MS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_POD_METRIC_IDLE_REFRESH_INTERVAL_MS env value for idle pod metrics refresh interval: %d ms", intValue)
+			return time.Duration(intValue) * time.Millisecond
+		}
+	}
+	return 0
+}
+
+func getPodMetricIdleScrapes() int {
+	value := utils.LoadEnv("AIBRIX_POD_METRIC_IDLE_SCRAPES", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_POD_METRIC_IDLE_SCRAPES: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_POD_METRIC_IDLE_SCRAPES env value for idle pod metrics scrapes: %d", intValue)
+			return intValue
+		}
+	}
+	return defaultPodMetricIdleScrapes
+}
+
+// scrapeActivity tracks the scrapes of a pod and whether it serves requests, to scrape idle pods less often.
+type scrapeActivity struct {
+	idleScrapes  int // consecutive scrapes reporting no running or waiting request
+	lastScrape   time.Time
+	lastDuration time.Duration
+	failures     int
+}
+
+// podIdleMetricRefreshInterval returns the scrape interval of the pod when idle, from its annotation or else from
+// AIBRIX_POD_METRIC_IDLE_REFRESH_INTERVAL_MS.
+func podIdleMetricRefreshInterval(pod *v1.Pod) time.Duration {
+	if value, ok := pod.Annotations[ModelIdleMetricRefreshIntervalAnnotationKey]; ok {
+		interval, err := time.ParseDuration(value)
+		if err == nil && interval >= 0 {
+			return interval
+		}
+		klog.V(4).InfoS("invalid idle metric refresh interval annotation", "pod", pod.Name, "value", value)
+	}
+	return podMetricIdleRefreshInterval
+}
+
+// isScrapeDueLocked returns true if the pod must be scraped at now. Pods are scraped at every refresh unless they
+// reported no request for podMetricIdleScrapes scrapes, then at their idle interval until the gateway routes a
+// request to them.
+func (c *Cache) isScrapeDueLocked(pod *v1.Pod, now time.Time) bool {
+	interval := podIdleMetricRefreshInterval(pod)
+	if interval <= 0 {
+		return true
+	}
+	activity, ok := c.scrapeActivity[pod.Name]
+	if !ok || activity.idleScrapes < podMetricIdleScrapes || c.GetPodInflightRequests(pod.Name) > 0 {
+		return true
+	}
+	return now.Sub(activity.lastScrape) >= interval
+}
+
+// recordScrapeActivityLocked records whether the scraped metrics of the pod report requests. A failed scrape counts as
+// active, for the engine health to be tracked at the refresh interval.
+func (c *Cache) recordScrapeActivityLocked(podName string, record *podMetricRecord, err error, now time.Time, duration time.Duration) {
+	if c.scrapeActivity == nil {
+		c.scrapeActivity = map[string]*scrapeActivity{}
+	}
+	activity, ok := c.scrapeActivity[podName]
+	if !ok {
+		activity = &scrapeActivity{}
+		c.scrapeActivity[podName] = activity
+	}
+	activity.lastScrape = now
+	activity.lastDuration = duration
+	podMetricScrapeDuration.Observe(duration.Seconds())
+	if err != nil {
+		activity.failures++
+	}
+	if err == nil && !record.serving() && c.GetPodInflightRequests(podName) == 0 {
+		activity.idleScrapes++
+	} else {
+		activity.idleScrapes = 0
+	}
+}
+
+// serving returns true if the engine reported running or waiting requests, for any model.
+func (r *podMetricRecord) serving() bool {
+	if r == nil {
+		return false
+	}
+	loads := []map[string]metrics.MetricValue{r.pod}
+	for _, modelMetrics := range r.models {
+		loads = append(loads, modelMetrics)
+	}
+	for _, load := range loads {
+		for _, metricName := range []string{metrics.NumRequestsRunning, metrics.NumRequestsWaiting} {
+			if value, ok := load[metricName]; ok && value.GetSimpleValue() > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
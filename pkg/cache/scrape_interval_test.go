@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	v1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("ScrapeInterval", func() {
+	var (
+		cache    *Cache
+		running  map[string]int
+		scrapes  map[string]int
+		fetch    func(string) (map[string]*dto.MetricFamily, error)
+		interval time.Duration
+	)
+
+	newPod := func(name, ip string) *v1.Pod {
+		pod := newHealthTestPod(name, true)
+		pod.Status.PodIP = ip
+		return pod
+	}
+
+	BeforeEach(func() {
+		fetch, interval = fetchEngineMetrics, podMetricIdleRefreshInterval
+		podMetricIdleRefreshInterval = time.Hour
+		running = map[string]int{}
+		scrapes = map[string]int{}
+		fetchEngineMetrics = func(url string) (map[string]*dto.MetricFamily, error) {
+			scrapes[url]++
+			var parser expfmt.TextParser
+			return parser.TextToMetricFamilies(strings.NewReader(fmt.Sprintf(
+				"# TYPE vllm:num_requests_running gauge\nvllm:num_requests_running{model_name=\"llama-7b\"} %d\n", running[url])))
+		}
+
+		cache = &Cache{
+			pods:              map[string]*v1.Pod{},
+			podToModelMapping: map[string]map[string]struct{}{},
+			modelToPodMapping: map[string]map[string]*v1.Pod{},
+		}
+		cache.SetPod(newPod("p1", "10.0.0.1"), "llama-7b")
+	})
+
+	AfterEach(func() {
+		fetchEngineMetrics, podMetricIdleRefreshInterval = fetch, interval
+	})
+
+	url := fmt.Sprintf("http://10.0.0.1:%d/metrics", podPort)
+
+	It("should scrape idle pods at the idle interval until a request is routed to them", func() {
+		for i := 0; i < podMetricIdleScrapes+2; i++ {
+			cache.updatePodMetrics()
+		}
+		Expect(scrapes[url]).To(Equal(podMetricIdleScrapes))
+
+		cache.AddPodRequest("p1")
+		cache.updatePodMetrics()
+		Expect(scrapes[url]).To(Equal(podMetricIdleScrapes + 1))
+
+		// the pod is active until it reported no request for podMetricIdleScrapes scrapes again.
+		cache.DonePodRequest("p1")
+		for i := 0; i < podMetricIdleScrapes+2; i++ {
+			cache.updatePodMetrics()
+		}
+		Expect(scrapes[url]).To(Equal(2*podMetricIdleScrapes + 1))
+
+		// the idle interval elapsed.
+		cache.scrapeActivity["p1"].lastScrape = time.Now().Add(-time.Hour)
+		cache.updatePodMetrics()
+		Expect(scrapes[url]).To(Equal(2*podMetricIdleScrapes + 2))
+	})
+
+	It("should scrape pods serving requests at every refresh", func() {
+		running[url] = 2
+		for i := 0; i < podMetricIdleScrapes+2; i++ {
+			cache.updatePodMetrics()
+		}
+		Expect(scrapes[url]).To(Equal(podMetricIdleScrapes + 2))
+	})
+
+	It("should honor the idle interval of the model", func() {
+		pod := newPod("p1", "10.0.0.1")
+		pod.Annotations = map[string]string{ModelIdleMetricRefreshIntervalAnnotationKey: "0s"}
+		cache.SetPod(pod, "llama-7b")
+		for i := 0; i < podMetricIdleScrapes+2; i++ {
+			cache.updatePodMetrics()
+		}
+		Expect(scrapes[url]).To(Equal(podMetricIdleScrapes + 2))
+	})
+})
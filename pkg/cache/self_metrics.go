@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cachePodsDesc = prometheus.NewDesc(
+		"aibrix_gateway_cache_pods",
+		"Number of pods tracked by the cache.",
+		nil, nil,
+	)
+	cacheModelsDesc = prometheus.NewDesc(
+		"aibrix_gateway_cache_models",
+		"Number of models tracked by the cache.",
+		nil, nil,
+	)
+	cacheRequestTracesDesc = prometheus.NewDesc(
+		"aibrix_gateway_cache_request_traces",
+		"Number of requests traced in the current request trace window.",
+		nil, nil,
+	)
+	cacheTraceWriteQueueDesc = prometheus.NewDesc(
+		"aibrix_gateway_cache_request_trace_write_queue",
+		"Number of request trace batches waiting to be written to the kv store.",
+		nil, nil,
+	)
+	podMetricScrapeLastDurationDesc = prometheus.NewDesc(
+		"aibrix_gateway_pod_metric_scrape_last_duration_seconds",
+		"Duration of the last metrics scrape of the pod.",
+		[]string{"pod"}, nil,
+	)
+	podMetricScrapeFailuresDesc = prometheus.NewDesc(
+		"aibrix_gateway_pod_metric_scrape_failures_total",
+		"Failed metrics scrapes of the pod since it was added to the cache.",
+		[]string{"pod"}, nil,
+	)
+
+	podMetricScrapeDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "aibrix_gateway_pod_metric_scrape_duration_seconds",
+		Help:    "Duration of the metrics scrapes of the pods, fetching and parsing included.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 12),
+	})
+	cacheLockWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "aibrix_gateway_cache_lock_wait_seconds",
+		Help:    "Time spent waiting for the cache lock, by mode.",
+		Buckets: prometheus.ExponentialBuckets(0.000001, 4, 10),
+	}, []string{"mode"})
+	cacheReadLockWait  = cacheLockWait.WithLabelValues("read")
+	cacheWriteLockWait = cacheLockWait.WithLabelValues("write")
+)
+
+func init() {
+	prometheus.MustRegister(podMetricScrapeDuration, cacheLockWait)
+}
+
+// timedRWMutex is a sync.RWMutex observing the time spent waiting for it in aibrix_gateway_cache_lock_wait_seconds.
+type timedRWMutex struct {
+	sync.RWMutex
+}
+
+func (m *timedRWMutex) Lock() {
+	start := time.Now()
+	m.RWMutex.Lock()
+	cacheWriteLockWait.Observe(time.Since(start).Seconds())
+}
+
+func (m *timedRWMutex) RLock() {
+	start := time.Now()
+	m.RWMutex.RLock()
+	cacheReadLockWait.Observe(time.Since(start).Seconds())
+}
+
+// cacheCollector exports the size of the cache and the scrape state of the pods at scrape time, so pods leaving the
+// cache don't leave stale series behind.
+type cacheCollector struct {
+	cache *Cache
+}
+
+var _ prometheus.Collector = (*cacheCollector)(nil)
+
+func (k *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cachePodsDesc
+	ch <- cacheModelsDesc
+	ch <- cacheRequestTracesDesc
+	ch <- cacheTraceWriteQueueDesc
+	ch <- podMetricScrapeLastDurationDesc
+	ch <- podMetricScrapeFailuresDesc
+}
+
+func (k *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	k.cache.mu.RLock()
+	defer k.cache.mu.RUnlock()
+
+	ch <- prometheus.MustNewConstMetric(cachePodsDesc, prometheus.GaugeValue, float64(len(k.cache.pods)))
+	ch <- prometheus.MustNewConstMetric(cacheModelsDesc, prometheus.GaugeValue, float64(len(k.cache.modelToPodMapping)))
+	ch <- prometheus.MustNewConstMetric(cacheRequestTracesDesc, prometheus.GaugeValue, float64(atomic.LoadInt32(&k.cache.numRequestsTraces)))
+	if k.cache.traceWriter != nil {
+		ch <- prometheus.MustNewConstMetric(cacheTraceWriteQueueDesc, prometheus.GaugeValue, float64(len(k.cache.traceWriter.queue)))
+	}
+	for podName, activity := range k.cache.scrapeActivity {
+		ch <- prometheus.MustNewConstMetric(podMetricScrapeLastDurationDesc, prometheus.GaugeValue, activity.lastDuration.Seconds(), podName)
+		ch <- prometheus.MustNewConstMetric(podMetricScrapeFailuresDesc, prometheus.CounterValue, float64(activity.failures), podName)
+	}
+}
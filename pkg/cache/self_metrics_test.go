@@ -0,0 +1,75 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	v1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("SelfMetrics", func() {
+	var fetch func(string) (map[string]*dto.MetricFamily, error)
+
+	BeforeEach(func() {
+		fetch = fetchEngineMetrics
+		fetchEngineMetrics = func(string) (map[string]*dto.MetricFamily, error) {
+			return map[string]*dto.MetricFamily{}, errors.New("connection refused")
+		}
+	})
+
+	AfterEach(func() {
+		fetchEngineMetrics = fetch
+	})
+
+	It("should export the size of the cache and the failed scrapes of the pods", func() {
+		cache := &Cache{
+			pods:              map[string]*v1.Pod{},
+			podToModelMapping: map[string]map[string]struct{}{},
+			modelToPodMapping: map[string]map[string]*v1.Pod{},
+		}
+		for _, name := range []string{"p1", "p2"} {
+			pod := newHealthTestPod(name, true)
+			pod.Status.PodIP = "10.0.0.1"
+			cache.SetPod(pod, "llama-7b")
+		}
+		cache.updatePodMetrics()
+		cache.updatePodMetrics()
+
+		Expect(testutil.CollectAndCompare(&cacheCollector{cache: cache}, strings.NewReader(`
+# HELP aibrix_gateway_cache_models Number of models tracked by the cache.
+# TYPE aibrix_gateway_cache_models gauge
+aibrix_gateway_cache_models 1
+# HELP aibrix_gateway_cache_pods Number of pods tracked by the cache.
+# TYPE aibrix_gateway_cache_pods gauge
+aibrix_gateway_cache_pods 2
+# HELP aibrix_gateway_pod_metric_scrape_failures_total Failed metrics scrapes of the pod since it was added to the cache.
+# TYPE aibrix_gateway_pod_metric_scrape_failures_total counter
+aibrix_gateway_pod_metric_scrape_failures_total{pod="p1"} 2
+aibrix_gateway_pod_metric_scrape_failures_total{pod="p2"} 2
+`), "aibrix_gateway_cache_models", "aibrix_gateway_cache_pods", "aibrix_gateway_pod_metric_scrape_failures_total")).To(Succeed())
+
+		// deleted pods leave no series behind.
+		cache.deletePod(cache.pods["p2"])
+		Expect(testutil.CollectAndCount(&cacheCollector{cache: cache}, "aibrix_gateway_pod_metric_scrape_failures_total")).To(Equal(1))
+	})
+})
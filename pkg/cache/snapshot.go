@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+// PodSnapshot is a point in time copy of the cache state of a pod.
+type PodSnapshot struct {
+	Pod          *v1.Pod
+	EngineReady  bool
+	Draining     bool
+	Inflight     int32
+	Topology     Topology
+	Models       []string
+	Metrics      map[string]metrics.MetricValue            // metric_name: metric_val
+	ModelMetrics map[string]map[string]metrics.MetricValue // model_name: map[metric_name]metric_val
+}
+
+// GetPodSnapshots returns a copy of the models and metrics of every pod in the cache.
+func (c *Cache) GetPodSnapshots() []PodSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	snapshots := make([]PodSnapshot, 0, len(c.pods))
+	for podName, pod := range c.pods {
+		snapshot := PodSnapshot{
+			Pod:          pod,
+			EngineReady:  c.isEngineReadyLocked(pod),
+			Draining:     c.isDrainingLocked(pod),
+			Inflight:     c.GetPodInflightRequests(podName),
+			Topology:     c.topologyLocked(pod),
+			Metrics:      map[string]metrics.MetricValue{},
+			ModelMetrics: map[string]map[string]metrics.MetricValue{},
+		}
+		for modelName := range c.podToModelMapping[podName] {
+			snapshot.Models = append(snapshot.Models, modelName)
+		}
+		record := c.podMetrics.load(podName)
+		if record == nil {
+			record = &podMetricRecord{}
+		}
+		for metricName, metricVal := range record.pod {
+			snapshot.Metrics[metricName] = metricVal
+		}
+		for modelName, modelMetrics := range record.models {
+			snapshot.ModelMetrics[modelName] = map[string]metrics.MetricValue{}
+			for metricName, metricVal := range modelMetrics {
+				snapshot.ModelMetrics[modelName][metricName] = metricVal
+			}
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
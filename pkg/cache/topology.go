@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	v1 "k8s.io/api/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// nodePoolLabels are the node labels identifying the node pool on common providers, in order of precedence.
+var nodePoolLabels = []string{
+	"karpenter.sh/nodepool",
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"kubernetes.azure.com/agentpool",
+}
+
+// Topology locates a pod or node in the cluster. Fields are empty if unknown.
+type Topology struct {
+	Zone     string `json:"zone,omitempty"`
+	Node     string `json:"node,omitempty"`
+	NodePool string `json:"nodePool,omitempty"`
+}
+
+func nodeTopology(node *v1.Node) Topology {
+	topology := Topology{Node: node.Name, Zone: node.Labels[v1.LabelTopologyZone]}
+	for _, label := range nodePoolLabels {
+		if pool, ok := node.Labels[label]; ok {
+			topology.NodePool = pool
+			break
+		}
+	}
+	return topology
+}
+
+func (c *Cache) addNode(obj interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node := obj.(*v1.Node)
+	if c.nodeTopology == nil {
+		c.nodeTopology = map[string]Topology{}
+	}
+	c.nodeTopology[node.Name] = nodeTopology(node)
+	klog.V(4).InfoS("node topology updated", "node", node.Name, "topology", c.nodeTopology[node.Name])
+}
+
+func (c *Cache) updateNode(oldObj interface{}, newObj interface{}) {
+	c.addNode(newObj)
+}
+
+func (c *Cache) deleteNode(obj interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return
+	}
+	delete(c.nodeTopology, node.Name)
+}
+
+// topologyLocked returns the topology of the pod from the labels of its node, pod labels take precedence.
+func (c *Cache) topologyLocked(pod *v1.Pod) Topology {
+	topology := c.nodeTopology[pod.Spec.NodeName]
+	topology.Node = pod.Spec.NodeName
+	if zone, ok := pod.Labels[v1.LabelTopologyZone]; ok {
+		topology.Zone = zone
+	}
+	return topology
+}
+
+// GetPodTopology returns the zone, node and node pool of the pod.
+func (c *Cache) GetPodTopology(podName string) (Topology, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pod, ok := c.pods[podName]
+	if !ok {
+		return Topology{}, false
+	}
+	return c.topologyLocked(pod), true
+}
+
+// GetNodeTopology returns the zone and node pool of the node, e.g. to locate the gateway replica itself.
+func (c *Cache) GetNodeTopology(nodeName string) (Topology, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	topology, ok := c.nodeTopology[nodeName]
+	return topology, ok
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	aibrixconfig "github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+const (
+	// traceWriteQueueSize bounds the batches waiting for the kv store, one minute of traces at the default interval.
+	traceWriteQueueSize = 6
+	traceWriteAttempts  = 3
+	traceWriteTimeout   = 2 * time.Second
+	traceWriteBackoff   = 500 * time.Millisecond
+
+	traceWriteSuccess = "success"
+	traceWriteRetried = "retried"
+	traceWriteDropped = "dropped"
+)
+
+var traceWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "aibrix_gateway_request_trace_writes_total",
+	Help: "Request trace batches written to the kv store, by result. Dropped batches are lost for the autoscaler.",
+}, []string{"result"})
+
+func init() {
+	prometheus.MustRegister(traceWrites)
+}
+
+// traceBatch holds the traces of all models for one write interval.
+type traceBatch struct {
+	roundT int64
+	// traces are merged into the traces of the same window written by the other replicas.
+	traces   map[string]map[string]int // key: trace
+	encoding string
+	// values are written as they are, e.g. the meta key.
+	values  map[string][]byte
+	ttl     time.Duration
+	created time.Time
+}
+
+// traceWriter writes request traces in the background, so a slow or unavailable kv store never holds up
+// request accounting. Batches are retried a few times, and the oldest one is dropped when the queue is full.
+type traceWriter struct {
+	store kvstore.Store
+	queue chan traceBatch
+}
+
+func newTraceWriter(store kvstore.Store) *traceWriter {
+	return &traceWriter{
+		store: store,
+		queue: make(chan traceBatch, traceWriteQueueSize),
+	}
+}
+
+// enqueue never blocks, if the queue is full the oldest batch makes room for the new one.
+func (w *traceWriter) enqueue(batch traceBatch) {
+	for {
+		select {
+		case w.queue <- batch:
+			return
+		default:
+		}
+		select {
+		case dropped := <-w.queue:
+			traceWrites.WithLabelValues(traceWriteDropped).Inc()
+			klog.Warningf("request trace queue is full, dropped the traces of %v", dropped.roundT)
+		default:
+		}
+	}
+}
+
+func (w *traceWriter) run(stopCh <-chan struct{}) {
+	for {
+		select {
+		case batch := <-w.queue:
+			w.write(batch, stopCh)
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// write merges the traces of the batch and sends its values, retrying until it succeeds, runs out of attempts or
+// would already have expired in the kv store.
+func (w *traceWriter) write(batch traceBatch, stopCh <-chan struct{}) {
+	backoff := traceWriteBackoff
+	for attempt := 1; ; attempt++ {
+		if time.Since(batch.created) >= batch.ttl {
+			traceWrites.WithLabelValues(traceWriteDropped).Inc()
+			klog.Warningf("request traces of %v expired before being written", batch.roundT)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), traceWriteTimeout)
+		err := w.writeBatch(ctx, batch, batch.ttl-time.Since(batch.created))
+		cancel()
+		if err == nil {
+			traceWrites.WithLabelValues(traceWriteSuccess).Inc()
+			klog.V(5).Infof("writeRequestTraceWithKey: %v", batch.roundT)
+			return
+		}
+		if attempt == traceWriteAttempts {
+			traceWrites.WithLabelValues(traceWriteDropped).Inc()
+			klog.ErrorS(err, "failed to write request traces, dropping them", "roundT", batch.roundT, "attempts", attempt)
+			return
+		}
+
+		traceWrites.WithLabelValues(traceWriteRetried).Inc()
+		klog.V(4).InfoS("failed to write request traces, retrying", "roundT", batch.roundT, "attempt", attempt, "error", err)
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// writeBatch merges the traces one key at a time, a trace is removed from the batch once merged so a retry doesn't
+// count it twice, then writes the values in one round trip.
+func (w *traceWriter) writeBatch(ctx context.Context, batch traceBatch, ttl time.Duration) error {
+	for key, trace := range batch.traces {
+		err := w.store.Update(ctx, key, func(current []byte) ([]byte, error) {
+			if current == nil {
+				return aibrixconfig.EncodeRequestTrace(trace, batch.encoding)
+			}
+			written, err := aibrixconfig.DecodeRequestTrace(current)
+			if err != nil {
+				klog.ErrorS(err, "overwriting invalid request trace", "key", key)
+				return aibrixconfig.EncodeRequestTrace(trace, batch.encoding)
+			}
+			return aibrixconfig.EncodeRequestTrace(mergeRequestTraces(written, trace), batch.encoding)
+		}, ttl)
+		if err != nil {
+			return err
+		}
+		delete(batch.traces, key)
+	}
+	if len(batch.values) == 0 {
+		return nil
+	}
+	return w.store.SetMany(ctx, batch.values, ttl)
+}
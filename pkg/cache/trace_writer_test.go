@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+// flakyStore fails the first writes.
+type flakyStore struct {
+	*kvstore.MemoryStore
+	failures int
+}
+
+func (s *flakyStore) SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("connection reset")
+	}
+	return s.MemoryStore.SetMany(ctx, values, ttl)
+}
+
+func traceWriteCount(result string) float64 {
+	var metric dto.Metric
+	Expect(traceWrites.WithLabelValues(result).Write(&metric)).To(Succeed())
+	return metric.GetCounter().GetValue()
+}
+
+var _ = Describe("traceWriter", func() {
+	batch := func(roundT int64) traceBatch {
+		return traceBatch{roundT: roundT, values: map[string][]byte{"aibrix:llama-7b_request_trace_100": []byte("{}")}, ttl: time.Minute, created: time.Now()}
+	}
+
+	It("should retry failed writes", func() {
+		store := &flakyStore{MemoryStore: kvstore.NewMemoryStore(), failures: 1}
+		writer := newTraceWriter(store)
+		retried := traceWriteCount(traceWriteRetried)
+
+		writer.write(batch(100), nil)
+		Expect(traceWriteCount(traceWriteRetried)).To(Equal(retried + 1))
+		_, err := store.Get(context.Background(), "aibrix:llama-7b_request_trace_100")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should drop batches after the last attempt", func() {
+		store := &flakyStore{MemoryStore: kvstore.NewMemoryStore(), failures: traceWriteAttempts}
+		writer := newTraceWriter(store)
+		dropped := traceWriteCount(traceWriteDropped)
+
+		writer.write(batch(100), nil)
+		Expect(traceWriteCount(traceWriteDropped)).To(Equal(dropped + 1))
+		_, err := store.Get(context.Background(), "aibrix:llama-7b_request_trace_100")
+		Expect(err).To(MatchError(kvstore.ErrNotFound))
+	})
+
+	It("should drop expired batches without writing them", func() {
+		store := kvstore.NewMemoryStore()
+		writer := newTraceWriter(store)
+		expired := batch(100)
+		expired.created = time.Now().Add(-expired.ttl)
+
+		writer.write(expired, nil)
+		_, err := store.Get(context.Background(), "aibrix:llama-7b_request_trace_100")
+		Expect(err).To(MatchError(kvstore.ErrNotFound))
+	})
+
+	It("should not merge the traces again when retrying", func() {
+		store := &flakyStore{MemoryStore: kvstore.NewMemoryStore(), failures: 1}
+		writer := newTraceWriter(store)
+		traces := batch(100)
+		traces.traces = map[string]map[string]int{"aibrix:qwen-7b_request_trace_100": {"0:0": 2, "meta_total_reqs": 2}}
+
+		writer.write(traces, nil)
+		writer.write(batch(100), nil)
+		value, err := store.Get(context.Background(), "aibrix:qwen-7b_request_trace_100")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(value)).To(MatchJSON(`{"0:0": 2, "meta_total_reqs": 2}`))
+	})
+
+	It("should drop the oldest batch when the queue is full", func() {
+		writer := newTraceWriter(kvstore.NewMemoryStore())
+		for roundT := int64(0); roundT <= traceWriteQueueSize; roundT++ {
+			writer.enqueue(batch(roundT * 10))
+		}
+		Expect(writer.queue).To(HaveLen(traceWriteQueueSize))
+		Expect((<-writer.queue).roundT).To(Equal(int64(10)))
+	})
+})
@@ -0,0 +1,70 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// prewarmLabel marks the pods of the model pre-warming DaemonSets, a Ready one has the weights of the model on its node.
+const prewarmLabel = "model.aibrix.ai/prewarm"
+
+// updateWarmNodesLocked records whether the node of a pre-warming pod has the weights of its model, it returns false
+// for other pods.
+func (c *Cache) updateWarmNodesLocked(pod *v1.Pod, deleted bool) bool {
+	modelName, ok := pod.Labels[prewarmLabel]
+	if !ok {
+		return false
+	}
+	if pod.Spec.NodeName == "" {
+		return true
+	}
+	if deleted || pod.DeletionTimestamp != nil || !utils.IsPodReady(pod) {
+		if nodes, ok := c.warmNodes[modelName]; ok && nodes[pod.Spec.NodeName] == pod.Name {
+			delete(nodes, pod.Spec.NodeName)
+			if len(nodes) == 0 {
+				delete(c.warmNodes, modelName)
+			}
+		}
+		return true
+	}
+	if c.warmNodes == nil {
+		c.warmNodes = map[string]map[string]string{}
+	}
+	if c.warmNodes[modelName] == nil {
+		c.warmNodes[modelName] = map[string]string{}
+	}
+	c.warmNodes[modelName][pod.Spec.NodeName] = pod.Name
+	return true
+}
+
+// GetWarmNodes returns the sorted nodes which have the weights of the model pre-pulled.
+func (c *Cache) GetWarmNodes(modelName string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	nodes := make([]string, 0, len(c.warmNodes[modelName]))
+	for node := range c.warmNodes[modelName] {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
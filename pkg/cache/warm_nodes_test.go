@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("WarmNodes", func() {
+	var cache *Cache
+
+	BeforeEach(func() {
+		cache = &Cache{
+			pods:              map[string]*v1.Pod{},
+			podToModelMapping: map[string]map[string]struct{}{},
+			modelToPodMapping: map[string]map[string]*v1.Pod{},
+		}
+	})
+
+	prewarm := func(name, node string, ready bool) *v1.Pod {
+		pod := newHealthTestPod(name, ready)
+		pod.Labels = map[string]string{prewarmLabel: "llama-7b"}
+		pod.Spec.NodeName = node
+		return pod
+	}
+
+	It("should track the nodes of ready pre-warming pods", func() {
+		p1 := prewarm("p1", "n1", false)
+		cache.addPod(p1)
+		Expect(cache.GetWarmNodes("llama-7b")).To(BeEmpty())
+
+		cache.updatePod(p1, prewarm("p1", "n1", true))
+		cache.addPod(prewarm("p2", "n2", true))
+		Expect(cache.GetWarmNodes("llama-7b")).To(Equal([]string{"n1", "n2"}))
+		// pre-warming pods are not serving pods of the model.
+		Expect(cache.pods).To(BeEmpty())
+
+		cache.deletePod(prewarm("p1", "n1", true))
+		Expect(cache.GetWarmNodes("llama-7b")).To(Equal([]string{"n2"}))
+		cache.updatePod(prewarm("p2", "n2", true), prewarm("p2", "n2", false))
+		Expect(cache.GetWarmNodes("llama-7b")).To(BeEmpty())
+	})
+
+	It("should keep a node warm when a stale pod of it goes away", func() {
+		cache.addPod(prewarm("p1", "n1", true))
+		cache.addPod(prewarm("p2", "n1", true))
+		cache.deletePod(prewarm("p1", "n1", true))
+		Expect(cache.GetWarmNodes("llama-7b")).To(Equal([]string{"n1"}))
+	})
+})
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cacheapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeCacheService struct{}
+
+func (f *fakeCacheService) ListModels(ctx context.Context, req *ListModelsRequest) (*ListModelsResponse, error) {
+	return &ListModelsResponse{Models: []*ModelInfo{{Name: "llama-7b", Pods: []string{"p1", "p2"}, ReadyPods: 1}}}, nil
+}
+
+func (f *fakeCacheService) ListPods(ctx context.Context, req *ListPodsRequest) (*ListPodsResponse, error) {
+	if req.Model != "llama-7b" {
+		return nil, errors.New("unknown model")
+	}
+	return &ListPodsResponse{Pods: []*PodInfo{{
+		Name:         "p1",
+		Namespace:    "default",
+		Ready:        true,
+		Models:       []string{"llama-7b"},
+		Metrics:      map[string]float64{"num_requests_running": 3},
+		ModelMetrics: map[string]*MetricValues{"llama-7b": {Values: map[string]float64{"avg_prompt_throughput_toks_per_s": 12.5}}},
+	}}}, nil
+}
+
+func (f *fakeCacheService) GetPrefixCacheStats(ctx context.Context, req *GetPrefixCacheStatsRequest) (*PrefixCacheStats, error) {
+	return &PrefixCacheStats{Enabled: true, Lookups: 10, Hits: 4, ModelPodBlocks: map[string]*PodBlocks{"llama-7b": {Blocks: map[string]int32{"p1": 7}}}}, nil
+}
+
+func (f *fakeCacheService) ListRoutingDecisions(ctx context.Context, req *ListRoutingDecisionsRequest) (*ListRoutingDecisionsResponse, error) {
+	return &ListRoutingDecisionsResponse{Decisions: []*RoutingDecision{{
+		Timestamp:  timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		RequestId:  "r1",
+		Model:      req.Model,
+		TargetPod:  "10.0.0.1:8000",
+		Candidates: []*CandidateScore{{Pod: "p1", Score: 0.5}},
+	}}}, nil
+}
+
+func (f *fakeCacheService) ExplainRouting(ctx context.Context, req *ExplainRoutingRequest) (*ExplainRoutingResponse, error) {
+	return &ExplainRoutingResponse{Model: req.Model, RoutingStrategy: req.Headers["routing-strategy"], Pods: []string{"p1"}, Explanations: []*RoutingExplanation{{
+		RoutingStrategy: req.Strategies[0],
+		TargetPod:       "p1",
+		TargetAddress:   "10.0.0.1:8000",
+		Candidates:      []*CandidateScore{{Pod: "p1", Score: 2}},
+	}}}, nil
+}
+
+func TestCacheServiceRoundTrip(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	RegisterCacheServiceServer(s, &fakeCacheService{})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := NewCacheServiceClient(conn)
+	ctx := context.Background()
+
+	models, err := client.ListModels(ctx, &ListModelsRequest{})
+	require.NoError(t, err)
+	require.Len(t, models.Models, 1)
+	assert.True(t, proto.Equal(&ModelInfo{Name: "llama-7b", Pods: []string{"p1", "p2"}, ReadyPods: 1}, models.Models[0]))
+
+	pods, err := client.ListPods(ctx, &ListPodsRequest{Model: "llama-7b"})
+	require.NoError(t, err)
+	require.Len(t, pods.Pods, 1)
+	assert.Equal(t, 3.0, pods.Pods[0].Metrics["num_requests_running"])
+	assert.Equal(t, 12.5, pods.Pods[0].ModelMetrics["llama-7b"].Values["avg_prompt_throughput_toks_per_s"])
+
+	_, err = client.ListPods(ctx, &ListPodsRequest{Model: "unknown"})
+	assert.ErrorContains(t, err, "unknown model")
+
+	stats, err := client.GetPrefixCacheStats(ctx, &GetPrefixCacheStatsRequest{})
+	require.NoError(t, err)
+	assert.True(t, stats.Enabled)
+	assert.Equal(t, int64(4), stats.Hits)
+	assert.Equal(t, int32(7), stats.ModelPodBlocks["llama-7b"].Blocks["p1"])
+
+	decisions, err := client.ListRoutingDecisions(ctx, &ListRoutingDecisionsRequest{Model: "llama-7b"})
+	require.NoError(t, err)
+	require.Len(t, decisions.Decisions, 1)
+	assert.Equal(t, "llama-7b", decisions.Decisions[0].Model)
+	assert.True(t, decisions.Decisions[0].Timestamp.AsTime().Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.Len(t, decisions.Decisions[0].Candidates, 1)
+	assert.True(t, proto.Equal(&CandidateScore{Pod: "p1", Score: 0.5}, decisions.Decisions[0].Candidates[0]))
+
+	explanation, err := client.ExplainRouting(ctx, &ExplainRoutingRequest{
+		Model:      "llama-7b",
+		Message:    "hello",
+		Headers:    map[string]string{"routing-strategy": "least-request"},
+		Strategies: []string{"least-request"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "least-request", explanation.RoutingStrategy)
+	require.Len(t, explanation.Explanations, 1)
+	assert.True(t, proto.Equal(&RoutingExplanation{
+		RoutingStrategy: "least-request",
+		TargetPod:       "p1",
+		TargetAddress:   "10.0.0.1:8000",
+		Candidates:      []*CandidateScore{{Pod: "p1", Score: 2}},
+	}, explanation.Explanations[0]))
+}
@@ -24,13 +24,21 @@ import (
 // MetricSourceApplyConfiguration represents a declarative configuration of the MetricSource type for use
 // with apply.
 type MetricSourceApplyConfiguration struct {
-	MetricSourceType *v1alpha1.MetricSourceType `json:"metricSourceType,omitempty"`
-	ProtocolType     *v1alpha1.ProtocolType     `json:"protocolType,omitempty"`
-	Endpoint         *string                    `json:"endpoint,omitempty"`
-	Path             *string                    `json:"path,omitempty"`
-	Port             *string                    `json:"port,omitempty"`
-	TargetMetric     *string                    `json:"targetMetric,omitempty"`
-	TargetValue      *string                    `json:"targetValue,omitempty"`
+	MetricSourceType         *v1alpha1.MetricSourceType           `json:"metricSourceType,omitempty"`
+	ProtocolType             *v1alpha1.ProtocolType               `json:"protocolType,omitempty"`
+	Endpoint                 *string                              `json:"endpoint,omitempty"`
+	Path                     *string                              `json:"path,omitempty"`
+	Port                     *string                              `json:"port,omitempty"`
+	TargetMetric             *string                              `json:"targetMetric,omitempty"`
+	TargetValue              *string                              `json:"targetValue,omitempty"`
+	Weight                   *string                              `json:"weight,omitempty"`
+	UpFluctuationTolerance   *string                              `json:"upFluctuationTolerance,omitempty"`
+	DownFluctuationTolerance *string                              `json:"downFluctuationTolerance,omitempty"`
+	Kafka                    *KafkaMetricSourceApplyConfiguration `json:"kafka,omitempty"`
+	Redis                    *RedisMetricSourceApplyConfiguration `json:"redis,omitempty"`
+	JSON                     *JSONMetricSourceApplyConfiguration  `json:"json,omitempty"`
+	Auth                     *MetricSourceAuthApplyConfiguration  `json:"auth,omitempty"`
+	TLS                      *MetricSourceTLSApplyConfiguration   `json:"tls,omitempty"`
 }
 
 // MetricSourceApplyConfiguration constructs a declarative configuration of the MetricSource type for use with
@@ -94,3 +102,67 @@ func (b *MetricSourceApplyConfiguration) WithTargetValue(value string) *MetricSo
 	b.TargetValue = &value
 	return b
 }
+
+// WithWeight sets the Weight field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Weight field is set to the value of the last call.
+func (b *MetricSourceApplyConfiguration) WithWeight(value string) *MetricSourceApplyConfiguration {
+	b.Weight = &value
+	return b
+}
+
+// WithUpFluctuationTolerance sets the UpFluctuationTolerance field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UpFluctuationTolerance field is set to the value of the last call.
+func (b *MetricSourceApplyConfiguration) WithUpFluctuationTolerance(value string) *MetricSourceApplyConfiguration {
+	b.UpFluctuationTolerance = &value
+	return b
+}
+
+// WithDownFluctuationTolerance sets the DownFluctuationTolerance field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DownFluctuationTolerance field is set to the value of the last call.
+func (b *MetricSourceApplyConfiguration) WithDownFluctuationTolerance(value string) *MetricSourceApplyConfiguration {
+	b.DownFluctuationTolerance = &value
+	return b
+}
+
+// WithKafka sets the Kafka field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Kafka field is set to the value of the last call.
+func (b *MetricSourceApplyConfiguration) WithKafka(value *KafkaMetricSourceApplyConfiguration) *MetricSourceApplyConfiguration {
+	b.Kafka = value
+	return b
+}
+
+// WithRedis sets the Redis field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Redis field is set to the value of the last call.
+func (b *MetricSourceApplyConfiguration) WithRedis(value *RedisMetricSourceApplyConfiguration) *MetricSourceApplyConfiguration {
+	b.Redis = value
+	return b
+}
+
+// WithJSON sets the JSON field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the JSON field is set to the value of the last call.
+func (b *MetricSourceApplyConfiguration) WithJSON(value *JSONMetricSourceApplyConfiguration) *MetricSourceApplyConfiguration {
+	b.JSON = value
+	return b
+}
+
+// WithAuth sets the Auth field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Auth field is set to the value of the last call.
+func (b *MetricSourceApplyConfiguration) WithAuth(value *MetricSourceAuthApplyConfiguration) *MetricSourceApplyConfiguration {
+	b.Auth = value
+	return b
+}
+
+// WithTLS sets the TLS field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLS field is set to the value of the last call.
+func (b *MetricSourceApplyConfiguration) WithTLS(value *MetricSourceTLSApplyConfiguration) *MetricSourceApplyConfiguration {
+	b.TLS = value
+	return b
+}
@@ -25,11 +25,16 @@ import (
 // PodAutoscalerSpecApplyConfiguration represents a declarative configuration of the PodAutoscalerSpec type for use
 // with apply.
 type PodAutoscalerSpecApplyConfiguration struct {
-	ScaleTargetRef  *v1.ObjectReference                      `json:"scaleTargetRef,omitempty"`
-	MinReplicas     *int32                                   `json:"minReplicas,omitempty"`
-	MaxReplicas     *int32                                   `json:"maxReplicas,omitempty"`
-	MetricsSources  []MetricSourceApplyConfiguration         `json:"metricsSources,omitempty"`
-	ScalingStrategy *autoscalingv1alpha1.ScalingStrategyType `json:"scalingStrategy,omitempty"`
+	ScaleTargetRef     *v1.ObjectReference                         `json:"scaleTargetRef,omitempty"`
+	RayWorkerGroup     *string                                     `json:"rayWorkerGroup,omitempty"`
+	MinReplicas        *int32                                      `json:"minReplicas,omitempty"`
+	MaxReplicas        *int32                                      `json:"maxReplicas,omitempty"`
+	MetricsSources     []MetricSourceApplyConfiguration            `json:"metricsSources,omitempty"`
+	MetricsAggregation *autoscalingv1alpha1.MetricsAggregationType `json:"metricsAggregation,omitempty"`
+	ScalingStrategy    *autoscalingv1alpha1.ScalingStrategyType    `json:"scalingStrategy,omitempty"`
+	SLOTargets         *SLOTargetsApplyConfiguration               `json:"sloTargets,omitempty"`
+	Behavior           *PodAutoscalerBehaviorApplyConfiguration    `json:"behavior,omitempty"`
+	Pools              []ScalingPoolApplyConfiguration             `json:"pools,omitempty"`
 }
 
 // PodAutoscalerSpecApplyConfiguration constructs a declarative configuration of the PodAutoscalerSpec type for use with
@@ -46,6 +51,14 @@ func (b *PodAutoscalerSpecApplyConfiguration) WithScaleTargetRef(value v1.Object
 	return b
 }
 
+// WithRayWorkerGroup sets the RayWorkerGroup field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RayWorkerGroup field is set to the value of the last call.
+func (b *PodAutoscalerSpecApplyConfiguration) WithRayWorkerGroup(value string) *PodAutoscalerSpecApplyConfiguration {
+	b.RayWorkerGroup = &value
+	return b
+}
+
 // WithMinReplicas sets the MinReplicas field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the MinReplicas field is set to the value of the last call.
@@ -75,6 +88,14 @@ func (b *PodAutoscalerSpecApplyConfiguration) WithMetricsSources(values ...*Metr
 	return b
 }
 
+// WithMetricsAggregation sets the MetricsAggregation field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MetricsAggregation field is set to the value of the last call.
+func (b *PodAutoscalerSpecApplyConfiguration) WithMetricsAggregation(value autoscalingv1alpha1.MetricsAggregationType) *PodAutoscalerSpecApplyConfiguration {
+	b.MetricsAggregation = &value
+	return b
+}
+
 // WithScalingStrategy sets the ScalingStrategy field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the ScalingStrategy field is set to the value of the last call.
@@ -82,3 +103,32 @@ func (b *PodAutoscalerSpecApplyConfiguration) WithScalingStrategy(value autoscal
 	b.ScalingStrategy = &value
 	return b
 }
+
+// WithSLOTargets sets the SLOTargets field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SLOTargets field is set to the value of the last call.
+func (b *PodAutoscalerSpecApplyConfiguration) WithSLOTargets(value *SLOTargetsApplyConfiguration) *PodAutoscalerSpecApplyConfiguration {
+	b.SLOTargets = value
+	return b
+}
+
+// WithBehavior sets the Behavior field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Behavior field is set to the value of the last call.
+func (b *PodAutoscalerSpecApplyConfiguration) WithBehavior(value *PodAutoscalerBehaviorApplyConfiguration) *PodAutoscalerSpecApplyConfiguration {
+	b.Behavior = value
+	return b
+}
+
+// WithPools adds the given value to the Pools field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Pools field.
+func (b *PodAutoscalerSpecApplyConfiguration) WithPools(values ...*ScalingPoolApplyConfiguration) *PodAutoscalerSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithPools")
+		}
+		b.Pools = append(b.Pools, *values[i])
+	}
+	return b
+}
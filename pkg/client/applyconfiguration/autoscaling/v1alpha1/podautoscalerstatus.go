@@ -29,6 +29,8 @@ type PodAutoscalerStatusApplyConfiguration struct {
 	DesiredScale  *int32                               `json:"desiredScale,omitempty"`
 	ActualScale   *int32                               `json:"actualScale,omitempty"`
 	Conditions    []metav1.ConditionApplyConfiguration `json:"conditions,omitempty"`
+	Pools         []PoolStatusApplyConfiguration       `json:"pools,omitempty"`
+	Placement     *PlacementStatusApplyConfiguration   `json:"placement,omitempty"`
 }
 
 // PodAutoscalerStatusApplyConfiguration constructs a declarative configuration of the PodAutoscalerStatus type for use with
@@ -73,3 +75,24 @@ func (b *PodAutoscalerStatusApplyConfiguration) WithConditions(values ...*metav1
 	}
 	return b
 }
+
+// WithPools adds the given value to the Pools field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Pools field.
+func (b *PodAutoscalerStatusApplyConfiguration) WithPools(values ...*PoolStatusApplyConfiguration) *PodAutoscalerStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithPools")
+		}
+		b.Pools = append(b.Pools, *values[i])
+	}
+	return b
+}
+
+// WithPlacement sets the Placement field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Placement field is set to the value of the last call.
+func (b *PodAutoscalerStatusApplyConfiguration) WithPlacement(value *PlacementStatusApplyConfiguration) *PodAutoscalerStatusApplyConfiguration {
+	b.Placement = value
+	return b
+}
@@ -18,6 +18,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
@@ -25,13 +26,16 @@ import (
 // ModelAdapterSpecApplyConfiguration represents a declarative configuration of the ModelAdapterSpec type for use
 // with apply.
 type ModelAdapterSpecApplyConfiguration struct {
-	BaseModel            *string                             `json:"baseModel,omitempty"`
-	PodSelector          *v1.LabelSelectorApplyConfiguration `json:"podSelector,omitempty"`
-	SchedulerName        *string                             `json:"schedulerName,omitempty"`
-	ArtifactURL          *string                             `json:"artifactURL,omitempty"`
-	CredentialsSecretRef *corev1.LocalObjectReference        `json:"credentialsSecretRef,omitempty"`
-	Replicas             *int32                              `json:"replicas,omitempty"`
-	AdditionalConfig     map[string]string                   `json:"additionalConfig,omitempty"`
+	BaseModel            *string                                        `json:"baseModel,omitempty"`
+	PodSelector          *v1.LabelSelectorApplyConfiguration            `json:"podSelector,omitempty"`
+	SchedulerName        *string                                        `json:"schedulerName,omitempty"`
+	SchedulingPolicy     *modelv1alpha1.ModelAdapterSchedulingPolicy    `json:"schedulingPolicy,omitempty"`
+	ArtifactURL          *string                                        `json:"artifactURL,omitempty"`
+	ArtifactChecksum     *string                                        `json:"artifactChecksum,omitempty"`
+	CredentialsSecretRef *corev1.LocalObjectReference                   `json:"credentialsSecretRef,omitempty"`
+	Replicas             *int32                                         `json:"replicas,omitempty"`
+	AdditionalConfig     map[string]string                              `json:"additionalConfig,omitempty"`
+	RolloutStrategy      *ModelAdapterRolloutStrategyApplyConfiguration `json:"rolloutStrategy,omitempty"`
 }
 
 // ModelAdapterSpecApplyConfiguration constructs a declarative configuration of the ModelAdapterSpec type for use with
@@ -64,6 +68,14 @@ func (b *ModelAdapterSpecApplyConfiguration) WithSchedulerName(value string) *Mo
 	return b
 }
 
+// WithSchedulingPolicy sets the SchedulingPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SchedulingPolicy field is set to the value of the last call.
+func (b *ModelAdapterSpecApplyConfiguration) WithSchedulingPolicy(value modelv1alpha1.ModelAdapterSchedulingPolicy) *ModelAdapterSpecApplyConfiguration {
+	b.SchedulingPolicy = &value
+	return b
+}
+
 // WithArtifactURL sets the ArtifactURL field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the ArtifactURL field is set to the value of the last call.
@@ -72,6 +84,14 @@ func (b *ModelAdapterSpecApplyConfiguration) WithArtifactURL(value string) *Mode
 	return b
 }
 
+// WithArtifactChecksum sets the ArtifactChecksum field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ArtifactChecksum field is set to the value of the last call.
+func (b *ModelAdapterSpecApplyConfiguration) WithArtifactChecksum(value string) *ModelAdapterSpecApplyConfiguration {
+	b.ArtifactChecksum = &value
+	return b
+}
+
 // WithCredentialsSecretRef sets the CredentialsSecretRef field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the CredentialsSecretRef field is set to the value of the last call.
@@ -101,3 +121,11 @@ func (b *ModelAdapterSpecApplyConfiguration) WithAdditionalConfig(entries map[st
 	}
 	return b
 }
+
+// WithRolloutStrategy sets the RolloutStrategy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RolloutStrategy field is set to the value of the last call.
+func (b *ModelAdapterSpecApplyConfiguration) WithRolloutStrategy(value *ModelAdapterRolloutStrategyApplyConfiguration) *ModelAdapterSpecApplyConfiguration {
+	b.RolloutStrategy = value
+	return b
+}
@@ -19,20 +19,26 @@ package v1alpha1
 
 import (
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
 // RayClusterFleetSpecApplyConfiguration represents a declarative configuration of the RayClusterFleetSpec type for use
 // with apply.
 type RayClusterFleetSpecApplyConfiguration struct {
-	Replicas                *int32                                    `json:"replicas,omitempty"`
-	Selector                *v1.LabelSelectorApplyConfiguration       `json:"selector,omitempty"`
-	Template                *RayClusterTemplateSpecApplyConfiguration `json:"template,omitempty"`
-	Strategy                *appsv1.DeploymentStrategy                `json:"strategy,omitempty"`
-	MinReadySeconds         *int32                                    `json:"minReadySeconds,omitempty"`
-	RevisionHistoryLimit    *int32                                    `json:"revisionHistoryLimit,omitempty"`
-	Paused                  *bool                                     `json:"paused,omitempty"`
-	ProgressDeadlineSeconds *int32                                    `json:"progressDeadlineSeconds,omitempty"`
+	Replicas                  *int32                                             `json:"replicas,omitempty"`
+	Selector                  *v1.LabelSelectorApplyConfiguration                `json:"selector,omitempty"`
+	Template                  *RayClusterTemplateSpecApplyConfiguration          `json:"template,omitempty"`
+	Strategy                  *appsv1.DeploymentStrategy                         `json:"strategy,omitempty"`
+	MinReadySeconds           *int32                                             `json:"minReadySeconds,omitempty"`
+	RevisionHistoryLimit      *int32                                             `json:"revisionHistoryLimit,omitempty"`
+	Paused                    *bool                                              `json:"paused,omitempty"`
+	Partition                 *int32                                             `json:"partition,omitempty"`
+	ProgressDeadlineSeconds   *int32                                             `json:"progressDeadlineSeconds,omitempty"`
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint                  `json:"topologySpreadConstraints,omitempty"`
+	DisruptionBudget          *RayClusterFleetDisruptionBudgetApplyConfiguration `json:"disruptionBudget,omitempty"`
+	GangScheduling            *GangSchedulingPolicyApplyConfiguration            `json:"gangScheduling,omitempty"`
+	ServingProbe              *ServingProbeApplyConfiguration                    `json:"servingProbe,omitempty"`
 }
 
 // RayClusterFleetSpecApplyConfiguration constructs a declarative configuration of the RayClusterFleetSpec type for use with
@@ -97,6 +103,14 @@ func (b *RayClusterFleetSpecApplyConfiguration) WithPaused(value bool) *RayClust
 	return b
 }
 
+// WithPartition sets the Partition field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Partition field is set to the value of the last call.
+func (b *RayClusterFleetSpecApplyConfiguration) WithPartition(value int32) *RayClusterFleetSpecApplyConfiguration {
+	b.Partition = &value
+	return b
+}
+
 // WithProgressDeadlineSeconds sets the ProgressDeadlineSeconds field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the ProgressDeadlineSeconds field is set to the value of the last call.
@@ -104,3 +118,37 @@ func (b *RayClusterFleetSpecApplyConfiguration) WithProgressDeadlineSeconds(valu
 	b.ProgressDeadlineSeconds = &value
 	return b
 }
+
+// WithTopologySpreadConstraints adds the given value to the TopologySpreadConstraints field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the TopologySpreadConstraints field.
+func (b *RayClusterFleetSpecApplyConfiguration) WithTopologySpreadConstraints(values ...corev1.TopologySpreadConstraint) *RayClusterFleetSpecApplyConfiguration {
+	for i := range values {
+		b.TopologySpreadConstraints = append(b.TopologySpreadConstraints, values[i])
+	}
+	return b
+}
+
+// WithDisruptionBudget sets the DisruptionBudget field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DisruptionBudget field is set to the value of the last call.
+func (b *RayClusterFleetSpecApplyConfiguration) WithDisruptionBudget(value *RayClusterFleetDisruptionBudgetApplyConfiguration) *RayClusterFleetSpecApplyConfiguration {
+	b.DisruptionBudget = value
+	return b
+}
+
+// WithGangScheduling sets the GangScheduling field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GangScheduling field is set to the value of the last call.
+func (b *RayClusterFleetSpecApplyConfiguration) WithGangScheduling(value *GangSchedulingPolicyApplyConfiguration) *RayClusterFleetSpecApplyConfiguration {
+	b.GangScheduling = value
+	return b
+}
+
+// WithServingProbe sets the ServingProbe field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ServingProbe field is set to the value of the last call.
+func (b *RayClusterFleetSpecApplyConfiguration) WithServingProbe(value *ServingProbeApplyConfiguration) *RayClusterFleetSpecApplyConfiguration {
+	b.ServingProbe = value
+	return b
+}
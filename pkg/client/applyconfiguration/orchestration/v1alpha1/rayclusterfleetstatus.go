@@ -28,6 +28,7 @@ type RayClusterFleetStatusApplyConfiguration struct {
 	UnavailableReplicas *int32                                       `json:"unavailableReplicas,omitempty"`
 	Conditions          []RayClusterFleetConditionApplyConfiguration `json:"conditions,omitempty"`
 	CollisionCount      *int32                                       `json:"collisionCount,omitempty"`
+	ServingStatuses     []RayClusterServingStatusApplyConfiguration  `json:"servingStatuses,omitempty"`
 }
 
 // RayClusterFleetStatusApplyConfiguration constructs a declarative configuration of the RayClusterFleetStatus type for use with
@@ -104,3 +105,16 @@ func (b *RayClusterFleetStatusApplyConfiguration) WithCollisionCount(value int32
 	b.CollisionCount = &value
 	return b
 }
+
+// WithServingStatuses adds the given value to the ServingStatuses field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ServingStatuses field.
+func (b *RayClusterFleetStatusApplyConfiguration) WithServingStatuses(values ...*RayClusterServingStatusApplyConfiguration) *RayClusterFleetStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithServingStatuses")
+		}
+		b.ServingStatuses = append(b.ServingStatuses, *values[i])
+	}
+	return b
+}
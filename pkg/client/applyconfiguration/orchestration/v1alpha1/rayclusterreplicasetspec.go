@@ -18,16 +18,20 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
 // RayClusterReplicaSetSpecApplyConfiguration represents a declarative configuration of the RayClusterReplicaSetSpec type for use
 // with apply.
 type RayClusterReplicaSetSpecApplyConfiguration struct {
-	Replicas        *int32                                    `json:"replicas,omitempty"`
-	MinReadySeconds *int32                                    `json:"minReadySeconds,omitempty"`
-	Selector        *v1.LabelSelectorApplyConfiguration       `json:"selector,omitempty"`
-	Template        *RayClusterTemplateSpecApplyConfiguration `json:"template,omitempty"`
+	Replicas                  *int32                                    `json:"replicas,omitempty"`
+	MinReadySeconds           *int32                                    `json:"minReadySeconds,omitempty"`
+	Selector                  *v1.LabelSelectorApplyConfiguration       `json:"selector,omitempty"`
+	Template                  *RayClusterTemplateSpecApplyConfiguration `json:"template,omitempty"`
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint         `json:"topologySpreadConstraints,omitempty"`
+	GangScheduling            *GangSchedulingPolicyApplyConfiguration   `json:"gangScheduling,omitempty"`
+	ServingProbe              *ServingProbeApplyConfiguration           `json:"servingProbe,omitempty"`
 }
 
 // RayClusterReplicaSetSpecApplyConfiguration constructs a declarative configuration of the RayClusterReplicaSetSpec type for use with
@@ -67,3 +71,29 @@ func (b *RayClusterReplicaSetSpecApplyConfiguration) WithTemplate(value *RayClus
 	b.Template = value
 	return b
 }
+
+// WithTopologySpreadConstraints adds the given value to the TopologySpreadConstraints field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the TopologySpreadConstraints field.
+func (b *RayClusterReplicaSetSpecApplyConfiguration) WithTopologySpreadConstraints(values ...corev1.TopologySpreadConstraint) *RayClusterReplicaSetSpecApplyConfiguration {
+	for i := range values {
+		b.TopologySpreadConstraints = append(b.TopologySpreadConstraints, values[i])
+	}
+	return b
+}
+
+// WithGangScheduling sets the GangScheduling field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GangScheduling field is set to the value of the last call.
+func (b *RayClusterReplicaSetSpecApplyConfiguration) WithGangScheduling(value *GangSchedulingPolicyApplyConfiguration) *RayClusterReplicaSetSpecApplyConfiguration {
+	b.GangScheduling = value
+	return b
+}
+
+// WithServingProbe sets the ServingProbe field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ServingProbe field is set to the value of the last call.
+func (b *RayClusterReplicaSetSpecApplyConfiguration) WithServingProbe(value *ServingProbeApplyConfiguration) *RayClusterReplicaSetSpecApplyConfiguration {
+	b.ServingProbe = value
+	return b
+}
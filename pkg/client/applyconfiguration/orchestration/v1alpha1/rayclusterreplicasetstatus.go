@@ -24,12 +24,13 @@ import (
 // RayClusterReplicaSetStatusApplyConfiguration represents a declarative configuration of the RayClusterReplicaSetStatus type for use
 // with apply.
 type RayClusterReplicaSetStatusApplyConfiguration struct {
-	Replicas             *int32                           `json:"replicas,omitempty"`
-	FullyLabeledReplicas *int32                           `json:"fullyLabeledReplicas,omitempty"`
-	ReadyReplicas        *int32                           `json:"readyReplicas,omitempty"`
-	AvailableReplicas    *int32                           `json:"availableReplicas,omitempty"`
-	ObservedGeneration   *int64                           `json:"observedGeneration,omitempty"`
-	Conditions           []v1.ConditionApplyConfiguration `json:"conditions,omitempty"`
+	Replicas             *int32                                      `json:"replicas,omitempty"`
+	FullyLabeledReplicas *int32                                      `json:"fullyLabeledReplicas,omitempty"`
+	ReadyReplicas        *int32                                      `json:"readyReplicas,omitempty"`
+	AvailableReplicas    *int32                                      `json:"availableReplicas,omitempty"`
+	ObservedGeneration   *int64                                      `json:"observedGeneration,omitempty"`
+	Conditions           []v1.ConditionApplyConfiguration            `json:"conditions,omitempty"`
+	ServingStatuses      []RayClusterServingStatusApplyConfiguration `json:"servingStatuses,omitempty"`
 }
 
 // RayClusterReplicaSetStatusApplyConfiguration constructs a declarative configuration of the RayClusterReplicaSetStatus type for use with
@@ -90,3 +91,16 @@ func (b *RayClusterReplicaSetStatusApplyConfiguration) WithConditions(values ...
 	}
 	return b
 }
+
+// WithServingStatuses adds the given value to the ServingStatuses field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ServingStatuses field.
+func (b *RayClusterReplicaSetStatusApplyConfiguration) WithServingStatuses(values ...*RayClusterServingStatusApplyConfiguration) *RayClusterReplicaSetStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithServingStatuses")
+		}
+		b.ServingStatuses = append(b.ServingStatuses, *values[i])
+	}
+	return b
+}
@@ -35,28 +35,60 @@ import (
 func ForKind(kind schema.GroupVersionKind) interface{} {
 	switch kind {
 	// Group=autoscaling, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("JSONMetricSource"):
+		return &autoscalingv1alpha1.JSONMetricSourceApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("KafkaMetricSource"):
+		return &autoscalingv1alpha1.KafkaMetricSourceApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("MetricSource"):
 		return &autoscalingv1alpha1.MetricSourceApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("MetricSourceAuth"):
+		return &autoscalingv1alpha1.MetricSourceAuthApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("MetricSourceTLS"):
+		return &autoscalingv1alpha1.MetricSourceTLSApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PlacementStatus"):
+		return &autoscalingv1alpha1.PlacementStatusApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("PodAutoscaler"):
 		return &autoscalingv1alpha1.PodAutoscalerApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PodAutoscalerBehavior"):
+		return &autoscalingv1alpha1.PodAutoscalerBehaviorApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("PodAutoscalerSpec"):
 		return &autoscalingv1alpha1.PodAutoscalerSpecApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("PodAutoscalerStatus"):
 		return &autoscalingv1alpha1.PodAutoscalerStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PoolPlacement"):
+		return &autoscalingv1alpha1.PoolPlacementApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PoolStatus"):
+		return &autoscalingv1alpha1.PoolStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("RedisMetricSource"):
+		return &autoscalingv1alpha1.RedisMetricSourceApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ScalingPool"):
+		return &autoscalingv1alpha1.ScalingPoolApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SLOTargets"):
+		return &autoscalingv1alpha1.SLOTargetsApplyConfiguration{}
 
 		// Group=model, Version=v1alpha1
 	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapter"):
 		return &applyconfigurationmodelv1alpha1.ModelAdapterApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapterInstanceStatus"):
+		return &applyconfigurationmodelv1alpha1.ModelAdapterInstanceStatusApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapterRolloutStatus"):
+		return &applyconfigurationmodelv1alpha1.ModelAdapterRolloutStatusApplyConfiguration{}
+	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapterRolloutStrategy"):
+		return &applyconfigurationmodelv1alpha1.ModelAdapterRolloutStrategyApplyConfiguration{}
 	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapterSpec"):
 		return &applyconfigurationmodelv1alpha1.ModelAdapterSpecApplyConfiguration{}
 	case modelv1alpha1.SchemeGroupVersion.WithKind("ModelAdapterStatus"):
 		return &applyconfigurationmodelv1alpha1.ModelAdapterStatusApplyConfiguration{}
 
 		// Group=orchestration, Version=v1alpha1
+	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("GangSchedulingPolicy"):
+		return &applyconfigurationorchestrationv1alpha1.GangSchedulingPolicyApplyConfiguration{}
 	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RayClusterFleet"):
 		return &applyconfigurationorchestrationv1alpha1.RayClusterFleetApplyConfiguration{}
 	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RayClusterFleetCondition"):
 		return &applyconfigurationorchestrationv1alpha1.RayClusterFleetConditionApplyConfiguration{}
+	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RayClusterFleetDisruptionBudget"):
+		return &applyconfigurationorchestrationv1alpha1.RayClusterFleetDisruptionBudgetApplyConfiguration{}
 	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RayClusterFleetSpec"):
 		return &applyconfigurationorchestrationv1alpha1.RayClusterFleetSpecApplyConfiguration{}
 	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RayClusterFleetStatus"):
@@ -67,8 +99,24 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &applyconfigurationorchestrationv1alpha1.RayClusterReplicaSetSpecApplyConfiguration{}
 	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RayClusterReplicaSetStatus"):
 		return &applyconfigurationorchestrationv1alpha1.RayClusterReplicaSetStatusApplyConfiguration{}
+	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RayClusterServingStatus"):
+		return &applyconfigurationorchestrationv1alpha1.RayClusterServingStatusApplyConfiguration{}
 	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RayClusterTemplateSpec"):
 		return &applyconfigurationorchestrationv1alpha1.RayClusterTemplateSpecApplyConfiguration{}
+	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RoleSpec"):
+		return &applyconfigurationorchestrationv1alpha1.RoleSpecApplyConfiguration{}
+	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("RoleStatus"):
+		return &applyconfigurationorchestrationv1alpha1.RoleStatusApplyConfiguration{}
+	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("ServingProbe"):
+		return &applyconfigurationorchestrationv1alpha1.ServingProbeApplyConfiguration{}
+	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("StormService"):
+		return &applyconfigurationorchestrationv1alpha1.StormServiceApplyConfiguration{}
+	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("StormServiceSpec"):
+		return &applyconfigurationorchestrationv1alpha1.StormServiceSpecApplyConfiguration{}
+	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("StormServiceStatus"):
+		return &applyconfigurationorchestrationv1alpha1.StormServiceStatusApplyConfiguration{}
+	case orchestrationv1alpha1.SchemeGroupVersion.WithKind("StormServiceUpdateStrategy"):
+		return &applyconfigurationorchestrationv1alpha1.StormServiceUpdateStrategyApplyConfiguration{}
 
 	}
 	return nil
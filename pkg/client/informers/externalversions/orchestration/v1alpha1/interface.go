@@ -27,6 +27,8 @@ type Interface interface {
 	RayClusterFleets() RayClusterFleetInformer
 	// RayClusterReplicaSets returns a RayClusterReplicaSetInformer.
 	RayClusterReplicaSets() RayClusterReplicaSetInformer
+	// StormServices returns a StormServiceInformer.
+	StormServices() StormServiceInformer
 }
 
 type version struct {
@@ -49,3 +51,8 @@ func (v *version) RayClusterFleets() RayClusterFleetInformer {
 func (v *version) RayClusterReplicaSets() RayClusterReplicaSetInformer {
 	return &rayClusterReplicaSetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// StormServices returns a StormServiceInformer.
+func (v *version) StormServices() StormServiceInformer {
+	return &stormServiceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
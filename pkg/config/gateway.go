@@ -0,0 +1,268 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// GatewayConfigMapKey is the ConfigMap data key holding the JSON encoded GatewayConfig.
+	GatewayConfigMapKey = "config.json"
+
+	reloadResultSuccess = "success"
+	reloadResultFailure = "failure"
+)
+
+var gatewayConfigReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "aibrix_gateway_config_reloads_total",
+	Help: "Number of gateway configuration reloads, failed reloads keep the previous configuration.",
+}, []string{"result"})
+
+// GatewayConfig is the content of the gateway configuration ConfigMap. Unset fields fall back to the environment
+// variables of the gateway plugin, so the ConfigMap only needs to hold the settings changed at runtime.
+type GatewayConfig struct {
+	// RoutingStrategy is used for requests without routing-strategy header or model configuration,
+	// it takes precedence over ROUTING_ALGORITHM.
+	RoutingStrategy string `json:"routingStrategy,omitempty"`
+	// ScaleFromZeroTimeout overrides AIBRIX_SCALE_FROM_ZERO_TIMEOUT_S.
+	ScaleFromZeroTimeout *metav1.Duration `json:"scaleFromZeroTimeout,omitempty"`
+	// ExternalRouterTimeout overrides AIBRIX_EXTERNAL_ROUTER_TIMEOUT_MS.
+	ExternalRouterTimeout *metav1.Duration `json:"externalRouterTimeout,omitempty"`
+	// DefaultRPM is the requests per minute limit of users without one.
+	DefaultRPM int64 `json:"defaultRPM,omitempty"`
+	// DefaultTPMMultiplier derives the tokens per minute limit of users without one from their RPM.
+	DefaultTPMMultiplier int64 `json:"defaultTPMMultiplier,omitempty"`
+	// TraceSampleRatio overrides AIBRIX_TRACE_SAMPLE_RATIO.
+	TraceSampleRatio *float64 `json:"traceSampleRatio,omitempty"`
+	// EngineHealthFailureThreshold overrides AIBRIX_ENGINE_HEALTH_FAILURE_THRESHOLD.
+	EngineHealthFailureThreshold int `json:"engineHealthFailureThreshold,omitempty"`
+	// RequestTraceInterval overrides AIBRIX_REQUEST_TRACE_INTERVAL_S.
+	RequestTraceInterval *metav1.Duration `json:"requestTraceInterval,omitempty"`
+	// RequestTraceTTL overrides AIBRIX_REQUEST_TRACE_TTL_S.
+	RequestTraceTTL *metav1.Duration `json:"requestTraceTTL,omitempty"`
+	// RequestTraceKeyPrefix overrides AIBRIX_REQUEST_TRACE_KEY_PREFIX.
+	RequestTraceKeyPrefix string `json:"requestTraceKeyPrefix,omitempty"`
+	// RequestTraceKeySchema overrides AIBRIX_REQUEST_TRACE_KEY_SCHEMA.
+	RequestTraceKeySchema string `json:"requestTraceKeySchema,omitempty"`
+	// RequestTraceEncoding overrides AIBRIX_REQUEST_TRACE_ENCODING.
+	RequestTraceEncoding string `json:"requestTraceEncoding,omitempty"`
+	// Middlewares transform the requests before they are routed and the responses, in order.
+	Middlewares []MiddlewareConfig `json:"middlewares,omitempty"`
+	// Failover endpoints receive the requests of a model while none of its pods in the cluster is ready, the first
+	// healthy endpoint serving the model is used.
+	Failover []FailoverConfig `json:"failover,omitempty"`
+	// FailbackDelay overrides AIBRIX_FAILBACK_DELAY_S.
+	FailbackDelay *metav1.Duration `json:"failbackDelay,omitempty"`
+	// Providers are external hosted APIs the chat completions of models fall back to once the pods of the cluster
+	// are at capacity, the first provider serving the model under its spend cap is used.
+	Providers []ProviderConfig `json:"providers,omitempty"`
+}
+
+// ProviderConfig declares a fallback route to an external hosted API.
+type ProviderConfig struct {
+	// Name identifies the route in metrics, logs and its spend.
+	Name string `json:"name"`
+	// Type is the API of the provider: openai, anthropic or bedrock.
+	Type string `json:"type"`
+	// Models restricts the route to the requests of these models, all models if empty.
+	Models []string `json:"models,omitempty"`
+	// Model is the model of the provider the requests are sent to, the model of the request if empty.
+	Model string `json:"model,omitempty"`
+	// BaseURL overrides the URL of the API, e.g. for another OpenAI compatible API.
+	BaseURL string `json:"baseURL,omitempty"`
+	// Region is the AWS region of Bedrock.
+	Region string `json:"region,omitempty"`
+	// CredentialsSecret is the Secret in the namespace of the gateway holding the credentials of the provider: the
+	// api-key entry, or aws-access-key-id, aws-secret-access-key and the optional aws-session-token for Bedrock.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	// Timeout bounds the requests to the provider, 60s if unset.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// InputCostPerMillion and OutputCostPerMillion are the prices in USD of a million prompt and completion tokens.
+	InputCostPerMillion  float64 `json:"inputCostPerMillion,omitempty"`
+	OutputCostPerMillion float64 `json:"outputCostPerMillion,omitempty"`
+	// SpendCap stops the fallback once the cost of its requests within the spend window reached it, in USD. 0 is
+	// unlimited.
+	SpendCap float64 `json:"spendCap,omitempty"`
+	// SpendWindow is the period of the spend cap, aligned on the Unix epoch, 24h if unset.
+	SpendWindow *metav1.Duration `json:"spendWindow,omitempty"`
+}
+
+// FailoverConfig declares a remote endpoint serving models, e.g. the gateway of another region.
+type FailoverConfig struct {
+	// Address is the host:port of the endpoint, served over plain HTTP. Host names are resolved on every probe.
+	Address string `json:"address"`
+	// Models restricts the endpoint to the requests of these models, all models if empty.
+	Models []string `json:"models,omitempty"`
+	// HealthPath is probed with GET requests, /v1/models if empty.
+	HealthPath string `json:"healthPath,omitempty"`
+}
+
+// MiddlewareConfig declares a request and response transformation of the gateway.
+type MiddlewareConfig struct {
+	// Name identifies the middleware in metrics, logs and errors.
+	Name string `json:"name"`
+	// Type is a built-in middleware, e.g. system-prompt, or plugin for a Go plugin.
+	Type string `json:"type"`
+	// Plugin is the path of the Go plugin in the gateway plugin container, for the plugin type.
+	Plugin string `json:"plugin,omitempty"`
+	// Models restricts the middleware to the requests of these models, all models if empty.
+	Models []string `json:"models,omitempty"`
+	// Config is handed to the middleware as is.
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// ParseGatewayConfig decodes and validates a gateway configuration, unknown fields are rejected to catch typos.
+func ParseGatewayConfig(data []byte) (GatewayConfig, error) {
+	var config GatewayConfig
+	if len(data) == 0 {
+		return config, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return GatewayConfig{}, fmt.Errorf("failed to parse gateway config: %w", err)
+	}
+
+	for name, duration := range map[string]*metav1.Duration{
+		"scaleFromZeroTimeout":  config.ScaleFromZeroTimeout,
+		"externalRouterTimeout": config.ExternalRouterTimeout,
+		"requestTraceInterval":  config.RequestTraceInterval,
+		"requestTraceTTL":       config.RequestTraceTTL,
+		"failbackDelay":         config.FailbackDelay,
+	} {
+		if duration != nil && duration.Duration <= 0 {
+			return GatewayConfig{}, fmt.Errorf("invalid %s %v, must be positive", name, duration.Duration)
+		}
+	}
+	if config.DefaultRPM < 0 || config.DefaultTPMMultiplier < 0 || config.EngineHealthFailureThreshold < 0 {
+		return GatewayConfig{}, fmt.Errorf("defaultRPM, defaultTPMMultiplier and engineHealthFailureThreshold must not be negative")
+	}
+	if ratio := config.TraceSampleRatio; ratio != nil && (*ratio < 0 || *ratio > 1) {
+		return GatewayConfig{}, fmt.Errorf("invalid traceSampleRatio %v, must be within [0, 1]", *ratio)
+	}
+	names := map[string]bool{}
+	for _, middleware := range config.Middlewares {
+		if middleware.Name == "" || names[middleware.Name] {
+			return GatewayConfig{}, fmt.Errorf("invalid middleware name %q, must be set and unique", middleware.Name)
+		}
+		names[middleware.Name] = true
+	}
+	names = map[string]bool{}
+	for _, provider := range config.Providers {
+		if provider.Name == "" || names[provider.Name] {
+			return GatewayConfig{}, fmt.Errorf("invalid provider name %q, must be set and unique", provider.Name)
+		}
+		names[provider.Name] = true
+		if provider.InputCostPerMillion < 0 || provider.OutputCostPerMillion < 0 || provider.SpendCap < 0 {
+			return GatewayConfig{}, fmt.Errorf("the costs and spend cap of provider %s must not be negative", provider.Name)
+		}
+		for field, duration := range map[string]*metav1.Duration{"timeout": provider.Timeout, "spendWindow": provider.SpendWindow} {
+			if duration != nil && duration.Duration <= 0 {
+				return GatewayConfig{}, fmt.Errorf("invalid %s %v of provider %s, must be positive", field, duration.Duration, provider.Name)
+			}
+		}
+	}
+	for _, failover := range config.Failover {
+		if _, port, err := net.SplitHostPort(failover.Address); err != nil || port == "" {
+			return GatewayConfig{}, fmt.Errorf("invalid failover address %q, must be host:port", failover.Address)
+		}
+	}
+	// the request trace settings left unset come from the environment, they must fit together.
+	if err := config.requestTrace(requestTraceEnv()).Validate(); err != nil {
+		return GatewayConfig{}, err
+	}
+	return config, nil
+}
+
+var gatewayConfig atomic.Pointer[GatewayConfig]
+
+func init() {
+	gatewayConfig.Store(&GatewayConfig{})
+	prometheus.MustRegister(gatewayConfigReloads)
+}
+
+// Gateway returns the latest gateway configuration snapshot, it must not be modified.
+func Gateway() *GatewayConfig {
+	return gatewayConfig.Load()
+}
+
+// SetGateway replaces the gateway configuration snapshot.
+func SetGateway(config GatewayConfig) {
+	gatewayConfig.Store(&config)
+}
+
+// WatchGatewayConfig keeps the gateway configuration in sync with the ConfigMap until stopCh is closed. validate
+// checks what this package doesn't know about, e.g. routing strategy names. A missing ConfigMap falls back to the
+// environment, an invalid one keeps the previous configuration.
+func WatchGatewayConfig(client kubernetes.Interface, namespace, name string, validate func(GatewayConfig) error, stopCh <-chan struct{}) {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+	informer := factory.Core().V1().ConfigMaps().Informer()
+
+	load := func(obj interface{}) {
+		cm, ok := obj.(*v1.ConfigMap)
+		if !ok {
+			return
+		}
+		config, err := ParseGatewayConfig([]byte(cm.Data[GatewayConfigMapKey]))
+		if err == nil && validate != nil {
+			err = validate(config)
+		}
+		if err != nil {
+			gatewayConfigReloads.WithLabelValues(reloadResultFailure).Inc()
+			klog.ErrorS(err, "ignoring invalid gateway configmap", "configmap", klog.KObj(cm))
+			return
+		}
+		SetGateway(config)
+		gatewayConfigReloads.WithLabelValues(reloadResultSuccess).Inc()
+		klog.InfoS("gateway config reloaded", "configmap", klog.KObj(cm), "config", cm.Data[GatewayConfigMapKey])
+	}
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    load,
+		UpdateFunc: func(_, newObj interface{}) { load(newObj) },
+		DeleteFunc: func(obj interface{}) {
+			SetGateway(GatewayConfig{})
+			gatewayConfigReloads.WithLabelValues(reloadResultSuccess).Inc()
+			klog.InfoS("gateway configmap deleted, using environment", "configmap", klog.KRef(namespace, name))
+		},
+	}); err != nil {
+		klog.ErrorS(err, "failed to watch gateway config")
+		return
+	}
+
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		klog.ErrorS(nil, "timed out waiting for gateway config cache to sync")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGatewayConfig(t *testing.T) {
+	config, err := ParseGatewayConfig([]byte(`{
+		"routingStrategy": "least-request",
+		"scaleFromZeroTimeout": "2m",
+		"defaultRPM": 50,
+		"traceSampleRatio": 0.1,
+		"middlewares": [{"name": "redact", "type": "pii-redaction", "models": ["llama"], "config": {"types": ["email"]}}],
+		"failover": [{"address": "gateway.us-west.example.com:80", "models": ["llama"]}],
+		"failbackDelay": "1m",
+		"providers": [{"name": "openai", "type": "openai", "model": "gpt-4o-mini", "credentialsSecret": "openai", "spendCap": 100}]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "least-request", config.RoutingStrategy)
+	assert.Equal(t, 2*time.Minute, config.ScaleFromZeroTimeout.Duration)
+	assert.Nil(t, config.ExternalRouterTimeout)
+	assert.Equal(t, int64(50), config.DefaultRPM)
+	assert.Equal(t, 0.1, *config.TraceSampleRatio)
+	assert.Equal(t, []MiddlewareConfig{{
+		Name: "redact", Type: "pii-redaction", Models: []string{"llama"}, Config: []byte(`{"types": ["email"]}`),
+	}}, config.Middlewares)
+	assert.Equal(t, []FailoverConfig{{Address: "gateway.us-west.example.com:80", Models: []string{"llama"}}}, config.Failover)
+	assert.Equal(t, time.Minute, config.FailbackDelay.Duration)
+	assert.Equal(t, []ProviderConfig{{
+		Name: "openai", Type: "openai", Model: "gpt-4o-mini", CredentialsSecret: "openai", SpendCap: 100,
+	}}, config.Providers)
+
+	config, err = ParseGatewayConfig(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, GatewayConfig{}, config)
+}
+
+func TestParseGatewayConfigInvalid(t *testing.T) {
+	for _, data := range []string{
+		`not-json`,
+		`{"routingStrategie": "random"}`,
+		`{"scaleFromZeroTimeout": "0s"}`,
+		`{"externalRouterTimeout": "-1s"}`,
+		`{"defaultRPM": -1}`,
+		`{"traceSampleRatio": 1.5}`,
+		`{"requestTraceInterval": "1500ms"}`,
+		`{"requestTraceInterval": "1m", "requestTraceTTL": "30s"}`,
+		`{"requestTraceKeySchema": "v3"}`,
+		`{"middlewares": [{"type": "watermark"}]}`,
+		`{"middlewares": [{"name": "a", "type": "watermark"}, {"name": "a", "type": "pii-redaction"}]}`,
+		`{"failover": [{"address": "gateway.us-west.example.com"}]}`,
+		`{"failbackDelay": "0s"}`,
+		`{"providers": [{"type": "openai"}]}`,
+		`{"providers": [{"name": "a", "type": "openai"}, {"name": "a", "type": "anthropic"}]}`,
+		`{"providers": [{"name": "a", "type": "openai", "spendCap": -1}]}`,
+		`{"providers": [{"name": "a", "type": "openai", "spendWindow": "0s"}]}`,
+	} {
+		_, err := ParseGatewayConfig([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestSetGateway(t *testing.T) {
+	assert.Equal(t, &GatewayConfig{}, Gateway())
+
+	SetGateway(GatewayConfig{RoutingStrategy: "random"})
+	defer SetGateway(GatewayConfig{})
+	assert.Equal(t, "random", Gateway().RoutingStrategy)
+}
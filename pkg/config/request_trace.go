@@ -0,0 +1,203 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// RequestTraceKeySchemaV1 keys traces as <prefix><model>_request_trace_<unix_seconds>.
+	RequestTraceKeySchemaV1 = "v1"
+	// RequestTraceKeySchemaV2 keys traces as <prefix>request_trace:v2:<model>:<unix_seconds>, which keeps models
+	// whose names share a prefix apart.
+	RequestTraceKeySchemaV2 = "v2"
+
+	DefaultRequestTraceInterval  = 10 * time.Second
+	DefaultRequestTraceTTL       = 10 * time.Minute
+	DefaultRequestTraceKeyPrefix = "aibrix:"
+)
+
+// RequestTraceConfig describes the request traces the gateway writes to the kv store.
+type RequestTraceConfig struct {
+	// Interval is the length of a trace window, in whole seconds.
+	Interval time.Duration
+	// TTL is how long a window is kept in the kv store.
+	TTL       time.Duration
+	KeyPrefix string
+	KeySchema string
+	// Encoding of the trace values, RequestTraceEncodingJSON if empty.
+	Encoding string
+}
+
+// Validate checks the windows can be keyed by unix seconds and outlive their interval.
+func (c RequestTraceConfig) Validate() error {
+	if c.Interval < time.Second || c.Interval%time.Second != 0 {
+		return fmt.Errorf("invalid request trace interval %v, must be a positive number of seconds", c.Interval)
+	}
+	if c.TTL < c.Interval {
+		return fmt.Errorf("invalid request trace ttl %v, must not be shorter than the interval %v", c.TTL, c.Interval)
+	}
+	if c.KeySchema != RequestTraceKeySchemaV1 && c.KeySchema != RequestTraceKeySchemaV2 {
+		return fmt.Errorf("unknown request trace key schema %q, must be %s or %s", c.KeySchema, RequestTraceKeySchemaV1, RequestTraceKeySchemaV2)
+	}
+	if c.Encoding != "" && c.Encoding != RequestTraceEncodingJSON && c.Encoding != RequestTraceEncodingBinary {
+		return fmt.Errorf("unknown request trace encoding %q, must be %s or %s", c.Encoding, RequestTraceEncodingJSON, RequestTraceEncodingBinary)
+	}
+	return nil
+}
+
+// ModelKeyPrefix is the part of the trace keys of the model before the timestamp.
+func (c RequestTraceConfig) ModelKeyPrefix(model string) string {
+	if c.KeySchema == RequestTraceKeySchemaV2 {
+		return fmt.Sprintf("%srequest_trace:v2:%s:", c.KeyPrefix, model)
+	}
+	return fmt.Sprintf("%s%s_request_trace_", c.KeyPrefix, model)
+}
+
+// Key is the key of the trace window of the model starting at the unix timestamp.
+func (c RequestTraceConfig) Key(model string, timestamp int64) string {
+	return c.ModelKeyPrefix(model) + strconv.FormatInt(timestamp, 10)
+}
+
+// MetaKey holds the RequestTraceMeta of the traces. It doesn't depend on the schema, so consumers can find it
+// knowing only the prefix.
+func (c RequestTraceConfig) MetaKey() string {
+	return c.KeyPrefix + "request_trace_meta"
+}
+
+// RequestTraceMeta describes the request traces to their consumers, e.g. the autoscaler or the GPU optimizer.
+type RequestTraceMeta struct {
+	// Version is the version of the trace content, see cache.RequestTraceVersion.
+	Version         int    `json:"version"`
+	KeySchema       string `json:"keySchema"`
+	KeyPrefix       string `json:"keyPrefix"`
+	KeyFormat       string `json:"keyFormat"`
+	IntervalSeconds int64  `json:"intervalSeconds"`
+	TTLSeconds      int64  `json:"ttlSeconds"`
+	// Precision scales the log2 of the token counts in the bucket keys.
+	Precision int `json:"precision"`
+	// Encoding of the trace values, JSON if empty. Binary values are recognized by their header as well.
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Meta describes the traces written with the configuration.
+func (c RequestTraceConfig) Meta(version, precision int) RequestTraceMeta {
+	return RequestTraceMeta{
+		Version:         version,
+		KeySchema:       c.KeySchema,
+		KeyPrefix:       c.KeyPrefix,
+		KeyFormat:       c.ModelKeyPrefix("{model}") + "{timestamp}",
+		IntervalSeconds: int64(c.Interval / time.Second),
+		TTLSeconds:      int64(c.TTL / time.Second),
+		Precision:       precision,
+		Encoding:        c.Encoding,
+	}
+}
+
+// Config returns the configuration the traces were written with.
+func (m RequestTraceMeta) Config() RequestTraceConfig {
+	return RequestTraceConfig{
+		Interval:  time.Duration(m.IntervalSeconds) * time.Second,
+		TTL:       time.Duration(m.TTLSeconds) * time.Second,
+		KeyPrefix: m.KeyPrefix,
+		KeySchema: m.KeySchema,
+		Encoding:  m.Encoding,
+	}
+}
+
+// ParseRequestTraceMeta decodes the content of the meta key into the configuration of the traces.
+func ParseRequestTraceMeta(data []byte) (RequestTraceConfig, error) {
+	var meta RequestTraceMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return RequestTraceConfig{}, err
+	}
+	config := meta.Config()
+	if err := config.Validate(); err != nil {
+		return RequestTraceConfig{}, err
+	}
+	return config, nil
+}
+
+var requestTraceEnv = sync.OnceValue(loadRequestTraceEnv)
+
+// RequestTrace returns the request trace configuration, the gateway ConfigMap takes precedence over
+// the environment. ParseGatewayConfig checks the result is valid.
+func RequestTrace() RequestTraceConfig {
+	return Gateway().requestTrace(requestTraceEnv())
+}
+
+// requestTrace overrides the request trace settings of base set in the gateway configuration.
+func (c *GatewayConfig) requestTrace(base RequestTraceConfig) RequestTraceConfig {
+	if c.RequestTraceInterval != nil {
+		base.Interval = c.RequestTraceInterval.Duration
+	}
+	if c.RequestTraceTTL != nil {
+		base.TTL = c.RequestTraceTTL.Duration
+	}
+	if c.RequestTraceKeyPrefix != "" {
+		base.KeyPrefix = c.RequestTraceKeyPrefix
+	}
+	if c.RequestTraceKeySchema != "" {
+		base.KeySchema = c.RequestTraceKeySchema
+	}
+	if c.RequestTraceEncoding != "" {
+		base.Encoding = c.RequestTraceEncoding
+	}
+	return base
+}
+
+func loadRequestTraceEnv() RequestTraceConfig {
+	config := RequestTraceConfig{
+		Interval:  getSeconds("AIBRIX_REQUEST_TRACE_INTERVAL_S", DefaultRequestTraceInterval),
+		TTL:       getSeconds("AIBRIX_REQUEST_TRACE_TTL_S", DefaultRequestTraceTTL),
+		KeyPrefix: utils.LoadEnv("AIBRIX_REQUEST_TRACE_KEY_PREFIX", DefaultRequestTraceKeyPrefix),
+		KeySchema: utils.LoadEnv("AIBRIX_REQUEST_TRACE_KEY_SCHEMA", RequestTraceKeySchemaV1),
+		Encoding:  utils.LoadEnv("AIBRIX_REQUEST_TRACE_ENCODING", ""),
+	}
+	if err := config.Validate(); err != nil {
+		klog.Infof("invalid request trace config: %v, falling back to default", err)
+		return RequestTraceConfig{
+			Interval:  DefaultRequestTraceInterval,
+			TTL:       DefaultRequestTraceTTL,
+			KeyPrefix: config.KeyPrefix,
+			KeySchema: RequestTraceKeySchemaV1,
+		}
+	}
+	return config
+}
+
+func getSeconds(name string, defaultValue time.Duration) time.Duration {
+	value := utils.LoadEnv(name, "")
+	if value == "" {
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil || intValue <= 0 {
+		klog.Infof("invalid %s: %s, falling back to default", name, value)
+		return defaultValue
+	}
+	return time.Duration(intValue) * time.Second
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// RequestTraceEncodingJSON writes the traces as a JSON object of the bucket and meta keys to their counts.
+	RequestTraceEncodingJSON = "json"
+	// RequestTraceEncodingBinary writes the traces with EncodeRequestTrace, a fraction of the size of JSON.
+	RequestTraceEncodingBinary = "binary"
+
+	// requestTraceBinaryMagic starts the binary traces, JSON traces start with '{'.
+	requestTraceBinaryMagic = "ATR"
+	// requestTraceBinaryVersion is the version of the binary layout, following the magic.
+	requestTraceBinaryVersion = 1
+)
+
+var errInvalidRequestTrace = errors.New("invalid binary request trace")
+
+// EncodeRequestTrace encodes a trace, the bucket keys "<input index>:<output index>" and the meta keys of the gateway
+// to their counts, with the encoding. Any encoding but RequestTraceEncodingBinary is JSON.
+//
+// The binary layout is the magic "ATR" and a version byte, followed by the meta entries and the buckets as varints:
+// the number of meta entries, each as the length of its key, the key and its value, then the number of buckets,
+// sorted by input and output index, each as the input index delta from the previous bucket, the output index, delta
+// too if the input index is the same, and the count.
+func EncodeRequestTrace(trace map[string]int, encoding string) ([]byte, error) {
+	if encoding != RequestTraceEncodingBinary {
+		return json.Marshal(trace)
+	}
+
+	type bucket struct{ input, output, count int64 }
+	var meta []string
+	buckets := make([]bucket, 0, len(trace))
+	for key, count := range trace {
+		if strings.HasPrefix(key, "meta_") {
+			meta = append(meta, key)
+			continue
+		}
+		input, output, err := parseRequestTraceKey(key)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket{input: input, output: output, count: int64(count)})
+	}
+	sort.Strings(meta)
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].input != buckets[j].input {
+			return buckets[i].input < buckets[j].input
+		}
+		return buckets[i].output < buckets[j].output
+	})
+
+	buf := make([]byte, 0, len(requestTraceBinaryMagic)+1+len(meta)*16+len(buckets)*4)
+	buf = append(buf, requestTraceBinaryMagic...)
+	buf = append(buf, requestTraceBinaryVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(meta)))
+	for _, key := range meta {
+		buf = binary.AppendUvarint(buf, uint64(len(key)))
+		buf = append(buf, key...)
+		buf = binary.AppendVarint(buf, int64(trace[key]))
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(buckets)))
+	var previous bucket
+	for i, b := range buckets {
+		output := b.output
+		if i > 0 && b.input == previous.input {
+			output -= previous.output
+		}
+		buf = binary.AppendVarint(buf, b.input-previous.input)
+		buf = binary.AppendVarint(buf, output)
+		buf = binary.AppendVarint(buf, b.count)
+		previous = b
+	}
+	return buf, nil
+}
+
+// DecodeRequestTrace decodes a trace written by the gateway in any encoding, told apart by their first bytes, into
+// the bucket and meta keys to their counts.
+func DecodeRequestTrace(data []byte) (map[string]int, error) {
+	if !bytes.HasPrefix(data, []byte(requestTraceBinaryMagic)) {
+		var trace map[string]int
+		if err := json.Unmarshal(data, &trace); err != nil {
+			return nil, err
+		}
+		return trace, nil
+	}
+
+	reader := bytes.NewReader(data[len(requestTraceBinaryMagic):])
+	version, err := reader.ReadByte()
+	if err != nil {
+		return nil, errInvalidRequestTrace
+	}
+	if version != requestTraceBinaryVersion {
+		return nil, fmt.Errorf("unsupported request trace binary version %d", version)
+	}
+
+	numMeta, err := binary.ReadUvarint(reader)
+	if err != nil || numMeta > uint64(reader.Len()) {
+		return nil, errInvalidRequestTrace
+	}
+	trace := make(map[string]int)
+	for i := uint64(0); i < numMeta; i++ {
+		length, err := binary.ReadUvarint(reader)
+		if err != nil || length > uint64(reader.Len()) {
+			return nil, errInvalidRequestTrace
+		}
+		key := make([]byte, length)
+		if _, err := io.ReadFull(reader, key); err != nil {
+			return nil, errInvalidRequestTrace
+		}
+		value, err := binary.ReadVarint(reader)
+		if err != nil {
+			return nil, errInvalidRequestTrace
+		}
+		trace[string(key)] = int(value)
+	}
+
+	numBuckets, err := binary.ReadUvarint(reader)
+	if err != nil || numBuckets > uint64(reader.Len()) {
+		return nil, errInvalidRequestTrace
+	}
+	var input, output int64
+	for i := uint64(0); i < numBuckets; i++ {
+		inputDelta, err := binary.ReadVarint(reader)
+		if err != nil {
+			return nil, errInvalidRequestTrace
+		}
+		outputValue, err := binary.ReadVarint(reader)
+		if err != nil {
+			return nil, errInvalidRequestTrace
+		}
+		count, err := binary.ReadVarint(reader)
+		if err != nil {
+			return nil, errInvalidRequestTrace
+		}
+		if i > 0 && inputDelta == 0 {
+			output += outputValue
+		} else {
+			output = outputValue
+		}
+		input += inputDelta
+		trace[strconv.FormatInt(input, 10)+":"+strconv.FormatInt(output, 10)] = int(count)
+	}
+	if reader.Len() != 0 {
+		return nil, errInvalidRequestTrace
+	}
+	return trace, nil
+}
+
+func parseRequestTraceKey(key string) (int64, int64, error) {
+	input, output, found := strings.Cut(key, ":")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid request trace key: %s", key)
+	}
+	inputIndex, err := strconv.ParseInt(input, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid request trace key: %s", key)
+	}
+	outputIndex, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid request trace key: %s", key)
+	}
+	return inputIndex, outputIndex, nil
+}
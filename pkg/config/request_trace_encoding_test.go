@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestTraceEncoding(t *testing.T) {
+	trace := map[string]int{"meta_v": 3, "meta_interval_sec": 10, "meta_precision": 10, "meta_total_reqs": 1500, "meta_pending_reqs": 0}
+	for input := 60; input < 140; input++ {
+		for output := 30; output < 90; output += 3 {
+			trace[fmt.Sprintf("%d:%d", input, output)] = input*output%7 + 1
+		}
+	}
+
+	jsonData, err := EncodeRequestTrace(trace, RequestTraceEncodingJSON)
+	require.NoError(t, err)
+	binaryData, err := EncodeRequestTrace(trace, RequestTraceEncodingBinary)
+	require.NoError(t, err)
+	assert.Less(t, len(binaryData)*3, len(jsonData))
+
+	for _, data := range [][]byte{jsonData, binaryData} {
+		decoded, err := DecodeRequestTrace(data)
+		require.NoError(t, err)
+		assert.Equal(t, trace, decoded)
+	}
+
+	// the empty encoding is JSON.
+	data, err := EncodeRequestTrace(map[string]int{"93:70": 4}, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"93:70": 4}`, string(data))
+
+	_, err = EncodeRequestTrace(map[string]int{"93": 4}, RequestTraceEncodingBinary)
+	assert.Error(t, err)
+}
+
+func TestDecodeInvalidRequestTrace(t *testing.T) {
+	data, err := EncodeRequestTrace(map[string]int{"meta_v": 3, "93:70": 4, "93:72": 1}, RequestTraceEncodingBinary)
+	require.NoError(t, err)
+	for i := len(requestTraceBinaryMagic); i < len(data); i++ {
+		_, err := DecodeRequestTrace(data[:i])
+		assert.Error(t, err, "truncated at %d", i)
+	}
+	_, err = DecodeRequestTrace(append(data, 0))
+	assert.Error(t, err)
+
+	_, err = DecodeRequestTrace([]byte("ATR\x02"))
+	assert.ErrorContains(t, err, "unsupported request trace binary version 2")
+	_, err = DecodeRequestTrace([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestRequestTraceEncodingConfig(t *testing.T) {
+	t.Setenv("AIBRIX_REQUEST_TRACE_ENCODING", RequestTraceEncodingBinary)
+	config := loadRequestTraceEnv()
+	assert.Equal(t, RequestTraceEncodingBinary, config.Encoding)
+
+	parsed, err := ParseRequestTraceMeta([]byte(`{"keySchema": "v1", "intervalSeconds": 10, "ttlSeconds": 600, "encoding": "binary"}`))
+	require.NoError(t, err)
+	assert.Equal(t, RequestTraceEncodingBinary, parsed.Encoding)
+
+	_, err = ParseGatewayConfig([]byte(`{"requestTraceEncoding": "gzip"}`))
+	assert.ErrorContains(t, err, "unknown request trace encoding")
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoadRequestTraceEnv(t *testing.T) {
+	config := loadRequestTraceEnv()
+	assert.Equal(t, RequestTraceConfig{Interval: 10 * time.Second, TTL: 10 * time.Minute, KeyPrefix: "aibrix:", KeySchema: "v1"}, config)
+	assert.Equal(t, "aibrix:llama_request_trace_1700000000", config.Key("llama", 1700000000))
+
+	t.Setenv("AIBRIX_REQUEST_TRACE_INTERVAL_S", "30")
+	t.Setenv("AIBRIX_REQUEST_TRACE_TTL_S", "3600")
+	t.Setenv("AIBRIX_REQUEST_TRACE_KEY_PREFIX", "prod:")
+	t.Setenv("AIBRIX_REQUEST_TRACE_KEY_SCHEMA", "v2")
+	config = loadRequestTraceEnv()
+	assert.Equal(t, RequestTraceConfig{Interval: 30 * time.Second, TTL: time.Hour, KeyPrefix: "prod:", KeySchema: "v2"}, config)
+	assert.Equal(t, "prod:request_trace:v2:llama:1700000000", config.Key("llama", 1700000000))
+	assert.Equal(t, "prod:request_trace_meta", config.MetaKey())
+
+	// a ttl shorter than the interval falls back to the defaults.
+	t.Setenv("AIBRIX_REQUEST_TRACE_TTL_S", "10")
+	config = loadRequestTraceEnv()
+	assert.Equal(t, 10*time.Second, config.Interval)
+	assert.Equal(t, 10*time.Minute, config.TTL)
+}
+
+func TestRequestTraceFromGatewayConfig(t *testing.T) {
+	SetGateway(GatewayConfig{RequestTraceInterval: &metav1.Duration{Duration: 5 * time.Second}, RequestTraceKeySchema: "v2"})
+	defer SetGateway(GatewayConfig{})
+
+	config := RequestTrace()
+	assert.Equal(t, 5*time.Second, config.Interval)
+	assert.Equal(t, 10*time.Minute, config.TTL)
+	assert.Equal(t, "aibrix:request_trace:v2:llama:", config.ModelKeyPrefix("llama"))
+}
+
+func TestRequestTraceMeta(t *testing.T) {
+	config := RequestTraceConfig{Interval: 30 * time.Second, TTL: time.Hour, KeyPrefix: "aibrix:", KeySchema: "v1"}
+	data, err := json.Marshal(config.Meta(3, 10))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 3,
+		"keySchema": "v1",
+		"keyPrefix": "aibrix:",
+		"keyFormat": "aibrix:{model}_request_trace_{timestamp}",
+		"intervalSeconds": 30,
+		"ttlSeconds": 3600,
+		"precision": 10
+	}`, string(data))
+
+	parsed, err := ParseRequestTraceMeta(data)
+	assert.NoError(t, err)
+	assert.Equal(t, config, parsed)
+
+	_, err = ParseRequestTraceMeta([]byte(`{"intervalSeconds": 0}`))
+	assert.Error(t, err)
+}
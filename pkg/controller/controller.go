@@ -20,10 +20,12 @@ import (
 	"github.com/vllm-project/aibrix/pkg/config"
 	"github.com/vllm-project/aibrix/pkg/controller/kvcache"
 	"github.com/vllm-project/aibrix/pkg/controller/modeladapter"
+	"github.com/vllm-project/aibrix/pkg/controller/modelprewarm"
 	"github.com/vllm-project/aibrix/pkg/controller/modelrouter"
 	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler"
 	"github.com/vllm-project/aibrix/pkg/controller/rayclusterfleet"
 	"github.com/vllm-project/aibrix/pkg/controller/rayclusterreplicaset"
+	"github.com/vllm-project/aibrix/pkg/controller/stormservice"
 	"github.com/vllm-project/aibrix/pkg/features"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/klog/v2"
@@ -59,6 +61,14 @@ func Initialize() {
 	if features.IsControllerEnabled(features.KVCacheController) {
 		controllerAddFuncs = append(controllerAddFuncs, kvcache.Add)
 	}
+
+	if features.IsControllerEnabled(features.StormServiceController) {
+		controllerAddFuncs = append(controllerAddFuncs, stormservice.Add)
+	}
+
+	if features.IsControllerEnabled(features.ModelPrewarmController) {
+		controllerAddFuncs = append(controllerAddFuncs, modelprewarm.Add)
+	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
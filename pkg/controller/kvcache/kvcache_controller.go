@@ -20,13 +20,17 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
 	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
 	"github.com/vllm-project/aibrix/pkg/config"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -34,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -56,6 +61,10 @@ const (
 
 	KVCacheLabelValueRoleCache    = "cache"
 	KVCacheLabelValueRoleMetadata = "metadata"
+
+	kvCacheRPCPort    = 9600
+	kvCacheSocketPath = "/var/run/vineyard.sock"
+	kvCacheSpillPath  = "/var/vineyard/spill"
 )
 
 var (
@@ -117,7 +126,8 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 			predicate.AnnotationChangedPredicate{},
 		))).
 		Owns(&corev1.Service{}).
-		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&appsv1.StatefulSet{}).
 		Watches(&corev1.Pod{}, &handler.EnqueueRequestForObject{}, builder.WithPredicates(podWithLabelFilter(KVCacheLabelKeyIdentifier))).
 		Complete(r)
 
@@ -140,8 +150,10 @@ type KVCacheReconciler struct {
 // +kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile reconciles a KVCache to desired state.
 func (r *KVCacheReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -163,8 +175,14 @@ func (r *KVCacheReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
-	// Handle kvCache Deployment
-	err = r.reconcileDeployment(ctx, kvCache)
+	// The cache pods used to be run by a Deployment, clean it up.
+	err = r.deleteLegacyDeployment(ctx, kvCache)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Handle kvCache StatefulSet
+	statefulSet, err := r.reconcileStatefulSet(ctx, kvCache)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -175,6 +193,17 @@ func (r *KVCacheReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
+	// Publish the connection info for inference engines
+	err = r.reconcileConnectionConfigMap(ctx, kvCache)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.updateStatus(ctx, kvCache, statefulSet)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -343,9 +372,18 @@ func (r *KVCacheReconciler) reconcilePod(ctx context.Context, pod *corev1.Pod) e
 }
 
 func (r *KVCacheReconciler) reconcileServices(ctx context.Context, kvCache *orchestrationv1alpha1.KVCache) error {
+	servicePort := corev1.ServicePort{Name: "vineyard-rpc", Port: rpcServicePort(kvCache), TargetPort: intstr.FromInt(kvCacheRPCPort), Protocol: corev1.ProtocolTCP}
+	if kvCache.Spec.Service.Type == corev1.ServiceTypeNodePort && kvCache.Spec.Service.NodePort != nil {
+		servicePort.NodePort = *kvCache.Spec.Service.NodePort
+	}
+	serviceType := kvCache.Spec.Service.Type
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeClusterIP
+	}
+
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-rpc", kvCache.Name),
+			Name:      rpcServiceName(kvCache),
 			Namespace: kvCache.Namespace,
 			Labels: map[string]string{
 				KVCacheLabelKeyIdentifier: kvCache.Name,
@@ -355,14 +393,12 @@ func (r *KVCacheReconciler) reconcileServices(ctx context.Context, kvCache *orch
 			},
 		},
 		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{
-				{Name: "vineyard-rpc", Port: 9600, TargetPort: intstr.FromInt(9600), Protocol: corev1.ProtocolTCP},
-			},
+			Ports: []corev1.ServicePort{servicePort},
 			Selector: map[string]string{
 				KVCacheLabelKeyIdentifier: kvCache.Name,
 				KVCacheLabelKeyRole:       KVCacheLabelValueRoleCache,
 			},
-			Type: corev1.ServiceTypeClusterIP,
+			Type: serviceType,
 		},
 	}
 
@@ -370,7 +406,33 @@ func (r *KVCacheReconciler) reconcileServices(ctx context.Context, kvCache *orch
 		return err
 	}
 
-	return nil
+	// The headless service gives every cache pod of the statefulset a stable network identity.
+	headlessService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      headlessServiceName(kvCache),
+			Namespace: kvCache.Namespace,
+			Labels: map[string]string{
+				KVCacheLabelKeyIdentifier: kvCache.Name,
+				KVCacheLabelKeyRole:       KVCacheLabelValueRoleCache,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(kvCache, orchestrationv1alpha1.GroupVersion.WithKind("KVCache")),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{
+				{Name: "vineyard-rpc", Port: kvCacheRPCPort, TargetPort: intstr.FromInt(kvCacheRPCPort), Protocol: corev1.ProtocolTCP},
+			},
+			Selector: map[string]string{
+				KVCacheLabelKeyIdentifier: kvCache.Name,
+				KVCacheLabelKeyRole:       KVCacheLabelValueRoleCache,
+			},
+			Type: corev1.ServiceTypeClusterIP,
+		},
+	}
+
+	return r.reconcileService(ctx, headlessService)
 }
 
 func (r *KVCacheReconciler) reconcileService(ctx context.Context, service *corev1.Service) error {
@@ -403,7 +465,54 @@ func needsUpdateService(service, found *corev1.Service) bool {
 		service.Spec.Type != found.Spec.Type
 }
 
-func (r *KVCacheReconciler) reconcileDeployment(ctx context.Context, kvCache *orchestrationv1alpha1.KVCache) error {
+// deleteLegacyDeployment removes the Deployment earlier versions ran the cache pods with,
+// they are run by a StatefulSet now.
+func (r *KVCacheReconciler) deleteLegacyDeployment(ctx context.Context, kvCache *orchestrationv1alpha1.KVCache) error {
+	found := &appsv1.Deployment{}
+	err := r.Get(ctx, types.NamespacedName{Name: kvCache.Name, Namespace: kvCache.Namespace}, found)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(found, kvCache) {
+		return nil
+	}
+
+	klog.InfoS("Deleting legacy Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, found))
+}
+
+func (r *KVCacheReconciler) reconcileStatefulSet(ctx context.Context, kvCache *orchestrationv1alpha1.KVCache) (*appsv1.StatefulSet, error) {
+	statefulSet, err := constructStatefulSet(kvCache)
+	if err != nil {
+		return nil, err
+	}
+
+	found := &appsv1.StatefulSet{}
+	err = r.Get(ctx, types.NamespacedName{Name: statefulSet.Name, Namespace: statefulSet.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		klog.InfoS("Creating a new StatefulSet", "StatefulSet.Namespace", statefulSet.Namespace, "StatefulSet.Name", statefulSet.Name)
+		if err := r.Create(ctx, statefulSet); err != nil {
+			return nil, err
+		}
+		return statefulSet, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if needsUpdateStatefulSet(statefulSet, found) {
+		// only replicas and the pod template of a statefulset can be changed.
+		found.Spec.Replicas = statefulSet.Spec.Replicas
+		found.Spec.Template = statefulSet.Spec.Template
+		klog.InfoS("Updating StatefulSet", "StatefulSet.Namespace", found.Namespace, "StatefulSet.Name", found.Name)
+		if err := r.Update(ctx, found); err != nil {
+			return nil, err
+		}
+	}
+
+	return found, nil
+}
+
+func constructStatefulSet(kvCache *orchestrationv1alpha1.KVCache) (*appsv1.StatefulSet, error) {
 	envs := []corev1.EnvVar{
 		{Name: "VINEYARDD_UID", Value: string(kvCache.ObjectMeta.UID)},
 		{Name: "VINEYARDD_NAME", Value: kvCache.Name},
@@ -463,33 +572,86 @@ func (r *KVCacheReconciler) reconcileDeployment(ctx context.Context, kvCache *or
 		affinity.PodAffinity = podAffinity
 	}
 
-	deployment := &appsv1.Deployment{
+	cpu, err := resource.ParseQuantity(kvCache.Spec.Cache.CPU)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cpu of kvcache %s: %w", kvCache.Name, err)
+	}
+	memory, err := resource.ParseQuantity(kvCache.Spec.Cache.Memory)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory of kvcache %s: %w", kvCache.Name, err)
+	}
+
+	shm := &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}
+	if size := kvCache.Spec.Cache.SharedMemorySize; size != "" {
+		sizeLimit, err := resource.ParseQuantity(size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shared memory size of kvcache %s: %w", kvCache.Name, err)
+		}
+		shm.SizeLimit = &sizeLimit
+	}
+
+	volumeMounts := []corev1.VolumeMount{
+		{Name: "vineyard-socket", MountPath: "/var/run"},
+		{Name: "shm", MountPath: "/dev/shm"},
+		{Name: "log", MountPath: "/var/log/vineyard"},
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: "vineyard-socket",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: socketHostPath(kvCache),
+				},
+			},
+		},
+		{
+			Name:         "shm",
+			VolumeSource: corev1.VolumeSource{EmptyDir: shm},
+		},
+		{
+			Name: "log",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+	if eviction := kvCache.Spec.Cache.Eviction; eviction != nil && eviction.Policy == orchestrationv1alpha1.EvictionPolicySpill {
+		spill := &corev1.EmptyDirVolumeSource{}
+		if size := eviction.SpillVolumeSize; size != "" {
+			sizeLimit, err := resource.ParseQuantity(size)
+			if err != nil {
+				return nil, fmt.Errorf("invalid spill volume size of kvcache %s: %w", kvCache.Name, err)
+			}
+			spill.SizeLimit = &sizeLimit
+		}
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "spill", MountPath: kvCacheSpillPath})
+		volumes = append(volumes, corev1.Volume{Name: "spill", VolumeSource: corev1.VolumeSource{EmptyDir: spill}})
+	}
+
+	labels := map[string]string{
+		KVCacheLabelKeyIdentifier: kvCache.Name,
+		KVCacheLabelKeyRole:       KVCacheLabelValueRoleCache,
+	}
+	statefulSet := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      kvCache.Name,
 			Namespace: kvCache.Namespace,
-			Labels: map[string]string{
-				KVCacheLabelKeyIdentifier: kvCache.Name,
-				KVCacheLabelKeyRole:       "cache",
-			},
+			Labels:    labels,
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(kvCache, orchestrationv1alpha1.GroupVersion.WithKind("KVCache")),
 			},
 		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: &kvCache.Spec.Replicas,
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    ptr.To(kvCache.Spec.Replicas),
+			ServiceName: headlessServiceName(kvCache),
+			// cache pods don't depend on each other, start and stop them all at once.
+			PodManagementPolicy: appsv1.ParallelPodManagement,
 			Selector: &metav1.LabelSelector{
-				// TODO: update metadata
-				MatchLabels: map[string]string{
-					KVCacheLabelKeyIdentifier: kvCache.Name,
-					KVCacheLabelKeyRole:       KVCacheLabelValueRoleCache,
-				},
+				MatchLabels: labels,
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						KVCacheLabelKeyIdentifier: kvCache.Name,
-						KVCacheLabelKeyRole:       KVCacheLabelValueRoleCache,
-					},
+					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
@@ -497,29 +659,29 @@ func (r *KVCacheReconciler) reconcileDeployment(ctx context.Context, kvCache *or
 							Name:  "kvcache",
 							Image: kvCache.Spec.Cache.Image,
 							Ports: []corev1.ContainerPort{
-								{Name: "rpc", ContainerPort: 9600, Protocol: corev1.ProtocolTCP},
+								{Name: "rpc", ContainerPort: kvCacheRPCPort, Protocol: corev1.ProtocolTCP},
 							},
 							Command: []string{
 								"/bin/bash",
 								"-c",
-								fmt.Sprintf(`/usr/local/bin/vineyardd --sync_crds true --socket /var/run/vineyard.sock --size --stream_threshold 80 --etcd_cmd etcd --etcd_prefix /vineyard --etcd_endpoint http://%s-etcd-service:2379`, kvCache.Name),
+								cacheServerCommand(kvCache),
 							},
 							Env: append(envs, kvCache.Spec.Cache.Env...),
 							Resources: corev1.ResourceRequirements{
 								Limits: corev1.ResourceList{
-									corev1.ResourceCPU:    resource.MustParse(kvCache.Spec.Cache.CPU),
-									corev1.ResourceMemory: resource.MustParse(kvCache.Spec.Cache.Memory),
+									corev1.ResourceCPU:    cpu,
+									corev1.ResourceMemory: memory,
 								},
 								Requests: corev1.ResourceList{
-									corev1.ResourceCPU:    resource.MustParse(kvCache.Spec.Cache.CPU),
-									corev1.ResourceMemory: resource.MustParse(kvCache.Spec.Cache.Memory),
+									corev1.ResourceCPU:    cpu,
+									corev1.ResourceMemory: memory,
 								},
 							},
 							ImagePullPolicy: corev1.PullPolicy(kvCache.Spec.Cache.ImagePullPolicy),
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									Exec: &corev1.ExecAction{
-										Command: []string{"ls", "/var/run/vineyard.sock"},
+										Command: []string{"ls", kvCacheSocketPath},
 									},
 								},
 							},
@@ -530,82 +692,163 @@ func (r *KVCacheReconciler) reconcileDeployment(ctx context.Context, kvCache *or
 								TimeoutSeconds:   1,
 								ProbeHandler: corev1.ProbeHandler{
 									TCPSocket: &corev1.TCPSocketAction{
-										Port: intstr.FromInt(9600),
+										Port: intstr.FromInt(kvCacheRPCPort),
 									},
 								},
 							},
-							VolumeMounts: []corev1.VolumeMount{
-								{Name: "vineyard-socket", MountPath: "/var/run"},
-								{Name: "shm", MountPath: "/dev/shm"},
-								{Name: "log", MountPath: "/var/log/vineyard"},
-							},
+							VolumeMounts: volumeMounts,
 						},
 					},
 					Affinity: &affinity,
-					Volumes: []corev1.Volume{
-						{
-							Name: "vineyard-socket",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: fmt.Sprintf("/var/run/vineyard-kubernetes/%s/%s", kvCache.Namespace, kvCache.Name),
-								},
-							},
-						},
-						{
-							Name: "shm",
-							VolumeSource: corev1.VolumeSource{
-								EmptyDir: &corev1.EmptyDirVolumeSource{
-									Medium: "Memory",
-								},
-							},
-						},
-						{
-							Name: "log",
-							VolumeSource: corev1.VolumeSource{
-								EmptyDir: &corev1.EmptyDirVolumeSource{},
-							},
-						},
-					},
+					Volumes:  volumes,
 				},
 			},
 		},
 	}
+	return statefulSet, nil
+}
 
-	found := &appsv1.Deployment{}
-	err := r.Get(ctx, types.NamespacedName{Name: deployment.Name, Namespace: deployment.Namespace}, found)
-	if err != nil && errors.IsNotFound(err) {
-		klog.InfoS("Creating a new Deployment", "Deployment.Namespace", deployment.Namespace, "Deployment.Name", deployment.Name)
-		err = r.Create(ctx, deployment)
-		if err != nil {
-			return err
+// cacheServerCommand returns the vineyardd command line of the cache pods, sized and evicting
+// as the spec of the kv cache asks.
+func cacheServerCommand(kvCache *orchestrationv1alpha1.KVCache) string {
+	args := []string{
+		"/usr/local/bin/vineyardd",
+		"--sync_crds", "true",
+		"--socket", kvCacheSocketPath,
+	}
+	if size := kvCache.Spec.Cache.SharedMemorySize; size != "" {
+		args = append(args, "--size", size)
+	}
+	args = append(args,
+		"--stream_threshold", "80",
+		"--etcd_cmd", "etcd",
+		"--etcd_prefix", "/vineyard",
+		"--etcd_endpoint", fmt.Sprintf("http://%s-etcd-service:2379", kvCache.Name),
+	)
+	if eviction := kvCache.Spec.Cache.Eviction; eviction != nil && eviction.Policy == orchestrationv1alpha1.EvictionPolicySpill {
+		args = append(args,
+			"--spill_path", kvCacheSpillPath,
+			"--spill_upper_rate", strconv.FormatFloat(float64(eviction.HighWatermark)/100, 'f', -1, 64),
+			"--spill_lower_rate", strconv.FormatFloat(float64(eviction.LowWatermark)/100, 'f', -1, 64),
+		)
+	}
+	return strings.Join(args, " ")
+}
+
+// needsUpdateStatefulSet checks if the statefulset spec of the new statefulset differs from the existing one,
+// only replicas and the image and command of the containers are considered at this moment.
+func needsUpdateStatefulSet(statefulSet *appsv1.StatefulSet, found *appsv1.StatefulSet) bool {
+	if !reflect.DeepEqual(statefulSet.Spec.Replicas, found.Spec.Replicas) {
+		return true
+	}
+	if len(statefulSet.Spec.Template.Spec.Containers) != len(found.Spec.Template.Spec.Containers) {
+		return true
+	}
+	for i, container := range found.Spec.Template.Spec.Containers {
+		desired := statefulSet.Spec.Template.Spec.Containers[i]
+		if desired.Image != container.Image || !reflect.DeepEqual(desired.Command, container.Command) {
+			return true
 		}
+	}
+	return false
+}
+
+// reconcileConnectionConfigMap publishes the environment variables inference engines connect to
+// the kv cache with, engines load them with envFrom.
+func (r *KVCacheReconciler) reconcileConnectionConfigMap(ctx context.Context, kvCache *orchestrationv1alpha1.KVCache) error {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      connectionConfigMapName(kvCache),
+			Namespace: kvCache.Namespace,
+			Labels: map[string]string{
+				KVCacheLabelKeyIdentifier: kvCache.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(kvCache, orchestrationv1alpha1.GroupVersion.WithKind("KVCache")),
+			},
+		},
+		Data: connectionEnvs(kvCache),
+	}
+
+	found := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		klog.InfoS("Creating a new ConfigMap", "ConfigMap.Namespace", configMap.Namespace, "ConfigMap.Name", configMap.Name)
+		return r.Create(ctx, configMap)
 	} else if err != nil {
 		return err
-	} else if needsUpdateDeployment(deployment, found) {
-		found.Spec = deployment.Spec
-		klog.InfoS("Updating Deployment", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
-		err = r.Update(ctx, found)
-		if err != nil {
-			return err
-		}
 	}
 
+	if !reflect.DeepEqual(configMap.Data, found.Data) {
+		found.Data = configMap.Data
+		klog.InfoS("Updating ConfigMap", "ConfigMap.Namespace", found.Namespace, "ConfigMap.Name", found.Name)
+		return r.Update(ctx, found)
+	}
 	return nil
 }
 
-// needsUpdateDeployment checks if the deployment spec of the new deployment differs from the existing one
-// only image and replicas are considered at this moment.
-func needsUpdateDeployment(deployment *appsv1.Deployment, found *appsv1.Deployment) bool {
-	imageChanged := false
-	for i, container := range found.Spec.Template.Spec.Containers {
-		if len(deployment.Spec.Template.Spec.Containers) > i {
-			if deployment.Spec.Template.Spec.Containers[i].Image != container.Image {
-				// update the image
-				found.Spec.Template.Spec.Containers[i].Image = deployment.Spec.Template.Spec.Containers[i].Image
-				imageChanged = true
-			}
-		}
+// connectionEnvs returns the environment variables of the inference engines using the kv cache.
+func connectionEnvs(kvCache *orchestrationv1alpha1.KVCache) map[string]string {
+	return map[string]string{
+		"AIBRIX_LLM_KV_CACHE":              "1",
+		"AIBRIX_LLM_KV_CACHE_SOCKET":       kvCacheSocketPath,
+		"AIBRIX_LLM_KV_CACHE_RPC_ENDPOINT": rpcEndpoint(kvCache),
+		"VLLM_USE_VINEYARD_CACHE":          "1",
+	}
+}
+
+// updateStatus reports the readiness of the cache pods and where engines connect to the cache.
+func (r *KVCacheReconciler) updateStatus(ctx context.Context, kvCache *orchestrationv1alpha1.KVCache, statefulSet *appsv1.StatefulSet) error {
+	newStatus := kvCache.Status.DeepCopy()
+	newStatus.ReadyReplicas = statefulSet.Status.ReadyReplicas
+	newStatus.RPCEndpoint = rpcEndpoint(kvCache)
+	newStatus.MetadataEndpoint = fmt.Sprintf("http://%s-etcd-service.%s:2379", kvCache.Name, kvCache.Namespace)
+	newStatus.SocketHostPath = socketHostPath(kvCache)
+	newStatus.ConnectionConfigMap = connectionConfigMapName(kvCache)
+
+	condition := metav1.Condition{
+		Type:    orchestrationv1alpha1.KVCacheReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "CachePodsReady",
+		Message: "All the cache pods are ready",
+	}
+	if newStatus.ReadyReplicas < kvCache.Spec.Replicas {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "CachePodsNotReady"
+		condition.Message = fmt.Sprintf("%d of %d cache pods are ready", newStatus.ReadyReplicas, kvCache.Spec.Replicas)
+	}
+	meta.SetStatusCondition(&newStatus.Conditions, condition)
+
+	if equality.Semantic.DeepEqual(&kvCache.Status, newStatus) {
+		return nil
+	}
+	kvCache.Status = *newStatus
+	return r.Status().Update(ctx, kvCache)
+}
+
+func rpcServicePort(kvCache *orchestrationv1alpha1.KVCache) int32 {
+	if kvCache.Spec.Service.Port != 0 {
+		return kvCache.Spec.Service.Port
 	}
+	return kvCacheRPCPort
+}
+
+func rpcServiceName(kvCache *orchestrationv1alpha1.KVCache) string {
+	return fmt.Sprintf("%s-rpc", kvCache.Name)
+}
+
+func rpcEndpoint(kvCache *orchestrationv1alpha1.KVCache) string {
+	return fmt.Sprintf("%s.%s:%d", rpcServiceName(kvCache), kvCache.Namespace, rpcServicePort(kvCache))
+}
+
+func headlessServiceName(kvCache *orchestrationv1alpha1.KVCache) string {
+	return fmt.Sprintf("%s-headless", kvCache.Name)
+}
+
+func connectionConfigMapName(kvCache *orchestrationv1alpha1.KVCache) string {
+	return fmt.Sprintf("%s-connection", kvCache.Name)
+}
 
-	return !reflect.DeepEqual(deployment.Spec.Replicas, found.Spec.Replicas) || imageChanged
+func socketHostPath(kvCache *orchestrationv1alpha1.KVCache) string {
+	return fmt.Sprintf("/var/run/vineyard-kubernetes/%s/%s", kvCache.Namespace, kvCache.Name)
 }
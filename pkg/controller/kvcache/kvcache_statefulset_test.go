@@ -0,0 +1,92 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvcache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+)
+
+func makeKVCache() *orchestrationv1alpha1.KVCache {
+	return &orchestrationv1alpha1.KVCache{
+		ObjectMeta: metav1.ObjectMeta{Name: "qwen-kvcache", Namespace: "default"},
+		Spec: orchestrationv1alpha1.KVCacheSpec{
+			Replicas: 2,
+			Cache: orchestrationv1alpha1.CacheSpec{
+				Image:  "aibrix/kvcache:20241120",
+				CPU:    "1",
+				Memory: "4Gi",
+			},
+			Service: orchestrationv1alpha1.ServiceConfig{Port: 9600},
+		},
+	}
+}
+
+func TestCacheServerCommand(t *testing.T) {
+	kvCache := makeKVCache()
+	assert.Equal(t, "/usr/local/bin/vineyardd --sync_crds true --socket /var/run/vineyard.sock --stream_threshold 80 "+
+		"--etcd_cmd etcd --etcd_prefix /vineyard --etcd_endpoint http://qwen-kvcache-etcd-service:2379", cacheServerCommand(kvCache))
+
+	kvCache.Spec.Cache.SharedMemorySize = "8Gi"
+	kvCache.Spec.Cache.Eviction = &orchestrationv1alpha1.EvictionSpec{
+		Policy:        orchestrationv1alpha1.EvictionPolicySpill,
+		HighWatermark: 90,
+		LowWatermark:  50,
+	}
+	assert.Equal(t, "/usr/local/bin/vineyardd --sync_crds true --socket /var/run/vineyard.sock --size 8Gi --stream_threshold 80 "+
+		"--etcd_cmd etcd --etcd_prefix /vineyard --etcd_endpoint http://qwen-kvcache-etcd-service:2379 "+
+		"--spill_path /var/vineyard/spill --spill_upper_rate 0.9 --spill_lower_rate 0.5", cacheServerCommand(kvCache))
+}
+
+func TestConstructStatefulSet(t *testing.T) {
+	kvCache := makeKVCache()
+	kvCache.Spec.Cache.SharedMemorySize = "8Gi"
+	kvCache.Spec.Cache.Eviction = &orchestrationv1alpha1.EvictionSpec{
+		Policy:          orchestrationv1alpha1.EvictionPolicySpill,
+		SpillVolumeSize: "100Gi",
+	}
+
+	statefulSet, err := constructStatefulSet(kvCache)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), *statefulSet.Spec.Replicas)
+	assert.Equal(t, "qwen-kvcache-headless", statefulSet.Spec.ServiceName)
+
+	volumes := map[string]resource.Quantity{}
+	for _, volume := range statefulSet.Spec.Template.Spec.Volumes {
+		if volume.EmptyDir != nil && volume.EmptyDir.SizeLimit != nil {
+			volumes[volume.Name] = *volume.EmptyDir.SizeLimit
+		}
+	}
+	assert.Equal(t, map[string]resource.Quantity{"shm": resource.MustParse("8Gi"), "spill": resource.MustParse("100Gi")}, volumes)
+
+	kvCache.Spec.Cache.Memory = "lots"
+	_, err = constructStatefulSet(kvCache)
+	assert.Error(t, err)
+}
+
+func TestConnectionEnvs(t *testing.T) {
+	kvCache := makeKVCache()
+	kvCache.Spec.Service.Port = 9700
+	envs := connectionEnvs(kvCache)
+	assert.Equal(t, "qwen-kvcache-rpc.default:9700", envs["AIBRIX_LLM_KV_CACHE_RPC_ENDPOINT"])
+	assert.Equal(t, "/var/run/vineyard.sock", envs["AIBRIX_LLM_KV_CACHE_SOCKET"])
+}
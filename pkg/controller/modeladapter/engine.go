@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/runtimeapi"
+)
+
+const (
+	// ModelAdapterEngineLabelKey is the pod label naming the inference engine, vLLM is assumed if it's missing.
+	ModelAdapterEngineLabelKey = "model.aibrix.ai/engine"
+	// ModelPortLabelKey is the pod label overriding the default port of the inference engine.
+	ModelPortLabelKey = "model.aibrix.ai/port"
+
+	EngineVLLM   = "vllm"
+	EngineSGLang = "sglang"
+	EngineTRTLLM = "trtllm"
+
+	DefaultSGLangEnginePort = "30000"
+
+	SGLangLoadLoraAdapterPath   = "/load_lora_adapter"
+	SGLangUnloadLoraAdapterPath = "/unload_lora_adapter"
+)
+
+// adapterEngine talks to the LoRA adapter API of an inference engine.
+type adapterEngine interface {
+	// urls returns the endpoints listing, loading and unloading the adapters on the pod.
+	urls(pod *corev1.Pod, runtimeConfig config.RuntimeConfig) URLConfig
+	// loadPayload returns the request body loading the adapter from the artifact.
+	loadPayload(adapterName, artifactURL string) (map[string]string, error)
+	// unloadPayload returns the request body unloading the adapter.
+	unloadPayload(adapterName string) map[string]string
+	// dynamicLoading is false for engines that load the adapter weights with the requests,
+	// the controller only manages the placement and service discovery for them.
+	dynamicLoading() bool
+}
+
+// engineForPod returns the engine serving on the pod according to its engine label.
+func engineForPod(pod *corev1.Pod) (adapterEngine, error) {
+	switch engine := pod.Labels[ModelAdapterEngineLabelKey]; engine {
+	case "", EngineVLLM:
+		return vllmEngine{}, nil
+	case EngineSGLang:
+		return sglangEngine{}, nil
+	case EngineTRTLLM:
+		return trtllmEngine{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported inference engine %q of pod %s/%s", engine, pod.Namespace, pod.Name)
+	}
+}
+
+// runtimeClient returns the client of the runtime sidecar fronting the engine on the pod, false if the controller
+// talks to the engine directly.
+func (r *ModelAdapterReconciler) runtimeClient(pod *corev1.Pod, engine adapterEngine, instance *modelv1alpha1.ModelAdapter) (*runtimeapi.Client, bool) {
+	if _, ok := engine.(vllmEngine); !ok || !r.RuntimeConfig.EnableRuntimeSidecar {
+		return nil, false
+	}
+	return runtimeapi.NewClient(engine.urls(pod, r.RuntimeConfig).BaseURL, instance.Spec.AdditionalConfig["api-key"]), true
+}
+
+// loraPath translates the artifact URL into the path the engine loads the adapter from.
+func loraPath(artifactURL string) (string, error) {
+	if strings.HasPrefix(artifactURL, "huggingface://") {
+		return extractHuggingFacePath(artifactURL)
+	}
+	// TODO: extend to other artifacts
+	return artifactURL, nil
+}
+
+type vllmEngine struct{}
+
+func (vllmEngine) urls(pod *corev1.Pod, runtimeConfig config.RuntimeConfig) URLConfig {
+	return BuildURLs(pod.Status.PodIP, runtimeConfig)
+}
+
+func (vllmEngine) loadPayload(adapterName, artifactURL string) (map[string]string, error) {
+	path, err := loraPath(artifactURL)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"lora_name": adapterName, "lora_path": path}, nil
+}
+
+func (vllmEngine) unloadPayload(adapterName string) map[string]string {
+	return map[string]string{"lora_name": adapterName}
+}
+
+func (vllmEngine) dynamicLoading() bool {
+	return true
+}
+
+// sglangEngine uses the SGLang native adapter API. The runtime sidecar only fronts vLLM, so it's always skipped.
+type sglangEngine struct{}
+
+func (sglangEngine) urls(pod *corev1.Pod, runtimeConfig config.RuntimeConfig) URLConfig {
+	var host string
+	if runtimeConfig.DebugMode {
+		host = fmt.Sprintf("http://%s:%s", "localhost", DefaultDebugInferenceEnginePort)
+	} else {
+		port := DefaultSGLangEnginePort
+		if value, ok := pod.Labels[ModelPortLabelKey]; ok {
+			port = value
+		}
+		host = fmt.Sprintf("http://%s:%s", pod.Status.PodIP, port)
+	}
+	return URLConfig{
+		BaseURL:          host,
+		ListModelsURL:    fmt.Sprintf("%s%s", host, ModelListPath),
+		LoadAdapterURL:   fmt.Sprintf("%s%s", host, SGLangLoadLoraAdapterPath),
+		UnloadAdapterURL: fmt.Sprintf("%s%s", host, SGLangUnloadLoraAdapterPath),
+	}
+}
+
+func (sglangEngine) loadPayload(adapterName, artifactURL string) (map[string]string, error) {
+	path, err := loraPath(artifactURL)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"lora_name": adapterName, "lora_path": path}, nil
+}
+
+func (sglangEngine) unloadPayload(adapterName string) map[string]string {
+	return map[string]string{"lora_name": adapterName}
+}
+
+func (sglangEngine) dynamicLoading() bool {
+	return true
+}
+
+// trtllmEngine covers TensorRT-LLM, which has no adapter API and takes the adapter with each request.
+type trtllmEngine struct{}
+
+func (trtllmEngine) urls(pod *corev1.Pod, runtimeConfig config.RuntimeConfig) URLConfig {
+	return URLConfig{}
+}
+
+func (trtllmEngine) loadPayload(adapterName, artifactURL string) (map[string]string, error) {
+	return nil, nil
+}
+
+func (trtllmEngine) unloadPayload(adapterName string) map[string]string {
+	return nil
+}
+
+func (trtllmEngine) dynamicLoading() bool {
+	return false
+}
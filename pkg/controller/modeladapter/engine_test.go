@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+func enginePod(engine, ip, port string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default", Labels: map[string]string{}},
+		Status:     corev1.PodStatus{PodIP: ip},
+	}
+	if engine != "" {
+		pod.Labels[ModelAdapterEngineLabelKey] = engine
+	}
+	if port != "" {
+		pod.Labels[ModelPortLabelKey] = port
+	}
+	return pod
+}
+
+func TestEngineForPod(t *testing.T) {
+	engine, err := engineForPod(enginePod("", "10.0.0.1", ""))
+	assert.NoError(t, err)
+	assert.IsType(t, vllmEngine{}, engine)
+
+	engine, err = engineForPod(enginePod(EngineSGLang, "10.0.0.1", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, "http://10.0.0.1:30000/load_lora_adapter", engine.urls(enginePod(EngineSGLang, "10.0.0.1", ""), config.RuntimeConfig{EnableRuntimeSidecar: true}).LoadAdapterURL)
+	assert.Equal(t, "http://10.0.0.1:8001/v1/models", engine.urls(enginePod(EngineSGLang, "10.0.0.1", "8001"), config.RuntimeConfig{}).ListModelsURL)
+
+	engine, err = engineForPod(enginePod(EngineTRTLLM, "10.0.0.1", ""))
+	assert.NoError(t, err)
+	assert.False(t, engine.dynamicLoading())
+
+	_, err = engineForPod(enginePod("unknown", "10.0.0.1", ""))
+	assert.Error(t, err)
+}
+
+func TestEngineLoadPayload(t *testing.T) {
+	payload, err := sglangEngine{}.loadPayload("lora", "huggingface://org/adapter")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"lora_name": "lora", "lora_path": "org/adapter"}, payload)
+
+	payload, err = vllmEngine{}.loadPayload("lora", "/models/adapter")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"lora_name": "lora", "lora_path": "/models/adapter"}, payload)
+}
+
+func TestReconcileLoadingOnPodEngines(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	instance := &modelv1alpha1.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{Name: "lora", Namespace: "default"},
+		Spec:       modelv1alpha1.ModelAdapterSpec{ArtifactURL: "huggingface://org/adapter"},
+	}
+
+	t.Run("sglang", func(t *testing.T) {
+		var loaded map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case ModelListPath:
+				_, _ = w.Write([]byte(`{"data":[{"id":"base"}]}`))
+			case SGLangLoadLoraAdapterPath:
+				_ = json.NewDecoder(req.Body).Decode(&loaded)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+		host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+		assert.NoError(t, err)
+
+		r := &ModelAdapterReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(enginePod(EngineSGLang, host, port)).Build(), Recorder: record.NewFakeRecorder(10)}
+		assert.NoError(t, r.reconcileLoadingOnPod(context.TODO(), instance, "pod-1"))
+		assert.Equal(t, map[string]string{"lora_name": "lora", "lora_path": "org/adapter"}, loaded)
+	})
+
+	t.Run("trtllm", func(t *testing.T) {
+		r := &ModelAdapterReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(enginePod(EngineTRTLLM, "10.0.0.1", "")).Build(), Recorder: record.NewFakeRecorder(10)}
+		assert.NoError(t, r.reconcileLoadingOnPod(context.TODO(), instance, "pod-1"))
+		assert.NoError(t, r.unloadModelAdapterFromPod(instance, "pod-1"))
+	})
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+)
+
+// Reasons for the load state of the adapter on a pod
+const (
+	// InstanceScheduledReason is set when the adapter has been scheduled to the pod.
+	InstanceScheduledReason = "Scheduled"
+	// InstanceDownloadingReason is set while the runtime sidecar pre-pulls the artifact.
+	InstanceDownloadingReason = "ArtifactDownloading"
+	// InstanceLoadedReason is set when the engine on the pod serves the adapter.
+	InstanceLoadedReason = "Loaded"
+	// InstanceLoadFailedReason is set when the artifact could not be downloaded or loaded on the pod.
+	InstanceLoadFailedReason = "LoadFailed"
+)
+
+// setInstanceStatus records the load state of the adapter on the pod, an event is emitted when the phase changes.
+func (r *ModelAdapterReconciler) setInstanceStatus(instance *modelv1alpha1.ModelAdapter, podName string, phase modelv1alpha1.ModelAdapterInstancePhase, reason, message string) {
+	now := metav1.Now()
+	var status *modelv1alpha1.ModelAdapterInstanceStatus
+	for i := range instance.Status.InstanceStatuses {
+		if instance.Status.InstanceStatuses[i].PodName == podName {
+			status = &instance.Status.InstanceStatuses[i]
+			break
+		}
+	}
+	if status == nil {
+		instance.Status.InstanceStatuses = append(instance.Status.InstanceStatuses, modelv1alpha1.ModelAdapterInstanceStatus{PodName: podName})
+		status = &instance.Status.InstanceStatuses[len(instance.Status.InstanceStatuses)-1]
+	}
+
+	if status.Reason != reason || status.Message != message {
+		status.Reason = reason
+		status.Message = message
+		status.LastUpdateTime = now
+	}
+	if status.Phase == phase {
+		return
+	}
+	status.Phase = phase
+	status.LastTransitionTime = now
+
+	switch phase {
+	case modelv1alpha1.ModelAdapterInstanceFailed:
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "InstanceFailed", "ModelAdapter failed to load on pod %s: %s", podName, message)
+	case modelv1alpha1.ModelAdapterInstanceReady:
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "InstanceReady", "ModelAdapter has been loaded on pod %s", podName)
+	default:
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "Instance"+string(phase), "ModelAdapter is %s on pod %s: %s", phase, podName, message)
+	}
+}
+
+// recordLoadResult records the outcome of loading the artifact on the pod.
+func (r *ModelAdapterReconciler) recordLoadResult(instance *modelv1alpha1.ModelAdapter, podName, artifactURL string, err error) {
+	switch {
+	case err == nil:
+		r.setInstanceStatus(instance, podName, modelv1alpha1.ModelAdapterInstanceReady, InstanceLoadedReason,
+			fmt.Sprintf("Artifact %s is loaded", artifactURL))
+	case errors.Is(err, errArtifactDownloading):
+		r.setInstanceStatus(instance, podName, modelv1alpha1.ModelAdapterInstanceLoading, InstanceDownloadingReason, err.Error())
+	default:
+		r.setInstanceStatus(instance, podName, modelv1alpha1.ModelAdapterInstanceFailed, InstanceLoadFailedReason, err.Error())
+	}
+}
+
+// removeInstanceStatus drops the load state of the adapter on the pod once it's no longer scheduled there.
+func removeInstanceStatus(instance *modelv1alpha1.ModelAdapter, podName string) {
+	var statuses []modelv1alpha1.ModelAdapterInstanceStatus
+	for _, status := range instance.Status.InstanceStatuses {
+		if status.PodName != podName {
+			statuses = append(statuses, status)
+		}
+	}
+	instance.Status.InstanceStatuses = statuses
+}
+
+// pruneInstanceStatuses drops the load state of the pods which neither host an instance nor are active anymore,
+// e.g. the failures of pods which have been deleted.
+func pruneInstanceStatuses(instance *modelv1alpha1.ModelAdapter, activePods []corev1.Pod) {
+	active := make(map[string]struct{}, len(activePods))
+	for _, pod := range activePods {
+		active[pod.Name] = struct{}{}
+	}
+	var statuses []modelv1alpha1.ModelAdapterInstanceStatus
+	for _, status := range instance.Status.InstanceStatuses {
+		if _, ok := active[status.PodName]; ok || StringInSlice(instance.Status.Instances, status.PodName) {
+			statuses = append(statuses, status)
+		}
+	}
+	instance.Status.InstanceStatuses = statuses
+}
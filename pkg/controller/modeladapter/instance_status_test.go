@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+)
+
+func TestRecordLoadResult(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &ModelAdapterReconciler{Recorder: recorder}
+	instance := &modelv1alpha1.ModelAdapter{ObjectMeta: metav1.ObjectMeta{Name: "lora", Namespace: "default"}}
+
+	r.setInstanceStatus(instance, "pod-1", modelv1alpha1.ModelAdapterInstancePending, InstanceScheduledReason, "scheduled")
+	assert.Equal(t, "Normal InstancePending ModelAdapter is Pending on pod pod-1: scheduled", <-recorder.Events)
+
+	r.recordLoadResult(instance, "pod-1", "s3://bucket/lora", errArtifactDownloading)
+	assert.Equal(t, modelv1alpha1.ModelAdapterInstanceLoading, instance.Status.InstanceStatuses[0].Phase)
+	assert.Equal(t, InstanceDownloadingReason, instance.Status.InstanceStatuses[0].Reason)
+	<-recorder.Events
+
+	r.recordLoadResult(instance, "pod-1", "s3://bucket/lora", fmt.Errorf("engine returned 500"))
+	assert.Equal(t, modelv1alpha1.ModelAdapterInstanceFailed, instance.Status.InstanceStatuses[0].Phase)
+	assert.Equal(t, "engine returned 500", instance.Status.InstanceStatuses[0].Message)
+	assert.Equal(t, "Warning InstanceFailed ModelAdapter failed to load on pod pod-1: engine returned 500", <-recorder.Events)
+
+	// retrying with the same failure doesn't emit another event.
+	transition := instance.Status.InstanceStatuses[0].LastTransitionTime
+	r.recordLoadResult(instance, "pod-1", "s3://bucket/lora", fmt.Errorf("engine returned 500"))
+	assert.Empty(t, recorder.Events)
+	assert.Equal(t, transition, instance.Status.InstanceStatuses[0].LastTransitionTime)
+
+	r.recordLoadResult(instance, "pod-1", "s3://bucket/lora", nil)
+	assert.Len(t, instance.Status.InstanceStatuses, 1)
+	assert.Equal(t, modelv1alpha1.ModelAdapterInstanceReady, instance.Status.InstanceStatuses[0].Phase)
+	assert.Equal(t, "Artifact s3://bucket/lora is loaded", instance.Status.InstanceStatuses[0].Message)
+	assert.Equal(t, "Normal InstanceReady ModelAdapter has been loaded on pod pod-1", <-recorder.Events)
+}
+
+func TestPruneInstanceStatuses(t *testing.T) {
+	instance := &modelv1alpha1.ModelAdapter{Status: modelv1alpha1.ModelAdapterStatus{
+		Instances: []string{"pod-1"},
+		InstanceStatuses: []modelv1alpha1.ModelAdapterInstanceStatus{
+			{PodName: "pod-1", Phase: modelv1alpha1.ModelAdapterInstanceReady},
+			{PodName: "pod-2", Phase: modelv1alpha1.ModelAdapterInstanceFailed},
+			{PodName: "pod-3", Phase: modelv1alpha1.ModelAdapterInstanceFailed},
+		},
+	}}
+
+	pruneInstanceStatuses(instance, []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "pod-2"}}})
+	var pods []string
+	for _, status := range instance.Status.InstanceStatuses {
+		pods = append(pods, status.PodName)
+	}
+	assert.Equal(t, []string{"pod-1", "pod-2"}, pods)
+
+	removeInstanceStatus(instance, "pod-1")
+	assert.Len(t, instance.Status.InstanceStatuses, 1)
+	assert.Equal(t, "pod-2", instance.Status.InstanceStatuses[0].PodName)
+}
@@ -24,16 +24,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
 	"github.com/vllm-project/aibrix/pkg/cache"
 	"github.com/vllm-project/aibrix/pkg/config"
 	"github.com/vllm-project/aibrix/pkg/controller/modeladapter/scheduling"
+	"github.com/vllm-project/aibrix/pkg/runtimeapi"
 	"github.com/vllm-project/aibrix/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -96,6 +98,8 @@ const (
 	LoadLoraRuntimeAPIPath   = "/v1/lora_adapter/load"
 	UnloadLoraAdapterPath    = "/v1/unload_lora_adapter"
 	UnloadLoraRuntimeAPIPath = "/v1/lora_adapter/unload"
+
+	defaultDesiredInstancesIdleWindow = 10 * time.Minute
 )
 
 var (
@@ -103,8 +107,30 @@ var (
 	controllerName                     = "model-adapter-controller"
 	defaultModelAdapterSchedulerPolicy = "leastAdapters"
 	defaultRequeueDuration             = 3 * time.Second
+	// desiredInstancesIdleWindow is how long the desired instances raised by the gateway are kept.
+	desiredInstancesIdleWindow = getDesiredInstancesIdleWindow()
+
+	// schedulingPolicySchedulers maps the scheduling policy of the spec to the scheduler implementing it.
+	schedulingPolicySchedulers = map[modelv1alpha1.ModelAdapterSchedulingPolicy]string{
+		modelv1alpha1.ModelAdapterSchedulingSpread:      "leastAdapters",
+		modelv1alpha1.ModelAdapterSchedulingPack:        "binPack",
+		modelv1alpha1.ModelAdapterSchedulingLeastLoaded: "leastLoaded",
+	}
 )
 
+func getDesiredInstancesIdleWindow() time.Duration {
+	value := utils.LoadEnv("AIBRIX_LORA_SCALE_IN_IDLE_WINDOW_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_LORA_SCALE_IN_IDLE_WINDOW_S: %s, falling back to default", value)
+		} else {
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultDesiredInstancesIdleWindow
+}
+
 type URLConfig struct {
 	BaseURL          string
 	ListModelsURL    string
@@ -152,11 +178,16 @@ func newReconciler(mgr manager.Manager, runtimeConfig config.RuntimeConfig) (rec
 		klog.Fatal(err)
 	}
 
-	// TODO: policy should be configured by users
 	scheduler, err := scheduling.NewScheduler(defaultModelAdapterSchedulerPolicy, c)
 	if err != nil {
 		return nil, err
 	}
+	policySchedulers := map[modelv1alpha1.ModelAdapterSchedulingPolicy]scheduling.Scheduler{}
+	for policy, name := range schedulingPolicySchedulers {
+		if policySchedulers[policy], err = scheduling.NewScheduler(name, c); err != nil {
+			return nil, err
+		}
+	}
 
 	reconciler := &ModelAdapterReconciler{
 		Client:              mgr.GetClient(),
@@ -166,7 +197,11 @@ func newReconciler(mgr manager.Manager, runtimeConfig config.RuntimeConfig) (rec
 		EndpointSliceLister: endpointSliceLister,
 		Recorder:            mgr.GetEventRecorderFor(controllerName),
 		scheduler:           scheduler,
+		policySchedulers:    policySchedulers,
 		RuntimeConfig:       runtimeConfig,
+		metricsProvider:     c,
+		topology:            c,
+		analyzer:            newRolloutAnalyzer(),
 	}
 	return reconciler, nil
 }
@@ -242,6 +277,8 @@ type ModelAdapterReconciler struct {
 	Scheme    *runtime.Scheme
 	Recorder  record.EventRecorder
 	scheduler scheduling.Scheduler
+	// policySchedulers serve the scheduling policies of the spec, scheduler is used when it's not set
+	policySchedulers map[modelv1alpha1.ModelAdapterSchedulingPolicy]scheduling.Scheduler
 	// PodLister is able to list/get pods from a shared informer's cache store
 	PodLister corelisters.PodLister
 	// ServiceLister is able to list/get services from a shared informer's cache store
@@ -249,6 +286,12 @@ type ModelAdapterReconciler struct {
 	// EndpointSliceLister is able to list/get services from a shared informer's cache store
 	EndpointSliceLister discoverylisters.EndpointSliceLister
 	RuntimeConfig       config.RuntimeConfig
+	// metricsProvider reads engine health and latency for the rollout analysis
+	metricsProvider rolloutMetricsProvider
+	// analyzer keeps the latency samples taken at the last rollout step
+	analyzer *rolloutAnalyzer
+	// topology locates the pods to spread the instances across zones
+	topology podTopologyProvider
 }
 
 //+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete
@@ -257,6 +300,7 @@ type ModelAdapterReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=services/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=model.aibrix.ai,resources=modeladapters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=model.aibrix.ai,resources=modeladapters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=model.aibrix.ai,resources=modeladapters/finalizers,verbs=update
@@ -354,11 +398,9 @@ func (r *ModelAdapterReconciler) DoReconcile(ctx context.Context, req ctrl.Reque
 	selectedPod := &corev1.Pod{}
 	existPods := false
 	var err error
-	if instance.Status.Instances != nil && len(instance.Status.Instances) != 0 {
+	for _, selectedPodName := range instance.Status.Instances {
 		// model adapter has already been scheduled to some pods
-		// check the scheduled pod first, verify the mapping is still valid.
-		// TODO: this needs to be changed once we support multiple lora adapters
-		selectedPodName := instance.Status.Instances[0]
+		// check the scheduled pods first, verify the mapping is still valid.
 		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: selectedPodName}, selectedPod); err != nil && apierrors.IsNotFound(err) {
 			klog.ErrorS(err, "Selected pod has been deleted and it should be removed from model adapter instance list", "modelAdapter", klog.KObj(instance))
 			// instance.Status.Instances has been outdated, and we need to clear the pod list
@@ -405,6 +447,8 @@ func (r *ModelAdapterReconciler) DoReconcile(ctx context.Context, req ctrl.Reque
 
 			instance.Status.Phase = modelv1alpha1.ModelAdapterScheduled
 			instance.Status.Instances = append(instance.Status.Instances, selectedPod.Name)
+			r.setInstanceStatus(instance, selectedPod.Name, modelv1alpha1.ModelAdapterInstancePending, InstanceScheduledReason,
+				fmt.Sprintf("ModelAdapter has been scheduled by %s scheduling", instance.Spec.SchedulingPolicy))
 			condition := NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeScheduled), metav1.ConditionTrue,
 				"Scheduled", fmt.Sprintf("ModelAdapter %s has been allocated to pod %s/%s", klog.KObj(instance), selectedPod.GetNamespace(), selectedPod.GetName()))
 			if err := r.updateStatus(ctx, instance, condition); err != nil {
@@ -432,7 +476,39 @@ func (r *ModelAdapterReconciler) DoReconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: defaultRequeueDuration}, err
 	}
 
-	// Step 3: Reconcile Service
+	// Step 3: Load or unload the adapter until it's on the desired number of pods
+	if err := r.reconcileScaleOut(ctx, instance); err != nil {
+		klog.ErrorS(err, "Failed to load ModelAdapter on additional pod", "modelAdapter", klog.KObj(instance))
+		// keep the failure of the pod in the status, it's not an instance yet.
+		if updateErr := r.updateStatus(ctx, instance); updateErr != nil {
+			klog.ErrorS(updateErr, "Failed to update ModelAdapter status", "modelAdapter", klog.KObj(instance))
+		}
+		return ctrl.Result{RequeueAfter: defaultRequeueDuration}, err
+	}
+
+	scaleInRequeue, err := r.reconcileScaleIn(ctx, instance)
+	if err != nil {
+		klog.ErrorS(err, "Failed to unload ModelAdapter from extra pod", "modelAdapter", klog.KObj(instance))
+		return ctrl.Result{RequeueAfter: defaultRequeueDuration}, err
+	}
+
+	// Step 3.1: Move an instance to a better pod if the scheduling policy asks for it
+	if err := r.reconcileRebalance(ctx, instance); err != nil {
+		klog.ErrorS(err, "Failed to rebalance ModelAdapter", "modelAdapter", klog.KObj(instance))
+		if updateErr := r.updateStatus(ctx, instance); updateErr != nil {
+			klog.ErrorS(updateErr, "Failed to update ModelAdapter status", "modelAdapter", klog.KObj(instance))
+		}
+		return ctrl.Result{RequeueAfter: defaultRequeueDuration}, err
+	}
+
+	// Step 4: Roll the artifact in the spec out to the instances
+	rolloutRequeue, err := r.reconcileRollout(ctx, instance)
+	if err != nil {
+		klog.ErrorS(err, "Failed to roll out ModelAdapter artifact", "modelAdapter", klog.KObj(instance))
+		return ctrl.Result{RequeueAfter: defaultRequeueDuration}, err
+	}
+
+	// Step 5: Reconcile Service
 	if ctrlResult, err := r.reconcileService(ctx, instance); err != nil {
 		instance.Status.Phase = modelv1alpha1.ModelAdapterResourceCreated
 		condition := NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeResourceCreated), metav1.ConditionFalse,
@@ -444,7 +520,7 @@ func (r *ModelAdapterReconciler) DoReconcile(ctx context.Context, req ctrl.Reque
 		return ctrlResult, err
 	}
 
-	// Step 4: Reconcile EndpointSlice
+	// Step 6: Reconcile EndpointSlice
 	if ctrlResult, err := r.reconcileEndpointSlice(ctx, instance); err != nil {
 		instance.Status.Phase = modelv1alpha1.ModelAdapterResourceCreated
 		condition := NewCondition(string(modelv1alpha1.ModelAdapterConditionTypeResourceCreated), metav1.ConditionFalse,
@@ -465,7 +541,11 @@ func (r *ModelAdapterReconciler) DoReconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
-	return ctrl.Result{}, nil
+	// reconcile again to scale in once the desired instances raised by the gateway expire.
+	if scaleInRequeue > 0 && (rolloutRequeue == 0 || scaleInRequeue < rolloutRequeue) {
+		return ctrl.Result{RequeueAfter: scaleInRequeue}, nil
+	}
+	return ctrl.Result{RequeueAfter: rolloutRequeue}, nil
 }
 
 func (r *ModelAdapterReconciler) updateStatus(ctx context.Context, instance *modelv1alpha1.ModelAdapter, conditions ...metav1.Condition) error {
@@ -482,11 +562,17 @@ func (r *ModelAdapterReconciler) updateStatus(ctx context.Context, instance *mod
 
 func (r *ModelAdapterReconciler) clearModelAdapterInstanceList(ctx context.Context, instance *modelv1alpha1.ModelAdapter, stalePodName string) error {
 	instance.Status.Instances = RemoveInstanceFromList(instance.Status.Instances, stalePodName)
-	// remove instance means the lora has not targets at this moment.
-	instance.Status.Phase = modelv1alpha1.ModelAdapterPending
+	removeInstanceStatus(instance, stalePodName)
 	condition := NewCondition(string(modelv1alpha1.ModelAdapterFailed), metav1.ConditionTrue,
 		StableInstanceFoundReason,
 		fmt.Sprintf("Pod (%s/%s) is stale or invalid for model adapter (%s/%s), clean up the list", instance.GetNamespace(), stalePodName, instance.GetNamespace(), instance.Name))
+	if len(instance.Status.Instances) != 0 {
+		// other pods are still serving the adapter, no need to reschedule.
+		return r.updateStatus(ctx, instance, condition)
+	}
+
+	// remove all instances means the lora has not targets at this moment.
+	instance.Status.Phase = modelv1alpha1.ModelAdapterPending
 
 	// We also need to update the scheduling and ready status to false
 	// When the pod get migrated, we need to update the status with latest LastTransitionTime.
@@ -533,18 +619,141 @@ func (r *ModelAdapterReconciler) getActivePodsForModelAdapter(ctx context.Contex
 
 // schedulePod picks a valid pod to schedule the model adapter
 func (r *ModelAdapterReconciler) schedulePod(ctx context.Context, instance *modelv1alpha1.ModelAdapter, activePods []corev1.Pod) (*corev1.Pod, error) {
-	// Implement your scheduling logic here to select a Pod based on the instance.Spec.PodSelector
-	// For the sake of example, we will just list the Pods matching the selector and pick the first one
-	return r.scheduler.SelectPod(ctx, instance.Name, activePods)
+	return r.schedulerFor(instance).SelectPod(ctx, instance.Name, activePods)
+}
+
+// schedulerFor returns the scheduler of the scheduling policy in the spec.
+func (r *ModelAdapterReconciler) schedulerFor(instance *modelv1alpha1.ModelAdapter) scheduling.Scheduler {
+	if scheduler, ok := r.policySchedulers[instance.Spec.SchedulingPolicy]; ok {
+		return scheduler
+	}
+	return r.scheduler
+}
+
+// reconcileRebalance moves one instance of the adapter to a better pod when the scheduler supports it, e.g. after
+// new pods joined. The adapter is loaded on the new pod before it's unloaded from the old one, so it keeps serving.
+func (r *ModelAdapterReconciler) reconcileRebalance(ctx context.Context, instance *modelv1alpha1.ModelAdapter) error {
+	rebalancer, ok := r.schedulerFor(instance).(scheduling.Rebalancer)
+	if !ok || len(instance.Status.Instances) == 0 {
+		return nil
+	}
+	// the instances of a progressing rollout serve different artifacts, keep them in place.
+	if rollout := instance.Status.Rollout; rollout != nil && rollout.Phase == modelv1alpha1.ModelAdapterRolloutProgressing {
+		return nil
+	}
+
+	activePods, err := r.getActivePodsForModelAdapter(ctx, instance)
+	if err != nil {
+		return err
+	}
+	var instancePods, candidates []corev1.Pod
+	for _, pod := range activePods {
+		if StringInSlice(instance.Status.Instances, pod.Name) {
+			instancePods = append(instancePods, pod)
+		} else {
+			candidates = append(candidates, pod)
+		}
+	}
+	if len(instancePods) == 0 || len(candidates) == 0 {
+		return nil
+	}
+
+	from, to, err := rebalancer.SelectMove(ctx, instance.Name, instancePods, candidates)
+	if err != nil || from == nil || to == nil {
+		return err
+	}
+	if err := r.reconcileLoadingOnPod(ctx, instance, to.Name); err != nil {
+		return err
+	}
+	instance.Status.Instances = append(instance.Status.Instances, to.Name)
+	if err := r.unloadModelAdapterFromPod(instance, from.Name); err != nil {
+		return err
+	}
+	instance.Status.Instances = RemoveInstanceFromList(instance.Status.Instances, from.Name)
+	removeInstanceStatus(instance, from.Name)
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, "Rebalanced", "ModelAdapter has been moved from pod %s to pod %s by %s scheduling", from.Name, to.Name, instance.Spec.SchedulingPolicy)
+	return nil
 }
 
 func (r *ModelAdapterReconciler) reconcileLoading(ctx context.Context, instance *modelv1alpha1.ModelAdapter) error {
-	if len(instance.Status.Instances) == 0 {
+	for _, podName := range instance.Status.Instances {
+		if err := r.reconcileLoadingOnPod(ctx, instance, podName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileScaleOut loads the adapter on more pods until the desired instances are reached, preferring the zones
+// hosting the fewest instances. The pod is only added to the instance list once the adapter is loaded, so the gateway
+// won't route to it earlier.
+func (r *ModelAdapterReconciler) reconcileScaleOut(ctx context.Context, instance *modelv1alpha1.ModelAdapter) error {
+	desired, _ := getDesiredInstances(instance, time.Now(), desiredInstancesIdleWindow)
+	if len(instance.Status.Instances) == 0 || len(instance.Status.Instances) >= desired {
 		return nil
 	}
 
+	activePods, err := r.getActivePodsForModelAdapter(ctx, instance)
+	if err != nil {
+		return err
+	}
+	pruneInstanceStatuses(instance, activePods)
+	for len(instance.Status.Instances) < desired {
+		var candidates []corev1.Pod
+		for _, pod := range activePods {
+			if !StringInSlice(instance.Status.Instances, pod.Name) {
+				candidates = append(candidates, pod)
+			}
+		}
+		if len(candidates) == 0 {
+			klog.V(4).InfoS("no more pods available to scale out model adapter", "modelAdapter", klog.KObj(instance), "desired", desired, "current", len(instance.Status.Instances))
+			return nil
+		}
+
+		selectedPod, err := r.schedulePod(ctx, instance, r.zoneSpreadCandidates(instance.Status.Instances, candidates))
+		if err != nil {
+			return err
+		}
+		if err := r.reconcileLoadingOnPod(ctx, instance, selectedPod.Name); err != nil {
+			return err
+		}
+
+		instance.Status.Instances = append(instance.Status.Instances, selectedPod.Name)
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "ScaledOut", "ModelAdapter has been loaded on additional pod %s, %d/%d instances", selectedPod.Name, len(instance.Status.Instances), desired)
+	}
+	return nil
+}
+
+// reconcileScaleIn unloads the adapter from the instances beyond the desired ones, starting with the zone
+// hosting the most instances. It returns when the desired instances raised by the gateway expire, 0 if they aren't.
+func (r *ModelAdapterReconciler) reconcileScaleIn(ctx context.Context, instance *modelv1alpha1.ModelAdapter) (time.Duration, error) {
+	desired, expiry := getDesiredInstances(instance, time.Now(), desiredInstancesIdleWindow)
+	// the instances of a progressing rollout serve different artifacts, keep them in place.
+	if rollout := instance.Status.Rollout; rollout != nil && rollout.Phase == modelv1alpha1.ModelAdapterRolloutProgressing {
+		return expiry, nil
+	}
+	for len(instance.Status.Instances) > desired {
+		victim := r.scaleInVictim(instance.Status.Instances)
+		if err := r.unloadModelAdapterFromPod(instance, victim); err != nil {
+			return expiry, err
+		}
+		instance.Status.Instances = RemoveInstanceFromList(instance.Status.Instances, victim)
+		removeInstanceStatus(instance, victim)
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "ScaledIn", "ModelAdapter has been unloaded from pod %s, %d/%d instances", victim, len(instance.Status.Instances), desired)
+	}
+	return expiry, nil
+}
+
+// reconcileLoadingOnPod loads the adapter on the pod unless it's loaded already, and records the outcome in the
+// instance statuses.
+func (r *ModelAdapterReconciler) reconcileLoadingOnPod(ctx context.Context, instance *modelv1alpha1.ModelAdapter, podName string) error {
+	err := r.loadOnPod(ctx, instance, podName)
+	r.recordLoadResult(instance, podName, artifactForPod(instance, podName), err)
+	return err
+}
+
+func (r *ModelAdapterReconciler) loadOnPod(ctx context.Context, instance *modelv1alpha1.ModelAdapter, podName string) error {
 	targetPod := &corev1.Pod{}
-	podName := instance.Status.Instances[0]
 	err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: podName}, targetPod)
 	if err != nil && apierrors.IsNotFound(err) {
 		return fmt.Errorf("pod %s/%s can not be found, skip loading", instance.GetName(), podName)
@@ -557,10 +766,16 @@ func (r *ModelAdapterReconciler) reconcileLoading(ctx context.Context, instance
 		return nil
 	}
 
-	urls := BuildURLs(targetPod.Status.PodIP, r.RuntimeConfig)
-
+	engine, err := engineForPod(targetPod)
+	if err != nil {
+		return err
+	}
+	if !engine.dynamicLoading() {
+		klog.V(4).InfoS("Inference engine loads LoRA adapters with requests, skipping registration", "pod", klog.KObj(targetPod))
+		return nil
+	}
 	// Check if the model is already loaded
-	exists, err := r.modelAdapterExists(urls.ListModelsURL, instance)
+	exists, err := r.modelAdapterExists(ctx, engine, targetPod, instance)
 	if err != nil {
 		return err
 	}
@@ -569,8 +784,12 @@ func (r *ModelAdapterReconciler) reconcileLoading(ctx context.Context, instance
 		return nil
 	}
 
-	// Load the Model adapter
-	err = r.loadModelAdapter(urls.LoadAdapterURL, instance)
+	// Load the Model adapter once its artifact is on the node
+	artifactURL, err := r.resolveArtifact(ctx, instance, targetPod, engine, artifactForPod(instance, podName))
+	if err != nil {
+		return err
+	}
+	err = r.loadModelAdapter(ctx, engine, targetPod, artifactURL, instance)
 	if err != nil {
 		return err
 	}
@@ -579,8 +798,21 @@ func (r *ModelAdapterReconciler) reconcileLoading(ctx context.Context, instance
 }
 
 // Separate method to check if the model already exists
-func (r *ModelAdapterReconciler) modelAdapterExists(url string, instance *modelv1alpha1.ModelAdapter) (bool, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (r *ModelAdapterReconciler) modelAdapterExists(ctx context.Context, engine adapterEngine, pod *corev1.Pod, instance *modelv1alpha1.ModelAdapter) (bool, error) {
+	if sidecar, ok := r.runtimeClient(pod, engine, instance); ok {
+		models, err := sidecar.ListEngineModels(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to get models: %v", err)
+		}
+		for _, model := range models {
+			if model.ID == instance.Name {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", engine.urls(pod, r.RuntimeConfig).ListModelsURL, nil)
 	if err != nil {
 		return false, err
 	}
@@ -629,29 +861,24 @@ func (r *ModelAdapterReconciler) modelAdapterExists(url string, instance *modelv
 }
 
 // Separate method to load the LoRA adapter
-func (r *ModelAdapterReconciler) loadModelAdapter(url string, instance *modelv1alpha1.ModelAdapter) error {
-	artifactURL := instance.Spec.ArtifactURL
-	if strings.HasPrefix(instance.Spec.ArtifactURL, "huggingface://") {
-		var err error
-		artifactURL, err = extractHuggingFacePath(instance.Spec.ArtifactURL)
-		if err != nil {
-			// Handle error, e.g., log it and return
-			klog.ErrorS(err, "Invalid artifact URL", "artifactURL", artifactURL)
-			return err
-		}
+func (r *ModelAdapterReconciler) loadModelAdapter(ctx context.Context, engine adapterEngine, pod *corev1.Pod, artifactURL string, instance *modelv1alpha1.ModelAdapter) error {
+	payload, err := engine.loadPayload(instance.Name, artifactURL)
+	if err != nil {
+		klog.ErrorS(err, "Invalid artifact URL", "artifactURL", artifactURL)
+		return err
 	}
-	// TODO: extend to other artifacts
-
-	payload := map[string]string{
-		"lora_name": instance.Name,
-		"lora_path": artifactURL,
+	if sidecar, ok := r.runtimeClient(pod, engine, instance); ok {
+		if err := sidecar.LoadLoraAdapter(ctx, &runtimeapi.LoadLoraAdapterRequest{LoraName: payload["lora_name"], LoraPath: payload["lora_path"]}); err != nil {
+			return fmt.Errorf("failed to load LoRA adapter: %v", err)
+		}
+		return nil
 	}
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", engine.urls(pod, r.RuntimeConfig).LoadAdapterURL, bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return err
 	}
@@ -688,9 +915,15 @@ func (r *ModelAdapterReconciler) unloadModelAdapter(instance *modelv1alpha1.Mode
 		return nil
 	}
 
-	// TODO:(jiaxin.shan) Support multiple instances
+	for _, podName := range instance.Status.Instances {
+		if err := r.unloadModelAdapterFromPod(instance, podName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	podName := instance.Status.Instances[0]
+func (r *ModelAdapterReconciler) unloadModelAdapterFromPod(instance *modelv1alpha1.ModelAdapter, podName string) error {
 	targetPod := &corev1.Pod{}
 	if err := r.Get(context.TODO(), types.NamespacedName{
 		Namespace: instance.Namespace,
@@ -704,15 +937,25 @@ func (r *ModelAdapterReconciler) unloadModelAdapter(instance *modelv1alpha1.Mode
 		return err
 	}
 
-	payload := map[string]string{
-		"lora_name": instance.Name,
+	engine, err := engineForPod(targetPod)
+	if err != nil {
+		return err
 	}
-	payloadBytes, err := json.Marshal(payload)
+	if !engine.dynamicLoading() {
+		return nil
+	}
+	if sidecar, ok := r.runtimeClient(targetPod, engine, instance); ok {
+		if err := sidecar.UnloadLoraAdapter(context.TODO(), &runtimeapi.UnloadLoraAdapterRequest{LoraName: instance.Name}); err != nil {
+			klog.Warningf("failed to unload LoRA adapter: %v", err)
+		}
+		return nil
+	}
+	payloadBytes, err := json.Marshal(engine.unloadPayload(instance.Name))
 	if err != nil {
 		return err
 	}
 
-	urls := BuildURLs(targetPod.Status.PodIP, r.RuntimeConfig)
+	urls := engine.urls(targetPod, r.RuntimeConfig)
 	req, err := http.NewRequest("POST", urls.UnloadAdapterURL, bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return err
@@ -800,47 +1043,38 @@ func (r *ModelAdapterReconciler) reconcileEndpointSlice(ctx context.Context, ins
 		return ctrl.Result{}, nil
 	}
 
-	// TODO: do necessary refactor to support multiple lora instance
-	podName := instance.Status.Instances[0]
-	pod := &corev1.Pod{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: instance.Namespace, Name: podName}, pod); err != nil {
-		if !apierrors.IsNotFound(err) {
-			klog.Warning("Error getting Pod from lora instance list", err)
-			return ctrl.Result{}, err
-		}
-
-		klog.Warningf("pod %s/%s has been deleted, let's clean up the endpoint slice", instance.GetNamespace(), podName)
-		// TODO: do necessary refactor to support multiple lora instance
-		// the tricky thing is we do not know the pod map to pod ip mapping. instance only save pods, endpointslice only save ips
-		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, found); err != nil {
-			if apierrors.IsNotFound(err) {
-				// this should barely happen, in this case, there's no need to move forward
-				klog.Warningf("Endpoint slice %s doesn't exist", klog.KObj(instance))
-				return ctrl.Result{}, nil
+	// collect all the pods hosting the model adapter, deleted or terminating pods are dropped from the endpoints.
+	var pods []*corev1.Pod
+	for _, podName := range instance.Status.Instances {
+		pod := &corev1.Pod{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: podName}, pod); err != nil {
+			if !apierrors.IsNotFound(err) {
+				klog.Warning("Error getting Pod from lora instance list", err)
+				return ctrl.Result{}, err
 			}
-			return ctrl.Result{}, err
+			klog.Warningf("pod %s/%s has been deleted, let's clean up the endpoint slice", instance.GetNamespace(), podName)
+			continue
 		}
-
-		// reset endpoint slice
-		found.Endpoints = []discoveryv1.Endpoint{}
-		if err := r.Update(ctx, found); err != nil {
-			klog.ErrorS(err, "Failed to update EndpointSlice after clearing endpoints", "EndpointSlice", found.Name)
-			return ctrl.Result{}, err
+		if pod.DeletionTimestamp != nil {
+			continue
 		}
-
-		return ctrl.Result{}, nil
+		pods = append(pods, pod)
 	}
 
-	// pod object fetched, let's check existence of endpoint slice
 	err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, found)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			klog.ErrorS(err, "Failed to get EndpointSlice")
 			return ctrl.Result{}, err
 		}
+		if len(pods) == 0 {
+			// this should barely happen, in this case, there's no need to move forward
+			klog.Warningf("Endpoint slice %s doesn't exist and no pods are available", klog.KObj(instance))
+			return ctrl.Result{}, nil
+		}
 
 		// EndpointSlice does not exist, create it
-		eps := buildModelAdapterEndpointSlice(instance, pod)
+		eps := buildModelAdapterEndpointSlice(instance, pods...)
 		// Set the owner reference
 		if err := ctrl.SetControllerReference(instance, eps, r.Scheme); err != nil {
 			klog.Error(err, "Failed to set controller reference to modelAdapter")
@@ -860,80 +1094,24 @@ func (r *ModelAdapterReconciler) reconcileEndpointSlice(ctx context.Context, ins
 			return ctrl.Result{}, err
 		}
 		instance.Status.Phase = modelv1alpha1.ModelAdapterRunning
-	} else {
-		// Existing EndpointSlice Found. Check if the Pod IP is already in the EndpointSlice
-		podIP := pod.Status.PodIP
-		alreadyExists := false
-		for _, endpoint := range found.Endpoints {
-			for _, address := range endpoint.Addresses {
-				if address == podIP {
-					alreadyExists = true
-					break
-				}
-			}
-			if alreadyExists {
-				break
-			}
-		}
-
-		// Append the Pod IP to the EndpointSlice if it doesn't exist
-		if !alreadyExists {
-			// TODO: come back when we start to support multi-instance.
-			//found.Endpoints = append(found.Endpoints, discoveryv1.Endpoint{
-			//	Addresses: []string{podIP},
-			//})
-
-			// override the endpoint with only one pod id.
-			// TODO: We need to refactor the logic once we start to support multi instances later
-			found.Endpoints = []discoveryv1.Endpoint{
-				{
-					Addresses: []string{podIP},
-				},
-			}
+		return ctrl.Result{}, nil
+	}
 
-			if err := r.Update(ctx, found); err != nil {
-				klog.ErrorS(err, "Failed to update EndpointSlice", "EndpointSlice", found.Name)
-				return ctrl.Result{}, err
-			}
-			instance.Status.Phase = modelv1alpha1.ModelAdapterRunning
-			klog.InfoS("Successfully updated EndpointSlice", "EndpointSlice", found.Name)
-		} else {
-			// pod has been deleted, and we should remove the pod name from the list
-			if pod.DeletionTimestamp != nil {
-				var updatedEndpoints []discoveryv1.Endpoint
-				podIP := pod.Status.PodIP
-
-				for _, endpoint := range found.Endpoints {
-					shouldRemove := false
-					var newAddresses []string
-
-					for _, address := range endpoint.Addresses {
-						if address == podIP {
-							shouldRemove = true
-						} else {
-							newAddresses = append(newAddresses, address)
-						}
-					}
-
-					if !shouldRemove || len(newAddresses) > 0 {
-						endpoint.Addresses = newAddresses
-						updatedEndpoints = append(updatedEndpoints, endpoint)
-					}
-				}
-
-				found.Endpoints = updatedEndpoints
-				if err := r.Update(ctx, found); err != nil {
-					klog.ErrorS(err, "Failed to update EndpointSlice after removing PodIP", "EndpointSlice", found.Name)
-					return ctrl.Result{}, err
-				}
-
-				instance.Status.Phase = modelv1alpha1.ModelAdapterFailed
-				klog.InfoS("Successfully removed Pod IP from EndpointSlice", "PodIP", podIP, "EndpointSlice", found.Name)
-			} else {
-				klog.V(4).InfoS("Pod IP already exists in EndpointSlice", "PodName", pod.Name, "PodIP", podIP)
-				instance.Status.Phase = modelv1alpha1.ModelAdapterRunning
-			}
+	// Existing EndpointSlice Found. Sync the endpoints with the pods hosting the adapter.
+	endpoints := buildModelAdapterEndpoints(pods)
+	if !apiequality.Semantic.DeepEqual(found.Endpoints, endpoints) {
+		found.Endpoints = endpoints
+		if err := r.Update(ctx, found); err != nil {
+			klog.ErrorS(err, "Failed to update EndpointSlice", "EndpointSlice", found.Name)
+			return ctrl.Result{}, err
 		}
+		klog.InfoS("Successfully updated EndpointSlice", "EndpointSlice", found.Name, "endpoints", len(endpoints))
+	}
+
+	if len(pods) == 0 {
+		instance.Status.Phase = modelv1alpha1.ModelAdapterFailed
+	} else {
+		instance.Status.Phase = modelv1alpha1.ModelAdapterRunning
 	}
 
 	return ctrl.Result{}, nil
@@ -944,6 +1122,12 @@ func (r *ModelAdapterReconciler) inconsistentModelAdapterStatus(oldStatus, newSt
 	if oldStatus.Phase != newStatus.Phase || !equalStringSlices(oldStatus.Instances, newStatus.Instances) {
 		return true
 	}
+	if oldStatus.ArtifactURL != newStatus.ArtifactURL || !apiequality.Semantic.DeepEqual(oldStatus.Rollout, newStatus.Rollout) {
+		return true
+	}
+	if !apiequality.Semantic.DeepEqual(oldStatus.InstanceStatuses, newStatus.InstanceStatuses) {
+		return true
+	}
 
 	return false
 }
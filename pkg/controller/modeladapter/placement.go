@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+// podTopologyProvider locates the pods, so the adapter instances can be spread across zones.
+type podTopologyProvider interface {
+	GetPodTopology(podName string) (cache.Topology, bool)
+}
+
+// podZone returns the zone of the pod, empty if unknown.
+func (r *ModelAdapterReconciler) podZone(podName string) string {
+	if r.topology == nil {
+		return ""
+	}
+	topology, _ := r.topology.GetPodTopology(podName)
+	return topology.Zone
+}
+
+// instanceZones counts the instances of the adapter per zone.
+func (r *ModelAdapterReconciler) instanceZones(instances []string) map[string]int {
+	zones := map[string]int{}
+	for _, podName := range instances {
+		zones[r.podZone(podName)]++
+	}
+	return zones
+}
+
+// zoneSpreadCandidates keeps the candidates in the zones hosting the fewest instances, so a zone outage
+// leaves the adapter loaded elsewhere. Pods in unknown zones are only kept if no zone is known.
+func (r *ModelAdapterReconciler) zoneSpreadCandidates(instances []string, candidates []corev1.Pod) []corev1.Pod {
+	zones := r.instanceZones(instances)
+	minCount := -1
+	var spread []corev1.Pod
+	for _, pod := range candidates {
+		zone := r.podZone(pod.Name)
+		if zone == "" {
+			continue
+		}
+		count := zones[zone]
+		if minCount == -1 || count < minCount {
+			minCount = count
+			spread = spread[:0]
+		}
+		if count == minCount {
+			spread = append(spread, pod)
+		}
+	}
+	if len(spread) == 0 {
+		return candidates
+	}
+	return spread
+}
+
+// scaleInVictim returns the instance to unload first, the latest one in the zone hosting the most instances.
+func (r *ModelAdapterReconciler) scaleInVictim(instances []string) string {
+	zones := r.instanceZones(instances)
+	victim := ""
+	for i := len(instances) - 1; i >= 0; i-- {
+		if victim == "" || zones[r.podZone(instances[i])] > zones[r.podZone(victim)] {
+			victim = instances[i]
+		}
+	}
+	return victim
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+type fakeTopology map[string]string
+
+func (f fakeTopology) GetPodTopology(podName string) (cache.Topology, bool) {
+	zone, ok := f[podName]
+	return cache.Topology{Zone: zone}, ok
+}
+
+func podNames(pods []corev1.Pod) []string {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func candidatePods(names ...string) []corev1.Pod {
+	pods := make([]corev1.Pod, 0, len(names))
+	for _, name := range names {
+		pods = append(pods, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	return pods
+}
+
+func TestZoneSpreadCandidates(t *testing.T) {
+	r := &ModelAdapterReconciler{topology: fakeTopology{
+		"pod-a1": "zone-a", "pod-a2": "zone-a", "pod-b1": "zone-b", "pod-b2": "zone-b", "pod-c1": "zone-c",
+	}}
+
+	candidates := r.zoneSpreadCandidates([]string{"pod-a1"}, candidatePods("pod-a2", "pod-b1", "pod-c1"))
+	assert.Equal(t, []string{"pod-b1", "pod-c1"}, podNames(candidates))
+
+	candidates = r.zoneSpreadCandidates([]string{"pod-a1", "pod-b1", "pod-c1"}, candidatePods("pod-a2", "pod-b2"))
+	assert.Equal(t, []string{"pod-a2", "pod-b2"}, podNames(candidates))
+
+	// pods in unknown zones are used when no zone is known.
+	candidates = r.zoneSpreadCandidates([]string{"pod-a1"}, candidatePods("pod-x", "pod-y"))
+	assert.Equal(t, []string{"pod-x", "pod-y"}, podNames(candidates))
+
+	r.topology = nil
+	candidates = r.zoneSpreadCandidates([]string{"pod-a1"}, candidatePods("pod-a2", "pod-b1"))
+	assert.Equal(t, []string{"pod-a2", "pod-b1"}, podNames(candidates))
+}
+
+func TestScaleInVictim(t *testing.T) {
+	r := &ModelAdapterReconciler{topology: fakeTopology{
+		"pod-a1": "zone-a", "pod-a2": "zone-a", "pod-b1": "zone-b",
+	}}
+
+	assert.Equal(t, "pod-a2", r.scaleInVictim([]string{"pod-a1", "pod-b1", "pod-a2"}))
+	assert.Equal(t, "pod-b1", r.scaleInVictim([]string{"pod-a1", "pod-b1"}))
+}
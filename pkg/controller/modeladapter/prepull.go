@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/runtimeapi"
+)
+
+// errArtifactDownloading is returned while the runtime sidecar pre-pulls the artifact, loading is retried later.
+var errArtifactDownloading = errors.New("artifact is being downloaded by the runtime sidecar")
+
+// prePullSchemes are the artifacts engines can't load by themselves, they are downloaded to the node by the runtime sidecar.
+var prePullSchemes = []string{"s3://", "gcs://", "oci://"}
+
+func needsPrePull(artifactURL string) bool {
+	for _, scheme := range prePullSchemes {
+		if strings.HasPrefix(artifactURL, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// artifactModelName names the local copy of the artifact. The artifact URL is part of it, so a new artifact
+// of the adapter is never served from the copy of the previous one.
+func artifactModelName(instance *modelv1alpha1.ModelAdapter, artifactURL string) string {
+	sum := sha256.Sum256([]byte(artifactURL + instance.Spec.ArtifactChecksum))
+	return fmt.Sprintf("%s-%s", instance.Name, hex.EncodeToString(sum[:])[:10])
+}
+
+// downloadExtraConfig passes the credentials secret and the checksum to the runtime sidecar downloader.
+// Secret keys are used as downloader options as-is, e.g. ak, sk, endpoint, region, username or password.
+func (r *ModelAdapterReconciler) downloadExtraConfig(ctx context.Context, instance *modelv1alpha1.ModelAdapter) (map[string]string, error) {
+	extraConfig := map[string]string{}
+	if ref := instance.Spec.CredentialsSecretRef; ref != nil {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: ref.Name}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get credentials secret %s/%s: %v", instance.Namespace, ref.Name, err)
+		}
+		for key, value := range secret.Data {
+			extraConfig[key] = string(value)
+		}
+	}
+	if instance.Spec.ArtifactChecksum != "" {
+		extraConfig["checksum"] = instance.Spec.ArtifactChecksum
+	}
+	return extraConfig, nil
+}
+
+// resolveArtifact returns the path the engine on the pod loads the artifact from. Remote artifacts are
+// pre-pulled to the node by the runtime sidecar first, errArtifactDownloading is returned until they're ready.
+func (r *ModelAdapterReconciler) resolveArtifact(ctx context.Context, instance *modelv1alpha1.ModelAdapter, pod *corev1.Pod, engine adapterEngine, artifactURL string) (string, error) {
+	if !needsPrePull(artifactURL) {
+		return artifactURL, nil
+	}
+	sidecar, ok := r.runtimeClient(pod, engine, instance)
+	if !ok {
+		return "", fmt.Errorf("artifact %s requires the runtime sidecar to download it", artifactURL)
+	}
+
+	extraConfig, err := r.downloadExtraConfig(ctx, instance)
+	if err != nil {
+		return "", err
+	}
+	card, err := sidecar.DownloadModel(ctx, &runtimeapi.DownloadModelRequest{
+		ModelURI:            artifactURL,
+		ModelName:           artifactModelName(instance, artifactURL),
+		DownloadExtraConfig: extraConfig,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to download artifact %s: %v", artifactURL, err)
+	}
+	if card.ModelStatus != runtimeapi.ModelStatusDownloaded {
+		klog.V(4).InfoS("Waiting for the runtime sidecar to download the artifact", "modelAdapter", klog.KObj(instance), "pod", klog.KObj(pod), "status", card.ModelStatus)
+		return "", errArtifactDownloading
+	}
+	return card.ModelRootPath, nil
+}
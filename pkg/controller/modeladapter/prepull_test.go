@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+func TestNeedsPrePull(t *testing.T) {
+	assert.True(t, needsPrePull("s3://bucket/adapter"))
+	assert.True(t, needsPrePull("gcs://bucket/adapter"))
+	assert.True(t, needsPrePull("oci://ghcr.io/org/adapter:v1"))
+	assert.False(t, needsPrePull("huggingface://org/adapter"))
+	assert.False(t, needsPrePull("/models/adapter"))
+}
+
+func TestArtifactModelName(t *testing.T) {
+	instance := &modelv1alpha1.ModelAdapter{ObjectMeta: metav1.ObjectMeta{Name: "lora"}}
+	v1 := artifactModelName(instance, "s3://bucket/v1")
+	assert.Equal(t, v1, artifactModelName(instance, "s3://bucket/v1"))
+	assert.NotEqual(t, v1, artifactModelName(instance, "s3://bucket/v2"))
+	assert.Regexp(t, "^lora-[a-f0-9]{10}$", v1)
+}
+
+func TestDownloadExtraConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"ak": []byte("access"), "sk": []byte("secret")},
+	}
+	r := &ModelAdapterReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()}
+	instance := &modelv1alpha1.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{Name: "lora", Namespace: "default"},
+		Spec: modelv1alpha1.ModelAdapterSpec{
+			ArtifactURL:          "s3://bucket/adapter",
+			ArtifactChecksum:     "sha256:0123",
+			CredentialsSecretRef: &corev1.LocalObjectReference{Name: "s3-credentials"},
+		},
+	}
+
+	extraConfig, err := r.downloadExtraConfig(context.TODO(), instance)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"ak": "access", "sk": "secret", "checksum": "sha256:0123"}, extraConfig)
+
+	instance.Spec.CredentialsSecretRef.Name = "missing"
+	_, err = r.downloadExtraConfig(context.TODO(), instance)
+	assert.Error(t, err)
+}
+
+func TestResolveArtifact(t *testing.T) {
+	instance := &modelv1alpha1.ModelAdapter{ObjectMeta: metav1.ObjectMeta{Name: "lora", Namespace: "default"}}
+	pod := enginePod("", "10.0.0.1", "")
+
+	r := &ModelAdapterReconciler{RuntimeConfig: config.RuntimeConfig{}}
+	path, err := r.resolveArtifact(context.TODO(), instance, pod, vllmEngine{}, "huggingface://org/adapter")
+	assert.NoError(t, err)
+	assert.Equal(t, "huggingface://org/adapter", path)
+
+	_, err = r.resolveArtifact(context.TODO(), instance, pod, vllmEngine{}, "s3://bucket/adapter")
+	assert.ErrorContains(t, err, "requires the runtime sidecar")
+
+	r.RuntimeConfig.EnableRuntimeSidecar = true
+	_, err = r.resolveArtifact(context.TODO(), instance, pod, sglangEngine{}, "oci://ghcr.io/org/adapter:v1")
+	assert.ErrorContains(t, err, "requires the runtime sidecar")
+}
@@ -25,16 +25,12 @@ import (
 	"k8s.io/utils/ptr"
 )
 
-func buildModelAdapterEndpointSlice(instance *modelv1alpha1.ModelAdapter, pod *corev1.Pod) *discoveryv1.EndpointSlice {
+func buildModelAdapterEndpointSlice(instance *modelv1alpha1.ModelAdapter, pods ...*corev1.Pod) *discoveryv1.EndpointSlice {
 	serviceLabels := map[string]string{
 		"kubernetes.io/service-name": instance.Name,
 	}
 
-	addresses := []discoveryv1.Endpoint{
-		{
-			Addresses: []string{pod.Status.PodIP},
-		},
-	}
+	addresses := buildModelAdapterEndpoints(pods)
 
 	ports := []discoveryv1.EndpointPort{
 		{
@@ -60,6 +56,17 @@ func buildModelAdapterEndpointSlice(instance *modelv1alpha1.ModelAdapter, pod *c
 	}
 }
 
+// buildModelAdapterEndpoints returns one endpoint per pod hosting the model adapter.
+func buildModelAdapterEndpoints(pods []*corev1.Pod) []discoveryv1.Endpoint {
+	endpoints := make([]discoveryv1.Endpoint, 0, len(pods))
+	for _, pod := range pods {
+		endpoints = append(endpoints, discoveryv1.Endpoint{
+			Addresses: []string{pod.Status.PodIP},
+		})
+	}
+	return endpoints
+}
+
 func buildModelAdapterService(instance *modelv1alpha1.ModelAdapter) *corev1.Service {
 	labels := map[string]string{
 		"adapter.model.aibrix.ai/name": instance.Name,
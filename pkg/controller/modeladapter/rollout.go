@@ -0,0 +1,382 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultCanaryPercent           = 20
+	defaultStepPercent             = 25
+	defaultAnalysisIntervalSeconds = 60
+	defaultMaxLatencyRatio         = 1.5
+)
+
+// rolloutMetricsProvider is the part of the cache the rollout analysis reads.
+type rolloutMetricsProvider interface {
+	IsEngineReady(podName string) bool
+	GetPodModelMetric(podName, modelName string, metricName string) (metrics.MetricValue, error)
+}
+
+// latencySample is the cumulative request latency histogram of a pod.
+type latencySample struct {
+	sum   float64
+	count float64
+}
+
+// rolloutAnalyzer keeps the latency samples taken at the last rollout step, so the analysis
+// only looks at requests served since then.
+type rolloutAnalyzer struct {
+	mu        sync.Mutex
+	baselines map[types.NamespacedName]map[string]latencySample
+}
+
+func newRolloutAnalyzer() *rolloutAnalyzer {
+	return &rolloutAnalyzer{baselines: map[types.NamespacedName]map[string]latencySample{}}
+}
+
+func (a *rolloutAnalyzer) snapshot(key types.NamespacedName, samples map[string]latencySample) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.baselines[key] = samples
+}
+
+func (a *rolloutAnalyzer) baseline(key types.NamespacedName) map[string]latencySample {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.baselines[key]
+}
+
+func (a *rolloutAnalyzer) delete(key types.NamespacedName) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.baselines, key)
+}
+
+// parseRolloutThreshold parses a threshold of the rollout strategy, empty value falls back to the default.
+func parseRolloutThreshold(value string, defaultValue float64) (float64, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if threshold < 0 {
+		return 0, fmt.Errorf("%s must not be negative", value)
+	}
+	return threshold, nil
+}
+
+// rolloutStepSize returns how many instances a step of percent covers, at least one.
+func rolloutStepSize(instances int, percent int32) int {
+	size := int(math.Ceil(float64(instances) * float64(percent) / 100))
+	if size < 1 {
+		size = 1
+	}
+	if size > instances {
+		size = instances
+	}
+	return size
+}
+
+// artifactForPod returns the artifact the pod should serve. Pods updated by a progressing rollout serve
+// the new artifact, others keep the stable one.
+func artifactForPod(instance *modelv1alpha1.ModelAdapter, podName string) string {
+	stable := instance.Status.ArtifactURL
+	if stable == "" {
+		return instance.Spec.ArtifactURL
+	}
+	rollout := instance.Status.Rollout
+	if rollout != nil && rollout.Phase == modelv1alpha1.ModelAdapterRolloutProgressing && StringInSlice(rollout.UpdatedInstances, podName) {
+		return rollout.ArtifactURL
+	}
+	return stable
+}
+
+// analyzeRollout compares the instances serving the new artifact with the other ones.
+// The rollout fails when too many updated engines are unhealthy or their mean latency grows beyond the ratio.
+func analyzeRollout(instance *modelv1alpha1.ModelAdapter, provider rolloutMetricsProvider, samples, baseline map[string]latencySample) (bool, string) {
+	strategy := instance.Spec.RolloutStrategy
+	rollout := instance.Status.Rollout
+	maxErrorRate, _ := parseRolloutThreshold(strategy.MaxErrorRate, 0)
+	maxLatencyRatio, _ := parseRolloutThreshold(strategy.MaxLatencyRatio, defaultMaxLatencyRatio)
+
+	if len(rollout.UpdatedInstances) == 0 {
+		return true, "no updated instances to analyze"
+	}
+
+	failed := 0
+	for _, podName := range rollout.UpdatedInstances {
+		if provider != nil && !provider.IsEngineReady(podName) {
+			failed++
+		}
+	}
+	errorRate := float64(failed) / float64(len(rollout.UpdatedInstances))
+	if errorRate > maxErrorRate {
+		return false, fmt.Sprintf("error rate %.2f exceeds %.2f", errorRate, maxErrorRate)
+	}
+
+	var canary, stable latencySample
+	for _, podName := range instance.Status.Instances {
+		sample, ok := samples[podName]
+		if !ok {
+			continue
+		}
+		if base, ok := baseline[podName]; ok && sample.count >= base.count {
+			sample.sum -= base.sum
+			sample.count -= base.count
+		}
+		if StringInSlice(rollout.UpdatedInstances, podName) {
+			canary.sum += sample.sum
+			canary.count += sample.count
+		} else {
+			stable.sum += sample.sum
+			stable.count += sample.count
+		}
+	}
+	// latency can only be compared when both sides served requests since the last step.
+	if canary.count == 0 || stable.count == 0 || stable.sum == 0 {
+		return true, fmt.Sprintf("error rate %.2f", errorRate)
+	}
+	ratio := (canary.sum / canary.count) / (stable.sum / stable.count)
+	if ratio > maxLatencyRatio {
+		return false, fmt.Sprintf("latency ratio %.2f exceeds %.2f", ratio, maxLatencyRatio)
+	}
+	return true, fmt.Sprintf("error rate %.2f, latency ratio %.2f", errorRate, ratio)
+}
+
+// latencySamples reads the request latency histogram of every instance from the cache.
+// The adapter's own series is preferred, falling back to the base model series of the pod.
+func (r *ModelAdapterReconciler) latencySamples(ctx context.Context, instance *modelv1alpha1.ModelAdapter) map[string]latencySample {
+	samples := map[string]latencySample{}
+	if r.metricsProvider == nil {
+		return samples
+	}
+	for _, podName := range instance.Status.Instances {
+		modelNames := []string{instance.Name}
+		pod := &corev1.Pod{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: podName}, pod); err == nil {
+			if baseModel, ok := pod.Labels[ModelIdentifierKey]; ok {
+				modelNames = append(modelNames, baseModel)
+			}
+		}
+		for _, modelName := range modelNames {
+			value, err := r.metricsProvider.GetPodModelMetric(podName, modelName, metrics.E2ERequestLatencySeconds)
+			if err != nil || value.GetHistogramValue() == nil {
+				continue
+			}
+			histogram := value.GetHistogramValue()
+			samples[podName] = latencySample{sum: histogram.Sum, count: histogram.Count}
+			break
+		}
+	}
+	return samples
+}
+
+// switchArtifactOnPod reloads the adapter on the pod from the given artifact. The artifact is pulled
+// before the current adapter is unloaded, so the pod keeps serving while it downloads.
+func (r *ModelAdapterReconciler) switchArtifactOnPod(ctx context.Context, instance *modelv1alpha1.ModelAdapter, podName, artifactURL string) error {
+	err := r.loadArtifactOnPod(ctx, instance, podName, artifactURL)
+	r.recordLoadResult(instance, podName, artifactURL, err)
+	return err
+}
+
+func (r *ModelAdapterReconciler) loadArtifactOnPod(ctx context.Context, instance *modelv1alpha1.ModelAdapter, podName, artifactURL string) error {
+	targetPod := &corev1.Pod{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: podName}, targetPod); err != nil {
+		return err
+	}
+	engine, err := engineForPod(targetPod)
+	if err != nil || !engine.dynamicLoading() {
+		return err
+	}
+	path, err := r.resolveArtifact(ctx, instance, targetPod, engine, artifactURL)
+	if err != nil {
+		return err
+	}
+	if err := r.unloadModelAdapterFromPod(instance, podName); err != nil {
+		return err
+	}
+	return r.loadModelAdapter(ctx, engine, targetPod, path, instance)
+}
+
+// revertRollout switches the updated instances of the rollout back to the stable artifact.
+func (r *ModelAdapterReconciler) revertRollout(ctx context.Context, instance *modelv1alpha1.ModelAdapter) error {
+	rollout := instance.Status.Rollout
+	for _, podName := range rollout.UpdatedInstances {
+		if err := r.switchArtifactOnPod(ctx, instance, podName, instance.Status.ArtifactURL); err != nil {
+			return err
+		}
+	}
+	rollout.UpdatedInstances = nil
+	return nil
+}
+
+// stepRollout loads the new artifact on up to size more instances.
+func (r *ModelAdapterReconciler) stepRollout(ctx context.Context, instance *modelv1alpha1.ModelAdapter, size int) error {
+	rollout := instance.Status.Rollout
+	for _, podName := range instance.Status.Instances {
+		if size == 0 {
+			break
+		}
+		if StringInSlice(rollout.UpdatedInstances, podName) {
+			continue
+		}
+		if err := r.switchArtifactOnPod(ctx, instance, podName, rollout.ArtifactURL); err != nil {
+			return err
+		}
+		rollout.UpdatedInstances = append(rollout.UpdatedInstances, podName)
+		size--
+	}
+	now := metav1.Now()
+	rollout.LastStepTime = &now
+	r.analyzer.snapshot(types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, r.latencySamples(ctx, instance))
+	return nil
+}
+
+// reconcileRollout moves the instances from the stable artifact to the one in the spec. Without a rollout strategy
+// all instances switch at once, otherwise a canary share switches first and the rest follows step by step as long
+// as the analysis passes. A failed analysis rolls the updated instances back and keeps the stable artifact until
+// the spec changes again. It returns when the rollout should be analyzed next.
+func (r *ModelAdapterReconciler) reconcileRollout(ctx context.Context, instance *modelv1alpha1.ModelAdapter) (time.Duration, error) {
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}
+	desired := instance.Spec.ArtifactURL
+	stable := instance.Status.ArtifactURL
+	if stable == "" {
+		instance.Status.ArtifactURL = desired
+		return 0, nil
+	}
+	if len(instance.Status.Instances) == 0 {
+		return 0, nil
+	}
+
+	rollout := instance.Status.Rollout
+	progressing := rollout != nil && rollout.Phase == modelv1alpha1.ModelAdapterRolloutProgressing
+	if progressing && rollout.ArtifactURL != desired {
+		// the spec moved on during the rollout, the updated instances go back to stable before starting over.
+		if err := r.revertRollout(ctx, instance); err != nil {
+			return 0, err
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "RolloutAborted", "Rollout of %s is aborted as the artifact changed", rollout.ArtifactURL)
+		rollout.Phase = modelv1alpha1.ModelAdapterRolloutRolledBack
+		rollout.Message = "artifact changed during rollout"
+		r.analyzer.delete(key)
+		progressing = false
+	}
+	if stable == desired {
+		return 0, nil
+	}
+	if rollout != nil && rollout.ArtifactURL == desired && rollout.Phase == modelv1alpha1.ModelAdapterRolloutRolledBack {
+		return 0, nil
+	}
+
+	strategy := instance.Spec.RolloutStrategy
+	if strategy == nil {
+		for _, podName := range instance.Status.Instances {
+			if err := r.switchArtifactOnPod(ctx, instance, podName, desired); err != nil {
+				return 0, err
+			}
+		}
+		instance.Status.ArtifactURL = desired
+		instance.Status.Rollout = nil
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "ArtifactUpdated", "ModelAdapter artifact has been updated to %s", desired)
+		return 0, nil
+	}
+
+	interval := time.Duration(defaultAnalysisIntervalSeconds) * time.Second
+	if strategy.AnalysisIntervalSeconds > 0 {
+		interval = time.Duration(strategy.AnalysisIntervalSeconds) * time.Second
+	}
+
+	if !progressing {
+		canaryPercent := strategy.CanaryPercent
+		if canaryPercent <= 0 {
+			canaryPercent = defaultCanaryPercent
+		}
+		instance.Status.Rollout = &modelv1alpha1.ModelAdapterRolloutStatus{
+			Phase:       modelv1alpha1.ModelAdapterRolloutProgressing,
+			ArtifactURL: desired,
+		}
+		size := rolloutStepSize(len(instance.Status.Instances), canaryPercent)
+		if err := r.stepRollout(ctx, instance, size); err != nil {
+			return 0, err
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "RolloutStarted", "Canary of %s has been loaded on %d/%d instances", desired, size, len(instance.Status.Instances))
+		return interval, nil
+	}
+
+	// instances may have been removed since the last step.
+	var updated []string
+	for _, podName := range rollout.UpdatedInstances {
+		if StringInSlice(instance.Status.Instances, podName) {
+			updated = append(updated, podName)
+		}
+	}
+	rollout.UpdatedInstances = updated
+
+	if rollout.LastStepTime != nil {
+		if elapsed := time.Since(rollout.LastStepTime.Time); elapsed < interval {
+			return interval - elapsed, nil
+		}
+	}
+
+	ok, message := analyzeRollout(instance, r.metricsProvider, r.latencySamples(ctx, instance), r.analyzer.baseline(key))
+	rollout.Message = message
+	if !ok {
+		if err := r.revertRollout(ctx, instance); err != nil {
+			return 0, err
+		}
+		rollout.Phase = modelv1alpha1.ModelAdapterRolloutRolledBack
+		r.analyzer.delete(key)
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "RolledBack", "Rollout of %s has been rolled back: %s", desired, message)
+		klog.InfoS("ModelAdapter rollout rolled back", "modelAdapter", klog.KObj(instance), "artifactURL", desired, "reason", message)
+		return 0, nil
+	}
+
+	if len(rollout.UpdatedInstances) >= len(instance.Status.Instances) {
+		rollout.Phase = modelv1alpha1.ModelAdapterRolloutCompleted
+		rollout.UpdatedInstances = nil
+		instance.Status.ArtifactURL = desired
+		r.analyzer.delete(key)
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "RolloutCompleted", "ModelAdapter artifact has been updated to %s", desired)
+		return 0, nil
+	}
+
+	stepPercent := strategy.StepPercent
+	if stepPercent <= 0 {
+		stepPercent = defaultStepPercent
+	}
+	if err := r.stepRollout(ctx, instance, rolloutStepSize(len(instance.Status.Instances), stepPercent)); err != nil {
+		return 0, err
+	}
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, "RolloutProgressed", "%s has been loaded on %d/%d instances", desired, len(rollout.UpdatedInstances), len(instance.Status.Instances))
+	return interval, nil
+}
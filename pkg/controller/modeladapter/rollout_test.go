@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modeladapter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+type fakeRolloutMetrics struct {
+	notReady  map[string]bool
+	latencies map[string]latencySample
+}
+
+func (f *fakeRolloutMetrics) IsEngineReady(podName string) bool {
+	return !f.notReady[podName]
+}
+
+func (f *fakeRolloutMetrics) GetPodModelMetric(podName, modelName string, metricName string) (metrics.MetricValue, error) {
+	sample, ok := f.latencies[podName]
+	if !ok {
+		return nil, fmt.Errorf("no metric available for %v", metricName)
+	}
+	return &metrics.HistogramMetricValue{Sum: sample.sum, Count: sample.count}, nil
+}
+
+func rolloutAdapter(updated ...string) *modelv1alpha1.ModelAdapter {
+	return &modelv1alpha1.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{Name: "adapter", Namespace: "default"},
+		Spec: modelv1alpha1.ModelAdapterSpec{
+			ArtifactURL:     "s3://bucket/v2",
+			RolloutStrategy: &modelv1alpha1.ModelAdapterRolloutStrategy{MaxErrorRate: "0", MaxLatencyRatio: "1.5"},
+		},
+		Status: modelv1alpha1.ModelAdapterStatus{
+			ArtifactURL: "s3://bucket/v1",
+			Instances:   []string{"pod-a", "pod-b", "pod-c", "pod-d"},
+			Rollout: &modelv1alpha1.ModelAdapterRolloutStatus{
+				Phase:            modelv1alpha1.ModelAdapterRolloutProgressing,
+				ArtifactURL:      "s3://bucket/v2",
+				UpdatedInstances: updated,
+			},
+		},
+	}
+}
+
+func TestRolloutStepSize(t *testing.T) {
+	assert.Equal(t, 1, rolloutStepSize(4, 20))
+	assert.Equal(t, 2, rolloutStepSize(10, 20))
+	assert.Equal(t, 3, rolloutStepSize(10, 25))
+	assert.Equal(t, 4, rolloutStepSize(4, 100))
+	assert.Equal(t, 1, rolloutStepSize(1, 1))
+}
+
+func TestArtifactForPod(t *testing.T) {
+	instance := rolloutAdapter("pod-a")
+	assert.Equal(t, "s3://bucket/v2", artifactForPod(instance, "pod-a"))
+	assert.Equal(t, "s3://bucket/v1", artifactForPod(instance, "pod-b"))
+
+	instance.Status.Rollout.Phase = modelv1alpha1.ModelAdapterRolloutRolledBack
+	assert.Equal(t, "s3://bucket/v1", artifactForPod(instance, "pod-a"))
+
+	instance.Status.ArtifactURL = ""
+	assert.Equal(t, "s3://bucket/v2", artifactForPod(instance, "pod-b"))
+}
+
+func TestAnalyzeRollout(t *testing.T) {
+	samples := map[string]latencySample{
+		"pod-a": {sum: 30, count: 20},
+		"pod-b": {sum: 20, count: 20},
+		"pod-c": {sum: 20, count: 20},
+	}
+	baseline := map[string]latencySample{
+		"pod-a": {sum: 10, count: 10},
+		"pod-b": {sum: 10, count: 10},
+		"pod-c": {sum: 10, count: 10},
+	}
+
+	t.Run("healthy canary", func(t *testing.T) {
+		ok, message := analyzeRollout(rolloutAdapter("pod-a"), &fakeRolloutMetrics{}, samples, nil)
+		assert.True(t, ok, message)
+	})
+
+	t.Run("unhealthy engine", func(t *testing.T) {
+		provider := &fakeRolloutMetrics{notReady: map[string]bool{"pod-a": true}}
+		ok, message := analyzeRollout(rolloutAdapter("pod-a"), provider, samples, nil)
+		assert.False(t, ok)
+		assert.Contains(t, message, "error rate")
+	})
+
+	t.Run("error rate within threshold", func(t *testing.T) {
+		instance := rolloutAdapter("pod-a", "pod-b")
+		instance.Spec.RolloutStrategy.MaxErrorRate = "0.5"
+		provider := &fakeRolloutMetrics{notReady: map[string]bool{"pod-a": true}}
+		ok, message := analyzeRollout(instance, provider, samples, nil)
+		assert.True(t, ok, message)
+	})
+
+	t.Run("latency since the last step", func(t *testing.T) {
+		// pod-a served 10 requests in 20s since the step, the others 10 requests in 10s.
+		ok, message := analyzeRollout(rolloutAdapter("pod-a"), &fakeRolloutMetrics{}, samples, baseline)
+		assert.False(t, ok)
+		assert.Contains(t, message, "latency ratio 2.00")
+	})
+
+	t.Run("no traffic skips latency", func(t *testing.T) {
+		ok, message := analyzeRollout(rolloutAdapter("pod-a"), &fakeRolloutMetrics{}, baseline, baseline)
+		assert.True(t, ok, message)
+	})
+}
+
+func TestReconcileRollout(t *testing.T) {
+	r := &ModelAdapterReconciler{analyzer: newRolloutAnalyzer()}
+
+	t.Run("first load records the stable artifact", func(t *testing.T) {
+		instance := rolloutAdapter()
+		instance.Status.ArtifactURL = ""
+		instance.Status.Rollout = nil
+		requeue, err := r.reconcileRollout(context.TODO(), instance)
+		assert.NoError(t, err)
+		assert.Zero(t, requeue)
+		assert.Equal(t, "s3://bucket/v2", instance.Status.ArtifactURL)
+	})
+
+	t.Run("rolled back artifact is not retried", func(t *testing.T) {
+		instance := rolloutAdapter()
+		instance.Status.Rollout.Phase = modelv1alpha1.ModelAdapterRolloutRolledBack
+		requeue, err := r.reconcileRollout(context.TODO(), instance)
+		assert.NoError(t, err)
+		assert.Zero(t, requeue)
+		assert.Equal(t, "s3://bucket/v1", instance.Status.ArtifactURL)
+	})
+
+	t.Run("waits for the analysis interval", func(t *testing.T) {
+		instance := rolloutAdapter("pod-a")
+		instance.Spec.RolloutStrategy.AnalysisIntervalSeconds = 60
+		stepTime := metav1.NewTime(time.Now().Add(-20 * time.Second))
+		instance.Status.Rollout.LastStepTime = &stepTime
+		requeue, err := r.reconcileRollout(context.TODO(), instance)
+		assert.NoError(t, err)
+		assert.InDelta(t, 40*time.Second, requeue, float64(time.Second))
+		assert.Equal(t, []string{"pod-a"}, instance.Status.Rollout.UpdatedInstances)
+	})
+
+	t.Run("prunes removed instances", func(t *testing.T) {
+		instance := rolloutAdapter("pod-a", "pod-x")
+		now := metav1.Now()
+		instance.Status.Rollout.LastStepTime = &now
+		_, err := r.reconcileRollout(context.TODO(), instance)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"pod-a"}, instance.Status.Rollout.UpdatedInstances)
+	})
+}
@@ -18,18 +18,24 @@ package scheduling
 
 import (
 	"context"
+	"errors"
 	"math"
+	"strconv"
 
 	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 )
 
+// defaultPodAdapterCapacity is used when the engine doesn't report its max_lora.
+const defaultPodAdapterCapacity = 10
+
 type binPackScheduler struct {
-	cache *cache.Cache
+	cache cache.Interface
 }
 
-func NewBinPackScheduler(c *cache.Cache) Scheduler {
+func NewBinPackScheduler(c cache.Interface) Scheduler {
 	return binPackScheduler{
 		cache: c,
 	}
@@ -38,25 +44,70 @@ func NewBinPackScheduler(c *cache.Cache) Scheduler {
 func (r binPackScheduler) SelectPod(ctx context.Context, model string, pods []v1.Pod) (*v1.Pod, error) {
 	// Binpack algorithm: choose the pod (1) can place the adapter, (2) with the least remaining space
 
-	selectedPod := v1.Pod{}
+	var selectedPod *v1.Pod
 	podRemainCapMin := math.MaxInt
 
-	for _, pod := range pods {
-		models, err := r.cache.GetModelsForPod(pod.Name)
+	for i := range pods {
+		models, err := r.cache.GetModelsForPod(pods[i].Name)
 		if err != nil {
 			return nil, err
 		}
-		podCap := 10 // todo: replace mock data
+		podCap := podAdapterCapacity(r.cache, pods[i].Name)
 		if len(models) >= podCap {
 			continue
 		}
 
 		if podCap-len(models) < podRemainCapMin {
-			selectedPod = pod
+			selectedPod = &pods[i]
 			podRemainCapMin = podCap - len(models)
 		}
 	}
+	if selectedPod == nil {
+		return nil, errors.New("no pod has room for the model adapter")
+	}
+
+	klog.InfoS("pod selected with first fit", "pod", klog.KObj(selectedPod))
+	return selectedPod, nil
+}
+
+// SelectMove moves the instance on the pod hosting the fewest adapters to the candidate hosting the most
+// that still has room, so the pod left behind can be freed up. Ties go to the pod with the smaller name,
+// which keeps two adapters from swapping pods with each other.
+func (r binPackScheduler) SelectMove(ctx context.Context, model string, instances []v1.Pod, candidates []v1.Pod) (*v1.Pod, *v1.Pod, error) {
+	from, fromCount := podWithAdapterCount(r.cache, instances, func(count, best int) bool { return count < best })
+	if from == nil {
+		return nil, nil, nil
+	}
+
+	var to *v1.Pod
+	toCount := 0
+	for i := range candidates {
+		models, err := r.cache.GetModelsForPod(candidates[i].Name)
+		if err != nil || len(models) >= podAdapterCapacity(r.cache, candidates[i].Name) {
+			continue
+		}
+		if to == nil || len(models) > toCount {
+			to = &candidates[i]
+			toCount = len(models)
+		}
+	}
+	if to == nil || toCount < fromCount || (toCount == fromCount && to.Name > from.Name) {
+		return nil, nil, nil
+	}
 
-	klog.InfoS("pod selected with first fit", "pod", klog.KObj(&selectedPod))
-	return &selectedPod, nil
+	klog.InfoS("model adapter instance rebalanced to pod with most model adapters", "model", model, "from", klog.KObj(from), "to", klog.KObj(to))
+	return from, to, nil
+}
+
+// podAdapterCapacity returns how many adapters the engine of the pod can hold.
+func podAdapterCapacity(c cache.Interface, podName string) int {
+	value, err := c.GetPodMetric(podName, metrics.MaxLora)
+	if err != nil {
+		return defaultPodAdapterCapacity
+	}
+	capacity, err := strconv.Atoi(value.GetLabelValue())
+	if err != nil || capacity <= 0 {
+		return defaultPodAdapterCapacity
+	}
+	return capacity
 }
@@ -26,10 +26,10 @@ import (
 )
 
 type leastAdapters struct {
-	cache *cache.Cache
+	cache cache.Interface
 }
 
-func NewLeastAdapters(c *cache.Cache) Scheduler {
+func NewLeastAdapters(c cache.Interface) Scheduler {
 	return leastAdapters{
 		cache: c,
 	}
@@ -53,3 +53,33 @@ func (r leastAdapters) SelectPod(ctx context.Context, model string, pods []v1.Po
 	klog.InfoS("pod selected with least model adapters", "pod", klog.KObj(&selectedPod))
 	return &selectedPod, nil
 }
+
+// SelectMove moves the instance on the pod hosting the most adapters to the candidate hosting the fewest,
+// as long as it narrows the gap between them.
+func (r leastAdapters) SelectMove(ctx context.Context, model string, instances []v1.Pod, candidates []v1.Pod) (*v1.Pod, *v1.Pod, error) {
+	from, fromCount := podWithAdapterCount(r.cache, instances, func(count, best int) bool { return count > best })
+	to, toCount := podWithAdapterCount(r.cache, candidates, func(count, best int) bool { return count < best })
+	if from == nil || to == nil || fromCount-toCount < 2 {
+		return nil, nil, nil
+	}
+
+	klog.InfoS("model adapter instance rebalanced to pod with least model adapters", "model", model, "from", klog.KObj(from), "to", klog.KObj(to))
+	return from, to, nil
+}
+
+// podWithAdapterCount returns the pod whose adapter count is preferred by better, pods missing in the cache are skipped.
+func podWithAdapterCount(c cache.Interface, pods []v1.Pod, better func(count, best int) bool) (*v1.Pod, int) {
+	var selected *v1.Pod
+	selectedCount := 0
+	for i := range pods {
+		models, err := c.GetModelsForPod(pods[i].Name)
+		if err != nil {
+			continue
+		}
+		if selected == nil || better(len(models), selectedCount) {
+			selected = &pods[i]
+			selectedCount = len(models)
+		}
+	}
+	return selected, selectedCount
+}
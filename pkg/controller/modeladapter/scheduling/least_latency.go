@@ -27,10 +27,10 @@ import (
 )
 
 type leastLatencyScheduler struct {
-	cache *cache.Cache
+	cache cache.Interface
 }
 
-func NewLeastLatencyScheduler(c *cache.Cache) Scheduler {
+func NewLeastLatencyScheduler(c cache.Interface) Scheduler {
 	return leastLatencyScheduler{
 		cache: c,
 	}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// modelIdentifier is the label of the base model served by the pod, engine metrics are reported under it.
+const modelIdentifier = "model.aibrix.ai/name"
+
+type leastLoadedScheduler struct {
+	cache cache.Interface
+}
+
+func NewLeastLoadedScheduler(c cache.Interface) Scheduler {
+	return leastLoadedScheduler{
+		cache: c,
+	}
+}
+
+func (r leastLoadedScheduler) SelectPod(ctx context.Context, model string, pods []v1.Pod) (*v1.Pod, error) {
+	if len(pods) == 0 {
+		return nil, errors.New("no pods to schedule model adapter")
+	}
+
+	var selectedPod *v1.Pod
+	podLoadMin := math.MaxFloat64
+	for i := range pods {
+		load := r.podLoad(&pods[i])
+		if load < podLoadMin {
+			selectedPod = &pods[i]
+			podLoadMin = load
+		}
+	}
+
+	klog.InfoS("pod selected with least load", "pod", klog.KObj(selectedPod), "load", podLoadMin)
+	return selectedPod, nil
+}
+
+// podLoad is the number of running and waiting requests on the engine, metrics not scraped yet count as idle.
+func (r leastLoadedScheduler) podLoad(pod *v1.Pod) float64 {
+	baseModel := pod.Labels[modelIdentifier]
+	load := 0.0
+	for _, metricName := range []string{metrics.NumRequestsRunning, metrics.NumRequestsWaiting} {
+		value, err := r.cache.GetPodModelMetric(pod.Name, baseModel, metricName)
+		if err != nil {
+			klog.V(4).InfoS("metric not available for least loaded scheduling", "pod", klog.KObj(pod), "metric", metricName, "error", err)
+			continue
+		}
+		load += value.GetSimpleValue()
+	}
+	return load
+}
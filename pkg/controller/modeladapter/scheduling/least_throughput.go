@@ -27,10 +27,10 @@ import (
 )
 
 type leastThroughputScheduler struct {
-	cache *cache.Cache
+	cache cache.Interface
 }
 
-func NewLeastThroughputScheduler(c *cache.Cache) Scheduler {
+func NewLeastThroughputScheduler(c cache.Interface) Scheduler {
 	return leastThroughputScheduler{
 		cache: c,
 	}
@@ -27,10 +27,10 @@ import (
 )
 
 type randomScheduler struct {
-	cache *cache.Cache
+	cache cache.Interface
 }
 
-func NewRandomScheduler(c *cache.Cache) Scheduler {
+func NewRandomScheduler(c cache.Interface) Scheduler {
 	return randomScheduler{
 		cache: c,
 	}
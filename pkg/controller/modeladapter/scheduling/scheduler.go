@@ -30,8 +30,16 @@ type Scheduler interface {
 	SelectPod(ctx context.Context, model string, pods []v1.Pod) (*v1.Pod, error)
 }
 
+// Rebalancer is implemented by schedulers whose placement can be improved by moving an instance
+// of the model adapter once pods join or leave.
+type Rebalancer interface {
+	// SelectMove returns the instance to move and the candidate pod to move it to,
+	// both are nil when the current placement is already fine.
+	SelectMove(ctx context.Context, model string, instances []v1.Pod, candidates []v1.Pod) (*v1.Pod, *v1.Pod, error)
+}
+
 // NewScheduler leverages the factory method to choose the right scheduler
-func NewScheduler(policyName string, c *cache.Cache) (Scheduler, error) {
+func NewScheduler(policyName string, c cache.Interface) (Scheduler, error) {
 	switch policyName {
 	case "random":
 		return NewRandomScheduler(c), nil
@@ -43,6 +51,8 @@ func NewScheduler(policyName string, c *cache.Cache) (Scheduler, error) {
 		return NewLeastLatencyScheduler(c), nil
 	case "leastThroughput":
 		return NewLeastThroughputScheduler(c), nil
+	case "leastLoaded":
+		return NewLeastLoadedScheduler(c), nil
 	default:
 		return nil, errors.New("unknown scheduler policy")
 	}
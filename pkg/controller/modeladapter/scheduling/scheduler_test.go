@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+func testPods(names ...string) []v1.Pod {
+	pods := make([]v1.Pod, 0, len(names))
+	for _, name := range names {
+		pods = append(pods, v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{modelIdentifier: "base"}}})
+	}
+	return pods
+}
+
+// testCache returns a cache where each pod hosts the given number of models.
+func testCache(counts map[string]int) *cache.Cache {
+	c := cache.New()
+	for pod, count := range counts {
+		for i := 0; i < count; i++ {
+			c.SetPod(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pod}}, string(rune('a'+i)))
+		}
+	}
+	return c
+}
+
+func TestSpreadSelectMove(t *testing.T) {
+	c := testCache(map[string]int{"pod-1": 4, "pod-2": 3, "pod-3": 1})
+	scheduler := NewLeastAdapters(c).(Rebalancer)
+
+	from, to, err := scheduler.SelectMove(context.TODO(), "lora", testPods("pod-1"), testPods("pod-2", "pod-3"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pod-1", from.Name)
+	assert.Equal(t, "pod-3", to.Name)
+
+	// moving from pod-2 to pod-1 only shifts the imbalance
+	from, to, err = scheduler.SelectMove(context.TODO(), "lora", testPods("pod-2"), testPods("pod-1"))
+	assert.NoError(t, err)
+	assert.Nil(t, from)
+	assert.Nil(t, to)
+}
+
+func TestPackSelectMove(t *testing.T) {
+	c := testCache(map[string]int{"pod-1": 1, "pod-2": 1, "pod-3": 3, "pod-4": 10})
+	scheduler := NewBinPackScheduler(c).(Rebalancer)
+
+	// pod-4 is full, so the instance goes to the fullest pod with room left.
+	from, to, err := scheduler.SelectMove(context.TODO(), "lora", testPods("pod-1"), testPods("pod-3", "pod-4"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pod-1", from.Name)
+	assert.Equal(t, "pod-3", to.Name)
+
+	// on a tie only one of the two pods gives up its instance.
+	from, _, err = scheduler.SelectMove(context.TODO(), "lora", testPods("pod-1"), testPods("pod-2"))
+	assert.NoError(t, err)
+	assert.Nil(t, from)
+	from, to, err = scheduler.SelectMove(context.TODO(), "lora", testPods("pod-2"), testPods("pod-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pod-2", from.Name)
+	assert.Equal(t, "pod-1", to.Name)
+}
+
+func TestPackSelectPodCapacity(t *testing.T) {
+	c := testCache(map[string]int{"pod-1": 2, "pod-2": 1})
+	c.SetPodMetric("pod-1", metrics.MaxLora, &metrics.LabelValueMetricValue{Value: "2"})
+	scheduler := NewBinPackScheduler(c)
+
+	pod, err := scheduler.SelectPod(context.TODO(), "lora", testPods("pod-1", "pod-2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pod-2", pod.Name)
+
+	_, err = scheduler.SelectPod(context.TODO(), "lora", testPods("pod-1"))
+	assert.Error(t, err)
+}
+
+func TestLeastLoadedSelectPod(t *testing.T) {
+	c := testCache(map[string]int{})
+	c.SetPodModelMetric("pod-1", "base", metrics.NumRequestsRunning, &metrics.SimpleMetricValue{Value: 4})
+	c.SetPodModelMetric("pod-1", "base", metrics.NumRequestsWaiting, &metrics.SimpleMetricValue{Value: 2})
+	c.SetPodModelMetric("pod-2", "base", metrics.NumRequestsRunning, &metrics.SimpleMetricValue{Value: 5})
+	scheduler := NewLeastLoadedScheduler(c)
+
+	pod, err := scheduler.SelectPod(context.TODO(), "lora", testPods("pod-1", "pod-2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pod-2", pod.Name)
+
+	_, err = scheduler.SelectPod(context.TODO(), "lora", nil)
+	assert.Error(t, err)
+}
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
 	"github.com/vllm-project/aibrix/pkg/config"
@@ -49,18 +51,55 @@ func validateModelAdapter(instance *modelv1alpha1.ModelAdapter) error {
 		return fmt.Errorf("replicas must be greater than 0")
 	}
 
+	if strategy := instance.Spec.RolloutStrategy; strategy != nil {
+		if _, err := parseRolloutThreshold(strategy.MaxErrorRate, 0); err != nil {
+			return fmt.Errorf("rolloutStrategy.maxErrorRate is not valid: %v", err)
+		}
+		if _, err := parseRolloutThreshold(strategy.MaxLatencyRatio, defaultMaxLatencyRatio); err != nil {
+			return fmt.Errorf("rolloutStrategy.maxLatencyRatio is not valid: %v", err)
+		}
+	}
+
 	return nil
 }
 
-// validateArtifactURL checks if the ArtifactURL has a valid schema (s3://, gcs://, huggingface://, https://, /)
+// validateArtifactURL checks if the ArtifactURL has a valid schema (s3://, gcs://, oci://, huggingface://, /)
 func validateArtifactURL(artifactURL string) error {
-	allowedSchemes := []string{"s3://", "gcs://", "huggingface://", "hf://", "/"}
+	allowedSchemes := []string{"s3://", "gcs://", "oci://", "huggingface://", "hf://", "/"}
 	for _, scheme := range allowedSchemes {
 		if strings.HasPrefix(artifactURL, scheme) {
 			return nil
 		}
 	}
-	return fmt.Errorf("artifactURL must start with one of the following schemes: s3://, gcs://, huggingface://")
+	return fmt.Errorf("artifactURL must start with one of the following schemes: s3://, gcs://, oci://, huggingface://")
+}
+
+// getDesiredInstances returns the number of pods the adapter should be loaded on, spec.replicas at least.
+// The gateway raises it through annotation when all pods hosting the adapter are saturated, the annotation is ignored
+// once the gateway didn't raise it for the idle window. It also returns how long the annotation remains in effect, 0 if
+// it isn't.
+func getDesiredInstances(instance *modelv1alpha1.ModelAdapter, now time.Time, idleWindow time.Duration) (int, time.Duration) {
+	desired := 1
+	if instance.Spec.Replicas != nil && *instance.Spec.Replicas > 1 {
+		desired = int(*instance.Spec.Replicas)
+	}
+	value, ok := instance.Annotations[modelv1alpha1.DesiredInstancesAnnotation]
+	if !ok {
+		return desired, 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= desired {
+		return desired, 0
+	}
+	raised, err := time.Parse(time.RFC3339, instance.Annotations[modelv1alpha1.DesiredInstancesTimeAnnotation])
+	if err != nil {
+		return desired, 0
+	}
+	remaining := raised.Add(idleWindow).Sub(now)
+	if remaining <= 0 {
+		return desired, 0
+	}
+	return n, remaining
 }
 
 func equalStringSlices(a, b []string) bool {
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -74,7 +75,7 @@ func TestValidateModelAdapter(t *testing.T) {
 		}
 
 		err := validateModelAdapter(instance)
-		assert.EqualError(t, err, "artifactURL must start with one of the following schemes: s3://, gcs://, huggingface://")
+		assert.EqualError(t, err, "artifactURL must start with one of the following schemes: s3://, gcs://, oci://, huggingface://")
 	})
 
 	// Case 4: Missing PodSelector
@@ -106,6 +107,22 @@ func TestValidateModelAdapter(t *testing.T) {
 		err := validateModelAdapter(instance)
 		assert.EqualError(t, err, "replicas must be greater than 0")
 	})
+
+	// Case 6: Invalid rollout threshold
+	t.Run("invalid rollout threshold", func(t *testing.T) {
+		instance := &modelv1alpha1.ModelAdapter{
+			Spec: modelv1alpha1.ModelAdapterSpec{
+				ArtifactURL: "s3://bucket/path/to/artifact",
+				PodSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "test"},
+				},
+				RolloutStrategy: &modelv1alpha1.ModelAdapterRolloutStrategy{MaxLatencyRatio: "-1"},
+			},
+		}
+
+		err := validateModelAdapter(instance)
+		assert.EqualError(t, err, "rolloutStrategy.maxLatencyRatio is not valid: -1 must not be negative")
+	})
 }
 
 // Test for validateArtifactURL function
@@ -131,7 +148,7 @@ func TestValidateArtifactURL(t *testing.T) {
 	// Case 4: Invalid scheme
 	t.Run("invalid scheme", func(t *testing.T) {
 		err := validateArtifactURL("ftp://bucket/path")
-		assert.EqualError(t, err, "artifactURL must start with one of the following schemes: s3://, gcs://, huggingface://")
+		assert.EqualError(t, err, "artifactURL must start with one of the following schemes: s3://, gcs://, oci://, huggingface://")
 	})
 }
 
@@ -308,3 +325,43 @@ func TestBuildURLs(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDesiredInstances(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	raised := func(desired string, ago time.Duration) map[string]string {
+		return map[string]string{
+			modelv1alpha1.DesiredInstancesAnnotation:     desired,
+			modelv1alpha1.DesiredInstancesTimeAnnotation: now.Add(-ago).Format(time.RFC3339),
+		}
+	}
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		replicas       *int32
+		expected       int
+		expectedExpiry time.Duration
+	}{
+		{name: "no annotation", annotations: nil, expected: 1},
+		{name: "scaled out", annotations: raised("3", time.Minute), expected: 3, expectedExpiry: 9 * time.Minute},
+		{name: "invalid value", annotations: raised("abc", time.Minute), expected: 1},
+		{name: "below minimum", annotations: raised("0", time.Minute), expected: 1},
+		{name: "replicas", replicas: ptr.To(int32(3)), expected: 3},
+		{name: "scaled out beyond replicas", annotations: raised("4", time.Minute), replicas: ptr.To(int32(2)), expected: 4, expectedExpiry: 9 * time.Minute},
+		{name: "replicas above annotation", annotations: raised("2", time.Minute), replicas: ptr.To(int32(3)), expected: 3},
+		{name: "idle window over", annotations: raised("3", 10*time.Minute), replicas: ptr.To(int32(2)), expected: 2},
+		{name: "no time", annotations: map[string]string{modelv1alpha1.DesiredInstancesAnnotation: "3"}, expected: 1},
+		{name: "invalid time", annotations: map[string]string{modelv1alpha1.DesiredInstancesAnnotation: "3", modelv1alpha1.DesiredInstancesTimeAnnotation: "yesterday"}, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &modelv1alpha1.ModelAdapter{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec:       modelv1alpha1.ModelAdapterSpec{Replicas: tt.replicas},
+			}
+			desired, expiry := getDesiredInstances(instance, now, 10*time.Minute)
+			assert.Equal(t, tt.expected, desired)
+			assert.Equal(t, tt.expectedExpiry, expiry)
+		})
+	}
+}
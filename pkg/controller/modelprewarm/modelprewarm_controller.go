@@ -0,0 +1,278 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelprewarm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// PrewarmAnnotation pre-pulls the weights of the scale target of a PodAutoscaler onto every node its pods can be
+	// scheduled on, with "true".
+	PrewarmAnnotation = "autoscaling.aibrix.ai/prewarm-weights"
+	// PrewarmLabel names the model whose weights a pre-warming pod pulled, the gateway tracks the warm nodes by it.
+	PrewarmLabel = "model.aibrix.ai/prewarm"
+
+	modelIdentifierLabel = "model.aibrix.ai/name"
+	templateHashKey      = "model.aibrix.ai/prewarm-template-hash"
+	pauseImageEnv        = "AIBRIX_PREWARM_PAUSE_IMAGE"
+	defaultPauseImage    = "registry.k8s.io/pause:3.9"
+)
+
+var controllerName = "model-prewarm-controller"
+
+// Add creates a new model pre-warming Controller and adds it to the Manager with default RBAC.
+// The Manager will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, runtimeConfig config.RuntimeConfig) error {
+	r, err := newReconciler(mgr, runtimeConfig)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, runtimeConfig config.RuntimeConfig) (reconcile.Reconciler, error) {
+	reconciler := &ModelPrewarmReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorderFor(controllerName),
+		PauseImage: utils.LoadEnv(pauseImageEnv, defaultPauseImage),
+	}
+	return reconciler, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		For(&autoscalingv1alpha1.PodAutoscaler{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			predicate.AnnotationChangedPredicate{},
+		))).
+		Owns(&appsv1.DaemonSet{}).
+		Complete(r)
+
+	klog.InfoS("Finished to add model-prewarm-controller")
+	return err
+}
+
+// ModelPrewarmReconciler runs a pre-warming DaemonSet for each PodAutoscaler with the PrewarmAnnotation. Its pods run
+// the init containers of the Deployment that download the weights to a hostPath volume on every node the pods of the
+// Deployment can be scheduled on, so a scale-up finds the weights on the node.
+type ModelPrewarmReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	Recorder   record.EventRecorder
+	PauseImage string
+}
+
+// +kubebuilder:rbac:groups=autoscaling.aibrix.ai,resources=podautoscalers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile creates, updates or deletes the pre-warming DaemonSet of a PodAutoscaler.
+func (r *ModelPrewarmReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	pa := &autoscalingv1alpha1.PodAutoscaler{}
+	if err := r.Get(ctx, req.NamespacedName, pa); err != nil {
+		if errors.IsNotFound(err) {
+			// the DaemonSet is garbage collected with its owner.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if pa.Annotations[PrewarmAnnotation] != "true" || pa.DeletionTimestamp != nil {
+		return ctrl.Result{}, r.deleteDaemonSet(ctx, pa)
+	}
+	if pa.Spec.ScaleTargetRef.Kind != "Deployment" {
+		r.Recorder.Eventf(pa, corev1.EventTypeWarning, "PrewarmUnsupported", "Pre-warming requires a Deployment scale target, got %s", pa.Spec.ScaleTargetRef.Kind)
+		return ctrl.Result{}, r.deleteDaemonSet(ctx, pa)
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: pa.Namespace, Name: pa.Spec.ScaleTargetRef.Name}, deployment); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get deployment %s: %v", pa.Spec.ScaleTargetRef.Name, err)
+	}
+	daemonSet, err := constructDaemonSet(pa, deployment, r.PauseImage)
+	if err != nil {
+		r.Recorder.Eventf(pa, corev1.EventTypeWarning, "PrewarmUnsupported", "Cannot pre-warm deployment %s: %v", deployment.Name, err)
+		return ctrl.Result{}, r.deleteDaemonSet(ctx, pa)
+	}
+	if err := controllerutil.SetControllerReference(pa, daemonSet, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	found := &appsv1.DaemonSet{}
+	err = r.Get(ctx, client.ObjectKeyFromObject(daemonSet), found)
+	if err != nil && errors.IsNotFound(err) {
+		klog.InfoS("Creating pre-warming DaemonSet", "DaemonSet", klog.KObj(daemonSet), "model", daemonSet.Labels[PrewarmLabel])
+		if err := r.Create(ctx, daemonSet); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Eventf(pa, corev1.EventTypeNormal, "PrewarmStarted", "Pre-warming the weights of deployment %s with DaemonSet %s", deployment.Name, daemonSet.Name)
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// the template is defaulted by the API server, so changes are detected on the hash of the desired one.
+	if found.Annotations[templateHashKey] != daemonSet.Annotations[templateHashKey] {
+		found.Labels = daemonSet.Labels
+		found.Annotations = daemonSet.Annotations
+		found.Spec.Template = daemonSet.Spec.Template
+		klog.InfoS("Updating pre-warming DaemonSet", "DaemonSet", klog.KObj(found))
+		if err := r.Update(ctx, found); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *ModelPrewarmReconciler) deleteDaemonSet(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler) error {
+	daemonSet := &appsv1.DaemonSet{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: pa.Namespace, Name: daemonSetName(pa)}, daemonSet)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(daemonSet, pa) {
+		return nil
+	}
+	klog.InfoS("Deleting pre-warming DaemonSet", "DaemonSet", klog.KObj(daemonSet))
+	return client.IgnoreNotFound(r.Delete(ctx, daemonSet))
+}
+
+func daemonSetName(pa *autoscalingv1alpha1.PodAutoscaler) string {
+	return pa.Name + "-prewarm"
+}
+
+// constructDaemonSet builds the pre-warming DaemonSet of the deployment: its pods run the init containers of the
+// deployment writing to hostPath volumes, which download the weights, and then idle in a pause container, ready once
+// the weights are on the node. They are scheduled like the pods of the deployment but don't request its GPUs.
+func constructDaemonSet(pa *autoscalingv1alpha1.PodAutoscaler, deployment *appsv1.Deployment, pauseImage string) (*appsv1.DaemonSet, error) {
+	template := deployment.Spec.Template
+	model := template.Labels[modelIdentifierLabel]
+	if model == "" {
+		model = deployment.Labels[modelIdentifierLabel]
+	}
+	if model == "" {
+		return nil, fmt.Errorf("the pod template has no %s label", modelIdentifierLabel)
+	}
+
+	hostPathVolumes := map[string]bool{}
+	for _, volume := range template.Spec.Volumes {
+		if volume.HostPath != nil {
+			hostPathVolumes[volume.Name] = true
+		}
+	}
+	var initContainers []corev1.Container
+	usedVolumes := map[string]bool{}
+	for _, container := range template.Spec.InitContainers {
+		writesHostPath := false
+		for _, mount := range container.VolumeMounts {
+			if hostPathVolumes[mount.Name] && !mount.ReadOnly {
+				writesHostPath = true
+			}
+		}
+		if !writesHostPath {
+			continue
+		}
+		container = *container.DeepCopy()
+		// sidecars of the engine are not needed to download the weights.
+		container.RestartPolicy = nil
+		for _, mount := range container.VolumeMounts {
+			usedVolumes[mount.Name] = true
+		}
+		initContainers = append(initContainers, container)
+	}
+	if len(initContainers) == 0 {
+		return nil, fmt.Errorf("no init container writes the weights to a hostPath volume")
+	}
+	var volumes []corev1.Volume
+	for _, volume := range template.Spec.Volumes {
+		if usedVolumes[volume.Name] {
+			volumes = append(volumes, volume)
+		}
+	}
+
+	labels := map[string]string{PrewarmLabel: model, "app.kubernetes.io/instance": daemonSetName(pa)}
+	podSpec := corev1.PodSpec{
+		InitContainers: initContainers,
+		Containers: []corev1.Container{{
+			Name:  "pause",
+			Image: pauseImage,
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1m"),
+				corev1.ResourceMemory: resource.MustParse("8Mi"),
+			}},
+		}},
+		Volumes:            volumes,
+		NodeSelector:       template.Spec.NodeSelector,
+		Affinity:           template.Spec.Affinity,
+		Tolerations:        template.Spec.Tolerations,
+		ImagePullSecrets:   template.Spec.ImagePullSecrets,
+		ServiceAccountName: template.Spec.ServiceAccountName,
+	}
+	data, err := json.Marshal(podSpec)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        daemonSetName(pa),
+			Namespace:   pa.Namespace,
+			Labels:      labels,
+			Annotations: map[string]string{templateHashKey: hex.EncodeToString(sum[:])[:16]},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}, nil
+}
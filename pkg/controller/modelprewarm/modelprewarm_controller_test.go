@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelprewarm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+func makeDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{modelIdentifierLabel: "llama"}},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: "init-model", Image: "aibrix/runtime:v0.2.0",
+						VolumeMounts: []corev1.VolumeMount{{Name: "model-hostpath", MountPath: "/models"}}},
+					{Name: "wait", Image: "busybox",
+						VolumeMounts: []corev1.VolumeMount{{Name: "model-hostpath", MountPath: "/models", ReadOnly: true}}},
+				},
+				Containers: []corev1.Container{{Name: "vllm-openai", Image: "vllm/vllm-openai:v0.7.1"}},
+				Volumes: []corev1.Volume{
+					{Name: "model-hostpath", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/root/models"}}},
+					{Name: "dshm", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				},
+				NodeSelector: map[string]string{"gpu": "l20"},
+			},
+		}},
+	}
+}
+
+func TestConstructDaemonSet(t *testing.T) {
+	pa := &autoscalingv1alpha1.PodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "llama-pa", Namespace: "default"}}
+	daemonSet, err := constructDaemonSet(pa, makeDeployment(), defaultPauseImage)
+	assert.NoError(t, err)
+	assert.Equal(t, "llama-pa-prewarm", daemonSet.Name)
+	assert.Equal(t, "llama", daemonSet.Spec.Template.Labels[PrewarmLabel])
+
+	spec := daemonSet.Spec.Template.Spec
+	assert.Len(t, spec.InitContainers, 1)
+	assert.Equal(t, "init-model", spec.InitContainers[0].Name)
+	assert.Len(t, spec.Volumes, 1)
+	assert.Equal(t, "model-hostpath", spec.Volumes[0].Name)
+	assert.Equal(t, map[string]string{"gpu": "l20"}, spec.NodeSelector)
+	assert.Equal(t, defaultPauseImage, spec.Containers[0].Image)
+
+	deployment := makeDeployment()
+	deployment.Spec.Template.Spec.Volumes[0].HostPath = nil
+	_, err = constructDaemonSet(pa, deployment, defaultPauseImage)
+	assert.Error(t, err)
+}
+
+func TestReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, appsv1.AddToScheme(scheme))
+	assert.NoError(t, autoscalingv1alpha1.AddToScheme(scheme))
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama-pa", Namespace: "default", UID: "uid",
+			Annotations: map[string]string{PrewarmAnnotation: "true"}},
+		Spec: autoscalingv1alpha1.PodAutoscalerSpec{
+			ScaleTargetRef: corev1.ObjectReference{Kind: "Deployment", Name: "llama"},
+		},
+	}
+	r := &ModelPrewarmReconciler{
+		Client:     fake.NewClientBuilder().WithScheme(scheme).WithObjects(pa, makeDeployment()).Build(),
+		Scheme:     scheme,
+		Recorder:   record.NewFakeRecorder(10),
+		PauseImage: defaultPauseImage,
+	}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pa)}
+	key := client.ObjectKey{Namespace: "default", Name: "llama-pa-prewarm"}
+
+	_, err := r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	daemonSet := &appsv1.DaemonSet{}
+	assert.NoError(t, r.Get(ctx, key, daemonSet))
+	assert.True(t, metav1.IsControlledBy(daemonSet, pa))
+
+	// a new download image is rolled out to the DaemonSet.
+	deployment := makeDeployment()
+	assert.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(deployment), deployment))
+	deployment.Spec.Template.Spec.InitContainers[0].Image = "aibrix/runtime:v0.3.0"
+	assert.NoError(t, r.Update(ctx, deployment))
+	_, err = r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Get(ctx, key, daemonSet))
+	assert.Equal(t, "aibrix/runtime:v0.3.0", daemonSet.Spec.Template.Spec.InitContainers[0].Image)
+
+	assert.NoError(t, r.Get(ctx, req.NamespacedName, pa))
+	delete(pa.Annotations, PrewarmAnnotation)
+	assert.NoError(t, r.Update(ctx, pa))
+	_, err = r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.True(t, errors.IsNotFound(r.Get(ctx, key, daemonSet)))
+}
@@ -0,0 +1,333 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/common"
+	podutil "github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// batchLendingReclaimTimeoutAnnotation lends the idle replicas of the scale target to batch workloads, the replicas
+	// the PodAutoscaler keeps above the demand of the interactive load, e.g. for the min replicas. When the interactive
+	// load rises the replicas are reclaimed: their batch requests complete for at most the timeout, e.g. "2m", before
+	// they serve interactive requests again.
+	batchLendingReclaimTimeoutAnnotation = common.AutoscalingLabelPrefix + "batch-lending-reclaim-timeout"
+	// podBatchLoanAnnotation tells the gateway a pod is lent to batch workloads, batchLoanLent, or being reclaimed,
+	// batchLoanReclaiming. It routes no interactive requests to the pod meanwhile.
+	podBatchLoanAnnotation = common.AutoscalingLabelPrefix + "batch-loan"
+	batchLoanLent          = "lent"
+	batchLoanReclaiming    = "reclaiming"
+
+	// batchLoanLabel names the PodAutoscaler of the Leases tracking its loans.
+	batchLoanLabel = common.AutoscalingLabelPrefix + "batch-loan-of"
+	// reclaimStartAnnotation is the time the reclaim of a loan started in RFC3339, set on its Lease.
+	reclaimStartAnnotation = common.AutoscalingLabelPrefix + "reclaim-start"
+	batchLoanHolder        = "batch"
+)
+
+// batchLendingReclaimTimeout returns how long the batch requests of a reclaimed pod may run, false if the pods are not
+// lent.
+func batchLendingReclaimTimeout(pa *autoscalingv1alpha1.PodAutoscaler) (time.Duration, bool) {
+	value, ok := pa.Annotations[batchLendingReclaimTimeoutAnnotation]
+	if !ok {
+		return 0, false
+	}
+	if len(pa.Spec.Pools) > 0 || pa.Spec.RayWorkerGroup != "" {
+		klog.InfoS("ignoring batch lending, it requires a scale target without pools or worker group", "PodAutoscaler", klog.KObj(pa))
+		return 0, false
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < time.Second {
+		klog.ErrorS(err, "invalid batch lending reclaim timeout", "PodAutoscaler", klog.KObj(pa), "value", value)
+		return 0, false
+	}
+	return timeout, true
+}
+
+// batchLoanName is the name of the Lease tracking the loan of the pod.
+func batchLoanName(podName string) string {
+	return podName + "-batch-loan"
+}
+
+// batchLoan is a pod lent to batch workloads and its Lease.
+type batchLoan struct {
+	pod   *corev1.Pod
+	lease *coordinationv1.Lease
+}
+
+func (l batchLoan) reclaimStart() (time.Time, bool) {
+	value, ok := l.lease.Annotations[reclaimStartAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	start, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// an invalid start doesn't hold the reclaim.
+		return time.Time{}, true
+	}
+	return start, true
+}
+
+func (l batchLoan) reclaimTimeout() time.Duration {
+	if l.lease.Spec.LeaseDurationSeconds == nil {
+		return 0
+	}
+	return time.Duration(*l.lease.Spec.LeaseDurationSeconds) * time.Second
+}
+
+// lendIdleReplicas lends the ready pods of the scale target the interactive load doesn't need to batch workloads, and
+// reclaims them when it needs them again, demandReplicas being the replicas the metrics recommend, negative if they are
+// unknown. Each loan is tracked by a Lease owned by the pod, whose duration bounds the reclaim. A reclaimed pod serves
+// interactive requests again once the gateway has no requests in-flight on it or the reclaim timed out.
+func (r *PodAutoscalerReconciler) lendIdleReplicas(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, scale *unstructured.Unstructured,
+	demandReplicas int32, now time.Time) error {
+	timeout, enabled := batchLendingReclaimTimeout(pa)
+	leaseList := &coordinationv1.LeaseList{}
+	if err := r.List(ctx, leaseList, client.InNamespace(pa.Namespace), client.MatchingLabels{batchLoanLabel: pa.Name}); err != nil {
+		return fmt.Errorf("failed to list batch loans: %v", err)
+	}
+	if !enabled && len(leaseList.Items) == 0 {
+		return nil
+	}
+	selector, err := extractLabelSelector(scale)
+	if err != nil {
+		return err
+	}
+	podList, err := podutil.GetPodListByLabelSelector(ctx, r.Client, pa.Namespace, selector)
+	if err != nil {
+		return err
+	}
+	leases := make(map[string]*coordinationv1.Lease, len(leaseList.Items))
+	for i := range leaseList.Items {
+		leases[leaseList.Items[i].Name] = &leaseList.Items[i]
+	}
+
+	var idle []*corev1.Pod
+	var lent, reclaiming []batchLoan
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		lease := leases[batchLoanName(pod.Name)]
+		delete(leases, batchLoanName(pod.Name))
+		_, annotated := pod.Annotations[podBatchLoanAnnotation]
+		_, draining := pod.Annotations[podDrainAnnotation]
+		if pod.DeletionTimestamp != nil || draining || !podutil.IsPodReady(pod) || (lease == nil && annotated) {
+			// the pods removed by a scale-down are drained of their batch requests like of interactive ones.
+			if lease != nil || annotated {
+				if err := r.endBatchLoan(ctx, pa, batchLoan{pod: pod, lease: lease}); err != nil {
+					return err
+				}
+			}
+			if pod.DeletionTimestamp == nil && !draining && podutil.IsPodReady(pod) {
+				idle = append(idle, pod)
+			}
+			continue
+		}
+		if lease == nil {
+			idle = append(idle, pod)
+			continue
+		}
+		loan := batchLoan{pod: pod, lease: lease}
+		if _, ok := loan.reclaimStart(); ok {
+			reclaiming = append(reclaiming, loan)
+		} else {
+			lent = append(lent, loan)
+		}
+	}
+	// the leases of pods the scale target doesn't select anymore.
+	for _, lease := range leases {
+		if err := client.IgnoreNotFound(r.Delete(ctx, lease)); err != nil {
+			return fmt.Errorf("failed to delete batch loan %s: %v", lease.Name, err)
+		}
+	}
+
+	// the interactive load keeps at least one pod.
+	target := len(lent)
+	if !enabled {
+		target = 0
+	} else if demandReplicas >= 0 {
+		target = max(len(idle)+len(lent)+len(reclaiming)-max(int(demandReplicas), 1), 0)
+	}
+
+	// reclaims that completed or timed out return the pods, the pods reclaimed in this pass are checked on the next
+	// ones since the gateway has to see the reclaim first.
+	var pending []batchLoan
+	for _, loan := range reclaiming {
+		start, _ := loan.reclaimStart()
+		if now.Sub(start) >= loan.reclaimTimeout() || r.podDrained(ctx, loan.pod.Name) {
+			if err := r.endBatchLoan(ctx, pa, loan); err != nil {
+				return err
+			}
+			continue
+		}
+		pending = append(pending, loan)
+	}
+	for _, loans := range [][]batchLoan{lent, pending} {
+		for _, loan := range loans {
+			if err := r.syncBatchLoanAnnotation(ctx, loan); err != nil {
+				return err
+			}
+		}
+	}
+
+	// pods still being reclaimed are lent again first when the interactive load dropped again.
+	for len(lent) < target && len(pending) > 0 {
+		loan := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		original := loan.lease.DeepCopy()
+		delete(loan.lease.Annotations, reclaimStartAnnotation)
+		if err := r.Patch(ctx, loan.lease, client.MergeFrom(original)); err != nil {
+			return fmt.Errorf("failed to patch batch loan %s: %v", loan.lease.Name, err)
+		}
+		if err := r.syncBatchLoanAnnotation(ctx, loan); err != nil {
+			return err
+		}
+		lent = append(lent, loan)
+	}
+	if len(lent) < target {
+		sortDrainCandidates(idle)
+		for i := 0; i < target-len(lent) && i < len(idle); i++ {
+			if err := r.lendPod(ctx, pa, idle[i], timeout, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// the loans made last are reclaimed first.
+	sort.SliceStable(lent, func(i, j int) bool { return acquireTime(lent[i].lease).After(acquireTime(lent[j].lease)) })
+	for _, loan := range lent[:len(lent)-target] {
+		original := loan.lease.DeepCopy()
+		if loan.lease.Annotations == nil {
+			loan.lease.Annotations = map[string]string{}
+		}
+		loan.lease.Annotations[reclaimStartAnnotation] = now.UTC().Format(time.RFC3339)
+		loan.lease.Spec.RenewTime = &metav1.MicroTime{Time: now}
+		if err := r.Patch(ctx, loan.lease, client.MergeFrom(original)); err != nil {
+			return fmt.Errorf("failed to patch batch loan %s: %v", loan.lease.Name, err)
+		}
+		if err := r.syncBatchLoanAnnotation(ctx, loan); err != nil {
+			return err
+		}
+		r.EventRecorder.Eventf(pa, corev1.EventTypeNormal, "ReclaimingPod", "Reclaiming pod %s from batch workloads", loan.pod.Name)
+	}
+	return nil
+}
+
+func acquireTime(lease *coordinationv1.Lease) time.Time {
+	if lease.Spec.AcquireTime == nil {
+		return time.Time{}
+	}
+	return lease.Spec.AcquireTime.Time
+}
+
+// lendPod creates the Lease of the loan of the pod and marks the pod lent for the gateway.
+func (r *PodAutoscalerReconciler) lendPod(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, pod *corev1.Pod, timeout time.Duration, now time.Time) error {
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      batchLoanName(pod.Name),
+			Namespace: pod.Namespace,
+			Labels:    map[string]string{batchLoanLabel: pa.Name},
+			// the loan ends with the pod.
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "Pod", Name: pod.Name, UID: pod.UID}},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To(batchLoanHolder),
+			LeaseDurationSeconds: ptr.To(int32(timeout.Seconds())),
+			AcquireTime:          &metav1.MicroTime{Time: now},
+			RenewTime:            &metav1.MicroTime{Time: now},
+		},
+	}
+	if err := r.Create(ctx, lease); err != nil {
+		return fmt.Errorf("failed to create batch loan %s: %v", lease.Name, err)
+	}
+	if err := r.syncBatchLoanAnnotation(ctx, batchLoan{pod: pod, lease: lease}); err != nil {
+		return err
+	}
+	r.EventRecorder.Eventf(pa, corev1.EventTypeNormal, "LentPod", "Lent pod %s to batch workloads", pod.Name)
+	klog.InfoS("lent pod to batch workloads", "PodAutoscaler", klog.KObj(pa), "pod", pod.Name)
+	return nil
+}
+
+// endBatchLoan deletes the Lease of the loan and returns the pod to interactive requests.
+func (r *PodAutoscalerReconciler) endBatchLoan(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, loan batchLoan) error {
+	if loan.lease != nil {
+		if err := client.IgnoreNotFound(r.Delete(ctx, loan.lease)); err != nil {
+			return fmt.Errorf("failed to delete batch loan %s: %v", loan.lease.Name, err)
+		}
+	}
+	if err := r.patchPodBatchLoan(ctx, loan.pod, ""); err != nil {
+		return err
+	}
+	r.EventRecorder.Eventf(pa, corev1.EventTypeNormal, "ReclaimedPod", "Reclaimed pod %s from batch workloads", loan.pod.Name)
+	klog.InfoS("reclaimed pod from batch workloads", "PodAutoscaler", klog.KObj(pa), "pod", loan.pod.Name)
+	return nil
+}
+
+// syncBatchLoanAnnotation marks the pod with the state of its loan, lent or reclaiming.
+func (r *PodAutoscalerReconciler) syncBatchLoanAnnotation(ctx context.Context, loan batchLoan) error {
+	state := batchLoanLent
+	if _, ok := loan.reclaimStart(); ok {
+		state = batchLoanReclaiming
+	}
+	return r.patchPodBatchLoan(ctx, loan.pod, state)
+}
+
+// patchPodBatchLoan sets the loan state of the pod, or removes it if state is empty.
+func (r *PodAutoscalerReconciler) patchPodBatchLoan(ctx context.Context, pod *corev1.Pod, state string) error {
+	if current, ok := pod.Annotations[podBatchLoanAnnotation]; current == state && (ok || state == "") {
+		return nil
+	}
+	original := pod.DeepCopy()
+	if state == "" {
+		delete(pod.Annotations, podBatchLoanAnnotation)
+	} else {
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[podBatchLoanAnnotation] = state
+	}
+	if err := r.Patch(ctx, pod, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("failed to patch pod %s: %v", pod.Name, err)
+	}
+	return nil
+}
+
+// withoutBatchLoans drops the pods lent to batch workloads, their metrics don't measure the interactive load.
+func withoutBatchLoans(pods []corev1.Pod) []corev1.Pod {
+	interactive := make([]corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if _, ok := pod.Annotations[podBatchLoanAnnotation]; !ok {
+			interactive = append(interactive, pod)
+		}
+	}
+	return interactive
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+func TestLendIdleReplicas(t *testing.T) {
+	inflight := map[string]bool{}
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inflight[r.URL.Query().Get("pod")] {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer gateway.Close()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, coordinationv1.AddToScheme(scheme))
+	busy := newTestReadyPod("llama-1", "llama")
+	busy.Annotations = map[string]string{podDeletionCostAnnotation: "100"}
+	r := &PodAutoscalerReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(busy,
+			newTestReadyPod("llama-2", "llama"), newTestReadyPod("llama-3", "llama")).Build(),
+		EventRecorder: record.NewFakeRecorder(20),
+		drainEndpoint: gateway.URL,
+	}
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default",
+			Annotations: map[string]string{batchLendingReclaimTimeoutAnnotation: "2m"}},
+	}
+	scale := newTestPoolScale("llama", 3)
+	ctx := context.Background()
+	now := time.Now()
+	loanStates := func() map[string]string {
+		states := map[string]string{}
+		for _, name := range []string{"llama-1", "llama-2", "llama-3"} {
+			pod := &corev1.Pod{}
+			require.NoError(t, r.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, pod))
+			lease := &coordinationv1.Lease{}
+			err := r.Get(ctx, client.ObjectKey{Namespace: "default", Name: batchLoanName(name)}, lease)
+			if state, ok := pod.Annotations[podBatchLoanAnnotation]; ok {
+				require.NoError(t, err, name)
+				assert.Equal(t, "batch", *lease.Spec.HolderIdentity)
+				assert.Equal(t, int32(120), *lease.Spec.LeaseDurationSeconds)
+				states[name] = state
+			} else {
+				assert.Error(t, err, name)
+			}
+		}
+		return states
+	}
+
+	// the interactive load needs one pod, the others are lent, the busiest pod is kept.
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, 1, now))
+	assert.Equal(t, map[string]string{"llama-2": batchLoanLent, "llama-3": batchLoanLent}, loanStates())
+
+	// unknown demand keeps the loans.
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, -1, now))
+	assert.Len(t, loanStates(), 2)
+
+	// a rising interactive load reclaims a pod, its batch requests complete first.
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, 2, now))
+	states := loanStates()
+	assert.Len(t, states, 2)
+	var reclaimed string
+	for name, state := range states {
+		if state == batchLoanReclaiming {
+			reclaimed = name
+		}
+	}
+	require.NotEmpty(t, reclaimed)
+	inflight[reclaimed] = true
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, 2, now.Add(time.Minute)))
+	assert.Equal(t, batchLoanReclaiming, loanStates()[reclaimed])
+
+	// a reclaim is cancelled when the interactive load drops again.
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, 1, now.Add(time.Minute)))
+	assert.Equal(t, map[string]string{"llama-2": batchLoanLent, "llama-3": batchLoanLent}, loanStates())
+
+	// the pod returns at the reclaim timeout even with batch requests in-flight, or once they completed.
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, 3, now.Add(time.Minute)))
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, 3, now.Add(3*time.Minute)))
+	inflight = map[string]bool{}
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, 3, now.Add(3*time.Minute)))
+	assert.Empty(t, loanStates())
+
+	// without the annotation the loans are reclaimed.
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, 1, now))
+	assert.Len(t, loanStates(), 2)
+	delete(pa.Annotations, batchLendingReclaimTimeoutAnnotation)
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, 1, now))
+	assert.Equal(t, map[string]string{"llama-2": batchLoanReclaiming, "llama-3": batchLoanReclaiming}, loanStates())
+	require.NoError(t, r.lendIdleReplicas(ctx, pa, scale, 1, now))
+	assert.Empty(t, loanStates())
+}
+
+func TestWithoutBatchLoans(t *testing.T) {
+	lent := newTestReadyPod("lent", "llama")
+	lent.Annotations = map[string]string{podBatchLoanAnnotation: batchLoanLent}
+	pods := withoutBatchLoans([]corev1.Pod{*newTestReadyPod("interactive", "llama"), *lent})
+	require.Len(t, pods, 1)
+	assert.Equal(t, "interactive", pods[0].Name)
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+// The behavior policies follow the HorizontalPodAutoscaler controller:
+// pkg/controller/podautoscaler/horizontal.go in kubernetes, the recommendation is first stabilized within the
+// stabilization windows and then limited by the policies on the replicas changed within their periods.
+
+type timestampedRecommendation struct {
+	recommendation int32
+	timestamp      time.Time
+}
+
+type timestampedScaleEvent struct {
+	replicaChange int32 // positive for both scale up and scale down
+	timestamp     time.Time
+}
+
+// behaviorHistory keeps the recommendations and scale events of each pa with behavior policies.
+type behaviorHistory struct {
+	mu              sync.Mutex
+	recommendations map[types.NamespacedName][]timestampedRecommendation
+	scaleUpEvents   map[types.NamespacedName][]timestampedScaleEvent
+	scaleDownEvents map[types.NamespacedName][]timestampedScaleEvent
+}
+
+func newBehaviorHistory() *behaviorHistory {
+	return &behaviorHistory{
+		recommendations: map[types.NamespacedName][]timestampedRecommendation{},
+		scaleUpEvents:   map[types.NamespacedName][]timestampedScaleEvent{},
+		scaleDownEvents: map[types.NamespacedName][]timestampedScaleEvent{},
+	}
+}
+
+// normalize stabilizes the recommendation of the pa and limits it by the scaling policies. It returns the normalized
+// replicas and, if the recommendation was changed, the reason.
+func (h *behaviorHistory) normalize(key types.NamespacedName, behavior *autoscalingv1alpha1.PodAutoscalerBehavior, currentReplicas, recommendation int32, now time.Time) (int32, string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stabilized := h.stabilizeLocked(key, behavior, currentReplicas, recommendation, now)
+	reason := ""
+	if stabilized != recommendation {
+		reason = "stabilization window"
+	}
+
+	desired := stabilized
+	if stabilized > currentReplicas && behavior.ScaleUp != nil {
+		limit := max(scaleUpLimit(currentReplicas, h.scaleUpEvents[key], h.scaleDownEvents[key], behavior.ScaleUp, now), currentReplicas)
+		if desired > limit {
+			desired = limit
+			reason = "scale up policy"
+		}
+	} else if stabilized < currentReplicas && behavior.ScaleDown != nil {
+		limit := min(scaleDownLimit(currentReplicas, h.scaleUpEvents[key], h.scaleDownEvents[key], behavior.ScaleDown, now), currentReplicas)
+		if desired < limit {
+			desired = limit
+			reason = "scale down policy"
+		}
+	}
+	return desired, reason
+}
+
+// stabilizeLocked records the recommendation and returns the replicas within the lowest recommendation of the scale
+// up window and the highest of the scale down window, so a single spike or dip does not rescale the target.
+func (h *behaviorHistory) stabilizeLocked(key types.NamespacedName, behavior *autoscalingv1alpha1.PodAutoscalerBehavior, currentReplicas, recommendation int32, now time.Time) int32 {
+	upCutoff := now.Add(-stabilizationWindow(behavior.ScaleUp))
+	downCutoff := now.Add(-stabilizationWindow(behavior.ScaleDown))
+	upRecommendation, downRecommendation := recommendation, recommendation
+
+	recommendations := h.recommendations[key][:0]
+	for _, rec := range h.recommendations[key] {
+		if rec.timestamp.Before(upCutoff) && rec.timestamp.Before(downCutoff) {
+			continue
+		}
+		if rec.timestamp.After(upCutoff) {
+			upRecommendation = min(rec.recommendation, upRecommendation)
+		}
+		if rec.timestamp.After(downCutoff) {
+			downRecommendation = max(rec.recommendation, downRecommendation)
+		}
+		recommendations = append(recommendations, rec)
+	}
+	h.recommendations[key] = append(recommendations, timestampedRecommendation{recommendation: recommendation, timestamp: now})
+
+	stabilized := currentReplicas
+	if stabilized < upRecommendation {
+		stabilized = upRecommendation
+	}
+	if stabilized > downRecommendation {
+		stabilized = downRecommendation
+	}
+	return stabilized
+}
+
+// recordScaleEvent records a rescale of the pa for the policies, the events outside the longest period are dropped.
+func (h *behaviorHistory) recordScaleEvent(key types.NamespacedName, behavior *autoscalingv1alpha1.PodAutoscalerBehavior, previousReplicas, newReplicas int32, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if newReplicas > previousReplicas {
+		h.scaleUpEvents[key] = appendScaleEvent(h.scaleUpEvents[key], newReplicas-previousReplicas, longestPolicyPeriod(behavior.ScaleUp), now)
+	} else if newReplicas < previousReplicas {
+		h.scaleDownEvents[key] = appendScaleEvent(h.scaleDownEvents[key], previousReplicas-newReplicas, longestPolicyPeriod(behavior.ScaleDown), now)
+	}
+}
+
+// delete drops the history of a deleted pa.
+func (h *behaviorHistory) delete(key types.NamespacedName) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.recommendations, key)
+	delete(h.scaleUpEvents, key)
+	delete(h.scaleDownEvents, key)
+}
+
+func appendScaleEvent(events []timestampedScaleEvent, replicaChange int32, period time.Duration, now time.Time) []timestampedScaleEvent {
+	kept := events[:0]
+	for _, event := range events {
+		if event.timestamp.After(now.Add(-period)) {
+			kept = append(kept, event)
+		}
+	}
+	return append(kept, timestampedScaleEvent{replicaChange: replicaChange, timestamp: now})
+}
+
+func stabilizationWindow(rules *autoscalingv2.HPAScalingRules) time.Duration {
+	if rules == nil || rules.StabilizationWindowSeconds == nil {
+		return 0
+	}
+	return time.Duration(*rules.StabilizationWindowSeconds) * time.Second
+}
+
+func longestPolicyPeriod(rules *autoscalingv2.HPAScalingRules) time.Duration {
+	var longest int32
+	if rules != nil {
+		for _, policy := range rules.Policies {
+			longest = max(longest, policy.PeriodSeconds)
+		}
+	}
+	return time.Duration(longest) * time.Second
+}
+
+func selectPolicy(rules *autoscalingv2.HPAScalingRules) autoscalingv2.ScalingPolicySelect {
+	if rules.SelectPolicy == nil {
+		return autoscalingv2.MaxChangePolicySelect
+	}
+	return *rules.SelectPolicy
+}
+
+// replicasChangedWithinPeriod sums the replicas changed by the events within the period of the policy.
+func replicasChangedWithinPeriod(periodSeconds int32, events []timestampedScaleEvent, now time.Time) int32 {
+	cutoff := now.Add(-time.Duration(periodSeconds) * time.Second)
+	var changed int32
+	for _, event := range events {
+		if event.timestamp.After(cutoff) {
+			changed += event.replicaChange
+		}
+	}
+	return changed
+}
+
+// scaleUpLimit returns the highest replicas the policies allow to scale up to, relative to the replicas at the start
+// of each policy period.
+func scaleUpLimit(currentReplicas int32, scaleUpEvents, scaleDownEvents []timestampedScaleEvent, rules *autoscalingv2.HPAScalingRules, now time.Time) int32 {
+	if len(rules.Policies) == 0 {
+		return math.MaxInt32
+	}
+	policySelect := selectPolicy(rules)
+	if policySelect == autoscalingv2.DisabledPolicySelect {
+		return currentReplicas
+	}
+	result := int32(math.MinInt32)
+	if policySelect == autoscalingv2.MinChangePolicySelect {
+		result = math.MaxInt32
+	}
+	for _, policy := range rules.Policies {
+		periodStartReplicas := currentReplicas - replicasChangedWithinPeriod(policy.PeriodSeconds, scaleUpEvents, now) +
+			replicasChangedWithinPeriod(policy.PeriodSeconds, scaleDownEvents, now)
+		var proposed int32
+		switch policy.Type {
+		case autoscalingv2.PodsScalingPolicy:
+			proposed = periodStartReplicas + policy.Value
+		case autoscalingv2.PercentScalingPolicy:
+			// rounded up, so a small target can still scale up by a percentage.
+			proposed = int32(math.Ceil(float64(periodStartReplicas) * (1 + float64(policy.Value)/100)))
+		default:
+			klog.InfoS("ignoring unknown scale up policy type", "type", policy.Type)
+			continue
+		}
+		if policySelect == autoscalingv2.MinChangePolicySelect {
+			result = min(result, proposed)
+		} else {
+			result = max(result, proposed)
+		}
+	}
+	return result
+}
+
+// scaleDownLimit returns the lowest replicas the policies allow to scale down to, relative to the replicas at the start
+// of each policy period.
+func scaleDownLimit(currentReplicas int32, scaleUpEvents, scaleDownEvents []timestampedScaleEvent, rules *autoscalingv2.HPAScalingRules, now time.Time) int32 {
+	if len(rules.Policies) == 0 {
+		return math.MinInt32
+	}
+	policySelect := selectPolicy(rules)
+	if policySelect == autoscalingv2.DisabledPolicySelect {
+		return currentReplicas
+	}
+	result := int32(math.MaxInt32)
+	if policySelect == autoscalingv2.MinChangePolicySelect {
+		result = math.MinInt32
+	}
+	for _, policy := range rules.Policies {
+		periodStartReplicas := currentReplicas - replicasChangedWithinPeriod(policy.PeriodSeconds, scaleUpEvents, now) +
+			replicasChangedWithinPeriod(policy.PeriodSeconds, scaleDownEvents, now)
+		var proposed int32
+		switch policy.Type {
+		case autoscalingv2.PodsScalingPolicy:
+			proposed = periodStartReplicas - policy.Value
+		case autoscalingv2.PercentScalingPolicy:
+			proposed = int32(float64(periodStartReplicas) * (1 - float64(policy.Value)/100))
+		default:
+			klog.InfoS("ignoring unknown scale down policy type", "type", policy.Type)
+			continue
+		}
+		if policySelect == autoscalingv2.MinChangePolicySelect {
+			result = max(result, proposed)
+		} else {
+			result = min(result, proposed)
+		}
+	}
+	return result
+}
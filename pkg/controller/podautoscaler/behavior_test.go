@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+func TestBehaviorStabilizationWindow(t *testing.T) {
+	key := types.NamespacedName{Namespace: "default", Name: "llama"}
+	behavior := &autoscalingv1alpha1.PodAutoscalerBehavior{
+		ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: ptr.To[int32](300)},
+	}
+	h := newBehaviorHistory()
+	now := time.Now()
+
+	replicas, _ := h.normalize(key, behavior, 5, 5, now)
+	assert.Equal(t, int32(5), replicas)
+	// the dip is held back by the higher recommendation within the window.
+	replicas, reason := h.normalize(key, behavior, 5, 2, now.Add(time.Minute))
+	assert.Equal(t, int32(5), replicas)
+	assert.Equal(t, "stabilization window", reason)
+	// scaling up is not stabilized without a scale up window.
+	replicas, reason = h.normalize(key, behavior, 5, 8, now.Add(2*time.Minute))
+	assert.Equal(t, int32(8), replicas)
+	assert.Empty(t, reason)
+	// once the higher recommendations left the window, the target scales down to the highest one within it.
+	replicas, _ = h.normalize(key, behavior, 8, 2, now.Add(8*time.Minute))
+	assert.Equal(t, int32(2), replicas)
+}
+
+func TestBehaviorScaleUpPolicies(t *testing.T) {
+	key := types.NamespacedName{Namespace: "default", Name: "llama"}
+	behavior := &autoscalingv1alpha1.PodAutoscalerBehavior{
+		ScaleUp: &autoscalingv2.HPAScalingRules{
+			Policies: []autoscalingv2.HPAScalingPolicy{
+				{Type: autoscalingv2.PodsScalingPolicy, Value: 2, PeriodSeconds: 60},
+				{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 60},
+			},
+		},
+	}
+	h := newBehaviorHistory()
+	now := time.Now()
+
+	// the policy allowing the highest change is selected by default.
+	replicas, reason := h.normalize(key, behavior, 1, 10, now)
+	assert.Equal(t, int32(3), replicas)
+	assert.Equal(t, "scale up policy", reason)
+	h.recordScaleEvent(key, behavior, 1, 3, now)
+
+	// the replicas added within the period count against the policies.
+	replicas, _ = h.normalize(key, behavior, 3, 10, now.Add(30*time.Second))
+	assert.Equal(t, int32(3), replicas)
+	replicas, _ = h.normalize(key, behavior, 3, 10, now.Add(90*time.Second))
+	assert.Equal(t, int32(6), replicas)
+
+	behavior.ScaleUp.SelectPolicy = ptr.To(autoscalingv2.MinChangePolicySelect)
+	replicas, _ = h.normalize(key, behavior, 3, 10, now.Add(90*time.Second))
+	assert.Equal(t, int32(5), replicas)
+
+	behavior.ScaleUp.SelectPolicy = ptr.To(autoscalingv2.DisabledPolicySelect)
+	replicas, _ = h.normalize(key, behavior, 3, 10, now.Add(90*time.Second))
+	assert.Equal(t, int32(3), replicas)
+}
+
+func TestBehaviorScaleDownPolicies(t *testing.T) {
+	key := types.NamespacedName{Namespace: "default", Name: "llama"}
+	behavior := &autoscalingv1alpha1.PodAutoscalerBehavior{
+		ScaleDown: &autoscalingv2.HPAScalingRules{
+			StabilizationWindowSeconds: ptr.To[int32](0),
+			Policies: []autoscalingv2.HPAScalingPolicy{
+				{Type: autoscalingv2.PercentScalingPolicy, Value: 50, PeriodSeconds: 60},
+			},
+		},
+	}
+	h := newBehaviorHistory()
+	now := time.Now()
+
+	replicas, reason := h.normalize(key, behavior, 10, 1, now)
+	assert.Equal(t, int32(5), replicas)
+	assert.Equal(t, "scale down policy", reason)
+	h.recordScaleEvent(key, behavior, 10, 5, now)
+
+	replicas, _ = h.normalize(key, behavior, 5, 1, now.Add(30*time.Second))
+	assert.Equal(t, int32(5), replicas)
+	replicas, _ = h.normalize(key, behavior, 5, 1, now.Add(90*time.Second))
+	assert.Equal(t, int32(2), replicas)
+
+	h.delete(key)
+	assert.NotContains(t, h.recommendations, key)
+	assert.NotContains(t, h.scaleDownEvents, key)
+}
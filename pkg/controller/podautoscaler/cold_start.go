@@ -0,0 +1,107 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/common"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/profiling"
+)
+
+const (
+	// coldStartPaddingAnnotation scales up ahead for the time new pods of the model take to serve, as measured by the
+	// gateway, with "true".
+	coldStartPaddingAnnotation = common.AutoscalingLabelPrefix + "cold-start-padding"
+)
+
+// coldStart returns the cold start estimate of the model of the pa, false if padding is disabled or no pod of the model
+// was seen starting yet.
+func (r *PodAutoscalerReconciler) coldStart(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler) (time.Duration, bool) {
+	if pa.Annotations[coldStartPaddingAnnotation] != "true" || r.coldStartStore == nil {
+		return 0, false
+	}
+	model := pa.Labels[modelIdentifierLabel]
+	if model == "" {
+		klog.InfoS("cold start padding requires the model label", "PodAutoscaler", klog.KObj(pa), "label", modelIdentifierLabel)
+		return 0, false
+	}
+	coldStart, err := profiling.LoadColdStart(ctx, r.coldStartStore, model)
+	if err != nil {
+		if !errors.Is(err, kvstore.ErrNotFound) {
+			klog.ErrorS(err, "failed to read the cold start estimate", "PodAutoscaler", klog.KObj(pa), "model", model)
+		}
+		return 0, false
+	}
+	if coldStart.Seconds <= 0 {
+		return 0, false
+	}
+	return time.Duration(coldStart.Seconds * float64(time.Second)), true
+}
+
+// padForColdStart returns the recommendation raised by its growth over the last cold start, which is the growth
+// expected until pods started now serve, false if padding is disabled.
+func (r *PodAutoscalerReconciler) padForColdStart(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, recommendation int32, now time.Time) (int32, bool) {
+	coldStart, ok := r.coldStart(ctx, pa)
+	if !ok || r.coldStartHistory == nil {
+		return recommendation, false
+	}
+	growth := r.coldStartHistory.growth(types.NamespacedName{Namespace: pa.Namespace, Name: pa.Name}, recommendation, coldStart, now)
+	return recommendation + max(growth, 0), true
+}
+
+// recommendationHistory keeps the recent metric recommendations of each pa with cold start padding.
+type recommendationHistory struct {
+	mu              sync.Mutex
+	recommendations map[types.NamespacedName][]timestampedRecommendation
+}
+
+func newRecommendationHistory() *recommendationHistory {
+	return &recommendationHistory{recommendations: map[types.NamespacedName][]timestampedRecommendation{}}
+}
+
+// growth records the recommendation and returns how much it grew since the oldest recommendation within the window.
+func (h *recommendationHistory) growth(key types.NamespacedName, recommendation int32, window time.Duration, now time.Time) int32 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := now.Add(-window)
+	recommendations := h.recommendations[key][:0]
+	for _, rec := range h.recommendations[key] {
+		if !rec.timestamp.Before(cutoff) {
+			recommendations = append(recommendations, rec)
+		}
+	}
+	h.recommendations[key] = append(recommendations, timestampedRecommendation{recommendation: recommendation, timestamp: now})
+	return recommendation - h.recommendations[key][0].recommendation
+}
+
+// delete drops the history of a deleted pa.
+func (h *recommendationHistory) delete(key types.NamespacedName) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.recommendations, key)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/profiling"
+)
+
+func TestPadForColdStart(t *testing.T) {
+	ctx := context.Background()
+	store := kvstore.NewMemoryStore()
+	r := &PodAutoscalerReconciler{coldStartStore: store, coldStartHistory: newRecommendationHistory()}
+	pa := &autoscalingv1alpha1.PodAutoscaler{ObjectMeta: metav1.ObjectMeta{
+		Name: "llama", Namespace: "default",
+		Labels:      map[string]string{modelIdentifierLabel: "llama"},
+		Annotations: map[string]string{coldStartPaddingAnnotation: "true"},
+	}}
+	now := time.Now()
+
+	// without an estimate the recommendation is not padded.
+	_, ok := r.padForColdStart(ctx, pa, 2, now)
+	assert.False(t, ok)
+
+	coldStart := profiling.ColdStart{Model: "llama"}
+	coldStart.Add(profiling.ColdStartSample{Pod: "llama-0", TotalSeconds: 120})
+	assert.NoError(t, profiling.SaveColdStart(ctx, store, coldStart))
+	estimate, ok := r.coldStart(ctx, pa)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, estimate)
+
+	replicas, ok := r.padForColdStart(ctx, pa, 2, now)
+	assert.True(t, ok)
+	assert.Equal(t, int32(2), replicas)
+	// the recommendation grew by 3 within the cold start, 3 more are expected by the time new pods serve.
+	replicas, _ = r.padForColdStart(ctx, pa, 5, now.Add(time.Minute))
+	assert.Equal(t, int32(8), replicas)
+	// the recommendation of 2 is older than the cold start.
+	replicas, _ = r.padForColdStart(ctx, pa, 6, now.Add(150*time.Second))
+	assert.Equal(t, int32(7), replicas)
+	// a decreasing recommendation is not padded.
+	replicas, _ = r.padForColdStart(ctx, pa, 4, now.Add(160*time.Second))
+	assert.Equal(t, int32(4), replicas)
+
+	delete(pa.Annotations, coldStartPaddingAnnotation)
+	_, ok = r.padForColdStart(ctx, pa, 4, now)
+	assert.False(t, ok)
+}
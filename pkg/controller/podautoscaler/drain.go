@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/common"
+	podutil "github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// scaleDownDrainTimeoutAnnotation drains the pods removed by a scale-down before the target is scaled down, for at
+	// most the timeout, e.g. "5m". The gateway stops routing to the pods and the target is scaled down once the requests
+	// in-flight on them completed.
+	scaleDownDrainTimeoutAnnotation = common.AutoscalingLabelPrefix + "scale-down-drain-timeout"
+	// podDrainAnnotation marks a pod to remove on scale-down with the time it was marked in RFC3339, the gateway stops
+	// routing to it.
+	podDrainAnnotation = common.AutoscalingLabelPrefix + "drain"
+	// podDeletionCostAnnotation makes the ReplicaSet remove the marked pods first, below the costs set by the gateway.
+	podDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
+	drainedPodDeletionCost    = "-2147483648"
+
+	gatewayDrainEndpointEnv     = "AIBRIX_GATEWAY_DRAIN_ENDPOINT"
+	defaultGatewayDrainEndpoint = "http://aibrix-gateway-plugins.aibrix-system:8080/drain"
+)
+
+var drainClient = &http.Client{Timeout: 5 * time.Second}
+
+// scaleDownDrainTimeout returns how long the pods removed by a scale-down are drained, false if they are not.
+func scaleDownDrainTimeout(pa *autoscalingv1alpha1.PodAutoscaler) (time.Duration, bool) {
+	value, ok := pa.Annotations[scaleDownDrainTimeoutAnnotation]
+	if !ok {
+		return 0, false
+	}
+	if len(pa.Spec.Pools) > 0 || pa.Spec.RayWorkerGroup != "" {
+		klog.InfoS("ignoring scale-down drain timeout, it requires a scale target without pools or worker group", "PodAutoscaler", klog.KObj(pa))
+		return 0, false
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		klog.ErrorS(err, "invalid scale-down drain timeout", "PodAutoscaler", klog.KObj(pa), "value", value)
+		return 0, false
+	}
+	return timeout, true
+}
+
+// drainBeforeScaleDown marks the pods the scale-down from currentReplicas to desiredReplicas removes, and returns true
+// once they are drained or the drain timeout passed, so the target can be scaled down. Pods marked for a cancelled
+// scale-down are released, the pods of a finished one stay marked until they are deleted.
+func (r *PodAutoscalerReconciler) drainBeforeScaleDown(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, scale *unstructured.Unstructured,
+	currentReplicas, desiredReplicas int32, now time.Time) (bool, error) {
+	timeout, ok := scaleDownDrainTimeout(pa)
+	if !ok {
+		return true, nil
+	}
+	selector, err := extractLabelSelector(scale)
+	if err != nil {
+		return false, err
+	}
+	podList, err := podutil.GetPodListByLabelSelector(ctx, r.Client, pa.Namespace, selector)
+	if err != nil {
+		return false, err
+	}
+	var marked, unmarked []*corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := pod.Annotations[podDrainAnnotation]; ok {
+			marked = append(marked, pod)
+		} else {
+			unmarked = append(unmarked, pod)
+		}
+	}
+
+	victims := int(currentReplicas - desiredReplicas)
+	keep := max(victims, min(len(marked)+len(unmarked)-int(currentReplicas), len(marked)))
+	// the pods marked first drained the longest.
+	sort.SliceStable(marked, func(i, j int) bool { return drainStart(marked[i]).Before(drainStart(marked[j])) })
+	for len(marked) > max(keep, 0) {
+		pod := marked[len(marked)-1]
+		if err := r.patchPodDrain(ctx, pod, ""); err != nil {
+			return false, err
+		}
+		klog.InfoS("released pod from scale-down drain", "PodAutoscaler", klog.KObj(pa), "pod", pod.Name)
+		marked = marked[:len(marked)-1]
+	}
+	if victims <= 0 {
+		return true, nil
+	}
+
+	drained := true
+	sortDrainCandidates(unmarked)
+	for i := 0; i < victims-len(marked) && i < len(unmarked); i++ {
+		pod := unmarked[i]
+		if err := r.patchPodDrain(ctx, pod, now.UTC().Format(time.RFC3339)); err != nil {
+			return false, err
+		}
+		r.EventRecorder.Eventf(pa, corev1.EventTypeNormal, "DrainingPod", "Draining pod %s before scaling down", pod.Name)
+		// the gateway has to see the mark before its in-flight requests tell whether the pod is drained.
+		drained = false
+	}
+	for _, pod := range marked {
+		if now.Sub(drainStart(pod)) >= timeout {
+			continue
+		}
+		if !r.podDrained(ctx, pod.Name) {
+			drained = false
+		}
+	}
+	return drained, nil
+}
+
+// sortDrainCandidates orders the pods from the best to remove: not ready first, then by the deletion cost the gateway
+// set, then the most recent.
+func sortDrainCandidates(pods []*corev1.Pod) {
+	sort.SliceStable(pods, func(i, j int) bool {
+		if readyI, readyJ := podutil.IsPodReady(pods[i]), podutil.IsPodReady(pods[j]); readyI != readyJ {
+			return !readyI
+		}
+		if costI, costJ := podDeletionCost(pods[i]), podDeletionCost(pods[j]); costI != costJ {
+			return costI < costJ
+		}
+		return pods[i].CreationTimestamp.After(pods[j].CreationTimestamp.Time)
+	})
+}
+
+func podDeletionCost(pod *corev1.Pod) int64 {
+	cost, err := strconv.ParseInt(pod.Annotations[podDeletionCostAnnotation], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return cost
+}
+
+// drainStart returns the time the pod was marked, the zero time if the mark is invalid so the pod is not waited for.
+func drainStart(pod *corev1.Pod) time.Time {
+	start, err := time.Parse(time.RFC3339, pod.Annotations[podDrainAnnotation])
+	if err != nil {
+		return time.Time{}
+	}
+	return start
+}
+
+// patchPodDrain marks the pod drained since start, or releases it if start is empty.
+func (r *PodAutoscalerReconciler) patchPodDrain(ctx context.Context, pod *corev1.Pod, start string) error {
+	original := pod.DeepCopy()
+	if start == "" {
+		delete(pod.Annotations, podDrainAnnotation)
+		// the gateway sets the cost again if it ranks the pods.
+		delete(pod.Annotations, podDeletionCostAnnotation)
+	} else {
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[podDrainAnnotation] = start
+		pod.Annotations[podDeletionCostAnnotation] = drainedPodDeletionCost
+	}
+	if err := r.Patch(ctx, pod, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("failed to patch pod %s: %v", pod.Name, err)
+	}
+	return nil
+}
+
+// podDrained asks the gateway whether requests are still in-flight on the pod, a pod is not drained while the gateway
+// can't tell.
+func (r *PodAutoscalerReconciler) podDrained(ctx context.Context, podName string) bool {
+	if r.drainEndpoint == "" {
+		return false
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.drainEndpoint+"?pod="+url.QueryEscape(podName), nil)
+	if err != nil {
+		klog.ErrorS(err, "invalid gateway drain endpoint", "endpoint", r.drainEndpoint)
+		return false
+	}
+	resp, err := drainClient.Do(req)
+	if err != nil {
+		klog.ErrorS(err, "failed to get the drain status of the pod from the gateway", "pod", podName)
+		return false
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			klog.ErrorS(err, "error closing response body")
+		}
+	}()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true
+	case http.StatusServiceUnavailable:
+		return false
+	default:
+		klog.InfoS("unexpected drain status of the pod from the gateway", "pod", podName, "status", resp.Status)
+		return false
+	}
+}
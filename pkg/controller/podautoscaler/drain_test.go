@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+func TestDrainBeforeScaleDown(t *testing.T) {
+	inflight := map[string]bool{}
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inflight[r.URL.Query().Get("pod")] {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer gateway.Close()
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	busy := newTestReadyPod("llama-1", "llama")
+	busy.Annotations = map[string]string{podDeletionCostAnnotation: "100"}
+	idle := newTestReadyPod("llama-2", "llama")
+	idle.Annotations = map[string]string{podDeletionCostAnnotation: "3"}
+	r := &PodAutoscalerReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(busy, idle,
+			newTestReadyPod("llama-3", "llama")).Build(),
+		EventRecorder: record.NewFakeRecorder(10),
+		drainEndpoint: gateway.URL,
+	}
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default",
+			Annotations: map[string]string{scaleDownDrainTimeoutAnnotation: "5m"}},
+	}
+	scale := newTestPoolScale("llama", 3)
+	ctx := context.Background()
+	now := time.Now()
+	getPod := func(name string) *corev1.Pod {
+		pod := &corev1.Pod{}
+		assert.NoError(t, r.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, pod))
+		return pod
+	}
+
+	// the pods of the lowest deletion cost are marked, the gateway has to see the marks first.
+	drained, err := r.drainBeforeScaleDown(ctx, pa, scale, 3, 1, now)
+	assert.NoError(t, err)
+	assert.False(t, drained)
+	assert.Contains(t, getPod("llama-2").Annotations, podDrainAnnotation)
+	assert.Equal(t, drainedPodDeletionCost, getPod("llama-3").Annotations[podDeletionCostAnnotation])
+	assert.NotContains(t, getPod("llama-1").Annotations, podDrainAnnotation)
+
+	inflight["llama-3"] = true
+	drained, err = r.drainBeforeScaleDown(ctx, pa, scale, 3, 1, now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.False(t, drained)
+
+	// a pod still busy at the timeout doesn't hold the scale-down.
+	drained, err = r.drainBeforeScaleDown(ctx, pa, scale, 3, 1, now.Add(5*time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, drained)
+
+	// a smaller scale-down releases a marked pod.
+	drained, err = r.drainBeforeScaleDown(ctx, pa, scale, 3, 2, now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.True(t, drained)
+	assert.Contains(t, getPod("llama-2").Annotations, podDrainAnnotation)
+	assert.NotContains(t, getPod("llama-3").Annotations, podDrainAnnotation)
+	assert.NotContains(t, getPod("llama-3").Annotations, podDeletionCostAnnotation)
+
+	// without a scale-down all pods are released.
+	drained, err = r.drainBeforeScaleDown(ctx, pa, scale, 3, 3, now)
+	assert.NoError(t, err)
+	assert.True(t, drained)
+	for _, name := range []string{"llama-1", "llama-2", "llama-3"} {
+		assert.NotContains(t, getPod(name).Annotations, podDrainAnnotation)
+	}
+}
+
+func TestSortDrainCandidates(t *testing.T) {
+	older := newTestReadyPod("older", "llama")
+	older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	newer := newTestReadyPod("newer", "llama")
+	newer.CreationTimestamp = metav1.NewTime(time.Now())
+	expensive := newTestReadyPod("expensive", "llama")
+	expensive.Annotations = map[string]string{podDeletionCostAnnotation: "50"}
+	unready := newTestReadyPod("unready", "llama")
+	unready.Annotations = map[string]string{podDeletionCostAnnotation: "1000"}
+	unready.Status.Conditions = nil
+
+	pods := []*corev1.Pod{expensive, older, unready, newer}
+	sortDrainCandidates(pods)
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	assert.Equal(t, []string{"unready", "newer", "older", "expensive"}, names)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forecast
+
+import (
+	"math"
+	"time"
+)
+
+// dailyLags and weeklyLags are the seasons a forecast looks back at, the same time on the last days and weeks.
+// Weekly lags weigh more as they also capture weekday and weekend patterns.
+var (
+	dailyLags  = []time.Duration{24 * time.Hour, 2 * 24 * time.Hour, 3 * 24 * time.Hour, 4 * 24 * time.Hour, 5 * 24 * time.Hour, 6 * 24 * time.Hour}
+	weeklyLags = []time.Duration{7 * 24 * time.Hour, 14 * 24 * time.Hour, 21 * 24 * time.Hour, 28 * 24 * time.Hour}
+)
+
+const (
+	weeklyLagWeight = 2
+	// the trend of the recent traffic against its season is clamped, so a quiet or busy hour doesn't dominate a peak.
+	minTrend = 0.5
+	maxTrend = 2
+)
+
+// History is the number of requests of a model per slot of SlotDuration, keyed by the unix time the slot starts.
+type History map[int64]float64
+
+// rate returns the request rate per second of the slot containing t, false if the slot is before the history starts.
+func (h History) rate(t time.Time, first int64) (float64, bool) {
+	slot := slotStart(t)
+	if slot < first {
+		return 0, false
+	}
+	// slots without requests are not recorded.
+	return h[slot] / SlotDuration.Seconds(), true
+}
+
+func (h History) first() int64 {
+	first := int64(math.MaxInt64)
+	for slot := range h {
+		first = min(first, slot)
+	}
+	return first
+}
+
+// seasonal returns the weighted average rate at the same time of past days and weeks.
+func (h History) seasonal(t time.Time, first int64) (float64, bool) {
+	sum, weights := 0.0, 0.0
+	add := func(lags []time.Duration, weight float64) {
+		for _, lag := range lags {
+			if rate, ok := h.rate(t.Add(-lag), first); ok {
+				sum += rate * weight
+				weights += weight
+			}
+		}
+	}
+	add(dailyLags, 1)
+	add(weeklyLags, weeklyLagWeight)
+	if weights == 0 {
+		return 0, false
+	}
+	return sum / weights, true
+}
+
+// Forecast predicts the request rate per second at now+lead from the history: the seasonal rate at that time, scaled
+// by the trend of the recent rate against the seasonal rate now, so traffic growing since last week is accounted for.
+// It returns false if the history has no season of at least a day.
+func Forecast(h History, now time.Time, lead time.Duration) (float64, bool) {
+	if len(h) == 0 {
+		return 0, false
+	}
+	first := h.first()
+	ahead, ok := h.seasonal(now.Add(lead), first)
+	if !ok {
+		return 0, false
+	}
+
+	// the slot containing now is incomplete, the trend compares the two slots before with their seasons.
+	recent, season := 0.0, 0.0
+	for i := 1; i <= 2; i++ {
+		t := now.Add(-time.Duration(i) * SlotDuration)
+		rate, ok := h.rate(t, first)
+		if !ok {
+			continue
+		}
+		if seasonal, ok := h.seasonal(t, first); ok {
+			recent += rate
+			season += seasonal
+		}
+	}
+	if season == 0 {
+		return ahead, true
+	}
+	trend := math.Max(minTrend, math.Min(maxTrend, recent/season))
+	return ahead * trend, true
+}
+
+func slotStart(t time.Time) int64 {
+	return t.Unix() - t.Unix()%int64(SlotDuration/time.Second)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+// dailyPattern records 100 requests per slot, except 1000 between 9:00 and 10:00, for the given days before now.
+func dailyPattern(now time.Time, days int, scale float64) History {
+	h := History{}
+	for t := now.Add(-time.Duration(days) * 24 * time.Hour); t.Before(now); t = t.Add(SlotDuration) {
+		requests := 100.0
+		if t.UTC().Hour() == 9 {
+			requests = 1000
+		}
+		h[slotStart(t)] = requests * scale
+	}
+	return h
+}
+
+func TestForecast(t *testing.T) {
+	now := time.Date(2024, 3, 11, 8, 50, 0, 0, time.UTC)
+
+	_, ok := Forecast(History{}, now, 15*time.Minute)
+	assert.False(t, ok)
+	_, ok = Forecast(History{slotStart(now.Add(-time.Hour)): 100}, now, 15*time.Minute)
+	assert.False(t, ok, "less than a day of history has no season")
+
+	h := dailyPattern(now, 14, 1)
+	rate, ok := Forecast(h, now, 15*time.Minute)
+	require.True(t, ok)
+	assert.InDelta(t, 1000/SlotDuration.Seconds(), rate, 1e-9, "the peak at 9:00 is forecast ahead")
+
+	rate, ok = Forecast(h, now, 5*time.Minute)
+	require.True(t, ok)
+	assert.InDelta(t, 100/SlotDuration.Seconds(), rate, 1e-9)
+
+	// traffic doubled today, the trend scales the seasonal peak.
+	h[slotStart(now.Add(-SlotDuration))] = 200
+	h[slotStart(now.Add(-2*SlotDuration))] = 200
+	rate, ok = Forecast(h, now, 15*time.Minute)
+	require.True(t, ok)
+	assert.InDelta(t, 2000/SlotDuration.Seconds(), rate, 1e-9)
+
+	// the trend is clamped.
+	h[slotStart(now.Add(-SlotDuration))] = 10000
+	h[slotStart(now.Add(-2*SlotDuration))] = 10000
+	rate, ok = Forecast(h, now, 15*time.Minute)
+	require.True(t, ok)
+	assert.InDelta(t, maxTrend*1000/SlotDuration.Seconds(), rate, 1e-9)
+}
+
+func TestForecastWeeklySeason(t *testing.T) {
+	now := time.Date(2024, 3, 11, 8, 50, 0, 0, time.UTC)
+	h := dailyPattern(now, 28, 1)
+	// the peak only happened a week ago, i.e. on the same weekday.
+	for lag := 1; lag <= 27; lag++ {
+		if lag%7 != 0 {
+			h[slotStart(now.Add(15*time.Minute-time.Duration(lag)*24*time.Hour))] = 100
+		}
+	}
+	rate, ok := Forecast(h, now, 15*time.Minute)
+	require.True(t, ok)
+	// 6 daily lags at 100 and 4 weekly lags at 1000 with their weight.
+	expected := (6*100.0 + 4*1000*weeklyLagWeight) / (6 + 4*weeklyLagWeight) / SlotDuration.Seconds()
+	assert.InDelta(t, expected, rate, 1e-9)
+}
+
+func TestWindowRequests(t *testing.T) {
+	requests, err := windowRequests([]byte(`{"meta_v": 3, "meta_total_reqs": 7, "93:70": 4}`))
+	require.NoError(t, err)
+	assert.Equal(t, 7, requests)
+
+	requests, err = windowRequests([]byte(`{"93:70": 4, "100:60": 2}`))
+	require.NoError(t, err)
+	assert.Equal(t, 6, requests, "v1 traces have no totals")
+
+	_, err = windowRequests([]byte(`not json`))
+	assert.Error(t, err)
+
+	data, err := config.EncodeRequestTrace(map[string]int{"meta_v": 3, "meta_total_reqs": 9, "93:70": 4}, config.RequestTraceEncodingBinary)
+	require.NoError(t, err)
+	requests, err = windowRequests(data)
+	require.NoError(t, err)
+	assert.Equal(t, 9, requests)
+}
+
+func TestSlotWindows(t *testing.T) {
+	slot := time.Unix(1700000100, 0)
+	windows := slotWindows(slot, 10*time.Second)
+	assert.Len(t, windows, 30)
+	assert.Equal(t, int64(1700000100), windows[0])
+
+	// windows are aligned on the epoch, not on the slot.
+	windows = slotWindows(slot, 7*time.Second)
+	assert.Equal(t, int64(1700000106), windows[0])
+	assert.Equal(t, int64(1700000393), windows[len(windows)-1])
+	assert.Len(t, windows, 42)
+}
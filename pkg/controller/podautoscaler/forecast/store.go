@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forecast
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+const (
+	// SlotDuration is the resolution of the request history.
+	SlotDuration = 5 * time.Minute
+	// HistoryRetention keeps four weeks of history for weekly seasonality, with a day of margin.
+	HistoryRetention = 29 * 24 * time.Hour
+
+	metaKeyTotalRequests = "meta_total_reqs"
+)
+
+// Store rolls the request traces the gateway writes every interval, which expire after their ttl (10 seconds and
+// 10 minutes by default), up into a request history per model in Redis. The history is kept long enough to forecast
+// daily and weekly seasons.
+type Store struct {
+	client redis.UniversalClient
+}
+
+func NewStore(client redis.UniversalClient) *Store {
+	return &Store{client: client}
+}
+
+func historyKey(model string) string {
+	return fmt.Sprintf("aibrix:%s_request_history", model)
+}
+
+// Sync records the slots of the model whose trace windows all ended and did not expire yet. Slots are recomputed
+// from the traces, so syncing a slot again, e.g. from another controller replica, is harmless.
+func (s *Store) Sync(ctx context.Context, model string, now time.Time) error {
+	traceConfig := s.traceConfig(ctx)
+	// the gateway takes up to an interval to write the trace of a window after it ended.
+	writeDelay := 2 * traceConfig.Interval
+	oldest := now.Add(-traceConfig.TTL + writeDelay)
+	slot := time.Unix(slotStart(oldest), 0)
+	if slot.Before(oldest) {
+		slot = slot.Add(SlotDuration)
+	}
+
+	updates := map[string]interface{}{}
+	for ; !slot.Add(SlotDuration + writeDelay).After(now); slot = slot.Add(SlotDuration) {
+		requests, err := s.slotRequests(ctx, model, slot, traceConfig)
+		if err != nil {
+			return err
+		}
+		updates[strconv.FormatInt(slot.Unix(), 10)] = requests
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	key := historyKey(model)
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, key, updates)
+	pipe.Expire(ctx, key, HistoryRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	return s.trim(ctx, model, now)
+}
+
+// traceConfig returns how the gateway writes the traces, read from the meta key it writes next to them, or the local
+// configuration until there is one.
+func (s *Store) traceConfig(ctx context.Context) config.RequestTraceConfig {
+	traceConfig := config.RequestTrace()
+	data, err := s.client.Get(ctx, traceConfig.MetaKey()).Bytes()
+	if err != nil {
+		return traceConfig
+	}
+	written, err := config.ParseRequestTraceMeta(data)
+	if err != nil {
+		klog.ErrorS(err, "ignoring invalid request trace meta", "key", traceConfig.MetaKey())
+		return traceConfig
+	}
+	return written
+}
+
+// slotRequests sums the requests of the trace windows in the slot, windows without requests are not written.
+func (s *Store) slotRequests(ctx context.Context, model string, slot time.Time, traceConfig config.RequestTraceConfig) (int, error) {
+	var keys []string
+	for _, window := range slotWindows(slot, traceConfig.Interval) {
+		keys = append(keys, traceConfig.Key(model, window))
+	}
+	// the windows hash to different slots, so they are read with a pipeline rather than MGET to support Redis Cluster.
+	pipe := s.client.Pipeline()
+	gets := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		gets[i] = pipe.Get(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, err
+	}
+	total := 0
+	for i, get := range gets {
+		data, err := get.Bytes()
+		if err != nil {
+			continue // expired or never written
+		}
+		requests, err := windowRequests(data)
+		if err != nil {
+			klog.ErrorS(err, "ignoring invalid request trace", "key", keys[i])
+			continue
+		}
+		total += requests
+	}
+	return total, nil
+}
+
+// slotWindows returns the timestamps of the trace windows in the slot, windows are aligned on the unix epoch.
+func slotWindows(slot time.Time, interval time.Duration) []int64 {
+	step := int64(interval / time.Second)
+	start, end := slot.Unix(), slot.Add(SlotDuration).Unix()
+	var windows []int64
+	for window := (start + step - 1) / step * step; window < end; window += step {
+		windows = append(windows, window)
+	}
+	return windows
+}
+
+// windowRequests returns the requests of a trace window, the buckets only count completed requests before v3.
+func windowRequests(data []byte) (int, error) {
+	trace, err := config.DecodeRequestTrace(data)
+	if err != nil {
+		return 0, err
+	}
+	if total, ok := trace[metaKeyTotalRequests]; ok {
+		return total, nil
+	}
+	total := 0
+	for key, count := range trace {
+		if len(key) < 5 || key[:5] != "meta_" {
+			total += count
+		}
+	}
+	return total, nil
+}
+
+// trim drops slots older than the retention, the key itself only expires once no slot is synced for the retention.
+func (s *Store) trim(ctx context.Context, model string, now time.Time) error {
+	slots, err := s.client.HKeys(ctx, historyKey(model)).Result()
+	if err != nil {
+		return err
+	}
+	var expired []string
+	for _, slot := range slots {
+		start, err := strconv.ParseInt(slot, 10, 64)
+		if err != nil || now.Sub(time.Unix(start, 0)) > HistoryRetention {
+			expired = append(expired, slot)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	return s.client.HDel(ctx, historyKey(model), expired...).Err()
+}
+
+// History returns the request history of the model.
+func (s *Store) History(ctx context.Context, model string) (History, error) {
+	values, err := s.client.HGetAll(ctx, historyKey(model)).Result()
+	if err != nil {
+		return nil, err
+	}
+	history := make(History, len(values))
+	for slot, value := range values {
+		start, err := strconv.ParseInt(slot, 10, 64)
+		if err != nil {
+			continue
+		}
+		requests, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		history[start] = requests
+	}
+	return history, nil
+}
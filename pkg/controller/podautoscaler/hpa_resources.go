@@ -64,6 +64,12 @@ func makeHPA(pa *pav1.PodAutoscaler) *autoscalingv2.HorizontalPodAutoscaler {
 	if minReplicas != nil && *minReplicas > 0 {
 		hpa.Spec.MinReplicas = minReplicas
 	}
+	if behavior := pa.Spec.Behavior; behavior != nil && (behavior.ScaleUp != nil || behavior.ScaleDown != nil) {
+		hpa.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{
+			ScaleUp:   behavior.ScaleUp,
+			ScaleDown: behavior.ScaleDown,
+		}
+	}
 	source, err := pav1.GetPaMetricSources(*pa)
 	if err != nil {
 		klog.ErrorS(err, "Failed to GetPaMetricSources")
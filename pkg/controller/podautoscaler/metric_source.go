@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/metrics"
+)
+
+// metricSourceCredentials reads the credentials of a metric source from the Secret of its auth, and its CA bundle from
+// the Secret of its TLS config, in the namespace of the pa.
+func (r *PodAutoscalerReconciler) metricSourceCredentials(ctx context.Context, namespace string, source autoscalingv1alpha1.MetricSource) (metrics.SourceCredentials, error) {
+	var credentials metrics.SourceCredentials
+	if source.Auth != nil {
+		var secret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: source.Auth.SecretName}, &secret); err != nil {
+			return metrics.SourceCredentials{}, fmt.Errorf("failed to get secret %s of metric source: %w", source.Auth.SecretName, err)
+		}
+		credentials.Token = string(secret.Data["token"])
+		credentials.Username = string(secret.Data["username"])
+		credentials.Password = string(secret.Data["password"])
+	}
+	if source.TLS != nil && source.TLS.CASecretName != "" {
+		var secret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: source.TLS.CASecretName}, &secret); err != nil {
+			return metrics.SourceCredentials{}, fmt.Errorf("failed to get CA secret %s of metric source: %w", source.TLS.CASecretName, err)
+		}
+		credentials.CABundle = secret.Data["ca.crt"]
+	}
+	return credentials, nil
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tidwall/gjson"
+	"k8s.io/klog/v2"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+const defaultRedisPort = "6379"
+
+// SourceCredentials are the credentials of a metric source, read from the Secret of its auth, and the CA bundle
+// verifying its certificate, read from the Secret of its TLS config.
+type SourceCredentials struct {
+	Token    string
+	Username string
+	Password string
+	CABundle []byte
+}
+
+type sourceCredentialsKey struct{}
+
+// WithSourceCredentials returns a context carrying the credentials for fetching the metric of a source.
+func WithSourceCredentials(ctx context.Context, credentials SourceCredentials) context.Context {
+	return context.WithValue(ctx, sourceCredentialsKey{}, credentials)
+}
+
+func sourceCredentialsFrom(ctx context.Context) SourceCredentials {
+	credentials, _ := ctx.Value(sourceCredentialsKey{}).(SourceCredentials)
+	return credentials
+}
+
+// setAuthHeader authenticates the request with the bearer token or, without token, the basic auth of the credentials.
+func (c SourceCredentials) setAuthHeader(req *http.Request) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	} else if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// IsExternalMetricSource returns true for sources which are read from an external system rather than a metrics endpoint.
+func IsExternalMetricSource(sourceType autoscalingv1alpha1.MetricSourceType) bool {
+	switch sourceType {
+	case autoscalingv1alpha1.KAFKA, autoscalingv1alpha1.REDIS, autoscalingv1alpha1.JSON:
+		return true
+	}
+	return false
+}
+
+// ExternalMetricsFetcher fetches metrics of the Kafka, Redis and JSON sources. It keeps an HTTP client per TLS config
+// and a Redis client per endpoint and DB, replaced when the credentials of the source change.
+type ExternalMetricsFetcher struct {
+	mu           sync.Mutex
+	clients      map[string]*http.Client
+	redisClients map[string]*sourceRedisClient
+}
+
+type sourceRedisClient struct {
+	client *redis.Client
+	// fingerprint identifies the credentials and TLS config the client was created with.
+	fingerprint string
+}
+
+func NewExternalMetricsFetcher() *ExternalMetricsFetcher {
+	return &ExternalMetricsFetcher{
+		clients:      map[string]*http.Client{},
+		redisClients: map[string]*sourceRedisClient{},
+	}
+}
+
+// sourceTLSConfig returns the TLS config of the source: the certificate is verified with the CA bundle of the
+// credentials or else the system roots, unless the source explicitly skips the verification. It also returns the
+// key of the config, identifying it in the clients of the fetcher.
+func sourceTLSConfig(source autoscalingv1alpha1.MetricSource, credentials SourceCredentials) (*tls.Config, string, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if source.TLS == nil {
+		return config, "", nil
+	}
+	if source.TLS.InsecureSkipVerify {
+		config.InsecureSkipVerify = true // nolint:gosec // explicit opt-in of the metric source
+		return config, "insecure", nil
+	}
+	if len(credentials.CABundle) == 0 {
+		return config, "", nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(credentials.CABundle) {
+		return nil, "", fmt.Errorf("CA bundle of secret %s has no valid certificate", source.TLS.CASecretName)
+	}
+	config.RootCAs = pool
+	sum := sha256.Sum256(credentials.CABundle)
+	return config, "ca-" + hex.EncodeToString(sum[:]), nil
+}
+
+// httpClient returns the HTTP client of the TLS config of the source.
+func (f *ExternalMetricsFetcher) httpClient(source autoscalingv1alpha1.MetricSource, credentials SourceCredentials) (*http.Client, error) {
+	config, key, err := sourceTLSConfig(source, credentials)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	client, ok := f.clients[key]
+	if !ok {
+		client = &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+		f.clients[key] = client
+	}
+	return client, nil
+}
+
+// redisClient returns the Redis client of the endpoint and DB. The client is created again, and the previous one
+// closed, when the credentials or the TLS config of the source change.
+func (f *ExternalMetricsFetcher) redisClient(source autoscalingv1alpha1.MetricSource, endpoint string, db int32, credentials SourceCredentials) (*redis.Client, error) {
+	config, tlsKey, err := sourceTLSConfig(source, credentials)
+	if err != nil {
+		return nil, err
+	}
+	password := sha256.Sum256([]byte(credentials.Password))
+	fingerprint := strings.Join([]string{credentials.Username, hex.EncodeToString(password[:]), strconv.FormatBool(source.TLS != nil), tlsKey}, "/")
+	key := endpoint + "/" + strconv.Itoa(int(db))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if cached, ok := f.redisClients[key]; ok {
+		if cached.fingerprint == fingerprint {
+			return cached.client, nil
+		}
+		if err := cached.client.Close(); err != nil {
+			klog.ErrorS(err, "error closing redis client", "endpoint", endpoint)
+		}
+	}
+	options := &redis.Options{
+		Addr:     endpoint,
+		Username: credentials.Username,
+		Password: credentials.Password,
+		DB:       int(db),
+	}
+	if source.TLS != nil {
+		options.TLSConfig = config
+	}
+	client := redis.NewClient(options)
+	f.redisClients[key] = &sourceRedisClient{client: client, fingerprint: fingerprint}
+	return client, nil
+}
+
+var externalMetricsFetcher = NewExternalMetricsFetcher()
+
+// FetchSourceMetric fetches the metric of an external source at the endpoint.
+func (f *ExternalMetricsFetcher) FetchSourceMetric(ctx context.Context, source autoscalingv1alpha1.MetricSource, endpoint string) (float64, error) {
+	switch source.MetricSourceType {
+	case autoscalingv1alpha1.KAFKA:
+		if source.Kafka == nil {
+			return 0, fmt.Errorf("kafka must be set for metric source type %s", source.MetricSourceType)
+		}
+		client, err := f.httpClient(source, sourceCredentialsFrom(ctx))
+		if err != nil {
+			return 0, err
+		}
+		return f.fetchKafkaLag(ctx, client, source.ProtocolType, endpoint, *source.Kafka)
+	case autoscalingv1alpha1.REDIS:
+		if source.Redis == nil {
+			return 0, fmt.Errorf("redis must be set for metric source type %s", source.MetricSourceType)
+		}
+		return f.fetchRedisQueueLength(ctx, source, endpoint, *source.Redis)
+	case autoscalingv1alpha1.JSON:
+		if source.JSON == nil {
+			return 0, fmt.Errorf("json must be set for metric source type %s", source.MetricSourceType)
+		}
+		client, err := f.httpClient(source, sourceCredentialsFrom(ctx))
+		if err != nil {
+			return 0, err
+		}
+		return f.fetchJSONField(ctx, client, source.ProtocolType, endpoint, source.Path, *source.JSON)
+	default:
+		return 0, fmt.Errorf("unsupported external metric source type: %s", source.MetricSourceType)
+	}
+}
+
+// fetchKafkaLag reads the lag of the consumer group from the v3 API of a Kafka REST proxy.
+func (f *ExternalMetricsFetcher) fetchKafkaLag(ctx context.Context, client *http.Client, protocol autoscalingv1alpha1.ProtocolType, endpoint string, kafka autoscalingv1alpha1.KafkaMetricSource) (float64, error) {
+	groupPath := fmt.Sprintf("v3/clusters/%s/consumer-groups/%s", url.PathEscape(kafka.ClusterID), url.PathEscape(kafka.ConsumerGroup))
+	if kafka.Topic == "" {
+		body, err := f.get(ctx, client, sourceURL(protocol, endpoint, groupPath+"/lag-summary"))
+		if err != nil {
+			return 0, err
+		}
+		var summary struct {
+			TotalLag *float64 `json:"total_lag"`
+		}
+		if err := json.Unmarshal(body, &summary); err != nil || summary.TotalLag == nil {
+			return 0, fmt.Errorf("failed to parse lag summary of consumer group %s: %v", kafka.ConsumerGroup, err)
+		}
+		return *summary.TotalLag, nil
+	}
+
+	body, err := f.get(ctx, client, sourceURL(protocol, endpoint, groupPath+"/lags"))
+	if err != nil {
+		return 0, err
+	}
+	var lags struct {
+		Data []struct {
+			TopicName string  `json:"topic_name"`
+			Lag       float64 `json:"lag"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &lags); err != nil {
+		return 0, fmt.Errorf("failed to parse lags of consumer group %s: %v", kafka.ConsumerGroup, err)
+	}
+	totalLag := 0.0
+	for _, partition := range lags.Data {
+		if partition.TopicName == kafka.Topic {
+			totalLag += partition.Lag
+		}
+	}
+	return totalLag, nil
+}
+
+// fetchRedisQueueLength reads the length of the key, whatever Redis type the queue is built on.
+func (f *ExternalMetricsFetcher) fetchRedisQueueLength(ctx context.Context, source autoscalingv1alpha1.MetricSource, endpoint string, queue autoscalingv1alpha1.RedisMetricSource) (float64, error) {
+	if _, _, err := net.SplitHostPort(endpoint); err != nil {
+		endpoint = net.JoinHostPort(endpoint, defaultRedisPort)
+	}
+	client, err := f.redisClient(source, endpoint, queue.DB, sourceCredentialsFrom(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	keyType, err := client.Type(ctx, queue.Key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get type of redis key %s: %v", queue.Key, err)
+	}
+	var length int64
+	switch keyType {
+	case "none":
+		// an empty queue is deleted by redis.
+		return 0, nil
+	case "list":
+		length, err = client.LLen(ctx, queue.Key).Result()
+	case "stream":
+		length, err = client.XLen(ctx, queue.Key).Result()
+	case "set":
+		length, err = client.SCard(ctx, queue.Key).Result()
+	case "zset":
+		length, err = client.ZCard(ctx, queue.Key).Result()
+	default:
+		return 0, fmt.Errorf("redis key %s of type %s is not a queue", queue.Key, keyType)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get length of redis key %s: %v", queue.Key, err)
+	}
+	return float64(length), nil
+}
+
+// fetchJSONField reads the number at the field of the JSON response, numbers in strings are accepted.
+func (f *ExternalMetricsFetcher) fetchJSONField(ctx context.Context, client *http.Client, protocol autoscalingv1alpha1.ProtocolType, endpoint, path string, field autoscalingv1alpha1.JSONMetricSource) (float64, error) {
+	body, err := f.get(ctx, client, sourceURL(protocol, endpoint, path))
+	if err != nil {
+		return 0, err
+	}
+	result := gjson.GetBytes(body, field.Field)
+	switch result.Type {
+	case gjson.Number:
+		return result.Num, nil
+	case gjson.String:
+		value, err := strconv.ParseFloat(result.Str, 64)
+		if err != nil {
+			return 0, fmt.Errorf("field %s is not a number: %v", field.Field, err)
+		}
+		return value, nil
+	default:
+		return 0, fmt.Errorf("field %s not found or not a number", field.Field)
+	}
+}
+
+func (f *ExternalMetricsFetcher) get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request to source %s: %v", url, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	sourceCredentialsFrom(ctx).setAuthHeader(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metrics from source %s: %v", url, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			klog.ErrorS(err, "error closing response body")
+		}
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from source %s: %v", url, err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("source %s responded with status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+func sourceURL(protocol autoscalingv1alpha1.ProtocolType, endpoint, path string) string {
+	if protocol == "" {
+		protocol = autoscalingv1alpha1.HTTP
+	}
+	return fmt.Sprintf("%s://%s/%s", protocol, endpoint, strings.TrimLeft(path, "/"))
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+var _ = Describe("External metric sources", func() {
+	var (
+		server        *httptest.Server
+		authorization string
+		responses     map[string]string
+	)
+
+	BeforeEach(func() {
+		authorization = ""
+		responses = map[string]string{
+			"/v3/clusters/c1/consumer-groups/inference/lag-summary": `{"kind":"KafkaConsumerGroupLagSummary","max_lag":40,"total_lag":120}`,
+			"/v3/clusters/c1/consumer-groups/inference/lags": `{"data":[
+				{"topic_name":"prompts","partition_id":0,"lag":30},
+				{"topic_name":"prompts","partition_id":1,"lag":12},
+				{"topic_name":"feedback","partition_id":0,"lag":78}]}`,
+			"/api/queue": `{"data":{"queue":{"length":17,"pending":"3"}}}`,
+		}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			body, ok := responses[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(body))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	fetch := func(ctx context.Context, source autoscalingv1alpha1.MetricSource) (float64, error) {
+		source.ProtocolType = autoscalingv1alpha1.HTTP
+		source.Endpoint = strings.TrimPrefix(server.URL, "http://")
+		return GetMetricFromSource(ctx, NewRestMetricsFetcher(), source)
+	}
+
+	It("should read the lag of a kafka consumer group", func() {
+		source := autoscalingv1alpha1.MetricSource{
+			MetricSourceType: autoscalingv1alpha1.KAFKA,
+			Kafka:            &autoscalingv1alpha1.KafkaMetricSource{ClusterID: "c1", ConsumerGroup: "inference"},
+		}
+		lag, err := fetch(context.Background(), source)
+		Expect(err).To(BeNil())
+		Expect(lag).To(Equal(120.0))
+
+		source.Kafka.Topic = "prompts"
+		lag, err = fetch(context.Background(), source)
+		Expect(err).To(BeNil())
+		Expect(lag).To(Equal(42.0))
+	})
+
+	It("should read a number from a json field", func() {
+		source := autoscalingv1alpha1.MetricSource{
+			MetricSourceType: autoscalingv1alpha1.JSON,
+			Path:             "/api/queue",
+			JSON:             &autoscalingv1alpha1.JSONMetricSource{Field: "data.queue.length"},
+		}
+		value, err := fetch(context.Background(), source)
+		Expect(err).To(BeNil())
+		Expect(value).To(Equal(17.0))
+
+		source.JSON.Field = "data.queue.pending"
+		value, err = fetch(context.Background(), source)
+		Expect(err).To(BeNil())
+		Expect(value).To(Equal(3.0))
+
+		source.JSON.Field = "data.queue.missing"
+		_, err = fetch(context.Background(), source)
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should authenticate with the credentials of the source", func() {
+		source := autoscalingv1alpha1.MetricSource{
+			MetricSourceType: autoscalingv1alpha1.JSON,
+			Path:             "/api/queue",
+			JSON:             &autoscalingv1alpha1.JSONMetricSource{Field: "data.queue.length"},
+		}
+		_, err := fetch(WithSourceCredentials(context.Background(), SourceCredentials{Token: "secret"}), source)
+		Expect(err).To(BeNil())
+		Expect(authorization).To(Equal("Bearer secret"))
+
+		_, err = fetch(WithSourceCredentials(context.Background(), SourceCredentials{Username: "aibrix", Password: "pass"}), source)
+		Expect(err).To(BeNil())
+		Expect(authorization).To(HavePrefix("Basic "))
+	})
+
+	It("should fail on error responses", func() {
+		source := autoscalingv1alpha1.MetricSource{
+			MetricSourceType: autoscalingv1alpha1.KAFKA,
+			Kafka:            &autoscalingv1alpha1.KafkaMetricSource{ClusterID: "c2", ConsumerGroup: "inference"},
+		}
+		_, err := fetch(context.Background(), source)
+		Expect(err).NotTo(BeNil())
+
+		source.Kafka = nil
+		_, err = fetch(context.Background(), source)
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should verify the certificate of https sources", func() {
+		tlsServer := httptest.NewTLSServer(server.Config.Handler)
+		defer tlsServer.Close()
+		source := autoscalingv1alpha1.MetricSource{
+			MetricSourceType: autoscalingv1alpha1.JSON,
+			ProtocolType:     autoscalingv1alpha1.HTTPS,
+			Endpoint:         strings.TrimPrefix(tlsServer.URL, "https://"),
+			Path:             "/api/queue",
+			JSON:             &autoscalingv1alpha1.JSONMetricSource{Field: "data.queue.length"},
+		}
+		fetcher := NewExternalMetricsFetcher()
+
+		_, err := fetcher.FetchSourceMetric(context.Background(), source, source.Endpoint)
+		Expect(err).To(MatchError(ContainSubstring("certificate")))
+
+		source.TLS = &autoscalingv1alpha1.MetricSourceTLS{CASecretName: "ca"}
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+		value, err := fetcher.FetchSourceMetric(WithSourceCredentials(context.Background(), SourceCredentials{CABundle: ca}), source, source.Endpoint)
+		Expect(err).To(BeNil())
+		Expect(value).To(Equal(17.0))
+
+		_, err = fetcher.FetchSourceMetric(WithSourceCredentials(context.Background(), SourceCredentials{CABundle: []byte("invalid")}), source, source.Endpoint)
+		Expect(err).NotTo(BeNil())
+
+		source.TLS = &autoscalingv1alpha1.MetricSourceTLS{InsecureSkipVerify: true}
+		value, err = fetcher.FetchSourceMetric(context.Background(), source, source.Endpoint)
+		Expect(err).To(BeNil())
+		Expect(value).To(Equal(17.0))
+	})
+
+	It("should reuse the redis client of an endpoint until its credentials change", func() {
+		fetcher := NewExternalMetricsFetcher()
+		source := autoscalingv1alpha1.MetricSource{MetricSourceType: autoscalingv1alpha1.REDIS}
+		credentials := SourceCredentials{Username: "aibrix", Password: "pass"}
+
+		client, err := fetcher.redisClient(source, "redis:6379", 0, credentials)
+		Expect(err).To(BeNil())
+		Expect(fetcher.redisClient(source, "redis:6379", 0, credentials)).To(BeIdenticalTo(client))
+		other, err := fetcher.redisClient(source, "redis:6379", 1, credentials)
+		Expect(err).To(BeNil())
+		Expect(other).NotTo(BeIdenticalTo(client))
+
+		credentials.Password = "rotated"
+		rotated, err := fetcher.redisClient(source, "redis:6379", 0, credentials)
+		Expect(err).To(BeNil())
+		Expect(rotated).NotTo(BeIdenticalTo(client))
+		Expect(client.Ping(context.Background()).Err()).To(MatchError(ContainSubstring("closed")))
+		Expect(fetcher.redisClients).To(HaveLen(2))
+	})
+})
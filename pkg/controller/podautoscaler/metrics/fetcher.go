@@ -97,6 +97,7 @@ func (f *RestMetricsFetcher) FetchMetric(ctx context.Context, protocol autoscali
 	if err != nil {
 		return 0.0, fmt.Errorf("failed to create request to source %s: %v", url, err)
 	}
+	sourceCredentialsFrom(ctx).setAuthHeader(req)
 
 	// Send the request using the default client
 	resp, err := f.client.Do(req)
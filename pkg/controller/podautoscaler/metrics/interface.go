@@ -55,6 +55,42 @@ func NewNamespaceNameMetric(pa *autoscalingv1alpha1.PodAutoscaler) (NamespaceNam
 	}, metricSource, nil
 }
 
+// SLOMetricName is the metric of the SLO scaling strategy, the ratio of the observed latency percentile to its target.
+const SLOMetricName = "slo_latency_ratio"
+
+// NewNamespaceNameMetrics creates a NamespaceNameMetric for each of the PodAutoscaler's metrics sources,
+// together with the corresponding MetricSource at the same index.
+// The SLO scaling strategy takes no metrics sources, it scales on a single SLOMetricName source with a target of 1.
+func NewNamespaceNameMetrics(pa *autoscalingv1alpha1.PodAutoscaler) ([]NamespaceNameMetric, []autoscalingv1alpha1.MetricSource, error) {
+	metricSources := pa.Spec.MetricsSources
+	if pa.Spec.ScalingStrategy == autoscalingv1alpha1.SLO {
+		if pa.Spec.SLOTargets == nil {
+			return nil, nil, fmt.Errorf("slo targets must be set for the %s scaling strategy", autoscalingv1alpha1.SLO)
+		}
+		metricSources = []autoscalingv1alpha1.MetricSource{{
+			MetricSourceType: autoscalingv1alpha1.POD,
+			TargetMetric:     SLOMetricName,
+			TargetValue:      "1",
+		}}
+	}
+	if len(metricSources) == 0 {
+		return nil, nil, fmt.Errorf("metrics sources must not be empty")
+	}
+	metricKeys := make([]NamespaceNameMetric, 0, len(metricSources))
+	for _, metricSource := range metricSources {
+		metricKeys = append(metricKeys, NamespaceNameMetric{
+			NamespacedName: types.NamespacedName{
+				Namespace: pa.Namespace,
+				Name:      pa.Spec.ScaleTargetRef.Name,
+			},
+			MetricName:  metricSource.TargetMetric,
+			PaNamespace: pa.Namespace,
+			PaName:      pa.Name,
+		})
+	}
+	return metricKeys, metricSources, nil
+}
+
 // PodMetric contains pod metric value (the metric values are expected to be the metric as a milli-value)
 type PodMetric struct {
 	Timestamp time.Time
@@ -132,6 +132,9 @@ func GetMetricFromSource(ctx context.Context, fetcher MetricFetcher, source auto
 			endpoint = fmt.Sprintf("%s:%s", u.Hostname(), source.Port)
 		}
 	}
+	if IsExternalMetricSource(source.MetricSourceType) {
+		return externalMetricsFetcher.FetchSourceMetric(ctx, source, endpoint)
+	}
 	return fetcher.FetchMetric(ctx, source.ProtocolType, endpoint, source.Path, source.TargetMetric)
 }
 
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/metrics"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/scaler"
+)
+
+const (
+	// the per-metric tolerances are handed to the APA scaler of a metric through its annotations.
+	apaUpFluctuationToleranceAnnotation   = scaler.APALabelPrefix + "up-fluctuation-tolerance"
+	apaDownFluctuationToleranceAnnotation = scaler.APALabelPrefix + "down-fluctuation-tolerance"
+)
+
+// metricReplicas is the desired replicas computed by the scaler of a single metric.
+type metricReplicas struct {
+	metricName string
+	replicas   int32
+	weight     float64
+}
+
+// sourcePodAutoscaler returns a view of the PodAutoscaler with the given metric source only, scalers work on a
+// single metric so every metric of a PodAutoscaler runs its own scaler on such a view.
+func sourcePodAutoscaler(pa *autoscalingv1alpha1.PodAutoscaler, source autoscalingv1alpha1.MetricSource) autoscalingv1alpha1.PodAutoscaler {
+	view := *pa.DeepCopy()
+	view.Spec.MetricsSources = []autoscalingv1alpha1.MetricSource{source}
+	if source.UpFluctuationTolerance == "" && source.DownFluctuationTolerance == "" {
+		return view
+	}
+	if view.Annotations == nil {
+		view.Annotations = map[string]string{}
+	}
+	if source.UpFluctuationTolerance != "" {
+		view.Annotations[apaUpFluctuationToleranceAnnotation] = source.UpFluctuationTolerance
+	}
+	if source.DownFluctuationTolerance != "" {
+		view.Annotations[apaDownFluctuationToleranceAnnotation] = source.DownFluctuationTolerance
+	}
+	return view
+}
+
+// metricWeight returns the weight of the metric source for the weighted aggregation, 1 if unset or invalid.
+func metricWeight(source autoscalingv1alpha1.MetricSource) float64 {
+	if source.Weight == "" {
+		return 1
+	}
+	weight, err := strconv.ParseFloat(source.Weight, 64)
+	if err != nil || weight <= 0 {
+		klog.ErrorS(err, "invalid metric weight, falling back to 1", "metric", source.TargetMetric, "weight", source.Weight)
+		return 1
+	}
+	return weight
+}
+
+// aggregateReplicas combines the desired replicas of all metrics and returns the names of the metrics driving the result.
+func aggregateReplicas(aggregation autoscalingv1alpha1.MetricsAggregationType, results []metricReplicas) (int32, string) {
+	if len(results) == 0 {
+		return 0, ""
+	}
+	if aggregation == autoscalingv1alpha1.WeightedAggregation {
+		var weightedSum, weights float64
+		names := make([]string, 0, len(results))
+		for _, result := range results {
+			weightedSum += float64(result.replicas) * result.weight
+			weights += result.weight
+			names = append(names, result.metricName)
+		}
+		return int32(math.Ceil(weightedSum/weights - 1e-9)), fmt.Sprintf("weighted %s", strings.Join(names, ","))
+	}
+
+	// Max: every metric stays within its target.
+	desired := results[0]
+	for _, result := range results[1:] {
+		if result.replicas > desired.replicas {
+			desired = result
+		}
+	}
+	return desired.replicas, desired.metricName
+}
+
+// deleteRemovedMetricScalers removes the scalers of metrics which are no longer part of the PodAutoscaler.
+func (r *PodAutoscalerReconciler) deleteRemovedMetricScalers(pa *autoscalingv1alpha1.PodAutoscaler, metricKeys []metrics.NamespaceNameMetric) {
+	current := make(map[metrics.NamespaceNameMetric]struct{}, len(metricKeys))
+	for _, metricKey := range metricKeys {
+		current[metricKey] = struct{}{}
+	}
+	for namespaceNameMetric := range r.AutoscalerMap {
+		if namespaceNameMetric.PaNamespace != pa.Namespace || namespaceNameMetric.PaName != pa.Name {
+			continue
+		}
+		if _, ok := current[namespaceNameMetric]; !ok {
+			klog.InfoS("Delete scaler of removed metric", "PodAutoscaler", klog.KObj(pa), "metric", namespaceNameMetric.MetricName)
+			delete(r.AutoscalerMap, namespaceNameMetric)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/metrics"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/scaler"
+)
+
+func TestAggregateReplicas(t *testing.T) {
+	results := []metricReplicas{
+		{metricName: "gpu_cache_usage_perc", replicas: 2, weight: 1},
+		{metricName: "num_requests_waiting", replicas: 5, weight: 1},
+		{metricName: "avg_prompt_throughput", replicas: 3, weight: 2},
+	}
+
+	replicas, metric := aggregateReplicas(autoscalingv1alpha1.MaxAggregation, results)
+	assert.Equal(t, int32(5), replicas)
+	assert.Equal(t, "num_requests_waiting", metric)
+
+	replicas, metric = aggregateReplicas("", results)
+	assert.Equal(t, int32(5), replicas, "Max is the default")
+	assert.Equal(t, "num_requests_waiting", metric)
+
+	// (2 + 5 + 3*2) / 4 = 3.25
+	replicas, metric = aggregateReplicas(autoscalingv1alpha1.WeightedAggregation, results)
+	assert.Equal(t, int32(4), replicas)
+	assert.Equal(t, "weighted gpu_cache_usage_perc,num_requests_waiting,avg_prompt_throughput", metric)
+
+	replicas, _ = aggregateReplicas(autoscalingv1alpha1.WeightedAggregation, results[:1])
+	assert.Equal(t, int32(2), replicas)
+}
+
+func TestSourcePodAutoscaler(t *testing.T) {
+	cache := autoscalingv1alpha1.MetricSource{TargetMetric: "gpu_cache_usage_perc", TargetValue: "50", UpFluctuationTolerance: "0.2"}
+	waiting := autoscalingv1alpha1.MetricSource{TargetMetric: "num_requests_waiting", TargetValue: "10", Weight: "2"}
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default", Annotations: map[string]string{apaDownFluctuationToleranceAnnotation: "0.3"}},
+		Spec:       autoscalingv1alpha1.PodAutoscalerSpec{MetricsSources: []autoscalingv1alpha1.MetricSource{cache, waiting}},
+	}
+
+	view := sourcePodAutoscaler(pa, cache)
+	source, err := autoscalingv1alpha1.GetPaMetricSources(view)
+	assert.NoError(t, err)
+	assert.Equal(t, cache, source)
+	assert.Equal(t, "0.2", view.Annotations[apaUpFluctuationToleranceAnnotation])
+	assert.Equal(t, "0.3", view.Annotations[apaDownFluctuationToleranceAnnotation], "pa-wide tolerance applies unless overridden")
+	assert.Len(t, pa.Spec.MetricsSources, 2, "the pa is not modified")
+	assert.NotContains(t, pa.Annotations, apaUpFluctuationToleranceAnnotation)
+
+	assert.Equal(t, 1.0, metricWeight(cache))
+	assert.Equal(t, 2.0, metricWeight(waiting))
+	assert.Equal(t, 1.0, metricWeight(autoscalingv1alpha1.MetricSource{Weight: "-1"}))
+}
+
+func TestDeleteRemovedMetricScalers(t *testing.T) {
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: autoscalingv1alpha1.PodAutoscalerSpec{MetricsSources: []autoscalingv1alpha1.MetricSource{
+			{TargetMetric: "gpu_cache_usage_perc"},
+		}},
+	}
+	metricKeys, _, err := metrics.NewNamespaceNameMetrics(pa)
+	assert.NoError(t, err)
+	removed := metrics.NamespaceNameMetric{MetricName: "num_requests_waiting", PaNamespace: "default", PaName: "llama"}
+	other := metrics.NamespaceNameMetric{MetricName: "num_requests_waiting", PaNamespace: "default", PaName: "mistral"}
+	r := &PodAutoscalerReconciler{AutoscalerMap: map[metrics.NamespaceNameMetric]scaler.Scaler{
+		metricKeys[0]: nil,
+		removed:       nil,
+		other:         nil,
+	}}
+
+	r.deleteRemovedMetricScalers(pa, metricKeys)
+	assert.Contains(t, r.AutoscalerMap, metricKeys[0])
+	assert.NotContains(t, r.AutoscalerMap, removed)
+	assert.Contains(t, r.AutoscalerMap, other)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/common"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/placement"
+)
+
+const (
+	// placementAnnotation recommends the cheapest replicas of the pools for the workload of the model in the status
+	// with "recommend", and also scales the pools in the proportions of the recommendation with "apply".
+	placementAnnotation = common.AutoscalingLabelPrefix + "placement"
+	placementRecommend  = "recommend"
+	placementApply      = "apply"
+	// placementWindowAnnotation is how far back the workload of the model is profiled, e.g. "1h".
+	placementWindowAnnotation = common.AutoscalingLabelPrefix + "placement-window"
+	defaultPlacementWindow    = 30 * time.Minute
+	// placementInterval is how often the recommendation is recomputed, the workload changes slowly.
+	placementInterval = 5 * time.Minute
+
+	workloadProfileEndpointEnv = "AIBRIX_WORKLOAD_PROFILE_ENDPOINT"
+)
+
+// updatePlacement recomputes the placement recommendation in the status of a pa with pools every placementInterval,
+// from the workload profile of the model and the GPU profile of the target of each pool.
+func (r *PodAutoscalerReconciler) updatePlacement(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, pools []scalingPool, now time.Time) {
+	mode := pa.Annotations[placementAnnotation]
+	if len(pools) == 0 || (mode != placementRecommend && mode != placementApply) {
+		if mode != "" {
+			klog.InfoS("placement requires pools and the recommend or apply mode", "PodAutoscaler", klog.KObj(pa), "mode", mode)
+		}
+		pa.Status.Placement = nil
+		return
+	}
+	if r.placementStore == nil {
+		return
+	}
+	if last := pa.Status.Placement; last != nil && len(last.Pools) == len(pools) && now.Sub(last.LastUpdateTime.Time) < placementInterval {
+		return
+	}
+	model := pa.Labels[modelIdentifierLabel]
+	if model == "" {
+		klog.InfoS("placement requires the model label", "PodAutoscaler", klog.KObj(pa), "label", modelIdentifierLabel)
+		return
+	}
+	window := defaultPlacementWindow
+	if value, ok := pa.Annotations[placementWindowAnnotation]; ok {
+		var err error
+		if window, err = time.ParseDuration(value); err != nil || window <= 0 {
+			klog.ErrorS(err, "invalid placement window", "PodAutoscaler", klog.KObj(pa), "value", value)
+			return
+		}
+	}
+
+	// an idle model is placed on the min replicas of the pools.
+	workload, err := r.placementStore.Workload(ctx, model, window)
+	if err != nil && !errors.Is(err, placement.ErrNoWorkload) {
+		klog.ErrorS(err, "failed to get workload profile", "PodAutoscaler", klog.KObj(pa), "model", model)
+		return
+	}
+	candidates := make([]placement.Pool, len(pools))
+	for i, pool := range pools {
+		candidates[i] = placement.Pool{Name: pool.name, MinReplicas: pool.minReplicas, MaxReplicas: pool.maxReplicas}
+		profile, err := r.placementStore.Profile(ctx, model, pool.scale.GetName())
+		if err != nil {
+			klog.ErrorS(err, "failed to get GPU profile", "PodAutoscaler", klog.KObj(pa), "model", model, "pool", pool.name)
+			return
+		}
+		if profile == nil {
+			// a pool without profile serves no request of the recommendation.
+			klog.V(4).InfoS("no GPU profile of pool", "PodAutoscaler", klog.KObj(pa), "model", model, "pool", pool.name, "deployment", pool.scale.GetName())
+			continue
+		}
+		candidates[i].Profile = *profile
+	}
+	recommendation, err := placement.Recommend(workload, candidates)
+	if err != nil {
+		klog.ErrorS(err, "failed to recommend placement", "PodAutoscaler", klog.KObj(pa), "model", model)
+		return
+	}
+	if recommendation.UnservedRate > 0 {
+		klog.InfoS("the pools cannot serve the workload within the SLO", "PodAutoscaler", klog.KObj(pa), "model", model,
+			"requestRate", workload.Rate, "unservedRequestRate", recommendation.UnservedRate)
+	}
+	pa.Status.Placement = placementStatus(workload, recommendation, pools, now)
+}
+
+func placementStatus(workload placement.Workload, recommendation placement.Recommendation, pools []scalingPool, now time.Time) *autoscalingv1alpha1.PlacementStatus {
+	status := &autoscalingv1alpha1.PlacementStatus{
+		LastUpdateTime: metav1.NewTime(now),
+		RequestRate:    formatPlacementValue(workload.Rate),
+		Cost:           formatPlacementValue(recommendation.Cost),
+		Pools:          make([]autoscalingv1alpha1.PoolPlacement, 0, len(pools)),
+	}
+	if recommendation.UnservedRate > 0 {
+		status.UnservedRequestRate = formatPlacementValue(recommendation.UnservedRate)
+	}
+	for i, pool := range pools {
+		status.Pools = append(status.Pools, autoscalingv1alpha1.PoolPlacement{
+			Name:     pool.name,
+			Replicas: recommendation.Replicas[i],
+			Cost:     formatPlacementValue(recommendation.Costs[i]),
+		})
+	}
+	return status
+}
+
+func formatPlacementValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// applyPlacement weighs the pools by the capacity of their recommended replicas in the apply mode, so the desired
+// capacity of the pa is split in the proportions of the cheapest mix. The weights of the spec are kept otherwise, or
+// while the recommendation places no replica.
+func applyPlacement(pa *autoscalingv1alpha1.PodAutoscaler, pools []scalingPool) {
+	status := pa.Status.Placement
+	if status == nil {
+		return
+	}
+	status.Applied = false
+	if pa.Annotations[placementAnnotation] != placementApply {
+		return
+	}
+	replicas := make(map[string]int32, len(status.Pools))
+	for _, pool := range status.Pools {
+		replicas[pool.Name] = pool.Replicas
+	}
+	var total float64
+	for _, pool := range pools {
+		total += float64(replicas[pool.name]) * pool.capacity
+	}
+	if total == 0 {
+		return
+	}
+	for i := range pools {
+		pools[i].weight = float64(replicas[pools[i].name]) * pools[i].capacity
+	}
+	status.Applied = true
+}
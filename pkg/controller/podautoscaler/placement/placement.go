@@ -0,0 +1,156 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// GPUProfile is the throughput of a model on a GPU type within an SLO, as aibrix_gen_profile benchmarks it.
+type GPUProfile struct {
+	GPU string `json:"gpu"`
+	// Cost of a replica, e.g. per hour.
+	Cost float64 `json:"cost"`
+	// Throughputs[i][j] are the requests per second a replica serves within the SLO for requests of up to
+	// Indexes[0][i] output tokens and Indexes[1][j] input tokens, 0 if the SLO cannot be met.
+	Throughputs [][]float64 `json:"tputs"`
+	Indexes     [][]float64 `json:"indexes"`
+}
+
+// Throughput returns the requests per second a replica serves for requests of the tokens, those of the smallest
+// benchmarked tokens at or above them, or the largest ones.
+func (p *GPUProfile) Throughput(inputTokens, outputTokens int) float64 {
+	if len(p.Indexes) != 2 {
+		return 0
+	}
+	i, j := tokenIndex(p.Indexes[0], outputTokens), tokenIndex(p.Indexes[1], inputTokens)
+	if i >= len(p.Throughputs) || j >= len(p.Throughputs[i]) {
+		return 0
+	}
+	return p.Throughputs[i][j]
+}
+
+func tokenIndex(ticks []float64, tokens int) int {
+	for i, tick := range ticks {
+		if tick >= float64(tokens) {
+			return i
+		}
+	}
+	return max(len(ticks)-1, 0)
+}
+
+// Workload is the traffic of a model to place.
+type Workload struct {
+	// Rate is the requests per second to serve.
+	Rate float64
+	// Buckets split the rate by the tokens of the requests.
+	Buckets []Bucket
+}
+
+// Bucket is a share of the requests with about the same tokens, e.g. their count.
+type Bucket struct {
+	InputTokens  int
+	OutputTokens int
+	Share        float64
+}
+
+// Pool is a target serving the model on a GPU type.
+type Pool struct {
+	Name        string
+	Profile     GPUProfile
+	MinReplicas *int32
+	MaxReplicas *int32
+}
+
+// Recommendation is the cheapest replicas of the pools serving a workload.
+type Recommendation struct {
+	// Replicas and Costs of each pool, in the order of the pools.
+	Replicas []int32
+	Costs    []float64
+	Cost     float64
+	// UnservedRate is the requests per second no pool serves within its SLO and max replicas.
+	UnservedRate float64
+}
+
+// Recommend places each bucket of the workload on the pools with the lowest cost per request first, spilling over
+// to the next cheapest pool once a pool reaches its max replicas. The load placed on a pool is rounded up into
+// replicas, within the limits of the pool.
+func Recommend(workload Workload, pools []Pool) (Recommendation, error) {
+	if len(pools) == 0 {
+		return Recommendation{}, fmt.Errorf("no pool to place the workload on")
+	}
+	var totalShare float64
+	for _, bucket := range workload.Buckets {
+		totalShare += bucket.Share
+	}
+	if workload.Rate > 0 && totalShare <= 0 {
+		return Recommendation{}, fmt.Errorf("no request tokens to place the workload by")
+	}
+
+	// the busiest buckets are placed first, so they get the cheapest pools when pools run out of replicas.
+	buckets := append([]Bucket(nil), workload.Buckets...)
+	sort.SliceStable(buckets, func(i, j int) bool { return buckets[i].Share > buckets[j].Share })
+
+	recommendation := Recommendation{Replicas: make([]int32, len(pools)), Costs: make([]float64, len(pools))}
+	load := make([]float64, len(pools))
+	for _, bucket := range buckets {
+		rate := workload.Rate * bucket.Share / totalShare
+		if rate <= 0 {
+			continue
+		}
+		order := make([]int, 0, len(pools))
+		throughputs := make([]float64, len(pools))
+		for i := range pools {
+			if throughputs[i] = pools[i].Profile.Throughput(bucket.InputTokens, bucket.OutputTokens); throughputs[i] > 0 {
+				order = append(order, i)
+			}
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			i, j := order[a], order[b]
+			return pools[i].Profile.Cost/throughputs[i] < pools[j].Profile.Cost/throughputs[j]
+		})
+		for _, i := range order {
+			replicas := rate / throughputs[i]
+			if pools[i].MaxReplicas != nil {
+				replicas = math.Min(replicas, math.Max(float64(*pools[i].MaxReplicas)-load[i], 0))
+			}
+			load[i] += replicas
+			if rate -= replicas * throughputs[i]; rate <= 1e-9 {
+				rate = 0
+				break
+			}
+		}
+		recommendation.UnservedRate += rate
+	}
+
+	for i, pool := range pools {
+		// the load is rounded to a thousandth of a replica first, so float errors don't add a replica.
+		replicas := int32(math.Ceil(math.Round(load[i]*1000) / 1000))
+		if pool.MinReplicas != nil && replicas < *pool.MinReplicas {
+			replicas = *pool.MinReplicas
+		}
+		if pool.MaxReplicas != nil && replicas > *pool.MaxReplicas {
+			replicas = *pool.MaxReplicas
+		}
+		recommendation.Replicas[i] = replicas
+		recommendation.Costs[i] = float64(replicas) * pool.Profile.Cost
+		recommendation.Cost += recommendation.Costs[i]
+	}
+	return recommendation, nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+func TestThroughput(t *testing.T) {
+	profile := GPUProfile{
+		Throughputs: [][]float64{{8, 4}, {2, 0}},
+		Indexes:     [][]float64{{128, 1024}, {256, 2048}},
+	}
+	assert.Equal(t, 8.0, profile.Throughput(100, 100))
+	assert.Equal(t, 4.0, profile.Throughput(1000, 128))
+	assert.Equal(t, 2.0, profile.Throughput(256, 129))
+	// larger requests than benchmarked count as the largest ones.
+	assert.Equal(t, 0.0, profile.Throughput(4096, 4096))
+	assert.Equal(t, 0.0, (&GPUProfile{}).Throughput(100, 100))
+}
+
+func TestRecommend(t *testing.T) {
+	profile := func(cost float64, short, long float64) GPUProfile {
+		return GPUProfile{Cost: cost, Throughputs: [][]float64{{short}, {long}}, Indexes: [][]float64{{128, 1024}, {1024}}}
+	}
+	// a100 is cheaper per request for long outputs, l20 for short ones, and only a100 meets the SLO of long outputs.
+	pools := []Pool{
+		{Name: "l20", Profile: profile(1, 4, 0)},
+		{Name: "a100", Profile: profile(3, 10, 2)},
+	}
+	workload := Workload{Rate: 20, Buckets: []Bucket{
+		{InputTokens: 512, OutputTokens: 100, Share: 3},
+		{InputTokens: 512, OutputTokens: 1000, Share: 1},
+	}}
+
+	// 15 rps of short outputs on 3.75 l20, 5 rps of long outputs on 2.5 a100.
+	recommendation, err := Recommend(workload, pools)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{4, 3}, recommendation.Replicas)
+	assert.Equal(t, []float64{4, 9}, recommendation.Costs)
+	assert.Equal(t, 13.0, recommendation.Cost)
+	assert.Zero(t, recommendation.UnservedRate)
+
+	// short outputs spill over to a100 once l20 runs out, long outputs beyond a100 are not served.
+	pools[0].MaxReplicas = ptr.To[int32](2)
+	pools[1].MaxReplicas = ptr.To[int32](3)
+	recommendation, err = Recommend(workload, pools)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{2, 3}, recommendation.Replicas)
+	// 7 rps of short outputs on 0.7 a100 leave 2.3 a100 for 4.6 of the 5 rps of long outputs.
+	assert.InDelta(t, 0.4, recommendation.UnservedRate, 1e-9)
+
+	// an idle workload keeps the min replicas.
+	pools[0].MinReplicas = ptr.To[int32](1)
+	recommendation, err = Recommend(Workload{}, pools)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{1, 0}, recommendation.Replicas)
+	assert.Equal(t, 1.0, recommendation.Cost)
+
+	_, err = Recommend(Workload{Rate: 1}, pools)
+	assert.Error(t, err)
+	_, err = Recommend(workload, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"k8s.io/klog/v2"
+)
+
+// DefaultWorkloadProfileEndpoint is the workload profile endpoint of the gateway plugins.
+const DefaultWorkloadProfileEndpoint = "http://aibrix-gateway-plugins.aibrix-system:8080/workload-profile"
+
+// ErrNoWorkload is returned when the gateway has no request trace of the model in the window.
+var ErrNoWorkload = errors.New("no request trace of the model")
+
+// Store reads the GPU profiles of a model from Redis and its workload profile from the gateway.
+type Store struct {
+	client           redis.UniversalClient
+	workloadEndpoint string
+	httpClient       *http.Client
+}
+
+func NewStore(client redis.UniversalClient, workloadEndpoint string) *Store {
+	return &Store{client: client, workloadEndpoint: workloadEndpoint, httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// profileKey is where aibrix_gen_profile writes the profile of the model on the deployment of a GPU type.
+func profileKey(model, deployment string) string {
+	return fmt.Sprintf("aibrix:profile_%s_%s", model, deployment)
+}
+
+// Profile returns the GPU profile of the model on the deployment, nil if it was not benchmarked.
+func (s *Store) Profile(ctx context.Context, model, deployment string) (*GPUProfile, error) {
+	data, err := s.client.Get(ctx, profileKey(model, deployment)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var profile GPUProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %v", profileKey(model, deployment), err)
+	}
+	return &profile, nil
+}
+
+// workloadProfile is the part of the workload profile of the gateway the placement needs.
+type workloadProfile struct {
+	ArrivalRate struct {
+		P90 float64 `json:"p90"`
+	} `json:"arrivalRate"`
+	Buckets []struct {
+		InputTokens  int `json:"inputTokens"`
+		OutputTokens int `json:"outputTokens"`
+		Count        int `json:"count"`
+	} `json:"buckets"`
+}
+
+// Workload returns the P90 arrival rate of the model over the window, split by the tokens of its completed requests.
+func (s *Store) Workload(ctx context.Context, model string, window time.Duration) (Workload, error) {
+	query := url.Values{"model": {model}, "window": {window.String()}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.workloadEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return Workload{}, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return Workload{}, fmt.Errorf("failed to get workload profile: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			klog.ErrorS(err, "error closing response body")
+		}
+	}()
+	if resp.StatusCode == http.StatusNotFound {
+		return Workload{}, ErrNoWorkload
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return Workload{}, fmt.Errorf("failed to get workload profile: %s: %s", resp.Status, body)
+	}
+
+	var profile workloadProfile
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
+		return Workload{}, fmt.Errorf("invalid workload profile: %v", err)
+	}
+	workload := Workload{Rate: profile.ArrivalRate.P90}
+	for _, bucket := range profile.Buckets {
+		workload.Buckets = append(workload.Buckets, Bucket{
+			InputTokens:  bucket.InputTokens,
+			OutputTokens: bucket.OutputTokens,
+			Share:        float64(bucket.Count),
+		})
+	}
+	return workload, nil
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreWorkload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "30m0s", r.URL.Query().Get("window"))
+		if r.URL.Query().Get("model") != "llama" {
+			http.Error(w, "no request trace of the model", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"model": "llama", "arrivalRate": {"mean": 1.5, "p90": 2.5}, "buckets": [
+			{"inputTokens": 128, "outputTokens": 1024, "count": 10, "rate": 0.25}]}`))
+	}))
+	defer server.Close()
+	store := NewStore(nil, server.URL)
+
+	workload, err := store.Workload(context.Background(), "llama", 30*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, Workload{Rate: 2.5, Buckets: []Bucket{{InputTokens: 128, OutputTokens: 1024, Share: 10}}}, workload)
+
+	_, err = store.Workload(context.Background(), "qwen", 30*time.Minute)
+	assert.ErrorIs(t, err, ErrNoWorkload)
+}
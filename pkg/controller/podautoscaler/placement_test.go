@@ -0,0 +1,67 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/placement"
+)
+
+func TestApplyPlacement(t *testing.T) {
+	now := time.Now()
+	pools := []scalingPool{
+		{name: "l20", capacity: 1, weight: 1},
+		{name: "a100", capacity: 2.5, weight: 1},
+	}
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{placementAnnotation: placementRecommend}},
+	}
+	pa.Status.Placement = placementStatus(placement.Workload{Rate: 20},
+		placement.Recommendation{Replicas: []int32{4, 2}, Costs: []float64{4, 6}, Cost: 10}, pools, now)
+	assert.Equal(t, &autoscalingv1alpha1.PlacementStatus{
+		LastUpdateTime: metav1.NewTime(now),
+		RequestRate:    "20.00",
+		Cost:           "10.00",
+		Pools: []autoscalingv1alpha1.PoolPlacement{
+			{Name: "l20", Replicas: 4, Cost: "4.00"},
+			{Name: "a100", Replicas: 2, Cost: "6.00"},
+		},
+	}, pa.Status.Placement)
+
+	// recommendations are only published.
+	applyPlacement(pa, pools)
+	assert.False(t, pa.Status.Placement.Applied)
+	assert.Equal(t, []int32{5, 2}, splitPoolReplicas(9, pools))
+
+	// 4 units on l20 and 5 units on a100.
+	pa.Annotations[placementAnnotation] = placementApply
+	applyPlacement(pa, pools)
+	assert.True(t, pa.Status.Placement.Applied)
+	assert.Equal(t, []int32{4, 2}, splitPoolReplicas(9, pools))
+
+	// the recommendation is dropped with the annotation.
+	delete(pa.Annotations, placementAnnotation)
+	(&PodAutoscalerReconciler{}).updatePlacement(context.Background(), pa, pools, now)
+	assert.Nil(t, pa.Status.Placement)
+}
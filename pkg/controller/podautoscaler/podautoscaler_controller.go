@@ -19,13 +19,18 @@ package podautoscaler
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
 	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/forecast"
 	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/metrics"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/placement"
 
 	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/scaler"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/storage"
 	podutil "github.com/vllm-project/aibrix/pkg/utils"
 	podutils "github.com/vllm-project/aibrix/pkg/utils"
 
@@ -39,6 +44,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
@@ -67,16 +73,33 @@ func Add(mgr manager.Manager, runtimeConfig config.RuntimeConfig) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager, runtimeConfig config.RuntimeConfig) (reconcile.Reconciler, error) {
+	redisClient, err := storage.NewRedisClient(storage.LoadRedisConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create redis client for request history: %v", err)
+	}
+
+	// the gateway writes the cold start estimates to its kv store.
+	coldStartStore, err := kvstore.New(podutil.LoadEnv("AIBRIX_KV_STORE", kvstore.BackendRedis), redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kv store for cold start estimates: %v", err)
+	}
+
 	// Instantiate a new PodAutoscalerReconciler with the given manager's client and scheme
 	reconciler := &PodAutoscalerReconciler{
-		Client:         mgr.GetClient(),
-		Scheme:         mgr.GetScheme(),
-		EventRecorder:  mgr.GetEventRecorderFor("PodAutoscaler"),
-		Mapper:         mgr.GetRESTMapper(),
-		resyncInterval: 10 * time.Second, // TODO: this should be override by an environment variable
-		eventCh:        make(chan event.GenericEvent),
-		AutoscalerMap:  make(map[metrics.NamespaceNameMetric]scaler.Scaler),
-		RuntimeConfig:  runtimeConfig,
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		EventRecorder:    mgr.GetEventRecorderFor("PodAutoscaler"),
+		Mapper:           mgr.GetRESTMapper(),
+		resyncInterval:   10 * time.Second, // TODO: this should be override by an environment variable
+		eventCh:          make(chan event.GenericEvent),
+		AutoscalerMap:    make(map[metrics.NamespaceNameMetric]scaler.Scaler),
+		RuntimeConfig:    runtimeConfig,
+		forecastStore:    forecast.NewStore(redisClient),
+		placementStore:   placement.NewStore(redisClient, podutil.LoadEnv(workloadProfileEndpointEnv, placement.DefaultWorkloadProfileEndpoint)),
+		drainEndpoint:    podutil.LoadEnv(gatewayDrainEndpointEnv, defaultGatewayDrainEndpoint),
+		behaviors:        newBehaviorHistory(),
+		coldStartStore:   coldStartStore,
+		coldStartHistory: newRecommendationHistory(),
 	}
 
 	return reconciler, nil
@@ -144,13 +167,19 @@ var _ reconcile.Reconciler = &PodAutoscalerReconciler{}
 // PodAutoscalerReconciler reconciles a PodAutoscaler object
 type PodAutoscalerReconciler struct {
 	client.Client
-	Scheme         *runtime.Scheme
-	EventRecorder  record.EventRecorder
-	Mapper         apimeta.RESTMapper
-	AutoscalerMap  map[metrics.NamespaceNameMetric]scaler.Scaler // AutoscalerMap maps each NamespaceNameMetric to its corresponding scaler instance.
-	resyncInterval time.Duration
-	eventCh        chan event.GenericEvent
-	RuntimeConfig  config.RuntimeConfig
+	Scheme           *runtime.Scheme
+	EventRecorder    record.EventRecorder
+	Mapper           apimeta.RESTMapper
+	AutoscalerMap    map[metrics.NamespaceNameMetric]scaler.Scaler // AutoscalerMap maps each NamespaceNameMetric to its corresponding scaler instance.
+	resyncInterval   time.Duration
+	eventCh          chan event.GenericEvent
+	RuntimeConfig    config.RuntimeConfig
+	forecastStore    *forecast.Store        // request history for predictive scaling
+	placementStore   *placement.Store       // GPU and workload profiles for placement recommendations
+	drainEndpoint    string                 // gateway endpoint reporting whether the pods drained before scale-down
+	behaviors        *behaviorHistory       // recommendations and scale events for the behavior policies
+	coldStartStore   kvstore.Store          // cold start estimates of the models written by the gateway
+	coldStartHistory *recommendationHistory // metric recommendations for the cold start padding
 }
 
 func (r *PodAutoscalerReconciler) deleteStaleScalerInCache(request types.NamespacedName) {
@@ -167,6 +196,12 @@ func (r *PodAutoscalerReconciler) deleteStaleScalerInCache(request types.Namespa
 			delete(r.AutoscalerMap, namespaceNameMetric)
 		}
 	}
+	if r.behaviors != nil {
+		r.behaviors.delete(request)
+	}
+	if r.coldStartHistory != nil {
+		r.coldStartHistory.delete(request)
+	}
 }
 
 //+kubebuilder:rbac:groups=autoscaling.aibrix.ai,resources=podautoscalers,verbs=get;list;watch;create;update;patch;delete
@@ -174,6 +209,10 @@ func (r *PodAutoscalerReconciler) deleteStaleScalerInCache(request types.Namespa
 //+kubebuilder:rbac:groups=autoscaling.aibrix.ai,resources=podautoscalers/finalizers,verbs=update
 //+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch;update
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=ray.io,resources=rayclusters,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main Kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state as specified by
@@ -209,7 +248,7 @@ func (r *PodAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	switch pa.Spec.ScalingStrategy {
 	case autoscalingv1alpha1.HPA:
 		return r.reconcileHPA(ctx, pa)
-	case autoscalingv1alpha1.KPA, autoscalingv1alpha1.APA:
+	case autoscalingv1alpha1.KPA, autoscalingv1alpha1.APA, autoscalingv1alpha1.SLO:
 		return r.reconcileCustomPA(ctx, pa)
 	}
 
@@ -261,7 +300,7 @@ func (r *PodAutoscalerReconciler) enqueuePodAutoscalers(ctx context.Context) err
 
 // checkValidAutoscalingStrategy checks if a string is in a list of valid strategies
 func checkValidAutoscalingStrategy(strategy autoscalingv1alpha1.ScalingStrategyType) bool {
-	validStrategies := []autoscalingv1alpha1.ScalingStrategyType{autoscalingv1alpha1.HPA, autoscalingv1alpha1.APA, autoscalingv1alpha1.KPA}
+	validStrategies := []autoscalingv1alpha1.ScalingStrategyType{autoscalingv1alpha1.HPA, autoscalingv1alpha1.APA, autoscalingv1alpha1.KPA, autoscalingv1alpha1.SLO}
 	for _, v := range validStrategies {
 		if v == strategy {
 			return true
@@ -330,11 +369,12 @@ func (r *PodAutoscalerReconciler) reconcileCustomPA(ctx context.Context, pa auto
 	paStatusOriginal := pa.Status.DeepCopy()
 	paType := pa.Spec.ScalingStrategy
 	scaleReference := fmt.Sprintf("%s/%s/%s", pa.Spec.ScaleTargetRef.Kind, pa.Namespace, pa.Spec.ScaleTargetRef.Name)
-	metricKey, metricSource, err := metrics.NewNamespaceNameMetric(&pa)
+	metricKeys, metricSources, err := metrics.NewNamespaceNameMetrics(&pa)
 	if err != nil {
 		r.EventRecorder.Event(&pa, corev1.EventTypeWarning, "FailedGetMetricKey", err.Error())
 		return ctrl.Result{}, err
 	}
+	r.deleteRemovedMetricScalers(&pa, metricKeys)
 
 	targetGV, err := schema.ParseGroupVersion(pa.Spec.ScaleTargetRef.APIVersion)
 	if err != nil {
@@ -385,16 +425,45 @@ func (r *PodAutoscalerReconciler) reconcileCustomPA(ctx context.Context, pa auto
 		return ctrl.Result{}, fmt.Errorf("failed to get 'replicas' from scale: %v", err)
 	}
 	currentReplicas := int32(currentReplicasInt64)
-
-	// Update the scale required metrics periodically
-	err = r.updateMetricsForScale(ctx, pa, scale, metricKey, metricSource, int(currentReplicas))
-	if err != nil {
-		r.EventRecorder.Event(&pa, corev1.EventTypeWarning, "FailedUpdateMetrics", err.Error())
-		return ctrl.Result{}, fmt.Errorf("failed to update metrics for scale target reference: %v", err)
+	if pa.Spec.RayWorkerGroup != "" {
+		currentReplicas, err = r.rayWorkerGroupReplicas(ctx, &pa, scale)
+		if err != nil {
+			r.EventRecorder.Eventf(&pa, corev1.EventTypeWarning, "FailedGetScale", "Error retrieving replicas of worker group %s: %v", pa.Spec.RayWorkerGroup, err)
+			return ctrl.Result{}, fmt.Errorf("failed to get replicas of worker group %s: %v", pa.Spec.RayWorkerGroup, err)
+		}
+	}
+	// with pools, the replicas of the pa count the capacity units of all pools.
+	var pools []scalingPool
+	if len(pa.Spec.Pools) > 0 {
+		pools, err = r.resolvePools(ctx, &pa, scale, targetGR)
+		if err != nil {
+			r.EventRecorder.Eventf(&pa, corev1.EventTypeWarning, "FailedGetScale", "Error retrieving scale of pools: %v", err)
+			return ctrl.Result{}, fmt.Errorf("failed to get scale of pools: %v", err)
+		}
+		currentReplicas = poolsCapacity(pools, poolsReplicas(pools))
+	}
+
+	// Update the scale required metrics periodically, each metric runs its own scaler on a view of the pa with that
+	// metric only. A failing metric is skipped as long as other metrics can still make a decision.
+	var metricErrs []error
+	updatedMetrics := make([]int, 0, len(metricKeys))
+	for i := range metricKeys {
+		sourcePA := sourcePodAutoscaler(&pa, metricSources[i])
+		if err := r.updateMetricsForScale(ctx, sourcePA, scale, pools, metricKeys[i], metricSources[i], int(currentReplicas)); err != nil {
+			r.EventRecorder.Eventf(&pa, corev1.EventTypeWarning, "FailedUpdateMetrics", "metric %s: %v", metricKeys[i].MetricName, err)
+			metricErrs = append(metricErrs, err)
+			continue
+		}
+		updatedMetrics = append(updatedMetrics, i)
+	}
+	if len(updatedMetrics) == 0 {
+		return ctrl.Result{}, fmt.Errorf("failed to update metrics for scale target reference: %v", utilerrors.NewAggregate(metricErrs))
 	}
 
 	// desired replica count
 	desiredReplicas := int32(0)
+	// the replicas the interactive load needs, unknown unless the metrics decide.
+	demandReplicas := int32(-1)
 	rescaleReason := ""
 	var minReplicas int32
 	// minReplica is optional
@@ -406,7 +475,17 @@ func (r *PodAutoscalerReconciler) reconcileCustomPA(ctx context.Context, pa auto
 
 	// check if rescale is needed by checking the replica settings
 	rescale := true
-	if currentReplicas == int32(0) && minReplicas != 0 {
+	idleWindow, scaleToZero := scaleToZeroIdleWindow(&pa)
+	idleReplicas, idleReason, idleDecided := int32(0), "", false
+	if scaleToZero {
+		idleReplicas, idleReason, idleDecided = scaleToZeroReplicas(&pa, currentReplicas, idleWindow, time.Now())
+	}
+	if idleDecided {
+		// an idle target goes to zero and the first request brings it back, independent of metrics.
+		desiredReplicas = idleReplicas
+		rescaleReason = idleReason
+		rescale = desiredReplicas != currentReplicas
+	} else if currentReplicas == int32(0) && minReplicas != 0 {
 		// if the replica is 0, then we should not enable autoscaling
 		desiredReplicas = 0
 		rescale = false
@@ -418,33 +497,64 @@ func (r *PodAutoscalerReconciler) reconcileCustomPA(ctx context.Context, pa auto
 		// if the currentReplicas is within the range, we should
 		// computeReplicasForMetrics gives
 		// TODO: check why it return the metrics name here?
-		metricDesiredReplicas, metricName, metricTimestamp, err := r.computeReplicasForMetrics(ctx, pa, scale, metricKey)
-		if err != nil {
+		var computeErrs []error
+		results := make([]metricReplicas, 0, len(updatedMetrics))
+		for _, i := range updatedMetrics {
+			sourcePA := sourcePodAutoscaler(&pa, metricSources[i])
+			replicas, metricName, metricTimestamp, err := r.computeReplicasForMetrics(ctx, sourcePA, scale, pools, metricKeys[i])
+			if err != nil {
+				r.EventRecorder.Eventf(&pa, corev1.EventTypeWarning, "FailedComputeMetricsReplicas", "metric %s: %v", metricKeys[i].MetricName, err)
+				computeErrs = append(computeErrs, err)
+				continue
+			}
+			klog.V(4).InfoS("Proposing desired replicas",
+				"desiredReplicas", replicas,
+				"metric", metricName,
+				"timestamp", metricTimestamp,
+				"scaleTarget", scaleReference)
+			results = append(results, metricReplicas{metricName: metricName, replicas: replicas, weight: metricWeight(metricSources[i])})
+		}
+		if len(results) == 0 {
 			r.setCurrentReplicasAndMetricsInStatus(&pa, currentReplicas)
 			if err := r.updateStatusIfNeeded(ctx, paStatusOriginal, &pa); err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to update the resource status")
 			}
-			r.EventRecorder.Event(&pa, corev1.EventTypeWarning, "FailedComputeMetricsReplicas", err.Error())
-			return ctrl.Result{}, fmt.Errorf("failed to compute desired number of replicas based on listed metrics for %s: %v", scaleReference, err)
+			return ctrl.Result{}, fmt.Errorf("failed to compute desired number of replicas based on listed metrics for %s: %v", scaleReference, utilerrors.NewAggregate(computeErrs))
 		}
-
-		klog.V(4).InfoS("Proposing desired replicas",
-			"desiredReplicas", metricDesiredReplicas,
-			"metric", metricName,
-			"timestamp", metricTimestamp,
-			"scaleTarget", scaleReference)
+		metricDesiredReplicas, metricName := aggregateReplicas(pa.Spec.MetricsAggregation, results)
 
 		rescaleMetric := ""
 		if metricDesiredReplicas > desiredReplicas {
 			desiredReplicas = metricDesiredReplicas
 			rescaleMetric = metricName
 		}
+		// pods started now serve after the cold start of the model, when the load grew as much again.
+		if paddedReplicas, ok := r.padForColdStart(ctx, &pa, metricDesiredReplicas, time.Now()); ok && paddedReplicas > desiredReplicas && paddedReplicas > currentReplicas {
+			desiredReplicas = paddedReplicas
+			rescaleMetric = fmt.Sprintf("%s padded for cold start", metricName)
+		}
+		// scale up ahead of forecast traffic, scaling down is left to the metrics once the traffic is gone.
+		if predictedReplicas, ok := r.predictReplicas(ctx, &pa); ok && predictedReplicas > desiredReplicas {
+			desiredReplicas = predictedReplicas
+			rescaleMetric = "forecast request rate"
+		}
+		demandReplicas = desiredReplicas
 		if desiredReplicas > currentReplicas {
 			rescaleReason = fmt.Sprintf("%s above target", rescaleMetric)
 		}
 		if desiredReplicas < currentReplicas {
 			rescaleReason = "All metrics below target"
 		}
+		if pa.Spec.Behavior != nil {
+			normalizedReplicas, reason := r.behaviors.normalize(types.NamespacedName{Namespace: pa.Namespace, Name: pa.Name},
+				pa.Spec.Behavior, currentReplicas, desiredReplicas, time.Now())
+			if reason != "" {
+				klog.V(2).InfoS("Scaling adjustment: recommendation limited by the scaling behavior.",
+					"recommendedReplicas", desiredReplicas, "adjustedTo", normalizedReplicas, "reason", reason)
+				rescaleReason = fmt.Sprintf("%s, limited by %s", rescaleReason, reason)
+			}
+			desiredReplicas = normalizedReplicas
+		}
 
 		// adjust desired metrics within the <min, max> range
 		if desiredReplicas > pa.Spec.MaxReplicas {
@@ -456,16 +566,48 @@ func (r *PodAutoscalerReconciler) reconcileCustomPA(ctx context.Context, pa auto
 				"recommendedReplicas", desiredReplicas, "adjustedTo", minReplicas)
 			desiredReplicas = minReplicas
 		}
+		// scale-to-zero targets keep serving until they are idle, even if the algorithm recommends zero.
+		if scaleToZero && desiredReplicas < scaleFromZeroReplicas {
+			desiredReplicas = scaleFromZeroReplicas
+		}
 
 		rescale = desiredReplicas != currentReplicas
 	}
 
+	// the capacity units are split over the pools, which are rescaled as soon as any pool changes.
+	r.updatePlacement(ctx, &pa, pools, time.Now())
+	var poolReplicas []int32
+	if len(pools) > 0 {
+		applyPlacement(&pa, pools)
+		poolReplicas = splitPoolReplicas(desiredReplicas, pools)
+		rescale = !slices.Equal(poolReplicas, poolsReplicas(pools))
+	} else {
+		// the pods removed by a scale-down finish their requests first, the scale-down waits for the next passes.
+		drained, err := r.drainBeforeScaleDown(ctx, &pa, scale, currentReplicas, desiredReplicas, time.Now())
+		if err != nil {
+			r.EventRecorder.Eventf(&pa, corev1.EventTypeWarning, "FailedDrain", "Error draining pods before scaling down: %v", err)
+		}
+		if rescale && desiredReplicas < currentReplicas && !drained {
+			rescale = false
+		}
+		// the replicas kept above the interactive load run batch workloads until it needs them.
+		if err := r.lendIdleReplicas(ctx, &pa, scale, demandReplicas, time.Now()); err != nil {
+			r.EventRecorder.Eventf(&pa, corev1.EventTypeWarning, "FailedBatchLending", "Error lending idle pods to batch workloads: %v", err)
+		}
+	}
+
 	r.EventRecorder.Eventf(&pa, corev1.EventTypeNormal, "AlgorithmRun",
 		"%s algorithm run. currentReplicas: %d, desiredReplicas: %d, rescale: %t",
 		pa.Spec.ScalingStrategy, currentReplicas, desiredReplicas, rescale)
 
 	if rescale {
-		if err := r.updateScale(ctx, pa.Namespace, targetGR, scale, desiredReplicas); err != nil {
+		var err error
+		if len(pools) > 0 {
+			err = r.updatePoolsScale(ctx, pa.Namespace, pools, poolReplicas)
+		} else {
+			err = r.updateScaleTarget(ctx, &pa, targetGR, scale, desiredReplicas)
+		}
+		if err != nil {
 			r.EventRecorder.Eventf(&pa, corev1.EventTypeWarning, "FailedRescale", "New size: %d; reason: %s; error: %v", desiredReplicas, rescaleReason, err)
 			setCondition(&pa, "AbleToScale", metav1.ConditionFalse, "FailedUpdateScale", "the %s controller was unable to update the target scale: %v", paType, err)
 			r.setCurrentReplicasAndMetricsInStatus(&pa, currentReplicas)
@@ -482,6 +624,12 @@ func (r *PodAutoscalerReconciler) reconcileCustomPA(ctx context.Context, pa auto
 		//}
 
 		r.EventRecorder.Eventf(&pa, corev1.EventTypeNormal, "SuccessfulRescale", "New size: %d; reason: %s", desiredReplicas, rescaleReason)
+		if pa.Spec.Behavior != nil {
+			r.behaviors.recordScaleEvent(types.NamespacedName{Namespace: pa.Namespace, Name: pa.Name},
+				pa.Spec.Behavior, currentReplicas, desiredReplicas, time.Now())
+		}
+		// the last scale time tells scale-to-zero which requests arrived after the target was scaled to zero.
+		r.setStatus(&pa, currentReplicas, desiredReplicas, true)
 
 		klog.InfoS("Successfully rescaled",
 			"PodAutoscaler", klog.KObj(&pa),
@@ -489,6 +637,9 @@ func (r *PodAutoscalerReconciler) reconcileCustomPA(ctx context.Context, pa auto
 			"desiredReplicas", desiredReplicas,
 			"reason", rescaleReason)
 	}
+	if len(pools) > 0 {
+		pa.Status.Pools = poolStatuses(pools, poolReplicas)
+	}
 
 	if err := r.updateStatusIfNeeded(ctx, paStatusOriginal, &pa); err != nil {
 		// we can overwrite retErr in this case because it's an internal error.
@@ -540,6 +691,14 @@ func (r *PodAutoscalerReconciler) scaleForResourceMappings(ctx context.Context,
 	return nil, schema.GroupResource{}, firstErr
 }
 
+// updateScaleTarget scales the target, or the worker group of a RayClusterFleet target if the pa sets one.
+func (r *PodAutoscalerReconciler) updateScaleTarget(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, targetGR schema.GroupResource, scale *unstructured.Unstructured, replicas int32) error {
+	if pa.Spec.RayWorkerGroup != "" {
+		return r.updateRayWorkerGroupReplicas(ctx, pa, scale, replicas)
+	}
+	return r.updateScale(ctx, pa.Namespace, targetGR, scale, replicas)
+}
+
 func (r *PodAutoscalerReconciler) updateScale(ctx context.Context, namespace string, targetGR schema.GroupResource, scale *unstructured.Unstructured, replicas int32) error {
 	err := unstructured.SetNestedField(scale.Object, int64(replicas), "spec", "replicas")
 	if err != nil {
@@ -575,6 +734,8 @@ func (r *PodAutoscalerReconciler) setStatus(pa *autoscalingv1alpha1.PodAutoscale
 		DesiredScale:  desiredReplicas,
 		LastScaleTime: pa.Status.LastScaleTime,
 		Conditions:    pa.Status.Conditions,
+		Pools:         pa.Status.Pools,
+		Placement:     pa.Status.Placement,
 	}
 
 	if rescale {
@@ -608,19 +769,24 @@ func (r *PodAutoscalerReconciler) updateStatus(ctx context.Context, pa *autoscal
 // It may return both valid metricDesiredReplicas and an error,
 // when some metrics still work and PA should perform scaling based on them.
 // If PodAutoscaler cannot do anything due to error, it returns -1 in metricDesiredReplicas as a failure signal.
-func (r *PodAutoscalerReconciler) computeReplicasForMetrics(ctx context.Context, pa autoscalingv1alpha1.PodAutoscaler, scale *unstructured.Unstructured, metricKey metrics.NamespaceNameMetric) (replicas int32, relatedMetrics string, timestamp time.Time, err error) {
+func (r *PodAutoscalerReconciler) computeReplicasForMetrics(ctx context.Context, pa autoscalingv1alpha1.PodAutoscaler, scale *unstructured.Unstructured, pools []scalingPool, metricKey metrics.NamespaceNameMetric) (replicas int32, relatedMetrics string, timestamp time.Time, err error) {
 	logger := klog.FromContext(ctx)
 	currentTimestamp := time.Now()
 
 	// Retrieve the selector string from the Scale object's Status,
 	// and convert *metav1.LabelSelector object to labels.Selector structure
-	labelsSelector, err := extractLabelSelector(scale)
+	_, labelsSelector, err := r.podSelectors(ctx, &pa, scale)
 	if err != nil {
 		return 0, "", currentTimestamp, err
 	}
 
-	originalReadyPodsCount, err := scaler.GetReadyPodsCount(ctx, r.Client, pa.Namespace, labelsSelector)
-
+	var originalReadyPodsCount int64
+	if len(pools) > 0 {
+		// the ready pods of the pools count in capacity units, like the replicas of the pa.
+		originalReadyPodsCount, err = r.poolsReadyCapacity(ctx, pa.Namespace, pools)
+	} else {
+		originalReadyPodsCount, err = scaler.GetReadyPodsCount(ctx, r.Client, pa.Namespace, labelsSelector)
+	}
 	if err != nil {
 		return 0, "", currentTimestamp, fmt.Errorf("error getting ready pods count: %w", err)
 	}
@@ -660,7 +826,7 @@ func (r *PodAutoscalerReconciler) updateScalerSpec(ctx context.Context, pa autos
 }
 
 // updateMetricsForScale: we pass into the currentReplicas to construct autoScaler, as KNative implementation
-func (r *PodAutoscalerReconciler) updateMetricsForScale(ctx context.Context, pa autoscalingv1alpha1.PodAutoscaler, scale *unstructured.Unstructured, metricKey metrics.NamespaceNameMetric, metricSource autoscalingv1alpha1.MetricSource, currentReplicas int) (err error) {
+func (r *PodAutoscalerReconciler) updateMetricsForScale(ctx context.Context, pa autoscalingv1alpha1.PodAutoscaler, scale *unstructured.Unstructured, pools []scalingPool, metricKey metrics.NamespaceNameMetric, metricSource autoscalingv1alpha1.MetricSource, currentReplicas int) (err error) {
 	currentTimestamp := time.Now()
 	var autoScaler scaler.Scaler
 	// it's similar to knative: pkg/autoscaler/scaling/multiscaler.go: func (m *MultiScaler) Create
@@ -677,6 +843,8 @@ func (r *PodAutoscalerReconciler) updateMetricsForScale(ctx context.Context, pa
 			autoScaler, err = scaler.NewKpaAutoscaler(currentReplicas, &pa, time.Now())
 		case autoscalingv1alpha1.APA:
 			autoScaler, err = scaler.NewApaAutoscaler(currentReplicas, &pa)
+		case autoscalingv1alpha1.SLO:
+			autoScaler, err = scaler.NewSloAutoscaler(&pa)
 		default:
 			return fmt.Errorf("unsupported scaling strategy: %s", pa.Spec.ScalingStrategy)
 		}
@@ -695,7 +863,7 @@ func (r *PodAutoscalerReconciler) updateMetricsForScale(ctx context.Context, pa
 
 	// Retrieve the selector string from the Scale object's Status,
 	// and convert *metav1.LabelSelector object to labels.Selector structure
-	labelsSelector, err := extractLabelSelector(scale)
+	labelsSelector, _, err := r.podSelectors(ctx, &pa, scale)
 	if err != nil {
 		return err
 	}
@@ -706,6 +874,15 @@ func (r *PodAutoscalerReconciler) updateMetricsForScale(ctx context.Context, pa
 		klog.ErrorS(err, "failed to get pod list by label selector")
 		return err
 	}
+	if len(pools) > 0 {
+		// the metrics of the pa are collected from the pods of all pools.
+		if podList.Items, err = r.poolsPods(ctx, pa.Namespace, pools); err != nil {
+			return err
+		}
+	}
+
+	// the pods lent to batch workloads don't serve the interactive load the pa scales for.
+	podList.Items = withoutBatchLoans(podList.Items)
 
 	// TODO: do we need to indicate the metrics source.
 	// Technically, the metrics could come from Kubernetes metrics API (resource or custom), pod prometheus endpoint or ai runtime
@@ -713,7 +890,14 @@ func (r *PodAutoscalerReconciler) updateMetricsForScale(ctx context.Context, pa
 	switch metricSource.MetricSourceType {
 	case autoscalingv1alpha1.POD:
 		return autoScaler.UpdateScaleTargetMetrics(ctx, metricKey, metricSource, podList.Items, currentTimestamp)
-	case autoscalingv1alpha1.DOMAIN:
+	case autoscalingv1alpha1.DOMAIN, autoscalingv1alpha1.KAFKA, autoscalingv1alpha1.REDIS, autoscalingv1alpha1.JSON:
+		if metricSource.Auth != nil || metricSource.TLS != nil {
+			credentials, err := r.metricSourceCredentials(ctx, pa.Namespace, metricSource)
+			if err != nil {
+				return err
+			}
+			ctx = metrics.WithSourceCredentials(ctx, credentials)
+		}
 		return autoScaler.UpdateSourceMetrics(ctx, metricKey, metricSource, currentTimestamp)
 	default:
 		return fmt.Errorf("unsupported protocol type: %v", metricSource.ProtocolType)
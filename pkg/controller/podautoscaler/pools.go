@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	podutil "github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// scalingPool is a pool of the pa resolved to the scale of its target.
+type scalingPool struct {
+	name        string
+	scale       *unstructured.Unstructured
+	targetGR    schema.GroupResource
+	capacity    float64
+	weight      float64
+	minReplicas *int32
+	maxReplicas *int32
+	replicas    int32
+}
+
+// resolvePools gets the scale of the target of each pool of the pa, the scale of the pa target is reused for the
+// pools without a target of their own.
+func (r *PodAutoscalerReconciler) resolvePools(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, scale *unstructured.Unstructured, targetGR schema.GroupResource) ([]scalingPool, error) {
+	pools := make([]scalingPool, 0, len(pa.Spec.Pools))
+	for _, spec := range pa.Spec.Pools {
+		pool := scalingPool{
+			name:        spec.Name,
+			scale:       scale,
+			targetGR:    targetGR,
+			minReplicas: spec.MinReplicas,
+			maxReplicas: spec.MaxReplicas,
+		}
+		var err error
+		if pool.capacity, err = parsePoolValue(spec.Capacity); err != nil || pool.capacity <= 0 {
+			return nil, fmt.Errorf("invalid capacity %q of pool %s", spec.Capacity, spec.Name)
+		}
+		if pool.weight, err = parsePoolValue(spec.Weight); err != nil || pool.weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q of pool %s", spec.Weight, spec.Name)
+		}
+		if spec.ScaleTargetRef != nil && !sameScaleTarget(*spec.ScaleTargetRef, pa.Spec.ScaleTargetRef) {
+			if pool.scale, pool.targetGR, err = r.scaleForTarget(ctx, pa.Namespace, *spec.ScaleTargetRef); err != nil {
+				return nil, fmt.Errorf("failed to get scale of pool %s: %w", spec.Name, err)
+			}
+		}
+		replicas, found, err := unstructured.NestedInt64(pool.scale.Object, "spec", "replicas")
+		if err != nil || !found {
+			return nil, fmt.Errorf("the 'replicas' field was not found in the scale object of pool %s", spec.Name)
+		}
+		pool.replicas = int32(replicas)
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// scaleForTarget gets the scale of the target in the namespace of the pa.
+func (r *PodAutoscalerReconciler) scaleForTarget(ctx context.Context, namespace string, ref corev1.ObjectReference) (*unstructured.Unstructured, schema.GroupResource, error) {
+	targetGV, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, schema.GroupResource{}, fmt.Errorf("invalid API version in scale target reference: %v", err)
+	}
+	mappings, err := r.Mapper.RESTMappings(schema.GroupKind{Group: targetGV.Group, Kind: ref.Kind})
+	if err != nil {
+		return nil, schema.GroupResource{}, fmt.Errorf("unable to determine resource for scale target reference: %v", err)
+	}
+	return r.scaleForResourceMappings(ctx, namespace, ref.Name, mappings)
+}
+
+func sameScaleTarget(a, b corev1.ObjectReference) bool {
+	return a.APIVersion == b.APIVersion && a.Kind == b.Kind && a.Name == b.Name
+}
+
+// parsePoolValue parses the capacity or weight of a pool, which default to 1.
+func parsePoolValue(value string) (float64, error) {
+	if value == "" {
+		return 1, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// poolsCapacity returns the capacity units of the given replicas of the pools, rounded up.
+func poolsCapacity(pools []scalingPool, replicas []int32) int32 {
+	var capacity float64
+	for i, pool := range pools {
+		capacity += float64(replicas[i]) * pool.capacity
+	}
+	return int32(math.Ceil(capacity))
+}
+
+// poolsReplicas returns the current replicas of the pools.
+func poolsReplicas(pools []scalingPool) []int32 {
+	replicas := make([]int32, len(pools))
+	for i, pool := range pools {
+		replicas[i] = pool.replicas
+	}
+	return replicas
+}
+
+// splitPoolReplicas splits the capacity units over the pools by their weights and converts the share of each pool
+// into replicas of its capacity, rounded up and within the limits of the pool.
+func splitPoolReplicas(capacity int32, pools []scalingPool) []int32 {
+	var totalWeight float64
+	for _, pool := range pools {
+		totalWeight += pool.weight
+	}
+	replicas := make([]int32, len(pools))
+	for i, pool := range pools {
+		if totalWeight > 0 {
+			share := float64(capacity) * pool.weight / totalWeight
+			replicas[i] = int32(math.Ceil(share / pool.capacity))
+		}
+		if pool.minReplicas != nil && replicas[i] < *pool.minReplicas {
+			replicas[i] = *pool.minReplicas
+		}
+		if pool.maxReplicas != nil && replicas[i] > *pool.maxReplicas {
+			replicas[i] = *pool.maxReplicas
+		}
+	}
+	return replicas
+}
+
+// poolsPods lists the pods of all pools, the metrics of the pa are collected from all of them.
+func (r *PodAutoscalerReconciler) poolsPods(ctx context.Context, namespace string, pools []scalingPool) ([]corev1.Pod, error) {
+	var pods []corev1.Pod
+	seen := map[string]bool{}
+	for _, pool := range pools {
+		selector, err := extractLabelSelector(pool.scale)
+		if err != nil {
+			return nil, fmt.Errorf("pool %s: %w", pool.name, err)
+		}
+		podList, err := podutil.GetPodListByLabelSelector(ctx, r.Client, namespace, selector)
+		if err != nil {
+			return nil, fmt.Errorf("pool %s: %w", pool.name, err)
+		}
+		for _, pod := range podList.Items {
+			if !seen[pod.Name] {
+				seen[pod.Name] = true
+				pods = append(pods, pod)
+			}
+		}
+	}
+	return pods, nil
+}
+
+// poolsReadyCapacity returns the capacity units of the ready pods of the pools, rounded to the closest unit.
+func (r *PodAutoscalerReconciler) poolsReadyCapacity(ctx context.Context, namespace string, pools []scalingPool) (int64, error) {
+	var capacity float64
+	for _, pool := range pools {
+		selector, err := extractLabelSelector(pool.scale)
+		if err != nil {
+			return 0, fmt.Errorf("pool %s: %w", pool.name, err)
+		}
+		podList, err := podutil.GetPodListByLabelSelector(ctx, r.Client, namespace, selector)
+		if err != nil {
+			return 0, fmt.Errorf("pool %s: %w", pool.name, err)
+		}
+		ready, err := podutil.CountReadyPods(podList)
+		if err != nil {
+			return 0, err
+		}
+		capacity += float64(ready) * pool.capacity
+	}
+	return int64(math.Round(capacity)), nil
+}
+
+// updatePoolsScale scales the pools whose replicas changed.
+func (r *PodAutoscalerReconciler) updatePoolsScale(ctx context.Context, namespace string, pools []scalingPool, replicas []int32) error {
+	for i, pool := range pools {
+		if pool.replicas == replicas[i] {
+			continue
+		}
+		if err := r.updateScale(ctx, namespace, pool.targetGR, pool.scale, replicas[i]); err != nil {
+			return fmt.Errorf("pool %s: %w", pool.name, err)
+		}
+	}
+	return nil
+}
+
+func poolStatuses(pools []scalingPool, replicas []int32) []autoscalingv1alpha1.PoolStatus {
+	statuses := make([]autoscalingv1alpha1.PoolStatus, 0, len(pools))
+	for i, pool := range pools {
+		statuses = append(statuses, autoscalingv1alpha1.PoolStatus{
+			Name:            pool.name,
+			DesiredReplicas: replicas[i],
+			ActualReplicas:  pool.replicas,
+		})
+	}
+	return statuses
+}
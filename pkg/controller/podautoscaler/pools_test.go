@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+func newTestPoolScale(name string, replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name, "namespace": "default"},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": name}},
+		},
+	}}
+}
+
+func newTestReadyPod(name, app string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": app}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestSplitPoolReplicas(t *testing.T) {
+	pools := []scalingPool{
+		{name: "l20", capacity: 1, weight: 1},
+		{name: "a100", capacity: 2.5, weight: 3},
+	}
+	// 10 units: 2.5 units on l20, 7.5 units on a100 of 2.5 units each.
+	assert.Equal(t, []int32{3, 3}, splitPoolReplicas(10, pools))
+	assert.Equal(t, int32(11), poolsCapacity(pools, []int32{3, 3}))
+	assert.Equal(t, []int32{0, 0}, splitPoolReplicas(0, pools))
+
+	pools[1].maxReplicas = ptr.To[int32](2)
+	pools[0].minReplicas = ptr.To[int32](4)
+	assert.Equal(t, []int32{4, 2}, splitPoolReplicas(10, pools))
+
+	// a pool without weight only keeps its minimum.
+	pools[1].weight = 0
+	assert.Equal(t, []int32{10, 0}, splitPoolReplicas(10, pools))
+}
+
+func TestResolvePools(t *testing.T) {
+	r := &PodAutoscalerReconciler{}
+	scale := newTestPoolScale("llama-l20", 2)
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: autoscalingv1alpha1.PodAutoscalerSpec{
+			ScaleTargetRef: corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "llama-l20"},
+			Pools: []autoscalingv1alpha1.ScalingPool{
+				{Name: "l20", Capacity: "1.5", Weight: "2"},
+			},
+		},
+	}
+
+	pools, err := r.resolvePools(context.Background(), pa, scale, schema.GroupResource{Group: "apps", Resource: "deployments"})
+	assert.NoError(t, err)
+	assert.Len(t, pools, 1)
+	assert.Equal(t, 1.5, pools[0].capacity)
+	assert.Equal(t, 2.0, pools[0].weight)
+	assert.Equal(t, int32(2), pools[0].replicas)
+	assert.Equal(t, []autoscalingv1alpha1.PoolStatus{{Name: "l20", DesiredReplicas: 3, ActualReplicas: 2}},
+		poolStatuses(pools, []int32{3}))
+
+	pa.Spec.Pools[0].Capacity = "0"
+	_, err = r.resolvePools(context.Background(), pa, scale, schema.GroupResource{})
+	assert.Error(t, err)
+}
+
+func TestPoolsReadyCapacity(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	r := &PodAutoscalerReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newTestReadyPod("llama-l20-0", "llama-l20"),
+		newTestReadyPod("llama-l20-1", "llama-l20"),
+		newTestReadyPod("llama-a100-0", "llama-a100"),
+	).Build()}
+	pools := []scalingPool{
+		{name: "l20", scale: newTestPoolScale("llama-l20", 2), capacity: 1},
+		{name: "a100", scale: newTestPoolScale("llama-a100", 1), capacity: 2.5},
+	}
+
+	capacity, err := r.poolsReadyCapacity(context.Background(), "default", pools)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), capacity)
+
+	pods, err := r.poolsPods(context.Background(), "default", pools)
+	assert.NoError(t, err)
+	assert.Len(t, pods, 3)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/common"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/forecast"
+)
+
+const (
+	// predictiveRequestsPerReplicaAnnotation enables predictive scaling, it is the request rate per second a replica serves.
+	predictiveRequestsPerReplicaAnnotation = common.AutoscalingLabelPrefix + "predictive-requests-per-replica"
+	// predictiveLeadTimeAnnotation is how long ahead of the forecast traffic replicas are scaled up, it should cover
+	// starting a replica and loading the model, e.g. "10m". With cold start padding it defaults to the cold start of
+	// the model.
+	predictiveLeadTimeAnnotation = common.AutoscalingLabelPrefix + "predictive-lead-time"
+	defaultPredictiveLeadTime    = 10 * time.Minute
+
+	// modelIdentifierLabel names the model whose request traces predictive scaling reads.
+	modelIdentifierLabel = "model.aibrix.ai/name"
+)
+
+// predictReplicas returns the replicas needed for the request rate forecast at the lead time, from the request history
+// of the model, false if predictive scaling is disabled or there is not enough history yet.
+func (r *PodAutoscalerReconciler) predictReplicas(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler) (int32, bool) {
+	value, ok := pa.Annotations[predictiveRequestsPerReplicaAnnotation]
+	if !ok || r.forecastStore == nil {
+		return 0, false
+	}
+	perReplica, err := strconv.ParseFloat(value, 64)
+	if err != nil || perReplica <= 0 {
+		klog.ErrorS(err, "invalid predictive requests per replica", "PodAutoscaler", klog.KObj(pa), "value", value)
+		return 0, false
+	}
+	model := pa.Labels[modelIdentifierLabel]
+	if model == "" {
+		klog.InfoS("predictive scaling requires the model label", "PodAutoscaler", klog.KObj(pa), "label", modelIdentifierLabel)
+		return 0, false
+	}
+	lead := defaultPredictiveLeadTime
+	if value, ok := pa.Annotations[predictiveLeadTimeAnnotation]; ok {
+		if lead, err = time.ParseDuration(value); err != nil || lead < 0 {
+			klog.ErrorS(err, "invalid predictive lead time", "PodAutoscaler", klog.KObj(pa), "value", value)
+			return 0, false
+		}
+	} else if coldStart, ok := r.coldStart(ctx, pa); ok {
+		lead = coldStart
+	}
+
+	now := time.Now()
+	// a failed sync leaves a gap in the history, the forecast is still made from the slots recorded before.
+	if err := r.forecastStore.Sync(ctx, model, now); err != nil {
+		klog.ErrorS(err, "failed to record request history", "PodAutoscaler", klog.KObj(pa), "model", model)
+	}
+	history, err := r.forecastStore.History(ctx, model)
+	if err != nil {
+		klog.ErrorS(err, "failed to read request history", "PodAutoscaler", klog.KObj(pa), "model", model)
+		return 0, false
+	}
+	rate, ok := forecast.Forecast(history, now, lead)
+	if !ok {
+		klog.V(4).InfoS("not enough request history to forecast", "PodAutoscaler", klog.KObj(pa), "model", model)
+		return 0, false
+	}
+
+	replicas := int32(math.Ceil(rate / perReplica))
+	klog.V(4).InfoS("Forecast request rate", "PodAutoscaler", klog.KObj(pa), "model", model, "leadTime", lead, "rate", rate, "replicas", replicas)
+	return replicas, true
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	rayclusterv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+)
+
+const (
+	rayClusterFleetKind = "RayClusterFleet"
+
+	// labels KubeRay sets on the pods of a RayCluster.
+	rayClusterLabelKey   = "ray.io/cluster"
+	rayNodeTypeLabelKey  = "ray.io/node-type"
+	rayNodeGroupLabelKey = "ray.io/group"
+	rayHeadNodeType      = "head"
+)
+
+// isRayClusterFleet checks if the pa scales a RayClusterFleet. The fleet selector selects RayClusters rather than
+// pods, the pods are found through the clusters.
+func isRayClusterFleet(pa *autoscalingv1alpha1.PodAutoscaler) bool {
+	gv, err := schema.ParseGroupVersion(pa.Spec.ScaleTargetRef.APIVersion)
+	return err == nil && gv.Group == orchestrationv1alpha1.GroupVersion.Group && pa.Spec.ScaleTargetRef.Kind == rayClusterFleetKind
+}
+
+// fleetRayClusters lists the RayClusters of the fleet which are not being deleted, sorted by name.
+func (r *PodAutoscalerReconciler) fleetRayClusters(ctx context.Context, namespace string, fleet *unstructured.Unstructured) ([]rayclusterv1.RayCluster, error) {
+	selector, err := extractLabelSelector(fleet)
+	if err != nil {
+		return nil, err
+	}
+	clusterList := &rayclusterv1.RayClusterList{}
+	if err := r.List(ctx, clusterList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list RayClusters of fleet %s: %w", fleet.GetName(), err)
+	}
+	clusters := make([]rayclusterv1.RayCluster, 0, len(clusterList.Items))
+	for _, cluster := range clusterList.Items {
+		if cluster.DeletionTimestamp == nil {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+	return clusters, nil
+}
+
+// podSelectors returns the selector of the pods to collect the metrics from and of the pods counted as the ready
+// replicas of the scale target. Both select the pods of the scale target, except for RayClusterFleets, whose head
+// pods serve the metrics and count as replicas, or the worker pods of the group if the pa scales a worker group.
+func (r *PodAutoscalerReconciler) podSelectors(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, scale *unstructured.Unstructured) (metricPods labels.Selector, replicaPods labels.Selector, err error) {
+	if !isRayClusterFleet(pa) {
+		selector, err := extractLabelSelector(scale)
+		return selector, selector, err
+	}
+
+	clusters, err := r.fleetRayClusters(ctx, pa.Namespace, scale)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(clusters) == 0 {
+		return labels.Nothing(), labels.Nothing(), nil
+	}
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+	clusterRequirement, err := labels.NewRequirement(rayClusterLabelKey, selection.In, names)
+	if err != nil {
+		return nil, nil, err
+	}
+	headRequirement, err := labels.NewRequirement(rayNodeTypeLabelKey, selection.Equals, []string{rayHeadNodeType})
+	if err != nil {
+		return nil, nil, err
+	}
+	metricPods = labels.NewSelector().Add(*clusterRequirement, *headRequirement)
+	if pa.Spec.RayWorkerGroup == "" {
+		return metricPods, metricPods, nil
+	}
+
+	groupRequirement, err := labels.NewRequirement(rayNodeGroupLabelKey, selection.Equals, []string{pa.Spec.RayWorkerGroup})
+	if err != nil {
+		return nil, nil, err
+	}
+	return metricPods, labels.NewSelector().Add(*clusterRequirement, *groupRequirement), nil
+}
+
+// rayWorkerGroupReplicas returns the replicas of the worker group of the pa summed over the RayClusters of the fleet.
+func (r *PodAutoscalerReconciler) rayWorkerGroupReplicas(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, fleet *unstructured.Unstructured) (int32, error) {
+	if !isRayClusterFleet(pa) {
+		return 0, fmt.Errorf("rayWorkerGroup requires a %s scale target, got %s", rayClusterFleetKind, pa.Spec.ScaleTargetRef.Kind)
+	}
+	clusters, err := r.fleetRayClusters(ctx, pa.Namespace, fleet)
+	if err != nil {
+		return 0, err
+	}
+	var replicas int32
+	for i := range clusters {
+		group := findWorkerGroup(&clusters[i], pa.Spec.RayWorkerGroup)
+		if group == nil {
+			return 0, fmt.Errorf("worker group %s not found in RayCluster %s", pa.Spec.RayWorkerGroup, clusters[i].Name)
+		}
+		replicas += ptr.Deref(group.Replicas, 0)
+	}
+	return replicas, nil
+}
+
+// updateRayWorkerGroupReplicas spreads the replicas of the worker group evenly over the RayClusters of the fleet.
+// KubeRay keeps the replicas of each cluster within the minReplicas and maxReplicas of the group.
+func (r *PodAutoscalerReconciler) updateRayWorkerGroupReplicas(ctx context.Context, pa *autoscalingv1alpha1.PodAutoscaler, fleet *unstructured.Unstructured, replicas int32) error {
+	clusters, err := r.fleetRayClusters(ctx, pa.Namespace, fleet)
+	if err != nil {
+		return err
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("fleet %s has no RayClusters to scale worker group %s", fleet.GetName(), pa.Spec.RayWorkerGroup)
+	}
+
+	for i, clusterReplicas := range spreadReplicas(replicas, len(clusters)) {
+		cluster := &clusters[i]
+		original := cluster.DeepCopy()
+		group := findWorkerGroup(cluster, pa.Spec.RayWorkerGroup)
+		if group == nil {
+			return fmt.Errorf("worker group %s not found in RayCluster %s", pa.Spec.RayWorkerGroup, cluster.Name)
+		}
+		if ptr.Deref(group.Replicas, 0) == clusterReplicas {
+			continue
+		}
+		group.Replicas = ptr.To(clusterReplicas)
+		if err := r.Patch(ctx, cluster, client.MergeFrom(original)); err != nil {
+			return fmt.Errorf("failed to scale worker group %s of RayCluster %s: %w", pa.Spec.RayWorkerGroup, cluster.Name, err)
+		}
+		klog.V(4).InfoS("Scaled RayCluster worker group", "RayCluster", klog.KObj(cluster),
+			"group", pa.Spec.RayWorkerGroup, "replicas", clusterReplicas)
+	}
+	return nil
+}
+
+func findWorkerGroup(cluster *rayclusterv1.RayCluster, name string) *rayclusterv1.WorkerGroupSpec {
+	for i := range cluster.Spec.WorkerGroupSpecs {
+		if cluster.Spec.WorkerGroupSpecs[i].GroupName == name {
+			return &cluster.Spec.WorkerGroupSpecs[i]
+		}
+	}
+	return nil
+}
+
+// spreadReplicas splits the replicas into n parts differing by at most one, the first parts take the remainder.
+func spreadReplicas(replicas int32, n int) []int32 {
+	parts := make([]int32, n)
+	for i := range parts {
+		parts[i] = replicas / int32(n)
+		if int32(i) < replicas%int32(n) {
+			parts[i]++
+		}
+	}
+	return parts
+}
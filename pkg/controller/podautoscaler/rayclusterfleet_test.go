@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"context"
+	"testing"
+
+	rayclusterv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+func newTestRayCluster(name string, workerReplicas int32) *rayclusterv1.RayCluster {
+	return &rayclusterv1.RayCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"model.aibrix.ai/name": "qwen"},
+		},
+		Spec: rayclusterv1.RayClusterSpec{
+			WorkerGroupSpecs: []rayclusterv1.WorkerGroupSpec{
+				{GroupName: "small-group", Replicas: ptr.To(workerReplicas)},
+			},
+		},
+	}
+}
+
+func newTestRayClusterFleetReconciler(t *testing.T) (*PodAutoscalerReconciler, *autoscalingv1alpha1.PodAutoscaler, *unstructured.Unstructured) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, rayclusterv1.AddToScheme(scheme))
+	r := &PodAutoscalerReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newTestRayCluster("qwen-b", 1),
+		newTestRayCluster("qwen-a", 1),
+	).Build()}
+
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "qwen", Namespace: "default"},
+		Spec: autoscalingv1alpha1.PodAutoscalerSpec{
+			ScaleTargetRef: corev1.ObjectReference{APIVersion: "orchestration.aibrix.ai/v1alpha1", Kind: "RayClusterFleet", Name: "qwen"},
+			RayWorkerGroup: "small-group",
+		},
+	}
+	fleet := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "qwen", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"model.aibrix.ai/name": "qwen"}},
+		},
+	}}
+	return r, pa, fleet
+}
+
+func TestRayClusterFleetPodSelectors(t *testing.T) {
+	r, pa, fleet := newTestRayClusterFleetReconciler(t)
+	assert.True(t, isRayClusterFleet(pa))
+
+	metricPods, replicaPods, err := r.podSelectors(context.Background(), pa, fleet)
+	assert.NoError(t, err)
+	head := labels.Set{rayClusterLabelKey: "qwen-a", rayNodeTypeLabelKey: "head", rayNodeGroupLabelKey: "headgroup"}
+	worker := labels.Set{rayClusterLabelKey: "qwen-b", rayNodeTypeLabelKey: "worker", rayNodeGroupLabelKey: "small-group"}
+	other := labels.Set{rayClusterLabelKey: "llama", rayNodeTypeLabelKey: "head", rayNodeGroupLabelKey: "headgroup"}
+	assert.True(t, metricPods.Matches(head))
+	assert.False(t, metricPods.Matches(worker))
+	assert.False(t, metricPods.Matches(other))
+	assert.True(t, replicaPods.Matches(worker))
+	assert.False(t, replicaPods.Matches(head))
+
+	pa.Spec.RayWorkerGroup = ""
+	_, replicaPods, err = r.podSelectors(context.Background(), pa, fleet)
+	assert.NoError(t, err)
+	assert.True(t, replicaPods.Matches(head))
+}
+
+func TestRayWorkerGroupReplicas(t *testing.T) {
+	r, pa, fleet := newTestRayClusterFleetReconciler(t)
+	ctx := context.Background()
+
+	replicas, err := r.rayWorkerGroupReplicas(ctx, pa, fleet)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), replicas)
+
+	assert.NoError(t, r.updateRayWorkerGroupReplicas(ctx, pa, fleet, 5))
+	for name, expected := range map[string]int32{"qwen-a": 3, "qwen-b": 2} {
+		cluster := &rayclusterv1.RayCluster{}
+		assert.NoError(t, r.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, cluster))
+		assert.Equal(t, expected, *cluster.Spec.WorkerGroupSpecs[0].Replicas)
+	}
+	replicas, err = r.rayWorkerGroupReplicas(ctx, pa, fleet)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), replicas)
+
+	pa.Spec.RayWorkerGroup = "large-group"
+	_, err = r.rayWorkerGroupReplicas(ctx, pa, fleet)
+	assert.Error(t, err)
+}
+
+func TestSpreadReplicas(t *testing.T) {
+	assert.Equal(t, []int32{2, 2, 1}, spreadReplicas(5, 3))
+	assert.Equal(t, []int32{0, 0}, spreadReplicas(0, 2))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/common"
+)
+
+const (
+	// scaleToZeroIdleWindowAnnotation enables scaling the target to zero once no request was seen within the window,
+	// e.g. "10m". It requires minReplicas: 0.
+	scaleToZeroIdleWindowAnnotation = common.AutoscalingLabelPrefix + "scale-to-zero-idle-window"
+	// lastRequestTimeAnnotation is set by the gateway to the time of the latest request in RFC3339, it scales the target
+	// from zero when it is newer than the last scale.
+	lastRequestTimeAnnotation = common.AutoscalingLabelPrefix + "last-request-time"
+
+	// scaleFromZeroReplicas is the number of replicas a target is scaled to on the first request, the scaling algorithm
+	// takes over once metrics are available.
+	scaleFromZeroReplicas = 1
+)
+
+// scaleToZeroIdleWindow returns the idle window after which the target is scaled to zero, false if scale-to-zero is disabled.
+func scaleToZeroIdleWindow(pa *autoscalingv1alpha1.PodAutoscaler) (time.Duration, bool) {
+	value, ok := pa.Annotations[scaleToZeroIdleWindowAnnotation]
+	if !ok {
+		return 0, false
+	}
+	if pa.Spec.MinReplicas == nil || *pa.Spec.MinReplicas != 0 {
+		klog.InfoS("ignoring scale-to-zero idle window, minReplicas is not 0", "PodAutoscaler", klog.KObj(pa))
+		return 0, false
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window <= 0 {
+		klog.ErrorS(err, "invalid scale-to-zero idle window", "PodAutoscaler", klog.KObj(pa), "value", value)
+		return 0, false
+	}
+	return window, true
+}
+
+// lastRequestTime returns the time of the latest request reported by the gateway, the zero time if none was reported.
+func lastRequestTime(pa *autoscalingv1alpha1.PodAutoscaler) time.Time {
+	value, ok := pa.Annotations[lastRequestTimeAnnotation]
+	if !ok {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		klog.ErrorS(err, "invalid last request time", "PodAutoscaler", klog.KObj(pa), "value", value)
+		return time.Time{}
+	}
+	return t
+}
+
+// scaleToZeroReplicas decides the replicas of a target with scale-to-zero enabled. It returns false if the scaling
+// algorithm decides, which is the case while the target serves requests.
+func scaleToZeroReplicas(pa *autoscalingv1alpha1.PodAutoscaler, currentReplicas int32, idleWindow time.Duration, now time.Time) (int32, string, bool) {
+	lastRequest := lastRequestTime(pa)
+	if currentReplicas == 0 {
+		// only requests after the target was scaled to zero wake it up, a target scaled to zero by hand waits for a new request too.
+		var lastScale time.Time
+		if pa.Status.LastScaleTime != nil {
+			lastScale = pa.Status.LastScaleTime.Time
+		}
+		if lastRequest.After(lastScale) && now.Sub(lastRequest) < idleWindow {
+			return scaleFromZeroReplicas, "request received while scaled to zero", true
+		}
+		return 0, "", true
+	}
+
+	// a new or just scaled target gets a full idle window to receive requests.
+	lastActive := pa.CreationTimestamp.Time
+	if pa.Status.LastScaleTime != nil && pa.Status.LastScaleTime.After(lastActive) {
+		lastActive = pa.Status.LastScaleTime.Time
+	}
+	if lastRequest.After(lastActive) {
+		lastActive = lastRequest
+	}
+	if now.Sub(lastActive) >= idleWindow {
+		return 0, fmt.Sprintf("no requests within the idle window of %s", idleWindow), true
+	}
+	return 0, "", false
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+func newScaleToZeroPA(minReplicas int32, annotations map[string]string, created time.Time, lastScale *time.Time) *autoscalingv1alpha1.PodAutoscaler {
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default", Annotations: annotations, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       autoscalingv1alpha1.PodAutoscalerSpec{MinReplicas: &minReplicas, MaxReplicas: 4},
+	}
+	if lastScale != nil {
+		t := metav1.NewTime(*lastScale)
+		pa.Status.LastScaleTime = &t
+	}
+	return pa
+}
+
+func TestScaleToZeroIdleWindow(t *testing.T) {
+	now := time.Now()
+	window, ok := scaleToZeroIdleWindow(newScaleToZeroPA(0, map[string]string{scaleToZeroIdleWindowAnnotation: "10m"}, now, nil))
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Minute, window)
+
+	_, ok = scaleToZeroIdleWindow(newScaleToZeroPA(1, map[string]string{scaleToZeroIdleWindowAnnotation: "10m"}, now, nil))
+	assert.False(t, ok, "minReplicas must be 0")
+	_, ok = scaleToZeroIdleWindow(newScaleToZeroPA(0, map[string]string{scaleToZeroIdleWindowAnnotation: "soon"}, now, nil))
+	assert.False(t, ok)
+	_, ok = scaleToZeroIdleWindow(newScaleToZeroPA(0, nil, now, nil))
+	assert.False(t, ok)
+}
+
+func TestScaleToZeroReplicas(t *testing.T) {
+	now := time.Now()
+	window := 10 * time.Minute
+	annotations := func(lastRequest time.Time) map[string]string {
+		return map[string]string{lastRequestTimeAnnotation: lastRequest.Format(time.RFC3339)}
+	}
+	longAgo := now.Add(-time.Hour)
+
+	testCases := []struct {
+		name            string
+		pa              *autoscalingv1alpha1.PodAutoscaler
+		currentReplicas int32
+		replicas        int32
+		decided         bool
+	}{
+		{"new target gets a full idle window", newScaleToZeroPA(0, nil, now.Add(-time.Minute), nil), 2, 0, false},
+		{"serving target follows the algorithm", newScaleToZeroPA(0, annotations(now.Add(-time.Minute)), longAgo, nil), 2, 0, false},
+		{"idle target scales to zero", newScaleToZeroPA(0, annotations(now.Add(-11*time.Minute)), longAgo, nil), 2, 0, true},
+		{"recent scale up is not idle", newScaleToZeroPA(0, annotations(longAgo), longAgo, &[]time.Time{now.Add(-time.Minute)}[0]), 1, 0, false},
+		{"request after scale to zero activates", newScaleToZeroPA(0, annotations(now.Add(-time.Second)), longAgo, &[]time.Time{now.Add(-time.Minute)}[0]), 0, 1, true},
+		{"request before scale to zero does not activate", newScaleToZeroPA(0, annotations(now.Add(-2*time.Minute)), longAgo, &[]time.Time{now.Add(-time.Minute)}[0]), 0, 0, true},
+		{"scaled to zero without requests stays at zero", newScaleToZeroPA(0, nil, longAgo, nil), 0, 0, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			replicas, _, decided := scaleToZeroReplicas(tc.pa, tc.currentReplicas, window, now)
+			assert.Equal(t, tc.decided, decided)
+			assert.Equal(t, tc.replicas, replicas)
+		})
+	}
+}
@@ -169,6 +169,14 @@ func (k *KpaScalingContext) UpdateByPaTypes(pa *autoscalingv1alpha1.PodAutoscale
 			k.ScaleDownDelay = v
 		}
 	}
+	// the panic threshold of the behavior takes precedence over the annotation.
+	if pa.Spec.Behavior != nil && pa.Spec.Behavior.PanicThreshold != "" {
+		v, err := strconv.ParseFloat(pa.Spec.Behavior.PanicThreshold, 64)
+		if err != nil {
+			return err
+		}
+		k.PanicThreshold = v
+	}
 
 	return nil
 }
@@ -40,6 +40,12 @@ func NewAutoscalerFactory(strategy autoscalingv1alpha1.ScalingStrategyType) (Sca
 			return nil, err
 		}
 		return autoscaler, nil
+	case autoscalingv1alpha1.SLO:
+		autoscaler, err := NewSloAutoscaler(nil)
+		if err != nil {
+			return nil, err
+		}
+		return autoscaler, nil
 	default:
 		return nil, fmt.Errorf("unsupported scaling strategy: %s", strategy)
 	}
@@ -0,0 +1,389 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/algorithm"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/common"
+	scalingcontext "github.com/vllm-project/aibrix/pkg/controller/podautoscaler/common"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/metrics"
+	enginemetrics "github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	SLOLabelPrefix                   = "slo." + scalingcontext.AutoscalingLabelPrefix
+	sloUpFluctuationToleranceLabel   = SLOLabelPrefix + "up-fluctuation-tolerance"
+	sloDownFluctuationToleranceLabel = SLOLabelPrefix + "down-fluctuation-tolerance"
+	sloWindowLabel                   = SLOLabelPrefix + "window"
+
+	// the engines report latency histograms per model, the model of a pod is read from its label.
+	sloModelIdentifier   = "model.aibrix.ai/name"
+	defaultSLOPercentile = 90
+)
+
+// podModelMetricGetter reads the metrics the cache scrapes from the engines.
+type podModelMetricGetter interface {
+	GetPodModelMetric(podName, modelName string, metricName string) (enginemetrics.MetricValue, error)
+}
+
+// SloScalingContext defines parameters for scaling on latency objectives. The current use per pod is the ratio of the
+// observed latency percentile to its target, so the target value is 1.
+type SloScalingContext struct {
+	scalingcontext.BaseScalingContext
+
+	// Percentile of the requests which should meet the targets, e.g. 90 for P90.
+	Percentile float64
+	// TTFT is the target time to first token in seconds, 0 if not targeted.
+	TTFT float64
+	// ITL is the target inter-token latency in seconds, 0 if not targeted.
+	ITL float64
+	// UpFluctuationTolerance represents the threshold before scaling up, as a fraction of the latency target.
+	UpFluctuationTolerance float64
+	// DownFluctuationTolerance represents the threshold before scaling down, as a fraction of the latency target.
+	DownFluctuationTolerance float64
+	// Window over which the latency percentiles are observed.
+	Window time.Duration
+}
+
+// NewSloScalingContext references APA and sets up a default configuration.
+func NewSloScalingContext() *SloScalingContext {
+	return &SloScalingContext{
+		BaseScalingContext:       *scalingcontext.NewBaseScalingContext(),
+		Percentile:               defaultSLOPercentile,
+		UpFluctuationTolerance:   0.1,
+		DownFluctuationTolerance: 0.2,
+		Window:                   time.Second * 60,
+	}
+}
+
+// NewSloScalingContextByPa initializes SloScalingContext by passed-in PodAutoscaler description
+func NewSloScalingContextByPa(pa *autoscalingv1alpha1.PodAutoscaler) (*SloScalingContext, error) {
+	res := NewSloScalingContext()
+	if pa == nil {
+		return res, nil
+	}
+	if err := res.UpdateByPaTypes(pa); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+var _ common.ScalingContext = (*SloScalingContext)(nil)
+
+func (s *SloScalingContext) GetUpFluctuationTolerance() float64 {
+	return s.UpFluctuationTolerance
+}
+
+func (s *SloScalingContext) GetDownFluctuationTolerance() float64 {
+	return s.DownFluctuationTolerance
+}
+
+func (s *SloScalingContext) UpdateByPaTypes(pa *autoscalingv1alpha1.PodAutoscaler) error {
+	if err := s.BaseScalingContext.UpdateByPaTypes(pa); err != nil {
+		return err
+	}
+	targets := pa.Spec.SLOTargets
+	if targets == nil {
+		return fmt.Errorf("slo targets must be set for the %s scaling strategy", autoscalingv1alpha1.SLO)
+	}
+	if targets.Percentile != "" {
+		v, err := strconv.ParseFloat(targets.Percentile, 64)
+		if err != nil || v <= 0 || v > 100 {
+			return fmt.Errorf("invalid slo percentile %q, must be within (0, 100]", targets.Percentile)
+		}
+		s.Percentile = v
+	}
+	var err error
+	if s.TTFT, err = parseLatencyTarget("ttft", targets.TTFT); err != nil {
+		return err
+	}
+	if s.ITL, err = parseLatencyTarget("itl", targets.ITL); err != nil {
+		return err
+	}
+	if s.TTFT == 0 && s.ITL == 0 {
+		return fmt.Errorf("at least one of the ttft and itl slo targets must be set")
+	}
+
+	for key, value := range pa.Annotations {
+		switch key {
+		case sloUpFluctuationToleranceLabel:
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+			s.UpFluctuationTolerance = v
+		case sloDownFluctuationToleranceLabel:
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+			s.DownFluctuationTolerance = v
+		case sloWindowLabel:
+			v, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			s.Window = v
+		}
+	}
+	return nil
+}
+
+func parseLatencyTarget(name, value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid slo %s target %q, must be a positive number of seconds", name, value)
+	}
+	return v, nil
+}
+
+// latencySample is a snapshot of the cumulative latency histograms of each pod.
+type latencySample struct {
+	timestamp time.Time
+	ttft      map[string]*enginemetrics.HistogramMetricValue
+	itl       map[string]*enginemetrics.HistogramMetricValue
+}
+
+// SloAutoscaler scales on the latency percentiles of the requests served within the window. The engines report
+// cumulative histograms, so the latencies of the window are the difference of the snapshots at its start and end.
+type SloAutoscaler struct {
+	specMux    sync.RWMutex
+	samplesMux sync.Mutex
+	podMetrics podModelMetricGetter
+
+	samples        []latencySample
+	scalingContext *SloScalingContext
+	algorithm      algorithm.ScalingAlgorithm
+}
+
+var _ Scaler = (*SloAutoscaler)(nil)
+
+// NewSloAutoscaler Initialize SloAutoscaler, it reads the latency histograms from the cache.
+func NewSloAutoscaler(pa *autoscalingv1alpha1.PodAutoscaler) (*SloAutoscaler, error) {
+	spec, err := NewSloScalingContextByPa(pa)
+	if err != nil {
+		return nil, err
+	}
+	return &SloAutoscaler{
+		scalingContext: spec,
+		// a latency ratio above the target calls for proportionally more pods, the same as APA does for usage.
+		algorithm: &algorithm.ApaScalingAlgorithm{},
+	}, nil
+}
+
+func (a *SloAutoscaler) UpdateScaleTargetMetrics(ctx context.Context, metricKey metrics.NamespaceNameMetric, source autoscalingv1alpha1.MetricSource, pods []v1.Pod, now time.Time) error {
+	if a.podMetrics == nil {
+		c, err := cache.GetCache()
+		if err != nil {
+			return fmt.Errorf("latency histograms are not available: %w", err)
+		}
+		a.podMetrics = c
+	}
+
+	sample := latencySample{
+		timestamp: now,
+		ttft:      map[string]*enginemetrics.HistogramMetricValue{},
+		itl:       map[string]*enginemetrics.HistogramMetricValue{},
+	}
+	for _, pod := range utils.FilterActivePods(pods) {
+		model := pod.Labels[sloModelIdentifier]
+		if model == "" {
+			continue
+		}
+		if histogram := a.podHistogram(pod.Name, model, enginemetrics.TimeToFirstTokenSeconds); histogram != nil {
+			sample.ttft[pod.Name] = histogram
+		}
+		if histogram := a.podHistogram(pod.Name, model, enginemetrics.TimePerOutputTokenSeconds); histogram != nil {
+			sample.itl[pod.Name] = histogram
+		}
+	}
+
+	window := a.GetScalingContext().(*SloScalingContext).Window
+	a.samplesMux.Lock()
+	defer a.samplesMux.Unlock()
+	a.samples = append(a.samples, sample)
+	// keep the latest sample from before the window as the baseline of the window.
+	start := 0
+	for i, s := range a.samples {
+		if !s.timestamp.After(now.Add(-window)) {
+			start = i
+		}
+	}
+	a.samples = a.samples[start:]
+	return nil
+}
+
+func (a *SloAutoscaler) podHistogram(podName, model, metricName string) *enginemetrics.HistogramMetricValue {
+	value, err := a.podMetrics.GetPodModelMetric(podName, model, metricName)
+	if err != nil {
+		klog.V(4).InfoS("No latency histogram for pod", "pod", podName, "model", model, "metric", metricName, "err", err)
+		return nil
+	}
+	return value.GetHistogramValue()
+}
+
+func (a *SloAutoscaler) UpdateSourceMetrics(ctx context.Context, metricKey metrics.NamespaceNameMetric, source autoscalingv1alpha1.MetricSource, now time.Time) error {
+	return fmt.Errorf("the %s scaling strategy only scales on pod metrics", autoscalingv1alpha1.SLO)
+}
+
+func (a *SloAutoscaler) Scale(originalReadyPodsCount int, metricKey metrics.NamespaceNameMetric, now time.Time) ScaleResult {
+	spec := a.GetScalingContext().(*SloScalingContext)
+	if originalReadyPodsCount == 0 {
+		klog.Errorf("Unexpected pod count for %s: %d", metricKey, originalReadyPodsCount)
+		return ScaleResult{}
+	}
+
+	a.samplesMux.Lock()
+	var first, last latencySample
+	hasWindow := len(a.samples) >= 2
+	if hasWindow {
+		first, last = a.samples[0], a.samples[len(a.samples)-1]
+	}
+	a.samplesMux.Unlock()
+
+	ratio, observed := 0.0, false
+	if hasWindow {
+		for _, target := range []struct {
+			name         string
+			value        float64
+			older, newer map[string]*enginemetrics.HistogramMetricValue
+		}{
+			{"ttft", spec.TTFT, first.ttft, last.ttft},
+			{"itl", spec.ITL, first.itl, last.itl},
+		} {
+			if target.value == 0 {
+				continue
+			}
+			latency, ok := histogramQuantile(windowHistogram(target.older, target.newer), spec.Percentile)
+			if !ok {
+				continue
+			}
+			klog.V(4).InfoS("Observed latency percentile", "metricKey", metricKey, "slo", target.name,
+				"percentile", spec.Percentile, "latency", latency, "target", target.value)
+			ratio = math.Max(ratio, latency/target.value)
+			observed = true
+		}
+	}
+	if !observed {
+		// without requests in the window the latencies say nothing about the capacity, keep the current pods.
+		klog.V(4).InfoS("No requests observed within the SLO window, keeping the current pods", "metricKey", metricKey)
+		return ScaleResult{DesiredPodCount: int32(originalReadyPodsCount), ScaleValid: true}
+	}
+
+	spec.SetCurrentUsePerPod(ratio)
+	desiredPodCount := a.algorithm.ComputeTargetReplicas(float64(originalReadyPodsCount), spec)
+	klog.InfoS("Use SLO scaling strategy", "currentPodCount", originalReadyPodsCount, "latencyRatio", ratio, "desiredPodCount", desiredPodCount)
+	return ScaleResult{
+		DesiredPodCount: desiredPodCount,
+		ScaleValid:      true,
+	}
+}
+
+func (a *SloAutoscaler) UpdateScalingContext(pa autoscalingv1alpha1.PodAutoscaler) error {
+	a.specMux.Lock()
+	defer a.specMux.Unlock()
+
+	updatedSpec, err := NewSloScalingContextByPa(&pa)
+	if err != nil {
+		return err
+	}
+	a.scalingContext = updatedSpec
+	return nil
+}
+
+func (a *SloAutoscaler) GetScalingContext() common.ScalingContext {
+	a.specMux.RLock()
+	defer a.specMux.RUnlock()
+
+	return a.scalingContext
+}
+
+// windowHistogram merges the histograms of all pods into the histogram of the requests served between the two
+// snapshots. Pods which started or restarted since the older snapshot count with all their requests.
+func windowHistogram(older, newer map[string]*enginemetrics.HistogramMetricValue) *enginemetrics.HistogramMetricValue {
+	merged := &enginemetrics.HistogramMetricValue{Buckets: map[string]float64{}}
+	for pod, n := range newer {
+		o, ok := older[pod]
+		if !ok || n.Count < o.Count {
+			o = &enginemetrics.HistogramMetricValue{}
+		}
+		merged.Count += n.Count - o.Count
+		merged.Sum += n.Sum - o.Sum
+		for bound, count := range n.Buckets {
+			merged.Buckets[bound] += count - o.Buckets[bound]
+		}
+	}
+	return merged
+}
+
+// histogramQuantile estimates the percentile of a histogram with cumulative buckets, interpolating linearly within
+// the bucket like Prometheus' histogram_quantile. It returns false if the histogram holds no requests.
+func histogramQuantile(h *enginemetrics.HistogramMetricValue, percentile float64) (float64, bool) {
+	if h.Count <= 0 {
+		return 0, false
+	}
+	type bucket struct {
+		bound float64
+		count float64
+	}
+	buckets := make([]bucket, 0, len(h.Buckets))
+	for bound, count := range h.Buckets {
+		if bound == "+Inf" {
+			continue
+		}
+		v, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			continue
+		}
+		buckets = append(buckets, bucket{bound: v, count: count})
+	}
+	if len(buckets) == 0 {
+		return 0, false
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].bound < buckets[j].bound })
+
+	rank := percentile / 100 * h.Count
+	lowerBound, lowerCount := 0.0, 0.0
+	for _, b := range buckets {
+		if b.count >= rank {
+			if b.count == lowerCount {
+				return b.bound, true
+			}
+			return lowerBound + (b.bound-lowerBound)*(rank-lowerCount)/(b.count-lowerCount), true
+		}
+		lowerBound, lowerCount = b.bound, b.count
+	}
+	// the percentile lies in the +Inf bucket, the highest finite bound is the best estimate.
+	return lowerBound, true
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/cache/fakecache"
+	"github.com/vllm-project/aibrix/pkg/controller/podautoscaler/metrics"
+	enginemetrics "github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+// ttftHistogram returns a cumulative histogram with the given number of requests per bucket.
+func ttftHistogram(fast, medium, slow float64) *enginemetrics.HistogramMetricValue {
+	return &enginemetrics.HistogramMetricValue{
+		Count: fast + medium + slow,
+		Buckets: map[string]float64{
+			"0.1":  fast,
+			"0.5":  fast + medium,
+			"1.0":  fast + medium + slow,
+			"+Inf": fast + medium + slow,
+		},
+	}
+}
+
+func newSloPA(targets autoscalingv1alpha1.SLOTargets) *autoscalingv1alpha1.PodAutoscaler {
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default"},
+		Spec: autoscalingv1alpha1.PodAutoscalerSpec{
+			ScalingStrategy: autoscalingv1alpha1.SLO,
+			SLOTargets:      &targets,
+		},
+	}
+	_, sources, _ := metrics.NewNamespaceNameMetrics(pa)
+	pa.Spec.MetricsSources = sources
+	return pa
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	_, ok := histogramQuantile(&enginemetrics.HistogramMetricValue{}, 90)
+	assert.False(t, ok)
+
+	// 50 requests within 0.1s, 50 within 0.5s: P90 lies 80% into the 0.1-0.5 bucket.
+	p90, ok := histogramQuantile(ttftHistogram(50, 50, 0), 90)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.42, p90, 1e-9)
+
+	p50, _ := histogramQuantile(ttftHistogram(50, 50, 0), 50)
+	assert.InDelta(t, 0.1, p50, 1e-9)
+}
+
+func TestWindowHistogram(t *testing.T) {
+	older := map[string]*enginemetrics.HistogramMetricValue{
+		"pod-a": ttftHistogram(100, 0, 0),
+		"pod-b": ttftHistogram(500, 0, 0),
+	}
+	newer := map[string]*enginemetrics.HistogramMetricValue{
+		"pod-a": ttftHistogram(110, 0, 10),
+		"pod-b": ttftHistogram(5, 5, 0), // restarted
+		"pod-c": ttftHistogram(0, 10, 0),
+	}
+	window := windowHistogram(older, newer)
+	assert.Equal(t, 40.0, window.Count)
+	assert.Equal(t, 15.0, window.Buckets["0.1"])
+	assert.Equal(t, 30.0, window.Buckets["0.5"])
+	assert.Equal(t, 40.0, window.Buckets["1.0"])
+}
+
+func TestSloScalingContext(t *testing.T) {
+	spec, err := NewSloScalingContextByPa(newSloPA(autoscalingv1alpha1.SLOTargets{Percentile: "99", TTFT: "0.5"}))
+	assert.NoError(t, err)
+	assert.Equal(t, 99.0, spec.Percentile)
+	assert.Equal(t, 0.5, spec.TTFT)
+	assert.Equal(t, 0.0, spec.ITL)
+	assert.Equal(t, 1.0, spec.GetTargetValue())
+
+	_, err = NewSloScalingContextByPa(newSloPA(autoscalingv1alpha1.SLOTargets{Percentile: "99"}))
+	assert.Error(t, err, "at least one target is required")
+	_, err = NewSloScalingContextByPa(newSloPA(autoscalingv1alpha1.SLOTargets{Percentile: "101", TTFT: "0.5"}))
+	assert.Error(t, err)
+	_, err = NewSloScalingContextByPa(newSloPA(autoscalingv1alpha1.SLOTargets{ITL: "-1"}))
+	assert.Error(t, err)
+}
+
+func TestSloScale(t *testing.T) {
+	pa := newSloPA(autoscalingv1alpha1.SLOTargets{TTFT: "0.25"})
+	metricKeys, _, err := metrics.NewNamespaceNameMetrics(pa)
+	assert.NoError(t, err)
+	metricKey := metricKeys[0]
+
+	podMetrics := fakecache.New()
+	pods := []v1.Pod{}
+	for _, name := range []string{"pod-a", "pod-b"} {
+		pods = append(pods, v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{sloModelIdentifier: "llama"}},
+			Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				PodIP:      "10.0.0.1",
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			},
+		})
+		podMetrics.SetPodModelMetric(name, "llama", enginemetrics.TimeToFirstTokenSeconds, ttftHistogram(1000, 0, 0))
+	}
+	autoscaler, err := NewSloAutoscaler(pa)
+	assert.NoError(t, err)
+	autoscaler.podMetrics = podMetrics
+
+	now := time.Now()
+	assert.NoError(t, autoscaler.UpdateScaleTargetMetrics(context.Background(), metricKey, pa.Spec.MetricsSources[0], pods, now))
+	result := autoscaler.Scale(2, metricKey, now)
+	assert.True(t, result.ScaleValid)
+	assert.Equal(t, int32(2), result.DesiredPodCount, "a single snapshot holds no window")
+
+	// within the window the P90 of 0.42s is well above the 0.25s target, despite the fast requests before.
+	for _, name := range []string{"pod-a", "pod-b"} {
+		podMetrics.SetPodModelMetric(name, "llama", enginemetrics.TimeToFirstTokenSeconds, ttftHistogram(1050, 50, 0))
+	}
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, autoscaler.UpdateScaleTargetMetrics(context.Background(), metricKey, pa.Spec.MetricsSources[0], pods, now))
+	result = autoscaler.Scale(2, metricKey, now)
+	assert.True(t, result.ScaleValid)
+	assert.Equal(t, int32(4), result.DesiredPodCount)
+
+	// no requests since the snapshot at the start of the window.
+	now = now.Add(90 * time.Second)
+	assert.NoError(t, autoscaler.UpdateScaleTargetMetrics(context.Background(), metricKey, pa.Spec.MetricsSources[0], pods, now))
+	result = autoscaler.Scale(4, metricKey, now)
+	assert.Equal(t, int32(4), result.DesiredPodCount)
+}
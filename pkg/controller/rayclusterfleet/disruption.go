@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rayclusterfleet
+
+import (
+	"context"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/rayclusterfleet/util"
+)
+
+// disruptionBudgetName returns the name of the PodDisruptionBudget over the head pods of the fleet.
+func disruptionBudgetName(f *orchestrationv1alpha1.RayClusterFleet) string {
+	return f.Name + "-head"
+}
+
+// constructDisruptionBudget returns the PodDisruptionBudget the fleet asks for, nil if it has none.
+func constructDisruptionBudget(f *orchestrationv1alpha1.RayClusterFleet) *policyv1.PodDisruptionBudget {
+	if f.Spec.DisruptionBudget == nil {
+		return nil
+	}
+	maxUnavailable := ptr.Deref(f.Spec.DisruptionBudget.MaxUnavailable, intstr.FromInt32(1))
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            disruptionBudgetName(f),
+			Namespace:       f.Namespace,
+			Labels:          map[string]string{util.FleetNameLabelKey: f.Name},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(f, controllerKind)},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: util.HeadPodLabels(f.Name)},
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
+
+// reconcileDisruptionBudget creates, updates or deletes the PodDisruptionBudget over the head pods of the fleet.
+func (r *RayClusterFleetReconciler) reconcileDisruptionBudget(ctx context.Context, f *orchestrationv1alpha1.RayClusterFleet) error {
+	desired := constructDisruptionBudget(f)
+
+	found := &policyv1.PodDisruptionBudget{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: f.Namespace, Name: disruptionBudgetName(f)}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(found, f) {
+		klog.InfoS("PodDisruptionBudget is not controlled by the fleet, skip it", "fleet", klog.KObj(f), "podDisruptionBudget", klog.KObj(found))
+		return nil
+	}
+
+	switch {
+	case desired == nil && exists:
+		klog.InfoS("Deleting PodDisruptionBudget", "fleet", klog.KObj(f), "podDisruptionBudget", klog.KObj(found))
+		return client.IgnoreNotFound(r.Delete(ctx, found))
+	case desired == nil:
+		return nil
+	case !exists:
+		klog.InfoS("Creating PodDisruptionBudget", "fleet", klog.KObj(f), "podDisruptionBudget", klog.KObj(desired))
+		return r.Create(ctx, desired)
+	case !apiequality.Semantic.DeepEqual(found.Spec.Selector, desired.Spec.Selector) ||
+		!apiequality.Semantic.DeepEqual(found.Spec.MaxUnavailable, desired.Spec.MaxUnavailable):
+		found.Spec.Selector = desired.Spec.Selector
+		found.Spec.MaxUnavailable = desired.Spec.MaxUnavailable
+		klog.InfoS("Updating PodDisruptionBudget", "fleet", klog.KObj(f), "podDisruptionBudget", klog.KObj(found))
+		return r.Update(ctx, found)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rayclusterfleet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/controller/rayclusterfleet/util"
+)
+
+func TestConstructDisruptionBudget(t *testing.T) {
+	fleet := &orchestrationv1alpha1.RayClusterFleet{
+		ObjectMeta: metav1.ObjectMeta{Name: "qwen", Namespace: "default"},
+	}
+	assert.Nil(t, constructDisruptionBudget(fleet))
+
+	fleet.Spec.DisruptionBudget = &orchestrationv1alpha1.RayClusterFleetDisruptionBudget{}
+	pdb := constructDisruptionBudget(fleet)
+	assert.Equal(t, "qwen-head", pdb.Name)
+	assert.Equal(t, util.HeadPodLabels("qwen"), pdb.Spec.Selector.MatchLabels)
+	assert.Equal(t, intstr.FromInt32(1), *pdb.Spec.MaxUnavailable)
+	assert.True(t, metav1.IsControlledBy(pdb, fleet))
+
+	fleet.Spec.DisruptionBudget.MaxUnavailable = ptr.To(intstr.FromString("25%"))
+	assert.Equal(t, intstr.FromString("25%"), *constructDisruptionBudget(fleet).Spec.MaxUnavailable)
+}
@@ -59,6 +59,15 @@ func (r *RayClusterFleetReconciler) syncRolloutStatus(ctx context.Context, allRS
 			condition := util.NewDeploymentCondition(orchestrationv1alpha1.RayClusterFleetProgressing, corev1.ConditionTrue, util.NewRSAvailableReason, msg)
 			util.SetDeploymentCondition(&newStatus, *condition)
 
+		case util.DeploymentPartitioned(d, &newStatus):
+			// The rollout waits for the partition to be lowered, which is not a lack of progress.
+			msg := fmt.Sprintf("Deployment %q is rolled out up to partition %d.", d.Name, util.Partition(d))
+			if newRS != nil {
+				msg = fmt.Sprintf("ReplicaSet %q is rolled out up to partition %d.", newRS.Name, util.Partition(d))
+			}
+			condition := util.NewDeploymentCondition(orchestrationv1alpha1.RayClusterFleetProgressing, corev1.ConditionUnknown, util.PartitionedDeployReason, msg)
+			util.SetDeploymentCondition(&newStatus, *condition)
+
 		case util.DeploymentProgressing(d, &newStatus):
 			// If there is any progress made, continue by not checking if the deployment failed. This
 			// behavior emulates the rolling updater progressDeadline check.
@@ -161,7 +170,7 @@ func (r *RayClusterFleetReconciler) requeueStuckDeployment(ctx context.Context,
 		return time.Duration(-1)
 	}
 	// No need to estimate progress if the rollout is complete or already timed out.
-	if util.DeploymentComplete(d, &newStatus) || currentCond.Reason == util.TimedOutReason {
+	if util.DeploymentComplete(d, &newStatus) || util.DeploymentPartitioned(d, &newStatus) || currentCond.Reason == util.TimedOutReason {
 		return time.Duration(-1)
 	}
 	// If there is no sign of progress at this point then there is a high chance that the
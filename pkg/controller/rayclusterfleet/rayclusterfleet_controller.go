@@ -26,6 +26,7 @@ import (
 	"github.com/vllm-project/aibrix/pkg/controller/util/expectation"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -79,6 +80,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		For(&orchestrationv1alpha1.RayClusterFleet{}).
 		Owns(&orchestrationv1alpha1.RayClusterReplicaSet{}).
 		Owns(&rayclusterv1.RayCluster{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
 		Complete(r)
 
 	klog.V(4).InfoS("Finished to add model-adapter-controller")
@@ -101,6 +103,7 @@ type RayClusterFleetReconciler struct {
 // +kubebuilder:rbac:groups=orchestration.aibrix.ai,resources=rayclusterfleets/finalizers,verbs=update
 // +kubebuilder:rbac:groups=orchestration.aibrix.ai,resources=rayclusterreplicasets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=orchestration.aibrix.ai,resources=rayclusterreplicasets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile method moves the RayClusterFleet to the desired State
 func (r *RayClusterFleetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -149,6 +152,10 @@ func (r *RayClusterFleetReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, r.syncStatusOnly(ctx, f, rsList)
 	}
 
+	if err := r.reconcileDisruptionBudget(ctx, f); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// check whether the fleet is in pause status
 	if err := r.checkPausedConditions(ctx, f); err != nil {
 		return ctrl.Result{}, err
@@ -126,6 +126,8 @@ func (r *RayClusterFleetReconciler) reconcileOldReplicaSets(ctx context.Context,
 	minAvailable := *(deployment.Spec.Replicas) - maxUnavailable
 	newRSUnavailablePodCount := *(newRS.Spec.Replicas) - newRS.Status.AvailableReplicas
 	maxScaledDown := allPodsCount - minAvailable - newRSUnavailablePodCount
+	// Old ray clusters within the partition are not rolled out.
+	maxScaledDown = min(maxScaledDown, oldPodsCount-util.Partition(deployment))
 	if maxScaledDown <= 0 {
 		return false, nil
 	}
@@ -206,7 +208,7 @@ func (r *RayClusterFleetReconciler) scaleDownOldReplicaSetsForRollingUpdate(ctx
 	sort.Sort(util.ReplicaSetsByCreationTimestamp(oldRSs))
 
 	totalScaledDown := int32(0)
-	totalScaleDownCount := availablePodCount - minAvailable
+	totalScaleDownCount := min(availablePodCount-minAvailable, util.GetReplicaCountForReplicaSets(oldRSs)-util.Partition(deployment))
 	for _, targetRS := range oldRSs {
 		if totalScaledDown >= totalScaleDownCount {
 			// No further scaling required.
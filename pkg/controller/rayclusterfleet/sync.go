@@ -28,6 +28,7 @@ import (
 	labelsutil "github.com/vllm-project/aibrix/pkg/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
@@ -98,8 +99,7 @@ func (r *RayClusterFleetReconciler) checkPausedConditions(ctx context.Context, d
 		return nil
 	}
 
-	err := r.Update(ctx, d)
-	return err
+	return r.Status().Update(ctx, d)
 }
 
 // getAllReplicaSetsAndSyncRevision returns all the replica sets for the provided deployment (new and all old), with new RS's and deployment's revision updated.
@@ -154,8 +154,15 @@ func (r *RayClusterFleetReconciler) getNewReplicaSet(ctx context.Context, d *orc
 		// Set existing new replica set's annotation
 		annotationsUpdated := util.SetNewReplicaSetAnnotations(ctx, d, rsCopy, newRevision, true, maxRevHistoryLengthInChars)
 		minReadySecondsNeedsUpdate := rsCopy.Spec.MinReadySeconds != d.Spec.MinReadySeconds
-		if annotationsUpdated || minReadySecondsNeedsUpdate {
+		spreadConstraints := util.HeadTopologySpreadConstraints(d)
+		spreadConstraintsNeedUpdate := !apiequality.Semantic.DeepEqual(rsCopy.Spec.TopologySpreadConstraints, spreadConstraints)
+		gangSchedulingNeedsUpdate := !apiequality.Semantic.DeepEqual(rsCopy.Spec.GangScheduling, d.Spec.GangScheduling)
+		servingProbeNeedsUpdate := !apiequality.Semantic.DeepEqual(rsCopy.Spec.ServingProbe, d.Spec.ServingProbe)
+		if annotationsUpdated || minReadySecondsNeedsUpdate || spreadConstraintsNeedUpdate || gangSchedulingNeedsUpdate || servingProbeNeedsUpdate {
 			rsCopy.Spec.MinReadySeconds = d.Spec.MinReadySeconds
+			rsCopy.Spec.TopologySpreadConstraints = spreadConstraints
+			rsCopy.Spec.GangScheduling = d.Spec.GangScheduling.DeepCopy()
+			rsCopy.Spec.ServingProbe = d.Spec.ServingProbe.DeepCopy()
 
 			if err := r.Update(ctx, rsCopy); err != nil {
 				return nil, err
@@ -165,7 +172,10 @@ func (r *RayClusterFleetReconciler) getNewReplicaSet(ctx context.Context, d *orc
 		}
 
 		// Should use the revision in existingNewRS's annotation, since it set by before
-		needsUpdate := util.SetDeploymentRevision(d, rsCopy.Annotations[util.RevisionAnnotation])
+		if err := r.syncFleetRevision(ctx, d, rsCopy.Annotations[util.RevisionAnnotation]); err != nil {
+			return nil, err
+		}
+		needsUpdate := false
 		// If no other Progressing condition has been recorded and we need to estimate the progress
 		// of this deployment then it is likely that old users started caring about progress. In that
 		// case we need to take into account the first time we noticed their new replica set.
@@ -207,10 +217,13 @@ func (r *RayClusterFleetReconciler) getNewReplicaSet(ctx context.Context, d *orc
 			Labels:          newRSTemplate.Labels,
 		},
 		Spec: orchestrationv1alpha1.RayClusterReplicaSetSpec{
-			Replicas:        new(int32),
-			MinReadySeconds: d.Spec.MinReadySeconds,
-			Selector:        newRSSelector,
-			Template:        newRSTemplate,
+			Replicas:                  new(int32),
+			MinReadySeconds:           d.Spec.MinReadySeconds,
+			Selector:                  newRSSelector,
+			Template:                  newRSTemplate,
+			TopologySpreadConstraints: util.HeadTopologySpreadConstraints(d),
+			GangScheduling:            d.Spec.GangScheduling.DeepCopy(),
+			ServingProbe:              d.Spec.ServingProbe.DeepCopy(),
 		},
 	}
 	allRSs := append(oldRSs, &newRS)
@@ -285,7 +298,10 @@ func (r *RayClusterFleetReconciler) getNewReplicaSet(ctx context.Context, d *orc
 		r.Recorder.Eventf(d, v1.EventTypeNormal, "ScalingReplicaSet", "Scaled up replica set %s to %d", createdRS.Name, newReplicasCount)
 	}
 
-	needsUpdate := util.SetDeploymentRevision(d, newRevision)
+	if err := r.syncFleetRevision(ctx, d, newRevision); err != nil {
+		return nil, err
+	}
+	needsUpdate := false
 	if !alreadyExists && util.HasProgressDeadline(d) {
 		msg := fmt.Sprintf("Created new replica set %q", createdRS.Name)
 		condition := util.NewDeploymentCondition(orchestrationv1alpha1.RayClusterFleetProgressing, v1.ConditionTrue, util.NewReplicaSetReason, msg)
@@ -298,6 +314,22 @@ func (r *RayClusterFleetReconciler) getNewReplicaSet(ctx context.Context, d *orc
 	return createdRS, err
 }
 
+// syncFleetRevision records the revision of the new replica set on the fleet. The fleet is a custom
+// resource with a status subresource, so the annotation has to be written through the main resource
+// instead of along with the status like the Deployment controller does.
+func (r *RayClusterFleetReconciler) syncFleetRevision(ctx context.Context, d *orchestrationv1alpha1.RayClusterFleet, revision string) error {
+	if !util.SetDeploymentRevision(d, revision) {
+		return nil
+	}
+	// Update overwrites d with the stored object, keep the status computed in this sync.
+	status := d.Status.DeepCopy()
+	if err := r.Update(ctx, d); err != nil {
+		return err
+	}
+	d.Status = *status
+	return nil
+}
+
 // scale scales proportionally in order to mitigate risk. Otherwise, scaling up can increase the size
 // of the new replica set and scaling down can decrease the sizes of the old ones, both of which would
 // have the effect of hastening the rollout progress, which could produce a higher proportion of unavailable
@@ -514,6 +546,7 @@ func calculateStatus(allRSs []*orchestrationv1alpha1.RayClusterReplicaSet, newRS
 		AvailableReplicas:   availableReplicas,
 		UnavailableReplicas: unavailableReplicas,
 		CollisionCount:      deployment.Status.CollisionCount,
+		ServingStatuses:     util.GetServingStatusesForReplicaSets(allRSs),
 	}
 
 	// Copy conditions one by one so we won't mutate the original object.
@@ -28,6 +28,7 @@ import (
 	"strings"
 	"time"
 
+	rayclusterv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
 	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
 	labelsutil "github.com/vllm-project/aibrix/pkg/utils"
 	appsv1 "k8s.io/api/apps/v1"
@@ -62,6 +63,12 @@ const (
 	// proportions in case the deployment has surge replicas.
 	MaxReplicasAnnotation = "deployment.kubernetes.io/max-replicas"
 
+	// FleetNameLabelKey is added to the head pods of the ray clusters of a fleet. The spread constraints
+	// and the PodDisruptionBudget of the fleet select the head pods with it.
+	FleetNameLabelKey = "orchestration.aibrix.ai/raycluster-fleet-name"
+	// rayNodeTypeLabelKey is the label KubeRay sets on ray pods with the type of the node, head or worker.
+	rayNodeTypeLabelKey = "ray.io/node-type"
+
 	// RollbackRevisionNotFound is not found rollback event reason
 	RollbackRevisionNotFound = "DeploymentRollbackRevisionNotFound"
 	// RollbackTemplateUnchanged is the template unchanged rollback event reason
@@ -95,6 +102,9 @@ const (
 	// ResumedDeployReason is added in a deployment when it is resumed. Useful for not failing accidentally
 	// deployments that paused amidst a rollout and are bounded by a deadline.
 	ResumedDeployReason = "DeploymentResumed"
+	// PartitionedDeployReason is added in a fleet when its rollout reached the partition. Lack of progress
+	// shouldn't be estimated until the partition is lowered.
+	PartitionedDeployReason = "DeploymentPartitioned"
 	//
 	// Available:
 
@@ -704,6 +714,20 @@ func GetReadyReplicaCountForReplicaSets(replicaSets []*orchestrationv1alpha1.Ray
 	return totalReadyReplicas
 }
 
+// GetServingStatusesForReplicaSets returns the serving state of the ray clusters of the given replica sets, sorted by name.
+func GetServingStatusesForReplicaSets(replicaSets []*orchestrationv1alpha1.RayClusterReplicaSet) []orchestrationv1alpha1.RayClusterServingStatus {
+	var statuses []orchestrationv1alpha1.RayClusterServingStatus
+	for _, rs := range replicaSets {
+		if rs != nil {
+			statuses = append(statuses, rs.Status.ServingStatuses...)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
 // GetAvailableReplicaCountForReplicaSets returns the number of available pods corresponding to the given replica sets.
 func GetAvailableReplicaCountForReplicaSets(replicaSets []*orchestrationv1alpha1.RayClusterReplicaSet) int32 {
 	totalAvailableReplicas := int32(0)
@@ -720,6 +744,25 @@ func IsRollingUpdate(deployment *orchestrationv1alpha1.RayClusterFleet) bool {
 	return deployment.Spec.Strategy.Type == appsv1.RollingUpdateDeploymentStrategyType
 }
 
+// Partition returns the number of ray clusters a rolling update of the fleet keeps on the previous revisions.
+func Partition(deployment *orchestrationv1alpha1.RayClusterFleet) int32 {
+	if !IsRollingUpdate(deployment) || deployment.Spec.Partition == nil || *deployment.Spec.Partition <= 0 {
+		return 0
+	}
+	return min(*deployment.Spec.Partition, *(deployment.Spec.Replicas))
+}
+
+// DeploymentPartitioned considers a fleet to be partitioned once the ray clusters above the partition
+// are updated and all desired replicas are available.
+func DeploymentPartitioned(deployment *orchestrationv1alpha1.RayClusterFleet, newStatus *orchestrationv1alpha1.RayClusterFleetStatus) bool {
+	partition := Partition(deployment)
+	return partition > 0 &&
+		newStatus.UpdatedReplicas == *(deployment.Spec.Replicas)-partition &&
+		newStatus.Replicas == *(deployment.Spec.Replicas) &&
+		newStatus.AvailableReplicas == *(deployment.Spec.Replicas) &&
+		newStatus.ObservedGeneration >= deployment.Generation
+}
+
 // DeploymentComplete considers a deployment to be complete once all of its desired replicas
 // are updated and available, and no old pods are running.
 func DeploymentComplete(deployment *orchestrationv1alpha1.RayClusterFleet, newStatus *orchestrationv1alpha1.RayClusterFleetStatus) bool {
@@ -775,7 +818,7 @@ func DeploymentTimedOut(ctx context.Context, deployment *orchestrationv1alpha1.R
 	//
 	// The Deployment will be resynced and eventually its Progressing condition will catch
 	// up with the state of the world.
-	if condition.Reason == NewRSAvailableReason {
+	if condition.Reason == NewRSAvailableReason || condition.Reason == PartitionedDeployReason {
 		return false
 	}
 	if condition.Reason == TimedOutReason {
@@ -813,10 +856,15 @@ func NewRSNewReplicas(deployment *orchestrationv1alpha1.RayClusterFleet, allRSs
 			// Cannot scale up.
 			return *(newRS.Spec.Replicas), nil
 		}
+		// Keep the partition on the old replica sets.
+		maxNewPods := *(deployment.Spec.Replicas) - Partition(deployment)
+		if *(newRS.Spec.Replicas) >= maxNewPods {
+			return *(newRS.Spec.Replicas), nil
+		}
 		// Scale up.
 		scaleUpCount := maxTotalPods - currentPodCount
 		// Do not exceed the number of desired replicas.
-		scaleUpCount = min(scaleUpCount, *(deployment.Spec.Replicas)-*(newRS.Spec.Replicas))
+		scaleUpCount = min(scaleUpCount, maxNewPods-*(newRS.Spec.Replicas))
 		return *(newRS.Spec.Replicas) + scaleUpCount, nil
 	case appsv1.RecreateDeploymentStrategyType:
 		return *(deployment.Spec.Replicas), nil
@@ -1078,3 +1126,28 @@ func listOptionsToControllerRuntime(listOpts metav1.ListOptions) []client.ListOp
 
 	return options
 }
+
+// HeadPodLabels returns the labels that select the head pods of the ray clusters of a fleet.
+func HeadPodLabels(fleetName string) map[string]string {
+	return map[string]string{
+		FleetNameLabelKey:   fleetName,
+		rayNodeTypeLabelKey: string(rayclusterv1.HeadNode),
+	}
+}
+
+// HeadTopologySpreadConstraints returns the spread constraints of the fleet, the ones without label
+// selector select the head pods of the fleet.
+func HeadTopologySpreadConstraints(fleet *orchestrationv1alpha1.RayClusterFleet) []v1.TopologySpreadConstraint {
+	if len(fleet.Spec.TopologySpreadConstraints) == 0 {
+		return nil
+	}
+	constraints := make([]v1.TopologySpreadConstraint, 0, len(fleet.Spec.TopologySpreadConstraints))
+	for _, constraint := range fleet.Spec.TopologySpreadConstraints {
+		constraint := *constraint.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{MatchLabels: HeadPodLabels(fleet.Name)}
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints
+}
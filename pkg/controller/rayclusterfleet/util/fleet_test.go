@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+)
+
+func rollingFleet(replicas int32, partition *int32) *orchestrationv1alpha1.RayClusterFleet {
+	return &orchestrationv1alpha1.RayClusterFleet{
+		Spec: orchestrationv1alpha1.RayClusterFleetSpec{
+			Replicas:  ptr.To(replicas),
+			Partition: partition,
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxSurge:       ptr.To(intstr.FromInt32(2)),
+					MaxUnavailable: ptr.To(intstr.FromInt32(0)),
+				},
+			},
+		},
+	}
+}
+
+func replicaSet(replicas int32) *orchestrationv1alpha1.RayClusterReplicaSet {
+	return &orchestrationv1alpha1.RayClusterReplicaSet{
+		Spec: orchestrationv1alpha1.RayClusterReplicaSetSpec{Replicas: ptr.To(replicas)},
+	}
+}
+
+func TestPartition(t *testing.T) {
+	assert.Equal(t, int32(0), Partition(rollingFleet(4, nil)))
+	assert.Equal(t, int32(3), Partition(rollingFleet(4, ptr.To[int32](3))))
+	assert.Equal(t, int32(4), Partition(rollingFleet(4, ptr.To[int32](10))))
+
+	recreate := rollingFleet(4, ptr.To[int32](3))
+	recreate.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	assert.Equal(t, int32(0), Partition(recreate))
+}
+
+func TestNewRSNewReplicasWithPartition(t *testing.T) {
+	tests := []struct {
+		name      string
+		partition *int32
+		oldRS     int32
+		newRS     int32
+		expected  int32
+	}{
+		{name: "no partition scales up by surge", oldRS: 4, newRS: 0, expected: 2},
+		{name: "partition caps the new replica set", partition: ptr.To[int32](3), oldRS: 4, newRS: 0, expected: 1},
+		{name: "partition reached", partition: ptr.To[int32](3), oldRS: 3, newRS: 1, expected: 1},
+		{name: "raised partition doesn't scale down updated clusters", partition: ptr.To[int32](3), oldRS: 2, newRS: 2, expected: 2},
+		{name: "partition above replicas holds the rollout", partition: ptr.To[int32](5), oldRS: 4, newRS: 0, expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fleet := rollingFleet(4, tt.partition)
+			newRS := replicaSet(tt.newRS)
+			allRSs := []*orchestrationv1alpha1.RayClusterReplicaSet{replicaSet(tt.oldRS), newRS}
+			replicas, err := NewRSNewReplicas(fleet, allRSs, newRS)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, replicas)
+		})
+	}
+}
+
+func TestDeploymentPartitioned(t *testing.T) {
+	fleet := rollingFleet(4, ptr.To[int32](1))
+	status := &orchestrationv1alpha1.RayClusterFleetStatus{Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 4}
+	assert.True(t, DeploymentPartitioned(fleet, status))
+	assert.False(t, DeploymentComplete(fleet, status))
+
+	status.AvailableReplicas = 3
+	assert.False(t, DeploymentPartitioned(fleet, status))
+
+	assert.False(t, DeploymentPartitioned(rollingFleet(4, nil), &orchestrationv1alpha1.RayClusterFleetStatus{Replicas: 4, UpdatedReplicas: 4, AvailableReplicas: 4}))
+}
+
+func TestHeadTopologySpreadConstraints(t *testing.T) {
+	fleet := rollingFleet(4, nil)
+	fleet.Name = "qwen"
+	assert.Nil(t, HeadTopologySpreadConstraints(fleet))
+
+	custom := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "qwen"}}
+	fleet.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+		{MaxSkew: 1, TopologyKey: corev1.LabelTopologyZone, WhenUnsatisfiable: corev1.DoNotSchedule},
+		{MaxSkew: 1, TopologyKey: corev1.LabelHostname, WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: custom},
+	}
+	constraints := HeadTopologySpreadConstraints(fleet)
+	assert.Len(t, constraints, 2)
+	assert.Equal(t, map[string]string{FleetNameLabelKey: "qwen", "ray.io/node-type": "head"}, constraints[0].LabelSelector.MatchLabels)
+	assert.Equal(t, custom, constraints[1].LabelSelector)
+	// the spec of the fleet is left untouched.
+	assert.Nil(t, fleet.Spec.TopologySpreadConstraints[0].LabelSelector)
+}
@@ -65,6 +65,7 @@ func newReconciler(mgr manager.Manager, runtimeConfig config.RuntimeConfig) (rec
 		Scheme:        mgr.GetScheme(),
 		Recorder:      mgr.GetEventRecorderFor(controllerName),
 		Expectations:  expectation.NewControllerExpectations(),
+		Prober:        &defaultServingProber{},
 		RuntimeConfig: runtimeConfig,
 	}
 	return reconciler, nil
@@ -94,7 +95,9 @@ type RayClusterReplicaSetReconciler struct {
 	// For example, there is a RayClusterReplicaSet with namespace "aibrix", name "llama7b" and replica 3,
 	// We will create the expectation:
 	// - "aibrix/llama7b", expects 3 adds.
-	Expectations  expectation.ControllerExpectationsInterface
+	Expectations expectation.ControllerExpectationsInterface
+	// Prober checks the model servers of the ray clusters when the replica set has a serving probe.
+	Prober        ServingProber
 	RuntimeConfig config.RuntimeConfig
 }
 
@@ -152,11 +155,16 @@ func (r *RayClusterReplicaSetReconciler) Reconcile(ctx context.Context, req ctrl
 	}
 
 	// status update if necessary
-	newStatus := calculateStatus(replicaset, filteredClusters, scaleError)
+	servingStatuses := r.probeServing(ctx, replicaset, filteredClusters)
+	newStatus := calculateStatus(replicaset, filteredClusters, servingStatuses, scaleError)
 	if err := r.updateReplicaSetStatus(replicaset, newStatus, rsKey); err != nil {
 		return reconcile.Result{}, err
 	}
 
+	// model servers don't notify us when they start or stop serving, probe them periodically.
+	if probe := replicaset.Spec.ServingProbe; probe != nil {
+		return ctrl.Result{RequeueAfter: time.Duration(probe.PeriodSeconds) * time.Second}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -18,9 +18,12 @@ package rayclusterreplicaset
 
 import (
 	"reflect"
+	"sort"
+	"time"
 
 	rayclusterv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
 	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+	fleetutil "github.com/vllm-project/aibrix/pkg/controller/rayclusterfleet/util"
 	rayclusterutil "github.com/vllm-project/aibrix/pkg/utils"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -79,6 +82,14 @@ func filterOutCondition(conditions []metav1.Condition, condType string) []metav1
 
 // Helper function to construct a new Pod from a ReplicaSet
 func constructRayCluster(replicaset *orchestrationv1alpha1.RayClusterReplicaSet) *rayclusterv1.RayCluster {
+	spec := replicaset.Spec.Template.Spec.DeepCopy()
+	headTemplate := &spec.HeadGroupSpec.Template
+	// label the head pods of a fleet, its spread constraints and disruption budget select them.
+	if ref := metav1.GetControllerOf(replicaset); ref != nil && ref.Kind == "RayClusterFleet" {
+		headTemplate.Labels = rayclusterutil.CloneAndAddLabel(headTemplate.Labels, fleetutil.FleetNameLabelKey, ref.Name)
+	}
+	headTemplate.Spec.TopologySpreadConstraints = append(headTemplate.Spec.TopologySpreadConstraints, replicaset.Spec.TopologySpreadConstraints...)
+
 	cluster := &rayclusterv1.RayCluster{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: replicaset.Name + "-",
@@ -89,12 +100,62 @@ func constructRayCluster(replicaset *orchestrationv1alpha1.RayClusterReplicaSet)
 				*metav1.NewControllerRef(replicaset, controllerKind),
 			},
 		},
-		Spec: replicaset.Spec.Template.Spec,
+		Spec: *spec,
 	}
+	applyGangScheduling(cluster, replicaset.Spec.GangScheduling)
 
 	return cluster
 }
 
+const (
+	// rayBatchSchedulerLabelKey selects the batch scheduler KubeRay creates the pod group with.
+	rayBatchSchedulerLabelKey = "ray.io/scheduler-name"
+	// rayPriorityClassLabelKey is the priority class of the pod group KubeRay creates.
+	rayPriorityClassLabelKey = "ray.io/priority-class-name"
+	// volcanoQueueLabelKey is the volcano queue the pod group is submitted to.
+	volcanoQueueLabelKey = "volcano.sh/queue-name"
+	// kueueQueueLabelKey is the kueue LocalQueue the ray cluster is submitted to.
+	kueueQueueLabelKey = "kueue.x-k8s.io/queue-name"
+)
+
+// applyGangScheduling labels the ray cluster for the gang scheduler of the policy, which admits
+// the head and all the minimum workers of the cluster at once.
+func applyGangScheduling(cluster *rayclusterv1.RayCluster, policy *orchestrationv1alpha1.GangSchedulingPolicy) {
+	if policy == nil {
+		return
+	}
+	labels := make(map[string]string, len(cluster.Labels)+3)
+	for k, v := range cluster.Labels {
+		labels[k] = v
+	}
+	switch policy.Scheduler {
+	case orchestrationv1alpha1.VolcanoGangScheduler:
+		labels[rayBatchSchedulerLabelKey] = string(policy.Scheduler)
+		if policy.Queue != "" {
+			labels[volcanoQueueLabelKey] = policy.Queue
+		}
+		if policy.PriorityClassName != "" {
+			labels[rayPriorityClassLabelKey] = policy.PriorityClassName
+		}
+	case orchestrationv1alpha1.KueueGangScheduler:
+		labels[kueueQueueLabelKey] = policy.Queue
+	}
+	cluster.Labels = labels
+
+	if policy.PriorityClassName == "" {
+		return
+	}
+	spec := &cluster.Spec
+	if spec.HeadGroupSpec.Template.Spec.PriorityClassName == "" {
+		spec.HeadGroupSpec.Template.Spec.PriorityClassName = policy.PriorityClassName
+	}
+	for i := range spec.WorkerGroupSpecs {
+		if spec.WorkerGroupSpecs[i].Template.Spec.PriorityClassName == "" {
+			spec.WorkerGroupSpecs[i].Template.Spec.PriorityClassName = policy.PriorityClassName
+		}
+	}
+}
+
 // filterActiveClusters filters out inactive Cluster from a list of RayClusters
 func filterActiveClusters(clusters []rayclusterv1.RayCluster) []rayclusterv1.RayCluster {
 	activeClusters := make([]rayclusterv1.RayCluster, 0)
@@ -136,14 +197,17 @@ func isStatusSame(rs *orchestrationv1alpha1.RayClusterReplicaSet, newStatus orch
 		rs.Status.ReadyReplicas == newStatus.ReadyReplicas &&
 		rs.Status.AvailableReplicas == newStatus.AvailableReplicas &&
 		rs.Generation == rs.Status.ObservedGeneration &&
-		reflect.DeepEqual(rs.Status.Conditions, newStatus.Conditions) {
+		reflect.DeepEqual(rs.Status.Conditions, newStatus.Conditions) &&
+		reflect.DeepEqual(rs.Status.ServingStatuses, newStatus.ServingStatuses) {
 		return true
 	}
 
 	return false
 }
 
-func calculateStatus(rs *orchestrationv1alpha1.RayClusterReplicaSet, filteredClusters []rayclusterv1.RayCluster, manageReplicasErr error) orchestrationv1alpha1.RayClusterReplicaSetStatus {
+// calculateStatus counts the ready and available ray clusters. With a serving probe, a ray cluster is
+// ready once its model is serving, and available once it has been serving for minReadySeconds.
+func calculateStatus(rs *orchestrationv1alpha1.RayClusterReplicaSet, filteredClusters []rayclusterv1.RayCluster, servingStatuses map[string]orchestrationv1alpha1.RayClusterServingStatus, manageReplicasErr error) orchestrationv1alpha1.RayClusterReplicaSetStatus {
 	newStatus := rs.Status
 	// Count the number of pods that have labels matching the labels of the cluster
 	// template of the replica set, the matching pods may have more
@@ -153,14 +217,31 @@ func calculateStatus(rs *orchestrationv1alpha1.RayClusterReplicaSet, filteredClu
 	fullyLabeledReplicasCount := 0
 	readyReplicasCount := 0
 	availableReplicasCount := 0
+	var servingStatusList []orchestrationv1alpha1.RayClusterServingStatus
+	now := metav1.Now()
 	templateLabel := labels.Set(rs.Spec.Template.Labels).AsSelectorPreValidated()
 	for _, cluster := range filteredClusters {
 		if templateLabel.Matches(labels.Set(cluster.Labels)) {
 			fullyLabeledReplicasCount++
 		}
+		if rs.Spec.ServingProbe != nil {
+			status, ok := servingStatuses[cluster.Name]
+			if !ok {
+				continue
+			}
+			servingStatusList = append(servingStatusList, status)
+			if status.Serving {
+				readyReplicasCount++
+				minReadySeconds := time.Duration(rs.Spec.MinReadySeconds) * time.Second
+				if rs.Spec.MinReadySeconds == 0 || status.LastTransitionTime.Add(minReadySeconds).Before(now.Time) {
+					availableReplicasCount++
+				}
+			}
+			continue
+		}
 		if rayclusterutil.IsRayClusterReady(&cluster) {
 			readyReplicasCount++
-			if rayclusterutil.IsRayClusterAvailable(&cluster, rs.Spec.MinReadySeconds, metav1.Now()) {
+			if rayclusterutil.IsRayClusterAvailable(&cluster, rs.Spec.MinReadySeconds, now) {
 				availableReplicasCount++
 			}
 		}
@@ -184,6 +265,10 @@ func calculateStatus(rs *orchestrationv1alpha1.RayClusterReplicaSet, filteredClu
 	newStatus.FullyLabeledReplicas = int32(fullyLabeledReplicasCount)
 	newStatus.ReadyReplicas = int32(readyReplicasCount)
 	newStatus.AvailableReplicas = int32(availableReplicasCount)
+	sort.Slice(servingStatusList, func(i, j int) bool {
+		return servingStatusList[i].Name < servingStatusList[j].Name
+	})
+	newStatus.ServingStatuses = servingStatusList
 
 	return newStatus
 }
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rayclusterreplicaset
+
+import (
+	"testing"
+
+	rayclusterv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+	fleetutil "github.com/vllm-project/aibrix/pkg/controller/rayclusterfleet/util"
+)
+
+func TestConstructRayClusterForFleet(t *testing.T) {
+	fleet := &orchestrationv1alpha1.RayClusterFleet{ObjectMeta: metav1.ObjectMeta{Name: "qwen", UID: "fleet-uid"}}
+	constraint := corev1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       corev1.LabelTopologyZone,
+		WhenUnsatisfiable: corev1.DoNotSchedule,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: fleetutil.HeadPodLabels("qwen")},
+	}
+	rs := &orchestrationv1alpha1.RayClusterReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "qwen-abc",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(fleet, orchestrationv1alpha1.GroupVersion.WithKind("RayClusterFleet"))},
+		},
+		Spec: orchestrationv1alpha1.RayClusterReplicaSetSpec{
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{constraint},
+		},
+	}
+	rs.Spec.Template.Spec.HeadGroupSpec.Template.Labels = map[string]string{"app": "qwen"}
+
+	cluster := constructRayCluster(rs)
+	headTemplate := cluster.Spec.HeadGroupSpec.Template
+	assert.Equal(t, map[string]string{"app": "qwen", fleetutil.FleetNameLabelKey: "qwen"}, headTemplate.Labels)
+	assert.Equal(t, []corev1.TopologySpreadConstraint{constraint}, headTemplate.Spec.TopologySpreadConstraints)
+	// the template of the replica set is left untouched.
+	assert.Equal(t, map[string]string{"app": "qwen"}, rs.Spec.Template.Spec.HeadGroupSpec.Template.Labels)
+	assert.Empty(t, rs.Spec.Template.Spec.HeadGroupSpec.Template.Spec.TopologySpreadConstraints)
+}
+
+func TestConstructRayClusterWithoutFleet(t *testing.T) {
+	rs := &orchestrationv1alpha1.RayClusterReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "default"}}
+	cluster := constructRayCluster(rs)
+	assert.NotContains(t, cluster.Spec.HeadGroupSpec.Template.Labels, fleetutil.FleetNameLabelKey)
+}
+
+func TestConstructRayClusterWithGangScheduling(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         *orchestrationv1alpha1.GangSchedulingPolicy
+		expectedLabels map[string]string
+	}{
+		{
+			name:           "volcano",
+			policy:         &orchestrationv1alpha1.GangSchedulingPolicy{Scheduler: orchestrationv1alpha1.VolcanoGangScheduler, Queue: "inference", PriorityClassName: "high"},
+			expectedLabels: map[string]string{"app": "qwen", "ray.io/scheduler-name": "volcano", "volcano.sh/queue-name": "inference", "ray.io/priority-class-name": "high"},
+		},
+		{
+			name:           "kueue",
+			policy:         &orchestrationv1alpha1.GangSchedulingPolicy{Scheduler: orchestrationv1alpha1.KueueGangScheduler, Queue: "inference", PriorityClassName: "high"},
+			expectedLabels: map[string]string{"app": "qwen", "kueue.x-k8s.io/queue-name": "inference"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := &orchestrationv1alpha1.RayClusterReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "qwen-abc", Namespace: "default"},
+				Spec:       orchestrationv1alpha1.RayClusterReplicaSetSpec{GangScheduling: tt.policy},
+			}
+			rs.Spec.Template.Labels = map[string]string{"app": "qwen"}
+			rs.Spec.Template.Spec.WorkerGroupSpecs = []rayclusterv1.WorkerGroupSpec{{GroupName: "small"}, {GroupName: "large"}}
+			rs.Spec.Template.Spec.WorkerGroupSpecs[1].Template.Spec.PriorityClassName = "custom"
+
+			cluster := constructRayCluster(rs)
+			assert.Equal(t, tt.expectedLabels, cluster.Labels)
+			assert.Equal(t, "high", cluster.Spec.HeadGroupSpec.Template.Spec.PriorityClassName)
+			assert.Equal(t, "high", cluster.Spec.WorkerGroupSpecs[0].Template.Spec.PriorityClassName)
+			assert.Equal(t, "custom", cluster.Spec.WorkerGroupSpecs[1].Template.Spec.PriorityClassName)
+			// the template of the replica set is left untouched.
+			assert.Equal(t, map[string]string{"app": "qwen"}, rs.Spec.Template.Labels)
+			assert.Empty(t, rs.Spec.Template.Spec.HeadGroupSpec.Template.Spec.PriorityClassName)
+		})
+	}
+}
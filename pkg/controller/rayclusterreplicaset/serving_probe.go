@@ -0,0 +1,209 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rayclusterreplicaset
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	rayclusterv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+	rayclusterutil "github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// ServingProber checks whether the model server at the endpoint answers the serving probe.
+type ServingProber interface {
+	Probe(ctx context.Context, probe *orchestrationv1alpha1.ServingProbe, endpoint string) error
+}
+
+// defaultServingProber probes the model servers over HTTP or gRPC.
+type defaultServingProber struct{}
+
+func (p *defaultServingProber) Probe(ctx context.Context, probe *orchestrationv1alpha1.ServingProbe, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(probe.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	switch {
+	case probe.HTTPGet != nil:
+		return probeHTTP(ctx, probe.HTTPGet, endpoint)
+	case probe.GRPC != nil:
+		return probeGRPC(ctx, probe.GRPC, endpoint)
+	}
+	return fmt.Errorf("serving probe has no handler")
+}
+
+func probeHTTP(ctx context.Context, action *corev1.HTTPGetAction, endpoint string) error {
+	scheme := "http"
+	if action.Scheme == corev1.URISchemeHTTPS {
+		scheme = "https"
+	}
+	u := url.URL{Scheme: scheme, Host: endpoint, Path: action.Path}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	for _, header := range action.HTTPHeaders {
+		req.Header.Add(header.Name, header.Value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HTTP probe failed with statuscode: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func probeGRPC(ctx context.Context, action *corev1.GRPCAction, endpoint string) error {
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	req := &healthpb.HealthCheckRequest{}
+	if action.Service != nil {
+		req.Service = *action.Service
+	}
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("gRPC probe failed with status: %s", resp.GetStatus())
+	}
+	return nil
+}
+
+// servingEndpoint returns the host:port of the model server of the ray cluster, the host is the
+// head service of the cluster unless the probe sets one.
+func servingEndpoint(cluster *rayclusterv1.RayCluster, probe *orchestrationv1alpha1.ServingProbe) (string, error) {
+	host := cluster.Status.Head.ServiceIP
+	if host == "" {
+		host = cluster.Status.Head.PodIP
+	}
+
+	var port int32
+	switch {
+	case probe.HTTPGet != nil:
+		if probe.HTTPGet.Host != "" {
+			host = probe.HTTPGet.Host
+		}
+		resolved, err := resolveHeadPort(cluster, probe.HTTPGet.Port)
+		if err != nil {
+			return "", err
+		}
+		port = resolved
+	case probe.GRPC != nil:
+		port = probe.GRPC.Port
+	default:
+		return "", fmt.Errorf("serving probe has no handler")
+	}
+
+	if host == "" {
+		return "", fmt.Errorf("head of the ray cluster has no address yet")
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+}
+
+// resolveHeadPort resolves a named port against the container ports of the head pod.
+func resolveHeadPort(cluster *rayclusterv1.RayCluster, port intstr.IntOrString) (int32, error) {
+	if port.Type == intstr.Int {
+		return port.IntVal, nil
+	}
+	for _, container := range cluster.Spec.HeadGroupSpec.Template.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if containerPort.Name == port.StrVal {
+				return containerPort.ContainerPort, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("head pod has no port named %q", port.StrVal)
+}
+
+// probeServing probes the model server of every ready ray cluster in parallel and returns their
+// serving state by cluster name. The transition time of a state is carried over from the last status.
+func (r *RayClusterReplicaSetReconciler) probeServing(ctx context.Context, rs *orchestrationv1alpha1.RayClusterReplicaSet, clusters []rayclusterv1.RayCluster) map[string]orchestrationv1alpha1.RayClusterServingStatus {
+	probe := rs.Spec.ServingProbe
+	if probe == nil {
+		return nil
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		statuses = make(map[string]orchestrationv1alpha1.RayClusterServingStatus, len(clusters))
+	)
+	for i := range clusters {
+		cluster := &clusters[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := orchestrationv1alpha1.RayClusterServingStatus{Name: cluster.Name}
+			endpoint, err := servingEndpoint(cluster, probe)
+			status.Endpoint = endpoint
+			if !rayclusterutil.IsRayClusterReady(cluster) {
+				err = fmt.Errorf("ray cluster is not ready")
+			} else if err == nil {
+				err = r.Prober.Probe(ctx, probe, endpoint)
+			}
+			status.Serving = err == nil
+			if err != nil {
+				status.Message = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			statuses[cluster.Name] = status
+		}()
+	}
+	wg.Wait()
+
+	return carryOverTransitionTimes(rs.Status.ServingStatuses, statuses, metav1.Now())
+}
+
+// carryOverTransitionTimes keeps the transition time of the clusters whose serving state didn't change.
+func carryOverTransitionTimes(previous []orchestrationv1alpha1.RayClusterServingStatus, statuses map[string]orchestrationv1alpha1.RayClusterServingStatus, now metav1.Time) map[string]orchestrationv1alpha1.RayClusterServingStatus {
+	last := make(map[string]orchestrationv1alpha1.RayClusterServingStatus, len(previous))
+	for _, status := range previous {
+		last[status.Name] = status
+	}
+	for name, status := range statuses {
+		if prev, ok := last[name]; ok && prev.Serving == status.Serving {
+			status.LastTransitionTime = prev.LastTransitionTime
+		} else {
+			status.LastTransitionTime = now
+		}
+		statuses[name] = status
+	}
+	return statuses
+}
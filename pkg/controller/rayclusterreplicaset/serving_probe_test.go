@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rayclusterreplicaset
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	rayclusterv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+)
+
+func TestServingEndpoint(t *testing.T) {
+	cluster := &rayclusterv1.RayCluster{}
+	cluster.Spec.HeadGroupSpec.Template.Spec.Containers = []corev1.Container{{
+		Name:  "ray-head",
+		Ports: []corev1.ContainerPort{{Name: "serve", ContainerPort: 8000}},
+	}}
+	httpProbe := &orchestrationv1alpha1.ServingProbe{HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromString("serve")}}
+
+	_, err := servingEndpoint(cluster, httpProbe)
+	assert.Error(t, err, "head without address")
+
+	cluster.Status.Head.PodIP = "10.0.0.2"
+	endpoint, err := servingEndpoint(cluster, httpProbe)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2:8000", endpoint)
+
+	cluster.Status.Head.ServiceIP = "10.96.0.10"
+	endpoint, err = servingEndpoint(cluster, &orchestrationv1alpha1.ServingProbe{GRPC: &corev1.GRPCAction{Port: 9000}})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.96.0.10:9000", endpoint)
+
+	_, err = servingEndpoint(cluster, &orchestrationv1alpha1.ServingProbe{HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromString("metrics")}})
+	assert.Error(t, err, "unknown named port")
+}
+
+func TestProbeHTTP(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" || !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	prober := &defaultServingProber{}
+	probe := &orchestrationv1alpha1.ServingProbe{
+		HTTPGet:        &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromInt32(8000)},
+		TimeoutSeconds: 1,
+	}
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+	assert.NoError(t, prober.Probe(context.Background(), probe, endpoint))
+
+	healthy = false
+	assert.Error(t, prober.Probe(context.Background(), probe, endpoint))
+}
+
+func TestCalculateStatusWithServingProbe(t *testing.T) {
+	rs := &orchestrationv1alpha1.RayClusterReplicaSet{
+		Spec: orchestrationv1alpha1.RayClusterReplicaSetSpec{
+			Replicas:        ptr.To[int32](3),
+			MinReadySeconds: 30,
+			ServingProbe:    &orchestrationv1alpha1.ServingProbe{GRPC: &corev1.GRPCAction{Port: 9000}},
+		},
+	}
+	clusters := []rayclusterv1.RayCluster{
+		{ObjectMeta: metav1.ObjectMeta{Name: "c"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+	}
+	longAgo := metav1.NewTime(time.Now().Add(-time.Minute))
+	servingStatuses := map[string]orchestrationv1alpha1.RayClusterServingStatus{
+		"a": {Name: "a", Serving: true, LastTransitionTime: longAgo},
+		"b": {Name: "b", Serving: true, LastTransitionTime: metav1.Now()},
+		"c": {Name: "c", Serving: false, Message: "ray cluster is not ready", LastTransitionTime: longAgo},
+	}
+
+	status := calculateStatus(rs, clusters, servingStatuses, nil)
+	assert.Equal(t, int32(3), status.Replicas)
+	assert.Equal(t, int32(2), status.ReadyReplicas)
+	assert.Equal(t, int32(1), status.AvailableReplicas)
+	assert.Len(t, status.ServingStatuses, 3)
+	assert.Equal(t, "a", status.ServingStatuses[0].Name)
+	assert.Equal(t, "c", status.ServingStatuses[2].Name)
+}
+
+func TestCarryOverTransitionTimes(t *testing.T) {
+	longAgo := metav1.NewTime(time.Now().Add(-time.Minute))
+	now := metav1.Now()
+	previous := []orchestrationv1alpha1.RayClusterServingStatus{
+		{Name: "a", Serving: true, LastTransitionTime: longAgo},
+		{Name: "b", Serving: false, LastTransitionTime: longAgo},
+	}
+	statuses := carryOverTransitionTimes(previous, map[string]orchestrationv1alpha1.RayClusterServingStatus{
+		"a": {Name: "a", Serving: true},
+		"b": {Name: "b", Serving: true},
+		"c": {Name: "c", Serving: false},
+	}, now)
+	assert.Equal(t, longAgo, statuses["a"].LastTransitionTime)
+	assert.Equal(t, now, statuses["b"].LastTransitionTime)
+	assert.Equal(t, now, statuses["c"].LastTransitionTime)
+}
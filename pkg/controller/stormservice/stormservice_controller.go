@@ -0,0 +1,427 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stormservice
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/config"
+	fleetutil "github.com/vllm-project/aibrix/pkg/controller/rayclusterfleet/util"
+)
+
+const (
+	StormServiceLabelKeyName = "stormservice.orchestration.aibrix.ai/name"
+	StormServiceLabelKeyRole = "stormservice.orchestration.aibrix.ai/role"
+
+	// StormServiceAnnotationRevision is the revision of the role template a Deployment runs.
+	StormServiceAnnotationRevision = "stormservice.orchestration.aibrix.ai/revision"
+)
+
+var (
+	controllerKind = orchestrationv1alpha1.GroupVersion.WithKind("StormService")
+	controllerName = "storm-service-controller"
+)
+
+// Add creates a new StormService Controller and adds it to the Manager with default RBAC.
+// The Manager will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, runtimeConfig config.RuntimeConfig) error {
+	r, err := newReconciler(mgr, runtimeConfig)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, runtimeConfig config.RuntimeConfig) (reconcile.Reconciler, error) {
+	reconciler := &StormServiceReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor(controllerName),
+		RuntimeConfig: runtimeConfig,
+	}
+	return reconciler, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		For(&orchestrationv1alpha1.StormService{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&appsv1.Deployment{}).
+		Complete(r)
+
+	klog.InfoS("Finished to add storm-service-controller")
+	return err
+}
+
+// StormServiceReconciler reconciles a StormService object
+type StormServiceReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Recorder      record.EventRecorder
+	RuntimeConfig config.RuntimeConfig
+}
+
+// +kubebuilder:rbac:groups=orchestration.aibrix.ai,resources=stormservices,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=orchestration.aibrix.ai,resources=stormservices/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=orchestration.aibrix.ai,resources=stormservices/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile runs a Deployment per role of the StormService and rolls template changes out role by role.
+func (r *StormServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	storm := &orchestrationv1alpha1.StormService{}
+	if err := r.Get(ctx, req.NamespacedName, storm); err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return. Created objects are automatically garbage collected.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+	if storm.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	deployments, err := r.getRoleDeployments(ctx, storm)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.deleteRemovedRoles(ctx, storm, deployments); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	updatable := updatableRoles(storm, deployments)
+	for i := range storm.Spec.Roles {
+		role := &storm.Spec.Roles[i]
+		deployment, err := r.reconcileRole(ctx, storm, role, deployments[role.Name], updatable.Has(role.Name))
+		if err != nil {
+			r.Recorder.Eventf(storm, "Warning", "FailedReconcileRole", "Failed to reconcile role %s: %v", role.Name, err)
+			return reconcile.Result{}, err
+		}
+		deployments[role.Name] = deployment
+	}
+
+	return reconcile.Result{}, r.updateStatus(ctx, storm, deployments)
+}
+
+// getRoleDeployments returns the Deployments controlled by the StormService by role name.
+func (r *StormServiceReconciler) getRoleDeployments(ctx context.Context, storm *orchestrationv1alpha1.StormService) (map[string]*appsv1.Deployment, error) {
+	deploymentList := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deploymentList, client.InNamespace(storm.Namespace), client.MatchingLabels{StormServiceLabelKeyName: storm.Name}); err != nil {
+		return nil, err
+	}
+	deployments := make(map[string]*appsv1.Deployment, len(deploymentList.Items))
+	for i := range deploymentList.Items {
+		deployment := &deploymentList.Items[i]
+		if !metav1.IsControlledBy(deployment, storm) {
+			continue
+		}
+		deployments[deployment.Labels[StormServiceLabelKeyRole]] = deployment
+	}
+	return deployments, nil
+}
+
+// deleteRemovedRoles deletes the Deployments of the roles removed from the spec.
+func (r *StormServiceReconciler) deleteRemovedRoles(ctx context.Context, storm *orchestrationv1alpha1.StormService, deployments map[string]*appsv1.Deployment) error {
+	roles := make(map[string]struct{}, len(storm.Spec.Roles))
+	for _, role := range storm.Spec.Roles {
+		roles[role.Name] = struct{}{}
+	}
+	for roleName, deployment := range deployments {
+		if _, ok := roles[roleName]; ok {
+			continue
+		}
+		klog.InfoS("Deleting the Deployment of a removed role", "stormService", klog.KObj(storm), "role", roleName)
+		if err := r.Delete(ctx, deployment); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		delete(deployments, roleName)
+	}
+	return nil
+}
+
+// reconcileRole creates the Deployment of the role, or updates it. The template of an existing Deployment
+// is only replaced once the role is allowed to be updated, replicas are always synced.
+func (r *StormServiceReconciler) reconcileRole(ctx context.Context, storm *orchestrationv1alpha1.StormService, role *orchestrationv1alpha1.RoleSpec, found *appsv1.Deployment, updatable bool) (*appsv1.Deployment, error) {
+	deployment := constructDeployment(storm, role)
+	if found == nil {
+		klog.InfoS("Creating the Deployment of a role", "stormService", klog.KObj(storm), "role", role.Name)
+		if err := r.Create(ctx, deployment); err != nil {
+			return nil, err
+		}
+		return deployment, nil
+	}
+
+	updated := found.DeepCopy()
+	updated.Spec.Replicas = deployment.Spec.Replicas
+	updated.Spec.Strategy = deployment.Spec.Strategy
+	revision := deployment.Annotations[StormServiceAnnotationRevision]
+	if updatable && found.Annotations[StormServiceAnnotationRevision] != revision {
+		updated.Spec.Template = deployment.Spec.Template
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[StormServiceAnnotationRevision] = revision
+	}
+	if equality.Semantic.DeepEqual(found.Spec, updated.Spec) && equality.Semantic.DeepEqual(found.Annotations, updated.Annotations) {
+		return found, nil
+	}
+
+	klog.InfoS("Updating the Deployment of a role", "stormService", klog.KObj(storm), "role", role.Name, "revision", updated.Annotations[StormServiceAnnotationRevision])
+	if err := r.Update(ctx, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// constructDeployment returns the Deployment running the pods of the role.
+func constructDeployment(storm *orchestrationv1alpha1.StormService, role *orchestrationv1alpha1.RoleSpec) *appsv1.Deployment {
+	selector := map[string]string{
+		StormServiceLabelKeyName: storm.Name,
+		StormServiceLabelKeyRole: role.Name,
+	}
+	template := *role.Template.DeepCopy()
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	for key, value := range selector {
+		template.Labels[key] = value
+	}
+
+	maxUnavailable := intstr.FromString("25%")
+	maxSurge := intstr.FromString("25%")
+	if storm.Spec.UpdateStrategy.MaxUnavailable != nil {
+		maxUnavailable = *storm.Spec.UpdateStrategy.MaxUnavailable
+	}
+	if storm.Spec.UpdateStrategy.MaxSurge != nil {
+		maxSurge = *storm.Spec.UpdateStrategy.MaxSurge
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      roleDeploymentName(storm, role),
+			Namespace: storm.Namespace,
+			Labels:    selector,
+			Annotations: map[string]string{
+				StormServiceAnnotationRevision: roleRevision(role),
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(storm, controllerKind),
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: role.Replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+			Template: template,
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxUnavailable: &maxUnavailable,
+					MaxSurge:       &maxSurge,
+				},
+			},
+		},
+	}
+}
+
+func roleDeploymentName(storm *orchestrationv1alpha1.StormService, role *orchestrationv1alpha1.RoleSpec) string {
+	return fmt.Sprintf("%s-%s", storm.Name, role.Name)
+}
+
+// roleRevision returns the hash of the pod template of the role.
+func roleRevision(role *orchestrationv1alpha1.RoleSpec) string {
+	hasher := fnv.New32a()
+	fleetutil.DeepHashObject(hasher, role.Template)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// serviceRevision returns the hash of the pod templates of all the roles.
+func serviceRevision(storm *orchestrationv1alpha1.StormService) string {
+	hasher := fnv.New32a()
+	revisions := make([]string, 0, len(storm.Spec.Roles))
+	for i := range storm.Spec.Roles {
+		role := &storm.Spec.Roles[i]
+		revisions = append(revisions, role.Name+"="+roleRevision(role))
+	}
+	sort.Strings(revisions)
+	fleetutil.DeepHashObject(hasher, revisions)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// roleRolledOut returns whether the Deployment runs the current template of the role on all its replicas.
+func roleRolledOut(role *orchestrationv1alpha1.RoleSpec, deployment *appsv1.Deployment) bool {
+	if deployment == nil || deployment.Annotations[StormServiceAnnotationRevision] != roleRevision(role) {
+		return false
+	}
+	replicas := replicasOrDefault(deployment.Spec.Replicas)
+	return deployment.Generation <= deployment.Status.ObservedGeneration &&
+		deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.Replicas == replicas &&
+		deployment.Status.AvailableReplicas == replicas
+}
+
+// updatableRoles returns the roles whose Deployments may take their current template. With the InOrder
+// strategy the roles of an upgrade order are only updated once all the roles of lower orders rolled out.
+func updatableRoles(storm *orchestrationv1alpha1.StormService, deployments map[string]*appsv1.Deployment) roleSet {
+	updatable := roleSet{}
+	if storm.Spec.Paused {
+		return updatable
+	}
+	if storm.Spec.UpdateStrategy.Type == orchestrationv1alpha1.ParallelStormServiceUpdateStrategyType {
+		for _, role := range storm.Spec.Roles {
+			updatable[role.Name] = struct{}{}
+		}
+		return updatable
+	}
+
+	byOrder := map[int32][]*orchestrationv1alpha1.RoleSpec{}
+	var orders []int32
+	for i := range storm.Spec.Roles {
+		role := &storm.Spec.Roles[i]
+		if _, ok := byOrder[role.UpgradeOrder]; !ok {
+			orders = append(orders, role.UpgradeOrder)
+		}
+		byOrder[role.UpgradeOrder] = append(byOrder[role.UpgradeOrder], role)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i] < orders[j] })
+
+	for _, order := range orders {
+		rolledOut := true
+		for _, role := range byOrder[order] {
+			updatable[role.Name] = struct{}{}
+			rolledOut = rolledOut && roleRolledOut(role, deployments[role.Name])
+		}
+		if !rolledOut {
+			break
+		}
+	}
+	return updatable
+}
+
+// updateStatus aggregates the states of the role Deployments into the status of the StormService.
+func (r *StormServiceReconciler) updateStatus(ctx context.Context, storm *orchestrationv1alpha1.StormService, deployments map[string]*appsv1.Deployment) error {
+	newStatus := calculateStatus(storm, deployments)
+	if equality.Semantic.DeepEqual(&storm.Status, newStatus) {
+		return nil
+	}
+	storm.Status = *newStatus
+	return r.Status().Update(ctx, storm)
+}
+
+func calculateStatus(storm *orchestrationv1alpha1.StormService, deployments map[string]*appsv1.Deployment) *orchestrationv1alpha1.StormServiceStatus {
+	newStatus := storm.Status.DeepCopy()
+	newStatus.ObservedGeneration = storm.Generation
+	newStatus.UpdateRevision = serviceRevision(storm)
+	newStatus.Roles = nil
+
+	var unavailable, updating []string
+	for i := range storm.Spec.Roles {
+		role := &storm.Spec.Roles[i]
+		roleStatus := orchestrationv1alpha1.RoleStatus{Name: role.Name}
+		deployment := deployments[role.Name]
+		if deployment != nil {
+			roleStatus.Replicas = deployment.Status.Replicas
+			roleStatus.ReadyReplicas = deployment.Status.ReadyReplicas
+			roleStatus.AvailableReplicas = deployment.Status.AvailableReplicas
+			if deployment.Annotations[StormServiceAnnotationRevision] == roleRevision(role) {
+				roleStatus.UpdatedReplicas = deployment.Status.UpdatedReplicas
+			}
+		}
+		newStatus.Roles = append(newStatus.Roles, roleStatus)
+
+		if roleStatus.AvailableReplicas < replicasOrDefault(role.Replicas) {
+			unavailable = append(unavailable, role.Name)
+		}
+		if !roleRolledOut(role, deployment) {
+			updating = append(updating, role.Name)
+		}
+	}
+	if len(updating) == 0 {
+		newStatus.CurrentRevision = newStatus.UpdateRevision
+	}
+
+	ready := metav1.Condition{
+		Type:    orchestrationv1alpha1.StormServiceReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "RolesAvailable",
+		Message: "The pods of all the roles are available",
+	}
+	if len(unavailable) > 0 {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "RolesUnavailable"
+		ready.Message = fmt.Sprintf("Roles with unavailable pods: %s", strings.Join(unavailable, ", "))
+	}
+	meta.SetStatusCondition(&newStatus.Conditions, ready)
+
+	progressing := metav1.Condition{
+		Type:    orchestrationv1alpha1.StormServiceProgressing,
+		Status:  metav1.ConditionFalse,
+		Reason:  "RolesRolledOut",
+		Message: fmt.Sprintf("All the roles run revision %s", newStatus.UpdateRevision),
+	}
+	if len(updating) > 0 {
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = "RolesUpdating"
+		progressing.Message = fmt.Sprintf("Roles rolling out revision %s: %s", newStatus.UpdateRevision, strings.Join(updating, ", "))
+		if storm.Spec.Paused {
+			progressing.Status = metav1.ConditionFalse
+			progressing.Reason = "Paused"
+			progressing.Message = fmt.Sprintf("Update of roles %s is paused", strings.Join(updating, ", "))
+		}
+	}
+	meta.SetStatusCondition(&newStatus.Conditions, progressing)
+	return newStatus
+}
+
+func replicasOrDefault(value *int32) int32 {
+	if value == nil {
+		return 1
+	}
+	return *value
+}
+
+// roleSet is a set of role names.
+type roleSet map[string]struct{}
+
+func (s roleSet) Has(name string) bool {
+	_, ok := s[name]
+	return ok
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stormservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	orchestrationv1alpha1 "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+)
+
+func makeRole(name string, order int32, image string) orchestrationv1alpha1.RoleSpec {
+	return orchestrationv1alpha1.RoleSpec{
+		Name:         name,
+		Replicas:     ptr.To[int32](2),
+		UpgradeOrder: order,
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"model.aibrix.ai/name": "llama"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: image}}},
+		},
+	}
+}
+
+func makeStormService() *orchestrationv1alpha1.StormService {
+	return &orchestrationv1alpha1.StormService{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama", Namespace: "default", Generation: 2},
+		Spec: orchestrationv1alpha1.StormServiceSpec{
+			Roles: []orchestrationv1alpha1.RoleSpec{
+				makeRole("prefill", 0, "vllm:v2"),
+				makeRole("decode", 0, "vllm:v2"),
+				makeRole("router", 1, "router:v2"),
+			},
+			UpdateStrategy: orchestrationv1alpha1.StormServiceUpdateStrategy{Type: orchestrationv1alpha1.InOrderStormServiceUpdateStrategyType},
+		},
+	}
+}
+
+// rolledOut returns the Deployment of the role with all replicas updated to the given template.
+func rolledOut(storm *orchestrationv1alpha1.StormService, role orchestrationv1alpha1.RoleSpec) *appsv1.Deployment {
+	deployment := constructDeployment(storm, &role)
+	deployment.Status = appsv1.DeploymentStatus{Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}
+	return deployment
+}
+
+func TestConstructDeployment(t *testing.T) {
+	storm := makeStormService()
+	deployment := constructDeployment(storm, &storm.Spec.Roles[0])
+
+	assert.Equal(t, "llama-prefill", deployment.Name)
+	assert.Equal(t, map[string]string{StormServiceLabelKeyName: "llama", StormServiceLabelKeyRole: "prefill"}, deployment.Spec.Selector.MatchLabels)
+	assert.Equal(t, "llama", deployment.Spec.Template.Labels["model.aibrix.ai/name"])
+	assert.Equal(t, "prefill", deployment.Spec.Template.Labels[StormServiceLabelKeyRole])
+	assert.Equal(t, roleRevision(&storm.Spec.Roles[0]), deployment.Annotations[StormServiceAnnotationRevision])
+	assert.True(t, metav1.IsControlledBy(deployment, storm))
+	// the template of the role is left untouched.
+	assert.NotContains(t, storm.Spec.Roles[0].Template.Labels, StormServiceLabelKeyRole)
+}
+
+func TestUpdatableRoles(t *testing.T) {
+	storm := makeStormService()
+	old := makeStormService()
+	for i := range old.Spec.Roles {
+		old.Spec.Roles[i].Template.Spec.Containers[0].Image = "v1"
+	}
+	deployments := map[string]*appsv1.Deployment{
+		"prefill": rolledOut(old, old.Spec.Roles[0]),
+		"decode":  rolledOut(old, old.Spec.Roles[1]),
+		"router":  rolledOut(old, old.Spec.Roles[2]),
+	}
+
+	// the roles of the lowest order are updated first.
+	assert.Equal(t, roleSet{"prefill": {}, "decode": {}}, updatableRoles(storm, deployments))
+
+	deployments["prefill"] = rolledOut(storm, storm.Spec.Roles[0])
+	assert.Equal(t, roleSet{"prefill": {}, "decode": {}}, updatableRoles(storm, deployments))
+
+	// the router follows once prefill and decode rolled out.
+	deployments["decode"] = rolledOut(storm, storm.Spec.Roles[1])
+	assert.Equal(t, roleSet{"prefill": {}, "decode": {}, "router": {}}, updatableRoles(storm, deployments))
+
+	deployments["decode"].Status.AvailableReplicas = 1
+	assert.Equal(t, roleSet{"prefill": {}, "decode": {}}, updatableRoles(storm, deployments))
+
+	storm.Spec.UpdateStrategy.Type = orchestrationv1alpha1.ParallelStormServiceUpdateStrategyType
+	assert.Len(t, updatableRoles(storm, deployments), 3)
+
+	storm.Spec.Paused = true
+	assert.Empty(t, updatableRoles(storm, deployments))
+}
+
+func TestCalculateStatus(t *testing.T) {
+	storm := makeStormService()
+	old := makeStormService()
+	old.Spec.Roles[2].Template.Spec.Containers[0].Image = "router:v1"
+	deployments := map[string]*appsv1.Deployment{
+		"prefill": rolledOut(storm, storm.Spec.Roles[0]),
+		"decode":  rolledOut(storm, storm.Spec.Roles[1]),
+		"router":  rolledOut(old, old.Spec.Roles[2]),
+	}
+
+	status := calculateStatus(storm, deployments)
+	assert.Equal(t, int64(2), status.ObservedGeneration)
+	assert.NotEmpty(t, status.UpdateRevision)
+	assert.Empty(t, status.CurrentRevision)
+	assert.Equal(t, orchestrationv1alpha1.RoleStatus{Name: "router", Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2}, status.Roles[2])
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, orchestrationv1alpha1.StormServiceReady))
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, orchestrationv1alpha1.StormServiceProgressing))
+
+	deployments["router"] = rolledOut(storm, storm.Spec.Roles[2])
+	delete(deployments, "decode")
+	status = calculateStatus(storm, deployments)
+	assert.Empty(t, status.CurrentRevision)
+	assert.Equal(t, "Roles with unavailable pods: decode", meta.FindStatusCondition(status.Conditions, orchestrationv1alpha1.StormServiceReady).Message)
+
+	deployments["decode"] = rolledOut(storm, storm.Spec.Roles[1])
+	status = calculateStatus(storm, deployments)
+	assert.Equal(t, status.UpdateRevision, status.CurrentRevision)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, orchestrationv1alpha1.StormServiceReady))
+	assert.False(t, meta.IsStatusConditionTrue(status.Conditions, orchestrationv1alpha1.StormServiceProgressing))
+}
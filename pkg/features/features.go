@@ -27,6 +27,8 @@ const (
 	ModelAdapterController         = "model-adapter-controller"
 	ModelRouteController           = "model-route-controller"
 	KVCacheController              = "kv-cache-controller"
+	StormServiceController         = "storm-service-controller"
+	ModelPrewarmController         = "model-prewarm-controller"
 )
 
 var (
@@ -39,6 +41,7 @@ var (
 
 	ValidControllers = []string{
 		PodAutoscalerController, DistributedInferenceController, ModelAdapterController, ModelRouteController, KVCacheController,
+		StormServiceController, ModelPrewarmController,
 	}
 )
 
@@ -112,4 +115,6 @@ func EnableAllControllers() {
 	EnabledControllers[DistributedInferenceController] = true
 	EnabledControllers[ModelRouteController] = true
 	EnabledControllers[KVCacheController] = true
+	EnabledControllers[StormServiceController] = true
+	EnabledControllers[ModelPrewarmController] = true
 }
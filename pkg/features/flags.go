@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// FlagConfigMapKey is the ConfigMap data key holding the JSON encoded FlagConfig.
+	FlagConfigMapKey = "flags.json"
+
+	// LoraActivation enables the gateway to load lora adapters on more pods when hosting pods saturate.
+	LoraActivation = "lora-activation"
+	// ScaleFromZero enables the gateway to hold requests of models scaled to zero until their PodAutoscaler scales them up.
+	ScaleFromZero = "scale-from-zero"
+)
+
+// defaultFlags holds the value of every known flag when it is not configured.
+var defaultFlags = map[string]bool{
+	LoraActivation: true,
+	ScaleFromZero:  true,
+}
+
+// FlagRule enables a flag for a fraction of the traffic it targets.
+type FlagRule struct {
+	Enabled bool `json:"enabled"`
+	// Percentage of the targeted traffic the flag is enabled for, 100 if unset.
+	Percentage *int32 `json:"percentage,omitempty"`
+}
+
+// FlagSpec configures a flag globally with optional per-model and per-tenant overrides.
+// Tenant rules take precedence over model rules, which take precedence over the global rule.
+type FlagSpec struct {
+	FlagRule `json:",inline"`
+	Models   map[string]FlagRule `json:"models,omitempty"`
+	Tenants  map[string]FlagRule `json:"tenants,omitempty"`
+}
+
+// FlagConfig is the content of the feature flag ConfigMap.
+type FlagConfig struct {
+	Flags map[string]FlagSpec `json:"flags"`
+}
+
+// Target identifies the traffic a flag is evaluated for.
+type Target struct {
+	Model  string
+	Tenant string
+	// Key buckets the traffic for percentage rollouts, the tenant is used if empty so a tenant sees a stable result.
+	Key string
+	// RequestID buckets the requests of the callers without tenant, each request is enrolled on its own.
+	RequestID string
+}
+
+// rolloutKey returns the key bucketing the target, empty if it can't be told apart from other traffic.
+func (t Target) rolloutKey() string {
+	for _, key := range []string{t.Key, t.Tenant, t.RequestID} {
+		if key != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// ParseFlagConfig decodes and validates a flag configuration.
+func ParseFlagConfig(data []byte) (FlagConfig, error) {
+	var config FlagConfig
+	if len(data) == 0 {
+		return config, nil
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return FlagConfig{}, fmt.Errorf("failed to parse feature flags: %w", err)
+	}
+	for name, spec := range config.Flags {
+		rules := []FlagRule{spec.FlagRule}
+		for _, rule := range spec.Models {
+			rules = append(rules, rule)
+		}
+		for _, rule := range spec.Tenants {
+			rules = append(rules, rule)
+		}
+		for _, rule := range rules {
+			if rule.Percentage != nil && (*rule.Percentage < 0 || *rule.Percentage > 100) {
+				return FlagConfig{}, fmt.Errorf("invalid percentage %d for flag %s, must be within [0, 100]", *rule.Percentage, name)
+			}
+		}
+	}
+	return config, nil
+}
+
+// FlagStore evaluates feature flags against the latest configuration.
+type FlagStore struct {
+	mu     sync.RWMutex
+	config FlagConfig
+}
+
+func NewFlagStore() *FlagStore {
+	return &FlagStore{}
+}
+
+// Update replaces the flag configuration.
+func (s *FlagStore) Update(config FlagConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config = config
+}
+
+// Enabled reports whether the flag is enabled for the target, unconfigured flags fall back to their default.
+func (s *FlagStore) Enabled(flag string, target Target) bool {
+	s.mu.RLock()
+	spec, ok := s.config.Flags[flag]
+	s.mu.RUnlock()
+	if !ok {
+		return defaultFlags[flag]
+	}
+
+	rule := spec.FlagRule
+	if modelRule, ok := spec.Models[target.Model]; ok && target.Model != "" {
+		rule = modelRule
+	}
+	if tenantRule, ok := spec.Tenants[target.Tenant]; ok && target.Tenant != "" {
+		rule = tenantRule
+	}
+
+	if !rule.Enabled {
+		return false
+	}
+	if rule.Percentage == nil || *rule.Percentage >= 100 {
+		return true
+	}
+	// the traffic without key isn't enrolled in partial rollouts, it would all fall in the same bucket.
+	key := target.rolloutKey()
+	if key == "" {
+		return false
+	}
+	return bucket(flag, key) < uint32(*rule.Percentage)
+}
+
+// bucket maps the key to [0, 100) per flag, so rollouts of different flags are independent.
+func bucket(flag, key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(flag))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	return h.Sum32() % 100
+}
+
+// Flags is the process wide flag store, populated by WatchFlags.
+var Flags = NewFlagStore()
+
+// IsFlagEnabled evaluates the flag against the process wide flag store.
+func IsFlagEnabled(flag string, target Target) bool {
+	return Flags.Enabled(flag, target)
+}
+
+// WatchFlags keeps the process wide flag store in sync with the ConfigMap until stopCh is closed.
+// A missing ConfigMap leaves every flag at its default, an invalid one keeps the previous configuration.
+func WatchFlags(client kubernetes.Interface, namespace, name string, stopCh <-chan struct{}) {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+	informer := factory.Core().V1().ConfigMaps().Informer()
+
+	load := func(obj interface{}) {
+		cm, ok := obj.(*v1.ConfigMap)
+		if !ok {
+			return
+		}
+		config, err := ParseFlagConfig([]byte(cm.Data[FlagConfigMapKey]))
+		if err != nil {
+			klog.ErrorS(err, "ignoring invalid feature flag configmap", "configmap", klog.KObj(cm))
+			return
+		}
+		Flags.Update(config)
+		klog.InfoS("feature flags reloaded", "configmap", klog.KObj(cm), "flags", len(config.Flags))
+	}
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    load,
+		UpdateFunc: func(_, newObj interface{}) { load(newObj) },
+		DeleteFunc: func(obj interface{}) {
+			Flags.Update(FlagConfig{})
+			klog.InfoS("feature flag configmap deleted, using defaults", "configmap", klog.KRef(namespace, name))
+		},
+	}); err != nil {
+		klog.ErrorS(err, "failed to watch feature flags")
+		return
+	}
+
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		klog.ErrorS(nil, "timed out waiting for feature flag cache to sync")
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagStoreTargeting(t *testing.T) {
+	config, err := ParseFlagConfig([]byte(`{
+		"flags": {
+			"semantic-cache": {
+				"enabled": false,
+				"models": {"llama-7b": {"enabled": true}},
+				"tenants": {"acme": {"enabled": false}, "globex": {"enabled": true}}
+			}
+		}
+	}`))
+	assert.NoError(t, err)
+	store := NewFlagStore()
+	store.Update(config)
+
+	assert.False(t, store.Enabled("semantic-cache", Target{Model: "qwen-7b"}), "global rule")
+	assert.True(t, store.Enabled("semantic-cache", Target{Model: "llama-7b"}), "model rule overrides global")
+	assert.False(t, store.Enabled("semantic-cache", Target{Model: "llama-7b", Tenant: "acme"}), "tenant rule overrides model")
+	assert.True(t, store.Enabled("semantic-cache", Target{Model: "qwen-7b", Tenant: "globex"}), "tenant rule overrides global")
+
+	assert.True(t, store.Enabled(LoraActivation, Target{}), "unconfigured flag uses its default")
+	assert.False(t, store.Enabled("unknown", Target{}))
+}
+
+func TestFlagStorePercentage(t *testing.T) {
+	config, err := ParseFlagConfig([]byte(`{"flags": {"hedging": {"enabled": true, "percentage": 20}}}`))
+	assert.NoError(t, err)
+	store := NewFlagStore()
+	store.Update(config)
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		if store.Enabled("hedging", Target{Key: fmt.Sprintf("request-%d", i)}) {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 200, enabled, 60)
+
+	// the same tenant always gets the same result
+	first := store.Enabled("hedging", Target{Tenant: "acme"})
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first, store.Enabled("hedging", Target{Tenant: "acme"}))
+	}
+
+	// the traffic without key isn't enrolled.
+	assert.False(t, store.Enabled("hedging", Target{Model: "llama-7b"}))
+}
+
+func TestFlagStoreRolloutRatio(t *testing.T) {
+	for _, percentage := range []int{0, 10, 50, 90, 100} {
+		config, err := ParseFlagConfig([]byte(fmt.Sprintf(`{"flags": {"hedging": {"enabled": true, "percentage": %d}}}`, percentage)))
+		assert.NoError(t, err)
+		store := NewFlagStore()
+		store.Update(config)
+
+		targets := map[string]func(i int) Target{
+			"tenants":     func(i int) Target { return Target{Model: "llama-7b", Tenant: fmt.Sprintf("tenant-%d", i)} },
+			"request ids": func(i int) Target { return Target{Model: "llama-7b", RequestID: fmt.Sprintf("request-%d", i)} },
+		}
+		for name, target := range targets {
+			enabled := 0
+			for i := 0; i < 10000; i++ {
+				if store.Enabled("hedging", target(i)) {
+					enabled++
+				}
+			}
+			assert.InDelta(t, float64(percentage)/100, float64(enabled)/10000, 0.02, "%d%% of %s", percentage, name)
+		}
+	}
+}
+
+func TestParseFlagConfigInvalid(t *testing.T) {
+	_, err := ParseFlagConfig([]byte(`{"flags": {"hedging": {"enabled": true, "tenants": {"acme": {"enabled": true, "percentage": 120}}}}}`))
+	assert.Error(t, err)
+
+	_, err = ParseFlagConfig([]byte(`not-json`))
+	assert.Error(t, err)
+
+	config, err := ParseFlagConfig(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, config.Flags)
+}
@@ -0,0 +1,504 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	etcdRequestTimeout = 5 * time.Second
+	// etcdScanLimit bounds the keys read by a range request of Scan.
+	etcdScanLimit = 500
+	// etcdLeaseReuseDivisor shares a lease between the keys written with the same ttl for that fraction of the ttl,
+	// they live up to that much longer.
+	etcdLeaseReuseDivisor = 10
+	// etcdPubSubPrefix holds one key per channel, every put is an event delivered to the watchers.
+	etcdPubSubPrefix = "aibrix/pubsub/"
+)
+
+// EtcdConfig describes how to reach etcd.
+type EtcdConfig struct {
+	// Endpoints are the client URLs of the etcd members, e.g. http://etcd-0.etcd:2379. They are tried in order.
+	Endpoints []string
+	Username  string
+	Password  string
+}
+
+// LoadEtcdConfig reads the etcd configuration from the environment, AIBRIX_ETCD_ENDPOINTS takes a comma separated list.
+func LoadEtcdConfig() EtcdConfig {
+	config := EtcdConfig{
+		Username: utils.LoadEnv("AIBRIX_ETCD_USERNAME", ""),
+		Password: utils.LoadEnv("AIBRIX_ETCD_PASSWORD", ""),
+	}
+	for _, endpoint := range strings.Split(utils.LoadEnv("AIBRIX_ETCD_ENDPOINTS", "http://localhost:2379"), ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			config.Endpoints = append(config.Endpoints, strings.TrimSuffix(endpoint, "/"))
+		}
+	}
+	return config
+}
+
+// etcdStore talks to the JSON gateway of the etcd v3 API, which every etcd member serves on its client port.
+// Expiring keys are attached to a lease shared by the writes with the same ttl for a tenth of it, Expire rewrites the
+// key with another lease.
+type etcdStore struct {
+	config EtcdConfig
+	client *http.Client
+	// streamClient has no timeout, watches are long running.
+	streamClient *http.Client
+
+	mu sync.Mutex
+	// tokens are the auth tokens by endpoint, simple tokens are only valid on the member that issued them.
+	tokens map[string]string
+
+	leaseMu sync.Mutex
+	leases  map[int64]etcdLease // by ttl in seconds
+}
+
+// etcdLease is shared by the writes with its ttl until reuseUntil.
+type etcdLease struct {
+	id         int64
+	reuseUntil time.Time
+}
+
+// etcdStatusError is a response of etcd with another status than 200.
+type etcdStatusError struct {
+	path    string
+	code    int
+	message string
+}
+
+func (e *etcdStatusError) Error() string {
+	return fmt.Sprintf("etcd %s returned %d: %s", e.path, e.code, e.message)
+}
+
+// invalidToken tells whether the auth token was rejected, e.g. expired or issued by another member.
+func (e *etcdStatusError) invalidToken() bool {
+	return e.code == http.StatusUnauthorized || strings.Contains(e.message, "invalid auth token")
+}
+
+var _ Store = (*etcdStore)(nil)
+
+// NewEtcdStore stores keys in etcd. Bytes are base64 encoded and 64 bit integers are strings in the JSON gateway.
+func NewEtcdStore(config EtcdConfig) (Store, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, fmt.Errorf("no etcd endpoint configured")
+	}
+	return &etcdStore{
+		config:       config,
+		client:       &http.Client{Timeout: etcdRequestTimeout},
+		streamClient: &http.Client{},
+		tokens:       map[string]string{},
+		leases:       map[int64]etcdLease{},
+	}, nil
+}
+
+type etcdKeyValue struct {
+	Key         []byte `json:"key"`
+	Value       []byte `json:"value"`
+	ModRevision int64  `json:"mod_revision,string"`
+}
+
+type etcdRangeRequest struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+	Limit    int64  `json:"limit,string,omitempty"`
+}
+
+type etcdRangeResponse struct {
+	Kvs  []etcdKeyValue `json:"kvs"`
+	More bool           `json:"more"`
+}
+
+type etcdPutRequest struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+	Lease int64  `json:"lease,string,omitempty"`
+}
+
+type etcdTxnRequest struct {
+	Compare []etcdCompare   `json:"compare,omitempty"`
+	Success []etcdRequestOp `json:"success"`
+}
+
+// etcdCompare compares the revision of the last write of the key, 0 if it doesn't exist.
+type etcdCompare struct {
+	Key         []byte `json:"key"`
+	Target      string `json:"target"`
+	Result      string `json:"result"`
+	ModRevision int64  `json:"mod_revision,string"`
+}
+
+type etcdTxnResponse struct {
+	Succeeded bool `json:"succeeded"`
+}
+
+type etcdRequestOp struct {
+	RequestPut *etcdPutRequest `json:"request_put,omitempty"`
+}
+
+type etcdLeaseGrantRequest struct {
+	TTL int64 `json:"TTL,string"`
+}
+
+type etcdLeaseGrantResponse struct {
+	ID int64 `json:"ID,string"`
+}
+
+type etcdWatchRequest struct {
+	CreateRequest etcdRangeRequest `json:"create_request"`
+}
+
+type etcdWatchResponse struct {
+	Result struct {
+		Events []struct {
+			Type string       `json:"type"`
+			Kv   etcdKeyValue `json:"kv"`
+		} `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// prefixEnd returns the range end covering every key with the prefix.
+func prefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// the prefix is all 0xff, the range ends with the keyspace.
+	return []byte{0}
+}
+
+func (s *etcdStore) Get(ctx context.Context, key string) ([]byte, error) {
+	var resp etcdRangeResponse
+	if err := s.call(ctx, "/v3/kv/range", etcdRangeRequest{Key: []byte(key)}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, ErrNotFound
+	}
+	return resp.Kvs[0].Value, nil
+}
+
+func (s *etcdStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	lease, err := s.grant(ctx, ttl)
+	if err != nil {
+		return err
+	}
+	return s.forgetLeaseOnError(ttl, s.call(ctx, "/v3/kv/put", etcdPutRequest{Key: []byte(key), Value: value, Lease: lease}, nil))
+}
+
+// SetMany puts the keys in one transaction, sharing one lease.
+func (s *etcdStore) SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	lease, err := s.grant(ctx, ttl)
+	if err != nil {
+		return err
+	}
+	txn := etcdTxnRequest{Success: make([]etcdRequestOp, 0, len(values))}
+	for key, value := range values {
+		txn.Success = append(txn.Success, etcdRequestOp{RequestPut: &etcdPutRequest{Key: []byte(key), Value: value, Lease: lease}})
+	}
+	return s.forgetLeaseOnError(ttl, s.call(ctx, "/v3/kv/txn", txn, nil))
+}
+
+// Update writes the new value in a transaction conditioned on the revision of the key it was computed from.
+func (s *etcdStore) Update(ctx context.Context, key string, fn func(current []byte) ([]byte, error), ttl time.Duration) error {
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		var current etcdRangeResponse
+		if err := s.call(ctx, "/v3/kv/range", etcdRangeRequest{Key: []byte(key)}, &current); err != nil {
+			return err
+		}
+		var value []byte
+		var revision int64
+		if len(current.Kvs) > 0 {
+			value, revision = current.Kvs[0].Value, current.Kvs[0].ModRevision
+		}
+		value, err := fn(value)
+		if err != nil {
+			return err
+		}
+		lease, err := s.grant(ctx, ttl)
+		if err != nil {
+			return err
+		}
+
+		txn := etcdTxnRequest{
+			Compare: []etcdCompare{{Key: []byte(key), Target: "MOD", Result: "EQUAL", ModRevision: revision}},
+			Success: []etcdRequestOp{{RequestPut: &etcdPutRequest{Key: []byte(key), Value: value, Lease: lease}}},
+		}
+		var resp etcdTxnResponse
+		if err := s.forgetLeaseOnError(ttl, s.call(ctx, "/v3/kv/txn", txn, &resp)); err != nil {
+			return err
+		}
+		if resp.Succeeded {
+			return nil
+		}
+	}
+	return ErrConflict
+}
+
+// grant returns a lease expiring after ttl, 0 without ttl. Leases have a one second resolution. A lease is reused by
+// the writes with the same ttl for a tenth of it, and granted that much longer, so every key lives at least its ttl
+// without a lease per write.
+func (s *etcdStore) grant(ctx context.Context, ttl time.Duration) (int64, error) {
+	if ttl <= 0 {
+		return 0, nil
+	}
+	seconds := int64(math.Ceil(ttl.Seconds()))
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+	now := time.Now()
+	if lease, ok := s.leases[seconds]; ok && now.Before(lease.reuseUntil) {
+		return lease.id, nil
+	}
+
+	reuse := time.Duration(seconds) * time.Second / etcdLeaseReuseDivisor
+	var resp etcdLeaseGrantResponse
+	if err := s.call(ctx, "/v3/lease/grant", etcdLeaseGrantRequest{TTL: seconds + int64(math.Ceil(reuse.Seconds()))}, &resp); err != nil {
+		return 0, err
+	}
+	s.leases[seconds] = etcdLease{id: resp.ID, reuseUntil: now.Add(reuse)}
+	return resp.ID, nil
+}
+
+// forgetLeaseOnError stops reusing the lease of the ttl after a failed write, which may be due to the lease, e.g.
+// revoked or lost with the data of etcd.
+func (s *etcdStore) forgetLeaseOnError(ttl time.Duration, err error) error {
+	if err != nil && ttl > 0 {
+		s.leaseMu.Lock()
+		delete(s.leases, int64(math.Ceil(ttl.Seconds())))
+		s.leaseMu.Unlock()
+	}
+	return err
+}
+
+func (s *etcdStore) Delete(ctx context.Context, key string) error {
+	return s.call(ctx, "/v3/kv/deleterange", etcdRangeRequest{Key: []byte(key)}, nil)
+}
+
+// Expire rewrites the key with a new lease, a write racing with it may be overwritten with the old value.
+func (s *etcdStore) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	value, err := s.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return s.Set(ctx, key, value, ttl)
+}
+
+// Scan reads the keys in pages of etcdScanLimit, ordered by key, each page starting after the last key of the previous.
+func (s *etcdStore) Scan(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	req := etcdRangeRequest{Key: []byte(prefix), RangeEnd: prefixEnd(prefix), Limit: etcdScanLimit}
+	for {
+		var resp etcdRangeResponse
+		if err := s.call(ctx, "/v3/kv/range", req, &resp); err != nil {
+			return err
+		}
+		for _, kv := range resp.Kvs {
+			if err := fn(string(kv.Key), kv.Value); err != nil {
+				return err
+			}
+		}
+		if !resp.More || len(resp.Kvs) == 0 {
+			return nil
+		}
+		req.Key = append(resp.Kvs[len(resp.Kvs)-1].Key, 0)
+	}
+}
+
+func (s *etcdStore) Publish(ctx context.Context, channel string, message []byte) error {
+	return s.call(ctx, "/v3/kv/put", etcdPutRequest{Key: []byte(etcdPubSubPrefix + channel), Value: message}, nil)
+}
+
+func (s *etcdStore) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	body, err := json.Marshal(etcdWatchRequest{CreateRequest: etcdRangeRequest{Key: []byte(etcdPubSubPrefix + channel)}})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, s.streamClient, "/v3/watch", body)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(chan []byte)
+	go func() {
+		defer close(messages)
+		defer resp.Body.Close()
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var watch etcdWatchResponse
+			if err := decoder.Decode(&watch); err != nil {
+				if ctx.Err() == nil {
+					klog.ErrorS(err, "etcd watch ended", "channel", channel)
+				}
+				return
+			}
+			if watch.Error != nil {
+				klog.ErrorS(nil, "etcd watch failed", "channel", channel, "error", watch.Error.Message)
+				return
+			}
+			for _, event := range watch.Result.Events {
+				if event.Type == "DELETE" {
+					continue
+				}
+				select {
+				case messages <- event.Kv.Value:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return messages, nil
+}
+
+func (s *etcdStore) Close() error {
+	s.client.CloseIdleConnections()
+	s.streamClient.CloseIdleConnections()
+	return nil
+}
+
+// call posts the request to the endpoints in order until one answers, and decodes the response into out.
+func (s *etcdStore) call(ctx context.Context, path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, s.client, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// do posts the body to the endpoints in order until one answers. A rejected token is renewed on the endpoint and
+// the request sent again once.
+func (s *etcdStore) do(ctx context.Context, client *http.Client, path string, body []byte) (*http.Response, error) {
+	var lastErr error
+	for _, endpoint := range s.config.Endpoints {
+		resp, err := s.send(ctx, client, endpoint, path, body)
+		var statusErr *etcdStatusError
+		if errors.As(err, &statusErr) && statusErr.invalidToken() && s.config.Username != "" {
+			s.forgetToken(endpoint)
+			resp, err = s.send(ctx, client, endpoint, path, body)
+		}
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if errors.As(err, &statusErr) && statusErr.code < http.StatusInternalServerError {
+			return nil, err // the request is wrong, other members would reject it too
+		}
+	}
+	return nil, lastErr
+}
+
+// send posts the body to the endpoint with the auth token of the endpoint.
+func (s *etcdStore) send(ctx context.Context, client *http.Client, endpoint, path string, body []byte) (*http.Response, error) {
+	token, err := s.authenticate(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, &etcdStatusError{path: path, code: resp.StatusCode, message: strings.TrimSpace(string(message))}
+	}
+	return resp, nil
+}
+
+// authenticate returns the auth token of the endpoint if credentials are configured. Simple tokens are only valid on
+// the member that issued them and expire after 5 minutes of inactivity, so the token is issued by the endpoint and
+// kept until it is rejected.
+func (s *etcdStore) authenticate(ctx context.Context, endpoint string) (string, error) {
+	if s.config.Username == "" {
+		return "", nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token := s.tokens[endpoint]; token != "" {
+		return token, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"name": s.config.Username, "password": s.config.Password})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/v3/auth/authenticate", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	var auth struct {
+		Token string `json:"token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&auth)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || err != nil || auth.Token == "" {
+		return "", fmt.Errorf("etcd authentication failed with status %d", resp.StatusCode)
+	}
+	s.tokens[endpoint] = auth.Token
+	return auth.Token, nil
+}
+
+func (s *etcdStore) forgetToken(endpoint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, endpoint)
+}
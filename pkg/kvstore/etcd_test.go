@@ -0,0 +1,275 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEtcd serves the subset of the etcd JSON gateway used by the store, keys are kept in a map.
+type fakeEtcd struct {
+	keys      map[string][]byte
+	leases    map[string]int64
+	ttls      map[int64]int64
+	lastLease int64
+	revisions map[string]int64
+	revision  int64
+	// conflicts are the next transactions preceded by a concurrent write of their keys.
+	conflicts int
+	ranges    int
+	// password enables authentication, token is the only token the member accepts.
+	password        string
+	token           string
+	authentications int
+}
+
+func (f *fakeEtcd) put(key string, value []byte, lease int64) {
+	f.revision++
+	f.keys[key] = value
+	f.leases[key] = lease
+	f.revisions[key] = f.revision
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Key      []byte        `json:"key"`
+		RangeEnd []byte        `json:"range_end"`
+		Value    []byte        `json:"value"`
+		Lease    int64         `json:"lease,string"`
+		TTL      int64         `json:"TTL,string"`
+		Limit    int64         `json:"limit,string"`
+		Password string        `json:"password"`
+		Compare  []etcdCompare `json:"compare"`
+		Success  []struct {
+			RequestPut etcdPutRequest `json:"request_put"`
+		} `json:"success"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Path == "/v3/auth/authenticate" {
+		if req.Password != f.password {
+			http.Error(w, `{"error": "etcdserver: authentication failed, invalid user ID or password"}`, http.StatusBadRequest)
+			return
+		}
+		f.authentications++
+		f.token = "token-" + strconv.Itoa(f.authentications)
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": f.token})
+		return
+	}
+	if f.password != "" && r.Header.Get("Authorization") != f.token {
+		http.Error(w, `{"error": "etcdserver: invalid auth token", "code": 16}`, http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		f.ranges++
+		var kvs []etcdKeyValue
+		for key, value := range f.keys {
+			if key == string(req.Key) || (req.RangeEnd != nil && key >= string(req.Key) && key < string(req.RangeEnd)) {
+				kvs = append(kvs, etcdKeyValue{Key: []byte(key), Value: value, ModRevision: f.revisions[key]})
+			}
+		}
+		sort.Slice(kvs, func(i, j int) bool { return string(kvs[i].Key) < string(kvs[j].Key) })
+		more := req.Limit > 0 && int64(len(kvs)) > req.Limit
+		if more {
+			kvs = kvs[:req.Limit]
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"kvs": kvs, "more": more})
+	case "/v3/kv/put":
+		f.put(string(req.Key), req.Value, req.Lease)
+		_, _ = w.Write([]byte(`{}`))
+	case "/v3/kv/txn":
+		for _, compare := range req.Compare {
+			if f.conflicts > 0 {
+				f.conflicts--
+				f.put(string(compare.Key), []byte("concurrent"), 0)
+			}
+			if compare.Target != "MOD" || compare.Result != "EQUAL" || f.revisions[string(compare.Key)] != compare.ModRevision {
+				_, _ = w.Write([]byte(`{"succeeded": false}`))
+				return
+			}
+		}
+		for _, op := range req.Success {
+			f.put(string(op.RequestPut.Key), op.RequestPut.Value, op.RequestPut.Lease)
+		}
+		_, _ = w.Write([]byte(`{"succeeded": true}`))
+	case "/v3/kv/deleterange":
+		delete(f.keys, string(req.Key))
+		delete(f.revisions, string(req.Key))
+		_, _ = w.Write([]byte(`{}`))
+	case "/v3/lease/grant":
+		f.lastLease++
+		f.ttls[f.lastLease] = req.TTL
+		_ = json.NewEncoder(w).Encode(map[string]string{"ID": strconv.FormatInt(f.lastLease, 10), "TTL": strconv.FormatInt(req.TTL, 10)})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeEtcd() *fakeEtcd {
+	return &fakeEtcd{keys: map[string][]byte{}, leases: map[string]int64{}, ttls: map[int64]int64{}, lastLease: 70, revisions: map[string]int64{}}
+}
+
+func TestEtcdStore(t *testing.T) {
+	fake := newFakeEtcd()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	// the first member is down, requests fail over to the next one.
+	store, err := NewEtcdStore(EtcdConfig{Endpoints: []string{"http://127.0.0.1:1", server.URL}})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Set(ctx, "aibrix:llama_request_trace_10", []byte("a"), 1500*time.Millisecond))
+	assert.NoError(t, store.Set(ctx, "aibrix:llama_request_trace_20", []byte("b"), 0))
+	assert.NoError(t, store.Set(ctx, "aibrix:llamb", []byte("c"), 0))
+	assert.Equal(t, int64(71), fake.leases["aibrix:llama_request_trace_10"])
+	assert.Equal(t, int64(3), fake.ttls[71], "ttl is rounded up to seconds, plus the reuse of the lease")
+	assert.Zero(t, fake.leases["aibrix:llama_request_trace_20"])
+
+	value, err := store.Get(ctx, "aibrix:llama_request_trace_10")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a"), value)
+	_, err = store.Get(ctx, "aibrix:qwen_request_trace_10")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var keys []string
+	assert.NoError(t, store.Scan(ctx, "aibrix:llama_", func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	assert.Equal(t, []string{"aibrix:llama_request_trace_10", "aibrix:llama_request_trace_20"}, keys)
+
+	assert.NoError(t, store.Expire(ctx, "aibrix:llama_request_trace_20", time.Minute))
+	assert.Equal(t, int64(72), fake.leases["aibrix:llama_request_trace_20"])
+	assert.ErrorIs(t, store.Expire(ctx, "aibrix:qwen_request_trace_10", time.Minute), ErrNotFound)
+
+	assert.NoError(t, store.Delete(ctx, "aibrix:llama_request_trace_20"))
+	_, err = store.Get(ctx, "aibrix:llama_request_trace_20")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// the keys of a batch share one lease, with the keys written with the same ttl shortly before.
+	assert.NoError(t, store.SetMany(ctx, map[string][]byte{"aibrix:qwen_request_trace_30": []byte("d"), "aibrix:llama_request_trace_30": []byte("e")}, time.Minute))
+	assert.Equal(t, []byte("d"), fake.keys["aibrix:qwen_request_trace_30"])
+	assert.Equal(t, int64(72), fake.leases["aibrix:qwen_request_trace_30"])
+	assert.Equal(t, int64(72), fake.leases["aibrix:llama_request_trace_30"])
+	assert.Equal(t, int64(66), fake.ttls[72])
+	assert.NoError(t, store.Set(ctx, "aibrix:qwen_request_trace_40", []byte("f"), 2*time.Minute))
+	assert.Equal(t, int64(73), fake.leases["aibrix:qwen_request_trace_40"])
+
+	// a lease is granted again once it was reused for a tenth of its ttl.
+	etcd := store.(*etcdStore)
+	lease := etcd.leases[60]
+	lease.reuseUntil = time.Now()
+	etcd.leases[60] = lease
+	assert.NoError(t, store.Set(ctx, "aibrix:qwen_request_trace_50", []byte("g"), time.Minute))
+	assert.Equal(t, int64(74), fake.leases["aibrix:qwen_request_trace_50"])
+}
+
+func TestEtcdStoreScan(t *testing.T) {
+	fake := newFakeEtcd()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	store, err := NewEtcdStore(EtcdConfig{Endpoints: []string{server.URL}})
+	assert.NoError(t, err)
+
+	for i := 0; i < 2*etcdScanLimit+1; i++ {
+		fake.put(fmt.Sprintf("aibrix:llama_request_trace_%04d", i), []byte("a"), 0)
+	}
+	fake.put("aibrix:qwen_request_trace_0", []byte("b"), 0)
+	var keys []string
+	assert.NoError(t, store.Scan(context.Background(), "aibrix:llama_", func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	assert.Len(t, keys, 2*etcdScanLimit+1)
+	assert.True(t, sort.StringsAreSorted(keys))
+	assert.Equal(t, 3, fake.ranges, "the keys are read in pages")
+}
+
+func TestEtcdStoreAuthentication(t *testing.T) {
+	first, second := newFakeEtcd(), newFakeEtcd()
+	first.password, second.password = "secret", "secret"
+	firstServer, secondServer := httptest.NewServer(first), httptest.NewServer(second)
+	defer secondServer.Close()
+	store, err := NewEtcdStore(EtcdConfig{Endpoints: []string{firstServer.URL, secondServer.URL}, Username: "aibrix", Password: "secret"})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Set(ctx, "aibrix:a", []byte("a"), 0))
+	assert.NoError(t, store.Set(ctx, "aibrix:b", []byte("b"), 0))
+	assert.Equal(t, 1, first.authentications, "the token is kept")
+
+	// the token expired, it is renewed and the request sent again.
+	first.token = "expired"
+	assert.NoError(t, store.Set(ctx, "aibrix:c", []byte("c"), 0))
+	assert.Equal(t, 2, first.authentications)
+	assert.Equal(t, []byte("c"), first.keys["aibrix:c"])
+
+	// the requests fail over to the second member with a token issued by it.
+	firstServer.Close()
+	assert.NoError(t, store.Set(ctx, "aibrix:d", []byte("d"), 0))
+	assert.Equal(t, 1, second.authentications)
+	assert.Equal(t, []byte("d"), second.keys["aibrix:d"])
+
+	// a token rejected again is not renewed a second time.
+	second.password = "rotated"
+	second.token = "expired"
+	assert.Error(t, store.Set(ctx, "aibrix:e", []byte("e"), 0))
+}
+
+func TestEtcdStoreUpdate(t *testing.T) {
+	fake := newFakeEtcd()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	store, err := NewEtcdStore(EtcdConfig{Endpoints: []string{server.URL}})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	appendB := func(current []byte) ([]byte, error) { return append(current, 'b'), nil }
+
+	assert.NoError(t, store.Update(ctx, "aibrix:counter", appendB, time.Minute))
+	assert.Equal(t, []byte("b"), fake.keys["aibrix:counter"])
+	assert.Equal(t, int64(71), fake.leases["aibrix:counter"])
+
+	// the value is computed again from the concurrent write.
+	fake.conflicts = 1
+	assert.NoError(t, store.Update(ctx, "aibrix:counter", appendB, 0))
+	assert.Equal(t, []byte("concurrentb"), fake.keys["aibrix:counter"])
+
+	fake.conflicts = updateAttempts
+	assert.ErrorIs(t, store.Update(ctx, "aibrix:counter", appendB, 0), ErrConflict)
+}
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte("aibrix;"), prefixEnd("aibrix:"))
+	assert.Equal(t, []byte("b"), prefixEnd("a\xff"))
+	assert.Equal(t, []byte{0}, prefixEnd("\xff"))
+}
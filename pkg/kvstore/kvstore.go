@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kvstore abstracts the key value store shared by the gateway replicas and the controllers, so state like
+// request traces can live in Redis, etcd or, for a single replica and tests, in memory.
+package kvstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	BackendRedis  = "redis"
+	BackendEtcd   = "etcd"
+	BackendMemory = "memory"
+)
+
+// updateAttempts bounds how often Update reads the key again after it changed concurrently.
+const updateAttempts = 10
+
+var (
+	// ErrNotFound is returned when the key doesn't exist or expired.
+	ErrNotFound = errors.New("key not found")
+	// ErrConflict is returned by Update when the key kept changing concurrently.
+	ErrConflict = errors.New("key changed concurrently")
+)
+
+// Store is a key value store with expiring keys and publish/subscribe.
+type Store interface {
+	// Get returns the value of the key, ErrNotFound if it doesn't exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set writes the value of the key, it expires after ttl unless ttl is 0.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetMany writes the values of the keys in one round trip where the backend supports it, they expire after ttl
+	// unless ttl is 0. It is not atomic, some keys may be written when an error is returned.
+	SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error
+	// Update replaces the value of the key with the one fn returns for its current value, nil if it doesn't exist, and
+	// it expires after ttl unless ttl is 0. fn is called again if the key changed before the new value was written, so
+	// concurrent updates of the key are all applied, or ErrConflict is returned if it kept changing.
+	Update(ctx context.Context, key string, fn func(current []byte) ([]byte, error), ttl time.Duration) error
+	// Delete removes the key, deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// Expire sets the ttl of an existing key, 0 persists it. It returns ErrNotFound if the key doesn't exist.
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	// Scan calls fn for every key with the prefix, in no particular order. An error returned by fn stops the scan.
+	Scan(ctx context.Context, prefix string, fn func(key string, value []byte) error) error
+	// Publish sends the message to the current subscribers of the channel.
+	Publish(ctx context.Context, channel string, message []byte) error
+	// Subscribe delivers the messages published on the channel until ctx is done, then the returned channel is closed.
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+	// Close releases the connections of the store.
+	Close() error
+}
+
+// New creates the store of the backend, redisClient is only used by the redis backend.
+func New(backend string, redisClient redis.UniversalClient) (Store, error) {
+	switch strings.ToLower(backend) {
+	case BackendRedis, "":
+		if redisClient == nil {
+			return nil, fmt.Errorf("redis kv store requires a redis client")
+		}
+		return NewRedisStore(redisClient), nil
+	case BackendEtcd:
+		return NewEtcdStore(LoadEtcdConfig())
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown kv store backend %q, must be one of redis, etcd or memory", backend)
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvstore
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memorySubscriberBuffer bounds the messages queued for a slow subscriber, further messages are dropped like
+// Redis drops messages of clients falling behind.
+const memorySubscriberBuffer = 64
+
+type memoryEntry struct {
+	value    []byte
+	expireAt time.Time // zero if the key doesn't expire
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// MemoryStore keeps keys in the process. It is meant for a single gateway replica and for tests.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	entries     map[string]memoryEntry
+	subscribers map[string]map[chan []byte]struct{}
+	now         func() time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:     map[string]memoryEntry{},
+		subscribers: map[string]map[chan []byte]struct{}{},
+		now:         time.Now,
+	}
+}
+
+func (s *MemoryStore) expireAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return s.now().Add(ttl)
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(s.now()) {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), entry.value...), nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryEntry{value: append([]byte(nil), value...), expireAt: s.expireAt(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) SetMany(_ context.Context, values map[string][]byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, value := range values {
+		s.entries[key] = memoryEntry{value: append([]byte(nil), value...), expireAt: s.expireAt(ttl)}
+	}
+	return nil
+}
+
+// Update calls fn under the lock of the store, fn must not call back into it.
+func (s *MemoryStore) Update(_ context.Context, key string, fn func(current []byte) ([]byte, error), ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var current []byte
+	if entry, ok := s.entries[key]; ok && !entry.expired(s.now()) {
+		current = append([]byte(nil), entry.value...)
+	}
+	value, err := fn(current)
+	if err != nil {
+		return err
+	}
+	s.entries[key] = memoryEntry{value: append([]byte(nil), value...), expireAt: s.expireAt(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) Expire(_ context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(s.now()) {
+		delete(s.entries, key)
+		return ErrNotFound
+	}
+	entry.expireAt = s.expireAt(ttl)
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *MemoryStore) Scan(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	// collect first, fn may call back into the store.
+	s.mu.Lock()
+	now := s.now()
+	matches := map[string][]byte{}
+	for key, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, key) // expired keys are purged lazily
+			continue
+		}
+		if strings.HasPrefix(key, prefix) {
+			matches[key] = append([]byte(nil), entry.value...)
+		}
+	}
+	s.mu.Unlock()
+
+	for key, value := range matches {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Publish(_ context.Context, channel string, message []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for subscriber := range s.subscribers[channel] {
+		select {
+		case subscriber <- append([]byte(nil), message...):
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	subscriber := make(chan []byte, memorySubscriberBuffer)
+	s.mu.Lock()
+	if s.subscribers[channel] == nil {
+		s.subscribers[channel] = map[chan []byte]struct{}{}
+	}
+	s.subscribers[channel][subscriber] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscribers[channel], subscriber)
+		if len(s.subscribers[channel]) == 0 {
+			delete(s.subscribers, channel)
+		}
+		close(subscriber)
+	}()
+	return subscriber, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Set(ctx, "aibrix:llama_request_trace_10", []byte("a"), time.Minute))
+	assert.NoError(t, store.Set(ctx, "aibrix:llama_request_trace_20", []byte("b"), 0))
+	assert.NoError(t, store.Set(ctx, "aibrix:qwen_request_trace_10", []byte("c"), 0))
+
+	value, err := store.Get(ctx, "aibrix:llama_request_trace_10")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a"), value)
+
+	scanned := map[string]string{}
+	assert.NoError(t, store.Scan(ctx, "aibrix:llama_", func(key string, value []byte) error {
+		scanned[key] = string(value)
+		return nil
+	}))
+	assert.Equal(t, map[string]string{"aibrix:llama_request_trace_10": "a", "aibrix:llama_request_trace_20": "b"}, scanned)
+
+	// keys expire with their ttl, Expire changes it.
+	assert.NoError(t, store.Expire(ctx, "aibrix:llama_request_trace_20", 2*time.Minute))
+	now = now.Add(time.Minute)
+	_, err = store.Get(ctx, "aibrix:llama_request_trace_10")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Expire(ctx, "aibrix:llama_request_trace_10", time.Minute), ErrNotFound)
+	_, err = store.Get(ctx, "aibrix:llama_request_trace_20")
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Expire(ctx, "aibrix:llama_request_trace_20", 0))
+	now = now.Add(time.Hour)
+	_, err = store.Get(ctx, "aibrix:llama_request_trace_20")
+	assert.NoError(t, err, "expire with 0 persists the key")
+
+	assert.NoError(t, store.SetMany(ctx, map[string][]byte{"aibrix:qwen_request_trace_20": []byte("d")}, time.Minute))
+	value, err = store.Get(ctx, "aibrix:qwen_request_trace_20")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("d"), value)
+
+	appendB := func(current []byte) ([]byte, error) { return append(current, 'b'), nil }
+	assert.NoError(t, store.Update(ctx, "aibrix:qwen_request_trace_20", appendB, time.Minute))
+	assert.NoError(t, store.Update(ctx, "aibrix:qwen_request_trace_30", appendB, time.Minute))
+	value, err = store.Get(ctx, "aibrix:qwen_request_trace_20")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("db"), value)
+	value, err = store.Get(ctx, "aibrix:qwen_request_trace_30")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("b"), value)
+
+	assert.NoError(t, store.Delete(ctx, "aibrix:llama_request_trace_20"))
+	assert.NoError(t, store.Delete(ctx, "aibrix:llama_request_trace_20"))
+	_, err = store.Get(ctx, "aibrix:llama_request_trace_20")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryStorePubSub(t *testing.T) {
+	store := NewMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	messages, err := store.Subscribe(ctx, "routing")
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Publish(context.Background(), "routing", []byte("hello")))
+	assert.NoError(t, store.Publish(context.Background(), "other", []byte("ignored")))
+	assert.Equal(t, []byte("hello"), <-messages)
+
+	cancel()
+	_, ok := <-messages
+	assert.False(t, ok, "the channel is closed once the context is done")
+	assert.NoError(t, store.Publish(context.Background(), "routing", []byte("after")))
+}
+
+func TestNew(t *testing.T) {
+	store, err := New(BackendMemory, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &MemoryStore{}, store)
+
+	_, err = New(BackendRedis, nil)
+	assert.Error(t, err)
+
+	_, err = New("consul", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/vllm-project/aibrix/pkg/storage"
+)
+
+const redisScanCount = 100
+
+type redisStore struct {
+	client redis.UniversalClient
+}
+
+var _ Store = (*redisStore)(nil)
+
+// NewRedisStore stores keys in Redis, in every topology supported by storage.NewRedisClient.
+func NewRedisStore(client redis.UniversalClient) Store {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *redisStore) SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	// pipelines are split by slot in cluster mode, so the keys don't need to share one.
+	pipe := s.client.Pipeline()
+	for key, value := range values {
+		pipe.Set(ctx, key, value, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Update watches the key, so the transaction writing the new value fails if the key changed since it was read.
+func (s *redisStore) Update(ctx context.Context, key string, fn func(current []byte) ([]byte, error), ttl time.Duration) error {
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+			current, err := tx.Get(ctx, key).Bytes()
+			if errors.Is(err, redis.Nil) {
+				current, err = nil, nil
+			}
+			if err != nil {
+				return err
+			}
+			value, err := fn(current)
+			if err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, value, ttl)
+				return nil
+			})
+			return err
+		}, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return ErrConflict
+}
+
+func (s *redisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+func (s *redisStore) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	var (
+		ok  bool
+		err error
+	)
+	if ttl == 0 {
+		// PERSIST also fails on keys without ttl, tell them apart from missing keys.
+		if ok, err = s.client.Persist(ctx, key).Result(); err == nil && !ok {
+			var exists int64
+			exists, err = s.client.Exists(ctx, key).Result()
+			ok = exists > 0
+		}
+	} else {
+		ok, err = s.client.Expire(ctx, key, ttl).Result()
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *redisStore) Scan(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	// keys are spread over the masters of a Redis Cluster, each one is scanned.
+	return storage.ForEachShard(ctx, s.client, func(ctx context.Context, shard *redis.Client) error {
+		iter := shard.Scan(ctx, 0, prefix+"*", redisScanCount).Iterator()
+		for iter.Next(ctx) {
+			key := iter.Val()
+			value, err := shard.Get(ctx, key).Bytes()
+			if errors.Is(err, redis.Nil) {
+				continue // expired while scanning
+			}
+			if err != nil {
+				return err
+			}
+			if err := fn(key, value); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	})
+}
+
+func (s *redisStore) Publish(ctx context.Context, channel string, message []byte) error {
+	return s.client.Publish(ctx, channel, message).Err()
+}
+
+func (s *redisStore) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	pubsub := s.client.Subscribe(ctx, channel)
+	// wait for the confirmation, so messages published once Subscribe returned are delivered.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	messages := make(chan []byte)
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return messages, nil
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
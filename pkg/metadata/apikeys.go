@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+	"k8s.io/klog/v2"
+)
+
+func (s *httpServer) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	var k utils.APIKey
+
+	err := decodeJSONBody(w, r, &k)
+	if err != nil {
+		var mr *malformedRequest
+		if errors.As(err, &mr) {
+			http.Error(w, mr.msg, mr.status)
+		} else {
+			klog.Info(err.Error())
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if k.Key == "" {
+		if k.Key, err = utils.GenerateAPIKey(); err != nil {
+			http.Error(w, fmt.Sprintf("error occurred on generating api key: %+v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := utils.SetAPIKey(k, s.redisClient); err != nil {
+		http.Error(w, fmt.Sprintf("error occurred on creating api key: %+v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// the plain key is only returned once, only its hash is stored.
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(k); err != nil {
+		klog.ErrorS(err, "failed to encode api key")
+	}
+}
+
+func (s *httpServer) deleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	var k utils.APIKey
+
+	err := decodeJSONBody(w, r, &k)
+	if err != nil {
+		var mr *malformedRequest
+		if errors.As(err, &mr) {
+			http.Error(w, mr.msg, mr.status)
+		} else {
+			klog.Info(err.Error())
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if k.Key == "" {
+		http.Error(w, "key is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := utils.DelAPIKey(k.Key, s.redisClient); err != nil {
+		http.Error(w, fmt.Sprintf("error occurred on deleting api key: %+v", err), http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprintf(w, "Deleted api key of user: %s", k.User)
+}
@@ -28,10 +28,10 @@ import (
 )
 
 type httpServer struct {
-	redisClient *redis.Client
+	redisClient redis.UniversalClient
 }
 
-func NewHTTPServer(addr string, redis *redis.Client) *http.Server {
+func NewHTTPServer(addr string, redis redis.UniversalClient) *http.Server {
 	server := &httpServer{
 		redisClient: redis,
 	}
@@ -40,6 +40,8 @@ func NewHTTPServer(addr string, redis *redis.Client) *http.Server {
 	r.HandleFunc("/ReadUser", server.readUser).Methods("POST")
 	r.HandleFunc("/UpdateUser", server.updateUser).Methods("POST")
 	r.HandleFunc("/DeleteUser", server.deleteUser).Methods("POST")
+	r.HandleFunc("/CreateAPIKey", server.createAPIKey).Methods("POST")
+	r.HandleFunc("/DeleteAPIKey", server.deleteAPIKey).Methods("POST")
 
 	return &http.Server{
 		Addr:    addr,
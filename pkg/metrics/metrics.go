@@ -46,6 +46,14 @@ const (
 	MaxLora                              = "max_lora"
 	WaitingLoraAdapters                  = "waiting_lora_adapters"
 	RunningLoraAdapters                  = "running_lora_adapters"
+	KVOffloadL1QueryTokens               = "kv_offload_l1_query_tokens_total"
+	KVOffloadL1HitTokens                 = "kv_offload_l1_hit_tokens_total"
+	KVOffloadL2QueryTokens               = "kv_offload_l2_query_tokens_total"
+	KVOffloadL2HitTokens                 = "kv_offload_l2_hit_tokens_total"
+	KVOffloadL1Evictions                 = "kv_offload_l1_evictions_total"
+	KVOffloadL2Evictions                 = "kv_offload_l2_evictions_total"
+	KVOffloadTransferBytes               = "kv_offload_transfer_bytes_total"
+	KVOffloadTransferBandwidth           = "kv_offload_transfer_bandwidth_bytes_per_s"
 )
 
 var (
@@ -301,5 +309,70 @@ var (
 			RawMetricName: "lora_requests_info",
 			Description:   "Count of waiting Lora Adapters",
 		},
+		// KV offloading connector metrics
+		KVOffloadL1QueryTokens: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: PodRawMetrics,
+			MetricType: MetricType{
+				Raw: Counter,
+			},
+			Description: "Number of prompt tokens looked up in the L1 (engine local) KV offloading cache",
+		},
+		KVOffloadL1HitTokens: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: PodRawMetrics,
+			MetricType: MetricType{
+				Raw: Counter,
+			},
+			Description: "Number of prompt tokens hit in the L1 (engine local) KV offloading cache",
+		},
+		KVOffloadL2QueryTokens: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: PodRawMetrics,
+			MetricType: MetricType{
+				Raw: Counter,
+			},
+			Description: "Number of prompt tokens looked up in the L2 (external) KV cache",
+		},
+		KVOffloadL2HitTokens: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: PodRawMetrics,
+			MetricType: MetricType{
+				Raw: Counter,
+			},
+			Description: "Number of prompt tokens hit in the L2 (external) KV cache",
+		},
+		KVOffloadL1Evictions: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: PodRawMetrics,
+			MetricType: MetricType{
+				Raw: Counter,
+			},
+			Description: "Number of blocks evicted from the L1 (engine local) KV offloading cache",
+		},
+		KVOffloadL2Evictions: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: PodRawMetrics,
+			MetricType: MetricType{
+				Raw: Counter,
+			},
+			Description: "Number of blocks evicted from the L2 (external) KV cache",
+		},
+		KVOffloadTransferBytes: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: PodRawMetrics,
+			MetricType: MetricType{
+				Raw: Counter,
+			},
+			Description: "Bytes of KV cache transferred between the engine and the offloading tiers",
+		},
+		KVOffloadTransferBandwidth: {
+			MetricScope:  PodModelMetricScope,
+			MetricSource: PodRawMetrics,
+			MetricType: MetricType{
+				Raw: Gauge,
+			},
+			Description: "KV cache transfer bandwidth in bytes per second, derived from transferred bytes between scrapes",
+		},
 	}
 )
@@ -17,11 +17,14 @@ limitations under the License.
 package metrics
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/api"
 	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
@@ -96,6 +99,16 @@ func extractMetricValue(line string) (float64, error) {
 	return strconv.ParseFloat(parts[len(parts)-1], 64)
 }
 
+// parseSampleValue is extractMetricValue without converting the line to a string.
+func parseSampleValue(line []byte) (float64, error) {
+	line = bytes.TrimRight(line, " \t\r")
+	i := bytes.LastIndexAny(line, " \t")
+	if i == -1 {
+		return 0, fmt.Errorf("unexpected format: %s", line)
+	}
+	return strconv.ParseFloat(string(line[i+1:]), 64)
+}
+
 // ParseMetricFromBody parses a simple metric from the Prometheus response body.
 func ParseMetricFromBody(body []byte, metricName string) (float64, error) {
 	lines := strings.Split(string(body), "\n")
@@ -111,6 +124,75 @@ func ParseMetricFromBody(body []byte, metricName string) (float64, error) {
 	return 0, fmt.Errorf("metrics %s not found", metricName)
 }
 
+// ParseMetricsFromBody parses the given metrics from the Prometheus response body in a single scan, instead of a scan
+// per metric as ParseMetricFromBody and ParseHistogramFromBody do. metricTypes maps the raw metric names to parse to
+// their type, the metrics not found in the body are missing from the result.
+func ParseMetricsFromBody(body []byte, metricTypes map[string]RawMetricType) (map[string]MetricValue, error) {
+	values := make(map[string]MetricValue, len(metricTypes))
+	for len(body) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(body, '\n'); i >= 0 {
+			line, body = body[:i], body[i+1:]
+		} else {
+			line, body = body, nil
+		}
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		end := bytes.IndexAny(line, "{ ")
+		if end <= 0 {
+			continue
+		}
+		name := line[:end]
+
+		if metricType, ok := metricTypes[string(name)]; ok && metricType != Histogram {
+			if _, ok := values[string(name)]; ok {
+				// the first sample wins, as in ParseMetricFromBody.
+				continue
+			}
+			value, err := parseSampleValue(line)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse metric value for %s: %w", name, err)
+			}
+			values[string(name)] = &SimpleMetricValue{Value: value}
+			continue
+		}
+
+		for _, suffix := range []string{"_sum", "_count", "_bucket"} {
+			if !bytes.HasSuffix(name, []byte(suffix)) {
+				continue
+			}
+			metricName := string(name[:len(name)-len(suffix)])
+			if metricTypes[metricName] != Histogram {
+				break
+			}
+			histogram, ok := values[metricName].(*HistogramMetricValue)
+			if !ok {
+				histogram = &HistogramMetricValue{Buckets: make(map[string]float64)}
+				values[metricName] = histogram
+			}
+			value, err := parseSampleValue(line)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s for metric %s: %w", suffix[1:], metricName, err)
+			}
+			switch suffix {
+			case "_sum":
+				histogram.Sum = value
+			case "_count":
+				histogram.Count = value
+			default:
+				bucketBoundary := extractBucketBoundary(string(line))
+				if bucketBoundary == "" {
+					return nil, fmt.Errorf("failed to extract bucket boundary for metric %s", metricName)
+				}
+				histogram.Buckets[bucketBoundary] = value
+			}
+			break
+		}
+	}
+	return values, nil
+}
+
 // BuildQuery dynamically injects labels into a PromQL query template.
 func BuildQuery(queryTemplate string, queryLabels map[string]string) string {
 	placeholderPattern := regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
@@ -199,16 +281,49 @@ func GetHistogramValue(metric *dto.Metric) (*HistogramMetricValue, error) {
 	return histogram, nil
 }
 
+const (
+	defaultScrapeTimeout      = 2 * time.Second
+	defaultScrapeMaxIdleConns = 1024
+)
+
+// defaultScrapeClient is the client of ParseMetricsURL.
+var defaultScrapeClient = NewScrapeClient(defaultScrapeTimeout, defaultScrapeMaxIdleConns)
+
+// NewScrapeClient returns a client to scrape the metrics of the engines. It keeps a connection alive per engine, up to
+// maxIdleConns across the engines, so that a refresh doesn't open a connection per engine and leave as many sockets in
+// TIME_WAIT. maxIdleConns should be at least the number of engines scraped, 0 means no limit.
+func NewScrapeClient(timeout time.Duration, maxIdleConns int) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxIdleConns
+	// a scrape is a single request at a time, a second connection is only needed while the first one is closing.
+	transport.MaxIdleConnsPerHost = 2
+	transport.IdleConnTimeout = 90 * time.Second
+	// HTTP/2 is negotiated with the engines serving their metrics over TLS.
+	transport.ForceAttemptHTTP2 = true
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// ParseMetricsURL scrapes and parses the metrics of url with a shared scrape client.
 func ParseMetricsURL(url string) (map[string]*dto.MetricFamily, error) {
-	resp, err := http.Get(url)
+	return ParseMetricsURLWithClient(defaultScrapeClient, url)
+}
+
+// ParseMetricsURLWithClient scrapes and parses the metrics of url with client.
+func ParseMetricsURLWithClient(client *http.Client, url string) (map[string]*dto.MetricFamily, error) {
+	resp, err := client.Get(url)
 	if err != nil {
 		return make(map[string]*dto.MetricFamily), fmt.Errorf("Failed to fetch metrics from %s: %v", url, err)
 	}
 	defer func() {
+		// drain the body so that the connection is reused.
+		_, _ = io.Copy(io.Discard, resp.Body)
 		if err := resp.Body.Close(); err != nil {
 			fmt.Printf("failed to close response body: %v", err)
 		}
 	}()
+	if resp.StatusCode != http.StatusOK {
+		return make(map[string]*dto.MetricFamily), fmt.Errorf("Failed to fetch metrics from %s: status %d", url, resp.StatusCode)
+	}
 
 	var parser expfmt.TextParser
 	allMetrics, err := parser.TextToMetricFamilies(resp.Body)
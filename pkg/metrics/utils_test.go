@@ -17,7 +17,14 @@ limitations under the License.
 package metrics
 
 import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -67,6 +74,128 @@ vllm:num_requests_waiting{model_name="Qwen/Qwen2.5-1.5B-Instruct"} 2
 	})
 }
 
+func TestParseMetricsFromBody(t *testing.T) {
+	body := []byte(`
+# HELP vllm:num_requests_waiting Number of requests waiting to be processed.
+# TYPE vllm:num_requests_waiting gauge
+vllm:num_requests_waiting{model_name="Qwen/Qwen2.5-1.5B-Instruct"} 2
+vllm:num_requests_waiting_total{model_name="Qwen/Qwen2.5-1.5B-Instruct"} 7
+# TYPE vllm:time_per_output_token_seconds histogram
+vllm:time_per_output_token_seconds_sum{model_name="Qwen/Qwen2.5-1.5B-Instruct"} 0.5
+vllm:time_per_output_token_seconds_count{model_name="Qwen/Qwen2.5-1.5B-Instruct"} 29.0
+vllm:time_per_output_token_seconds_bucket{le="0.1",model_name="Qwen/Qwen2.5-1.5B-Instruct"} 20.0
+vllm:time_per_output_token_seconds_bucket{le="+Inf",model_name="Qwen/Qwen2.5-1.5B-Instruct"} 29.0
+process_open_fds 12`)
+
+	values, err := ParseMetricsFromBody(body, map[string]RawMetricType{
+		"vllm:num_requests_waiting":          Gauge,
+		"vllm:num_requests_running":          Gauge,
+		"vllm:time_per_output_token_seconds": Histogram,
+		"process_open_fds":                   Counter,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]MetricValue{
+		"vllm:num_requests_waiting": &SimpleMetricValue{Value: 2},
+		"vllm:time_per_output_token_seconds": &HistogramMetricValue{
+			Sum: 0.5, Count: 29, Buckets: map[string]float64{"0.1": 20, "+Inf": 29},
+		},
+		"process_open_fds": &SimpleMetricValue{Value: 12},
+	}, values)
+
+	_, err = ParseMetricsFromBody([]byte("vllm:num_requests_waiting NaN?"), map[string]RawMetricType{"vllm:num_requests_waiting": Gauge})
+	assert.Error(t, err)
+}
+
+// benchmarkMetricsBody returns an exposition payload the size of a vLLM engine serving several models.
+func benchmarkMetricsBody() ([]byte, map[string]RawMetricType) {
+	var body strings.Builder
+	metricTypes := map[string]RawMetricType{}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("vllm:gauge_%d", i)
+		metricTypes[name] = Gauge
+		fmt.Fprintf(&body, "# TYPE %s gauge\n", name)
+		for model := 0; model < 8; model++ {
+			fmt.Fprintf(&body, "%s{model_name=\"model-%d\"} %d\n", name, model, i)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("vllm:histogram_%d", i)
+		metricTypes[name] = Histogram
+		fmt.Fprintf(&body, "# TYPE %s histogram\n", name)
+		for model := 0; model < 8; model++ {
+			for _, le := range []string{"0.01", "0.05", "0.1", "0.5", "1", "5", "10", "+Inf"} {
+				fmt.Fprintf(&body, "%s_bucket{le=\"%s\",model_name=\"model-%d\"} %d\n", name, le, model, i)
+			}
+			fmt.Fprintf(&body, "%s_sum{model_name=\"model-%d\"} %d\n", name, model, i)
+			fmt.Fprintf(&body, "%s_count{model_name=\"model-%d\"} %d\n", name, model, i)
+		}
+	}
+	return []byte(body.String()), metricTypes
+}
+
+func BenchmarkParseMetricPerName(b *testing.B) {
+	body, metricTypes := benchmarkMetricsBody()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for name, metricType := range metricTypes {
+			var err error
+			if metricType == Histogram {
+				_, err = ParseHistogramFromBody(body, name)
+			} else {
+				_, err = ParseMetricFromBody(body, name)
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkParseMetricsFromBody(b *testing.B) {
+	body, metricTypes := benchmarkMetricsBody()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseMetricsFromBody(body, metricTypes); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseMetricsURLWithClient(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("# TYPE vllm:num_requests_running gauge\nvllm:num_requests_running 3\n"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewScrapeClient(100*time.Millisecond, 0)
+	for i := 0; i < 5; i++ {
+		families, err := ParseMetricsURLWithClient(client, server.URL+"/metrics")
+		assert.NoError(t, err)
+		assert.Equal(t, 3.0, families["vllm:num_requests_running"].GetMetric()[0].GetGauge().GetValue())
+	}
+	// the connection is kept alive across scrapes.
+	assert.Equal(t, int32(1), conns.Load())
+
+	_, err := ParseMetricsURLWithClient(client, server.URL+"/missing")
+	assert.Error(t, err)
+	_, err = ParseMetricsURLWithClient(client, server.URL+"/slow")
+	assert.Error(t, err)
+}
+
 func TestExtractBucketBoundary(t *testing.T) {
 	line := `vllm:time_per_output_token_seconds_bucket{le="0.1",model_name="Qwen/Qwen2.5-1.5B-Instruct"} 29.0`
 
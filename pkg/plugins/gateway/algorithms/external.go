@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/routerapi"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const defaultExternalRouterTimeoutMs = 100
+
+var (
+	externalRouterTimeout = getExternalRouterTimeout()
+	externalRouterMetrics = getExternalRouterMetrics()
+)
+
+func getExternalRouterTimeout() time.Duration {
+	value := utils.LoadEnv("AIBRIX_EXTERNAL_ROUTER_TIMEOUT_MS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_EXTERNAL_ROUTER_TIMEOUT_MS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_EXTERNAL_ROUTER_TIMEOUT_MS env value for external router timeout: %d ms", intValue)
+			return time.Duration(intValue) * time.Millisecond
+		}
+	}
+	return defaultExternalRouterTimeoutMs * time.Millisecond
+}
+
+func getExternalRouterMetrics() []string {
+	value := utils.LoadEnv("AIBRIX_EXTERNAL_ROUTER_METRICS", "")
+	if value == "" {
+		return []string{metrics.NumRequestsRunning, metrics.NumRequestsWaiting, metrics.GPUCacheUsagePerc}
+	}
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// externalRouter delegates pod selection to an external gRPC service implementing routerapi. The fallback router
+// is used whenever the external router fails, times out or picks a pod that is not a ready candidate.
+type externalRouter struct {
+	client   routerapi.ExternalRouterClient
+	cache    cache.Interface
+	fallback Router
+	timeout  time.Duration
+	metrics  []string
+}
+
+func NewExternalRouter(addr string, fallback Router) (Router, error) {
+	if fallback == nil {
+		return nil, fmt.Errorf("external router requires a fallback router")
+	}
+	c, err := cache.GetCache()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create external router client for %s: %v", addr, err)
+	}
+	klog.InfoS("external router initialized", "address", addr, "timeout", externalRouterTimeout, "metrics", externalRouterMetrics)
+
+	return externalRouter{
+		client:   routerapi.NewExternalRouterClient(conn),
+		cache:    c,
+		fallback: fallback,
+		timeout:  externalRouterTimeout,
+		metrics:  externalRouterMetrics,
+	}, nil
+}
+
+func (r externalRouter) Route(ctx context.Context, pods map[string]*v1.Pod, model, message string) (string, error) {
+	readyPods := utils.FilterReadyPods(pods)
+	if len(readyPods) == 0 {
+		return "", fmt.Errorf("no ready pods available for fallback")
+	}
+
+	targetPod, err := r.routeExternal(ctx, pods, model, message)
+	if err != nil {
+		klog.Warningf("external router failed, using fallback router: %v", err)
+		return r.fallback.Route(ctx, pods, model, message)
+	}
+	return getPodAddress(targetPod.Status.PodIP)
+}
+
+func (r externalRouter) routeExternal(ctx context.Context, pods map[string]*v1.Pod, model, message string) (*v1.Pod, error) {
+	req := &routerapi.RouteRequest{Model: model, Message: message, Pods: make([]*routerapi.Pod, 0, len(pods))}
+	for _, pod := range pods {
+		req.Pods = append(req.Pods, &routerapi.Pod{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Ip:        pod.Status.PodIP,
+			Ready:     utils.IsPodReady(pod),
+			Metrics:   r.podMetrics(pod.Name, model),
+		})
+	}
+
+	timeout := r.timeout
+	if configured := config.Gateway().ExternalRouterTimeout; configured != nil {
+		timeout = configured.Duration
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := r.client.Route(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	pod, ok := pods[resp.Pod]
+	if !ok || !utils.IsPodReady(pod) || pod.Status.PodIP == "" {
+		return nil, fmt.Errorf("selected pod %q is not a ready candidate", resp.Pod)
+	}
+	for _, score := range resp.Scores {
+		recordCandidateScore(ctx, score.Pod, score.Score)
+	}
+	return pod, nil
+}
+
+// podMetrics returns the configured metrics of the pod, model metrics take precedence over pod metrics.
+func (r externalRouter) podMetrics(podName, model string) map[string]float64 {
+	if r.cache == nil {
+		return nil
+	}
+	values := map[string]float64{}
+	for _, name := range r.metrics {
+		metricVal, err := r.cache.GetPodModelMetric(podName, model, name)
+		if err != nil {
+			metricVal, err = r.cache.GetPodMetric(podName, name)
+			if err != nil {
+				continue
+			}
+		}
+		if histogram := metricVal.GetHistogramValue(); histogram != nil {
+			values[name] = histogram.GetMean()
+		} else {
+			values[name] = metricVal.GetSimpleValue()
+		}
+	}
+	return values
+}
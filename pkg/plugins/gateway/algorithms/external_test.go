@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/routerapi"
+)
+
+type fakeExternalRouter struct {
+	route func(req *routerapi.RouteRequest) (*routerapi.RouteResponse, error)
+}
+
+func (f *fakeExternalRouter) Route(ctx context.Context, req *routerapi.RouteRequest) (*routerapi.RouteResponse, error) {
+	return f.route(req)
+}
+
+type staticRouter string
+
+func (r staticRouter) Route(ctx context.Context, pods map[string]*v1.Pod, model, message string) (string, error) {
+	return string(r), nil
+}
+
+func newExternalTestRouter(t *testing.T, fake *fakeExternalRouter) externalRouter {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	routerapi.RegisterExternalRouterServer(s, fake)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	c := cache.New()
+	c.SetPodMetric("p1", metrics.NumRequestsRunning, &metrics.SimpleMetricValue{Value: 3})
+	return externalRouter{
+		client:   routerapi.NewExternalRouterClient(conn),
+		cache:    c,
+		fallback: staticRouter("fallback"),
+		timeout:  time.Second,
+		metrics:  []string{metrics.NumRequestsRunning},
+	}
+}
+
+func newExternalTestPod(name, ip string, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status: v1.PodStatus{
+			PodIP:      ip,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestExternalRouter(t *testing.T) {
+	pods := map[string]*v1.Pod{
+		"p1": newExternalTestPod("p1", "10.0.0.1", true),
+		"p2": newExternalTestPod("p2", "10.0.0.2", false),
+	}
+
+	var received *routerapi.RouteRequest
+	r := newExternalTestRouter(t, &fakeExternalRouter{route: func(req *routerapi.RouteRequest) (*routerapi.RouteResponse, error) {
+		received = req
+		return &routerapi.RouteResponse{Pod: "p1", Scores: []*routerapi.CandidateScore{{Pod: "p1", Score: 0.9}}}, nil
+	}})
+
+	ctx, recorder := WithScoreRecorder(context.Background())
+	target, err := r.Route(ctx, pods, "llama", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:8000", target)
+	assert.Equal(t, []CandidateScore{{Pod: "p1", Score: 0.9}}, recorder.Scores())
+
+	require.NotNil(t, received)
+	assert.Equal(t, "llama", received.Model)
+	assert.Equal(t, "hello", received.Message)
+	assert.Len(t, received.Pods, 2)
+	for _, pod := range received.Pods {
+		if pod.Name == "p1" {
+			assert.True(t, pod.Ready)
+			assert.Equal(t, map[string]float64{metrics.NumRequestsRunning: 3}, pod.Metrics)
+		} else {
+			assert.False(t, pod.Ready)
+		}
+	}
+}
+
+func TestExternalRouterFallback(t *testing.T) {
+	pods := map[string]*v1.Pod{
+		"p1": newExternalTestPod("p1", "10.0.0.1", true),
+		"p2": newExternalTestPod("p2", "10.0.0.2", false),
+	}
+
+	tests := []struct {
+		name  string
+		route func(req *routerapi.RouteRequest) (*routerapi.RouteResponse, error)
+	}{
+		{"error", func(req *routerapi.RouteRequest) (*routerapi.RouteResponse, error) {
+			return nil, errors.New("boom")
+		}},
+		{"unknown pod", func(req *routerapi.RouteRequest) (*routerapi.RouteResponse, error) {
+			return &routerapi.RouteResponse{Pod: "p3"}, nil
+		}},
+		{"not ready pod", func(req *routerapi.RouteRequest) (*routerapi.RouteResponse, error) {
+			return &routerapi.RouteResponse{Pod: "p2"}, nil
+		}},
+		{"timeout", func(req *routerapi.RouteRequest) (*routerapi.RouteResponse, error) {
+			time.Sleep(200 * time.Millisecond)
+			return &routerapi.RouteResponse{Pod: "p1"}, nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newExternalTestRouter(t, &fakeExternalRouter{route: tt.route})
+			r.timeout = 50 * time.Millisecond
+			target, err := r.Route(context.Background(), pods, "llama", "hello")
+			assert.NoError(t, err)
+			assert.Equal(t, "fallback", target)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"sync"
+)
+
+type feedbackKey struct{}
+
+// Outcome is what the gateway learned about a routed request once it ended.
+type Outcome struct {
+	// Success is true if the engine completed the response.
+	Success bool
+	// PromptTokens and CachedTokens are the prompt tokens reported by the engine and those served from its
+	// prefix cache, CachedTokens is -1 if the engine did not report it.
+	PromptTokens int64
+	CachedTokens int64
+}
+
+// Feedback delivers the outcome of a request to the routers that routed it.
+type Feedback struct {
+	mu        sync.Mutex
+	outcome   Outcome
+	callbacks []func(Outcome)
+	reported  bool
+}
+
+// WithFeedback returns a context carrying a feedback that routers subscribe to.
+func WithFeedback(ctx context.Context) (context.Context, *Feedback) {
+	feedback := &Feedback{outcome: Outcome{CachedTokens: -1}}
+	return context.WithValue(ctx, feedbackKey{}, feedback), feedback
+}
+
+// RecordUsage records the prompt token usage reported by the engine if the context carries a feedback.
+func RecordUsage(ctx context.Context, promptTokens, cachedTokens int64) {
+	feedback, ok := ctx.Value(feedbackKey{}).(*Feedback)
+	if !ok {
+		return
+	}
+	feedback.mu.Lock()
+	feedback.outcome.PromptTokens = promptTokens
+	feedback.outcome.CachedTokens = cachedTokens
+	feedback.mu.Unlock()
+}
+
+// Report runs the subscribed callbacks with the outcome of the request, only the first call has an effect.
+func (f *Feedback) Report(success bool) {
+	f.mu.Lock()
+	if f.reported {
+		f.mu.Unlock()
+		return
+	}
+	f.reported = true
+	f.outcome.Success = success
+	outcome, callbacks := f.outcome, f.callbacks
+	f.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(outcome)
+	}
+}
+
+// onOutcome subscribes the callback to the outcome of the request if the context carries a feedback.
+func onOutcome(ctx context.Context, callback func(Outcome)) {
+	feedback, ok := ctx.Value(feedbackKey{}).(*Feedback)
+	if !ok {
+		return
+	}
+	feedback.mu.Lock()
+	feedback.callbacks = append(feedback.callbacks, callback)
+	feedback.mu.Unlock()
+}
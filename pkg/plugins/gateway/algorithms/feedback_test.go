@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePrefixFeedback struct {
+	confirmed, rejected, removed []int
+}
+
+func (f *fakePrefixFeedback) ConfirmPrefix(tokens []int, model, pod string) {
+	f.confirmed = append(f.confirmed, tokens...)
+}
+
+func (f *fakePrefixFeedback) RejectPrefix(tokens []int, model, pod string) {
+	f.rejected = append(f.rejected, tokens...)
+}
+
+func (f *fakePrefixFeedback) RemovePrefix(tokens []int, model, pod string) {
+	f.removed = append(f.removed, tokens...)
+}
+
+func TestFeedback(t *testing.T) {
+	ctx, feedback := WithFeedback(context.Background())
+	var outcomes []Outcome
+	onOutcome(ctx, func(outcome Outcome) { outcomes = append(outcomes, outcome) })
+
+	RecordUsage(ctx, 100, 40)
+	feedback.Report(true)
+	feedback.Report(false)
+	assert.Equal(t, []Outcome{{Success: true, PromptTokens: 100, CachedTokens: 40}}, outcomes)
+
+	// no feedback in context is a no-op
+	onOutcome(context.Background(), func(Outcome) { t.Fatal("unexpected callback") })
+	RecordUsage(context.Background(), 1, 1)
+}
+
+func TestApplyPrefixOutcome(t *testing.T) {
+	tokens := make([]int, 64)
+	for i := range tokens {
+		tokens[i] = i
+	}
+
+	f := &fakePrefixFeedback{}
+	applyPrefixOutcome(f, Outcome{Success: false, CachedTokens: -1}, tokens, 32, true, "m", "p")
+	assert.Equal(t, tokens[32:], f.rejected)
+	assert.Empty(t, f.confirmed)
+
+	f = &fakePrefixFeedback{}
+	applyPrefixOutcome(f, Outcome{Success: true, CachedTokens: -1}, tokens, 32, true, "m", "p")
+	assert.Equal(t, tokens[32:], f.confirmed)
+
+	// engine reports 20 of 128 prompt tokens cached, 10 gateway tokens round up to the first block.
+	f = &fakePrefixFeedback{}
+	applyPrefixOutcome(f, Outcome{Success: true, PromptTokens: 128, CachedTokens: 20}, tokens, 48, true, "m", "p")
+	assert.Equal(t, append(append([]int{}, tokens[48:]...), tokens[:16]...), f.confirmed)
+	assert.Equal(t, tokens[16:48], f.removed)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// hashLoadFactor bounds the in-flight requests of a pod selected by hash to 1.25 times the mean of the pods.
+const hashLoadFactor = 1.25
+
+type podHashRing struct {
+	pods string
+	ring *utils.HashRing
+}
+
+// podHashRings holds the hash ring of the ready pods of each model, rebuilt when the pods change.
+type podHashRings struct {
+	mu    sync.Mutex
+	rings map[string]podHashRing
+}
+
+func newPodHashRings() *podHashRings {
+	return &podHashRings{rings: map[string]podHashRing{}}
+}
+
+func (r *podHashRings) get(model string, pods []*v1.Pod) *utils.HashRing {
+	names := make([]string, len(pods))
+	for i, pod := range pods {
+		names[i] = pod.Name
+	}
+	sort.Strings(names)
+	key := strings.Join(names, ",")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ring, ok := r.rings[model]; ok && ring.pods == key {
+		return ring.ring
+	}
+	weights := make(map[string]int, len(names))
+	for _, name := range names {
+		weights[name] = 1
+	}
+	ring := utils.NewHashRing(weights, utils.DefaultVirtualNodes)
+	r.rings[model] = podHashRing{pods: key, ring: ring}
+	return ring
+}
+
+// selectHashedPod returns the pod of the key on the hash ring of the pods, passing over the pods with more in-flight
+// requests than their share. Without the cache the pods are selected by hash alone.
+func (r *podHashRings) selectHashedPod(c cache.Interface, model, key string, pods []*v1.Pod) *v1.Pod {
+	load := func(string) float64 { return 0 }
+	if c != nil {
+		load = func(pod string) float64 {
+			return float64(c.GetPodInflightBatchItems(pod) + c.GetPodRemoteInflightBatchItems(pod))
+		}
+	}
+	name := r.get(model, pods).GetBounded(key, load, hashLoadFactor)
+	for _, pod := range pods {
+		if pod.Name == name {
+			return pod
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodHashRings(t *testing.T) {
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	p1, p2, p3 := newPod("p1"), newPod("p2"), newPod("p3")
+	rings := newPodHashRings()
+
+	// the ring is kept while the pods are the same, in any order.
+	ring := rings.get("llama", []*v1.Pod{p1, p2})
+	assert.Same(t, ring, rings.get("llama", []*v1.Pod{p2, p1}))
+	assert.NotSame(t, ring, rings.get("llama", []*v1.Pod{p1, p2, p3}))
+	assert.Equal(t, 3, rings.get("llama", []*v1.Pod{p1, p2, p3}).Len())
+
+	target := rings.selectHashedPod(nil, "llama", "prompt", []*v1.Pod{p1, p2, p3})
+	assert.NotNil(t, target)
+	for i := 0; i < 10; i++ {
+		assert.Same(t, target, rings.selectHashedPod(nil, "llama", "prompt", []*v1.Pod{p3, p2, p1}))
+	}
+}
@@ -28,7 +28,7 @@ import (
 )
 
 type leastBusyTimeRouter struct {
-	cache *cache.Cache
+	cache cache.Interface
 }
 
 func NewLeastBusyTimeRouter() (Router, error) {
@@ -63,6 +63,8 @@ func (r leastBusyTimeRouter) Route(ctx context.Context, pods map[string]*v1.Pod,
 		busyTimeRatioValue := busyTimeRatio.GetSimpleValue()
 		klog.V(4).Infof("pod: %v, podIP: %v, GPU busy time ratio: %v", pod.Name, pod.Status.PodIP, busyTimeRatioValue)
 
+		recordCandidateScore(ctx, pod.Name, busyTimeRatioValue)
+
 		if busyTimeRatioValue < minBusyTimeRatio {
 			minBusyTimeRatio = busyTimeRatioValue
 			targetPodIP = pod.Status.PodIP
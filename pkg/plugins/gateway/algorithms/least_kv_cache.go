@@ -29,7 +29,7 @@ import (
 )
 
 type leastKvCacheRouter struct {
-	cache *cache.Cache
+	cache cache.Interface
 }
 
 func NewLeastKvCacheRouter() (Router, error) {
@@ -74,6 +74,8 @@ func (r leastKvCacheRouter) Route(ctx context.Context, pods map[string]*v1.Pod,
 		klog.V(4).Infof("pod: %v, podIP: %v, gpuCache: %v, cpuCache: %v, kaCache: %v",
 			pod.Name, pod.Status.PodIP, gpuCache.GetSimpleValue(), cpuCache.GetSimpleValue(), totalCache)
 
+		recordCandidateScore(ctx, pod.Name, totalCache)
+
 		if totalCache <= minKvCache {
 			minKvCache = totalCache
 			targetPodIP = pod.Status.PodIP
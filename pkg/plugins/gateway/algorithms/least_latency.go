@@ -29,7 +29,7 @@ import (
 )
 
 type leastExpectedLatencyRouter struct {
-	cache *cache.Cache
+	cache cache.Interface
 }
 
 func NewLeastExpectedLatencyRouter() (Router, error) {
@@ -126,6 +126,8 @@ func (r leastExpectedLatencyRouter) Route(ctx context.Context, pods map[string]*
 		klog.V(4).Infof("pod: %v, podIP: %v, queuingLatency: %v, prefillLatency: %v, decodeLatency: %v, totalExpectedLatency: %v",
 			pod.Name, pod.Status.PodIP, queuingLatency.GetSimpleValue(), prefillLatency, decodeLatency, totalExpectedLatency)
 
+		recordCandidateScore(ctx, pod.Name, totalExpectedLatency)
+
 		if totalExpectedLatency <= minExpectedLatency {
 			minExpectedLatency = totalExpectedLatency
 			targetPodIP = pod.Status.PodIP
@@ -30,7 +30,7 @@ import (
 )
 
 type leastRequestRouter struct {
-	cache *cache.Cache
+	cache cache.Interface
 }
 
 func NewLeastRequestRouter() (Router, error) {
@@ -75,9 +75,15 @@ func (r leastRequestRouter) Route(ctx context.Context, pods map[string]*v1.Pod,
 		}
 
 		totalReq := runningReq.GetSimpleValue() + waitingReq.GetSimpleValue() + swappedReq.GetSimpleValue()
+		// the engine reports the requests routed since its last scrape late, the inputs of batch requests such as
+		// embeddings are requests to the engine. The other gateway replicas report the requests they routed.
+		inflight := r.cache.GetPodInflightBatchItems(pod.Name) + r.cache.GetPodRemoteInflightBatchItems(pod.Name)
+		totalReq = math.Max(totalReq, float64(inflight))
 		klog.V(4).Infof("pod: %v, podIP: %v, runningReq: %v, waitingReq: %v, swappedReq: %v, totalReq: %v",
 			pod.Name, pod.Status.PodIP, runningReq, waitingReq, swappedReq, totalReq)
 
+		recordCandidateScore(ctx, pod.Name, totalReq)
+
 		if totalReq <= minCount {
 			minCount = totalReq
 			targetPodIP = pod.Status.PodIP
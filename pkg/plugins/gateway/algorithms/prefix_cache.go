@@ -20,8 +20,12 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"slices"
 	"strconv"
+	"sync"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vllm-project/aibrix/pkg/cache"
 	"github.com/vllm-project/aibrix/pkg/plugins/gateway/prefixcacheindexer"
 	"github.com/vllm-project/aibrix/pkg/utils"
 	v1 "k8s.io/api/core/v1"
@@ -34,8 +38,83 @@ const (
 
 var (
 	prefixCacheMatchThresholdPercent = getPrefixCacheMatchThresholdPercent()
+
+	prefixCacheBlocksDesc = prometheus.NewDesc(
+		"aibrix_gateway_prefix_cache_blocks",
+		"Prefix blocks indexed by the prefix cache router.",
+		nil, nil,
+	)
+	prefixCacheSpeculativeDesc = prometheus.NewDesc(
+		"aibrix_gateway_prefix_cache_speculative_placements",
+		"Prefix placements recorded at routing time and not confirmed by the engine yet.",
+		nil, nil,
+	)
+	prefixCacheLookupsDesc = prometheus.NewDesc(
+		"aibrix_gateway_prefix_cache_lookups_total",
+		"Prefix cache lookups of the prefix cache router.",
+		nil, nil,
+	)
+	prefixCacheHitsDesc = prometheus.NewDesc(
+		"aibrix_gateway_prefix_cache_hits_total",
+		"Prefix cache lookups matching at least a block.",
+		nil, nil,
+	)
+	prefixCacheHitRatioDesc = prometheus.NewDesc(
+		"aibrix_gateway_prefix_cache_hit_ratio",
+		"Ratio of the prefix cache lookups matching at least a block.",
+		nil, nil,
+	)
+
+	prefixCacheStats = &prefixCacheCollector{}
 )
 
+func init() {
+	prometheus.MustRegister(prefixCacheStats)
+}
+
+// prefixCacheCollector exports the statistics of the indexer of the prefix cache router at scrape time.
+type prefixCacheCollector struct {
+	mu       sync.Mutex
+	provider prefixcacheindexer.StatsProvider
+}
+
+var _ prometheus.Collector = (*prefixCacheCollector)(nil)
+
+// setProvider replaces the indexer whose statistics are exported, the gateway creates a single prefix cache router.
+func (p *prefixCacheCollector) setProvider(provider prefixcacheindexer.StatsProvider) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.provider = provider
+}
+
+func (p *prefixCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- prefixCacheBlocksDesc
+	ch <- prefixCacheSpeculativeDesc
+	ch <- prefixCacheLookupsDesc
+	ch <- prefixCacheHitsDesc
+	ch <- prefixCacheHitRatioDesc
+}
+
+func (p *prefixCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	p.mu.Lock()
+	provider := p.provider
+	p.mu.Unlock()
+	if provider == nil {
+		return
+	}
+
+	stats := provider.Stats()
+	var hitRatio float64
+	if stats.Lookups > 0 {
+		hitRatio = float64(stats.Hits) / float64(stats.Lookups)
+	}
+	ch <- prometheus.MustNewConstMetric(prefixCacheBlocksDesc, prometheus.GaugeValue, float64(stats.Blocks))
+	ch <- prometheus.MustNewConstMetric(prefixCacheSpeculativeDesc, prometheus.GaugeValue, float64(stats.Speculative))
+	ch <- prometheus.MustNewConstMetric(prefixCacheLookupsDesc, prometheus.CounterValue, float64(stats.Lookups))
+	ch <- prometheus.MustNewConstMetric(prefixCacheHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(prefixCacheHitRatioDesc, prometheus.GaugeValue, hitRatio)
+}
+
 func getPrefixCacheMatchThresholdPercent() int {
 	value := utils.LoadEnv("AIBRIX_PREFIX_CACHE_MATCH_THRESHOLD_PERCENT", "")
 	if value != "" {
@@ -53,12 +132,51 @@ func getPrefixCacheMatchThresholdPercent() int {
 
 type prefixCacheRouter struct {
 	prefixCacheIndexer prefixcacheindexer.PrefixCacheIndexer
+	cache              cache.Interface
+	rings              *podHashRings
+	sessions           *prefixSessions
 }
 
 func NewPrefixCacheRouter() (Router, error) {
+	// c is left a nil cache.Interface without a cache for the nil checks of the sessions and the hash rings, a nil
+	// *cache.Cache would not compare equal to nil.
+	var c cache.Interface
+	if instance, err := cache.GetCache(); err == nil {
+		c = instance
+	}
+	router := newPrefixCacheRouter(c, prefixcacheindexer.NewPrefixHashTable())
+	if provider, ok := router.prefixCacheIndexer.(prefixcacheindexer.StatsProvider); ok {
+		prefixCacheStats.setProvider(provider)
+	}
+	return router, nil
+}
+
+func newPrefixCacheRouter(c cache.Interface, prefixCacheIndexer prefixcacheindexer.PrefixCacheIndexer) prefixCacheRouter {
+	// report prefix ownership so scale-down prefers pods caching the fewest prefixes, and checkpoint the prefix blocks
+	// along with the cache to warm start after a restart.
+	if c != nil {
+		if provider, ok := prefixCacheIndexer.(cache.PodOwnershipProvider); ok {
+			c.AddOwnershipProvider(provider)
+		}
+		if checkpointer, ok := prefixCacheIndexer.(cache.Checkpointer); ok {
+			c.AddCheckpointer("prefix-cache", checkpointer)
+		}
+	}
 	return prefixCacheRouter{
-		prefixCacheIndexer: prefixcacheindexer.NewPrefixHashTable(),
-	}, nil
+		prefixCacheIndexer: prefixCacheIndexer,
+		cache:              c,
+		rings:              newPodHashRings(),
+		sessions:           newPrefixSessions(c, prefixSessionTTL),
+	}
+}
+
+// PrefixCacheStats returns the statistics of the prefix cache indexer, false if it doesn't expose any.
+func (p prefixCacheRouter) PrefixCacheStats() (prefixcacheindexer.Stats, bool) {
+	provider, ok := p.prefixCacheIndexer.(prefixcacheindexer.StatsProvider)
+	if !ok {
+		return prefixcacheindexer.Stats{}, false
+	}
+	return provider.Stats(), true
 }
 
 func (p prefixCacheRouter) Route(ctx context.Context, pods map[string]*v1.Pod, model, message string) (string, error) {
@@ -72,21 +190,37 @@ func (p prefixCacheRouter) Route(ctx context.Context, pods map[string]*v1.Pod, m
 		}
 	}
 
-	tokens, err := utils.TokenizeInputText(message)
+	tokens, err := PromptTokens(model, message)
 	if err != nil {
 		return "", err
 	}
 
 	var targetPod *v1.Pod
 	matchedTokens, unMatchedTokens, matchedPods := p.prefixCacheIndexer.MatchPrefix(tokens, model, readyPods)
-	if len(matchedTokens)*100/len(tokens) > prefixCacheMatchThresholdPercent {
+	matchPercent := len(matchedTokens) * 100 / len(tokens)
+	for _, pod := range matchedPods {
+		recordCandidateScore(ctx, pod.Name, float64(matchPercent))
+	}
+	if matchPercent > prefixCacheMatchThresholdPercent {
 		targetPod = matchedPods[rand.Intn(len(matchedPods))]
+	} else if pod, matched := p.sessions.lookup(ctx, model, tokens, readyPods); pod != nil && matched > len(matchedTokens) {
+		// the prefix was routed by another replica, or by this one before it restarted.
+		targetPod = pod
 	} else {
-		// TODO: add better load balanced algorithms as fallback
-		targetPod = readyPods[rand.Intn(len(readyPods))]
+		// prompts starting alike go to the same pod until it is indexed, unless the pod is loaded beyond its share.
+		targetPod = p.rings.selectHashedPod(p.cache, model, fmt.Sprint(tokens[:min(len(tokens), prefixcacheindexer.BlockSize())]), readyPods)
 	}
-	if len(unMatchedTokens) > 0 {
-		p.prefixCacheIndexer.AddPrefix(unMatchedTokens, model, targetPod.Name)
+	if !isDryRun(ctx) {
+		if len(unMatchedTokens) > 0 {
+			p.prefixCacheIndexer.AddPrefix(unMatchedTokens, model, targetPod.Name)
+		}
+		p.sessions.record(model, tokens, targetPod.Name)
+	}
+	if feedback, ok := p.prefixCacheIndexer.(prefixcacheindexer.PrefixFeedback); ok {
+		matchedOnTarget := slices.Contains(matchedPods, targetPod)
+		onOutcome(ctx, func(outcome Outcome) {
+			applyPrefixOutcome(feedback, outcome, tokens, len(matchedTokens), matchedOnTarget, model, targetPod.Name)
+		})
 	}
 
 	var matchedPodNames, readyPodNames []string
@@ -107,3 +241,33 @@ func (p prefixCacheRouter) Route(ctx context.Context, pods map[string]*v1.Pod, m
 
 	return getPodAddress(targetPod.Status.PodIP)
 }
+
+// applyPrefixOutcome confirms the blocks added for a completed request and drops them if it failed. When the engine
+// reports its prefix cache hits, matched blocks beyond the cached tokens were evicted from the pod and are removed.
+func applyPrefixOutcome(feedback prefixcacheindexer.PrefixFeedback, outcome Outcome, tokens []int, matched int, matchedOnTarget bool, model, pod string) {
+	unMatchedTokens := tokens[matched:]
+	if !outcome.Success {
+		if len(unMatchedTokens) > 0 {
+			feedback.RejectPrefix(unMatchedTokens, model, pod)
+		}
+		return
+	}
+	if len(unMatchedTokens) > 0 {
+		feedback.ConfirmPrefix(unMatchedTokens, model, pod)
+	}
+	if !matchedOnTarget || outcome.CachedTokens < 0 || outcome.PromptTokens <= 0 {
+		return
+	}
+
+	// engine and gateway tokenizers differ, scale the cached tokens and keep the block they end in.
+	blockSize := prefixcacheindexer.BlockSize()
+	cached := int(int64(len(tokens)) * outcome.CachedTokens / outcome.PromptTokens)
+	cached = (cached + blockSize - 1) / blockSize * blockSize
+	if cached >= matched {
+		feedback.ConfirmPrefix(tokens[:matched], model, pod)
+		return
+	}
+	feedback.ConfirmPrefix(tokens[:cached], model, pod)
+	feedback.RemovePrefix(tokens[cached:matched], model, pod)
+	klog.V(4).InfoS("engine evicted matched prefix blocks", "model", model, "pod", pod, "matchedTokens", matched, "cachedTokens", cached)
+}
@@ -450,6 +450,11 @@ func (p *prefixCacheAndLoadRouter) Route(ctx context.Context, pods map[string]*v
 		}
 	}
 
+	// the prefix tree and the load histogram are updated while routing.
+	if isDryRun(ctx) {
+		return "", ErrDryRunUnsupported
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -458,9 +463,7 @@ func (p *prefixCacheAndLoadRouter) Route(ctx context.Context, pods map[string]*v
 	klog.Infof("current actual ready pods: %d", len(readyPods))
 	p.updatePodSet(readyPods)
 	klog.Infof("num pods in data structure after updatePodSet: %d", p.numPods)
-	trimmedMessage := utils.TrimMessage(message)
-	klog.Infof("Trimmed message: '%s'", trimmedMessage)
-	tokens, err := utils.TokenizeInputText(trimmedMessage)
+	tokens, err := PromptTokens(model, message)
 	if err != nil {
 		return "", err
 	}
@@ -532,8 +535,9 @@ func (p *prefixCacheAndLoadRouter) Route(ctx context.Context, pods map[string]*v
 			}
 			klog.Infof("Selected pod %s from longest matching node with match length %d", targetPod.Name, longestMatch.matchLength)
 		} else {
-			token_in_string, err := utils.DetokenizeText(tokens)
-			matched_tokens_in_string, _ := utils.DetokenizeText(matchedTokens)
+			modelTokenizer := modelTokenizers.ForModel(model)
+			token_in_string, err := modelTokenizer.Decode(tokens)
+			matched_tokens_in_string, _ := modelTokenizer.Decode(matchedTokens)
 			if err != nil {
 				klog.Errorf("DetokenizeTexts failed: %s, tokens: '%v', matchedTokens: '%v', model: %s", err, token_in_string, matched_tokens_in_string, model)
 			} else {
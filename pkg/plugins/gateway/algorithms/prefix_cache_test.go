@@ -0,0 +1,54 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache/fakecache"
+)
+
+func TestPrefixCacheRouterWithFakeCache(t *testing.T) {
+	pods := map[string]*v1.Pod{
+		"p1": newExternalTestPod("p1", "10.0.0.1", true),
+		"p2": newExternalTestPod("p2", "10.0.0.2", true),
+		"p3": newExternalTestPod("p3", "10.0.0.3", true),
+	}
+	message := strings.Repeat("the system prompt shared by every request of the tenant ", 20)
+	tokens, err := PromptTokens("llama", message)
+	require.NoError(t, err)
+
+	c := fakecache.New(
+		fakecache.WithPods("llama", pods["p1"], pods["p2"], pods["p3"]),
+		fakecache.WithPrefixBlocks("llama", "p2", tokens),
+	)
+	router := newPrefixCacheRouter(c, c.PrefixCacheIndexer())
+	assert.Len(t, c.OwnershipProviders(), 1)
+	assert.Contains(t, c.Checkpointers(), "prefix-cache")
+
+	for i := 0; i < 5; i++ {
+		target, err := router.Route(context.Background(), pods, "llama", message)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.2:8000", target, "the prompt is cached on p2")
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/prefixcacheindexer"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	prefixSessionKeyPrefix              = "aibrix:prefix-session:"
+	defaultPrefixSessionTTLInSecs       = 0
+	defaultPrefixSessionLookupTimeoutMs = 20
+	prefixSessionWriteTimeout           = time.Second
+)
+
+var (
+	prefixSessionTTL           = getPrefixSessionTTL()
+	prefixSessionLookupTimeout = getPrefixSessionLookupTimeout()
+
+	prefixSessionLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_prefix_session_lookups_total",
+		Help: "Lookups of the persisted prefix routing decisions, by result: hit, miss or error.",
+	}, []string{"model", "result"})
+)
+
+func init() {
+	prometheus.MustRegister(prefixSessionLookups)
+}
+
+// getPrefixSessionTTL returns how long routing decisions are persisted, 0, the default, disables persisting them.
+func getPrefixSessionTTL() time.Duration {
+	value := utils.LoadEnv("AIBRIX_PREFIX_SESSION_TTL_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_PREFIX_SESSION_TTL_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_PREFIX_SESSION_TTL_S env value for prefix session ttl: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultPrefixSessionTTLInSecs * time.Second
+}
+
+func getPrefixSessionLookupTimeout() time.Duration {
+	value := utils.LoadEnv("AIBRIX_PREFIX_SESSION_LOOKUP_TIMEOUT_MS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_PREFIX_SESSION_LOOKUP_TIMEOUT_MS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_PREFIX_SESSION_LOOKUP_TIMEOUT_MS env value for prefix session lookup timeout: %d ms", intValue)
+			return time.Duration(intValue) * time.Millisecond
+		}
+	}
+	return defaultPrefixSessionLookupTimeoutMs * time.Millisecond
+}
+
+// prefixSessions persists the recent routing decisions of the prefix cache router in the kv store, so that the prefix
+// locality established by a gateway replica survives its restart and is shared with the other replicas. Prompts are
+// recorded at the prefixes of 1, 2, 4, ... blocks, a prompt sharing at least half of its longest prefix with a recorded
+// one is found.
+type prefixSessions struct {
+	store kvstore.Store
+	ttl   time.Duration
+}
+
+// newPrefixSessions returns nil, which persists nothing, if the cache has no kv store or persisting is disabled.
+func newPrefixSessions(c cache.Interface, ttl time.Duration) *prefixSessions {
+	if c == nil || c.KVStore() == nil || ttl <= 0 {
+		return nil
+	}
+	return &prefixSessions{store: c.KVStore(), ttl: ttl}
+}
+
+type prefixCheckpoint struct {
+	key    string
+	tokens int
+}
+
+// prefixCheckpoints returns the keys of the prefixes of 1, 2, 4, ... full blocks of the tokens, the longest last. The
+// hashes are not seeded so that all gateway replicas agree on them.
+func prefixCheckpoints(model string, tokens []int) []prefixCheckpoint {
+	blockSize := prefixcacheindexer.BlockSize()
+	digest := xxhash.New()
+	var checkpoints []prefixCheckpoint
+	next := 1
+	for blocks := 1; blocks*blockSize <= len(tokens); blocks++ {
+		_, _ = digest.Write(prefixcacheindexer.IntArrayToByteArray(tokens[(blocks-1)*blockSize : blocks*blockSize]))
+		if blocks == next {
+			checkpoints = append(checkpoints, prefixCheckpoint{
+				key:    fmt.Sprintf("%s%s:%016x", prefixSessionKeyPrefix, model, digest.Sum64()),
+				tokens: blocks * blockSize,
+			})
+			next *= 2
+		}
+	}
+	return checkpoints
+}
+
+// lookup returns the ready pod the longest recorded prefix of the tokens was routed to and the length of the prefix
+// in tokens, nil if none. The checkpoints are read concurrently within the lookup timeout.
+func (s *prefixSessions) lookup(ctx context.Context, model string, tokens []int, readyPods []*v1.Pod) (*v1.Pod, int) {
+	if s == nil {
+		return nil, 0
+	}
+	checkpoints := prefixCheckpoints(model, tokens)
+	if len(checkpoints) == 0 {
+		return nil, 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, prefixSessionLookupTimeout)
+	defer cancel()
+
+	pods := make([]string, len(checkpoints))
+	errs := make([]error, len(checkpoints))
+	var wg sync.WaitGroup
+	for i, checkpoint := range checkpoints {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			value, err := s.store.Get(ctx, key)
+			pods[i], errs[i] = string(value), err
+		}(i, checkpoint.key)
+	}
+	wg.Wait()
+
+	failed := false
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		if errs[i] != nil {
+			failed = failed || !errors.Is(errs[i], kvstore.ErrNotFound)
+			continue
+		}
+		for _, pod := range readyPods {
+			if pod.Name == pods[i] {
+				prefixSessionLookups.WithLabelValues(model, "hit").Inc()
+				return pod, checkpoints[i].tokens
+			}
+		}
+	}
+	if failed {
+		klog.V(4).InfoS("failed to look up the prefix sessions", "model", model, "errors", errors.Join(errs...))
+		prefixSessionLookups.WithLabelValues(model, "error").Inc()
+	} else {
+		prefixSessionLookups.WithLabelValues(model, "miss").Inc()
+	}
+	return nil, 0
+}
+
+// record persists the routing of the tokens to the pod in the background, refreshing the ttl of the prefixes.
+func (s *prefixSessions) record(model string, tokens []int, pod string) {
+	if s == nil {
+		return
+	}
+	checkpoints := prefixCheckpoints(model, tokens)
+	if len(checkpoints) == 0 {
+		return
+	}
+	values := make(map[string][]byte, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		values[checkpoint.key] = []byte(pod)
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), prefixSessionWriteTimeout)
+		defer cancel()
+		if err := s.store.SetMany(ctx, values, s.ttl); err != nil {
+			klog.V(4).InfoS("failed to record the prefix session", "model", model, "pod", pod, "err", err)
+		}
+	}()
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/prefixcacheindexer"
+)
+
+func sessionTokens(blocks int, offset int) []int {
+	tokens := make([]int, blocks*prefixcacheindexer.BlockSize())
+	for i := range tokens {
+		tokens[i] = i + offset
+	}
+	return tokens
+}
+
+func TestPrefixCheckpoints(t *testing.T) {
+	blockSize := prefixcacheindexer.BlockSize()
+	var lengths []int
+	for _, checkpoint := range prefixCheckpoints("llama", append(sessionTokens(11, 0), 1, 2, 3)) {
+		lengths = append(lengths, checkpoint.tokens)
+	}
+	assert.Equal(t, []int{blockSize, 2 * blockSize, 4 * blockSize, 8 * blockSize}, lengths)
+	assert.Empty(t, prefixCheckpoints("llama", []int{1, 2, 3}))
+
+	// the keys only depend on the model and the tokens.
+	assert.Equal(t, prefixCheckpoints("llama", sessionTokens(4, 0)), prefixCheckpoints("llama", sessionTokens(4, 0)))
+	assert.NotEqual(t, prefixCheckpoints("llama", sessionTokens(4, 0))[0].key, prefixCheckpoints("qwen", sessionTokens(4, 0))[0].key)
+}
+
+func TestPrefixSessions(t *testing.T) {
+	store := kvstore.NewMemoryStore()
+	sessions := &prefixSessions{store: store, ttl: time.Minute}
+	pods := []*v1.Pod{
+		newExternalTestPod("p1", "10.0.0.1", true),
+		newExternalTestPod("p2", "10.0.0.2", true),
+	}
+
+	conversation := sessionTokens(6, 0)
+	sessions.record("llama", conversation, "p2")
+	assert.Eventually(t, func() bool {
+		_, err := store.Get(context.Background(), prefixCheckpoints("llama", conversation)[2].key)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// a later turn of the conversation extends the prompt, it shares the prefix of 4 blocks.
+	pod, matched := sessions.lookup(context.Background(), "llama", append(conversation, sessionTokens(4, 1000)...), pods)
+	assert.Equal(t, pods[1], pod)
+	assert.Equal(t, 4*prefixcacheindexer.BlockSize(), matched)
+
+	pod, _ = sessions.lookup(context.Background(), "llama", sessionTokens(6, 1000), pods)
+	assert.Nil(t, pod)
+	pod, _ = sessions.lookup(context.Background(), "qwen", conversation, pods)
+	assert.Nil(t, pod)
+	// the pod is not ready anymore.
+	pod, _ = sessions.lookup(context.Background(), "llama", conversation, pods[:1])
+	assert.Nil(t, pod)
+
+	// without a kv store nothing is persisted.
+	var disabled *prefixSessions
+	disabled.record("llama", conversation, "p1")
+	pod, _ = disabled.lookup(context.Background(), "llama", conversation, pods)
+	assert.Nil(t, pod)
+	assert.Nil(t, newPrefixSessions(cache.NewOfflineCache(), time.Minute))
+}
+
+func TestPrefixSessionTTL(t *testing.T) {
+	// persisting is opt-in.
+	t.Setenv("AIBRIX_PREFIX_SESSION_TTL_S", "")
+	assert.Equal(t, time.Duration(0), getPrefixSessionTTL())
+	t.Setenv("AIBRIX_PREFIX_SESSION_TTL_S", "300")
+	assert.Equal(t, 5*time.Minute, getPrefixSessionTTL())
+	t.Setenv("AIBRIX_PREFIX_SESSION_TTL_S", "-1")
+	assert.Equal(t, time.Duration(0), getPrefixSessionTTL())
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"sort"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// PrefillOptimizedLabel marks pods tuned for long prompts, e.g. with a large chunked prefill budget, with "true".
+	PrefillOptimizedLabel = "model.aibrix.ai/prefill-optimized"
+
+	defaultLongPrefillTokens = 4096
+	// bytesPerToken estimates the tokens of prompts the tokenizer of the model failed to count.
+	bytesPerToken = 4
+)
+
+var (
+	lengthAwareRoutingEnabled = utils.LoadEnv("AIBRIX_LENGTH_AWARE_ROUTING", "false") == "true"
+	longPrefillTokens         = getLongPrefillTokens()
+)
+
+func getLongPrefillTokens() int {
+	value := utils.LoadEnv("AIBRIX_LONG_PREFILL_TOKENS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_LONG_PREFILL_TOKENS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_LONG_PREFILL_TOKENS env value for long prefill threshold: %d", intValue)
+			return intValue
+		}
+	}
+	return defaultLongPrefillTokens
+}
+
+// RequestLengthAffinity separates long prefills from decodes. The prefill of a long prompt stalls the decode steps
+// batched with it, so requests whose prompt is estimated at the long prefill threshold or more go to the pods labeled
+// prefill optimized, or else to the half of the pods with the least decode work in flight. Shorter requests avoid the
+// prefill optimized pods while other pods are ready.
+type RequestLengthAffinity struct {
+	cache       cache.Interface
+	threshold   int
+	countTokens func(model, message string) (int, error)
+}
+
+// NewRequestLengthAffinity returns the request length affinity enabled by AIBRIX_LENGTH_AWARE_ROUTING, nil if it is
+// disabled.
+func NewRequestLengthAffinity() *RequestLengthAffinity {
+	if !lengthAwareRoutingEnabled {
+		return nil
+	}
+	c, err := cache.GetCache()
+	if err != nil {
+		klog.ErrorS(err, "length aware routing disabled")
+		return nil
+	}
+	klog.InfoS("length aware routing enabled", "longPrefillTokens", longPrefillTokens)
+	return &RequestLengthAffinity{cache: c, threshold: longPrefillTokens, countTokens: CountPromptTokens}
+}
+
+// IsLongPrefill returns true if the estimated prompt tokens of the message reach the long prefill threshold.
+func (a *RequestLengthAffinity) IsLongPrefill(model, message string) bool {
+	tokens, err := a.countTokens(model, message)
+	if err != nil {
+		tokens = len(message) / bytesPerToken
+	}
+	return tokens >= a.threshold
+}
+
+// Filter keeps the pods suited to the length of the request, pods are returned unchanged if the affinity is
+// disabled or no ready pod is suited.
+func (a *RequestLengthAffinity) Filter(pods map[string]*v1.Pod, model, message string) map[string]*v1.Pod {
+	if a == nil {
+		return pods
+	}
+	readyPods := utils.FilterReadyPods(pods)
+	if len(readyPods) < 2 {
+		return pods
+	}
+
+	long := a.IsLongPrefill(model, message)
+	var optimized, general []*v1.Pod
+	for _, pod := range readyPods {
+		if pod.Labels[PrefillOptimizedLabel] == "true" {
+			optimized = append(optimized, pod)
+		} else {
+			general = append(general, pod)
+		}
+	}
+	switch {
+	case long && len(optimized) > 0:
+		klog.V(4).InfoS("routing long prefill to prefill optimized pods", "model", model, "pods", len(optimized))
+		return toPodMap(optimized)
+	case !long && len(optimized) > 0 && len(general) > 0:
+		return toPodMap(general)
+	case !long:
+		return pods
+	}
+
+	// without prefill optimized pods, long prefills go where they stall the least decode work.
+	decodeWork := make(map[string]float64, len(general))
+	for _, pod := range general {
+		decodeWork[pod.Name] = a.decodeWork(pod, model)
+	}
+	sort.SliceStable(general, func(i, j int) bool {
+		return decodeWork[general[i].Name] < decodeWork[general[j].Name]
+	})
+	keep := (len(general) + 1) / 2
+	klog.V(4).InfoS("routing long prefill away from decoding pods", "model", model, "pods", keep, "maxDecodeWork", decodeWork[general[keep-1].Name])
+	return toPodMap(general[:keep])
+}
+
+// decodeWork estimates the seconds of decoding the pod has in flight: its running requests by the mean time its
+// requests spend decoding. Pods without metrics have no decode work.
+func (a *RequestLengthAffinity) decodeWork(pod *v1.Pod, model string) float64 {
+	running, err := a.cache.GetPodModelMetric(pod.Name, model, metrics.NumRequestsRunning)
+	if err != nil {
+		if running, err = a.cache.GetPodMetric(pod.Name, metrics.NumRequestsRunning); err != nil {
+			return 0
+		}
+	}
+	decodeTime := 1.0
+	if value, err := a.cache.GetPodModelMetric(pod.Name, model, metrics.RequestDecodeTimeSeconds); err == nil {
+		if histogram := value.GetHistogramValue(); histogram != nil && histogram.GetCount() > 0 {
+			decodeTime = histogram.GetMean()
+		}
+	}
+	return running.GetSimpleValue() * decodeTime
+}
+
+func toPodMap(pods []*v1.Pod) map[string]*v1.Pod {
+	podMap := make(map[string]*v1.Pod, len(pods))
+	for _, pod := range pods {
+		podMap[pod.Name] = pod
+	}
+	return podMap
+}
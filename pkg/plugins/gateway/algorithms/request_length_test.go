@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+func TestRequestLengthAffinity(t *testing.T) {
+	pods := map[string]*v1.Pod{
+		"p1": newExternalTestPod("p1", "10.0.0.1", true),
+		"p2": newExternalTestPod("p2", "10.0.0.2", true),
+		"p3": newExternalTestPod("p3", "10.0.0.3", true),
+		"p4": newExternalTestPod("p4", "10.0.0.4", false),
+	}
+	decodeTime := func(mean float64) metrics.MetricValue {
+		return &metrics.HistogramMetricValue{Sum: mean * 10, Count: 10}
+	}
+	c := cache.New()
+	for _, pod := range pods {
+		c.SetPod(pod, "m")
+	}
+	setPodMetrics(c, map[string]map[string]metrics.MetricValue{
+		"p1": {metrics.NumRequestsRunning: &metrics.SimpleMetricValue{Value: 2}},
+		"p2": {metrics.NumRequestsRunning: &metrics.SimpleMetricValue{Value: 8}},
+	})
+	setPodModelMetrics(c, map[string]map[string]map[string]metrics.MetricValue{
+		"p1": {"m": {metrics.RequestDecodeTimeSeconds: decodeTime(10)}},
+		"p3": {"m": {metrics.NumRequestsRunning: &metrics.SimpleMetricValue{Value: 4}, metrics.RequestDecodeTimeSeconds: decodeTime(2)}},
+	})
+	a := &RequestLengthAffinity{cache: c, threshold: 100, countTokens: func(model, message string) (int, error) {
+		return len(strings.Fields(message)), nil
+	}}
+	short, long := "hello", strings.Repeat("word ", 100)
+
+	assert.Len(t, a.Filter(pods, "m", short), 4, "short requests go anywhere without prefill optimized pods")
+	// decode work in flight: p1 2x10s, p2 8x1s, p3 4x2s.
+	filtered := a.Filter(pods, "m", long)
+	assert.Len(t, filtered, 2)
+	assert.Contains(t, filtered, "p2")
+	assert.Contains(t, filtered, "p3")
+
+	pods["p3"].Labels = map[string]string{PrefillOptimizedLabel: "true"}
+	assert.Equal(t, []string{"p3"}, keys(a.Filter(pods, "m", long)))
+	assert.NotContains(t, a.Filter(pods, "m", short), "p3")
+
+	// prompts the tokenizer fails on are estimated from their size.
+	a.countTokens = func(model, message string) (int, error) { return 0, errors.New("no tokenizer") }
+	assert.True(t, a.IsLongPrefill("m", strings.Repeat("a", 400)))
+	assert.False(t, a.IsLongPrefill("m", strings.Repeat("a", 399)))
+
+	var disabled *RequestLengthAffinity
+	assert.Len(t, disabled.Filter(pods, "m", long), 4)
+}
+
+func keys(pods map[string]*v1.Pod) []string {
+	var names []string
+	for name := range pods {
+		names = append(names, name)
+	}
+	return names
+}
@@ -18,6 +18,7 @@ package routingalgorithms
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/vllm-project/aibrix/pkg/utils"
@@ -32,6 +33,22 @@ type Router interface {
 	Route(ctx context.Context, pods map[string]*v1.Pod, model, message string) (string, error)
 }
 
+type dryRunKey struct{}
+
+// ErrDryRunUnsupported is returned by routers that cannot select a pod without updating their state.
+var ErrDryRunUnsupported = errors.New("routing strategy does not support dry runs")
+
+// WithDryRun returns a context in which routers select a pod without recording the request, e.g. to explain what they
+// would do with it.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+func isDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
 // selectRandomPodWithRand selects a random pod from the provided pod map.
 // It returns an error if no ready pods are available.
 func selectRandomPod(pods map[string]*v1.Pod, randomFn func(int) int) (string, error) {
@@ -19,72 +19,76 @@ package routingalgorithms
 import (
 	"context"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vllm-project/aibrix/pkg/cache"
 	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/prefixcacheindexer"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestNoPods(t *testing.T) {
-	c := cache.Cache{}
+	c := cache.New()
 	r1 := randomRouter{}
 	model := ""
-	targetPodIP, err := r1.Route(context.TODO(), c.Pods, model, "")
+	targetPodIP, err := r1.Route(context.TODO(), c.GetPods(), model, "")
 	assert.Empty(t, targetPodIP, "targetPodIP must be empty")
 	assert.Error(t, err, "no pod has IP")
 
 	r2 := leastRequestRouter{
-		cache: &c,
+		cache: c,
 	}
-	targetPodIP, err = r2.Route(context.TODO(), c.Pods, model, "")
+	targetPodIP, err = r2.Route(context.TODO(), c.GetPods(), model, "")
 	assert.Empty(t, targetPodIP, "targetPodIP must be empty")
 	assert.Error(t, err, "no pod has IP")
 
 	r3 := throughputRouter{
-		cache: &c,
+		cache: c,
 	}
-	targetPodIP, err = r3.Route(context.TODO(), c.Pods, model, "")
+	targetPodIP, err = r3.Route(context.TODO(), c.GetPods(), model, "")
 	assert.Empty(t, targetPodIP, "targetPodIP must be empty")
 	assert.Error(t, err, "no pod has IP")
 }
 
 func TestWithNoIPPods(t *testing.T) {
-	c := cache.Cache{
-		Pods: map[string]*v1.Pod{
-			"p1": {
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "p1",
-				},
+	pods := map[string]*v1.Pod{
+		"p1": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "p1",
 			},
-			"p2": {
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "p2",
-				},
+		},
+		"p2": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "p2",
 			},
 		},
 	}
+	c := cache.New()
+	for _, pod := range pods {
+		c.SetPod(pod, "")
+	}
 	model := ""
 
 	r1 := randomRouter{}
-	targetPodIP, err := r1.Route(context.TODO(), c.Pods, model, "")
+	targetPodIP, err := r1.Route(context.TODO(), pods, model, "")
 	assert.Empty(t, targetPodIP, "targetPodIP must be empty")
 	assert.Error(t, err, "no pod has IP")
 
 	r2 := leastRequestRouter{
-		cache: &c,
+		cache: c,
 	}
-	targetPodIP, err = r2.Route(context.TODO(), c.Pods, model, "")
+	targetPodIP, err = r2.Route(context.TODO(), pods, model, "")
 	assert.Empty(t, targetPodIP, "targetPodIP must be empty")
 	assert.Error(t, err, "no pod has IP")
 
 	r3 := throughputRouter{
-		cache: &c,
+		cache: c,
 	}
-	targetPodIP, err = r3.Route(context.TODO(), c.Pods, model, "")
+	targetPodIP, err = r3.Route(context.TODO(), pods, model, "")
 	assert.Empty(t, targetPodIP, "targetPodIP must be empty")
 	assert.Error(t, err, "no pod has IP")
 }
@@ -93,73 +97,75 @@ func TestWithIPPods(t *testing.T) {
 	// two case:
 	// case 1: pod ready
 	// case 2: pod ready & terminating -> we can send request at this moment.
-	c := cache.Cache{
-		Pods: map[string]*v1.Pod{
-			"p1": {
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "p1",
-				},
-				Status: v1.PodStatus{
-					PodIP: "0.0.0.0",
-					Conditions: []v1.PodCondition{
-						{
-							Type:   v1.PodReady,
-							Status: v1.ConditionTrue,
-						},
-					},
-				},
+	pods := map[string]*v1.Pod{
+		"p1": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "p1",
 			},
-			"p2": {
-				ObjectMeta: metav1.ObjectMeta{
-					Name:              "p2",
-					DeletionTimestamp: &metav1.Time{Time: time.Now()},
-				},
-				Status: v1.PodStatus{
-					PodIP: "1.0.0.0",
-					Conditions: []v1.PodCondition{
-						{
-							Type:   v1.PodReady,
-							Status: v1.ConditionTrue,
-						},
+			Status: v1.PodStatus{
+				PodIP: "0.0.0.0",
+				Conditions: []v1.PodCondition{
+					{
+						Type:   v1.PodReady,
+						Status: v1.ConditionTrue,
 					},
 				},
 			},
 		},
-		PodMetrics: map[string]map[string]metrics.MetricValue{
-			"p1": {
-				metrics.NumRequestsRunning:              &metrics.SimpleMetricValue{Value: 5},
-				metrics.NumRequestsWaiting:              &metrics.SimpleMetricValue{Value: 5},
-				metrics.NumRequestsSwapped:              &metrics.SimpleMetricValue{Value: 5},
-				metrics.AvgPromptThroughputToksPerS:     &metrics.SimpleMetricValue{Value: 20},
-				metrics.AvgGenerationThroughputToksPerS: &metrics.SimpleMetricValue{Value: 20},
+		"p2": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "p2",
+				DeletionTimestamp: &metav1.Time{Time: time.Now()},
 			},
-			"p2": {
-				metrics.NumRequestsRunning:              &metrics.SimpleMetricValue{Value: 15},
-				metrics.NumRequestsWaiting:              &metrics.SimpleMetricValue{Value: 15},
-				metrics.NumRequestsSwapped:              &metrics.SimpleMetricValue{Value: 15},
-				metrics.AvgPromptThroughputToksPerS:     &metrics.SimpleMetricValue{Value: 15},
-				metrics.AvgGenerationThroughputToksPerS: &metrics.SimpleMetricValue{Value: 2},
+			Status: v1.PodStatus{
+				PodIP: "1.0.0.0",
+				Conditions: []v1.PodCondition{
+					{
+						Type:   v1.PodReady,
+						Status: v1.ConditionTrue,
+					},
+				},
 			},
 		},
 	}
+	c := cache.New()
+	for _, pod := range pods {
+		c.SetPod(pod, "")
+	}
+	setPodMetrics(c, map[string]map[string]metrics.MetricValue{
+		"p1": {
+			metrics.NumRequestsRunning:              &metrics.SimpleMetricValue{Value: 5},
+			metrics.NumRequestsWaiting:              &metrics.SimpleMetricValue{Value: 5},
+			metrics.NumRequestsSwapped:              &metrics.SimpleMetricValue{Value: 5},
+			metrics.AvgPromptThroughputToksPerS:     &metrics.SimpleMetricValue{Value: 20},
+			metrics.AvgGenerationThroughputToksPerS: &metrics.SimpleMetricValue{Value: 20},
+		},
+		"p2": {
+			metrics.NumRequestsRunning:              &metrics.SimpleMetricValue{Value: 15},
+			metrics.NumRequestsWaiting:              &metrics.SimpleMetricValue{Value: 15},
+			metrics.NumRequestsSwapped:              &metrics.SimpleMetricValue{Value: 15},
+			metrics.AvgPromptThroughputToksPerS:     &metrics.SimpleMetricValue{Value: 15},
+			metrics.AvgGenerationThroughputToksPerS: &metrics.SimpleMetricValue{Value: 2},
+		},
+	})
 	model := ""
 
 	r1 := randomRouter{}
-	targetPodIP, err := r1.Route(context.TODO(), c.Pods, model, "")
+	targetPodIP, err := r1.Route(context.TODO(), pods, model, "")
 	assert.NotEmpty(t, targetPodIP, "targetPodIP is not empty")
 	assert.NoError(t, err)
 
 	r2 := leastRequestRouter{
-		cache: &c,
+		cache: c,
 	}
-	targetPodIP, err = r2.Route(context.TODO(), c.Pods, model, "")
+	targetPodIP, err = r2.Route(context.TODO(), pods, model, "")
 	assert.NotEmpty(t, targetPodIP, "targetPodIP is not empty")
 	assert.NoError(t, err)
 
 	r3 := throughputRouter{
-		cache: &c,
+		cache: c,
 	}
-	targetPodIP, err = r3.Route(context.TODO(), c.Pods, model, "")
+	targetPodIP, err = r3.Route(context.TODO(), pods, model, "")
 	assert.NotEmpty(t, targetPodIP, "targetPodIP is not empty")
 	assert.NoError(t, err)
 }
@@ -272,3 +278,57 @@ func TestSelectRandomPod(t *testing.T) {
 		})
 	}
 }
+
+func TestDryRun(t *testing.T) {
+	readyPod := func(name, ip string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.PodStatus{
+				PodIP:      ip,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			},
+		}
+	}
+	pods := map[string]*v1.Pod{"p1": readyPod("p1", "10.0.0.1"), "p2": readyPod("p2", "10.0.0.2")}
+	message := strings.Repeat("the same long prompt is sent again and again ", 50)
+
+	// a dry run selects a pod without indexing the prompt on it.
+	indexer := prefixcacheindexer.NewPrefixHashTable()
+	router := prefixCacheRouter{prefixCacheIndexer: indexer, rings: newPodHashRings()}
+	target, err := router.Route(WithDryRun(context.Background()), pods, "llama", message)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, target)
+	stats, _ := router.PrefixCacheStats()
+	assert.Zero(t, stats.Blocks)
+
+	_, err = router.Route(context.Background(), pods, "llama", message)
+	assert.NoError(t, err)
+	stats, _ = router.PrefixCacheStats()
+	assert.NotZero(t, stats.Blocks)
+
+	// routers updating their state while routing refuse dry runs.
+	loadRouter, err := NewPrefixCacheAndLoadRouter()
+	assert.NoError(t, err)
+	_, err = loadRouter.Route(WithDryRun(context.Background()), pods, "llama", message)
+	assert.ErrorIs(t, err, ErrDryRunUnsupported)
+}
+
+// setPodMetrics sets the metrics of the pods in the cache, as if they were scraped.
+func setPodMetrics(c cache.Interface, podMetrics map[string]map[string]metrics.MetricValue) {
+	for podName, values := range podMetrics {
+		for metricName, value := range values {
+			c.SetPodMetric(podName, metricName, value)
+		}
+	}
+}
+
+// setPodModelMetrics sets the metrics of the models on the pods in the cache, as if they were scraped.
+func setPodModelMetrics(c cache.Interface, podModelMetrics map[string]map[string]map[string]metrics.MetricValue) {
+	for podName, models := range podModelMetrics {
+		for modelName, values := range models {
+			for metricName, value := range values {
+				c.SetPodModelMetric(podName, modelName, metricName, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"sync"
+)
+
+type scoreRecorderKey struct{}
+
+// CandidateScore is the score a router assigned to a candidate pod, its meaning depends on the router.
+type CandidateScore struct {
+	Pod   string  `json:"pod"`
+	Score float64 `json:"score"`
+}
+
+// ScoreRecorder collects the candidate scores computed while routing a request.
+type ScoreRecorder struct {
+	mu     sync.Mutex
+	scores []CandidateScore
+}
+
+// WithScoreRecorder returns a context carrying a recorder that routers report candidate scores to.
+func WithScoreRecorder(ctx context.Context) (context.Context, *ScoreRecorder) {
+	recorder := &ScoreRecorder{}
+	return context.WithValue(ctx, scoreRecorderKey{}, recorder), recorder
+}
+
+// Scores returns the recorded candidate scores.
+func (r *ScoreRecorder) Scores() []CandidateScore {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]CandidateScore(nil), r.scores...)
+}
+
+// recordCandidateScore records the score of a candidate pod if the context carries a recorder.
+func recordCandidateScore(ctx context.Context, podName string, score float64) {
+	recorder, ok := ctx.Value(scoreRecorderKey{}).(*ScoreRecorder)
+	if !ok {
+		return
+	}
+	recorder.mu.Lock()
+	recorder.scores = append(recorder.scores, CandidateScore{Pod: podName, Score: score})
+	recorder.mu.Unlock()
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreRecorder(t *testing.T) {
+	// no recorder in context
+	recordCandidateScore(context.Background(), "p1", 1)
+
+	ctx, recorder := WithScoreRecorder(context.Background())
+	recordCandidateScore(ctx, "p1", 1)
+	recordCandidateScore(ctx, "p2", 2.5)
+	assert.Equal(t, []CandidateScore{{Pod: "p1", Score: 1}, {Pod: "p2", Score: 2.5}}, recorder.Scores())
+}
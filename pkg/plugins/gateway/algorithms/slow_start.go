@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultSlowStartMinWeightPercent = 10
+	warmupRequestTimeout             = 60 * time.Second
+	warmupMaxTokens                  = 1
+)
+
+var (
+	slowStartWindow        = getSlowStartWindow()
+	slowStartMinWeight     = getSlowStartMinWeight()
+	slowStartWarmupEnabled = utils.LoadEnv("AIBRIX_SLOW_START_WARMUP", "false") == "true"
+)
+
+func getSlowStartWindow() time.Duration {
+	value := utils.LoadEnv("AIBRIX_SLOW_START_WINDOW_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_SLOW_START_WINDOW_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_SLOW_START_WINDOW_S env value for slow start window: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return 0
+}
+
+func getSlowStartMinWeight() float64 {
+	value := utils.LoadEnv("AIBRIX_SLOW_START_MIN_WEIGHT_PERCENT", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 || intValue > 100 {
+			klog.Infof("invalid AIBRIX_SLOW_START_MIN_WEIGHT_PERCENT: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_SLOW_START_MIN_WEIGHT_PERCENT env value for slow start min weight: %d", intValue)
+			return float64(intValue) / 100
+		}
+	}
+	return defaultSlowStartMinWeightPercent / 100.0
+}
+
+// SlowStart ramps up the traffic of newly ready pods. During the window after a pod becomes ready, it stays a routing
+// candidate with a probability growing linearly from the min weight to 1, so every router sends it a growing share of
+// requests. Optionally a synthetic warm-up request is sent to each new pod the first time it is seen.
+type SlowStart struct {
+	window    time.Duration
+	minWeight float64
+	warmup    bool
+
+	now        func() time.Time
+	randFloat  func() float64
+	sendWarmup func(pod *v1.Pod, model string)
+	warmed     sync.Map // pod_uid: struct{}
+}
+
+// NewSlowStart returns the slow start configured by AIBRIX_SLOW_START_WINDOW_S, nil if it is disabled.
+func NewSlowStart() *SlowStart {
+	if slowStartWindow == 0 {
+		return nil
+	}
+	klog.InfoS("slow start enabled", "window", slowStartWindow, "minWeight", slowStartMinWeight, "warmup", slowStartWarmupEnabled)
+	return &SlowStart{
+		window:     slowStartWindow,
+		minWeight:  slowStartMinWeight,
+		warmup:     slowStartWarmupEnabled,
+		now:        time.Now,
+		randFloat:  rand.Float64,
+		sendWarmup: sendWarmupRequest,
+	}
+}
+
+// Filter drops warming pods from the candidates according to their weight. Pods are returned unchanged if
+// slow start is disabled, or if no ready pod is out of its window since there is nothing to shift traffic to.
+func (s *SlowStart) Filter(pods map[string]*v1.Pod, model string) map[string]*v1.Pod {
+	if s == nil {
+		return pods
+	}
+
+	now := s.now()
+	weights := map[string]float64{}
+	warmedUp := 0
+	for _, pod := range utils.FilterReadyPods(pods) {
+		weight := s.weight(pod, now)
+		if weight >= 1 {
+			warmedUp++
+			continue
+		}
+		weights[pod.Name] = weight
+		if s.warmup {
+			if _, loaded := s.warmed.LoadOrStore(pod.UID, struct{}{}); !loaded {
+				go s.sendWarmup(pod, model)
+			}
+		}
+	}
+	if len(weights) == 0 || warmedUp == 0 {
+		return pods
+	}
+
+	filtered := make(map[string]*v1.Pod, len(pods))
+	for name, pod := range pods {
+		if weight, ok := weights[name]; ok && s.randFloat() >= weight {
+			klog.V(4).InfoS("skipping pod in slow start", "pod", name, "weight", weight)
+			continue
+		}
+		filtered[name] = pod
+	}
+	return filtered
+}
+
+// weight returns the selection weight of a ready pod, 1 once the pod is out of the slow start window.
+func (s *SlowStart) weight(pod *v1.Pod, now time.Time) float64 {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type != v1.PodReady || condition.LastTransitionTime.IsZero() {
+			continue
+		}
+		elapsed := now.Sub(condition.LastTransitionTime.Time)
+		if elapsed >= s.window {
+			return 1
+		}
+		weight := float64(elapsed) / float64(s.window)
+		if weight < s.minWeight {
+			return s.minWeight
+		}
+		return weight
+	}
+	return 1
+}
+
+// sendWarmupRequest sends a single token completion to the pod, so the engine captures graphs and allocates
+// its caches before serving real traffic.
+func sendWarmupRequest(pod *v1.Pod, model string) {
+	addr, err := getPodAddress(pod.Status.PodIP)
+	if err != nil {
+		return
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"model":      model,
+		"prompt":     "warm up",
+		"max_tokens": warmupMaxTokens,
+	})
+
+	client := &http.Client{Timeout: warmupRequestTimeout}
+	start := time.Now()
+	resp, err := client.Post(fmt.Sprintf("http://%s/v1/completions", addr), "application/json", bytes.NewReader(body))
+	if err != nil {
+		klog.ErrorS(err, "failed to send warm-up request", "pod", klog.KObj(pod), "model", model)
+		return
+	}
+	_ = resp.Body.Close()
+	klog.InfoS("warm-up request finished", "pod", klog.KObj(pod), "model", model, "status", resp.StatusCode, "duration", time.Since(start))
+}
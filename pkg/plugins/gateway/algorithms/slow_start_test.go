@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newSlowStartPod(name string, readySince time.Time) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name)},
+		Status: v1.PodStatus{
+			PodIP: "10.0.0.1",
+			Conditions: []v1.PodCondition{{
+				Type:               v1.PodReady,
+				Status:             v1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(readySince),
+			}},
+		},
+	}
+}
+
+func TestSlowStartWeight(t *testing.T) {
+	now := time.Now()
+	s := &SlowStart{window: 100 * time.Second, minWeight: 0.1}
+
+	assert.Equal(t, 1.0, s.weight(newSlowStartPod("old", now.Add(-time.Hour)), now))
+	assert.InDelta(t, 0.5, s.weight(newSlowStartPod("half", now.Add(-50*time.Second)), now), 0.001)
+	assert.Equal(t, 0.1, s.weight(newSlowStartPod("new", now), now), "weight is at least the min weight")
+	assert.Equal(t, 1.0, s.weight(&v1.Pod{}, now), "pods without ready time are not throttled")
+}
+
+func TestSlowStartFilter(t *testing.T) {
+	now := time.Now()
+	var mu sync.Mutex
+	var warmed []string
+	done := make(chan struct{}, 2)
+	s := &SlowStart{
+		window:    100 * time.Second,
+		minWeight: 0.1,
+		warmup:    true,
+		now:       func() time.Time { return now },
+		sendWarmup: func(pod *v1.Pod, model string) {
+			mu.Lock()
+			warmed = append(warmed, pod.Name+"/"+model)
+			mu.Unlock()
+			done <- struct{}{}
+		},
+	}
+	pods := map[string]*v1.Pod{
+		"old":  newSlowStartPod("old", now.Add(-time.Hour)),
+		"half": newSlowStartPod("half", now.Add(-50*time.Second)),
+	}
+
+	s.randFloat = func() float64 { return 0.6 }
+	filtered := s.Filter(pods, "llama")
+	assert.Len(t, filtered, 1, "pod is skipped when the draw is above its weight")
+	assert.Contains(t, filtered, "old")
+
+	s.randFloat = func() float64 { return 0.4 }
+	assert.Len(t, s.Filter(pods, "llama"), 2)
+
+	<-done
+	mu.Lock()
+	assert.Equal(t, []string{"half/llama"}, warmed, "warm-up is sent once per pod")
+	mu.Unlock()
+
+	onlyNew := map[string]*v1.Pod{"half": pods["half"]}
+	s.randFloat = func() float64 { return 0.99 }
+	assert.Len(t, s.Filter(onlyNew, "llama"), 1, "all pods warming keeps the candidates")
+
+	var disabled *SlowStart
+	assert.Len(t, disabled.Filter(pods, "llama"), 2)
+}
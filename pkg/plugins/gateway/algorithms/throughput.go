@@ -30,7 +30,7 @@ import (
 )
 
 type throughputRouter struct {
-	cache *cache.Cache
+	cache cache.Interface
 }
 
 func NewThroughputRouter() (Router, error) {
@@ -74,6 +74,8 @@ func (r throughputRouter) Route(ctx context.Context, pods map[string]*v1.Pod, mo
 		klog.V(4).Infof("pod: %v, podIP: %v, promptThroughput: %v, generationThroughput: %v, totalThroughput: %v",
 			pod.Name, pod.Status.PodIP, promptThroughput, generationThroughput, totalThroughput)
 
+		recordCandidateScore(ctx, pod.Name, totalThroughput)
+
 		if totalThroughput <= minCount {
 			minCount = totalThroughput
 			targetPodIP = pod.Status.PodIP
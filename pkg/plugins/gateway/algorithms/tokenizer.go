@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/tokenizer"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// TokenCountExact tokenizes whole prompts.
+	TokenCountExact = "exact"
+	// TokenCountApproximate tokenizes at most AIBRIX_APPROXIMATE_TOKEN_COUNT_MAX_BYTES of a prompt: the head and the
+	// tail to count its tokens, the head to match its prefix.
+	TokenCountApproximate = "approximate"
+)
+
+var (
+	// modelTokenizers tokenizes prompts with the tokenizer and the chat template declared by the pods of the model, so
+	// prefixes of models with different vocabularies are hashed on their own tokens.
+	modelTokenizers = tokenizer.NewRegistry(modelTokenizerSpec, modelChatTemplateSpec, modelEngineAddresses)
+
+	approximateTokenCountMaxBytes = getApproximateTokenCountMaxBytes()
+	// admissionMaxBytes and prefixMatchingMaxBytes bound the bytes tokenized per prompt, 0 tokenizes whole prompts.
+	admissionMaxBytes      = getTokenCountMaxBytes("AIBRIX_ADMISSION_TOKEN_COUNT", TokenCountApproximate)
+	prefixMatchingMaxBytes = getTokenCountMaxBytes("AIBRIX_PREFIX_MATCHING_TOKEN_COUNT", TokenCountExact)
+)
+
+func getApproximateTokenCountMaxBytes() int {
+	value := utils.LoadEnv("AIBRIX_APPROXIMATE_TOKEN_COUNT_MAX_BYTES", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_APPROXIMATE_TOKEN_COUNT_MAX_BYTES: %s, falling back to default", value)
+		} else {
+			return intValue
+		}
+	}
+	return tokenizer.DefaultApproximateMaxBytes
+}
+
+func getTokenCountMaxBytes(name, defaultMode string) int {
+	mode := utils.LoadEnv(name, defaultMode)
+	if mode != TokenCountExact && mode != TokenCountApproximate {
+		klog.Infof("invalid %s: %s, falling back to default", name, mode)
+		mode = defaultMode
+	}
+	if mode == TokenCountApproximate {
+		return approximateTokenCountMaxBytes
+	}
+	return 0
+}
+
+// PromptTokens tokenizes the message routed for the model the way its engines do, to match its prefix. Messages of
+// chat completions are rendered with the chat template of the model, other messages are tokenized as they are.
+// Only the head of large prompts is tokenized with AIBRIX_PREFIX_MATCHING_TOKEN_COUNT=approximate.
+func PromptTokens(model, message string) ([]int, error) {
+	if messages, err := tokenizer.ParseMessages([]byte(message)); err == nil {
+		return modelTokenizers.EncodeChatHead(model, messages, prefixMatchingMaxBytes)
+	}
+	return tokenizer.EncodeHead(modelTokenizers.ForModel(model), message, prefixMatchingMaxBytes)
+}
+
+// CountPromptTokens counts the tokens of the message like PromptTokens, to admit the request. Large prompts are
+// counted approximately unless AIBRIX_ADMISSION_TOKEN_COUNT=exact.
+func CountPromptTokens(model, message string) (int, error) {
+	if messages, err := tokenizer.ParseMessages([]byte(message)); err == nil {
+		return modelTokenizers.CountChat(model, messages, admissionMaxBytes)
+	}
+	return tokenizer.CountApproximate(modelTokenizers.ForModel(model), message, admissionMaxBytes)
+}
+
+// modelTokenizerSpec returns the model.aibrix.ai/tokenizer annotation of the model pods.
+func modelTokenizerSpec(model string) string {
+	info := modelInfo(model)
+	if info == nil {
+		return ""
+	}
+	return info.Tokenizer
+}
+
+// modelChatTemplateSpec returns the model.aibrix.ai/chat-template annotation of the model pods.
+func modelChatTemplateSpec(model string) string {
+	info := modelInfo(model)
+	if info == nil {
+		return ""
+	}
+	return info.ChatTemplate
+}
+
+func modelInfo(model string) *cache.ModelInfo {
+	c, err := cache.GetCache()
+	if err != nil {
+		return nil
+	}
+	info, err := c.GetModelInfo(model)
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// modelEngineAddresses returns the engines of the model remote tokenizers call.
+func modelEngineAddresses(model string) []string {
+	c, err := cache.GetCache()
+	if err != nil {
+		return nil
+	}
+	pods, err := c.GetPodsForModel(model)
+	if err != nil {
+		return nil
+	}
+	var addresses []string
+	for _, pod := range utils.FilterReadyPods(pods) {
+		if address, err := getPodAddress(pod.Status.PodIP); err == nil {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const defaultZoneSpilloverWaitingRequests = 4
+
+var (
+	zoneAwareRoutingEnabled      = utils.LoadEnv("AIBRIX_ZONE_AWARE_ROUTING", "false") == "true"
+	gatewayZone                  = utils.LoadEnv("AIBRIX_GATEWAY_ZONE", "")
+	gatewayNodeName              = utils.LoadEnv("NODE_NAME", "")
+	zoneSpilloverWaitingRequests = getZoneSpilloverWaitingRequests()
+)
+
+func getZoneSpilloverWaitingRequests() float64 {
+	value := utils.LoadEnv("AIBRIX_ZONE_SPILLOVER_WAITING_REQUESTS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_ZONE_SPILLOVER_WAITING_REQUESTS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_ZONE_SPILLOVER_WAITING_REQUESTS env value for zone spillover threshold: %d", intValue)
+			return float64(intValue)
+		}
+	}
+	return defaultZoneSpilloverWaitingRequests
+}
+
+// ZoneAffinity prefers pods in the zone of the gateway replica to cut cross-zone traffic. Pods of other zones stay
+// candidates when no ready pod runs in the local zone, or when every local pod queues at least the spillover threshold.
+type ZoneAffinity struct {
+	cache     cache.Interface
+	zone      string
+	nodeName  string
+	threshold float64
+}
+
+// NewZoneAffinity returns the zone affinity enabled by AIBRIX_ZONE_AWARE_ROUTING, nil if it is disabled.
+// The zone of the gateway is AIBRIX_GATEWAY_ZONE, or the zone of the node NODE_NAME.
+func NewZoneAffinity() *ZoneAffinity {
+	if !zoneAwareRoutingEnabled {
+		return nil
+	}
+	c, err := cache.GetCache()
+	if err != nil {
+		klog.ErrorS(err, "zone aware routing disabled")
+		return nil
+	}
+	if gatewayZone == "" && gatewayNodeName == "" {
+		klog.Warning("zone aware routing disabled, neither AIBRIX_GATEWAY_ZONE nor NODE_NAME is set")
+		return nil
+	}
+	klog.InfoS("zone aware routing enabled", "zone", gatewayZone, "node", gatewayNodeName, "spilloverWaitingRequests", zoneSpilloverWaitingRequests)
+	return &ZoneAffinity{cache: c, zone: gatewayZone, nodeName: gatewayNodeName, threshold: zoneSpilloverWaitingRequests}
+}
+
+// Zone returns the zone of the gateway replica, "" until the topology of its node is known.
+func (z *ZoneAffinity) Zone() string {
+	if z.zone != "" {
+		return z.zone
+	}
+	topology, _ := z.cache.GetNodeTopology(z.nodeName)
+	return topology.Zone
+}
+
+// Filter keeps the pods in the local zone if they have capacity left, pods are returned unchanged otherwise.
+func (z *ZoneAffinity) Filter(pods map[string]*v1.Pod, model string) map[string]*v1.Pod {
+	if z == nil {
+		return pods
+	}
+	zone := z.Zone()
+	if zone == "" {
+		return pods
+	}
+
+	local := make(map[string]*v1.Pod, len(pods))
+	for name, pod := range pods {
+		if topology, ok := z.cache.GetPodTopology(name); ok && topology.Zone == zone {
+			local[name] = pod
+		}
+	}
+	readyLocal := utils.FilterReadyPods(local)
+	if len(readyLocal) == 0 {
+		klog.V(4).InfoS("no ready pod in local zone, routing across zones", "zone", zone, "model", model)
+		return pods
+	}
+	if len(local) < len(pods) && z.saturated(readyLocal, model) {
+		klog.V(4).InfoS("local zone saturated, routing across zones", "zone", zone, "model", model)
+		return pods
+	}
+	return local
+}
+
+// saturated returns true if every pod queues at least threshold requests. Pods without metrics have capacity.
+func (z *ZoneAffinity) saturated(pods []*v1.Pod, model string) bool {
+	for _, pod := range pods {
+		metricVal, err := z.cache.GetPodModelMetric(pod.Name, model, metrics.NumRequestsWaiting)
+		if err != nil {
+			metricVal, err = z.cache.GetPodMetric(pod.Name, metrics.NumRequestsWaiting)
+			if err != nil {
+				return false
+			}
+		}
+		if metricVal.GetSimpleValue() < z.threshold {
+			return false
+		}
+	}
+	return true
+}
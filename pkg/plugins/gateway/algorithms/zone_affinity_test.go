@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routingalgorithms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+func newZoneTestPod(name, ip, zone string) *v1.Pod {
+	pod := newExternalTestPod(name, ip, true)
+	pod.Labels = map[string]string{v1.LabelTopologyZone: zone}
+	return pod
+}
+
+func TestZoneAffinity(t *testing.T) {
+	pods := map[string]*v1.Pod{
+		"a1": newZoneTestPod("a1", "10.0.0.1", "zone-a"),
+		"a2": newZoneTestPod("a2", "10.0.0.2", "zone-a"),
+		"b1": newZoneTestPod("b1", "10.0.0.3", "zone-b"),
+	}
+	c := cache.New()
+	for _, pod := range pods {
+		c.SetPod(pod, "m")
+	}
+	setPodMetrics(c, map[string]map[string]metrics.MetricValue{
+		"a1": {metrics.NumRequestsWaiting: &metrics.SimpleMetricValue{Value: 4}},
+		"a2": {metrics.NumRequestsWaiting: &metrics.SimpleMetricValue{Value: 1}},
+	})
+	z := &ZoneAffinity{cache: c, zone: "zone-a", threshold: 4}
+
+	filtered := z.Filter(pods, "m")
+	assert.Len(t, filtered, 2, "local zone has capacity")
+	assert.NotContains(t, filtered, "b1")
+
+	c.SetPodMetric("a2", metrics.NumRequestsWaiting, &metrics.SimpleMetricValue{Value: 5})
+	assert.Len(t, z.Filter(pods, "m"), 3, "local zone saturated")
+
+	z.zone = "zone-c"
+	assert.Len(t, z.Filter(pods, "m"), 3, "no pod in local zone")
+
+	var disabled *ZoneAffinity
+	assert.Len(t, disabled.Filter(pods, "m"), 3)
+}
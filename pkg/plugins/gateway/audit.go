@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"k8s.io/klog/v2"
+
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// EnvAuditLogSink enables the routing audit log, either "stdout" or a file path.
+	EnvAuditLogSink = "AIBRIX_AUDIT_LOG_SINK"
+
+	auditLogBufferSize = 1024
+
+	defaultRoutingHistorySize = 256
+)
+
+// AuditRecord is the routing decision of a request, written as one JSON line to the audit sink.
+type AuditRecord struct {
+	Timestamp       time.Time                `json:"timestamp"`
+	RequestID       string                   `json:"request_id"`
+	User            string                   `json:"user,omitempty"`
+	Model           string                   `json:"model,omitempty"`
+	RoutingStrategy string                   `json:"routing_strategy,omitempty"`
+	TargetPod       string                   `json:"target_pod,omitempty"`
+	Candidates      []routing.CandidateScore `json:"candidates,omitempty"`
+	// QueueingDelayMs is the time from the request arriving at the gateway to the routing decision.
+	QueueingDelayMs int64 `json:"queueing_delay_ms"`
+	DurationMs      int64 `json:"duration_ms"`
+	StatusCode      int   `json:"status_code,omitempty"`
+}
+
+// auditLogger writes audit records asynchronously, records are dropped rather than blocking requests
+// when the sink can't keep up.
+type auditLogger struct {
+	records chan *AuditRecord
+	encoder *json.Encoder
+}
+
+// newAuditLogger creates the audit logger configured by AIBRIX_AUDIT_LOG_SINK, nil if auditing is disabled.
+func newAuditLogger() *auditLogger {
+	sink := utils.LoadEnv(EnvAuditLogSink, "")
+	if sink == "" {
+		return nil
+	}
+
+	var w io.Writer
+	if sink == "stdout" {
+		w = os.Stdout
+	} else {
+		f, err := os.OpenFile(sink, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			klog.Fatalf("failed to open audit log sink %s: %v", sink, err)
+		}
+		w = f
+	}
+	klog.InfoS("routing audit log enabled", "sink", sink)
+
+	l := &auditLogger{
+		records: make(chan *AuditRecord, auditLogBufferSize),
+		encoder: json.NewEncoder(w),
+	}
+	go l.run()
+	return l
+}
+
+func (l *auditLogger) run() {
+	for record := range l.records {
+		if err := l.encoder.Encode(record); err != nil {
+			klog.ErrorS(err, "failed to write audit record", "requestID", record.RequestID)
+		}
+	}
+}
+
+// Log queues the record for writing, it is a no-op if auditing is disabled.
+func (l *auditLogger) Log(record *AuditRecord) {
+	if l == nil {
+		return
+	}
+	select {
+	case l.records <- record:
+	default:
+		klog.V(4).InfoS("audit log buffer is full, dropping record", "requestID", record.RequestID)
+	}
+}
+
+func getRoutingHistorySize() int {
+	value := utils.LoadEnv("AIBRIX_ROUTING_HISTORY_SIZE", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_ROUTING_HISTORY_SIZE: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_ROUTING_HISTORY_SIZE env value for routing history size: %d", intValue)
+			return intValue
+		}
+	}
+	return defaultRoutingHistorySize
+}
+
+// routingHistory keeps the most recent routing decisions in memory for the cache service,
+// independently of whether the audit log is enabled.
+type routingHistory struct {
+	mu      sync.Mutex
+	records []*AuditRecord
+	next    int
+	full    bool
+}
+
+// newRoutingHistory returns a history of the given size, nil if size is 0.
+func newRoutingHistory(size int) *routingHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &routingHistory{records: make([]*AuditRecord, size)}
+}
+
+// Add records the decision, overwriting the oldest one once the history is full.
+func (h *routingHistory) Add(record *AuditRecord) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Recent returns up to limit decisions matching the model, newest first. An empty model matches all
+// decisions and a non-positive limit returns the whole history.
+func (h *routingHistory) Recent(model string, limit int) []*AuditRecord {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	count := h.next
+	if h.full {
+		count = len(h.records)
+	}
+	var recent []*AuditRecord
+	for i := 1; i <= count; i++ {
+		record := h.records[(h.next-i+len(h.records))%len(h.records)]
+		if model != "" && record.Model != model {
+			continue
+		}
+		recent = append(recent, record)
+		if limit > 0 && len(recent) == limit {
+			break
+		}
+	}
+	return recent
+}
+
+// immediateResponseCode returns the status code if the response short-circuits the request.
+func immediateResponseCode(resp *extProcPb.ProcessingResponse) (int, bool) {
+	immediate, ok := resp.GetResponse().(*extProcPb.ProcessingResponse_ImmediateResponse)
+	if !ok {
+		return 0, false
+	}
+	return int(immediate.ImmediateResponse.GetStatus().GetCode()), true
+}
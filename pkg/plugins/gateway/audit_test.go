@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/assert"
+
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+)
+
+func TestAuditLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := &auditLogger{
+		records: make(chan *AuditRecord, 1),
+		encoder: json.NewEncoder(&buf),
+	}
+
+	l.Log(&AuditRecord{
+		RequestID:       "req-1",
+		Model:           "llama-7b",
+		RoutingStrategy: "least-request",
+		TargetPod:       "10.0.0.1:8000",
+		Candidates:      []routing.CandidateScore{{Pod: "p1", Score: 1}, {Pod: "p2", Score: 3}},
+		StatusCode:      200,
+	})
+	// the buffer is full, the record is dropped instead of blocking
+	l.Log(&AuditRecord{RequestID: "req-2"})
+	close(l.records)
+	l.run()
+
+	var record AuditRecord
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "req-1", record.RequestID)
+	assert.Equal(t, "10.0.0.1:8000", record.TargetPod)
+	assert.Len(t, record.Candidates, 2)
+	assert.NotContains(t, buf.String(), "req-2")
+
+	// disabled logger is a no-op
+	var disabled *auditLogger
+	disabled.Log(&AuditRecord{Timestamp: time.Now()})
+}
+
+func TestImmediateResponseCode(t *testing.T) {
+	code, ok := immediateResponseCode(generateErrorResponse(envoyTypePb.StatusCode_TooManyRequests, nil, "rpm exceeded"))
+	assert.True(t, ok)
+	assert.Equal(t, 429, code)
+
+	_, ok = immediateResponseCode(&extProcPb.ProcessingResponse{
+		Response: &extProcPb.ProcessingResponse_RequestBody{},
+	})
+	assert.False(t, ok)
+}
+
+func TestRoutingHistory(t *testing.T) {
+	h := newRoutingHistory(3)
+	for i, model := range []string{"llama", "qwen", "llama", "llama"} {
+		h.Add(&AuditRecord{RequestID: string(rune('a' + i)), Model: model})
+	}
+
+	requestIDs := func(records []*AuditRecord) []string {
+		var ids []string
+		for _, record := range records {
+			ids = append(ids, record.RequestID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"d", "c", "b"}, requestIDs(h.Recent("", 0)), "oldest record is overwritten")
+	assert.Equal(t, []string{"d", "c"}, requestIDs(h.Recent("llama", 0)))
+	assert.Equal(t, []string{"d"}, requestIDs(h.Recent("", 1)))
+
+	disabled := newRoutingHistory(0)
+	disabled.Add(&AuditRecord{Model: "llama"})
+	assert.Empty(t, disabled.Recent("", 0))
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/redis/go-redis/v9"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/auth"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	EnvAuthMode            = "AIBRIX_AUTH_MODE"
+	EnvAuthSecretName      = "AIBRIX_AUTH_SECRET_NAME"
+	EnvAuthRefreshInterval = "AIBRIX_AUTH_SECRET_REFRESH_INTERVAL_S"
+
+	AuthModeNone   = "none"
+	AuthModeRedis  = "redis"
+	AuthModeSecret = "secret"
+
+	defaultAuthSecretName               = "aibrix-gateway-api-keys"
+	defaultAuthRefreshIntervalInSeconds = 30
+
+	HeaderErrorAuthentication = "x-error-authentication"
+)
+
+// LoadAuthenticator creates the api key authenticator configured by AIBRIX_AUTH_MODE, shared by the gateway and the
+// batch API. Authentication is disabled by default, in which case the caller is identified by the user header.
+func LoadAuthenticator(redisClient redis.UniversalClient, client kubernetes.Interface) auth.Authenticator {
+	mode := strings.ToLower(utils.LoadEnv(EnvAuthMode, AuthModeNone))
+	switch mode {
+	case AuthModeNone, "":
+		return nil
+	case AuthModeRedis:
+		klog.InfoS("api key authentication enabled", "mode", mode)
+		return auth.NewRedisAuthenticator(redisClient)
+	case AuthModeSecret:
+		namespace := utils.LoadEnv("POD_NAMESPACE", "aibrix-system")
+		name := utils.LoadEnv(EnvAuthSecretName, defaultAuthSecretName)
+		a, err := auth.NewSecretAuthenticator(client, namespace, name, getAuthRefreshInterval(), nil)
+		if err != nil {
+			klog.Fatalf("failed to initialize api key authentication: %v", err)
+		}
+		klog.InfoS("api key authentication enabled", "mode", mode, "secret", klog.KRef(namespace, name))
+		return a
+	default:
+		klog.Fatalf("invalid %s: %s, must be one of %s, %s, %s", EnvAuthMode, mode, AuthModeNone, AuthModeRedis, AuthModeSecret)
+	}
+	return nil
+}
+
+func getAuthRefreshInterval() time.Duration {
+	value := utils.LoadEnv(EnvAuthRefreshInterval, "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid %s: %s, falling back to default", EnvAuthRefreshInterval, value)
+		} else {
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultAuthRefreshIntervalInSeconds * time.Second
+}
+
+// authenticate resolves the caller identity from the Authorization header.
+func (s *Server) authenticate(ctx context.Context, requestID string, headers []*configPb.HeaderValue) (*auth.Identity, *extProcPb.ProcessingResponse) {
+	var authorization string
+	for _, header := range headers {
+		if strings.ToLower(header.Key) == "authorization" {
+			authorization = string(header.RawValue)
+		}
+	}
+
+	apiKey, err := auth.ParseBearerToken(authorization)
+	if err == nil {
+		var identity *auth.Identity
+		identity, err = s.authenticator.Authenticate(ctx, apiKey)
+		if err == nil {
+			return identity, nil
+		}
+	}
+
+	code := envoyTypePb.StatusCode_Unauthorized
+	message := err.Error()
+	if !errors.Is(err, auth.ErrMissingAPIKey) && !errors.Is(err, auth.ErrInvalidAPIKey) && !errors.Is(err, auth.ErrDisabledAPIKey) {
+		klog.ErrorS(err, "failed to authenticate request", "requestID", requestID)
+		code = envoyTypePb.StatusCode_InternalServerError
+		message = "error on authenticating request"
+	}
+	return nil, generateErrorResponse(code,
+		[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+			Key: HeaderErrorAuthentication, RawValue: []byte("true"),
+		}}},
+		message)
+}
+
+// tenantOf returns the tenant of the caller without loading its limits: the tenant of its API key, else of its user
+// record, else the user itself.
+func (s *Server) tenantOf(username string, identity *auth.Identity) string {
+	if identity != nil && identity.Tenant != "" {
+		return identity.Tenant
+	}
+	if username == "" || s.redisClient == nil {
+		return username
+	}
+	user, err := utils.GetUser(utils.User{Name: username}, s.redisClient)
+	if err != nil {
+		return username
+	}
+	return user.TenantID()
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrMissingAPIKey  = errors.New("missing api key")
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrDisabledAPIKey = errors.New("api key is disabled")
+)
+
+// Identity is the caller identity resolved from an api key.
+type Identity struct {
+	User     string
+	Tenant   string
+	Metadata map[string]string
+}
+
+// Authenticator validates api keys and resolves the identity they belong to.
+type Authenticator interface {
+	// Authenticate returns the identity of the api key, or an error if the key is unknown or disabled.
+	Authenticate(ctx context.Context, apiKey string) (*Identity, error)
+}
+
+// ParseBearerToken extracts the api key from an Authorization header value.
+func ParseBearerToken(header string) (string, error) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", ErrMissingAPIKey
+	}
+	return strings.TrimSpace(token), nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseBearerToken(t *testing.T) {
+	token, err := ParseBearerToken("Bearer sk-123")
+	assert.NoError(t, err)
+	assert.Equal(t, "sk-123", token)
+
+	token, err = ParseBearerToken("bearer  sk-123 ")
+	assert.NoError(t, err)
+	assert.Equal(t, "sk-123", token)
+
+	for _, header := range []string{"", "sk-123", "Basic dXNlcjpwYXNz", "Bearer "} {
+		_, err = ParseBearerToken(header)
+		assert.ErrorIs(t, err, ErrMissingAPIKey, header)
+	}
+}
+
+func TestSecretAuthenticator(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-keys", Namespace: "aibrix-system"},
+		Data: map[string][]byte{
+			"alice":   []byte(`{"key":"sk-alice","user":"alice","tenant":"acme"}`),
+			"bob":     []byte(`{"key":"sk-bob","user":"bob","disabled":true}`),
+			"invalid": []byte(`not-json`),
+		},
+	})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	a, err := NewSecretAuthenticator(client, "aibrix-system", "api-keys", time.Minute, stopCh)
+	assert.NoError(t, err)
+
+	identity, err := a.Authenticate(context.Background(), "sk-alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", identity.User)
+	assert.Equal(t, "acme", identity.Tenant)
+
+	_, err = a.Authenticate(context.Background(), "sk-bob")
+	assert.ErrorIs(t, err, ErrDisabledAPIKey)
+
+	_, err = a.Authenticate(context.Background(), "sk-unknown")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+}
+
+func TestSecretAuthenticatorMissingSecret(t *testing.T) {
+	_, err := NewSecretAuthenticator(fake.NewSimpleClientset(), "aibrix-system", "api-keys", time.Minute, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+type redisAuthenticator struct {
+	client redis.UniversalClient
+}
+
+// NewRedisAuthenticator validates api keys against the key store managed by the metadata service.
+func NewRedisAuthenticator(client redis.UniversalClient) Authenticator {
+	return &redisAuthenticator{client: client}
+}
+
+func (a *redisAuthenticator) Authenticate(ctx context.Context, apiKey string) (*Identity, error) {
+	key, err := utils.GetAPIKey(apiKey, a.client)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrInvalidAPIKey
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to look up api key: %w", err)
+	}
+
+	return identityFromAPIKey(key)
+}
+
+func identityFromAPIKey(key utils.APIKey) (*Identity, error) {
+	if key.Disabled {
+		return nil, ErrDisabledAPIKey
+	}
+	return &Identity{User: key.User, Tenant: key.Tenant, Metadata: key.Metadata}, nil
+}
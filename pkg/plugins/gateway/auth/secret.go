@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// secretAuthenticator validates api keys against a Secret, each data entry holds a JSON encoded utils.APIKey
+// including the plain key. The Secret is reloaded periodically so keys can be rotated without restart.
+type secretAuthenticator struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+
+	mu   sync.RWMutex
+	keys map[string]utils.APIKey // hashed_key: APIKey
+}
+
+// NewSecretAuthenticator loads the api keys from the Secret and refreshes them every refreshInterval until stopCh is closed.
+func NewSecretAuthenticator(client kubernetes.Interface, namespace, name string, refreshInterval time.Duration, stopCh <-chan struct{}) (Authenticator, error) {
+	a := &secretAuthenticator{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+		keys:      map[string]utils.APIKey{},
+	}
+	if err := a.reload(context.Background()); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := a.reload(context.Background()); err != nil {
+					klog.ErrorS(err, "failed to reload api keys, keeping previous keys", "secret", klog.KRef(namespace, name))
+				}
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+	return a, nil
+}
+
+func (a *secretAuthenticator) reload(ctx context.Context) error {
+	secret, err := a.client.CoreV1().Secrets(a.namespace).Get(ctx, a.name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get api key secret %s/%s: %w", a.namespace, a.name, err)
+	}
+
+	keys := make(map[string]utils.APIKey, len(secret.Data))
+	for entry, data := range secret.Data {
+		var key utils.APIKey
+		if err := json.Unmarshal(data, &key); err != nil || key.Key == "" || key.User == "" {
+			klog.Warningf("skipping invalid api key entry %s in secret %s/%s", entry, a.namespace, a.name)
+			continue
+		}
+		hashed := utils.HashAPIKey(key.Key)
+		key.Key = ""
+		keys[hashed] = key
+	}
+
+	a.mu.Lock()
+	a.keys = keys
+	a.mu.Unlock()
+	klog.V(4).InfoS("loaded api keys", "secret", klog.KRef(a.namespace, a.name), "count", len(keys))
+	return nil
+}
+
+func (a *secretAuthenticator) Authenticate(ctx context.Context, apiKey string) (*Identity, error) {
+	a.mu.RLock()
+	key, ok := a.keys[utils.HashAPIKey(apiKey)]
+	a.mu.RUnlock()
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	return identityFromAPIKey(key)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package batch serves the OpenAI batch API: JSONL files of requests uploaded through the files API run in the
+// background on the pods of their models with spare capacity, and their responses are written to output files.
+package batch
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+)
+
+const (
+	StatusValidating = "validating"
+	StatusFailed     = "failed"
+	StatusInProgress = "in_progress"
+	StatusFinalizing = "finalizing"
+	StatusCompleted  = "completed"
+	StatusExpired    = "expired"
+	StatusCancelling = "cancelling"
+	StatusCancelled  = "cancelled"
+
+	PurposeBatch       = "batch"
+	PurposeBatchOutput = "batch_output"
+
+	// CompletionWindow is the only completion window of batches, as in the OpenAI API.
+	CompletionWindow = "24h"
+)
+
+// endpoints are the endpoints batches can call.
+var endpoints = map[string]bool{
+	"/v1/chat/completions": true,
+	"/v1/completions":      true,
+	"/v1/embeddings":       true,
+}
+
+// Batch follows the OpenAI batch object.
+type Batch struct {
+	ID               string  `json:"id"`
+	Object           string  `json:"object"`
+	Endpoint         string  `json:"endpoint"`
+	Errors           *Errors `json:"errors,omitempty"`
+	InputFileID      string  `json:"input_file_id"`
+	CompletionWindow string  `json:"completion_window"`
+	Status           string  `json:"status"`
+	OutputFileID     string  `json:"output_file_id,omitempty"`
+	ErrorFileID      string  `json:"error_file_id,omitempty"`
+
+	CreatedAt    int64 `json:"created_at"`
+	InProgressAt int64 `json:"in_progress_at,omitempty"`
+	ExpiresAt    int64 `json:"expires_at"`
+	FinalizingAt int64 `json:"finalizing_at,omitempty"`
+	CompletedAt  int64 `json:"completed_at,omitempty"`
+	FailedAt     int64 `json:"failed_at,omitempty"`
+	ExpiredAt    int64 `json:"expired_at,omitempty"`
+	CancellingAt int64 `json:"cancelling_at,omitempty"`
+	CancelledAt  int64 `json:"cancelled_at,omitempty"`
+
+	// PausedAt and PauseReason are set while the batch policy pauses the batch, an extension of the OpenAI object.
+	PausedAt    int64  `json:"paused_at,omitempty"`
+	PauseReason string `json:"pause_reason,omitempty"`
+
+	RequestCounts RequestCounts     `json:"request_counts"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+
+	// Owner is the user who created the batch, only visible to it, an extension of the OpenAI object.
+	Owner string `json:"owner,omitempty"`
+}
+
+// Done returns true if the batch reached a final status.
+func (b *Batch) Done() bool {
+	switch b.Status {
+	case StatusFailed, StatusCompleted, StatusExpired, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// RequestCounts counts the requests of a batch by outcome.
+type RequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// Errors lists the validation errors of a failed batch.
+type Errors struct {
+	Object string  `json:"object"`
+	Data   []Error `json:"data"`
+}
+
+// Error is a validation error of a line of the input file, or of the whole file if Line is 0.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// File follows the OpenAI file object.
+type File struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int    `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+
+	// Owner is the user who uploaded the file, or whose batch wrote it, only visible to it, an extension of the
+	// OpenAI object.
+	Owner string `json:"owner,omitempty"`
+}
+
+// RequestLine is a line of a batch input file.
+type RequestLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// ResponseLine is a line of a batch output or error file.
+type ResponseLine struct {
+	ID       string        `json:"id"`
+	CustomID string        `json:"custom_id"`
+	Response *LineResponse `json:"response"`
+	Error    *LineError    `json:"error"`
+}
+
+// LineResponse is the response of the engine to a request of a batch.
+type LineResponse struct {
+	StatusCode int             `json:"status_code"`
+	RequestID  string          `json:"request_id"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// LineError is the reason a request of a batch got no response.
+type LineError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// newID returns a random id with the prefix of its object, e.g. batch_ or file-.
+func newID(prefix string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}
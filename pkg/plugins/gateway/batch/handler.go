@@ -0,0 +1,348 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/auth"
+)
+
+const (
+	defaultMaxFileBytes = 200 << 20
+	defaultListLimit    = 20
+	maxListLimit        = 100
+	// multipartMemory is the part of uploads held in memory, the rest is spooled to disk by net/http.
+	multipartMemory = 32 << 20
+)
+
+var maxFileBytes = getIntEnv("AIBRIX_BATCH_MAX_FILE_BYTES", defaultMaxFileBytes)
+
+// Handler serves the OpenAI files and batch endpoints: POST /v1/files, GET and DELETE /v1/files/{id},
+// GET /v1/files/{id}/content, POST and GET /v1/batches, GET /v1/batches/{id} and POST /v1/batches/{id}/cancel.
+// Created batches are submitted to the scheduler.
+//
+// The caller is authenticated by its api key as the inference requests are, or identified by the user header if the
+// gateway has no authenticator. The files and batches are owned by the user who created them, the others get 404.
+func Handler(store *Store, scheduler *Scheduler, authenticator auth.Authenticator) http.Handler {
+	h := &handler{store: store, scheduler: scheduler, authenticator: authenticator}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/files", h.createFile)
+	mux.HandleFunc("GET /v1/files/{id}", h.getFile)
+	mux.HandleFunc("DELETE /v1/files/{id}", h.deleteFile)
+	mux.HandleFunc("GET /v1/files/{id}/content", h.getFileContent)
+	mux.HandleFunc("POST /v1/batches", h.createBatch)
+	mux.HandleFunc("GET /v1/batches", h.listBatches)
+	mux.HandleFunc("GET /v1/batches/{id}", h.getBatch)
+	mux.HandleFunc("POST /v1/batches/{id}/cancel", h.cancelBatch)
+	return h.authenticate(mux)
+}
+
+type handler struct {
+	store         *Store
+	scheduler     *Scheduler
+	authenticator auth.Authenticator
+}
+
+type ownerKey struct{}
+
+// authenticate resolves the user of the request before serving it, and rejects the requests of unknown users.
+func (h *handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		owner := r.Header.Get("user")
+		if h.authenticator != nil {
+			apiKey, err := auth.ParseBearerToken(r.Header.Get("Authorization"))
+			var identity *auth.Identity
+			if err == nil {
+				identity, err = h.authenticator.Authenticate(r.Context(), apiKey)
+			}
+			if err != nil {
+				if !errors.Is(err, auth.ErrMissingAPIKey) && !errors.Is(err, auth.ErrInvalidAPIKey) && !errors.Is(err, auth.ErrDisabledAPIKey) {
+					klog.ErrorS(err, "failed to authenticate batch api request")
+					writeError(w, http.StatusInternalServerError, "error on authenticating request")
+					return
+				}
+				writeError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			owner = identity.User
+		}
+		if owner == "" {
+			writeError(w, http.StatusUnauthorized, "user header is required")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ownerKey{}, owner)))
+	})
+}
+
+// owner returns the user of the request resolved by authenticate.
+func owner(r *http.Request) string {
+	owner, _ := r.Context().Value(ownerKey{}).(string)
+	return owner
+}
+
+// getOwnedFile returns the file if the user of the request owns it, ErrNotFound otherwise.
+func (h *handler) getOwnedFile(r *http.Request, id string) (*File, error) {
+	file, err := h.store.GetFile(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if file.Owner != owner(r) {
+		return nil, ErrNotFound
+	}
+	return file, nil
+}
+
+// getOwnedBatch returns the batch if the user of the request owns it, ErrNotFound otherwise.
+func (h *handler) getOwnedBatch(r *http.Request, id string) (*Batch, error) {
+	batch, err := h.store.GetBatch(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Owner != owner(r) {
+		return nil, ErrNotFound
+	}
+	return batch, nil
+}
+
+func (h *handler) createFile(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(maxFileBytes)+multipartMemory)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid multipart form: %v", err))
+		return
+	}
+	if purpose := r.FormValue("purpose"); purpose != PurposeBatch {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("purpose must be %s, got %q", PurposeBatch, purpose))
+		return
+	}
+	upload, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer upload.Close()
+	if header.Size > int64(maxFileBytes) {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("file is %d bytes, more than the max of %d", header.Size, maxFileBytes))
+		return
+	}
+	content, err := io.ReadAll(upload)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("failed to read file: %v", err))
+		return
+	}
+	file, err := h.store.CreateFile(r.Context(), owner(r), header.Filename, PurposeBatch, content)
+	if err != nil {
+		klog.ErrorS(err, "failed to create file", "filename", header.Filename)
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, file)
+}
+
+func (h *handler) getFile(w http.ResponseWriter, r *http.Request) {
+	file, err := h.getOwnedFile(r, r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, "file", r.PathValue("id"), err)
+		return
+	}
+	writeJSON(w, file)
+}
+
+func (h *handler) deleteFile(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := h.getOwnedFile(r, id); err != nil {
+		writeStoreError(w, "file", id, err)
+		return
+	}
+	if err := h.store.DeleteFile(r.Context(), id); err != nil {
+		writeStoreError(w, "file", id, err)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"id": id, "object": "file", "deleted": true})
+}
+
+func (h *handler) getFileContent(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.getOwnedFile(r, r.PathValue("id")); err != nil {
+		writeStoreError(w, "file", r.PathValue("id"), err)
+		return
+	}
+	content, err := h.store.GetFileContent(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, "file", r.PathValue("id"), err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if _, err := w.Write(content); err != nil {
+		klog.ErrorS(err, "failed to write file content")
+	}
+}
+
+// createBatchRequest is the body of POST /v1/batches.
+type createBatchRequest struct {
+	InputFileID      string            `json:"input_file_id"`
+	Endpoint         string            `json:"endpoint"`
+	CompletionWindow string            `json:"completion_window"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+func (h *handler) createBatch(w http.ResponseWriter, r *http.Request) {
+	var req createBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if !endpoints[req.Endpoint] {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unsupported endpoint %q, must be /v1/chat/completions, /v1/completions or /v1/embeddings", req.Endpoint))
+		return
+	}
+	if req.CompletionWindow != CompletionWindow {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("completion_window must be %s", CompletionWindow))
+		return
+	}
+	file, err := h.getOwnedFile(r, req.InputFileID)
+	if err != nil {
+		writeStoreError(w, "file", req.InputFileID, err)
+		return
+	}
+	if file.Purpose != PurposeBatch {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("input file %s must have the %s purpose", file.ID, PurposeBatch))
+		return
+	}
+
+	now := h.store.now()
+	batch := &Batch{
+		ID:               newID("batch_"),
+		Object:           "batch",
+		Endpoint:         req.Endpoint,
+		InputFileID:      req.InputFileID,
+		CompletionWindow: req.CompletionWindow,
+		Status:           StatusValidating,
+		CreatedAt:        now.Unix(),
+		ExpiresAt:        now.Add(24 * time.Hour).Unix(),
+		Metadata:         req.Metadata,
+		Owner:            owner(r),
+	}
+	if err := h.store.SaveBatch(r.Context(), batch); err != nil {
+		klog.ErrorS(err, "failed to create batch")
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.scheduler.Submit(batch.ID)
+	klog.InfoS("batch created", "batch", batch.ID, "endpoint", batch.Endpoint, "inputFile", batch.InputFileID, "owner", batch.Owner)
+	writeJSON(w, batch)
+}
+
+func (h *handler) listBatches(w http.ResponseWriter, r *http.Request) {
+	limit := defaultListLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > maxListLimit {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be within [1, %d]", maxListLimit))
+			return
+		}
+	}
+	batches, hasMore, err := h.store.ListBatches(r.Context(), owner(r), r.URL.Query().Get("after"), limit)
+	if err != nil {
+		klog.ErrorS(err, "failed to list batches")
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	list := map[string]interface{}{"object": "list", "data": batches, "has_more": hasMore}
+	if len(batches) == 0 {
+		list["data"] = []*Batch{}
+	} else {
+		list["first_id"] = batches[0].ID
+		list["last_id"] = batches[len(batches)-1].ID
+	}
+	writeJSON(w, list)
+}
+
+func (h *handler) getBatch(w http.ResponseWriter, r *http.Request) {
+	batch, err := h.getOwnedBatch(r, r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, "batch", r.PathValue("id"), err)
+		return
+	}
+	writeJSON(w, batch)
+}
+
+// cancelBatch asks the replica running the batch to cancel it. Batches no replica runs yet are cancelled at once.
+func (h *handler) cancelBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	batch, err := h.getOwnedBatch(r, r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, "batch", r.PathValue("id"), err)
+		return
+	}
+	if batch.Done() || batch.Status == StatusCancelling {
+		if batch.Status != StatusCancelled && batch.Status != StatusCancelling {
+			writeError(w, http.StatusConflict, fmt.Sprintf("batch %s is %s and can't be cancelled", batch.ID, batch.Status))
+			return
+		}
+		writeJSON(w, batch)
+		return
+	}
+	if err := h.store.requestCancel(ctx, batch.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := h.store.now().Unix()
+	batch.CancellingAt = now
+	if leased, err := h.store.leased(ctx, batch.ID); err == nil && !leased && batch.Status == StatusValidating {
+		batch.Status = StatusCancelled
+		batch.CancelledAt = now
+	} else {
+		batch.Status = StatusCancelling
+	}
+	if err := h.store.SaveBatch(ctx, batch); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, batch)
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		klog.ErrorS(err, "failed to encode response")
+	}
+}
+
+// writeError writes an error in the format of the gateway errors.
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]interface{}{"message": message, "code": code}})
+}
+
+func writeStoreError(w http.ResponseWriter, object, id string, err error) {
+	if errors.Is(err, ErrNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s does not exist", object, id))
+		return
+	}
+	klog.ErrorS(err, "failed to read from the batch store", object, id)
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
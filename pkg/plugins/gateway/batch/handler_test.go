@@ -0,0 +1,248 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/auth"
+)
+
+func uploadFile(t *testing.T, h http.Handler, purpose, content string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("purpose", purpose))
+	part, err := form.CreateFormFile("file", "input.jsonl")
+	require.NoError(t, err)
+	_, _ = part.Write([]byte(content))
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/files", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("user", "alice")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func serve(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	return serveAs(h, "alice", method, path, body)
+}
+
+func serveAs(h http.Handler, user, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if user != "" {
+		req.Header.Set("user", user)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+type fakeAuthenticator map[string]*auth.Identity
+
+func (a fakeAuthenticator) Authenticate(_ context.Context, apiKey string) (*auth.Identity, error) {
+	if identity, ok := a[apiKey]; ok {
+		return identity, nil
+	}
+	return nil, auth.ErrInvalidAPIKey
+}
+
+func TestHandlerFiles(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	h := Handler(store, NewScheduler(store, nil), nil)
+
+	rec := uploadFile(t, h, PurposeBatch, "line\n")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var file File
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &file))
+	assert.True(t, strings.HasPrefix(file.ID, "file-"))
+	assert.Equal(t, File{ID: file.ID, Object: "file", Bytes: 5, CreatedAt: file.CreatedAt, Filename: "input.jsonl", Purpose: PurposeBatch, Owner: "alice"}, file)
+
+	rec = serve(h, http.MethodGet, "/v1/files/"+file.ID, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(h, http.MethodGet, "/v1/files/"+file.ID+"/content", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "line\n", rec.Body.String())
+
+	rec = serve(h, http.MethodDelete, "/v1/files/"+file.ID, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":"`+file.ID+`","object":"file","deleted":true}`, rec.Body.String())
+	rec = serve(h, http.MethodGet, "/v1/files/"+file.ID+"/content", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"error":{"message":"file `+file.ID+` does not exist","code":404}}`, rec.Body.String())
+
+	rec = uploadFile(t, h, "fine-tune", "line\n")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandlerBatches(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	scheduler := NewScheduler(store, nil)
+	h := Handler(store, scheduler, nil)
+
+	file, err := store.CreateFile(context.Background(), "alice", "input.jsonl", PurposeBatch, []byte(requestLine("a", "m", "hello")))
+	require.NoError(t, err)
+	output, err := store.CreateFile(context.Background(), "alice", "output.jsonl", PurposeBatchOutput, []byte("{}"))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"unsupported endpoint", `{"input_file_id":"` + file.ID + `","endpoint":"/v1/rerank","completion_window":"24h"}`, http.StatusBadRequest},
+		{"unsupported completion window", `{"input_file_id":"` + file.ID + `","endpoint":"/v1/completions","completion_window":"1h"}`, http.StatusBadRequest},
+		{"unknown file", `{"input_file_id":"file-unknown","endpoint":"/v1/completions","completion_window":"24h"}`, http.StatusNotFound},
+		{"output file", `{"input_file_id":"` + output.ID + `","endpoint":"/v1/completions","completion_window":"24h"}`, http.StatusBadRequest},
+		{"invalid body", `{`, http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.code, serve(h, http.MethodPost, "/v1/batches", tc.body).Code)
+		})
+	}
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		rec := serve(h, http.MethodPost, "/v1/batches",
+			`{"input_file_id":"`+file.ID+`","endpoint":"/v1/completions","completion_window":"24h","metadata":{"team":"a"}}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var batch Batch
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &batch))
+		assert.Equal(t, StatusValidating, batch.Status)
+		assert.Equal(t, batch.CreatedAt+24*3600, batch.ExpiresAt)
+		assert.Equal(t, map[string]string{"team": "a"}, batch.Metadata)
+		assert.Equal(t, "alice", batch.Owner)
+		assert.Equal(t, batch.ID, <-scheduler.queue)
+		ids = append(ids, batch.ID)
+	}
+
+	rec := serve(h, http.MethodGet, "/v1/batches/"+ids[0], "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, http.StatusNotFound, serve(h, http.MethodGet, "/v1/batches/batch_unknown", "").Code)
+
+	var list struct {
+		Data    []Batch `json:"data"`
+		FirstID string  `json:"first_id"`
+		LastID  string  `json:"last_id"`
+		HasMore bool    `json:"has_more"`
+	}
+	rec = serve(h, http.MethodGet, "/v1/batches?limit=2", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Len(t, list.Data, 2)
+	assert.True(t, list.HasMore)
+	rec = serve(h, http.MethodGet, "/v1/batches?limit=2&after="+list.LastID, "")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Len(t, list.Data, 1)
+	assert.False(t, list.HasMore)
+	assert.Equal(t, http.StatusBadRequest, serve(h, http.MethodGet, "/v1/batches?limit=0", "").Code)
+
+	// a batch no replica runs yet is cancelled at once, a running one is cancelled by its replica.
+	rec = serve(h, http.MethodPost, "/v1/batches/"+ids[0]+"/cancel", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var batch Batch
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &batch))
+	assert.Equal(t, StatusCancelled, batch.Status)
+
+	_, err = store.acquireLease(context.Background(), ids[1], "replica", leaseTTL)
+	require.NoError(t, err)
+	rec = serve(h, http.MethodPost, "/v1/batches/"+ids[1]+"/cancel", "")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &batch))
+	assert.Equal(t, StatusCancelling, batch.Status)
+	cancelled, err := store.cancelRequested(context.Background(), ids[1])
+	assert.NoError(t, err)
+	assert.True(t, cancelled)
+
+	completed, err := store.GetBatch(context.Background(), ids[2])
+	require.NoError(t, err)
+	completed.Status = StatusCompleted
+	require.NoError(t, store.SaveBatch(context.Background(), completed))
+	assert.Equal(t, http.StatusConflict, serve(h, http.MethodPost, "/v1/batches/"+ids[2]+"/cancel", "").Code)
+}
+
+func TestHandlerAuthentication(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	h := Handler(store, NewScheduler(store, nil), fakeAuthenticator{"sk-alice": {User: "alice"}})
+
+	withKey := func(apiKey, method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("user", "alice")
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	// the user header is ignored when the gateway authenticates the api keys.
+	assert.Equal(t, http.StatusUnauthorized, withKey("", http.MethodGet, "/v1/batches").Code)
+	assert.Equal(t, http.StatusUnauthorized, withKey("sk-unknown", http.MethodGet, "/v1/batches").Code)
+	assert.Equal(t, http.StatusOK, withKey("sk-alice", http.MethodGet, "/v1/batches").Code)
+
+	// without an authenticator, the caller is identified by the user header.
+	h = Handler(store, NewScheduler(store, nil), nil)
+	assert.Equal(t, http.StatusUnauthorized, serveAs(h, "", http.MethodGet, "/v1/batches", "").Code)
+}
+
+func TestHandlerOwnership(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	scheduler := NewScheduler(store, nil)
+	h := Handler(store, scheduler, nil)
+
+	file, err := store.CreateFile(context.Background(), "alice", "input.jsonl", PurposeBatch, []byte(requestLine("a", "m", "hello")))
+	require.NoError(t, err)
+	rec := serve(h, http.MethodPost, "/v1/batches", `{"input_file_id":"`+file.ID+`","endpoint":"/v1/completions","completion_window":"24h"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var batch Batch
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &batch))
+	<-scheduler.queue
+
+	// the files and batches of other users don't exist for bob.
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodGet, "/v1/files/" + file.ID, ""},
+		{http.MethodGet, "/v1/files/" + file.ID + "/content", ""},
+		{http.MethodDelete, "/v1/files/" + file.ID, ""},
+		{http.MethodGet, "/v1/batches/" + batch.ID, ""},
+		{http.MethodPost, "/v1/batches/" + batch.ID + "/cancel", ""},
+		{http.MethodPost, "/v1/batches", `{"input_file_id":"` + file.ID + `","endpoint":"/v1/completions","completion_window":"24h"}`},
+	} {
+		assert.Equal(t, http.StatusNotFound, serveAs(h, "bob", tc.method, tc.path, tc.body).Code, tc.method+" "+tc.path)
+	}
+	var list struct {
+		Data []Batch `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(serveAs(h, "bob", http.MethodGet, "/v1/batches", "").Body.Bytes(), &list))
+	assert.Empty(t, list.Data)
+	require.NoError(t, json.Unmarshal(serve(h, http.MethodGet, "/v1/batches", "").Body.Bytes(), &list))
+	assert.Len(t, list.Data, 1)
+
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/v1/files/"+file.ID+"/content", "").Code)
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/v1/batches/"+batch.ID, "").Code)
+}
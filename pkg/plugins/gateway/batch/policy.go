@@ -0,0 +1,255 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	PauseOutsideWindow   = "outside_window"
+	PauseHighUtilization = "high_utilization"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Policy decides when the requests of batches are sent, batches are paused otherwise.
+type Policy struct {
+	// Windows are the times batches run, any time if empty.
+	Windows []Window
+	// Location is the time zone of the windows.
+	Location *time.Location
+	// MaxUtilization pauses batches while the utilization of the cluster, the mean KV cache usage of the ready pods,
+	// is at or above it. 0 disables it.
+	MaxUtilization float64
+}
+
+// Window is a daily time range, from Start to End after midnight, crossing midnight if End is before Start.
+type Window struct {
+	// Days the window starts on, every day if all false.
+	Days       [7]bool
+	Start, End time.Duration
+}
+
+// ParseWindows parses a comma separated list of windows, e.g. "22:00-06:00" or "Sat-Sun 00:00-24:00". Days are a
+// day or a range of days, the windows crossing midnight belong to the day they start.
+func ParseWindows(value string) ([]Window, error) {
+	var windows []Window
+	for _, text := range strings.Split(value, ",") {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		window := Window{}
+		days, times, ok := strings.Cut(text, " ")
+		if !ok {
+			days, times = "", text
+		}
+		if days != "" {
+			first, last, isRange := strings.Cut(strings.ToLower(days), "-")
+			if !isRange {
+				last = first
+			}
+			from, ok1 := weekdays[first]
+			to, ok2 := weekdays[last]
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("invalid days %q in window %q", days, text)
+			}
+			for day := from; ; day = (day + 1) % 7 {
+				window.Days[day] = true
+				if day == to {
+					break
+				}
+			}
+		}
+		start, end, ok := strings.Cut(strings.TrimSpace(times), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid window %q, must be HH:MM-HH:MM", text)
+		}
+		var err error
+		if window.Start, err = parseTimeOfDay(start); err != nil {
+			return nil, fmt.Errorf("invalid window %q: %w", text, err)
+		}
+		if window.End, err = parseTimeOfDay(end); err != nil {
+			return nil, fmt.Errorf("invalid window %q: %w", text, err)
+		}
+		if window.Start == window.End {
+			return nil, fmt.Errorf("invalid window %q, it is empty", text)
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	hours, minutes, ok := strings.Cut(value, ":")
+	h, err1 := strconv.Atoi(hours)
+	m, err2 := strconv.Atoi(minutes)
+	if !ok || err1 != nil || err2 != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time %q, must be HH:MM", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// contains returns true if the time, in the time zone of the windows, is within the window.
+func (w Window) contains(t time.Time) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	sinceMidnight := t.Sub(midnight)
+	startsOn := func(day time.Weekday) bool {
+		return w.Days == [7]bool{} || w.Days[day]
+	}
+	if w.Start < w.End {
+		return startsOn(t.Weekday()) && sinceMidnight >= w.Start && sinceMidnight < w.End
+	}
+	return (startsOn(t.Weekday()) && sinceMidnight >= w.Start) || (startsOn((t.Weekday()+6)%7) && sinceMidnight < w.End)
+}
+
+// LoadPolicy reads the AIBRIX_BATCH_WINDOWS, AIBRIX_BATCH_TIMEZONE and AIBRIX_BATCH_MAX_UTILIZATION environment
+// variables, invalid values fall back to running batches any time.
+func LoadPolicy() Policy {
+	policy := Policy{Location: time.UTC}
+	if value := utils.LoadEnv("AIBRIX_BATCH_WINDOWS", ""); value != "" {
+		windows, err := ParseWindows(value)
+		if err != nil {
+			klog.Infof("invalid AIBRIX_BATCH_WINDOWS: %s, falling back to default: %v", value, err)
+		} else {
+			klog.Infof("using AIBRIX_BATCH_WINDOWS env value for batch windows: %s", value)
+			policy.Windows = windows
+		}
+	}
+	if value := utils.LoadEnv("AIBRIX_BATCH_TIMEZONE", ""); value != "" {
+		location, err := time.LoadLocation(value)
+		if err != nil {
+			klog.Infof("invalid AIBRIX_BATCH_TIMEZONE: %s, falling back to default", value)
+		} else {
+			policy.Location = location
+		}
+	}
+	if value := utils.LoadEnv("AIBRIX_BATCH_MAX_UTILIZATION", ""); value != "" {
+		utilization, err := strconv.ParseFloat(value, 64)
+		if err != nil || utilization < 0 || utilization > 1 {
+			klog.Infof("invalid AIBRIX_BATCH_MAX_UTILIZATION: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_BATCH_MAX_UTILIZATION env value for batch max utilization: %v", utilization)
+			policy.MaxUtilization = utilization
+		}
+	}
+	return policy
+}
+
+// pauseReason returns why batches can't run at the time, empty if they can. The utilization is only computed if the
+// policy limits it.
+func (p Policy) pauseReason(now time.Time, utilization func() float64) string {
+	if len(p.Windows) > 0 {
+		local := now
+		if p.Location != nil {
+			local = now.In(p.Location)
+		}
+		inWindow := false
+		for _, window := range p.Windows {
+			if window.contains(local) {
+				inWindow = true
+				break
+			}
+		}
+		if !inWindow {
+			return PauseOutsideWindow
+		}
+	}
+	if p.MaxUtilization > 0 && utilization() >= p.MaxUtilization {
+		return PauseHighUtilization
+	}
+	return ""
+}
+
+// clusterUtilization is the mean utilization of the ready pods of the cluster: the KV cache usage of the busiest model
+// of the pod, or else its in-flight requests over the max pod load. A cluster without ready pods is idle.
+func (s *Scheduler) clusterUtilization(pods Pods) float64 {
+	readyPods := utils.FilterReadyPods(pods.GetPods())
+	if len(readyPods) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, pod := range readyPods {
+		usage, found := 0.0, false
+		models, _ := pods.GetModelsForPod(pod.Name)
+		for model := range models {
+			if value, err := pods.GetPodModelMetric(pod.Name, model, metrics.GPUCacheUsagePerc); err == nil {
+				usage, found = max(usage, value.GetSimpleValue()), true
+			}
+		}
+		if !found {
+			load := pods.GetPodInflightBatchItems(pod.Name) + pods.GetPodRemoteInflightBatchItems(pod.Name)
+			usage = min(float64(load)/float64(s.maxPodLoad), 1)
+		}
+		total += usage
+	}
+	return total / float64(len(readyPods))
+}
+
+// waitForPolicy waits until the policy lets the batch run, saving it as paused meanwhile, or ctx is done. The requests
+// in flight when the batch pauses complete.
+func (s *Scheduler) waitForPolicy(ctx context.Context, pods Pods, batch *Batch, mu *sync.Mutex) error {
+	for {
+		reason := s.policy.pauseReason(s.now(), func() float64 { return s.clusterUtilization(pods) })
+		mu.Lock()
+		changed := reason != batch.PauseReason
+		if changed {
+			batch.PauseReason = reason
+			batch.PausedAt = 0
+			if reason != "" {
+				batch.PausedAt = s.now().Unix()
+			}
+		}
+		var err error
+		if changed {
+			err = s.store.SaveBatch(ctx, batch)
+		}
+		mu.Unlock()
+		if err != nil && ctx.Err() == nil {
+			klog.ErrorS(err, "failed to save batch", "batch", batch.ID)
+		}
+		if reason == "" {
+			if changed {
+				klog.InfoS("resumed batch", "batch", batch.ID)
+			}
+			return nil
+		}
+		if changed {
+			batchPauses.WithLabelValues(reason).Inc()
+			klog.InfoS("paused batch", "batch", batch.ID, "reason", reason)
+		}
+		select {
+		case <-time.After(s.pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+func TestParseWindows(t *testing.T) {
+	windows, err := ParseWindows("22:00-06:00, Sat-Sun 00:00-24:00,fri 12:30-13:00")
+	require.NoError(t, err)
+	require.Len(t, windows, 3)
+	assert.Equal(t, Window{Start: 22 * time.Hour, End: 6 * time.Hour}, windows[0])
+	assert.Equal(t, Window{Days: [7]bool{time.Sunday: true, time.Saturday: true}, End: 24 * time.Hour}, windows[1])
+	assert.Equal(t, Window{Days: [7]bool{time.Friday: true}, Start: 12*time.Hour + 30*time.Minute, End: 13 * time.Hour}, windows[2])
+
+	for _, value := range []string{"22:00", "25:00-06:00", "22:00-06:60", "Someday 22:00-06:00", "10:00-10:00"} {
+		_, err := ParseWindows(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestPolicyPauseReason(t *testing.T) {
+	windows, err := ParseWindows("Mon-Fri 22:00-06:00,Sat-Sun 00:00-24:00")
+	require.NoError(t, err)
+	policy := Policy{Windows: windows, Location: time.UTC}
+	idle := func() float64 { return 0 }
+
+	testCases := []struct {
+		name   string
+		time   time.Time
+		reason string
+	}{
+		{"weekday night", time.Date(2025, 3, 5, 23, 0, 0, 0, time.UTC), ""},
+		{"weekday morning after a night window", time.Date(2025, 3, 6, 5, 59, 0, 0, time.UTC), ""},
+		{"weekday day", time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC), PauseOutsideWindow},
+		{"end of the window", time.Date(2025, 3, 5, 6, 0, 0, 0, time.UTC), PauseOutsideWindow},
+		{"weekend day", time.Date(2025, 3, 8, 12, 0, 0, 0, time.UTC), ""},
+		{"monday morning after the weekend", time.Date(2025, 3, 10, 5, 0, 0, 0, time.UTC), PauseOutsideWindow},
+		{"saturday morning after friday night", time.Date(2025, 3, 8, 5, 0, 0, 0, time.UTC), ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.reason, policy.pauseReason(tc.time, idle))
+		})
+	}
+
+	location := time.FixedZone("UTC+8", 8*3600)
+	policy.Location = location
+	assert.Equal(t, "", policy.pauseReason(time.Date(2025, 3, 5, 15, 0, 0, 0, time.UTC), idle))
+
+	policy = Policy{MaxUtilization: 0.8}
+	assert.Equal(t, "", policy.pauseReason(time.Now(), func() float64 { return 0.5 }))
+	assert.Equal(t, PauseHighUtilization, policy.pauseReason(time.Now(), func() float64 { return 0.8 }))
+	assert.Equal(t, "", Policy{}.pauseReason(time.Now(), func() float64 { return 1 }))
+}
+
+func TestClusterUtilization(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	s := newTestScheduler(store, 0)
+	assert.Equal(t, 0.0, s.clusterUtilization(newFakePods("127.0.0.1")))
+
+	pods := newFakePods("127.0.0.1", "p1", "p2")
+	pods.setKVUsage("p1", 0.9)
+	pods.setLoad("p2", 1)
+	assert.InDelta(t, 0.7, s.clusterUtilization(pods), 1e-9)
+}
+
+func TestSchedulerPausesBatch(t *testing.T) {
+	_, host, port := newTestEngine(t)
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	s := newTestScheduler(store, port)
+	s.policy = Policy{MaxUtilization: 0.5}
+	pods := newFakePods(host, "p1")
+	pods.setKVUsage("p1", 0.9)
+
+	batch := createTestBatch(t, store, requestLine("a", "m", "hello"))
+	done := make(chan struct{})
+	go func() {
+		s.run(context.Background(), pods, batch.ID)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		paused, err := store.GetBatch(context.Background(), batch.ID)
+		return err == nil && paused.Status == StatusInProgress && paused.PauseReason == PauseHighUtilization && paused.PausedAt != 0
+	}, time.Second, 5*time.Millisecond)
+
+	pods.setKVUsage("p1", 0.1)
+	<-done
+	batch, err := store.GetBatch(context.Background(), batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, batch.Status)
+	assert.Empty(t, batch.PauseReason)
+	assert.Zero(t, batch.PausedAt)
+	assert.Equal(t, RequestCounts{Total: 1, Completed: 1}, batch.RequestCounts)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// S3Config locates the bucket of the S3 file store, any S3 compatible service such as MinIO works.
+type S3Config struct {
+	// Endpoint is the URL of the service, objects are addressed path style under it.
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is prepended to the keys of the files, e.g. batches/.
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// LoadS3Config reads the AIBRIX_BATCH_S3_ENDPOINT, AIBRIX_BATCH_S3_REGION, AIBRIX_BATCH_S3_BUCKET and
+// AIBRIX_BATCH_S3_PREFIX environment variables, and the standard AWS credential variables.
+func LoadS3Config() S3Config {
+	region := utils.LoadEnv("AIBRIX_BATCH_S3_REGION", "us-east-1")
+	return S3Config{
+		Endpoint:        utils.LoadEnv("AIBRIX_BATCH_S3_ENDPOINT", fmt.Sprintf("https://s3.%s.amazonaws.com", region)),
+		Region:          region,
+		Bucket:          utils.LoadEnv("AIBRIX_BATCH_S3_BUCKET", ""),
+		Prefix:          utils.LoadEnv("AIBRIX_BATCH_S3_PREFIX", ""),
+		AccessKeyID:     utils.LoadEnv("AWS_ACCESS_KEY_ID", ""),
+		SecretAccessKey: utils.LoadEnv("AWS_SECRET_ACCESS_KEY", ""),
+		SessionToken:    utils.LoadEnv("AWS_SESSION_TOKEN", ""),
+	}
+}
+
+// s3FileStore keeps the contents of the files as objects of an S3 bucket, signed with AWS signature version 4.
+type s3FileStore struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3FileStore creates the file store of the bucket.
+func NewS3FileStore(config S3Config) (FileStore, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 batch file store requires AIBRIX_BATCH_S3_BUCKET")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 batch file store requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	if _, err := url.Parse(config.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid AIBRIX_BATCH_S3_ENDPOINT %q: %w", config.Endpoint, err)
+	}
+	return &s3FileStore{config: config, client: &http.Client{Timeout: 5 * time.Minute}, now: time.Now}, nil
+}
+
+func (s *s3FileStore) Put(ctx context.Context, id string, content []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, id, content)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkS3Response(resp, http.StatusOK)
+}
+
+func (s *s3FileStore) Get(ctx context.Context, id string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if err := checkS3Response(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (s *s3FileStore) Delete(ctx context.Context, id string) error {
+	resp, err := s.do(ctx, http.MethodDelete, id, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkS3Response(resp, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+func (s *s3FileStore) do(ctx context.Context, method, id string, content []byte) (*http.Response, error) {
+	objectURL := strings.TrimSuffix(s.config.Endpoint, "/") + "/" + url.PathEscape(s.config.Bucket) + "/" +
+		url.PathEscape(s.config.Prefix+id)
+	req, err := http.NewRequestWithContext(ctx, method, objectURL, bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+	signS3Request(req, content, s.config, s.now())
+	return s.client.Do(req)
+}
+
+func checkS3Response(resp *http.Response, expected ...int) error {
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 %s %s returned %d: %s", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, message)
+}
+
+// signS3Request signs the request with AWS signature version 4, over the host and all the headers of the request.
+func signS3Request(req *http.Request, payload []byte, config S3Config, now time.Time) {
+	payloadHash := sha256.Sum256(payload)
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), req.URL.RawQuery, canonicalHeaders.String(), signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	scope := amzDate[:8] + "/" + config.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+config.SecretAccessKey), amzDate[:8])
+	key = hmacSHA256(key, config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
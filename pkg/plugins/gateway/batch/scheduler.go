@@ -0,0 +1,660 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultMaxConcurrency = 8
+	defaultMaxPodLoad     = 4
+	defaultMaxLentPodLoad = 32
+	defaultMaxRequests    = 50000
+	// maxValidationErrors bounds the errors reported for an invalid input file.
+	maxValidationErrors = 100
+
+	enginePort   = 8000
+	leaseTTL     = 30 * time.Second
+	pollInterval = time.Second
+	queueSize    = 1024
+
+	resultCompleted = "completed"
+	resultFailed    = "failed"
+)
+
+var (
+	maxConcurrency = getIntEnv("AIBRIX_BATCH_MAX_CONCURRENCY", defaultMaxConcurrency)
+	maxPodLoad     = getIntEnv("AIBRIX_BATCH_MAX_POD_LOAD", defaultMaxPodLoad)
+	maxLentPodLoad = getIntEnv("AIBRIX_BATCH_MAX_LENT_POD_LOAD", defaultMaxLentPodLoad)
+	maxRequests    = getIntEnv("AIBRIX_BATCH_MAX_REQUESTS", defaultMaxRequests)
+
+	batchRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_batch_requests_total",
+		Help: "Requests of batches sent to the engines, by model and result, completed or failed.",
+	}, []string{"model", "result"})
+	batchesDone = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_batches_total",
+		Help: "Batches run to a final status, completed, failed, expired or cancelled.",
+	}, []string{"status"})
+	batchPauses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_batch_pauses_total",
+		Help: "Pauses of running batches by the batch policy, by reason, outside_window or high_utilization.",
+	}, []string{"reason"})
+
+	errUnknownModel = errors.New("model does not exist")
+)
+
+func init() {
+	prometheus.MustRegister(batchRequests, batchesDone, batchPauses)
+}
+
+func getIntEnv(name string, defaultValue int) int {
+	value := utils.LoadEnv(name, "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid %s: %s, falling back to default", name, value)
+		} else {
+			klog.Infof("using %s env value: %d", name, intValue)
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// Pods are the pods the requests of the batches run on and the load the gateway routed to them, the gateway cache.
+type Pods interface {
+	GetReadyPodsForModel(model string) (map[string]*v1.Pod, error)
+	GetLentPodsForModel(model string) map[string]*v1.Pod
+	GetPodInflightBatchItems(podName string) int32
+	GetPodRemoteInflightBatchItems(podName string) int32
+	GetPods() map[string]*v1.Pod
+	GetModelsForPod(podName string) (map[string]struct{}, error)
+	GetPodModelMetric(podName, modelName string, metricName string) (metrics.MetricValue, error)
+	AddPodRequest(podName string)
+	DonePodRequest(podName string)
+	AddPodBatchItems(podName string, items int32)
+	DonePodBatchItems(podName string, items int32)
+}
+
+// Limiter applies the rate limits of the users to the requests of their batches, the gateway counts them with their
+// online requests.
+type Limiter interface {
+	// Allow counts a request of the user and returns true if the user is within its limits, false otherwise.
+	Allow(ctx context.Context, user string) (bool, error)
+	// AddTokens counts the tokens of a request of the user.
+	AddTokens(ctx context.Context, user string, tokens int64) error
+}
+
+// Scheduler runs the batches one at a time, oldest first, as a low priority workload: a request is only sent to a pod
+// of its model with fewer in-flight requests than AIBRIX_BATCH_MAX_POD_LOAD, online requests included, and at most
+// AIBRIX_BATCH_MAX_CONCURRENCY requests are in flight per gateway replica. The pods of the model lent to batch
+// workloads by the PodAutoscaler serve no online requests and are used first, up to AIBRIX_BATCH_MAX_LENT_POD_LOAD
+// in-flight requests. The policy pauses the batches outside of their windows or while the cluster is busy, and the
+// requests of a batch wait for its owner to be within its rate limits.
+//
+// A replica holds a lease on the batch it runs. Batches whose replica stopped are picked up by another replica and
+// run again from the start, their requests may be sent twice.
+type Scheduler struct {
+	store          *Store
+	client         *http.Client
+	owner          string
+	port           int
+	queue          chan string
+	maxConcurrency int
+	maxPodLoad     int32
+	maxLentPodLoad int32
+	policy         Policy
+	limiter        Limiter
+	pollInterval   time.Duration
+	now            func() time.Time
+}
+
+// NewScheduler creates the scheduler of the batches of the store, the requests are not rate limited if limiter is nil.
+func NewScheduler(store *Store, limiter Limiter) *Scheduler {
+	owner, err := os.Hostname()
+	if err != nil || owner == "" {
+		owner = uuid.New().String()
+	}
+	return &Scheduler{
+		store:          store,
+		client:         &http.Client{},
+		owner:          owner,
+		port:           enginePort,
+		queue:          make(chan string, queueSize),
+		maxConcurrency: maxConcurrency,
+		maxPodLoad:     int32(maxPodLoad),
+		maxLentPodLoad: int32(maxLentPodLoad),
+		policy:         LoadPolicy(),
+		limiter:        limiter,
+		pollInterval:   pollInterval,
+		now:            time.Now,
+	}
+}
+
+// Submit queues the batch. Batches not queued, e.g. when the queue is full, are picked up by the scans of the
+// pending batches.
+func (s *Scheduler) Submit(id string) {
+	select {
+	case s.queue <- id:
+	default:
+		klog.InfoS("batch queue is full, the batch waits for the next scan", "batch", id)
+	}
+}
+
+// Run runs the queued batches on the pods until ctx is done, and scans for the pending batches no replica runs.
+func (s *Scheduler) Run(ctx context.Context, pods Pods) {
+	ticker := time.NewTicker(leaseTTL)
+	defer ticker.Stop()
+	s.scanPending(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.run(ctx, pods, id)
+		case <-ticker.C:
+			s.scanPending(ctx)
+		}
+	}
+}
+
+// scanPending queues the batches not in a final status whose lease expired, oldest first.
+func (s *Scheduler) scanPending(ctx context.Context) {
+	batches, err := s.store.scanBatches(ctx)
+	if err != nil {
+		klog.ErrorS(err, "failed to scan pending batches")
+		return
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].CreatedAt < batches[j].CreatedAt })
+	for _, batch := range batches {
+		if batch.Done() {
+			continue
+		}
+		if leased, err := s.store.leased(ctx, batch.ID); err != nil || leased {
+			continue
+		}
+		s.Submit(batch.ID)
+	}
+}
+
+// parsedLine is a validated request of a batch.
+type parsedLine struct {
+	RequestLine
+	model string
+}
+
+func (s *Scheduler) run(ctx context.Context, pods Pods, id string) {
+	if ok, err := s.store.acquireLease(ctx, id, s.owner, leaseTTL); err != nil || !ok {
+		if err != nil {
+			klog.ErrorS(err, "failed to acquire the batch lease", "batch", id)
+		}
+		return
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.renewLease(runCtx, cancel, id)
+	defer func() {
+		if err := s.store.releaseLease(context.Background(), id, s.owner); err != nil {
+			klog.ErrorS(err, "failed to release the batch lease", "batch", id)
+		}
+	}()
+
+	batch, err := s.store.GetBatch(runCtx, id)
+	if err != nil {
+		klog.ErrorS(err, "failed to load batch", "batch", id)
+		return
+	}
+	if batch.Done() {
+		return
+	}
+	if cancelled, err := s.store.cancelRequested(runCtx, id); err == nil && cancelled {
+		s.finish(runCtx, batch, StatusCancelled, nil, nil)
+		return
+	}
+
+	lines, validationErrors := s.validate(runCtx, batch)
+	if len(validationErrors) > 0 {
+		batch.Errors = &Errors{Object: "list", Data: validationErrors}
+		s.finish(runCtx, batch, StatusFailed, nil, nil)
+		return
+	}
+	batch.Status = StatusInProgress
+	if batch.InProgressAt == 0 {
+		batch.InProgressAt = s.now().Unix()
+	}
+	batch.RequestCounts = RequestCounts{Total: len(lines)}
+	if err := s.store.SaveBatch(runCtx, batch); err != nil {
+		klog.ErrorS(err, "failed to save batch", "batch", id)
+		return
+	}
+	klog.InfoS("running batch", "batch", id, "requests", len(lines))
+
+	results, status := s.execute(runCtx, pods, batch, lines)
+	if runCtx.Err() != nil {
+		// the replica is stopping or lost the lease, the batch runs again on the next replica picking it up.
+		return
+	}
+	s.finish(runCtx, batch, status, lines, results)
+}
+
+// renewLease keeps the lease of the batch until ctx is done, it cancels the run if another replica took the lease.
+func (s *Scheduler) renewLease(ctx context.Context, cancel context.CancelFunc, id string) {
+	ticker := time.NewTicker(leaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		ok, err := s.store.acquireLease(ctx, id, s.owner, leaseTTL)
+		if err != nil {
+			klog.ErrorS(err, "failed to renew the batch lease", "batch", id)
+			continue
+		}
+		if !ok {
+			klog.InfoS("batch lease taken by another replica, stopping the batch", "batch", id)
+			cancel()
+			return
+		}
+	}
+}
+
+// validate parses the input file of the batch, requests must be POST requests to the endpoint of the batch, with a
+// unique custom_id and a model.
+func (s *Scheduler) validate(ctx context.Context, batch *Batch) ([]parsedLine, []Error) {
+	content, err := s.store.GetFileContent(ctx, batch.InputFileID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, []Error{{Code: "file_not_found", Message: fmt.Sprintf("input file %s does not exist", batch.InputFileID)}}
+	}
+	if err != nil {
+		return nil, []Error{{Code: "file_unavailable", Message: err.Error()}}
+	}
+
+	var lines []parsedLine
+	var validationErrors []Error
+	customIDs := map[string]bool{}
+	for i, text := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		lineError := func(code, param, message string) {
+			validationErrors = append(validationErrors, Error{Code: code, Message: message, Param: param, Line: i + 1})
+		}
+		line := parsedLine{}
+		var body map[string]interface{}
+		switch {
+		case json.Unmarshal([]byte(text), &line.RequestLine) != nil:
+			lineError("invalid_json_line", "", "line is not a JSON request")
+		case line.CustomID == "":
+			lineError("missing_required_parameter", "custom_id", "custom_id is required")
+		case customIDs[line.CustomID]:
+			lineError("duplicate_custom_id", "custom_id", fmt.Sprintf("custom_id %s is not unique", line.CustomID))
+		case line.Method != http.MethodPost:
+			lineError("invalid_method", "method", "method must be POST")
+		case line.URL != batch.Endpoint:
+			lineError("mismatched_endpoint", "url", fmt.Sprintf("url must be the endpoint of the batch, %s", batch.Endpoint))
+		case json.Unmarshal(line.Body, &body) != nil || body == nil:
+			lineError("invalid_body", "body", "body must be a JSON object")
+		default:
+			line.model, _ = body["model"].(string)
+			if line.model == "" {
+				lineError("missing_required_parameter", "body.model", "body.model is required")
+				break
+			}
+			// the responses of batches are not streamed.
+			delete(body, "stream")
+			delete(body, "stream_options")
+			line.Body, _ = json.Marshal(body)
+			customIDs[line.CustomID] = true
+			lines = append(lines, line)
+		}
+		if len(validationErrors) >= maxValidationErrors {
+			break
+		}
+	}
+	if len(validationErrors) > 0 {
+		return nil, validationErrors
+	}
+	if len(lines) == 0 {
+		return nil, []Error{{Code: "empty_file", Message: "input file has no request"}}
+	}
+	if len(lines) > maxRequests {
+		return nil, []Error{{Code: "too_many_requests", Message: fmt.Sprintf("input file has %d requests, more than the max of %d", len(lines), maxRequests)}}
+	}
+	return lines, nil
+}
+
+// execute sends the requests of the batch, waiting for pods with spare capacity. It stops dispatching when the batch
+// is cancelled or expires, and returns the results of the requests sent, nil for the others, with the final status.
+func (s *Scheduler) execute(ctx context.Context, pods Pods, batch *Batch, lines []parsedLine) ([]*ResponseLine, string) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make([]*ResponseLine, len(lines))
+		slots   = make(chan struct{}, s.maxConcurrency)
+		status  = StatusCompleted
+	)
+	dispatchCtx, stopDispatch := context.WithCancel(ctx)
+	defer stopDispatch()
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		if stop := s.watch(dispatchCtx, batch, &mu); stop != "" {
+			mu.Lock()
+			status = stop
+			mu.Unlock()
+			stopDispatch()
+		}
+	}()
+
+	complete := func(i int, result *ResponseLine) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[i] = result
+		outcome := resultFailed
+		if succeeded(result) {
+			outcome = resultCompleted
+			batch.RequestCounts.Completed++
+		} else {
+			batch.RequestCounts.Failed++
+		}
+		batchRequests.WithLabelValues(lines[i].model, outcome).Inc()
+	}
+	for i := range lines {
+		if err := s.waitForPolicy(dispatchCtx, pods, batch, &mu); err != nil {
+			break
+		}
+		if err := s.waitForLimits(dispatchCtx, batch); err != nil {
+			break
+		}
+		name, pod, err := s.acquirePod(dispatchCtx, pods, lines[i].model, slots)
+		if errors.Is(err, errUnknownModel) {
+			complete(i, &ResponseLine{ID: newID("batch_req_"), CustomID: lines[i].CustomID,
+				Error: &LineError{Code: "model_not_found", Message: fmt.Sprintf("model %s does not exist", lines[i].model)}})
+			continue
+		}
+		if err != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			result := s.send(ctx, pods, name, pod, lines[i])
+			s.addTokens(ctx, batch, result)
+			complete(i, result)
+		}(i)
+	}
+	wg.Wait()
+	stopDispatch()
+	<-watched
+	return results, status
+}
+
+// waitForLimits waits until the owner of the batch is within its rate limits and counts the next request.
+func (s *Scheduler) waitForLimits(ctx context.Context, batch *Batch) error {
+	if s.limiter == nil || batch.Owner == "" {
+		return nil
+	}
+	for {
+		allowed, err := s.limiter.Allow(ctx, batch.Owner)
+		if err != nil && ctx.Err() == nil {
+			klog.ErrorS(err, "failed to check the rate limits of the batch owner", "batch", batch.ID, "owner", batch.Owner)
+		}
+		if allowed {
+			return nil
+		}
+		select {
+		case <-time.After(s.pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// addTokens counts the tokens of the response against the rate limits of the owner of the batch.
+func (s *Scheduler) addTokens(ctx context.Context, batch *Batch, result *ResponseLine) {
+	if s.limiter == nil || batch.Owner == "" || result.Response == nil {
+		return
+	}
+	var body struct {
+		Usage struct {
+			TotalTokens int64 `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(result.Response.Body, &body); err != nil || body.Usage.TotalTokens == 0 {
+		return
+	}
+	if err := s.limiter.AddTokens(ctx, batch.Owner, body.Usage.TotalTokens); err != nil {
+		klog.ErrorS(err, "failed to count the tokens of the batch owner", "batch", batch.ID, "owner", batch.Owner)
+	}
+}
+
+// watch saves the progress of the batch until ctx is done, and returns the status stopping it when it is cancelled or
+// expires.
+func (s *Scheduler) watch(ctx context.Context, batch *Batch, mu *sync.Mutex) string {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ""
+		case <-ticker.C:
+		}
+		if s.now().Unix() >= batch.ExpiresAt {
+			return StatusExpired
+		}
+		if cancelled, err := s.store.cancelRequested(ctx, batch.ID); err == nil && cancelled {
+			mu.Lock()
+			batch.Status = StatusCancelling
+			batch.CancellingAt = s.now().Unix()
+			err := s.store.SaveBatch(ctx, batch)
+			mu.Unlock()
+			if err != nil {
+				klog.ErrorS(err, "failed to save batch", "batch", batch.ID)
+			}
+			return StatusCancelled
+		}
+		mu.Lock()
+		err := s.store.SaveBatch(ctx, batch)
+		mu.Unlock()
+		if err != nil && ctx.Err() == nil {
+			klog.ErrorS(err, "failed to save batch progress", "batch", batch.ID)
+		}
+	}
+}
+
+// acquirePod takes a slot and returns the least loaded pod of the model lent to batch workloads whose load is below
+// the max lent pod load, or else the least loaded pod of the model whose load is below the max pod load, waiting until
+// one is, or errUnknownModel if the model doesn't exist.
+func (s *Scheduler) acquirePod(ctx context.Context, pods Pods, model string, slots chan struct{}) (string, *v1.Pod, error) {
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return "", nil, ctx.Err()
+	}
+	for {
+		modelPods, err := pods.GetReadyPodsForModel(model)
+		if err != nil {
+			<-slots
+			return "", nil, errUnknownModel
+		}
+		if name, pod := leastLoadedPod(pods, pods.GetLentPodsForModel(model), s.maxLentPodLoad); pod != nil {
+			return name, pod, nil
+		}
+		if name, pod := leastLoadedPod(pods, modelPods, s.maxPodLoad); pod != nil {
+			return name, pod, nil
+		}
+		select {
+		case <-time.After(s.pollInterval):
+		case <-ctx.Done():
+			<-slots
+			return "", nil, ctx.Err()
+		}
+	}
+}
+
+// leastLoadedPod returns the pod with the fewest in-flight requests below the max load, nil if there is none.
+func leastLoadedPod(pods Pods, candidates map[string]*v1.Pod, maxLoad int32) (string, *v1.Pod) {
+	var target *v1.Pod
+	var targetName string
+	targetLoad := maxLoad
+	for name, pod := range candidates {
+		if pod.Status.PodIP == "" {
+			continue
+		}
+		load := pods.GetPodInflightBatchItems(name) + pods.GetPodRemoteInflightBatchItems(name)
+		if load < targetLoad || (load == targetLoad && target != nil && name < targetName) {
+			target, targetName, targetLoad = pod, name, load
+		}
+	}
+	return targetName, target
+}
+
+// send sends the request to the pod, counted as in-flight for the routing of online requests meanwhile.
+func (s *Scheduler) send(ctx context.Context, pods Pods, name string, pod *v1.Pod, line parsedLine) *ResponseLine {
+	pods.AddPodRequest(name)
+	pods.AddPodBatchItems(name, 1)
+	defer func() {
+		pods.DonePodRequest(name)
+		pods.DonePodBatchItems(name, 1)
+	}()
+
+	result := &ResponseLine{ID: newID("batch_req_"), CustomID: line.CustomID}
+	url := fmt.Sprintf("http://%s:%d%s", pod.Status.PodIP, s.port, line.URL)
+	req, err := http.NewRequestWithContext(ctx, line.Method, url, bytes.NewReader(line.Body))
+	if err != nil {
+		result.Error = &LineError{Code: "invalid_request", Message: err.Error()}
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-request-id", result.ID)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		result.Error = &LineError{Code: "engine_unavailable", Message: err.Error()}
+		return result
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = &LineError{Code: "engine_unavailable", Message: err.Error()}
+		return result
+	}
+	if !json.Valid(body) {
+		body, _ = json.Marshal(string(body))
+	}
+	result.Response = &LineResponse{StatusCode: resp.StatusCode, RequestID: result.ID, Body: body}
+	return result
+}
+
+func succeeded(result *ResponseLine) bool {
+	return result.Response != nil && result.Response.StatusCode >= 200 && result.Response.StatusCode < 300
+}
+
+// finish writes the output and error files of the batch and saves its final status. The requests of expired batches
+// that were not sent are reported in the error file.
+func (s *Scheduler) finish(ctx context.Context, batch *Batch, status string, lines []parsedLine, results []*ResponseLine) {
+	now := s.now().Unix()
+	batch.PausedAt, batch.PauseReason = 0, ""
+	if len(lines) > 0 {
+		batch.Status = StatusFinalizing
+		batch.FinalizingAt = now
+		if err := s.store.SaveBatch(ctx, batch); err != nil {
+			klog.ErrorS(err, "failed to save batch", "batch", batch.ID)
+		}
+	}
+
+	var output, errorOutput bytes.Buffer
+	for i, result := range results {
+		if result == nil {
+			if status != StatusExpired {
+				continue
+			}
+			result = &ResponseLine{ID: newID("batch_req_"), CustomID: lines[i].CustomID,
+				Error: &LineError{Code: "batch_expired", Message: "request was not sent before the batch expired"}}
+			batch.RequestCounts.Failed++
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			continue
+		}
+		if succeeded(result) {
+			output.Write(append(data, '\n'))
+		} else {
+			errorOutput.Write(append(data, '\n'))
+		}
+	}
+	if output.Len() > 0 {
+		if file, err := s.store.CreateFile(ctx, batch.Owner, batch.ID+"_output.jsonl", PurposeBatchOutput, output.Bytes()); err != nil {
+			klog.ErrorS(err, "failed to write batch output file", "batch", batch.ID)
+		} else {
+			batch.OutputFileID = file.ID
+		}
+	}
+	if errorOutput.Len() > 0 {
+		if file, err := s.store.CreateFile(ctx, batch.Owner, batch.ID+"_error.jsonl", PurposeBatchOutput, errorOutput.Bytes()); err != nil {
+			klog.ErrorS(err, "failed to write batch error file", "batch", batch.ID)
+		} else {
+			batch.ErrorFileID = file.ID
+		}
+	}
+
+	now = s.now().Unix()
+	batch.Status = status
+	switch status {
+	case StatusCompleted:
+		batch.CompletedAt = now
+	case StatusFailed:
+		batch.FailedAt = now
+	case StatusExpired:
+		batch.ExpiredAt = now
+	case StatusCancelled:
+		if batch.CancellingAt == 0 {
+			batch.CancellingAt = now
+		}
+		batch.CancelledAt = now
+	}
+	if err := s.store.SaveBatch(ctx, batch); err != nil {
+		klog.ErrorS(err, "failed to save batch", "batch", batch.ID)
+		return
+	}
+	batchesDone.WithLabelValues(status).Inc()
+	klog.InfoS("batch done", "batch", batch.ID, "status", status, "completed", batch.RequestCounts.Completed, "failed", batch.RequestCounts.Failed)
+}
@@ -0,0 +1,464 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+// fakePods serves the pods of the model m, with the loads and KV cache usages set by the test.
+type fakePods struct {
+	mu       sync.Mutex
+	pods     map[string]*v1.Pod
+	lent     map[string]*v1.Pod
+	load     map[string]int32
+	kvUsage  map[string]float64
+	requests map[string]int
+}
+
+func newFakePods(ip string, names ...string) *fakePods {
+	p := &fakePods{pods: map[string]*v1.Pod{}, load: map[string]int32{}, kvUsage: map[string]float64{}, requests: map[string]int{}}
+	for _, name := range names {
+		p.pods[name] = &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: v1.PodStatus{PodIP: ip,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}}}
+	}
+	return p
+}
+
+func (p *fakePods) GetLentPodsForModel(model string) map[string]*v1.Pod {
+	if model != "m" {
+		return nil
+	}
+	return p.lent
+}
+
+func (p *fakePods) GetPods() map[string]*v1.Pod {
+	return p.pods
+}
+
+func (p *fakePods) GetModelsForPod(string) (map[string]struct{}, error) {
+	return map[string]struct{}{"m": {}}, nil
+}
+
+func (p *fakePods) GetPodModelMetric(name, model string, metricName string) (metrics.MetricValue, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	usage, ok := p.kvUsage[name]
+	if !ok || model != "m" || metricName != metrics.GPUCacheUsagePerc {
+		return nil, fmt.Errorf("no metric %s for pod %s", metricName, name)
+	}
+	return &metrics.SimpleMetricValue{Value: usage}, nil
+}
+
+func (p *fakePods) GetReadyPodsForModel(model string) (map[string]*v1.Pod, error) {
+	if model != "m" {
+		return nil, errUnknownModel
+	}
+	return p.pods, nil
+}
+
+func (p *fakePods) GetPodInflightBatchItems(name string) int32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.load[name]
+}
+
+func (p *fakePods) GetPodRemoteInflightBatchItems(string) int32 { return 0 }
+
+func (p *fakePods) AddPodRequest(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests[name]++
+}
+
+func (p *fakePods) DonePodRequest(string) {}
+
+func (p *fakePods) AddPodBatchItems(name string, items int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.load[name] += items
+}
+
+func (p *fakePods) DonePodBatchItems(name string, items int32) {
+	p.AddPodBatchItems(name, -items)
+}
+
+func (p *fakePods) setLoad(name string, load int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.load[name] = load
+}
+
+func (p *fakePods) setKVUsage(name string, usage float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.kvUsage[name] = usage
+}
+
+// newTestEngine echoes the custom prompt of completions, a token per byte, and fails prompts starting with fail.
+func newTestEngine(t *testing.T) (*httptest.Server, string, int) {
+	engine := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		if _, ok := body["stream"]; ok {
+			http.Error(w, "stream", http.StatusBadRequest)
+			return
+		}
+		prompt, _ := body["prompt"].(string)
+		if strings.HasPrefix(prompt, "fail") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"bad prompt"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"path": r.URL.Path, "text": prompt,
+			"usage": map[string]interface{}{"total_tokens": len(prompt)}})
+	}))
+	t.Cleanup(engine.Close)
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(engine.URL, "http://"))
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return engine, host, portNumber
+}
+
+func newTestScheduler(store *Store, port int) *Scheduler {
+	s := NewScheduler(store, nil)
+	s.port = port
+	s.pollInterval = 10 * time.Millisecond
+	s.maxPodLoad = 2
+	return s
+}
+
+func createTestBatch(t *testing.T, store *Store, content string) *Batch {
+	file, err := store.CreateFile(context.Background(), "alice", "input.jsonl", PurposeBatch, []byte(content))
+	require.NoError(t, err)
+	batch := &Batch{
+		ID: newID("batch_"), Object: "batch", Endpoint: "/v1/completions", InputFileID: file.ID, CompletionWindow: CompletionWindow,
+		Status: StatusValidating, CreatedAt: time.Now().Unix(), ExpiresAt: time.Now().Add(time.Hour).Unix(), Owner: "alice",
+	}
+	require.NoError(t, store.SaveBatch(context.Background(), batch))
+	return batch
+}
+
+func requestLine(customID, model, prompt string) string {
+	body, _ := json.Marshal(map[string]interface{}{"model": model, "prompt": prompt, "stream": true})
+	line, _ := json.Marshal(RequestLine{CustomID: customID, Method: "POST", URL: "/v1/completions", Body: body})
+	return string(line)
+}
+
+func readLines(t *testing.T, store *Store, id string) map[string]ResponseLine {
+	content, err := store.GetFileContent(context.Background(), id)
+	require.NoError(t, err)
+	lines := map[string]ResponseLine{}
+	for _, text := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var line ResponseLine
+		require.NoError(t, json.Unmarshal([]byte(text), &line))
+		lines[line.CustomID] = line
+	}
+	return lines
+}
+
+func TestSchedulerRunsBatch(t *testing.T) {
+	_, host, port := newTestEngine(t)
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	pods := newFakePods(host, "p1", "p2")
+	s := newTestScheduler(store, port)
+
+	batch := createTestBatch(t, store, strings.Join([]string{
+		requestLine("a", "m", "hello"),
+		"",
+		requestLine("b", "m", "fail me"),
+		requestLine("c", "unknown", "hello"),
+		requestLine("d", "m", "world"),
+	}, "\n"))
+	s.run(context.Background(), pods, batch.ID)
+
+	batch, err := store.GetBatch(context.Background(), batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, batch.Status)
+	assert.Equal(t, RequestCounts{Total: 4, Completed: 2, Failed: 2}, batch.RequestCounts)
+	assert.NotZero(t, batch.InProgressAt)
+	assert.NotZero(t, batch.FinalizingAt)
+	assert.NotZero(t, batch.CompletedAt)
+
+	output := readLines(t, store, batch.OutputFileID)
+	assert.Len(t, output, 2)
+	assert.Equal(t, http.StatusOK, output["a"].Response.StatusCode)
+	assert.JSONEq(t, `{"path":"/v1/completions","text":"hello","usage":{"total_tokens":5}}`, string(output["a"].Response.Body))
+	assert.Equal(t, output["a"].ID, output["a"].Response.RequestID)
+	assert.Nil(t, output["a"].Error)
+
+	errorOutput := readLines(t, store, batch.ErrorFileID)
+	assert.Len(t, errorOutput, 2)
+	assert.Equal(t, http.StatusBadRequest, errorOutput["b"].Response.StatusCode)
+	assert.JSONEq(t, `{"error":"bad prompt"}`, string(errorOutput["b"].Response.Body))
+	assert.Equal(t, "model_not_found", errorOutput["c"].Error.Code)
+
+	// the requests are counted on the pods, and the lease is released.
+	assert.Equal(t, 3, pods.requests["p1"]+pods.requests["p2"])
+	assert.Equal(t, int32(0), pods.GetPodInflightBatchItems("p1")+pods.GetPodInflightBatchItems("p2"))
+	leased, err := store.leased(context.Background(), batch.ID)
+	assert.NoError(t, err)
+	assert.False(t, leased)
+}
+
+func TestSchedulerFailsInvalidBatch(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	s := newTestScheduler(store, 0)
+
+	chat, _ := json.Marshal(RequestLine{CustomID: "c", Method: "POST", URL: "/v1/chat/completions", Body: json.RawMessage(`{"model":"m"}`)})
+	get, _ := json.Marshal(RequestLine{CustomID: "g", Method: "GET", URL: "/v1/completions", Body: json.RawMessage(`{"model":"m"}`)})
+	batch := createTestBatch(t, store, strings.Join([]string{
+		requestLine("a", "m", "hello"),
+		requestLine("a", "m", "hello"),
+		"not json",
+		string(chat),
+		string(get),
+		requestLine("", "m", "hello"),
+		requestLine("b", "", "hello"),
+	}, "\n"))
+	s.run(context.Background(), newFakePods("127.0.0.1", "p1"), batch.ID)
+
+	batch, err := store.GetBatch(context.Background(), batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, batch.Status)
+	assert.NotZero(t, batch.FailedAt)
+	require.NotNil(t, batch.Errors)
+	var codes []string
+	var lines []int
+	for _, e := range batch.Errors.Data {
+		codes = append(codes, e.Code)
+		lines = append(lines, e.Line)
+	}
+	assert.Equal(t, []string{"duplicate_custom_id", "invalid_json_line", "mismatched_endpoint", "invalid_method",
+		"missing_required_parameter", "missing_required_parameter"}, codes)
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7}, lines)
+
+	empty := createTestBatch(t, store, "\n")
+	s.run(context.Background(), newFakePods("127.0.0.1", "p1"), empty.ID)
+	empty, err = store.GetBatch(context.Background(), empty.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, empty.Status)
+	assert.Equal(t, "empty_file", empty.Errors.Data[0].Code)
+}
+
+// fakeLimiter allows a number of requests and counts the tokens, by user.
+type fakeLimiter struct {
+	mu      sync.Mutex
+	allowed map[string]int
+	tokens  map[string]int64
+}
+
+func (l *fakeLimiter) Allow(_ context.Context, user string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.allowed[user] == 0 {
+		return false, nil
+	}
+	l.allowed[user]--
+	return true, nil
+}
+
+func (l *fakeLimiter) AddTokens(_ context.Context, user string, tokens int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens[user] += tokens
+	return nil
+}
+
+func (l *fakeLimiter) allow(user string, requests int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.allowed[user] += requests
+}
+
+func TestSchedulerAppliesRateLimits(t *testing.T) {
+	_, host, port := newTestEngine(t)
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	pods := newFakePods(host, "p1")
+	s := newTestScheduler(store, port)
+	limiter := &fakeLimiter{allowed: map[string]int{"alice": 1}, tokens: map[string]int64{}}
+	s.limiter = limiter
+
+	batch := createTestBatch(t, store, strings.Join([]string{
+		requestLine("a", "m", "hello"),
+		requestLine("b", "m", "world!"),
+	}, "\n"))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.run(context.Background(), pods, batch.ID)
+	}()
+
+	// the second request waits for alice to be within her limits.
+	select {
+	case <-done:
+		t.Fatal("ran a request over the rate limits")
+	case <-time.After(50 * time.Millisecond):
+	}
+	limiter.allow("alice", 1)
+	<-done
+
+	batch, err := store.GetBatch(context.Background(), batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, RequestCounts{Total: 2, Completed: 2}, batch.RequestCounts)
+	assert.Equal(t, int64(11), limiter.tokens["alice"])
+
+	output, err := store.GetFile(context.Background(), batch.OutputFileID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", output.Owner)
+}
+
+func TestSchedulerWaitsForSpareCapacity(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	s := newTestScheduler(store, 0)
+	pods := newFakePods("127.0.0.1", "p1", "p2")
+	pods.setLoad("p1", 2)
+	pods.setLoad("p2", 1)
+	slots := make(chan struct{}, 1)
+
+	name, _, err := s.acquirePod(context.Background(), pods, "m", slots)
+	assert.NoError(t, err)
+	assert.Equal(t, "p2", name)
+	<-slots
+
+	pods.setLoad("p2", 3)
+	acquired := make(chan string)
+	go func() {
+		name, _, _ := s.acquirePod(context.Background(), pods, "m", slots)
+		acquired <- name
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired a saturated pod")
+	case <-time.After(50 * time.Millisecond):
+	}
+	pods.setLoad("p1", 1)
+	assert.Equal(t, "p1", <-acquired)
+
+	_, _, err = s.acquirePod(context.Background(), pods, "unknown", make(chan struct{}, 1))
+	assert.ErrorIs(t, err, errUnknownModel)
+}
+
+func TestSchedulerPrefersLentPods(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	s := newTestScheduler(store, 0)
+	s.maxLentPodLoad = 3
+	pods := newFakePods("127.0.0.1", "p1")
+	pods.lent = map[string]*v1.Pod{"lent": {ObjectMeta: metav1.ObjectMeta{Name: "lent"}, Status: v1.PodStatus{PodIP: "127.0.0.1"}}}
+	pods.setLoad("lent", 2)
+	slots := make(chan struct{}, 2)
+
+	// a lent pod takes more requests than the shared pods.
+	name, _, err := s.acquirePod(context.Background(), pods, "m", slots)
+	assert.NoError(t, err)
+	assert.Equal(t, "lent", name)
+
+	pods.setLoad("lent", 3)
+	name, _, err = s.acquirePod(context.Background(), pods, "m", slots)
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", name)
+}
+
+func TestSchedulerExpiresBatch(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	s := newTestScheduler(store, 0)
+	pods := newFakePods("127.0.0.1", "p1")
+	pods.setLoad("p1", 2)
+
+	batch := createTestBatch(t, store, requestLine("a", "m", "hello")+"\n"+requestLine("b", "m", "world"))
+	batch.ExpiresAt = time.Now().Add(-time.Second).Unix()
+	require.NoError(t, store.SaveBatch(context.Background(), batch))
+	s.run(context.Background(), pods, batch.ID)
+
+	batch, err := store.GetBatch(context.Background(), batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusExpired, batch.Status)
+	assert.NotZero(t, batch.ExpiredAt)
+	assert.Equal(t, RequestCounts{Total: 2, Failed: 2}, batch.RequestCounts)
+	assert.Empty(t, batch.OutputFileID)
+	errorOutput := readLines(t, store, batch.ErrorFileID)
+	assert.Equal(t, "batch_expired", errorOutput["a"].Error.Code)
+	assert.Equal(t, "batch_expired", errorOutput["b"].Error.Code)
+}
+
+func TestSchedulerCancelsBatch(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	s := newTestScheduler(store, 0)
+	pods := newFakePods("127.0.0.1", "p1")
+	pods.setLoad("p1", 2)
+
+	batch := createTestBatch(t, store, requestLine("a", "m", "hello"))
+	done := make(chan struct{})
+	go func() {
+		s.run(context.Background(), pods, batch.ID)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		running, err := store.GetBatch(context.Background(), batch.ID)
+		return err == nil && running.Status == StatusInProgress
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, store.requestCancel(context.Background(), batch.ID))
+	<-done
+
+	batch, err := store.GetBatch(context.Background(), batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, batch.Status)
+	assert.NotZero(t, batch.CancellingAt)
+	assert.NotZero(t, batch.CancelledAt)
+	assert.Empty(t, batch.ErrorFileID)
+}
+
+func TestSchedulerSkipsLeasedBatch(t *testing.T) {
+	store := NewStore(kvstore.NewMemoryStore(), &kvFileStore{kv: kvstore.NewMemoryStore()})
+	s := newTestScheduler(store, 0)
+	batch := createTestBatch(t, store, requestLine("a", "m", "hello"))
+
+	ok, err := store.acquireLease(context.Background(), batch.ID, "other", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	s.run(context.Background(), newFakePods("127.0.0.1", "p1"), batch.ID)
+	batch, err = store.GetBatch(context.Background(), batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusValidating, batch.Status)
+
+	// the scan only queues pending batches without a lease.
+	pending := createTestBatch(t, store, requestLine("a", "m", "hello"))
+	s.scanPending(context.Background())
+	assert.Equal(t, pending.ID, <-s.queue)
+	assert.Empty(t, s.queue)
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	FileStoreKV = "kvstore"
+	FileStoreS3 = "s3"
+
+	batchKeyPrefix       = "aibrix:batch:"
+	fileKeyPrefix        = "aibrix:batch-file:"
+	fileContentKeyPrefix = "aibrix:batch-file-content:"
+	leaseKeyPrefix       = "aibrix:batch-lease:"
+	cancelKeyPrefix      = "aibrix:batch-cancel:"
+
+	defaultRetention = 30 * 24 * time.Hour
+)
+
+// ErrNotFound is returned for unknown batches and files.
+var ErrNotFound = errors.New("not found")
+
+var retention = getRetention()
+
+func getRetention() time.Duration {
+	value := utils.LoadEnv("AIBRIX_BATCH_RETENTION", "")
+	if value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			klog.Infof("invalid AIBRIX_BATCH_RETENTION: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_BATCH_RETENTION env value for batch retention: %v", duration)
+			return duration
+		}
+	}
+	return defaultRetention
+}
+
+// FileStore keeps the contents of the files, input files and the output and error files of batches.
+type FileStore interface {
+	// Put writes the content of the file.
+	Put(ctx context.Context, id string, content []byte) error
+	// Get returns the content of the file, ErrNotFound if it doesn't exist.
+	Get(ctx context.Context, id string) ([]byte, error)
+	// Delete removes the content of the file, deleting a missing file is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// NewFileStore creates the file store of the backend, the kv store itself or an S3 bucket configured by the
+// AIBRIX_BATCH_S3_* environment variables.
+func NewFileStore(backend string, kv kvstore.Store) (FileStore, error) {
+	switch strings.ToLower(backend) {
+	case FileStoreKV, "":
+		return &kvFileStore{kv: kv}, nil
+	case FileStoreS3:
+		return NewS3FileStore(LoadS3Config())
+	default:
+		return nil, fmt.Errorf("unknown batch file store %q, must be one of kvstore or s3", backend)
+	}
+}
+
+// kvFileStore keeps the contents of the files in the kv store, they expire after the retention.
+type kvFileStore struct {
+	kv kvstore.Store
+}
+
+func (s *kvFileStore) Put(ctx context.Context, id string, content []byte) error {
+	return s.kv.Set(ctx, fileContentKeyPrefix+id, content, retention)
+}
+
+func (s *kvFileStore) Get(ctx context.Context, id string) ([]byte, error) {
+	content, err := s.kv.Get(ctx, fileContentKeyPrefix+id)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return content, err
+}
+
+func (s *kvFileStore) Delete(ctx context.Context, id string) error {
+	return s.kv.Delete(ctx, fileContentKeyPrefix+id)
+}
+
+// Store persists the batches and the files in the kv store shared by the gateway replicas, the contents of the files
+// in the file store. Records expire after AIBRIX_BATCH_RETENTION, 30 days by default.
+type Store struct {
+	kv    kvstore.Store
+	files FileStore
+	now   func() time.Time
+}
+
+// NewStore creates the store of the batches and the files.
+func NewStore(kv kvstore.Store, files FileStore) *Store {
+	return &Store{kv: kv, files: files, now: time.Now}
+}
+
+// CreateFile writes the content of a new file of the owner.
+func (s *Store) CreateFile(ctx context.Context, owner, filename, purpose string, content []byte) (*File, error) {
+	file := &File{
+		ID:        newID("file-"),
+		Object:    "file",
+		Bytes:     len(content),
+		CreatedAt: s.now().Unix(),
+		Filename:  filename,
+		Purpose:   purpose,
+		Owner:     owner,
+	}
+	if err := s.files.Put(ctx, file.ID, content); err != nil {
+		return nil, fmt.Errorf("failed to write file content: %w", err)
+	}
+	if err := s.put(ctx, fileKeyPrefix+file.ID, file, retention); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// GetFile returns the file, ErrNotFound if it doesn't exist.
+func (s *Store) GetFile(ctx context.Context, id string) (*File, error) {
+	file := &File{}
+	if err := s.get(ctx, fileKeyPrefix+id, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// GetFileContent returns the content of the file, ErrNotFound if it doesn't exist.
+func (s *Store) GetFileContent(ctx context.Context, id string) ([]byte, error) {
+	if _, err := s.GetFile(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.files.Get(ctx, id)
+}
+
+// DeleteFile removes the file, ErrNotFound if it doesn't exist.
+func (s *Store) DeleteFile(ctx context.Context, id string) error {
+	if _, err := s.GetFile(ctx, id); err != nil {
+		return err
+	}
+	if err := s.files.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.kv.Delete(ctx, fileKeyPrefix+id)
+}
+
+// SaveBatch writes the batch.
+func (s *Store) SaveBatch(ctx context.Context, batch *Batch) error {
+	return s.put(ctx, batchKeyPrefix+batch.ID, batch, retention)
+}
+
+// GetBatch returns the batch, ErrNotFound if it doesn't exist.
+func (s *Store) GetBatch(ctx context.Context, id string) (*Batch, error) {
+	batch := &Batch{}
+	if err := s.get(ctx, batchKeyPrefix+id, batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// ListBatches returns up to limit batches of the owner, the most recent first, created before the batch after if set.
+// It also returns whether more batches follow.
+func (s *Store) ListBatches(ctx context.Context, owner, after string, limit int) ([]*Batch, bool, error) {
+	scanned, err := s.scanBatches(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	batches := scanned[:0]
+	for _, batch := range scanned {
+		if batch.Owner == owner {
+			batches = append(batches, batch)
+		}
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		if batches[i].CreatedAt != batches[j].CreatedAt {
+			return batches[i].CreatedAt > batches[j].CreatedAt
+		}
+		return batches[i].ID > batches[j].ID
+	})
+	if after != "" {
+		for i, batch := range batches {
+			if batch.ID == after {
+				batches = batches[i+1:]
+				break
+			}
+		}
+	}
+	if len(batches) > limit {
+		return batches[:limit], true, nil
+	}
+	return batches, false, nil
+}
+
+func (s *Store) scanBatches(ctx context.Context) ([]*Batch, error) {
+	var batches []*Batch
+	err := s.kv.Scan(ctx, batchKeyPrefix, func(key string, value []byte) error {
+		batch := &Batch{}
+		if err := json.Unmarshal(value, batch); err != nil {
+			klog.ErrorS(err, "skipping undecodable batch", "key", key)
+			return nil
+		}
+		batches = append(batches, batch)
+		return nil
+	})
+	return batches, err
+}
+
+// acquireLease makes the owner run the batch for ttl, it returns false if another owner holds the lease. Two
+// replicas acquiring a free lease at the same time may both get it, the later write wins the next renewal.
+func (s *Store) acquireLease(ctx context.Context, id, owner string, ttl time.Duration) (bool, error) {
+	current, err := s.kv.Get(ctx, leaseKeyPrefix+id)
+	if err == nil && string(current) != owner {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, kvstore.ErrNotFound) {
+		return false, err
+	}
+	if err := s.kv.Set(ctx, leaseKeyPrefix+id, []byte(owner), ttl); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// releaseLease frees the lease of the batch if the owner still holds it.
+func (s *Store) releaseLease(ctx context.Context, id, owner string) error {
+	current, err := s.kv.Get(ctx, leaseKeyPrefix+id)
+	if errors.Is(err, kvstore.ErrNotFound) || (err == nil && string(current) != owner) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.kv.Delete(ctx, leaseKeyPrefix+id)
+}
+
+// leased returns true if a replica holds the lease of the batch.
+func (s *Store) leased(ctx context.Context, id string) (bool, error) {
+	_, err := s.kv.Get(ctx, leaseKeyPrefix+id)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// requestCancel asks the replica running the batch to cancel it.
+func (s *Store) requestCancel(ctx context.Context, id string) error {
+	return s.kv.Set(ctx, cancelKeyPrefix+id, []byte("true"), retention)
+}
+
+// cancelRequested returns true if the batch was asked to be cancelled.
+func (s *Store) cancelRequested(ctx context.Context, id string) (bool, error) {
+	_, err := s.kv.Get(ctx, cancelKeyPrefix+id)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *Store) put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, key, data, ttl)
+}
+
+func (s *Store) get(ctx context.Context, key string, value interface{}) error {
+	data, err := s.kv.Get(ctx, key)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
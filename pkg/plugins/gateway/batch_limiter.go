@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/batch"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/ratelimiter"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// batchLimiter applies the RPM and TPM limits of the users to the requests of their batches, in the counters of
+// their online requests.
+type batchLimiter struct {
+	redisClient redis.UniversalClient
+	ratelimiter ratelimiter.RateLimiter
+}
+
+// NewBatchLimiter creates the rate limiter of the batch requests.
+func NewBatchLimiter(redisClient redis.UniversalClient) batch.Limiter {
+	return &batchLimiter{
+		redisClient: redisClient,
+		ratelimiter: ratelimiter.NewRedisAccountRateLimiter("aibrix", redisClient, 1*time.Minute),
+	}
+}
+
+// Allow counts a request of the user if it is below its RPM and TPM limits. Users without a user record get the
+// default limits.
+func (l *batchLimiter) Allow(ctx context.Context, username string) (bool, error) {
+	user, err := utils.GetUser(utils.User{Name: username}, l.redisClient)
+	if errors.Is(err, redis.Nil) {
+		user, err = utils.User{Name: username}, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	user = withDefaultLimits(user)
+
+	rpm, err := l.ratelimiter.Get(ctx, fmt.Sprintf("%v_RPM_CURRENT", username))
+	if err != nil {
+		return false, err
+	}
+	tpm, err := l.ratelimiter.Get(ctx, fmt.Sprintf("%v_TPM_CURRENT", username))
+	if err != nil {
+		return false, err
+	}
+	if rpm >= user.Rpm || tpm >= user.Tpm {
+		return false, nil
+	}
+	if _, err := l.ratelimiter.Incr(ctx, fmt.Sprintf("%v_RPM_CURRENT", username), 1); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AddTokens counts the tokens of a request of the user in its TPM.
+func (l *batchLimiter) AddTokens(ctx context.Context, username string, tokens int64) error {
+	_, err := l.ratelimiter.Incr(ctx, fmt.Sprintf("%v_TPM_CURRENT", username), tokens)
+	return err
+}
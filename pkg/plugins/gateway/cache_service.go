@@ -0,0 +1,286 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/cacheapi"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/prefixcacheindexer"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+type prefixCacheStatsProvider interface {
+	PrefixCacheStats() (prefixcacheindexer.Stats, bool)
+}
+
+// cacheService serves the cache state of the gateway to aibrixctl and other debugging tools.
+type cacheService struct {
+	cache         cache.Interface
+	routers       map[string]routing.Router
+	history       *routingHistory
+	modelConfigs  *modelConfigStore
+	zoneAffinity  *routing.ZoneAffinity
+	requestLength *routing.RequestLengthAffinity
+}
+
+// NewCacheService returns the cache service of the gateway, it shares the cache and routers of the server.
+func NewCacheService(s *Server) cacheapi.CacheServiceServer {
+	return &cacheService{
+		cache:         s.cache,
+		routers:       s.routers,
+		history:       s.routingHistory,
+		modelConfigs:  s.modelConfigs,
+		zoneAffinity:  s.zoneAffinity,
+		requestLength: s.requestLength,
+	}
+}
+
+func (c *cacheService) ListModels(ctx context.Context, req *cacheapi.ListModelsRequest) (*cacheapi.ListModelsResponse, error) {
+	models := c.cache.GetModels()
+	sort.Strings(models)
+
+	resp := &cacheapi.ListModelsResponse{Models: make([]*cacheapi.ModelInfo, 0, len(models))}
+	for _, model := range models {
+		pods, err := c.cache.GetPodsForModel(model)
+		if err != nil {
+			// model was removed after listing
+			continue
+		}
+		info := &cacheapi.ModelInfo{Name: model, ReadyPods: int32(len(utils.FilterReadyPods(pods)))}
+		if readyPods, err := c.cache.GetReadyPodsForModel(model); err == nil {
+			info.EngineReadyPods = int32(len(readyPods))
+		}
+		if modelInfo, err := c.cache.GetModelInfo(model); err == nil {
+			info.MaxModelLen = int32(modelInfo.MaxModelLen)
+			info.Dtype = modelInfo.DType
+			info.Quantization = modelInfo.Quantization
+			info.Engine = modelInfo.Engine
+			info.EngineVersion = modelInfo.EngineVersion
+			info.Capabilities = modelInfo.Capabilities
+		}
+		info.WarmNodes = c.cache.GetWarmNodes(model)
+		for name := range pods {
+			info.Pods = append(info.Pods, name)
+		}
+		sort.Strings(info.Pods)
+		resp.Models = append(resp.Models, info)
+	}
+	return resp, nil
+}
+
+func (c *cacheService) ListPods(ctx context.Context, req *cacheapi.ListPodsRequest) (*cacheapi.ListPodsResponse, error) {
+	resp := &cacheapi.ListPodsResponse{}
+	for _, snapshot := range c.cache.GetPodSnapshots() {
+		sort.Strings(snapshot.Models)
+		if req.Model != "" && !slices.Contains(snapshot.Models, req.Model) {
+			continue
+		}
+		info := &cacheapi.PodInfo{
+			Name:        snapshot.Pod.Name,
+			Namespace:   snapshot.Pod.Namespace,
+			Ip:          snapshot.Pod.Status.PodIP,
+			Ready:       utils.IsPodReady(snapshot.Pod),
+			EngineReady: snapshot.EngineReady,
+			Draining:    snapshot.Draining,
+			Inflight:    snapshot.Inflight,
+			Zone:        snapshot.Topology.Zone,
+			Node:        snapshot.Topology.Node,
+			NodePool:    snapshot.Topology.NodePool,
+			Models:      snapshot.Models,
+			Metrics:     toMetricValues(snapshot.Metrics),
+		}
+		if len(snapshot.ModelMetrics) > 0 {
+			info.ModelMetrics = map[string]*cacheapi.MetricValues{}
+			for model, modelMetrics := range snapshot.ModelMetrics {
+				info.ModelMetrics[model] = &cacheapi.MetricValues{Values: toMetricValues(modelMetrics)}
+			}
+		}
+		resp.Pods = append(resp.Pods, info)
+	}
+	sort.Slice(resp.Pods, func(i, j int) bool {
+		if resp.Pods[i].Namespace != resp.Pods[j].Namespace {
+			return resp.Pods[i].Namespace < resp.Pods[j].Namespace
+		}
+		return resp.Pods[i].Name < resp.Pods[j].Name
+	})
+	return resp, nil
+}
+
+func (c *cacheService) GetPrefixCacheStats(ctx context.Context, req *cacheapi.GetPrefixCacheStatsRequest) (*cacheapi.PrefixCacheStats, error) {
+	provider, ok := c.routers[RouterPrefixCache].(prefixCacheStatsProvider)
+	if !ok {
+		return &cacheapi.PrefixCacheStats{}, nil
+	}
+	stats, ok := provider.PrefixCacheStats()
+	if !ok {
+		return &cacheapi.PrefixCacheStats{}, nil
+	}
+	resp := &cacheapi.PrefixCacheStats{
+		Enabled:       true,
+		Lookups:       stats.Lookups,
+		Hits:          stats.Hits,
+		MatchedTokens: stats.MatchedTokens,
+		TotalTokens:   stats.TotalTokens,
+		Blocks:        int32(stats.Blocks),
+		Speculative:   int32(stats.Speculative),
+	}
+	if len(stats.ModelPodBlocks) > 0 {
+		resp.ModelPodBlocks = make(map[string]*cacheapi.PodBlocks, len(stats.ModelPodBlocks))
+		for model, podBlocks := range stats.ModelPodBlocks {
+			blocks := make(map[string]int32, len(podBlocks))
+			for pod, n := range podBlocks {
+				blocks[pod] = int32(n)
+			}
+			resp.ModelPodBlocks[model] = &cacheapi.PodBlocks{Blocks: blocks}
+		}
+	}
+	return resp, nil
+}
+
+func (c *cacheService) ListRoutingDecisions(ctx context.Context, req *cacheapi.ListRoutingDecisionsRequest) (*cacheapi.ListRoutingDecisionsResponse, error) {
+	resp := &cacheapi.ListRoutingDecisionsResponse{}
+	for _, record := range c.history.Recent(req.Model, int(req.Limit)) {
+		decision := &cacheapi.RoutingDecision{
+			Timestamp:       timestamppb.New(record.Timestamp),
+			RequestId:       record.RequestID,
+			Model:           record.Model,
+			RoutingStrategy: record.RoutingStrategy,
+			TargetPod:       record.TargetPod,
+			QueueingDelayMs: record.QueueingDelayMs,
+			DurationMs:      record.DurationMs,
+			StatusCode:      int32(record.StatusCode),
+		}
+		for _, candidate := range record.Candidates {
+			decision.Candidates = append(decision.Candidates, &cacheapi.CandidateScore{Pod: candidate.Pod, Score: candidate.Score})
+		}
+		resp.Decisions = append(resp.Decisions, decision)
+	}
+	return resp, nil
+}
+
+// ExplainRouting routes the request with each strategy in a dry run, on the ready pods of the model in the zone of the
+// gateway. Pods warming up are not held back as slow start sends them warmup requests, so a strategy may select a pod
+// the gateway would not route to yet.
+func (c *cacheService) ExplainRouting(ctx context.Context, req *cacheapi.ExplainRoutingRequest) (*cacheapi.ExplainRoutingResponse, error) {
+	if req.Model == "" {
+		return nil, status.Error(codes.InvalidArgument, "model is required")
+	}
+	message := req.Message
+	if req.Body != nil {
+		endpoint := req.Endpoint
+		if endpoint == "" {
+			endpoint = EndpointChatCompletions
+		}
+		input, err := parseRequestInput(endpoint, req.Body.AsMap())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+		}
+		message = input.message
+	}
+	pods, err := c.cache.GetReadyPodsForModel(req.Model)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "model %s does not exist", req.Model)
+	}
+	pods = c.zoneAffinity.Filter(pods, req.Model)
+	pods = c.requestLength.Filter(pods, req.Model, message)
+
+	var headerStrategy string
+	for key, value := range req.Headers {
+		if strings.ToLower(key) == HeaderRoutingStrategy {
+			headerStrategy = value
+		}
+	}
+	modelConfig, _ := c.modelConfigs.Get(req.Model)
+	resp := &cacheapi.ExplainRoutingResponse{
+		Model:           req.Model,
+		RoutingStrategy: resolveRoutingStrategy(headerStrategy, modelConfig),
+		Pods:            make([]string, 0, len(pods)),
+	}
+	for name := range pods {
+		resp.Pods = append(resp.Pods, name)
+	}
+	sort.Strings(resp.Pods)
+
+	strategies := req.Strategies
+	if len(strategies) == 0 {
+		for strategy := range c.routers {
+			strategies = append(strategies, strategy)
+		}
+		sort.Strings(strategies)
+	}
+	for _, strategy := range strategies {
+		resp.Explanations = append(resp.Explanations, c.explainRouting(ctx, strategy, pods, req.Model, message))
+	}
+	return resp, nil
+}
+
+func (c *cacheService) explainRouting(ctx context.Context, strategy string, pods map[string]*v1.Pod, model, message string) *cacheapi.RoutingExplanation {
+	explanation := &cacheapi.RoutingExplanation{RoutingStrategy: strategy}
+	router, ok := c.routers[strategy]
+	if !ok {
+		explanation.Error = "unknown or uninitialized routing strategy"
+		return explanation
+	}
+	ctx, scores := routing.WithScoreRecorder(routing.WithDryRun(ctx))
+	address, err := router.Route(ctx, pods, model, message)
+	for _, candidate := range scores.Scores() {
+		explanation.Candidates = append(explanation.Candidates, &cacheapi.CandidateScore{Pod: candidate.Pod, Score: candidate.Score})
+	}
+	if err != nil {
+		explanation.Error = err.Error()
+		return explanation
+	}
+	explanation.TargetAddress = address
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	for name, pod := range pods {
+		if pod.Status.PodIP == host {
+			explanation.TargetPod = name
+		}
+	}
+	return explanation
+}
+
+// toMetricValues flattens metric values, histograms are reported as their mean and
+// prometheus or label values are skipped.
+func toMetricValues(values map[string]metrics.MetricValue) map[string]float64 {
+	result := make(map[string]float64, len(values))
+	for name, value := range values {
+		switch v := value.(type) {
+		case *metrics.SimpleMetricValue:
+			result[name] = v.Value
+		case *metrics.HistogramMetricValue:
+			result[name] = v.GetMean()
+		}
+	}
+	return result
+}
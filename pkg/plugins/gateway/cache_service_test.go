@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/cacheapi"
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+)
+
+type recordingRouter struct {
+	messages []string
+	err      error
+}
+
+func (r *recordingRouter) Route(ctx context.Context, pods map[string]*v1.Pod, model, message string) (string, error) {
+	r.messages = append(r.messages, message)
+	if r.err != nil {
+		return "", r.err
+	}
+	return "10.0.0.1:8000", nil
+}
+
+func TestExplainRouting(t *testing.T) {
+	random, err := NewRouter(RouterRandom)
+	require.NoError(t, err)
+	recording := &recordingRouter{}
+	c := cache.New()
+	c.SetPod(newModelPod("p1", "llama-7b-5d4f8", "5d4f8", true), "llama-7b")
+	service := &cacheService{
+		cache: c,
+		routers: map[string]routing.Router{
+			RouterRandom:       random,
+			RouterLeastRequest: recording,
+			RouterPrefixCache:  &recordingRouter{err: errors.New("no tokenizer")},
+		},
+		modelConfigs: newModelConfigStore(),
+	}
+	ctx := context.Background()
+
+	body, err := structpb.NewStruct(map[string]interface{}{"messages": []interface{}{map[string]interface{}{"role": "user", "content": "hi"}}})
+	require.NoError(t, err)
+	resp, err := service.ExplainRouting(ctx, &cacheapi.ExplainRoutingRequest{
+		Model:   "llama-7b",
+		Body:    body,
+		Headers: map[string]string{"Routing-Strategy": RouterLeastRequest},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RouterLeastRequest, resp.RoutingStrategy)
+	// the engine of the pod has not been observed serving yet.
+	assert.Empty(t, resp.Pods)
+	require.Len(t, resp.Explanations, 3)
+	assert.True(t, proto.Equal(&cacheapi.RoutingExplanation{RoutingStrategy: RouterLeastRequest, TargetAddress: "10.0.0.1:8000"}, resp.Explanations[0]))
+	assert.True(t, proto.Equal(&cacheapi.RoutingExplanation{RoutingStrategy: RouterPrefixCache, Error: "no tokenizer"}, resp.Explanations[1]))
+	assert.Equal(t, RouterRandom, resp.Explanations[2].RoutingStrategy)
+	assert.NotEmpty(t, resp.Explanations[2].Error)
+	assert.Equal(t, []string{`[{"content":"hi","role":"user"}]`}, recording.messages)
+
+	pods := map[string]*v1.Pod{"p1": newModelPod("p1", "llama-7b-5d4f8", "5d4f8", true)}
+	assert.True(t, proto.Equal(&cacheapi.RoutingExplanation{RoutingStrategy: RouterRandom, TargetPod: "p1", TargetAddress: "10.0.0.1:8000"},
+		service.explainRouting(ctx, RouterRandom, pods, "llama-7b", "hi")))
+
+	resp, err = service.ExplainRouting(ctx, &cacheapi.ExplainRoutingRequest{Model: "llama-7b", Message: "hi", Strategies: []string{"unknown"}})
+	require.NoError(t, err)
+	require.Len(t, resp.Explanations, 1)
+	assert.True(t, proto.Equal(&cacheapi.RoutingExplanation{RoutingStrategy: "unknown", Error: "unknown or uninitialized routing strategy"}, resp.Explanations[0]))
+
+	_, err = service.ExplainRouting(ctx, &cacheapi.ExplainRoutingRequest{Model: "qwen-7b"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = service.ExplainRouting(ctx, &cacheapi.ExplainRoutingRequest{Model: "llama-7b", Endpoint: EndpointCompletions, Body: &structpb.Struct{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
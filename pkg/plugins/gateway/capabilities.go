@@ -0,0 +1,100 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+// multimodalContentTypes are the types of the non text parts of chat messages.
+var multimodalContentTypes = map[string]bool{
+	"image_url":   true,
+	"input_audio": true,
+	"audio_url":   true,
+	"video_url":   true,
+}
+
+// requiredCapabilities returns the engine capabilities the request relies on: tools the model may call, a JSON
+// response format or guided decoding and non text message parts.
+func requiredCapabilities(jsonMap map[string]interface{}) []string {
+	var capabilities []string
+	if tools, ok := jsonMap["tools"].([]interface{}); ok && len(tools) > 0 && jsonMap["tool_choice"] != "none" {
+		capabilities = append(capabilities, cache.CapabilityToolCalling)
+	}
+	format, _ := jsonMap["response_format"].(map[string]interface{})
+	if formatType := format["type"]; formatType == "json_object" || formatType == "json_schema" || hasGuidedDecoding(jsonMap) {
+		capabilities = append(capabilities, cache.CapabilityJSONMode)
+	}
+	messages, _ := jsonMap["messages"].([]interface{})
+	for _, message := range messages {
+		message, _ := message.(map[string]interface{})
+		parts, _ := message["content"].([]interface{})
+		for _, part := range parts {
+			part, _ := part.(map[string]interface{})
+			if partType, _ := part["type"].(string); multimodalContentTypes[partType] {
+				return append(capabilities, cache.CapabilityMultimodal)
+			}
+		}
+	}
+	return capabilities
+}
+
+// filterByCapabilities drops the candidates whose engine lacks a capability the request requires. The pods are
+// returned unchanged if none has them, the engine then rejects the request with its own error.
+func (s *Server) filterByCapabilities(model string, pods map[string]*v1.Pod, input requestInput) map[string]*v1.Pod {
+	if len(input.capabilities) == 0 {
+		return pods
+	}
+	capable := make(map[string]*v1.Pod, len(pods))
+	for name, pod := range pods {
+		if hasCapabilities(s.cache.GetPodCapabilities(name), input.capabilities) {
+			capable[name] = pod
+		}
+	}
+	if len(capable) == 0 {
+		klog.V(4).InfoS("no pod has the capabilities of the request", "model", model, "capabilities", input.capabilities)
+		return pods
+	}
+	if len(capable) < len(pods) {
+		klog.V(4).InfoS("routing to pods with the capabilities of the request", "model", model, "capabilities", input.capabilities, "pods", len(capable))
+	}
+	return capable
+}
+
+// hasGuidedDecoding returns true if the request sets a guided decoding parameter of vLLM.
+func hasGuidedDecoding(jsonMap map[string]interface{}) bool {
+	for _, key := range []string{"guided_json", "guided_regex", "guided_choice", "guided_grammar"} {
+		if value, ok := jsonMap[key]; ok && value != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCapabilities(capabilities, required []string) bool {
+	for _, capability := range required {
+		if !slices.Contains(capabilities, capability) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+func TestRequiredCapabilities(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		capabilities []string
+	}{
+		{
+			name: "text chat",
+			body: `{"messages": [{"role": "user", "content": "hi"}]}`,
+		},
+		{
+			name:         "tools",
+			body:         `{"messages": [{"role": "user", "content": "hi"}], "tools": [{"type": "function"}]}`,
+			capabilities: []string{cache.CapabilityToolCalling},
+		},
+		{
+			name: "tools not to call",
+			body: `{"messages": [{"role": "user", "content": "hi"}], "tools": [{"type": "function"}], "tool_choice": "none"}`,
+		},
+		{
+			name:         "json schema",
+			body:         `{"prompt": "hi", "response_format": {"type": "json_schema"}}`,
+			capabilities: []string{cache.CapabilityJSONMode},
+		},
+		{
+			name:         "guided decoding",
+			body:         `{"prompt": "hi", "guided_choice": ["yes", "no"]}`,
+			capabilities: []string{cache.CapabilityJSONMode},
+		},
+		{
+			name: "text response format",
+			body: `{"prompt": "hi", "response_format": {"type": "text"}}`,
+		},
+		{
+			name:         "image",
+			body:         `{"messages": [{"role": "user", "content": [{"type": "text", "text": "what is it?"}, {"type": "image_url", "image_url": {"url": "http://image"}}]}]}`,
+			capabilities: []string{cache.CapabilityMultimodal},
+		},
+	}
+
+	for _, tc := range testCases {
+		var jsonMap map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(tc.body), &jsonMap))
+		assert.Equal(t, tc.capabilities, requiredCapabilities(jsonMap), tc.name)
+	}
+}
+
+func TestFilterByCapabilities(t *testing.T) {
+	pods := map[string]*v1.Pod{
+		"tools": newModelPod("tools", "llama-7b-5d4f8", "5d4f8", true),
+		"plain": newModelPod("plain", "llama-7b-5d4f8", "5d4f8", true),
+	}
+	pods["tools"].Spec.Containers = []v1.Container{{Args: []string{"--enable-auto-tool-choice"}}}
+	c := cache.New()
+	for _, pod := range pods {
+		c.SetPod(pod, "llama-7b")
+	}
+	s := &Server{cache: c}
+
+	assert.Len(t, s.filterByCapabilities("llama-7b", pods, requestInput{}), 2)
+	assert.Len(t, s.filterByCapabilities("llama-7b", pods, requestInput{capabilities: []string{cache.CapabilityJSONMode}}), 2)
+
+	filtered := s.filterByCapabilities("llama-7b", pods, requestInput{capabilities: []string{cache.CapabilityToolCalling}})
+	assert.Len(t, filtered, 1)
+	assert.Contains(t, filtered, "tools")
+
+	// the engine rejects requests no pod is capable of.
+	multimodal := requestInput{capabilities: []string{cache.CapabilityMultimodal}}
+	assert.Len(t, s.filterByCapabilities("llama-7b", pods, multimodal), 2)
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// HeaderQueueDepth is set on the responses of a model to the requests waiting on its ready pods.
+	HeaderQueueDepth = "x-aibrix-queue-depth"
+	// HeaderRetryAfterMs is set on the responses of a model to how long clients should wait before sending it more
+	// requests, 0 while it has spare capacity.
+	HeaderRetryAfterMs = "x-aibrix-retry-after-ms"
+
+	capacityPath = "/v1/capacity"
+
+	EnvMaxRetryAfterMs = "AIBRIX_MAX_RETRY_AFTER_MS"
+
+	// the states of a model: available with spare capacity, queueing on its pods, saturated when its requests are
+	// rejected at capacity and unavailable without ready pods.
+	capacityAvailable   = "available"
+	capacityQueueing    = "queueing"
+	capacitySaturated   = "saturated"
+	capacityUnavailable = "unavailable"
+
+	// defaultRequestLatency estimates the latency of the requests of pods not reporting it.
+	defaultRequestLatency = time.Second
+	minRetryAfter         = 100 * time.Millisecond
+	defaultMaxRetryAfter  = 30 * time.Second
+)
+
+var maxRetryAfter = time.Duration(getPositiveIntEnv(EnvMaxRetryAfterMs, int(defaultMaxRetryAfter.Milliseconds()))) * time.Millisecond
+
+// ModelCapacity is the saturation state of a model, for clients to back off adaptively.
+type ModelCapacity struct {
+	Model      string `json:"model"`
+	State      string `json:"state"`
+	ReadyPods  int    `json:"ready_pods"`
+	QueueDepth int    `json:"queue_depth"`
+	// Inflight and ConcurrencyLimit are the requests admitted by the adaptive concurrency limit of the model and the
+	// limit, unset without adaptive concurrency.
+	Inflight         int   `json:"inflight,omitempty"`
+	ConcurrencyLimit int   `json:"concurrency_limit,omitempty"`
+	RetryAfterMs     int64 `json:"retry_after_ms"`
+}
+
+// CapacityList is the response of the /v1/capacity endpoint.
+type CapacityList struct {
+	Object string          `json:"object"`
+	Data   []ModelCapacity `json:"data"`
+}
+
+// capacityRequest returns the models the request asks the capacity of, all models if none.
+func capacityRequest(headers []*configPb.HeaderValue) ([]string, bool) {
+	var method, path string
+	for _, header := range headers {
+		switch header.Key {
+		case ":method":
+			method = string(header.RawValue)
+		case ":path":
+			path = string(header.RawValue)
+		}
+	}
+	var query string
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+	if method != "GET" || strings.TrimSuffix(path, "/") != capacityPath {
+		return nil, false
+	}
+	values, _ := url.ParseQuery(query)
+	return values["model"], true
+}
+
+// capacityOf returns the capacity of the model from the ready pods in the cache and its adaptive concurrency limit.
+func (s *Server) capacityOf(model string) ModelCapacity {
+	modelConfig, _ := s.modelConfigs.Get(model)
+	pods, _ := s.cache.GetReadyPodsForModel(model)
+	readyPods := utils.FilterReadyPods(pods)
+	var inflight, limit int
+	if modelConfig.AdaptiveConcurrency && s.concurrency != nil {
+		inflight, limit = s.concurrency.usage(model, len(readyPods))
+	}
+	return estimateCapacity(s.cache, model, readyPods, modelConfig.MaxQueuedRequests, inflight, limit)
+}
+
+// estimateCapacity sums the requests waiting on the ready pods and estimates the time it takes them to drain: every
+// latency of a request, the running requests of a pod complete and as many waiting ones start.
+func estimateCapacity(c cache.Interface, model string, readyPods []*v1.Pod, maxQueuedRequests, inflight, limit int) ModelCapacity {
+	capacity := ModelCapacity{Model: model, ReadyPods: len(readyPods), Inflight: inflight, ConcurrencyLimit: limit}
+	if len(readyPods) == 0 {
+		capacity.State = capacityUnavailable
+		capacity.RetryAfterMs = maxRetryAfter.Milliseconds()
+		return capacity
+	}
+
+	var waiting, running, latency float64
+	queued := true
+	for _, pod := range readyPods {
+		podWaiting, _ := podModelMetric(c, pod.Name, model, metrics.NumRequestsWaiting)
+		podRunning, _ := podModelMetric(c, pod.Name, model, metrics.NumRequestsRunning)
+		podLatency, ok := podModelMetric(c, pod.Name, model, metrics.AvgE2ELatencyPod)
+		if !ok || podLatency <= 0 {
+			podLatency = defaultRequestLatency.Seconds()
+		}
+		waiting += podWaiting
+		running += podRunning
+		latency += podLatency
+		queued = queued && maxQueuedRequests > 0 && podWaiting >= float64(maxQueuedRequests)
+	}
+	capacity.QueueDepth = int(waiting)
+	latency /= float64(len(readyPods))
+
+	switch {
+	case queued || limit > 0 && inflight >= limit:
+		capacity.State = capacitySaturated
+	case waiting > 0:
+		capacity.State = capacityQueueing
+	default:
+		capacity.State = capacityAvailable
+		return capacity
+	}
+	// at capacity without waiting requests, the next request is admitted once one of the in-flight requests completes.
+	batches := math.Max(1, waiting/math.Max(1, running))
+	retryAfter := time.Duration(batches * latency * float64(time.Second))
+	capacity.RetryAfterMs = min(max(retryAfter, minRetryAfter), maxRetryAfter).Milliseconds()
+	return capacity
+}
+
+// podModelMetric returns the metric of the model on the pod, or else of the pod.
+func podModelMetric(c cache.Interface, pod, model, metric string) (float64, bool) {
+	value, err := c.GetPodModelMetric(pod, model, metric)
+	if err != nil {
+		if value, err = c.GetPodMetric(pod, metric); err != nil {
+			return 0, false
+		}
+	}
+	return value.GetSimpleValue(), true
+}
+
+// capacityHeaders are the backpressure headers of the responses of a model.
+func capacityHeaders(capacity ModelCapacity) []*configPb.HeaderValueOption {
+	return []*configPb.HeaderValueOption{
+		{Header: &configPb.HeaderValue{Key: HeaderQueueDepth, RawValue: []byte(strconv.Itoa(capacity.QueueDepth))}},
+		{Header: &configPb.HeaderValue{Key: HeaderRetryAfterMs, RawValue: []byte(strconv.FormatInt(capacity.RetryAfterMs, 10))}},
+	}
+}
+
+// generateCapacityResponse answers the /v1/capacity endpoint with the capacity of the models, all models if none
+// is given.
+func (s *Server) generateCapacityResponse(requestID string, models []string) *extProcPb.ProcessingResponse {
+	if len(models) == 0 {
+		models = s.cache.GetModels()
+	}
+	sort.Strings(models)
+	list := CapacityList{Object: "list", Data: make([]ModelCapacity, 0, len(models))}
+	for _, model := range models {
+		if !s.cache.CheckModelExists(model) {
+			continue
+		}
+		list.Data = append(list.Data, s.capacityOf(model))
+	}
+	body, err := json.Marshal(list)
+	if err != nil {
+		klog.ErrorS(err, "failed to marshal capacity", "requestID", requestID)
+		return generateErrorResponse(envoyTypePb.StatusCode_InternalServerError, nil, "error on getting capacity")
+	}
+
+	return &extProcPb.ProcessingResponse{
+		Response: &extProcPb.ProcessingResponse_ImmediateResponse{
+			ImmediateResponse: &extProcPb.ImmediateResponse{
+				Status: &envoyTypePb.HttpStatus{Code: envoyTypePb.StatusCode_OK},
+				Headers: &extProcPb.HeaderMutation{
+					SetHeaders: []*configPb.HeaderValueOption{
+						{Header: &configPb.HeaderValue{Key: "Content-Type", Value: "application/json"}},
+						{Header: &configPb.HeaderValue{Key: "Cache-Control", Value: "no-store"}},
+					},
+				},
+				Body: string(body),
+			},
+		},
+	}
+}
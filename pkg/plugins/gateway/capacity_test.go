@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/auth"
+)
+
+// fakeAuthenticator authenticates the API keys of its map as their identities.
+type fakeAuthenticator map[string]*auth.Identity
+
+func (a fakeAuthenticator) Authenticate(_ context.Context, apiKey string) (*auth.Identity, error) {
+	if identity, ok := a[apiKey]; ok {
+		return identity, nil
+	}
+	return nil, auth.ErrInvalidAPIKey
+}
+
+func requestHeaders(headers ...*configPb.HeaderValue) *extProcPb.ProcessingRequest {
+	return &extProcPb.ProcessingRequest{Request: &extProcPb.ProcessingRequest_RequestHeaders{
+		RequestHeaders: &extProcPb.HttpHeaders{Headers: &configPb.HeaderMap{Headers: headers}},
+	}}
+}
+
+func TestCapacityRequest(t *testing.T) {
+	headers := func(method, path string) []*configPb.HeaderValue {
+		return []*configPb.HeaderValue{{Key: ":method", RawValue: []byte(method)}, {Key: ":path", RawValue: []byte(path)}}
+	}
+	models, ok := capacityRequest(headers("GET", "/v1/capacity"))
+	assert.True(t, ok)
+	assert.Empty(t, models)
+	models, ok = capacityRequest(headers("GET", "/v1/capacity/?model=llama&model=qwen"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"llama", "qwen"}, models)
+	_, ok = capacityRequest(headers("POST", "/v1/capacity"))
+	assert.False(t, ok)
+	_, ok = capacityRequest(headers("GET", "/v1/models"))
+	assert.False(t, ok)
+}
+
+func TestEstimateCapacity(t *testing.T) {
+	c := cache.New()
+	setPodMetrics := func(podName string, waiting, running, latency float64) {
+		c.SetPodMetric(podName, metrics.NumRequestsWaiting, &metrics.SimpleMetricValue{Value: waiting})
+		c.SetPodMetric(podName, metrics.NumRequestsRunning, &metrics.SimpleMetricValue{Value: running})
+		c.SetPodMetric(podName, metrics.AvgE2ELatencyPod, &metrics.SimpleMetricValue{Value: latency})
+	}
+	setPodMetrics("p1", 0, 4, 2)
+	setPodMetrics("p2", 0, 2, 2)
+	pods := []*v1.Pod{newModelPod("p1", "llama-5d4f8", "5d4f8", true), newModelPod("p2", "llama-5d4f8", "5d4f8", true)}
+
+	capacity := estimateCapacity(c, "llama", pods, 0, 0, 0)
+	assert.Equal(t, ModelCapacity{Model: "llama", State: capacityAvailable, ReadyPods: 2}, capacity)
+
+	// 12 waiting requests on 6 running take 2 latencies of 2s to start.
+	setPodMetrics("p1", 8, 4, 2)
+	setPodMetrics("p2", 4, 2, 2)
+	capacity = estimateCapacity(c, "llama", pods, 0, 0, 0)
+	assert.Equal(t, capacityQueueing, capacity.State)
+	assert.Equal(t, 12, capacity.QueueDepth)
+	assert.Equal(t, int64(4000), capacity.RetryAfterMs)
+
+	// all pods reached the max queued requests.
+	capacity = estimateCapacity(c, "llama", pods, 4, 0, 0)
+	assert.Equal(t, capacitySaturated, capacity.State)
+
+	// the adaptive concurrency limit is reached, the next request is admitted once a request completes.
+	setPodMetrics("p1", 0, 4, 0.05)
+	setPodMetrics("p2", 0, 2, 0.05)
+	capacity = estimateCapacity(c, "llama", pods, 0, 32, 32)
+	assert.Equal(t, ModelCapacity{
+		Model: "llama", State: capacitySaturated, ReadyPods: 2, Inflight: 32, ConcurrencyLimit: 32, RetryAfterMs: minRetryAfter.Milliseconds(),
+	}, capacity)
+
+	capacity = estimateCapacity(c, "llama", nil, 0, 0, 0)
+	assert.Equal(t, capacityUnavailable, capacity.State)
+	assert.Equal(t, maxRetryAfter.Milliseconds(), capacity.RetryAfterMs)
+}
+
+func TestGenerateCapacityResponse(t *testing.T) {
+	c := cache.New()
+	c.SetPod(newModelPod("p1", "qwen-9c7b6", "9c7b6", false), "qwen")
+	c.SetPod(newModelPod("p2", "llama-5d4f8", "5d4f8", false), "llama")
+	s := &Server{cache: c}
+
+	var list CapacityList
+	resp := s.generateCapacityResponse("r1", nil)
+	require.NoError(t, json.Unmarshal([]byte(resp.GetImmediateResponse().Body), &list))
+	require.Len(t, list.Data, 2)
+	assert.Equal(t, "llama", list.Data[0].Model)
+	assert.Equal(t, capacityUnavailable, list.Data[0].State)
+	assert.Equal(t, "qwen", list.Data[1].Model)
+
+	resp = s.generateCapacityResponse("r2", []string{"qwen", "unknown"})
+	require.NoError(t, json.Unmarshal([]byte(resp.GetImmediateResponse().Body), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "qwen", list.Data[0].Model)
+}
+
+func TestCapacityRequestAuthentication(t *testing.T) {
+	c := cache.New()
+	c.SetPod(newModelPod("p1", "llama-5d4f8", "5d4f8", false), "llama")
+	s := &Server{cache: c, authenticator: fakeAuthenticator{"sk-1": {User: "alice"}}}
+	path := &configPb.HeaderValue{Key: ":path", RawValue: []byte("/v1/capacity")}
+	method := &configPb.HeaderValue{Key: ":method", RawValue: []byte("GET")}
+
+	resp, _, _, _ := s.HandleRequestHeaders(context.Background(), "r1", requestHeaders(method, path))
+	assert.Equal(t, envoyTypePb.StatusCode_Unauthorized, resp.GetImmediateResponse().GetStatus().GetCode())
+
+	resp, _, _, _ = s.HandleRequestHeaders(context.Background(), "r2", requestHeaders(method, path,
+		&configPb.HeaderValue{Key: "authorization", RawValue: []byte("Bearer sk-2")}))
+	assert.Equal(t, envoyTypePb.StatusCode_Unauthorized, resp.GetImmediateResponse().GetStatus().GetCode())
+
+	var list CapacityList
+	resp, _, _, _ = s.HandleRequestHeaders(context.Background(), "r3", requestHeaders(method, path,
+		&configPb.HeaderValue{Key: "authorization", RawValue: []byte("Bearer sk-1")}))
+	require.NoError(t, json.Unmarshal([]byte(resp.GetImmediateResponse().Body), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "llama", list.Data[0].Model)
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// the concurrency limit of a ready pod, probed from initialConcurrencyLimit within its bounds.
+	initialConcurrencyLimit = 16
+	minConcurrencyLimit     = 1
+	maxConcurrencyLimit     = 1024
+
+	// concurrencyWindow and minConcurrencySamples bound the samples of the latency compared to the baseline.
+	concurrencyWindow     = time.Second
+	minConcurrencySamples = 10
+	// the baseline latency is measured again every baselineInterval, the limit lowered by probeFraction for a window
+	// so that requests don't queue on the engines.
+	baselineInterval = time.Minute
+	probeFraction    = 0.25
+	// limitSmoothing is the weight of a new limit.
+	limitSmoothing = 0.2
+	// sloBackoff multiplies the limit of a model whose latency exceeds its SLO.
+	sloBackoff = 0.9
+)
+
+var (
+	concurrencyLimitGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "aibrix_gateway_concurrency_limit",
+		Help: "Adaptive concurrency limit of a ready pod of the model.",
+	}, []string{"model"})
+	concurrencyLimitedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_concurrency_limited_requests_total",
+		Help: "Requests rejected by the adaptive concurrency limit of the model.",
+	}, []string{"model"})
+)
+
+func init() {
+	prometheus.MustRegister(concurrencyLimitGauge, concurrencyLimitedRequests)
+}
+
+// modelConcurrency is the gradient limit of a model. Every window the latencies of its requests are compared to the
+// baseline latency of the model without queueing: the limit grows while they stay close to it and shrinks as requests
+// queue up on the engines. Latencies above the SLO back the limit off multiplicatively.
+type modelConcurrency struct {
+	// limit is the concurrency of a ready pod, the model admits limit times its ready pods so the limit follows the
+	// size of the fleet.
+	limit    float64
+	inflight int
+	// baseline is the lowest latency in seconds since it was last measured, 0 until the first window.
+	baseline float64
+	// probing measures the baseline in the window at a fraction of the limit, until the next probe.
+	probing   bool
+	nextProbe time.Time
+
+	windowStart time.Time
+	latencySum  float64
+	samples     int
+	// maxInflight is the highest concurrency of a ready pod in the window.
+	maxInflight float64
+}
+
+func (m *modelConcurrency) admitted(readyPods int) float64 {
+	limit := m.limit * float64(readyPods)
+	if m.probing {
+		limit *= probeFraction
+	}
+	return math.Max(1, math.Floor(limit))
+}
+
+// update moves the limit on the mean latency of the window.
+func (m *modelConcurrency) update(now time.Time, slo time.Duration) {
+	latency := m.latencySum / float64(m.samples)
+	if m.probing || m.baseline == 0 {
+		m.baseline = latency
+		m.probing = false
+		m.nextProbe = now.Add(baselineInterval)
+		return
+	}
+	m.baseline = math.Min(m.baseline, latency)
+
+	switch {
+	case slo > 0 && latency > slo.Seconds():
+		m.limit *= sloBackoff
+	case m.maxInflight < m.limit/2:
+		// the load doesn't reach the limit, its latencies tell nothing about a higher one.
+	default:
+		gradient := math.Max(0.5, math.Min(1, m.baseline/latency))
+		target := m.limit*gradient + math.Sqrt(m.limit)
+		m.limit += (target - m.limit) * limitSmoothing
+	}
+	m.limit = math.Max(minConcurrencyLimit, math.Min(maxConcurrencyLimit, m.limit))
+	m.probing = !now.Before(m.nextProbe)
+}
+
+// concurrencyLimiter holds the adaptive concurrency limits of the models.
+type concurrencyLimiter struct {
+	mu     sync.Mutex
+	models map[string]*modelConcurrency
+	now    func() time.Time
+}
+
+func newConcurrencyLimiter() *concurrencyLimiter {
+	return &concurrencyLimiter{models: map[string]*modelConcurrency{}, now: time.Now}
+}
+
+// concurrencySlot is a request admitted by the limit of its model.
+type concurrencySlot struct {
+	model string
+	slo   time.Duration
+	start time.Time
+	// probe is set for the requests admitted while the baseline is measured.
+	probe    bool
+	sampled  bool
+	released bool
+}
+
+// acquire admits a request to the model unless it already has its limit of in-flight requests across readyPods.
+func (l *concurrencyLimiter) acquire(model string, readyPods int, slo time.Duration) (*concurrencySlot, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m, ok := l.models[model]
+	if !ok {
+		m = &modelConcurrency{limit: initialConcurrencyLimit, windowStart: l.now()}
+		l.models[model] = m
+	}
+	readyPods = max(readyPods, 1)
+	if float64(m.inflight) >= m.admitted(readyPods) {
+		return nil, false
+	}
+	m.inflight++
+	m.maxInflight = math.Max(m.maxInflight, float64(m.inflight)/float64(readyPods))
+	return &concurrencySlot{model: model, slo: slo, start: l.now(), probe: m.probing}, true
+}
+
+// observe samples the latency of the request once, at the first chunk of its response.
+func (l *concurrencyLimiter) observe(slot *concurrencySlot) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if slot.sampled || slot.released {
+		return
+	}
+	slot.sampled = true
+	m := l.models[slot.model]
+	// the requests admitted before the probe still queue, they don't tell the baseline.
+	if m.probing && !slot.probe {
+		return
+	}
+	now := l.now()
+	m.latencySum += now.Sub(slot.start).Seconds()
+	m.samples++
+	if now.Sub(m.windowStart) < concurrencyWindow || m.samples < minConcurrencySamples {
+		return
+	}
+	m.update(now, slot.slo)
+	concurrencyLimitGauge.WithLabelValues(slot.model).Set(m.limit)
+	m.windowStart, m.latencySum, m.samples, m.maxInflight = now, 0, 0, 0
+}
+
+// release returns the slot of a finished request, only the first call has an effect.
+func (l *concurrencyLimiter) release(slot *concurrencySlot) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if slot.released {
+		return
+	}
+	slot.released = true
+	l.models[slot.model].inflight--
+}
+
+// limit returns the concurrency the model admits on readyPods.
+func (l *concurrencyLimiter) limit(model string, readyPods int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if m, ok := l.models[model]; ok {
+		return int(m.admitted(max(readyPods, 1)))
+	}
+	return initialConcurrencyLimit * max(readyPods, 1)
+}
+
+// usage returns the in-flight requests of the model and the concurrency it admits on readyPods.
+func (l *concurrencyLimiter) usage(model string, readyPods int) (int, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if m, ok := l.models[model]; ok {
+		return m.inflight, int(m.admitted(max(readyPods, 1)))
+	}
+	return 0, initialConcurrencyLimit * max(readyPods, 1)
+}
+
+// requestConcurrency holds the slot of a request admitted by the adaptive concurrency limit of its model.
+type requestConcurrency struct {
+	slot *concurrencySlot
+}
+
+type requestConcurrencyKey struct{}
+
+func withRequestConcurrency(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestConcurrencyKey{}, &requestConcurrency{})
+}
+
+func requestConcurrencyFrom(ctx context.Context) *requestConcurrency {
+	concurrency, _ := ctx.Value(requestConcurrencyKey{}).(*requestConcurrency)
+	return concurrency
+}
+
+// admitConcurrency admits the request under the adaptive concurrency limit of the model, false if the model is at
+// its limit. Models without adaptive concurrency admit every request.
+func (s *Server) admitConcurrency(ctx context.Context, model string, modelConfig ModelConfig, readyPods int) bool {
+	concurrency := requestConcurrencyFrom(ctx)
+	if !modelConfig.AdaptiveConcurrency || s.concurrency == nil || concurrency == nil {
+		return true
+	}
+	slot, ok := s.concurrency.acquire(model, readyPods, modelConfig.LatencySLO)
+	if !ok {
+		concurrencyLimitedRequests.WithLabelValues(model).Inc()
+		return false
+	}
+	concurrency.slot = slot
+	return true
+}
+
+// observeConcurrency samples the latency of the request at the first chunk of a successful response.
+func (s *Server) observeConcurrency(ctx context.Context) {
+	if concurrency := requestConcurrencyFrom(ctx); concurrency != nil && concurrency.slot != nil {
+		s.concurrency.observe(concurrency.slot)
+	}
+}
+
+// releaseConcurrency frees the slot of the request once it is done.
+func (s *Server) releaseConcurrency(ctx context.Context) {
+	if concurrency := requestConcurrencyFrom(ctx); concurrency != nil && concurrency.slot != nil {
+		s.concurrency.release(concurrency.slot)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// simulateConcurrency saturates the limit of the model on pods running capacity requests at once in a second, the
+// requests beyond their capacity queue up. It returns the per pod limit after rounds of requests.
+func simulateConcurrency(l *concurrencyLimiter, now *time.Time, pods, capacity, rounds int, slo time.Duration) float64 {
+	for i := 0; i < rounds; i++ {
+		var slots []*concurrencySlot
+		for {
+			slot, ok := l.acquire("llama", pods, slo)
+			if !ok {
+				break
+			}
+			slots = append(slots, slot)
+		}
+		perPod := float64(len(slots)) / float64(pods)
+		*now = now.Add(time.Duration(float64(time.Second) * math.Max(1, perPod/float64(capacity))))
+		for _, slot := range slots {
+			l.observe(slot)
+			l.release(slot)
+		}
+	}
+	return l.models["llama"].limit
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	now := time.Now()
+	l := newConcurrencyLimiter()
+	l.now = func() time.Time { return now }
+
+	// the limit grows past the capacity of the pods until the queueing shows in the latency.
+	limit := simulateConcurrency(l, &now, 2, 32, 300, 0)
+	assert.Greater(t, limit, 32.0)
+	assert.Less(t, limit, 48.0)
+	// it follows the capacity of the pods as it changes.
+	limit = simulateConcurrency(l, &now, 2, 64, 300, 0)
+	assert.Greater(t, limit, 64.0)
+	assert.Less(t, limit, 80.0)
+
+	// the latency SLO backs the limit off close to the capacity.
+	limit = simulateConcurrency(l, &now, 2, 32, 300, 1100*time.Millisecond)
+	assert.Greater(t, limit, 28.0)
+	assert.LessOrEqual(t, limit, 1.1*32)
+}
+
+func TestConcurrencyLimiterFleetSize(t *testing.T) {
+	l := newConcurrencyLimiter()
+	// the model admits the limit of each ready pod.
+	assert.Len(t, acquireAll(l, 2), 2*initialConcurrencyLimit)
+	assert.Equal(t, 2*initialConcurrencyLimit, l.limit("llama", 2))
+	assert.Len(t, acquireAll(l, 3), initialConcurrencyLimit)
+	assert.Empty(t, acquireAll(l, 1))
+}
+
+func acquireAll(l *concurrencyLimiter, pods int) []*concurrencySlot {
+	var slots []*concurrencySlot
+	for {
+		slot, ok := l.acquire("llama", pods, 0)
+		if !ok {
+			return slots
+		}
+		slots = append(slots, slot)
+	}
+}
+
+func TestConcurrencyLimiterIdle(t *testing.T) {
+	now := time.Now()
+	l := newConcurrencyLimiter()
+	l.now = func() time.Time { return now }
+
+	// a load below the limit doesn't raise it.
+	for i := 0; i < 100; i++ {
+		var slots []*concurrencySlot
+		for j := 0; j < 4; j++ {
+			slot, ok := l.acquire("llama", 1, 0)
+			assert.True(t, ok)
+			slots = append(slots, slot)
+		}
+		now = now.Add(time.Second)
+		for _, slot := range slots {
+			l.observe(slot)
+			l.release(slot)
+		}
+	}
+	assert.Equal(t, float64(initialConcurrencyLimit), l.models["llama"].limit)
+
+	// slots are released once.
+	slot, _ := l.acquire("llama", 1, 0)
+	l.release(slot)
+	l.release(slot)
+	assert.Equal(t, 0, l.models["llama"].inflight)
+}
+
+func TestAdmitConcurrency(t *testing.T) {
+	s := &Server{concurrency: newConcurrencyLimiter()}
+	config := ModelConfig{AdaptiveConcurrency: true}
+
+	ctx := withRequestConcurrency(context.Background())
+	assert.True(t, s.admitConcurrency(ctx, "llama", config, 1))
+	assert.NotNil(t, requestConcurrencyFrom(ctx).slot)
+	for i := 1; i < initialConcurrencyLimit; i++ {
+		assert.True(t, s.admitConcurrency(withRequestConcurrency(context.Background()), "llama", config, 1))
+	}
+	assert.False(t, s.admitConcurrency(withRequestConcurrency(context.Background()), "llama", config, 1))
+	// models without adaptive concurrency are not limited.
+	assert.True(t, s.admitConcurrency(withRequestConcurrency(context.Background()), "llama", ModelConfig{}, 1))
+
+	s.observeConcurrency(ctx)
+	s.releaseConcurrency(ctx)
+	assert.True(t, s.admitConcurrency(withRequestConcurrency(context.Background()), "llama", config, 1))
+}
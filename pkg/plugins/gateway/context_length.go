@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// filterByContextLength drops the candidates whose max context length is smaller than the request, the input tokens
+// plus the tokens to generate, when the pods of the model were launched with different max context lengths. Pods
+// of unknown max context length stay candidates. The pods are returned unchanged if none fits, the engine then
+// rejects the request with its own error. Batched inputs are served as separate requests and are not filtered.
+func (s *Server) filterByContextLength(ctx context.Context, model string, pods map[string]*v1.Pod, input requestInput) map[string]*v1.Pod {
+	if input.batchSize > 1 {
+		return pods
+	}
+	maxModelLens := make(map[string]int, len(pods))
+	smallest, largest := 0, 0
+	for name := range pods {
+		maxModelLen := s.cache.GetPodMaxModelLen(name, model)
+		if maxModelLen <= 0 {
+			continue
+		}
+		maxModelLens[name] = maxModelLen
+		if smallest == 0 || maxModelLen < smallest {
+			smallest = maxModelLen
+		}
+		largest = max(largest, maxModelLen)
+	}
+	// every request fitting on a pod fits on all of them, the request is not tokenized.
+	if smallest == largest {
+		return pods
+	}
+
+	requestTokens := int(estimateInputTokens(ctx, model, input)) + input.maxTokens
+	if requestTokens <= smallest {
+		return pods
+	}
+	fitting := make(map[string]*v1.Pod, len(pods))
+	for name, pod := range pods {
+		if maxModelLen, ok := maxModelLens[name]; !ok || requestTokens <= maxModelLen {
+			fitting[name] = pod
+		}
+	}
+	if len(fitting) == 0 {
+		klog.V(4).InfoS("request exceeds the max context length of every pod", "model", model, "requestTokens", requestTokens, "maxModelLen", largest)
+		return pods
+	}
+	klog.V(4).InfoS("routing to pods fitting the context of the request", "model", model, "requestTokens", requestTokens, "pods", len(fitting))
+	return fitting
+}
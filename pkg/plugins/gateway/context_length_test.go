@@ -0,0 +1,64 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+func TestFilterByContextLength(t *testing.T) {
+	pods := map[string]*v1.Pod{
+		"short":   newModelPod("short", "llama-7b-5d4f8", "5d4f8", true),
+		"long":    newModelPod("long", "llama-7b-5d4f8", "5d4f8", true),
+		"unknown": newModelPod("unknown", "llama-7b-5d4f8", "5d4f8", true),
+	}
+	pods["short"].Annotations = map[string]string{cache.ModelMaxModelLenAnnotationKey: "4096"}
+	pods["long"].Annotations = map[string]string{cache.ModelMaxModelLenAnnotationKey: "32768"}
+	c := cache.New()
+	for _, pod := range pods {
+		c.SetPod(pod, "llama-7b")
+	}
+	s := &Server{cache: c}
+	ctx := context.Background()
+
+	fits := requestInput{tokens: 1000, batchSize: 1, maxTokens: 1000}
+	assert.Len(t, s.filterByContextLength(ctx, "llama-7b", pods, fits), 3)
+
+	exceedsShort := requestInput{tokens: 3000, batchSize: 1, maxTokens: 2000}
+	filtered := s.filterByContextLength(ctx, "llama-7b", pods, exceedsShort)
+	assert.Len(t, filtered, 2)
+	assert.NotContains(t, filtered, "short")
+
+	exceedsAll := requestInput{tokens: 30000, batchSize: 1, maxTokens: 4000}
+	assert.Len(t, s.filterByContextLength(ctx, "llama-7b", pods, exceedsAll), 1)
+	// the engine rejects requests no pod fits.
+	known := map[string]*v1.Pod{"short": pods["short"], "long": pods["long"]}
+	assert.Len(t, s.filterByContextLength(ctx, "llama-7b", known, exceedsAll), 2)
+
+	batch := requestInput{tokens: 6000, batchSize: 2}
+	assert.Len(t, s.filterByContextLength(ctx, "llama-7b", pods, batch), 3)
+
+	// pods of the same max context length are not filtered.
+	pods["long"].Annotations[cache.ModelMaxModelLenAnnotationKey] = "4096"
+	assert.Len(t, s.filterByContextLength(ctx, "llama-7b", pods, exceedsShort), 3)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"time"
+
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+)
+
+type requestStartKey struct{}
+
+// withRequestStart records when the gateway received the request, request timeouts are measured from it.
+func withRequestStart(ctx context.Context, start time.Time) context.Context {
+	return context.WithValue(ctx, requestStartKey{}, start)
+}
+
+// remainingTimeout returns what is left of the timeout since the request start, the full timeout if the start is unknown.
+func remainingTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	start, ok := ctx.Value(requestStartKey{}).(time.Time)
+	if !ok {
+		return timeout
+	}
+	return timeout - time.Since(start)
+}
+
+type processingRequest struct {
+	req *extProcPb.ProcessingRequest
+	err error
+}
+
+// receive forwards the messages of the stream until it fails or ctx is done, so Process can select on deadlines.
+func receive(ctx context.Context, srv extProcPb.ExternalProcessor_ProcessServer) <-chan processingRequest {
+	ch := make(chan processingRequest)
+	go func() {
+		defer close(ch)
+		for {
+			req, err := srv.Recv()
+			select {
+			case ch <- processingRequest{req: req, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
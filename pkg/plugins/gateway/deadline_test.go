@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProcessServer struct {
+	extProcPb.ExternalProcessor_ProcessServer
+	requests []*extProcPb.ProcessingRequest
+	err      error
+}
+
+func (f *fakeProcessServer) Recv() (*extProcPb.ProcessingRequest, error) {
+	if len(f.requests) == 0 {
+		return nil, f.err
+	}
+	req := f.requests[0]
+	f.requests = f.requests[1:]
+	return req, nil
+}
+
+func TestRemainingTimeout(t *testing.T) {
+	assert.Equal(t, time.Minute, remainingTimeout(context.Background(), time.Minute))
+
+	ctx := withRequestStart(context.Background(), time.Now().Add(-20*time.Second))
+	remaining := remainingTimeout(ctx, time.Minute)
+	assert.True(t, remaining <= 40*time.Second && remaining > 39*time.Second, "remaining %v", remaining)
+	assert.Negative(t, remainingTimeout(ctx, 10*time.Second))
+}
+
+func TestReceive(t *testing.T) {
+	req := &extProcPb.ProcessingRequest{}
+	requests := receive(context.Background(), &fakeProcessServer{requests: []*extProcPb.ProcessingRequest{req}, err: io.EOF})
+
+	r := <-requests
+	assert.Same(t, req, r.req)
+	assert.NoError(t, r.err)
+	r = <-requests
+	assert.Equal(t, io.EOF, r.err)
+	_, ok := <-requests
+	assert.False(t, ok, "channel is closed after an error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	requests = receive(ctx, &fakeProcessServer{err: errors.New("reset")})
+	cancel()
+	for range requests {
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cacheapi"
+)
+
+// DebugState is a sanitized dump of the cache state of the gateway: pod specs, requests and routing history are left
+// out.
+type DebugState struct {
+	Time        time.Time
+	Goroutines  int
+	Models      []*cacheapi.ModelInfo
+	Pods        []*cacheapi.PodInfo
+	PrefixCache *cacheapi.PrefixCacheStats
+}
+
+// debugStateJSON is the JSON form of DebugState, the messages of the cache service are in their protobuf JSON form.
+type debugStateJSON struct {
+	Time        time.Time         `json:"time"`
+	Goroutines  int               `json:"goroutines"`
+	Models      []json.RawMessage `json:"models"`
+	Pods        []json.RawMessage `json:"pods"`
+	PrefixCache json.RawMessage   `json:"prefixCache"`
+}
+
+func (s DebugState) MarshalJSON() ([]byte, error) {
+	state := debugStateJSON{Time: s.Time, Goroutines: s.Goroutines, Models: []json.RawMessage{}, Pods: []json.RawMessage{}}
+	for _, model := range s.Models {
+		data, err := protojson.Marshal(model)
+		if err != nil {
+			return nil, err
+		}
+		state.Models = append(state.Models, data)
+	}
+	for _, pod := range s.Pods {
+		data, err := protojson.Marshal(pod)
+		if err != nil {
+			return nil, err
+		}
+		state.Pods = append(state.Pods, data)
+	}
+	var err error
+	if state.PrefixCache, err = protojson.Marshal(s.PrefixCache); err != nil {
+		return nil, err
+	}
+	return json.Marshal(state)
+}
+
+// DebugHandler serves the diagnostics of the gateway:
+//   - /debug/pprof/ the pprof profiles, e.g. /debug/pprof/profile?seconds=30 for the CPU profile.
+//   - /debug/goroutines the stacks of all the goroutines.
+//   - /debug/cache the DebugState as JSON.
+//
+// It exposes the internals of the gateway and must only be served on an opt-in, non public port.
+func DebugHandler(s *Server) http.Handler {
+	return debugHandler(NewCacheService(s).(*cacheService))
+}
+
+func debugHandler(service *cacheService) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/goroutines", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := rpprof.Lookup("goroutine").WriteTo(w, 2); err != nil {
+			klog.ErrorS(err, "failed to write goroutine dump")
+		}
+	})
+	mux.HandleFunc("/debug/cache", func(w http.ResponseWriter, r *http.Request) {
+		state := DebugState{Time: time.Now(), Goroutines: runtime.NumGoroutine()}
+		models, err := service.ListModels(r.Context(), &cacheapi.ListModelsRequest{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		pods, err := service.ListPods(r.Context(), &cacheapi.ListPodsRequest{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if state.PrefixCache, err = service.GetPrefixCacheStats(r.Context(), &cacheapi.GetPrefixCacheStatsRequest{}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		state.Models, state.Pods = models.Models, pods.Pods
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(state); err != nil {
+			klog.ErrorS(err, "failed to encode debug state")
+		}
+	})
+	return mux
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/cacheapi"
+)
+
+func TestDebugHandler(t *testing.T) {
+	c := cache.New()
+	pod := newModelPod("p1", "llama-7b-5d4f8", "5d4f8", true)
+	pod.Spec.Containers = []v1.Container{{Name: "vllm", Env: []v1.EnvVar{{Name: "HF_TOKEN", Value: "secret"}}}}
+	c.SetPod(pod, "llama-7b")
+	handler := debugHandler(&cacheService{cache: c, modelConfigs: newModelConfigStore()})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/cache", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret")
+	var state debugStateJSON
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &state))
+	require.Len(t, state.Models, 1)
+	model := &cacheapi.ModelInfo{}
+	require.NoError(t, protojson.Unmarshal(state.Models[0], model))
+	assert.Equal(t, "llama-7b", model.Name)
+	require.Len(t, state.Pods, 1)
+	podInfo := &cacheapi.PodInfo{}
+	require.NoError(t, protojson.Unmarshal(state.Pods[0], podInfo))
+	assert.Equal(t, "p1", podInfo.Name)
+	assert.Equal(t, "10.0.0.1", podInfo.Ip)
+	assert.JSONEq(t, "{}", string(state.PrefixCache))
+	assert.Positive(t, state.Goroutines)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/goroutines", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "TestDebugHandler")
+}
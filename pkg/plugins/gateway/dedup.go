@@ -0,0 +1,225 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/responsecache"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// HeaderDedup set to false on a request opts it out of coalescing. On responses, coalesced marks the response
+	// shared from a concurrent identical request.
+	HeaderDedup = "x-aibrix-dedup"
+
+	// EnvRequestDedup set to true enables the coalescing of concurrent identical requests.
+	EnvRequestDedup = "AIBRIX_REQUEST_DEDUP"
+)
+
+var coalescedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "aibrix_gateway_coalesced_requests_total",
+	Help: "Requests that waited for a concurrent identical request, by model and result: shared its response or fallback to their own.",
+}, []string{"model", "result"})
+
+func init() {
+	prometheus.MustRegister(coalescedRequests)
+}
+
+// loadRequestDeduplicator returns the deduplicator of the gateway, nil if coalescing is disabled, the default.
+func loadRequestDeduplicator() *requestDeduplicator {
+	value := utils.LoadEnv(EnvRequestDedup, "false")
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		klog.Warningf("invalid %s: %s, falling back to default", EnvRequestDedup, value)
+		enabled = false
+	}
+	if !enabled {
+		return nil
+	}
+	return newRequestDeduplicator()
+}
+
+// inflightRequest is a request sent to the engines that identical requests wait for.
+type inflightRequest struct {
+	done chan struct{}
+	// body is the response of the engine, nil if the request failed.
+	body []byte
+}
+
+// requestDeduplicator coalesces concurrent identical requests, e.g. retry storms. The first one leads and is sent to
+// the engines, the followers wait for its response.
+type requestDeduplicator struct {
+	mu       sync.Mutex
+	inflight map[string]*inflightRequest
+}
+
+func newRequestDeduplicator() *requestDeduplicator {
+	return &requestDeduplicator{inflight: map[string]*inflightRequest{}}
+}
+
+// join returns the in-flight request with the key, true if the caller leads it.
+func (d *requestDeduplicator) join(key string) (*inflightRequest, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if request, ok := d.inflight[key]; ok {
+		return request, false
+	}
+	request := &inflightRequest{done: make(chan struct{})}
+	d.inflight[key] = request
+	return request, true
+}
+
+// finish hands the response of the leader to the followers, a nil body makes them send their own request. Only the
+// first call for a request has an effect.
+func (d *requestDeduplicator) finish(key string, request *inflightRequest, body []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.inflight[key] != request {
+		return
+	}
+	delete(d.inflight, key)
+	request.body = body
+	close(request.done)
+}
+
+// requestDedup is the coalescing state of a request.
+type requestDedup struct {
+	optOut bool
+	// key and leading are set when the request leads identical ones.
+	key     string
+	leading *inflightRequest
+}
+
+type requestDedupKey struct{}
+
+// withRequestDedup holds the coalescing state of the request, opted out by the x-aibrix-dedup: false header.
+func withRequestDedup(ctx context.Context, headers []*configPb.HeaderValue) context.Context {
+	dedup := &requestDedup{}
+	for _, header := range headers {
+		if strings.ToLower(header.Key) == HeaderDedup {
+			if enabled, err := strconv.ParseBool(string(header.RawValue)); err == nil && !enabled {
+				dedup.optOut = true
+			}
+		}
+	}
+	return context.WithValue(ctx, requestDedupKey{}, dedup)
+}
+
+func requestDedupFrom(ctx context.Context) *requestDedup {
+	dedup, _ := ctx.Value(requestDedupKey{}).(*requestDedup)
+	return dedup
+}
+
+// deterministicRequest returns true if the engines answer the request with the same response every time: embeddings
+// and rerank requests, and completions sampled greedily, with a temperature of 0, or with a seed.
+func deterministicRequest(endpoint string, body map[string]interface{}) bool {
+	if endpoint != EndpointCompletions && endpoint != EndpointChatCompletions {
+		return true
+	}
+	if temperature, ok := body["temperature"].(float64); ok && temperature == 0 {
+		return true
+	}
+	_, seeded := body["seed"].(float64)
+	return seeded
+}
+
+// awaitIdenticalRequest answers the request with the response of a concurrent identical request of the tenant of the
+// user, nil if the request leads, opted out, is sampled or the identical request failed, i.e. it must be sent to the
+// engines. The tokens of a shared response count towards the TPM limit of the user.
+func (s *Server) awaitIdenticalRequest(ctx context.Context, requestID string, user utils.User, model, externalModel, endpoint string, modelConfig ModelConfig, body map[string]interface{}) *extProcPb.ProcessingResponse {
+	dedup := requestDedupFrom(ctx)
+	if s.dedup == nil || dedup == nil || dedup.optOut || !deterministicRequest(endpoint, body) {
+		return nil
+	}
+	key, ok := responsecache.Key(user.TenantID(), model, endpoint, body)
+	if !ok {
+		return nil
+	}
+	request, leader := s.dedup.join(key)
+	if leader {
+		dedup.key, dedup.leading = key, request
+		return nil
+	}
+
+	klog.InfoS("waiting for an identical request", "requestID", requestID, "model", model)
+	var timeout <-chan time.Time
+	if modelConfig.RequestTimeout > 0 {
+		timer := time.NewTimer(remainingTimeout(ctx, modelConfig.RequestTimeout))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-request.done:
+	case <-timeout:
+		coalescedRequests.WithLabelValues(model, "fallback").Inc()
+		return nil
+	case <-ctx.Done():
+		return nil
+	}
+	if request.body == nil {
+		klog.InfoS("identical request failed, sending the request", "requestID", requestID, "model", model)
+		coalescedRequests.WithLabelValues(model, "fallback").Inc()
+		return nil
+	}
+	coalescedRequests.WithLabelValues(model, "shared").Inc()
+	s.countSharedTokens(ctx, requestID, user, request.body)
+	return s.completedResponse(ctx, requestID, model, externalModel, endpoint, request.body, []*configPb.HeaderValueOption{
+		{Header: &configPb.HeaderValue{Key: HeaderDedup, RawValue: []byte("coalesced")}},
+	})
+}
+
+// countSharedTokens counts the tokens of a response shared with the request towards the TPM limit of the user, as if
+// the engines served it.
+func (s *Server) countSharedTokens(ctx context.Context, requestID string, user utils.User, body []byte) {
+	if user.Name == "" || s.ratelimiter == nil {
+		return
+	}
+	var response struct {
+		Usage struct {
+			TotalTokens int64 `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || response.Usage.TotalTokens == 0 {
+		return
+	}
+	if _, err := s.ratelimiter.Incr(ctx, fmt.Sprintf("%v_TPM_CURRENT", user.Name), response.Usage.TotalTokens); err != nil {
+		klog.ErrorS(err, "failed to count the tokens of a coalesced request", "requestID", requestID, "username", user.Name)
+	}
+}
+
+// finishIdenticalRequests hands the response of a leading request to the requests waiting for it, nil makes them
+// send their own.
+func (s *Server) finishIdenticalRequests(ctx context.Context, body []byte) {
+	dedup := requestDedupFrom(ctx)
+	if s.dedup == nil || dedup == nil || dedup.leading == nil {
+		return
+	}
+	s.dedup.finish(dedup.key, dedup.leading, body)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+func TestRequestDeduplicator(t *testing.T) {
+	d := newRequestDeduplicator()
+	leading, leader := d.join("a")
+	assert.True(t, leader)
+	following, leader := d.join("a")
+	assert.False(t, leader)
+	assert.Same(t, leading, following)
+	_, leader = d.join("b")
+	assert.True(t, leader)
+
+	d.finish("a", leading, []byte("response"))
+	<-following.done
+	assert.Equal(t, "response", string(following.body))
+	// finishing again has no effect, the next request leads.
+	d.finish("a", leading, nil)
+	assert.Equal(t, "response", string(following.body))
+	_, leader = d.join("a")
+	assert.True(t, leader)
+}
+
+func TestDeterministicRequest(t *testing.T) {
+	assert.True(t, deterministicRequest(EndpointEmbeddings, map[string]interface{}{"input": "hello"}))
+	assert.True(t, deterministicRequest(EndpointCompletions, map[string]interface{}{"temperature": 0.0}))
+	assert.True(t, deterministicRequest(EndpointChatCompletions, map[string]interface{}{"temperature": 0.7, "seed": 42.0}))
+	assert.False(t, deterministicRequest(EndpointChatCompletions, map[string]interface{}{}))
+	assert.False(t, deterministicRequest(EndpointCompletions, map[string]interface{}{"temperature": 0.7}))
+}
+
+func TestAwaitIdenticalRequest(t *testing.T) {
+	limiter := fakeRateLimiter{}
+	s := &Server{dedup: newRequestDeduplicator(), ratelimiter: limiter}
+	alice := utils.User{Name: "alice", Tenant: "team-a"}
+	body := func(prompt string) map[string]interface{} {
+		return map[string]interface{}{"model": "llama", "prompt": prompt, "temperature": 0.0}
+	}
+	await := func(ctx context.Context, prompt string) <-chan *extProcPb.ProcessingResponse {
+		responses := make(chan *extProcPb.ProcessingResponse, 1)
+		go func() {
+			responses <- s.awaitIdenticalRequest(ctx, "follower", utils.User{Name: "bob", Tenant: "team-a"}, "llama", "llama", EndpointCompletions, ModelConfig{}, body(prompt))
+		}()
+		return responses
+	}
+
+	// the first request leads, identical ones wait for its response.
+	leaderCtx := withRequestDedup(context.Background(), nil)
+	assert.Nil(t, s.awaitIdenticalRequest(leaderCtx, "leader", alice, "llama", "llama", EndpointCompletions, ModelConfig{}, body("hello")))
+	follower := await(withRequestDedup(context.Background(), nil), "hello")
+	assert.Eventually(t, func() bool {
+		s.dedup.mu.Lock()
+		defer s.dedup.mu.Unlock()
+		return len(s.dedup.inflight) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// requests opting out don't wait.
+	optOut := withRequestDedup(context.Background(), []*configPb.HeaderValue{{Key: HeaderDedup, RawValue: []byte("false")}})
+	assert.Nil(t, s.awaitIdenticalRequest(optOut, "opt-out", alice, "llama", "llama", EndpointCompletions, ModelConfig{}, body("hello")))
+
+	// sampled requests and the requests of other tenants don't wait.
+	sampled := body("hello")
+	sampled["temperature"] = 0.7
+	assert.Nil(t, s.awaitIdenticalRequest(withRequestDedup(context.Background(), nil), "sampled", alice, "llama", "llama", EndpointCompletions, ModelConfig{}, sampled))
+	otherTenant := withRequestDedup(context.Background(), nil)
+	assert.Nil(t, s.awaitIdenticalRequest(otherTenant, "other-tenant", utils.User{Name: "carol", Tenant: "team-b"}, "llama", "llama", EndpointCompletions, ModelConfig{}, body("hello")))
+	s.finishIdenticalRequests(otherTenant, nil)
+
+	// the tokens of the shared response count towards the TPM of the follower.
+	s.finishIdenticalRequests(leaderCtx, []byte(`{"model":"llama","choices":[{"text":"world"}],"usage":{"total_tokens":12}}`))
+	resp := <-follower
+	assert.NotNil(t, resp)
+	assert.Equal(t, `{"model":"llama","choices":[{"text":"world"}],"usage":{"total_tokens":12}}`, resp.GetImmediateResponse().GetBody())
+	assert.Equal(t, HeaderDedup, resp.GetImmediateResponse().GetHeaders().GetSetHeaders()[0].GetHeader().GetKey())
+	assert.Equal(t, int64(12), limiter["bob_TPM_CURRENT"])
+
+	// the followers of a failed request send their own.
+	leaderCtx = withRequestDedup(context.Background(), nil)
+	assert.Nil(t, s.awaitIdenticalRequest(leaderCtx, "leader", alice, "llama", "llama", EndpointCompletions, ModelConfig{}, body("failing")))
+	follower = await(withRequestDedup(context.Background(), nil), "failing")
+	time.Sleep(10 * time.Millisecond)
+	s.finishIdenticalRequests(leaderCtx, nil)
+	assert.Nil(t, <-follower)
+
+	// a follower gives up at the request timeout.
+	leaderCtx = withRequestDedup(context.Background(), nil)
+	assert.Nil(t, s.awaitIdenticalRequest(leaderCtx, "leader", alice, "llama", "llama", EndpointCompletions, ModelConfig{}, body("slow")))
+	assert.Nil(t, s.awaitIdenticalRequest(withRequestDedup(context.Background(), nil), "follower", alice, "llama", "llama",
+		EndpointCompletions, ModelConfig{RequestTimeout: 10 * time.Millisecond}, body("slow")))
+	s.finishIdenticalRequests(leaderCtx, nil)
+
+	// streamed requests are not coalesced.
+	streamed := body("hello")
+	streamed["stream"] = true
+	ctx := withRequestDedup(context.Background(), nil)
+	assert.Nil(t, s.awaitIdenticalRequest(ctx, "streamed", alice, "llama", "llama", EndpointCompletions, ModelConfig{}, streamed))
+	assert.Nil(t, requestDedupFrom(ctx).leading)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+// DrainStatus reports the in-flight requests the gateway routed to a pod.
+type DrainStatus struct {
+	Pod      string `json:"pod"`
+	Draining bool   `json:"draining"`
+	Drained  bool   `json:"drained"`
+	Inflight int32  `json:"inflight"`
+	// RemoteInflight are the in-flight requests the other gateway replicas reported for the pod.
+	RemoteInflight int32 `json:"remoteInflight"`
+}
+
+// DrainHandler serves GET /drain?pod=<name>. It responds 503 while the gateway replicas still have requests in-flight
+// on the pod, so a preStop hook of the engine or the PodAutoscaler can poll it until the pod is drained.
+func DrainHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		podName := r.URL.Query().Get("pod")
+		if podName == "" {
+			http.Error(w, "pod query parameter is required", http.StatusBadRequest)
+			return
+		}
+		c, err := cache.GetCache()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		status := DrainStatus{
+			Pod:            podName,
+			Draining:       c.IsPodDraining(podName),
+			Drained:        c.IsPodDrained(podName),
+			Inflight:       c.GetPodInflightRequests(podName),
+			RemoteInflight: c.GetPodRemoteInflightBatchItems(podName),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if status.Inflight > 0 || status.RemoteInflight > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			klog.ErrorS(err, "failed to encode drain status")
+		}
+	})
+}
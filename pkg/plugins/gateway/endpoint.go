@@ -0,0 +1,219 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+)
+
+const (
+	EndpointChatCompletions = "chat_completions"
+	EndpointCompletions     = "completions"
+	EndpointEmbeddings      = "embeddings"
+	// EndpointRerank covers the rerank APIs of Jina and Cohere and the score API of vLLM, scoring documents
+	// against a query.
+	EndpointRerank = "rerank"
+)
+
+// endpointPaths maps the request paths to their endpoint, unknown paths are handled as chat completions.
+var endpointPaths = map[string]string{
+	"/v1/chat/completions": EndpointChatCompletions,
+	"/v1/completions":      EndpointCompletions,
+	"/v1/embeddings":       EndpointEmbeddings,
+	"/rerank":              EndpointRerank,
+	"/v1/rerank":           EndpointRerank,
+	"/v2/rerank":           EndpointRerank,
+	"/score":               EndpointRerank,
+	"/v1/score":            EndpointRerank,
+}
+
+// getEndpoint returns the endpoint of the request from its :path header.
+func getEndpoint(headers []*configPb.HeaderValue) string {
+	for _, header := range headers {
+		if header.Key != ":path" {
+			continue
+		}
+		path := string(header.RawValue)
+		if i := strings.IndexByte(path, '?'); i >= 0 {
+			path = path[:i]
+		}
+		if endpoint, ok := endpointPaths[strings.TrimSuffix(path, "/")]; ok {
+			return endpoint
+		}
+	}
+	return EndpointChatCompletions
+}
+
+// requestInput is what the gateway routes and admits a request on.
+type requestInput struct {
+	// message is handed to the routers, the JSON messages of chat completions and the texts of other requests.
+	message string
+	// texts are counted one by one, e.g. the inputs of an embedding request. Chat messages are one JSON text.
+	texts []string
+	// query is scored against every text of a rerank request, so it is counted once per text.
+	query string
+	// tokens is the number of inputs given as token ids.
+	tokens int
+	// batchSize is the number of inputs the engine serves as separate requests, 1 for chat and completions.
+	batchSize int
+	// maxTokens is the max number of tokens to generate of chat and completions, 0 if not set.
+	maxTokens int
+	// capabilities are the engine capabilities chat and completions require, see requiredCapabilities.
+	capabilities []string
+	// images are the image parts of chat messages, their tokens are estimated once the model is known.
+	images      []imageInput
+	imageTokens int
+}
+
+// parseRequestInput extracts the input of a request to the endpoint.
+func parseRequestInput(endpoint string, jsonMap map[string]interface{}) (requestInput, error) {
+	input := requestInput{batchSize: 1}
+	switch endpoint {
+	case EndpointChatCompletions:
+		messages, ok := jsonMap["messages"]
+		if !ok {
+			return input, fmt.Errorf("no messages in the request body")
+		}
+		messagesJSON, err := json.Marshal(messages)
+		if err != nil {
+			return input, fmt.Errorf("unable to marshal messages from request body")
+		}
+		input.message = string(messagesJSON)
+		input.texts = []string{input.message}
+		input.images = parseImages(messages)
+		input.maxTokens = requestMaxTokens(jsonMap)
+		input.capabilities = requiredCapabilities(jsonMap)
+		return input, nil
+	case EndpointCompletions:
+		input.maxTokens = requestMaxTokens(jsonMap)
+		input.capabilities = requiredCapabilities(jsonMap)
+		return input, input.addTexts(jsonMap["prompt"], "prompt", true)
+	case EndpointEmbeddings:
+		return input, input.addTexts(jsonMap["input"], "input", true)
+	case EndpointRerank:
+		if query, ok := jsonMap["query"]; ok {
+			// rerank: a query and its documents, strings or objects with a text.
+			if input.query, ok = query.(string); !ok {
+				return input, fmt.Errorf("query must be a string")
+			}
+			if err := input.addTexts(jsonMap["documents"], "documents", true); err != nil {
+				return input, err
+			}
+		} else {
+			// score: text_1 against each of text_2, or pairwise when both are lists.
+			if query, ok := jsonMap["text_1"].(string); ok {
+				input.query = query
+			} else if err := input.addTexts(jsonMap["text_1"], "text_1", false); err != nil {
+				return input, err
+			}
+			if err := input.addTexts(jsonMap["text_2"], "text_2", true); err != nil {
+				return input, err
+			}
+		}
+		if input.query != "" {
+			input.message = input.query + "\n" + input.message
+		}
+		return input, nil
+	}
+	return input, fmt.Errorf("unknown endpoint %s", endpoint)
+}
+
+// requestMaxTokens returns the max_completion_tokens of the request, or its deprecated max_tokens.
+func requestMaxTokens(jsonMap map[string]interface{}) int {
+	for _, key := range []string{"max_completion_tokens", "max_tokens"} {
+		if value, ok := jsonMap[key].(float64); ok && value > 0 {
+			return int(value)
+		}
+	}
+	return 0
+}
+
+// addTexts adds a string, a list of strings or of objects with a text, or token ids. Each element of a list is an
+// input of the batch when batched is true.
+func (in *requestInput) addTexts(value interface{}, field string, batched bool) error {
+	var texts []string
+	var tokens, items int
+	switch v := value.(type) {
+	case string:
+		texts, items = []string{v}, 1
+	case []interface{}:
+		for _, element := range v {
+			switch e := element.(type) {
+			case string:
+				texts = append(texts, e)
+			case float64:
+				// a single prompt of token ids.
+				tokens++
+				continue
+			case []interface{}:
+				tokens += len(e)
+			case map[string]interface{}:
+				text, ok := e["text"].(string)
+				if !ok {
+					return fmt.Errorf("%s must be strings or objects with a text", field)
+				}
+				texts = append(texts, text)
+			default:
+				return fmt.Errorf("%s must be strings or token ids", field)
+			}
+			items++
+		}
+		if items == 0 && tokens > 0 {
+			items = 1
+		}
+	default:
+		return fmt.Errorf("no %s in the request body", field)
+	}
+	if items == 0 {
+		return fmt.Errorf("empty %s in the request body", field)
+	}
+
+	in.texts = append(in.texts, texts...)
+	in.tokens += tokens
+	if batched {
+		in.batchSize = items
+	}
+	in.message = strings.Join(in.texts, "\n")
+	return nil
+}
+
+// countTokens counts the input tokens the engine processes for the model, approximately for large inputs.
+func (in requestInput) countTokens(model string) (int, error) {
+	count := in.tokens + in.imageTokens
+	var queryTokens int
+	if in.query != "" {
+		tokens, err := routing.CountPromptTokens(model, in.query)
+		if err != nil {
+			return 0, err
+		}
+		queryTokens = tokens
+	}
+	for _, text := range in.texts {
+		tokens, err := routing.CountPromptTokens(model, text)
+		if err != nil {
+			return 0, err
+		}
+		count += tokens + queryTokens
+	}
+	return count, nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"testing"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEndpoint(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/v1/chat/completions": EndpointChatCompletions,
+		"/v1/completions":      EndpointCompletions,
+		"/v1/embeddings/":      EndpointEmbeddings,
+		"/v1/rerank?x=1":       EndpointRerank,
+		"/v2/rerank":           EndpointRerank,
+		"/score":               EndpointRerank,
+		"/v1/unknown":          EndpointChatCompletions,
+	} {
+		headers := []*configPb.HeaderValue{{Key: ":method", RawValue: []byte("POST")}, {Key: ":path", RawValue: []byte(path)}}
+		assert.Equal(t, expected, getEndpoint(headers), path)
+	}
+	assert.Equal(t, EndpointChatCompletions, getEndpoint(nil))
+}
+
+func TestParseRequestInput(t *testing.T) {
+	testCases := []struct {
+		name      string
+		endpoint  string
+		body      string
+		message   string
+		texts     []string
+		query     string
+		tokens    int
+		batchSize int
+		maxTokens int
+		err       bool
+	}{
+		{
+			name:      "chat",
+			endpoint:  EndpointChatCompletions,
+			body:      `{"messages": [{"role": "user", "content": "hi"}]}`,
+			message:   `[{"content":"hi","role":"user"}]`,
+			texts:     []string{`[{"content":"hi","role":"user"}]`},
+			batchSize: 1,
+		},
+		{
+			name:     "chat without messages",
+			endpoint: EndpointChatCompletions,
+			body:     `{"prompt": "hi"}`,
+			err:      true,
+		},
+		{
+			name:      "completion",
+			endpoint:  EndpointCompletions,
+			body:      `{"prompt": "hi"}`,
+			message:   "hi",
+			texts:     []string{"hi"},
+			batchSize: 1,
+		},
+		{
+			name:      "chat with max tokens",
+			endpoint:  EndpointChatCompletions,
+			body:      `{"messages": [{"role": "user", "content": "hi"}], "max_tokens": 16, "max_completion_tokens": 32}`,
+			message:   `[{"content":"hi","role":"user"}]`,
+			texts:     []string{`[{"content":"hi","role":"user"}]`},
+			batchSize: 1,
+			maxTokens: 32,
+		},
+		{
+			name:      "completion with max tokens",
+			endpoint:  EndpointCompletions,
+			body:      `{"prompt": "hi", "max_tokens": 16}`,
+			message:   "hi",
+			texts:     []string{"hi"},
+			batchSize: 1,
+			maxTokens: 16,
+		},
+		{
+			name:      "completion of token ids",
+			endpoint:  EndpointCompletions,
+			body:      `{"prompt": [1, 2, 3]}`,
+			tokens:    3,
+			batchSize: 1,
+		},
+		{
+			name:      "embedding batch",
+			endpoint:  EndpointEmbeddings,
+			body:      `{"input": ["a", "b", "c"]}`,
+			message:   "a\nb\nc",
+			texts:     []string{"a", "b", "c"},
+			batchSize: 3,
+		},
+		{
+			name:      "embedding batch of token ids",
+			endpoint:  EndpointEmbeddings,
+			body:      `{"input": [[1, 2], [3, 4, 5]]}`,
+			tokens:    5,
+			batchSize: 2,
+		},
+		{
+			name:     "embedding without input",
+			endpoint: EndpointEmbeddings,
+			body:     `{"input": []}`,
+			err:      true,
+		},
+		{
+			name:      "rerank",
+			endpoint:  EndpointRerank,
+			body:      `{"query": "q", "documents": ["a", {"text": "b"}]}`,
+			message:   "q\na\nb",
+			texts:     []string{"a", "b"},
+			query:     "q",
+			batchSize: 2,
+		},
+		{
+			name:     "rerank with invalid documents",
+			endpoint: EndpointRerank,
+			body:     `{"query": "q", "documents": [{"image": "b"}]}`,
+			err:      true,
+		},
+		{
+			name:      "score",
+			endpoint:  EndpointRerank,
+			body:      `{"text_1": "q", "text_2": ["a", "b", "c"]}`,
+			message:   "q\na\nb\nc",
+			texts:     []string{"a", "b", "c"},
+			query:     "q",
+			batchSize: 3,
+		},
+		{
+			name:      "pairwise score",
+			endpoint:  EndpointRerank,
+			body:      `{"text_1": ["q1", "q2"], "text_2": ["a", "b"]}`,
+			message:   "q1\nq2\na\nb",
+			texts:     []string{"q1", "q2", "a", "b"},
+			batchSize: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		var jsonMap map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(tc.body), &jsonMap))
+		input, err := parseRequestInput(tc.endpoint, jsonMap)
+		if tc.err {
+			assert.Error(t, err, tc.name)
+			continue
+		}
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.message, input.message, tc.name)
+		assert.Equal(t, tc.texts, input.texts, tc.name)
+		assert.Equal(t, tc.query, input.query, tc.name)
+		assert.Equal(t, tc.tokens, input.tokens, tc.name)
+		assert.Equal(t, tc.batchSize, input.batchSize, tc.name)
+		assert.Equal(t, tc.maxTokens, input.maxTokens, tc.name)
+	}
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultFailoverProbeIntervalInMS = 5000
+	defaultFailoverFailureThreshold  = 3
+	defaultFailoverSuccessThreshold  = 2
+	defaultFailbackDelayInS          = 30
+	defaultFailoverHealthPath        = "/v1/models"
+
+	failoverEvent = "failover"
+	failbackEvent = "failback"
+)
+
+var (
+	failoverEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_failover_events_total",
+		Help: "Failovers of a model to a remote endpoint and failbacks to its pods in the cluster.",
+	}, []string{"model", "endpoint", "event"})
+	failoverActive = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "aibrix_gateway_failover_active",
+		Help: "1 while the requests of the model are sent to a remote endpoint.",
+	}, []string{"model"})
+	failoverRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_failover_requests_total",
+		Help: "Requests sent to a remote failover endpoint.",
+	}, []string{"model", "endpoint"})
+	failoverEndpointHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "aibrix_gateway_failover_endpoint_healthy",
+		Help: "1 while the remote failover endpoint passes its health probes.",
+	}, []string{"endpoint"})
+)
+
+func init() {
+	prometheus.MustRegister(failoverEvents, failoverActive, failoverRequests, failoverEndpointHealthy)
+}
+
+// failoverEndpoint is the health of a remote endpoint. It turns unhealthy after failureThreshold failed probes in a
+// row and healthy again after successThreshold successful ones, or its first one.
+type failoverEndpoint struct {
+	healthy bool
+	// probed is false until the first probe completed.
+	probed    bool
+	successes int
+	failures  int
+	// target is the ip:port envoy sends the requests to, the address resolved by the last successful probe.
+	target string
+}
+
+// modelFailover is the failover state of a model.
+type modelFailover struct {
+	endpoint string
+	// readySince is when the pods of the model in the cluster are ready again, zero while none is.
+	readySince time.Time
+}
+
+// failoverRouter sends the requests of models without ready pods in the cluster to remote endpoints. A model fails back
+// once its pods have been ready for the failback delay, so that a flapping pod doesn't move the traffic back and forth.
+type failoverRouter struct {
+	mu        sync.Mutex
+	endpoints map[string]*failoverEndpoint // address: *failoverEndpoint
+	models    map[string]*modelFailover    // model_name: *modelFailover
+
+	client           *http.Client
+	resolver         *net.Resolver
+	probeInterval    time.Duration
+	failureThreshold int
+	successThreshold int
+	failbackDelay    time.Duration
+	now              func() time.Time
+}
+
+func newFailoverRouter() *failoverRouter {
+	probeInterval := time.Duration(getPositiveIntEnv("AIBRIX_FAILOVER_PROBE_INTERVAL_MS", defaultFailoverProbeIntervalInMS)) * time.Millisecond
+	r := &failoverRouter{
+		endpoints:        map[string]*failoverEndpoint{},
+		models:           map[string]*modelFailover{},
+		client:           &http.Client{Timeout: probeInterval},
+		resolver:         net.DefaultResolver,
+		probeInterval:    probeInterval,
+		failureThreshold: getPositiveIntEnv("AIBRIX_FAILOVER_FAILURE_THRESHOLD", defaultFailoverFailureThreshold),
+		successThreshold: getPositiveIntEnv("AIBRIX_FAILOVER_SUCCESS_THRESHOLD", defaultFailoverSuccessThreshold),
+		failbackDelay:    time.Duration(getPositiveIntEnv("AIBRIX_FAILBACK_DELAY_S", defaultFailbackDelayInS)) * time.Second,
+		now:              time.Now,
+	}
+	go r.run()
+	return r
+}
+
+func getPositiveIntEnv(name string, defaultValue int) int {
+	value := utils.LoadEnv(name, "")
+	if value == "" {
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil || intValue <= 0 {
+		klog.Infof("invalid %s: %s, falling back to default", name, value)
+		return defaultValue
+	}
+	klog.Infof("using %s env value: %d", name, intValue)
+	return intValue
+}
+
+// run probes the endpoints of the gateway configuration every probe interval.
+func (r *failoverRouter) run() {
+	ticker := time.NewTicker(r.probeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.probeAll(context.Background(), config.Gateway().Failover)
+	}
+}
+
+func (r *failoverRouter) probeAll(ctx context.Context, endpoints []config.FailoverConfig) {
+	var wg sync.WaitGroup
+	for _, endpoint := range endpoints {
+		wg.Add(1)
+		go func(endpoint config.FailoverConfig) {
+			defer wg.Done()
+			target, err := r.probe(ctx, endpoint)
+			r.recordProbe(endpoint.Address, target, err)
+		}(endpoint)
+	}
+	wg.Wait()
+
+	// forget the endpoints removed from the configuration.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for address := range r.endpoints {
+		if !slices.ContainsFunc(endpoints, func(endpoint config.FailoverConfig) bool { return endpoint.Address == address }) {
+			delete(r.endpoints, address)
+			failoverEndpointHealthy.DeleteLabelValues(address)
+		}
+	}
+}
+
+// probe resolves the address of the endpoint and sends a GET request to its health path, it returns the resolved
+// address if the endpoint responded with 2xx.
+func (r *failoverRouter) probe(ctx context.Context, endpoint config.FailoverConfig) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.probeInterval)
+	defer cancel()
+	host, port, err := net.SplitHostPort(endpoint.Address)
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(host) == nil {
+		ips, err := r.resolver.LookupIP(ctx, "ip4", host)
+		if err != nil || len(ips) == 0 {
+			return "", fmt.Errorf("failed to resolve %s: %w", host, err)
+		}
+		host = ips[0].String()
+	}
+	target := net.JoinHostPort(host, port)
+
+	path := endpoint.HealthPath
+	if path == "" {
+		path = defaultFailoverHealthPath
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+target+path, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("health probe returned %d", resp.StatusCode)
+	}
+	return target, nil
+}
+
+func (r *failoverRouter) recordProbe(address, target string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	endpoint, ok := r.endpoints[address]
+	if !ok {
+		endpoint = &failoverEndpoint{}
+		r.endpoints[address] = endpoint
+	}
+	if err != nil {
+		endpoint.successes = 0
+		endpoint.failures++
+		if endpoint.healthy && endpoint.failures >= r.failureThreshold {
+			endpoint.healthy = false
+			klog.InfoS("failover endpoint is unhealthy", "endpoint", address, "error", err)
+		}
+	} else {
+		endpoint.failures = 0
+		endpoint.successes++
+		endpoint.target = target
+		if !endpoint.healthy && (!endpoint.probed || endpoint.successes >= r.successThreshold) {
+			endpoint.healthy = true
+			klog.InfoS("failover endpoint is healthy", "endpoint", address, "target", target)
+		}
+	}
+	endpoint.probed = true
+	failoverEndpointHealthy.WithLabelValues(address).Set(boolToFloat(endpoint.healthy))
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// route returns the endpoint the request of the model is sent to and the address envoy sends it to, empty if it is
+// served in the cluster. localReady is whether the model has a ready pod in the cluster.
+func (r *failoverRouter) route(model string, localReady bool) (string, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	endpoint, target := r.healthyEndpointLocked(model)
+	state, active := r.models[model]
+	now := r.now()
+	if !active {
+		if localReady || endpoint == "" {
+			return "", ""
+		}
+		r.models[model] = &modelFailover{endpoint: endpoint}
+		failoverEvents.WithLabelValues(model, endpoint, failoverEvent).Inc()
+		failoverActive.WithLabelValues(model).Set(1)
+		klog.InfoS("model failed over to a remote endpoint, no pod is ready in the cluster", "model", model, "endpoint", endpoint)
+		return endpoint, target
+	}
+
+	if !localReady {
+		state.readySince = time.Time{}
+	} else if state.readySince.IsZero() {
+		state.readySince = now
+	}
+	failbackDelay := r.failbackDelay
+	if delay := config.Gateway().FailbackDelay; delay != nil {
+		failbackDelay = delay.Duration
+	}
+	if localReady && now.Sub(state.readySince) >= failbackDelay {
+		delete(r.models, model)
+		failoverEvents.WithLabelValues(model, state.endpoint, failbackEvent).Inc()
+		failoverActive.WithLabelValues(model).Set(0)
+		klog.InfoS("model failed back to the cluster, its pods are ready", "model", model, "endpoint", state.endpoint)
+		return "", ""
+	}
+	// the pods of the cluster serve the requests while no endpoint is healthy, if they can.
+	if endpoint == "" {
+		return "", ""
+	}
+	state.endpoint = endpoint
+	return endpoint, target
+}
+
+// healthyEndpointLocked returns the first healthy endpoint serving the model in the configuration and its target.
+func (r *failoverRouter) healthyEndpointLocked(model string) (string, string) {
+	for _, endpoint := range config.Gateway().Failover {
+		if len(endpoint.Models) > 0 && !slices.Contains(endpoint.Models, model) {
+			continue
+		}
+		if health, ok := r.endpoints[endpoint.Address]; ok && health.healthy {
+			return endpoint.Address, health.target
+		}
+	}
+	return "", ""
+}
+
+// failOver sends the request to the failover endpoint of the model if it has no ready pod in the cluster, or had none
+// recently. It returns a nil response if the request is served in the cluster, e.g. no endpoint is configured for the
+// model, or it was forwarded by another gateway.
+func (s *Server) failOver(ctx context.Context, requestID, model, routingStrategy string, body map[string]interface{}) (*extProcPb.ProcessingResponse, string, bool, int64) {
+	if s.failover == nil || len(config.Gateway().Failover) == 0 || isFederated(ctx) {
+		return nil, "", false, 0
+	}
+	pods, err := s.cache.GetReadyPodsForModel(model)
+	localReady := err == nil && len(utils.FilterReadyPods(pods)) > 0
+	endpoint, target := s.failover.route(model, localReady)
+	if endpoint == "" {
+		return nil, "", false, 0
+	}
+	stream, _ := body["stream"].(bool)
+	failoverRequests.WithLabelValues(model, endpoint).Inc()
+	klog.InfoS("request sent to the failover endpoint", "requestID", requestID, "model", model, "endpoint", endpoint, "target", target)
+	term := s.cache.AddRequestCount(requestID, model)
+	return forwardRequest(ctx, target, routingStrategy), target, stream, term
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+func newTestFailoverRouter(now *time.Time) *failoverRouter {
+	return &failoverRouter{
+		endpoints:        map[string]*failoverEndpoint{},
+		models:           map[string]*modelFailover{},
+		client:           &http.Client{Timeout: time.Second},
+		resolver:         net.DefaultResolver,
+		probeInterval:    time.Second,
+		failureThreshold: 2,
+		successThreshold: 2,
+		failbackDelay:    30 * time.Second,
+		now:              func() time.Time { return *now },
+	}
+}
+
+func TestFailoverProbe(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/health", r.URL.Path)
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+	_, port, err := net.SplitHostPort(address)
+	require.NoError(t, err)
+	endpoints := []config.FailoverConfig{{Address: "localhost:" + port, HealthPath: "/health"}}
+
+	now := time.Now()
+	r := newTestFailoverRouter(&now)
+	r.probeAll(context.Background(), endpoints)
+	assert.True(t, r.endpoints["localhost:"+port].healthy, "the first successful probe makes the endpoint healthy")
+	assert.Equal(t, "127.0.0.1:"+port, r.endpoints["localhost:"+port].target)
+
+	healthy = false
+	r.probeAll(context.Background(), endpoints)
+	assert.True(t, r.endpoints["localhost:"+port].healthy)
+	r.probeAll(context.Background(), endpoints)
+	assert.False(t, r.endpoints["localhost:"+port].healthy)
+
+	healthy = true
+	r.probeAll(context.Background(), endpoints)
+	assert.False(t, r.endpoints["localhost:"+port].healthy)
+	r.probeAll(context.Background(), endpoints)
+	assert.True(t, r.endpoints["localhost:"+port].healthy)
+
+	// the endpoints removed from the configuration are forgotten.
+	r.probeAll(context.Background(), nil)
+	assert.Empty(t, r.endpoints)
+}
+
+func TestFailoverRoute(t *testing.T) {
+	config.SetGateway(config.GatewayConfig{Failover: []config.FailoverConfig{
+		{Address: "us-west:80", Models: []string{"llama"}},
+		{Address: "eu-west:80", Models: []string{"mistral"}},
+	}})
+	defer config.SetGateway(config.GatewayConfig{})
+	now := time.Now()
+	r := newTestFailoverRouter(&now)
+	r.recordProbe("us-west:80", "10.0.0.2:80", nil)
+	r.recordProbe("eu-west:80", "10.0.0.3:80", nil)
+
+	endpoint, _ := r.route("llama", true)
+	assert.Empty(t, endpoint, "the pods of the cluster serve the model")
+	endpoint, target := r.route("llama", false)
+	assert.Equal(t, "us-west:80", endpoint)
+	assert.Equal(t, "10.0.0.2:80", target)
+	endpoint, _ = r.route("mistral", false)
+	assert.Equal(t, "eu-west:80", endpoint, "the endpoints restricted to other models are skipped")
+
+	// the model stays failed over until its pods have been ready for the failback delay.
+	now = now.Add(time.Second)
+	endpoint, _ = r.route("llama", true)
+	assert.Equal(t, "us-west:80", endpoint)
+	now = now.Add(20 * time.Second)
+	endpoint, _ = r.route("llama", false)
+	assert.Equal(t, "us-west:80", endpoint, "a pod failing again restarts the delay")
+	endpoint, _ = r.route("llama", true)
+	assert.Equal(t, "us-west:80", endpoint)
+	now = now.Add(20 * time.Second)
+	endpoint, _ = r.route("llama", true)
+	assert.Equal(t, "us-west:80", endpoint)
+
+	// the pods of the cluster serve the model while no endpoint is healthy.
+	r.recordProbe("us-west:80", "", errors.New("connection refused"))
+	r.recordProbe("us-west:80", "", errors.New("connection refused"))
+	endpoint, _ = r.route("llama", true)
+	assert.Empty(t, endpoint)
+	endpoint, _ = r.route("llama", false)
+	assert.Empty(t, endpoint)
+
+	r.recordProbe("us-west:80", "10.0.0.2:80", nil)
+	r.recordProbe("us-west:80", "10.0.0.2:80", nil)
+	endpoint, _ = r.route("llama", true)
+	assert.Equal(t, "us-west:80", endpoint)
+	now = now.Add(30 * time.Second)
+	endpoint, _ = r.route("llama", true)
+	assert.Empty(t, endpoint, "the model failed back")
+	assert.NotContains(t, r.models, "llama")
+}
@@ -0,0 +1,432 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/provider"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// HeaderProvider is set on the responses served by an external provider to the name of its route.
+	HeaderProvider = "x-aibrix-provider"
+
+	providerSpendKeyPrefix     = "aibrix:provider-spend:"
+	defaultProviderSpendWindow = 24 * time.Hour
+	// providerCredentialsRefresh is how long the credentials read from a Secret are used before reading it again.
+	providerCredentialsRefresh = time.Minute
+	// the spend is counted in micro USD, redis counters are integers.
+	microUSD = 1e6
+	// providerBytesPerToken approximates the prompt tokens of the requests to reserve their spend.
+	providerBytesPerToken = 4
+)
+
+var (
+	providerRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_provider_requests_total",
+		Help: "Requests falling back to an external provider, by result: success, error, unsupported or spend_cap.",
+	}, []string{"model", "provider", "result"})
+	providerTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_provider_tokens_total",
+		Help: "Tokens billed by the external providers, by type: prompt or completion.",
+	}, []string{"model", "provider", "type"})
+	providerSpend = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_provider_spend_usd_total",
+		Help: "Cost in USD of the requests sent to the external providers.",
+	}, []string{"provider"})
+)
+
+func init() {
+	prometheus.MustRegister(providerRequests, providerTokens, providerSpend)
+}
+
+type providerRoute struct {
+	config   config.ProviderConfig
+	provider provider.Provider
+}
+
+type providerCredentials struct {
+	credentials provider.Credentials
+	fetched     time.Time
+}
+
+type providerSpendWindow struct {
+	index int64
+	spend int64
+}
+
+// providerFallback sends the chat completions the cluster has no capacity for to the providers of the gateway
+// configuration, within their spend caps. The spend is shared by the gateway replicas through redis, it is counted per
+// replica without redis.
+type providerFallback struct {
+	redisClient redis.UniversalClient
+	client      kubernetes.Interface
+	namespace   string
+	now         func() time.Time
+
+	mu          sync.Mutex
+	config      *config.GatewayConfig
+	routes      []providerRoute
+	credentials map[string]providerCredentials
+	spend       map[string]providerSpendWindow
+}
+
+func newProviderFallback(redisClient redis.UniversalClient, client kubernetes.Interface) *providerFallback {
+	return &providerFallback{
+		redisClient: redisClient,
+		client:      client,
+		namespace:   utils.LoadEnv("POD_NAMESPACE", "aibrix-system"),
+		now:         time.Now,
+		credentials: map[string]providerCredentials{},
+		spend:       map[string]providerSpendWindow{},
+	}
+}
+
+// routesFor returns the routes of the latest configuration serving the model, in order. The routes are built once per
+// configuration snapshot, the routes failing to build are skipped: they are rejected on reload so this only happens
+// at startup.
+func (f *providerFallback) routesFor(model string) []providerRoute {
+	gatewayConfig := config.Gateway()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.config != gatewayConfig {
+		f.routes = nil
+		for _, route := range gatewayConfig.Providers {
+			p, err := provider.New(route)
+			if err != nil {
+				klog.ErrorS(err, "failed to build the provider, skipping it", "provider", route.Name)
+				continue
+			}
+			f.routes = append(f.routes, providerRoute{config: route, provider: p})
+		}
+		f.config = gatewayConfig
+	}
+	var routes []providerRoute
+	for _, route := range f.routes {
+		if len(route.config.Models) == 0 || slices.Contains(route.config.Models, model) {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// credentialsOf reads the credentials Secret of the route, at most once per providerCredentialsRefresh.
+func (f *providerFallback) credentialsOf(ctx context.Context, route config.ProviderConfig) (provider.Credentials, error) {
+	if route.CredentialsSecret == "" {
+		return nil, nil
+	}
+	f.mu.Lock()
+	cached, ok := f.credentials[route.CredentialsSecret]
+	f.mu.Unlock()
+	if ok && f.now().Sub(cached.fetched) < providerCredentialsRefresh {
+		return cached.credentials, nil
+	}
+	if f.client == nil {
+		return nil, fmt.Errorf("no kubernetes client to read secret %s", route.CredentialsSecret)
+	}
+	secret, err := f.client.CoreV1().Secrets(f.namespace).Get(ctx, route.CredentialsSecret, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider secret %s/%s: %w", f.namespace, route.CredentialsSecret, err)
+	}
+	credentials := make(provider.Credentials, len(secret.Data))
+	for key, value := range secret.Data {
+		credentials[key] = string(bytes.TrimSpace(value))
+	}
+	f.mu.Lock()
+	f.credentials[route.CredentialsSecret] = providerCredentials{credentials: credentials, fetched: f.now()}
+	f.mu.Unlock()
+	return credentials, nil
+}
+
+// spendWindow returns the index of the current spend window of the route and its length.
+func (f *providerFallback) spendWindow(route config.ProviderConfig) (int64, time.Duration) {
+	window := defaultProviderSpendWindow
+	if route.SpendWindow != nil {
+		window = route.SpendWindow.Duration
+	}
+	return f.now().UnixNano() / int64(window), window
+}
+
+func providerSpendKey(route string, index int64) string {
+	return fmt.Sprintf("%s%s:%d", providerSpendKeyPrefix, route, index)
+}
+
+// spent returns the spend of the route in its current window, in micro USD.
+func (f *providerFallback) spent(ctx context.Context, route config.ProviderConfig) (int64, error) {
+	index, _ := f.spendWindow(route)
+	if f.redisClient == nil {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if window := f.spend[route.Name]; window.index == index {
+			return window.spend, nil
+		}
+		return 0, nil
+	}
+	spend, err := f.redisClient.Get(ctx, providerSpendKey(route.Name, index)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return spend, err
+}
+
+// reserveSpend adds the estimated cost of a request to the spend of the route in its current window, in micro USD,
+// unless the spend reached the spend cap. The spend is checked and reserved at once so that the concurrent requests of
+// all the replicas see each other's reservations. It returns the index of the window the spend was reserved in.
+func (f *providerFallback) reserveSpend(ctx context.Context, route config.ProviderConfig, estimate int64) (int64, bool, error) {
+	index, window := f.spendWindow(route)
+	spendCap := int64(route.SpendCap * microUSD)
+	if f.redisClient == nil {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		current := f.spend[route.Name]
+		if current.index != index {
+			current = providerSpendWindow{index: index}
+		}
+		if current.spend >= spendCap {
+			return index, false, nil
+		}
+		current.spend += estimate
+		f.spend[route.Name] = current
+		return index, true, nil
+	}
+	key := providerSpendKey(route.Name, index)
+	pipe := f.redisClient.TxPipeline()
+	incr := pipe.IncrBy(ctx, key, estimate)
+	pipe.Expire(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return index, false, err
+	}
+	if incr.Val()-estimate >= spendCap {
+		if estimate != 0 {
+			if err := f.redisClient.DecrBy(ctx, key, estimate).Err(); err != nil {
+				return index, false, err
+			}
+		}
+		return index, false, nil
+	}
+	return index, true, nil
+}
+
+// addSpend adds a cost, negative to release a reservation, to the spend of the route in the window of the index, in
+// micro USD.
+func (f *providerFallback) addSpend(ctx context.Context, route config.ProviderConfig, index, spend int64) error {
+	_, window := f.spendWindow(route)
+	if f.redisClient == nil {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		current := f.spend[route.Name]
+		if current.index != index {
+			// the window of the reservation is over.
+			return nil
+		}
+		current.spend += spend
+		f.spend[route.Name] = current
+		return nil
+	}
+	key := providerSpendKey(route.Name, index)
+	pipe := f.redisClient.TxPipeline()
+	pipe.IncrBy(ctx, key, spend)
+	pipe.Expire(ctx, key, window)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// cost returns the cost of the usage on the route, in micro USD.
+func cost(route config.ProviderConfig, usage provider.Usage) int64 {
+	return int64(float64(usage.PromptTokens)*route.InputCostPerMillion + float64(usage.CompletionTokens)*route.OutputCostPerMillion)
+}
+
+// estimatedCost returns the cost of the request on the route before sending it, in micro USD: its messages at
+// providerBytesPerToken and its max tokens if set.
+func estimatedCost(route config.ProviderConfig, body map[string]interface{}) int64 {
+	messages, _ := json.Marshal(body["messages"])
+	return cost(route, provider.Usage{
+		PromptTokens:     int64(len(messages) / providerBytesPerToken),
+		CompletionTokens: int64(requestMaxTokens(body)),
+	})
+}
+
+// complete sends the chat completion to the first route serving the model under its spend cap. It returns the route
+// and the response, a nil response if no route could serve the request.
+func (f *providerFallback) complete(ctx context.Context, requestID, model string, body map[string]interface{}) (string, []byte) {
+	for _, route := range f.routesFor(model) {
+		name := route.config.Name
+		// the spend is reserved before the request and adjusted to its cost after.
+		var index, reserved int64
+		if route.config.SpendCap > 0 {
+			reserved = estimatedCost(route.config, body)
+			var ok bool
+			var err error
+			index, ok, err = f.reserveSpend(ctx, route.config, reserved)
+			if err != nil {
+				klog.ErrorS(err, "failed to reserve the provider spend, skipping it", "requestID", requestID, "provider", name)
+				providerRequests.WithLabelValues(model, name, "error").Inc()
+				continue
+			}
+			if !ok {
+				providerRequests.WithLabelValues(model, name, "spend_cap").Inc()
+				continue
+			}
+		} else {
+			index, _ = f.spendWindow(route.config)
+		}
+		release := func() {
+			if reserved == 0 {
+				return
+			}
+			if err := f.addSpend(ctx, route.config, index, -reserved); err != nil {
+				klog.ErrorS(err, "failed to release the provider spend", "requestID", requestID, "provider", name)
+			}
+		}
+		credentials, err := f.credentialsOf(ctx, route.config)
+		if err != nil {
+			klog.ErrorS(err, "failed to get the provider credentials, skipping it", "requestID", requestID, "provider", name)
+			providerRequests.WithLabelValues(model, name, "error").Inc()
+			release()
+			continue
+		}
+		requestCtx, cancel := context.WithTimeout(ctx, provider.Timeout(route.config))
+		response, usage, err := route.provider.ChatCompletion(requestCtx, body, credentials)
+		cancel()
+		if errors.Is(err, provider.ErrUnsupported) {
+			klog.V(4).InfoS("request not supported by the provider", "requestID", requestID, "provider", name, "reason", err)
+			providerRequests.WithLabelValues(model, name, "unsupported").Inc()
+			release()
+			continue
+		}
+		if err != nil {
+			klog.ErrorS(err, "provider request failed", "requestID", requestID, "provider", name)
+			providerRequests.WithLabelValues(model, name, "error").Inc()
+			release()
+			continue
+		}
+
+		providerRequests.WithLabelValues(model, name, "success").Inc()
+		providerTokens.WithLabelValues(model, name, "prompt").Add(float64(usage.PromptTokens))
+		providerTokens.WithLabelValues(model, name, "completion").Add(float64(usage.CompletionTokens))
+		spend := cost(route.config, usage)
+		if spend > 0 {
+			providerSpend.WithLabelValues(name).Add(float64(spend) / microUSD)
+		}
+		if spend != reserved {
+			if err := f.addSpend(ctx, route.config, index, spend-reserved); err != nil {
+				klog.ErrorS(err, "failed to record the provider spend", "requestID", requestID, "provider", name)
+			}
+		}
+		return name, response
+	}
+	return "", nil
+}
+
+// fallBackToProvider answers a chat completion the cluster has no capacity for with an external provider. It returns
+// a nil response if no provider served it, the request is then rejected as it would be without providers.
+func (s *Server) fallBackToProvider(ctx context.Context, requestID, model, externalModel, endpoint string, body map[string]interface{}) *extProcPb.ProcessingResponse {
+	if s.providers == nil || endpoint != EndpointChatCompletions {
+		return nil
+	}
+	name, response := s.providers.complete(ctx, requestID, model, body)
+	if response == nil {
+		return nil
+	}
+	klog.InfoS("request served by an external provider", "requestID", requestID, "model", model, "provider", name)
+	resp := s.completedResponse(ctx, requestID, model, externalModel, endpoint, response, []*configPb.HeaderValueOption{
+		{Header: &configPb.HeaderValue{Key: HeaderProvider, RawValue: []byte(name)}},
+	})
+	if stream, _ := body["stream"].(bool); stream {
+		streamOptions, _ := body["stream_options"].(map[string]interface{})
+		includeUsage, _ := streamOptions["include_usage"].(bool)
+		if err := streamCompletedResponse(resp.GetImmediateResponse(), includeUsage); err != nil {
+			klog.ErrorS(err, "failed to stream the provider response", "requestID", requestID, "provider", name)
+			return nil
+		}
+	}
+	return resp
+}
+
+// streamCompletedResponse turns the chat completion of the response into the server-sent events of a streamed one:
+// a chunk with the whole message, a usage chunk if requested and the end of the stream.
+func streamCompletedResponse(response *extProcPb.ImmediateResponse, includeUsage bool) error {
+	var completion map[string]interface{}
+	if err := json.Unmarshal([]byte(response.Body), &completion); err != nil {
+		return err
+	}
+	chunk := map[string]interface{}{
+		"id":      completion["id"],
+		"object":  "chat.completion.chunk",
+		"created": completion["created"],
+		"model":   completion["model"],
+	}
+	choices, _ := completion["choices"].([]interface{})
+	deltas := make([]interface{}, 0, len(choices))
+	for _, item := range choices {
+		choice, _ := item.(map[string]interface{})
+		deltas = append(deltas, map[string]interface{}{
+			"index":         choice["index"],
+			"delta":         choice["message"],
+			"finish_reason": choice["finish_reason"],
+		})
+	}
+	chunk["choices"] = deltas
+
+	var events bytes.Buffer
+	write := func(chunk map[string]interface{}) error {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		events.WriteString("data: ")
+		events.Write(data)
+		events.WriteString("\n\n")
+		return nil
+	}
+	if err := write(chunk); err != nil {
+		return err
+	}
+	if includeUsage {
+		chunk["choices"] = []interface{}{}
+		chunk["usage"] = completion["usage"]
+		if err := write(chunk); err != nil {
+			return err
+		}
+	}
+	events.WriteString("data: [DONE]\n\n")
+	response.Body = events.String()
+	for _, header := range response.GetHeaders().GetSetHeaders() {
+		if header.Header.Key == "Content-Type" {
+			header.Header.RawValue = []byte("text/event-stream")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+func TestFallBackToProvider(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"id": "chatcmpl-1", "object": "chat.completion", "created": 1, "model": "gpt-4o-mini",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 1000, "completion_tokens": 500, "total_tokens": 1500}}`))
+	}))
+	defer server.Close()
+
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "openai-credentials", Namespace: "aibrix-system"},
+		Data:       map[string][]byte{"api-key": []byte("sk-test\n")},
+	})
+	config.SetGateway(config.GatewayConfig{Providers: []config.ProviderConfig{
+		{Name: "other-model", Type: "openai", BaseURL: server.URL, Models: []string{"other"}},
+		{
+			Name: "openai", Type: "openai", BaseURL: server.URL, Model: "gpt-4o-mini", CredentialsSecret: "openai-credentials",
+			InputCostPerMillion: 1, OutputCostPerMillion: 2, SpendCap: 0.004, SpendWindow: &metav1.Duration{Duration: time.Hour},
+		},
+	}})
+	defer config.SetGateway(config.GatewayConfig{})
+	s := &Server{providers: newProviderFallback(nil, client)}
+	body := map[string]interface{}{"model": "llama", "messages": []interface{}{map[string]interface{}{"role": "user", "content": "hi"}}}
+
+	resp := s.fallBackToProvider(context.Background(), "r1", "llama", "llama", EndpointChatCompletions, body)
+	require.NotNil(t, resp)
+	immediate := resp.GetImmediateResponse()
+	assert.Contains(t, immediate.Body, `"content": "Hello"`)
+	headers := map[string]string{}
+	for _, header := range immediate.GetHeaders().GetSetHeaders() {
+		headers[header.GetHeader().GetKey()] = string(header.GetHeader().GetRawValue())
+	}
+	assert.Equal(t, "openai", headers[HeaderProvider])
+	assert.Equal(t, "application/json", headers["Content-Type"])
+	assert.Equal(t, []string{"Bearer sk-test"}, authorizations)
+
+	// 1000 prompt and 500 completion tokens cost 0.002 USD.
+	spend, err := s.providers.spent(context.Background(), config.Gateway().Providers[1])
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), spend)
+
+	// the streamed requests are answered with server-sent events.
+	stream := map[string]interface{}{
+		"model": "llama", "stream": true, "stream_options": map[string]interface{}{"include_usage": true},
+		"messages": []interface{}{map[string]interface{}{"role": "user", "content": "hi"}},
+	}
+	resp = s.fallBackToProvider(context.Background(), "r2", "llama", "llama", EndpointChatCompletions, stream)
+	require.NotNil(t, resp)
+	events := strings.Split(strings.TrimSpace(resp.GetImmediateResponse().Body), "\n\n")
+	require.Len(t, events, 3)
+	assert.Contains(t, events[0], `"delta":{"content":"Hello","role":"assistant"}`)
+	assert.Contains(t, events[1], `"usage":{"completion_tokens":500`)
+	assert.Equal(t, "data: [DONE]", events[2])
+
+	// the spend cap of the route is reached, the requests are rejected as without providers.
+	assert.Nil(t, s.fallBackToProvider(context.Background(), "r3", "llama", "llama", EndpointChatCompletions, body))
+	assert.Len(t, authorizations, 2)
+
+	// only chat completions fall back.
+	assert.Nil(t, s.fallBackToProvider(context.Background(), "r4", "other", "other", EndpointCompletions, body))
+	assert.Len(t, authorizations, 2)
+
+	// the spend window is over.
+	s.providers.now = func() time.Time { return time.Now().Add(time.Hour) }
+	assert.NotNil(t, s.fallBackToProvider(context.Background(), "r5", "llama", "llama", EndpointChatCompletions, body))
+}
+
+func TestProviderSpendReservation(t *testing.T) {
+	unblock := make(chan struct{})
+	var arrivals atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrivals.Add(1)
+		select {
+		case <-unblock:
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "chatcmpl-1", "object": "chat.completion", "created": 1, "model": "gpt-4o-mini",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 1000, "completion_tokens": 500, "total_tokens": 1500}}`))
+	}))
+	defer server.Close()
+
+	config.SetGateway(config.GatewayConfig{Providers: []config.ProviderConfig{{
+		Name: "openai", Type: "openai", BaseURL: server.URL, InputCostPerMillion: 1, OutputCostPerMillion: 2,
+		SpendCap: 0.004, Timeout: &metav1.Duration{Duration: 5 * time.Second},
+	}}})
+	defer config.SetGateway(config.GatewayConfig{})
+	f := newProviderFallback(nil, nil)
+	route := config.Gateway().Providers[0]
+	body := map[string]interface{}{
+		"model": "llama", "max_tokens": float64(1500),
+		"messages": []interface{}{map[string]interface{}{"role": "user", "content": "hi"}},
+	}
+	// 1500 max tokens reserve 0.003 USD, the second concurrent request still fits under the spend cap, not the third.
+	estimate := estimatedCost(route, body)
+	assert.Equal(t, int64(3000+len(`[{"content":"hi","role":"user"}]`)/providerBytesPerToken), estimate)
+
+	results := make(chan string, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			name, _ := f.complete(context.Background(), "r", "llama", body)
+			results <- name
+		}()
+	}
+	assert.Equal(t, "", <-results)
+	spend, err := f.spent(context.Background(), route)
+	require.NoError(t, err)
+	assert.Equal(t, 2*estimate, spend)
+	close(unblock)
+	assert.Equal(t, "openai", <-results)
+	assert.Equal(t, "openai", <-results)
+	assert.Equal(t, int32(2), arrivals.Load())
+
+	// the reservations are adjusted to the cost of the requests.
+	spend, err = f.spent(context.Background(), route)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4000), spend)
+
+	// the reservations of the failed requests are released.
+	f.spend = map[string]providerSpendWindow{}
+	server.Close()
+	name, response := f.complete(context.Background(), "r", "llama", body)
+	assert.Equal(t, "", name)
+	assert.Nil(t, response)
+	spend, err = f.spent(context.Background(), route)
+	require.NoError(t, err)
+	assert.Zero(t, spend)
+}
+
+func TestProviderTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	config.SetGateway(config.GatewayConfig{Providers: []config.ProviderConfig{{
+		Name: "openai", Type: "openai", BaseURL: server.URL, Timeout: &metav1.Duration{Duration: 50 * time.Millisecond},
+	}}})
+	defer config.SetGateway(config.GatewayConfig{})
+	f := newProviderFallback(nil, nil)
+	start := time.Now()
+	_, response := f.complete(context.Background(), "r", "llama", map[string]interface{}{"model": "llama"})
+	assert.Nil(t, response)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
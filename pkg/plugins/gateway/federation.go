@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+const (
+	// HeaderFederatedFrom is set on the requests sent to another cluster or failover endpoint to the name of the
+	// cluster sending them. The gateway receiving them serves them in its cluster, they are never sent to a third one.
+	HeaderFederatedFrom = "x-aibrix-federated-from"
+
+	// why a request is sent to another cluster: failover if the model has no ready pod in the cluster, overflow if its
+	// pods are at capacity.
+	federationFailover = "failover"
+	federationOverflow = "overflow"
+)
+
+var federatedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "aibrix_gateway_federated_requests_total",
+	Help: "Requests sent to the gateway of another cluster of the federation.",
+}, []string{"model", "cluster", "reason"})
+
+func init() {
+	prometheus.MustRegister(federatedRequests)
+}
+
+type federatedFromKey struct{}
+
+// withFederation records the cluster that sent the request, empty if it was sent by a client.
+func withFederation(ctx context.Context, headers []*configPb.HeaderValue) context.Context {
+	for _, header := range headers {
+		if strings.ToLower(header.Key) == HeaderFederatedFrom {
+			return context.WithValue(ctx, federatedFromKey{}, string(header.RawValue))
+		}
+	}
+	return ctx
+}
+
+// isFederated returns true if the request was sent by another cluster.
+func isFederated(ctx context.Context) bool {
+	_, ok := ctx.Value(federatedFromKey{}).(string)
+	return ok
+}
+
+// federate sends the request to the least loaded other cluster serving the model, unchanged, so that its gateway
+// routes it to one of its pods. It returns a nil response if no other cluster serves the model, e.g. the gateway is not
+// federated, or the request was sent by another cluster.
+func (s *Server) federate(ctx context.Context, requestID, model, routingStrategy string, body map[string]interface{}, reason string) (*extProcPb.ProcessingResponse, string, bool, int64) {
+	if isFederated(ctx) {
+		return nil, "", false, 0
+	}
+	clusters := s.cache.GetFederatedClusters(model)
+	if len(clusters) == 0 {
+		return nil, "", false, 0
+	}
+	cluster := clusters[0]
+	stream, _ := body["stream"].(bool)
+	federatedRequests.WithLabelValues(model, cluster.Cluster, reason).Inc()
+	klog.InfoS("request sent to another cluster", "requestID", requestID, "model", model, "cluster", cluster.Cluster, "address", cluster.Address, "reason", reason)
+	term := s.cache.AddRequestCount(requestID, model)
+	return forwardRequest(ctx, cluster.Address, routingStrategy), cluster.Address, stream, term
+}
+
+// forwardRequest sends the request unchanged to the gateway at the address, marked with HeaderFederatedFrom.
+func forwardRequest(ctx context.Context, address, routingStrategy string) *extProcPb.ProcessingResponse {
+	// envoy sends the requests with a routing strategy to the target address.
+	if routingStrategy == "" {
+		routingStrategy = RouterRandom
+	}
+	headers := []*configPb.HeaderValueOption{
+		{Header: &configPb.HeaderValue{Key: HeaderRoutingStrategy, RawValue: []byte(routingStrategy)}},
+		{Header: &configPb.HeaderValue{Key: HeaderTargetPod, RawValue: []byte(address)}},
+		{Header: &configPb.HeaderValue{Key: HeaderFederatedFrom, RawValue: []byte(federationSource())}},
+	}
+	headers = append(headers, traceparentHeader(ctx)...)
+
+	return &extProcPb.ProcessingResponse{
+		Response: &extProcPb.ProcessingResponse_RequestBody{
+			RequestBody: &extProcPb.BodyResponse{
+				Response: &extProcPb.CommonResponse{
+					HeaderMutation: &extProcPb.HeaderMutation{
+						SetHeaders: headers,
+					},
+					// the request is routed again in case it had no routing strategy.
+					ClearRouteCache: true,
+				},
+			},
+		},
+	}
+}
+
+// federationSource names the sender of the forwarded requests: the cluster of the gateway, or else its replica.
+func federationSource() string {
+	if cluster := cache.FederationCluster(); cluster != "" {
+		return cluster
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return "unknown"
+}
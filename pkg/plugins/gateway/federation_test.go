@@ -0,0 +1,64 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+func TestFederate(t *testing.T) {
+	c := cache.NewOfflineCache()
+	c.UpdateFederatedClusters([]cache.ClusterSummary{
+		{Cluster: "us-west", Address: "10.0.0.2:80", Models: map[string]cache.ModelSummary{"federated-model": {Pods: 2, ReadyPods: 2, Inflight: 6}}},
+		{Cluster: "eu-west", Address: "10.0.0.3:80", Models: map[string]cache.ModelSummary{"federated-model": {Pods: 2, ReadyPods: 2, Inflight: 1}}},
+	})
+	defer c.UpdateFederatedClusters(nil)
+	s := &Server{cache: c}
+
+	resp, address, stream, _ := s.federate(context.Background(), "r1", "federated-model", "", map[string]interface{}{"stream": true}, federationFailover)
+	require.NotNil(t, resp)
+	assert.Equal(t, "10.0.0.3:80", address)
+	assert.True(t, stream)
+	common := resp.GetRequestBody().GetResponse()
+	assert.True(t, common.GetClearRouteCache())
+	assert.Nil(t, common.GetBodyMutation())
+	headers := map[string]string{}
+	for _, header := range common.GetHeaderMutation().GetSetHeaders() {
+		headers[header.GetHeader().GetKey()] = string(header.GetHeader().GetRawValue())
+	}
+	assert.Equal(t, RouterRandom, headers[HeaderRoutingStrategy])
+	assert.Equal(t, "10.0.0.3:80", headers[HeaderTargetPod])
+	assert.Contains(t, headers, HeaderFederatedFrom)
+
+	// no other cluster serves the model.
+	resp, _, _, _ = s.federate(context.Background(), "r2", "unknown-model", "", nil, federationFailover)
+	assert.Nil(t, resp)
+
+	// the requests sent by another cluster are never sent again.
+	ctx := withFederation(context.Background(), []*configPb.HeaderValue{{Key: "X-Aibrix-Federated-From", RawValue: []byte("us-west")}})
+	assert.True(t, isFederated(ctx))
+	resp, _, _, _ = s.federate(ctx, "r3", "federated-model", RouterLeastRequest, nil, federationOverflow)
+	assert.Nil(t, resp)
+	assert.False(t, isFederated(withFederation(context.Background(), nil)))
+}
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"slices"
 	"strconv"
@@ -44,8 +45,16 @@ import (
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/client/clientset/versioned"
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/features"
 	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/auth"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/middleware"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/provider"
 	ratelimiter "github.com/vllm-project/aibrix/pkg/plugins/gateway/ratelimiter"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/responsecache"
+	"github.com/vllm-project/aibrix/pkg/tracing"
 	"github.com/vllm-project/aibrix/pkg/utils"
 	healthPb "google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -59,10 +68,17 @@ const (
 	HeaderErrorRequestBodyProcessing = "x-error-request-body-processing"
 	HeaderErrorResponseUnmarshal     = "x-error-response-unmarshal"
 	HeaderErrorResponseUnknown       = "x-error-response-unknown"
+	// HeaderErrorMiddleware names the middleware rejecting the request.
+	HeaderErrorMiddleware = "x-error-middleware"
+	// HeaderErrorImageLimit reports a request exceeding the image count or size limits.
+	HeaderErrorImageLimit = "x-error-image-limit"
 
 	// Model & Deployment Headers
 	HeaderErrorNoModelInRequest = "x-error-no-model-in-request"
 	HeaderErrorNoModelBackends  = "x-error-no-model-backends"
+	HeaderErrorModelQueueFull   = "x-error-model-queue-full"
+	// HeaderErrorConcurrencyLimit reports the adaptive concurrency limit of the model rejecting the request.
+	HeaderErrorConcurrencyLimit = "x-error-concurrency-limit"
 
 	// Streaming Headers
 	HeaderErrorStreaming                 = "x-error-streaming"
@@ -73,6 +89,13 @@ const (
 	HeaderWentIntoReqHeaders = "x-went-into-req-headers"
 	HeaderTargetPod          = "target-pod"
 	HeaderRoutingStrategy    = "routing-strategy"
+	// HeaderUpstreamTimeout overrides the route timeout of envoy for the request.
+	HeaderUpstreamTimeout = "x-envoy-upstream-rq-timeout-ms"
+	// HeaderRequestDeadline tells the engine the time left in ms before the gateway gives up on the request.
+	HeaderRequestDeadline     = "x-aibrix-request-deadline-ms"
+	HeaderErrorRequestTimeout = "x-error-request-timeout"
+	// HeaderErrorScaleFromZero reports a model scaled to zero that did not come up in time.
+	HeaderErrorScaleFromZero = "x-error-scale-from-zero"
 
 	// RPM & TPM Update Errors
 	HeaderUpdateTPM        = "x-update-tpm"
@@ -87,7 +110,9 @@ const (
 	DefaultTPMMultiplier = 1000
 
 	// Envs
-	EnvRoutingAlgorithm = "ROUTING_ALGORITHM"
+	EnvRoutingAlgorithm       = "ROUTING_ALGORITHM"
+	EnvExternalRouterAddr     = "AIBRIX_EXTERNAL_ROUTER_ADDR"
+	EnvExternalRouterFallback = "AIBRIX_EXTERNAL_ROUTER_FALLBACK"
 
 	// Router names
 	RouterRandom             = "random"
@@ -98,10 +123,11 @@ const (
 	RouterLeastKvCache       = "least-kv-cache"
 	RouterLeastBusyTime      = "least-busy-time"
 	RouterLeastLatency       = "least-latency"
+	RouterExternal           = "external"
 )
 
 var (
-	routingStrategies = []string{"random", "least-request", "throughput", "prefix-cache", "prefix-cache-and-load", "least-kv-cache", "least-busy-time", "least-latency"}
+	routingStrategies = []string{"random", "least-request", "throughput", "prefix-cache", "prefix-cache-and-load", "least-kv-cache", "least-busy-time", "least-latency", "external"}
 
 	ErrorUnknownResponse = errors.New("unknown response")
 
@@ -120,16 +146,41 @@ var routerConstructors = map[string]func() (routing.Router, error){
 	RouterLeastLatency:       func() (routing.Router, error) { return routing.NewLeastExpectedLatencyRouter() },
 }
 
+// NewRouter initializes the router of the routing strategy, outside of a gateway server, e.g. to replay traces against it.
+func NewRouter(routingStrategy string) (routing.Router, error) {
+	constructor, ok := routerConstructors[routingStrategy]
+	if !ok {
+		return nil, fmt.Errorf("unknown routing strategy: %s", routingStrategy)
+	}
+	return constructor()
+}
+
 type Server struct {
 	routers             map[string]routing.Router
-	redisClient         *redis.Client
+	redisClient         redis.UniversalClient
 	ratelimiter         ratelimiter.RateLimiter
 	client              kubernetes.Interface
 	requestCountTracker map[string]int
-	cache               *cache.Cache
+	cache               cache.Interface
+	modelRewriter       *modelNameRewriter
+	loraActivator       *loraActivator
+	authenticator       auth.Authenticator
+	auditLogger         *auditLogger
+	routingHistory      *routingHistory
+	modelConfigs        *modelConfigStore
+	slowStart           *routing.SlowStart
+	zoneAffinity        *routing.ZoneAffinity
+	requestLength       *routing.RequestLengthAffinity
+	scaleFromZero       *scaleFromZeroActivator
+	middlewares         middlewareChains
+	responseCache       *responsecache.Cache
+	dedup               *requestDeduplicator
+	concurrency         *concurrencyLimiter
+	failover            *failoverRouter
+	providers           *providerFallback
 }
 
-func NewServer(redisClient *redis.Client, client kubernetes.Interface) *Server {
+func NewServer(redisClient redis.UniversalClient, client kubernetes.Interface, aibrixClient versioned.Interface, authenticator auth.Authenticator) *Server {
 	c, err := cache.GetCache()
 	if err != nil {
 		panic(err)
@@ -144,6 +195,21 @@ func NewServer(redisClient *redis.Client, client kubernetes.Interface) *Server {
 		client:              client,
 		requestCountTracker: map[string]int{},
 		cache:               c,
+		modelRewriter:       loadModelNameRewriter(),
+		loraActivator:       newLoraActivator(aibrixClient, c),
+		authenticator:       authenticator,
+		auditLogger:         newAuditLogger(),
+		routingHistory:      newRoutingHistory(getRoutingHistorySize()),
+		modelConfigs:        newModelConfigStore(),
+		slowStart:           routing.NewSlowStart(),
+		zoneAffinity:        routing.NewZoneAffinity(),
+		requestLength:       routing.NewRequestLengthAffinity(),
+		scaleFromZero:       newScaleFromZeroActivator(aibrixClient, c),
+		responseCache:       loadResponseCache(redisClient),
+		dedup:               loadRequestDeduplicator(),
+		concurrency:         newConcurrencyLimiter(),
+		failover:            newFailoverRouter(),
+		providers:           newProviderFallback(redisClient, client),
 	}
 }
 
@@ -158,6 +224,17 @@ func initializeRouters() map[string]routing.Router {
 		}
 		routers[name] = router
 	}
+
+	// the external router delegates to a gRPC service and falls back to one of the routers above.
+	if addr := utils.LoadEnv(EnvExternalRouterAddr, ""); addr != "" {
+		fallback := utils.LoadEnv(EnvExternalRouterFallback, RouterLeastRequest)
+		router, err := routing.NewExternalRouter(addr, routers[fallback])
+		if err != nil {
+			klog.Warningf("failed to initialize router %s with fallback %s: %v", RouterExternal, fallback, err)
+		} else {
+			routers[RouterExternal] = router
+		}
+	}
 	return routers
 }
 
@@ -174,54 +251,134 @@ func (s *HealthServer) Watch(in *healthPb.HealthCheckRequest, srv healthPb.Healt
 func (s *Server) Process(srv extProcPb.ExternalProcessor_ProcessServer) error {
 	var user utils.User
 	var rpm, traceTerm int64
-	var respErrorCode int
-	var model, routingStrategy, targetPodIP string
-	var stream, isRespError bool
-	ctx := srv.Context()
+	var respErrorCode, batchSize int
+	var model, externalModel, routingStrategy, targetPodIP, targetPod, endpoint string
+	var stream, isRespError, traced, traceDone bool
+	var deadline <-chan time.Time
+	ctx, scores := routing.WithScoreRecorder(srv.Context())
+	ctx, feedback := routing.WithFeedback(ctx)
 	requestID := uuid.New().String()
 	completed := false
 
 	klog.InfoS("Processing request", "requestID", requestID)
 
+	var spans *requestSpans
+	audit := &AuditRecord{Timestamp: time.Now(), RequestID: requestID}
+	ctx = withRequestStart(ctx, audit.Timestamp)
+	ctx = withMiddlewareChain(ctx, s.middlewares.Get())
+	ctx = withResponseCacheMiss(ctx)
+	ctx = withRequestConcurrency(ctx)
+	ctx = withStructuredOutput(ctx)
+	ctx = withModelRewriteStream(ctx)
+	defer func() {
+		// the client disconnected, the request timed out or the engine failed before the response completed.
+		if traced && !traceDone {
+			s.cache.DoneRequestCount(requestID, model, traceTerm)
+		}
+		requestBuffers.Delete(requestID)
+		// identical requests waiting for a failed one send their own.
+		s.finishIdenticalRequests(ctx, nil)
+		s.releaseConcurrency(ctx)
+		feedback.Report(traceDone && audit.StatusCode == http.StatusOK)
+		spans.end(map[string]interface{}{
+			"request_id":       requestID,
+			"model":            model,
+			"routing_strategy": routingStrategy,
+			"target_pod":       targetPodIP,
+			"status_code":      audit.StatusCode,
+		})
+		audit.User = user.Name
+		audit.Model = model
+		audit.RoutingStrategy = routingStrategy
+		audit.TargetPod = targetPodIP
+		audit.Candidates = scores.Scores()
+		audit.DurationMs = time.Since(audit.Timestamp).Milliseconds()
+		s.auditLogger.Log(audit)
+		if audit.Model != "" {
+			s.routingHistory.Add(audit)
+		}
+		if targetPod != "" {
+			s.cache.DonePodRequest(targetPod)
+			s.cache.DonePodBatchItems(targetPod, int32(batchSize))
+		}
+	}()
+
+	requests := receive(ctx, srv)
 	for {
+		var req *extProcPb.ProcessingRequest
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		default:
-		}
-
-		req, err := srv.Recv()
-		if err == io.EOF {
-			return nil
-		}
-		if err != nil {
-			return status.Errorf(codes.Unknown, "cannot receive stream request: %v", err)
+		case <-deadline:
+			// failing the stream makes envoy reset the request and its upstream connection.
+			klog.InfoS("request timed out", "requestID", requestID, "model", model, "targetPodIP", targetPodIP)
+			audit.StatusCode = http.StatusGatewayTimeout
+			return status.Error(codes.DeadlineExceeded, "request timed out")
+		case r, ok := <-requests:
+			if !ok {
+				return ctx.Err()
+			}
+			if r.err == io.EOF {
+				return nil
+			}
+			if r.err != nil {
+				return status.Errorf(codes.Unknown, "cannot receive stream request: %v", r.err)
+			}
+			req = r.req
 		}
 
 		resp := &extProcPb.ProcessingResponse{}
 		switch v := req.Request.(type) {
 
 		case *extProcPb.ProcessingRequest_RequestHeaders:
+			ctx, spans = startRequestSpans(ctx, v.RequestHeaders.GetHeaders().GetHeaders())
+			endpoint = getEndpoint(v.RequestHeaders.GetHeaders().GetHeaders())
+			ctx = withRequestDedup(ctx, v.RequestHeaders.GetHeaders().GetHeaders())
+			ctx = withFederation(ctx, v.RequestHeaders.GetHeaders().GetHeaders())
 			resp, user, rpm, routingStrategy = s.HandleRequestHeaders(ctx, requestID, req)
 
 		case *extProcPb.ProcessingRequest_RequestBody:
-			resp, model, targetPodIP, stream, traceTerm = s.HandleRequestBody(ctx, requestID, req, user, routingStrategy)
+			resp, model, externalModel, routingStrategy, targetPodIP, stream, traceTerm, batchSize = s.HandleRequestBody(ctx, requestID, req, user, routingStrategy, endpoint)
+			if _, rejected := immediateResponseCode(resp); !rejected {
+				traced = true
+				if targetPodIP != "" {
+					targetPod = s.trackPodRequest(model, targetPodIP, batchSize)
+				}
+				if modelConfig, _ := s.modelConfigs.Get(model); modelConfig.RequestTimeout > 0 {
+					timer := time.NewTimer(remainingTimeout(ctx, modelConfig.RequestTimeout))
+					defer timer.Stop()
+					deadline = timer.C
+				}
+			}
+			audit.QueueingDelayMs = time.Since(audit.Timestamp).Milliseconds()
+			spans.routed(ctx)
 
 		case *extProcPb.ProcessingRequest_ResponseHeaders:
-			resp, isRespError, respErrorCode = s.HandleResponseHeaders(ctx, requestID, req, targetPodIP)
+			resp, isRespError, respErrorCode = s.HandleResponseHeaders(ctx, requestID, req, model, externalModel, targetPodIP)
+			audit.StatusCode = http.StatusOK
+			if isRespError {
+				audit.StatusCode = respErrorCode
+			}
 
 		case *extProcPb.ProcessingRequest_ResponseBody:
 			respBody := req.Request.(*extProcPb.ProcessingRequest_ResponseBody)
+			spans.responseChunk(ctx, respBody.ResponseBody.GetEndOfStream())
 			if isRespError {
 				klog.ErrorS(errors.New("request end"), string(respBody.ResponseBody.GetBody()), "requestID", requestID)
 				generateErrorResponse(envoyTypePb.StatusCode(respErrorCode), nil, string(respBody.ResponseBody.GetBody()))
 			} else {
-				resp, completed = s.HandleResponseBody(ctx, requestID, req, user, rpm, model, targetPodIP, stream, traceTerm, completed)
+				s.observeConcurrency(ctx)
+				resp, completed = s.HandleResponseBody(ctx, requestID, req, user, rpm, model, externalModel, endpoint, targetPodIP, stream, traceTerm, completed)
+				traceDone = completed && respBody.ResponseBody.GetEndOfStream()
 			}
 		default:
 			klog.Infof("Unknown Request type %+v\n", v)
 		}
 
+		if code, ok := immediateResponseCode(resp); ok {
+			audit.StatusCode = code
+		}
+
 		if err := srv.Send(resp); err != nil {
 			klog.Infof("send error %v", err)
 		}
@@ -237,10 +394,29 @@ func (s *Server) HandleRequestHeaders(ctx context.Context, requestID string, req
 	var errRes *extProcPb.ProcessingResponse
 
 	h := req.Request.(*extProcPb.ProcessingRequest_RequestHeaders)
-	for _, n := range h.RequestHeaders.Headers.Headers {
-		if strings.ToLower(n.Key) == "user" {
-			username = string(n.RawValue)
+	var identity *auth.Identity
+	if s.authenticator != nil {
+		identity, errRes = s.authenticate(ctx, requestID, h.RequestHeaders.Headers.Headers)
+		if errRes != nil {
+			return errRes, utils.User{}, rpm, ""
 		}
+		username = identity.User
+	} else {
+		for _, n := range h.RequestHeaders.Headers.Headers {
+			if strings.ToLower(n.Key) == "user" {
+				username = string(n.RawValue)
+			}
+		}
+	}
+
+	// the model list and the capacity of the models are only served to authenticated callers.
+	if isModelListRequest(h.RequestHeaders.Headers.Headers) {
+		klog.InfoS("serving model list from cache", "requestID", requestID)
+		return s.generateModelListResponse(requestID, s.tenantOf(username, identity)), utils.User{}, rpm, ""
+	}
+	if models, ok := capacityRequest(h.RequestHeaders.Headers.Headers); ok {
+		klog.InfoS("serving capacity from cache", "requestID", requestID)
+		return s.generateCapacityResponse(requestID, models), utils.User{}, rpm, ""
 	}
 
 	routingStrategy, routingStrategyEnabled := GetRoutingStrategy(h.RequestHeaders.Headers.Headers)
@@ -252,9 +428,20 @@ func (s *Server) HandleRequestHeaders(ctx context.Context, requestID string, req
 				Key: HeaderErrorInvalidRouting, RawValue: []byte(routingStrategy),
 			}}}, "incorrect routing strategy"), utils.User{}, rpm, routingStrategy
 	}
+	if !hasRoutingStrategyHeader(h.RequestHeaders.Headers.Headers) {
+		// the default strategy is resolved once the model is known, it may be overridden per model.
+		routingStrategy = ""
+	}
 
 	if username != "" {
 		user, err = utils.GetUser(utils.User{Name: username}, s.redisClient)
+		if identity != nil && errors.Is(err, redis.Nil) {
+			// authenticated users without a user record get the default limits.
+			user, err = utils.User{Name: username}, nil
+		}
+		if identity != nil && identity.Tenant != "" {
+			user.Tenant = identity.Tenant
+		}
 		if err != nil {
 			klog.ErrorS(err, "unable to process user info", "requestID", requestID, "username", username)
 			return generateErrorResponse(
@@ -293,11 +480,12 @@ func (s *Server) HandleRequestHeaders(ctx context.Context, requestID string, req
 	}, user, rpm, routingStrategy
 }
 
-func (s *Server) HandleRequestBody(ctx context.Context, requestID string, req *extProcPb.ProcessingRequest, user utils.User, routingStrategy string) (*extProcPb.ProcessingResponse, string, string, bool, int64) {
+func (s *Server) HandleRequestBody(ctx context.Context, requestID string, req *extProcPb.ProcessingRequest, user utils.User, routingStrategy, endpoint string) (*extProcPb.ProcessingResponse, string, string, string, string, bool, int64, int) {
 	klog.InfoS("-- In RequestBody processing ...", "requestID", requestID)
-	var model, targetPodIP string
+	var model, externalModel, targetPodIP string
 	var ok, stream bool
 	var term int64 // Identify the trace window
+	batchSize := 1
 
 	var jsonMap map[string]interface{}
 
@@ -307,7 +495,7 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, req *e
 		return generateErrorResponse(envoyTypePb.StatusCode_InternalServerError,
 			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
 				Key: HeaderErrorRequestBodyProcessing, RawValue: []byte("true")}}},
-			"error processing request body"), model, targetPodIP, stream, term
+			"error processing request body"), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
 	}
 
 	if model, ok = jsonMap["model"].(string); !ok || model == "" {
@@ -315,26 +503,134 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, req *e
 		return generateErrorResponse(envoyTypePb.StatusCode_InternalServerError,
 			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
 				Key: HeaderErrorNoModelInRequest, RawValue: []byte(model)}}},
-			"no model in request body"), model, targetPodIP, stream, term
+			"no model in request body"), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+	}
+
+	// translate white-labeled model names to the deployment name before any lookup.
+	externalModel = model
+	if internalModel, rewritten := s.modelRewriter.ToInternal(user.TenantID(), model); rewritten {
+		klog.InfoS("model name rewritten", "requestID", requestID, "externalModel", externalModel, "model", internalModel)
+		model = internalModel
+		jsonMap["model"] = model
+	}
+
+	// transform the request before it is admitted and routed, the middlewares may change what it is routed on.
+	transformed, err := middlewareChainFrom(ctx).TransformRequest(ctx, &middleware.Request{
+		Model: model, Endpoint: endpoint, User: user.Name, Body: jsonMap,
+	})
+	if err != nil {
+		klog.ErrorS(err, "request rejected by middleware", "requestID", requestID, "model", model)
+		var middlewareErr *middleware.Error
+		errors.As(err, &middlewareErr)
+		return generateErrorResponse(envoyTypePb.StatusCode_BadRequest,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorMiddleware, RawValue: []byte(middlewareErr.Middleware)}}},
+			err.Error()), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+	}
+	if model, ok = jsonMap["model"].(string); !ok || model == "" {
+		klog.ErrorS(nil, "model removed by middleware", "requestID", requestID)
+		return generateErrorResponse(envoyTypePb.StatusCode_InternalServerError,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorNoModelInRequest, RawValue: []byte(model)}}},
+			"no model in request body"), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+	}
+
+	modelConfig, _ := s.modelConfigs.Get(model)
+	routingStrategy = resolveRoutingStrategy(routingStrategy, modelConfig)
+
+	// answer repeated prompts from the response cache, even while the model has no ready pod.
+	if resp := s.lookupResponseCache(ctx, requestID, user, model, externalModel, endpoint, modelConfig, jsonMap); resp != nil {
+		return resp, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+	}
+	// coalesce concurrent identical requests, only the first one is sent to the engines.
+	if resp := s.awaitIdenticalRequest(ctx, requestID, user, model, externalModel, endpoint, modelConfig, jsonMap); resp != nil {
+		return resp, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+	}
+
+	// hold the request while a model scaled to zero is scaled up again.
+	waitTimeout := scaleFromZeroTimeout
+	if timeout := config.Gateway().ScaleFromZeroTimeout; timeout != nil {
+		waitTimeout = timeout.Duration
+	}
+	if modelConfig.RequestTimeout > 0 {
+		waitTimeout = min(waitTimeout, remainingTimeout(ctx, modelConfig.RequestTimeout))
+	}
+	// the feature flags of the request are rolled out per tenant or user, per request for the anonymous callers.
+	flagTarget := features.Target{Model: model, Tenant: user.TenantID(), RequestID: requestID}
+	waited, err := s.scaleFromZero.WaitForReadyPods(ctx, flagTarget, waitTimeout)
+	// send the requests of a model without ready pods to its failover endpoint, until its pods are ready again.
+	if resp, targetPodIP, stream, term := s.failOver(ctx, requestID, model, routingStrategy, jsonMap); resp != nil {
+		return resp, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+	}
+	if waited && err != nil {
+		klog.ErrorS(err, "model was not scaled from zero", "requestID", requestID, "model", model)
+		return generateErrorResponse(envoyTypePb.StatusCode_ServiceUnavailable,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorScaleFromZero, RawValue: []byte(model)}}},
+			fmt.Sprintf("model %s is scaling up from zero, retry later", model)), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+	} else if waited {
+		klog.InfoS("model scaled from zero", "requestID", requestID, "model", model)
 	}
 
 	// early reject the request if model doesn't exist.
 	if !s.cache.CheckModelExists(model) {
+		if resp, targetPodIP, stream, term := s.federate(ctx, requestID, model, routingStrategy, jsonMap, federationFailover); resp != nil {
+			return resp, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
 		klog.ErrorS(nil, "model doesn't exist in cache, probably wrong model name", "requestID", requestID, "model", model)
 		return generateErrorResponse(envoyTypePb.StatusCode_BadRequest,
 			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
 				Key: HeaderErrorNoModelBackends, RawValue: []byte(model)}}},
-			fmt.Sprintf("model %s does not exist", model)), model, targetPodIP, stream, term
+			fmt.Sprintf("model %s does not exist", model)), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
 	}
 
-	// early reject if no pods are ready to accept request for a model
-	pods, err := s.cache.GetPodsForModel(model)
+	// early reject if no pods are ready to accept request for a model, pods whose engine is not serving yet are excluded.
+	_, cacheSpan := tracing.StartSpan(ctx, "cache.get_pods", tracing.SpanKindInternal)
+	pods, err := s.cache.GetReadyPodsForModel(model)
+	cacheSpan.SetAttribute("model", model)
+	cacheSpan.SetAttribute("pods", len(pods))
+	cacheSpan.RecordError(err)
+	cacheSpan.End()
 	if len(pods) == 0 || len(utils.FilterReadyPods(pods)) == 0 || err != nil {
+		if resp, targetPodIP, stream, term := s.federate(ctx, requestID, model, routingStrategy, jsonMap, federationFailover); resp != nil {
+			return resp, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
+		if resp := s.fallBackToProvider(ctx, requestID, model, externalModel, endpoint, jsonMap); resp != nil {
+			return resp, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
 		klog.ErrorS(err, "no ready pod available", "requestID", requestID, "model", model)
 		return generateErrorResponse(envoyTypePb.StatusCode_ServiceUnavailable,
-			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
-				Key: HeaderErrorNoModelBackends, RawValue: []byte("true")}}},
-			fmt.Sprintf("error on getting pods for model %s", model)), model, targetPodIP, stream, term
+			append([]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorNoModelBackends, RawValue: []byte("true")}}}, capacityHeaders(s.capacityOf(model))...),
+			fmt.Sprintf("error on getting pods for model %s", model)), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+	}
+
+	if modelConfig.MaxQueuedRequests > 0 && allPodsQueued(s.cache, model, utils.FilterReadyPods(pods), float64(modelConfig.MaxQueuedRequests)) {
+		if resp, targetPodIP, stream, term := s.federate(ctx, requestID, model, routingStrategy, jsonMap, federationOverflow); resp != nil {
+			return resp, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
+		if resp := s.fallBackToProvider(ctx, requestID, model, externalModel, endpoint, jsonMap); resp != nil {
+			return resp, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
+		klog.InfoS("rejecting request, all pods have reached the max queued requests", "requestID", requestID, "model", model, "maxQueuedRequests", modelConfig.MaxQueuedRequests)
+		return generateErrorResponse(envoyTypePb.StatusCode_TooManyRequests,
+			append([]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorModelQueueFull, RawValue: []byte(strconv.Itoa(modelConfig.MaxQueuedRequests))}}}, capacityHeaders(s.capacityOf(model))...),
+			fmt.Sprintf("model %s is at capacity, retry later", model)), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+	}
+	if readyPods := len(utils.FilterReadyPods(pods)); !s.admitConcurrency(ctx, model, modelConfig, readyPods) {
+		if resp, targetPodIP, stream, term := s.federate(ctx, requestID, model, routingStrategy, jsonMap, federationOverflow); resp != nil {
+			return resp, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
+		if resp := s.fallBackToProvider(ctx, requestID, model, externalModel, endpoint, jsonMap); resp != nil {
+			return resp, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
+		limit := s.concurrency.limit(model, readyPods)
+		klog.InfoS("rejecting request, the model has reached its concurrency limit", "requestID", requestID, "model", model, "limit", limit)
+		return generateErrorResponse(envoyTypePb.StatusCode_TooManyRequests,
+			append([]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorConcurrencyLimit, RawValue: []byte(strconv.Itoa(limit))}}}, capacityHeaders(s.capacityOf(model))...),
+			fmt.Sprintf("model %s is at capacity, retry later", model)), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
 	}
 
 	stream, ok = jsonMap["stream"].(bool)
@@ -345,7 +641,7 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, req *e
 			return generateErrorResponse(envoyTypePb.StatusCode_InternalServerError,
 				[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
 					Key: HeaderErrorNoStreamOptions, RawValue: []byte("stream options not set")}}},
-				"no stream option available"), model, targetPodIP, stream, term
+				"no stream option available"), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
 		}
 		includeUsage, ok := streamOptions["include_usage"].(bool)
 		if !includeUsage || !ok {
@@ -353,7 +649,48 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, req *e
 			return generateErrorResponse(envoyTypePb.StatusCode_InternalServerError,
 				[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
 					Key: HeaderErrorStreamOptionsIncludeUsage, RawValue: []byte("include usage for stream options not set")}}},
-				"no stream with usage option available"), model, targetPodIP, stream, term
+				"no stream with usage option available"), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
+	}
+
+	input, inputErr := parseRequestInput(endpoint, jsonMap)
+	if inputErr == nil {
+		batchSize = input.batchSize
+	}
+	tracing.SpanFromContext(ctx).SetAttribute("endpoint", endpoint)
+	tracing.SpanFromContext(ctx).SetAttribute("batch_size", batchSize)
+	if err := parseStructuredOutput(ctx, endpoint, modelConfig, jsonMap); err != nil {
+		klog.InfoS("rejecting request with an invalid response format", "requestID", requestID, "model", model, "error", err)
+		return generateErrorResponse(envoyTypePb.StatusCode_BadRequest,
+			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+				Key: HeaderErrorInvalidResponseFormat, RawValue: []byte("true")}}},
+			err.Error()), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+	}
+	if inputErr == nil && len(input.images) > 0 {
+		if err := checkImageLimits(input.images); err != nil {
+			klog.InfoS("rejecting request exceeding the image limits", "requestID", requestID, "model", model, "error", err)
+			return generateErrorResponse(envoyTypePb.StatusCode_BadRequest,
+				[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+					Key: HeaderErrorImageLimit, RawValue: []byte("true")}}},
+				err.Error()), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
+		input.imageTokens = s.imageTokens(model, input.images)
+		tracing.SpanFromContext(ctx).SetAttribute("images", len(input.images))
+		tracing.SpanFromContext(ctx).SetAttribute("image_tokens", input.imageTokens)
+	}
+
+	// count the input tokens as the engine does, chat template included, to admit and trace the request.
+	if inputErr == nil && (user.Name != "" || tracing.SpanFromContext(ctx).IsRecording()) {
+		inputTokens := estimateInputTokens(ctx, model, input)
+		tracing.SpanFromContext(ctx).SetAttribute("input_tokens", inputTokens)
+		if user.Name != "" && inputTokens > 0 {
+			if code, err := s.checkTPM(ctx, user.Name, withDefaultLimits(user).Tpm, inputTokens); err != nil {
+				klog.ErrorS(err, "error on checking limits", "requestID", requestID, "username", user.Name, "inputTokens", inputTokens)
+				return generateErrorResponse(code,
+					[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+						Key: HeaderErrorTPMExceeded, RawValue: []byte("true")}}},
+					err.Error()), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+			}
 		}
 	}
 
@@ -367,21 +704,32 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, req *e
 		})
 		klog.InfoS("request start", "requestID", requestID, "model", model)
 	} else {
-		message, extErr := getRequestMessage(jsonMap)
-		if extErr != nil {
-			return extErr, model, targetPodIP, stream, term
+		if inputErr != nil {
+			klog.ErrorS(inputErr, "invalid request input", "requestID", requestID, "endpoint", endpoint)
+			return generateErrorResponse(envoyTypePb.StatusCode_InternalServerError,
+				[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{Key: HeaderErrorRequestBodyProcessing, RawValue: []byte("true")}}},
+				inputErr.Error()), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
 		}
 
-		targetPodIP, err = s.selectTargetPod(ctx, routingStrategy, pods, model, message)
+		routeCtx, routeSpan := tracing.StartSpan(ctx, "gateway.route", tracing.SpanKindInternal)
+		candidates := s.filterByCapabilities(model, pods, input)
+		candidates = s.filterByContextLength(routeCtx, model, candidates, input)
+		targetPodIP, err = s.selectTargetPod(routeCtx, routingStrategy, candidates, model, input.message)
+		routeSpan.SetAttribute("routing_strategy", routingStrategy)
+		routeSpan.SetAttribute("target_pod", targetPodIP)
+		routeSpan.RecordError(err)
+		routeSpan.End()
 		if targetPodIP == "" || err != nil {
 			klog.ErrorS(err, "failed to select target pod", "requestID", requestID, "routingStrategy", routingStrategy, "model", model)
 			return generateErrorResponse(
 				envoyTypePb.StatusCode_ServiceUnavailable,
 				[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
 					Key: HeaderErrorRouting, RawValue: []byte("true")}}},
-				"error on selecting target pod"), model, targetPodIP, stream, term
+				"error on selecting target pod"), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
 		}
 
+		s.loraActivator.MaybeActivate(flagTarget, pods)
+
 		headers = append(headers,
 			&configPb.HeaderValueOption{
 				Header: &configPb.HeaderValue{
@@ -398,6 +746,49 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, req *e
 		klog.InfoS("request start", "requestID", requestID, "model", model, "routingStrategy", routingStrategy, "targetPodIP", targetPodIP)
 	}
 
+	s.scaleFromZero.RecordActivity(flagTarget)
+
+	var bodyMutation *extProcPb.BodyMutation
+	if externalModel != model || transformed {
+		rewrittenBody, err := json.Marshal(jsonMap)
+		if err != nil {
+			klog.ErrorS(err, "failed to marshal rewritten request body", "requestID", requestID)
+			return generateErrorResponse(envoyTypePb.StatusCode_InternalServerError,
+				[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+					Key: HeaderErrorRequestBodyProcessing, RawValue: []byte("true")}}},
+				"error processing request body"), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
+		bodyMutation = &extProcPb.BodyMutation{
+			Mutation: &extProcPb.BodyMutation_Body{Body: rewrittenBody},
+		}
+		headers = append(headers, &configPb.HeaderValueOption{
+			Header: &configPb.HeaderValue{
+				Key:      "Content-Length",
+				RawValue: []byte(strconv.Itoa(len(rewrittenBody))),
+			},
+		})
+	}
+
+	if modelConfig.RequestTimeout > 0 {
+		remaining := remainingTimeout(ctx, modelConfig.RequestTimeout)
+		if remaining <= 0 {
+			klog.InfoS("request timed out before routing", "requestID", requestID, "model", model, "timeout", modelConfig.RequestTimeout)
+			return generateErrorResponse(envoyTypePb.StatusCode_GatewayTimeout,
+				[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{
+					Key: HeaderErrorRequestTimeout, RawValue: []byte(modelConfig.RequestTimeout.String())}}},
+				"request timed out"), model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
+		}
+		deadlineMs := []byte(strconv.FormatInt(remaining.Milliseconds(), 10))
+		headers = append(headers,
+			&configPb.HeaderValueOption{
+				Header: &configPb.HeaderValue{Key: HeaderUpstreamTimeout, RawValue: deadlineMs},
+			},
+			&configPb.HeaderValueOption{
+				Header: &configPb.HeaderValue{Key: HeaderRequestDeadline, RawValue: deadlineMs},
+			})
+	}
+	headers = append(headers, traceparentHeader(ctx)...)
+
 	term = s.cache.AddRequestCount(requestID, model)
 
 	return &extProcPb.ProcessingResponse{
@@ -407,13 +798,14 @@ func (s *Server) HandleRequestBody(ctx context.Context, requestID string, req *e
 					HeaderMutation: &extProcPb.HeaderMutation{
 						SetHeaders: headers,
 					},
+					BodyMutation: bodyMutation,
 				},
 			},
 		},
-	}, model, targetPodIP, stream, term
+	}, model, externalModel, routingStrategy, targetPodIP, stream, term, batchSize
 }
 
-func (s *Server) HandleResponseHeaders(ctx context.Context, requestID string, req *extProcPb.ProcessingRequest, targetPodIP string) (*extProcPb.ProcessingResponse, bool, int) {
+func (s *Server) HandleResponseHeaders(ctx context.Context, requestID string, req *extProcPb.ProcessingRequest, model, externalModel, targetPodIP string) (*extProcPb.ProcessingResponse, bool, int) {
 	klog.InfoS("-- In ResponseHeaders processing ...", "requestID", requestID)
 	b := req.Request.(*extProcPb.ProcessingRequest_ResponseHeaders)
 
@@ -432,6 +824,25 @@ func (s *Server) HandleResponseHeaders(ctx context.Context, requestID string, re
 		})
 	}
 
+	if model != "" && s.cache != nil {
+		headers = append(headers, capacityHeaders(s.capacityOf(model))...)
+	}
+
+	if miss := responseCacheMissFrom(ctx); miss != nil && miss.entry != nil {
+		headers = append(headers, &configPb.HeaderValueOption{
+			Header: &configPb.HeaderValue{
+				Key:      HeaderResponseCache,
+				RawValue: []byte("miss"),
+			},
+		})
+	}
+
+	// the middlewares and the model name rewrite change the length of the body.
+	var removeHeaders []string
+	if middlewareChainFrom(ctx).TransformsResponses(model) || (externalModel != "" && externalModel != model) {
+		removeHeaders = append(removeHeaders, "content-length")
+	}
+
 	var isProcessingError bool
 	var processingErrorCode int
 	for _, headerValue := range b.ResponseHeaders.Headers.Headers {
@@ -442,6 +853,9 @@ func (s *Server) HandleResponseHeaders(ctx context.Context, requestID string, re
 				processingErrorCode = code
 			}
 		}
+		if slices.Contains(removeHeaders, strings.ToLower(headerValue.Key)) {
+			continue
+		}
 		headers = append(headers, &configPb.HeaderValueOption{
 			Header: &configPb.HeaderValue{
 				Key:      headerValue.Key,
@@ -455,7 +869,8 @@ func (s *Server) HandleResponseHeaders(ctx context.Context, requestID string, re
 			ResponseHeaders: &extProcPb.HeadersResponse{
 				Response: &extProcPb.CommonResponse{
 					HeaderMutation: &extProcPb.HeaderMutation{
-						SetHeaders: headers,
+						SetHeaders:    headers,
+						RemoveHeaders: removeHeaders,
 					},
 					ClearRouteCache: true,
 				},
@@ -464,7 +879,7 @@ func (s *Server) HandleResponseHeaders(ctx context.Context, requestID string, re
 	}, isProcessingError, processingErrorCode
 }
 
-func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *extProcPb.ProcessingRequest, user utils.User, rpm int64, model string, targetPodIP string, stream bool, traceTerm int64, hasCompleted bool) (*extProcPb.ProcessingResponse, bool) {
+func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *extProcPb.ProcessingRequest, user utils.User, rpm int64, model, externalModel, endpoint, targetPodIP string, stream bool, traceTerm int64, hasCompleted bool) (*extProcPb.ProcessingResponse, bool) {
 	b := req.Request.(*extProcPb.ProcessingRequest_ResponseBody)
 	klog.InfoS("-- In ResponseBody processing ...", "requestID", requestID, "endOfSteam", b.ResponseBody.EndOfStream)
 
@@ -472,11 +887,23 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 	var usage openai.CompletionUsage
 	var promptTokens, completionTokens int64
 	var headers []*configPb.HeaderValueOption
+	var bodyMutation *extProcPb.BodyMutation
 	complete := hasCompleted
+	responseBody := b.ResponseBody.GetBody()
+	transformsResponses := middlewareChainFrom(ctx).TransformsResponses(model)
+
+	// the middlewares transform whole non streamed bodies, their model is rewritten once complete.
+	rewrittenBody := responseBody
+	if externalModel != "" && externalModel != model && (stream || !transformsResponses) {
+		rewrittenBody = s.modelRewriter.ToExternalChunk(modelRewriteStreamFrom(ctx), responseBody, model, externalModel, b.ResponseBody.EndOfStream)
+		bodyMutation = &extProcPb.BodyMutation{
+			Mutation: &extProcPb.BodyMutation_Body{Body: rewrittenBody},
+		}
+	}
 
 	defer func() {
-		// Wrapped in a function to delay the evaluation of parameters. Using complete to make sure DoneRequestTrace only call once for a request.
-		if !hasCompleted && complete && b.ResponseBody.EndOfStream {
+		// Wrapped in a function to delay the evaluation of parameters. The end of stream is received once, so DoneRequestTrace is called once for a request.
+		if complete && b.ResponseBody.EndOfStream {
 			s.cache.DoneRequestTrace(requestID, model, promptTokens, completionTokens, traceTerm)
 		}
 	}()
@@ -488,6 +915,7 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 		streaming := ssestream.NewStream[openai.ChatCompletionChunk](ssestream.NewDecoder(t), nil)
 		for streaming.Next() {
 			evt := streaming.Current()
+			appendStructuredOutput(ctx, evt)
 			if len(evt.Choices) == 0 {
 				// Do not overwrite model, res can be empty.
 				usage = evt.Usage
@@ -503,6 +931,9 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 				}}},
 				err.Error()), complete
 		}
+		if b.ResponseBody.EndOfStream {
+			validateStructuredOutput(ctx, requestID, model, true, nil)
+		}
 	} else {
 		// Use request ID as a key to store per-request buffer
 		// Retrieve or create buffer
@@ -513,10 +944,16 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 
 		if !b.ResponseBody.EndOfStream {
 			// Partial data received, wait for more chunks, we just return a common response here.
+			if transformsResponses {
+				// the middlewares transform the whole body, it is sent with the last chunk.
+				bodyMutation = &extProcPb.BodyMutation{Mutation: &extProcPb.BodyMutation_ClearBody{ClearBody: true}}
+			}
 			return &extProcPb.ProcessingResponse{
 				Response: &extProcPb.ProcessingResponse_ResponseBody{
 					ResponseBody: &extProcPb.BodyResponse{
-						Response: &extProcPb.CommonResponse{},
+						Response: &extProcPb.CommonResponse{
+							BodyMutation: bodyMutation,
+						},
 					},
 				},
 			}, complete
@@ -524,6 +961,7 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 
 		// Last part received, process the full response
 		finalBody := buffer.Bytes()
+		responseBody = finalBody
 		// Clean up the buffer after final processing
 		requestBuffers.Delete(requestID)
 
@@ -553,6 +991,9 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 		}
 		// Do not overwrite model, res can be empty.
 		usage = res.Usage
+		validateStructuredOutput(ctx, requestID, model, false, finalBody)
+		s.storeResponse(ctx, requestID, finalBody)
+		s.finishIdenticalRequests(ctx, finalBody)
 	}
 
 	var requestEnd string
@@ -561,9 +1002,18 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 		// Update promptTokens and completeTokens
 		promptTokens = usage.PromptTokens
 		completionTokens = usage.CompletionTokens
+		if promptTokens == 0 && completionTokens == 0 {
+			// rerank responses only report the total tokens, all of them input.
+			promptTokens = usage.TotalTokens
+		}
+		cachedTokens := usage.PromptTokensDetails.CachedTokens
+		if usage.PromptTokensDetails.JSON.CachedTokens.IsNull() {
+			cachedTokens = -1
+		}
+		routing.RecordUsage(ctx, promptTokens, cachedTokens)
 		// Count token per user.
 		if user.Name != "" {
-			tpm, err := s.ratelimiter.Incr(ctx, fmt.Sprintf("%v_TPM_CURRENT", user), res.Usage.TotalTokens)
+			tpm, err := s.ratelimiter.Incr(ctx, fmt.Sprintf("%v_TPM_CURRENT", user.Name), res.Usage.TotalTokens)
 			if err != nil {
 				return generateErrorResponse(
 					envoyTypePb.StatusCode_InternalServerError,
@@ -605,6 +1055,24 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 		klog.Infof("request end, requestID: %s - %s", requestID, requestEnd)
 	}
 
+	if transformsResponses {
+		if stream {
+			responseBody = rewrittenBody
+		} else if externalModel != "" {
+			responseBody = s.modelRewriter.ToExternal(responseBody, model, externalModel)
+		}
+		transformed := &middleware.Response{
+			Model: model, Endpoint: endpoint, Engine: s.targetEngine(model, targetPodIP), Stream: stream,
+			EndOfStream: b.ResponseBody.EndOfStream, Body: responseBody,
+		}
+		if err := middlewareChainFrom(ctx).TransformResponse(ctx, transformed); err != nil {
+			klog.ErrorS(err, "failed to transform response, passing it untransformed", "requestID", requestID, "model", model)
+		}
+		bodyMutation = &extProcPb.BodyMutation{
+			Mutation: &extProcPb.BodyMutation_Body{Body: transformed.Body},
+		}
+	}
+
 	return &extProcPb.ProcessingResponse{
 		Response: &extProcPb.ProcessingResponse_ResponseBody{
 			ResponseBody: &extProcPb.BodyResponse{
@@ -612,19 +1080,33 @@ func (s *Server) HandleResponseBody(ctx context.Context, requestID string, req *
 					HeaderMutation: &extProcPb.HeaderMutation{
 						SetHeaders: headers,
 					},
+					BodyMutation: bodyMutation,
 				},
 			},
 		},
 	}, complete
 }
 
-func (s *Server) checkLimits(ctx context.Context, user utils.User) (int64, *extProcPb.ProcessingResponse, error) {
+// withDefaultLimits returns the user with the default RPM and TPM limits where the user has none.
+func withDefaultLimits(user utils.User) utils.User {
+	gatewayConfig := config.Gateway()
 	if user.Rpm == 0 {
 		user.Rpm = int64(DefaultRPM)
+		if gatewayConfig.DefaultRPM > 0 {
+			user.Rpm = gatewayConfig.DefaultRPM
+		}
 	}
 	if user.Tpm == 0 {
 		user.Tpm = user.Rpm * int64(DefaultTPMMultiplier)
+		if gatewayConfig.DefaultTPMMultiplier > 0 {
+			user.Tpm = user.Rpm * gatewayConfig.DefaultTPMMultiplier
+		}
 	}
+	return user
+}
+
+func (s *Server) checkLimits(ctx context.Context, user utils.User) (int64, *extProcPb.ProcessingResponse, error) {
+	user = withDefaultLimits(user)
 
 	code, err := s.checkRPM(ctx, user.Name, user.Rpm)
 	if err != nil {
@@ -646,7 +1128,7 @@ func (s *Server) checkLimits(ctx context.Context, user utils.User) (int64, *extP
 			err.Error()), err
 	}
 
-	code, err = s.checkTPM(ctx, user.Name, user.Tpm)
+	code, err = s.checkTPM(ctx, user.Name, user.Tpm, 0)
 	if err != nil {
 		return 0, generateErrorResponse(
 			code,
@@ -681,13 +1163,14 @@ func (s *Server) incrRPM(ctx context.Context, username string) (int64, envoyType
 	return rpm, envoyTypePb.StatusCode_OK, nil
 }
 
-func (s *Server) checkTPM(ctx context.Context, username string, tpmLimit int64) (envoyTypePb.StatusCode, error) {
+// checkTPM checks the tokens of the user in the current minute, plus the input tokens of the request once known.
+func (s *Server) checkTPM(ctx context.Context, username string, tpmLimit, inputTokens int64) (envoyTypePb.StatusCode, error) {
 	tpmCurrent, err := s.ratelimiter.Get(ctx, fmt.Sprintf("%v_TPM_CURRENT", username))
 	if err != nil {
 		return envoyTypePb.StatusCode_InternalServerError, fmt.Errorf("fail to get TPM for user: %v", username)
 	}
 
-	if tpmCurrent >= tpmLimit {
+	if tpmCurrent >= tpmLimit || tpmCurrent+inputTokens > tpmLimit {
 		return envoyTypePb.StatusCode_TooManyRequests, fmt.Errorf("user: %v has exceeded TPM: %v", username, tpmLimit)
 	}
 
@@ -695,27 +1178,54 @@ func (s *Server) checkTPM(ctx context.Context, username string, tpmLimit int64)
 }
 
 func (s *Server) selectTargetPod(ctx context.Context, routingStrategy string, pods map[string]*v1.Pod, model, message string) (string, error) {
-	var route routing.Router
-	switch routingStrategy {
-	case "least-request":
-		route = s.routers[routingStrategy]
-	case "throughput":
-		route = s.routers[routingStrategy]
-	case "prefix-cache":
-		route = s.routers[routingStrategy]
-	case "prefix-cache-and-load":
-		route = s.routers[routingStrategy]
-	case "least-kv-cache":
-		route = s.routers[routingStrategy]
-	case "least-busy-time":
-		route = s.routers[routingStrategy]
-	case "least-latency":
-		route = s.routers[routingStrategy]
-	default:
-		route = s.routers["random"]
-	}
-
-	return route.Route(ctx, pods, model, message)
+	route, ok := s.routers[routingStrategy]
+	if !ok {
+		// unknown or uninitialized routers fall back to random routing.
+		route = s.routers[RouterRandom]
+	}
+
+	pods = s.zoneAffinity.Filter(pods, model)
+	pods = s.requestLength.Filter(pods, model, message)
+	return route.Route(ctx, s.slowStart.Filter(pods, model), model, message)
+}
+
+// trackPodRequest counts the request and its batch inputs as in-flight on the target pod until the stream ends, so
+// terminating pods know when they are drained and routers see the load not reported by the engine yet. It returns the
+// pod name, or "" if the pod is not in the cache anymore.
+func (s *Server) trackPodRequest(model, targetPodIP string, batchSize int) string {
+	name, pod := s.targetPod(model, targetPodIP)
+	if pod == nil {
+		return ""
+	}
+	s.cache.AddPodRequest(name)
+	s.cache.AddPodBatchItems(name, int32(batchSize))
+	return name
+}
+
+// targetPod returns the pod of the model at the target address, nil if it is gone.
+func (s *Server) targetPod(model, targetPodIP string) (string, *v1.Pod) {
+	host, _, err := net.SplitHostPort(targetPodIP)
+	if err != nil {
+		host = targetPodIP
+	}
+	pods, err := s.cache.GetPodsForModel(model)
+	if err != nil {
+		return "", nil
+	}
+	for name, pod := range pods {
+		if pod.Status.PodIP == host {
+			return name, pod
+		}
+	}
+	return "", nil
+}
+
+// targetEngine returns the inference engine of the pod at the target address, empty if unknown.
+func (s *Server) targetEngine(model, targetPodIP string) string {
+	if _, pod := s.targetPod(model, targetPodIP); pod != nil {
+		return cache.PodEngine(pod)
+	}
+	return ""
 }
 
 func validateRoutingStrategy(routingStrategy string) bool {
@@ -723,6 +1233,23 @@ func validateRoutingStrategy(routingStrategy string) bool {
 	return slices.Contains(routingStrategies, routingStrategy)
 }
 
+// ValidateGatewayConfig checks the gateway configuration against the routing strategies, the middlewares and the
+// provider types known to the gateway.
+func ValidateGatewayConfig(gatewayConfig config.GatewayConfig) error {
+	if gatewayConfig.RoutingStrategy != "" && !validateRoutingStrategy(gatewayConfig.RoutingStrategy) {
+		return fmt.Errorf("invalid routingStrategy: %s", gatewayConfig.RoutingStrategy)
+	}
+	if _, err := middleware.Build(gatewayConfig.Middlewares); err != nil {
+		return err
+	}
+	for _, route := range gatewayConfig.Providers {
+		if _, err := provider.New(route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func generateErrorResponse(statusCode envoyTypePb.StatusCode, headers []*configPb.HeaderValueOption, body string) *extProcPb.ProcessingResponse {
 	// Set the Content-Type header to application/json
 	headers = append(headers, &configPb.HeaderValueOption{
@@ -747,23 +1274,46 @@ func generateErrorResponse(statusCode envoyTypePb.StatusCode, headers []*configP
 	}
 }
 
-func getRequestMessage(jsonMap map[string]interface{}) (string, *extProcPb.ProcessingResponse) {
-	messages, ok := jsonMap["messages"]
-	if !ok {
-		return "", generateErrorResponse(envoyTypePb.StatusCode_InternalServerError,
-			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{Key: HeaderErrorRequestBodyProcessing, RawValue: []byte("true")}}},
-			"no messages in the request body")
-	}
-	messagesJSON, err := json.Marshal(messages)
+// estimateInputTokens counts the input tokens of the request with the tokenizer and the chat template of the model,
+// approximately for large inputs. It returns 0 if the input can't be tokenized.
+func estimateInputTokens(ctx context.Context, model string, input requestInput) int64 {
+	_, span := tracing.StartSpan(ctx, "gateway.tokenize", tracing.SpanKindInternal)
+	defer span.End()
+	tokens, err := input.countTokens(model)
+	span.SetAttribute("model", model)
+	span.SetAttribute("input_tokens", tokens)
+	span.RecordError(err)
 	if err != nil {
-		return "", generateErrorResponse(envoyTypePb.StatusCode_InternalServerError,
-			[]*configPb.HeaderValueOption{{Header: &configPb.HeaderValue{Key: HeaderErrorRequestBodyProcessing, RawValue: []byte("true")}}},
-			"unable to marshal messages from request body")
+		klog.ErrorS(err, "failed to count input tokens", "model", model)
+		return 0
 	}
-	return string(messagesJSON), nil
+	return int64(tokens)
 }
 
-// GetRoutingStrategy retrieves the routing strategy from the headers or environment variable
+func hasRoutingStrategyHeader(headers []*configPb.HeaderValue) bool {
+	for _, header := range headers {
+		if strings.ToLower(header.Key) == HeaderRoutingStrategy {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveRoutingStrategy returns the routing strategy of the request, the routing-strategy header takes precedence
+// over the model configuration, which takes precedence over the gateway configuration and the ROUTING_ALGORITHM
+// environment variable.
+func resolveRoutingStrategy(headerStrategy string, modelConfig ModelConfig) string {
+	if headerStrategy != "" {
+		return headerStrategy
+	}
+	if modelConfig.RoutingStrategy != "" {
+		return modelConfig.RoutingStrategy
+	}
+	routingStrategy, _ := GetRoutingStrategy(nil)
+	return routingStrategy
+}
+
+// GetRoutingStrategy retrieves the routing strategy from the headers, the gateway configuration or environment variable
 // It returns the routing strategy value and whether custom routing strategy is enabled.
 func GetRoutingStrategy(headers []*configPb.HeaderValue) (string, bool) {
 	var routingStrategy string
@@ -778,7 +1328,11 @@ func GetRoutingStrategy(headers []*configPb.HeaderValue) (string, bool) {
 		}
 	}
 
-	// If header not set, check environment variable
+	// If header not set, check the gateway configuration and then environment variable
+	if !routingStrategyEnabled && config.Gateway().RoutingStrategy != "" {
+		routingStrategy = config.Gateway().RoutingStrategy
+		routingStrategyEnabled = true
+	}
 	if !routingStrategyEnabled {
 		if value, exists := utils.CheckEnvExists(EnvRoutingAlgorithm); exists {
 			routingStrategy = value
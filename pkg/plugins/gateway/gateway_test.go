@@ -17,11 +17,16 @@ limitations under the License.
 package gateway
 
 import (
+	"context"
+	"encoding/json"
 	"os"
 	"testing"
 
 	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/config"
 )
 
 func Test_ValidateRoutingStrategy(t *testing.T) {
@@ -123,3 +128,78 @@ func TestGetRoutingStrategy(t *testing.T) {
 		_ = os.Unsetenv("ROUTING_ALGORITHM")
 	}
 }
+
+func TestGetRoutingStrategyFromGatewayConfig(t *testing.T) {
+	t.Setenv("ROUTING_ALGORITHM", "random")
+	config.SetGateway(config.GatewayConfig{RoutingStrategy: "least-request"})
+	defer config.SetGateway(config.GatewayConfig{})
+
+	routingStrategy, enabled := GetRoutingStrategy(nil)
+	assert.Equal(t, "least-request", routingStrategy, "gateway config takes priority over environment variable")
+	assert.True(t, enabled)
+
+	routingStrategy, _ = GetRoutingStrategy([]*configPb.HeaderValue{{Key: "routing-strategy", RawValue: []byte("random")}})
+	assert.Equal(t, "random", routingStrategy, "header routing strategy takes priority over gateway config")
+
+	assert.NoError(t, ValidateGatewayConfig(config.GatewayConfig{RoutingStrategy: "least-request"}))
+	assert.Error(t, ValidateGatewayConfig(config.GatewayConfig{RoutingStrategy: "unknown"}))
+	assert.NoError(t, ValidateGatewayConfig(config.GatewayConfig{Middlewares: []config.MiddlewareConfig{
+		{Name: "watermark", Type: "watermark", Config: json.RawMessage(`{"text": "[ai]"}`)},
+	}}))
+	assert.Error(t, ValidateGatewayConfig(config.GatewayConfig{Middlewares: []config.MiddlewareConfig{
+		{Name: "unknown", Type: "unknown"},
+	}}))
+}
+
+func TestEstimateInputTokens(t *testing.T) {
+	ctx := context.Background()
+	chat, err := parseRequestInput(EndpointChatCompletions, map[string]interface{}{
+		"messages": []interface{}{map[string]interface{}{"role": "user", "content": "hello world"}},
+	})
+	assert.NoError(t, err)
+	// the chat template adds the role markers and the assistant prompt to the content.
+	assert.Greater(t, estimateInputTokens(ctx, "llama", chat), int64(2))
+
+	completion, err := parseRequestInput(EndpointCompletions, map[string]interface{}{"prompt": "hello world"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), estimateInputTokens(ctx, "llama", completion))
+
+	// the query is scored against each document.
+	rerank, err := parseRequestInput(EndpointRerank, map[string]interface{}{
+		"query": "hello", "documents": []interface{}{"hello world", map[string]interface{}{"text": "world"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1+2+1+1), estimateInputTokens(ctx, "llama", rerank))
+}
+
+type fakeRateLimiter map[string]int64
+
+func (f fakeRateLimiter) Get(_ context.Context, key string) (int64, error) {
+	return f[key], nil
+}
+
+func (f fakeRateLimiter) GetLimit(context.Context, string) (int64, error) {
+	return 0, nil
+}
+
+func (f fakeRateLimiter) Incr(_ context.Context, key string, val int64) (int64, error) {
+	f[key] += val
+	return f[key], nil
+}
+
+func TestCheckTPM(t *testing.T) {
+	s := &Server{ratelimiter: fakeRateLimiter{"alice_TPM_CURRENT": 900}}
+	ctx := context.Background()
+
+	code, err := s.checkTPM(ctx, "alice", 1000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, envoyTypePb.StatusCode_OK, code)
+	_, err = s.checkTPM(ctx, "alice", 1000, 100)
+	assert.NoError(t, err)
+	// the input tokens of the request would exceed the limit.
+	code, err = s.checkTPM(ctx, "alice", 1000, 101)
+	assert.Error(t, err)
+	assert.Equal(t, envoyTypePb.StatusCode_TooManyRequests, code)
+	_, err = s.checkTPM(ctx, "alice", 900, 0)
+	assert.Error(t, err)
+}
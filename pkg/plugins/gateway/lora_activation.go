@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/client/clientset/versioned"
+	"github.com/vllm-project/aibrix/pkg/features"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultLoraSaturationWaitingRequests = 4
+	defaultLoraActivationCooldownInSecs  = 60
+)
+
+var (
+	loraSaturationWaitingRequests = getLoraSaturationWaitingRequests()
+	loraActivationCooldown        = getLoraActivationCooldown()
+)
+
+func getLoraSaturationWaitingRequests() float64 {
+	value := utils.LoadEnv("AIBRIX_LORA_SATURATION_WAITING_REQUESTS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_LORA_SATURATION_WAITING_REQUESTS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_LORA_SATURATION_WAITING_REQUESTS env value for lora saturation threshold: %d", intValue)
+			return float64(intValue)
+		}
+	}
+	return defaultLoraSaturationWaitingRequests
+}
+
+func getLoraActivationCooldown() time.Duration {
+	value := utils.LoadEnv("AIBRIX_LORA_ACTIVATION_COOLDOWN_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_LORA_ACTIVATION_COOLDOWN_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_LORA_ACTIVATION_COOLDOWN_S env value for lora activation cooldown: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultLoraActivationCooldownInSecs * time.Second
+}
+
+// loraActivator asks the ModelAdapter controller to load an adapter on one more pod
+// once all pods currently hosting it are saturated.
+type loraActivator struct {
+	client       versioned.Interface
+	cache        cache.Interface
+	lastActivate sync.Map // adapter_name: time.Time
+}
+
+func newLoraActivator(client versioned.Interface, c cache.Interface) *loraActivator {
+	return &loraActivator{
+		client: client,
+		cache:  c,
+	}
+}
+
+// MaybeActivate requests a new adapter instance if the model of the request is a lora adapter and all its ready pods
+// are saturated. The patch is issued asynchronously and rate limited per adapter, it never blocks the request.
+func (a *loraActivator) MaybeActivate(flagTarget features.Target, pods map[string]*v1.Pod) {
+	if a == nil || a.client == nil || !features.IsFlagEnabled(features.LoraActivation, flagTarget) {
+		return
+	}
+	model := flagTarget.Model
+	adapter, err := a.cache.GetModelAdapter(model)
+	if err != nil {
+		return
+	}
+	if !allPodsQueued(a.cache, model, utils.FilterReadyPods(pods), loraSaturationWaitingRequests) {
+		return
+	}
+
+	now := time.Now()
+	if last, ok := a.lastActivate.Load(adapter.Name); ok && now.Sub(last.(time.Time)) < loraActivationCooldown {
+		return
+	}
+	a.lastActivate.Store(adapter.Name, now)
+
+	desired := len(adapter.Status.Instances) + 1
+	key := types.NamespacedName{Namespace: adapter.Namespace, Name: adapter.Name}
+	go func() {
+		if err := a.patchDesiredInstances(key, desired, now); err != nil {
+			klog.ErrorS(err, "failed to request lora adapter activation", "modelAdapter", key, "desiredInstances", desired)
+			return
+		}
+		klog.InfoS("requested lora adapter activation on additional pod", "modelAdapter", key, "desiredInstances", desired)
+	}()
+}
+
+// patchDesiredInstances raises the desired instances of the adapter along with the time, the controller falls back to
+// spec.replicas once it's not raised again within its idle window.
+func (a *loraActivator) patchDesiredInstances(key types.NamespacedName, desired int, now time.Time) error {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q,%q:%q}}}`,
+		modelv1alpha1.DesiredInstancesAnnotation, strconv.Itoa(desired),
+		modelv1alpha1.DesiredInstancesTimeAnnotation, now.UTC().Format(time.RFC3339))
+	_, err := a.client.ModelV1alpha1().ModelAdapters(key.Namespace).Patch(context.Background(), key.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	modelv1alpha1 "github.com/vllm-project/aibrix/api/model/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/client/clientset/versioned/fake"
+)
+
+func TestPatchDesiredInstances(t *testing.T) {
+	client := fake.NewSimpleClientset(&modelv1alpha1.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{Name: "lora", Namespace: "default", Annotations: map[string]string{"other": "kept"}},
+	})
+	a := newLoraActivator(client, nil)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	require.NoError(t, a.patchDesiredInstances(types.NamespacedName{Namespace: "default", Name: "lora"}, 3, now))
+
+	adapter, err := client.ModelV1alpha1().ModelAdapters("default").Get(context.Background(), "lora", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"other":                                  "kept",
+		modelv1alpha1.DesiredInstancesAnnotation: "3",
+		modelv1alpha1.DesiredInstancesTimeAnnotation: "2025-01-01T11:00:00Z",
+	}, adapter.Annotations)
+}
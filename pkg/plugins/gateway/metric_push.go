@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/auth"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const maxMetricPushBytes = 1 << 20
+
+// metricPushToken is the bearer token the pushes must carry, pushes are only checked against the pod IP if empty.
+var metricPushToken = utils.LoadEnv("AIBRIX_METRIC_PUSH_TOKEN", "")
+
+// MetricPushHandler serves POST /metrics/push, the engines or their sidecars push a cache.MetricPush when their
+// metrics change, so that routing doesn't wait for the next scrape. A push is only accepted from the IP of the pod it
+// is about, and with AIBRIX_METRIC_PUSH_TOKEN set, with the token as bearer token.
+func MetricPushHandler() http.Handler {
+	return metricPushHandler(metricPushToken, func(podName string) (string, error) {
+		c, err := cache.GetCache()
+		if err != nil {
+			return "", err
+		}
+		pod, err := c.GetPod(podName)
+		if err != nil {
+			return "", err
+		}
+		return pod.Status.PodIP, nil
+	}, func(push cache.MetricPush) error {
+		c, err := cache.GetCache()
+		if err != nil {
+			return err
+		}
+		return c.PushPodMetrics(push)
+	})
+}
+
+func metricPushHandler(token string, podIP func(podName string) (string, error), push func(cache.MetricPush) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if token != "" {
+			pushToken, err := auth.ParseBearerToken(r.Header.Get("Authorization"))
+			if err != nil || subtle.ConstantTimeCompare([]byte(pushToken), []byte(token)) != 1 {
+				http.Error(w, "invalid metric push token", http.StatusUnauthorized)
+				return
+			}
+		}
+		var metricPush cache.MetricPush
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxMetricPushBytes)).Decode(&metricPush); err != nil {
+			http.Error(w, "invalid metric push: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if metricPush.Pod == "" {
+			http.Error(w, "pod is required", http.StatusBadRequest)
+			return
+		}
+		ip, err := podIP(metricPush.Pod)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if host, _, _ := net.SplitHostPort(r.RemoteAddr); ip == "" || host != ip {
+			klog.V(4).InfoS("rejected metric push from another address than the pod", "pod", metricPush.Pod, "remoteAddr", r.RemoteAddr)
+			http.Error(w, "metrics can only be pushed from the pod", http.StatusForbidden)
+			return
+		}
+		if err := push(metricPush); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, cache.ErrMetricPushDisabled) {
+				status = http.StatusNotFound
+			}
+			klog.V(4).ErrorS(err, "rejected metric push", "pod", metricPush.Pod)
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+func TestMetricPushHandler(t *testing.T) {
+	var pushed []cache.MetricPush
+	var pushErr error
+	handler := metricPushHandler("", podIPs, func(push cache.MetricPush) error {
+		pushed = append(pushed, push)
+		return pushErr
+	})
+	post := func(method, body string) int {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/metrics/push", strings.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:41234"
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, post(http.MethodPost, `{"pod": "p1", "model": "llama", "metrics": {"num_requests_waiting": 4}}`))
+	assert.Equal(t, []cache.MetricPush{{Pod: "p1", Model: "llama", Metrics: map[string]float64{"num_requests_waiting": 4}}}, pushed)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, post(http.MethodGet, ""))
+	assert.Equal(t, http.StatusBadRequest, post(http.MethodPost, `{"pod":`))
+	assert.Equal(t, http.StatusBadRequest, post(http.MethodPost, `{"metrics": {"num_requests_waiting": 4}}`))
+	assert.Equal(t, http.StatusBadRequest, post(http.MethodPost, `{"pod": "unknown", "metrics": {"num_requests_waiting": 4}}`))
+
+	pushErr = errors.New("metric max_lora can't be pushed")
+	assert.Equal(t, http.StatusBadRequest, post(http.MethodPost, `{"pod": "p1", "metrics": {"max_lora": 4}}`))
+	pushErr = cache.ErrMetricPushDisabled
+	assert.Equal(t, http.StatusNotFound, post(http.MethodPost, `{"pod": "p1", "metrics": {"num_requests_waiting": 4}}`))
+}
+
+func TestMetricPushHandlerRejectsSpoofedPush(t *testing.T) {
+	var pushed []cache.MetricPush
+	handler := metricPushHandler("", podIPs, func(push cache.MetricPush) error {
+		pushed = append(pushed, push)
+		return nil
+	})
+	for _, push := range []string{`{"pod": "p2", "metrics": {"num_requests_waiting": 0}}`, `{"pod": "pending", "metrics": {"num_requests_waiting": 0}}`} {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/metrics/push", strings.NewReader(push))
+		req.RemoteAddr = "10.0.0.1:41234"
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusForbidden, recorder.Code, push)
+	}
+	assert.Empty(t, pushed)
+}
+
+func TestMetricPushHandlerToken(t *testing.T) {
+	var pushed []cache.MetricPush
+	handler := metricPushHandler("push-token", podIPs, func(push cache.MetricPush) error {
+		pushed = append(pushed, push)
+		return nil
+	})
+	post := func(authorization string) int {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/metrics/push", strings.NewReader(`{"pod": "p1", "metrics": {"num_requests_waiting": 4}}`))
+		req.RemoteAddr = "10.0.0.1:41234"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post(""))
+	assert.Equal(t, http.StatusUnauthorized, post("Bearer other-token"))
+	assert.Empty(t, pushed)
+	assert.Equal(t, http.StatusNoContent, post("Bearer push-token"))
+	assert.Len(t, pushed, 1)
+}
+
+func podIPs(podName string) (string, error) {
+	switch podName {
+	case "p1":
+		return "10.0.0.1", nil
+	case "p2":
+		return "10.0.0.2", nil
+	case "pending":
+		return "", nil
+	}
+	return "", fmt.Errorf("pod does not exist in the cache: %s", podName)
+}
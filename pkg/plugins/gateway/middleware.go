@@ -0,0 +1,63 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/middleware"
+)
+
+// middlewareChains builds the middleware chain of the gateway configuration once per configuration snapshot.
+type middlewareChains struct {
+	mu     sync.Mutex
+	config *config.GatewayConfig
+	chain  *middleware.Chain
+}
+
+// Get returns the chain of the latest configuration. A configuration failing to build runs no middleware, it is
+// rejected on reload so this only happens to plugins failing at startup.
+func (m *middlewareChains) Get() *middleware.Chain {
+	gatewayConfig := config.Gateway()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.config != gatewayConfig {
+		chain, err := middleware.Build(gatewayConfig.Middlewares)
+		if err != nil {
+			klog.ErrorS(err, "failed to build the middlewares, running none")
+		}
+		m.config, m.chain = gatewayConfig, chain
+	}
+	return m.chain
+}
+
+type middlewareChainKey struct{}
+
+// withMiddlewareChain keeps the chain of a request for all its phases, even if the configuration changes meanwhile.
+func withMiddlewareChain(ctx context.Context, chain *middleware.Chain) context.Context {
+	return context.WithValue(ctx, middlewareChainKey{}, chain)
+}
+
+// middlewareChainFrom returns the chain of the request, nil runs no middleware.
+func middlewareChainFrom(ctx context.Context) *middleware.Chain {
+	chain, _ := ctx.Value(middlewareChainKey{}).(*middleware.Chain)
+	return chain
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	TypeSystemPrompt = "system-prompt"
+	TypePIIRedaction = "pii-redaction"
+	TypeWatermark    = "watermark"
+	// TypeToolCallNormalization rewrites the tool calls of chat completions to the OpenAI format.
+	TypeToolCallNormalization = "tool-call-normalization"
+
+	endpointChatCompletions = "chat_completions"
+	endpointCompletions     = "completions"
+
+	defaultRedactionReplacement = "[REDACTED]"
+)
+
+func init() {
+	Register(TypeSystemPrompt, newSystemPrompt)
+	Register(TypePIIRedaction, newPIIRedaction)
+	Register(TypeWatermark, newWatermark)
+	Register(TypeToolCallNormalization, newToolCallNormalization)
+}
+
+// decodeConfig decodes the configuration of a middleware, unknown fields are rejected to catch typos.
+func decodeConfig(data json.RawMessage, config interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(config)
+}
+
+// systemPrompt puts a system message before the messages of chat completions and before the prompt of completions.
+type systemPrompt struct {
+	Content string `json:"content"`
+}
+
+func newSystemPrompt(config json.RawMessage) (interface{}, error) {
+	m := &systemPrompt{}
+	if err := decodeConfig(config, m); err != nil {
+		return nil, err
+	}
+	if m.Content == "" {
+		return nil, fmt.Errorf("no content")
+	}
+	return m, nil
+}
+
+func (m *systemPrompt) TransformRequest(_ context.Context, req *Request) error {
+	switch req.Endpoint {
+	case endpointChatCompletions:
+		messages, _ := req.Body["messages"].([]interface{})
+		system := map[string]interface{}{"role": "system", "content": m.Content}
+		req.Body["messages"] = append([]interface{}{system}, messages...)
+	case endpointCompletions:
+		if prompt, ok := req.Body["prompt"].(string); ok {
+			req.Body["prompt"] = m.Content + "\n\n" + prompt
+		}
+	}
+	return nil
+}
+
+// piiPatterns match personal data by type. Credit card numbers are also checked with the Luhn algorithm.
+var piiPatterns = map[string]*regexp.Regexp{
+	"email":       regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	"credit_card": regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+	"ssn":         regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+	"phone":       regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?\(?\b\d{3}\)?[ .-]?\d{3}[ .-]?\d{4}\b`),
+	"ipv4":        regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`),
+}
+
+// piiTypes is the order the patterns apply in, card numbers before the phone numbers they contain.
+var piiTypes = []string{"email", "credit_card", "ssn", "phone", "ipv4"}
+
+// piiRedaction replaces personal data in the prompts, and in the responses if enabled.
+type piiRedaction struct {
+	Types       []string `json:"types"`
+	Replacement string   `json:"replacement"`
+	Responses   bool     `json:"responses"`
+
+	patterns []*regexp.Regexp
+	luhn     []bool // the pattern at the same index is checked with the Luhn algorithm
+}
+
+func newPIIRedaction(config json.RawMessage) (interface{}, error) {
+	m := &piiRedaction{Replacement: defaultRedactionReplacement}
+	if err := decodeConfig(config, m); err != nil {
+		return nil, err
+	}
+	types := m.Types
+	if len(types) == 0 {
+		types = piiTypes
+	}
+	for _, piiType := range piiTypes {
+		for _, t := range types {
+			if t == piiType {
+				m.patterns = append(m.patterns, piiPatterns[t])
+				m.luhn = append(m.luhn, t == "credit_card")
+			}
+		}
+	}
+	for _, t := range types {
+		if _, ok := piiPatterns[t]; !ok {
+			return nil, fmt.Errorf("unknown type %q, must be one of %s", t, strings.Join(piiTypes, ", "))
+		}
+	}
+	if strings.ContainsAny(m.Replacement, `"\`) {
+		return nil, fmt.Errorf("the replacement must not contain quotes or backslashes")
+	}
+	return m, nil
+}
+
+func (m *piiRedaction) redact(text string) string {
+	for i, pattern := range m.patterns {
+		if m.luhn[i] {
+			text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+				if luhnValid(match) {
+					return m.Replacement
+				}
+				return match
+			})
+		} else {
+			text = pattern.ReplaceAllLiteralString(text, m.Replacement)
+		}
+	}
+	return text
+}
+
+// redactValue redacts the strings of a decoded JSON value in place.
+func (m *piiRedaction) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return m.redact(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = m.redactValue(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = m.redactValue(v[key])
+		}
+	}
+	return value
+}
+
+func (m *piiRedaction) TransformRequest(_ context.Context, req *Request) error {
+	for _, field := range []string{"messages", "prompt", "input", "query", "documents", "text_1", "text_2"} {
+		if value, ok := req.Body[field]; ok {
+			req.Body[field] = m.redactValue(value)
+		}
+	}
+	return nil
+}
+
+func (m *piiRedaction) TransformResponse(_ context.Context, resp *Response) error {
+	if m.Responses {
+		// the replacement has no character to escape, it can replace text within JSON strings.
+		resp.Body = []byte(m.redact(string(resp.Body)))
+	}
+	return nil
+}
+
+// luhnValid checks the digits of a card number.
+func luhnValid(number string) bool {
+	sum, digits := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// watermark appends a text to the completions, as a last event of streamed responses.
+type watermark struct {
+	Text string `json:"text"`
+}
+
+func newWatermark(config json.RawMessage) (interface{}, error) {
+	m := &watermark{}
+	if err := decodeConfig(config, m); err != nil {
+		return nil, err
+	}
+	if m.Text == "" {
+		return nil, fmt.Errorf("no text")
+	}
+	return m, nil
+}
+
+var sseDone = []byte("data: [DONE]")
+
+func (m *watermark) TransformResponse(_ context.Context, resp *Response) error {
+	if resp.Endpoint != endpointChatCompletions && resp.Endpoint != endpointCompletions {
+		return nil
+	}
+
+	if resp.Stream {
+		i := bytes.Index(resp.Body, sseDone)
+		if i < 0 {
+			return nil
+		}
+		choice := map[string]interface{}{"index": 0, "text": m.Text}
+		object := "text_completion"
+		if resp.Endpoint == endpointChatCompletions {
+			choice = map[string]interface{}{"index": 0, "delta": map[string]interface{}{"content": m.Text}}
+			object = "chat.completion.chunk"
+		}
+		event, err := json.Marshal(map[string]interface{}{"object": object, "model": resp.Model, "choices": []interface{}{choice}})
+		if err != nil {
+			return err
+		}
+		body := append([]byte(nil), resp.Body[:i]...)
+		body = append(body, "data: "...)
+		body = append(body, event...)
+		body = append(body, "\n\n"...)
+		resp.Body = append(body, resp.Body[i:]...)
+		return nil
+	}
+
+	var completion map[string]interface{}
+	if err := json.Unmarshal(resp.Body, &completion); err != nil {
+		return err
+	}
+	choices, _ := completion["choices"].([]interface{})
+	for _, c := range choices {
+		choice, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if message, ok := choice["message"].(map[string]interface{}); ok {
+			if content, ok := message["content"].(string); ok {
+				message["content"] = content + m.Text
+			}
+		} else if text, ok := choice["text"].(string); ok {
+			choice["text"] = text + m.Text
+		}
+	}
+	body, err := json.Marshal(completion)
+	if err != nil {
+		return err
+	}
+	resp.Body = body
+	return nil
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package middleware transforms the requests and the responses going through the gateway, e.g. to inject system
+// prompts, redact personal data or watermark responses. Middlewares are built-in or loaded from Go plugins.
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+const (
+	// TypePlugin loads the middleware from the Go plugin at MiddlewareConfig.Plugin.
+	TypePlugin = "plugin"
+
+	phaseRequest  = "request"
+	phaseResponse = "response"
+)
+
+// errPluginsUnsupported rejects the plugin middlewares of a gateway built without cgo.
+var errPluginsUnsupported = errors.New("go plugins are not supported, the gateway is built without cgo")
+
+var (
+	middlewareDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "aibrix_gateway_middleware_duration_seconds",
+		Help:    "Time spent in a middleware, by phase: request or response.",
+		Buckets: []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5},
+	}, []string{"middleware", "phase"})
+	middlewareErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_middleware_errors_total",
+		Help: "Failed middleware calls, by phase. Failed requests are rejected, failed responses are passed untransformed.",
+	}, []string{"middleware", "phase"})
+)
+
+func init() {
+	prometheus.MustRegister(middlewareDuration, middlewareErrors)
+}
+
+// Request is a request body before it is routed.
+type Request struct {
+	Model string
+	// Endpoint is the OpenAI-compatible endpoint of the request, e.g. chat_completions or embeddings.
+	Endpoint string
+	User     string
+	// Body is the decoded JSON body, middlewares modify it in place.
+	Body map[string]interface{}
+}
+
+// Response is the whole body of a response, or a chunk of server-sent events of a streamed response.
+type Response struct {
+	Model    string
+	Endpoint string
+	// Engine is the inference engine of the pod serving the response, e.g. vllm, empty if unknown.
+	Engine string
+	Stream bool
+	// EndOfStream is true for the last chunk of a streamed response.
+	EndOfStream bool
+	// Body is replaced by middlewares transforming the response.
+	Body []byte
+}
+
+// RequestTransformer transforms requests, an error rejects the request.
+type RequestTransformer interface {
+	TransformRequest(ctx context.Context, req *Request) error
+}
+
+// ResponseTransformer transforms responses, an error passes the response untransformed.
+type ResponseTransformer interface {
+	TransformResponse(ctx context.Context, resp *Response) error
+}
+
+// Factory creates a middleware from its configuration. The middleware implements RequestTransformer,
+// ResponseTransformer or both.
+type Factory func(config json.RawMessage) (interface{}, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register makes a middleware type available to the gateway configuration.
+func Register(typeName string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[typeName] = factory
+}
+
+// Error is the failure of a middleware.
+type Error struct {
+	Middleware string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("middleware %s: %v", e.Middleware, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+type middleware struct {
+	name     string
+	models   []string
+	request  RequestTransformer
+	response ResponseTransformer
+}
+
+func (m *middleware) appliesTo(model string) bool {
+	return len(m.models) == 0 || slices.Contains(m.models, model)
+}
+
+// Chain runs the middlewares in their configuration order. A nil chain runs none.
+type Chain struct {
+	middlewares []*middleware
+}
+
+// Build creates the middlewares of the configuration. A plugin middleware fails the configuration before any
+// middleware is created if the gateway can't load Go plugins.
+func Build(configs []config.MiddlewareConfig) (*Chain, error) {
+	for _, cfg := range configs {
+		if cfg.Type == TypePlugin && !PluginsSupported {
+			return nil, fmt.Errorf("invalid middleware %s: %w", cfg.Name, errPluginsUnsupported)
+		}
+	}
+	chain := &Chain{}
+	for _, cfg := range configs {
+		var (
+			instance interface{}
+			err      error
+		)
+		if cfg.Type == TypePlugin {
+			instance, err = loadPlugin(cfg.Plugin, cfg.Config)
+		} else {
+			mu.RLock()
+			factory, ok := factories[cfg.Type]
+			mu.RUnlock()
+			if !ok {
+				return nil, fmt.Errorf("unknown type %q of middleware %s", cfg.Type, cfg.Name)
+			}
+			instance, err = factory(cfg.Config)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create middleware %s: %w", cfg.Name, err)
+		}
+
+		m := &middleware{name: cfg.Name, models: cfg.Models}
+		m.request, _ = instance.(RequestTransformer)
+		m.response, _ = instance.(ResponseTransformer)
+		if m.request == nil && m.response == nil {
+			return nil, fmt.Errorf("middleware %s transforms neither requests nor responses", cfg.Name)
+		}
+		chain.middlewares = append(chain.middlewares, m)
+	}
+	return chain, nil
+}
+
+// TransformsResponses reports whether a middleware transforms the responses of the model.
+func (c *Chain) TransformsResponses(model string) bool {
+	if c == nil {
+		return false
+	}
+	for _, m := range c.middlewares {
+		if m.response != nil && m.appliesTo(model) {
+			return true
+		}
+	}
+	return false
+}
+
+// TransformRequest runs the request middlewares of the model. It stops at the first failure, returned as an *Error.
+// It reports whether a middleware ran, i.e. the body may have changed.
+func (c *Chain) TransformRequest(ctx context.Context, req *Request) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+	transformed := false
+	for _, m := range c.middlewares {
+		if m.request == nil || !m.appliesTo(req.Model) {
+			continue
+		}
+		start := time.Now()
+		err := m.request.TransformRequest(ctx, req)
+		middlewareDuration.WithLabelValues(m.name, phaseRequest).Observe(time.Since(start).Seconds())
+		if err != nil {
+			middlewareErrors.WithLabelValues(m.name, phaseRequest).Inc()
+			return transformed, &Error{Middleware: m.name, Err: err}
+		}
+		transformed = true
+	}
+	return transformed, nil
+}
+
+// TransformResponse runs the response middlewares of the model. A failing middleware leaves the body as it was and
+// the next middlewares run, the failures are returned joined.
+func (c *Chain) TransformResponse(ctx context.Context, resp *Response) error {
+	if c == nil {
+		return nil
+	}
+	var errs []error
+	for _, m := range c.middlewares {
+		if m.response == nil || !m.appliesTo(resp.Model) {
+			continue
+		}
+		body := resp.Body
+		start := time.Now()
+		err := m.response.TransformResponse(ctx, resp)
+		middlewareDuration.WithLabelValues(m.name, phaseResponse).Observe(time.Since(start).Seconds())
+		if err != nil {
+			middlewareErrors.WithLabelValues(m.name, phaseResponse).Inc()
+			resp.Body = body
+			errs = append(errs, &Error{Middleware: m.name, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
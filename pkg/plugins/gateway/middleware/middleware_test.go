@@ -0,0 +1,244 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+// appendText appends its text to the prompt and to the response, or fails.
+type appendText struct {
+	text string
+	fail bool
+}
+
+func (m *appendText) TransformRequest(_ context.Context, req *Request) error {
+	if m.fail {
+		return errors.New("failed")
+	}
+	req.Body["prompt"] = req.Body["prompt"].(string) + m.text
+	return nil
+}
+
+func (m *appendText) TransformResponse(_ context.Context, resp *Response) error {
+	if m.fail {
+		resp.Body = nil
+		return errors.New("failed")
+	}
+	resp.Body = append(resp.Body, m.text...)
+	return nil
+}
+
+func init() {
+	Register("append", func(data json.RawMessage) (interface{}, error) {
+		m := &appendText{}
+		var cfg struct {
+			Text string `json:"text"`
+			Fail bool   `json:"fail"`
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, err
+		}
+		m.text, m.fail = cfg.Text, cfg.Fail
+		return m, nil
+	})
+	Register("noop", func(json.RawMessage) (interface{}, error) { return struct{}{}, nil })
+}
+
+func TestChain(t *testing.T) {
+	chain, err := Build([]config.MiddlewareConfig{
+		{Name: "a", Type: "append", Config: json.RawMessage(`{"text": "a"}`)},
+		{Name: "b", Type: "append", Models: []string{"llama"}, Config: json.RawMessage(`{"text": "b"}`)},
+	})
+	assert.NoError(t, err)
+	assert.True(t, chain.TransformsResponses("mistral"))
+
+	// the middlewares run in order, for their models only.
+	req := &Request{Model: "llama", Body: map[string]interface{}{"prompt": ">"}}
+	transformed, err := chain.TransformRequest(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, transformed)
+	assert.Equal(t, ">ab", req.Body["prompt"])
+
+	req = &Request{Model: "mistral", Body: map[string]interface{}{"prompt": ">"}}
+	_, err = chain.TransformRequest(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, ">a", req.Body["prompt"])
+
+	resp := &Response{Model: "llama", Body: []byte(">")}
+	assert.NoError(t, chain.TransformResponse(context.Background(), resp))
+	assert.Equal(t, ">ab", string(resp.Body))
+
+	// a nil chain runs nothing.
+	var none *Chain
+	transformed, err = none.TransformRequest(context.Background(), req)
+	assert.NoError(t, err)
+	assert.False(t, transformed)
+	assert.False(t, none.TransformsResponses("llama"))
+}
+
+func TestChainFailures(t *testing.T) {
+	chain, err := Build([]config.MiddlewareConfig{
+		{Name: "failing", Type: "append", Config: json.RawMessage(`{"fail": true}`)},
+		{Name: "after", Type: "append", Config: json.RawMessage(`{"text": "b"}`)},
+	})
+	assert.NoError(t, err)
+
+	// a failing request middleware rejects the request.
+	errorsBefore := testutil.ToFloat64(middlewareErrors.WithLabelValues("failing", phaseRequest))
+	req := &Request{Model: "llama", Body: map[string]interface{}{"prompt": ">"}}
+	_, err = chain.TransformRequest(context.Background(), req)
+	var middlewareErr *Error
+	assert.ErrorAs(t, err, &middlewareErr)
+	assert.Equal(t, "failing", middlewareErr.Middleware)
+	assert.Equal(t, ">", req.Body["prompt"])
+	assert.Equal(t, errorsBefore+1, testutil.ToFloat64(middlewareErrors.WithLabelValues("failing", phaseRequest)))
+
+	// a failing response middleware leaves the body untransformed, the next ones still run.
+	resp := &Response{Model: "llama", Body: []byte(">")}
+	assert.Error(t, chain.TransformResponse(context.Background(), resp))
+	assert.Equal(t, ">b", string(resp.Body))
+	// the latency of every middleware is observed, failing or not.
+	assert.Greater(t, testutil.CollectAndCount(middlewareDuration), 2)
+}
+
+func TestBuildErrors(t *testing.T) {
+	for name, configs := range map[string][]config.MiddlewareConfig{
+		"unknown type":        {{Name: "a", Type: "unknown"}},
+		"invalid config":      {{Name: "a", Type: TypeWatermark, Config: json.RawMessage(`{"txt": "x"}`)}},
+		"no transformation":   {{Name: "a", Type: "noop"}},
+		"missing plugin file": {{Name: "a", Type: TypePlugin, Plugin: "/nonexistent.so"}},
+		"unknown pii type":    {{Name: "a", Type: TypePIIRedaction, Config: json.RawMessage(`{"types": ["name"]}`)}},
+	} {
+		_, err := Build(configs)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestBuildRejectsPluginsWithoutCgo(t *testing.T) {
+	if PluginsSupported {
+		t.Skip("the gateway is built with cgo")
+	}
+	_, err := Build([]config.MiddlewareConfig{
+		{Name: "policy", Type: TypeSystemPrompt, Config: json.RawMessage(`{"content": "Be brief."}`)},
+		{Name: "custom", Type: TypePlugin, Plugin: "/plugins/custom.so"},
+	})
+	assert.ErrorIs(t, err, errPluginsUnsupported)
+}
+
+func TestSystemPrompt(t *testing.T) {
+	m, err := newSystemPrompt(json.RawMessage(`{"content": "Be brief."}`))
+	assert.NoError(t, err)
+
+	chat := &Request{Endpoint: endpointChatCompletions, Body: map[string]interface{}{
+		"messages": []interface{}{map[string]interface{}{"role": "user", "content": "hi"}},
+	}}
+	assert.NoError(t, m.(RequestTransformer).TransformRequest(context.Background(), chat))
+	messages := chat.Body["messages"].([]interface{})
+	assert.Len(t, messages, 2)
+	assert.Equal(t, map[string]interface{}{"role": "system", "content": "Be brief."}, messages[0])
+
+	completion := &Request{Endpoint: endpointCompletions, Body: map[string]interface{}{"prompt": "hi"}}
+	assert.NoError(t, m.(RequestTransformer).TransformRequest(context.Background(), completion))
+	assert.Equal(t, "Be brief.\n\nhi", completion.Body["prompt"])
+
+	_, err = newSystemPrompt(json.RawMessage(`{}`))
+	assert.Error(t, err)
+}
+
+func TestPIIRedaction(t *testing.T) {
+	m, err := newPIIRedaction(json.RawMessage(`{"responses": true}`))
+	assert.NoError(t, err)
+	redaction := m.(*piiRedaction)
+
+	for text, expected := range map[string]string{
+		"mail jane.doe@example.com now":        "mail [REDACTED] now",
+		"card 4111 1111 1111 1111 expires":     "card [REDACTED] expires",
+		"order 4111 1111 1111 1112 shipped":    "order 4111 1111 1111 1112 shipped",
+		"ssn 123-45-6789":                      "ssn [REDACTED]",
+		"call +1 415-555-0132 or 415.555.0199": "call [REDACTED] or [REDACTED]",
+		"host 10.0.0.12 is down":               "host [REDACTED] is down",
+		"version 1.2.3 is fine":                "version 1.2.3 is fine",
+	} {
+		assert.Equal(t, expected, redaction.redact(text), text)
+	}
+
+	req := &Request{Endpoint: endpointChatCompletions, Body: map[string]interface{}{
+		"model":    "jane@example.com",
+		"messages": []interface{}{map[string]interface{}{"role": "user", "content": "I am jane@example.com"}},
+	}}
+	assert.NoError(t, redaction.TransformRequest(context.Background(), req))
+	assert.Equal(t, "I am [REDACTED]", req.Body["messages"].([]interface{})[0].(map[string]interface{})["content"])
+	assert.Equal(t, "jane@example.com", req.Body["model"])
+
+	resp := &Response{Body: []byte(`{"choices":[{"text":"write to jane@example.com"}]}`)}
+	assert.NoError(t, redaction.TransformResponse(context.Background(), resp))
+	assert.Equal(t, `{"choices":[{"text":"write to [REDACTED]"}]}`, string(resp.Body))
+
+	// only the configured types are redacted.
+	m, err = newPIIRedaction(json.RawMessage(`{"types": ["ssn"], "replacement": "***"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.com ***", m.(*piiRedaction).redact("jane@example.com 123-45-6789"))
+}
+
+func TestWatermark(t *testing.T) {
+	m, err := newWatermark(json.RawMessage(`{"text": " [ai]"}`))
+	assert.NoError(t, err)
+	watermark := m.(ResponseTransformer)
+
+	chat := &Response{Endpoint: endpointChatCompletions, Body: []byte(`{"choices":[{"message":{"role":"assistant","content":"hi"}}]}`)}
+	assert.NoError(t, watermark.TransformResponse(context.Background(), chat))
+	assert.JSONEq(t, `{"choices":[{"message":{"role":"assistant","content":"hi [ai]"}}]}`, string(chat.Body))
+
+	completion := &Response{Endpoint: endpointCompletions, Body: []byte(`{"choices":[{"text":"hi"}]}`)}
+	assert.NoError(t, watermark.TransformResponse(context.Background(), completion))
+	assert.JSONEq(t, `{"choices":[{"text":"hi [ai]"}]}`, string(completion.Body))
+
+	// streamed responses get a last event before the end of the stream.
+	chunk := "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n"
+	stream := &Response{Endpoint: endpointChatCompletions, Stream: true, Model: "llama", Body: []byte(chunk)}
+	assert.NoError(t, watermark.TransformResponse(context.Background(), stream))
+	assert.Equal(t, chunk, string(stream.Body))
+	stream.Body = []byte(chunk + "data: [DONE]\n\n")
+	assert.NoError(t, watermark.TransformResponse(context.Background(), stream))
+	events := strings.Split(strings.TrimSpace(string(stream.Body)), "\n\n")
+	assert.Len(t, events, 3)
+	assert.JSONEq(t, `{"object":"chat.completion.chunk","model":"llama","choices":[{"index":0,"delta":{"content":" [ai]"}}]}`,
+		strings.TrimPrefix(events[1], "data: "))
+	assert.Equal(t, "data: [DONE]", events[2])
+
+	// embeddings are not watermarked.
+	embedding := &Response{Endpoint: "embeddings", Body: []byte(`{"data":[]}`)}
+	assert.NoError(t, watermark.TransformResponse(context.Background(), embedding))
+	assert.Equal(t, `{"data":[]}`, string(embedding.Body))
+}
+
+func TestLuhn(t *testing.T) {
+	assert.True(t, luhnValid("4111-1111-1111-1111"))
+	assert.True(t, luhnValid("5500 0000 0000 0004"))
+	assert.False(t, luhnValid("4111 1111 1111 1112"))
+	assert.False(t, luhnValid("0000 0000 0"))
+}
@@ -0,0 +1,56 @@
+//go:build cgo && (linux || darwin || freebsd)
+
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"plugin"
+)
+
+// PluginSymbol is the factory a Go plugin exports, a function with the signature of Factory:
+//
+//	func NewMiddleware(config json.RawMessage) (interface{}, error)
+//
+// Plugins are built with go build -buildmode=plugin against the same gateway sources and Go version.
+const PluginSymbol = "NewMiddleware"
+
+// PluginsSupported reports whether the gateway can load Go plugins, which needs a gateway built with cgo.
+const PluginsSupported = true
+
+// loadPlugin creates a middleware from the Go plugin at path. A plugin is loaded once, later calls create new
+// middlewares from the same factory.
+func loadPlugin(path string, config json.RawMessage) (interface{}, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no plugin path")
+	}
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, err
+	}
+	factory, ok := symbol.(func(json.RawMessage) (interface{}, error))
+	if !ok {
+		return nil, fmt.Errorf("%s of plugin %s is a %T, not a middleware factory", PluginSymbol, path, symbol)
+	}
+	return factory(config)
+}
@@ -0,0 +1,28 @@
+//go:build !cgo || !(linux || darwin || freebsd)
+
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package middleware
+
+import "encoding/json"
+
+// PluginsSupported reports whether the gateway can load Go plugins, which needs a gateway built with cgo.
+const PluginsSupported = false
+
+func loadPlugin(path string, config json.RawMessage) (interface{}, error) {
+	return nil, errPluginsUnsupported
+}
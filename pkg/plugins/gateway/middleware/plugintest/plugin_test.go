@@ -0,0 +1,58 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugintest loads a middleware Go plugin. It is apart from the middleware package, whose tests would change
+// the package the plugin is checked against.
+package plugintest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/middleware"
+)
+
+func TestLoadPlugin(t *testing.T) {
+	if !middleware.PluginsSupported {
+		t.Skip("go plugins need a gateway built with cgo")
+	}
+	if testing.Short() {
+		t.Skip("building the plugin is slow")
+	}
+	path := filepath.Join(t.TempDir(), "tagger.so")
+	build := exec.Command("go", "build", "-buildmode=plugin", "-o", path, "./testdata/tagger")
+	build.Env = append(os.Environ(), "CGO_ENABLED=1")
+	output, err := build.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	chain, err := middleware.Build([]config.MiddlewareConfig{
+		{Name: "tagger", Type: middleware.TypePlugin, Plugin: path, Config: json.RawMessage(`{"tag": "team-a"}`)},
+	})
+	require.NoError(t, err)
+	req := &middleware.Request{Model: "llama", Body: map[string]interface{}{"prompt": "hi"}}
+	transformed, err := chain.TransformRequest(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, transformed)
+	assert.Equal(t, "team-a", req.Body["user"])
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// tagger is the middleware plugin of the plugin load test, it sets the user of the requests to the configured tag.
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/middleware"
+)
+
+type tagger struct {
+	Tag string `json:"tag"`
+}
+
+func (t *tagger) TransformRequest(ctx context.Context, req *middleware.Request) error {
+	req.Body["user"] = t.Tag
+	return nil
+}
+
+func NewMiddleware(config json.RawMessage) (interface{}, error) {
+	t := &tagger{}
+	if err := json.Unmarshal(config, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+const (
+	finishReasonStop      = "stop"
+	finishReasonToolCalls = "tool_calls"
+)
+
+// toolCallAdapter converts the tool calls of an engine to the OpenAI format.
+type toolCallAdapter struct {
+	// textToolCalls parses the tool calls left in the message content by engines serving without a tool call parser,
+	// in the hermes <tool_call> tags, after the mistral [TOOL_CALLS] token or the llama <|python_tag|> token.
+	textToolCalls bool
+	// finishReasons maps the finish reasons of the engine to the OpenAI ones.
+	finishReasons map[string]string
+}
+
+var (
+	toolCallAdapters = map[string]toolCallAdapter{
+		"vllm":   {textToolCalls: true},
+		"sglang": {textToolCalls: true},
+		"tgi":    {finishReasons: map[string]string{"eos_token": finishReasonStop, "stop_sequence": finishReasonStop}},
+	}
+	// defaultToolCallAdapter applies to other engines and to responses whose engine is unknown, e.g. served from cache.
+	defaultToolCallAdapter = toolCallAdapter{
+		textToolCalls: true,
+		finishReasons: map[string]string{"eos_token": finishReasonStop, "stop_sequence": finishReasonStop},
+	}
+
+	hermesToolCallPattern = regexp.MustCompile(`(?s)<tool_call>\s*(.*?)\s*</tool_call>`)
+)
+
+const (
+	mistralToolCallsToken = "[TOOL_CALLS]"
+	llamaPythonTagToken   = "<|python_tag|>"
+)
+
+// toolCallNormalization rewrites the tool calls of chat completions to the OpenAI format whatever the engine: calls of
+// type function with a unique id and JSON string arguments, no content and a tool_calls finish reason.
+type toolCallNormalization struct{}
+
+func newToolCallNormalization(config json.RawMessage) (interface{}, error) {
+	m := &toolCallNormalization{}
+	if err := decodeConfig(config, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *toolCallNormalization) TransformResponse(_ context.Context, resp *Response) error {
+	if resp.Endpoint != endpointChatCompletions {
+		return nil
+	}
+	adapter, ok := toolCallAdapters[resp.Engine]
+	if !ok {
+		adapter = defaultToolCallAdapter
+	}
+
+	if resp.Stream {
+		resp.Body = normalizeToolCallEvents(resp.Body, adapter)
+		return nil
+	}
+	var completion map[string]interface{}
+	if err := json.Unmarshal(resp.Body, &completion); err != nil {
+		return err
+	}
+	changed := false
+	choices, _ := completion["choices"].([]interface{})
+	for _, c := range choices {
+		if choice, ok := c.(map[string]interface{}); ok && normalizeChoiceToolCalls(choice, "message", adapter) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	body, err := json.Marshal(completion)
+	if err != nil {
+		return err
+	}
+	resp.Body = body
+	return nil
+}
+
+// normalizeToolCallEvents normalizes the chunks of the server-sent events. The tool calls left in the content of
+// streamed messages are not parsed, and the finish reason is only set for chunks carrying tool calls.
+func normalizeToolCallEvents(body []byte, adapter toolCallAdapter) []byte {
+	lines := bytes.Split(body, []byte("\n"))
+	changed := false
+	for i, line := range lines {
+		data, ok := bytes.CutPrefix(line, []byte("data: "))
+		if !ok || bytes.Equal(data, []byte("[DONE]")) {
+			continue
+		}
+		var chunk map[string]interface{}
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			// the event is split across chunks of the response.
+			continue
+		}
+		chunkChanged := false
+		choices, _ := chunk["choices"].([]interface{})
+		for _, c := range choices {
+			if choice, ok := c.(map[string]interface{}); ok && normalizeChoiceToolCalls(choice, "delta", adapter) {
+				chunkChanged = true
+			}
+		}
+		if !chunkChanged {
+			continue
+		}
+		if data, err := json.Marshal(chunk); err == nil {
+			lines[i] = append([]byte("data: "), data...)
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// normalizeChoiceToolCalls normalizes the message, or the delta of streamed chunks, of the choice in place. It
+// reports whether the choice changed.
+func normalizeChoiceToolCalls(choice map[string]interface{}, field string, adapter toolCallAdapter) bool {
+	changed := false
+	if reason, ok := choice["finish_reason"].(string); ok {
+		if mapped, ok := adapter.finishReasons[reason]; ok {
+			choice["finish_reason"] = mapped
+			changed = true
+		}
+	}
+	message, ok := choice[field].(map[string]interface{})
+	if !ok {
+		return changed
+	}
+
+	calls, hasCalls := message["tool_calls"].([]interface{})
+	if hasCalls && len(calls) == 0 {
+		// engines return an empty list for messages without tool calls, the OpenAI API omits it.
+		delete(message, "tool_calls")
+		return true
+	}
+	if !hasCalls && adapter.textToolCalls && field == "message" {
+		text, _ := message["content"].(string)
+		if parsed, content, ok := parseTextToolCalls(text); ok {
+			message["tool_calls"] = parsed
+			message["content"] = content
+			calls, hasCalls, changed = parsed, true, true
+		}
+	}
+	if !hasCalls {
+		return changed
+	}
+
+	ids := map[string]bool{}
+	for i, c := range calls {
+		call, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		normalized := normalizeToolCall(call, i, field == "delta", ids)
+		if !toolCallEqual(call, normalized) {
+			calls[i] = normalized
+			changed = true
+		}
+	}
+	if field == "message" {
+		if content, ok := message["content"].(string); ok && strings.TrimSpace(content) == "" {
+			message["content"] = nil
+			changed = true
+		}
+		if reason, _ := choice["finish_reason"].(string); reason == "" || reason == finishReasonStop {
+			choice["finish_reason"] = finishReasonToolCalls
+			changed = true
+		}
+	} else if reason, _ := choice["finish_reason"].(string); reason == finishReasonStop {
+		choice["finish_reason"] = finishReasonToolCalls
+		changed = true
+	}
+	return changed
+}
+
+// normalizeToolCall returns the call in the OpenAI format. Deltas of streamed calls keep their index, and only the
+// first delta of a call, with its name, gets an id and a type.
+func normalizeToolCall(call map[string]interface{}, position int, delta bool, ids map[string]bool) map[string]interface{} {
+	function, _ := call["function"].(map[string]interface{})
+	if function == nil {
+		// some engines put the name and the arguments at the top level of the call.
+		function = call
+	}
+	normalized := map[string]interface{}{}
+	normalizedFunction := map[string]interface{}{}
+	if name, ok := function["name"]; ok {
+		normalizedFunction["name"] = name
+	}
+	arguments, ok := function["arguments"]
+	if !ok {
+		// llama models name the arguments parameters.
+		arguments = function["parameters"]
+	}
+	switch args := arguments.(type) {
+	case string:
+		normalizedFunction["arguments"] = args
+	case nil:
+		if !delta {
+			normalizedFunction["arguments"] = "{}"
+		}
+	default:
+		if data, err := json.Marshal(args); err == nil {
+			normalizedFunction["arguments"] = string(data)
+		}
+	}
+	normalized["function"] = normalizedFunction
+
+	if delta {
+		normalized["index"] = position
+		if index, ok := call["index"]; ok {
+			normalized["index"] = index
+		}
+		if _, ok := normalizedFunction["name"]; !ok {
+			return normalized
+		}
+	}
+	id, _ := call["id"].(string)
+	if id == "" || ids[id] {
+		id = newToolCallID()
+	}
+	ids[id] = true
+	normalized["id"] = id
+	normalized["type"] = "function"
+	return normalized
+}
+
+// parseTextToolCalls parses the tool calls of the content, it returns the calls and the rest of the content, nil if
+// empty, or false if the content has no tool call.
+func parseTextToolCalls(content string) ([]interface{}, interface{}, bool) {
+	trimmed := strings.TrimSpace(content)
+	var calls []interface{}
+	var rest string
+	switch {
+	case strings.HasPrefix(trimmed, mistralToolCallsToken):
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(trimmed, mistralToolCallsToken)), &calls); err != nil {
+			return nil, nil, false
+		}
+	case strings.HasPrefix(trimmed, llamaPythonTagToken):
+		for _, text := range strings.Split(strings.TrimPrefix(trimmed, llamaPythonTagToken), ";") {
+			var call interface{}
+			if err := json.Unmarshal([]byte(text), &call); err != nil {
+				return nil, nil, false
+			}
+			calls = append(calls, call)
+		}
+	default:
+		matches := hermesToolCallPattern.FindAllStringSubmatch(trimmed, -1)
+		if len(matches) == 0 {
+			return nil, nil, false
+		}
+		for _, match := range matches {
+			var call interface{}
+			if err := json.Unmarshal([]byte(match[1]), &call); err != nil {
+				return nil, nil, false
+			}
+			calls = append(calls, call)
+		}
+		rest = strings.TrimSpace(hermesToolCallPattern.ReplaceAllString(trimmed, ""))
+	}
+	for _, call := range calls {
+		if call, ok := call.(map[string]interface{}); !ok || call["name"] == nil {
+			return nil, nil, false
+		}
+	}
+	if rest == "" {
+		return calls, nil, true
+	}
+	return calls, rest, true
+}
+
+func toolCallEqual(call, normalized map[string]interface{}) bool {
+	a, errA := json.Marshal(call)
+	b, errB := json.Marshal(normalized)
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
+
+// newToolCallID returns a random id in the format of the OpenAI API.
+func newToolCallID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "call_" + hex.EncodeToString(b)
+}
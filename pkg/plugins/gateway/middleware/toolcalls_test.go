@@ -0,0 +1,178 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// generatedIDs matches the ids generated for tool calls, replaced by call_generated in the expected outputs.
+var generatedIDs = regexp.MustCompile(`call_[0-9a-f]{24}`)
+
+func TestToolCallNormalizationConformance(t *testing.T) {
+	testCases := []struct {
+		name     string
+		engine   string
+		body     string
+		expected string
+	}{
+		{
+			name:     "openai tool calls are kept",
+			engine:   "vllm",
+			body:     `{"choices":[{"finish_reason":"tool_calls","index":0,"message":{"content":null,"role":"assistant","tool_calls":[{"function":{"arguments":"{\"city\":\"Paris\"}","name":"get_weather"},"id":"call_1","type":"function"}]}}]}`,
+			expected: `{"choices":[{"finish_reason":"tool_calls","index":0,"message":{"content":null,"role":"assistant","tool_calls":[{"function":{"arguments":"{\"city\":\"Paris\"}","name":"get_weather"},"id":"call_1","type":"function"}]}}]}`,
+		},
+		{
+			name:     "vllm empty tool calls are removed",
+			engine:   "vllm",
+			body:     `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"Hello","role":"assistant","tool_calls":[]}}]}`,
+			expected: `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"Hello","role":"assistant"}}]}`,
+		},
+		{
+			name:     "hermes tool calls in the content",
+			engine:   "vllm",
+			body:     `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"<tool_call>\n{\"name\": \"get_weather\", \"arguments\": {\"city\": \"Paris\"}}\n</tool_call>\n<tool_call>{\"name\": \"get_time\", \"arguments\": {}}</tool_call>","role":"assistant"}}]}`,
+			expected: `{"choices":[{"finish_reason":"tool_calls","index":0,"message":{"content":null,"role":"assistant","tool_calls":[{"function":{"arguments":"{\"city\":\"Paris\"}","name":"get_weather"},"id":"call_generated","type":"function"},{"function":{"arguments":"{}","name":"get_time"},"id":"call_generated","type":"function"}]}}]}`,
+		},
+		{
+			name:     "hermes tool calls keep the text around them",
+			engine:   "sglang",
+			body:     `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"Let me check. <tool_call>{\"name\": \"get_weather\", \"arguments\": {\"city\": \"Paris\"}}</tool_call>","role":"assistant"}}]}`,
+			expected: `{"choices":[{"finish_reason":"tool_calls","index":0,"message":{"content":"Let me check.","role":"assistant","tool_calls":[{"function":{"arguments":"{\"city\":\"Paris\"}","name":"get_weather"},"id":"call_generated","type":"function"}]}}]}`,
+		},
+		{
+			name:     "mistral tool calls in the content",
+			engine:   "vllm",
+			body:     `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"[TOOL_CALLS][{\"name\": \"get_weather\", \"arguments\": {\"city\": \"Paris\"}}]","role":"assistant"}}]}`,
+			expected: `{"choices":[{"finish_reason":"tool_calls","index":0,"message":{"content":null,"role":"assistant","tool_calls":[{"function":{"arguments":"{\"city\":\"Paris\"}","name":"get_weather"},"id":"call_generated","type":"function"}]}}]}`,
+		},
+		{
+			name:     "llama tool calls in the content",
+			engine:   "vllm",
+			body:     `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"<|python_tag|>{\"name\": \"get_weather\", \"parameters\": {\"city\": \"Paris\"}}; {\"name\": \"get_time\", \"parameters\": {}}","role":"assistant"}}]}`,
+			expected: `{"choices":[{"finish_reason":"tool_calls","index":0,"message":{"content":null,"role":"assistant","tool_calls":[{"function":{"arguments":"{\"city\":\"Paris\"}","name":"get_weather"},"id":"call_generated","type":"function"},{"function":{"arguments":"{}","name":"get_time"},"id":"call_generated","type":"function"}]}}]}`,
+		},
+		{
+			name:     "malformed tool calls in the content are kept as content",
+			engine:   "vllm",
+			body:     `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"<tool_call>{\"name\": \"get_weather\", </tool_call>","role":"assistant"}}]}`,
+			expected: `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"<tool_call>{\"name\": \"get_weather\", </tool_call>","role":"assistant"}}]}`,
+		},
+		{
+			name:     "tgi object arguments, extra fields and finish reason",
+			engine:   "tgi",
+			body:     `{"choices":[{"finish_reason":"eos_token","index":0,"message":{"content":"","role":"assistant","tool_calls":[{"function":{"arguments":{"city":"Paris"},"description":null,"name":"get_weather"},"id":"0","type":"function"}]}}]}`,
+			expected: `{"choices":[{"finish_reason":"tool_calls","index":0,"message":{"content":null,"role":"assistant","tool_calls":[{"function":{"arguments":"{\"city\":\"Paris\"}","name":"get_weather"},"id":"0","type":"function"}]}}]}`,
+		},
+		{
+			name:     "tgi finish reasons without tool calls",
+			engine:   "tgi",
+			body:     `{"choices":[{"finish_reason":"eos_token","index":0,"message":{"content":"Hello","role":"assistant"}}]}`,
+			expected: `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"Hello","role":"assistant"}}]}`,
+		},
+		{
+			name:     "tgi does not parse the content",
+			engine:   "tgi",
+			body:     `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"<tool_call>{\"name\": \"get_weather\", \"arguments\": {}}</tool_call>","role":"assistant"}}]}`,
+			expected: `{"choices":[{"finish_reason":"stop","index":0,"message":{"content":"<tool_call>{\"name\": \"get_weather\", \"arguments\": {}}</tool_call>","role":"assistant"}}]}`,
+		},
+		{
+			name:     "missing and duplicate ids, missing type and arguments",
+			engine:   "",
+			body:     `{"choices":[{"finish_reason":"tool_calls","index":0,"message":{"role":"assistant","tool_calls":[{"function":{"name":"get_time"},"id":"call_1"},{"function":{"arguments":"{}","name":"get_date"},"id":"call_1","type":"function"},{"function":{"arguments":"{}","name":"get_day"}}]}}]}`,
+			expected: `{"choices":[{"finish_reason":"tool_calls","index":0,"message":{"role":"assistant","tool_calls":[{"function":{"arguments":"{}","name":"get_time"},"id":"call_1","type":"function"},{"function":{"arguments":"{}","name":"get_date"},"id":"call_generated","type":"function"},{"function":{"arguments":"{}","name":"get_day"},"id":"call_generated","type":"function"}]}}]}`,
+		},
+		{
+			name:     "flat tool calls",
+			engine:   "unknown",
+			body:     `{"choices":[{"finish_reason":"length","index":0,"message":{"content":null,"role":"assistant","tool_calls":[{"arguments":{"city":"Paris"},"name":"get_weather"}]}}]}`,
+			expected: `{"choices":[{"finish_reason":"length","index":0,"message":{"content":null,"role":"assistant","tool_calls":[{"function":{"arguments":"{\"city\":\"Paris\"}","name":"get_weather"},"id":"call_generated","type":"function"}]}}]}`,
+		},
+	}
+
+	m, err := newToolCallNormalization(nil)
+	assert.NoError(t, err)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &Response{Model: "m", Endpoint: endpointChatCompletions, Engine: tc.engine, Body: []byte(tc.body)}
+			assert.NoError(t, m.(ResponseTransformer).TransformResponse(context.Background(), resp))
+			assert.JSONEq(t, tc.expected, generatedIDs.ReplaceAllString(string(resp.Body), "call_generated"))
+		})
+	}
+}
+
+func TestToolCallNormalizationGeneratesUniqueIDs(t *testing.T) {
+	m, err := newToolCallNormalization(nil)
+	assert.NoError(t, err)
+	resp := &Response{
+		Endpoint: endpointChatCompletions,
+		Body:     []byte(`{"choices":[{"message":{"tool_calls":[{"function":{"name":"a","arguments":"{}"}},{"function":{"name":"b","arguments":"{}"}}]}}]}`),
+	}
+	assert.NoError(t, m.(ResponseTransformer).TransformResponse(context.Background(), resp))
+	ids := generatedIDs.FindAllString(string(resp.Body), -1)
+	assert.Len(t, ids, 2)
+	assert.NotEqual(t, ids[0], ids[1])
+}
+
+func TestToolCallNormalizationStream(t *testing.T) {
+	m, err := newToolCallNormalization(nil)
+	assert.NoError(t, err)
+
+	events := []string{
+		`data: {"choices":[{"delta":{"role":"assistant","tool_calls":[{"function":{"arguments":"","name":"get_weather"},"id":"call_1"}]},"index":0}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"function":{"arguments":{"city":"Paris"}},"index":0}]},"index":0}]}`,
+		`data: {"choices":[{"delta":{},"finish_reason":"eos_token","index":0}]}`,
+		`data: {"choices":[{"delta":{"content":"Hel`,
+		`data: [DONE]`,
+	}
+	resp := &Response{Endpoint: endpointChatCompletions, Engine: "tgi", Stream: true, Body: []byte(strings.Join(events, "\n\n"))}
+	assert.NoError(t, m.(ResponseTransformer).TransformResponse(context.Background(), resp))
+
+	lines := strings.Split(string(resp.Body), "\n\n")
+	assert.Len(t, lines, len(events))
+	expected := []string{
+		`{"choices":[{"delta":{"role":"assistant","tool_calls":[{"function":{"arguments":"","name":"get_weather"},"id":"call_1","index":0,"type":"function"}]},"index":0}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"function":{"arguments":"{\"city\":\"Paris\"}"},"index":0}]},"index":0}]}`,
+		`{"choices":[{"delta":{},"finish_reason":"stop","index":0}]}`,
+	}
+	for i, event := range expected {
+		data, ok := strings.CutPrefix(lines[i], "data: ")
+		assert.True(t, ok)
+		assert.JSONEq(t, event, data)
+	}
+	// partial and done events are passed as is.
+	assert.Equal(t, events[3], lines[3])
+	assert.Equal(t, events[4], lines[4])
+}
+
+func TestToolCallNormalizationSkipsOtherEndpoints(t *testing.T) {
+	m, err := newToolCallNormalization(nil)
+	assert.NoError(t, err)
+	body := `{"choices":[{"finish_reason":"eos_token","text":"<tool_call>{\"name\": \"a\"}</tool_call>"}]}`
+	resp := &Response{Endpoint: endpointCompletions, Body: []byte(body)}
+	assert.NoError(t, m.(ResponseTransformer).TransformResponse(context.Background(), resp))
+	assert.Equal(t, body, string(resp.Body))
+
+	_, err = newToolCallNormalization(json.RawMessage(`{"engine":"vllm"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	aibrixcache "github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/responsecache"
+)
+
+const (
+	// annotations on model Deployments overriding the gateway defaults for the model.
+	modelRoutingStrategyAnnotationKey   = "model.aibrix.ai/routing-strategy"
+	modelRequestTimeoutAnnotationKey    = "model.aibrix.ai/request-timeout"
+	modelMaxQueuedRequestsAnnotationKey = "model.aibrix.ai/max-queued-requests"
+	// the response cache of the model: exact or semantic, how long responses are served and the prompt similarity of
+	// semantic matches.
+	modelResponseCacheAnnotationKey           = "model.aibrix.ai/response-cache"
+	modelResponseCacheTTLAnnotationKey        = "model.aibrix.ai/response-cache-ttl"
+	modelResponseCacheSimilarityAnnotationKey = "model.aibrix.ai/response-cache-similarity"
+	// the adaptive concurrency limit of the model and the latency to its first response chunk it keeps requests within.
+	modelAdaptiveConcurrencyAnnotationKey = "model.aibrix.ai/adaptive-concurrency"
+	modelLatencySLOAnnotationKey          = "model.aibrix.ai/latency-slo"
+	// whether the outputs of the model are checked against the response format or guided decoding of the requests.
+	modelValidateStructuredOutputAnnotationKey = "model.aibrix.ai/validate-structured-output"
+)
+
+// ModelConfig is the per model configuration of the gateway.
+type ModelConfig struct {
+	// RoutingStrategy is used when the request has no routing-strategy header, it takes precedence over ROUTING_ALGORITHM.
+	RoutingStrategy string
+	// RequestTimeout bounds the time envoy waits for the engine to respond, 0 keeps the route timeout.
+	RequestTimeout time.Duration
+	// MaxQueuedRequests rejects requests with 429 once every ready pod has that many waiting requests,
+	// instead of queueing them on the engines. 0 disables the limit.
+	MaxQueuedRequests int
+	// ResponseCache answers repeated prompts from earlier responses, disabled when its mode is empty.
+	ResponseCache responsecache.Options
+	// AdaptiveConcurrency rejects requests with 429 beyond a concurrency limit probed from the observed latencies.
+	AdaptiveConcurrency bool
+	// LatencySLO backs the adaptive concurrency limit off while the first response chunks take longer. 0 only
+	// follows the latency gradient.
+	LatencySLO time.Duration
+	// ValidateStructuredOutput checks the outputs of requests constraining them, e.g. with a JSON schema response
+	// format, and counts the violations.
+	ValidateStructuredOutput bool
+}
+
+func (c ModelConfig) isEmpty() bool {
+	return c == ModelConfig{}
+}
+
+// parseModelConfig reads the model configuration from the annotations, invalid values are rejected as a whole.
+func parseModelConfig(annotations map[string]string) (ModelConfig, error) {
+	var config ModelConfig
+	if value, ok := annotations[modelRoutingStrategyAnnotationKey]; ok {
+		if !validateRoutingStrategy(value) {
+			return ModelConfig{}, fmt.Errorf("invalid %s: %s", modelRoutingStrategyAnnotationKey, value)
+		}
+		config.RoutingStrategy = value
+	}
+	if value, ok := annotations[modelRequestTimeoutAnnotationKey]; ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			return ModelConfig{}, fmt.Errorf("invalid %s: %s", modelRequestTimeoutAnnotationKey, value)
+		}
+		config.RequestTimeout = timeout
+	}
+	if value, ok := annotations[modelMaxQueuedRequestsAnnotationKey]; ok {
+		maxQueued, err := strconv.Atoi(value)
+		if err != nil || maxQueued < 0 {
+			return ModelConfig{}, fmt.Errorf("invalid %s: %s", modelMaxQueuedRequestsAnnotationKey, value)
+		}
+		config.MaxQueuedRequests = maxQueued
+	}
+	if value, ok := annotations[modelResponseCacheAnnotationKey]; ok {
+		config.ResponseCache.Mode = value
+	}
+	if value, ok := annotations[modelResponseCacheTTLAnnotationKey]; ok {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return ModelConfig{}, fmt.Errorf("invalid %s: %s", modelResponseCacheTTLAnnotationKey, value)
+		}
+		config.ResponseCache.TTL = ttl
+	}
+	if value, ok := annotations[modelResponseCacheSimilarityAnnotationKey]; ok {
+		similarity, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return ModelConfig{}, fmt.Errorf("invalid %s: %s", modelResponseCacheSimilarityAnnotationKey, value)
+		}
+		config.ResponseCache.MinSimilarity = similarity
+	}
+	if value, ok := annotations[modelAdaptiveConcurrencyAnnotationKey]; ok {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return ModelConfig{}, fmt.Errorf("invalid %s: %s", modelAdaptiveConcurrencyAnnotationKey, value)
+		}
+		config.AdaptiveConcurrency = enabled
+	}
+	if value, ok := annotations[modelLatencySLOAnnotationKey]; ok {
+		slo, err := time.ParseDuration(value)
+		if err != nil || slo < 0 {
+			return ModelConfig{}, fmt.Errorf("invalid %s: %s", modelLatencySLOAnnotationKey, value)
+		}
+		config.LatencySLO = slo
+	}
+	if value, ok := annotations[modelValidateStructuredOutputAnnotationKey]; ok {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return ModelConfig{}, fmt.Errorf("invalid %s: %s", modelValidateStructuredOutputAnnotationKey, value)
+		}
+		config.ValidateStructuredOutput = enabled
+	}
+	if err := config.ResponseCache.Validate(); err != nil {
+		return ModelConfig{}, err
+	}
+	return config, nil
+}
+
+type modelConfigSource struct {
+	model  string
+	config ModelConfig
+}
+
+// modelConfigStore holds the model configurations read from the annotations of model Deployments. A model served by
+// several Deployments, e.g. on heterogeneous GPUs, uses the first value set for each field in namespace/name order.
+type modelConfigStore struct {
+	mu      sync.RWMutex
+	sources map[string]modelConfigSource // deployment namespace/name: source
+	configs map[string]ModelConfig       // model_name: config
+}
+
+func newModelConfigStore() *modelConfigStore {
+	return &modelConfigStore{
+		sources: map[string]modelConfigSource{},
+		configs: map[string]ModelConfig{},
+	}
+}
+
+// Get returns the configuration of the model, false if the model has none.
+func (s *modelConfigStore) Get(model string) (ModelConfig, bool) {
+	if s == nil {
+		return ModelConfig{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	config, ok := s.configs[model]
+	return config, ok
+}
+
+func (s *modelConfigStore) update(key string, source *modelConfigSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if source == nil || source.config.isEmpty() {
+		delete(s.sources, key)
+	} else {
+		s.sources[key] = *source
+	}
+
+	keys := make([]string, 0, len(s.sources))
+	for key := range s.sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	configs := map[string]ModelConfig{}
+	for _, key := range keys {
+		source := s.sources[key]
+		config := configs[source.model]
+		if config.RoutingStrategy == "" {
+			config.RoutingStrategy = source.config.RoutingStrategy
+		}
+		if config.RequestTimeout == 0 {
+			config.RequestTimeout = source.config.RequestTimeout
+		}
+		if config.MaxQueuedRequests == 0 {
+			config.MaxQueuedRequests = source.config.MaxQueuedRequests
+		}
+		if config.ResponseCache.Mode == "" {
+			config.ResponseCache.Mode = source.config.ResponseCache.Mode
+		}
+		if config.ResponseCache.TTL == 0 {
+			config.ResponseCache.TTL = source.config.ResponseCache.TTL
+		}
+		if config.ResponseCache.MinSimilarity == 0 {
+			config.ResponseCache.MinSimilarity = source.config.ResponseCache.MinSimilarity
+		}
+		if !config.AdaptiveConcurrency {
+			config.AdaptiveConcurrency = source.config.AdaptiveConcurrency
+		}
+		if config.LatencySLO == 0 {
+			config.LatencySLO = source.config.LatencySLO
+		}
+		if !config.ValidateStructuredOutput {
+			config.ValidateStructuredOutput = source.config.ValidateStructuredOutput
+		}
+		configs[source.model] = config
+	}
+	s.configs = configs
+}
+
+func (s *modelConfigStore) onDeployment(obj interface{}) {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return
+	}
+	key := deployment.Namespace + "/" + deployment.Name
+	model := deployment.Labels[modelIdentifierLabel]
+	config, err := parseModelConfig(deployment.Annotations)
+	if err != nil {
+		klog.ErrorS(err, "ignoring invalid model configuration", "deployment", klog.KObj(deployment), "model", model)
+		s.update(key, nil)
+		return
+	}
+	if !config.isEmpty() {
+		klog.InfoS("model configuration loaded", "deployment", klog.KObj(deployment), "model", model, "config", config)
+	}
+	s.update(key, &modelConfigSource{model: model, config: config})
+}
+
+func (s *modelConfigStore) onDeploymentDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if deployment, ok := obj.(*appsv1.Deployment); ok {
+		s.update(deployment.Namespace+"/"+deployment.Name, nil)
+	}
+}
+
+// allPodsQueued returns true if every ready pod has at least threshold requests waiting for the model.
+// Pods without metrics are considered available.
+func allPodsQueued(c aibrixcache.Interface, model string, readyPods []*v1.Pod, threshold float64) bool {
+	if len(readyPods) == 0 {
+		return false
+	}
+	for _, pod := range readyPods {
+		metricVal, err := c.GetPodModelMetric(pod.Name, model, metrics.NumRequestsWaiting)
+		if err != nil {
+			metricVal, err = c.GetPodMetric(pod.Name, metrics.NumRequestsWaiting)
+			if err != nil {
+				return false
+			}
+		}
+		if metricVal.GetSimpleValue() < threshold {
+			return false
+		}
+	}
+	return true
+}
+
+// WatchModelConfigs keeps the per model configuration in sync with the annotations of model Deployments until stopCh is closed.
+func (s *Server) WatchModelConfigs(stopCh <-chan struct{}) {
+	watchModelConfigs(s.client, s.modelConfigs, stopCh)
+}
+
+func watchModelConfigs(client kubernetes.Interface, store *modelConfigStore, stopCh <-chan struct{}) {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = modelIdentifierLabel
+		}))
+	informer := factory.Apps().V1().Deployments().Informer()
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    store.onDeployment,
+		UpdateFunc: func(_, newObj interface{}) { store.onDeployment(newObj) },
+		DeleteFunc: store.onDeploymentDelete,
+	}); err != nil {
+		klog.ErrorS(err, "failed to watch model configurations")
+		return
+	}
+
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		klog.ErrorS(nil, "timed out waiting for model configuration cache to sync")
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/responsecache"
+)
+
+func newModelDeployment(name, model string, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Labels:      map[string]string{modelIdentifierLabel: model},
+			Annotations: annotations,
+		},
+	}
+}
+
+func TestParseModelConfig(t *testing.T) {
+	config, err := parseModelConfig(map[string]string{
+		modelRoutingStrategyAnnotationKey:   "least-request",
+		modelRequestTimeoutAnnotationKey:    "90s",
+		modelMaxQueuedRequestsAnnotationKey: "8",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ModelConfig{RoutingStrategy: "least-request", RequestTimeout: 90 * time.Second, MaxQueuedRequests: 8}, config)
+
+	config, err = parseModelConfig(map[string]string{
+		modelResponseCacheAnnotationKey:           "semantic",
+		modelResponseCacheTTLAnnotationKey:        "1h",
+		modelResponseCacheSimilarityAnnotationKey: "0.9",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, responsecache.Options{Mode: responsecache.ModeSemantic, TTL: time.Hour, MinSimilarity: 0.9}, config.ResponseCache)
+
+	config, err = parseModelConfig(map[string]string{
+		modelAdaptiveConcurrencyAnnotationKey: "true",
+		modelLatencySLOAnnotationKey:          "2s",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ModelConfig{AdaptiveConcurrency: true, LatencySLO: 2 * time.Second}, config)
+
+	config, err = parseModelConfig(map[string]string{modelValidateStructuredOutputAnnotationKey: "true"})
+	assert.NoError(t, err)
+	assert.Equal(t, ModelConfig{ValidateStructuredOutput: true}, config)
+
+	config, err = parseModelConfig(map[string]string{"unrelated": "value"})
+	assert.NoError(t, err)
+	assert.True(t, config.isEmpty())
+
+	for _, annotations := range []map[string]string{
+		{modelRoutingStrategyAnnotationKey: "fastest"},
+		{modelRequestTimeoutAnnotationKey: "90"},
+		{modelMaxQueuedRequestsAnnotationKey: "-1"},
+		{modelResponseCacheAnnotationKey: "fuzzy"},
+		{modelResponseCacheTTLAnnotationKey: "1"},
+		{modelResponseCacheSimilarityAnnotationKey: "2"},
+		{modelAdaptiveConcurrencyAnnotationKey: "yes"},
+		{modelLatencySLOAnnotationKey: "-1s"},
+		{modelValidateStructuredOutputAnnotationKey: "always"},
+	} {
+		_, err := parseModelConfig(annotations)
+		assert.Error(t, err, annotations)
+	}
+}
+
+func TestModelConfigStore(t *testing.T) {
+	s := newModelConfigStore()
+	s.onDeployment(newModelDeployment("llama-a100", "llama", map[string]string{modelRoutingStrategyAnnotationKey: "least-request"}))
+	s.onDeployment(newModelDeployment("llama-l20", "llama", map[string]string{
+		modelRoutingStrategyAnnotationKey: "throughput",
+		modelRequestTimeoutAnnotationKey:  "30s",
+	}))
+	s.onDeployment(newModelDeployment("qwen", "qwen", nil))
+
+	config, ok := s.Get("llama")
+	assert.True(t, ok)
+	assert.Equal(t, ModelConfig{RoutingStrategy: "least-request", RequestTimeout: 30 * time.Second}, config, "fields merge in deployment name order")
+	_, ok = s.Get("qwen")
+	assert.False(t, ok)
+
+	// invalid annotations drop the configuration of the deployment.
+	s.onDeployment(newModelDeployment("llama-a100", "llama", map[string]string{modelRoutingStrategyAnnotationKey: "fastest"}))
+	config, _ = s.Get("llama")
+	assert.Equal(t, "throughput", config.RoutingStrategy)
+
+	s.onDeploymentDelete(newModelDeployment("llama-l20", "llama", nil))
+	_, ok = s.Get("llama")
+	assert.False(t, ok)
+
+	var nilStore *modelConfigStore
+	_, ok = nilStore.Get("llama")
+	assert.False(t, ok)
+}
+
+func TestWatchModelConfigs(t *testing.T) {
+	client := fake.NewSimpleClientset(newModelDeployment("llama", "llama", map[string]string{modelMaxQueuedRequestsAnnotationKey: "4"}))
+	s := newModelConfigStore()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	watchModelConfigs(client, s, stopCh)
+	config, ok := s.Get("llama")
+	assert.True(t, ok)
+	assert.Equal(t, 4, config.MaxQueuedRequests)
+}
+
+func TestResolveRoutingStrategy(t *testing.T) {
+	t.Setenv(EnvRoutingAlgorithm, "random")
+	config := ModelConfig{RoutingStrategy: "least-request"}
+
+	assert.Equal(t, "throughput", resolveRoutingStrategy("throughput", config))
+	assert.Equal(t, "least-request", resolveRoutingStrategy("", config))
+	assert.Equal(t, "random", resolveRoutingStrategy("", ModelConfig{}))
+}
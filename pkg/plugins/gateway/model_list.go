@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	modelListPath = "/v1/models"
+	modelOwner    = "aibrix"
+
+	modelTypeBase    = "base"
+	modelTypeAdapter = "adapter"
+)
+
+// ModelCard follows the OpenAI model object, extended with the serving state known to the gateway.
+type ModelCard struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+
+	Type          string   `json:"type"`
+	Parent        string   `json:"parent,omitempty"`
+	Ready         bool     `json:"ready"`
+	ReadyReplicas int      `json:"ready_replicas"`
+	Replicas      int      `json:"replicas"`
+	Deployments   []string `json:"deployments"`
+
+	MaxModelLen   int      `json:"max_model_len,omitempty"`
+	DType         string   `json:"dtype,omitempty"`
+	Quantization  string   `json:"quantization,omitempty"`
+	Engine        string   `json:"engine,omitempty"`
+	EngineVersion string   `json:"engine_version,omitempty"`
+	Capabilities  []string `json:"capabilities,omitempty"`
+}
+
+// ModelList is the response of the /v1/models endpoint.
+type ModelList struct {
+	Object string      `json:"object"`
+	Data   []ModelCard `json:"data"`
+}
+
+func isModelListRequest(headers []*configPb.HeaderValue) bool {
+	var method, path string
+	for _, header := range headers {
+		switch header.Key {
+		case ":method":
+			method = string(header.RawValue)
+		case ":path":
+			path = string(header.RawValue)
+		}
+	}
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	return method == "GET" && strings.TrimSuffix(path, "/") == modelListPath
+}
+
+// listModels builds the model list of tenant from the pods and adapters currently in the cache. Models are listed
+// under their external names for the tenant, models hidden from the tenant by the name mappings are left out.
+func listModels(c cache.Interface, rewriter *modelNameRewriter, tenant string) ModelList {
+	models := c.GetModels()
+	sort.Strings(models)
+
+	list := ModelList{Object: "list", Data: make([]ModelCard, 0, len(models))}
+	for _, model := range models {
+		pods, err := c.GetPodsForModel(model)
+		if err != nil {
+			// model was removed after listing
+			continue
+		}
+		card := ModelCard{
+			ID:            model,
+			Object:        "model",
+			OwnedBy:       modelOwner,
+			Type:          modelTypeBase,
+			Replicas:      len(pods),
+			ReadyReplicas: len(utils.FilterReadyPods(pods)),
+			Deployments:   getOwningDeployments(pods),
+		}
+		card.Ready = card.ReadyReplicas > 0
+		if info, err := c.GetModelInfo(model); err == nil {
+			card.MaxModelLen = info.MaxModelLen
+			card.DType = info.DType
+			card.Quantization = info.Quantization
+			card.Engine = info.Engine
+			card.EngineVersion = info.EngineVersion
+			card.Capabilities = info.Capabilities
+		}
+		for _, pod := range pods {
+			if created := pod.CreationTimestamp.Unix(); card.Created == 0 || created < card.Created {
+				card.Created = created
+			}
+		}
+		if adapter, err := c.GetModelAdapter(model); err == nil {
+			card.Type = modelTypeAdapter
+			card.Created = adapter.CreationTimestamp.Unix()
+			if adapter.Spec.BaseModel != nil {
+				// the base model is left out if it is hidden from the tenant.
+				if parents, _ := rewriter.ExternalNames(tenant, *adapter.Spec.BaseModel); len(parents) > 0 {
+					card.Parent = parents[0]
+				}
+			}
+		}
+		names, _ := rewriter.ExternalNames(tenant, model)
+		for _, name := range names {
+			card.ID = name
+			list.Data = append(list.Data, card)
+		}
+	}
+	sort.SliceStable(list.Data, func(i, j int) bool { return list.Data[i].ID < list.Data[j].ID })
+	return list
+}
+
+// getOwningDeployments returns the sorted namespace/name of the workloads owning the pods.
+func getOwningDeployments(pods map[string]*v1.Pod) []string {
+	seen := map[string]struct{}{}
+	for _, pod := range pods {
+		for _, owner := range pod.OwnerReferences {
+			if owner.Controller == nil || !*owner.Controller {
+				continue
+			}
+			name := owner.Name
+			// ReplicaSets created by a Deployment are suffixed with the pod template hash.
+			if hash, ok := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok && owner.Kind == "ReplicaSet" {
+				name = strings.TrimSuffix(name, "-"+hash)
+			}
+			seen[pod.Namespace+"/"+name] = struct{}{}
+		}
+	}
+
+	deployments := make([]string, 0, len(seen))
+	for name := range seen {
+		deployments = append(deployments, name)
+	}
+	sort.Strings(deployments)
+	return deployments
+}
+
+func (s *Server) generateModelListResponse(requestID, tenant string) *extProcPb.ProcessingResponse {
+	body, err := json.Marshal(listModels(s.cache, s.modelRewriter, tenant))
+	if err != nil {
+		klog.ErrorS(err, "failed to marshal model list", "requestID", requestID)
+		return generateErrorResponse(envoyTypePb.StatusCode_InternalServerError, nil, "error on listing models")
+	}
+
+	return &extProcPb.ProcessingResponse{
+		Response: &extProcPb.ProcessingResponse_ImmediateResponse{
+			ImmediateResponse: &extProcPb.ImmediateResponse{
+				Status: &envoyTypePb.HttpStatus{
+					Code: envoyTypePb.StatusCode_OK,
+				},
+				Headers: &extProcPb.HeaderMutation{
+					SetHeaders: []*configPb.HeaderValueOption{{
+						Header: &configPb.HeaderValue{
+							Key:   "Content-Type",
+							Value: "application/json",
+						},
+					}},
+				},
+				Body: string(body),
+			},
+		},
+	}
+}
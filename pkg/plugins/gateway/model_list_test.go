@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+func newModelPod(name, replicaSet, hash string, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"pod-template-hash": hash},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: replicaSet, Controller: ptr.To(true)},
+			},
+		},
+		Status: v1.PodStatus{
+			PodIP:      "10.0.0.1",
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestListModels(t *testing.T) {
+	p1 := newModelPod("p1", "llama-7b-5d4f8", "5d4f8", true)
+	p1.Annotations = map[string]string{
+		cache.ModelMaxModelLenAnnotationKey:  "4096",
+		cache.ModelQuantizationAnnotationKey: "awq",
+	}
+	c := cache.New()
+	c.SetPod(p1, "llama-7b")
+	c.SetPod(newModelPod("p2", "llama-7b-5d4f8", "5d4f8", false), "llama-7b")
+	c.SetPod(newModelPod("p3", "qwen-7b-9c7b6", "9c7b6", false), "qwen-7b")
+
+	list := listModels(c, &modelNameRewriter{}, "")
+	assert.Equal(t, "list", list.Object)
+	assert.Len(t, list.Data, 2)
+
+	assert.Equal(t, "llama-7b", list.Data[0].ID)
+	assert.Equal(t, modelTypeBase, list.Data[0].Type)
+	assert.True(t, list.Data[0].Ready)
+	assert.Equal(t, 1, list.Data[0].ReadyReplicas)
+	assert.Equal(t, 2, list.Data[0].Replicas)
+	assert.Equal(t, []string{"default/llama-7b"}, list.Data[0].Deployments)
+	assert.Equal(t, 4096, list.Data[0].MaxModelLen)
+	assert.Equal(t, "awq", list.Data[0].Quantization)
+	assert.Equal(t, "vllm", list.Data[0].Engine)
+
+	assert.Equal(t, "qwen-7b", list.Data[1].ID)
+	assert.False(t, list.Data[1].Ready)
+}
+
+func TestListModelsExternalNames(t *testing.T) {
+	c := cache.New()
+	c.SetPod(newModelPod("p1", "llama-70b-5d4f8", "5d4f8", true), "llama-70b")
+	c.SetPod(newModelPod("p2", "qwen-72b-9c7b6", "9c7b6", true), "qwen-72b")
+	c.SetPod(newModelPod("p3", "llama-7b-7c6d5", "7c6d5", true), "llama-7b")
+	r, err := newModelNameRewriter([]ModelNameMapping{
+		{External: "acme/gpt-large", Internal: "llama-70b"},
+		{Tenant: "carol", External: "carol/private", Internal: "qwen-72b"},
+	})
+	require.NoError(t, err)
+
+	ids := func(list ModelList) []string {
+		var ids []string
+		for _, card := range list.Data {
+			ids = append(ids, card.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"acme/gpt-large", "llama-7b"}, ids(listModels(c, r, "alice")))
+	assert.Equal(t, []string{"acme/gpt-large", "carol/private", "llama-7b"}, ids(listModels(c, r, "carol")))
+}
+
+func TestIsModelListRequest(t *testing.T) {
+	headers := func(method, path string) []*configPb.HeaderValue {
+		return []*configPb.HeaderValue{
+			{Key: ":method", RawValue: []byte(method)},
+			{Key: ":path", RawValue: []byte(path)},
+		}
+	}
+
+	assert.True(t, isModelListRequest(headers("GET", "/v1/models")))
+	assert.True(t, isModelListRequest(headers("GET", "/v1/models/?limit=10")))
+	assert.False(t, isModelListRequest(headers("POST", "/v1/models")))
+	assert.False(t, isModelListRequest(headers("GET", "/v1/chat/completions")))
+}
+
+func TestModelListRequestAuthentication(t *testing.T) {
+	c := cache.New()
+	c.SetPod(newModelPod("p1", "llama-7b-5d4f8", "5d4f8", true), "llama-7b")
+	r, err := newModelNameRewriter([]ModelNameMapping{{Tenant: "acme", External: "acme/gpt", Internal: "llama-7b"}})
+	require.NoError(t, err)
+	s := &Server{cache: c, modelRewriter: r, authenticator: fakeAuthenticator{
+		"sk-1": {User: "alice", Tenant: "acme"},
+		"sk-2": {User: "bob"},
+	}}
+	method := &configPb.HeaderValue{Key: ":method", RawValue: []byte("GET")}
+	path := &configPb.HeaderValue{Key: ":path", RawValue: []byte("/v1/models")}
+
+	resp, _, _, _ := s.HandleRequestHeaders(context.Background(), "r1", requestHeaders(method, path))
+	assert.Equal(t, envoyTypePb.StatusCode_Unauthorized, resp.GetImmediateResponse().GetStatus().GetCode())
+
+	var list ModelList
+	resp, _, _, _ = s.HandleRequestHeaders(context.Background(), "r2", requestHeaders(method, path,
+		&configPb.HeaderValue{Key: "authorization", RawValue: []byte("Bearer sk-1")}))
+	require.NoError(t, json.Unmarshal([]byte(resp.GetImmediateResponse().Body), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "acme/gpt", list.Data[0].ID)
+
+	resp, _, _, _ = s.HandleRequestHeaders(context.Background(), "r3", requestHeaders(method, path,
+		&configPb.HeaderValue{Key: "authorization", RawValue: []byte("Bearer sk-2")}))
+	require.NoError(t, json.Unmarshal([]byte(resp.GetImmediateResponse().Body), &list))
+	assert.Empty(t, list.Data, "the model is only exposed to acme")
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	EnvModelNameMappingFile = "AIBRIX_MODEL_NAME_MAPPING_FILE"
+
+	// anyTenant is used for mappings that apply to every tenant.
+	anyTenant = ""
+
+	// modelFieldName starts the model field rewritten in the responses.
+	modelFieldName = `"model"`
+	// maxModelFieldSpaces bounds the whitespace around the colon of a model field split across chunks.
+	maxModelFieldSpaces = 16
+)
+
+// ModelNameMapping maps an external model name (e.g. acme/gpt-large) to the internal deployment name.
+type ModelNameMapping struct {
+	// Tenant scopes the mapping to a user, empty applies to all users.
+	Tenant   string `json:"tenant,omitempty"`
+	External string `json:"external"`
+	Internal string `json:"internal"`
+}
+
+// modelNameRewriter translates model names between the white-labeled catalog and the deployments.
+type modelNameRewriter struct {
+	toInternal map[string]map[string]string // tenant: map[external_name]internal_name
+	// patterns match the model fields of the internal names in the responses, by internal name.
+	patterns map[string]*regexp.Regexp
+}
+
+func newModelNameRewriter(mappings []ModelNameMapping) (*modelNameRewriter, error) {
+	r := &modelNameRewriter{toInternal: map[string]map[string]string{}, patterns: map[string]*regexp.Regexp{}}
+	for _, m := range mappings {
+		if m.External == "" || m.Internal == "" {
+			return nil, fmt.Errorf("invalid model name mapping, external and internal are required: %+v", m)
+		}
+		if _, ok := r.toInternal[m.Tenant]; !ok {
+			r.toInternal[m.Tenant] = map[string]string{}
+		}
+		if existing, ok := r.toInternal[m.Tenant][m.External]; ok && existing != m.Internal {
+			return nil, fmt.Errorf("conflicting model name mapping for tenant %q: %s maps to both %s and %s", m.Tenant, m.External, existing, m.Internal)
+		}
+		r.toInternal[m.Tenant][m.External] = m.Internal
+		if _, ok := r.patterns[m.Internal]; !ok {
+			r.patterns[m.Internal] = regexp.MustCompile(modelFieldName + `\s*:\s*"` + regexp.QuoteMeta(m.Internal) + `"`)
+		}
+	}
+	return r, nil
+}
+
+// loadModelNameRewriter loads mappings from the file configured by AIBRIX_MODEL_NAME_MAPPING_FILE.
+// An empty rewriter is returned if no file is configured.
+func loadModelNameRewriter() *modelNameRewriter {
+	path := utils.GetEnv(EnvModelNameMappingFile, "")
+	if path == "" {
+		return &modelNameRewriter{toInternal: map[string]map[string]string{}, patterns: map[string]*regexp.Regexp{}}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		klog.Fatalf("failed to read model name mapping file %s: %v", path, err)
+	}
+	var mappings []ModelNameMapping
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		klog.Fatalf("failed to parse model name mapping file %s: %v", path, err)
+	}
+	r, err := newModelNameRewriter(mappings)
+	if err != nil {
+		klog.Fatal(err)
+	}
+	klog.InfoS("loaded model name mappings", "file", path, "count", len(mappings))
+	return r
+}
+
+// ToInternal returns the deployment name for the external model name requested by tenant.
+// Tenant specific mappings take precedence over global ones.
+func (r *modelNameRewriter) ToInternal(tenant, model string) (string, bool) {
+	if internal, ok := r.toInternal[tenant][model]; ok {
+		return internal, true
+	}
+	if internal, ok := r.toInternal[anyTenant][model]; ok {
+		return internal, true
+	}
+	return model, false
+}
+
+// ExternalNames returns the sorted external names under which tenant requests the deployment, and whether the
+// deployment is mapped at all. Unmapped deployments are requested by their own name, mapped ones only by the external
+// names of the tenant, which may be none. Global mappings apply unless the tenant maps the external name itself.
+func (r *modelNameRewriter) ExternalNames(tenant, internal string) ([]string, bool) {
+	if _, ok := r.patterns[internal]; !ok {
+		return []string{internal}, false
+	}
+	var names []string
+	for external, mapped := range r.toInternal[tenant] {
+		if mapped == internal {
+			names = append(names, external)
+		}
+	}
+	if tenant != anyTenant {
+		for external, mapped := range r.toInternal[anyTenant] {
+			if _, overridden := r.toInternal[tenant][external]; mapped == internal && !overridden {
+				names = append(names, external)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, true
+}
+
+// ToExternal rewrites the model field in a response body from internal back to external name.
+func (r *modelNameRewriter) ToExternal(body []byte, internal, external string) []byte {
+	if internal == external || len(body) == 0 {
+		return body
+	}
+	pattern, ok := r.patterns[internal]
+	if !ok {
+		return body
+	}
+	replacement, _ := json.Marshal(external)
+	return pattern.ReplaceAllLiteral(body, append([]byte(modelFieldName+":"), replacement...))
+}
+
+// modelRewriteStream holds the end of the last chunk of a response that may start a model field completed by the
+// next chunk.
+type modelRewriteStream struct {
+	tail []byte
+}
+
+type modelRewriteStreamKey struct{}
+
+// withModelRewriteStream holds the model rewrite state of the response chunks of the request.
+func withModelRewriteStream(ctx context.Context) context.Context {
+	return context.WithValue(ctx, modelRewriteStreamKey{}, &modelRewriteStream{})
+}
+
+func modelRewriteStreamFrom(ctx context.Context) *modelRewriteStream {
+	stream, _ := ctx.Value(modelRewriteStreamKey{}).(*modelRewriteStream)
+	return stream
+}
+
+// ToExternalChunk rewrites the model fields of a response chunk like ToExternal, including those split across chunks:
+// the end of the chunk which may start a model field is held back and prepended to the next chunk. Without stream,
+// the chunk is rewritten on its own.
+func (r *modelNameRewriter) ToExternalChunk(stream *modelRewriteStream, chunk []byte, internal, external string, endOfStream bool) []byte {
+	if stream == nil {
+		return r.ToExternal(chunk, internal, external)
+	}
+	body := chunk
+	if len(stream.tail) > 0 {
+		body = append(stream.tail, chunk...)
+		stream.tail = nil
+	}
+	body = r.ToExternal(body, internal, external)
+	if !endOfStream {
+		if i := partialModelField(body, internal); i >= 0 {
+			stream.tail = append([]byte(nil), body[i:]...)
+			body = body[:i]
+		}
+	}
+	return body
+}
+
+// partialModelField returns where the end of body starts a model field of the internal name that isn't complete, -1
+// if it doesn't.
+func partialModelField(body []byte, internal string) int {
+	start := len(body) - len(modelFieldName) - 2*maxModelFieldSpaces - len(internal) - 3
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < len(body); i++ {
+		if body[i] == '"' && isModelFieldPrefix(body[i:], internal) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isModelFieldPrefix tells whether data is the beginning of a model field of the internal name, but not all of it.
+func isModelFieldPrefix(data []byte, internal string) bool {
+	if len(data) <= len(modelFieldName) {
+		return bytes.HasPrefix([]byte(modelFieldName), data)
+	}
+	if !bytes.HasPrefix(data, []byte(modelFieldName)) {
+		return false
+	}
+	data = bytes.TrimLeft(data[len(modelFieldName):], " \t\r\n")
+	if len(data) == 0 {
+		return true
+	}
+	if data[0] != ':' {
+		return false
+	}
+	data = bytes.TrimLeft(data[1:], " \t\r\n")
+	value := `"` + internal + `"`
+	return len(data) < len(value) && bytes.HasPrefix([]byte(value), data)
+}
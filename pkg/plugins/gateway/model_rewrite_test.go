@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelNameRewriter(t *testing.T) {
+	r, err := newModelNameRewriter([]ModelNameMapping{
+		{External: "acme/gpt-large", Internal: "llama-70b"},
+		{Tenant: "bob", External: "acme/gpt-large", Internal: "llama-70b-dedicated"},
+	})
+	assert.NoError(t, err)
+
+	var tests = []struct {
+		tenant            string
+		model             string
+		expectedModel     string
+		expectedRewritten bool
+		message           string
+	}{
+		{
+			tenant:            "alice",
+			model:             "acme/gpt-large",
+			expectedModel:     "llama-70b",
+			expectedRewritten: true,
+			message:           "global mapping applies to any tenant",
+		},
+		{
+			tenant:            "bob",
+			model:             "acme/gpt-large",
+			expectedModel:     "llama-70b-dedicated",
+			expectedRewritten: true,
+			message:           "tenant mapping takes priority over global mapping",
+		},
+		{
+			tenant:            "alice",
+			model:             "llama-7b",
+			expectedModel:     "llama-7b",
+			expectedRewritten: false,
+			message:           "unmapped model is passed through",
+		},
+	}
+
+	for _, tt := range tests {
+		model, rewritten := r.ToInternal(tt.tenant, tt.model)
+		assert.Equal(t, tt.expectedModel, model, tt.message)
+		assert.Equal(t, tt.expectedRewritten, rewritten, tt.message)
+	}
+}
+
+func TestModelNameRewriterExternalNames(t *testing.T) {
+	r, err := newModelNameRewriter([]ModelNameMapping{
+		{External: "acme/gpt-large", Internal: "llama-70b"},
+		{External: "acme/gpt-xl", Internal: "llama-70b"},
+		{Tenant: "bob", External: "acme/gpt-large", Internal: "llama-70b-dedicated"},
+		{Tenant: "carol", External: "carol/private", Internal: "qwen-72b"},
+	})
+	assert.NoError(t, err)
+
+	names, mapped := r.ExternalNames("alice", "llama-70b")
+	assert.Equal(t, []string{"acme/gpt-large", "acme/gpt-xl"}, names)
+	assert.True(t, mapped)
+	names, _ = r.ExternalNames("bob", "llama-70b")
+	assert.Equal(t, []string{"acme/gpt-xl"}, names, "the tenant mapping overrides the global one")
+	names, _ = r.ExternalNames("bob", "llama-70b-dedicated")
+	assert.Equal(t, []string{"acme/gpt-large"}, names)
+	names, mapped = r.ExternalNames("alice", "qwen-72b")
+	assert.Empty(t, names, "the deployment is only exposed to carol")
+	assert.True(t, mapped)
+	names, mapped = r.ExternalNames("alice", "llama-7b")
+	assert.Equal(t, []string{"llama-7b"}, names)
+	assert.False(t, mapped)
+}
+
+func TestModelNameRewriterConflict(t *testing.T) {
+	_, err := newModelNameRewriter([]ModelNameMapping{
+		{External: "acme/gpt-large", Internal: "llama-70b"},
+		{External: "acme/gpt-large", Internal: "llama-7b"},
+	})
+	assert.Error(t, err)
+}
+
+func TestModelNameRewriterToExternal(t *testing.T) {
+	r, err := newModelNameRewriter([]ModelNameMapping{{External: "acme/gpt-large", Internal: "llama-70b"}})
+	assert.NoError(t, err)
+
+	body := []byte(`data: {"id":"1","model": "llama-70b","choices":[]}`)
+	assert.Equal(t, `data: {"id":"1","model":"acme/gpt-large","choices":[]}`,
+		string(r.ToExternal(body, "llama-70b", "acme/gpt-large")))
+
+	body = []byte(`{"id":"1","model":"llama-70b-dedicated"}`)
+	assert.Equal(t, string(body), string(r.ToExternal(body, "llama-70b", "acme/gpt-large")),
+		"model with the same prefix must not be rewritten")
+
+	body = []byte(`{"id":"1","model":"qwen-72b"}`)
+	assert.Equal(t, string(body), string(r.ToExternal(body, "qwen-72b", "acme/gpt-large")),
+		"unmapped model must not be rewritten")
+}
+
+func TestModelNameRewriterToExternalChunk(t *testing.T) {
+	r, err := newModelNameRewriter([]ModelNameMapping{{External: "acme/gpt-large", Internal: "llama-70b"}})
+	assert.NoError(t, err)
+	body := `data: {"id":"1","model" : "llama-70b","choices":[]}` + "\n\n" + `data: {"id":"2","model":"llama-70b-dedicated"}` + "\n\n"
+	expected := `data: {"id":"1","model":"acme/gpt-large","choices":[]}` + "\n\n" + `data: {"id":"2","model":"llama-70b-dedicated"}` + "\n\n"
+
+	// the model field is split at every position across two chunks.
+	for split := 0; split <= len(body); split++ {
+		stream := &modelRewriteStream{}
+		first := r.ToExternalChunk(stream, []byte(body[:split]), "llama-70b", "acme/gpt-large", false)
+		second := r.ToExternalChunk(stream, []byte(body[split:]), "llama-70b", "acme/gpt-large", true)
+		assert.Equal(t, expected, string(first)+string(second), "split at %d", split)
+		assert.Empty(t, stream.tail)
+	}
+
+	// only the start of a model field is held back.
+	stream := &modelRewriteStream{}
+	chunk := r.ToExternalChunk(stream, []byte(`data: {"id":"1","choices":[]}`+"\n\n"), "llama-70b", "acme/gpt-large", false)
+	assert.Equal(t, `data: {"id":"1","choices":[]}`+"\n\n", string(chunk))
+	chunk = r.ToExternalChunk(stream, []byte(`data: {"id":"2","model":"llama`), "llama-70b", "acme/gpt-large", false)
+	assert.Equal(t, `data: {"id":"2",`, string(chunk))
+	assert.Equal(t, `"model":"llama`, string(stream.tail))
+}
+
+func TestRewrittenResponseHeaders(t *testing.T) {
+	s := &Server{}
+	req := &extProcPb.ProcessingRequest{Request: &extProcPb.ProcessingRequest_ResponseHeaders{
+		ResponseHeaders: &extProcPb.HttpHeaders{Headers: &configPb.HeaderMap{Headers: []*configPb.HeaderValue{
+			{Key: ":status", RawValue: []byte("200")},
+			{Key: "content-length", RawValue: []byte("42")},
+		}}},
+	}}
+	hasContentLength := func(resp *extProcPb.ProcessingResponse) bool {
+		for _, header := range resp.GetResponseHeaders().GetResponse().GetHeaderMutation().GetSetHeaders() {
+			if header.GetHeader().GetKey() == "content-length" {
+				return true
+			}
+		}
+		return false
+	}
+
+	resp, _, _ := s.HandleResponseHeaders(context.Background(), "r1", req, "llama-70b", "llama-70b", "")
+	assert.True(t, hasContentLength(resp))
+	resp, _, _ = s.HandleResponseHeaders(context.Background(), "r2", req, "llama-70b", "acme/gpt-large", "")
+	assert.False(t, hasContentLength(resp), "the rewritten body has another length")
+	assert.Contains(t, resp.GetResponseHeaders().GetResponse().GetHeaderMutation().GetRemoveHeaders(), "content-length")
+}
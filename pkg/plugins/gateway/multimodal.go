@@ -0,0 +1,212 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"  // register the gif decoder of image.DecodeConfig
+	_ "image/jpeg" // register the jpeg decoder of image.DecodeConfig
+	_ "image/png"  // register the png decoder of image.DecodeConfig
+	"math"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// VisionEncoderTile splits images into 512px tiles of 170 tokens on top of 85 tokens, like the OpenAI models.
+	VisionEncoderTile = "tile"
+	// VisionEncoderQwen2VL turns every 28x28 pixels of images resized within its pixel bounds into a token.
+	VisionEncoderQwen2VL = "qwen2-vl"
+	// VisionEncoderLlava encodes every image into 576 tokens, as 24x24 patches of a 336px image.
+	VisionEncoderLlava = "llava"
+
+	defaultMaxImageBytes = 20 << 20
+	// defaultImageSize is assumed for images whose size is unknown, e.g. given by URL.
+	defaultImageSize = 1024
+)
+
+var (
+	defaultVisionEncoder  = getDefaultVisionEncoder()
+	maxImagesPerRequest   = getMaxImagesPerRequest()
+	maxImageBytes         = getMaxImageBytes()
+	visionEncoderEstimate = map[string]func(img imageInput) int{
+		VisionEncoderTile:    tileImageTokens,
+		VisionEncoderQwen2VL: qwen2VLImageTokens,
+		VisionEncoderLlava:   func(imageInput) int { return 576 },
+	}
+)
+
+func getDefaultVisionEncoder() string {
+	value := utils.LoadEnv("AIBRIX_DEFAULT_VISION_ENCODER", VisionEncoderTile)
+	if value != VisionEncoderTile && value != VisionEncoderQwen2VL && value != VisionEncoderLlava {
+		klog.Infof("invalid AIBRIX_DEFAULT_VISION_ENCODER: %s, falling back to default", value)
+		return VisionEncoderTile
+	}
+	return value
+}
+
+func getMaxImagesPerRequest() int {
+	value := utils.LoadEnv("AIBRIX_MAX_IMAGES_PER_REQUEST", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_MAX_IMAGES_PER_REQUEST: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_MAX_IMAGES_PER_REQUEST env value for max images per request: %d", intValue)
+			return intValue
+		}
+	}
+	return 0
+}
+
+func getMaxImageBytes() int {
+	value := utils.LoadEnv("AIBRIX_MAX_IMAGE_BYTES", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_MAX_IMAGE_BYTES: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_MAX_IMAGE_BYTES env value for max image size: %d bytes", intValue)
+			return intValue
+		}
+	}
+	return defaultMaxImageBytes
+}
+
+// imageInput is an image part of the messages of a chat completion.
+type imageInput struct {
+	// width and height are 0 if unknown, e.g. for images given by URL or of an unsupported format.
+	width, height int
+	// bytes is the decoded size of inline images, 0 for images given by URL.
+	bytes int
+	// detail is the requested fidelity, low, high or auto.
+	detail string
+}
+
+// parseImages returns the image_url parts of the messages. The size of inline base64 images is read from their
+// header, images given by URL are not fetched.
+func parseImages(messages interface{}) []imageInput {
+	var images []imageInput
+	list, _ := messages.([]interface{})
+	for _, message := range list {
+		message, _ := message.(map[string]interface{})
+		parts, _ := message["content"].([]interface{})
+		for _, part := range parts {
+			part, _ := part.(map[string]interface{})
+			if part["type"] != "image_url" {
+				continue
+			}
+			var url string
+			img := imageInput{}
+			switch imageURL := part["image_url"].(type) {
+			case string:
+				url = imageURL
+			case map[string]interface{}:
+				url, _ = imageURL["url"].(string)
+				img.detail, _ = imageURL["detail"].(string)
+			}
+			if header, payload, ok := strings.Cut(url, ","); ok && strings.HasPrefix(header, "data:") && strings.HasSuffix(header, ";base64") {
+				img.bytes = base64.StdEncoding.DecodedLen(len(payload)) - strings.Count(payload[max(len(payload)-2, 0):], "=")
+				if config, _, err := image.DecodeConfig(base64.NewDecoder(base64.StdEncoding, strings.NewReader(payload))); err == nil {
+					img.width, img.height = config.Width, config.Height
+				}
+			}
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+// checkImageLimits returns an error if the request has more images than AIBRIX_MAX_IMAGES_PER_REQUEST or an inline
+// image larger than AIBRIX_MAX_IMAGE_BYTES, 0 disables a limit.
+func checkImageLimits(images []imageInput) error {
+	if maxImagesPerRequest > 0 && len(images) > maxImagesPerRequest {
+		return fmt.Errorf("request has %d images, more than the max of %d", len(images), maxImagesPerRequest)
+	}
+	for i, img := range images {
+		if maxImageBytes > 0 && img.bytes > maxImageBytes {
+			return fmt.Errorf("image %d is %d bytes, more than the max of %d", i, img.bytes, maxImageBytes)
+		}
+	}
+	return nil
+}
+
+// imageTokens estimates the input tokens of the images with the vision encoder of the model, declared by the
+// model.aibrix.ai/vision-encoder annotation of its pods, or else AIBRIX_DEFAULT_VISION_ENCODER.
+func (s *Server) imageTokens(model string, images []imageInput) int {
+	encoder := defaultVisionEncoder
+	if info, err := s.cache.GetModelInfo(model); err == nil && info.VisionEncoder != "" {
+		encoder = info.VisionEncoder
+	}
+	estimate, ok := visionEncoderEstimate[encoder]
+	if !ok {
+		klog.V(4).InfoS("unknown vision encoder, falling back to default", "model", model, "visionEncoder", encoder)
+		estimate = visionEncoderEstimate[defaultVisionEncoder]
+	}
+	tokens := 0
+	for _, img := range images {
+		tokens += estimate(img)
+	}
+	return tokens
+}
+
+func (img imageInput) size() (float64, float64) {
+	if img.width <= 0 || img.height <= 0 {
+		return defaultImageSize, defaultImageSize
+	}
+	return float64(img.width), float64(img.height)
+}
+
+// tileImageTokens fits the image in 2048x2048, scales it down to 768px on its shortest side and counts its 512px tiles.
+func tileImageTokens(img imageInput) int {
+	if img.detail == "low" {
+		return 85
+	}
+	width, height := img.size()
+	if scale := 2048 / max(width, height); scale < 1 {
+		width, height = width*scale, height*scale
+	}
+	if scale := 768 / min(width, height); scale < 1 {
+		width, height = width*scale, height*scale
+	}
+	tiles := math.Ceil(width/512) * math.Ceil(height/512)
+	return 85 + 170*int(tiles)
+}
+
+// qwen2VLImageTokens rounds the image to 28px and resizes it within 4 to 16384 tokens, keeping its aspect ratio.
+func qwen2VLImageTokens(img imageInput) int {
+	const factor, minPixels, maxPixels = 28.0, 4 * 28 * 28, 16384 * 28 * 28
+	width, height := img.size()
+	w := max(factor, math.Round(width/factor)*factor)
+	h := max(factor, math.Round(height/factor)*factor)
+	if w*h > maxPixels {
+		beta := math.Sqrt(width * height / maxPixels)
+		w = max(factor, math.Floor(width/beta/factor)*factor)
+		h = max(factor, math.Floor(height/beta/factor)*factor)
+	} else if w*h < minPixels {
+		beta := math.Sqrt(minPixels / (width * height))
+		w = math.Ceil(width*beta/factor) * factor
+		h = math.Ceil(height*beta/factor) * factor
+	}
+	return int(w/factor) * int(h/factor)
+}
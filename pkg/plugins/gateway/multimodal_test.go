@@ -0,0 +1,85 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+)
+
+func TestParseImages(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 640, 480))))
+	dataURL := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	body := `{"messages": [
+		{"role": "system", "content": "describe the images"},
+		{"role": "user", "content": [
+			{"type": "text", "text": "what is it?"},
+			{"type": "image_url", "image_url": {"url": "` + dataURL + `"}},
+			{"type": "image_url", "image_url": {"url": "http://images/cat.jpg", "detail": "low"}}
+		]}
+	]}`
+	var jsonMap map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(body), &jsonMap))
+	input, err := parseRequestInput(EndpointChatCompletions, jsonMap)
+	assert.NoError(t, err)
+	assert.Equal(t, []imageInput{
+		{width: 640, height: 480, bytes: buf.Len()},
+		{detail: "low"},
+	}, input.images)
+}
+
+func TestCheckImageLimits(t *testing.T) {
+	defer func(images, size int) { maxImagesPerRequest, maxImageBytes = images, size }(maxImagesPerRequest, maxImageBytes)
+	maxImagesPerRequest, maxImageBytes = 2, 1000
+
+	assert.NoError(t, checkImageLimits([]imageInput{{bytes: 1000}, {}}))
+	assert.Error(t, checkImageLimits([]imageInput{{}, {}, {}}))
+	assert.Error(t, checkImageLimits([]imageInput{{bytes: 1001}}))
+
+	maxImagesPerRequest, maxImageBytes = 0, 0
+	assert.NoError(t, checkImageLimits([]imageInput{{}, {}, {bytes: 1 << 30}}))
+}
+
+func TestImageTokens(t *testing.T) {
+	assert.Equal(t, 765, tileImageTokens(imageInput{width: 4000, height: 3000}))
+	assert.Equal(t, 85+170*2, tileImageTokens(imageInput{width: 1000, height: 400}))
+	assert.Equal(t, 85, tileImageTokens(imageInput{width: 4000, height: 3000, detail: "low"}))
+	assert.Equal(t, 765, tileImageTokens(imageInput{}), "images of unknown size are assumed 1024px")
+
+	assert.Equal(t, 4, qwen2VLImageTokens(imageInput{width: 56, height: 56}))
+	assert.Equal(t, 4, qwen2VLImageTokens(imageInput{width: 10, height: 10}))
+	assert.Equal(t, 37*37, qwen2VLImageTokens(imageInput{}))
+	assert.LessOrEqual(t, qwen2VLImageTokens(imageInput{width: 8000, height: 8000}), 16384)
+
+	pod := newModelPod("p1", "qwen-vl-5d4f8", "5d4f8", true)
+	pod.Annotations = map[string]string{cache.ModelVisionEncoderAnnotationKey: VisionEncoderLlava}
+	c := cache.New()
+	c.SetPod(pod, "qwen-vl")
+	s := &Server{cache: c}
+	assert.Equal(t, 2*576, s.imageTokens("qwen-vl", []imageInput{{}, {width: 56, height: 56}}))
+	assert.Equal(t, 765, s.imageTokens("llama-7b", []imageInput{{}}), "models without vision encoder use the default")
+}
@@ -19,9 +19,12 @@ package prefixcacheindexer
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"math/rand"
+	"sort"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cespare/xxhash/v2"
@@ -34,6 +37,8 @@ const (
 	defaultPrefixCacheBlockSize              = 16
 	defaultPrefixCacheEvictionInternalInMS   = 50
 	defaultPrefixCacheEvictionDurationInMins = 60
+	defaultPrefixCacheSpeculativeTTLInSecs   = 120
+	defaultPrefixCacheCheckpointMaxBlocks    = 10000
 )
 
 var (
@@ -41,6 +46,8 @@ var (
 	prefixCacheBlockSize        = getPrefixCacheBlockSize()
 	prefixCacheEvictionInterval = getPrefixCacheEvictionInterval()
 	prefixCacheEvictionDuration = getPrefixCacheEvictionDuration()
+	prefixCacheSpeculativeTTL   = getPrefixCacheSpeculativeTTL()
+	prefixCacheCheckpointBlocks = getPrefixCacheCheckpointMaxBlocks()
 )
 
 func getPrefixCacheBlockSize() int {
@@ -88,15 +95,54 @@ func getPrefixCacheEvictionDuration() time.Duration {
 	return defaultPrefixCacheEvictionDurationInMins * time.Minute
 }
 
+func getPrefixCacheSpeculativeTTL() time.Duration {
+	value := utils.LoadEnv("AIBRIX_PREFIX_CACHE_SPECULATIVE_TTL_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_PREFIX_CACHE_SPECULATIVE_TTL_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_PREFIX_CACHE_SPECULATIVE_TTL_S env value for prefix cache speculative ttl: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultPrefixCacheSpeculativeTTLInSecs * time.Second
+}
+
+func getPrefixCacheCheckpointMaxBlocks() int {
+	value := utils.LoadEnv("AIBRIX_PREFIX_CACHE_CHECKPOINT_MAX_BLOCKS", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 0 {
+			klog.Infof("invalid AIBRIX_PREFIX_CACHE_CHECKPOINT_MAX_BLOCKS: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_PREFIX_CACHE_CHECKPOINT_MAX_BLOCKS env value for prefix cache checkpoint max blocks: %d", intValue)
+			return intValue
+		}
+	}
+	return defaultPrefixCacheCheckpointMaxBlocks
+}
+
+// BlockSize returns the number of tokens per prefix block.
+func BlockSize() int {
+	return prefixCacheBlockSize
+}
+
 type PrefixHashTable struct {
 	mu     sync.RWMutex
 	blocks map[uint64]Block
 	hash   *xxhash.Digest
 	seed   uint64
+
+	lookups       atomic.Int64
+	hits          atomic.Int64
+	matchedTokens atomic.Int64
+	totalTokens   atomic.Int64
 }
 
 type Block struct {
 	modelToPods    map[string]map[string]time.Time // model_name: map[pod_name]pod_last_access_time
+	speculative    map[string]map[string]time.Time // model_name: map[pod_name]routed_time, placements not confirmed yet
 	lastAccessTime time.Time                       //block_last_access_time
 }
 
@@ -153,6 +199,13 @@ func (c *PrefixHashTable) MatchPrefix(tokens []int, model string, pods []*v1.Pod
 	matchedTokens := tokens[0:lastTokenMatchIndex]
 	unMatchedTokens := tokens[lastTokenMatchIndex:]
 
+	c.lookups.Add(1)
+	c.totalTokens.Add(int64(len(tokens)))
+	c.matchedTokens.Add(int64(len(matchedTokens)))
+	if len(matchedTokens) > 0 {
+		c.hits.Add(1)
+	}
+
 	var matchedPods []*v1.Pod
 	blockPods := lastMatchedBlock.modelToPods[model]
 	for _, pod := range pods {
@@ -193,10 +246,92 @@ func (c *PrefixHashTable) AddPrefix(unMatchedTokens []int, model, pod string) {
 			block.modelToPods[model] = blockPods
 		}
 
+		// placements are speculative until the engine is known to hold the block.
+		if _, ok := blockPods[pod]; !ok {
+			if block.speculative == nil {
+				block.speculative = map[string]map[string]time.Time{}
+				c.blocks[prefixHash] = block
+			}
+			if block.speculative[model] == nil {
+				block.speculative[model] = map[string]time.Time{}
+			}
+			block.speculative[model][pod] = time.Now()
+		}
 		blockPods[pod] = time.Now()
 	}
 }
 
+// ConfirmPrefix marks the speculative placements of the tokens on the pod as cached by the engine.
+func (c *PrefixHashTable) ConfirmPrefix(tokens []int, model, pod string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.forEachBlockLocked(tokens, func(hash uint64, block Block) {
+		if speculative := block.speculative[model]; speculative != nil {
+			delete(speculative, pod)
+			if len(speculative) == 0 {
+				delete(block.speculative, model)
+			}
+		}
+	})
+}
+
+// RejectPrefix drops the speculative placements of the tokens on the pod, e.g. when the request failed.
+func (c *PrefixHashTable) RejectPrefix(tokens []int, model, pod string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.forEachBlockLocked(tokens, func(hash uint64, block Block) {
+		if _, ok := block.speculative[model][pod]; ok {
+			c.removePlacementLocked(hash, block, model, pod)
+		}
+	})
+}
+
+// RemovePrefix drops the placements of the tokens on the pod, e.g. when the engine evicted them.
+func (c *PrefixHashTable) RemovePrefix(tokens []int, model, pod string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.forEachBlockLocked(tokens, func(hash uint64, block Block) {
+		c.removePlacementLocked(hash, block, model, pod)
+	})
+}
+
+func (c *PrefixHashTable) forEachBlockLocked(tokens []int, fn func(hash uint64, block Block)) {
+	for i := 0; i < len(tokens); i += prefixCacheBlockSize {
+		end := i + prefixCacheBlockSize
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+
+		_, _ = c.hash.Write(IntArrayToByteArray(tokens[i:end]))
+		prefixHash := c.hash.Sum64()
+		c.hash.ResetWithSeed(c.seed)
+		if block, ok := c.blocks[prefixHash]; ok {
+			fn(prefixHash, block)
+		}
+	}
+}
+
+func (c *PrefixHashTable) removePlacementLocked(hash uint64, block Block, model, pod string) {
+	if speculative := block.speculative[model]; speculative != nil {
+		delete(speculative, pod)
+		if len(speculative) == 0 {
+			delete(block.speculative, model)
+		}
+	}
+	if blockPods := block.modelToPods[model]; blockPods != nil {
+		delete(blockPods, pod)
+		if len(blockPods) == 0 {
+			delete(block.modelToPods, model)
+		}
+	}
+	if len(block.modelToPods) == 0 {
+		delete(c.blocks, hash)
+	}
+}
+
 func (c *PrefixHashTable) Evict(now time.Time) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -205,8 +340,131 @@ func (c *PrefixHashTable) Evict(now time.Time) {
 		if now.Sub(block.lastAccessTime) > prefixCacheEvictionDuration {
 			delete(c.blocks, hash)
 			klog.InfoS("prefix cache block evicted", "hash", hash)
+			continue
+		}
+		for model, pods := range block.speculative {
+			for pod, routedTime := range pods {
+				if now.Sub(routedTime) > prefixCacheSpeculativeTTL {
+					c.removePlacementLocked(hash, block, model, pod)
+					klog.V(4).InfoS("unconfirmed prefix cache placement expired", "hash", hash, "model", model, "pod", pod)
+				}
+			}
+		}
+	}
+}
+
+// PodOwnership returns the number of prefix blocks cached on each pod for the model.
+func (c *PrefixHashTable) PodOwnership(model string) map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ownership := map[string]int{}
+	for _, block := range c.blocks {
+		for pod := range block.modelToPods[model] {
+			ownership[pod]++
+		}
+	}
+	return ownership
+}
+
+// Stats returns the lookup counters since start and the current blocks per model and pod.
+func (c *PrefixHashTable) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats := Stats{
+		Lookups:        c.lookups.Load(),
+		Hits:           c.hits.Load(),
+		MatchedTokens:  c.matchedTokens.Load(),
+		TotalTokens:    c.totalTokens.Load(),
+		Blocks:         len(c.blocks),
+		ModelPodBlocks: map[string]map[string]int{},
+	}
+	for _, block := range c.blocks {
+		for model, pods := range block.modelToPods {
+			if _, ok := stats.ModelPodBlocks[model]; !ok {
+				stats.ModelPodBlocks[model] = map[string]int{}
+			}
+			for pod := range pods {
+				stats.ModelPodBlocks[model][pod]++
+			}
+		}
+		for _, pods := range block.speculative {
+			stats.Speculative += len(pods)
+		}
+	}
+	return stats
+}
+
+type hashTableCheckpoint struct {
+	Seed   uint64            `json:"seed"`
+	Blocks []blockCheckpoint `json:"blocks"`
+}
+
+type blockCheckpoint struct {
+	Hash        uint64                          `json:"hash"`
+	ModelToPods map[string]map[string]time.Time `json:"modelToPods"`
+	LastAccess  time.Time                       `json:"lastAccess"`
+}
+
+// Checkpoint returns the seed and the most recently accessed blocks with their confirmed placements, up to
+// AIBRIX_PREFIX_CACHE_CHECKPOINT_MAX_BLOCKS blocks. Speculative placements are not checkpointed.
+func (c *PrefixHashTable) Checkpoint() (json.RawMessage, error) {
+	c.mu.RLock()
+	checkpoint := hashTableCheckpoint{Seed: c.seed}
+	for hash, block := range c.blocks {
+		modelToPods := map[string]map[string]time.Time{}
+		for model, pods := range block.modelToPods {
+			for pod, accessTime := range pods {
+				if _, ok := block.speculative[model][pod]; ok {
+					continue
+				}
+				if modelToPods[model] == nil {
+					modelToPods[model] = map[string]time.Time{}
+				}
+				modelToPods[model][pod] = accessTime
+			}
+		}
+		if len(modelToPods) > 0 {
+			checkpoint.Blocks = append(checkpoint.Blocks, blockCheckpoint{Hash: hash, ModelToPods: modelToPods, LastAccess: block.lastAccessTime})
+		}
+	}
+	c.mu.RUnlock()
+
+	if len(checkpoint.Blocks) > prefixCacheCheckpointBlocks {
+		sort.Slice(checkpoint.Blocks, func(i, j int) bool {
+			return checkpoint.Blocks[i].LastAccess.After(checkpoint.Blocks[j].LastAccess)
+		})
+		checkpoint.Blocks = checkpoint.Blocks[:prefixCacheCheckpointBlocks]
+	}
+	return json.Marshal(checkpoint)
+}
+
+// Restore adopts the seed and the blocks of the checkpoint if the table is still empty, the blocks that would have
+// been evicted by now are dropped.
+func (c *PrefixHashTable) Restore(data json.RawMessage, age time.Duration) error {
+	var checkpoint hashTableCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.blocks) > 0 {
+		klog.InfoS("prefix cache already in use, skipping the checkpoint restore", "blocks", len(c.blocks))
+		return nil
+	}
+	now := time.Now()
+	for _, block := range checkpoint.Blocks {
+		if now.Sub(block.LastAccess) > prefixCacheEvictionDuration {
+			continue
 		}
+		c.blocks[block.Hash] = Block{modelToPods: block.ModelToPods, lastAccessTime: block.LastAccess}
 	}
+	c.seed = checkpoint.Seed
+	c.hash.ResetWithSeed(c.seed)
+	klog.InfoS("prefix cache restored from checkpoint", "blocks", len(c.blocks), "age", age)
+	return nil
 }
 
 func IntArrayToByteArray(intArray []int) []byte {
@@ -142,3 +142,84 @@ func Test_MatchPrefix(t *testing.T) {
 		assert.Equal(t, tt.matchPods, matchPods)
 	}
 }
+
+func Test_SpeculativePlacement(t *testing.T) {
+	cache := PrefixHashTable{
+		blocks: map[uint64]Block{},
+		hash:   xxhash.NewWithSeed(0),
+		seed:   0,
+	}
+	pods := []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "p1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "p2"}},
+	}
+	tokens, err := utils.TokenizeInputText("Hello World! What a Good Day! Good Morning! 你好世界！多么美好的一天啊！早上好！")
+	assert.NoError(t, err)
+
+	cache.AddPrefix(tokens, "m1", "p1")
+	assert.Equal(t, 3, cache.Stats().Speculative)
+	cache.ConfirmPrefix(tokens, "m1", "p1")
+	assert.Equal(t, 0, cache.Stats().Speculative)
+
+	cache.AddPrefix(tokens, "m1", "p2")
+	assert.Equal(t, 3, cache.Stats().Speculative)
+	cache.RejectPrefix(tokens, "m1", "p2")
+	_, _, matchPods := cache.MatchPrefix(tokens, "m1", pods)
+	assert.Equal(t, []*v1.Pod{pods[0]}, matchPods, "rejected placements are dropped")
+
+	cache.RejectPrefix(tokens, "m1", "p1")
+	_, _, matchPods = cache.MatchPrefix(tokens, "m1", pods)
+	assert.Equal(t, []*v1.Pod{pods[0]}, matchPods, "confirmed placements are kept on reject")
+
+	cache.AddPrefix(tokens, "m1", "p2")
+	cache.Evict(time.Now().Add(prefixCacheSpeculativeTTL + time.Second))
+	_, _, matchPods = cache.MatchPrefix(tokens, "m1", pods)
+	assert.Equal(t, []*v1.Pod{pods[0]}, matchPods, "unconfirmed placements expire")
+
+	cache.RemovePrefix(tokens[prefixCacheBlockSize:], "m1", "p1")
+	matchedTokens, _, _ := cache.MatchPrefix(tokens, "m1", pods)
+	assert.Len(t, matchedTokens, prefixCacheBlockSize)
+}
+
+func Test_CheckpointRestore(t *testing.T) {
+	cache := PrefixHashTable{
+		blocks: map[uint64]Block{},
+		hash:   xxhash.NewWithSeed(42),
+		seed:   42,
+	}
+	pods := []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "p1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "p2"}},
+	}
+	tokens, err := utils.TokenizeInputText("Hello World! What a Good Day! Good Morning! 你好世界！多么美好的一天啊！早上好！")
+	assert.NoError(t, err)
+	cache.AddPrefix(tokens, "m1", "p1")
+	cache.ConfirmPrefix(tokens, "m1", "p1")
+	// speculative placements are not checkpointed.
+	cache.AddPrefix(tokens, "m1", "p2")
+
+	data, err := cache.Checkpoint()
+	assert.NoError(t, err)
+
+	restored := PrefixHashTable{
+		blocks: map[uint64]Block{},
+		hash:   xxhash.NewWithSeed(7),
+		seed:   7,
+	}
+	assert.NoError(t, restored.Restore(data, time.Minute))
+	matchedTokens, _, matchPods := restored.MatchPrefix(tokens, "m1", pods)
+	assert.Equal(t, tokens, matchedTokens)
+	assert.Equal(t, []*v1.Pod{pods[0]}, matchPods)
+	assert.Equal(t, 0, restored.Stats().Speculative)
+
+	// a table already in use keeps its blocks.
+	inUse := PrefixHashTable{
+		blocks: map[uint64]Block{},
+		hash:   xxhash.NewWithSeed(7),
+		seed:   7,
+	}
+	inUse.AddPrefix(tokens[:prefixCacheBlockSize], "m1", "p2")
+	assert.NoError(t, inUse.Restore(data, time.Minute))
+	assert.Equal(t, uint64(7), inUse.seed)
+	assert.Equal(t, 1, inUse.Stats().Blocks)
+}
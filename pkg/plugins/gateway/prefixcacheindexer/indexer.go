@@ -34,3 +34,30 @@ type PrefixCacheIndexer interface {
 	// TODO: Add max blocks to cache, add LRU policy along with TTL and add performance benchmark tests.
 	Evict(now time.Time)
 }
+
+// Stats summarizes the prefix cache lookups and the blocks currently indexed.
+type Stats struct {
+	Lookups        int64
+	Hits           int64
+	MatchedTokens  int64
+	TotalTokens    int64
+	Blocks         int
+	Speculative    int                       // placements not confirmed by the engine yet
+	ModelPodBlocks map[string]map[string]int // model_name: map[pod_name]blocks
+}
+
+// PrefixFeedback is implemented by indexers recording placements speculatively at routing time. Placements are
+// confirmed or dropped once the outcome of the request or engine KV cache events are known, unconfirmed placements expire.
+type PrefixFeedback interface {
+	// ConfirmPrefix marks the placements of tokens on the pod as cached by the engine.
+	ConfirmPrefix(tokens []int, model, pod string)
+	// RejectPrefix drops the speculative placements of tokens on the pod, confirmed placements are kept.
+	RejectPrefix(tokens []int, model, pod string)
+	// RemovePrefix drops the placements of tokens on the pod, e.g. after the engine evicted them.
+	RemovePrefix(tokens []int, model, pod string)
+}
+
+// StatsProvider is implemented by indexers that expose statistics.
+type StatsProvider interface {
+	Stats() Stats
+}
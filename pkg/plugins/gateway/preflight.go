@@ -0,0 +1,313 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/metrics"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	modelAdapterGroup        = "model.aibrix.ai"
+	modelAdapterGroupVersion = "model.aibrix.ai/v1alpha1"
+	modelIdentifierLabel     = "model.aibrix.ai/name"
+	// preflightPodPort is the port the cache scrapes metrics from on inference pods.
+	preflightPodPort  = 8000
+	preflightTimeout  = 5 * time.Second
+	preflightDialWait = 2 * time.Second
+)
+
+// PreflightCheck is the result of a single preflight check.
+type PreflightCheck struct {
+	Name string `json:"name"`
+	// Critical checks must pass before the gateway starts serving traffic.
+	Critical bool   `json:"critical"`
+	Passed   bool   `json:"passed"`
+	Message  string `json:"message,omitempty"`
+}
+
+// PreflightReport aggregates all preflight checks, it is served by the readiness endpoint.
+type PreflightReport struct {
+	Ready bool `json:"ready"`
+	// Serving is true once the cache is synced and the gRPC server accepts the ext_proc calls of Envoy.
+	Serving   bool             `json:"serving"`
+	Timestamp time.Time        `json:"timestamp"`
+	Checks    []PreflightCheck `json:"checks"`
+}
+
+// Preflight validates the dependencies of the gateway plugin at startup, so misconfiguration
+// surfaces as a readable report instead of a panic from the informers or the first request.
+type Preflight struct {
+	k8sClient   kubernetes.Interface
+	redisClient redis.UniversalClient
+	// redisOptional makes the redis check non critical, when only user records, rate limits and api keys need Redis.
+	redisOptional bool
+	report        atomic.Pointer[PreflightReport]
+	serving       atomic.Bool
+}
+
+func NewPreflight(k8sClient kubernetes.Interface, redisClient redis.UniversalClient) *Preflight {
+	p := &Preflight{
+		k8sClient:   k8sClient,
+		redisClient: redisClient,
+	}
+	p.report.Store(&PreflightReport{Timestamp: time.Now()})
+	return p
+}
+
+type accessRequirement struct {
+	group    string
+	resource string
+	verb     string
+	critical bool
+	reason   string
+}
+
+var gatewayAccessRequirements = []accessRequirement{
+	{group: "", resource: "pods", verb: "list", critical: true, reason: "pod informer"},
+	{group: "", resource: "pods", verb: "watch", critical: true, reason: "pod informer"},
+	{group: "", resource: "pods", verb: "patch", critical: false, reason: "pod deletion cost"},
+	{group: "apps", resource: "deployments", verb: "list", critical: false, reason: "per model configuration"},
+	{group: "apps", resource: "deployments", verb: "watch", critical: false, reason: "per model configuration"},
+	{group: "", resource: "nodes", verb: "list", critical: false, reason: "zone aware routing"},
+	{group: "", resource: "nodes", verb: "watch", critical: false, reason: "zone aware routing"},
+	{group: modelAdapterGroup, resource: "modeladapters", verb: "list", critical: true, reason: "model adapter informer"},
+	{group: modelAdapterGroup, resource: "modeladapters", verb: "watch", critical: true, reason: "model adapter informer"},
+	{group: modelAdapterGroup, resource: "modeladapters", verb: "patch", critical: false, reason: "lora adapter activation"},
+	{group: "autoscaling.aibrix.ai", resource: "podautoscalers", verb: "list", critical: false, reason: "scale from zero"},
+	{group: "autoscaling.aibrix.ai", resource: "podautoscalers", verb: "patch", critical: false, reason: "scale from zero"},
+}
+
+// Run executes all checks and stores the report.
+func (p *Preflight) Run(ctx context.Context) *PreflightReport {
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+
+	var checks []PreflightCheck
+	for _, req := range gatewayAccessRequirements {
+		checks = append(checks, p.checkAccess(ctx, req))
+	}
+	checks = append(checks,
+		p.checkCRD(),
+		p.checkRedis(ctx),
+		p.checkPrometheus(ctx),
+		p.checkPodReachability(ctx),
+	)
+
+	report := &PreflightReport{Ready: true, Timestamp: time.Now(), Checks: checks}
+	for _, check := range checks {
+		if check.Critical && !check.Passed {
+			report.Ready = false
+		}
+	}
+	p.report.Store(report)
+	return report
+}
+
+// Report returns the latest preflight report.
+func (p *Preflight) Report() *PreflightReport {
+	return p.report.Load()
+}
+
+// SetServing marks the gateway as serving, once the cache is synced and right before the gRPC server accepts
+// connections.
+func (p *Preflight) SetServing() {
+	p.serving.Store(true)
+}
+
+// ServeGRPC marks the gateway as serving and serves s on lis, which already accepts the connections of Envoy, so
+// that the readiness endpoint never reports ready while the ext_proc calls are refused. The cache must be synced.
+func (p *Preflight) ServeGRPC(s *grpc.Server, lis net.Listener) error {
+	p.SetServing()
+	return s.Serve(lis)
+}
+
+// ServeHTTP serves the latest report, responding 503 until all critical checks pass and the gateway is serving.
+func (p *Preflight) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := *p.Report()
+	report.Serving = p.serving.Load()
+	w.Header().Set("Content-Type", "application/json")
+	if !report.Ready || !report.Serving {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		klog.ErrorS(err, "failed to encode preflight report")
+	}
+}
+
+// LogReport logs every check of the report, failures are logged as errors.
+func LogReport(report *PreflightReport) {
+	for _, check := range report.Checks {
+		if check.Passed {
+			klog.InfoS("preflight check passed", "check", check.Name, "message", check.Message)
+		} else if check.Critical {
+			klog.ErrorS(nil, "preflight check failed", "check", check.Name, "message", check.Message)
+		} else {
+			klog.Warningf("preflight check %s failed (non-critical): %s", check.Name, check.Message)
+		}
+	}
+	klog.InfoS("preflight finished", "ready", report.Ready)
+}
+
+func (p *Preflight) checkAccess(ctx context.Context, req accessRequirement) PreflightCheck {
+	resource := req.resource
+	if req.group != "" {
+		resource = req.resource + "." + req.group
+	}
+	check := PreflightCheck{Name: fmt.Sprintf("rbac/%s/%s", resource, req.verb), Critical: req.critical}
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group:    req.group,
+				Resource: req.resource,
+				Verb:     req.verb,
+			},
+		},
+	}
+	result, err := p.k8sClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		check.Message = fmt.Sprintf("failed to review access: %v", err)
+		return check
+	}
+	if !result.Status.Allowed {
+		check.Message = fmt.Sprintf("service account is not allowed to %s %s cluster-wide, required by %s", req.verb, resource, req.reason)
+		return check
+	}
+	check.Passed = true
+	return check
+}
+
+func (p *Preflight) checkCRD() PreflightCheck {
+	check := PreflightCheck{Name: "crd/modeladapters", Critical: true}
+	resources, err := p.k8sClient.Discovery().ServerResourcesForGroupVersion(modelAdapterGroupVersion)
+	if err != nil {
+		check.Message = fmt.Sprintf("failed to discover %s, is the ModelAdapter CRD installed: %v", modelAdapterGroupVersion, err)
+		return check
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "modeladapters" {
+			check.Passed = true
+			return check
+		}
+	}
+	check.Message = fmt.Sprintf("modeladapters is not served by %s, is the ModelAdapter CRD installed", modelAdapterGroupVersion)
+	return check
+}
+
+// RedisOptional lets the gateway start without Redis, when the kv store of the gateway is not Redis.
+func (p *Preflight) RedisOptional() {
+	p.redisOptional = true
+}
+
+func (p *Preflight) checkRedis(ctx context.Context) PreflightCheck {
+	check := PreflightCheck{Name: "redis", Critical: !p.redisOptional}
+	if p.redisClient == nil {
+		check.Message = "redis client is not configured"
+		return check
+	}
+	if err := p.redisClient.Ping(ctx).Err(); err != nil {
+		check.Message = fmt.Sprintf("failed to ping redis: %v", err)
+		return check
+	}
+	check.Passed = true
+	return check
+}
+
+func (p *Preflight) checkPrometheus(ctx context.Context) PreflightCheck {
+	check := PreflightCheck{Name: "prometheus", Critical: false}
+	endpoint := utils.LoadEnv("PROMETHEUS_ENDPOINT", "")
+	if endpoint == "" {
+		check.Passed = true
+		check.Message = "PROMETHEUS_ENDPOINT is not configured, skipped"
+		return check
+	}
+	api, err := metrics.InitializePrometheusAPI(endpoint,
+		utils.LoadEnv("PROMETHEUS_BASIC_AUTH_USERNAME", ""), utils.LoadEnv("PROMETHEUS_BASIC_AUTH_PASSWORD", ""))
+	if err != nil {
+		check.Message = err.Error()
+		return check
+	}
+	if _, err := api.Buildinfo(ctx); err != nil {
+		check.Message = fmt.Sprintf("failed to query prometheus at %s: %v", endpoint, err)
+		return check
+	}
+	check.Passed = true
+	return check
+}
+
+func (p *Preflight) checkPodReachability(ctx context.Context) PreflightCheck {
+	check := PreflightCheck{Name: "pod-reachability", Critical: false}
+	podList, err := p.k8sClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: modelIdentifierLabel})
+	if err != nil {
+		check.Message = fmt.Sprintf("failed to list model pods: %v", err)
+		return check
+	}
+
+	var sample *v1.Pod
+	for i := range podList.Items {
+		if utils.IsPodReady(&podList.Items[i]) && podList.Items[i].Status.PodIP != "" {
+			sample = &podList.Items[i]
+			break
+		}
+	}
+	if sample == nil {
+		check.Passed = true
+		check.Message = "no ready model pods found, skipped"
+		return check
+	}
+
+	addr := net.JoinHostPort(sample.Status.PodIP, strconv.Itoa(preflightPodPort))
+	conn, err := net.DialTimeout("tcp", addr, preflightDialWait)
+	if err != nil {
+		check.Message = fmt.Sprintf("failed to reach pod %s/%s at %s: %v", sample.Namespace, sample.Name, addr, err)
+		return check
+	}
+	_ = conn.Close()
+	check.Passed = true
+	check.Message = fmt.Sprintf("reached pod %s/%s at %s", sample.Namespace, sample.Name, addr)
+	return check
+}
+
+// FailedChecks returns the names of failed checks, used for concise logging.
+func (r *PreflightReport) FailedChecks() string {
+	var failed []string
+	for _, check := range r.Checks {
+		if !check.Passed {
+			failed = append(failed, check.Name)
+		}
+	}
+	return strings.Join(failed, ",")
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthPb "google.golang.org/grpc/health/grpc_health_v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newPreflightClient(allowed func(attrs *authorizationv1.ResourceAttributes) bool) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = allowed(review.Spec.ResourceAttributes)
+		return true, review, nil
+	})
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: modelAdapterGroupVersion,
+		APIResources: []metav1.APIResource{{Name: "modeladapters"}},
+	}}
+	return client
+}
+
+func findCheck(report *PreflightReport, name string) PreflightCheck {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	return PreflightCheck{}
+}
+
+func TestPreflightRBAC(t *testing.T) {
+	client := newPreflightClient(func(attrs *authorizationv1.ResourceAttributes) bool {
+		return attrs.Verb != "watch"
+	})
+	report := NewPreflight(client, nil).Run(context.Background())
+
+	assert.False(t, report.Ready)
+	assert.True(t, findCheck(report, "rbac/pods/list").Passed)
+	assert.False(t, findCheck(report, "rbac/pods/watch").Passed)
+	assert.False(t, findCheck(report, "rbac/modeladapters.model.aibrix.ai/watch").Passed)
+	assert.True(t, findCheck(report, "crd/modeladapters").Passed)
+	assert.False(t, findCheck(report, "redis").Passed, "nil redis client must fail")
+	assert.True(t, findCheck(report, "pod-reachability").Passed, "no model pods is not a failure")
+}
+
+func TestPreflightMissingCRD(t *testing.T) {
+	client := newPreflightClient(func(attrs *authorizationv1.ResourceAttributes) bool { return true })
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = nil
+	report := NewPreflight(client, nil).Run(context.Background())
+
+	check := findCheck(report, "crd/modeladapters")
+	assert.False(t, check.Passed)
+	assert.True(t, check.Critical)
+	assert.Contains(t, check.Message, "ModelAdapter CRD")
+}
+
+func TestPreflightServeHTTP(t *testing.T) {
+	p := NewPreflight(nil, nil)
+	recorder := httptest.NewRecorder()
+	p.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "not ready before preflight runs")
+
+	p.report.Store(&PreflightReport{Ready: true})
+	recorder = httptest.NewRecorder()
+	p.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "not ready before the gRPC server is serving")
+	assert.Contains(t, recorder.Body.String(), `"serving":false`)
+
+	p.SetServing()
+	recorder = httptest.NewRecorder()
+	p.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"ready":true`)
+	assert.Contains(t, recorder.Body.String(), `"serving":true`)
+}
+
+func TestPreflightServingBeforeSucceededPreflight(t *testing.T) {
+	p := NewPreflight(nil, nil)
+	p.SetServing()
+	recorder := httptest.NewRecorder()
+	p.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "not ready until the critical checks pass")
+}
+
+func TestPreflightReadyOnceGRPCServing(t *testing.T) {
+	p := NewPreflight(nil, nil)
+	p.report.Store(&PreflightReport{Ready: true})
+	readyz := func() int {
+		recorder := httptest.NewRecorder()
+		p.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return recorder.Code
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	healthPb.RegisterHealthServer(s, &HealthServer{})
+	defer s.Stop()
+	assert.Equal(t, http.StatusServiceUnavailable, readyz(), "not ready before the gRPC server is serving")
+
+	go func() { _ = p.ServeGRPC(s, lis) }()
+	require.Eventually(t, func() bool { return readyz() == http.StatusOK }, 5*time.Second, 10*time.Millisecond)
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := healthPb.NewHealthClient(conn).Check(ctx, &healthPb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthPb.HealthCheckResponse_SERVING, resp.Status)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const anthropicVersion = "2023-06-01"
+
+// anthropic is the Messages API of Anthropic.
+type anthropic struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func (p *anthropic) ChatCompletion(ctx context.Context, body map[string]interface{}, credentials Credentials) ([]byte, Usage, error) {
+	chat, err := parseChatRequest(p.model, body)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+	messages := make([]map[string]interface{}, 0, len(chat.messages))
+	for _, message := range chat.messages {
+		messages = append(messages, map[string]interface{}{"role": message.role, "content": message.text})
+	}
+	request := map[string]interface{}{
+		"model":      chat.model,
+		"messages":   messages,
+		"max_tokens": chat.maxTokens,
+	}
+	if len(chat.system) > 0 {
+		request["system"] = strings.Join(chat.system, "\n\n")
+	}
+	if chat.temperature != nil {
+		request["temperature"] = *chat.temperature
+	}
+	if chat.topP != nil {
+		request["top_p"] = *chat.topP
+	}
+	if len(chat.stop) > 0 {
+		request["stop_sequences"] = chat.stop
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+	header := http.Header{}
+	header.Set("x-api-key", credentials[CredentialAPIKey])
+	header.Set("anthropic-version", anthropicVersion)
+	response, err := post(ctx, p.client, p.baseURL+"/v1/messages", data, header, nil)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+
+	var message struct {
+		ID      string `json:"id"`
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int64 `json:"input_tokens"`
+			OutputTokens int64 `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(response, &message); err != nil {
+		return nil, Usage{}, fmt.Errorf("invalid anthropic message: %w", err)
+	}
+	var text strings.Builder
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	usage := Usage{PromptTokens: message.Usage.InputTokens, CompletionTokens: message.Usage.OutputTokens}
+	completion, err := chatCompletion(message.ID, message.Model, text.String(), finishReason(message.StopReason), usage)
+	return completion, usage, err
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const bedrockService = "bedrock"
+
+// bedrock is the Converse API of Amazon Bedrock, the requests are signed with AWS Signature Version 4.
+type bedrock struct {
+	baseURL string
+	region  string
+	model   string
+	client  *http.Client
+	// now is the signing time.
+	now func() time.Time
+}
+
+func (p *bedrock) ChatCompletion(ctx context.Context, body map[string]interface{}, credentials Credentials) ([]byte, Usage, error) {
+	chat, err := parseChatRequest(p.model, body)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+	if credentials[CredentialAWSAccessKeyID] == "" || credentials[CredentialAWSSecretAccessKey] == "" {
+		return nil, Usage{}, fmt.Errorf("bedrock requires %s and %s credentials", CredentialAWSAccessKeyID, CredentialAWSSecretAccessKey)
+	}
+	messages := make([]map[string]interface{}, 0, len(chat.messages))
+	for _, message := range chat.messages {
+		messages = append(messages, map[string]interface{}{
+			"role":    message.role,
+			"content": []map[string]string{{"text": message.text}},
+		})
+	}
+	inference := map[string]interface{}{"maxTokens": chat.maxTokens}
+	if chat.temperature != nil {
+		inference["temperature"] = *chat.temperature
+	}
+	if chat.topP != nil {
+		inference["topP"] = *chat.topP
+	}
+	if len(chat.stop) > 0 {
+		inference["stopSequences"] = chat.stop
+	}
+	request := map[string]interface{}{"messages": messages, "inferenceConfig": inference}
+	if len(chat.system) > 0 {
+		system := make([]map[string]string, 0, len(chat.system))
+		for _, text := range chat.system {
+			system = append(system, map[string]string{"text": text})
+		}
+		request["system"] = system
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+	url := p.baseURL + "/model/" + awsEscape(chat.model) + "/converse"
+	sign := func(req *http.Request, payload []byte) error {
+		now := time.Now
+		if p.now != nil {
+			now = p.now
+		}
+		signV4(req, payload, credentials, p.region, bedrockService, now())
+		return nil
+	}
+	response, err := post(ctx, p.client, url, data, nil, sign)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+
+	var converse struct {
+		Output struct {
+			Message struct {
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"message"`
+		} `json:"output"`
+		StopReason string `json:"stopReason"`
+		Usage      struct {
+			InputTokens  int64 `json:"inputTokens"`
+			OutputTokens int64 `json:"outputTokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(response, &converse); err != nil {
+		return nil, Usage{}, fmt.Errorf("invalid bedrock response: %w", err)
+	}
+	var text strings.Builder
+	for _, block := range converse.Output.Message.Content {
+		text.WriteString(block.Text)
+	}
+	usage := Usage{PromptTokens: converse.Usage.InputTokens, CompletionTokens: converse.Usage.OutputTokens}
+	completion, err := chatCompletion("chatcmpl-"+uuid.New().String(), chat.model, text.String(), finishReason(converse.StopReason), usage)
+	return completion, usage, err
+}
+
+// awsEscape encodes everything but the unreserved characters, as AWS expects in paths.
+func awsEscape(value string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(value) {
+		if 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' || b == '-' || b == '_' || b == '.' || b == '~' {
+			escaped.WriteByte(b)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+// signV4 signs the request with AWS Signature Version 4, the host and all the headers of the request are signed.
+func signV4(req *http.Request, payload []byte, credentials Credentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if token := credentials[CredentialAWSSessionToken]; token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	// the path is encoded twice, every service but S3 expects it.
+	segments := strings.Split(req.URL.EscapedPath(), "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+	}
+	canonicalURI := strings.Join(segments, "/")
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		req.Method, canonicalURI, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+	key := hmacSHA256([]byte("AWS4"+credentials[CredentialAWSSecretAccessKey]), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials[CredentialAWSAccessKeyID], scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
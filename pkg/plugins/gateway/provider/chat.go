@@ -0,0 +1,159 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultMaxTokens is sent to the APIs requiring a max number of output tokens when the request has none.
+const defaultMaxTokens = 4096
+
+// chatRequest is an OpenAI chat completion reduced to what the APIs that are not OpenAI compatible are sent: the text
+// of the messages and the sampling parameters.
+type chatRequest struct {
+	model  string
+	system []string
+	// messages alternate between the user and assistant roles, consecutive messages of a role are merged.
+	messages    []chatMessage
+	maxTokens   int64
+	temperature *float64
+	topP        *float64
+	stop        []string
+}
+
+type chatMessage struct {
+	role string
+	text string
+}
+
+// parseChatRequest reads the chat completion, tools and non text content are ErrUnsupported.
+func parseChatRequest(routeModel string, body map[string]interface{}) (chatRequest, error) {
+	request := chatRequest{model: modelOf(routeModel, body), maxTokens: defaultMaxTokens}
+	for _, key := range []string{"tools", "functions", "tool_choice", "n", "logprobs"} {
+		if _, ok := body[key]; ok {
+			return chatRequest{}, fmt.Errorf("%w: %s", ErrUnsupported, key)
+		}
+	}
+	messages, _ := body["messages"].([]interface{})
+	for _, item := range messages {
+		message, _ := item.(map[string]interface{})
+		role, _ := message["role"].(string)
+		text, err := messageText(message["content"])
+		if err != nil {
+			return chatRequest{}, err
+		}
+		switch role {
+		case "system", "developer":
+			request.system = append(request.system, text)
+		case "user", "assistant":
+			if last := len(request.messages) - 1; last >= 0 && request.messages[last].role == role {
+				request.messages[last].text += "\n\n" + text
+			} else {
+				request.messages = append(request.messages, chatMessage{role: role, text: text})
+			}
+		default:
+			return chatRequest{}, fmt.Errorf("%w: %s message", ErrUnsupported, role)
+		}
+	}
+	if len(request.messages) == 0 {
+		return chatRequest{}, fmt.Errorf("%w: no user message", ErrUnsupported)
+	}
+	for _, key := range []string{"max_completion_tokens", "max_tokens"} {
+		if value, ok := body[key].(float64); ok && value > 0 {
+			request.maxTokens = int64(value)
+			break
+		}
+	}
+	if value, ok := body["temperature"].(float64); ok {
+		request.temperature = &value
+	}
+	if value, ok := body["top_p"].(float64); ok {
+		request.topP = &value
+	}
+	switch stop := body["stop"].(type) {
+	case string:
+		request.stop = []string{stop}
+	case []interface{}:
+		for _, item := range stop {
+			if text, ok := item.(string); ok {
+				request.stop = append(request.stop, text)
+			}
+		}
+	}
+	return request, nil
+}
+
+// messageText returns the text of a message content, a string or text parts.
+func messageText(content interface{}) (string, error) {
+	switch content := content.(type) {
+	case string:
+		return content, nil
+	case []interface{}:
+		var texts []string
+		for _, item := range content {
+			part, _ := item.(map[string]interface{})
+			if part["type"] != "text" {
+				return "", fmt.Errorf("%w: %v content", ErrUnsupported, part["type"])
+			}
+			text, _ := part["text"].(string)
+			texts = append(texts, text)
+		}
+		return strings.Join(texts, ""), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("%w: content of type %T", ErrUnsupported, content)
+	}
+}
+
+// chatCompletion encodes the OpenAI chat completion of the text generated by a provider.
+func chatCompletion(id, model, text, finishReason string, usage Usage) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"id":      id,
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   model,
+		"choices": []map[string]interface{}{{
+			"index":         0,
+			"message":       map[string]interface{}{"role": "assistant", "content": text},
+			"finish_reason": finishReason,
+		}},
+		"usage": map[string]interface{}{
+			"prompt_tokens":     usage.PromptTokens,
+			"completion_tokens": usage.CompletionTokens,
+			"total_tokens":      usage.PromptTokens + usage.CompletionTokens,
+		},
+	})
+}
+
+// finishReason maps the stop reasons of Anthropic and Bedrock to the OpenAI finish reasons.
+func finishReason(stopReason string) string {
+	switch stopReason {
+	case "max_tokens":
+		return "length"
+	case "tool_use":
+		return "tool_calls"
+	case "content_filtered", "guardrail_intervened":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
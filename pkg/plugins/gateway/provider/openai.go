@@ -0,0 +1,62 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+)
+
+// openAI is the OpenAI API or a compatible one, the requests are sent as is.
+type openAI struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func (p *openAI) ChatCompletion(ctx context.Context, body map[string]interface{}, credentials Credentials) ([]byte, Usage, error) {
+	request := maps.Clone(body)
+	request["model"] = modelOf(p.model, body)
+	request["stream"] = false
+	delete(request, "stream_options")
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+	header := http.Header{}
+	if key := credentials[CredentialAPIKey]; key != "" {
+		header.Set("Authorization", "Bearer "+key)
+	}
+	response, err := post(ctx, p.client, p.baseURL+"/chat/completions", data, header, nil)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+
+	var completion struct {
+		Usage struct {
+			PromptTokens     int64 `json:"prompt_tokens"`
+			CompletionTokens int64 `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(response, &completion); err != nil {
+		return nil, Usage{}, fmt.Errorf("invalid chat completion: %w", err)
+	}
+	return response, Usage{PromptTokens: completion.Usage.PromptTokens, CompletionTokens: completion.Usage.CompletionTokens}, nil
+}
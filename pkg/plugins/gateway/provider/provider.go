@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package provider sends OpenAI chat completions to external hosted APIs, translating the requests and the responses
+// of the APIs that are not OpenAI compatible.
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+const (
+	TypeOpenAI    = "openai"
+	TypeAnthropic = "anthropic"
+	TypeBedrock   = "bedrock"
+
+	// the entries of the credentials Secret.
+	CredentialAPIKey             = "api-key"
+	CredentialAWSAccessKeyID     = "aws-access-key-id"
+	CredentialAWSSecretAccessKey = "aws-secret-access-key"
+	CredentialAWSSessionToken    = "aws-session-token"
+
+	defaultTimeout = 60 * time.Second
+	// maxResponseBytes bounds the responses read from the providers.
+	maxResponseBytes = 16 << 20
+	// maxErrorBytes bounds the response bodies quoted in errors.
+	maxErrorBytes = 512
+)
+
+// ErrUnsupported is returned for the requests the provider can't translate, e.g. with tools.
+var ErrUnsupported = errors.New("unsupported request")
+
+// Credentials are the entries of the credentials Secret of a provider.
+type Credentials map[string]string
+
+// Usage is the tokens the provider billed for a request.
+type Usage struct {
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// Provider is an external hosted API.
+type Provider interface {
+	// ChatCompletion sends the OpenAI chat completion request and returns the OpenAI chat completion response, never
+	// streamed, and its usage.
+	ChatCompletion(ctx context.Context, body map[string]interface{}, credentials Credentials) ([]byte, Usage, error)
+}
+
+// New builds the provider of the route.
+func New(route config.ProviderConfig) (Provider, error) {
+	client := &http.Client{Timeout: Timeout(route)}
+	switch route.Type {
+	case TypeOpenAI:
+		return &openAI{baseURL: baseURL(route.BaseURL, "https://api.openai.com/v1"), model: route.Model, client: client}, nil
+	case TypeAnthropic:
+		return &anthropic{baseURL: baseURL(route.BaseURL, "https://api.anthropic.com"), model: route.Model, client: client}, nil
+	case TypeBedrock:
+		if route.Region == "" {
+			return nil, fmt.Errorf("provider %s: bedrock requires a region", route.Name)
+		}
+		defaultURL := fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", route.Region)
+		return &bedrock{baseURL: baseURL(route.BaseURL, defaultURL), region: route.Region, model: route.Model, client: client}, nil
+	default:
+		return nil, fmt.Errorf("provider %s: unknown type %q, must be one of %s, %s or %s", route.Name, route.Type, TypeOpenAI, TypeAnthropic, TypeBedrock)
+	}
+}
+
+// Timeout returns how long the requests to the provider of the route may take.
+func Timeout(route config.ProviderConfig) time.Duration {
+	if route.Timeout != nil {
+		return route.Timeout.Duration
+	}
+	return defaultTimeout
+}
+
+func baseURL(value, defaultValue string) string {
+	if value == "" {
+		value = defaultValue
+	}
+	return strings.TrimSuffix(value, "/")
+}
+
+// modelOf returns the model the request is sent to, the model of the route or else of the request.
+func modelOf(routeModel string, body map[string]interface{}) string {
+	if routeModel != "" {
+		return routeModel
+	}
+	model, _ := body["model"].(string)
+	return model
+}
+
+// post sends the JSON body and returns the response body, non 2xx responses are errors.
+func post(ctx context.Context, client *http.Client, url string, body []byte, header http.Header, sign func(*http.Request, []byte) error) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if sign != nil {
+		if err := sign(req, body); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if len(data) > maxErrorBytes {
+			data = data[:maxErrorBytes]
+		}
+		return nil, fmt.Errorf("provider returned %d: %s", resp.StatusCode, data)
+	}
+	return data, nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+)
+
+func chatBody(t *testing.T, data string) map[string]interface{} {
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &body))
+	return body
+}
+
+// serve returns a server answering with the response and recording the last request.
+func serve(t *testing.T, response string, requests *[]*http.Request, bodies *[]map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &body))
+		*requests = append(*requests, r)
+		*bodies = append(*bodies, body)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOpenAI(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := serve(t, `{"id": "chatcmpl-1", "choices": [], "usage": {"prompt_tokens": 12, "completion_tokens": 3}}`, &requests, &bodies)
+	p, err := New(config.ProviderConfig{Name: "openai", Type: TypeOpenAI, BaseURL: server.URL + "/v1/", Model: "gpt-4o-mini"})
+	require.NoError(t, err)
+
+	body := chatBody(t, `{"model": "llama", "stream": true, "stream_options": {"include_usage": true}, "messages": [{"role": "user", "content": "hi"}]}`)
+	response, usage, err := p.ChatCompletion(context.Background(), body, Credentials{CredentialAPIKey: "sk-test"})
+	require.NoError(t, err)
+	assert.Contains(t, string(response), "chatcmpl-1")
+	assert.Equal(t, Usage{PromptTokens: 12, CompletionTokens: 3}, usage)
+	assert.Equal(t, "/v1/chat/completions", requests[0].URL.Path)
+	assert.Equal(t, "Bearer sk-test", requests[0].Header.Get("Authorization"))
+	assert.Equal(t, "gpt-4o-mini", bodies[0]["model"])
+	assert.Equal(t, false, bodies[0]["stream"])
+	assert.NotContains(t, bodies[0], "stream_options")
+	assert.Equal(t, "llama", body["model"], "the request is not modified")
+}
+
+func TestAnthropic(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := serve(t, `{
+		"id": "msg_1", "model": "claude-3-5-haiku", "stop_reason": "max_tokens",
+		"content": [{"type": "text", "text": "Hello"}, {"type": "text", "text": " there"}],
+		"usage": {"input_tokens": 20, "output_tokens": 5}
+	}`, &requests, &bodies)
+	p, err := New(config.ProviderConfig{Name: "claude", Type: TypeAnthropic, BaseURL: server.URL, Model: "claude-3-5-haiku"})
+	require.NoError(t, err)
+
+	body := chatBody(t, `{"model": "llama", "max_tokens": 64, "temperature": 0.2, "stop": "END", "messages": [
+		{"role": "system", "content": "Be brief."},
+		{"role": "user", "content": [{"type": "text", "text": "Hi"}]},
+		{"role": "user", "content": "there"}
+	]}`)
+	response, usage, err := p.ChatCompletion(context.Background(), body, Credentials{CredentialAPIKey: "sk-ant"})
+	require.NoError(t, err)
+	assert.Equal(t, Usage{PromptTokens: 20, CompletionTokens: 5}, usage)
+	assert.Equal(t, "/v1/messages", requests[0].URL.Path)
+	assert.Equal(t, "sk-ant", requests[0].Header.Get("x-api-key"))
+	assert.Equal(t, anthropicVersion, requests[0].Header.Get("anthropic-version"))
+	assert.Equal(t, map[string]interface{}{
+		"model":          "claude-3-5-haiku",
+		"system":         "Be brief.",
+		"messages":       []interface{}{map[string]interface{}{"role": "user", "content": "Hi\n\nthere"}},
+		"max_tokens":     float64(64),
+		"temperature":    0.2,
+		"stop_sequences": []interface{}{"END"},
+	}, bodies[0])
+
+	completion := chatBody(t, string(response))
+	assert.Equal(t, "chat.completion", completion["object"])
+	choice := completion["choices"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Hello there", choice["message"].(map[string]interface{})["content"])
+	assert.Equal(t, "length", choice["finish_reason"])
+	assert.Equal(t, float64(25), completion["usage"].(map[string]interface{})["total_tokens"])
+
+	_, _, err = p.ChatCompletion(context.Background(), chatBody(t, `{"tools": [], "messages": [{"role": "user", "content": "hi"}]}`), nil)
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	_, _, err = p.ChatCompletion(context.Background(), chatBody(t, `{"messages": [{"role": "user", "content": [{"type": "image_url"}]}]}`), nil)
+	assert.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestBedrock(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := serve(t, `{
+		"output": {"message": {"role": "assistant", "content": [{"text": "Hello"}]}},
+		"stopReason": "end_turn", "usage": {"inputTokens": 8, "outputTokens": 2}
+	}`, &requests, &bodies)
+	p, err := New(config.ProviderConfig{Name: "bedrock", Type: TypeBedrock, BaseURL: server.URL, Region: "us-east-1", Model: "anthropic.claude-3-haiku-20240307-v1:0"})
+	require.NoError(t, err)
+
+	body := chatBody(t, `{"model": "llama", "messages": [{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}]}`)
+	credentials := Credentials{CredentialAWSAccessKeyID: "AKID", CredentialAWSSecretAccessKey: "secret", CredentialAWSSessionToken: "token"}
+	response, usage, err := p.ChatCompletion(context.Background(), body, credentials)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{PromptTokens: 8, CompletionTokens: 2}, usage)
+	assert.Equal(t, "/model/anthropic.claude-3-haiku-20240307-v1%3A0/converse", requests[0].URL.EscapedPath())
+	assert.True(t, strings.HasPrefix(requests[0].Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+	assert.Contains(t, requests[0].Header.Get("Authorization"), "/us-east-1/bedrock/aws4_request")
+	assert.Equal(t, "token", requests[0].Header.Get("X-Amz-Security-Token"))
+	assert.Equal(t, map[string]interface{}{
+		"system":          []interface{}{map[string]interface{}{"text": "Be brief."}},
+		"messages":        []interface{}{map[string]interface{}{"role": "user", "content": []interface{}{map[string]interface{}{"text": "Hi"}}}},
+		"inferenceConfig": map[string]interface{}{"maxTokens": float64(defaultMaxTokens)},
+	}, bodies[0])
+	choice := chatBody(t, string(response))["choices"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Hello", choice["message"].(map[string]interface{})["content"])
+	assert.Equal(t, "stop", choice["finish_reason"])
+
+	_, _, err = p.ChatCompletion(context.Background(), body, nil)
+	assert.Error(t, err, "bedrock requires credentials")
+	_, err = New(config.ProviderConfig{Name: "bedrock", Type: TypeBedrock})
+	assert.Error(t, err, "bedrock requires a region")
+	_, err = New(config.ProviderConfig{Name: "unknown", Type: "cohere"})
+	assert.Error(t, err)
+}
+
+func TestSignV4(t *testing.T) {
+	// the get-vanilla case of the AWS Signature Version 4 test suite.
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	credentials := Credentials{CredentialAWSAccessKeyID: "AKIDEXAMPLE", CredentialAWSSecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, credentials, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, "+
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31", req.Header.Get("Authorization"))
+}
+
+func TestProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "rate limited"}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	p, err := New(config.ProviderConfig{Name: "openai", Type: TypeOpenAI, BaseURL: server.URL})
+	require.NoError(t, err)
+	_, _, err = p.ChatCompletion(context.Background(), chatBody(t, `{"messages": []}`), nil)
+	assert.ErrorContains(t, err, "429")
+}
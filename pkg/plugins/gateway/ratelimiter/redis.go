@@ -28,13 +28,13 @@ import (
 const binSize = 64
 
 type redisRateLimiter struct {
-	client     *redis.Client
+	client     redis.UniversalClient
 	name       string
 	windowSize time.Duration
 }
 
 // NewRedisAccountRateLimiter is a simple fixed window rate limiter
-func NewRedisAccountRateLimiter(name string, client *redis.Client, windowSize time.Duration) RateLimiter {
+func NewRedisAccountRateLimiter(name string, client redis.UniversalClient, windowSize time.Duration) RateLimiter {
 	if windowSize < time.Second {
 		windowSize = time.Second
 	}
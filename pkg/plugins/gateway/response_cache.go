@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/redis/go-redis/v9"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/middleware"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/responsecache"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// HeaderResponseCache is hit for responses served from the cache and miss for cacheable ones that were not.
+	HeaderResponseCache = "x-aibrix-cache"
+	// HeaderResponseCacheMatch is exact or semantic for cache hits.
+	HeaderResponseCacheMatch = "x-aibrix-cache-match"
+	// HeaderResponseCacheSimilarity is the similarity of the prompt to the cached one for cache hits.
+	HeaderResponseCacheSimilarity = "x-aibrix-cache-similarity"
+
+	// the OpenAI-compatible embeddings endpoint and model embedding the prompts of semantic matches.
+	EnvResponseCacheEmbeddingURL     = "AIBRIX_RESPONSE_CACHE_EMBEDDING_URL"
+	EnvResponseCacheEmbeddingModel   = "AIBRIX_RESPONSE_CACHE_EMBEDDING_MODEL"
+	EnvResponseCacheEmbeddingTimeout = "AIBRIX_RESPONSE_CACHE_EMBEDDING_TIMEOUT_MS"
+
+	defaultResponseCacheEmbeddingTimeout = time.Second
+	// responseCacheStoreTimeout bounds the write of a response, done once the response is returned.
+	responseCacheStoreTimeout = 5 * time.Second
+)
+
+// loadResponseCache creates the response cache in the kv store of the gateway. Semantic matches need Redis with the
+// search module and an embedding model, models configured for them are matched exactly otherwise.
+func loadResponseCache(redisClient redis.UniversalClient) *responsecache.Cache {
+	backend := utils.LoadEnv("AIBRIX_KV_STORE", kvstore.BackendRedis)
+	store, err := kvstore.New(backend, redisClient)
+	if err != nil {
+		klog.ErrorS(err, "failed to create the response cache store, responses are not cached")
+		return nil
+	}
+
+	var index responsecache.VectorIndex
+	var embedder responsecache.Embedder
+	if url := utils.LoadEnv(EnvResponseCacheEmbeddingURL, ""); url != "" {
+		if backend != kvstore.BackendRedis {
+			klog.InfoS("semantic response cache requires the redis kv store, prompts are matched exactly", "backend", backend)
+		} else {
+			index = responsecache.NewRedisIndex(redisClient)
+			embedder = responsecache.NewHTTPEmbedder(url, utils.LoadEnv(EnvResponseCacheEmbeddingModel, ""), getResponseCacheEmbeddingTimeout())
+		}
+	}
+	return responsecache.New(store, index, embedder)
+}
+
+func getResponseCacheEmbeddingTimeout() time.Duration {
+	value := utils.LoadEnv(EnvResponseCacheEmbeddingTimeout, "")
+	if value == "" {
+		return defaultResponseCacheEmbeddingTimeout
+	}
+	timeout, err := strconv.Atoi(value)
+	if err != nil || timeout <= 0 {
+		klog.Warningf("invalid %s: %s, falling back to default", EnvResponseCacheEmbeddingTimeout, value)
+		return defaultResponseCacheEmbeddingTimeout
+	}
+	return time.Duration(timeout) * time.Millisecond
+}
+
+// responseCacheMiss is a cacheable request that missed the cache, its response is stored once complete.
+type responseCacheMiss struct {
+	entry   *responsecache.Entry
+	options responsecache.Options
+}
+
+type responseCacheMissKey struct{}
+
+// withResponseCacheMiss holds the cache miss of the request, recorded by the request body phase for the response
+// phases.
+func withResponseCacheMiss(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseCacheMissKey{}, &responseCacheMiss{})
+}
+
+func responseCacheMissFrom(ctx context.Context) *responseCacheMiss {
+	miss, _ := ctx.Value(responseCacheMissKey{}).(*responseCacheMiss)
+	return miss
+}
+
+// lookupResponseCache answers the request from the responses cached for the tenant of the user and the model, nil if
+// it must be served by the engine. Lookup failures are logged and served by the engine.
+func (s *Server) lookupResponseCache(ctx context.Context, requestID string, user utils.User, model, externalModel, endpoint string, modelConfig ModelConfig, body map[string]interface{}) *extProcPb.ProcessingResponse {
+	if s.responseCache == nil || modelConfig.ResponseCache.Mode == "" {
+		return nil
+	}
+	entry, ok := responsecache.NewEntry(user.TenantID(), model, endpoint, body)
+	if !ok {
+		return nil
+	}
+	match, err := s.responseCache.Lookup(ctx, model, entry, modelConfig.ResponseCache)
+	if err != nil {
+		klog.ErrorS(err, "failed to look up the response cache", "requestID", requestID, "model", model)
+	}
+	if match == nil {
+		if miss := responseCacheMissFrom(ctx); miss != nil {
+			miss.entry, miss.options = entry, modelConfig.ResponseCache
+		}
+		return nil
+	}
+
+	klog.InfoS("response served from cache", "requestID", requestID, "model", model, "match", match.Type, "similarity", match.Similarity)
+	return s.completedResponse(ctx, requestID, model, externalModel, endpoint, match.Body, []*configPb.HeaderValueOption{
+		{Header: &configPb.HeaderValue{Key: HeaderResponseCache, RawValue: []byte("hit")}},
+		{Header: &configPb.HeaderValue{Key: HeaderResponseCacheMatch, RawValue: []byte(match.Type)}},
+		{Header: &configPb.HeaderValue{Key: HeaderResponseCacheSimilarity, RawValue: []byte(strconv.FormatFloat(match.Similarity, 'f', 4, 64))}},
+	})
+}
+
+// completedResponse answers the request with the engine response of another request to the model, translated to the
+// external model name and transformed by the response middlewares.
+func (s *Server) completedResponse(ctx context.Context, requestID, model, externalModel, endpoint string, body []byte, headers []*configPb.HeaderValueOption) *extProcPb.ProcessingResponse {
+	response := &middleware.Response{
+		Model: model, Endpoint: endpoint, EndOfStream: true,
+		Body: s.modelRewriter.ToExternal(body, model, externalModel),
+	}
+	if err := middlewareChainFrom(ctx).TransformResponse(ctx, response); err != nil {
+		klog.ErrorS(err, "failed to transform response, passing it untransformed", "requestID", requestID, "model", model)
+	}
+	headers = append(headers, &configPb.HeaderValueOption{
+		Header: &configPb.HeaderValue{Key: "Content-Type", RawValue: []byte("application/json")},
+	})
+	return &extProcPb.ProcessingResponse{
+		Response: &extProcPb.ProcessingResponse_ImmediateResponse{
+			ImmediateResponse: &extProcPb.ImmediateResponse{
+				Status:  &envoyTypePb.HttpStatus{Code: envoyTypePb.StatusCode_OK},
+				Headers: &extProcPb.HeaderMutation{SetHeaders: headers},
+				Body:    string(response.Body),
+			},
+		},
+	}
+}
+
+// storeResponse caches the complete response of a request that missed the cache, without delaying the response.
+func (s *Server) storeResponse(ctx context.Context, requestID string, body []byte) {
+	miss := responseCacheMissFrom(ctx)
+	if s.responseCache == nil || miss == nil || miss.entry == nil {
+		return
+	}
+	entry, options := miss.entry, miss.options
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), responseCacheStoreTimeout)
+		defer cancel()
+		if err := s.responseCache.Store(ctx, entry, body, options); err != nil {
+			klog.ErrorS(err, "failed to store the response in cache", "requestID", requestID)
+		}
+	}()
+}
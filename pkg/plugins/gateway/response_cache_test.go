@@ -0,0 +1,81 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	envoyTypePb "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/responsecache"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+func TestResponseCache(t *testing.T) {
+	store := kvstore.NewMemoryStore()
+	s := &Server{responseCache: responsecache.New(store, nil, nil)}
+	modelConfig := ModelConfig{ResponseCache: responsecache.Options{Mode: responsecache.ModeExact}}
+	body := func() map[string]interface{} {
+		return map[string]interface{}{"model": "llama", "prompt": "hello"}
+	}
+	alice := utils.User{Name: "alice", Tenant: "team-a"}
+
+	// models without a response cache are not looked up.
+	ctx := withResponseCacheMiss(context.Background())
+	assert.Nil(t, s.lookupResponseCache(ctx, "1", alice, "llama", "llama", EndpointCompletions, ModelConfig{}, body()))
+	assert.Nil(t, responseCacheMissFrom(ctx).entry)
+
+	// a miss is stored once the response completes.
+	assert.Nil(t, s.lookupResponseCache(ctx, "1", alice, "llama", "llama", EndpointCompletions, modelConfig, body()))
+	miss := responseCacheMissFrom(ctx)
+	assert.NotNil(t, miss.entry)
+	s.storeResponse(ctx, "1", []byte(`{"model":"llama","choices":[{"text":"world"}]}`))
+	assert.Eventually(t, func() bool {
+		_, err := store.Get(context.Background(), miss.entry.Key)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// the same prompt is answered from the cache.
+	ctx = withResponseCacheMiss(context.Background())
+	resp := s.lookupResponseCache(ctx, "2", alice, "llama", "llama", EndpointCompletions, modelConfig, body())
+	assert.NotNil(t, resp)
+	immediate := resp.GetImmediateResponse()
+	assert.Equal(t, envoyTypePb.StatusCode_OK, immediate.GetStatus().GetCode())
+	assert.Equal(t, `{"model":"llama","choices":[{"text":"world"}]}`, immediate.GetBody())
+	headers := map[string]string{}
+	for _, header := range immediate.GetHeaders().GetSetHeaders() {
+		headers[header.GetHeader().GetKey()] = string(header.GetHeader().GetRawValue())
+	}
+	assert.Equal(t, "hit", headers[HeaderResponseCache])
+	assert.Equal(t, responsecache.MatchExact, headers[HeaderResponseCacheMatch])
+	assert.Nil(t, responseCacheMissFrom(ctx).entry)
+
+	// the users of the tenant share the responses, other tenants don't.
+	assert.NotNil(t, s.lookupResponseCache(withResponseCacheMiss(context.Background()), "2", utils.User{Name: "bob", Tenant: "team-a"}, "llama", "llama", EndpointCompletions, modelConfig, body()))
+	assert.Nil(t, s.lookupResponseCache(withResponseCacheMiss(context.Background()), "2", utils.User{Name: "carol", Tenant: "team-b"}, "llama", "llama", EndpointCompletions, modelConfig, body()))
+	assert.Nil(t, s.lookupResponseCache(withResponseCacheMiss(context.Background()), "2", utils.User{}, "llama", "llama", EndpointCompletions, modelConfig, body()))
+
+	// streamed requests are not cached.
+	streamed := body()
+	streamed["stream"] = true
+	assert.Nil(t, s.lookupResponseCache(ctx, "3", alice, "llama", "llama", EndpointCompletions, modelConfig, streamed))
+	assert.Nil(t, responseCacheMissFrom(ctx).entry)
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package responsecache answers repeated prompts from the responses of earlier ones, matched by the hash of the
+// request or, for chat and completion prompts, by the similarity of their embeddings.
+package responsecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+const (
+	// ModeExact answers requests identical to an earlier one.
+	ModeExact = "exact"
+	// ModeSemantic also answers requests whose prompt is similar to the one of an earlier request with the same
+	// parameters.
+	ModeSemantic = "semantic"
+
+	MatchExact    = "exact"
+	MatchSemantic = "semantic"
+
+	DefaultTTL           = 10 * time.Minute
+	DefaultMinSimilarity = 0.95
+
+	keyPrefix = "aibrix:response-cache:"
+
+	// the endpoints whose prompts are matched semantically, as named by the gateway.
+	endpointChatCompletions = "chat_completions"
+	endpointCompletions     = "completions"
+)
+
+var lookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "aibrix_gateway_response_cache_lookups_total",
+	Help: "Response cache lookups by model and result: exact or semantic hits, misses and errors.",
+}, []string{"model", "result"})
+
+func init() {
+	prometheus.MustRegister(lookups)
+}
+
+// ignoredFields don't change the response, requests of a tenant differing only by them share their cache entry. The
+// user field is the end user of the caller, the entries are already scoped to the tenant of the caller.
+var ignoredFields = []string{"stream", "stream_options", "user"}
+
+// Options are the cache settings of a model.
+type Options struct {
+	Mode string
+	// TTL is how long a response is served from the cache.
+	TTL time.Duration
+	// MinSimilarity is the cosine similarity from which a prompt is answered by a semantic match.
+	MinSimilarity float64
+}
+
+// Validate checks the mode and the bounds of the options, an empty mode disables the cache.
+func (o Options) Validate() error {
+	if o.Mode != "" && o.Mode != ModeExact && o.Mode != ModeSemantic {
+		return fmt.Errorf("unknown response cache mode %q, must be %s or %s", o.Mode, ModeExact, ModeSemantic)
+	}
+	if o.TTL < 0 {
+		return fmt.Errorf("negative response cache ttl: %v", o.TTL)
+	}
+	if o.MinSimilarity < 0 || o.MinSimilarity > 1 {
+		return fmt.Errorf("response cache similarity must be within [0, 1]: %v", o.MinSimilarity)
+	}
+	return nil
+}
+
+func (o Options) ttl() time.Duration {
+	if o.TTL == 0 {
+		return DefaultTTL
+	}
+	return o.TTL
+}
+
+func (o Options) minSimilarity() float64 {
+	if o.MinSimilarity == 0 {
+		return DefaultMinSimilarity
+	}
+	return o.MinSimilarity
+}
+
+// Entry identifies the response of a request in the cache.
+type Entry struct {
+	// Key is the key of the response, the hash of the tenant, the model, the endpoint and the request body.
+	Key string
+	// scope hashes the tenant, the model, the endpoint and the parameters of the request, semantic matches stay
+	// within it.
+	scope string
+	// prompt is the text embedded for semantic matches, empty if the request is only matched exactly.
+	prompt    string
+	embedding []float32
+}
+
+// NewEntry creates the entry of a request of the tenant, false if the request is not cacheable, e.g. streamed.
+// Responses are never shared across tenants.
+func NewEntry(tenant, model, endpoint string, body map[string]interface{}) (*Entry, bool) {
+	key, request, ok := requestKey(tenant, model, endpoint, body)
+	if !ok {
+		return nil, false
+	}
+	entry := &Entry{Key: keyPrefix + key}
+	var err error
+
+	// the prompt is left out of the scope, the parameters must still be the same.
+	switch endpoint {
+	case endpointChatCompletions:
+		entry.prompt = chatPrompt(request["messages"])
+		delete(request, "messages")
+	case endpointCompletions:
+		entry.prompt, _ = request["prompt"].(string)
+		delete(request, "prompt")
+	}
+	if entry.prompt != "" {
+		if entry.scope, err = hash(tenant, model, endpoint, request); err != nil {
+			entry.prompt = ""
+		}
+	}
+	return entry, true
+}
+
+// Key returns the hash of a request of the tenant, identical for requests of the tenant answered by the same response.
+// It is false for streamed requests, whose responses are not shared.
+func Key(tenant, model, endpoint string, body map[string]interface{}) (string, bool) {
+	key, _, ok := requestKey(tenant, model, endpoint, body)
+	return key, ok
+}
+
+// requestKey returns the hash of the request of the tenant and its fields changing the response.
+func requestKey(tenant, model, endpoint string, body map[string]interface{}) (string, map[string]interface{}, bool) {
+	if stream, _ := body["stream"].(bool); stream {
+		return "", nil, false
+	}
+	request := make(map[string]interface{}, len(body))
+	for field, value := range body {
+		request[field] = value
+	}
+	for _, field := range ignoredFields {
+		delete(request, field)
+	}
+	key, err := hash(tenant, model, endpoint, request)
+	if err != nil {
+		return "", nil, false
+	}
+	return key, request, true
+}
+
+// hash hashes the JSON of the request, whose object keys are sorted by encoding/json.
+func hash(tenant, model, endpoint string, request map[string]interface{}) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	sum.Write([]byte(tenant + "\x00" + model + "\x00" + endpoint + "\x00"))
+	sum.Write(data)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// chatPrompt returns the roles and text contents of the messages, one message per line.
+func chatPrompt(value interface{}) string {
+	messages, _ := value.([]interface{})
+	var prompt strings.Builder
+	for _, m := range messages {
+		message, ok := m.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		role, _ := message["role"].(string)
+		prompt.WriteString(role + ": ")
+		switch content := message["content"].(type) {
+		case string:
+			prompt.WriteString(content)
+		case []interface{}:
+			for _, p := range content {
+				if part, ok := p.(map[string]interface{}); ok && part["type"] == "text" {
+					text, _ := part["text"].(string)
+					prompt.WriteString(text)
+				}
+			}
+		}
+		prompt.WriteString("\n")
+	}
+	return prompt.String()
+}
+
+// Match is a cached response found for a request.
+type Match struct {
+	Body []byte
+	// Type is MatchExact or MatchSemantic.
+	Type string
+	// Similarity of the prompt to the cached one, 1 for exact matches.
+	Similarity float64
+}
+
+// Cache stores the responses in a kv store and, for semantic matches, the embeddings of their prompts in a vector
+// index.
+type Cache struct {
+	store    kvstore.Store
+	index    VectorIndex
+	embedder Embedder
+}
+
+// New creates a response cache. Semantic matches are disabled when index or embedder is nil, models configured for
+// them are matched exactly.
+func New(store kvstore.Store, index VectorIndex, embedder Embedder) *Cache {
+	return &Cache{store: store, index: index, embedder: embedder}
+}
+
+func (c *Cache) semantic(entry *Entry, opts Options) bool {
+	return opts.Mode == ModeSemantic && entry.prompt != "" && c.index != nil && c.embedder != nil
+}
+
+// Lookup returns the cached response of the request, nil on a miss. The embedding of the prompt is kept in the entry
+// to store the response of a miss.
+func (c *Cache) Lookup(ctx context.Context, model string, entry *Entry, opts Options) (*Match, error) {
+	match, err := c.lookup(ctx, entry, opts)
+	switch {
+	case err != nil:
+		lookups.WithLabelValues(model, "error").Inc()
+	case match == nil:
+		lookups.WithLabelValues(model, "miss").Inc()
+	default:
+		lookups.WithLabelValues(model, match.Type).Inc()
+	}
+	return match, err
+}
+
+func (c *Cache) lookup(ctx context.Context, entry *Entry, opts Options) (*Match, error) {
+	body, err := c.store.Get(ctx, entry.Key)
+	if err == nil {
+		return &Match{Body: body, Type: MatchExact, Similarity: 1}, nil
+	}
+	if !errors.Is(err, kvstore.ErrNotFound) {
+		return nil, err
+	}
+	if !c.semantic(entry, opts) {
+		return nil, nil
+	}
+
+	if entry.embedding, err = c.embedder.Embed(ctx, entry.prompt); err != nil {
+		return nil, fmt.Errorf("failed to embed the prompt: %w", err)
+	}
+	key, similarity, err := c.index.Search(ctx, entry.scope, entry.embedding)
+	if err != nil || key == "" || similarity < opts.minSimilarity() {
+		return nil, err
+	}
+	body, err = c.store.Get(ctx, key)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		// the response expired before its embedding.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Match{Body: body, Type: MatchSemantic, Similarity: similarity}, nil
+}
+
+// Store caches the response of the request for the TTL of the model.
+func (c *Cache) Store(ctx context.Context, entry *Entry, body []byte, opts Options) error {
+	if err := c.store.Set(ctx, entry.Key, body, opts.ttl()); err != nil {
+		return err
+	}
+	if c.semantic(entry, opts) && entry.embedding != nil {
+		return c.index.Add(ctx, entry.scope, entry.embedding, entry.Key, opts.ttl())
+	}
+	return nil
+}
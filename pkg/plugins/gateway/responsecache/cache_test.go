@@ -0,0 +1,256 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+func chatRequest(content string, fields map[string]interface{}) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":    "llama",
+		"messages": []interface{}{map[string]interface{}{"role": "user", "content": content}},
+	}
+	for field, value := range fields {
+		body[field] = value
+	}
+	return body
+}
+
+func TestNewEntry(t *testing.T) {
+	entry, ok := NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("hello", nil))
+	assert.True(t, ok)
+	assert.Equal(t, "user: hello\n", entry.prompt)
+
+	// the user doesn't change the response.
+	other, _ := NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("hello", map[string]interface{}{"user": "jane"}))
+	assert.Equal(t, entry.Key, other.Key)
+
+	// other prompts share the scope of the same parameters only.
+	other, _ = NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("hi", nil))
+	assert.NotEqual(t, entry.Key, other.Key)
+	assert.Equal(t, entry.scope, other.scope)
+	other, _ = NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("hello", map[string]interface{}{"temperature": 0.5}))
+	assert.NotEqual(t, entry.Key, other.Key)
+	assert.NotEqual(t, entry.scope, other.scope)
+	other, _ = NewEntry("team-a", "mistral", endpointChatCompletions, chatRequest("hello", nil))
+	assert.NotEqual(t, entry.Key, other.Key)
+
+	// tenants don't share responses, nor semantic matches.
+	other, _ = NewEntry("team-b", "llama", endpointChatCompletions, chatRequest("hello", nil))
+	assert.NotEqual(t, entry.Key, other.Key)
+	assert.NotEqual(t, entry.scope, other.scope)
+
+	// embeddings are only matched exactly.
+	entry, ok = NewEntry("team-a", "bge", "embeddings", map[string]interface{}{"input": "hello"})
+	assert.True(t, ok)
+	assert.Empty(t, entry.prompt)
+
+	_, ok = NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("hello", map[string]interface{}{"stream": true}))
+	assert.False(t, ok)
+}
+
+func TestOptionsValidate(t *testing.T) {
+	assert.NoError(t, Options{}.Validate())
+	assert.NoError(t, Options{Mode: ModeSemantic, TTL: time.Hour, MinSimilarity: 0.9}.Validate())
+	assert.Error(t, Options{Mode: "fuzzy"}.Validate())
+	assert.Error(t, Options{Mode: ModeExact, TTL: -time.Second}.Validate())
+	assert.Error(t, Options{Mode: ModeSemantic, MinSimilarity: 1.5}.Validate())
+}
+
+// wordEmbedder embeds texts on the counts of a few words.
+type wordEmbedder struct{}
+
+func (wordEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	vector := make([]float32, 3)
+	for i, word := range []string{"weather", "paris", "london"} {
+		for j := 0; j+len(word) <= len(text); j++ {
+			if text[j:j+len(word)] == word {
+				vector[i]++
+			}
+		}
+	}
+	return vector, nil
+}
+
+type indexedVector struct {
+	scope  string
+	vector []float32
+	key    string
+}
+
+// memoryIndex searches the vectors exhaustively.
+type memoryIndex struct {
+	vectors []indexedVector
+}
+
+func (m *memoryIndex) Search(_ context.Context, scope string, vector []float32) (string, float64, error) {
+	best, bestSimilarity := "", -1.0
+	for _, v := range m.vectors {
+		if v.scope != scope {
+			continue
+		}
+		if similarity := cosine(vector, v.vector); similarity > bestSimilarity {
+			best, bestSimilarity = v.key, similarity
+		}
+	}
+	return best, bestSimilarity, nil
+}
+
+func (m *memoryIndex) Add(_ context.Context, scope string, vector []float32, key string, _ time.Duration) error {
+	m.vectors = append(m.vectors, indexedVector{scope: scope, vector: vector, key: key})
+	return nil
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i] * b[i])
+		normA += float64(a[i] * a[i])
+		normB += float64(b[i] * b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+func TestCacheExact(t *testing.T) {
+	ctx := context.Background()
+	cache := New(kvstore.NewMemoryStore(), nil, nil)
+	options := Options{Mode: ModeSemantic}
+
+	entry, _ := NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("weather in paris", nil))
+	match, err := cache.Lookup(ctx, "llama", entry, options)
+	assert.NoError(t, err)
+	assert.Nil(t, match)
+	assert.NoError(t, cache.Store(ctx, entry, []byte(`{"id":"1"}`), options))
+
+	entry, _ = NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("weather in paris", map[string]interface{}{"user": "jane"}))
+	match, err = cache.Lookup(ctx, "llama", entry, options)
+	assert.NoError(t, err)
+	assert.Equal(t, &Match{Body: []byte(`{"id":"1"}`), Type: MatchExact, Similarity: 1}, match)
+
+	// without a vector index similar prompts miss.
+	entry, _ = NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("the weather in paris", nil))
+	match, err = cache.Lookup(ctx, "llama", entry, options)
+	assert.NoError(t, err)
+	assert.Nil(t, match)
+}
+
+func TestCacheSemantic(t *testing.T) {
+	ctx := context.Background()
+	index := &memoryIndex{}
+	cache := New(kvstore.NewMemoryStore(), index, wordEmbedder{})
+	options := Options{Mode: ModeSemantic, MinSimilarity: 0.9}
+
+	entry, _ := NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("weather in paris", nil))
+	match, err := cache.Lookup(ctx, "llama", entry, options)
+	assert.NoError(t, err)
+	assert.Nil(t, match)
+	assert.NoError(t, cache.Store(ctx, entry, []byte(`{"id":"paris"}`), options))
+	assert.Len(t, index.vectors, 1)
+
+	entry, _ = NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("what's the weather in paris?", nil))
+	match, err = cache.Lookup(ctx, "llama", entry, options)
+	assert.NoError(t, err)
+	assert.Equal(t, MatchSemantic, match.Type)
+	assert.Equal(t, `{"id":"paris"}`, string(match.Body))
+	assert.InDelta(t, 1, match.Similarity, 1e-6)
+
+	// dissimilar prompts and other parameters miss.
+	entry, _ = NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("weather in london", nil))
+	match, err = cache.Lookup(ctx, "llama", entry, options)
+	assert.NoError(t, err)
+	assert.Nil(t, match)
+	entry, _ = NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("weather in paris", map[string]interface{}{"max_tokens": 10}))
+	match, err = cache.Lookup(ctx, "llama", entry, options)
+	assert.NoError(t, err)
+	assert.Nil(t, match)
+
+	// exact mode doesn't embed prompts.
+	entry, _ = NewEntry("team-a", "llama", endpointChatCompletions, chatRequest("what's the weather in paris?", nil))
+	match, err = cache.Lookup(ctx, "llama", entry, Options{Mode: ModeExact})
+	assert.NoError(t, err)
+	assert.Nil(t, match)
+	assert.Nil(t, entry.embedding)
+}
+
+func TestParseSearchReply(t *testing.T) {
+	resp2 := []interface{}{int64(1), "aibrix:response-cache-vector:abc",
+		[]interface{}{"response", "aibrix:response-cache:abc", "distance", "0.0125"}}
+	key, distance, err := parseSearchReply(resp2)
+	assert.NoError(t, err)
+	assert.Equal(t, "aibrix:response-cache:abc", key)
+	assert.InDelta(t, 0.0125, distance, 1e-9)
+
+	resp3 := map[interface{}]interface{}{
+		"total_results": int64(1),
+		"results": []interface{}{map[interface{}]interface{}{
+			"id": "aibrix:response-cache-vector:abc",
+			"extra_attributes": map[interface{}]interface{}{
+				"response": "aibrix:response-cache:abc", "distance": "0.5",
+			},
+		}},
+	}
+	key, distance, err = parseSearchReply(resp3)
+	assert.NoError(t, err)
+	assert.Equal(t, "aibrix:response-cache:abc", key)
+	assert.InDelta(t, 0.5, distance, 1e-9)
+
+	key, _, err = parseSearchReply([]interface{}{int64(0)})
+	assert.NoError(t, err)
+	assert.Empty(t, key)
+	_, _, err = parseSearchReply("OK")
+	assert.Error(t, err)
+}
+
+func TestEncodeVector(t *testing.T) {
+	assert.Equal(t, []byte{0, 0, 0x80, 0x3f, 0, 0, 0, 0xc0}, encodeVector([]float32{1, -2}))
+}
+
+func TestHTTPEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "bge", request["model"])
+		assert.Equal(t, "hello", request["input"])
+		_, _ = w.Write([]byte(`{"data":[{"embedding":[0.5,0.25]}]}`))
+	}))
+	defer server.Close()
+
+	embedding, err := NewHTTPEmbedder(server.URL, "bge", time.Second).Embed(context.Background(), "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, []float32{0.5, 0.25}, embedding)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	_, err = NewHTTPEmbedder(failing.URL, "bge", time.Second).Embed(context.Background(), "hello")
+	assert.ErrorContains(t, err, "overloaded")
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisIndexName = "aibrix-response-cache"
+	// redisVectorPrefix is the prefix of the hashes holding the embeddings, the only keys of the index.
+	redisVectorPrefix = "aibrix:response-cache-vector:"
+)
+
+// VectorIndex finds the response of the prompt closest to an embedding.
+type VectorIndex interface {
+	// Search returns the key of the response whose prompt embedding is the most similar to the vector within the
+	// scope and its cosine similarity, an empty key if the scope has none.
+	Search(ctx context.Context, scope string, vector []float32) (string, float64, error)
+	// Add indexes the embedding of the prompt of the response key, for ttl.
+	Add(ctx context.Context, scope string, vector []float32, key string, ttl time.Duration) error
+}
+
+// Embedder computes the embedding of a prompt.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// redisIndex is a vector index of Redis Stack or any Redis with the search module.
+type redisIndex struct {
+	client redis.UniversalClient
+
+	mu      sync.Mutex
+	created bool
+}
+
+// NewRedisIndex indexes the embeddings in hashes searched with FT.SEARCH. The index is created with the dimension
+// of the first embedding.
+func NewRedisIndex(client redis.UniversalClient) VectorIndex {
+	return &redisIndex{client: client}
+}
+
+func (r *redisIndex) ensureIndex(ctx context.Context, dim int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.created {
+		return nil
+	}
+	err := r.client.Do(ctx, "FT.CREATE", redisIndexName, "ON", "HASH", "PREFIX", "1", redisVectorPrefix,
+		"SCHEMA", "scope", "TAG", "embedding", "VECTOR", "HNSW", "6",
+		"TYPE", "FLOAT32", "DIM", dim, "DISTANCE_METRIC", "COSINE").Err()
+	if err != nil && !strings.Contains(err.Error(), "Index already exists") {
+		return fmt.Errorf("failed to create the response cache index: %w", err)
+	}
+	r.created = true
+	return nil
+}
+
+func (r *redisIndex) Search(ctx context.Context, scope string, vector []float32) (string, float64, error) {
+	if err := r.ensureIndex(ctx, len(vector)); err != nil {
+		return "", 0, err
+	}
+	reply, err := r.client.Do(ctx, "FT.SEARCH", redisIndexName,
+		fmt.Sprintf("(@scope:{%s})=>[KNN 1 @embedding $vector AS distance]", scope),
+		"PARAMS", "2", "vector", encodeVector(vector),
+		"RETURN", "2", "response", "distance", "DIALECT", "2").Result()
+	if err != nil {
+		return "", 0, err
+	}
+	key, distance, err := parseSearchReply(reply)
+	if err != nil || key == "" {
+		return "", 0, err
+	}
+	// the cosine distance of Redis is 1 - the cosine similarity.
+	return key, 1 - distance, nil
+}
+
+func (r *redisIndex) Add(ctx context.Context, scope string, vector []float32, key string, ttl time.Duration) error {
+	if err := r.ensureIndex(ctx, len(vector)); err != nil {
+		return err
+	}
+	vectorKey := redisVectorPrefix + strings.TrimPrefix(key, keyPrefix)
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, vectorKey, "scope", scope, "embedding", encodeVector(vector), "response", key)
+	pipe.Expire(ctx, vectorKey, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// encodeVector encodes the vector as the little endian FLOAT32 blob of Redis.
+func encodeVector(vector []float32) []byte {
+	blob := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(blob[4*i:], math.Float32bits(v))
+	}
+	return blob
+}
+
+// parseSearchReply returns the response key and the distance of the first document of an FT.SEARCH reply, in RESP2
+// (an array of the count, then of the id and the fields of each document) or RESP3 (a map with the results).
+func parseSearchReply(reply interface{}) (string, float64, error) {
+	var fields map[string]interface{}
+	switch r := reply.(type) {
+	case []interface{}:
+		if len(r) < 3 {
+			return "", 0, nil
+		}
+		values, ok := r[2].([]interface{})
+		if !ok {
+			return "", 0, fmt.Errorf("unexpected search reply: %v", reply)
+		}
+		fields = map[string]interface{}{}
+		for i := 0; i+1 < len(values); i += 2 {
+			fields[fmt.Sprint(values[i])] = values[i+1]
+		}
+	case map[interface{}]interface{}:
+		results, _ := r["results"].([]interface{})
+		if len(results) == 0 {
+			return "", 0, nil
+		}
+		result, _ := results[0].(map[interface{}]interface{})
+		attributes, ok := result["extra_attributes"].(map[interface{}]interface{})
+		if !ok {
+			return "", 0, fmt.Errorf("unexpected search reply: %v", reply)
+		}
+		fields = map[string]interface{}{}
+		for name, value := range attributes {
+			fields[fmt.Sprint(name)] = value
+		}
+	default:
+		return "", 0, fmt.Errorf("unexpected search reply: %v", reply)
+	}
+
+	key, _ := fields["response"].(string)
+	distance, err := strconv.ParseFloat(fmt.Sprint(fields["distance"]), 64)
+	if key == "" || err != nil {
+		return "", 0, fmt.Errorf("unexpected search reply: %v", reply)
+	}
+	return key, distance, nil
+}
+
+// httpEmbedder calls an OpenAI-compatible embeddings endpoint.
+type httpEmbedder struct {
+	url    string
+	model  string
+	client *http.Client
+}
+
+// NewHTTPEmbedder embeds prompts with the model served at url, the address of an OpenAI-compatible /v1/embeddings.
+func NewHTTPEmbedder(url, model string, timeout time.Duration) Embedder {
+	return &httpEmbedder{url: url, model: model, client: &http.Client{Timeout: timeout}}
+}
+
+func (e *httpEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": e.model, "input": text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("embeddings request failed with %d: %s", resp.StatusCode, message)
+	}
+
+	var embeddings struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&embeddings); err != nil {
+		return nil, err
+	}
+	if len(embeddings.Data) == 0 || len(embeddings.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("no embedding in the response")
+	}
+	return embeddings.Data[0].Embedding, nil
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/client/clientset/versioned"
+	"github.com/vllm-project/aibrix/pkg/features"
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// scaleToZeroIdleWindowAnnotationKey and lastRequestTimeAnnotationKey must match the annotations of the PodAutoscaler controller.
+	scaleToZeroIdleWindowAnnotationKey = "autoscaling.aibrix.ai/scale-to-zero-idle-window"
+	lastRequestTimeAnnotationKey       = "autoscaling.aibrix.ai/last-request-time"
+
+	defaultScaleFromZeroTimeoutInSecs     = 300
+	defaultRequestActivityIntervalInSecs  = 60
+	scaleTargetLookupTTL                  = 30 * time.Second
+	scaleFromZeroActivationCooldown       = 5 * time.Second
+	scaleFromZeroReadyPodsPollingInterval = 500 * time.Millisecond
+)
+
+var (
+	scaleFromZeroTimeout    = getScaleFromZeroTimeout()
+	requestActivityInterval = getRequestActivityInterval()
+)
+
+func getScaleFromZeroTimeout() time.Duration {
+	value := utils.LoadEnv("AIBRIX_SCALE_FROM_ZERO_TIMEOUT_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_SCALE_FROM_ZERO_TIMEOUT_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_SCALE_FROM_ZERO_TIMEOUT_S env value for scale from zero timeout: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultScaleFromZeroTimeoutInSecs * time.Second
+}
+
+func getRequestActivityInterval() time.Duration {
+	value := utils.LoadEnv("AIBRIX_REQUEST_ACTIVITY_INTERVAL_S", "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue <= 0 {
+			klog.Infof("invalid AIBRIX_REQUEST_ACTIVITY_INTERVAL_S: %s, falling back to default", value)
+		} else {
+			klog.Infof("using AIBRIX_REQUEST_ACTIVITY_INTERVAL_S env value for request activity interval: %d s", intValue)
+			return time.Duration(intValue) * time.Second
+		}
+	}
+	return defaultRequestActivityIntervalInSecs * time.Second
+}
+
+// scaleTarget is the PodAutoscaler of a model with scale-to-zero enabled, cached for scaleTargetLookupTTL.
+type scaleTarget struct {
+	key     types.NamespacedName
+	found   bool
+	expires time.Time
+}
+
+// scaleFromZeroActivator reports requests of models whose PodAutoscaler scales to zero when idle, and holds requests
+// of a model scaled to zero until the PodAutoscaler controller brings a pod up. PodAutoscalers are matched to models
+// with the model.aibrix.ai/name label.
+type scaleFromZeroActivator struct {
+	client     versioned.Interface
+	cache      cache.Interface
+	targets    sync.Map // model_name: scaleTarget
+	lastReport sync.Map // model_name: time.Time
+}
+
+func newScaleFromZeroActivator(client versioned.Interface, c cache.Interface) *scaleFromZeroActivator {
+	return &scaleFromZeroActivator{
+		client: client,
+		cache:  c,
+	}
+}
+
+// RecordActivity reports a request of the model to its PodAutoscaler, so the model is not scaled to zero while serving.
+// Reports are issued asynchronously at most once per interval, it never blocks the request.
+func (a *scaleFromZeroActivator) RecordActivity(flagTarget features.Target) {
+	if a == nil || a.client == nil || !features.IsFlagEnabled(features.ScaleFromZero, flagTarget) {
+		return
+	}
+	model := flagTarget.Model
+	if !a.claimReport(model, requestActivityInterval) {
+		return
+	}
+	go func() {
+		target, ok := a.lookup(context.Background(), model)
+		if !ok {
+			return
+		}
+		if err := a.patchLastRequestTime(context.Background(), target, time.Now()); err != nil {
+			klog.ErrorS(err, "failed to report request activity", "podAutoscaler", target, "model", model)
+		}
+	}()
+}
+
+// WaitForReadyPods holds the request while the model is scaled to zero. It requests a scale up and returns once the
+// engine of a pod serves, or with an error when timeout expires first. It returns false without waiting if the model
+// has ready pods or does not scale to zero.
+func (a *scaleFromZeroActivator) WaitForReadyPods(ctx context.Context, flagTarget features.Target, timeout time.Duration) (bool, error) {
+	if a == nil || a.client == nil || !features.IsFlagEnabled(features.ScaleFromZero, flagTarget) {
+		return false, nil
+	}
+	model := flagTarget.Model
+	if a.hasReadyPods(model) {
+		return false, nil
+	}
+	target, ok := a.lookup(ctx, model)
+	if !ok {
+		return false, nil
+	}
+
+	// concurrent requests share one activation, the controller only needs to see one request after the scale down.
+	if a.claimReport(model, scaleFromZeroActivationCooldown) {
+		if err := a.patchLastRequestTime(ctx, target, time.Now()); err != nil {
+			return true, fmt.Errorf("failed to request scale from zero of %s: %v", target, err)
+		}
+		klog.InfoS("requested scale from zero", "podAutoscaler", target, "model", model)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(scaleFromZeroReadyPodsPollingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return true, fmt.Errorf("no pod of model %s became ready within %s", model, timeout)
+		case <-ticker.C:
+			if a.hasReadyPods(model) {
+				return true, nil
+			}
+		}
+	}
+}
+
+func (a *scaleFromZeroActivator) hasReadyPods(model string) bool {
+	pods, err := a.cache.GetReadyPodsForModel(model)
+	return err == nil && len(utils.FilterReadyPods(pods)) > 0
+}
+
+// claimReport returns true if the model was not reported within the interval, and records the report.
+func (a *scaleFromZeroActivator) claimReport(model string, interval time.Duration) bool {
+	now := time.Now()
+	if last, ok := a.lastReport.Load(model); ok && now.Sub(last.(time.Time)) < interval {
+		return false
+	}
+	a.lastReport.Store(model, now)
+	return true
+}
+
+// lookup finds the PodAutoscaler of the model with scale-to-zero enabled.
+func (a *scaleFromZeroActivator) lookup(ctx context.Context, model string) (types.NamespacedName, bool) {
+	if cached, ok := a.targets.Load(model); ok && time.Now().Before(cached.(scaleTarget).expires) {
+		return cached.(scaleTarget).key, cached.(scaleTarget).found
+	}
+
+	target := scaleTarget{expires: time.Now().Add(scaleTargetLookupTTL)}
+	list, err := a.client.AutoscalingV1alpha1().PodAutoscalers(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", modelIdentifierLabel, model),
+	})
+	if err != nil {
+		klog.ErrorS(err, "failed to list pod autoscalers", "model", model)
+		return types.NamespacedName{}, false
+	}
+	for _, pa := range list.Items {
+		if _, ok := pa.Annotations[scaleToZeroIdleWindowAnnotationKey]; ok {
+			target.key = types.NamespacedName{Namespace: pa.Namespace, Name: pa.Name}
+			target.found = true
+			break
+		}
+	}
+	a.targets.Store(model, target)
+	return target.key, target.found
+}
+
+func (a *scaleFromZeroActivator) patchLastRequestTime(ctx context.Context, key types.NamespacedName, t time.Time) error {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, lastRequestTimeAnnotationKey, t.UTC().Format(time.RFC3339))
+	_, err := a.client.AutoscalingV1alpha1().PodAutoscalers(key.Namespace).Patch(ctx, key.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1alpha1 "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/client/clientset/versioned/fake"
+	"github.com/vllm-project/aibrix/pkg/features"
+)
+
+func TestScaleFromZeroWaitForReadyPods(t *testing.T) {
+	pa := &autoscalingv1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "llama-7b-pa",
+			Namespace:   "default",
+			Labels:      map[string]string{modelIdentifierLabel: "llama-7b"},
+			Annotations: map[string]string{scaleToZeroIdleWindowAnnotationKey: "10m"},
+		},
+	}
+	client := fake.NewSimpleClientset(pa)
+	activator := newScaleFromZeroActivator(client, cache.New())
+
+	// models without a scale-to-zero autoscaler are not held.
+	waited, err := activator.WaitForReadyPods(context.Background(), features.Target{Model: "qwen-7b"}, time.Second)
+	assert.False(t, waited)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	waited, err = activator.WaitForReadyPods(context.Background(), features.Target{Model: "llama-7b"}, 100*time.Millisecond)
+	assert.True(t, waited)
+	assert.Error(t, err, "no pod comes up in the test")
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	updated, err := client.AutoscalingV1alpha1().PodAutoscalers("default").Get(context.Background(), "llama-7b-pa", metav1.GetOptions{})
+	require.NoError(t, err)
+	requested, err := time.Parse(time.RFC3339, updated.Annotations[lastRequestTimeAnnotationKey])
+	require.NoError(t, err)
+	assert.WithinDuration(t, start, requested, 2*time.Second)
+
+	// concurrent requests within the cooldown share the activation.
+	assert.False(t, activator.claimReport("llama-7b", scaleFromZeroActivationCooldown))
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodProfile models the serving capacity of one engine pod.
+type PodProfile struct {
+	// MaxRunning is the maximum number of requests in a batch, further requests wait.
+	MaxRunning int `json:"maxRunning"`
+	// PrefillTokensPerSecond is the speed of prefilling uncached prompt tokens, one request at a time.
+	PrefillTokensPerSecond float64 `json:"prefillTokensPerSecond"`
+	// DecodeTokensPerSecond is the decode speed of a request running alone.
+	DecodeTokensPerSecond float64 `json:"decodeTokensPerSecond"`
+	// DecodeBatchSlowdown slows down decoding by this fraction for every other running request.
+	DecodeBatchSlowdown float64 `json:"decodeBatchSlowdown"`
+	// KVCacheTokens is the number of tokens that fit into the kv cache of running requests.
+	KVCacheTokens int `json:"kvCacheTokens"`
+	// PrefixCacheGroups is the number of shared prompt prefixes the pod keeps cached, least recently used first out.
+	PrefixCacheGroups int `json:"prefixCacheGroups"`
+}
+
+// DefaultPodProfile roughly models a 7B model on a single A10 GPU.
+var DefaultPodProfile = PodProfile{
+	MaxRunning:             64,
+	PrefillTokensPerSecond: 8000,
+	DecodeTokensPerSecond:  40,
+	DecodeBatchSlowdown:    0.02,
+	KVCacheTokens:          100000,
+	PrefixCacheGroups:      16,
+}
+
+func (p PodProfile) validate() error {
+	if p.MaxRunning <= 0 || p.PrefillTokensPerSecond <= 0 || p.DecodeTokensPerSecond <= 0 || p.KVCacheTokens <= 0 {
+		return fmt.Errorf("invalid pod profile %+v: batch size, speeds and kv cache must be positive", p)
+	}
+	if p.DecodeBatchSlowdown < 0 || p.PrefixCacheGroups < 0 {
+		return fmt.Errorf("invalid pod profile %+v: slowdown and prefix cache groups must not be negative", p)
+	}
+	return nil
+}
+
+type simRequest struct {
+	id           int
+	group        int
+	inputTokens  int
+	outputTokens int
+	cachedTokens int
+	arrival      float64
+	admitted     float64
+	firstToken   float64
+	prefillLeft  float64
+	decodeLeft   float64
+}
+
+// simPod serves requests like a continuous batching engine: waiting requests are admitted while the batch and the kv
+// cache have room, prompts are prefilled one at a time in admission order, pausing decoding, then all prefilled
+// requests decode together.
+type simPod struct {
+	pod     *v1.Pod
+	profile PodProfile
+
+	clock   float64
+	running []*simRequest
+	waiting []*simRequest
+	kvUsed  int
+
+	prefixes     *list.List // of group, most recently used first
+	prefixLookup map[int]*list.Element
+
+	// counters since the last metrics refresh
+	busy             float64
+	prefilledTokens  float64
+	decodedTokens    float64
+	queueTime        float64
+	admittedRequests int
+}
+
+func newSimPod(index int, model string, profile PodProfile) *simPod {
+	return &simPod{
+		pod: &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-sim-%d", model, index),
+				Namespace: "default",
+				Labels:    map[string]string{"model.aibrix.ai/name": model},
+			},
+			Status: v1.PodStatus{
+				PodIP: fmt.Sprintf("10.%d.%d.%d", index>>16&0xff, index>>8&0xff, index&0xff),
+				Phase: v1.PodRunning,
+				Conditions: []v1.PodCondition{
+					{Type: v1.PodReady, Status: v1.ConditionTrue},
+				},
+			},
+		},
+		profile:      profile,
+		prefixes:     list.New(),
+		prefixLookup: map[int]*list.Element{},
+	}
+}
+
+// enqueue accepts the request at the current clock, looking up its prompt prefix in the prefix cache.
+func (p *simPod) enqueue(req *simRequest, prefixTokens int) {
+	if elem, ok := p.prefixLookup[req.group]; ok {
+		req.cachedTokens = prefixTokens
+		p.prefixes.MoveToFront(elem)
+	} else if p.profile.PrefixCacheGroups > 0 {
+		p.prefixLookup[req.group] = p.prefixes.PushFront(req.group)
+		if p.prefixes.Len() > p.profile.PrefixCacheGroups {
+			oldest := p.prefixes.Back()
+			p.prefixes.Remove(oldest)
+			delete(p.prefixLookup, oldest.Value.(int))
+		}
+	}
+	req.prefillLeft = float64(req.inputTokens - req.cachedTokens)
+	req.decodeLeft = float64(req.outputTokens)
+	p.waiting = append(p.waiting, req)
+}
+
+func (p *simPod) admit() {
+	for len(p.waiting) > 0 && len(p.running) < p.profile.MaxRunning {
+		req := p.waiting[0]
+		tokens := req.inputTokens + req.outputTokens
+		// a request larger than the whole kv cache still runs alone instead of blocking the queue forever.
+		if p.kvUsed+tokens > p.profile.KVCacheTokens && len(p.running) > 0 {
+			return
+		}
+		p.waiting = p.waiting[1:]
+		req.admitted = p.clock
+		p.queueTime += p.clock - req.arrival
+		p.admittedRequests++
+		p.kvUsed += tokens
+		p.running = append(p.running, req)
+	}
+}
+
+// advance serves requests until the clock reaches now, calling done for every completed request.
+func (p *simPod) advance(now float64, done func(*simRequest)) {
+	const epsilon = 1e-9
+	for p.clock < now {
+		p.admit()
+		if len(p.running) == 0 {
+			p.clock = now
+			return
+		}
+		dt := now - p.clock
+
+		if req := p.prefilling(); req != nil {
+			step := math.Min(dt, req.prefillLeft/p.profile.PrefillTokensPerSecond)
+			req.prefillLeft -= step * p.profile.PrefillTokensPerSecond
+			p.prefilledTokens += step * p.profile.PrefillTokensPerSecond
+			p.busy += step
+			p.clock += step
+			if req.prefillLeft <= epsilon {
+				req.prefillLeft = 0
+				req.firstToken = p.clock
+			}
+			continue
+		}
+
+		rate := p.decodeRate()
+		shortest := math.Inf(1)
+		for _, req := range p.running {
+			shortest = math.Min(shortest, req.decodeLeft)
+		}
+		step := math.Min(dt, shortest/rate)
+		p.busy += step
+		p.clock += step
+		running := p.running[:0]
+		for _, req := range p.running {
+			req.decodeLeft -= step * rate
+			p.decodedTokens += step * rate
+			if req.decodeLeft <= epsilon {
+				p.kvUsed -= req.inputTokens + req.outputTokens
+				done(req)
+				continue
+			}
+			running = append(running, req)
+		}
+		p.running = running
+	}
+}
+
+func (p *simPod) prefilling() *simRequest {
+	for _, req := range p.running {
+		if req.prefillLeft > 0 {
+			return req
+		}
+	}
+	return nil
+}
+
+func (p *simPod) decodeRate() float64 {
+	return p.profile.DecodeTokensPerSecond / (1 + p.profile.DecodeBatchSlowdown*float64(len(p.running)-1))
+}
+
+// kvCacheUsage is the fraction of the kv cache used by the tokens processed so far.
+func (p *simPod) kvCacheUsage() float64 {
+	used := 0.0
+	for _, req := range p.running {
+		used += float64(req.inputTokens) - req.prefillLeft + float64(req.outputTokens) - req.decodeLeft
+	}
+	return math.Min(1, used/float64(p.profile.KVCacheTokens))
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/metrics"
+)
+
+// metricScraper publishes the state of the simulated pods to the cache, with the metrics routers read from engines.
+type metricScraper struct {
+	cache cache.Interface
+	model string
+
+	// averages of the whole trace until a pod completed requests
+	avgInput  float64
+	avgOutput float64
+	completed map[*simPod]*podHistory
+}
+
+// podHistory accumulates completed requests like the engine histograms.
+type podHistory struct {
+	requests     float64
+	inputTokens  float64
+	outputTokens float64
+	prefill      metrics.HistogramMetricValue
+	decode       metrics.HistogramMetricValue
+}
+
+func newMetricScraper(c cache.Interface, model string, avgInput, avgOutput float64) *metricScraper {
+	return &metricScraper{cache: c, model: model, avgInput: avgInput, avgOutput: avgOutput, completed: map[*simPod]*podHistory{}}
+}
+
+func (s *metricScraper) history(pod *simPod) *podHistory {
+	h, ok := s.completed[pod]
+	if !ok {
+		// seed with one ideal request so latency estimates are defined before the first completion.
+		h = &podHistory{
+			requests:     1,
+			inputTokens:  s.avgInput,
+			outputTokens: s.avgOutput,
+			prefill:      metrics.HistogramMetricValue{Sum: s.avgInput / pod.profile.PrefillTokensPerSecond, Count: 1},
+			decode:       metrics.HistogramMetricValue{Sum: s.avgOutput / pod.profile.DecodeTokensPerSecond, Count: 1},
+		}
+		s.completed[pod] = h
+	}
+	return h
+}
+
+func (s *metricScraper) observe(pod *simPod, req *simRequest) {
+	h := s.history(pod)
+	h.requests++
+	h.inputTokens += float64(req.inputTokens)
+	h.outputTokens += float64(req.outputTokens)
+	h.prefill.Sum += req.firstToken - req.admitted
+	h.prefill.Count++
+	h.decode.Sum += pod.clock - req.firstToken
+	h.decode.Count++
+}
+
+// refresh publishes the metrics of all pods, rates cover the interval since the last refresh.
+func (s *metricScraper) refresh(fleet []*simPod, interval float64) {
+	for _, pod := range fleet {
+		h := s.history(pod)
+		queueTime := 0.0
+		if pod.admittedRequests > 0 {
+			queueTime = pod.queueTime / float64(pod.admittedRequests)
+		}
+		prefill, decode := h.prefill, h.decode
+		for name, value := range map[string]float64{
+			metrics.NumRequestsRunning:              float64(len(pod.running)),
+			metrics.NumRequestsWaiting:              float64(len(pod.waiting)),
+			metrics.NumRequestsSwapped:              0,
+			metrics.GPUCacheUsagePerc:               pod.kvCacheUsage(),
+			metrics.CPUCacheUsagePerc:               0,
+			metrics.AvgPromptThroughputToksPerS:     pod.prefilledTokens / interval,
+			metrics.AvgGenerationThroughputToksPerS: pod.decodedTokens / interval,
+			metrics.AvgPromptToksPerReq:             h.inputTokens / h.requests,
+			metrics.AvgGenerationToksPerReq:         h.outputTokens / h.requests,
+			metrics.RequestQueueTimeSeconds:         queueTime,
+		} {
+			s.cache.SetPodModelMetric(pod.pod.Name, s.model, name, &metrics.SimpleMetricValue{Value: value})
+		}
+		s.cache.SetPodModelMetric(pod.pod.Name, s.model, metrics.RequestPrefillTimeSeconds, &prefill)
+		s.cache.SetPodModelMetric(pod.pod.Name, s.model, metrics.RequestDecodeTimeSeconds, &decode)
+		s.cache.SetPodMetric(pod.pod.Name, "gpu_busy_time_ratio", &metrics.SimpleMetricValue{Value: min(pod.busy/interval, 1)})
+
+		pod.busy, pod.prefilledTokens, pod.decodedTokens, pod.queueTime, pod.admittedRequests = 0, 0, 0, 0, 0
+	}
+}
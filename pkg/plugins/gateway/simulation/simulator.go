@@ -0,0 +1,278 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+)
+
+// Config describes the simulated fleet and how trace requests are synthesized.
+type Config struct {
+	Model   string
+	Pods    int
+	Profile PodProfile
+	// RateScale multiplies the number of requests of every trace bucket, e.g. 2 replays the trace at twice the load.
+	RateScale float64
+	// PrefixGroups is the number of distinct shared prompt prefixes, e.g. system prompts, requests pick one at random.
+	PrefixGroups int
+	// SharedPrefixRatio is the fraction of the prompt made of the shared prefix.
+	SharedPrefixRatio float64
+	// MaxPromptWords caps the words of a synthesized prompt, which routers tokenize, to keep long traces fast.
+	MaxPromptWords int
+	// RefreshInterval is how often the metrics seen by routers are refreshed, like the gateway scraping engines.
+	RefreshInterval time.Duration
+	Seed            int64
+}
+
+// DefaultConfig returns the configuration of a fleet of pods with the default profile.
+func DefaultConfig(model string, pods int) Config {
+	return Config{
+		Model:             model,
+		Pods:              pods,
+		Profile:           DefaultPodProfile,
+		RateScale:         1,
+		PrefixGroups:      32,
+		SharedPrefixRatio: 0.5,
+		MaxPromptWords:    512,
+		RefreshInterval:   50 * time.Millisecond,
+		Seed:              1,
+	}
+}
+
+func (c Config) validate() error {
+	if c.Model == "" || c.Pods <= 0 {
+		return fmt.Errorf("model and a positive number of pods are required")
+	}
+	if c.RateScale <= 0 || c.PrefixGroups <= 0 || c.MaxPromptWords <= 0 || c.RefreshInterval <= 0 {
+		return fmt.Errorf("rate scale, prefix groups, max prompt words and refresh interval must be positive")
+	}
+	if c.SharedPrefixRatio < 0 || c.SharedPrefixRatio > 1 {
+		return fmt.Errorf("shared prefix ratio must be between 0 and 1")
+	}
+	return c.Profile.validate()
+}
+
+// Result summarizes the replay of a trace against one router. Latencies are in seconds of simulated time.
+type Result struct {
+	Requests      int            `json:"requests"`
+	Failed        int            `json:"failed"`
+	LatencyP50    float64        `json:"latencyP50"`
+	LatencyP99    float64        `json:"latencyP99"`
+	TTFTP50       float64        `json:"ttftP50"`
+	TTFTP99       float64        `json:"ttftP99"`
+	MeanQueueTime float64        `json:"meanQueueTime"`
+	Makespan      float64        `json:"makespan"`
+	LoadImbalance float64        `json:"loadImbalance"` // requests of the busiest pod over the mean
+	PrefixHitRate float64        `json:"prefixHitRate"` // cached prompt tokens over all prompt tokens
+	PodRequests   map[string]int `json:"podRequests"`
+}
+
+type arrival struct {
+	time         float64
+	inputTokens  int
+	outputTokens int
+}
+
+type inflight struct {
+	ctx      context.Context
+	feedback *routing.Feedback
+	pod      *simPod
+}
+
+// Run replays the trace windows against the router on a simulated fleet, using a virtual clock so hours of trace
+// replay in seconds. Routers read pods and metrics from the cache, so cache.NewOfflineCache must be called before
+// the router is created. Runs share the cache and must not be concurrent.
+func Run(ctx context.Context, router routing.Router, windows []TraceWindow, config Config) (Result, error) {
+	if err := config.validate(); err != nil {
+		return Result{}, err
+	}
+	c, err := cache.GetCache()
+	if err != nil {
+		return Result{}, fmt.Errorf("call cache.NewOfflineCache before creating routers: %v", err)
+	}
+	rnd := rand.New(rand.NewSource(config.Seed))
+	arrivals := expandTrace(windows, config.RateScale, rnd)
+	if len(arrivals) == 0 {
+		return Result{}, errors.New("trace has no requests")
+	}
+
+	avgInput, avgOutput := 0.0, 0.0
+	for _, a := range arrivals {
+		avgInput += float64(a.inputTokens)
+		avgOutput += float64(a.outputTokens)
+	}
+	avgInput /= float64(len(arrivals))
+	avgOutput /= float64(len(arrivals))
+
+	fleet := make([]*simPod, config.Pods)
+	pods := make(map[string]*v1.Pod, config.Pods)
+	podsByIP := make(map[string]*simPod, config.Pods)
+	for i := range fleet {
+		fleet[i] = newSimPod(i, config.Model, config.Profile)
+		pods[fleet[i].pod.Name] = fleet[i].pod
+		podsByIP[fleet[i].pod.Status.PodIP] = fleet[i]
+		c.SetPod(fleet[i].pod, config.Model)
+	}
+	scraper := newMetricScraper(c, config.Model, avgInput, avgOutput)
+	scraper.refresh(fleet, config.RefreshInterval.Seconds())
+
+	result := Result{Requests: len(arrivals), PodRequests: map[string]int{}}
+	var latencies, ttfts []float64
+	var queueTime, promptTokens, cachedTokens, finished float64
+	requests := map[*simRequest]inflight{}
+	done := func(req *simRequest) {
+		r := requests[req]
+		delete(requests, req)
+		finished = math.Max(finished, r.pod.clock)
+		latencies = append(latencies, r.pod.clock-req.arrival)
+		ttfts = append(ttfts, req.firstToken-req.arrival)
+		queueTime += req.admitted - req.arrival
+		promptTokens += float64(req.inputTokens)
+		cachedTokens += float64(req.cachedTokens)
+		scraper.observe(r.pod, req)
+		routing.RecordUsage(r.ctx, int64(req.inputTokens), int64(req.cachedTokens))
+		r.feedback.Report(true)
+	}
+
+	lastRefresh := 0.0
+	for i, a := range arrivals {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+		for _, pod := range fleet {
+			pod.advance(a.time, done)
+		}
+		if a.time-lastRefresh >= config.RefreshInterval.Seconds() {
+			scraper.refresh(fleet, a.time-lastRefresh)
+			lastRefresh = a.time
+		}
+
+		group := rnd.Intn(config.PrefixGroups)
+		prefixTokens := int(float64(a.inputTokens) * config.SharedPrefixRatio)
+		reqCtx, feedback := routing.WithFeedback(ctx)
+		address, err := router.Route(reqCtx, pods, config.Model, prompt(i, group, a.inputTokens, config))
+		pod := podsByIP[podHost(address)]
+		if err != nil || pod == nil {
+			klog.V(4).Infof("request %d failed to route to %q: %v", i, address, err)
+			feedback.Report(false)
+			result.Failed++
+			continue
+		}
+		req := &simRequest{id: i, group: group, inputTokens: a.inputTokens, outputTokens: a.outputTokens, arrival: a.time}
+		requests[req] = inflight{ctx: reqCtx, feedback: feedback, pod: pod}
+		pod.enqueue(req, prefixTokens)
+		result.PodRequests[pod.pod.Name]++
+	}
+	for _, pod := range fleet {
+		pod.advance(math.Inf(1), done)
+	}
+
+	for _, r := range requests {
+		klog.Warningf("request left on pod %s after the replay", r.pod.pod.Name)
+	}
+	completed := float64(len(latencies))
+	if completed > 0 {
+		result.LatencyP50 = percentile(latencies, 50)
+		result.LatencyP99 = percentile(latencies, 99)
+		result.TTFTP50 = percentile(ttfts, 50)
+		result.TTFTP99 = percentile(ttfts, 99)
+		result.MeanQueueTime = queueTime / completed
+		result.Makespan = finished
+	}
+	if promptTokens > 0 {
+		result.PrefixHitRate = cachedTokens / promptTokens
+	}
+	busiest, total := 0, 0
+	for _, pod := range fleet {
+		total += result.PodRequests[pod.pod.Name]
+		if result.PodRequests[pod.pod.Name] > busiest {
+			busiest = result.PodRequests[pod.pod.Name]
+		}
+	}
+	if total > 0 {
+		result.LoadImbalance = float64(busiest) / (float64(total) / float64(len(fleet)))
+	}
+	return result, nil
+}
+
+// expandTrace turns the buckets of every window into requests arriving evenly spread over the window, in random order.
+func expandTrace(windows []TraceWindow, rateScale float64, rnd *rand.Rand) []arrival {
+	var arrivals []arrival
+	for _, window := range windows {
+		var batch []arrival
+		for _, bucket := range window.Buckets {
+			for i := 0; i < int(math.Round(float64(bucket.Count)*rateScale)); i++ {
+				batch = append(batch, arrival{inputTokens: max(bucket.InputTokens, 1), outputTokens: max(bucket.OutputTokens, 1)})
+			}
+		}
+		rnd.Shuffle(len(batch), func(i, j int) { batch[i], batch[j] = batch[j], batch[i] })
+		offset := window.Start.Sub(windows[0].Start).Seconds()
+		for i := range batch {
+			batch[i].time = offset + (float64(i)+0.5)*window.Interval.Seconds()/float64(len(batch))
+		}
+		arrivals = append(arrivals, batch...)
+	}
+	sort.SliceStable(arrivals, func(i, j int) bool { return arrivals[i].time < arrivals[j].time })
+	return arrivals
+}
+
+// prompt synthesizes a prompt starting with the shared prefix of the group, followed by words unique to the request.
+func prompt(id, group, inputTokens int, config Config) string {
+	words := min(inputTokens, config.MaxPromptWords)
+	prefixWords := int(float64(words) * config.SharedPrefixRatio)
+	var sb strings.Builder
+	for i := 0; i < words; i++ {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		if i < prefixWords {
+			fmt.Fprintf(&sb, "g%d-%d", group, i)
+		} else {
+			fmt.Fprintf(&sb, "r%d-%d", id, i)
+		}
+	}
+	return sb.String()
+}
+
+func podHost(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	index := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(index, 0)]
+}
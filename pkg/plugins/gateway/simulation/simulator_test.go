@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	routing "github.com/vllm-project/aibrix/pkg/plugins/gateway/algorithms"
+)
+
+func TestParseTraceWindow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	window, err := ParseTraceWindow(start, []byte(`{"meta_v": 3, "meta_interval_sec": 5, "meta_precision": 10, "meta_total_reqs": 7, "100:70": 4, "93:50": 3}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, start, window.Start)
+	assert.Equal(t, 5*time.Second, window.Interval)
+	assert.Equal(t, 7, window.Requests)
+	assert.Equal(t, []TraceBucket{
+		{InputTokens: 630, OutputTokens: 32, Count: 3},
+		{InputTokens: 1024, OutputTokens: 128, Count: 4},
+	}, window.Buckets)
+
+	// v1 traces have no meta data.
+	window, err = ParseTraceWindow(start, []byte(`{"10:10": 1}`))
+	require.NoError(t, err)
+	assert.Equal(t, cache.RequestTraceWriteInterval, window.Interval)
+	assert.Equal(t, 1, window.Requests)
+	assert.Equal(t, []TraceBucket{{InputTokens: 2, OutputTokens: 2, Count: 1}}, window.Buckets)
+
+	_, err = ParseTraceWindow(start, []byte(`{"10": 1}`))
+	assert.Error(t, err)
+
+	data, err := config.EncodeRequestTrace(map[string]int{"meta_v": 3, "meta_interval_sec": 5, "meta_precision": 10, "100:70": 4}, config.RequestTraceEncodingBinary)
+	require.NoError(t, err)
+	window, err = ParseTraceWindow(start, data)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, window.Interval)
+	assert.Equal(t, []TraceBucket{{InputTokens: 1024, OutputTokens: 128, Count: 4}}, window.Buckets)
+}
+
+func TestLoadStoreTraces(t *testing.T) {
+	ctx := context.Background()
+	store := kvstore.NewMemoryStore()
+	traceConfig := config.RequestTraceConfig{Interval: 5 * time.Second, TTL: time.Hour, KeyPrefix: "aibrix:", KeySchema: config.RequestTraceKeySchemaV2}
+	meta, err := json.Marshal(traceConfig.Meta(cache.RequestTraceVersion, 10))
+	require.NoError(t, err)
+	require.NoError(t, store.Set(ctx, traceConfig.MetaKey(), meta, 0))
+	require.NoError(t, store.Set(ctx, traceConfig.Key("llama", 1700000005), []byte(`{"10:10": 2}`), 0))
+	require.NoError(t, store.Set(ctx, traceConfig.Key("llama", 1700000000), []byte(`{"10:10": 1}`), 0))
+	require.NoError(t, store.Set(ctx, traceConfig.Key("llama-70b", 1700000000), []byte(`{"10:10": 3}`), 0))
+
+	// the v2 keys are found through the meta key.
+	windows, err := LoadStoreTraces(ctx, store, "llama")
+	require.NoError(t, err)
+	require.Len(t, windows, 2)
+	assert.Equal(t, time.Unix(1700000000, 0), windows[0].Start)
+	assert.Equal(t, 1, windows[0].Buckets[0].Count)
+	assert.Equal(t, 2, windows[1].Buckets[0].Count)
+}
+
+func TestSimPodServesBatch(t *testing.T) {
+	pod := newSimPod(0, "llama", PodProfile{MaxRunning: 1, PrefillTokensPerSecond: 1000, DecodeTokensPerSecond: 10, KVCacheTokens: 10000})
+	var done []*simRequest
+	pod.enqueue(&simRequest{id: 1, inputTokens: 100, outputTokens: 10}, 0)
+	pod.enqueue(&simRequest{id: 2, inputTokens: 100, outputTokens: 10}, 0)
+
+	pod.advance(1.05, func(req *simRequest) { done = append(done, req) })
+	require.Len(t, done, 0)
+	assert.Len(t, pod.waiting, 1, "the batch holds a single request")
+
+	pod.advance(10, func(req *simRequest) { done = append(done, req) })
+	require.Len(t, done, 2)
+	assert.InDelta(t, 0.1, done[0].firstToken, 1e-6)
+	assert.InDelta(t, 1.1, done[1].admitted, 1e-6)
+	assert.InDelta(t, 1.2, done[1].firstToken, 1e-6)
+}
+
+func TestSimPodPrefixCache(t *testing.T) {
+	pod := newSimPod(0, "llama", PodProfile{MaxRunning: 4, PrefillTokensPerSecond: 1000, DecodeTokensPerSecond: 10, KVCacheTokens: 10000, PrefixCacheGroups: 1})
+	first, second, third := &simRequest{group: 1, inputTokens: 100}, &simRequest{group: 1, inputTokens: 100}, &simRequest{group: 2, inputTokens: 100}
+	pod.enqueue(first, 50)
+	pod.enqueue(second, 50)
+	pod.enqueue(third, 50)
+	assert.Equal(t, 0, first.cachedTokens)
+	assert.Equal(t, 50, second.cachedTokens)
+	assert.Equal(t, 0, third.cachedTokens)
+	_, ok := pod.prefixLookup[1]
+	assert.False(t, ok, "group 1 is evicted by group 2")
+}
+
+func TestRun(t *testing.T) {
+	cache.NewOfflineCache()
+	router, err := routing.NewLeastRequestRouter()
+	require.NoError(t, err)
+
+	start := time.Unix(1700000000, 0)
+	windows := []TraceWindow{
+		{Start: start, Interval: 10 * time.Second, Buckets: []TraceBucket{{InputTokens: 512, OutputTokens: 64, Count: 40}}},
+		{Start: start.Add(10 * time.Second), Interval: 10 * time.Second, Buckets: []TraceBucket{{InputTokens: 1024, OutputTokens: 128, Count: 20}}},
+	}
+	config := DefaultConfig("llama", 4)
+	result, err := Run(context.Background(), router, windows, config)
+	require.NoError(t, err)
+
+	assert.Equal(t, 60, result.Requests)
+	assert.Equal(t, 0, result.Failed)
+	total := 0
+	for _, count := range result.PodRequests {
+		total += count
+	}
+	assert.Equal(t, 60, total)
+	assert.Greater(t, result.LatencyP99, result.TTFTP99)
+	assert.GreaterOrEqual(t, result.LatencyP99, result.LatencyP50)
+	assert.Greater(t, result.PrefixHitRate, 0.0)
+	assert.Less(t, result.LoadImbalance, 2.0)
+
+	again, err := Run(context.Background(), router, windows, config)
+	require.NoError(t, err)
+	assert.InDelta(t, result.LatencyP50, again.LatencyP50, 0.01, "replays are reproducible")
+
+	_, err = Run(context.Background(), router, nil, config)
+	assert.Error(t, err)
+}
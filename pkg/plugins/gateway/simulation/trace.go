@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vllm-project/aibrix/pkg/cache"
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+const (
+	// defaultTraceInterval and defaultTracePrecision apply to v1 traces without meta data.
+	defaultTraceInterval  = cache.RequestTraceWriteInterval
+	defaultTracePrecision = 1 / cache.RequestTracePrecision
+)
+
+// TraceWindow is one interval of the request trace the gateway writes to the kv store, by default under
+// aibrix:<model>_request_trace_<unix_seconds>.
+type TraceWindow struct {
+	Start    time.Time
+	Interval time.Duration
+	// Requests is the number of requests received in the window, completed or not. The buckets only hold the
+	// completed ones.
+	Requests int
+	Buckets  []TraceBucket
+}
+
+// TraceBucket counts the requests of a window with about the same input and output tokens.
+type TraceBucket struct {
+	InputTokens  int
+	OutputTokens int
+	Count        int
+}
+
+// ParseTraceWindow parses a trace written by the gateway. Buckets are keyed by the log2 of input and output tokens
+// scaled by the precision, e.g. "93:70" with precision 10 holds requests with about 2^9.3 input and 2^7 output tokens.
+func ParseTraceWindow(start time.Time, data []byte) (TraceWindow, error) {
+	trace, err := config.DecodeRequestTrace(data)
+	if err != nil {
+		return TraceWindow{}, err
+	}
+
+	window := TraceWindow{Start: start, Interval: defaultTraceInterval}
+	if interval, ok := trace[cache.MetaKeyIntervalInSeconds.ToString()]; ok && interval > 0 {
+		window.Interval = time.Duration(interval) * time.Second
+	}
+	precision := defaultTracePrecision
+	if value, ok := trace[cache.MetaKeyTracePrecision.ToString()]; ok && value > 0 {
+		precision = float64(value)
+	}
+
+	for key, count := range trace {
+		if strings.HasPrefix(key, "meta_") || count <= 0 {
+			continue
+		}
+		input, output, found := strings.Cut(key, ":")
+		if !found {
+			return TraceWindow{}, fmt.Errorf("invalid trace key: %s", key)
+		}
+		inputIndex, err := strconv.Atoi(input)
+		if err != nil {
+			return TraceWindow{}, fmt.Errorf("invalid trace key: %s", key)
+		}
+		outputIndex, err := strconv.Atoi(output)
+		if err != nil {
+			return TraceWindow{}, fmt.Errorf("invalid trace key: %s", key)
+		}
+		window.Requests += count
+		window.Buckets = append(window.Buckets, TraceBucket{
+			InputTokens:  int(math.Round(math.Pow(2, float64(inputIndex)/precision))),
+			OutputTokens: int(math.Round(math.Pow(2, float64(outputIndex)/precision))),
+			Count:        count,
+		})
+	}
+	// traces before v3 only count completed requests.
+	if total, ok := trace[cache.MetaKeyTotalRequests.ToString()]; ok {
+		window.Requests = total
+	}
+	// map iteration is random, keep replays reproducible.
+	sort.Slice(window.Buckets, func(i, j int) bool {
+		if window.Buckets[i].InputTokens != window.Buckets[j].InputTokens {
+			return window.Buckets[i].InputTokens < window.Buckets[j].InputTokens
+		}
+		return window.Buckets[i].OutputTokens < window.Buckets[j].OutputTokens
+	})
+	return window, nil
+}
+
+// LoadStoreTraces reads the trace windows of the model from the kv store the gateway writes them to, ordered by time.
+// The keys are laid out as described by the trace meta key, or by the local configuration without one.
+func LoadStoreTraces(ctx context.Context, store kvstore.Store, model string) ([]TraceWindow, error) {
+	traceConfig := config.RequestTrace()
+	if data, err := store.Get(ctx, traceConfig.MetaKey()); err == nil {
+		if written, err := config.ParseRequestTraceMeta(data); err == nil {
+			traceConfig = written
+		}
+	}
+	prefix := traceConfig.ModelKeyPrefix(model)
+	var windows []TraceWindow
+	err := store.Scan(ctx, prefix, func(key string, data []byte) error {
+		timestamp, err := strconv.ParseInt(strings.TrimPrefix(key, prefix), 10, 64)
+		if err != nil {
+			return nil // another model sharing the prefix
+		}
+		window, err := ParseTraceWindow(time.Unix(timestamp, 0), data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		windows = append(windows, window)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortWindows(windows)
+	return windows, nil
+}
+
+// LoadTraceFile reads trace windows from a file with one JSON object per line, holding the unix timestamp
+// of the window and the trace as written to Redis, e.g. {"timestamp": 1700000000, "trace": {"93:70": 4}}.
+func LoadTraceFile(path string) ([]TraceWindow, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var windows []TraceWindow
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record struct {
+			Timestamp int64           `json:"timestamp"`
+			Trace     json.RawMessage `json:"trace"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		window, err := ParseTraceWindow(time.Unix(record.Timestamp, 0), record.Trace)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		windows = append(windows, window)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortWindows(windows)
+	return windows, nil
+}
+
+func sortWindows(windows []TraceWindow) {
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+}
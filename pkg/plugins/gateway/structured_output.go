@@ -0,0 +1,187 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/structuredoutput"
+)
+
+const (
+	// HeaderErrorInvalidResponseFormat reports a malformed response_format or guided decoding parameter.
+	HeaderErrorInvalidResponseFormat = "x-error-invalid-response-format"
+
+	finishReasonLength    = "length"
+	finishReasonToolCalls = "tool_calls"
+)
+
+var (
+	structuredOutputValidations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_structured_output_validations_total",
+		Help: "Outputs of the model checked against the response format or guided decoding of their request.",
+	}, []string{"model"})
+	structuredOutputViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_structured_output_violations_total",
+		Help: "Outputs of the model violating the response format or guided decoding of their request, by reason.",
+	}, []string{"model", "reason"})
+)
+
+func init() {
+	prometheus.MustRegister(structuredOutputValidations, structuredOutputViolations)
+}
+
+// requestStructuredOutput is the output constraint of a request whose outputs are validated, with the outputs
+// streamed so far.
+type requestStructuredOutput struct {
+	spec    *structuredoutput.Spec
+	outputs map[int64]*streamedOutput
+}
+
+type streamedOutput struct {
+	content      strings.Builder
+	finishReason string
+}
+
+type requestStructuredOutputKey struct{}
+
+// withStructuredOutput holds the output constraint of the request, recorded by the request body phase for the
+// response phases.
+func withStructuredOutput(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestStructuredOutputKey{}, &requestStructuredOutput{})
+}
+
+func structuredOutputFrom(ctx context.Context) *requestStructuredOutput {
+	output, _ := ctx.Value(requestStructuredOutputKey{}).(*requestStructuredOutput)
+	return output
+}
+
+// parseStructuredOutput checks the output constraint of chat and completion requests, and records it if the model
+// validates its outputs.
+func parseStructuredOutput(ctx context.Context, endpoint string, modelConfig ModelConfig, body map[string]interface{}) error {
+	if endpoint != EndpointChatCompletions && endpoint != EndpointCompletions {
+		return nil
+	}
+	spec, err := structuredoutput.Parse(body)
+	if err != nil {
+		return err
+	}
+	if output := structuredOutputFrom(ctx); output != nil && spec != nil && modelConfig.ValidateStructuredOutput {
+		output.spec = spec
+	}
+	return nil
+}
+
+// appendStructuredOutput accumulates the content of a streamed chat completion chunk.
+func appendStructuredOutput(ctx context.Context, chunk openai.ChatCompletionChunk) {
+	output := structuredOutputFrom(ctx)
+	if output == nil || output.spec == nil {
+		return
+	}
+	if output.outputs == nil {
+		output.outputs = map[int64]*streamedOutput{}
+	}
+	for _, choice := range chunk.Choices {
+		streamed, ok := output.outputs[choice.Index]
+		if !ok {
+			streamed = &streamedOutput{}
+			output.outputs[choice.Index] = streamed
+		}
+		streamed.content.WriteString(choice.Delta.Content)
+		if choice.FinishReason != "" {
+			streamed.finishReason = string(choice.FinishReason)
+		}
+	}
+}
+
+// validateStructuredOutput checks the outputs of the request against its constraint once the response completed,
+// the body of non streamed responses or else the streamed chunks. Violations are logged and counted, the response is
+// passed as is.
+func validateStructuredOutput(ctx context.Context, requestID, model string, stream bool, body []byte) {
+	output := structuredOutputFrom(ctx)
+	if output == nil || output.spec == nil {
+		return
+	}
+	var outputs []*streamedOutput
+	if stream {
+		indexes := make([]int64, 0, len(output.outputs))
+		for index := range output.outputs {
+			indexes = append(indexes, index)
+		}
+		sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+		for _, index := range indexes {
+			outputs = append(outputs, output.outputs[index])
+		}
+	} else {
+		var response struct {
+			Choices []struct {
+				// text of completions, message content of chat completions.
+				Text    string `json:"text"`
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+				FinishReason string `json:"finish_reason"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return
+		}
+		for _, choice := range response.Choices {
+			streamed := &streamedOutput{finishReason: choice.FinishReason}
+			streamed.content.WriteString(choice.Text + choice.Message.Content)
+			outputs = append(outputs, streamed)
+		}
+	}
+
+	for i, streamed := range outputs {
+		if streamed.finishReason == finishReasonToolCalls {
+			// the model called tools instead of answering.
+			continue
+		}
+		structuredOutputValidations.WithLabelValues(model).Inc()
+		if err := output.spec.Validate(streamed.content.String()); err != nil {
+			reason := violationReason(err)
+			if streamed.finishReason == finishReasonLength {
+				reason = "truncated"
+			}
+			klog.InfoS("output violates the structure of the request", "requestID", requestID, "model", model, "choice", i, "reason", reason, "error", err)
+			structuredOutputViolations.WithLabelValues(model, reason).Inc()
+		}
+	}
+}
+
+func violationReason(err error) string {
+	switch {
+	case errors.Is(err, structuredoutput.ErrInvalidJSON):
+		return "invalid_json"
+	case errors.Is(err, structuredoutput.ErrSchemaViolation):
+		return "schema"
+	case errors.Is(err, structuredoutput.ErrChoiceViolation):
+		return "choice"
+	case errors.Is(err, structuredoutput.ErrRegexViolation):
+		return "regex"
+	}
+	return "unknown"
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuredOutput(t *testing.T) {
+	body := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"messages": [], "response_format": {"type": "json_schema", "json_schema": {"name": "answer", "schema": {"type": "object", "required": ["answer"]}}}}`), &body))
+	validate := ModelConfig{ValidateStructuredOutput: true}
+
+	// the outputs of models not validating them are not checked.
+	ctx := withStructuredOutput(context.Background())
+	assert.NoError(t, parseStructuredOutput(ctx, EndpointChatCompletions, ModelConfig{}, body))
+	assert.Nil(t, structuredOutputFrom(ctx).spec)
+
+	ctx = withStructuredOutput(context.Background())
+	assert.NoError(t, parseStructuredOutput(ctx, EndpointChatCompletions, validate, body))
+	checked := testutil.ToFloat64(structuredOutputValidations.WithLabelValues("llama"))
+	violations := testutil.ToFloat64(structuredOutputViolations.WithLabelValues("llama", "schema"))
+	truncated := testutil.ToFloat64(structuredOutputViolations.WithLabelValues("llama", "truncated"))
+	validateStructuredOutput(ctx, "r1", "llama", false, []byte(`{"choices": [
+		{"message": {"content": "{\"answer\": 42}"}, "finish_reason": "stop"},
+		{"message": {"content": "{\"result\": 42}"}, "finish_reason": "stop"},
+		{"message": {"content": "{\"ans"}, "finish_reason": "length"}
+	]}`))
+	assert.Equal(t, checked+3, testutil.ToFloat64(structuredOutputValidations.WithLabelValues("llama")))
+	assert.Equal(t, violations+1, testutil.ToFloat64(structuredOutputViolations.WithLabelValues("llama", "schema")))
+	assert.Equal(t, truncated+1, testutil.ToFloat64(structuredOutputViolations.WithLabelValues("llama", "truncated")))
+
+	// streamed outputs are validated once complete.
+	ctx = withStructuredOutput(context.Background())
+	assert.NoError(t, parseStructuredOutput(ctx, EndpointChatCompletions, validate, body))
+	for _, content := range []string{`{"ans`, `wer": `, `42}`} {
+		appendStructuredOutput(ctx, openai.ChatCompletionChunk{Choices: []openai.ChatCompletionChunkChoice{{Delta: openai.ChatCompletionChunkChoicesDelta{Content: content}}}})
+	}
+	violations = testutil.ToFloat64(structuredOutputViolations.WithLabelValues("llama", "schema"))
+	validateStructuredOutput(ctx, "r2", "llama", true, nil)
+	assert.Equal(t, checked+4, testutil.ToFloat64(structuredOutputValidations.WithLabelValues("llama")))
+	assert.Equal(t, violations, testutil.ToFloat64(structuredOutputViolations.WithLabelValues("llama", "schema")))
+
+	// malformed constraints are rejected, other endpoints are not constrained.
+	body["response_format"] = map[string]interface{}{"type": "json_schema"}
+	assert.Error(t, parseStructuredOutput(withStructuredOutput(context.Background()), EndpointChatCompletions, validate, body))
+	assert.NoError(t, parseStructuredOutput(withStructuredOutput(context.Background()), EndpointEmbeddings, validate, body))
+}
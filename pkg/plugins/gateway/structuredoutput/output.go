@@ -0,0 +1,143 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package structuredoutput reads the output constraints of requests, the OpenAI response_format and the guided
+// decoding parameters of vLLM, and checks the outputs of the engines against them.
+package structuredoutput
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+var (
+	// ErrInvalidJSON is returned for outputs that must be JSON and are not.
+	ErrInvalidJSON = errors.New("output is not valid JSON")
+	// ErrSchemaViolation is returned for JSON outputs violating the schema of the request.
+	ErrSchemaViolation = errors.New("output violates the schema")
+	// ErrChoiceViolation is returned for outputs which are none of the choices of the request.
+	ErrChoiceViolation = errors.New("output is none of the choices")
+	// ErrRegexViolation is returned for outputs not matching the regular expression of the request.
+	ErrRegexViolation = errors.New("output does not match the regular expression")
+)
+
+// Spec is the structure a request constrains the output to.
+type Spec struct {
+	// JSON requires the output to be JSON, of Schema if set.
+	JSON   bool
+	Schema *Schema
+	// Choices of guided_choice, the output must be one of them.
+	Choices []string
+	// Regex of guided_regex, nil if RE2 can't compile it.
+	Regex *regexp.Regexp
+	// Guided is true for every constraint enforced by guided decoding, JSON schemas, choices, regular expressions
+	// and grammars. Plain JSON mode is not.
+	Guided bool
+}
+
+// Parse reads the response_format, guided_json, guided_choice, guided_regex and guided_grammar of the request body.
+// It returns nil if the output is not constrained, and an error if a constraint is malformed, e.g. an invalid schema.
+func Parse(body map[string]interface{}) (*Spec, error) {
+	spec := &Spec{}
+	constrained := false
+	if format, ok := body["response_format"].(map[string]interface{}); ok {
+		switch format["type"] {
+		case "json_object":
+			spec.JSON, constrained = true, true
+		case "json_schema":
+			jsonSchema, ok := format["json_schema"].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("response_format json_schema must be an object")
+			}
+			schema, err := Compile(jsonSchema["schema"])
+			if err != nil {
+				return nil, fmt.Errorf("invalid response_format: %w", err)
+			}
+			spec.JSON, spec.Schema, spec.Guided, constrained = true, schema, true, true
+		}
+	}
+	if value, ok := body["guided_json"]; ok && value != nil {
+		// the schema may be given as a JSON string.
+		if text, ok := value.(string); ok {
+			if err := json.Unmarshal([]byte(text), &value); err != nil {
+				return nil, fmt.Errorf("invalid guided_json: %w", err)
+			}
+		}
+		schema, err := Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid guided_json: %w", err)
+		}
+		spec.JSON, spec.Schema, spec.Guided, constrained = true, schema, true, true
+	}
+	if value, ok := body["guided_choice"]; ok && value != nil {
+		choices, ok := value.([]interface{})
+		if !ok || len(choices) == 0 {
+			return nil, fmt.Errorf("guided_choice must be a non empty list of strings")
+		}
+		for _, choice := range choices {
+			text, ok := choice.(string)
+			if !ok {
+				return nil, fmt.Errorf("guided_choice must be a non empty list of strings")
+			}
+			spec.Choices = append(spec.Choices, text)
+		}
+		spec.Guided, constrained = true, true
+	}
+	if value, ok := body["guided_regex"]; ok && value != nil {
+		pattern, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("guided_regex must be a string")
+		}
+		// the engines take Python regular expressions, those RE2 can't compile are not checked.
+		spec.Regex, _ = regexp.Compile(`^(?:` + pattern + `)$`)
+		spec.Guided, constrained = true, true
+	}
+	if value, ok := body["guided_grammar"]; ok && value != nil {
+		if _, ok := value.(string); !ok {
+			return nil, fmt.Errorf("guided_grammar must be a string")
+		}
+		spec.Guided, constrained = true, true
+	}
+	if !constrained {
+		return nil, nil
+	}
+	return spec, nil
+}
+
+// Validate checks the output of the engine against the spec, the returned error wraps one of the Err variables.
+func (s *Spec) Validate(output string) error {
+	if s.JSON {
+		var value interface{}
+		if err := json.Unmarshal([]byte(output), &value); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		}
+		if s.Schema != nil {
+			if err := s.Schema.Validate(value); err != nil {
+				return fmt.Errorf("%w: %v", ErrSchemaViolation, err)
+			}
+		}
+	}
+	if len(s.Choices) > 0 && !slices.Contains(s.Choices, output) {
+		return ErrChoiceViolation
+	}
+	if s.Regex != nil && !s.Regex.MatchString(output) {
+		return ErrRegexViolation
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structuredoutput
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	spec, err := Parse(decode(t, `{"prompt": "hi"}`).(map[string]interface{}))
+	assert.NoError(t, err)
+	assert.Nil(t, spec)
+	spec, err = Parse(decode(t, `{"prompt": "hi", "response_format": {"type": "text"}}`).(map[string]interface{}))
+	assert.NoError(t, err)
+	assert.Nil(t, spec)
+
+	spec, err = Parse(decode(t, `{"response_format": {"type": "json_object"}}`).(map[string]interface{}))
+	assert.NoError(t, err)
+	assert.True(t, spec.JSON)
+	assert.False(t, spec.Guided)
+	assert.NoError(t, spec.Validate(`{"answer": 42}`))
+	assert.ErrorIs(t, spec.Validate(`the answer is 42`), ErrInvalidJSON)
+
+	spec, err = Parse(decode(t, `{"response_format": {"type": "json_schema", "json_schema": {"name": "answer", "schema": {"type": "object", "required": ["answer"]}}}}`).(map[string]interface{}))
+	assert.NoError(t, err)
+	assert.True(t, spec.Guided)
+	assert.NoError(t, spec.Validate(`{"answer": 42}`))
+	assert.ErrorIs(t, spec.Validate(`{"result": 42}`), ErrSchemaViolation)
+
+	spec, err = Parse(decode(t, `{"guided_json": "{\"type\": \"integer\"}"}`).(map[string]interface{}))
+	assert.NoError(t, err)
+	assert.NoError(t, spec.Validate(`42`))
+	assert.ErrorIs(t, spec.Validate(`"42"`), ErrSchemaViolation)
+
+	spec, err = Parse(decode(t, `{"guided_choice": ["yes", "no"]}`).(map[string]interface{}))
+	assert.NoError(t, err)
+	assert.NoError(t, spec.Validate("yes"))
+	assert.ErrorIs(t, spec.Validate("maybe"), ErrChoiceViolation)
+
+	spec, err = Parse(decode(t, `{"guided_regex": "[0-9]+"}`).(map[string]interface{}))
+	assert.NoError(t, err)
+	assert.NoError(t, spec.Validate("42"))
+	assert.ErrorIs(t, spec.Validate("42 apples"), ErrRegexViolation)
+
+	// grammars and regular expressions RE2 can't compile are guided but not checked.
+	spec, err = Parse(decode(t, `{"guided_grammar": "root ::= \"yes\"", "guided_regex": "(?<=a)b"}`).(map[string]interface{}))
+	assert.NoError(t, err)
+	assert.True(t, spec.Guided)
+	assert.NoError(t, spec.Validate("anything"))
+
+	for _, body := range []string{
+		`{"response_format": {"type": "json_schema"}}`,
+		`{"response_format": {"type": "json_schema", "json_schema": {"name": "answer", "schema": {"type": "answer"}}}}`,
+		`{"guided_json": "{not json"}`,
+		`{"guided_choice": []}`,
+		`{"guided_choice": [1, 2]}`,
+		`{"guided_regex": 42}`,
+	} {
+		_, err := Parse(decode(t, body).(map[string]interface{}))
+		assert.Error(t, err, body)
+	}
+}
@@ -0,0 +1,381 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structuredoutput
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRefDepth bounds the $ref chain followed while validating, recursive schemas nest deeper than any real output.
+const maxRefDepth = 64
+
+var schemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+// Schema is a JSON schema of the output of the engine. It checks the keywords guided decoding backends enforce: type,
+// enum, const, properties, required, additionalProperties, items, prefixItems, length and range bounds, pattern,
+// allOf, anyOf, oneOf, not and local $ref. Other keywords are ignored, and oneOf is checked as anyOf.
+type Schema struct {
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// Compile checks the schema, a JSON object or boolean as decoded by encoding/json.
+func Compile(schema interface{}) (*Schema, error) {
+	s := &Schema{root: schema, patterns: map[string]*regexp.Regexp{}}
+	if err := s.check(schema, ""); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) check(node interface{}, path string) error {
+	if _, ok := node.(bool); ok {
+		return nil
+	}
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("schema%s must be an object or a boolean", at(path))
+	}
+
+	switch t := schema["type"].(type) {
+	case nil:
+	case string:
+		if !schemaTypes[t] {
+			return fmt.Errorf("schema%s has an unknown type %q", at(path), t)
+		}
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); !ok || !schemaTypes[name] {
+				return fmt.Errorf("schema%s has an unknown type %v", at(path), item)
+			}
+		}
+	default:
+		return fmt.Errorf("schema%s type must be a string or a list", at(path))
+	}
+	if required, ok := schema["required"]; ok {
+		list, ok := required.([]interface{})
+		if !ok {
+			return fmt.Errorf("schema%s required must be a list", at(path))
+		}
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return fmt.Errorf("schema%s required must list property names", at(path))
+			}
+		}
+	}
+	if enum, ok := schema["enum"]; ok {
+		if _, ok := enum.([]interface{}); !ok {
+			return fmt.Errorf("schema%s enum must be a list", at(path))
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		// patterns are ECMA 262 regular expressions, those RE2 can't compile are not checked.
+		if re, err := regexp.Compile(pattern); err == nil {
+			s.patterns[pattern] = re
+		}
+	}
+	if ref, ok := schema["$ref"]; ok {
+		ref, ok := ref.(string)
+		if !ok {
+			return fmt.Errorf("schema%s $ref must be a string", at(path))
+		}
+		if _, err := s.resolve(ref); err != nil {
+			return fmt.Errorf("schema%s: %w", at(path), err)
+		}
+	}
+
+	for _, keyword := range []string{"properties", "$defs", "definitions"} {
+		if value, ok := schema[keyword]; ok {
+			subschemas, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("schema%s %s must be an object", at(path), keyword)
+			}
+			for name, subschema := range subschemas {
+				if err := s.check(subschema, path+"/"+keyword+"/"+name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, keyword := range []string{"additionalProperties", "not"} {
+		if subschema, ok := schema[keyword]; ok {
+			if err := s.check(subschema, path+"/"+keyword); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range []string{"items", "prefixItems", "allOf", "anyOf", "oneOf"} {
+		value, ok := schema[keyword]
+		if !ok {
+			continue
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			if keyword == "items" {
+				if err := s.check(value, path+"/items"); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("schema%s %s must be a list", at(path), keyword)
+		}
+		for i, subschema := range list {
+			if err := s.check(subschema, path+"/"+keyword+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns the subschema at the local reference, a JSON pointer in the schema, e.g. #/$defs/step.
+func (s *Schema) resolve(ref string) (interface{}, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("only local $ref are supported, got %q", ref)
+	}
+	node := s.root
+	if pointer == "" {
+		return node, nil
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		schema, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+		if node, ok = schema[token]; !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+	}
+	return node, nil
+}
+
+// Validate checks the value, as decoded by encoding/json, against the schema.
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate(s.root, value, "", 0)
+}
+
+func (s *Schema) validate(node, value interface{}, path string, depth int) error {
+	if allowed, ok := node.(bool); ok {
+		if !allowed {
+			return fmt.Errorf("value%s is not allowed", at(path))
+		}
+		return nil
+	}
+	schema := node.(map[string]interface{})
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if depth >= maxRefDepth {
+			return fmt.Errorf("value%s nests more than %d references", at(path), maxRefDepth)
+		}
+		target, err := s.resolve(ref)
+		if err != nil {
+			return err
+		}
+		if err := s.validate(target, value, path, depth+1); err != nil {
+			return err
+		}
+	}
+	if err := checkType(schema["type"], value, path); err != nil {
+		return err
+	}
+	if expected, ok := schema["const"]; ok && !reflect.DeepEqual(expected, value) {
+		return fmt.Errorf("value%s must be %v", at(path), expected)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		return fmt.Errorf("value%s must be one of %v", at(path), enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if err := s.validateObject(schema, v, path, depth); err != nil {
+			return err
+		}
+	case []interface{}:
+		if err := s.validateArray(schema, v, path, depth); err != nil {
+			return err
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if bound, ok := schema["minLength"].(float64); ok && length < bound {
+			return fmt.Errorf("value%s is shorter than %v", at(path), bound)
+		}
+		if bound, ok := schema["maxLength"].(float64); ok && length > bound {
+			return fmt.Errorf("value%s is longer than %v", at(path), bound)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re := s.patterns[pattern]; re != nil && !re.MatchString(v) {
+				return fmt.Errorf("value%s does not match %q", at(path), pattern)
+			}
+		}
+	case float64:
+		if bound, ok := schema["minimum"].(float64); ok && v < bound {
+			return fmt.Errorf("value%s is less than %v", at(path), bound)
+		}
+		if bound, ok := schema["maximum"].(float64); ok && v > bound {
+			return fmt.Errorf("value%s is greater than %v", at(path), bound)
+		}
+		if bound, ok := schema["exclusiveMinimum"].(float64); ok && v <= bound {
+			return fmt.Errorf("value%s is not greater than %v", at(path), bound)
+		}
+		if bound, ok := schema["exclusiveMaximum"].(float64); ok && v >= bound {
+			return fmt.Errorf("value%s is not less than %v", at(path), bound)
+		}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, subschema := range all {
+			if err := s.validate(subschema, value, path, depth); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		if options, ok := schema[keyword].([]interface{}); ok {
+			matched := false
+			for _, subschema := range options {
+				if s.validate(subschema, value, path, depth) == nil {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return fmt.Errorf("value%s matches none of %s", at(path), keyword)
+			}
+		}
+	}
+	if not, ok := schema["not"]; ok && s.validate(not, value, path, depth) == nil {
+		return fmt.Errorf("value%s must not match the schema", at(path))
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(schema, object map[string]interface{}, path string, depth int) error {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				return fmt.Errorf("value%s misses the required property %q", at(path), name)
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional, hasAdditional := schema["additionalProperties"]
+	for name, value := range object {
+		if property, ok := properties[name]; ok {
+			if err := s.validate(property, value, path+"/"+name, depth); err != nil {
+				return err
+			}
+		} else if hasAdditional {
+			if err := s.validate(additional, value, path+"/"+name, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(schema map[string]interface{}, array []interface{}, path string, depth int) error {
+	length := float64(len(array))
+	if bound, ok := schema["minItems"].(float64); ok && length < bound {
+		return fmt.Errorf("value%s has fewer than %v items", at(path), bound)
+	}
+	if bound, ok := schema["maxItems"].(float64); ok && length > bound {
+		return fmt.Errorf("value%s has more than %v items", at(path), bound)
+	}
+	prefix, _ := schema["prefixItems"].([]interface{})
+	if tuple, ok := schema["items"].([]interface{}); ok {
+		prefix = tuple
+	}
+	for i, item := range array {
+		itemPath := path + "/" + strconv.Itoa(i)
+		if i < len(prefix) {
+			if err := s.validate(prefix[i], item, itemPath, depth); err != nil {
+				return err
+			}
+		} else if items, ok := schema["items"]; ok {
+			if _, tuple := items.([]interface{}); tuple {
+				continue
+			}
+			if err := s.validate(items, item, itemPath, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkType(schemaType, value interface{}, path string) error {
+	var types []interface{}
+	switch t := schemaType.(type) {
+	case nil:
+		return nil
+	case string:
+		types = []interface{}{t}
+	case []interface{}:
+		types = t
+	}
+	for _, t := range types {
+		if isType(t.(string), value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("value%s must be of type %v", at(path), schemaType)
+}
+
+func isType(schemaType string, value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return schemaType == "null"
+	case bool:
+		return schemaType == "boolean"
+	case string:
+		return schemaType == "string"
+	case float64:
+		return schemaType == "number" || (schemaType == "integer" && v == math.Trunc(v))
+	case []interface{}:
+		return schemaType == "array"
+	case map[string]interface{}:
+		return schemaType == "object"
+	}
+	return false
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// at formats the JSON pointer of a location for error messages, empty for the root.
+func at(path string) string {
+	if path == "" {
+		return ""
+	}
+	return " at " + path
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structuredoutput
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decode(t *testing.T, data string) interface{} {
+	var value interface{}
+	assert.NoError(t, json.Unmarshal([]byte(data), &value))
+	return value
+}
+
+func TestCompile(t *testing.T) {
+	for _, schema := range []string{
+		`{}`,
+		`true`,
+		`{"type": ["string", "null"]}`,
+		`{"$defs": {"step": {"type": "string"}}, "type": "array", "items": {"$ref": "#/$defs/step"}}`,
+	} {
+		_, err := Compile(decode(t, schema))
+		assert.NoError(t, err, schema)
+	}
+
+	for _, schema := range []string{
+		`"object"`,
+		`{"type": "text"}`,
+		`{"required": "name"}`,
+		`{"properties": {"name": "string"}}`,
+		`{"anyOf": {"type": "string"}}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "https://schemas/step.json"}`,
+	} {
+		_, err := Compile(decode(t, schema))
+		assert.Error(t, err, schema)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema, err := Compile(decode(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1, "pattern": "^[a-z]+$"},
+			"age": {"type": "integer", "minimum": 0},
+			"role": {"enum": ["admin", "user"]},
+			"steps": {"type": "array", "items": {"$ref": "#/$defs/step"}, "maxItems": 2}
+		},
+		"required": ["name", "age"],
+		"additionalProperties": false,
+		"$defs": {"step": {"anyOf": [{"type": "string"}, {"type": "null"}]}}
+	}`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, schema.Validate(decode(t, `{"name": "alice", "age": 30, "role": "admin", "steps": ["a", null]}`)))
+	for _, value := range []string{
+		`[]`,
+		`{"name": "alice"}`,
+		`{"name": "Alice", "age": 30}`,
+		`{"name": "", "age": 30}`,
+		`{"name": "alice", "age": 30.5}`,
+		`{"name": "alice", "age": -1}`,
+		`{"name": "alice", "age": 30, "role": "root"}`,
+		`{"name": "alice", "age": 30, "steps": [1]}`,
+		`{"name": "alice", "age": 30, "steps": ["a", "b", "c"]}`,
+		`{"name": "alice", "age": 30, "email": "alice@example.com"}`,
+	} {
+		assert.Error(t, schema.Validate(decode(t, value)), value)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"strings"
+
+	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+
+	"github.com/vllm-project/aibrix/pkg/tracing"
+)
+
+// requestSpans tracks the spans of a request through the gateway:
+// gateway.request covers the whole request, gateway.queue the time until a pod is selected,
+// upstream.ttft the time to the first response chunk and upstream.streaming the rest of the response.
+type requestSpans struct {
+	root      *tracing.Span
+	queue     *tracing.Span
+	ttft      *tracing.Span
+	streaming *tracing.Span
+}
+
+// startRequestSpans starts the request spans, joining the trace of the incoming traceparent header if any.
+func startRequestSpans(ctx context.Context, headers []*configPb.HeaderValue) (context.Context, *requestSpans) {
+	for _, header := range headers {
+		if strings.ToLower(header.Key) == tracing.TraceparentHeader {
+			if sc, ok := tracing.ParseTraceparent(string(header.RawValue)); ok {
+				ctx = tracing.ContextWithRemoteParent(ctx, sc)
+			}
+		}
+	}
+
+	spans := &requestSpans{}
+	ctx, spans.root = tracing.StartSpan(ctx, "gateway.request", tracing.SpanKindServer)
+	_, spans.queue = tracing.StartSpan(ctx, "gateway.queue", tracing.SpanKindInternal)
+	return ctx, spans
+}
+
+// routed ends the queue span and starts waiting for the first token from the engine.
+func (r *requestSpans) routed(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	r.queue.End()
+	_, r.ttft = tracing.StartSpan(ctx, "upstream.ttft", tracing.SpanKindClient)
+}
+
+// responseChunk records the arrival of a response body chunk.
+func (r *requestSpans) responseChunk(ctx context.Context, endOfStream bool) {
+	if r == nil {
+		return
+	}
+	if r.streaming == nil && r.ttft != nil {
+		r.ttft.End()
+		_, r.streaming = tracing.StartSpan(ctx, "upstream.streaming", tracing.SpanKindInternal)
+	}
+	if endOfStream {
+		r.streaming.End()
+	}
+}
+
+// end finishes all spans of the request, spans already ended are left untouched.
+func (r *requestSpans) end(attributes map[string]interface{}) {
+	if r == nil {
+		return
+	}
+	for key, value := range attributes {
+		r.root.SetAttribute(key, value)
+	}
+	r.queue.End()
+	r.ttft.End()
+	r.streaming.End()
+	r.root.End()
+}
+
+// traceparentHeader propagates the trace context of the request to the engine.
+func traceparentHeader(ctx context.Context) []*configPb.HeaderValueOption {
+	span := tracing.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return nil
+	}
+	return []*configPb.HeaderValueOption{{
+		Header: &configPb.HeaderValue{
+			Key:      tracing.TraceparentHeader,
+			RawValue: []byte(span.SpanContext().Traceparent()),
+		},
+	}}
+}
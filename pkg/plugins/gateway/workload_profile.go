@@ -0,0 +1,201 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+	"github.com/vllm-project/aibrix/pkg/plugins/gateway/simulation"
+)
+
+// WorkloadProfile summarizes the request traces of a model kept in the kv store, for capacity planning such as the
+// GPU optimizer.
+type WorkloadProfile struct {
+	Model           string    `json:"model"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	IntervalSeconds int       `json:"intervalSeconds"`
+	Requests        int       `json:"requests"`
+	// ArrivalRates are the requests per second of each interval from Start, intervals without trace are idle.
+	ArrivalRates []float64            `json:"arrivalRates"`
+	ArrivalRate  WorkloadDistribution `json:"arrivalRate"`
+	// InputTokens, OutputTokens and Buckets only count completed requests.
+	InputTokens  WorkloadDistribution `json:"inputTokens"`
+	OutputTokens WorkloadDistribution `json:"outputTokens"`
+	Buckets      []WorkloadBucket     `json:"buckets"`
+}
+
+// WorkloadDistribution summarizes the values of a workload profile.
+type WorkloadDistribution struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// WorkloadBucket counts the completed requests with about the same input and output tokens, Rate is their requests
+// per second over the profile.
+type WorkloadBucket struct {
+	InputTokens  int     `json:"inputTokens"`
+	OutputTokens int     `json:"outputTokens"`
+	Count        int     `json:"count"`
+	Rate         float64 `json:"rate"`
+}
+
+// WorkloadProfileHandler serves the workload profile of the model query parameter from the request traces in the
+// store, over the last window query parameter, e.g. 5m, or all traces kept.
+func WorkloadProfileHandler(store kvstore.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		model := r.URL.Query().Get("model")
+		if model == "" {
+			http.Error(w, "model query parameter is required", http.StatusBadRequest)
+			return
+		}
+		var window time.Duration
+		if value := r.URL.Query().Get("window"); value != "" {
+			var err error
+			if window, err = time.ParseDuration(value); err != nil || window <= 0 {
+				http.Error(w, "window query parameter must be a positive duration, e.g. 5m", http.StatusBadRequest)
+				return
+			}
+		}
+
+		windows, err := simulation.LoadStoreTraces(r.Context(), store, model)
+		if err != nil {
+			klog.ErrorS(err, "failed to load request traces", "model", model)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if window > 0 {
+			since := time.Now().Add(-window)
+			first := sort.Search(len(windows), func(i int) bool { return !windows[i].Start.Before(since) })
+			windows = windows[first:]
+		}
+		if len(windows) == 0 {
+			http.Error(w, "no request trace of the model", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(buildWorkloadProfile(model, windows)); err != nil {
+			klog.ErrorS(err, "failed to encode workload profile")
+		}
+	})
+}
+
+// buildWorkloadProfile summarizes trace windows ordered by time, at least one.
+func buildWorkloadProfile(model string, windows []simulation.TraceWindow) WorkloadProfile {
+	last := windows[len(windows)-1]
+	interval := last.Interval
+	profile := WorkloadProfile{
+		Model:           model,
+		Start:           windows[0].Start,
+		End:             last.Start.Add(interval),
+		IntervalSeconds: int(interval / time.Second),
+		Buckets:         []WorkloadBucket{},
+	}
+	profile.ArrivalRates = make([]float64, int(math.Ceil(float64(profile.End.Sub(profile.Start))/float64(interval))))
+
+	buckets := map[[2]int]int{}
+	for _, window := range windows {
+		profile.Requests += window.Requests
+		// the interval is the one of the latest window if it was reconfigured meanwhile.
+		if i := int(window.Start.Sub(profile.Start) / interval); i < len(profile.ArrivalRates) {
+			profile.ArrivalRates[i] += float64(window.Requests) / interval.Seconds()
+		}
+		for _, bucket := range window.Buckets {
+			buckets[[2]int{bucket.InputTokens, bucket.OutputTokens}] += bucket.Count
+		}
+	}
+	profile.ArrivalRate = distribution(profile.ArrivalRates, nil)
+
+	duration := profile.End.Sub(profile.Start).Seconds()
+	inputs, outputs, counts := []float64{}, []float64{}, []float64{}
+	for key, count := range buckets {
+		profile.Buckets = append(profile.Buckets, WorkloadBucket{
+			InputTokens:  key[0],
+			OutputTokens: key[1],
+			Count:        count,
+			Rate:         float64(count) / duration,
+		})
+	}
+	sort.Slice(profile.Buckets, func(i, j int) bool {
+		if profile.Buckets[i].InputTokens != profile.Buckets[j].InputTokens {
+			return profile.Buckets[i].InputTokens < profile.Buckets[j].InputTokens
+		}
+		return profile.Buckets[i].OutputTokens < profile.Buckets[j].OutputTokens
+	})
+	for _, bucket := range profile.Buckets {
+		inputs = append(inputs, float64(bucket.InputTokens))
+		outputs = append(outputs, float64(bucket.OutputTokens))
+		counts = append(counts, float64(bucket.Count))
+	}
+	profile.InputTokens = distribution(inputs, counts)
+	profile.OutputTokens = distribution(outputs, counts)
+	return profile
+}
+
+// distribution summarizes the values, each counted as often as its weight, or once without weights.
+func distribution(values, weights []float64) WorkloadDistribution {
+	if len(values) == 0 {
+		return WorkloadDistribution{}
+	}
+	if weights == nil {
+		weights = make([]float64, len(values))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return values[order[i]] < values[order[j]] })
+
+	var total, sum float64
+	for i, value := range values {
+		total += weights[i]
+		sum += value * weights[i]
+	}
+	if total == 0 {
+		return WorkloadDistribution{}
+	}
+	percentile := func(p float64) float64 {
+		rank, seen := math.Ceil(p/100*total), 0.0
+		for _, i := range order {
+			if seen += weights[i]; seen >= rank {
+				return values[i]
+			}
+		}
+		return values[order[len(order)-1]]
+	}
+	return WorkloadDistribution{
+		Mean: sum / total,
+		P50:  percentile(50),
+		P90:  percentile(90),
+		P99:  percentile(99),
+		Max:  values[order[len(order)-1]],
+	}
+}
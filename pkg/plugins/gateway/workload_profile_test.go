@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/config"
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+func TestWorkloadProfile(t *testing.T) {
+	ctx := context.Background()
+	store := kvstore.NewMemoryStore()
+	traceConfig := config.RequestTrace()
+	now := time.Now().Unix() / 10 * 10
+	traces := map[int64]string{
+		// the window 20 seconds ago has no request.
+		now - 30: `{"meta_v": 3, "meta_interval_sec": 10, "meta_precision": 10, "meta_total_reqs": 30, "100:70": 20, "70:100": 10}`,
+		now - 10: `{"meta_v": 3, "meta_interval_sec": 10, "meta_precision": 10, "meta_total_reqs": 12, "100:70": 10}`,
+		now:      `{"meta_v": 3, "meta_interval_sec": 10, "meta_precision": 10, "meta_total_reqs": 2}`,
+	}
+	for timestamp, trace := range traces {
+		require.NoError(t, store.Set(ctx, traceConfig.Key("llama", timestamp), []byte(trace), 0))
+	}
+
+	get := func(query string) (*httptest.ResponseRecorder, WorkloadProfile) {
+		recorder := httptest.NewRecorder()
+		WorkloadProfileHandler(store).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/workload-profile?"+query, nil))
+		var profile WorkloadProfile
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &profile))
+		}
+		return recorder, profile
+	}
+
+	recorder, profile := get("model=llama")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, time.Unix(now-30, 0), profile.Start.Local())
+	assert.Equal(t, time.Unix(now+10, 0), profile.End.Local())
+	assert.Equal(t, 10, profile.IntervalSeconds)
+	assert.Equal(t, 44, profile.Requests)
+	assert.Equal(t, []float64{3, 0, 1.2, 0.2}, profile.ArrivalRates)
+	assert.InDelta(t, 1.1, profile.ArrivalRate.Mean, 1e-9)
+	assert.Equal(t, 3.0, profile.ArrivalRate.Max)
+	assert.Equal(t, []WorkloadBucket{
+		{InputTokens: 128, OutputTokens: 1024, Count: 10, Rate: 0.25},
+		{InputTokens: 1024, OutputTokens: 128, Count: 30, Rate: 0.75},
+	}, profile.Buckets)
+	assert.Equal(t, WorkloadDistribution{Mean: 800, P50: 1024, P90: 1024, P99: 1024, Max: 1024}, profile.InputTokens)
+	assert.Equal(t, 128.0, profile.OutputTokens.P50)
+
+	// only the windows started in the last 25 seconds.
+	recorder, profile = get("model=llama&window=25s")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 14, profile.Requests)
+	assert.Equal(t, []float64{1.2, 0.2}, profile.ArrivalRates)
+
+	recorder, _ = get("model=qwen")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder, _ = get("window=5m")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder, _ = get("model=llama&window=later")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+const (
+	coldStartKeyPrefix = "aibrix:cold_start_"
+	// MaxColdStartSamples is the number of most recent cold starts the estimate of a model is computed from.
+	MaxColdStartSamples = 20
+)
+
+// ColdStart is the rolling estimate of how long a new pod of the model takes to serve, written by the gateway.
+type ColdStart struct {
+	Model string `json:"model"`
+	// Seconds is the P90 total duration of the Samples.
+	Seconds float64 `json:"seconds"`
+	// Samples are the most recent cold starts, ordered by the time the pods started serving.
+	Samples []ColdStartSample `json:"samples"`
+	Updated time.Time         `json:"updated"`
+}
+
+// ColdStartSample is the cold start of a pod, split in the time to schedule it, to start its containers, which includes
+// pulling the images, and to load the model until the engine serves, which includes loading the weights and capturing
+// the graphs.
+type ColdStartSample struct {
+	Pod               string    `json:"pod"`
+	SchedulingSeconds float64   `json:"schedulingSeconds"`
+	StartupSeconds    float64   `json:"startupSeconds"`
+	LoadingSeconds    float64   `json:"loadingSeconds"`
+	TotalSeconds      float64   `json:"totalSeconds"`
+	Serving           time.Time `json:"serving"`
+}
+
+// ColdStartKey returns the key of the cold start estimate of the model.
+func ColdStartKey(model string) string {
+	return coldStartKeyPrefix + model
+}
+
+// Add records the sample, replacing an earlier sample of the same pod, e.g. written by another gateway replica, and
+// updates the estimate.
+func (c *ColdStart) Add(sample ColdStartSample) {
+	samples := []ColdStartSample{sample}
+	for _, s := range c.Samples {
+		if s.Pod != sample.Pod {
+			samples = append(samples, s)
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Serving.Before(samples[j].Serving) })
+	if len(samples) > MaxColdStartSamples {
+		samples = samples[len(samples)-MaxColdStartSamples:]
+	}
+	c.Samples = samples
+
+	totals := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		totals = append(totals, s.TotalSeconds)
+	}
+	c.Seconds = percentile(totals, 90)
+}
+
+// SaveColdStart writes the cold start estimate of the model to the store, it doesn't expire.
+func SaveColdStart(ctx context.Context, store kvstore.Store, coldStart ColdStart) error {
+	data, err := json.Marshal(coldStart)
+	if err != nil {
+		return err
+	}
+	return store.Set(ctx, ColdStartKey(coldStart.Model), data, 0)
+}
+
+// LoadColdStart reads the cold start estimate of the model, kvstore.ErrNotFound if no pod of the model was seen starting.
+func LoadColdStart(ctx context.Context, store kvstore.Store, model string) (*ColdStart, error) {
+	data, err := store.Get(ctx, ColdStartKey(model))
+	if err != nil {
+		return nil, err
+	}
+	var coldStart ColdStart
+	if err := json.Unmarshal(data, &coldStart); err != nil {
+		return nil, fmt.Errorf("invalid cold start %s: %v", ColdStartKey(model), err)
+	}
+	return &coldStart, nil
+}
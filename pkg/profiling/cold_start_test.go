@@ -0,0 +1,63 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiling
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+func TestColdStartAdd(t *testing.T) {
+	start := time.Now()
+	coldStart := ColdStart{Model: "llama"}
+	for i := 0; i < MaxColdStartSamples+5; i++ {
+		coldStart.Add(ColdStartSample{Pod: fmt.Sprintf("llama-%d", i), TotalSeconds: float64(100 + i), Serving: start.Add(time.Duration(i) * time.Minute)})
+	}
+	require.Len(t, coldStart.Samples, MaxColdStartSamples)
+	assert.Equal(t, "llama-5", coldStart.Samples[0].Pod)
+	assert.Equal(t, 122.0, coldStart.Seconds)
+
+	// a pod reported again, e.g. by another gateway replica, replaces its sample.
+	coldStart.Add(ColdStartSample{Pod: "llama-24", TotalSeconds: 300, Serving: start.Add(24 * time.Minute)})
+	require.Len(t, coldStart.Samples, MaxColdStartSamples)
+	assert.Equal(t, 300.0, coldStart.Samples[MaxColdStartSamples-1].TotalSeconds)
+	assert.Equal(t, 122.0, coldStart.Seconds)
+}
+
+func TestSaveLoadColdStart(t *testing.T) {
+	ctx := context.Background()
+	store := kvstore.NewMemoryStore()
+	_, err := LoadColdStart(ctx, store, "llama")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+
+	coldStart := ColdStart{Model: "llama"}
+	coldStart.Add(ColdStartSample{Pod: "llama-0", StartupSeconds: 30, LoadingSeconds: 90, TotalSeconds: 125})
+	require.NoError(t, SaveColdStart(ctx, store, coldStart))
+	assert.Equal(t, "aibrix:cold_start_llama", ColdStartKey("llama"))
+
+	loaded, err := LoadColdStart(ctx, store, "llama")
+	require.NoError(t, err)
+	assert.Equal(t, 125.0, loaded.Seconds)
+	assert.Equal(t, 90.0, loaded.Samples[0].LoadingSeconds)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiling
+
+import (
+	"sort"
+	"time"
+)
+
+// Met tells whether the level is within the SLO, a level with failed requests is not.
+func (s SLO) Met(level Level) bool {
+	if level.Errors > 0 {
+		return false
+	}
+	if s.LatencyP90 > 0 && level.LatencyP90 > s.LatencyP90 {
+		return false
+	}
+	if s.TimePerOutputTokenP90 > 0 && level.TimePerOutputTokenP90 > s.TimePerOutputTokenP90 {
+		return false
+	}
+	return true
+}
+
+// Collect builds the capacity profile of the measured levels: the capacity is the one of the most concurrent level
+// within the SLO, as long as all less concurrent levels are too.
+func Collect(model, gpu string, inputTokens, outputTokens int, slo SLO, levels []Level) CapacityProfile {
+	profile := CapacityProfile{
+		Model:        model,
+		GPU:          gpu,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		SLO:          slo,
+		Levels:       append([]Level(nil), levels...),
+		Created:      time.Now(),
+	}
+	sort.Slice(profile.Levels, func(i, j int) bool { return profile.Levels[i].Concurrency < profile.Levels[j].Concurrency })
+	for _, level := range profile.Levels {
+		if !slo.Met(level) {
+			break
+		}
+		profile.MaxConcurrency = level.Concurrency
+		profile.TokensPerSecond = level.TokensPerSecond
+		profile.RequestsPerSecond = level.RequestsPerSecond
+	}
+	return profile
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollect(t *testing.T) {
+	slo := SLO{TimePerOutputTokenP90: 0.05}
+	levels := []Level{
+		{Concurrency: 4, Requests: 8, TokensPerSecond: 300, RequestsPerSecond: 2.4, TimePerOutputTokenP90: 0.03},
+		{Concurrency: 1, Requests: 8, TokensPerSecond: 90, RequestsPerSecond: 0.7, TimePerOutputTokenP90: 0.02},
+		{Concurrency: 8, Requests: 8, TokensPerSecond: 400, TimePerOutputTokenP90: 0.06},
+		{Concurrency: 16, Requests: 16, TokensPerSecond: 450, TimePerOutputTokenP90: 0.04, Errors: 1},
+	}
+
+	profile := Collect("llama", "L20", 512, 128, slo, levels)
+	assert.Equal(t, 4, profile.MaxConcurrency)
+	assert.Equal(t, 300.0, profile.TokensPerSecond)
+	assert.Equal(t, 2.4, profile.RequestsPerSecond)
+	assert.Equal(t, []int{1, 4, 8, 16}, []int{profile.Levels[0].Concurrency, profile.Levels[1].Concurrency,
+		profile.Levels[2].Concurrency, profile.Levels[3].Concurrency})
+
+	// without latency bounds, the failed requests still miss the SLO.
+	assert.Equal(t, 8, Collect("llama", "L20", 512, 128, SLO{}, levels).MaxConcurrency)
+	assert.Zero(t, Collect("llama", "L20", 512, 128, SLO{TimePerOutputTokenP90: 0.01}, levels).MaxConcurrency)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package profiling measures the capacity of a model served on a GPU type and keeps the capacity profiles, keyed by
+// model and GPU type, and the cold start estimates of the models in the kv store shared by the gateway and the
+// controllers.
+package profiling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+const keyPrefix = "aibrix:capacity_profile_"
+
+// CapacityProfile is the measured capacity of a pod serving a model on a GPU type, for requests of about InputTokens
+// and OutputTokens.
+type CapacityProfile struct {
+	Model        string `json:"model"`
+	GPU          string `json:"gpu"`
+	InputTokens  int    `json:"inputTokens"`
+	OutputTokens int    `json:"outputTokens"`
+	SLO          SLO    `json:"slo"`
+	// MaxConcurrency is the most concurrent requests a pod serves within the SLO, 0 if even one request misses it.
+	MaxConcurrency int `json:"maxConcurrency"`
+	// TokensPerSecond and RequestsPerSecond are the output tokens and requests a pod serves at MaxConcurrency.
+	TokensPerSecond   float64 `json:"tokensPerSecond"`
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	// Levels are the measurements of each concurrency, ordered by concurrency.
+	Levels  []Level   `json:"levels"`
+	Created time.Time `json:"created"`
+}
+
+// SLO bounds the latencies of the requests a pod serves, a zero bound is not checked.
+type SLO struct {
+	// LatencyP90 is the P90 end to end latency in seconds.
+	LatencyP90 float64 `json:"latencyP90,omitempty"`
+	// TimePerOutputTokenP90 is the P90 latency per output token in seconds.
+	TimePerOutputTokenP90 float64 `json:"timePerOutputTokenP90,omitempty"`
+}
+
+// Level is the measurement of a concurrency.
+type Level struct {
+	Concurrency           int     `json:"concurrency"`
+	Requests              int     `json:"requests"`
+	Errors                int     `json:"errors"`
+	RequestsPerSecond     float64 `json:"requestsPerSecond"`
+	TokensPerSecond       float64 `json:"tokensPerSecond"`
+	LatencyP90            float64 `json:"latencyP90"`
+	TimePerOutputTokenP90 float64 `json:"timePerOutputTokenP90"`
+}
+
+// Key returns the key of the capacity profile of the model on the GPU type.
+func Key(model, gpu string) string {
+	return fmt.Sprintf("%s%s_%s", keyPrefix, model, gpu)
+}
+
+// Save writes the capacity profile to the store, it replaces the previous profile of the model and GPU type and
+// doesn't expire.
+func Save(ctx context.Context, store kvstore.Store, profile CapacityProfile) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	return store.Set(ctx, Key(profile.Model, profile.GPU), data, 0)
+}
+
+// Load reads the capacity profile of the model on the GPU type, kvstore.ErrNotFound if it was not profiled.
+func Load(ctx context.Context, store kvstore.Store, model, gpu string) (*CapacityProfile, error) {
+	data, err := store.Get(ctx, Key(model, gpu))
+	if err != nil {
+		return nil, err
+	}
+	var profile CapacityProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("invalid capacity profile %s: %v", Key(model, gpu), err)
+	}
+	return &profile, nil
+}
+
+// List reads the capacity profiles of the model on all GPU types, ordered by GPU type.
+func List(ctx context.Context, store kvstore.Store, model string) ([]CapacityProfile, error) {
+	var profiles []CapacityProfile
+	err := store.Scan(ctx, keyPrefix+model+"_", func(key string, value []byte) error {
+		var profile CapacityProfile
+		if err := json.Unmarshal(value, &profile); err != nil {
+			return fmt.Errorf("invalid capacity profile %s: %v", key, err)
+		}
+		// the prefix of a model also matches the models whose name continues with an underscore.
+		if profile.Model == model && strings.HasSuffix(key, "_"+profile.GPU) {
+			profiles = append(profiles, profile)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].GPU < profiles[j].GPU })
+	return profiles, nil
+}
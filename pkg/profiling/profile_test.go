@@ -0,0 +1,53 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vllm-project/aibrix/pkg/kvstore"
+)
+
+func TestSaveLoadList(t *testing.T) {
+	ctx := context.Background()
+	store := kvstore.NewMemoryStore()
+	for _, profile := range []CapacityProfile{
+		{Model: "llama", GPU: "L20", MaxConcurrency: 32, TokensPerSecond: 1200},
+		{Model: "llama", GPU: "A10", MaxConcurrency: 16, TokensPerSecond: 500},
+		{Model: "llama_2", GPU: "A10", MaxConcurrency: 8},
+	} {
+		require.NoError(t, Save(ctx, store, profile))
+	}
+	assert.Equal(t, "aibrix:capacity_profile_llama_L20", Key("llama", "L20"))
+
+	profile, err := Load(ctx, store, "llama", "L20")
+	require.NoError(t, err)
+	assert.Equal(t, 32, profile.MaxConcurrency)
+	_, err = Load(ctx, store, "llama", "H100")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+
+	// the profiles of llama_2 share the key prefix of llama.
+	profiles, err := List(ctx, store, "llama")
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, "A10", profiles[0].GPU)
+	assert.Equal(t, "L20", profiles[1].GPU)
+}
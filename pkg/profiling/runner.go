@@ -0,0 +1,196 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// DefaultConcurrencies doubles the concurrent requests up to the largest batches engines run.
+var DefaultConcurrencies = []int{1, 2, 4, 8, 16, 32, 64, 128, 256}
+
+// Runner measures the capacity of an engine serving the model by sending it completions of InputTokens and
+// OutputTokens at increasing concurrencies.
+type Runner struct {
+	// Endpoint is the base URL of the OpenAI compatible engine, e.g. http://10.0.0.1:8000.
+	Endpoint string
+	Model    string
+	APIKey   string
+	// InputTokens is approximated by a prompt of as many words, OutputTokens are generated ignoring end of sequence.
+	InputTokens  int
+	OutputTokens int
+	// Concurrencies are measured in order, up to the first one missing the SLO.
+	Concurrencies []int
+	// RequestsPerLevel is the number of requests sent at each concurrency, at least the concurrency.
+	RequestsPerLevel int
+	SLO              SLO
+	Client           *http.Client
+}
+
+type completionUsage struct {
+	Usage struct {
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+type requestResult struct {
+	latency      time.Duration
+	outputTokens int
+	err          error
+}
+
+// Run measures the concurrencies, it fails if no request of the first concurrency succeeds.
+func (r *Runner) Run(ctx context.Context) ([]Level, error) {
+	var levels []Level
+	for i, concurrency := range r.Concurrencies {
+		level := r.measure(ctx, concurrency)
+		if err := ctx.Err(); err != nil {
+			return levels, err
+		}
+		if i == 0 && level.Errors == level.Requests {
+			return nil, fmt.Errorf("no request to %s succeeded", r.Endpoint)
+		}
+		klog.InfoS("Measured concurrency", "concurrency", concurrency, "requestsPerSecond", level.RequestsPerSecond,
+			"tokensPerSecond", level.TokensPerSecond, "latencyP90", level.LatencyP90, "errors", level.Errors)
+		levels = append(levels, level)
+		if !r.SLO.Met(level) {
+			break
+		}
+	}
+	return levels, nil
+}
+
+// measure sends the requests of the concurrency from as many workers.
+func (r *Runner) measure(ctx context.Context, concurrency int) Level {
+	requests := max(r.RequestsPerLevel, concurrency)
+	results := make(chan requestResult, requests)
+	next := make(chan struct{}, requests)
+	for i := 0; i < requests; i++ {
+		next <- struct{}{}
+	}
+	close(next)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range next {
+				results <- r.send(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+	return summarize(concurrency, time.Since(start), results)
+}
+
+func summarize(concurrency int, elapsed time.Duration, results <-chan requestResult) Level {
+	level := Level{Concurrency: concurrency}
+	var latencies, perToken []float64
+	tokens := 0
+	for result := range results {
+		level.Requests++
+		if result.err != nil {
+			level.Errors++
+			continue
+		}
+		tokens += result.outputTokens
+		latencies = append(latencies, result.latency.Seconds())
+		perToken = append(perToken, result.latency.Seconds()/float64(max(result.outputTokens, 1)))
+	}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		level.RequestsPerSecond = float64(level.Requests-level.Errors) / seconds
+		level.TokensPerSecond = float64(tokens) / seconds
+	}
+	level.LatencyP90 = percentile(latencies, 90)
+	level.TimePerOutputTokenP90 = percentile(perToken, 90)
+	return level
+}
+
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	index := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(index, 0)]
+}
+
+// send sends a completion and returns its latency and output tokens.
+func (r *Runner) send(ctx context.Context) requestResult {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      r.Model,
+		"prompt":     strings.TrimSpace(strings.Repeat("hello ", r.InputTokens)),
+		"max_tokens": r.OutputTokens,
+		"ignore_eos": true,
+		"stream":     false,
+	})
+	if err != nil {
+		return requestResult{err: err}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(r.Endpoint, "/")+"/v1/completions", bytes.NewReader(body))
+	if err != nil {
+		return requestResult{err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.APIKey)
+	}
+
+	start := time.Now()
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return requestResult{err: err}
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			klog.ErrorS(err, "error closing response body")
+		}
+	}()
+	data, err := io.ReadAll(resp.Body)
+	latency := time.Since(start)
+	if err != nil {
+		return requestResult{err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return requestResult{err: fmt.Errorf("%s: %s", resp.Status, data)}
+	}
+	var usage completionUsage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return requestResult{err: fmt.Errorf("invalid completion: %v", err)}
+	}
+	tokens := usage.Usage.CompletionTokens
+	if tokens == 0 {
+		tokens = r.OutputTokens
+	}
+	return requestResult{latency: latency, outputTokens: tokens}
+}
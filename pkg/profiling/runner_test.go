@@ -0,0 +1,73 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiling
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner(t *testing.T) {
+	var running atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/completions", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "llama", body["model"])
+		assert.Equal(t, 10.0, body["max_tokens"])
+
+		// the latency grows with the concurrent requests.
+		concurrent := running.Add(1)
+		defer running.Add(-1)
+		time.Sleep(time.Duration(concurrent) * 5 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"usage": {"completion_tokens": 10}}`))
+	}))
+	defer server.Close()
+
+	runner := Runner{
+		Endpoint:         server.URL,
+		Model:            "llama",
+		APIKey:           "key",
+		OutputTokens:     10,
+		Concurrencies:    []int{1, 2, 16, 32},
+		RequestsPerLevel: 4,
+		SLO:              SLO{LatencyP90: 0.05},
+		Client:           http.DefaultClient,
+	}
+	levels, err := runner.Run(context.Background())
+	require.NoError(t, err)
+	// 16 concurrent requests take about 80ms, the sweep stops there.
+	require.Len(t, levels, 3)
+	assert.Equal(t, 4, levels[0].Requests)
+	assert.Equal(t, 16, levels[2].Requests)
+	assert.Zero(t, levels[2].Errors)
+	assert.Greater(t, levels[0].TokensPerSecond, 0.0)
+	assert.Greater(t, levels[2].LatencyP90, 0.05)
+
+	runner.Endpoint = "http://127.0.0.1:1"
+	_, err = runner.Run(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,21 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package routerapi is the contract between the gateway and external routers. The gateway sends the candidate
+// pods of a request to the external router, which picks one of them. The service and its messages are defined in
+// routerapi.proto so routers can be written in any language, the Go code is generated from it with
+// make generate-proto.
+package routerapi
@@ -0,0 +1,156 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package runtimeapi is the client of the management API of the AI runtime sidecar. The sidecar runs next to the
+// inference engine and fronts its engine specific endpoints, so the control plane loads adapters, downloads models
+// and reloads the engine config the same way whatever the engine.
+package runtimeapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPort is the port of the runtime sidecar API.
+	DefaultPort = "8080"
+
+	EngineModelsPath       = "/v1/models"
+	LoadLoraAdapterPath    = "/v1/lora_adapter/load"
+	UnloadLoraAdapterPath  = "/v1/lora_adapter/unload"
+	DownloadModelPath      = "/v1/model/download"
+	ListModelsPath         = "/v1/model/list"
+	ReloadEngineConfigPath = "/v1/engine/config/reload"
+
+	defaultTimeout = 30 * time.Second
+)
+
+// ErrNotSupported is returned when the engine behind the sidecar doesn't support the operation.
+var ErrNotSupported = errors.New("not supported by the inference engine")
+
+// Client calls the runtime sidecar of a pod.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the sidecar at baseURL, e.g. http://10.0.0.1:8080. The api key, if set, is passed
+// to the engine.
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// ListEngineModels returns the base models and adapters served by the engine.
+func (c *Client) ListEngineModels(ctx context.Context) ([]EngineModel, error) {
+	resp := &listEngineModelsResponse{}
+	if err := c.do(ctx, http.MethodGet, EngineModelsPath, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// LoadLoraAdapter loads the adapter on the engine.
+func (c *Client) LoadLoraAdapter(ctx context.Context, req *LoadLoraAdapterRequest) error {
+	return c.do(ctx, http.MethodPost, LoadLoraAdapterPath, req, nil)
+}
+
+// UnloadLoraAdapter unloads the adapter from the engine.
+func (c *Client) UnloadLoraAdapter(ctx context.Context, req *UnloadLoraAdapterRequest) error {
+	return c.do(ctx, http.MethodPost, UnloadLoraAdapterPath, req, nil)
+}
+
+// DownloadModel starts downloading the model unless it is downloading or downloaded already, and returns its status.
+// It is polled until the status is ModelStatusDownloaded.
+func (c *Client) DownloadModel(ctx context.Context, req *DownloadModelRequest) (*ModelStatusCard, error) {
+	card := &ModelStatusCard{}
+	if err := c.do(ctx, http.MethodPost, DownloadModelPath, req, card); err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// ListModels returns the status of the models downloaded to localDir, the default directory of the sidecar if empty.
+func (c *Client) ListModels(ctx context.Context, localDir string) ([]ModelStatusCard, error) {
+	var req interface{}
+	if localDir != "" {
+		req = &listModelRequest{LocalDir: localDir}
+	}
+	resp := &listModelResponse{}
+	if err := c.do(ctx, http.MethodGet, ListModelsPath, req, resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// ReloadEngineConfig applies the config to the running engine without restarting it.
+func (c *Client) ReloadEngineConfig(ctx context.Context, req *ReloadEngineConfigRequest) error {
+	return c.do(ctx, http.MethodPost, ReloadEngineConfigPath, req, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotImplemented {
+		return fmt.Errorf("%s %s: %w", method, path, ErrNotSupported)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		data, _ := io.ReadAll(resp.Body)
+		errResp := &ErrorResponse{}
+		if json.Unmarshal(data, errResp) == nil && errResp.Message != "" {
+			return fmt.Errorf("%s %s failed with status %d: %s", method, path, resp.StatusCode, errResp.Message)
+		}
+		return fmt.Errorf("%s %s failed with status %d: %s", method, path, resp.StatusCode, data)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
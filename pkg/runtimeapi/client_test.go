@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	var loaded LoadLoraAdapterRequest
+	var downloads []DownloadModelRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case EngineModelsPath:
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"base","root":"/models/base"},{"id":"lora","root":"/adapters/lora"}]}`))
+		case LoadLoraAdapterPath:
+			_ = json.NewDecoder(r.Body).Decode(&loaded)
+		case UnloadLoraAdapterPath:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"object":"error","message":"adapter lora not found","type":"ServerError","code":404}`))
+		case DownloadModelPath:
+			var req DownloadModelRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			downloads = append(downloads, req)
+			status := ModelStatusDownloading
+			if len(downloads) > 1 {
+				status = ModelStatusDownloaded
+			}
+			_ = json.NewEncoder(w).Encode(ModelStatusCard{ModelName: req.ModelName, ModelRootPath: "/models/" + req.ModelName, ModelStatus: status})
+		case ReloadEngineConfigPath:
+			w.WriteHeader(http.StatusNotImplemented)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := NewClient(server.URL+"/", "key")
+
+	models, err := c.ListEngineModels(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []EngineModel{{ID: "base", Root: "/models/base"}, {ID: "lora", Root: "/adapters/lora"}}, models)
+
+	assert.NoError(t, c.LoadLoraAdapter(ctx, &LoadLoraAdapterRequest{LoraName: "lora", LoraPath: "/adapters/lora"}))
+	assert.Equal(t, LoadLoraAdapterRequest{LoraName: "lora", LoraPath: "/adapters/lora"}, loaded)
+
+	err = c.UnloadLoraAdapter(ctx, &UnloadLoraAdapterRequest{LoraName: "lora"})
+	assert.EqualError(t, err, "POST /v1/lora_adapter/unload failed with status 404: adapter lora not found")
+
+	req := &DownloadModelRequest{ModelURI: "s3://bucket/lora", ModelName: "lora", DownloadExtraConfig: map[string]string{"ak": "a"}}
+	card, err := c.DownloadModel(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ModelStatusDownloading, card.ModelStatus)
+	card, err = c.DownloadModel(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ModelStatusDownloaded, card.ModelStatus)
+	assert.Equal(t, "/models/lora", card.ModelRootPath)
+	assert.Equal(t, *req, downloads[0])
+
+	err = c.ReloadEngineConfig(ctx, &ReloadEngineConfigRequest{Config: map[string]string{"max-num-seqs": "128"}})
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	_, err = c.ListModels(ctx, "")
+	assert.Error(t, err)
+}
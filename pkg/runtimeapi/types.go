@@ -0,0 +1,81 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeapi
+
+// Model download status reported by the runtime sidecar.
+const (
+	ModelStatusNotExist    = "not_exist"
+	ModelStatusDownloading = "downloading"
+	ModelStatusDownloaded  = "downloaded"
+)
+
+type LoadLoraAdapterRequest struct {
+	LoraName string `json:"lora_name"`
+	LoraPath string `json:"lora_path"`
+}
+
+type UnloadLoraAdapterRequest struct {
+	LoraName string `json:"lora_name"`
+}
+
+// DownloadModelRequest downloads the model to the node of the sidecar. The model is downloaded to the default
+// directory of the sidecar if LocalDir is empty.
+type DownloadModelRequest struct {
+	ModelURI            string            `json:"model_uri"`
+	LocalDir            string            `json:"local_dir,omitempty"`
+	ModelName           string            `json:"model_name,omitempty"`
+	DownloadExtraConfig map[string]string `json:"download_extra_config,omitempty"`
+}
+
+// ModelStatusCard is the download status of a model on the node of the sidecar.
+type ModelStatusCard struct {
+	ModelName     string `json:"model_name"`
+	ModelRootPath string `json:"model_root_path"`
+	Source        string `json:"source"`
+	ModelStatus   string `json:"model_status"`
+}
+
+type listModelRequest struct {
+	LocalDir string `json:"local_dir"`
+}
+
+type listModelResponse struct {
+	Data []ModelStatusCard `json:"data"`
+}
+
+// EngineModel is a base model or adapter served by the engine.
+type EngineModel struct {
+	ID   string `json:"id"`
+	Root string `json:"root,omitempty"`
+}
+
+type listEngineModelsResponse struct {
+	Data []EngineModel `json:"data"`
+}
+
+// ReloadEngineConfigRequest applies the config to the running engine, keys are engine arguments.
+type ReloadEngineConfigRequest struct {
+	Config map[string]string `json:"config"`
+}
+
+// ErrorResponse is returned by the sidecar with a non 2xx status.
+type ErrorResponse struct {
+	Object  string `json:"object"`
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    int    `json:"code"`
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package storage builds the clients of the stores shared by the aibrix components.
+package storage
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+// RedisMode is the topology of the Redis deployment.
+type RedisMode string
+
+const (
+	// RedisModeStandalone connects to a single Redis server.
+	RedisModeStandalone RedisMode = "standalone"
+	// RedisModeSentinel discovers the master through Redis Sentinel and follows failovers.
+	RedisModeSentinel RedisMode = "sentinel"
+	// RedisModeCluster connects to a Redis Cluster, keys are routed to the node owning their slot.
+	RedisModeCluster RedisMode = "cluster"
+)
+
+// RedisConfig describes how to reach Redis. Secrets are expected to be injected in the environment from Secret
+// references, and TLS material to be mounted from Secrets.
+type RedisConfig struct {
+	Mode RedisMode
+	// Addrs are the server addresses in standalone mode, the sentinel addresses in sentinel mode, and the seed
+	// nodes in cluster mode.
+	Addrs []string
+	// MasterName is the name of the master monitored by the sentinels.
+	MasterName string
+	Username   string
+	Password   string
+	// SentinelUsername and SentinelPassword authenticate against the sentinels, if they require it.
+	SentinelUsername string
+	SentinelPassword string
+	// DB is the database to select, it must be 0 in cluster mode.
+	DB  int
+	TLS RedisTLSConfig
+}
+
+// RedisTLSConfig enables TLS towards Redis. Without a CA file the system roots are used.
+type RedisTLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// LoadRedisConfig reads the Redis configuration from the environment. REDIS_ADDRS takes a comma separated list and
+// takes precedence over REDIS_HOST and REDIS_PORT.
+func LoadRedisConfig() RedisConfig {
+	config := RedisConfig{
+		Mode:             RedisMode(strings.ToLower(utils.LoadEnv("REDIS_MODE", string(RedisModeStandalone)))),
+		MasterName:       utils.LoadEnv("REDIS_SENTINEL_MASTER_NAME", ""),
+		Username:         utils.LoadEnv("REDIS_USERNAME", ""),
+		Password:         utils.LoadEnv("REDIS_PASSWORD", ""),
+		SentinelUsername: utils.LoadEnv("REDIS_SENTINEL_USERNAME", ""),
+		SentinelPassword: utils.LoadEnv("REDIS_SENTINEL_PASSWORD", ""),
+		TLS: RedisTLSConfig{
+			Enabled:            utils.LoadEnv("REDIS_TLS_ENABLED", "false") == "true",
+			CAFile:             utils.LoadEnv("REDIS_TLS_CA_FILE", ""),
+			CertFile:           utils.LoadEnv("REDIS_TLS_CERT_FILE", ""),
+			KeyFile:            utils.LoadEnv("REDIS_TLS_KEY_FILE", ""),
+			ServerName:         utils.LoadEnv("REDIS_TLS_SERVER_NAME", ""),
+			InsecureSkipVerify: utils.LoadEnv("REDIS_TLS_INSECURE_SKIP_VERIFY", "false") == "true",
+		},
+	}
+	for _, addr := range strings.Split(utils.LoadEnv("REDIS_ADDRS", ""), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			config.Addrs = append(config.Addrs, addr)
+		}
+	}
+	if len(config.Addrs) == 0 {
+		config.Addrs = []string{utils.LoadEnv("REDIS_HOST", "localhost") + ":" + utils.LoadEnv("REDIS_PORT", "6379")}
+	}
+	if value := utils.LoadEnv("REDIS_DB", ""); value != "" {
+		// an invalid value is reported by Validate.
+		db, err := strconv.Atoi(value)
+		if err != nil {
+			db = -1
+		}
+		config.DB = db
+	}
+	return config
+}
+
+// Validate checks the configuration is consistent with its mode.
+func (c RedisConfig) Validate() error {
+	if len(c.Addrs) == 0 {
+		return fmt.Errorf("no redis address configured")
+	}
+	if c.DB < 0 {
+		return fmt.Errorf("invalid redis db %d", c.DB)
+	}
+	switch c.Mode {
+	case RedisModeStandalone:
+		if len(c.Addrs) > 1 {
+			return fmt.Errorf("standalone redis takes a single address, got %d", len(c.Addrs))
+		}
+	case RedisModeSentinel:
+		if c.MasterName == "" {
+			return fmt.Errorf("REDIS_SENTINEL_MASTER_NAME is required in sentinel mode")
+		}
+	case RedisModeCluster:
+		if c.DB != 0 {
+			return fmt.Errorf("redis cluster only supports db 0, got %d", c.DB)
+		}
+	default:
+		return fmt.Errorf("unknown redis mode %q, must be one of standalone, sentinel or cluster", c.Mode)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("redis tls cert and key files must be set together")
+	}
+	return nil
+}
+
+// String describes the endpoint for logs and error messages, without credentials.
+func (c RedisConfig) String() string {
+	if c.Mode == RedisModeSentinel {
+		return fmt.Sprintf("%s %s@%s", c.Mode, c.MasterName, strings.Join(c.Addrs, ","))
+	}
+	return fmt.Sprintf("%s %s", c.Mode, strings.Join(c.Addrs, ","))
+}
+
+func (c RedisTLSConfig) build() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify, // nolint:gosec // opt-in for self-signed test setups
+	}
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redis ca file: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in redis ca file %s", c.CAFile)
+		}
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load redis client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// NewRedisClient creates a client for the configured topology without checking connectivity. The client works the
+// same in every mode, except that commands spanning several keys must target keys of the same slot in cluster mode.
+func NewRedisClient(config RedisConfig) (redis.UniversalClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := config.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            config.Addrs,
+		MasterName:       config.MasterName,
+		Username:         config.Username,
+		Password:         config.Password,
+		SentinelUsername: config.SentinelUsername,
+		SentinelPassword: config.SentinelPassword,
+		DB:               config.DB,
+		TLSConfig:        tlsConfig,
+	}
+	switch config.Mode {
+	case RedisModeSentinel:
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case RedisModeCluster:
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		return redis.NewClient(opts.Simple()), nil
+	}
+}
+
+// ForEachShard calls fn with the client of every node holding keys, e.g. to scan keys across a cluster.
+func ForEachShard(ctx context.Context, client redis.UniversalClient, fn func(ctx context.Context, client *redis.Client) error) error {
+	switch c := client.(type) {
+	case *redis.ClusterClient:
+		return c.ForEachMaster(ctx, fn)
+	case *redis.Client:
+		return fn(ctx, c)
+	default:
+		return fmt.Errorf("unsupported redis client %T", client)
+	}
+}
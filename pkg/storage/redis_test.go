@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRedisConfig(t *testing.T) {
+	t.Setenv("REDIS_HOST", "aibrix-redis-master")
+	config := LoadRedisConfig()
+	assert.Equal(t, RedisModeStandalone, config.Mode)
+	assert.Equal(t, []string{"aibrix-redis-master:6379"}, config.Addrs)
+	assert.NoError(t, config.Validate())
+
+	t.Setenv("REDIS_MODE", "Sentinel")
+	t.Setenv("REDIS_ADDRS", "sentinel-0:26379, sentinel-1:26379,")
+	t.Setenv("REDIS_SENTINEL_MASTER_NAME", "mymaster")
+	t.Setenv("REDIS_PASSWORD", "secret")
+	t.Setenv("REDIS_DB", "2")
+	config = LoadRedisConfig()
+	assert.Equal(t, RedisModeSentinel, config.Mode)
+	assert.Equal(t, []string{"sentinel-0:26379", "sentinel-1:26379"}, config.Addrs)
+	assert.Equal(t, "secret", config.Password)
+	assert.Equal(t, 2, config.DB)
+	assert.Equal(t, "sentinel mymaster@sentinel-0:26379,sentinel-1:26379", config.String())
+	assert.NotContains(t, config.String(), "secret")
+
+	t.Setenv("REDIS_DB", "two")
+	assert.Error(t, LoadRedisConfig().Validate())
+}
+
+func TestRedisConfigValidate(t *testing.T) {
+	for name, config := range map[string]RedisConfig{
+		"no address":               {Mode: RedisModeStandalone},
+		"standalone with replicas": {Mode: RedisModeStandalone, Addrs: []string{"a:6379", "b:6379"}},
+		"sentinel without master":  {Mode: RedisModeSentinel, Addrs: []string{"a:26379"}},
+		"cluster with db":          {Mode: RedisModeCluster, Addrs: []string{"a:6379"}, DB: 1},
+		"unknown mode":             {Mode: "replicated", Addrs: []string{"a:6379"}},
+		"cert without key":         {Mode: RedisModeStandalone, Addrs: []string{"a:6379"}, TLS: RedisTLSConfig{Enabled: true, CertFile: "tls.crt"}},
+	} {
+		assert.Error(t, config.Validate(), name)
+	}
+}
+
+func TestNewRedisClient(t *testing.T) {
+	client, err := NewRedisClient(RedisConfig{Mode: RedisModeStandalone, Addrs: []string{"localhost:6379"}})
+	assert.NoError(t, err)
+	assert.IsType(t, &redis.Client{}, client)
+
+	client, err = NewRedisClient(RedisConfig{Mode: RedisModeSentinel, Addrs: []string{"localhost:26379"}, MasterName: "mymaster"})
+	assert.NoError(t, err)
+	assert.IsType(t, &redis.Client{}, client)
+
+	client, err = NewRedisClient(RedisConfig{Mode: RedisModeCluster, Addrs: []string{"localhost:6379"}})
+	assert.NoError(t, err)
+	assert.IsType(t, &redis.ClusterClient{}, client)
+
+	_, err = NewRedisClient(RedisConfig{
+		Mode:  RedisModeStandalone,
+		Addrs: []string{"localhost:6379"},
+		TLS:   RedisTLSConfig{Enabled: true, CAFile: "/nonexistent/ca.crt"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultApproximateMaxBytes is the size of the prompts above which token counts are approximated.
+const DefaultApproximateMaxBytes = 64 << 10
+
+// CountApproximate counts the tokens of the text. Texts longer than maxBytes are only tokenized on a head and a tail
+// sample of maxBytes/2 each, the bytes per token of the samples are extrapolated to the whole text. The estimate is
+// within a few percent of the exact count when the samples look like the rest of the text, e.g. prose or code, and
+// is exact when maxBytes <= 0.
+func CountApproximate(t Tokenizer, text string, maxBytes int) (int, error) {
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		tokens, err := t.Encode(text)
+		return len(tokens), err
+	}
+
+	head := text[:cutBefore(text, maxBytes/2)]
+	tail := text[cutAfter(text, len(text)-maxBytes/2):]
+	headTokens, err := t.Encode(head)
+	if err != nil {
+		return 0, err
+	}
+	tailTokens, err := t.Encode(tail)
+	if err != nil {
+		return 0, err
+	}
+	sampledBytes := len(head) + len(tail)
+	if sampledBytes == 0 {
+		return 0, nil
+	}
+	return int(math.Round(float64(len(text)) * float64(len(headTokens)+len(tailTokens)) / float64(sampledBytes))), nil
+}
+
+// EncodeHead tokenizes at most the first maxBytes of the text, or all of it when maxBytes <= 0. The head is cut
+// before a whitespace, so its tokens are the first tokens of the whole text for pre-tokenizers splitting words
+// before their leading space, like GPT-2 and SentencePiece ones.
+func EncodeHead(t Tokenizer, text string, maxBytes int) ([]int, error) {
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		return t.Encode(text)
+	}
+	return t.Encode(text[:cutBefore(text, maxBytes)])
+}
+
+// cutBefore returns the offset of the last whitespace at or before n, or the rune boundary before n if there is none.
+func cutBefore(text string, n int) int {
+	if i := strings.LastIndexFunc(text[:n+1], unicode.IsSpace); i > 0 {
+		return i
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return n
+}
+
+// cutAfter returns the offset of the first whitespace at or after n, or the rune boundary after n if there is none.
+func cutAfter(text string, n int) int {
+	if i := strings.IndexFunc(text[n:], unicode.IsSpace); i >= 0 {
+		return n + i
+	}
+	for n < len(text) && !utf8.RuneStart(text[n]) {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const (
+	ChatTemplateChatML  = "chatml"
+	ChatTemplateLlama3  = "llama3"
+	ChatTemplateMistral = "mistral"
+
+	DefaultChatTemplate = ChatTemplateChatML
+)
+
+// builtinChatTemplates are the chat templates of the common model families.
+var builtinChatTemplates = map[string]string{
+	ChatTemplateChatML: `{{range .Messages}}<|im_start|>{{.Role}}
+{{.Content}}<|im_end|>
+{{end}}{{if .AddGenerationPrompt}}<|im_start|>assistant
+{{end}}`,
+	ChatTemplateLlama3: `<|begin_of_text|>{{range .Messages}}<|start_header_id|>{{.Role}}<|end_header_id|>
+
+{{.Content}}<|eot_id|>{{end}}{{if .AddGenerationPrompt}}<|start_header_id|>assistant<|end_header_id|>
+
+{{end}}`,
+	ChatTemplateMistral: `<s>{{range .Messages}}{{if eq .Role "user"}}[INST] {{.Content}} [/INST]{{else if eq .Role "assistant"}}{{.Content}}</s>{{else}}{{.Content}}
+
+{{end}}{{end}}`,
+}
+
+// Message is a message of a chat completion request.
+type Message struct {
+	Role    string
+	Content string
+}
+
+// ParseMessages parses the messages of a chat completion request. The text parts of multi-part contents are
+// concatenated, other parts such as images are not counted.
+func ParseMessages(data []byte) ([]Message, error) {
+	var raw []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	messages := make([]Message, 0, len(raw))
+	for _, m := range raw {
+		message := Message{Role: m.Role}
+		if len(m.Content) > 0 && m.Content[0] == '[' {
+			var parts []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(m.Content, &parts); err != nil {
+				return nil, err
+			}
+			var content strings.Builder
+			for _, part := range parts {
+				if part.Type == "text" {
+					content.WriteString(part.Text)
+				}
+			}
+			message.Content = content.String()
+		} else if len(m.Content) > 0 && string(m.Content) != "null" {
+			if err := json.Unmarshal(m.Content, &message.Content); err != nil {
+				return nil, err
+			}
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// ChatEncoder is implemented by tokenizers applying the chat template of the model themselves, e.g. the engines.
+type ChatEncoder interface {
+	EncodeChat(messages []Message) ([]int, error)
+}
+
+// ChatTemplate renders the messages of a chat completion into the prompt the engine tokenizes.
+type ChatTemplate struct {
+	template *template.Template
+}
+
+// NewChatTemplate creates the chat template described by spec, the name of a built-in template (chatml, llama3 or
+// mistral) or a Go text/template executed with .Messages, each with .Role and .Content, and .AddGenerationPrompt.
+func NewChatTemplate(spec string) (*ChatTemplate, error) {
+	source, ok := builtinChatTemplates[spec]
+	if !ok {
+		if !strings.Contains(spec, "{{") {
+			return nil, fmt.Errorf("unknown chat template %q, must be one of %s, %s, %s or a Go template", spec, ChatTemplateChatML, ChatTemplateLlama3, ChatTemplateMistral)
+		}
+		source = spec
+	}
+	tmpl, err := template.New("chat").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chat template: %v", err)
+	}
+	return &ChatTemplate{template: tmpl}, nil
+}
+
+// Render renders the messages followed by the prompt of the assistant reply.
+func (t *ChatTemplate) Render(messages []Message) (string, error) {
+	var prompt strings.Builder
+	err := t.template.Execute(&prompt, struct {
+		Messages            []Message
+		AddGenerationPrompt bool
+	}{messages, true})
+	if err != nil {
+		return "", err
+	}
+	return prompt.String(), nil
+}
@@ -0,0 +1,409 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// gpt2Pattern splits words like GPT-2, without the \s+(?!\S) alternative RE2 doesn't support, see splitWords.
+	gpt2Pattern = `'s|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^\s\p{L}\p{N}]+|\s+`
+	// metaspace replaces spaces in SentencePiece vocabularies.
+	metaspace = "▁"
+
+	huggingFaceDownloadTimeout = 30 * time.Second
+	// maxCachedWords bounds the memory of the word cache, common words are cached first.
+	maxCachedWords = 100000
+)
+
+type huggingFaceFile struct {
+	AddedTokens []struct {
+		ID      int    `json:"id"`
+		Content string `json:"content"`
+	} `json:"added_tokens"`
+	PreTokenizer json.RawMessage `json:"pre_tokenizer"`
+	Model        struct {
+		Type         string            `json:"type"`
+		Vocab        map[string]int    `json:"vocab"`
+		Merges       []json.RawMessage `json:"merges"`
+		UnkToken     *string           `json:"unk_token"`
+		ByteFallback bool              `json:"byte_fallback"`
+	} `json:"model"`
+}
+
+// huggingFaceTokenizer implements the BPE models of tokenizer.json files, either byte level like GPT-2, Llama 3 or
+// Qwen, or SentencePiece like Llama 2 or Mistral. Normalizers other than the SentencePiece space replacement are not
+// applied, so rare inputs may be split differently than by the engine.
+type huggingFaceTokenizer struct {
+	vocab        map[string]int
+	tokens       map[int]string // id: token, including added tokens
+	ranks        map[[2]string]int
+	added        map[string]int
+	addedPattern *regexp.Regexp // nil without added tokens
+	byteLevel    bool
+	words        *regexp.Regexp // byte level only
+	byteFallback bool
+	unk          int // -1 without unknown token
+
+	mu    sync.RWMutex
+	cache map[string][]int // word: ids
+}
+
+// LoadHuggingFace loads a tokenizer.json file from a path or an http(s) URL.
+func LoadHuggingFace(location string) (Tokenizer, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		data, err = download(location)
+	} else {
+		data, err = os.ReadFile(location)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tokenizer %s: %v", location, err)
+	}
+	return ParseHuggingFace(data)
+}
+
+func download(url string) ([]byte, error) {
+	client := &http.Client{Timeout: huggingFaceDownloadTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ParseHuggingFace parses the content of a tokenizer.json file.
+func ParseHuggingFace(data []byte) (Tokenizer, error) {
+	var file huggingFaceFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Model.Type != "BPE" {
+		return nil, fmt.Errorf("unsupported tokenizer model %q, only BPE is supported", file.Model.Type)
+	}
+
+	t := &huggingFaceTokenizer{
+		vocab:        file.Model.Vocab,
+		tokens:       make(map[int]string, len(file.Model.Vocab)+len(file.AddedTokens)),
+		ranks:        make(map[[2]string]int, len(file.Model.Merges)),
+		added:        map[string]int{},
+		byteLevel:    bytes.Contains(file.PreTokenizer, []byte("ByteLevel")),
+		byteFallback: file.Model.ByteFallback,
+		unk:          -1,
+		cache:        map[string][]int{},
+	}
+	for token, id := range file.Model.Vocab {
+		t.tokens[id] = token
+	}
+	for rank, raw := range file.Model.Merges {
+		pair, err := parseMerge(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid merge %d: %v", rank, err)
+		}
+		if _, ok := t.ranks[pair]; !ok {
+			t.ranks[pair] = rank
+		}
+	}
+	if file.Model.UnkToken != nil {
+		if id, ok := file.Model.Vocab[*file.Model.UnkToken]; ok {
+			t.unk = id
+		}
+	}
+
+	// added tokens, e.g. chat template markers, are matched before splitting words, the longest first.
+	var contents []string
+	for _, token := range file.AddedTokens {
+		t.added[token.Content] = token.ID
+		t.tokens[token.ID] = token.Content
+		contents = append(contents, regexp.QuoteMeta(token.Content))
+	}
+	if len(contents) > 0 {
+		sort.Slice(contents, func(i, j int) bool { return len(contents[i]) > len(contents[j]) })
+		t.addedPattern = regexp.MustCompile(strings.Join(contents, "|"))
+	}
+
+	if t.byteLevel {
+		pattern := gpt2Pattern
+		if split := findSplitRegex(file.PreTokenizer); split != "" {
+			pattern = strings.Replace(split, `\s+(?!\S)|`, "", 1)
+		}
+		words, err := regexp.Compile(`^(?:` + pattern + `)`)
+		if err != nil {
+			// e.g. other lookarounds, the GPT-2 split is close enough.
+			words = regexp.MustCompile(`^(?:` + gpt2Pattern + `)`)
+		}
+		t.words = words
+	}
+	return t, nil
+}
+
+func parseMerge(raw json.RawMessage) ([2]string, error) {
+	var merge string
+	if err := json.Unmarshal(raw, &merge); err == nil {
+		left, right, found := strings.Cut(merge, " ")
+		if !found {
+			return [2]string{}, fmt.Errorf("%q is not a pair", merge)
+		}
+		return [2]string{left, right}, nil
+	}
+	var pair []string
+	if err := json.Unmarshal(raw, &pair); err != nil || len(pair) != 2 {
+		return [2]string{}, fmt.Errorf("%s is not a pair", raw)
+	}
+	return [2]string{pair[0], pair[1]}, nil
+}
+
+// findSplitRegex returns the regex of the first Split pre tokenizer.
+func findSplitRegex(raw json.RawMessage) string {
+	var node interface{}
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return ""
+	}
+	var find func(node interface{}) string
+	find = func(node interface{}) string {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			if value["type"] == "Split" {
+				if pattern, ok := value["pattern"].(map[string]interface{}); ok {
+					if regex, ok := pattern["Regex"].(string); ok {
+						return regex
+					}
+				}
+			}
+			for _, child := range value {
+				if regex := find(child); regex != "" {
+					return regex
+				}
+			}
+		case []interface{}:
+			for _, child := range value {
+				if regex := find(child); regex != "" {
+					return regex
+				}
+			}
+		}
+		return ""
+	}
+	return find(node)
+}
+
+func (t *huggingFaceTokenizer) Encode(text string) ([]int, error) {
+	var ids []int
+	start := 0
+	if t.addedPattern != nil {
+		for _, match := range t.addedPattern.FindAllStringIndex(text, -1) {
+			ids = t.encodeText(ids, text[start:match[0]], start == 0)
+			ids = append(ids, t.added[text[match[0]:match[1]]])
+			start = match[1]
+		}
+	}
+	return t.encodeText(ids, text[start:], start == 0), nil
+}
+
+func (t *huggingFaceTokenizer) encodeText(ids []int, text string, first bool) []int {
+	if text == "" {
+		return ids
+	}
+	if t.byteLevel {
+		for _, word := range t.splitWords(text) {
+			ids = append(ids, t.encodeWord(byteLevelEncode(word))...)
+		}
+		return ids
+	}
+
+	// SentencePiece marks the start of every word, including the first one, with the metaspace.
+	text = strings.ReplaceAll(text, " ", metaspace)
+	if first {
+		text = metaspace + text
+	}
+	for len(text) > 0 {
+		end := strings.Index(text[len(metaspace):], metaspace)
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += len(metaspace)
+		}
+		ids = append(ids, t.encodeWord(text[:end])...)
+		text = text[end:]
+	}
+	return ids
+}
+
+// splitWords splits text with the pre tokenizer regex. Whitespace runs followed by a word give their last space to
+// the word, which is what \s+(?!\S) does in the original patterns.
+func (t *huggingFaceTokenizer) splitWords(text string) []string {
+	var words []string
+	for i := 0; i < len(text); {
+		var end int
+		if match := t.words.FindStringIndex(text[i:]); match != nil && match[1] > 0 {
+			end = i + match[1]
+		} else {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			end = i + size
+		}
+		word := text[i:end]
+		if end < len(text) && utf8.RuneCountInString(word) > 1 && strings.TrimSpace(word) == "" {
+			last, size := utf8.DecodeLastRuneInString(word)
+			if next, _ := utf8.DecodeRuneInString(text[end:]); !unicode.IsSpace(next) && last != '\n' && last != '\r' {
+				end -= size
+				word = text[i:end]
+			}
+		}
+		words = append(words, word)
+		i = end
+	}
+	return words
+}
+
+// encodeWord applies the merges to the symbols of the word, the lowest ranked pair first.
+func (t *huggingFaceTokenizer) encodeWord(word string) []int {
+	t.mu.RLock()
+	ids, ok := t.cache[word]
+	t.mu.RUnlock()
+	if ok {
+		return ids
+	}
+
+	symbols := make([]string, 0, len(word))
+	for _, r := range word {
+		symbols = append(symbols, string(r))
+	}
+	for len(symbols) > 1 {
+		best, bestRank := -1, 0
+		for i := 0; i < len(symbols)-1; i++ {
+			if rank, ok := t.ranks[[2]string{symbols[i], symbols[i+1]}]; ok && (best < 0 || rank < bestRank) {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		pair := [2]string{symbols[best], symbols[best+1]}
+		merged := symbols[:0:0]
+		for i := 0; i < len(symbols); i++ {
+			if i < len(symbols)-1 && symbols[i] == pair[0] && symbols[i+1] == pair[1] {
+				merged = append(merged, pair[0]+pair[1])
+				i++
+			} else {
+				merged = append(merged, symbols[i])
+			}
+		}
+		symbols = merged
+	}
+
+	for _, symbol := range symbols {
+		if id, ok := t.vocab[symbol]; ok {
+			ids = append(ids, id)
+			continue
+		}
+		if t.byteFallback {
+			for _, b := range []byte(symbol) {
+				if id, ok := t.vocab[fmt.Sprintf("<0x%02X>", b)]; ok {
+					ids = append(ids, id)
+				}
+			}
+			continue
+		}
+		if t.unk >= 0 {
+			ids = append(ids, t.unk)
+		}
+	}
+
+	t.mu.Lock()
+	if len(t.cache) < maxCachedWords {
+		t.cache[word] = ids
+	}
+	t.mu.Unlock()
+	return ids
+}
+
+func (t *huggingFaceTokenizer) Decode(ids []int) (string, error) {
+	var out []byte
+	for _, id := range ids {
+		token, ok := t.tokens[id]
+		if !ok {
+			return "", fmt.Errorf("unknown token id %d", id)
+		}
+		if _, added := t.added[token]; added {
+			out = append(out, token...)
+			continue
+		}
+		if t.byteLevel {
+			for _, r := range token {
+				out = append(out, unicodeToByte[r])
+			}
+			continue
+		}
+		if len(token) == 6 && strings.HasPrefix(token, "<0x") && strings.HasSuffix(token, ">") {
+			if b, err := strconv.ParseUint(token[3:5], 16, 8); err == nil {
+				out = append(out, byte(b))
+				continue
+			}
+		}
+		out = append(out, strings.ReplaceAll(token, metaspace, " ")...)
+	}
+	if !t.byteLevel {
+		return strings.TrimPrefix(string(out), " "), nil
+	}
+	return string(out), nil
+}
+
+// byteToUnicode maps bytes to printable runes, so byte level vocabularies don't hold whitespace or control characters.
+var byteToUnicode, unicodeToByte = func() ([256]rune, map[rune]byte) {
+	var forward [256]rune
+	backward := make(map[rune]byte, 256)
+	n := 0
+	for b := 0; b < 256; b++ {
+		r := rune(b)
+		if !(b >= '!' && b <= '~' || b >= 0xA1 && b <= 0xAC || b >= 0xAE && b <= 0xFF) {
+			r = rune(256 + n)
+			n++
+		}
+		forward[b] = r
+		backward[r] = byte(b)
+	}
+	return forward, backward
+}()
+
+func byteLevelEncode(word string) string {
+	var sb strings.Builder
+	for i := 0; i < len(word); i++ {
+		sb.WriteRune(byteToUnicode[word[i]])
+	}
+	return sb.String()
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// promptCache keeps the tokens of the most recently used prompts. Prompts are keyed by their hash, so long prompts
+// only cost their tokens.
+type promptCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List                          // most recently used first
+	entries  map[[sha256.Size]byte]*list.Element // prompt hash: element holding a *promptEntry
+}
+
+type promptEntry struct {
+	key    [sha256.Size]byte
+	tokens []int
+}
+
+func newPromptCache(capacity int) *promptCache {
+	return &promptCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[[sha256.Size]byte]*list.Element{},
+	}
+}
+
+// get returns a copy of the tokens of the prompt, callers may modify it.
+func (c *promptCache) get(prompt string) ([]int, bool) {
+	if c.capacity <= 0 {
+		return nil, false
+	}
+	key := sha256.Sum256([]byte(prompt))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return append([]int(nil), element.Value.(*promptEntry).tokens...), true
+}
+
+func (c *promptCache) add(prompt string, tokens []int) {
+	if c.capacity <= 0 {
+		return
+	}
+	key := sha256.Sum256([]byte(prompt))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*promptEntry).tokens = tokens
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&promptEntry{key: key, tokens: tokens})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*promptEntry).key)
+	}
+}
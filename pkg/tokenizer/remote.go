@@ -0,0 +1,210 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	defaultRemoteTimeoutInMS    = 500
+	defaultRemoteCacheSize      = 4096
+	defaultRemoteMaxConcurrency = 16
+	remoteResultCacheHit        = "hit"
+	remoteResultCoalesced       = "coalesced"
+	remoteResultTokenized       = "tokenized"
+	remoteResultError           = "error"
+)
+
+var (
+	remoteClient         = &http.Client{Timeout: time.Duration(getRemoteSetting("AIBRIX_REMOTE_TOKENIZER_TIMEOUT_MS", defaultRemoteTimeoutInMS, 1)) * time.Millisecond}
+	remoteCacheSize      = getRemoteSetting("AIBRIX_REMOTE_TOKENIZER_CACHE_SIZE", defaultRemoteCacheSize, 0) // 0 disables caching
+	remoteMaxConcurrency = getRemoteSetting("AIBRIX_REMOTE_TOKENIZER_MAX_CONCURRENCY", defaultRemoteMaxConcurrency, 1)
+
+	remoteTokenizeRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aibrix_gateway_remote_tokenizer_requests_total",
+		Help: "Prompts tokenized by the engines, by result: served from the cache, joined an identical in-flight request, tokenized or failed.",
+	}, []string{"model", "result"})
+)
+
+func init() {
+	prometheus.MustRegister(remoteTokenizeRequests)
+}
+
+func getRemoteSetting(name string, defaultValue, minValue int) int {
+	value := utils.LoadEnv(name, "")
+	if value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < minValue {
+			klog.Infof("invalid %s: %s, falling back to default", name, value)
+		} else {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// remoteTokenizer uses the /tokenize and /detokenize endpoints of vLLM, trying the engines in order. Chat messages are
+// tokenized with the chat template of the engine. Recent prompts are cached. vLLM tokenizes one prompt per call, so
+// concurrent requests are batched by joining identical prompts in flight and bounding the calls to the engines.
+type remoteTokenizer struct {
+	model     string
+	addresses func() []string
+	cache     *promptCache
+	// slots bounds the concurrent calls to the engines.
+	slots chan struct{}
+
+	mu       sync.Mutex
+	inflight map[string]*tokenizeCall // prompt or chat request: call
+}
+
+// tokenizeCall is a prompt being tokenized, requests for the same prompt wait for done.
+type tokenizeCall struct {
+	done   chan struct{}
+	tokens []int
+	err    error
+}
+
+// NewRemote creates a tokenizer calling the engines serving the model, addresses returns their host:port.
+func NewRemote(model string, addresses func() []string) Tokenizer {
+	return &remoteTokenizer{
+		model:     model,
+		addresses: addresses,
+		cache:     newPromptCache(remoteCacheSize),
+		slots:     make(chan struct{}, remoteMaxConcurrency),
+		inflight:  map[string]*tokenizeCall{},
+	}
+}
+
+func (t *remoteTokenizer) Encode(text string) ([]int, error) {
+	return t.encode(text, func() ([]int, error) {
+		return t.tokenize(map[string]interface{}{"model": t.model, "prompt": text})
+	})
+}
+
+// EncodeChat tokenizes the messages with the chat template of the engine.
+func (t *remoteTokenizer) EncodeChat(messages []Message) ([]int, error) {
+	chat := make([]map[string]string, 0, len(messages))
+	for _, message := range messages {
+		chat = append(chat, map[string]string{"role": message.Role, "content": message.Content})
+	}
+	request := map[string]interface{}{"model": t.model, "messages": chat, "add_generation_prompt": true}
+	key, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	return t.encode(string(key), func() ([]int, error) { return t.tokenize(request) })
+}
+
+// encode returns the tokens of the prompt identified by key, from the cache, an identical request in flight or the
+// engines.
+func (t *remoteTokenizer) encode(key string, tokenize func() ([]int, error)) ([]int, error) {
+	if tokens, ok := t.cache.get(key); ok {
+		remoteTokenizeRequests.WithLabelValues(t.model, remoteResultCacheHit).Inc()
+		return tokens, nil
+	}
+
+	t.mu.Lock()
+	if call, ok := t.inflight[key]; ok {
+		t.mu.Unlock()
+		<-call.done
+		remoteTokenizeRequests.WithLabelValues(t.model, remoteResultCoalesced).Inc()
+		return append([]int(nil), call.tokens...), call.err
+	}
+	call := &tokenizeCall{done: make(chan struct{})}
+	t.inflight[key] = call
+	t.mu.Unlock()
+
+	t.slots <- struct{}{}
+	call.tokens, call.err = tokenize()
+	<-t.slots
+	if call.err == nil {
+		t.cache.add(key, call.tokens)
+		remoteTokenizeRequests.WithLabelValues(t.model, remoteResultTokenized).Inc()
+	} else {
+		remoteTokenizeRequests.WithLabelValues(t.model, remoteResultError).Inc()
+	}
+
+	t.mu.Lock()
+	delete(t.inflight, key)
+	t.mu.Unlock()
+	close(call.done)
+	return append([]int(nil), call.tokens...), call.err
+}
+
+func (t *remoteTokenizer) tokenize(request map[string]interface{}) ([]int, error) {
+	var resp struct {
+		Tokens []int `json:"tokens"`
+	}
+	if err := t.call("/tokenize", request, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tokens, nil
+}
+
+func (t *remoteTokenizer) Decode(tokens []int) (string, error) {
+	var resp struct {
+		Prompt string `json:"prompt"`
+	}
+	if err := t.call("/detokenize", map[string]interface{}{"model": t.model, "tokens": tokens}, &resp); err != nil {
+		return "", err
+	}
+	return resp.Prompt, nil
+}
+
+func (t *remoteTokenizer) call(path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	addresses := t.addresses()
+	if len(addresses) == 0 {
+		return fmt.Errorf("no engine to tokenize with for model %s", t.model)
+	}
+
+	var lastErr error
+	for _, address := range addresses {
+		resp, err := remoteClient.Post(fmt.Sprintf("http://%s%s", address, path), "application/json", bytes.NewReader(body))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		err = func() error {
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("%s%s returned %d", address, path, resp.StatusCode)
+			}
+			return json.NewDecoder(resp.Body).Decode(out)
+		}()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return lastErr
+}
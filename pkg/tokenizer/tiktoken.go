@@ -0,0 +1,49 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+var setOfflineLoader sync.Once
+
+type tiktokenTokenizer struct {
+	encoding *tiktoken.Tiktoken
+}
+
+// NewTiktoken loads a tiktoken encoding bundled with the gateway: cl100k_base, p50k_base or r50k_base.
+func NewTiktoken(encoding string) (Tokenizer, error) {
+	// the encodings are not downloaded at runtime.
+	setOfflineLoader.Do(func() { tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader()) })
+	tke, err := tiktoken.GetEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return &tiktokenTokenizer{encoding: tke}, nil
+}
+
+func (t *tiktokenTokenizer) Encode(text string) ([]int, error) {
+	return t.encoding.Encode(text, nil, nil), nil
+}
+
+func (t *tiktokenTokenizer) Decode(tokens []int) (string, error) {
+	return t.encoding.Decode(tokens), nil
+}
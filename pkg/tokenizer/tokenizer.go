@@ -0,0 +1,202 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tokenizer turns prompts into token ids the way the model serving them does, so that prefixes are matched
+// on the tokens the engines actually cache.
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// TypeTiktoken takes the name of a tiktoken encoding, e.g. tiktoken:cl100k_base.
+	TypeTiktoken = "tiktoken"
+	// TypeHuggingFace takes the path or http(s) URL of a tokenizer.json file, e.g. hf:/models/llama/tokenizer.json.
+	TypeHuggingFace = "hf"
+	// TypeRemote calls the tokenize endpoint of the engines serving the model.
+	TypeRemote = "remote"
+
+	DefaultSpec = TypeTiktoken + ":cl100k_base"
+)
+
+// Tokenizer encodes text into token ids and back.
+type Tokenizer interface {
+	Encode(text string) ([]int, error)
+	Decode(tokens []int) (string, error)
+}
+
+// New creates the tokenizer described by spec, <type>:<argument>. Remote tokenizers are bound to a model and are
+// created with NewRemote.
+func New(spec string) (Tokenizer, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case TypeTiktoken:
+		return NewTiktoken(arg)
+	case TypeHuggingFace:
+		return LoadHuggingFace(arg)
+	case TypeRemote:
+		return nil, fmt.Errorf("remote tokenizers are bound to a model")
+	default:
+		return nil, fmt.Errorf("unknown tokenizer %q, must be one of %s, %s or %s", spec, TypeTiktoken, TypeHuggingFace, TypeRemote)
+	}
+}
+
+// Registry holds the tokenizer and the chat template of every model. Tokenizers and templates are loaded once per
+// spec, a spec failing to load falls back to the default one until the spec of the model changes.
+type Registry struct {
+	// specOf returns the tokenizer spec of the model, empty for the default one.
+	specOf func(model string) string
+	// templateOf returns the chat template spec of the model, empty for the template of the engine or the default one.
+	templateOf func(model string) string
+	// addresses returns the host:port of the engines serving the model, for remote tokenizers.
+	addresses           func(model string) []string
+	defaultSpec         string
+	defaultTemplateSpec string
+
+	mu         sync.Mutex
+	tokenizers map[string]Tokenizer     // spec, or remote:model_name: Tokenizer
+	templates  map[string]*ChatTemplate // spec: ChatTemplate
+}
+
+// NewRegistry creates a registry using AIBRIX_DEFAULT_TOKENIZER and AIBRIX_DEFAULT_CHAT_TEMPLATE for models without
+// spec.
+func NewRegistry(specOf, templateOf func(model string) string, addresses func(model string) []string) *Registry {
+	return &Registry{
+		specOf:              specOf,
+		templateOf:          templateOf,
+		addresses:           addresses,
+		defaultSpec:         utils.LoadEnv("AIBRIX_DEFAULT_TOKENIZER", DefaultSpec),
+		defaultTemplateSpec: utils.LoadEnv("AIBRIX_DEFAULT_CHAT_TEMPLATE", DefaultChatTemplate),
+		tokenizers:          map[string]Tokenizer{},
+		templates:           map[string]*ChatTemplate{},
+	}
+}
+
+// ForModel returns the tokenizer of the model.
+func (r *Registry) ForModel(model string) Tokenizer {
+	spec := r.specOf(model)
+	if spec == "" {
+		spec = r.defaultSpec
+	}
+	key := spec
+	if spec == TypeRemote {
+		key = TypeRemote + ":" + model
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tokenizer, ok := r.tokenizers[key]; ok {
+		return tokenizer
+	}
+
+	var (
+		tokenizer Tokenizer
+		err       error
+	)
+	if spec == TypeRemote {
+		tokenizer = NewRemote(model, func() []string { return r.addresses(model) })
+	} else {
+		tokenizer, err = New(spec)
+	}
+	if err != nil {
+		klog.ErrorS(err, "failed to load tokenizer, using the default one", "model", model, "tokenizer", spec)
+		if tokenizer, err = New(r.defaultSpec); err != nil {
+			klog.ErrorS(err, "failed to load default tokenizer, using cl100k_base", "tokenizer", r.defaultSpec)
+			tokenizer, _ = New(DefaultSpec)
+		}
+	}
+	r.tokenizers[key] = tokenizer
+	return tokenizer
+}
+
+// EncodeChat tokenizes the messages of a chat completion as the engine does, chat template included. Tokenizers
+// applying the template of the engine are used unless the model declares its own template.
+func (r *Registry) EncodeChat(model string, messages []Message) ([]int, error) {
+	return r.EncodeChatHead(model, messages, 0)
+}
+
+// EncodeChatHead tokenizes at most the first maxBytes of the rendered messages, see EncodeHead. Messages tokenized by
+// the engine are tokenized whole.
+func (r *Registry) EncodeChatHead(model string, messages []Message, maxBytes int) ([]int, error) {
+	tokenizer, prompt, chatEncoder, err := r.renderChat(model, messages)
+	if err != nil {
+		return nil, err
+	}
+	if chatEncoder != nil {
+		return chatEncoder.EncodeChat(messages)
+	}
+	return EncodeHead(tokenizer, prompt, maxBytes)
+}
+
+// CountChat counts the tokens of the messages, approximately beyond maxBytes of rendered messages, see
+// CountApproximate. Messages tokenized by the engine are counted exactly.
+func (r *Registry) CountChat(model string, messages []Message, maxBytes int) (int, error) {
+	tokenizer, prompt, chatEncoder, err := r.renderChat(model, messages)
+	if err != nil {
+		return 0, err
+	}
+	if chatEncoder != nil {
+		tokens, err := chatEncoder.EncodeChat(messages)
+		return len(tokens), err
+	}
+	return CountApproximate(tokenizer, prompt, maxBytes)
+}
+
+// renderChat returns the tokenizer of the model and the messages rendered with its chat template, or the chat encoder
+// of the model when it applies the template of the engine.
+func (r *Registry) renderChat(model string, messages []Message) (Tokenizer, string, ChatEncoder, error) {
+	tokenizer := r.ForModel(model)
+	spec := r.templateOf(model)
+	if chatEncoder, ok := tokenizer.(ChatEncoder); ok && spec == "" {
+		return tokenizer, "", chatEncoder, nil
+	}
+
+	prompt, err := r.chatTemplate(model, spec).Render(messages)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return tokenizer, prompt, nil, nil
+}
+
+func (r *Registry) chatTemplate(model, spec string) *ChatTemplate {
+	if spec == "" {
+		spec = r.defaultTemplateSpec
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if template, ok := r.templates[spec]; ok {
+		return template
+	}
+
+	template, err := NewChatTemplate(spec)
+	if err != nil {
+		klog.ErrorS(err, "failed to load chat template, using the default one", "model", model, "chatTemplate", spec)
+		if template, err = NewChatTemplate(r.defaultTemplateSpec); err != nil {
+			klog.ErrorS(err, "failed to load default chat template, using chatml", "chatTemplate", r.defaultTemplateSpec)
+			template, _ = NewChatTemplate(DefaultChatTemplate)
+		}
+	}
+	r.templates[spec] = template
+	return template
+}
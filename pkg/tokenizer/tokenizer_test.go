@@ -0,0 +1,365 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenizer
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// byteLevelTokenizer is a GPT-2 style tokenizer.json, Ġ is the byte level space.
+const byteLevelTokenizer = `{
+	"added_tokens": [{"id": 15, "content": "<|im_start|>"}],
+	"pre_tokenizer": {"type": "ByteLevel", "add_prefix_space": false},
+	"model": {
+		"type": "BPE",
+		"vocab": {"h": 0, "e": 1, "l": 2, "o": 3, "Ġ": 4, "w": 5, "r": 6, "d": 7, "he": 8, "ll": 9, "hell": 10,
+			"hello": 11, "Ġw": 12, "or": 13, "ld": 14},
+		"merges": ["h e", "l l", ["he", "ll"], "hell o", "Ġ w", "o r", "l d"]
+	}
+}`
+
+// sentencePieceTokenizer is a Llama 2 style tokenizer.json with byte fallback.
+const sentencePieceTokenizer = `{
+	"normalizer": {"type": "Sequence", "normalizers": [{"type": "Prepend", "prepend": "▁"}, {"type": "Replace", "pattern": {"String": " "}, "content": "▁"}]},
+	"model": {
+		"type": "BPE",
+		"unk_token": "<unk>",
+		"byte_fallback": true,
+		"vocab": {"▁": 0, "h": 1, "i": 2, "▁h": 3, "▁hi": 4, "<0xE4>": 5, "<0xBD>": 6, "<0xA0>": 7, "<unk>": 8},
+		"merges": ["▁ h", "▁h i"]
+	}
+}`
+
+func TestHuggingFaceByteLevel(t *testing.T) {
+	tokenizer, err := ParseHuggingFace([]byte(byteLevelTokenizer))
+	require.NoError(t, err)
+
+	for text, expected := range map[string][]int{
+		"hello world":              {11, 12, 13, 14},
+		"<|im_start|>hello world":  {15, 11, 12, 13, 14},
+		"hello  world":             {11, 4, 12, 13, 14},
+		"hello world<|im_start|>w": {11, 12, 13, 14, 15, 5},
+	} {
+		tokens, err := tokenizer.Encode(text)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, tokens, text)
+
+		decoded, err := tokenizer.Decode(tokens)
+		assert.NoError(t, err)
+		assert.Equal(t, text, decoded)
+	}
+}
+
+func TestHuggingFaceSentencePiece(t *testing.T) {
+	tokenizer, err := ParseHuggingFace([]byte(sentencePieceTokenizer))
+	require.NoError(t, err)
+
+	tokens, err := tokenizer.Encode("hi 你")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 0, 5, 6, 7}, tokens)
+
+	decoded, err := tokenizer.Decode(tokens)
+	assert.NoError(t, err)
+	assert.Equal(t, "hi 你", decoded)
+}
+
+func TestParseHuggingFaceInvalid(t *testing.T) {
+	_, err := ParseHuggingFace([]byte(`{"model": {"type": "WordPiece"}}`))
+	assert.Error(t, err)
+	_, err = ParseHuggingFace([]byte(`{"model": {"type": "BPE", "merges": ["hello"]}}`))
+	assert.Error(t, err)
+}
+
+func TestRemote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "llama", req["model"])
+		switch r.URL.Path {
+		case "/tokenize":
+			_, _ = w.Write([]byte(`{"tokens": [1, 2, 3], "count": 3}`))
+		case "/detokenize":
+			_, _ = w.Write([]byte(`{"prompt": "hello"}`))
+		}
+	}))
+	defer server.Close()
+
+	// the first engine is down.
+	tokenizer := NewRemote("llama", func() []string { return []string{"127.0.0.1:1", strings.TrimPrefix(server.URL, "http://")} })
+	tokens, err := tokenizer.Encode("hello")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, tokens)
+	text, err := tokenizer.Decode(tokens)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", text)
+
+	_, err = NewRemote("llama", func() []string { return nil }).Encode("hello")
+	assert.Error(t, err)
+}
+
+func TestRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokenizer.json")
+	require.NoError(t, os.WriteFile(path, []byte(byteLevelTokenizer), 0o644))
+	specs := map[string]string{
+		"qwen":    TypeHuggingFace + ":" + path,
+		"broken":  TypeHuggingFace + ":" + filepath.Join(t.TempDir(), "missing.json"),
+		"llama":   TypeRemote,
+		"mistral": TypeRemote,
+	}
+	registry := NewRegistry(func(model string) string { return specs[model] }, func(string) string { return "" }, func(string) []string { return nil })
+
+	tokens, err := registry.ForModel("qwen").Encode("hello world")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{11, 12, 13, 14}, tokens)
+	assert.Same(t, registry.ForModel("qwen"), registry.ForModel("qwen"))
+
+	// models without tokenizer and broken ones use cl100k_base.
+	tokens, err = registry.ForModel("gpt").Encode("hello world")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{15339, 1917}, tokens)
+	assert.IsType(t, &tiktokenTokenizer{}, registry.ForModel("broken"))
+
+	assert.IsType(t, &remoteTokenizer{}, registry.ForModel("llama"))
+	// remote tokenizers are bound to their model.
+	assert.NotSame(t, registry.ForModel("llama"), registry.ForModel("mistral"))
+}
+
+func TestParseMessages(t *testing.T) {
+	messages, err := ParseMessages([]byte(`[
+		{"role": "system", "content": "be brief"},
+		{"role": "user", "content": [{"type": "text", "text": "what is "}, {"type": "image_url", "image_url": {"url": "x"}}, {"type": "text", "text": "this?"}]},
+		{"role": "assistant", "content": null}
+	]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Message{{"system", "be brief"}, {"user", "what is this?"}, {"assistant", ""}}, messages)
+
+	_, err = ParseMessages([]byte(`"hello"`))
+	assert.Error(t, err)
+}
+
+func TestChatTemplate(t *testing.T) {
+	messages := []Message{{"system", "be brief"}, {"user", "hi"}}
+
+	chatML, err := NewChatTemplate(ChatTemplateChatML)
+	require.NoError(t, err)
+	prompt, err := chatML.Render(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "<|im_start|>system\nbe brief<|im_end|>\n<|im_start|>user\nhi<|im_end|>\n<|im_start|>assistant\n", prompt)
+
+	llama3, err := NewChatTemplate(ChatTemplateLlama3)
+	require.NoError(t, err)
+	prompt, err = llama3.Render(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "<|begin_of_text|><|start_header_id|>system<|end_header_id|>\n\nbe brief<|eot_id|>"+
+		"<|start_header_id|>user<|end_header_id|>\n\nhi<|eot_id|><|start_header_id|>assistant<|end_header_id|>\n\n", prompt)
+
+	mistral, err := NewChatTemplate(ChatTemplateMistral)
+	require.NoError(t, err)
+	prompt, err = mistral.Render(append(messages, Message{"assistant", "hello"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "<s>be brief\n\n[INST] hi [/INST]hello</s>", prompt)
+
+	custom, err := NewChatTemplate(`{{range .Messages}}{{.Role}}: {{.Content}}; {{end}}{{if .AddGenerationPrompt}}assistant:{{end}}`)
+	require.NoError(t, err)
+	prompt, err = custom.Render(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "system: be brief; user: hi; assistant:", prompt)
+
+	_, err = NewChatTemplate("vicuna")
+	assert.Error(t, err)
+	_, err = NewChatTemplate("{{range .Messages}")
+	assert.Error(t, err)
+}
+
+func TestRegistryEncodeChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		// the engine applies its own chat template.
+		assert.Equal(t, []interface{}{map[string]interface{}{"role": "user", "content": "hi"}}, req["messages"])
+		assert.Equal(t, true, req["add_generation_prompt"])
+		_, _ = w.Write([]byte(`{"tokens": [1, 2, 3, 4, 5]}`))
+	}))
+	defer server.Close()
+	specs := map[string]string{"llama": TypeRemote, "mistral": TypeRemote}
+	templates := map[string]string{"mistral": `{{range .Messages}}{{.Content}}{{end}}`, "broken": "vicuna"}
+	registry := NewRegistry(func(model string) string { return specs[model] }, func(model string) string { return templates[model] },
+		func(model string) []string {
+			if model == "mistral" {
+				return nil
+			}
+			return []string{strings.TrimPrefix(server.URL, "http://")}
+		})
+	messages := []Message{{"user", "hi"}}
+
+	tokens, err := registry.EncodeChat("llama", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, tokens)
+
+	// a template declared by the model is rendered before tokenizing.
+	_, err = registry.EncodeChat("mistral", messages)
+	assert.Error(t, err, "the prompt is tokenized by the engines of the model")
+
+	// models without template and broken ones use chatml.
+	tokens, err = registry.EncodeChat("gpt", messages)
+	assert.NoError(t, err)
+	expected, _ := registry.ForModel("gpt").Encode("<|im_start|>user\nhi<|im_end|>\n<|im_start|>assistant\n")
+	assert.Equal(t, expected, tokens)
+	assert.Greater(t, len(tokens), 1)
+	tokens, err = registry.EncodeChat("broken", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, tokens)
+}
+
+// randomText returns n bytes of words drawn from vocabulary, separated by spaces and line breaks.
+func randomText(r *rand.Rand, vocabulary []string, n int) string {
+	var text strings.Builder
+	for text.Len() < n {
+		text.WriteString(vocabulary[r.Intn(len(vocabulary))])
+		if r.Intn(12) == 0 {
+			text.WriteString(".\n")
+		} else {
+			text.WriteString(" ")
+		}
+	}
+	return text.String()
+}
+
+func TestCountApproximate(t *testing.T) {
+	tokenizer, err := New(DefaultSpec)
+	require.NoError(t, err)
+	r := rand.New(rand.NewSource(42))
+	for name, vocabulary := range map[string][]string{
+		"prose": {"the", "gateway", "routes", "requests", "to", "engines", "serving", "a", "model", "with", "prefix", "cache",
+			"tokenization", "latency", "of", "large", "prompts", "is", "bounded", "approximately", "Kubernetes", "pods"},
+		"code": {"func", "(r", "*Registry)", "if", "err", "!=", "nil", "{", "}", "return", "x[i]", ":=", "0;", "i++",
+			"fmt.Sprintf(\"%d\",", "n)", "//", "TODO"},
+		"cjk": {"网关", "路由", "请求", "模型", "缓存", "前缀", "延迟", "提示词", "分词器", "の", "トークン"},
+	} {
+		text := randomText(r, vocabulary, 256<<10)
+		exact, err := tokenizer.Encode(text)
+		require.NoError(t, err)
+
+		approximate, err := CountApproximate(tokenizer, text, DefaultApproximateMaxBytes)
+		assert.NoError(t, err)
+		// the samples look like the rest of the text, the estimate is within 2% of the exact count.
+		assert.InEpsilon(t, len(exact), approximate, 0.02, name)
+
+		// small texts and maxBytes <= 0 are counted exactly.
+		count, err := CountApproximate(tokenizer, text, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, len(exact), count, name)
+		count, err = CountApproximate(tokenizer, text[:cutBefore(text, 1000)], DefaultApproximateMaxBytes)
+		assert.NoError(t, err)
+		head, _ := tokenizer.Encode(text[:cutBefore(text, 1000)])
+		assert.Equal(t, len(head), count, name)
+
+		// the head tokens are the first tokens of the text.
+		head, err = EncodeHead(tokenizer, text, DefaultApproximateMaxBytes)
+		assert.NoError(t, err)
+		assert.Greater(t, len(head), 0)
+		assert.Equal(t, exact[:len(head)], head, name)
+		all, _ := EncodeHead(tokenizer, text, 0)
+		assert.Equal(t, exact, all, name)
+	}
+}
+
+func TestCutOnRuneBoundaries(t *testing.T) {
+	text := strings.Repeat("分词", 10)
+	assert.Equal(t, 6, cutBefore(text, 7))
+	assert.Equal(t, 9, cutAfter(text, 7))
+	assert.Equal(t, 5, cutBefore("hello world", 8))
+	assert.Equal(t, 5, cutAfter("hello world", 2))
+}
+
+func TestRemoteCachesAndJoinsPrompts(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		_, _ = w.Write([]byte(`{"tokens": [1, 2, 3]}`))
+	}))
+	defer server.Close()
+	tokenizer := NewRemote("llama", func() []string { return []string{strings.TrimPrefix(server.URL, "http://")} }).(*remoteTokenizer)
+
+	// identical prompts in flight are tokenized once.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens, err := tokenizer.Encode("hello")
+			assert.NoError(t, err)
+			assert.Equal(t, []int{1, 2, 3}, tokens)
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		tokenizer.mu.Lock()
+		defer tokenizer.mu.Unlock()
+		return len(tokenizer.inflight) == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond) // let the other requests join
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	// then served from the cache, the cached tokens can't be modified by callers.
+	tokens, err := tokenizer.Encode("hello")
+	assert.NoError(t, err)
+	tokens[0] = 42
+	tokens, _ = tokenizer.Encode("hello")
+	assert.Equal(t, []int{1, 2, 3}, tokens)
+	assert.Equal(t, int32(1), calls.Load())
+
+	_, err = tokenizer.Encode("world")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestPromptCache(t *testing.T) {
+	cache := newPromptCache(2)
+	cache.add("a", []int{1})
+	cache.add("b", []int{2})
+	_, ok := cache.get("a") // b is now the least recently used
+	assert.True(t, ok)
+	cache.add("c", []int{3})
+
+	_, ok = cache.get("b")
+	assert.False(t, ok)
+	tokens, ok := cache.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []int{1}, tokens)
+
+	disabled := newPromptCache(0)
+	disabled.add("a", []int{1})
+	_, ok = disabled.get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/vllm-project/aibrix/pkg/utils"
+)
+
+const (
+	// Standard OpenTelemetry exporter environment variables.
+	EnvOTLPTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	EnvOTLPEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvServiceName        = "OTEL_SERVICE_NAME"
+	EnvTraceSampleRatio   = "AIBRIX_TRACE_SAMPLE_RATIO"
+
+	exportBatchSize     = 512
+	exportQueueSize     = 4096
+	exportFlushInterval = 5 * time.Second
+	exportTimeout       = 10 * time.Second
+)
+
+var tracer *Tracer
+
+// Init enables tracing if an OTLP endpoint is configured, spans are no-ops otherwise.
+func Init(defaultServiceName string, stopCh <-chan struct{}) {
+	endpoint := utils.LoadEnv(EnvOTLPTracesEndpoint, "")
+	if endpoint == "" {
+		if base := utils.LoadEnv(EnvOTLPEndpoint, ""); base != "" {
+			endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+		}
+	}
+	if endpoint == "" {
+		return
+	}
+
+	ratio := 1.0
+	if value := utils.LoadEnv(EnvTraceSampleRatio, ""); value != "" {
+		if v, err := strconv.ParseFloat(value, 64); err != nil || v < 0 || v > 1 {
+			klog.Infof("invalid %s: %s, falling back to default", EnvTraceSampleRatio, value)
+		} else {
+			ratio = v
+		}
+	}
+
+	serviceName := utils.LoadEnv(EnvServiceName, defaultServiceName)
+	tracer = &Tracer{
+		exporter:    newExporter(endpoint, serviceName, stopCh),
+		sampleRatio: ratio,
+	}
+	klog.InfoS("tracing enabled", "endpoint", endpoint, "serviceName", serviceName, "sampleRatio", ratio)
+}
+
+// StartSpan starts a span with the process wide tracer.
+func StartSpan(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	return tracer.StartSpan(ctx, name, kind)
+}
+
+type exporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+	spans       chan *Span
+}
+
+func newExporter(endpoint, serviceName string, stopCh <-chan struct{}) *exporter {
+	e := &exporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: exportTimeout},
+		spans:       make(chan *Span, exportQueueSize),
+	}
+	go e.run(stopCh)
+	return e
+}
+
+func (e *exporter) export(span *Span) {
+	select {
+	case e.spans <- span:
+	default:
+		klog.V(4).InfoS("trace export queue is full, dropping span", "span", span.name)
+	}
+}
+
+func (e *exporter) run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(exportFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, exportBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			klog.ErrorS(err, "failed to export spans", "endpoint", e.endpoint, "spans", len(batch))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span := <-e.spans:
+			batch = append(batch, span)
+			if len(batch) >= exportBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stopCh:
+			// drain the queue so spans ended before shutdown are exported
+			for {
+				select {
+				case span := <-e.spans:
+					batch = append(batch, span)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *exporter) send(spans []*Span) error {
+	body, err := json.Marshal(e.encode(spans))
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OTLP JSON encoding, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpKeyValue struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+func (e *exporter) encode(spans []*Span) map[string]interface{} {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		span.mu.Lock()
+		s := otlpSpan{
+			TraceID:           hex.EncodeToString(span.sc.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.sc.SpanID[:]),
+			Name:              span.name,
+			Kind:              span.kind,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+			Status:            otlpStatus{Code: 1}, // STATUS_CODE_OK
+		}
+		if span.parentSpanID != [8]byte{} {
+			s.ParentSpanID = hex.EncodeToString(span.parentSpanID[:])
+		}
+		for key, value := range span.attributes {
+			s.Attributes = append(s.Attributes, otlpKeyValue{Key: key, Value: encodeValue(value)})
+		}
+		if span.err != nil {
+			s.Status = otlpStatus{Code: 2, Message: span.err.Error()} // STATUS_CODE_ERROR
+		}
+		span.mu.Unlock()
+		encoded = append(encoded, s)
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []otlpKeyValue{{Key: "service.name", Value: encodeValue(e.serviceName)}},
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": "github.com/vllm-project/aibrix"},
+				"spans": encoded,
+			}},
+		}},
+	}
+}
+
+func encodeValue(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": v}
+	case bool:
+		return map[string]interface{}{"boolValue": v}
+	case int:
+		return map[string]interface{}{"intValue": strconv.Itoa(v)}
+	case int64:
+		return map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		return map[string]interface{}{"doubleValue": v}
+	default:
+		return map[string]interface{}{"stringValue": fmt.Sprint(v)}
+	}
+}
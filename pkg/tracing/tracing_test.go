@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceparent(t *testing.T) {
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceparent(value)
+	assert.True(t, ok)
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, value, sc.Traceparent())
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01",
+	} {
+		_, ok := ParseTraceparent(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tr *Tracer
+	ctx, span := tr.StartSpan(context.Background(), "noop", SpanKindInternal)
+	assert.Nil(t, span)
+	assert.Nil(t, SpanFromContext(ctx))
+	// no-op spans must be safe to use
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("failed"))
+	span.End()
+	assert.False(t, span.IsRecording())
+}
+
+func TestSpanExport(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		_ = json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	stopCh := make(chan struct{})
+	tr := &Tracer{exporter: newExporter(server.URL, "test", stopCh), sampleRatio: 1}
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, root := tr.StartSpan(ContextWithRemoteParent(context.Background(), remote), "root", SpanKindServer)
+	_, child := tr.StartSpan(ctx, "child", SpanKindInternal)
+	assert.Equal(t, remote.TraceID, root.SpanContext().TraceID, "root span joins the remote trace")
+	assert.Equal(t, root.SpanContext().SpanID, child.parentSpanID)
+
+	child.SetAttribute("pods", 3)
+	child.RecordError(errors.New("no ready pods"))
+	child.End()
+	root.End()
+	root.End() // ending twice exports once
+	close(stopCh)
+
+	select {
+	case payload := <-received:
+		spans := payload["resourceSpans"].([]interface{})[0].(map[string]interface{})["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+		assert.Len(t, spans, 2)
+		first := spans[0].(map[string]interface{})
+		assert.Equal(t, "child", first["name"])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", first["traceId"])
+		assert.Equal(t, float64(2), first["status"].(map[string]interface{})["code"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("spans were not exported")
+	}
+}
+
+func TestSampled(t *testing.T) {
+	var traceID [16]byte
+	assert.True(t, sampled(traceID, 1))
+	assert.False(t, sampled(traceID, 0))
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const apiKeyPrefix = "sk-"
+
+// APIKey is the metadata attached to an api key. Only the hash of the key is stored.
+type APIKey struct {
+	// Key is the plain api key, it is only set on creation and never persisted.
+	Key      string            `json:"key,omitempty"`
+	User     string            `json:"user" validate:"required"`
+	Tenant   string            `json:"tenant,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Disabled bool              `json:"disabled,omitempty"`
+}
+
+// GenerateAPIKey returns a new random api key.
+func GenerateAPIKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// HashAPIKey returns the digest used to store and look up an api key.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func GetAPIKey(key string, redisClient redis.UniversalClient) (APIKey, error) {
+	val, err := redisClient.Get(context.Background(), genAPIKeyKey(HashAPIKey(key))).Result()
+	if err != nil {
+		return APIKey{}, err
+	}
+	apiKey := &APIKey{}
+	if err := json.Unmarshal([]byte(val), apiKey); err != nil {
+		return APIKey{}, err
+	}
+
+	return *apiKey, nil
+}
+
+func SetAPIKey(k APIKey, redisClient redis.UniversalClient) error {
+	if k.Key == "" || k.User == "" {
+		return fmt.Errorf("key and user are required")
+	}
+
+	hashed := HashAPIKey(k.Key)
+	k.Key = ""
+	b, err := json.Marshal(&k)
+	if err != nil {
+		return err
+	}
+
+	return redisClient.Set(context.Background(), genAPIKeyKey(hashed), string(b), 0).Err()
+}
+
+func DelAPIKey(key string, redisClient redis.UniversalClient) error {
+	return redisClient.Del(context.Background(), genAPIKeyKey(HashAPIKey(key))).Err()
+}
+
+func genAPIKeyKey(hashed string) string {
+	return fmt.Sprintf("aibrix-apikeys/%s", hashed)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// DefaultVirtualNodes is the number of points of a node of weight 1 on a hash ring, enough to spread the keys within
+// a few percent of the weights.
+const DefaultVirtualNodes = 100
+
+type hashRingPoint struct {
+	hash uint64
+	node string
+}
+
+// HashRing maps keys to weighted nodes by consistent hashing: adding or removing a node only moves the keys of its
+// points. Each node has virtualNodes points per unit of weight. A HashRing is immutable and safe for concurrent use.
+type HashRing struct {
+	points []hashRingPoint
+	// index maps the top bits of a hash to its first point, so a lookup scans about one point.
+	index       []int
+	shift       uint
+	weights     map[string]int
+	totalWeight int
+}
+
+// NewHashRing creates the ring of the nodes with their weights, nodes without a positive weight are left out.
+func NewHashRing(weights map[string]int, virtualNodes int) *HashRing {
+	r := &HashRing{weights: map[string]int{}}
+	for node, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+		r.weights[node] = weight
+		r.totalWeight += weight
+		for i := 0; i < weight*virtualNodes; i++ {
+			r.points = append(r.points, hashRingPoint{hash: xxhash.Sum64String(node + "#" + strconv.Itoa(i)), node: node})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash != r.points[j].hash {
+			return r.points[i].hash < r.points[j].hash
+		}
+		return r.points[i].node < r.points[j].node
+	})
+
+	// one index entry per point at least, rounded to a power of two.
+	indexBits := bits.Len(uint(max(len(r.points)-1, 0)))
+	r.shift = uint(64 - indexBits)
+	r.index = make([]int, 1<<indexBits)
+	point := 0
+	for i := range r.index {
+		start := uint64(i) << r.shift
+		for point < len(r.points) && r.points[point].hash < start {
+			point++
+		}
+		r.index[i] = point
+	}
+	return r
+}
+
+// Len returns the number of nodes of the ring.
+func (r *HashRing) Len() int {
+	return len(r.weights)
+}
+
+func hashKey(key string) uint64 {
+	return xxhash.Sum64String(key)
+}
+
+// search returns the first point at or after the hash, wrapping around the ring.
+func (r *HashRing) search(hash uint64) int {
+	i := r.index[hash>>r.shift]
+	for i < len(r.points) && r.points[i].hash < hash {
+		i++
+	}
+	if i == len(r.points) {
+		return 0
+	}
+	return i
+}
+
+// Get returns the node of the key, empty if the ring has no node.
+func (r *HashRing) Get(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	return r.points[r.search(hashKey(key))].node
+}
+
+// GetBounded returns the node of the key with bounded loads: nodes whose load would exceed factor times their share of
+// the total load, the key included, are passed over for the next node on the ring. load returns the current load of a
+// node, e.g. its in-flight requests, and factor is above 1, e.g. 1.25. It returns empty if the ring has no node.
+func (r *HashRing) GetBounded(key string, load func(node string) float64, factor float64) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	loads := make(map[string]float64, len(r.weights))
+	total := 1.0
+	for node := range r.weights {
+		loads[node] = load(node)
+		total += loads[node]
+	}
+
+	start := r.search(hashKey(key))
+	for i := 0; i < len(r.points); i++ {
+		node := r.points[(start+i)%len(r.points)].node
+		capacity := math.Ceil(factor * total * float64(r.weights[node]) / float64(r.totalWeight))
+		if loads[node]+1 <= capacity {
+			return node
+		}
+	}
+	// only reached with a factor below 1.
+	return r.points[start].node
+}
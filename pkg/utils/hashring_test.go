@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ringKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func TestHashRingGet(t *testing.T) {
+	assert.Empty(t, NewHashRing(nil, DefaultVirtualNodes).Get("key"))
+	assert.Equal(t, "a", NewHashRing(map[string]int{"a": 1}, 1).Get("key"))
+
+	ring := NewHashRing(map[string]int{"a": 1, "b": 1, "c": 1, "d": 0}, DefaultVirtualNodes)
+	assert.Equal(t, 3, ring.Len())
+	counts := map[string]int{}
+	for _, key := range ringKeys(30000) {
+		node := ring.Get(key)
+		assert.Equal(t, node, ring.Get(key))
+		counts[node]++
+	}
+	assert.Len(t, counts, 3)
+	for node, count := range counts {
+		assert.InDelta(t, 10000, count, 1500, node)
+	}
+
+	// the lookup through the index matches a binary search of the ring.
+	for _, key := range ringKeys(1000) {
+		hash := ring.points[ring.search(hashKey(key))].hash
+		i := sort.Search(len(ring.points), func(i int) bool { return ring.points[i].hash >= hashKey(key) })
+		assert.Equal(t, ring.points[i%len(ring.points)].hash, hash)
+	}
+}
+
+func TestHashRingWeights(t *testing.T) {
+	ring := NewHashRing(map[string]int{"a": 1, "b": 3}, DefaultVirtualNodes)
+	counts := map[string]int{}
+	for _, key := range ringKeys(40000) {
+		counts[ring.Get(key)]++
+	}
+	assert.InDelta(t, 10000, counts["a"], 1500)
+	assert.InDelta(t, 30000, counts["b"], 1500)
+}
+
+func TestHashRingChurn(t *testing.T) {
+	nodes := map[string]int{}
+	for i := 0; i < 10; i++ {
+		nodes["pod-"+strconv.Itoa(i)] = 1
+	}
+	before := NewHashRing(nodes, DefaultVirtualNodes)
+	nodes["pod-10"] = 1
+	added := NewHashRing(nodes, DefaultVirtualNodes)
+	delete(nodes, "pod-3")
+	removed := NewHashRing(nodes, DefaultVirtualNodes)
+
+	keys := ringKeys(20000)
+	moved := 0
+	for _, key := range keys {
+		// only the keys of the new node move when adding it.
+		if node := added.Get(key); node != before.Get(key) {
+			assert.Equal(t, "pod-10", node)
+			moved++
+		}
+		// only the keys of the removed node move when removing it.
+		if node := added.Get(key); node != "pod-3" {
+			assert.Equal(t, node, removed.Get(key))
+		}
+	}
+	// about 1/11 of the keys move, modulo hashing would move most of them.
+	assert.InDelta(t, len(keys)/11, moved, float64(len(keys))/50)
+}
+
+func TestHashRingGetBounded(t *testing.T) {
+	ring := NewHashRing(map[string]int{"a": 1, "b": 1, "c": 1, "d": 1}, DefaultVirtualNodes)
+	noLoad := func(string) float64 { return 0 }
+	for _, key := range ringKeys(100) {
+		assert.Equal(t, ring.Get(key), ring.GetBounded(key, noLoad, 1.25))
+	}
+
+	// a key whose node is at capacity goes to the next node on the ring, the same for the same loads.
+	key := "hot"
+	hot := ring.Get(key)
+	loads := map[string]float64{hot: 10}
+	load := func(node string) float64 { return loads[node] }
+	next := ring.GetBounded(key, load, 1.25)
+	assert.NotEqual(t, hot, next)
+	assert.Equal(t, next, ring.GetBounded(key, load, 1.25))
+
+	// placing keys one by one keeps every node within the bound.
+	loads = map[string]float64{}
+	for _, key := range append(ringKeys(1000), make([]string, 1000)...) {
+		loads[ring.GetBounded(key, load, 1.25)]++
+	}
+	for node, count := range loads {
+		assert.LessOrEqual(t, count, 1.25*2000/4+1, node)
+	}
+	assert.Empty(t, NewHashRing(nil, DefaultVirtualNodes).GetBounded(key, load, 1.25))
+}
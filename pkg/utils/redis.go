@@ -17,17 +17,7 @@ limitations under the License.
 package utils
 
 import (
-	"context"
-	"fmt"
 	"os"
-
-	"github.com/redis/go-redis/v9"
-	"k8s.io/klog/v2"
-)
-
-var (
-	redis_host = GetEnv("REDIS_HOST", "localhost")
-	redis_port = GetEnv("REDIS_PORT", "6379")
 )
 
 // CheckEnvExists checks if an environment variable exists.
@@ -44,18 +34,3 @@ func GetEnv(key, defaultValue string) string {
 	}
 	return value
 }
-
-func GetRedisClient() *redis.Client {
-	// Connect to Redis
-	client := redis.NewClient(&redis.Options{
-		Addr: redis_host + ":" + redis_port,
-		DB:   0, // Default DB
-	})
-	pong, err := client.Ping(context.Background()).Result()
-	if err != nil {
-		klog.Fatalf("Error connecting to Redis: %v", err)
-	}
-	fmt.Println("Connected to Redis:", pong)
-
-	return client
-}
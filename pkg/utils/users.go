@@ -25,12 +25,21 @@ import (
 )
 
 type User struct {
-	Name string `json:"name" validate:"required"`
-	Rpm  int64  `json:"rpm"`
-	Tpm  int64  `json:"tpm"`
+	Name   string `json:"name" validate:"required"`
+	Rpm    int64  `json:"rpm"`
+	Tpm    int64  `json:"tpm"`
+	Tenant string `json:"tenant,omitempty"`
 }
 
-func CheckUser(u User, redisClient *redis.Client) bool {
+// TenantID returns the tenant of the user, a user without tenant is its own tenant.
+func (u User) TenantID() string {
+	if u.Tenant != "" {
+		return u.Tenant
+	}
+	return u.Name
+}
+
+func CheckUser(u User, redisClient redis.UniversalClient) bool {
 	val, err := redisClient.Exists(context.Background(), genKey(u.Name)).Result()
 	if err != nil {
 		return false
@@ -39,7 +48,7 @@ func CheckUser(u User, redisClient *redis.Client) bool {
 	return val != 0
 }
 
-func GetUser(u User, redisClient *redis.Client) (User, error) {
+func GetUser(u User, redisClient redis.UniversalClient) (User, error) {
 	val, err := redisClient.Get(context.Background(), genKey(u.Name)).Result()
 	if err != nil {
 		return User{}, err
@@ -53,7 +62,7 @@ func GetUser(u User, redisClient *redis.Client) (User, error) {
 	return *user, nil
 }
 
-func SetUser(u User, redisClient *redis.Client) error {
+func SetUser(u User, redisClient redis.UniversalClient) error {
 	if u.Rpm < 0 || u.Tpm < 0 {
 		return fmt.Errorf("rpm or tpm can not negative")
 	}
@@ -66,7 +75,7 @@ func SetUser(u User, redisClient *redis.Client) error {
 	return redisClient.Set(context.Background(), genKey(u.Name), string(b), 0).Err()
 }
 
-func DelUser(u User, redisClient *redis.Client) error {
+func DelUser(u User, redisClient redis.UniversalClient) error {
 	return redisClient.Del(context.Background(), genKey(u.Name)).Err()
 }
 
@@ -20,9 +20,13 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -30,6 +34,9 @@ import (
 	modelapi "github.com/vllm-project/aibrix/api/model/v1alpha1"
 )
 
+// artifactURLSchemes are the artifact sources the ModelAdapter controller can load adapters from.
+var artifactURLSchemes = []string{"s3://", "gcs://", "oci://", "huggingface://", "hf://", "/"}
+
 type ModelAdapterWebhook struct{}
 
 // SetupBackendRuntimeWebhook will setup the manager to manage the webhooks
@@ -47,6 +54,28 @@ var _ webhook.CustomDefaulter = &ModelAdapterWebhook{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (w *ModelAdapterWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	adapter, ok := obj.(*modelapi.ModelAdapter)
+	if !ok {
+		return fmt.Errorf("expected a ModelAdapter but got a %T", obj)
+	}
+
+	if adapter.Spec.Replicas == nil {
+		adapter.Spec.Replicas = ptr.To[int32](1)
+	}
+	if adapter.Spec.SchedulingPolicy == "" {
+		adapter.Spec.SchedulingPolicy = modelapi.ModelAdapterSchedulingSpread
+	}
+	if strategy := adapter.Spec.RolloutStrategy; strategy != nil {
+		if strategy.CanaryPercent == 0 {
+			strategy.CanaryPercent = 20
+		}
+		if strategy.StepPercent == 0 {
+			strategy.StepPercent = 25
+		}
+		if strategy.AnalysisIntervalSeconds == 0 {
+			strategy.AnalysisIntervalSeconds = 60
+		}
+	}
 	return nil
 }
 
@@ -56,24 +85,87 @@ var _ webhook.CustomValidator = &ModelAdapterWebhook{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (w *ModelAdapterWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
-	var allErrs field.ErrorList
-	specPath := field.NewPath("spec")
-
-	adapter := obj.(*modelapi.ModelAdapter)
-
-	if _, err := url.ParseRequestURI(adapter.Spec.ArtifactURL); err != nil {
-		allErrs = append(allErrs, field.Invalid(specPath.Child("artifactURL"), adapter.Spec.ArtifactURL, fmt.Sprintf("artifactURL is invalid: %v", err)))
+	adapter, ok := obj.(*modelapi.ModelAdapter)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelAdapter but got a %T", obj)
 	}
-
-	return nil, allErrs.ToAggregate()
+	return nil, validateModelAdapter(adapter).ToAggregate()
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (w *ModelAdapterWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
-	return nil, nil
+	adapter, ok := newObj.(*modelapi.ModelAdapter)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelAdapter but got a %T", newObj)
+	}
+	return nil, validateModelAdapter(adapter).ToAggregate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (w *ModelAdapterWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	return nil, nil
 }
+
+func validateModelAdapter(adapter *modelapi.ModelAdapter) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, validateArtifactURL(specPath.Child("artifactURL"), adapter.Spec.ArtifactURL)...)
+	allErrs = append(allErrs, validatePodSelector(specPath.Child("podSelector"), adapter.Spec.PodSelector)...)
+
+	if adapter.Spec.Replicas != nil && *adapter.Spec.Replicas <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("replicas"), *adapter.Spec.Replicas, "must be greater than 0"))
+	}
+	if ref := adapter.Spec.CredentialsSecretRef; ref != nil && ref.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("credentialsSecretRef", "name"), "secret name is required"))
+	}
+	if strategy := adapter.Spec.RolloutStrategy; strategy != nil {
+		strategyPath := specPath.Child("rolloutStrategy")
+		allErrs = append(allErrs, validateNonNegativeFloat(strategyPath.Child("maxErrorRate"), strategy.MaxErrorRate)...)
+		allErrs = append(allErrs, validateNonNegativeFloat(strategyPath.Child("maxLatencyRatio"), strategy.MaxLatencyRatio)...)
+	}
+	return allErrs
+}
+
+func validateArtifactURL(path *field.Path, artifactURL string) field.ErrorList {
+	if artifactURL == "" {
+		return field.ErrorList{field.Required(path, "artifactURL is required")}
+	}
+	if _, err := url.ParseRequestURI(artifactURL); err != nil {
+		return field.ErrorList{field.Invalid(path, artifactURL, fmt.Sprintf("artifactURL is invalid: %v", err))}
+	}
+	for _, scheme := range artifactURLSchemes {
+		if strings.HasPrefix(artifactURL, scheme) {
+			return nil
+		}
+	}
+	return field.ErrorList{field.Invalid(path, artifactURL,
+		fmt.Sprintf("artifactURL must start with one of the following schemes: %s", strings.Join(artifactURLSchemes, ", ")))}
+}
+
+// validatePodSelector rejects selectors which can't be parsed, or which are empty and would place the adapter
+// on any pod of the namespace.
+func validatePodSelector(path *field.Path, selector *metav1.LabelSelector) field.ErrorList {
+	if selector == nil {
+		return field.ErrorList{field.Required(path, "podSelector is required")}
+	}
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return field.ErrorList{field.Invalid(path, selector, "podSelector must select the pods of the base model")}
+	}
+	if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+		return field.ErrorList{field.Invalid(path, selector, err.Error())}
+	}
+	return nil
+}
+
+// validateNonNegativeFloat validates an optional number formatted as string.
+func validateNonNegativeFloat(path *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v < 0 {
+		return field.ErrorList{field.Invalid(path, value, "must be a non-negative number")}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	autoscalingapi "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+type PodAutoscalerWebhook struct{}
+
+// SetupPodAutoscalerWebhook will setup the manager to manage the webhooks
+func SetupPodAutoscalerWebhook(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&autoscalingapi.PodAutoscaler{}).
+		WithDefaulter(&PodAutoscalerWebhook{}).
+		WithValidator(&PodAutoscalerWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-autoscaling-aibrix-ai-v1alpha1-podautoscaler,mutating=true,failurePolicy=fail,sideEffects=None,groups=autoscaling.aibrix.ai,resources=podautoscalers,verbs=create;update,versions=v1alpha1,name=mpodautoscaler.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &PodAutoscalerWebhook{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (w *PodAutoscalerWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	pa, ok := obj.(*autoscalingapi.PodAutoscaler)
+	if !ok {
+		return fmt.Errorf("expected a PodAutoscaler but got a %T", obj)
+	}
+
+	if pa.Spec.MetricsAggregation == "" && len(pa.Spec.MetricsSources) > 1 {
+		pa.Spec.MetricsAggregation = autoscalingapi.MaxAggregation
+	}
+	for i := range pa.Spec.MetricsSources {
+		source := &pa.Spec.MetricsSources[i]
+		if source.ProtocolType == "" && source.MetricSourceType != autoscalingapi.REDIS {
+			source.ProtocolType = autoscalingapi.HTTP
+		}
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-autoscaling-aibrix-ai-v1alpha1-podautoscaler,mutating=false,failurePolicy=fail,sideEffects=None,groups=autoscaling.aibrix.ai,resources=podautoscalers,verbs=create;update,versions=v1alpha1,name=vpodautoscaler.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &PodAutoscalerWebhook{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (w *PodAutoscalerWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	pa, ok := obj.(*autoscalingapi.PodAutoscaler)
+	if !ok {
+		return nil, fmt.Errorf("expected a PodAutoscaler but got a %T", obj)
+	}
+	return nil, validatePodAutoscaler(pa).ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (w *PodAutoscalerWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	pa, ok := newObj.(*autoscalingapi.PodAutoscaler)
+	if !ok {
+		return nil, fmt.Errorf("expected a PodAutoscaler but got a %T", newObj)
+	}
+	return nil, validatePodAutoscaler(pa).ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (w *PodAutoscalerWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validatePodAutoscaler(pa *autoscalingapi.PodAutoscaler) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if pa.Spec.ScaleTargetRef.Kind == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("scaleTargetRef", "kind"), "kind of the scale target is required"))
+	}
+	if pa.Spec.ScaleTargetRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("scaleTargetRef", "name"), "name of the scale target is required"))
+	}
+	if pa.Spec.RayWorkerGroup != "" && pa.Spec.ScaleTargetRef.Kind != "RayClusterFleet" {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("rayWorkerGroup"), pa.Spec.RayWorkerGroup, "rayWorkerGroup requires a RayClusterFleet scale target"))
+	}
+
+	allErrs = append(allErrs, validateReplicaBounds(specPath, pa.Spec.MinReplicas, &pa.Spec.MaxReplicas)...)
+
+	sourcesPath := specPath.Child("metricsSources")
+	switch pa.Spec.ScalingStrategy {
+	case autoscalingapi.SLO:
+		if len(pa.Spec.MetricsSources) != 0 {
+			allErrs = append(allErrs, field.Forbidden(sourcesPath, "the SLO scaling strategy takes no metricsSources"))
+		}
+		allErrs = append(allErrs, validateSLOTargets(specPath.Child("sloTargets"), pa.Spec.SLOTargets)...)
+	case autoscalingapi.HPA:
+		if len(pa.Spec.MetricsSources) != 1 {
+			allErrs = append(allErrs, field.Invalid(sourcesPath, len(pa.Spec.MetricsSources), "the HPA scaling strategy takes exactly one metric source"))
+		} else if sourceType := pa.Spec.MetricsSources[0].MetricSourceType; sourceType != autoscalingapi.POD {
+			allErrs = append(allErrs, field.NotSupported(sourcesPath.Index(0).Child("metricSourceType"), sourceType, []string{string(autoscalingapi.POD)}))
+		}
+	default:
+		if len(pa.Spec.MetricsSources) == 0 {
+			allErrs = append(allErrs, field.Required(sourcesPath, fmt.Sprintf("the %s scaling strategy requires metricsSources", pa.Spec.ScalingStrategy)))
+		}
+	}
+	if pa.Spec.ScalingStrategy != autoscalingapi.SLO && pa.Spec.SLOTargets != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("sloTargets"), "sloTargets require the SLO scaling strategy"))
+	}
+
+	for i := range pa.Spec.MetricsSources {
+		allErrs = append(allErrs, validateMetricSource(sourcesPath.Index(i), &pa.Spec.MetricsSources[i], pa.Spec.ScalingStrategy)...)
+	}
+
+	if behavior := pa.Spec.Behavior; behavior != nil && behavior.PanicThreshold != "" {
+		if v, err := strconv.ParseFloat(behavior.PanicThreshold, 64); err != nil || v <= 1 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("behavior", "panicThreshold"), behavior.PanicThreshold, "must be a number greater than 1"))
+		}
+	}
+
+	names := sets.New[string]()
+	for i, pool := range pa.Spec.Pools {
+		poolPath := specPath.Child("pools").Index(i)
+		if names.Has(pool.Name) {
+			allErrs = append(allErrs, field.Duplicate(poolPath.Child("name"), pool.Name))
+		}
+		names.Insert(pool.Name)
+		allErrs = append(allErrs, validatePositiveFloat(poolPath.Child("capacity"), pool.Capacity)...)
+		allErrs = append(allErrs, validatePositiveFloat(poolPath.Child("weight"), pool.Weight)...)
+		allErrs = append(allErrs, validateReplicaBounds(poolPath, pool.MinReplicas, pool.MaxReplicas)...)
+	}
+	return allErrs
+}
+
+// validateReplicaBounds checks 0 <= minReplicas <= maxReplicas and maxReplicas >= 1, either may be unset.
+func validateReplicaBounds(path *field.Path, minReplicas, maxReplicas *int32) field.ErrorList {
+	var allErrs field.ErrorList
+	if minReplicas != nil && *minReplicas < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("minReplicas"), *minReplicas, "must be greater than or equal to 0"))
+	}
+	if maxReplicas != nil && *maxReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxReplicas"), *maxReplicas, "must be greater than or equal to 1"))
+	}
+	if minReplicas != nil && maxReplicas != nil && *minReplicas > *maxReplicas {
+		allErrs = append(allErrs, field.Invalid(path.Child("minReplicas"), *minReplicas, "must be less than or equal to maxReplicas"))
+	}
+	return allErrs
+}
+
+func validateSLOTargets(path *field.Path, targets *autoscalingapi.SLOTargets) field.ErrorList {
+	if targets == nil {
+		return field.ErrorList{field.Required(path, "the SLO scaling strategy requires sloTargets")}
+	}
+	var allErrs field.ErrorList
+	if targets.TTFT == "" && targets.ITL == "" {
+		allErrs = append(allErrs, field.Required(path, "at least one of ttft and itl is required"))
+	}
+	allErrs = append(allErrs, validatePositiveFloat(path.Child("ttft"), targets.TTFT)...)
+	allErrs = append(allErrs, validatePositiveFloat(path.Child("itl"), targets.ITL)...)
+	if targets.Percentile != "" {
+		if v, err := strconv.ParseFloat(targets.Percentile, 64); err != nil || v <= 0 || v >= 100 {
+			allErrs = append(allErrs, field.Invalid(path.Child("percentile"), targets.Percentile, "must be a number between 0 and 100"))
+		}
+	}
+	return allErrs
+}
+
+// validateMetricSource checks the fields each metric source type requires, and that the configuration of other
+// source types isn't set.
+func validateMetricSource(path *field.Path, source *autoscalingapi.MetricSource, strategy autoscalingapi.ScalingStrategyType) field.ErrorList {
+	var allErrs field.ErrorList
+	if source.TargetMetric == "" {
+		allErrs = append(allErrs, field.Required(path.Child("targetMetric"), "targetMetric is required"))
+	}
+	if source.TargetValue == "" {
+		allErrs = append(allErrs, field.Required(path.Child("targetValue"), "targetValue is required"))
+	} else {
+		allErrs = append(allErrs, validatePositiveFloat(path.Child("targetValue"), source.TargetValue)...)
+	}
+	allErrs = append(allErrs, validatePositiveFloat(path.Child("weight"), source.Weight)...)
+	allErrs = append(allErrs, validateNonNegativeFloat(path.Child("upFluctuationTolerance"), source.UpFluctuationTolerance)...)
+	allErrs = append(allErrs, validateNonNegativeFloat(path.Child("downFluctuationTolerance"), source.DownFluctuationTolerance)...)
+
+	if source.MetricSourceType == autoscalingapi.POD {
+		// the HPA reads pod metrics from the metrics APIs rather than scraping the pods.
+		if source.Port == "" && strategy != autoscalingapi.HPA {
+			allErrs = append(allErrs, field.Required(path.Child("port"), "port is required for the pod metric source"))
+		}
+		if source.Auth != nil {
+			allErrs = append(allErrs, field.Forbidden(path.Child("auth"), "auth is not supported for the pod metric source"))
+		}
+	} else if source.Endpoint == "" {
+		allErrs = append(allErrs, field.Required(path.Child("endpoint"), fmt.Sprintf("endpoint is required for the %s metric source", source.MetricSourceType)))
+	}
+
+	configs := []struct {
+		name       string
+		sourceType autoscalingapi.MetricSourceType
+		set        bool
+	}{
+		{"kafka", autoscalingapi.KAFKA, source.Kafka != nil},
+		{"redis", autoscalingapi.REDIS, source.Redis != nil},
+		{"json", autoscalingapi.JSON, source.JSON != nil},
+	}
+	for _, config := range configs {
+		switch {
+		case config.sourceType == source.MetricSourceType && !config.set:
+			allErrs = append(allErrs, field.Required(path.Child(config.name), fmt.Sprintf("%s is required for the %s metric source", config.name, source.MetricSourceType)))
+		case config.sourceType != source.MetricSourceType && config.set:
+			allErrs = append(allErrs, field.Forbidden(path.Child(config.name), fmt.Sprintf("%s is only supported for the %s metric source", config.name, config.sourceType)))
+		}
+	}
+	return allErrs
+}
+
+// validatePositiveFloat validates an optional number formatted as string.
+func validatePositiveFloat(path *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	if v, err := strconv.ParseFloat(value, 64); err != nil || v <= 0 {
+		return field.ErrorList{field.Invalid(path, value, "must be a positive number")}
+	}
+	return nil
+}
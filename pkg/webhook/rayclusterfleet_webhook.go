@@ -0,0 +1,301 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	rayclusterv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	orchestrationapi "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+)
+
+// defaultRollingUpdateFraction is the maxSurge and maxUnavailable a RayClusterFleet rolls out with
+// when they are not specified, the same as a Deployment.
+var defaultRollingUpdateFraction = intstr.FromString("25%")
+
+type RayClusterFleetWebhook struct{}
+
+// SetupRayClusterFleetWebhook will setup the manager to manage the webhooks
+func SetupRayClusterFleetWebhook(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&orchestrationapi.RayClusterFleet{}).
+		WithDefaulter(&RayClusterFleetWebhook{}).
+		WithValidator(&RayClusterFleetWebhook{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-orchestration-aibrix-ai-v1alpha1-rayclusterfleet,mutating=true,failurePolicy=fail,sideEffects=None,groups=orchestration.aibrix.ai,resources=rayclusterfleets,verbs=create;update,versions=v1alpha1,name=mrayclusterfleet.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &RayClusterFleetWebhook{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (w *RayClusterFleetWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	fleet, ok := obj.(*orchestrationapi.RayClusterFleet)
+	if !ok {
+		return fmt.Errorf("expected a RayClusterFleet but got a %T", obj)
+	}
+
+	if fleet.Spec.Replicas == nil {
+		fleet.Spec.Replicas = ptr.To[int32](1)
+	}
+	strategy := &fleet.Spec.Strategy
+	if strategy.Type == "" {
+		strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+	}
+	if strategy.Type == appsv1.RollingUpdateDeploymentStrategyType {
+		if strategy.RollingUpdate == nil {
+			strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{}
+		}
+		if strategy.RollingUpdate.MaxSurge == nil {
+			strategy.RollingUpdate.MaxSurge = ptr.To(defaultRollingUpdateFraction)
+		}
+		if strategy.RollingUpdate.MaxUnavailable == nil {
+			strategy.RollingUpdate.MaxUnavailable = ptr.To(defaultRollingUpdateFraction)
+		}
+	}
+	if budget := fleet.Spec.DisruptionBudget; budget != nil && budget.MaxUnavailable == nil {
+		budget.MaxUnavailable = ptr.To(intstr.FromInt32(1))
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-orchestration-aibrix-ai-v1alpha1-rayclusterfleet,mutating=false,failurePolicy=fail,sideEffects=None,groups=orchestration.aibrix.ai,resources=rayclusterfleets,verbs=create;update,versions=v1alpha1,name=vrayclusterfleet.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &RayClusterFleetWebhook{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (w *RayClusterFleetWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	fleet, ok := obj.(*orchestrationapi.RayClusterFleet)
+	if !ok {
+		return nil, fmt.Errorf("expected a RayClusterFleet but got a %T", obj)
+	}
+	return nil, validateRayClusterFleet(fleet).ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (w *RayClusterFleetWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldFleet, ok := oldObj.(*orchestrationapi.RayClusterFleet)
+	if !ok {
+		return nil, fmt.Errorf("expected a RayClusterFleet but got a %T", oldObj)
+	}
+	fleet, ok := newObj.(*orchestrationapi.RayClusterFleet)
+	if !ok {
+		return nil, fmt.Errorf("expected a RayClusterFleet but got a %T", newObj)
+	}
+
+	allErrs := validateRayClusterFleet(fleet)
+	// the revision hash labels of the member replica sets are derived from the selector, same as a Deployment.
+	if oldFleet.Spec.Selector != nil && !equality.Semantic.DeepEqual(oldFleet.Spec.Selector, fleet.Spec.Selector) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "selector"), fleet.Spec.Selector, "field is immutable"))
+	}
+	return nil, allErrs.ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (w *RayClusterFleetWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateRayClusterFleet(fleet *orchestrationapi.RayClusterFleet) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if fleet.Spec.Replicas != nil && *fleet.Spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("replicas"), *fleet.Spec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	selectorPath := specPath.Child("selector")
+	if fleet.Spec.Selector == nil {
+		allErrs = append(allErrs, field.Required(selectorPath, "selector is required"))
+	} else if selector, err := metav1.LabelSelectorAsSelector(fleet.Spec.Selector); err != nil {
+		allErrs = append(allErrs, field.Invalid(selectorPath, fleet.Spec.Selector, err.Error()))
+	} else if selector.Empty() {
+		allErrs = append(allErrs, field.Invalid(selectorPath, fleet.Spec.Selector, "empty selector is invalid for a RayClusterFleet"))
+	} else if !selector.Matches(labels.Set(fleet.Spec.Template.Labels)) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("template", "metadata", "labels"), fleet.Spec.Template.Labels, "`selector` does not match template `labels`"))
+	}
+
+	allErrs = append(allErrs, validateFleetStrategy(specPath.Child("strategy"), &fleet.Spec.Strategy)...)
+	if partition := fleet.Spec.Partition; partition != nil {
+		if *partition < 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("partition"), *partition, "must be greater than or equal to 0"))
+		} else if *partition > 0 && fleet.Spec.Strategy.Type != appsv1.RollingUpdateDeploymentStrategyType {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("partition"), "may only be specified when strategy `type` is 'RollingUpdate'"))
+		}
+	}
+
+	for i, constraint := range fleet.Spec.TopologySpreadConstraints {
+		allErrs = append(allErrs, validateTopologySpreadConstraint(specPath.Child("topologySpreadConstraints").Index(i), &constraint)...)
+	}
+	if budget := fleet.Spec.DisruptionBudget; budget != nil && budget.MaxUnavailable != nil {
+		allErrs = append(allErrs, validateIntOrPercent(specPath.Child("disruptionBudget", "maxUnavailable"), *budget.MaxUnavailable, true)...)
+	}
+	if policy := fleet.Spec.GangScheduling; policy != nil {
+		allErrs = append(allErrs, validateGangScheduling(specPath.Child("gangScheduling"), policy, &fleet.Spec.Template.Spec)...)
+	}
+	if probe := fleet.Spec.ServingProbe; probe != nil {
+		allErrs = append(allErrs, validateServingProbe(specPath.Child("servingProbe"), probe)...)
+	}
+
+	if fleet.Spec.MinReadySeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("minReadySeconds"), fleet.Spec.MinReadySeconds, "must be greater than or equal to 0"))
+	}
+	if limit := fleet.Spec.RevisionHistoryLimit; limit != nil && *limit < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("revisionHistoryLimit"), *limit, "must be greater than or equal to 0"))
+	}
+	if deadline := fleet.Spec.ProgressDeadlineSeconds; deadline != nil && *deadline <= fleet.Spec.MinReadySeconds {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("progressDeadlineSeconds"), *deadline, "must be greater than minReadySeconds"))
+	}
+	return allErrs
+}
+
+// validateFleetStrategy checks maxSurge and maxUnavailable the way the Deployment validation does.
+func validateFleetStrategy(path *field.Path, strategy *appsv1.DeploymentStrategy) field.ErrorList {
+	var allErrs field.ErrorList
+	switch strategy.Type {
+	case appsv1.RecreateDeploymentStrategyType:
+		if strategy.RollingUpdate != nil {
+			allErrs = append(allErrs, field.Forbidden(path.Child("rollingUpdate"), "may not be specified when strategy `type` is 'Recreate'"))
+		}
+	case appsv1.RollingUpdateDeploymentStrategyType:
+		if strategy.RollingUpdate == nil {
+			return append(allErrs, field.Required(path.Child("rollingUpdate"), "this should be defaulted and never be nil"))
+		}
+		rollingPath := path.Child("rollingUpdate")
+		maxSurge := intstr.ValueOrDefault(strategy.RollingUpdate.MaxSurge, intstr.FromInt32(0))
+		maxUnavailable := intstr.ValueOrDefault(strategy.RollingUpdate.MaxUnavailable, intstr.FromInt32(0))
+		allErrs = append(allErrs, validateIntOrPercent(rollingPath.Child("maxSurge"), *maxSurge, false)...)
+		allErrs = append(allErrs, validateIntOrPercent(rollingPath.Child("maxUnavailable"), *maxUnavailable, true)...)
+		if isZeroIntOrPercent(*maxSurge) && isZeroIntOrPercent(*maxUnavailable) {
+			// both zero would never let the rollout make progress.
+			allErrs = append(allErrs, field.Invalid(rollingPath.Child("maxUnavailable"), maxUnavailable.String(), "may not be 0 when `maxSurge` is 0"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), strategy.Type,
+			[]string{string(appsv1.RecreateDeploymentStrategyType), string(appsv1.RollingUpdateDeploymentStrategyType)}))
+	}
+	return allErrs
+}
+
+// validateTopologySpreadConstraint checks the fields the scheduler requires of a spread constraint.
+func validateTopologySpreadConstraint(path *field.Path, constraint *corev1.TopologySpreadConstraint) field.ErrorList {
+	var allErrs field.ErrorList
+	if constraint.MaxSkew <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxSkew"), constraint.MaxSkew, "must be greater than zero"))
+	}
+	if constraint.TopologyKey == "" {
+		allErrs = append(allErrs, field.Required(path.Child("topologyKey"), "can not be empty"))
+	}
+	switch constraint.WhenUnsatisfiable {
+	case corev1.DoNotSchedule, corev1.ScheduleAnyway:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("whenUnsatisfiable"), constraint.WhenUnsatisfiable,
+			[]string{string(corev1.DoNotSchedule), string(corev1.ScheduleAnyway)}))
+	}
+	return allErrs
+}
+
+// validateGangScheduling checks the policy can be served by its scheduler.
+func validateGangScheduling(path *field.Path, policy *orchestrationapi.GangSchedulingPolicy, spec *rayclusterv1.RayClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	switch policy.Scheduler {
+	case orchestrationapi.VolcanoGangScheduler:
+	case orchestrationapi.KueueGangScheduler:
+		if policy.Queue == "" {
+			allErrs = append(allErrs, field.Required(path.Child("queue"), "kueue requires the LocalQueue of the ray clusters"))
+		}
+		// kueue admits a fixed number of pods, it doesn't support ray clusters that autoscale.
+		if spec.EnableInTreeAutoscaling != nil && *spec.EnableInTreeAutoscaling {
+			allErrs = append(allErrs, field.Forbidden(path.Child("scheduler"), "kueue doesn't support ray clusters with in-tree autoscaling enabled"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("scheduler"), policy.Scheduler,
+			[]string{string(orchestrationapi.VolcanoGangScheduler), string(orchestrationapi.KueueGangScheduler)}))
+	}
+	return allErrs
+}
+
+// validateServingProbe checks the probe has exactly one handler with a valid port.
+func validateServingProbe(path *field.Path, probe *orchestrationapi.ServingProbe) field.ErrorList {
+	var allErrs field.ErrorList
+	switch {
+	case probe.HTTPGet != nil && probe.GRPC != nil:
+		allErrs = append(allErrs, field.Forbidden(path.Child("grpc"), "may not specify more than 1 handler type"))
+	case probe.HTTPGet != nil:
+		if port := probe.HTTPGet.Port; port.Type == intstr.Int && (port.IntVal < 1 || port.IntVal > 65535) {
+			allErrs = append(allErrs, field.Invalid(path.Child("httpGet", "port"), port.IntVal, "must be between 1 and 65535, inclusive"))
+		} else if port.Type == intstr.String && port.StrVal == "" {
+			allErrs = append(allErrs, field.Required(path.Child("httpGet", "port"), "must be a number or the name of a head container port"))
+		}
+	case probe.GRPC != nil:
+		if port := probe.GRPC.Port; port < 1 || port > 65535 {
+			allErrs = append(allErrs, field.Invalid(path.Child("grpc", "port"), port, "must be between 1 and 65535, inclusive"))
+		}
+	default:
+		allErrs = append(allErrs, field.Required(path, "must specify a handler type"))
+	}
+	if probe.TimeoutSeconds < 1 {
+		allErrs = append(allErrs, field.Invalid(path.Child("timeoutSeconds"), probe.TimeoutSeconds, "must be greater than 0"))
+	}
+	if probe.PeriodSeconds < 1 {
+		allErrs = append(allErrs, field.Invalid(path.Child("periodSeconds"), probe.PeriodSeconds, "must be greater than 0"))
+	}
+	return allErrs
+}
+
+// validateIntOrPercent checks the value is a non-negative integer or percentage, capped at 100% if requested.
+func validateIntOrPercent(path *field.Path, value intstr.IntOrString, capAtHundred bool) field.ErrorList {
+	if value.Type == intstr.Int {
+		if value.IntVal < 0 {
+			return field.ErrorList{field.Invalid(path, value.IntVal, "must be greater than or equal to 0")}
+		}
+		return nil
+	}
+	percent, err := intstr.GetScaledValueFromIntOrPercent(&value, 100, false)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, value.StrVal, "must be an integer or percentage (e.g '5%')")}
+	}
+	if percent < 0 {
+		return field.ErrorList{field.Invalid(path, value.StrVal, "must be greater than or equal to 0")}
+	}
+	if capAtHundred && percent > 100 {
+		return field.ErrorList{field.Invalid(path, value.StrVal, "must not be greater than 100%")}
+	}
+	return nil
+}
+
+func isZeroIntOrPercent(value intstr.IntOrString) bool {
+	if value.Type == intstr.Int {
+		return value.IntVal == 0
+	}
+	percent, err := intstr.GetScaledValueFromIntOrPercent(&value, 100, false)
+	return err == nil && percent == 0
+}
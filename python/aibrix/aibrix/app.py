@@ -32,6 +32,7 @@ from aibrix.openapi.protocol import (
     ErrorResponse,
     ListModelRequest,
     LoadLoraAdapterRequest,
+    ReloadEngineConfigRequest,
     UnloadLoraAdapterRequest,
 )
 
@@ -145,6 +146,17 @@ async def unload_lora_adapter(request: UnloadLoraAdapterRequest, raw_request: Re
     return Response(status_code=200, content=response)
 
 
+@router.post("/v1/engine/config/reload")
+async def reload_engine_config(
+    request: ReloadEngineConfigRequest, raw_request: Request
+):
+    response = await inference_engine(raw_request).reload_config(request)
+    if isinstance(response, ErrorResponse):
+        return JSONResponse(content=response.model_dump(), status_code=response.code)
+
+    return Response(status_code=200, content=response)
+
+
 # /v1/models is a query to inference engine, this is different from following
 # /v1/model/list which is used to fetch runtime managed models locally.
 @router.get("/v1/models")
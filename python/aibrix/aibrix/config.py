@@ -18,7 +18,7 @@ DEFAULT_METRIC_COLLECTOR_TIMEOUT = 1
 DOWNLOAD CACHE DIR would be like:
 .
 └── .cache
-    └── huggingface | s3 | tos
+    └── huggingface | s3 | tos | gcs | oci
         ├── .gitignore
         └── download
 """
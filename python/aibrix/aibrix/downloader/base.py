@@ -12,7 +12,9 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import hashlib
 import re
+import shutil
 import time
 from abc import ABC, abstractmethod
 from concurrent.futures import ThreadPoolExecutor, wait
@@ -21,7 +23,8 @@ from pathlib import Path
 from typing import ClassVar, Dict, List, Optional
 
 from aibrix import envs
-from aibrix.downloader.entity import RemoteSource
+from aibrix.downloader.entity import RemoteSource, get_local_download_paths
+from aibrix.downloader.utils import save_meta_data
 from aibrix.logger import init_logger
 
 logger = init_logger(__name__)
@@ -42,6 +45,10 @@ class DownloadExtraConfig:
     hf_token: Optional[str] = None
     hf_revision: Optional[str] = None
 
+    # Auth config for oci registry
+    username: Optional[str] = None
+    password: Optional[str] = None
+
     # parrallel config
     num_threads: Optional[int] = None
     max_io_queue: Optional[int] = None
@@ -52,6 +59,8 @@ class DownloadExtraConfig:
     # other config
     allow_file_suffix: Optional[List[str]] = None
     force_download: Optional[bool] = None
+    # sha256 digest of the adapter weights, e.g. "sha256:<hex>"
+    checksum: Optional[str] = None
 
 
 DEFAULT_DOWNLOADER_EXTRA_CONFIG = DownloadExtraConfig()
@@ -179,15 +188,28 @@ class BaseDownloader(ABC):
 
         # TODO check local file exists
         st = time.perf_counter()
-        if self._is_directory():
-            self.download_directory(local_path=model_path)
-        else:
-            self.download(
-                local_path=model_path,
-                bucket_path=self.bucket_path,
-                bucket_name=self.bucket_name,
-                enable_range=self._support_range_download(),
+        checksum = self.download_extra_config.checksum
+        if checksum:
+            # The checksum is tracked as one more file of the model, so the model
+            # is only reported as downloaded once the weights are verified.
+            checksum_file = get_local_download_paths(
+                model_path, CHECKSUM_FILE_NAME, self.source
             )
+            with checksum_file.download_lock():
+                self._download_files(model_path)
+                try:
+                    verify_checksum(model_path, checksum)
+                except ValueError:
+                    logger.error(
+                        f"Checksum verification of {self.model_uri} failed, "
+                        f"removing the downloaded files in {model_path}."
+                    )
+                    shutil.rmtree(model_path, ignore_errors=True)
+                    raise
+                checksum_file.file_path.write_text(checksum)
+                save_meta_data(checksum_file.metadata_path, checksum)
+        else:
+            self._download_files(model_path)
         duration = time.perf_counter() - st
         logger.info(
             f"Downloader {self.__class__.__name__} download "
@@ -197,10 +219,55 @@ class BaseDownloader(ABC):
 
         return model_path
 
+    def _download_files(self, model_path: Path):
+        if self._is_directory():
+            self.download_directory(local_path=model_path)
+        else:
+            self.download(
+                local_path=model_path,
+                bucket_path=self.bucket_path,
+                bucket_name=self.bucket_name,
+                enable_range=self._support_range_download(),
+            )
+
     def __hash__(self):
         return hash(tuple(self.__dict__))
 
 
+CHECKSUM_FILE_NAME = ".checksum"
+CHECKSUM_TARGET_FILE_NAME = "adapter_model.safetensors"
+
+
+def verify_checksum(model_path: Path, checksum: str):
+    """Verify the sha256 digest of the adapter weights in model_path.
+    The weights are adapter_model.safetensors, or the only file downloaded."""
+    algorithm, _, expected = checksum.partition(":")
+    if algorithm != "sha256" or not expected:
+        raise ValueError(f"Unsupported checksum {checksum}, expect sha256:<hex>.")
+
+    target = model_path.joinpath(CHECKSUM_TARGET_FILE_NAME)
+    if not target.exists():
+        files = [
+            file
+            for file in model_path.iterdir()
+            if file.is_file() and not file.name.startswith(".")
+        ]
+        if len(files) != 1:
+            raise ValueError(
+                f"Can not find {CHECKSUM_TARGET_FILE_NAME} in {model_path} to verify."
+            )
+        target = files[0]
+
+    digest = hashlib.sha256()
+    with open(target, "rb") as f:
+        for chunk in iter(lambda: f.read(1024 * 1024), b""):
+            digest.update(chunk)
+    if digest.hexdigest() != expected:
+        raise ValueError(
+            f"Checksum of {target} is sha256:{digest.hexdigest()}, expect {checksum}."
+        )
+
+
 def get_downloader(
     model_uri: str,
     model_name: Optional[str] = None,
@@ -218,6 +285,18 @@ def get_downloader(
         from aibrix.downloader.s3 import S3Downloader
 
         return S3Downloader(model_uri, model_name, download_config, enable_progress_bar)
+    elif re.match(envs.DOWNLOADER_GCS_REGEX, model_uri):
+        from aibrix.downloader.gcs import GCSDownloader
+
+        return GCSDownloader(
+            model_uri, model_name, download_config, enable_progress_bar
+        )
+    elif re.match(envs.DOWNLOADER_OCI_REGEX, model_uri):
+        from aibrix.downloader.oci import OCIDownloader
+
+        return OCIDownloader(
+            model_uri, model_name, download_config, enable_progress_bar
+        )
     elif re.match(envs.DOWNLOADER_TOS_REGEX, model_uri):
         if envs.DOWNLOADER_TOS_VERSION == "v1":
             from aibrix.downloader.tos import TOSDownloaderV1
@@ -30,6 +30,8 @@ logger = init_logger(__name__)
 class RemoteSource(Enum):
     S3 = "s3"
     TOS = "tos"
+    GCS = "gcs"
+    OCI = "oci"
     HUGGINGFACE = "huggingface"
     UNKNOWN = "unknown"
 
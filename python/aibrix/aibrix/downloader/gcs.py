@@ -0,0 +1,67 @@
+# Copyright 2024 The Aibrix Team.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+from typing import ClassVar, Dict, Optional
+
+from aibrix import envs
+from aibrix.common.errors import ArgNotCongiuredError
+from aibrix.downloader.base import (
+    DEFAULT_DOWNLOADER_EXTRA_CONFIG,
+    DownloadExtraConfig,
+)
+from aibrix.downloader.entity import RemoteSource
+from aibrix.downloader.s3 import S3BaseDownloader
+
+
+class GCSDownloader(S3BaseDownloader):
+    """Downloader for Google Cloud Storage through its S3 compatible XML API,
+    authenticated with HMAC keys."""
+
+    _source: ClassVar[RemoteSource] = RemoteSource.GCS
+
+    def __init__(
+        self,
+        model_uri,
+        model_name: Optional[str] = None,
+        download_extra_config: DownloadExtraConfig = DEFAULT_DOWNLOADER_EXTRA_CONFIG,
+        enable_progress_bar: bool = False,
+    ):
+        super().__init__(
+            scheme="gcs",
+            model_uri=model_uri,
+            model_name=model_name,
+            download_extra_config=download_extra_config,
+            enable_progress_bar=enable_progress_bar,
+        )  # type: ignore
+
+    def _get_auth_config(self) -> Dict[str, Optional[str]]:
+        ak, sk = (
+            self.download_extra_config.ak or envs.DOWNLOADER_GCS_ACCESS_KEY_ID,
+            self.download_extra_config.sk or envs.DOWNLOADER_GCS_SECRET_ACCESS_KEY,
+        )
+        if ak is None or ak == "":
+            raise ArgNotCongiuredError(
+                arg_name="ak", arg_source="--download-extra-config"
+            )
+        if sk is None or sk == "":
+            raise ArgNotCongiuredError(
+                arg_name="sk", arg_source="--download-extra-config"
+            )
+
+        return {
+            "region_name": self.download_extra_config.region or "auto",
+            "endpoint_url": self.download_extra_config.endpoint
+            or envs.DOWNLOADER_GCS_ENDPOINT_URL,
+            "aws_access_key_id": ak,
+            "aws_secret_access_key": sk,
+        }
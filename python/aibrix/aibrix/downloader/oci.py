@@ -0,0 +1,267 @@
+# Copyright 2024 The Aibrix Team.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+import hashlib
+import re
+from contextlib import nullcontext
+from functools import lru_cache
+from pathlib import Path
+from typing import ClassVar, Dict, List, Optional, Tuple
+from urllib.parse import urlparse
+
+import httpx
+from tqdm import tqdm
+
+from aibrix import envs
+from aibrix.common.errors import ArgNotCongiuredError, ModelNotFoundError
+from aibrix.downloader.base import (
+    DEFAULT_DOWNLOADER_EXTRA_CONFIG,
+    BaseDownloader,
+    DownloadExtraConfig,
+)
+from aibrix.downloader.entity import RemoteSource, get_local_download_paths
+from aibrix.downloader.utils import meta_file, need_to_download, save_meta_data
+from aibrix.logger import init_logger
+
+logger = init_logger(__name__)
+
+OCI_MANIFEST_MEDIA_TYPES = ",".join(
+    [
+        "application/vnd.oci.image.manifest.v1+json",
+        "application/vnd.docker.distribution.manifest.v2+json",
+    ]
+)
+OCI_TITLE_ANNOTATION = "org.opencontainers.image.title"
+OCI_DEFAULT_REFERENCE = "latest"
+
+
+def _parse_reference_from_uri(uri: str) -> Tuple[str, str, str]:
+    """Parse oci://registry/repository[:tag|@digest] into
+    (registry, repository, reference)."""
+    parsed = urlparse(uri, scheme="oci")
+    registry = parsed.netloc
+    repository = parsed.path.lstrip("/")
+    reference = OCI_DEFAULT_REFERENCE
+    if "@" in repository:
+        repository, reference = repository.split("@", 1)
+    elif ":" in repository.split("/")[-1]:
+        repository, reference = repository.rsplit(":", 1)
+    return registry, repository, reference
+
+
+def _parse_auth_challenge(header: str) -> Dict[str, str]:
+    """Parse the parameters of a `WWW-Authenticate: Bearer ...` header."""
+    return dict(re.findall(r'(\w+)="([^"]*)"', header))
+
+
+class OCIDownloader(BaseDownloader):
+    """Downloader for artifacts pushed to an OCI registry, e.g. with oras.
+    Every layer is a file of the model, named by its title annotation."""
+
+    _source: ClassVar[RemoteSource] = RemoteSource.OCI
+
+    def __init__(
+        self,
+        model_uri,
+        model_name: Optional[str] = None,
+        download_extra_config: DownloadExtraConfig = DEFAULT_DOWNLOADER_EXTRA_CONFIG,
+        enable_progress_bar: bool = False,
+    ):
+        registry, repository, reference = _parse_reference_from_uri(model_uri)
+        if model_name is None:
+            model_name = repository.split("/")[-1]
+            logger.info(f"model_name is not set, using `{model_name}` as model_name")
+
+        self.download_extra_config = download_extra_config
+        self.reference = reference
+        self.username = (
+            self.download_extra_config.username or envs.DOWNLOADER_OCI_USERNAME
+        )
+        self.password = (
+            self.download_extra_config.password or envs.DOWNLOADER_OCI_PASSWORD
+        )
+        endpoint = self.download_extra_config.endpoint or f"https://{registry}"
+        self.client = httpx.Client(base_url=endpoint, follow_redirects=True)
+        self._token: Optional[str] = None
+
+        super().__init__(
+            model_uri=model_uri,
+            model_name=model_name,
+            bucket_path=repository,
+            bucket_name=registry,
+            download_extra_config=download_extra_config,
+            enable_progress_bar=enable_progress_bar,
+        )  # type: ignore
+
+    def _valid_config(self):
+        if self.model_name is None or self.model_name == "":
+            raise ArgNotCongiuredError(arg_name="model_name", arg_source="--model-name")
+
+        if self.bucket_name is None or self.bucket_name == "":
+            raise ArgNotCongiuredError(arg_name="registry", arg_source="--model-uri")
+
+        if self.bucket_path is None or self.bucket_path == "":
+            raise ArgNotCongiuredError(arg_name="repository", arg_source="--model-uri")
+
+        try:
+            self._manifest()
+        except Exception as e:
+            logger.error(f"OCI artifact {self.model_uri} not exist for {e}")
+            raise ModelNotFoundError(model_uri=self.model_uri, detail_msg=str(e))
+
+    def _basic_auth(self) -> Optional[Tuple[str, str]]:
+        if self.username and self.password:
+            return (self.username, self.password)
+        return None
+
+    def _fetch_token(self, challenge: str) -> Optional[str]:
+        params = _parse_auth_challenge(challenge)
+        realm = params.pop("realm", None)
+        if realm is None:
+            return None
+        resp = httpx.get(realm, params=params, auth=self._basic_auth())
+        resp.raise_for_status()
+        body = resp.json()
+        return body.get("token") or body.get("access_token")
+
+    def _send(self, method: str, url: str, headers: Dict[str, str]) -> httpx.Response:
+        """Send a request to the registry, authenticating with a bearer token
+        from the registry's token endpoint or basic auth when challenged."""
+        request_headers = dict(headers)
+        if self._token is not None:
+            request_headers["Authorization"] = f"Bearer {self._token}"
+        request = self.client.build_request(method, url, headers=request_headers)
+        resp = self.client.send(request, stream=True)
+        if resp.status_code != httpx.codes.UNAUTHORIZED:
+            return resp
+
+        challenge = resp.headers.get("WWW-Authenticate", "")
+        resp.close()
+        request_headers.pop("Authorization", None)
+        auth = None
+        if challenge.lower().startswith("bearer"):
+            self._token = self._fetch_token(challenge)
+            if self._token is not None:
+                request_headers["Authorization"] = f"Bearer {self._token}"
+        else:
+            auth = self._basic_auth()
+        request = self.client.build_request(method, url, headers=request_headers)
+        return self.client.send(request, stream=True, auth=auth)
+
+    @lru_cache()
+    def _manifest(self) -> Dict:
+        resp = self._send(
+            "GET",
+            f"/v2/{self.bucket_path}/manifests/{self.reference}",
+            headers={"Accept": OCI_MANIFEST_MEDIA_TYPES},
+        )
+        try:
+            resp.read()
+            resp.raise_for_status()
+            return resp.json()
+        finally:
+            resp.close()
+
+    def _layers(self) -> Dict[str, Dict]:
+        layers = {}
+        for layer in self._manifest().get("layers", []):
+            title = layer.get("annotations", {}).get(OCI_TITLE_ANNOTATION)
+            if title is None:
+                logger.warning(
+                    f"Skip layer {layer.get('digest')} of {self.model_uri} "
+                    f"without {OCI_TITLE_ANNOTATION} annotation."
+                )
+                continue
+            layers[title] = layer
+        return layers
+
+    def _is_directory(self) -> bool:
+        """Check if model_uri is a directory."""
+        return len(self._layers()) != 1
+
+    def _directory_list(self, path: str) -> List[str]:
+        return list(self._layers().keys())
+
+    def _support_range_download(self) -> bool:
+        return False
+
+    def _download_files(self, model_path: Path):
+        if self._is_directory():
+            self.download_directory(local_path=model_path)
+        else:
+            # a single layer artifact is downloaded by its title
+            self.download(
+                local_path=model_path,
+                bucket_path=next(iter(self._layers().keys())),
+                bucket_name=self.bucket_name,
+                enable_range=False,
+            )
+
+    def download(
+        self,
+        local_path: Path,
+        bucket_path: str,
+        bucket_name: Optional[str] = None,
+        enable_range: bool = True,
+    ):
+        layer = self._layers().get(bucket_path)
+        if layer is None:
+            raise ValueError(
+                f"OCI artifact {self.model_uri} has no file {bucket_path}."
+            )
+
+        _file_name = bucket_path.split("/")[-1]
+        local_file = local_path.joinpath(_file_name).absolute()
+
+        digest = layer.get("digest", "")
+        file_size = layer.get("size", 0)
+        meta_data_file = meta_file(
+            local_path=local_path, file_name=_file_name, source=self._source.value
+        )
+
+        if not need_to_download(local_file, meta_data_file, file_size, digest):
+            return
+
+        algorithm, _, expected = digest.partition(":")
+        if algorithm != "sha256":
+            raise ValueError(f"Unsupported digest {digest} of {bucket_path}.")
+
+        with tqdm(
+            desc=_file_name, total=file_size, unit="b", unit_scale=True
+        ) if self.enable_progress_bar else nullcontext() as pbar:
+            download_file = get_local_download_paths(
+                local_path, _file_name, self._source
+            )
+            with download_file.download_lock():
+                resp = self._send(
+                    "GET", f"/v2/{self.bucket_path}/blobs/{digest}", headers={}
+                )
+                try:
+                    resp.raise_for_status()
+                    sha256 = hashlib.sha256()
+                    with open(local_file, "wb") as f:
+                        for chunk in resp.iter_bytes():
+                            sha256.update(chunk)
+                            f.write(chunk)
+                            if pbar is not None:
+                                pbar.update(len(chunk))
+                finally:
+                    resp.close()
+
+                if sha256.hexdigest() != expected:
+                    local_file.unlink(missing_ok=True)
+                    raise ValueError(
+                        f"Digest of {bucket_path} is sha256:{sha256.hexdigest()}, "
+                        f"expect {digest}."
+                    )
+                save_meta_data(meta_data_file, digest)
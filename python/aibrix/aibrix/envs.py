@@ -65,6 +65,8 @@ DOWNLOADER_CHECK_FILE_EXIST = _is_true(os.getenv("DOWNLOADER_CHECK_FILE_EXIST",
 # Downloader Regex
 DOWNLOADER_S3_REGEX = r"^s3://"
 DOWNLOADER_TOS_REGEX = r"^tos://"
+DOWNLOADER_GCS_REGEX = r"^gcs://"
+DOWNLOADER_OCI_REGEX = r"^oci://"
 
 # Downloader HuggingFace Envs
 DOWNLOADER_HF_TOKEN = os.getenv("HF_TOKEN")
@@ -85,6 +87,17 @@ DOWNLOADER_AWS_SECRET_ACCESS_KEY = os.getenv("AWS_SECRET_ACCESS_KEY")
 DOWNLOADER_AWS_ENDPOINT_URL = os.getenv("AWS_ENDPOINT_URL")
 DOWNLOADER_AWS_REGION = os.getenv("AWS_REGION")
 
+# Downloader GCS Envs, GCS is accessed through its S3 compatible API with HMAC keys
+DOWNLOADER_GCS_ACCESS_KEY_ID = os.getenv("GCS_ACCESS_KEY_ID")
+DOWNLOADER_GCS_SECRET_ACCESS_KEY = os.getenv("GCS_SECRET_ACCESS_KEY")
+DOWNLOADER_GCS_ENDPOINT_URL = os.getenv(
+    "GCS_ENDPOINT_URL", "https://storage.googleapis.com"
+)
+
+# Downloader OCI registry Envs
+DOWNLOADER_OCI_USERNAME = os.getenv("OCI_USERNAME")
+DOWNLOADER_OCI_PASSWORD = os.getenv("OCI_PASSWORD")
+
 # Metric Standardizing Related Config
 # Scrape config
 METRIC_SCRAPE_PATH = os.getenv("METRIC_SCRAPE_PATH", "/metrics")
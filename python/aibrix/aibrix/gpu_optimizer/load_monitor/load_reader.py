@@ -12,7 +12,6 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
-import json
 import logging
 import math
 import re
@@ -23,6 +22,8 @@ import numpy as np
 import pandas as pd
 from redis import Redis
 
+from .trace_codec import decode_request_trace
+
 logger = logging.getLogger("aibrix.gpu_optimizer.load_reader")
 
 unittest_filepath = "unittest_694cb6cf-f5b3-42ca-b3c1-55ff0b358bdb"
@@ -233,6 +234,8 @@ class GatewayLoadReader:
     {
         "{round(log2(input_tokens))}-{round(log2(output_tokens))}: {frequency}
     }
+
+    or the same object in the binary encoding of the gateway, see trace_codec.
     """
 
     def __init__(
@@ -341,15 +344,11 @@ class GatewayLoadReader:
                 logger.warning(f"Failed to retrieve {logging_key} from Redis")
             return None
 
-        # Deserialize by json: dict[string]int
+        # Deserialize by json or the binary encoding: dict[string]int
         try:
-            profile = json.loads(profile_data.decode())
-            if not isinstance(profile, dict):
-                raise Exception("Load profile is not a dictionary")
-
-            return profile
+            return decode_request_trace(profile_data)
         except Exception as e:
-            raise Exception(f"{e}, raw: {profile_data.decode()}")
+            raise Exception(f"{e}, raw: {profile_data!r}")
 
     def progress(self) -> str:
         return ""
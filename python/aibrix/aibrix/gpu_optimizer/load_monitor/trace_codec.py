@@ -0,0 +1,88 @@
+# Copyright 2025 The Aibrix Team.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import json
+from typing import Dict, Tuple
+
+# Binary request traces start with the magic and the version of their layout, JSON traces start with '{'.
+TRACE_BINARY_MAGIC = b"ATR"
+TRACE_BINARY_VERSION = 1
+
+
+def decode_request_trace(data: bytes) -> Dict[str, int]:
+    """Decode a request trace written by the gateway, in JSON or in the binary encoding of
+    config.EncodeRequestTrace, into the bucket keys "{input index}:{output index}" and the meta keys to their counts.
+
+    The binary layout is the magic "ATR" and a version byte, followed by varints: the number of meta entries, each as
+    the length of its key, the key and its value, then the number of buckets, each as the input index delta from the
+    previous bucket, the output index, delta too if the input index is the same, and the count.
+    """
+    if not data.startswith(TRACE_BINARY_MAGIC):
+        trace = json.loads(data.decode())
+        if not isinstance(trace, dict):
+            raise ValueError("Request trace is not a dictionary")
+        return trace
+
+    pos = len(TRACE_BINARY_MAGIC)
+    if pos >= len(data):
+        raise ValueError("Invalid binary request trace")
+    version = data[pos]
+    if version != TRACE_BINARY_VERSION:
+        raise ValueError(f"Unsupported request trace binary version {version}")
+    pos += 1
+
+    trace: Dict[str, int] = {}
+    num_meta, pos = _read_uvarint(data, pos)
+    for _ in range(num_meta):
+        length, pos = _read_uvarint(data, pos)
+        if pos + length > len(data):
+            raise ValueError("Invalid binary request trace")
+        key = data[pos : pos + length].decode()
+        pos += length
+        trace[key], pos = _read_varint(data, pos)
+
+    num_buckets, pos = _read_uvarint(data, pos)
+    input_index, output_index = 0, 0
+    for i in range(num_buckets):
+        input_delta, pos = _read_varint(data, pos)
+        output_value, pos = _read_varint(data, pos)
+        count, pos = _read_varint(data, pos)
+        if i > 0 and input_delta == 0:
+            output_index += output_value
+        else:
+            output_index = output_value
+        input_index += input_delta
+        trace[f"{input_index}:{output_index}"] = count
+    if pos != len(data):
+        raise ValueError("Invalid binary request trace")
+    return trace
+
+
+def _read_uvarint(data: bytes, pos: int) -> Tuple[int, int]:
+    value, shift = 0, 0
+    while pos < len(data):
+        byte = data[pos]
+        pos += 1
+        value |= (byte & 0x7F) << shift
+        if byte < 0x80:
+            return value, pos
+        shift += 7
+        if shift > 63:
+            break
+    raise ValueError("Invalid binary request trace")
+
+
+def _read_varint(data: bytes, pos: int) -> Tuple[int, int]:
+    value, pos = _read_uvarint(data, pos)
+    return (value >> 1) ^ -(value & 1), pos
@@ -0,0 +1,60 @@
+# Copyright 2025 The Aibrix Team.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+import unittest
+
+from aibrix.gpu_optimizer.load_monitor.trace_codec import decode_request_trace
+
+# Encoded by config.EncodeRequestTrace with the binary encoding.
+BINARY_TRACE = bytes.fromhex(
+    "4154520105116d6574615f696e74657276616c5f73656314116d6574615f70656e64696e675f"
+    "72657173140e6d6574615f707265636973696f6e140f6d6574615f746f74616c5f72657173b4"
+    "01066d6574615f7606049e015c020004040814025e70d804"
+)
+
+EXPECTED_TRACE = {
+    "meta_v": 3,
+    "meta_interval_sec": 10,
+    "meta_precision": 10,
+    "meta_total_reqs": 90,
+    "meta_pending_reqs": 10,
+    "79:46": 1,
+    "79:48": 2,
+    "83:10": 1,
+    "130:56": 300,
+}
+
+
+class TestTraceCodec(unittest.TestCase):
+    def test_decode_binary(self):
+        self.assertEqual(decode_request_trace(BINARY_TRACE), EXPECTED_TRACE)
+
+    def test_decode_json(self):
+        self.assertEqual(
+            decode_request_trace(b'{"79:46": 1, "meta_v": 3}'), {"79:46": 1, "meta_v": 3}
+        )
+        with self.assertRaises(ValueError):
+            decode_request_trace(b"[1, 2]")
+
+    def test_decode_invalid(self):
+        for i in range(3, len(BINARY_TRACE)):
+            with self.assertRaises(ValueError):
+                decode_request_trace(BINARY_TRACE[:i])
+        with self.assertRaises(ValueError):
+            decode_request_trace(BINARY_TRACE + b"\x00")
+        with self.assertRaisesRegex(ValueError, "version 2"):
+            decode_request_trace(b"ATR\x02")
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -22,6 +22,7 @@ from packaging.version import Version
 from aibrix.openapi.protocol import (
     ErrorResponse,
     LoadLoraAdapterRequest,
+    ReloadEngineConfigRequest,
     UnloadLoraAdapterRequest,
 )
 
@@ -71,6 +72,16 @@ class InferenceEngine(ABC):
             status_code=HTTPStatus.NOT_IMPLEMENTED,
         )
 
+    async def reload_config(
+        self, request: ReloadEngineConfigRequest
+    ) -> Union[ErrorResponse, str]:
+        return self._create_error_response(
+            f"Inference engine {self.name} with version {self.version} "
+            "not support reload config",
+            err_type="NotImplementedError",
+            status_code=HTTPStatus.NOT_IMPLEMENTED,
+        )
+
 
 def get_inference_engine(engine: str, version: str, endpoint: str) -> InferenceEngine:
     if engine.lower() == "vllm":
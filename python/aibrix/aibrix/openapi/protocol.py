@@ -45,6 +45,10 @@ class UnloadLoraAdapterRequest(NoExtraBaseModel):
     lora_int_id: Optional[int] = Field(default=None)
 
 
+class ReloadEngineConfigRequest(NoExtraBaseModel):
+    config: Dict[str, str]
+
+
 class DownloadModelRequest(NoProtectedBaseModel):
     model_uri: str
     local_dir: Optional[str] = None
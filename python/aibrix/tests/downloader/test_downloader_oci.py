@@ -0,0 +1,70 @@
+# Copyright 2024 The Aibrix Team.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import hashlib
+import tempfile
+from pathlib import Path
+
+import pytest
+
+from aibrix.downloader.base import verify_checksum
+from aibrix.downloader.oci import _parse_auth_challenge, _parse_reference_from_uri
+
+
+@pytest.mark.parametrize(
+    "uri,expected",
+    [
+        (
+            "oci://ghcr.io/org/lora:v1",
+            ("ghcr.io", "org/lora", "v1"),
+        ),
+        (
+            "oci://localhost:5000/org/lora",
+            ("localhost:5000", "org/lora", "latest"),
+        ),
+        (
+            "oci://ghcr.io/org/lora@sha256:abc",
+            ("ghcr.io", "org/lora", "sha256:abc"),
+        ),
+    ],
+)
+def test_parse_reference_from_uri(uri, expected):
+    assert _parse_reference_from_uri(uri) == expected
+
+
+def test_parse_auth_challenge():
+    params = _parse_auth_challenge(
+        'Bearer realm="https://ghcr.io/token",service="ghcr.io",'
+        'scope="repository:org/lora:pull"'
+    )
+    assert params == {
+        "realm": "https://ghcr.io/token",
+        "service": "ghcr.io",
+        "scope": "repository:org/lora:pull",
+    }
+
+
+def test_verify_checksum():
+    with tempfile.TemporaryDirectory() as tmp_dir:
+        model_path = Path(tmp_dir)
+        content = b"adapter weights"
+        model_path.joinpath("adapter_model.safetensors").write_bytes(content)
+        model_path.joinpath("adapter_config.json").write_text("{}")
+        digest = hashlib.sha256(content).hexdigest()
+
+        verify_checksum(model_path, f"sha256:{digest}")
+        with pytest.raises(ValueError):
+            verify_checksum(model_path, "sha256:" + "0" * 64)
+        with pytest.raises(ValueError):
+            verify_checksum(model_path, f"md5:{digest}")
@@ -76,5 +76,58 @@ var _ = ginkgo.Describe("modelAdapter default and validation", func() {
 			},
 			failed: true,
 		}),
+		ginkgo.Entry("adapter creation with unsupported artifactURL scheme should be failed", &testValidatingCase{
+			adapter: func() *modelapi.ModelAdapter {
+				adapter := makeModelAdapter(ns.Name)
+				adapter.Spec.ArtifactURL = "ftp://models/lora"
+				return adapter
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("adapter creation with empty podSelector should be failed", &testValidatingCase{
+			adapter: func() *modelapi.ModelAdapter {
+				adapter := makeModelAdapter(ns.Name)
+				adapter.Spec.PodSelector = &metav1.LabelSelector{}
+				return adapter
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("adapter creation with invalid rollout threshold should be failed", &testValidatingCase{
+			adapter: func() *modelapi.ModelAdapter {
+				adapter := makeModelAdapter(ns.Name)
+				adapter.Spec.RolloutStrategy = &modelapi.ModelAdapterRolloutStrategy{MaxLatencyRatio: "fast"}
+				return adapter
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("valid adapter creation should be succeeded", &testValidatingCase{
+			adapter: func() *modelapi.ModelAdapter {
+				return makeModelAdapter(ns.Name)
+			},
+			failed: false,
+		}),
 	)
+
+	ginkgo.It("should default replicas and scheduling policy", func() {
+		adapter := makeModelAdapter(ns.Name)
+		gomega.Expect(k8sClient.Create(ctx, adapter)).To(gomega.Succeed())
+		gomega.Expect(adapter.Spec.Replicas).NotTo(gomega.BeNil())
+		gomega.Expect(*adapter.Spec.Replicas).To(gomega.Equal(int32(1)))
+		gomega.Expect(adapter.Spec.SchedulingPolicy).To(gomega.Equal(modelapi.ModelAdapterSchedulingSpread))
+	})
 })
+
+func makeModelAdapter(namespace string) *modelapi.ModelAdapter {
+	return &modelapi.ModelAdapter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-adapter",
+			Namespace: namespace,
+		},
+		Spec: modelapi.ModelAdapterSpec{
+			ArtifactURL: "huggingface://yard1/llama-2-7b-sql-lora-test",
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"model.aibrix.ai/name": "llama2-7b"},
+			},
+		},
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	autoscalingapi "github.com/vllm-project/aibrix/api/autoscaling/v1alpha1"
+)
+
+var _ = ginkgo.Describe("podAutoscaler default and validation", func() {
+	var ns *corev1.Namespace
+
+	ginkgo.BeforeEach(func() {
+		ns = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-ns-",
+			},
+		}
+		gomega.Expect(k8sClient.Create(ctx, ns)).To(gomega.Succeed())
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(k8sClient.Delete(ctx, ns)).To(gomega.Succeed())
+		var pas autoscalingapi.PodAutoscalerList
+		gomega.Expect(k8sClient.List(ctx, &pas)).To(gomega.Succeed())
+
+		for _, item := range pas.Items {
+			gomega.Expect(k8sClient.Delete(ctx, &item)).To(gomega.Succeed())
+		}
+	})
+
+	type testValidatingCase struct {
+		pa     func() *autoscalingapi.PodAutoscaler
+		failed bool
+	}
+	ginkgo.DescribeTable("test validating",
+		func(tc *testValidatingCase) {
+			if tc.failed {
+				gomega.Expect(k8sClient.Create(ctx, tc.pa())).Should(gomega.HaveOccurred())
+			} else {
+				gomega.Expect(k8sClient.Create(ctx, tc.pa())).To(gomega.Succeed())
+			}
+		},
+		ginkgo.Entry("valid pa creation should be succeeded", &testValidatingCase{
+			pa: func() *autoscalingapi.PodAutoscaler {
+				return makePodAutoscaler(ns.Name)
+			},
+			failed: false,
+		}),
+		ginkgo.Entry("pa creation with minReplicas above maxReplicas should be failed", &testValidatingCase{
+			pa: func() *autoscalingapi.PodAutoscaler {
+				pa := makePodAutoscaler(ns.Name)
+				pa.Spec.MinReplicas = ptr.To[int32](5)
+				pa.Spec.MaxReplicas = 2
+				return pa
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("pa creation with kafka metric source missing kafka config should be failed", &testValidatingCase{
+			pa: func() *autoscalingapi.PodAutoscaler {
+				pa := makePodAutoscaler(ns.Name)
+				pa.Spec.MetricsSources[0] = autoscalingapi.MetricSource{
+					MetricSourceType: autoscalingapi.KAFKA,
+					Endpoint:         "kafka-rest.default:8082",
+					TargetMetric:     "lag",
+					TargetValue:      "100",
+				}
+				return pa
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("pa creation with SLO strategy and metric sources should be failed", &testValidatingCase{
+			pa: func() *autoscalingapi.PodAutoscaler {
+				pa := makePodAutoscaler(ns.Name)
+				pa.Spec.ScalingStrategy = autoscalingapi.SLO
+				pa.Spec.SLOTargets = &autoscalingapi.SLOTargets{TTFT: "0.5"}
+				return pa
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("pa creation with non-numeric target value should be failed", &testValidatingCase{
+			pa: func() *autoscalingapi.PodAutoscaler {
+				pa := makePodAutoscaler(ns.Name)
+				pa.Spec.MetricsSources[0].TargetValue = "half"
+				return pa
+			},
+			failed: true,
+		}),
+	)
+
+	ginkgo.It("should default the protocol type of metric sources", func() {
+		pa := makePodAutoscaler(ns.Name)
+		pa.Spec.MetricsSources[0].ProtocolType = ""
+		gomega.Expect(k8sClient.Create(ctx, pa)).To(gomega.Succeed())
+		gomega.Expect(pa.Spec.MetricsSources[0].ProtocolType).To(gomega.Equal(autoscalingapi.HTTP))
+	})
+})
+
+func makePodAutoscaler(namespace string) *autoscalingapi.PodAutoscaler {
+	return &autoscalingapi.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pa",
+			Namespace: namespace,
+		},
+		Spec: autoscalingapi.PodAutoscalerSpec{
+			ScaleTargetRef: corev1.ObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "llama2-7b",
+			},
+			MinReplicas:     ptr.To[int32](1),
+			MaxReplicas:     4,
+			ScalingStrategy: autoscalingapi.KPA,
+			MetricsSources: []autoscalingapi.MetricSource{{
+				MetricSourceType: autoscalingapi.POD,
+				ProtocolType:     autoscalingapi.HTTP,
+				Port:             "8000",
+				Path:             "/metrics",
+				TargetMetric:     "gpu_cache_usage_perc",
+				TargetValue:      "0.5",
+			}},
+		},
+	}
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2025 The Aibrix Team.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	rayclusterv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	orchestrationapi "github.com/vllm-project/aibrix/api/orchestration/v1alpha1"
+)
+
+var _ = ginkgo.Describe("rayClusterFleet default and validation", func() {
+	var ns *corev1.Namespace
+
+	ginkgo.BeforeEach(func() {
+		ns = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-ns-",
+			},
+		}
+		gomega.Expect(k8sClient.Create(ctx, ns)).To(gomega.Succeed())
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(k8sClient.Delete(ctx, ns)).To(gomega.Succeed())
+		var fleets orchestrationapi.RayClusterFleetList
+		gomega.Expect(k8sClient.List(ctx, &fleets)).To(gomega.Succeed())
+
+		for _, item := range fleets.Items {
+			gomega.Expect(k8sClient.Delete(ctx, &item)).To(gomega.Succeed())
+		}
+	})
+
+	type testValidatingCase struct {
+		fleet  func() *orchestrationapi.RayClusterFleet
+		failed bool
+	}
+	ginkgo.DescribeTable("test validating",
+		func(tc *testValidatingCase) {
+			if tc.failed {
+				gomega.Expect(k8sClient.Create(ctx, tc.fleet())).Should(gomega.HaveOccurred())
+			} else {
+				gomega.Expect(k8sClient.Create(ctx, tc.fleet())).To(gomega.Succeed())
+			}
+		},
+		ginkgo.Entry("valid fleet creation should be succeeded", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				return makeRayClusterFleet(ns.Name)
+			},
+			failed: false,
+		}),
+		ginkgo.Entry("fleet creation with zero maxSurge and maxUnavailable should be failed", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{
+					MaxSurge:       ptr.To(intstr.FromInt32(0)),
+					MaxUnavailable: ptr.To(intstr.FromString("0%")),
+				}
+				return fleet
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("fleet creation with maxUnavailable above 100% should be failed", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{
+					MaxUnavailable: ptr.To(intstr.FromString("150%")),
+				}
+				return fleet
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("fleet creation with rollingUpdate on Recreate strategy should be failed", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.Strategy = appsv1.DeploymentStrategy{
+					Type:          appsv1.RecreateDeploymentStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(1))},
+				}
+				return fleet
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("fleet creation with partition on Recreate strategy should be failed", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+				fleet.Spec.Partition = ptr.To[int32](1)
+				return fleet
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("fleet creation with zero maxSkew spread constraint should be failed", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+					MaxSkew:           0,
+					TopologyKey:       corev1.LabelTopologyZone,
+					WhenUnsatisfiable: corev1.DoNotSchedule,
+				}}
+				return fleet
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("fleet creation with volcano gang scheduling should be succeeded", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.GangScheduling = &orchestrationapi.GangSchedulingPolicy{Scheduler: orchestrationapi.VolcanoGangScheduler}
+				return fleet
+			},
+			failed: false,
+		}),
+		ginkgo.Entry("fleet creation with kueue gang scheduling without queue should be failed", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.GangScheduling = &orchestrationapi.GangSchedulingPolicy{Scheduler: orchestrationapi.KueueGangScheduler}
+				return fleet
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("fleet creation with kueue gang scheduling of autoscaling clusters should be failed", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.GangScheduling = &orchestrationapi.GangSchedulingPolicy{Scheduler: orchestrationapi.KueueGangScheduler, Queue: "inference"}
+				fleet.Spec.Template.Spec.EnableInTreeAutoscaling = ptr.To(true)
+				return fleet
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("fleet creation with http serving probe should be succeeded", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.ServingProbe = &orchestrationapi.ServingProbe{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromInt32(8000)},
+				}
+				return fleet
+			},
+			failed: false,
+		}),
+		ginkgo.Entry("fleet creation with serving probe without handler should be failed", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.ServingProbe = &orchestrationapi.ServingProbe{}
+				return fleet
+			},
+			failed: true,
+		}),
+		ginkgo.Entry("fleet creation with selector not matching template labels should be failed", &testValidatingCase{
+			fleet: func() *orchestrationapi.RayClusterFleet {
+				fleet := makeRayClusterFleet(ns.Name)
+				fleet.Spec.Template.Labels = map[string]string{"app": "other"}
+				return fleet
+			},
+			failed: true,
+		}),
+	)
+
+	ginkgo.It("should default the rolling update strategy", func() {
+		fleet := makeRayClusterFleet(ns.Name)
+		gomega.Expect(k8sClient.Create(ctx, fleet)).To(gomega.Succeed())
+		gomega.Expect(fleet.Spec.Strategy.Type).To(gomega.Equal(appsv1.RollingUpdateDeploymentStrategyType))
+		gomega.Expect(fleet.Spec.Strategy.RollingUpdate).NotTo(gomega.BeNil())
+		gomega.Expect(*fleet.Spec.Strategy.RollingUpdate.MaxSurge).To(gomega.Equal(intstr.FromString("25%")))
+		gomega.Expect(*fleet.Spec.Strategy.RollingUpdate.MaxUnavailable).To(gomega.Equal(intstr.FromString("25%")))
+	})
+
+	ginkgo.It("should default the disruption budget", func() {
+		fleet := makeRayClusterFleet(ns.Name)
+		fleet.Spec.DisruptionBudget = &orchestrationapi.RayClusterFleetDisruptionBudget{}
+		gomega.Expect(k8sClient.Create(ctx, fleet)).To(gomega.Succeed())
+		gomega.Expect(*fleet.Spec.DisruptionBudget.MaxUnavailable).To(gomega.Equal(intstr.FromInt32(1)))
+	})
+
+	ginkgo.It("should reject selector updates", func() {
+		fleet := makeRayClusterFleet(ns.Name)
+		gomega.Expect(k8sClient.Create(ctx, fleet)).To(gomega.Succeed())
+		fleet.Spec.Selector.MatchLabels["tier"] = "serving"
+		fleet.Spec.Template.Labels["tier"] = "serving"
+		gomega.Expect(k8sClient.Update(ctx, fleet)).Should(gomega.HaveOccurred())
+	})
+})
+
+func makeRayClusterFleet(namespace string) *orchestrationapi.RayClusterFleet {
+	return &orchestrationapi.RayClusterFleet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-fleet",
+			Namespace: namespace,
+		},
+		Spec: orchestrationapi.RayClusterFleetSpec{
+			Replicas: ptr.To[int32](2),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"model.aibrix.ai/name": "qwen-coder-7b"},
+			},
+			Template: orchestrationapi.RayClusterTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"model.aibrix.ai/name": "qwen-coder-7b"},
+				},
+				Spec: rayclusterv1.RayClusterSpec{
+					HeadGroupSpec: rayclusterv1.HeadGroupSpec{
+						RayStartParams: map[string]string{"dashboard-host": "0.0.0.0"},
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{
+									Name:  "ray-head",
+									Image: "rayproject/ray:2.10.0",
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -127,6 +127,10 @@ var _ = BeforeSuite(func() {
 
 	err = apiwebhook.SetupBackendRuntimeWebhook(mgr)
 	Expect(err).NotTo(HaveOccurred())
+	err = apiwebhook.SetupPodAutoscalerWebhook(mgr)
+	Expect(err).NotTo(HaveOccurred())
+	err = apiwebhook.SetupRayClusterFleetWebhook(mgr)
+	Expect(err).NotTo(HaveOccurred())
 
 	//+kubebuilder:scaffold:webhook
 